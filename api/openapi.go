@@ -0,0 +1,9 @@
+// Package api holds the OpenAPI document of the HTTP API, which the server serves and validates requests against
+package api
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI 3.1 document of the HTTP API, in YAML
+//
+//go:embed openapi.yaml
+var OpenAPI []byte
@@ -0,0 +1,128 @@
+// Command snapdeploy-agent is a self-hosted build runner. It claims the builds a server running with
+// BUILD_BACKEND=agent queues, clones, builds and pushes them with the Docker daemon of the machine it runs on,
+// and streams their output and outcome back over the build agent gRPC API:
+//
+//	export SNAPDEPLOY_AGENT_TOKEN=...
+//	snapdeploy-agent --server core.example.com:9090
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"snapdeploy-core/internal/infrastructure/builder"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const usage = `Usage: snapdeploy-agent [--server HOST:PORT] [--token TOKEN] [--name NAME] [--work-dir DIR]
+                        [--max-build-disk-mb MB] [--min-free-disk-mb MB] [--clone-cache=false] [--insecure]
+
+Claims builds from a SnapDeploy server running with BUILD_BACKEND=agent and runs them with the local Docker daemon.
+The docker CLI must be logged in to the registry images are pushed to.
+
+The token is read from --token or SNAPDEPLOY_AGENT_TOKEN, the server address from --server or SNAPDEPLOY_AGENT_SERVER.
+Builds don't start while the disk of --work-dir has less than --min-free-disk-mb left, and are stopped once their
+directory grows past --max-build-disk-mb or the disk fills up.
+The history of each repository built is cached in --work-dir, so its next builds only fetch what was pushed since.
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "snapdeploy-agent: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	hostname, _ := os.Hostname()
+
+	flags := flag.NewFlagSet("snapdeploy-agent", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	server := flags.String("server", envOr("SNAPDEPLOY_AGENT_SERVER", "localhost:9090"), "address of the build agent gRPC API")
+	token := flags.String("token", os.Getenv("SNAPDEPLOY_AGENT_TOKEN"), "agent token (created with POST /agent-tokens, or AGENT_TOKEN of the server)")
+	name := flags.String("name", envOr("SNAPDEPLOY_AGENT_NAME", hostname), "name shown in the logs of the deployments this agent builds")
+	workDir := flags.String("work-dir", envOr("SNAPDEPLOY_AGENT_WORK_DIR", filepath.Join(os.TempDir(), "snapdeploy-agent")), "where repositories are cloned")
+	maxBuildDisk := flags.Int("max-build-disk-mb", envOrInt("SNAPDEPLOY_AGENT_MAX_BUILD_DISK_MB", 5120), "size in MB a build's directory may grow to before it is stopped, 0 is unlimited")
+	minFreeDisk := flags.Int("min-free-disk-mb", envOrInt("SNAPDEPLOY_AGENT_MIN_FREE_DISK_MB", 2048), "free disk space in MB builds need to start and keep running")
+	cloneCache := flags.Bool("clone-cache", os.Getenv("SNAPDEPLOY_AGENT_CLONE_CACHE") != "false", "cache the history of repositories in --work-dir for their next builds")
+	plaintext := flags.Bool("insecure", false, "connect without TLS, for servers on a trusted network")
+	if err := flags.Parse(args); err != nil {
+		return flag.ErrHelp
+	}
+	if *token == "" {
+		return errors.New("no agent token, set SNAPDEPLOY_AGENT_TOKEN or pass --token")
+	}
+	if *name == "" {
+		return errors.New("no agent name, pass --name")
+	}
+
+	transport := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	if *plaintext {
+		transport = insecure.NewCredentials()
+	}
+	conn, err := grpc.NewClient(*server,
+		grpc.WithTransportCredentials(transport),
+		grpc.WithPerRPCCredentials(bearerToken{token: *token, secure: !*plaintext}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", *server, err)
+	}
+	defer conn.Close()
+
+	workspace := builder.NewWorkspace(*workDir, int64(*maxBuildDisk)<<20, int64(*minFreeDisk)<<20)
+	workspace.SetCloneCache(*cloneCache)
+	go workspace.RunCleanup(ctx, workspaceCleanupInterval)
+
+	slog.Info("Build agent started", "server", *server, "name", *name, "work_dir", *workDir)
+	newRunner(conn, *name, workspace).Run(ctx)
+	slog.Info("Build agent stopped")
+	return nil
+}
+
+// bearerToken presents the agent token as "authorization: Bearer <token>" metadata on every call
+type bearerToken struct {
+	token  string
+	secure bool
+}
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+func (t bearerToken) RequireTransportSecurity() bool {
+	return t.secure
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func envOrInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
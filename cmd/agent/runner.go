@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/infrastructure/builder"
+	"snapdeploy-core/internal/presentation/agentrpc"
+	"snapdeploy-core/internal/presentation/agentrpc/agentpb"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	// claimRetryDelay is how long the runner waits after a failed claim before asking again
+	claimRetryDelay = 5 * time.Second
+
+	// logFlushInterval is how often build output is pushed to the server
+	logFlushInterval = time.Second
+
+	// completeTimeout bounds reporting the outcome of a build, which is also done while the agent shuts down
+	completeTimeout = 30 * time.Second
+
+	// workspaceCleanupInterval is how often directories left behind by builds of a crashed agent are removed
+	workspaceCleanupInterval = 30 * time.Minute
+)
+
+// runner claims builds one at a time and runs them on this machine
+type runner struct {
+	jobs      agentpb.BuildJobServiceClient
+	logs      agentpb.LogIngestionServiceClient
+	name      string
+	workspace *builder.Workspace
+}
+
+func newRunner(conn grpc.ClientConnInterface, name string, workspace *builder.Workspace) *runner {
+	return &runner{
+		jobs:      agentpb.NewBuildJobServiceClient(conn),
+		logs:      agentpb.NewLogIngestionServiceClient(conn),
+		name:      name,
+		workspace: workspace,
+	}
+}
+
+// Run claims and runs builds until the context is cancelled
+func (r *runner) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		resp, err := r.jobs.ClaimBuild(ctx, &agentpb.ClaimBuildRequest{AgentName: r.name})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Error("Failed to claim build", "error", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(claimRetryDelay):
+			}
+			continue
+		}
+
+		// The server answers with no build when none was queued while it waited
+		if build := resp.GetBuild(); build != nil {
+			r.runBuild(ctx, build)
+		}
+	}
+}
+
+// runBuild runs a claimed build, pushing its output as it is written, and reports the outcome
+func (r *runner) runBuild(ctx context.Context, build *agentpb.Build) {
+	logger := slog.With("build_id", build.GetBuildId(), "deployment_id", build.GetDeploymentId())
+	logger.Info("Running build", "image", build.GetImageTag())
+
+	req := builder.BuildRequest{
+		RepositoryURL: build.GetRepositoryUrl(),
+		Branch:        build.GetBranch(),
+		CommitHash:    build.GetCommitHash(),
+		ImageTag:      build.GetImageTag(),
+		Dockerfile:    build.GetDockerfile(),
+		BuildArgs:     build.GetBuildArgs(),
+	}
+	var creds *repo.CloneCredentials
+	if c := build.GetCloneCredentials(); c != nil {
+		creds = &repo.CloneCredentials{URL: c.GetUrl(), Username: c.GetUsername(), Token: c.GetToken()}
+	}
+
+	out := newLogPusher(ctx, r.logs, build.GetDeploymentId(), logger)
+	out.add("Building on agent " + r.name)
+	buildErr := builder.RunBuild(ctx, r.workspace, req, creds, out.add)
+	out.close()
+
+	complete := &agentpb.CompleteBuildRequest{BuildId: build.GetBuildId(), Status: string(builder.BuildSucceeded)}
+	if buildErr != nil {
+		complete.Status = string(builder.BuildFailed)
+		complete.Error = buildErr.Error()
+		if ctx.Err() != nil {
+			complete.Error = "the agent was stopped"
+		}
+	}
+
+	// The outcome is reported even when the agent is stopping, so the deployment doesn't wait for the timeout
+	reportCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), completeTimeout)
+	defer cancel()
+	if _, err := r.jobs.CompleteBuild(reportCtx, complete); err != nil {
+		logger.Error("Failed to report build outcome", "error", err)
+		return
+	}
+	logger.Info("Build finished", "status", complete.Status)
+}
+
+// logPusher batches the output of a build and pushes it to the server on a PushLogs stream
+type logPusher struct {
+	deploymentID string
+	logger       *slog.Logger
+
+	mu      sync.Mutex
+	pending []string
+	wake    chan struct{}
+	done    chan struct{}
+}
+
+func newLogPusher(ctx context.Context, client agentpb.LogIngestionServiceClient, deploymentID string, logger *slog.Logger) *logPusher {
+	p := &logPusher{
+		deploymentID: deploymentID,
+		logger:       logger,
+		wake:         make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+	go p.push(context.WithoutCancel(ctx), client)
+	return p
+}
+
+// add queues a line of output, flushing early once a full batch is pending
+func (p *logPusher) add(line string) {
+	p.mu.Lock()
+	p.pending = append(p.pending, line)
+	full := len(p.pending) >= agentrpc.MaxLinesPerBatch
+	p.mu.Unlock()
+
+	if full {
+		select {
+		case p.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// close pushes the remaining output and waits for the server to acknowledge it
+func (p *logPusher) close() {
+	close(p.wake)
+	<-p.done
+}
+
+// push sends the pending output every logFlushInterval until the pusher is closed. Output is dropped,
+// rather than holding up the build, when the server can't take it.
+func (p *logPusher) push(ctx context.Context, client agentpb.LogIngestionServiceClient) {
+	defer close(p.done)
+
+	stream, err := client.PushLogs(ctx)
+	if err != nil {
+		p.logger.Error("Failed to open log stream", "error", err)
+	}
+
+	ticker := time.NewTicker(logFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		closed := false
+		select {
+		case _, ok := <-p.wake:
+			closed = !ok
+		case <-ticker.C:
+		}
+
+		p.mu.Lock()
+		lines := p.pending
+		p.pending = nil
+		p.mu.Unlock()
+
+		for len(lines) > 0 && stream != nil {
+			batch := lines[:min(len(lines), agentrpc.MaxLinesPerBatch)]
+			lines = lines[len(batch):]
+			if err := stream.Send(&agentpb.PushLogsRequest{DeploymentId: p.deploymentID, Lines: batch}); err != nil {
+				p.logger.Error("Failed to push build output", "error", err)
+				stream = nil
+			}
+		}
+
+		if closed {
+			if stream != nil {
+				if _, err := stream.CloseAndRecv(); err != nil {
+					p.logger.Error("Failed to push build output", "error", err)
+				}
+			}
+			return
+		}
+	}
+}
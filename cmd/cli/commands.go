@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/pkg/client"
+)
+
+const (
+	// pageSize is the number of items listed per API request
+	pageSize = 100
+	// statusPollInterval is how often a followed deployment is checked for completion
+	statusPollInterval = 5 * time.Second
+)
+
+// commands runs the CLI's commands against the API
+type commands struct {
+	client *client.Client
+	out    io.Writer
+}
+
+func (c *commands) listProjects(ctx context.Context) error {
+	user, err := c.client.CurrentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDOMAIN\tTYPE\tSTATUS\tURL")
+	for page := 1; ; page++ {
+		projects, err := c.client.ListProjects(ctx, user.ID, &client.ListOptions{Page: page, Limit: pageSize})
+		if err != nil {
+			return err
+		}
+		for _, p := range projects.Projects {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.ID, p.CustomDomain, p.Type, p.Status, p.DeploymentURL)
+		}
+		if int64(page) >= projects.Pagination.TotalPages {
+			break
+		}
+	}
+	return w.Flush()
+}
+
+func (c *commands) deploy(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	projectID := fs.String("project", "", "project ID")
+	env := fs.String("env", "", "environment to deploy to (defaults to production)")
+	branch := fs.String("branch", "", "branch to deploy (defaults to the checked out one)")
+	commit := fs.String("commit", "", "commit to deploy (defaults to the checked out one)")
+	follow := fs.Bool("follow", false, "stream the logs until the deployment finishes")
+	if _, err := parseFlags(fs, args); err != nil || *projectID == "" {
+		return errUsage
+	}
+
+	var err error
+	if *commit == "" {
+		if *commit, err = git(ctx, "rev-parse", "HEAD"); err != nil {
+			return fmt.Errorf("no --commit given and the checked out one is unknown: %w", err)
+		}
+	}
+	if *branch == "" {
+		if *branch, err = git(ctx, "rev-parse", "--abbrev-ref", "HEAD"); err != nil || *branch == "HEAD" {
+			return errors.New("no --branch given and no branch is checked out")
+		}
+	}
+
+	dep, err := c.client.CreateDeployment(ctx, &client.CreateDeploymentRequest{
+		ProjectID:   *projectID,
+		CommitHash:  *commit,
+		Branch:      *branch,
+		Environment: *env,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.started(ctx, dep, *follow)
+}
+
+func (c *commands) logs(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("logs", flag.ContinueOnError)
+	follow := fs.Bool("follow", false, "stream the logs until the deployment finishes")
+	positional, err := parseFlags(fs, args)
+	if err != nil || len(positional) != 1 {
+		return errUsage
+	}
+	deploymentID := positional[0]
+
+	if *follow {
+		return c.followDeployment(ctx, deploymentID)
+	}
+
+	dep, err := c.client.GetDeployment(ctx, deploymentID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(c.out, dep.Logs)
+	if dep.Logs != "" && !strings.HasSuffix(dep.Logs, "\n") {
+		fmt.Fprintln(c.out)
+	}
+	return nil
+}
+
+func (c *commands) listEnv(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("env list", flag.ContinueOnError)
+	projectID := fs.String("project", "", "project ID")
+	env := fs.String("env", "", "environment (defaults to production)")
+	if _, err := parseFlags(fs, args); err != nil || *projectID == "" {
+		return errUsage
+	}
+
+	envVars, err := c.client.ListEnvVars(ctx, *projectID, *env)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSCOPE")
+	for _, v := range envVars.EnvironmentVariables {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", v.Key, v.Value, v.Scope)
+	}
+	return w.Flush()
+}
+
+func (c *commands) setEnv(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("env set", flag.ContinueOnError)
+	projectID := fs.String("project", "", "project ID")
+	env := fs.String("env", "", "environment (defaults to production)")
+	scope := fs.String("scope", "", "BUILD, RUNTIME or BOTH (defaults to RUNTIME)")
+	pairs, err := parseFlags(fs, args)
+	if err != nil || *projectID == "" || len(pairs) == 0 {
+		return errUsage
+	}
+
+	// Check every pair before setting any, so a typo doesn't leave the variables half set
+	requests := make([]*client.CreateEnvVarRequest, 0, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid variable %q, expected KEY=VALUE", pair)
+		}
+		requests = append(requests, &client.CreateEnvVarRequest{
+			Key:         key,
+			Value:       value,
+			Scope:       strings.ToUpper(*scope),
+			Environment: *env,
+		})
+	}
+
+	for _, req := range requests {
+		envVar, err := c.client.SetEnvVar(ctx, *projectID, req)
+		if err != nil {
+			return fmt.Errorf("failed to set %s: %w", req.Key, err)
+		}
+		fmt.Fprintf(c.out, "Set %s (%s) in %s\n", envVar.Key, envVar.Scope, envVar.Environment)
+	}
+	fmt.Fprintln(c.out, "Changes take effect on the next deployment.")
+	return nil
+}
+
+func (c *commands) rollback(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("rollback", flag.ContinueOnError)
+	projectID := fs.String("project", "", "project ID")
+	env := fs.String("env", project.EnvironmentProduction.String(), "environment to roll back")
+	to := fs.String("to", "", "deployment to go back to (defaults to the previous successful one)")
+	follow := fs.Bool("follow", false, "stream the logs until the deployment finishes")
+	if _, err := parseFlags(fs, args); err != nil || *projectID == "" {
+		return errUsage
+	}
+
+	var target *client.Deployment
+	var err error
+	if *to != "" {
+		target, err = c.client.GetDeployment(ctx, *to)
+		if err != nil {
+			return err
+		}
+		if target.ProjectID != *projectID {
+			return fmt.Errorf("deployment %s belongs to another project", target.ID)
+		}
+	} else {
+		target, err = c.previousDeployment(ctx, *projectID, *env)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(c.out, "Rolling back to %s@%s (deployment %s)\n", target.Branch, shortCommit(target.CommitHash), target.ID)
+	dep, err := c.client.CreateDeployment(ctx, &client.CreateDeploymentRequest{
+		ProjectID:   *projectID,
+		CommitHash:  target.CommitHash,
+		Branch:      target.Branch,
+		Environment: target.Environment,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.started(ctx, dep, *follow)
+}
+
+// previousDeployment finds the last successful deployment of an environment with a different commit
+// than the running one
+func (c *commands) previousDeployment(ctx context.Context, projectID, env string) (*client.Deployment, error) {
+	var current *client.Deployment
+	opts := &client.ListOptions{Limit: pageSize}
+	for {
+		deployments, err := c.client.ListProjectDeployments(ctx, projectID, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range deployments.Deployments {
+			if dep.Environment != env || dep.Status != deployment.StatusDeployed.String() {
+				continue
+			}
+			if current == nil {
+				current = dep
+			} else if dep.CommitHash != current.CommitHash {
+				return dep, nil
+			}
+		}
+		// Deployments started meanwhile don't shift the cursor like they would shift pages
+		if deployments.Pagination.NextCursor == "" {
+			break
+		}
+		opts.Cursor = deployments.Pagination.NextCursor
+	}
+
+	if current == nil {
+		return nil, fmt.Errorf("%s has no successful deployment", env)
+	}
+	return nil, fmt.Errorf("%s has no successful deployment before %s to roll back to", env, shortCommit(current.CommitHash))
+}
+
+// started reports a new deployment, following it if asked to
+func (c *commands) started(ctx context.Context, dep *client.Deployment, follow bool) error {
+	fmt.Fprintf(c.out, "Deploying %s@%s to %s (deployment %s)\n", dep.Branch, shortCommit(dep.CommitHash), dep.Environment, dep.ID)
+	if dep.Status == deployment.StatusWaitingApproval.String() {
+		fmt.Fprintf(c.out, "%s is protected, the deployment waits for approval\n", dep.Environment)
+	}
+	if !follow {
+		return nil
+	}
+	return c.followDeployment(ctx, dep.ID)
+}
+
+// followDeployment prints a deployment's logs as they come until it finishes, failing unless it deployed
+func (c *commands) followDeployment(ctx context.Context, deploymentID string) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	streamErr := make(chan error, 1)
+	go func() {
+		streamErr <- c.client.StreamLogs(streamCtx, deploymentID, func(line string) {
+			fmt.Fprintln(c.out, line)
+		})
+	}()
+
+	ticker := time.NewTicker(statusPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-streamErr:
+			if err != nil {
+				return err
+			}
+			// The server closed the stream, keep waiting for the outcome
+			streamErr = nil
+		case <-ticker.C:
+			dep, err := c.client.GetDeployment(ctx, deploymentID)
+			if err != nil {
+				return err
+			}
+			status := deployment.DeploymentStatus(dep.Status)
+			if !status.IsTerminal() {
+				continue
+			}
+
+			// Give the stream a moment to deliver the last lines
+			time.Sleep(time.Second)
+			cancel()
+			if status != deployment.StatusDeployed {
+				return fmt.Errorf("deployment %s ended %s", deploymentID, status)
+			}
+			fmt.Fprintf(c.out, "Deployment %s succeeded\n", deploymentID)
+			return nil
+		}
+	}
+}
+
+// parseFlags parses flags that may come before, between or after positional arguments,
+// returning the positional ones
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// git runs a git command in the working directory, returning its trimmed output
+func git(ctx context.Context, args ...string) (string, error) {
+	output, err := exec.CommandContext(ctx, "git", args...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func shortCommit(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,105 @@
+// Command snapdeploy is the SnapDeploy command line client. It talks to the public API with an API token:
+//
+//	export SNAPDEPLOY_TOKEN=...
+//	snapdeploy projects list
+//	snapdeploy deploy --project <id> --follow
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"snapdeploy-core/pkg/client"
+)
+
+const usage = `Usage: snapdeploy [--api-url URL] [--token TOKEN] <command> [flags]
+
+Commands:
+  projects list                              List your projects
+  deploy --project ID [--env ENV]            Deploy a commit (defaults to the checked out one)
+         [--branch BRANCH] [--commit SHA] [--follow]
+  logs DEPLOYMENT_ID [--follow]              Print a deployment's logs
+  env list --project ID [--env ENV]          List environment variables (values are masked)
+  env set --project ID [--env ENV]           Set environment variables
+          [--scope BUILD|RUNTIME|BOTH] KEY=VALUE...
+  rollback --project ID [--env ENV]          Redeploy the previous successful deployment
+           [--to DEPLOYMENT_ID] [--follow]
+
+The API token is read from --token or SNAPDEPLOY_TOKEN, the API URL from --api-url or SNAPDEPLOY_API_URL.
+`
+
+// errUsage is returned when a command is called with missing or invalid arguments
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "snapdeploy: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, out io.Writer) error {
+	global := flag.NewFlagSet("snapdeploy", flag.ContinueOnError)
+	global.SetOutput(io.Discard)
+	apiURL := global.String("api-url", envOr("SNAPDEPLOY_API_URL", client.DefaultBaseURL), "SnapDeploy API URL")
+	token := global.String("token", os.Getenv("SNAPDEPLOY_TOKEN"), "API token")
+	if err := global.Parse(args); err != nil {
+		return errUsage
+	}
+
+	args = global.Args()
+	if len(args) == 0 {
+		return errUsage
+	}
+	if *token == "" {
+		return errors.New("no API token, set SNAPDEPLOY_TOKEN or pass --token")
+	}
+
+	cmd := &commands{client: client.New(*apiURL, *token), out: out}
+	switch args[0] {
+	case "projects":
+		if len(args) < 2 || args[1] != "list" {
+			return errUsage
+		}
+		return cmd.listProjects(ctx)
+	case "deploy":
+		return cmd.deploy(ctx, args[1:])
+	case "logs":
+		return cmd.logs(ctx, args[1:])
+	case "env":
+		if len(args) < 2 {
+			return errUsage
+		}
+		switch args[1] {
+		case "list":
+			return cmd.listEnv(ctx, args[2:])
+		case "set":
+			return cmd.setEnv(ctx, args[2:])
+		}
+		return errUsage
+	case "rollback":
+		return cmd.rollback(ctx, args[1:])
+	default:
+		return errUsage
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -3,51 +3,58 @@ package main
 import (
 	"context"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"snapdeploy-core/api"
 	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/bitbucket"
 	"snapdeploy-core/internal/clerk"
 	"snapdeploy-core/internal/config"
 	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/events"
+	"snapdeploy-core/internal/domain/quota"
+	"snapdeploy-core/internal/domain/repo"
 	"snapdeploy-core/internal/github"
+	"snapdeploy-core/internal/gitlab"
+	"snapdeploy-core/internal/infrastructure/alerts"
 	"snapdeploy-core/internal/infrastructure/builder"
 	"snapdeploy-core/internal/infrastructure/codebuild"
+	"snapdeploy-core/internal/infrastructure/ecr"
 	"snapdeploy-core/internal/infrastructure/ecs"
+	"snapdeploy-core/internal/infrastructure/ecsevents"
 	"snapdeploy-core/internal/infrastructure/encryption"
+	"snapdeploy-core/internal/infrastructure/logfanout"
+	infraBitbucket "snapdeploy-core/internal/infrastructure/bitbucket"
 	infraClerk "snapdeploy-core/internal/infrastructure/clerk"
 	infraGitHub "snapdeploy-core/internal/infrastructure/github"
+	infraGitLab "snapdeploy-core/internal/infrastructure/gitlab"
+	infraStripe "snapdeploy-core/internal/infrastructure/stripe"
 	"snapdeploy-core/internal/infrastructure/persistence"
+	"snapdeploy-core/internal/infrastructure/sbom"
+	"snapdeploy-core/internal/infrastructure/sts"
+	"snapdeploy-core/internal/infrastructure/uptime"
+	"snapdeploy-core/internal/logging"
 	"snapdeploy-core/internal/middleware"
+	"snapdeploy-core/internal/openapi"
+	"snapdeploy-core/internal/presentation/agentrpc"
 	"snapdeploy-core/internal/presentation/handlers"
+	"snapdeploy-core/internal/ratelimit"
+	"snapdeploy-core/internal/stripe"
+	"snapdeploy-core/internal/tracing"
 
 	"github.com/gin-gonic/gin"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
-// @title SnapDeploy Core API
-// @version 1.0
-// @description A modern deployment platform with DDD architecture
-// @termsOfService http://swagger.io/terms/
-
-// @contact.name SnapDeploy Team
-// @contact.email support@snapdeploy.com
-
-// @license.name MIT
-// @license.url https://opensource.org/licenses/MIT
-
-// @host localhost:8080
-// @BasePath /api/v1
-
-// @securityDefinitions.apikey ClerkAuth
-// @in header
-// @name Authorization
-// @description Clerk JWT token
-
 func main() {
 	// Load configuration
 	cfg, err := config.Load()
@@ -55,6 +62,15 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Structured, leveled logs; the standard log package writes through the same logger
+	logging.Setup(cfg.Logging.Level, cfg.Logging.Format)
+
+	// Spans of HTTP requests, queries, AWS calls, builds and deploys are exported over OTLP
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.OTLPEndpoint, cfg.Tracing.ServiceName, cfg.Tracing.SampleRatio)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+
 	// Initialize database
 	db, err := database.NewConnection(&cfg.Database)
 	if err != nil {
@@ -66,17 +82,51 @@ func main() {
 	// External service clients
 	clerkClient := clerk.NewClient(&cfg.Clerk)
 	githubClient := github.NewClient()
+	// Cache GitHub responses so repeated syncs and listings are revalidated instead of spending users' rate limits
+	if cfg.GitHub.CacheRedisURL != "" {
+		githubCache, err := github.NewRedisCache(cfg.GitHub.CacheRedisURL, time.Duration(cfg.GitHub.CacheTTLHours)*time.Hour)
+		if err != nil {
+			log.Fatalf("Failed to initialize GitHub response cache: %v", err)
+		}
+		defer githubCache.Close()
+		githubClient.SetCache(githubCache)
+	} else {
+		githubClient.SetCache(github.NewMemoryCache(cfg.GitHub.CacheMaxEntries))
+	}
 
 	// Infrastructure implementations of domain services
 	clerkService := infraClerk.NewClerkService(clerkClient)
-	githubService := infraGitHub.NewGitHubService(githubClient)
+	// Git providers users can sync and deploy repositories from
+	gitProviders := []repo.GitProvider{
+		infraGitHub.NewGitHubProvider(githubClient),
+		infraGitLab.NewGitLabProvider(gitlab.NewClient()),
+		infraBitbucket.NewBitbucketProvider(bitbucket.NewClient()),
+	}
 
 	// Initialize encryption service
-	encryptionService, err := encryption.NewEncryptionService()
+	dataKeyTTL := time.Duration(cfg.Encryption.KMSDataKeyTTLMinutes) * time.Minute
+	var encryptionService *encryption.EncryptionService
+	if cfg.Encryption.Key != "" {
+		encryptionService, err = encryption.NewEncryptionService(cfg.Encryption.Key)
+		if err == nil && cfg.Encryption.KMSKeyID != "" {
+			err = encryptionService.AddKMSKey(context.Background(), cfg.Encryption.KMSKeyID, dataKeyTTL)
+		}
+	} else {
+		// No static key is held, everything is encrypted with data keys of the KMS key
+		encryptionService, err = encryption.NewKMSEncryptionService(context.Background(), cfg.Encryption.KMSKeyID, cfg.Encryption.KMSDigestKey, dataKeyTTL)
+	}
 	if err != nil {
 		log.Fatalf("Failed to initialize encryption service: %v", err)
 	}
-	log.Printf("Encryption service initialized")
+	for id, key := range cfg.Encryption.Keys {
+		if err := encryptionService.AddKey(id, key); err != nil {
+			log.Fatalf("Failed to initialize encryption service: %v", err)
+		}
+	}
+	if err := encryptionService.SetActiveKey(cfg.Encryption.ActiveKey()); err != nil {
+		log.Fatalf("Failed to initialize encryption service: %v", err)
+	}
+	slog.Info("Encryption service initialized", "active_key_id", encryptionService.ActiveKeyID())
 
 	// Repository implementations
 	userRepository := persistence.NewUserRepository(db)
@@ -84,18 +134,167 @@ func main() {
 	projectRepository := persistence.NewProjectRepository(db)
 	deploymentRepository := persistence.NewDeploymentRepository(db)
 	envVarRepository := persistence.NewEnvVarRepository(db, encryptionService)
+	incidentRepository := persistence.NewIncidentRepository(db)
+	usageRepository := persistence.NewUsageRepository(db)
+	quotaRepository := persistence.NewQuotaRepository(db)
+	timelineRepository := persistence.NewTimelineRepository(db)
+	installationRepository := persistence.NewInstallationRepository(db)
+	buildJobRepository := persistence.NewBuildJobRepository(db)
+	idempotencyRepository := persistence.NewIdempotencyRepository(db)
+	snapshotRepository := persistence.NewSnapshotRepository(db)
+	branchRepository := persistence.NewBranchRepository(db)
+	cronRunRepository := persistence.NewCronRunRepository(db)
+	commandRunRepository := persistence.NewCommandRunRepository(db)
+	shellSessionRepository := persistence.NewShellSessionRepository(db)
+	approvalRepository := persistence.NewApprovalRepository(db)
+	manifestRepository := persistence.NewManifestRepository(db)
+	scanRepository := persistence.NewScanRepository(db)
+	stepRepository := persistence.NewStepRepository(db)
+	envChangeRepository := persistence.NewEnvChangeRepository(db)
+	healthCheckRepository := persistence.NewHealthCheckRepository(db)
+	projectIncidentRepository := persistence.NewProjectIncidentRepository(db)
+	billingAccountRepository := persistence.NewBillingAccountRepository(db)
+	agentTokenRepository := persistence.NewAgentTokenRepository(db)
+
+	// Multi-step writes share a transaction across repositories
+	unitOfWork := persistence.NewUnitOfWork(db)
+
+	// Write deployment logs in batches rather than a row update per line
+	var deploymentLogs *persistence.BufferedDeploymentRepository
+	if cfg.Logs.FlushIntervalMs > 0 {
+		deploymentLogs = persistence.NewBufferedDeploymentRepository(deploymentRepository, db, cfg.Logs.FlushLines, time.Duration(cfg.Logs.FlushIntervalMs)*time.Millisecond)
+		deploymentRepository = deploymentLogs
+	}
+
+	// Publish deployment events (status changes) once they are saved
+	eventDispatcher := events.NewDispatcher()
+	deploymentRepository = persistence.NewEventPublishingDeploymentRepository(deploymentRepository, eventDispatcher)
 
 	// Initialize application layer
 	// Application services (use cases)
 	userService := service.NewUserService(userRepository, repositoryRepository, clerkService)
-	repositoryService := service.NewRepositoryService(repositoryRepository, githubService)
-	projectService := service.NewProjectService(projectRepository)
-	deploymentService := service.NewDeploymentService(deploymentRepository, projectRepository)
-	envVarService := service.NewEnvVarService(envVarRepository, projectRepository, encryptionService)
+	repositoryService := service.NewRepositoryService(repositoryRepository, gitProviders...)
+	repositoryService.SetUnitOfWork(unitOfWork)
+	projectService := service.NewProjectService(projectRepository, envVarRepository, unitOfWork)
+	deploymentService := service.NewDeploymentService(deploymentRepository, projectRepository, buildJobRepository, approvalRepository, unitOfWork)
+	deploymentService.SetStepRepository(stepRepository)
+	envVarService := service.NewEnvVarService(envVarRepository, projectRepository, deploymentRepository, envChangeRepository, encryptionService)
+	envVarService.SetRedeployer(deploymentService)
+	// Duplicated projects get copies of their source's environment variables and can deploy what it runs
+	projectService.SetDuplication(envVarService, deploymentService)
+	// Deleting a user tears down their projects first
+	userService.SetProjectRemover(projectService)
+	userExportService := service.NewUserExportService(userService, repositoryService, installationRepository, projectService, deploymentService, envVarService)
+	// Plan limits are checked as projects, environment variables and deployments are created
+	quotaService := service.NewQuotaService(quotaRepository, quotaPlans(cfg.Quotas), userRepository, projectRepository, deploymentRepository, envVarRepository, usageRepository)
+	projectService.SetProjectQuota(quotaService)
+	projectService.SetBaseDomain(cfg.AWS.DNS.BaseDomain)
+	projectService.SetDatabaseServer(service.DatabaseServer{
+		Host:     cfg.Datastores.Postgres.Host,
+		Port:     cfg.Datastores.Postgres.Port,
+		User:     cfg.Datastores.Postgres.User,
+		Password: cfg.Datastores.Postgres.Password,
+	})
+	envVarService.SetEnvVarQuota(quotaService)
+	// Users are billed through Stripe, their subscription decides their plan
+	billingService := service.NewBillingService(billingAccountRepository, userRepository, projectRepository, usageRepository, quotaService, cfg.Billing.PricePlans)
+	if cfg.Billing.Enabled() {
+		billingService.SetBillingProvider(infraStripe.NewBillingProvider(stripe.NewClient(&cfg.Billing), cfg.Billing.BuildMinutesMeter, cfg.Billing.FargateHoursMeter, cfg.Billing.PortalReturnURL))
+		slog.Info("Stripe billing enabled")
+	}
+	// Projects can be created from a catalog of sample apps, copying them into the user's GitHub account
+	templateService := service.NewTemplateService(persistence.NewTemplateRepository(db), projectService, envVarService, unitOfWork)
+	templateService.SetRepositoryCopier(infraGitHub.NewTemplateCopier(githubClient))
+	systemStatusService := service.NewSystemStatusService(incidentRepository)
+	keyRotationService := service.NewKeyRotationService(persistence.NewEnvVarReencrypter(db, encryptionService))
+	usageService := service.NewUsageService(usageRepository, deploymentRepository, projectRepository, service.UsagePricing{
+		PerVCPUHour:    cfg.Usage.PricePerVCPUHour,
+		PerGBHour:      cfg.Usage.PricePerGBHour,
+		PerBuildMinute: cfg.Usage.PricePerBuildMinute,
+		PerEgressGB:    cfg.Usage.PricePerEgressGB,
+	})
+	jobService := service.NewJobService(service.JobLimits{
+		MaxConcurrentPerUser: cfg.Jobs.MaxConcurrentPerUser,
+		MaxQueuedPerUser:     cfg.Jobs.MaxQueuedPerUser,
+		Timeout:              time.Duration(cfg.Jobs.TimeoutSeconds) * time.Second,
+	})
+	metricsService := service.NewMetricsService(projectRepository)
+	agentTokenService := service.NewAgentTokenService(agentTokenRepository)
+	databaseService := service.NewDatabaseService(projectRepository, deploymentRepository)
+	databaseBranchService := service.NewDatabaseBranchService(projectRepository, snapshotRepository, branchRepository)
+	authorizationService := service.NewAuthorizationService(userRepository, projectRepository, deploymentRepository)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepository, time.Duration(cfg.Idempotency.KeyTTLHours)*time.Hour)
+	retentionService := service.NewRetentionService(projectRepository, deploymentRepository)
+	cronRunService := service.NewCronRunService(projectRepository, deploymentRepository, cronRunRepository)
+	commandService := service.NewCommandService(projectRepository, deploymentRepository, commandRunRepository)
+	commandService.SetLogBroadcaster(handlers.GetSSEManager())
+	shellService := service.NewShellService(projectRepository, shellSessionRepository, service.ShellLimits{
+		MaxDuration: time.Duration(cfg.Shell.MaxSessionMinutes) * time.Minute,
+		IdleTimeout: time.Duration(cfg.Shell.IdleTimeoutMinutes) * time.Minute,
+	})
+	deploymentCompareService := service.NewDeploymentCompareService(deploymentRepository, projectRepository, manifestRepository)
+	monitoringService := service.NewMonitoringService(projectRepository, deploymentRepository, healthCheckRepository, projectIncidentRepository,
+		uptime.NewHTTPProber(time.Duration(cfg.Uptime.CheckTimeoutSeconds)*time.Second), service.MonitoringSettings{
+			FailureThreshold: cfg.Uptime.FailureThreshold,
+			HistoryDays:      cfg.Uptime.HistoryDays,
+			ProbeTimeout:     time.Duration(cfg.Uptime.CheckTimeoutSeconds) * time.Second,
+			BaseDomain:       cfg.AWS.DNS.BaseDomain,
+		})
+	// Announce incidents on the operators' alert webhook too
+	if cfg.System.AlertWebhookURL != "" {
+		monitoringService.AddAlerter(alerts.NewWebhookAlerter(cfg.System.AlertWebhookURL))
+	}
+	statusPageService := service.NewStatusPageService(projectRepository, deploymentRepository)
+	// Status pages show the uptime measured by health checks
+	statusPageService.SetUptimeSource(monitoringService)
+	statusPageService.SetBaseDomain(cfg.AWS.DNS.BaseDomain)
+
+	// Sync and clone repositories through GitHub App installations (optional)
+	githubInstallationService := service.NewGitHubInstallationService(installationRepository, clerkClient)
+	if cfg.GitHub.AppEnabled() {
+		githubApp, err := github.NewApp(githubClient, cfg.GitHub.AppID, cfg.GitHub.AppSlug, []byte(cfg.GitHub.AppPrivateKey))
+		if err != nil {
+			log.Fatalf("Failed to initialize GitHub App: %v", err)
+		}
+		githubInstallationService.SetGitHubApp(infraGitHub.NewGitHubAppService(githubApp))
+		repositoryService.SetInstallationRepositorySource(githubInstallationService)
+		slog.Info("GitHub App initialized", "app_slug", cfg.GitHub.AppSlug)
+	}
+
+	// Record deployment status changes and migration results on deployment timelines
+	timelineService := service.NewTimelineService(deploymentRepository, projectRepository, timelineRepository)
+	timelineService.RegisterHandlers(eventDispatcher)
+
+	// Record the deployments picking up environment variable changes
+	envVarService.RegisterHandlers(eventDispatcher)
+
+	// Report deployment status to GitHub commits and the Deployments API
+	githubStatusService := service.NewGitHubStatusService(deploymentRepository, projectRepository, userRepository, clerkClient, githubClient)
+	githubStatusService.SetBaseDomain(cfg.AWS.DNS.BaseDomain)
+	if cfg.GitHub.StatusReportingEnabled {
+		githubStatusService.RegisterHandlers(eventDispatcher)
+	}
 
 	// Initialize presentation layer
 	// HTTP handlers
-	healthHandler := handlers.NewHealthHandler()
+	// Readiness fails while a dependency deployments need is down
+	healthChecks := []handlers.HealthCheck{{Name: "postgres", Check: db.PingContext}}
+	if cfg.Builds.Backend == builder.BackendDocker {
+		healthChecks = append(healthChecks, handlers.HealthCheck{Name: "docker", Check: builder.PingDocker})
+	}
+	if stsClient, err := sts.NewSTSClient(); err != nil {
+		slog.Warn("Could not initialize STS client, readiness won't check AWS credentials", "error", err)
+	} else {
+		healthChecks = append(healthChecks, handlers.HealthCheck{Name: "aws", Check: stsClient.CheckCredentials})
+	}
+	healthHandler := handlers.NewHealthHandler(healthChecks...)
+
+	// Requests are checked against the OpenAPI document, which is also served to clients
+	apiDocument, err := openapi.Load(api.OpenAPI)
+	if err != nil {
+		log.Fatalf("Failed to load OpenAPI document: %v", err)
+	}
+	openAPIHandler := handlers.NewOpenAPIHandler(apiDocument)
 	
 	// Initialize template generator for Dockerfile generation
 	templateGenerator, err := builder.NewTemplateGenerator()
@@ -103,46 +302,231 @@ func main() {
 		log.Fatalf("Failed to initialize template generator: %v", err)
 	}
 
-	// Initialize CodeBuild service (required)
-	codebuildProjectName := os.Getenv("CODEBUILD_PROJECT_NAME")
-	if codebuildProjectName == "" {
-		log.Fatalf("CODEBUILD_PROJECT_NAME environment variable is required")
+	// Clone private repositories with GitHub App installation tokens or the owner's GitLab/Bitbucket token
+	gitCloneService := service.NewGitCloneService(userRepository, clerkClient, gitProviders...)
+	gitCloneService.SetCloneTokenSource(githubInstallationService)
+	// Deployment comparisons read the commits between two deployments with the same tokens
+	deploymentCompareService.SetCommitComparisonSource(gitCloneService)
+
+	// Initialize ECS deployment orchestrator (optional - only if deploying to ECS)
+	var deploymentCallback builder.DeploymentCallback
+	var infrastructurePlanner service.InfrastructurePlanner
+	var domainRecords service.DomainRecordChecker
+	var ecsOrchestrator *ecs.DeploymentOrchestrator
+	var ecsEvents *ecsevents.Consumer
+	if !cfg.AWS.DeploysEnabled() {
+		slog.Warn("ECS deployment settings are not set, deployments will only build images")
+	} else if ecsOrchestrator, err = ecs.NewDeploymentOrchestrator(&cfg.AWS, &cfg.Datastores, deploymentRepository, envVarRepository); err != nil {
+		slog.Warn("ECS deployment orchestrator not initialized, deployments will only build images", "error", err)
+	} else {
+		// Deploy images once they are built
+		deploymentCallback = ecs.NewDeploymentCallbackAdapter(ecsOrchestrator)
+		// Tear down cloud resources when projects are deleted
+		projectService.SetInfrastructureTeardown(ecsOrchestrator)
+		// Restart running services without rebuilding
+		deploymentService.SetServiceRestarter(ecsOrchestrator)
+		// Retry failed deployments with the image they already pushed, recording the steps deployments go through
+		deploymentService.SetDeploymentResumer(ecsOrchestrator)
+		ecsOrchestrator.SetStepRepository(stepRepository)
+		// Report runtime metrics of deployed services
+		metricsService.SetMetricsSource(ecsOrchestrator)
+		// Meter the bytes deployed services send
+		usageService.SetEgressSource(ecsOrchestrator)
+		// Manage the Postgres databases of projects that require one
+		if dbManager := ecsOrchestrator.DatabaseManager(); dbManager != nil {
+			databaseService.SetDatabaseManager(dbManager)
+			databaseBranchService.SetDatabaseCopier(dbManager)
+		}
+		// Add ECS and load balancer events to deployment timelines
+		timelineService.SetTimelineEventSource(ecsOrchestrator)
+		// Record the runs of cron jobs and read their logs
+		cronRunService.SetScheduledTaskSource(ecsOrchestrator)
+		// Run one-off commands in the containers of projects
+		commandService.SetCommandRunner(ecsOrchestrator)
+		// Open interactive shells in the running tasks of projects
+		shellService.SetShellBroker(ecsOrchestrator)
+		// Stop the services of users whose payment failed
+		billingService.SetServiceSuspender(ecsOrchestrator)
+		// Alert operators when deployments hit AWS quotas
+		ecsOrchestrator.SetAdminAlerter(alerts.NewWebhookAlerter(cfg.System.AlertWebhookURL))
+		// Never interleave changes to a project's target groups, DNS records and services, even across instances
+		ecsOrchestrator.SetProjectLocker(persistence.NewProjectLocker(db))
+		// Allocate listener rule priorities from a table every instance shares, reusing those of deleted rules
+		ecsOrchestrator.SetPriorityStore(persistence.NewListenerPriorityStore(db))
+		// Give each project an account of its own on the MySQL server, its password encrypted at rest
+		ecsOrchestrator.SetDatastoreCredentials(persistence.NewDatastoreCredentialStore(db, encryptionService))
+		// Follow rollouts with the service events EventBridge queues rather than polling the services
+		if queueURL := cfg.AWS.ECS.EventsQueueURL; queueURL != "" {
+			if ecsEvents, err = ecsevents.NewConsumer(queueURL, cfg.AWS.ECS.ClusterName); err != nil {
+				slog.Warn("ECS event consumer not initialized, rollouts will be polled", "error", err)
+			} else {
+				ecsOrchestrator.SetServiceEvents(ecsEvents)
+			}
+		}
+		// Work out the cloud changes of dry-run deployments
+		infrastructurePlanner = ecsOrchestrator
+		// Check custom domains against existing DNS records when validating projects
+		domainRecords = ecsOrchestrator
+		slog.Info("ECS deployment orchestrator initialized")
 	}
+	projectService.SetValidationSources(gitCloneService, domainRecords)
 
-	codebuildService, err := codebuild.NewCodeBuildService(
-		codebuildProjectName,
-		deploymentRepository,
-		projectRepository,
-	)
-	if err != nil {
-		log.Fatalf("Failed to initialize CodeBuild service: %v", err)
+	// Images are pushed to ECR when DOCKER_REGISTRY points at it
+	var ecrClient *ecr.ECRClient
+	if ecr.IsECRRegistry(cfg.AWS.DockerRegistry) {
+		if ecrClient, err = ecr.NewECRClient(cfg.AWS.DockerRegistry, cfg.AWS.ECS.ExecutionRoleARN); err != nil {
+			slog.Warn("ECR client not initialized", "error", err)
+		}
 	}
-	log.Printf("CodeBuild service initialized with project: %s", codebuildProjectName)
 
-	// Initialize ECS deployment orchestrator (optional - only if deploying to ECS)
-	ecsOrchestrator, err := ecs.NewDeploymentOrchestrator(deploymentRepository, envVarRepository)
-	if err != nil {
-		log.Printf("Warning: ECS deployment orchestrator not initialized: %v", err)
-		log.Printf("Deployments will only build images without deploying to ECS")
-	} else {
-		// Set up the deployment callback
-		deploymentCallback := ecs.NewDeploymentCallbackAdapter(ecsOrchestrator)
-		codebuildService.SetDeploymentCallback(deploymentCallback)
-		log.Printf("ECS deployment orchestrator initialized successfully")
+	// Scan built images and generate their SBOM before they are deployed
+	imageScanService := service.NewImageScanService(scanRepository, deploymentRepository, service.ScanPolicy{
+		Timeout:     time.Duration(cfg.Scans.TimeoutMinutes) * time.Minute,
+		MaxCritical: cfg.Scans.MaxCritical,
+	})
+	if cfg.Scans.Enabled && deploymentCallback != nil {
+		if ecrClient != nil {
+			imageScanService.SetVulnerabilityScanner(ecrClient)
+		}
+		if syft, err := sbom.NewSyftGenerator(cfg.Scans.SyftPath); err != nil {
+			slog.Warn("SBOMs will not be generated for images", "error", err)
+		} else {
+			if ecrClient != nil {
+				syft.SetRegistryCredentials(ecrClient)
+			}
+			imageScanService.SetSBOMGenerator(syft)
+		}
+		if imageScanService.Enabled() {
+			deploymentCallback = imageScanService.ScanBeforeDeploying(deploymentCallback)
+			slog.Info("Image scanning enabled", "max_critical", cfg.Scans.MaxCritical)
+		}
+	}
+	if deploymentCallback != nil {
+		// The clone, build and push steps are complete once the image is handed over
+		deploymentCallback = deploymentService.RecordBuildSteps(deploymentCallback)
 	}
 
-	userHandler := handlers.NewUserHandler(userService)
-	repositoryHandler := handlers.NewRepositoryHandler(repositoryService, clerkClient)
-	projectHandler := handlers.NewProjectHandler(projectService, userService)
+	// Build images with CodeBuild, with the local Docker daemon when BUILD_BACKEND=docker,
+	// or on self-hosted build agents when BUILD_BACKEND=agent
+	var buildBackend builder.BuildBackend
+	var buildEvents *codebuild.EventConsumer
+	var agentBackend *builder.AgentBackend
+	var buildWorkspace *builder.Workspace
+	switch cfg.Builds.Backend {
+	case builder.BackendDocker:
+		buildWorkspace = builder.NewWorkspace(cfg.Builds.WorkDir, int64(cfg.Builds.MaxBuildDiskMB)<<20, int64(cfg.Builds.MinFreeDiskMB)<<20)
+		buildWorkspace.SetCloneCache(cfg.Builds.CloneCache)
+		dockerBuilder := builder.NewBuilderService(buildWorkspace, deploymentRepository, projectRepository)
+		dockerBuilder.SetSSEManager(handlers.GetSSEManager())
+		dockerBuilder.SetUsageRecorder(usageService)
+		dockerBuilder.SetCloneCredentialsProvider(gitCloneService)
+		if deploymentCallback != nil {
+			dockerBuilder.SetDeploymentCallback(deploymentCallback)
+		}
+		buildBackend = dockerBuilder
+		slog.Info("Local Docker builder initialized", "work_dir", cfg.Builds.WorkDir)
+	case builder.BackendAgent:
+		agentBackend = builder.NewAgentBackend(deploymentRepository, projectRepository)
+		agentBackend.SetSSEManager(handlers.GetSSEManager())
+		agentBackend.SetCloneCredentialsProvider(gitCloneService)
+		if deploymentCallback != nil {
+			agentBackend.SetDeploymentCallback(deploymentCallback)
+		}
+		buildBackend = agentBackend
+		slog.Info("Builds dispatched to self-hosted build agents")
+	default:
+		codebuildService, err := codebuild.NewCodeBuildService(
+			&cfg.AWS,
+			deploymentRepository,
+			projectRepository,
+		)
+		if err != nil {
+			log.Fatalf("Failed to initialize CodeBuild service: %v", err)
+		}
+		// Stream build logs to SSE clients and meter build minutes for usage reporting
+		codebuildService.SetSSEManager(handlers.GetSSEManager())
+		codebuildService.SetUsageRecorder(usageService)
+		codebuildService.SetCloneCredentialsProvider(gitCloneService)
+		// Follow builds with the events EventBridge queues rather than polling their status
+		if queueURL := cfg.AWS.CodeBuildQueue; queueURL != "" {
+			if buildEvents, err = codebuild.NewEventConsumer(queueURL, cfg.AWS.CodeBuildProject); err != nil {
+				slog.Warn("CodeBuild event consumer not initialized, build statuses will be polled", "error", err)
+			} else {
+				codebuildService.SetBuildEvents(buildEvents)
+			}
+		}
+		if deploymentCallback != nil {
+			codebuildService.SetDeploymentCallback(deploymentCallback)
+		}
+		buildBackend = codebuildService
+		slog.Info("CodeBuild service initialized", "project", cfg.AWS.CodeBuildProject)
+	}
+
+	userHandler := handlers.NewUserHandler(userService, userExportService, jobService)
+	repositoryHandler := handlers.NewRepositoryHandler(repositoryService, jobService, userService, clerkClient)
+	projectHandler := handlers.NewProjectHandler(projectService, userService, jobService, cfg.System.OperatorIDs)
 	envVarHandler := handlers.NewEnvVarHandler(envVarService, userService)
-	deploymentHandler := handlers.NewDeploymentHandler(
-		deploymentService, 
-		userService, 
-		codebuildService, 
-		templateGenerator,
-		projectRepository, 
-		deploymentRepository,
-	)
+	templateHandler := handlers.NewTemplateHandler(templateService, userService, clerkClient)
+	systemHandler := handlers.NewSystemHandler(systemStatusService)
+	encryptionHandler := handlers.NewEncryptionHandler(keyRotationService)
+	quotaHandler := handlers.NewQuotaHandler(quotaService)
+	billingHandler := handlers.NewBillingHandler(billingService, userService, cfg.Billing.StripeWebhookSecret)
+	usageHandler := handlers.NewUsageHandler(usageService)
+	jobHandler := handlers.NewJobHandler(jobService)
+	metricsHandler := handlers.NewMetricsHandler(metricsService)
+	databaseHandler := handlers.NewDatabaseHandler(databaseService, databaseBranchService)
+	timelineHandler := handlers.NewTimelineHandler(timelineService)
+	cronRunHandler := handlers.NewCronRunHandler(cronRunService)
+	commandHandler := handlers.NewCommandHandler(commandService, userService)
+	agentTokenHandler := handlers.NewAgentTokenHandler(agentTokenService, userService)
+	shellHandler := handlers.NewShellHandler(shellService, userService, cfg.CORS.AllowedOrigins)
+	githubAppHandler := handlers.NewGitHubAppHandler(githubInstallationService, cfg.GitHub.AppWebhookSecret)
+	githubAppHandler.SetPushDeployService(service.NewPushDeployService(projectRepository, installationRepository, deploymentService))
+	deploymentCompareHandler := handlers.NewDeploymentCompareHandler(deploymentCompareService)
+	statusPageHandler := handlers.NewStatusPageHandler(statusPageService)
+	monitoringHandler := handlers.NewMonitoringHandler(monitoringService)
+	badgeHandler := handlers.NewBadgeHandler(service.NewBadgeService(deploymentRepository, projectRepository, encryptionService))
+	scanHandler := handlers.NewScanHandler(imageScanService)
+	deploymentHandler := handlers.NewDeploymentHandler(deploymentService, userService, cfg.Builds.RetryAfterSeconds, cfg.System.OperatorIDs)
+
+	// Start the builds queued with new deployments on a bounded worker pool
+	buildService := service.NewBuildService(buildJobRepository, deploymentRepository, projectRepository, buildBackend, templateGenerator, service.BuildLimits{
+		Workers:              cfg.Builds.Workers,
+		MaxConcurrentPerUser: cfg.Builds.MaxConcurrentPerUser,
+		MaxQueuedPerUser:     cfg.Builds.MaxQueuedPerUser,
+		MaxQueued:            cfg.Builds.MaxQueued,
+	})
+	deploymentService.SetBuildAdmission(buildService)
+	buildService.SetBuildQuota(quotaService)
+	buildService.SetBuildBilling(billingService)
+	buildService.SetDockerRegistry(cfg.AWS.DockerRegistry)
+	// Build-scoped environment variables are passed to builds as Docker build args
+	if source, ok := envVarRepository.(service.BuildVariableSource); ok {
+		buildService.SetBuildVariableSource(source)
+	}
+	// Environment variable values printed by builds are masked before logs are stored or streamed
+	if secrets, ok := envVarRepository.(service.LogSecretSource); ok {
+		buildService.SetLogSecretSource(secrets)
+		deploymentService.SetLogSecretSource(secrets)
+		commandService.SetLogSecretSource(secrets)
+	}
+	// Deployments apply the snapdeploy.yaml of the commit they build
+	buildService.SetRepositoryConfigSource(gitCloneService, envVarRepository)
+	// Record what each deployment is built with, so deployments can be compared
+	if digests, ok := envVarRepository.(service.EnvVarDigestSource); ok {
+		buildService.SetManifestRecorder(manifestRepository, digests)
+	}
+
+	// Provision one ECR repository per project when pushing to ECR, and clean up images deployments no longer use
+	var imageCleanupService *service.ImageCleanupService
+	if ecrClient != nil {
+		buildService.SetImageRepositoryManager(ecrClient)
+		imageCleanupService = service.NewImageCleanupService(projectRepository, deploymentRepository, ecrClient,
+			cfg.Images.RetainDeployments, cfg.Images.CleanupDryRun)
+	}
+
+	// Dry-run deployments are planned the way builds and deployments would go
+	deploymentService.SetDeploymentPlanners(buildService, infrastructurePlanner)
 
 	// Initialize auth middleware
 	authMiddleware, err := middleware.NewAuthMiddleware(cfg)
@@ -150,6 +534,17 @@ func main() {
 		log.Fatalf("Failed to initialize auth middleware: %v", err)
 	}
 
+	// Pick up Clerk signing key rotations without a restart
+	keyRefreshCtx, stopKeyRefresh := context.WithCancel(context.Background())
+	defer stopKeyRefresh()
+	go authMiddleware.RunKeyRefresh(keyRefreshCtx, time.Duration(cfg.Clerk.JWKSRefreshMinutes)*time.Minute)
+
+	// Expensive routes are rate limited per user
+	rateLimitStore := ratelimit.NewMemoryStore()
+	rateLimit := func(route string, limit config.RouteRateLimit) gin.HandlerFunc {
+		return middleware.RateLimit(rateLimitStore, route, ratelimit.Limit{PerMinute: limit.PerMinute, Burst: limit.Burst})
+	}
+
 	// Set Gin mode
 	if os.Getenv("GIN_MODE") == "" {
 		gin.SetMode(gin.ReleaseMode)
@@ -159,35 +554,68 @@ func main() {
 	router := gin.New()
 
 	// Add middleware
-	router.Use(gin.Logger())
+	router.Use(middleware.RequestID())
+	router.Use(otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return !strings.HasPrefix(r.URL.Path, "/api/v1/health") // Load balancer health checks would drown out real traffic
+	})))
+	router.Use(middleware.RequestLogger())
 	router.Use(gin.Recovery())
-
-	// CORS middleware
-	router.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
-
-		// Handle preflight OPTIONS requests
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-
-		c.Next()
-	})
+	// Logs failed requests with their cause and responds to errors no handler did
+	router.Use(middleware.Errors())
+	// Bound request bodies before any middleware reads them; routes taking logs, variables or webhooks have their own limits
+	router.Use(middleware.BodyLimit(int64(cfg.Server.MaxBodyBytes), map[string]int64{
+		"/api/v1/deployments/:id/logs": int64(cfg.Server.MaxLogBodyBytes),
+		"/api/v1/projects/:id/env":     int64(cfg.Server.MaxEnvVarBodyBytes),
+		"/api/v1/github/webhooks":      int64(cfg.Server.MaxWebhookBodyBytes),
+		"/api/v1/billing/webhooks":     int64(cfg.Server.MaxWebhookBodyBytes),
+	}))
+
+	// Browsers may only call the API from the configured origins, e.g. the dashboard
+	router.Use(middleware.CORS(cfg.CORS))
 
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
-		// Debug logging middleware
-		v1.Use(func(c *gin.Context) {
-			log.Printf("[ROUTE DEBUG] %s %s", c.Request.Method, c.Request.URL.Path)
-			c.Next()
-		})
+		// Attach the active incident banner to every API response
+		v1.Use(middleware.SystemBanner(systemStatusService))
+		// Request bodies must be declared as JSON, webhooks' included, as handlers decode them as JSON
+		v1.Use(middleware.RequireJSON())
+		v1.Use(middleware.OpenAPIValidation(apiDocument, "/api/v1", cfg.OpenAPI.RequestValidation, cfg.OpenAPI.ValidateResponses))
 
 		// Health check endpoint (no auth required)
 		v1.GET("/health", healthHandler.Health)
+		v1.GET("/health/live", healthHandler.Live)
+		v1.GET("/health/ready", healthHandler.Ready)
+
+		// System status routes
+		system := v1.Group("/system")
+		{
+			// Status page is public so it stays reachable during auth incidents
+			system.GET("/status", systemHandler.GetStatus)
+
+			incidents := system.Group("/incidents")
+			incidents.Use(authMiddleware.RequireAuth(), middleware.RequireOperator(cfg.System.OperatorIDs))
+			{
+				incidents.POST("", systemHandler.CreateIncident)
+				incidents.PUT("/:id", systemHandler.UpdateIncident)
+				incidents.POST("/:id/resolve", systemHandler.ResolveIncident)
+			}
+		}
+
+		// Platform administration routes
+		admin := v1.Group("/admin")
+		admin.Use(authMiddleware.RequireAuth(), middleware.RequireOperator(cfg.System.OperatorIDs))
+		{
+			admin.GET("/encryption", encryptionHandler.GetStatus)
+			admin.POST("/encryption/rotate", encryptionHandler.Rotate)
+			admin.PUT("/users/:id/limits", quotaHandler.UpdateUserLimits)
+		}
+
+		// GitHub App webhooks are authenticated by their signature, not a user session
+		v1.POST("/github/webhooks", githubAppHandler.HandleWebhook)
+		// Stripe webhooks are authenticated by their signature as well
+		v1.POST("/billing/webhooks", billingHandler.HandleWebhook)
+		v1.GET("/billing/portal", authMiddleware.RequireAuth(), billingHandler.GetPortal)
 
 		// Auth routes
 		auth := v1.Group("/auth")
@@ -198,23 +626,95 @@ func main() {
 
 		// User routes
 		users := v1.Group("/users")
-		users.Use(authMiddleware.RequireAuth())
+		users.Use(authMiddleware.RequireAuth(), middleware.RequireUserAccess(authorizationService, cfg.System.OperatorIDs))
 		{
+			users.DELETE("/:id", userHandler.DeleteUser)
+			users.GET("/:id/export", userHandler.ExportUserData)
+			users.GET("/:id/limits", quotaHandler.GetUserLimits)
 			users.GET("/:id/repos", repositoryHandler.GetUserRepositories)
-			users.POST("/:id/repos/sync", repositoryHandler.SyncRepositories)
+			users.POST("/:id/repos/sync", rateLimit("sync_repositories", cfg.RateLimits.SyncRepositories), repositoryHandler.SyncRepositories)
 			users.GET("/:id/projects", projectHandler.GetUserProjects)
-			users.POST("/:id/projects", projectHandler.CreateProject)
+			users.POST("/:id/projects", middleware.Idempotency(idempotencyService, "create_project"), rateLimit("create_project", cfg.RateLimits.CreateProject), projectHandler.CreateProject)
+			users.GET("/:id/usage", usageHandler.GetUserUsage)
+			users.GET("/:id/github/installations", githubAppHandler.GetUserInstallations)
+			users.POST("/:id/github/installations", githubAppHandler.ClaimInstallation)
+		}
+
+		// Repository routes
+		repos := v1.Group("/repos")
+		repos.Use(authMiddleware.RequireAuth())
+		{
+			repos.GET("/:id/branches", repositoryHandler.GetRepositoryBranches)
+			repos.GET("/:id/commits", repositoryHandler.GetRepositoryCommits)
+		}
+
+		// Status badges are embedded in READMEs, their signed token stands in for a user session
+		v1.GET("/badges/projects/:id/status.svg", badgeHandler.GetStatusBadge)
+
+		// Shell sessions are connected to by terminals, their one-time token stands in for a user session
+		v1.GET("/shell/sessions/:id/connect", shellHandler.ConnectShell)
+
+		// Status pages are shared with a project's users, who don't have an account
+		v1.GET("/public/projects/:slug/status", statusPageHandler.GetStatusPage)
+
+		// Validating a configuration before the project is created doesn't target a project
+		v1.POST("/projects/validate", authMiddleware.RequireAuth(), projectHandler.ValidateProject)
+
+		// Creating a project from a template doesn't target an existing project either
+		v1.POST("/projects/from-template/:template_id", authMiddleware.RequireAuth(), middleware.Idempotency(idempotencyService, "create_project_from_template"), rateLimit("create_project", cfg.RateLimits.CreateProject), templateHandler.CreateProjectFromTemplate)
+
+		// Template catalog
+		templates := v1.Group("/templates")
+		templates.Use(authMiddleware.RequireAuth())
+		{
+			templates.GET("", templateHandler.ListTemplates)
+			templates.GET("/:template_id", templateHandler.GetTemplate)
+		}
+
+		// Custom domain availability, checked before a project claims one
+		domains := v1.Group("/domains")
+		domains.Use(authMiddleware.RequireAuth())
+		{
+			domains.GET("/check", projectHandler.CheckDomain)
 		}
 
 		// Project routes
 		projects := v1.Group("/projects")
-		projects.Use(authMiddleware.RequireAuth())
+		projects.Use(authMiddleware.RequireAuth(), middleware.RequireProjectAccess(authorizationService, cfg.System.OperatorIDs))
 		{
 			projects.GET("/:id", projectHandler.GetProject)
 			projects.PUT("/:id", projectHandler.UpdateProject)
 			projects.DELETE("/:id", projectHandler.DeleteProject)
+			projects.POST("/:id/duplicate", middleware.Idempotency(idempotencyService, "duplicate_project"), rateLimit("create_project", cfg.RateLimits.CreateProject), projectHandler.DuplicateProject)
+			projects.GET("/:id/deploy-rules", projectHandler.GetDeployRules)
+			projects.PUT("/:id/deploy-rules", projectHandler.UpdateDeployRules)
+			projects.GET("/:id/environments/:environment/approvers", deploymentHandler.GetEnvironmentApprovers)
+			projects.PUT("/:id/environments/:environment/approvers", deploymentHandler.SetEnvironmentApprovers)
 			projects.GET("/:id/deployments", deploymentHandler.GetProjectDeployments)
 			projects.GET("/:id/deployments/latest", deploymentHandler.GetLatestProjectDeployment)
+			projects.GET("/:id/deployments/compare", deploymentCompareHandler.CompareDeployments)
+			projects.POST("/:id/restart", rateLimit("restart_project", cfg.RateLimits.RestartProject), deploymentHandler.RestartProject)
+			projects.GET("/:id/usage", usageHandler.GetProjectUsage)
+			projects.GET("/:id/badge", badgeHandler.GetProjectBadge)
+			projects.GET("/:id/incidents", monitoringHandler.ListIncidents)
+			projects.GET("/:id/metrics", metricsHandler.GetProjectMetrics)
+			// Managed database
+			projects.GET("/:id/database", databaseHandler.GetProjectDatabase)
+			projects.POST("/:id/database/reset", databaseHandler.ResetProjectDatabase)
+			projects.GET("/:id/database/snapshots", databaseHandler.ListSnapshots)
+			projects.POST("/:id/database/snapshots", databaseHandler.CreateSnapshot)
+			projects.DELETE("/:id/database/snapshots/:snapshot_id", databaseHandler.DeleteSnapshot)
+			projects.GET("/:id/database/branches", databaseHandler.ListBranches)
+			projects.POST("/:id/database/branches", databaseHandler.CreateBranch)
+			projects.DELETE("/:id/database/branches/:branch_id", databaseHandler.DeleteBranch)
+			// One-off commands
+			projects.POST("/:id/exec", rateLimit("run_command", cfg.RateLimits.RunCommand), commandHandler.RunCommand)
+			projects.GET("/:id/exec", commandHandler.ListCommandRuns)
+			projects.GET("/:id/exec/:run_id", commandHandler.GetCommandRun)
+			projects.GET("/:id/exec/:run_id/stream", commandHandler.StreamCommandOutput)
+			// Interactive shells
+			projects.POST("/:id/shell", rateLimit("start_shell", cfg.RateLimits.StartShell), shellHandler.StartShell)
+			projects.GET("/:id/shell", shellHandler.ListShellSessions)
 			// Environment variables
 			projects.GET("/:id/env", envVarHandler.GetProjectEnvVars)
 			projects.POST("/:id/env", envVarHandler.CreateOrUpdateEnvVar)
@@ -224,30 +724,64 @@ func main() {
 		// Deployment routes
 		deployments := v1.Group("/deployments")
 		{
-			// SSE endpoint - NO AUTH for now (outside middleware)
-			deployments.GET("/:id/logs/stream", func(c *gin.Context) {
-				log.Printf("[SSE] Endpoint hit directly - no auth middleware")
-				deploymentHandler.StreamDeploymentLogs(c)
-			})
-
 			// Protected routes
 			protectedDeployments := deployments.Group("")
 			protectedDeployments.Use(authMiddleware.RequireAuth())
 			{
-				protectedDeployments.POST("", deploymentHandler.CreateDeployment)
-				protectedDeployments.GET("/:id", deploymentHandler.GetDeployment)
-				protectedDeployments.PATCH("/:id/status", deploymentHandler.UpdateDeploymentStatus)
-				protectedDeployments.POST("/:id/logs", deploymentHandler.AppendDeploymentLog)
-				protectedDeployments.DELETE("/:id", deploymentHandler.DeleteDeployment)
+				protectedDeployments.POST("", middleware.Idempotency(idempotencyService, "create_deployment"), rateLimit("create_deployment", cfg.RateLimits.CreateDeployment), deploymentHandler.CreateDeployment)
+
+				// Approvers of the deployment's environment decide on it without owning it, the service checks them
+				protectedDeployments.POST("/:id/approve", deploymentHandler.ApproveDeployment)
+				protectedDeployments.POST("/:id/reject", deploymentHandler.RejectDeployment)
+
+				// Only the deployment's owner can access it
+				deployment := protectedDeployments.Group("/:id")
+				deployment.Use(middleware.RequireDeploymentAccess(authorizationService, cfg.System.OperatorIDs))
+				{
+					deployment.GET("", deploymentHandler.GetDeployment)
+					// Browsers open the stream with a stream token in the query, EventSource can't send headers
+					deployment.GET("/logs/stream", deploymentHandler.StreamDeploymentLogs)
+					deployment.POST("/logs/stream/token", authMiddleware.IssueStreamToken())
+					deployment.GET("/timeline", timelineHandler.GetDeploymentTimeline)
+					deployment.GET("/scan", scanHandler.GetDeploymentScan)
+					deployment.GET("/scan/sbom", scanHandler.GetDeploymentSBOM)
+					deployment.GET("/runs", cronRunHandler.ListRuns)
+					deployment.GET("/runs/:run_id/logs", cronRunHandler.GetRunLogs)
+					deployment.PATCH("", deploymentHandler.AnnotateDeployment)
+					deployment.PATCH("/status", deploymentHandler.UpdateDeploymentStatus)
+					deployment.POST("/pin", deploymentHandler.PinDeployment)
+					deployment.DELETE("/pin", deploymentHandler.UnpinDeployment)
+					deployment.GET("/approvals", deploymentHandler.GetDeploymentApprovals)
+					deployment.GET("/steps", deploymentHandler.GetDeploymentSteps)
+					deployment.POST("/retry", deploymentHandler.RetryDeployment)
+					deployment.POST("/logs", deploymentHandler.AppendDeploymentLog)
+					deployment.DELETE("", deploymentHandler.DeleteDeployment)
+				}
 			}
 		}
 
 		// User deployment routes
 		users.GET("/:id/deployments", deploymentHandler.GetUserDeployments)
+
+		// Background job routes
+		jobs := v1.Group("/jobs")
+		jobs.Use(authMiddleware.RequireAuth())
+		{
+			jobs.GET("/:id", jobHandler.GetJob)
+		}
+
+		// Tokens of the self-hosted build agents running the user's builds
+		agentTokens := v1.Group("/agent-tokens")
+		agentTokens.Use(authMiddleware.RequireAuth())
+		{
+			agentTokens.GET("", agentTokenHandler.ListAgentTokens)
+			agentTokens.POST("", agentTokenHandler.CreateAgentToken)
+			agentTokens.DELETE("/:id", agentTokenHandler.RevokeAgentToken)
+		}
 	}
 
-	// Swagger documentation
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// The API's OpenAPI document, for clients to generate code from
+	router.GET("/openapi.json", openAPIHandler.Document)
 
 	// Create HTTP server
 	server := &http.Server{
@@ -257,20 +791,176 @@ func main() {
 		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
 		IdleTimeout:  time.Duration(cfg.Server.IdleTimeout) * time.Second,
 	}
+	// End log streams on shutdown, they would otherwise hold it up until it times out
+	server.RegisterOnShutdown(handlers.GetSSEManager().Shutdown)
+
+	// Meter compute usage of running services in the background
+	meterCtx, stopMeter := context.WithCancel(context.Background())
+	defer stopMeter()
+	go usageService.RunMeter(meterCtx, time.Duration(cfg.Usage.MeterIntervalMinutes)*time.Minute)
+
+	// Make new users Stripe customers and report their usage in the background
+	billingCtx, stopBilling := context.WithCancel(context.Background())
+	defer stopBilling()
+	go billingService.RunSync(billingCtx, time.Duration(cfg.Billing.SyncIntervalMinutes)*time.Minute)
+
+	// Send GitHub status reports in the background
+	githubStatusCtx, stopGitHubStatus := context.WithCancel(context.Background())
+	defer stopGitHubStatus()
+	go githubStatusService.Run(githubStatusCtx)
+
+	// Move old deployments out of the active table in the background
+	archiveCtx, stopArchiver := context.WithCancel(context.Background())
+	defer stopArchiver()
+	go deploymentService.RunArchiver(archiveCtx, time.Duration(cfg.Deployments.ArchiveIntervalHours)*time.Hour, cfg.Deployments.ArchiveAfterMonths)
+
+	// Purge deleted projects and deployments once their retention period is over
+	retentionCtx, stopRetention := context.WithCancel(context.Background())
+	defer stopRetention()
+	go retentionService.RunPurger(retentionCtx, time.Duration(cfg.Retention.PurgeIntervalHours)*time.Hour, cfg.Retention.PurgeAfterDays)
+
+	// Run the project teardowns a stopped instance left unfinished again
+	teardownCtx, stopTeardownResumer := context.WithCancel(context.Background())
+	defer stopTeardownResumer()
+	go projectService.RunTeardownResumer(teardownCtx, 10*time.Minute)
+
+	// Delete images that none of a project's recent deployments use
+	if imageCleanupService != nil {
+		imageCleanupCtx, stopImageCleanup := context.WithCancel(context.Background())
+		defer stopImageCleanup()
+		go imageCleanupService.RunCleanup(imageCleanupCtx, time.Duration(cfg.Images.CleanupIntervalHours)*time.Hour)
+	}
+
+	// Remove the directories of local builds whose process crashed before it cleaned up, and unused repository caches
+	if buildWorkspace != nil {
+		workspaceCleanupCtx, stopWorkspaceCleanup := context.WithCancel(context.Background())
+		defer stopWorkspaceCleanup()
+		go buildWorkspace.RunCleanup(workspaceCleanupCtx, time.Duration(cfg.Builds.CleanupIntervalMinutes)*time.Minute)
+	}
+
+	// Drop database snapshots and branches whose TTL has run out
+	branchCleanupCtx, stopBranchCleanup := context.WithCancel(context.Background())
+	defer stopBranchCleanup()
+	go databaseBranchService.RunCleanup(branchCleanupCtx, time.Duration(cfg.Branches.CleanupIntervalMinutes)*time.Minute)
+
+	// Record the runs of cron jobs before ECS forgets their stopped tasks
+	cronRunCtx, stopCronRunSync := context.WithCancel(context.Background())
+	defer stopCronRunSync()
+	go cronRunService.RunSync(cronRunCtx, time.Duration(cfg.Cron.SyncIntervalSeconds)*time.Second)
+
+	// Share deployment log lines with the other API instances so clients see them whichever instance they reach
+	if cfg.Logs.FanOut == "postgres" {
+		logFanOut := logfanout.NewPostgresFanOut(db, handlers.GetSSEManager())
+		handlers.GetSSEManager().SetPublisher(logFanOut)
+		logFanOutCtx, stopLogFanOut := context.WithCancel(context.Background())
+		defer stopLogFanOut()
+		go logFanOut.Run(logFanOutCtx)
+	}
+
+	// Receive the build events builds are followed with
+	if buildEvents != nil {
+		buildEventsCtx, stopBuildEvents := context.WithCancel(context.Background())
+		defer stopBuildEvents()
+		go buildEvents.Run(buildEventsCtx)
+	}
+
+	// Receive the service events rollouts wait for
+	if ecsEvents != nil {
+		ecsEventsCtx, stopECSEvents := context.WithCancel(context.Background())
+		defer stopECSEvents()
+		go ecsEvents.Run(ecsEventsCtx)
+	}
+
+	// Check the health endpoints of deployed projects and open incidents while they fail
+	monitorCtx, stopMonitor := context.WithCancel(context.Background())
+	defer stopMonitor()
+	go monitoringService.RunMonitor(monitorCtx, time.Duration(cfg.Uptime.CheckIntervalSeconds)*time.Second)
+
+	// Claim queued builds from the database, including those queued before a restart
+	buildCtx, stopBuilds := context.WithCancel(context.Background())
+	defer stopBuilds()
+	buildsStopped := make(chan struct{})
+	go func() {
+		buildService.Run(buildCtx)
+		close(buildsStopped)
+	}()
+
+	// Write buffered deployment logs every flush interval
+	if deploymentLogs != nil {
+		logsCtx, stopLogs := context.WithCancel(context.Background())
+		defer stopLogs()
+		go deploymentLogs.Run(logsCtx)
+	}
+
+	// Fail deployments left in progress by a crashed build or deploy
+	reaperCtx, stopReaper := context.WithCancel(context.Background())
+	defer stopReaper()
+	go deploymentService.RunReaper(reaperCtx, time.Duration(cfg.Deployments.ReaperIntervalMinutes)*time.Minute, time.Duration(cfg.Deployments.TimeoutMinutes)*time.Minute)
+
+	// Re-encrypt environment variables with the active encryption key when a rotation is started
+	keyRotationCtx, stopKeyRotation := context.WithCancel(context.Background())
+	defer stopKeyRotation()
+	go keyRotationService.Run(keyRotationCtx)
+
+	// Forget responses to idempotent requests once they can no longer be retried
+	idempotencyCtx, stopIdempotencyPurger := context.WithCancel(context.Background())
+	defer stopIdempotencyPurger()
+	go idempotencyService.RunPurger(idempotencyCtx, time.Duration(cfg.Idempotency.PurgeIntervalMinutes)*time.Minute)
 
 	// Start server in a goroutine
 	go func() {
-		log.Printf("Server starting on %s", cfg.GetServerAddress())
+		slog.Info("Server starting", "address", cfg.GetServerAddress())
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to start server: %v", err)
 		}
 	}()
 
+	// Serve the gRPC API build agents report to alongside the HTTP API
+	var agentServer *grpc.Server
+	if cfg.AgentsEnabled() {
+		var creds credentials.TransportCredentials
+		if cfg.Agents.TLSCertFile != "" {
+			creds, err = credentials.NewServerTLSFromFile(cfg.Agents.TLSCertFile, cfg.Agents.TLSKeyFile)
+			if err != nil {
+				log.Fatalf("Failed to load build agent TLS certificate: %v", err)
+			}
+		} else {
+			slog.Warn("Build agent gRPC API is served without TLS, terminate TLS in front of it")
+		}
+
+		listener, err := net.Listen("tcp", cfg.GetAgentAddress())
+		if err != nil {
+			log.Fatalf("Failed to listen for build agents: %v", err)
+		}
+		agentRPC := agentrpc.NewServer(deploymentService, handlers.GetSSEManager())
+		if agentBackend != nil {
+			agentRPC.SetBuildDispatcher(agentBackend)
+		}
+		agentRPC.SetAgentAuthenticator(agentTokenService)
+		agentServer = agentrpc.NewGRPCServer(agentRPC, cfg.Agents.Token, creds)
+		go func() {
+			slog.Info("Build agent gRPC server starting", "address", cfg.GetAgentAddress())
+			if err := agentServer.Serve(listener); err != nil {
+				log.Fatalf("Failed to serve build agents: %v", err)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server...")
+	stopMeter()
+	stopMonitor()
+
+	// Stop claiming builds, give the running ones a grace period and queue those still running after it again.
+	// The API stays up meanwhile so build agents can report and clients keep following the logs.
+	stopBuilds()
+	<-buildsStopped
+	graceCtx, cancelGrace := context.WithTimeout(context.Background(), time.Duration(cfg.Builds.ShutdownGraceSeconds)*time.Second)
+	buildService.Shutdown(graceCtx)
+	cancelGrace()
 
 	// Give outstanding requests 30 seconds to complete
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -279,6 +969,46 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatal("Server forced to shutdown:", err)
 	}
+	if agentServer != nil {
+		stopAgents(ctx, agentServer)
+	}
+	if deploymentLogs != nil {
+		// Nothing logs anymore, the lines still buffered are written before exiting
+		deploymentLogs.Flush(ctx)
+	}
 
-	log.Println("Server exited")
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+
+	slog.Info("Server exited")
+}
+
+// quotaPlans converts the configured plans to those users' limits are checked against
+func quotaPlans(cfg config.QuotasConfig) quota.Plans {
+	plans := quota.Plans{Default: cfg.DefaultPlan, Limits: make(map[string]quota.Limits, len(cfg.Plans))}
+	for name, limits := range cfg.Plans {
+		plans.Limits[name] = quota.Limits{
+			Projects:             limits.Projects,
+			ConcurrentBuilds:     limits.ConcurrentBuilds,
+			EnvVars:              limits.EnvVars,
+			BuildMinutesPerMonth: limits.BuildMinutesPerMonth,
+		}
+	}
+	return plans
+}
+
+// stopAgents lets open build agent calls finish, cutting them off once the context is done
+func stopAgents(ctx context.Context, agentServer *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		agentServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		agentServer.Stop()
+	}
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+
+	"snapdeploy-core/api"
+	"snapdeploy-core/internal/openapi"
+)
+
+// route is a route main registers, with the full path of its group
+type route struct {
+	method string
+	path   string
+}
+
+// registeredRoutes reads the routes main registers on the router from its source: the paths of the router's
+// groups, assigned with Group, are joined with the paths their routes are registered with
+func registeredRoutes(t *testing.T) []route {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", nil, 0)
+	if err != nil {
+		t.Fatalf("failed to parse main.go: %v", err)
+	}
+
+	methods := map[string]bool{"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true}
+	groups := map[string]string{"router": ""}
+	var routes []route
+
+	// Groups are assigned before their routes are registered, so the source order resolves them
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			if len(node.Lhs) != 1 || len(node.Rhs) != 1 {
+				return true
+			}
+			name, ok := node.Lhs[0].(*ast.Ident)
+			call, isCall := node.Rhs[0].(*ast.CallExpr)
+			if !ok || !isCall {
+				return true
+			}
+			if prefix, path, ok := routeCall(call, groups, "Group"); ok {
+				groups[name.Name] = prefix + path
+			}
+		case *ast.CallExpr:
+			sel, ok := node.Fun.(*ast.SelectorExpr)
+			if !ok || !methods[sel.Sel.Name] {
+				return true
+			}
+			if prefix, path, ok := routeCall(node, groups, sel.Sel.Name); ok {
+				routes = append(routes, route{method: sel.Sel.Name, path: prefix + path})
+			}
+		}
+		return true
+	})
+	return routes
+}
+
+// routeCall returns the path of a group and the path literal of a call of one of its methods
+func routeCall(call *ast.CallExpr, groups map[string]string, method string) (prefix, path string, ok bool) {
+	sel, isSel := call.Fun.(*ast.SelectorExpr)
+	if !isSel || sel.Sel.Name != method || len(call.Args) == 0 {
+		return "", "", false
+	}
+	group, isIdent := sel.X.(*ast.Ident)
+	if !isIdent {
+		return "", "", false
+	}
+	prefix, known := groups[group.Name]
+	lit, isLit := call.Args[0].(*ast.BasicLit)
+	if !known || !isLit || lit.Kind != token.STRING {
+		return "", "", false
+	}
+	path, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", "", false
+	}
+	return prefix, path, true
+}
+
+func TestRoutesAreDescribedByOpenAPIDocument(t *testing.T) {
+	doc, err := openapi.Load(api.OpenAPI)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	routes := registeredRoutes(t)
+	checked := 0
+	for _, r := range routes {
+		path, ok := strings.CutPrefix(r.path, "/api/v1")
+		if !ok {
+			continue
+		}
+		checked++
+		if doc.Operation(r.method, path) == nil {
+			t.Errorf("%s %s is registered but not described in api/openapi.yaml", r.method, r.path)
+		}
+	}
+	// The routes were read from the source, finding none would mean they no longer can be
+	if checked < 50 {
+		t.Fatalf("found %d routes under /api/v1 in main.go, want the routes main registers", checked)
+	}
+}
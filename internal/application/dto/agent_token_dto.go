@@ -0,0 +1,24 @@
+package dto
+
+// CreateAgentTokenRequest represents a request to create a token for the user's self-hosted build agents
+type CreateAgentTokenRequest struct {
+	Name string `json:"name" binding:"required"` // Tells the user's tokens apart, e.g. the machine the agent runs on
+}
+
+// AgentTokenResponse represents a token the user's self-hosted build agents authenticate with
+type AgentTokenResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+}
+
+// CreateAgentTokenResponse represents a created agent token, with the secret only shown this once
+type CreateAgentTokenResponse struct {
+	AgentToken *AgentTokenResponse `json:"agent_token"`
+	Token      string              `json:"token"` // Secret agents present, as SNAPDEPLOY_AGENT_TOKEN
+}
+
+// AgentTokenListResponse represents the user's agent tokens, newest first
+type AgentTokenListResponse struct {
+	AgentTokens []*AgentTokenResponse `json:"agent_tokens"`
+}
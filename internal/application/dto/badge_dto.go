@@ -0,0 +1,14 @@
+package dto
+
+// ProjectBadgeResponse represents the status badge of a project, to embed in its README
+type ProjectBadgeResponse struct {
+	ImageURL string `json:"image_url"` // Signed URL of the SVG badge, shows the latest deployment
+	Markdown string `json:"markdown"`
+}
+
+// BadgeStatus is what a status badge shows: a label, and a message on a background of the color
+type BadgeStatus struct {
+	Label   string
+	Message string
+	Color   string // Hex color, e.g. #4c1
+}
@@ -0,0 +1,9 @@
+package dto
+
+// BillingPortalResponse represents a link to the Stripe customer portal, where users manage their subscription,
+// payment methods and invoices
+type BillingPortalResponse struct {
+	URL       string `json:"url"`       // Valid for a short while, open it right away
+	Plan      string `json:"plan"`      // Plan the user's subscription entitles them to, empty for the default plan
+	Suspended bool   `json:"suspended"` // Whether a payment failed and the user's services are stopped
+}
@@ -0,0 +1,30 @@
+package dto
+
+// RunCommandRequest represents a request to run a one-off command in a project's container
+type RunCommandRequest struct {
+	Command     string `json:"command" binding:"required,max=1024"` // Split on whitespace, not run by a shell, e.g. "bin/rails db:seed"
+	Environment string `json:"environment,omitempty"`               // Defaults to production
+}
+
+// CommandRunResponse represents a one-off command run in a project's container
+type CommandRunResponse struct {
+	ID              string   `json:"id"`
+	ProjectID       string   `json:"project_id"`
+	DeploymentID    string   `json:"deployment_id"` // Deployment whose image and environment variables the command ran with
+	UserID          string   `json:"user_id"`       // User who ran the command
+	Environment     string   `json:"environment"`
+	Command         string   `json:"command"`
+	Status          string   `json:"status"`              // RUNNING, SUCCEEDED or FAILED
+	ExitCode        *int     `json:"exit_code,omitempty"` // Unset while running or if the command never started
+	Error           string   `json:"error,omitempty"`     // Why a failed run failed
+	Output          []string `json:"output,omitempty"`    // Lines the command wrote, oldest first; only returned for a single run
+	StartedAt       string   `json:"started_at"`
+	FinishedAt      string   `json:"finished_at,omitempty"`
+	DurationSeconds int64    `json:"duration_seconds"`
+}
+
+// CommandRunListResponse represents the most recent command runs of a project, newest first
+type CommandRunListResponse struct {
+	ProjectID string                `json:"project_id"`
+	Runs      []*CommandRunResponse `json:"runs"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+// CronRunResponse represents one scheduled run of a CRON project's deployment
+type CronRunResponse struct {
+	ID              string `json:"id"`
+	DeploymentID    string `json:"deployment_id"`
+	TaskARN         string `json:"task_arn"`
+	Status          string `json:"status"`              // RUNNING, SUCCEEDED or FAILED
+	ExitCode        *int   `json:"exit_code,omitempty"` // Unset while running or if the container never started
+	Reason          string `json:"reason,omitempty"`    // Why a failed run stopped
+	StartedAt       string `json:"started_at"`
+	StoppedAt       string `json:"stopped_at,omitempty"`
+	DurationSeconds int64  `json:"duration_seconds"`
+}
+
+// CronRunListResponse represents the most recent runs of a deployment, newest first
+type CronRunListResponse struct {
+	DeploymentID string             `json:"deployment_id"`
+	Runs         []*CronRunResponse `json:"runs"`
+}
+
+// CronRunLogsResponse represents the log lines a run's container wrote, oldest first
+type CronRunLogsResponse struct {
+	RunID  string   `json:"run_id"`
+	Status string   `json:"status"`
+	Lines  []string `json:"lines"`
+}
@@ -0,0 +1,61 @@
+package dto
+
+// DatabaseBackupResponse represents a backup taken of a project database before a destructive operation
+type DatabaseBackupResponse struct {
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// ProjectDatabaseResponse represents a project's managed Postgres database.
+// The database is created by the project's first deployment and kept across later ones.
+type ProjectDatabaseResponse struct {
+	ProjectID string                    `json:"project_id"`
+	Required  bool                      `json:"required"`
+	Name      string                    `json:"name"`
+	Exists    bool                      `json:"exists"`
+	SizeBytes int64                     `json:"size_bytes"`
+	Backups   []*DatabaseBackupResponse `json:"backups"`
+}
+
+// ResetDatabaseResponse represents the outcome of resetting a project database
+type ResetDatabaseResponse struct {
+	Database *ProjectDatabaseResponse `json:"database"`
+	// Backup is the name of the backup taken before the reset, empty if there was nothing to back up
+	Backup string `json:"backup,omitempty"`
+}
+
+// CreateDatabaseSnapshotRequest represents the request to snapshot a project's database
+type CreateDatabaseSnapshotRequest struct {
+	// TTLHours is how long the snapshot is kept, 0 for 168 hours
+	TTLHours int `json:"ttl_hours" binding:"omitempty,min=1,max=720"`
+}
+
+// DatabaseSnapshotResponse represents a frozen copy of a project's database
+type DatabaseSnapshotResponse struct {
+	ID           string `json:"id"`
+	ProjectID    string `json:"project_id"`
+	DatabaseName string `json:"database_name"`
+	SizeBytes    int64  `json:"size_bytes"`
+	CreatedAt    string `json:"created_at"`
+	ExpiresAt    string `json:"expires_at"`
+}
+
+// CreateDatabaseBranchRequest represents the request to restore a snapshot into a new database
+type CreateDatabaseBranchRequest struct {
+	SnapshotID string `json:"snapshot_id" binding:"required,uuid"`
+	Name       string `json:"name" binding:"required"`
+	// TTLHours is how long the branch is kept, 0 for 72 hours
+	TTLHours int `json:"ttl_hours" binding:"omitempty,min=1,max=720"`
+}
+
+// DatabaseBranchResponse represents an ephemeral database restored from a snapshot
+type DatabaseBranchResponse struct {
+	ID           string  `json:"id"`
+	ProjectID    string  `json:"project_id"`
+	SnapshotID   *string `json:"snapshot_id"` // Null once the snapshot has been deleted
+	Name         string  `json:"name"`
+	DatabaseName string  `json:"database_name"`
+	CreatedAt    string  `json:"created_at"`
+	ExpiresAt    string  `json:"expires_at"`
+}
@@ -2,9 +2,22 @@ package dto
 
 // CreateDeploymentRequest represents the request to create a deployment
 type CreateDeploymentRequest struct {
-	ProjectID  string `json:"project_id" binding:"required"`
-	CommitHash string `json:"commit_hash" binding:"required"`
-	Branch     string `json:"branch" binding:"required"`
+	ProjectID   string `json:"project_id" binding:"required"`
+	CommitHash  string `json:"commit_hash" binding:"required"`
+	Branch      string `json:"branch" binding:"required"`
+	Environment string `json:"environment,omitempty"` // Optional - defaults to production
+
+	// Optional annotations, e.g. a release name, release notes and labels
+	Title       string            `json:"title,omitempty" binding:"max=120"`
+	Description string            `json:"description,omitempty" binding:"max=5000"`
+	Labels      map[string]string `json:"labels,omitempty" binding:"omitempty,max=20"`
+}
+
+// AnnotateDeploymentRequest represents the request to change the annotations of a deployment, omitted fields are kept
+type AnnotateDeploymentRequest struct {
+	Title       *string           `json:"title" binding:"omitempty,max=120"`
+	Description *string           `json:"description" binding:"omitempty,max=5000"`
+	Labels      map[string]string `json:"labels" binding:"omitempty,max=20"` // Replaces all labels, {} removes them
 }
 
 // UpdateDeploymentStatusRequest represents the request to update deployment status
@@ -19,20 +32,155 @@ type AppendDeploymentLogRequest struct {
 
 // DeploymentResponse represents a deployment in API responses
 type DeploymentResponse struct {
-	ID         string `json:"id"`
-	ProjectID  string `json:"project_id"`
-	UserID     string `json:"user_id"`
-	CommitHash string `json:"commit_hash"`
-	Branch     string `json:"branch"`
-	Status     string `json:"status"`
-	Logs       string `json:"logs"`
-	CreatedAt  string `json:"created_at"`
-	UpdatedAt  string `json:"updated_at"`
+	ID          string            `json:"id"`
+	ProjectID   string            `json:"project_id"`
+	UserID      string            `json:"user_id"`
+	CommitHash  string            `json:"commit_hash"`
+	Branch      string            `json:"branch"`
+	Environment string            `json:"environment"`
+	Type        string            `json:"type"`
+	Status      string            `json:"status"`
+	Logs        string            `json:"logs"`
+	ImageURI    string            `json:"image_uri,omitempty"`    // Image the deployment runs, once it starts running one
+	ImageDigest string            `json:"image_digest,omitempty"` // Digest of the image, when the registry reports one
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Pinned      bool              `json:"pinned"` // Pinned deployments can't be deleted and are kept from cleanup
+	CreatedAt   string            `json:"created_at"`
+	UpdatedAt   string            `json:"updated_at"`
+	DeletedAt   string            `json:"deleted_at,omitempty"` // Only set on deleted deployments, which only operators can read
+}
+
+// DeploymentDecisionRequest represents the request to approve or reject a deployment waiting for approval
+type DeploymentDecisionRequest struct {
+	Comment string `json:"comment" binding:"max=500"` // Optional - reason recorded with the decision
+}
+
+// DeploymentApprovalResponse represents the record of a decision on a deployment
+type DeploymentApprovalResponse struct {
+	DeploymentID string `json:"deployment_id"`
+	UserID       string `json:"user_id"`  // User who decided
+	Decision     string `json:"decision"` // APPROVED or REJECTED
+	Comment      string `json:"comment,omitempty"`
+	DecidedAt    string `json:"decided_at"`
+}
+
+// DeploymentApprovalListResponse represents the decisions on a deployment, oldest first
+type DeploymentApprovalListResponse struct {
+	Approvals []*DeploymentApprovalResponse `json:"approvals"`
+}
+
+// EnvironmentApproversRequest represents the request to designate who decides on the deployments to a protected environment
+type EnvironmentApproversRequest struct {
+	UserIDs []string `json:"user_ids" binding:"required,max=20"` // Replaces the approvers, empty leaves the decisions to operators
+}
+
+// EnvironmentApproversResponse represents the users designated to decide on the deployments to an environment
+type EnvironmentApproversResponse struct {
+	Environment string   `json:"environment"`
+	UserIDs     []string `json:"user_ids"`
+}
+
+// DeploymentStepResponse represents the outcome of a pipeline step of a deployment
+type DeploymentStepResponse struct {
+	Step       string `json:"step"`   // clone, build, push, db, migrate, alb, ecs or dns
+	Status     string `json:"status"` // RUNNING, SUCCEEDED, FAILED or SKIPPED
+	Error      string `json:"error,omitempty"`
+	StartedAt  string `json:"started_at"`
+	FinishedAt string `json:"finished_at,omitempty"`
+}
+
+// DeploymentStepListResponse represents the pipeline steps of a deployment in the order they run
+type DeploymentStepListResponse struct {
+	Steps      []*DeploymentStepResponse `json:"steps"`
+	ResumeFrom string                    `json:"resume_from,omitempty"` // Step a retry picks up from, for failed deployments
+}
+
+// DeploymentListFilter narrows a list of deployments and sets its order, from the list's query parameters
+type DeploymentListFilter struct {
+	Status string // Deployment status in any case, e.g. failed
+	Branch string
+	Since  string   // Date such as 2024-01-01 or RFC 3339 time, only deployments created since then
+	Labels []string // Labels as key:value, only deployments with all of them
+	Order  string   // created_at.desc (default) or created_at.asc
 }
 
 // DeploymentListResponse represents a paginated list of deployments
 type DeploymentListResponse struct {
 	Deployments []*DeploymentResponse `json:"deployments"`
 	Pagination  PaginationResponse    `json:"pagination"`
+	ETag        string                `json:"-"` // Version of the page for conditional requests, changes whenever one of its items does
+}
+
+// ComparedDeploymentResponse represents one side of a deployment comparison
+type ComparedDeploymentResponse struct {
+	ID          string `json:"id"`
+	CommitHash  string `json:"commit_hash"`
+	Branch      string `json:"branch"`
+	Environment string `json:"environment"`
+	Status      string `json:"status"`
+	ImageTag    string `json:"image_tag,omitempty"` // Empty if its build never started
+	CreatedAt   string `json:"created_at"`
+}
+
+// CommitRangeResponse represents the commits deployed by the later of two deployments but not by the earlier one
+type CommitRangeResponse struct {
+	Commits   []*CommitResponse `json:"commits"`       // Newest first
+	Truncated bool              `json:"truncated"`     // Whether the Git provider returned only part of a long range
+	URL       string            `json:"url,omitempty"` // Comparison page at the Git provider
+}
+
+// EnvVarChangesResponse represents the environment variables that differ between two deployments, by name.
+// Values are never returned.
+type EnvVarChangesResponse struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"`
+}
+
+// ConfigChangeResponse represents a project setting that differs between two deployments
+type ConfigChangeResponse struct {
+	Key  string `json:"key"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// DeploymentComparisonResponse represents what changed between two deployments of a project.
+// Warnings name the parts that could not be compared; they are null in the response.
+type DeploymentComparisonResponse struct {
+	ProjectID string                      `json:"project_id"`
+	From      *ComparedDeploymentResponse `json:"from"`
+	To        *ComparedDeploymentResponse `json:"to"`
+	Commits   *CommitRangeResponse        `json:"commits"`
+	EnvVars   *EnvVarChangesResponse      `json:"env_vars"`
+	Config    []*ConfigChangeResponse     `json:"config"`
+	Warnings  []string                    `json:"warnings,omitempty"`
+}
+
+// PlannedChangeResponse represents a change a deployment would make to a cloud resource
+type PlannedChangeResponse struct {
+	Action   string `json:"action"` // CREATE, UPDATE, REPLACE, DELETE or RUN
+	Resource string `json:"resource"`
+	Name     string `json:"name"`
+	Detail   string `json:"detail,omitempty"`
 }
 
+// DeploymentPlanResponse represents what a deployment would do, worked out by a dry run without executing
+// anything. Errors are why the deployment would fail; it is valid when there are none.
+type DeploymentPlanResponse struct {
+	ProjectID        string                   `json:"project_id"`
+	CommitHash       string                   `json:"commit_hash"`
+	Branch           string                   `json:"branch"`
+	Environment      string                   `json:"environment"`
+	Valid            bool                     `json:"valid"`
+	RequiresApproval bool                     `json:"requires_approval"`
+	RepoConfig       bool                     `json:"repo_config"` // Whether the repository's snapdeploy.yaml would be applied
+	ImageTag         string                   `json:"image_tag"`
+	Dockerfile       string                   `json:"dockerfile"`
+	BuildArgs        []string                 `json:"build_args"` // Names only, values are never returned
+	EnvVars          []string                 `json:"env_vars"`   // Names only, values are never returned
+	Changes          []*PlannedChangeResponse `json:"changes"`
+	Errors           []string                 `json:"errors,omitempty"`
+	Warnings         []string                 `json:"warnings,omitempty"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+// EncryptionStatusResponse represents the encryption keys stored environment variable values use
+type EncryptionStatusResponse struct {
+	ActiveKeyID   string               `json:"active_key_id"`
+	Keys          []EncryptionKeyUsage `json:"keys"`
+	PendingValues int64                `json:"pending_values"` // Values not yet encrypted with the active key
+	Rotation      *KeyRotationResponse `json:"rotation,omitempty"`
+}
+
+// EncryptionKeyUsage represents how many stored values a key encrypts
+type EncryptionKeyUsage struct {
+	KeyID  string `json:"key_id"`
+	Values int64  `json:"values"`
+}
+
+// KeyRotationResponse represents the latest run of the re-encryption job on the instance answering
+type KeyRotationResponse struct {
+	KeyID       string `json:"key_id"` // Key the values were re-encrypted with
+	Status      string `json:"status"` // RUNNING, COMPLETED or FAILED
+	Reencrypted int    `json:"reencrypted"`
+	Failed      int    `json:"failed"` // Values that couldn't be decrypted, such as those of a key no longer configured
+	Error       string `json:"error,omitempty"`
+	StartedAt   string `json:"started_at"`
+	FinishedAt  string `json:"finished_at,omitempty"`
+}
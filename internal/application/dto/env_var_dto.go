@@ -2,8 +2,10 @@ package dto
 
 // CreateEnvVarRequest represents the request to create/update an environment variable
 type CreateEnvVarRequest struct {
-	Key   string `json:"key" binding:"required"`
-	Value string `json:"value" binding:"required"`
+	Key         string `json:"key" binding:"required"`
+	Value       string `json:"value" binding:"required"`
+	Scope       string `json:"scope" binding:"omitempty,oneof=BUILD RUNTIME BOTH"` // Optional - defaults to RUNTIME, BUILD variables are passed to the image build as build args
+	Environment string `json:"environment"`                                        // Optional - defaults to production
 }
 
 // UpdateEnvVarRequest represents the request to update an environment variable
@@ -14,17 +16,31 @@ type UpdateEnvVarRequest struct {
 // EnvVarResponse represents an environment variable in API responses
 // NOTE: Value is ALWAYS masked for security - never exposed to frontend
 type EnvVarResponse struct {
-	ID        string `json:"id"`
-	ProjectID string `json:"project_id"`
-	Key       string `json:"key"`
-	Value     string `json:"value"` // Masked: "f*******t"
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID          string `json:"id"`
+	ProjectID   string `json:"project_id"`
+	Key         string `json:"key"`
+	Value       string `json:"value"` // Masked: "f*******t"
+	Scope       string `json:"scope"` // BUILD, RUNTIME or BOTH
+	Environment string `json:"environment"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+
+	RedeployDeploymentID string `json:"redeploy_deployment_id,omitempty"` // Deployment started to pick the change up, for projects redeploying on changes
 }
 
 // EnvVarListResponse represents a list of environment variables
 type EnvVarListResponse struct {
-	EnvironmentVariables []*EnvVarResponse `json:"environment_variables"`
-	Count                int64             `json:"count"`
+	EnvironmentVariables []*EnvVarResponse  `json:"environment_variables"`
+	Count                int64              `json:"count"`
+	LastChange           *EnvChangeResponse `json:"last_change,omitempty"` // Latest change to the environment's variables, absent if none was recorded
+}
+
+// EnvChangeResponse represents the latest change to an environment's variables and the deployment that
+// picked it up. Running tasks keep the variables they started with until then.
+type EnvChangeResponse struct {
+	ChangedAt           string `json:"changed_at"`
+	Pending             bool   `json:"pending"`                         // Whether no deployment picked the change up yet
+	AppliedDeploymentID string `json:"applied_deployment_id,omitempty"` // First deployment created after the change that succeeded
+	AppliedAt           string `json:"applied_at,omitempty"`            // When that deployment succeeded
 }
 
@@ -0,0 +1,21 @@
+package dto
+
+// ClaimGitHubInstallationRequest links a GitHub App installation to the current user
+type ClaimGitHubInstallationRequest struct {
+	InstallationID int64 `json:"installation_id" binding:"required"`
+}
+
+// GitHubInstallationResponse represents a GitHub App installation claimed by a user
+type GitHubInstallationResponse struct {
+	ID           int64  `json:"id"`
+	AccountLogin string `json:"account_login"`
+	AccountType  string `json:"account_type"`
+	Suspended    bool   `json:"suspended"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// GitHubInstallationListResponse represents a user's GitHub App installations
+type GitHubInstallationListResponse struct {
+	InstallURL    string                        `json:"install_url"`
+	Installations []*GitHubInstallationResponse `json:"installations"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+// IdempotentResponse is the stored response replayed to a request retried with the same Idempotency-Key
+type IdempotentResponse struct {
+	StatusCode int
+	Body       []byte
+}
@@ -0,0 +1,20 @@
+package dto
+
+// MetricSeries represents the values of one metric, aligned with the response timestamps.
+// A null value means no data was reported for that interval.
+type MetricSeries struct {
+	Name   string     `json:"name"`
+	Unit   string     `json:"unit"`
+	Values []*float64 `json:"values"`
+}
+
+// ProjectMetricsResponse represents a project's runtime metrics on a shared time axis
+type ProjectMetricsResponse struct {
+	ProjectID   string          `json:"project_id"`
+	Period      string          `json:"period"`
+	Start       string          `json:"start"`
+	End         string          `json:"end"`
+	StepSeconds int             `json:"step_seconds"`
+	Timestamps  []string        `json:"timestamps"`
+	Series      []*MetricSeries `json:"series"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+// ProjectIncidentResponse represents a period a project's health checks kept failing
+type ProjectIncidentResponse struct {
+	ID              string `json:"id"`
+	Status          string `json:"status"` // OPEN while checks keep failing, RESOLVED once one succeeded again
+	Cause           string `json:"cause"`  // Why the last failed check failed
+	FailedChecks    int    `json:"failed_checks"`
+	StartedAt       string `json:"started_at"`
+	ResolvedAt      string `json:"resolved_at,omitempty"`
+	DurationSeconds int64  `json:"duration_seconds"` // How long the incident lasted, or has lasted so far
+}
+
+// ProjectIncidentListResponse represents the most recent incidents of a project, newest first
+type ProjectIncidentListResponse struct {
+	Incidents []*ProjectIncidentResponse `json:"incidents"`
+}
@@ -2,48 +2,204 @@ package dto
 
 // CreateProjectRequest represents the request to create a project
 type CreateProjectRequest struct {
-	RepositoryURL    string `json:"repository_url" binding:"required"`
-	InstallCommand   string `json:"install_command" binding:"required"`
-	BuildCommand     string `json:"build_command"` // Optional
-	RunCommand       string `json:"run_command" binding:"required"`
-	Language         string `json:"language" binding:"required"`
-	CustomDomain     string `json:"custom_domain"`     // Optional - will auto-generate if empty
-	RequireDB        bool   `json:"require_db"`        // Whether to create a dedicated database
-	MigrationCommand string `json:"migration_command"` // Optional - command to run migrations (e.g., "npm run migrate")
+	RepositoryURL         string           `json:"repository_url" binding:"required"`
+	InstallCommand        string           `json:"install_command" binding:"required"`
+	BuildCommand          string           `json:"build_command"` // Optional
+	RunCommand            string           `json:"run_command" binding:"required"`
+	Language              string           `json:"language" binding:"required"`
+	CustomDomain          string           `json:"custom_domain"`                                                                    // Optional - will auto-generate if empty
+	RequireDB             bool             `json:"require_db"`                                                                       // Whether to create a dedicated database
+	MigrationCommand      string           `json:"migration_command"`                                                                // Optional - command to run migrations (e.g., "npm run migrate")
+	ImageRetention        int              `json:"image_retention" binding:"min=0,max=100"`                                          // Optional - recent deployments whose images are kept, 0 for the platform default
+	Type                  string           `json:"type" binding:"omitempty,oneof=WEB WORKER CRON STATIC"`                            // Optional - defaults to WEB, WORKER runs without a load balancer or domain, CRON runs on a schedule, STATIC is served from S3 through CloudFront
+	Schedule              string           `json:"schedule" binding:"max=256"`                                                       // Required for CRON projects - EventBridge schedule such as cron(0 3 * * ? *) or rate(1 hour)
+	OutputDirectory       string           `json:"output_directory" binding:"max=255"`                                               // STATIC only - directory the build writes the site to, such as dist or build, defaults to dist
+	DeploymentStrategy    string           `json:"deployment_strategy" binding:"omitempty,oneof=ROLLING BLUE_GREEN RECREATE CANARY"` // Optional - defaults to ROLLING
+	CanaryPercent         int              `json:"canary_percent" binding:"min=0,max=50"`                                            // Optional - share of traffic the canary of a CANARY deployment receives, 0 for the default
+	CanaryBakeMinutes     int              `json:"canary_bake_minutes" binding:"min=0,max=60"`                                       // Optional - minutes the canary is watched before it is promoted, 0 for the default
+	Datastores            []string         `json:"datastores" binding:"omitempty,dive,oneof=REDIS MYSQL"`                            // Optional - datastores to provision besides the Postgres database
+	VolumeMountPath       string           `json:"volume_mount_path" binding:"max=255"`                                              // Optional - where a persistent volume is mounted, empty for none
+	VolumeSizeGB          int              `json:"volume_size_gb" binding:"min=0,max=100"`                                           // Optional - size the volume is expected to stay within, 0 for the default
+	Services              []ServiceRequest `json:"services" binding:"omitempty,max=5,dive"`                                          // Optional - processes run besides the main one from the same repository and environment variables
+	Environments          []string         `json:"environments" binding:"omitempty,max=5"`                                           // Optional - environments deployed besides production, such as staging, each with its own environment variables, subdomain and services
+	ProtectedEnvironments []string         `json:"protected_environments" binding:"omitempty,max=6"`                                 // Optional - environments, production included, whose deployments wait for approval before they are built
+	DeploymentTarget      string           `json:"deployment_target" binding:"omitempty,oneof=ECS LAMBDA"`                           // Optional - defaults to ECS, LAMBDA runs a WEB project as a Lambda function started per request
+	LambdaEndpoint        string           `json:"lambda_endpoint" binding:"omitempty,oneof=API_GATEWAY FUNCTION_URL"`               // LAMBDA only - API_GATEWAY (the default) serves the function on the project's subdomain, FUNCTION_URL on its own Lambda URL
+	PublicStatusPage      bool             `json:"public_status_page"`                                                               // Optional - whether anyone may read the project's status page at /public/projects/<custom domain>/status
+	BuildSize             string           `json:"build_size" binding:"omitempty,oneof=SMALL MEDIUM LARGE"`                          // Optional - CPU and memory of builds, SMALL (the default) 2 vCPUs and 3 GiB, MEDIUM 4 vCPUs and 7 GiB, LARGE 8 vCPUs and 15 GiB
+	BuildTimeoutMinutes   int              `json:"build_timeout_minutes" binding:"omitempty,min=5,max=480"`                          // Optional - minutes a build may run before it is stopped and the deployment fails, 0 for the default of 30
+	RedeployOnEnvChange   bool             `json:"redeploy_on_env_change"`                                                           // Optional - whether changing an environment variable redeploys the image its environment last deployed successfully
+}
+
+// ValidateProjectRequest represents a project configuration to check before the project is created
+type ValidateProjectRequest struct {
+	CreateProjectRequest
+	Branch string `json:"branch" binding:"max=255"` // Optional - branch the first deployment is built from, checked to exist
+	Port   int    `json:"port"`                     // Optional - port the app listens on, 0 for the default
+}
+
+// FieldError is a problem with one field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ProjectValidationResponse lists the problems a project configuration would run into
+type ProjectValidationResponse struct {
+	Valid    bool         `json:"valid"`
+	Errors   []FieldError `json:"errors"`   // Problems that must be fixed before the project can be created and deployed
+	Warnings []FieldError `json:"warnings"` // Checks that couldn't be made, the configuration may still be valid
+}
+
+// DomainAvailabilityResponse reports whether a custom domain can be chosen for a new project
+type DomainAvailabilityResponse struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"` // Why the domain can't be chosen, empty when it is available
 }
 
 // UpdateProjectRequest represents the request to update a project
 type UpdateProjectRequest struct {
-	RepositoryURL    string `json:"repository_url" binding:"required"`
-	InstallCommand   string `json:"install_command" binding:"required"`
-	BuildCommand     string `json:"build_command"` // Optional
-	RunCommand       string `json:"run_command" binding:"required"`
-	Language         string `json:"language" binding:"required"`
-	CustomDomain     string `json:"custom_domain"`     // Optional - will auto-generate if empty
-	RequireDB        bool   `json:"require_db"`        // Whether to create a dedicated database
-	MigrationCommand string `json:"migration_command"` // Optional - command to run migrations (e.g., "npm run migrate")
+	RepositoryURL         string           `json:"repository_url" binding:"required"`
+	InstallCommand        string           `json:"install_command" binding:"required"`
+	BuildCommand          string           `json:"build_command"` // Optional
+	RunCommand            string           `json:"run_command" binding:"required"`
+	Language              string           `json:"language" binding:"required"`
+	CustomDomain          string           `json:"custom_domain"`                                                                    // Optional - will auto-generate if empty
+	RequireDB             bool             `json:"require_db"`                                                                       // Whether to create a dedicated database
+	MigrationCommand      string           `json:"migration_command"`                                                                // Optional - command to run migrations (e.g., "npm run migrate")
+	ImageRetention        int              `json:"image_retention" binding:"min=0,max=100"`                                          // Optional - recent deployments whose images are kept, 0 for the platform default
+	Type                  string           `json:"type" binding:"omitempty,oneof=WEB WORKER CRON STATIC"`                            // Optional - defaults to WEB, WORKER runs without a load balancer or domain, CRON runs on a schedule, STATIC is served from S3 through CloudFront
+	Schedule              string           `json:"schedule" binding:"max=256"`                                                       // Required for CRON projects - EventBridge schedule such as cron(0 3 * * ? *) or rate(1 hour)
+	OutputDirectory       string           `json:"output_directory" binding:"max=255"`                                               // STATIC only - directory the build writes the site to, such as dist or build, defaults to dist
+	DeploymentStrategy    string           `json:"deployment_strategy" binding:"omitempty,oneof=ROLLING BLUE_GREEN RECREATE CANARY"` // Optional - defaults to ROLLING
+	CanaryPercent         int              `json:"canary_percent" binding:"min=0,max=50"`                                            // Optional - share of traffic the canary of a CANARY deployment receives, 0 for the default
+	CanaryBakeMinutes     int              `json:"canary_bake_minutes" binding:"min=0,max=60"`                                       // Optional - minutes the canary is watched before it is promoted, 0 for the default
+	Datastores            []string         `json:"datastores" binding:"omitempty,dive,oneof=REDIS MYSQL"`                            // Optional - datastores to provision besides the Postgres database
+	VolumeMountPath       string           `json:"volume_mount_path" binding:"max=255"`                                              // Optional - where a persistent volume is mounted, empty for none
+	VolumeSizeGB          int              `json:"volume_size_gb" binding:"min=0,max=100"`                                           // Optional - size the volume is expected to stay within, 0 for the default
+	Services              []ServiceRequest `json:"services" binding:"omitempty,max=5,dive"`                                          // Optional - processes run besides the main one from the same repository and environment variables
+	Environments          []string         `json:"environments" binding:"omitempty,max=5"`                                           // Optional - environments deployed besides production, such as staging, each with its own environment variables, subdomain and services
+	ProtectedEnvironments []string         `json:"protected_environments" binding:"omitempty,max=6"`                                 // Optional - environments, production included, whose deployments wait for approval before they are built
+	DeploymentTarget      string           `json:"deployment_target" binding:"omitempty,oneof=ECS LAMBDA"`                           // Optional - defaults to ECS, LAMBDA runs a WEB project as a Lambda function started per request
+	LambdaEndpoint        string           `json:"lambda_endpoint" binding:"omitempty,oneof=API_GATEWAY FUNCTION_URL"`               // LAMBDA only - API_GATEWAY (the default) serves the function on the project's subdomain, FUNCTION_URL on its own Lambda URL
+	PublicStatusPage      bool             `json:"public_status_page"`                                                               // Optional - whether anyone may read the project's status page at /public/projects/<custom domain>/status
+	BuildSize             string           `json:"build_size" binding:"omitempty,oneof=SMALL MEDIUM LARGE"`                          // Optional - CPU and memory of builds, SMALL (the default) 2 vCPUs and 3 GiB, MEDIUM 4 vCPUs and 7 GiB, LARGE 8 vCPUs and 15 GiB
+	BuildTimeoutMinutes   int              `json:"build_timeout_minutes" binding:"omitempty,min=5,max=480"`                          // Optional - minutes a build may run before it is stopped and the deployment fails, 0 for the default of 30
+	RedeployOnEnvChange   bool             `json:"redeploy_on_env_change"`                                                           // Optional - whether changing an environment variable redeploys the image its environment last deployed successfully
+}
+
+// DuplicateProjectRequest represents the request to duplicate a project
+type DuplicateProjectRequest struct {
+	CustomDomain string `json:"custom_domain"` // Optional - will auto-generate if empty
+	Deploy       bool   `json:"deploy"`        // Whether to deploy the commit the project runs in production to the copy
+}
+
+// DuplicateProjectResponse represents a project's copy and its initial deployment
+type DuplicateProjectResponse struct {
+	Project         *ProjectResponse    `json:"project"`
+	EnvVarsCopied   int                 `json:"env_vars_copied"`            // Environment variables copied across the project's environments
+	Deployment      *DeploymentResponse `json:"deployment,omitempty"`       // Initial deployment, if one was requested and started
+	DeploymentError string              `json:"deployment_error,omitempty"` // Why the requested initial deployment didn't start
 }
 
 // ProjectResponse represents a project in API responses
 type ProjectResponse struct {
-	ID               string `json:"id"`
-	UserID           string `json:"user_id"`
-	RepositoryURL    string `json:"repository_url"`
-	InstallCommand   string `json:"install_command"`
-	BuildCommand     string `json:"build_command"`
-	RunCommand       string `json:"run_command"`
-	Language         string `json:"language"`
-	CustomDomain     string `json:"custom_domain"`
-	DeploymentURL    string `json:"deployment_url"`         // Full URL like https://my-app.snapdeploy.app
-	RequireDB        bool   `json:"require_db"`             // Whether project has a dedicated database
-	MigrationCommand string `json:"migration_command"`      // Migration command if configured
-	DatabaseURL      string `json:"database_url,omitempty"` // Database connection URL (only if requireDB=true)
-	CreatedAt        string `json:"created_at"`
-	UpdatedAt        string `json:"updated_at"`
+	ID                  string                 `json:"id"`
+	UserID              string                 `json:"user_id"`
+	RepositoryURL       string                 `json:"repository_url"`
+	InstallCommand      string                 `json:"install_command"`
+	BuildCommand        string                 `json:"build_command"`
+	RunCommand          string                 `json:"run_command"`
+	Language            string                 `json:"language"`
+	CustomDomain        string                 `json:"custom_domain"`
+	DeploymentURL       string                 `json:"deployment_url"`           // Full URL like https://my-app.snapdeploy.app, empty for WORKER and CRON projects
+	RequireDB           bool                   `json:"require_db"`               // Whether project has a dedicated database
+	MigrationCommand    string                 `json:"migration_command"`        // Migration command if configured
+	DatabaseURL         string                 `json:"database_url,omitempty"`   // Database connection URL (only if requireDB=true)
+	Status              string                 `json:"status"`                   // ACTIVE, DELETING or DELETE_FAILED
+	StatusMessage       string                 `json:"status_message,omitempty"` // Teardown progress or failure reason
+	ImageRetention      int                    `json:"image_retention"`          // Recent deployments whose images are kept, 0 for the platform default
+	Type                string                 `json:"type"`                     // WEB, WORKER, CRON or STATIC
+	Schedule            string                 `json:"schedule,omitempty"`       // When a CRON project runs
+	OutputDirectory     string                 `json:"output_directory"`         // Directory a STATIC project's build writes the site to
+	DeploymentTarget    string                 `json:"deployment_target"`        // ECS or LAMBDA
+	LambdaEndpoint      string                 `json:"lambda_endpoint"`          // API_GATEWAY or FUNCTION_URL for LAMBDA projects
+	PublicStatusPage    bool                   `json:"public_status_page"`       // Whether anyone may read the project's status page
+	BuildSize           string                 `json:"build_size"`               // SMALL, MEDIUM or LARGE
+	BuildTimeoutMinutes int                    `json:"build_timeout_minutes"`    // Minutes a build may run before it is stopped
+	RedeployOnEnvChange bool                   `json:"redeploy_on_env_change"`   // Whether changing an environment variable redeploys its environment
+	DeploymentStrategy  string                 `json:"deployment_strategy"`      // ROLLING, BLUE_GREEN, RECREATE or CANARY
+	CanaryPercent       int                    `json:"canary_percent"`           // Share of traffic a canary receives
+	CanaryBakeMinutes   int                    `json:"canary_bake_minutes"`      // Minutes a canary is watched before it is promoted
+	Datastores          []string               `json:"datastores"`               // REDIS and MYSQL datastores provisioned besides the Postgres database
+	VolumeMountPath     string                 `json:"volume_mount_path"`        // Where the persistent volume is mounted, empty for none
+	VolumeSizeGB        int                    `json:"volume_size_gb"`           // Size the persistent volume is expected to stay within
+	Services            []*ServiceResponse     `json:"services"`                 // Processes run besides the main one
+	Environments        []*EnvironmentResponse `json:"environments"`             // Production and the environments deployed besides it
+	Port                int                    `json:"port"`                     // Port the container listens on, set by snapdeploy.yaml
+	HealthCheckPath     string                 `json:"health_check_path"`        // Path the load balancer checks, set by snapdeploy.yaml
+	CPU                 int                    `json:"cpu"`                      // CPU units of the project's tasks, set by snapdeploy.yaml
+	Memory              int                    `json:"memory"`                   // Memory in MiB of the project's tasks, set by snapdeploy.yaml
+	CreatedAt           string                 `json:"created_at"`
+	UpdatedAt           string                 `json:"updated_at"`
+	DeletedAt           string                 `json:"deleted_at,omitempty"` // Only set on deleted projects, which only operators can read
+}
+
+// ServiceRequest defines a process a project runs besides its main one
+type ServiceRequest struct {
+	Name     string `json:"name" binding:"required,max=12"`
+	Type     string `json:"type" binding:"required,oneof=WEB WORKER CRON"`
+	Command  string `json:"command" binding:"required,max=500"` // Command run in place of the project's run command
+	Port     int    `json:"port" binding:"min=0,max=65535"`     // WEB only - port the service listens on, 0 for 8080
+	Replicas int    `json:"replicas" binding:"min=0,max=10"`    // Tasks kept running, 0 for 1. CRON services start one task per run
+	Schedule string `json:"schedule" binding:"max=256"`         // CRON only - EventBridge schedule such as rate(5 minutes)
+}
+
+// ServiceResponse represents a process a project runs besides its main one
+type ServiceResponse struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Command  string `json:"command"`
+	Port     int    `json:"port,omitempty"`
+	Replicas int    `json:"replicas"`
+	Schedule string `json:"schedule,omitempty"`
+	URL      string `json:"url,omitempty"` // Where a WEB service is reachable, like https://my-app-api.snapdeploy.app
+}
+
+// EnvironmentResponse represents an environment a project is deployed to
+type EnvironmentResponse struct {
+	Name      string `json:"name"`
+	URL       string `json:"url,omitempty"` // Where the environment is served, like https://my-app-staging.snapdeploy.app
+	Protected bool   `json:"protected"`     // Whether deployments to the environment wait for approval
 }
 
 // ProjectListResponse represents a paginated list of projects
 type ProjectListResponse struct {
 	Projects   []*ProjectResponse `json:"projects"`
 	Pagination PaginationResponse `json:"pagination"`
+	ETag       string             `json:"-"` // Version of the page for conditional requests, changes whenever one of its items does
+}
+
+// DeployRuleRequest maps the branches matching a pattern to the environment pushes to them are deployed to
+type DeployRuleRequest struct {
+	Branch      string `json:"branch" binding:"required,max=255"`     // Branch name in which * matches any characters but /, e.g. release/*
+	Environment string `json:"environment" binding:"required,max=20"` // Production or one of the project's environments
+}
+
+// UpdateDeployRulesRequest replaces the rules deciding which branches pushes are deployed from
+type UpdateDeployRulesRequest struct {
+	Rules []DeployRuleRequest `json:"rules" binding:"max=20,dive"` // Matched in order, the first matching rule wins. Empty to deploy no pushes
+}
+
+// DeployRuleResponse represents a rule mapping branches to the environment pushes to them are deployed to
+type DeployRuleResponse struct {
+	Branch      string `json:"branch"`
+	Environment string `json:"environment"`
+}
+
+// DeployRulesResponse represents the rules deciding which branches pushes are deployed from
+type DeployRulesResponse struct {
+	ProjectID string                `json:"project_id"`
+	Rules     []*DeployRuleResponse `json:"rules"` // In the order they are matched
 }
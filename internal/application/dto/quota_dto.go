@@ -0,0 +1,26 @@
+package dto
+
+// UserLimitsResponse represents the plan a user is on and how much of each of its limits they use
+type UserLimitsResponse struct {
+	UserID string        `json:"user_id"`
+	Plan   string        `json:"plan"`
+	Limits []*LimitUsage `json:"limits"`
+}
+
+// LimitUsage represents a limit of a user and how much of it is used
+type LimitUsage struct {
+	Resource   string   `json:"resource"`       // projects, concurrent_builds, env_vars or build_minutes
+	Limit      int      `json:"limit"`          // 0 is unlimited
+	Used       *float64 `json:"used,omitempty"` // Not set for env_vars, which are limited per project environment
+	Overridden bool     `json:"overridden"`     // Whether the limit is set for the user instead of coming from their plan
+}
+
+// UpdateUserLimitsRequest represents a request to put a user on a plan and override some of its limits.
+// It replaces the plan and overrides the user had: limits left out keep the plan's.
+type UpdateUserLimitsRequest struct {
+	Plan                 string `json:"plan"` // Empty for the default plan
+	Projects             *int   `json:"projects,omitempty" binding:"omitempty,min=0"`
+	ConcurrentBuilds     *int   `json:"concurrent_builds,omitempty" binding:"omitempty,min=0"`
+	EnvVars              *int   `json:"env_vars,omitempty" binding:"omitempty,min=0"`
+	BuildMinutesPerMonth *int   `json:"build_minutes_per_month,omitempty" binding:"omitempty,min=0"`
+}
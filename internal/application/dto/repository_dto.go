@@ -3,6 +3,7 @@ package dto
 // RepositoryResponse represents repository data in API responses
 type RepositoryResponse struct {
 	ID          string  `json:"id"`
+	Provider    string  `json:"provider"`
 	Name        string  `json:"name"`
 	FullName    string  `json:"full_name"`
 	Description *string `json:"description"`
@@ -21,9 +22,35 @@ type RepositoryResponse struct {
 type RepositoryListResponse struct {
 	Repositories []*RepositoryResponse `json:"repositories"`
 	Pagination   PaginationResponse    `json:"pagination"`
+	ETag         string                `json:"-"` // Version of the page for conditional requests, changes whenever one of its items does
 }
 
 // RepositorySyncResponse represents the response from syncing repositories
 type RepositorySyncResponse struct {
 	Message string `json:"message"`
+	Synced  int    `json:"synced"` // Number of repositories created or updated, only the changed ones for incremental syncs
+}
+
+// RepositoryBranchesResponse represents the branches of a repository
+type RepositoryBranchesResponse struct {
+	RepositoryID  string   `json:"repository_id"`
+	Provider      string   `json:"provider"`
+	DefaultBranch string   `json:"default_branch,omitempty"`
+	Branches      []string `json:"branches"`
+}
+
+// CommitResponse represents a commit in API responses
+type CommitResponse struct {
+	SHA         string `json:"sha"`
+	Message     string `json:"message"`
+	Author      string `json:"author"`
+	CommittedAt string `json:"committed_at"`
+	URL         string `json:"url"`
+}
+
+// RepositoryCommitsResponse represents the recent commits on a branch of a repository
+type RepositoryCommitsResponse struct {
+	RepositoryID string            `json:"repository_id"`
+	Branch       string            `json:"branch"`
+	Commits      []*CommitResponse `json:"commits"`
 }
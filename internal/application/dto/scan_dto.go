@@ -0,0 +1,30 @@
+package dto
+
+// DeploymentScanResponse represents what scanning a deployment's image found before it was deployed
+type DeploymentScanResponse struct {
+	DeploymentID    string                  `json:"deployment_id"`
+	ImageURI        string                  `json:"image_uri"`
+	Status          string                  `json:"status"`          // COMPLETED, or FAILED when the vulnerabilities couldn't be read
+	Error           string                  `json:"error,omitempty"` // Why the vulnerabilities or the SBOM couldn't be read
+	Summary         string                  `json:"summary"`         // e.g. "2 critical, 5 high"
+	SeverityCounts  map[string]int          `json:"severity_counts"`
+	Vulnerabilities []VulnerabilityResponse `json:"vulnerabilities"` // The most severe first, at most 100
+	SBOM            *SBOMSummaryResponse    `json:"sbom,omitempty"`  // Omitted if no SBOM could be generated
+	Blocked         bool                    `json:"blocked"`         // Whether the deployment was stopped for its critical vulnerabilities
+	ScannedAt       string                  `json:"scanned_at"`
+}
+
+// VulnerabilityResponse represents a known vulnerability found in a package of an image
+type VulnerabilityResponse struct {
+	ID       string `json:"id"`
+	Severity string `json:"severity"`
+	Package  string `json:"package"`
+	Version  string `json:"version,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// SBOMSummaryResponse represents the SBOM of an image, downloaded from GET /deployments/:id/scan/sbom
+type SBOMSummaryResponse struct {
+	Format   string `json:"format"`
+	Packages int    `json:"packages"`
+}
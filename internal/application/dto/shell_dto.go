@@ -0,0 +1,38 @@
+package dto
+
+// StartShellRequest represents a request to open an interactive shell in a project's running container
+type StartShellRequest struct {
+	Environment string `json:"environment,omitempty"` // Defaults to production
+}
+
+// StartShellResponse represents a shell session waiting for the user's terminal to connect
+type StartShellResponse struct {
+	Session    *ShellSessionResponse `json:"session"`
+	Token      string                `json:"token"`       // One-time token the terminal connects with
+	ConnectURL string                `json:"connect_url"` // WebSocket path the terminal connects to, token included
+	ExpiresAt  string                `json:"expires_at"`  // The token can't be used after this
+}
+
+// ShellSessionResponse represents an interactive shell opened in a project's running container
+type ShellSessionResponse struct {
+	ID              string `json:"id"`
+	ProjectID       string `json:"project_id"`
+	UserID          string `json:"user_id"` // User who opened the shell
+	Environment     string `json:"environment"`
+	Status          string `json:"status"`               // PENDING, ACTIVE, ENDED or EXPIRED
+	TaskArn         string `json:"task_arn,omitempty"`   // Task the shell ran in
+	EndReason       string `json:"end_reason,omitempty"` // CLOSED, EXITED, IDLE_TIMEOUT, TIME_LIMIT or FAILED
+	Error           string `json:"error,omitempty"`
+	BytesIn         int64  `json:"bytes_in"`  // Bytes typed into the shell
+	BytesOut        int64  `json:"bytes_out"` // Bytes the shell wrote
+	CreatedAt       string `json:"created_at"`
+	StartedAt       string `json:"started_at,omitempty"`
+	EndedAt         string `json:"ended_at,omitempty"`
+	DurationSeconds int64  `json:"duration_seconds"`
+}
+
+// ShellSessionListResponse represents the most recent shell sessions of a project, newest first
+type ShellSessionListResponse struct {
+	ProjectID string                  `json:"project_id"`
+	Sessions  []*ShellSessionResponse `json:"sessions"`
+}
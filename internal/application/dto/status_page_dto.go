@@ -0,0 +1,18 @@
+package dto
+
+// ProjectStatusPageResponse represents the public status page of a project. It only shows what the project's
+// visitors could find out themselves, never commits, branches or logs.
+type ProjectStatusPageResponse struct {
+	Name           string            `json:"name"`                       // Custom domain of the project, its status page is found by
+	URL            string            `json:"url,omitempty"`              // Where the project is live, empty for projects not served on a URL
+	Status         string            `json:"status"`                     // Status of the latest production deployment, NONE before the first
+	LastDeployedAt string            `json:"last_deployed_at,omitempty"` // When production was last deployed successfully
+	Uptime         []*UptimeResponse `json:"uptime"`                     // Share of successful health checks, empty until the project is checked
+}
+
+// UptimeResponse represents the share of a project's health checks that succeeded over a period
+type UptimeResponse struct {
+	Period  string  `json:"period"`  // 24h, 7d or 30d
+	Percent float64 `json:"percent"` // Rounded to two decimals
+	Checks  int64   `json:"checks"`  // Health checks made over the period
+}
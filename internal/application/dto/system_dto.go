@@ -0,0 +1,54 @@
+package dto
+
+import "time"
+
+// CreateIncidentRequest represents the request to post an incident or maintenance notice
+type CreateIncidentRequest struct {
+	Kind     string     `json:"kind" binding:"required"`
+	Severity string     `json:"severity" binding:"required"`
+	Title    string     `json:"title" binding:"required"`
+	Message  string     `json:"message" binding:"required"`
+	StartsAt *time.Time `json:"starts_at,omitempty"` // Defaults to now
+	EndsAt   *time.Time `json:"ends_at,omitempty"`   // Scheduled end of a maintenance window
+}
+
+// UpdateIncidentRequest represents the request to update an incident
+type UpdateIncidentRequest struct {
+	Severity string     `json:"severity" binding:"required"`
+	Title    string     `json:"title" binding:"required"`
+	Message  string     `json:"message" binding:"required"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+}
+
+// IncidentResponse represents an incident in API responses
+type IncidentResponse struct {
+	ID         string `json:"id"`
+	Kind       string `json:"kind"`
+	Severity   string `json:"severity"`
+	Title      string `json:"title"`
+	Message    string `json:"message"`
+	StartsAt   string `json:"starts_at"`
+	EndsAt     string `json:"ends_at,omitempty"`
+	ResolvedAt string `json:"resolved_at,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+// SystemBanner represents the notice shown to users while an incident is active
+type SystemBanner struct {
+	IncidentID string `json:"incident_id"`
+	Kind       string `json:"kind"`
+	Severity   string `json:"severity"`
+	Title      string `json:"title"`
+	Message    string `json:"message"`
+}
+
+// SystemStatusResponse represents the platform status page
+type SystemStatusResponse struct {
+	Status    string              `json:"status"` // operational, maintenance, degraded or major_outage
+	Banner    *SystemBanner       `json:"banner,omitempty"`
+	Active    []*IncidentResponse `json:"active"`
+	Scheduled []*IncidentResponse `json:"scheduled"`
+	Resolved  []*IncidentResponse `json:"resolved"` // Resolved within the history window
+	UpdatedAt string              `json:"updated_at"`
+}
@@ -0,0 +1,39 @@
+package dto
+
+// TemplateResponse represents a template of the catalog projects can be created from
+type TemplateResponse struct {
+	ID               string                      `json:"id"`
+	Name             string                      `json:"name"`
+	Description      string                      `json:"description"`
+	RepositoryURL    string                      `json:"repository_url"`
+	Language         string                      `json:"language"`
+	Type             string                      `json:"type"`
+	InstallCommand   string                      `json:"install_command"`
+	BuildCommand     string                      `json:"build_command"`
+	RunCommand       string                      `json:"run_command"`
+	RequireDB        bool                        `json:"require_db"`
+	MigrationCommand string                      `json:"migration_command,omitempty"`
+	EnvVars          []*TemplateVariableResponse `json:"env_vars"`
+}
+
+// TemplateVariableResponse represents an environment variable a template pre-fills
+type TemplateVariableResponse struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"` // Default value, empty if the user supplies it
+	Description string `json:"description,omitempty"`
+	Scope       string `json:"scope"`    // BUILD, RUNTIME or BOTH
+	Required    bool   `json:"required"` // Whether projects can't be created without a value
+}
+
+// TemplateListResponse represents the template catalog
+type TemplateListResponse struct {
+	Templates []*TemplateResponse `json:"templates"`
+}
+
+// CreateProjectFromTemplateRequest represents the request to create a project from a template
+type CreateProjectFromTemplateRequest struct {
+	RepositoryName string            `json:"repository_name" binding:"omitempty,max=100"` // Optional - copies the template repository into a repository of this name in the user's GitHub account, the project deploys the template repository itself otherwise
+	Private        bool              `json:"private"`                                     // Whether the copied repository is private
+	CustomDomain   string            `json:"custom_domain"`                               // Optional - will auto-generate if empty
+	EnvVars        map[string]string `json:"env_vars" binding:"omitempty,max=100"`        // Optional - values of the template's environment variables, and further variables to set
+}
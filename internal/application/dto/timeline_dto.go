@@ -0,0 +1,19 @@
+package dto
+
+// TimelineEntryResponse represents a single event in a deployment's timeline
+type TimelineEntryResponse struct {
+	Timestamp string `json:"timestamp"`
+	Source    string `json:"source"`
+	Type      string `json:"type"`
+	Message   string `json:"message"`
+}
+
+// DeploymentTimelineResponse represents the chronological history of a deployment across all of its sources.
+// Warnings name the sources that could not be read; their entries are missing from the timeline.
+type DeploymentTimelineResponse struct {
+	DeploymentID string                   `json:"deployment_id"`
+	ProjectID    string                   `json:"project_id"`
+	Status       string                   `json:"status"`
+	Entries      []*TimelineEntryResponse `json:"entries"`
+	Warnings     []string                 `json:"warnings,omitempty"`
+}
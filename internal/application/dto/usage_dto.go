@@ -0,0 +1,27 @@
+package dto
+
+// UsageTotals represents metered usage and its estimated cost
+type UsageTotals struct {
+	VCPUHours        float64 `json:"vcpu_hours"`
+	MemoryGBHours    float64 `json:"memory_gb_hours"`
+	BuildMinutes     float64 `json:"build_minutes"`
+	EgressGB         float64 `json:"egress_gb"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
+}
+
+// ProjectUsageResponse represents a project's usage summary
+type ProjectUsageResponse struct {
+	ProjectID   string `json:"project_id"`
+	PeriodStart string `json:"period_start"`
+	PeriodEnd   string `json:"period_end"`
+	UsageTotals
+}
+
+// UserUsageResponse represents a user's usage summary across all of their projects
+type UserUsageResponse struct {
+	UserID      string                  `json:"user_id"`
+	PeriodStart string                  `json:"period_start"`
+	PeriodEnd   string                  `json:"period_end"`
+	Projects    []*ProjectUsageResponse `json:"projects"`
+	UsageTotals
+}
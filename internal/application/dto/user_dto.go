@@ -33,8 +33,26 @@ type UserListResponse struct {
 
 // PaginationResponse represents pagination metadata
 type PaginationResponse struct {
-	Page       int32 `json:"page"`
-	Limit      int32 `json:"limit"`
-	Total      int64 `json:"total"`
-	TotalPages int64 `json:"total_pages"`
+	Page       int32  `json:"page,omitempty"` // Not set for lists continued from a cursor
+	Limit      int32  `json:"limit"`
+	Total      int64  `json:"total"`
+	TotalPages int64  `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"` // Continues the list after this page, empty on its last page
+}
+
+// UserDataExport represents an archive of everything stored about a user
+type UserDataExport struct {
+	ExportedAt          string                        `json:"exported_at"`
+	User                *UserResponse                 `json:"user"`
+	Repositories        []*RepositoryResponse         `json:"repositories"`
+	GitHubInstallations []*GitHubInstallationResponse `json:"github_installations"`
+	Projects            []*ProjectDataExport          `json:"projects"`
+}
+
+// ProjectDataExport represents a project of a user data export with its environment variables (values masked)
+// and deployments
+type ProjectDataExport struct {
+	Project              *ProjectResponse      `json:"project"`
+	EnvironmentVariables []*EnvVarResponse     `json:"environment_variables"`
+	Deployments          []*DeploymentResponse `json:"deployments"`
 }
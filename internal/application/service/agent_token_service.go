@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/agent"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// AgentTokenService manages the tokens users' self-hosted build agents authenticate with. Agents presenting a
+// user's token are only handed the builds of that user's projects.
+type AgentTokenService struct {
+	tokenRepo agent.TokenRepository
+}
+
+// NewAgentTokenService creates a new agent token service
+func NewAgentTokenService(tokenRepo agent.TokenRepository) *AgentTokenService {
+	return &AgentTokenService{tokenRepo: tokenRepo}
+}
+
+// CreateToken creates a token for the user's agents. The secret is only returned here.
+func (s *AgentTokenService) CreateToken(ctx context.Context, userID string, req *dto.CreateAgentTokenRequest) (*dto.CreateAgentTokenResponse, error) {
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	token, secret, err := agent.NewToken(uid, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokenRepo.Save(ctx, token); err != nil {
+		return nil, err
+	}
+
+	return &dto.CreateAgentTokenResponse{
+		AgentToken: s.toDTO(token),
+		Token:      secret,
+	}, nil
+}
+
+// ListTokens lists the user's agent tokens, newest first
+func (s *AgentTokenService) ListTokens(ctx context.Context, userID string) (*dto.AgentTokenListResponse, error) {
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	tokens, err := s.tokenRepo.FindByUserID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.AgentTokenListResponse{AgentTokens: make([]*dto.AgentTokenResponse, 0, len(tokens))}
+	for _, token := range tokens {
+		response.AgentTokens = append(response.AgentTokens, s.toDTO(token))
+	}
+	return response, nil
+}
+
+// RevokeToken revokes one of the user's agent tokens. Agents presenting it are refused from their next call.
+func (s *AgentTokenService) RevokeToken(ctx context.Context, userID, tokenID string) error {
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+	id, err := agent.ParseTokenID(tokenID)
+	if err != nil {
+		return err
+	}
+
+	return s.tokenRepo.Delete(ctx, id, uid)
+}
+
+// AuthenticateAgent returns the token an agent presented and the user whose builds it runs.
+// Returns agent.ErrTokenNotFound for tokens that don't exist or were revoked.
+func (s *AgentTokenService) AuthenticateAgent(ctx context.Context, secret string) (tokenID, userID string, err error) {
+	token, err := s.tokenRepo.FindByHash(ctx, agent.HashToken(secret))
+	if err != nil {
+		return "", "", err
+	}
+	return token.ID().String(), token.UserID().String(), nil
+}
+
+// toDTO converts a domain agent token to DTO
+func (s *AgentTokenService) toDTO(token *agent.Token) *dto.AgentTokenResponse {
+	return &dto.AgentTokenResponse{
+		ID:        token.ID().String(),
+		Name:      token.Name(),
+		CreatedAt: token.CreatedAt().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,54 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/agent"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestAgentTokenService_AuthenticateAgent(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockAgentTokenRepository()
+	svc := service.NewAgentTokenService(repo)
+	owner, other := user.NewUserID(), user.NewUserID()
+
+	created, err := svc.CreateToken(ctx, owner.String(), &dto.CreateAgentTokenRequest{Name: "build-01"})
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+	if !strings.HasPrefix(created.Token, agent.TokenPrefix) {
+		t.Errorf("CreateToken() secret = %q, want the %s prefix", created.Token, agent.TokenPrefix)
+	}
+	for hash := range repo.tokens {
+		if strings.Contains(hash, created.Token) {
+			t.Errorf("the secret was stored instead of its hash")
+		}
+	}
+
+	tokenID, userID, err := svc.AuthenticateAgent(ctx, created.Token)
+	if err != nil {
+		t.Fatalf("AuthenticateAgent() error = %v", err)
+	}
+	if tokenID != created.AgentToken.ID || userID != owner.String() {
+		t.Errorf("AuthenticateAgent() = %s, %s, want the token of %s", tokenID, userID, owner)
+	}
+	if _, _, err := svc.AuthenticateAgent(ctx, created.Token+"0"); !errors.Is(err, agent.ErrTokenNotFound) {
+		t.Errorf("AuthenticateAgent() with another secret error = %v, want ErrTokenNotFound", err)
+	}
+
+	if err := svc.RevokeToken(ctx, other.String(), tokenID); !errors.Is(err, agent.ErrTokenNotFound) {
+		t.Errorf("RevokeToken() by another user error = %v, want ErrTokenNotFound", err)
+	}
+	if err := svc.RevokeToken(ctx, owner.String(), tokenID); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+	if _, _, err := svc.AuthenticateAgent(ctx, created.Token); !errors.Is(err, agent.ErrTokenNotFound) {
+		t.Errorf("AuthenticateAgent() after the revocation error = %v, want ErrTokenNotFound", err)
+	}
+}
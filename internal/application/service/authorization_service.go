@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// AuthorizationService decides whether a user may access the users, projects and deployments
+// addressed by ID in request paths. Resources belong to the user who created them.
+type AuthorizationService struct {
+	userRepo       user.Repository
+	projectRepo    project.ProjectRepository
+	deploymentRepo deployment.DeploymentRepository
+}
+
+// NewAuthorizationService creates a new authorization service
+func NewAuthorizationService(
+	userRepo user.Repository,
+	projectRepo project.ProjectRepository,
+	deploymentRepo deployment.DeploymentRepository,
+) *AuthorizationService {
+	return &AuthorizationService{
+		userRepo:       userRepo,
+		projectRepo:    projectRepo,
+		deploymentRepo: deploymentRepo,
+	}
+}
+
+// CanAccessUser checks if the Clerk user is the user with the given ID
+func (s *AuthorizationService) CanAccessUser(ctx context.Context, clerkUserID, userID string) (bool, error) {
+	caller, err := s.findCaller(ctx, clerkUserID)
+	if err != nil || caller == nil {
+		return false, err
+	}
+
+	return caller.ID().String() == userID, nil
+}
+
+// CanAccessProject checks if the Clerk user owns the project.
+// Returns project.ErrProjectNotFound if there is no such project.
+func (s *AuthorizationService) CanAccessProject(ctx context.Context, clerkUserID, projectID string) (bool, error) {
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return false, project.ErrProjectNotFound
+	}
+
+	proj, err := s.projectRepo.FindByID(ctx, pid)
+	if err != nil {
+		return false, err
+	}
+
+	caller, err := s.findCaller(ctx, clerkUserID)
+	if err != nil || caller == nil {
+		return false, err
+	}
+
+	return proj.BelongsToUser(caller.ID()), nil
+}
+
+// CanAccessDeployment checks if the Clerk user owns the deployment, archived or not.
+// Returns deployment.ErrDeploymentNotFound if there is no such deployment.
+func (s *AuthorizationService) CanAccessDeployment(ctx context.Context, clerkUserID, deploymentID string) (bool, error) {
+	did, err := deployment.ParseDeploymentID(deploymentID)
+	if err != nil {
+		return false, deployment.ErrDeploymentNotFound
+	}
+
+	dep, err := s.deploymentRepo.FindByID(ctx, did)
+	if err != nil {
+		return false, err
+	}
+
+	caller, err := s.findCaller(ctx, clerkUserID)
+	if err != nil || caller == nil {
+		return false, err
+	}
+
+	return dep.BelongsToUser(caller.ID()), nil
+}
+
+// findCaller retrieves the user signed in with a Clerk ID, or nil if they have not been created yet
+// and so own nothing
+func (s *AuthorizationService) findCaller(ctx context.Context, clerkUserID string) (*user.User, error) {
+	clerkID, err := user.NewClerkUserID(clerkUserID)
+	if err != nil {
+		return nil, nil
+	}
+
+	caller, err := s.userRepo.FindByClerkID(ctx, clerkID)
+	if err != nil {
+		var domainErr *user.DomainError
+		if errors.As(err, &domainErr) && domainErr.Code == "USER_NOT_FOUND" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return caller, nil
+}
@@ -0,0 +1,83 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+
+	"github.com/google/uuid"
+)
+
+func TestAuthorizationService_OwnerOnly(t *testing.T) {
+	ctx := context.Background()
+
+	users := newMockUserRepository()
+	owner, err := user.NewUser("owner@example.com", "owner", "user_owner")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	other, err := user.NewUser("other@example.com", "other", "user_other")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	users.Save(ctx, owner)
+	users.Save(ctx, other)
+
+	proj := newTestProject(t, owner.ID(), "my-app")
+	dep := newTestDeployment(t, proj, project.EnvironmentProduction, deployment.StatusPending)
+
+	svc := service.NewAuthorizationService(
+		users,
+		newMockProjectRepository(proj),
+		newMockDeploymentRepository(dep),
+	)
+
+	checks := []struct {
+		name  string
+		check func(ctx context.Context, clerkUserID, id string) (bool, error)
+		id    string
+	}{
+		{"user", svc.CanAccessUser, owner.ID().String()},
+		{"project", svc.CanAccessProject, proj.ID().String()},
+		{"deployment", svc.CanAccessDeployment, dep.ID().String()},
+	}
+
+	for _, tt := range checks {
+		t.Run(tt.name, func(t *testing.T) {
+			if allowed, err := tt.check(ctx, "user_owner", tt.id); err != nil || !allowed {
+				t.Errorf("owner access = %v, %v; want true, nil", allowed, err)
+			}
+			if allowed, err := tt.check(ctx, "user_other", tt.id); err != nil || allowed {
+				t.Errorf("other user access = %v, %v; want false, nil", allowed, err)
+			}
+			// Users who have never signed in before own nothing
+			if allowed, err := tt.check(ctx, "user_new", tt.id); err != nil || allowed {
+				t.Errorf("unknown user access = %v, %v; want false, nil", allowed, err)
+			}
+		})
+	}
+}
+
+func TestAuthorizationService_NotFound(t *testing.T) {
+	ctx := context.Background()
+	svc := service.NewAuthorizationService(
+		newMockUserRepository(),
+		newMockProjectRepository(),
+		newMockDeploymentRepository(),
+	)
+
+	if _, err := svc.CanAccessProject(ctx, "user_a", uuid.NewString()); !errors.Is(err, project.ErrProjectNotFound) {
+		t.Errorf("CanAccessProject(missing) error = %v, want ErrProjectNotFound", err)
+	}
+	if _, err := svc.CanAccessProject(ctx, "user_a", "not-a-uuid"); !errors.Is(err, project.ErrProjectNotFound) {
+		t.Errorf("CanAccessProject(invalid ID) error = %v, want ErrProjectNotFound", err)
+	}
+	if _, err := svc.CanAccessDeployment(ctx, "user_a", uuid.NewString()); !errors.Is(err, deployment.ErrDeploymentNotFound) {
+		t.Errorf("CanAccessDeployment(missing) error = %v, want ErrDeploymentNotFound", err)
+	}
+}
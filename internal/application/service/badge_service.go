@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+)
+
+// ErrInvalidBadgeToken is returned when a badge is requested without the token signed for its project,
+// so badges can't be used to find out which project IDs exist
+var ErrInvalidBadgeToken = errors.New("invalid badge token")
+
+// badgeTokenLength is the length of badge tokens, the hex digest is truncated to keep badge URLs short
+const badgeTokenLength = 32
+
+// BadgeSigner signs the tokens of badge URLs with a key only the server knows
+type BadgeSigner interface {
+	Digest(plaintext string) string
+}
+
+// badgeColors are the background colors of the deployment statuses on badges
+var badgeColors = map[deployment.DeploymentStatus]string{
+	deployment.StatusDeployed:   "#4c1",
+	deployment.StatusFailed:     "#e05d44",
+	deployment.StatusRolledBack: "#fe7d37",
+	deployment.StatusRejected:   "#9f9f9f",
+}
+
+// badgeInProgressColor is the background color of deployments still in progress
+const badgeInProgressColor = "#dfb317"
+
+// BadgeService renders the status of projects' latest deployment for public badges
+type BadgeService struct {
+	deploymentRepo deployment.DeploymentRepository
+	projectRepo    project.ProjectRepository
+	signer         BadgeSigner
+}
+
+// NewBadgeService creates a new badge service
+func NewBadgeService(
+	deploymentRepo deployment.DeploymentRepository,
+	projectRepo project.ProjectRepository,
+	signer BadgeSigner,
+) *BadgeService {
+	return &BadgeService{
+		deploymentRepo: deploymentRepo,
+		projectRepo:    projectRepo,
+		signer:         signer,
+	}
+}
+
+// GetProjectBadge returns the signed URL of a project's status badge under baseURL, the scheme and host the
+// API is reached at
+func (s *BadgeService) GetProjectBadge(ctx context.Context, projectID, baseURL string) (*dto.ProjectBadgeResponse, error) {
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+	proj, err := s.projectRepo.FindByID(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	imageURL := fmt.Sprintf("%s/api/v1/badges/projects/%s/status.svg?token=%s",
+		strings.TrimSuffix(baseURL, "/"), proj.ID().String(), s.badgeToken(proj.ID()))
+	return &dto.ProjectBadgeResponse{
+		ImageURL: imageURL,
+		Markdown: fmt.Sprintf("![deployment status](%s)", imageURL),
+	}, nil
+}
+
+// GetBadgeStatus returns the status of the project's latest deployment, of the branch if it isn't empty.
+// Returns ErrInvalidBadgeToken unless the token was signed for the project.
+func (s *BadgeService) GetBadgeStatus(ctx context.Context, projectID, token, branch string) (*dto.BadgeStatus, error) {
+	// Unknown projects and wrong tokens are told apart only after the token is checked
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, ErrInvalidBadgeToken
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.badgeToken(pid))) != 1 {
+		return nil, ErrInvalidBadgeToken
+	}
+
+	deployments, err := s.deploymentRepo.FindByProjectID(ctx, pid, deployment.ListFilter{Branch: branch}, 1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find latest deployment: %w", err)
+	}
+	if len(deployments) == 0 {
+		return &dto.BadgeStatus{Label: "deploy", Message: "no deployments", Color: "#9f9f9f"}, nil
+	}
+
+	status := deployments[0].Status()
+	color, ok := badgeColors[status]
+	if !ok {
+		color = badgeInProgressColor
+	}
+	return &dto.BadgeStatus{
+		Label:   "deploy",
+		Message: strings.ReplaceAll(strings.ToLower(status.String()), "_", " "),
+		Color:   color,
+	}, nil
+}
+
+// badgeToken signs the ID of a project for its badge URLs
+func (s *BadgeService) badgeToken(id project.ProjectID) string {
+	return s.signer.Digest("badge:" + id.String())[:badgeTokenLength]
+}
@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// mockBadgeSigner stands in for the keyed digest, distinct plaintexts get distinct digests
+type mockBadgeSigner struct{}
+
+func (mockBadgeSigner) Digest(plaintext string) string {
+	return strings.Repeat("0", 64-len(plaintext)%64) + plaintext
+}
+
+// badgeToken returns the token of the project's badge URL
+func badgeToken(t *testing.T, svc *service.BadgeService, proj *project.Project) string {
+	t.Helper()
+	badge, err := svc.GetProjectBadge(context.Background(), proj.ID().String(), "https://api.snapdeploy.app/")
+	if err != nil {
+		t.Fatalf("GetProjectBadge() error = %v", err)
+	}
+	prefix := "https://api.snapdeploy.app/api/v1/badges/projects/" + proj.ID().String() + "/status.svg?token="
+	if !strings.HasPrefix(badge.ImageURL, prefix) {
+		t.Fatalf("ImageURL = %q, want prefix %q", badge.ImageURL, prefix)
+	}
+	if !strings.Contains(badge.Markdown, badge.ImageURL) {
+		t.Errorf("Markdown = %q, want it to embed the image URL", badge.Markdown)
+	}
+	return strings.TrimPrefix(badge.ImageURL, prefix)
+}
+
+func TestBadgeService_GetBadgeStatus_RejectsInvalidTokens(t *testing.T) {
+	proj := newTestProject(t, user.NewUserID(), "my-app")
+	deployments := newMockDeploymentRepository()
+	svc := service.NewBadgeService(deployments, newMockProjectRepository(proj), mockBadgeSigner{})
+	token := badgeToken(t, svc, proj)
+
+	other := newTestProject(t, user.NewUserID(), "other")
+	tests := []struct {
+		name      string
+		projectID string
+		token     string
+	}{
+		{"missing token", proj.ID().String(), ""},
+		{"wrong token", proj.ID().String(), strings.Repeat("f", len(token))},
+		{"token of another project", other.ID().String(), token},
+		{"invalid project ID", "not-a-uuid", token},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.GetBadgeStatus(context.Background(), tt.projectID, tt.token, "")
+			if !errors.Is(err, service.ErrInvalidBadgeToken) {
+				t.Errorf("GetBadgeStatus() error = %v, want ErrInvalidBadgeToken", err)
+			}
+		})
+	}
+	if deployments.lists != 0 {
+		t.Errorf("deployments were looked up %d times for invalid tokens", deployments.lists)
+	}
+}
+
+func TestBadgeService_GetBadgeStatus(t *testing.T) {
+	proj := newTestProject(t, user.NewUserID(), "my-app")
+	deployments := newMockDeploymentRepository()
+	svc := service.NewBadgeService(deployments, newMockProjectRepository(proj), mockBadgeSigner{})
+	token := badgeToken(t, svc, proj)
+
+	status, err := svc.GetBadgeStatus(context.Background(), proj.ID().String(), token, "")
+	if err != nil {
+		t.Fatalf("GetBadgeStatus() error = %v", err)
+	}
+	if status.Message != "no deployments" {
+		t.Errorf("Message = %q, want no deployments", status.Message)
+	}
+
+	building := newTestDeployment(t, proj, project.EnvironmentProduction, deployment.StatusBuilding)
+	failed, _ := deployment.NewDeployment(proj.ID(), proj.UserID(), "def5678", "feature", project.EnvironmentProduction)
+	failed.UpdateStatus(deployment.StatusBuilding)
+	failed.UpdateStatus(deployment.StatusFailed)
+	deployments.deployments = []*deployment.Deployment{building, failed}
+
+	tests := []struct {
+		branch      string
+		wantMessage string
+		wantColor   string
+	}{
+		{"", "failed", "#e05d44"},
+		{"main", "building", "#dfb317"},
+		{"release", "no deployments", "#9f9f9f"},
+	}
+	for _, tt := range tests {
+		t.Run("branch "+tt.branch, func(t *testing.T) {
+			status, err := svc.GetBadgeStatus(context.Background(), proj.ID().String(), token, tt.branch)
+			if err != nil {
+				t.Fatalf("GetBadgeStatus() error = %v", err)
+			}
+			if status.Message != tt.wantMessage || status.Color != tt.wantColor {
+				t.Errorf("GetBadgeStatus() = %q %s, want %q %s", status.Message, status.Color, tt.wantMessage, tt.wantColor)
+			}
+		})
+	}
+}
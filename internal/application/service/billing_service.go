@@ -0,0 +1,420 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/billing"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/usage"
+	"snapdeploy-core/internal/domain/user"
+	"snapdeploy-core/internal/stripe"
+)
+
+// billingPageSize is how many users, accounts or projects billing reads at once
+const billingPageSize = 100
+
+// billedMetrics are the metrics reported to the payment provider
+var billedMetrics = []usage.Metric{usage.MetricBuildMinutes, usage.MetricVCPUHours}
+
+// PlanAssigner puts users on the plan their subscription entitles them to
+type PlanAssigner interface {
+	AssignPlan(ctx context.Context, userID user.UserID, plan string) error
+}
+
+// ServiceSuspender stops the running services of a project while its owner's payment is overdue and starts
+// them again once it is made
+type ServiceSuspender interface {
+	SuspendProject(ctx context.Context, proj *project.Project) error
+	ResumeProject(ctx context.Context, proj *project.Project) error
+}
+
+// BillingService bills users through Stripe: it makes users customers, reports their build minutes and
+// Fargate hours, keeps their plan in line with their subscription and suspends them when a payment fails
+type BillingService struct {
+	accountRepo billing.AccountRepository
+	userRepo    user.Repository
+	projectRepo project.ProjectRepository
+	usageRepo   usage.RecordRepository
+	plans       PlanAssigner
+	pricePlans  map[string]string // Quota plan of each Stripe price
+	provider    billing.Provider
+	suspender   ServiceSuspender
+}
+
+// NewBillingService creates a new billing service
+func NewBillingService(
+	accountRepo billing.AccountRepository,
+	userRepo user.Repository,
+	projectRepo project.ProjectRepository,
+	usageRepo usage.RecordRepository,
+	plans PlanAssigner,
+	pricePlans map[string]string,
+) *BillingService {
+	return &BillingService{
+		accountRepo: accountRepo,
+		userRepo:    userRepo,
+		projectRepo: projectRepo,
+		usageRepo:   usageRepo,
+		plans:       plans,
+		pricePlans:  pricePlans,
+	}
+}
+
+// SetBillingProvider sets the payment provider users are billed through (optional)
+func (s *BillingService) SetBillingProvider(provider billing.Provider) {
+	s.provider = provider
+}
+
+// SetServiceSuspender sets what stops the services of users whose payment failed (optional)
+func (s *BillingService) SetServiceSuspender(suspender ServiceSuspender) {
+	s.suspender = suspender
+}
+
+// Enabled reports whether a payment provider is configured
+func (s *BillingService) Enabled() bool {
+	return s.provider != nil
+}
+
+// CheckAccount returns ErrAccountSuspended if a payment of the user failed and isn't made yet
+func (s *BillingService) CheckAccount(ctx context.Context, userID user.UserID) error {
+	account, err := s.accountRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, billing.ErrAccountNotFound) {
+			return nil
+		}
+		return err
+	}
+	if account.IsSuspended() {
+		return billing.ErrAccountSuspended
+	}
+	return nil
+}
+
+// GetPortal returns a link to the Stripe customer portal of a user, making them a customer first if needed
+func (s *BillingService) GetPortal(ctx context.Context, userID string) (*dto.BillingPortalResponse, error) {
+	if s.provider == nil {
+		return nil, billing.ErrBillingDisabled
+	}
+
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	account, err := s.SyncCustomer(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := s.provider.CreatePortalSession(ctx, account.CustomerID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create portal session: %w", err)
+	}
+
+	return &dto.BillingPortalResponse{
+		URL:       url,
+		Plan:      account.Plan(),
+		Suspended: account.IsSuspended(),
+	}, nil
+}
+
+// SyncCustomer returns the billing account of a user, creating their customer if they have none
+func (s *BillingService) SyncCustomer(ctx context.Context, userID user.UserID) (*billing.Account, error) {
+	if s.provider == nil {
+		return nil, billing.ErrBillingDisabled
+	}
+
+	account, err := s.accountRepo.FindByUserID(ctx, userID)
+	if err == nil || !errors.Is(err, billing.ErrAccountNotFound) {
+		return account, err
+	}
+
+	usr, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	customerID, err := s.provider.CreateCustomer(ctx, userID, usr.Email().String(), usr.Username().String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create customer: %w", err)
+	}
+
+	account, err = billing.NewAccount(userID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.accountRepo.Save(ctx, account); err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Created billing customer", "user_id", userID.String(), "customer_id", customerID)
+	return account, nil
+}
+
+// SyncCustomers makes every user without a billing account a customer. A batch with failures stops the sync,
+// those users are tried again on the next one.
+func (s *BillingService) SyncCustomers(ctx context.Context) (int, error) {
+	created := 0
+	for {
+		userIDs, err := s.accountRepo.FindUnbilledUserIDs(ctx, billingPageSize)
+		if err != nil {
+			return created, err
+		}
+
+		var errs []error
+		for _, userID := range userIDs {
+			if _, err := s.SyncCustomer(ctx, userID); err != nil {
+				errs = append(errs, fmt.Errorf("user %s: %w", userID, err))
+				continue
+			}
+			created++
+		}
+		if len(errs) > 0 {
+			return created, errors.Join(errs...)
+		}
+
+		if len(userIDs) < billingPageSize {
+			return created, nil
+		}
+	}
+}
+
+// ReportUsage reports the build minutes and Fargate vCPU hours every customer used since they were last reported
+func (s *BillingService) ReportUsage(ctx context.Context, now time.Time) error {
+	if s.provider == nil {
+		return billing.ErrBillingDisabled
+	}
+
+	var errs []error
+	for offset := int32(0); ; offset += billingPageSize {
+		accounts, err := s.accountRepo.List(ctx, billingPageSize, offset)
+		if err != nil {
+			return err
+		}
+
+		for _, account := range accounts {
+			if err := s.reportAccountUsage(ctx, account, now); err != nil {
+				errs = append(errs, fmt.Errorf("customer %s: %w", account.CustomerID(), err))
+			}
+		}
+
+		if len(accounts) < billingPageSize {
+			break
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// reportAccountUsage reports each billed metric of an account in whole units, carrying fractions over. Each
+// metric's progress is saved once reported, so a failure never reports a metric twice.
+func (s *BillingService) reportAccountUsage(ctx context.Context, account *billing.Account, now time.Time) error {
+	for _, metric := range billedMetrics {
+		meter, err := account.Meter(metric)
+		if err != nil {
+			return err
+		}
+		if !now.After(meter.ReportedUntil) {
+			continue
+		}
+
+		byProject, err := s.usageRepo.SumByUserID(ctx, account.UserID(), meter.ReportedUntil, now)
+		if err != nil {
+			return fmt.Errorf("failed to sum usage: %w", err)
+		}
+		var quantity float64
+		for _, totals := range byProject {
+			quantity += totals[metric]
+		}
+
+		whole, carry := meter.Split(quantity)
+		if whole > 0 {
+			identifier := fmt.Sprintf("%s-%s-%d", account.UserID(), metric, now.Unix())
+			if err := s.provider.ReportUsage(ctx, account.CustomerID(), metric, whole, identifier, now); err != nil {
+				return fmt.Errorf("failed to report %s: %w", metric, err)
+			}
+		}
+
+		if err := account.Reported(metric, now, carry); err != nil {
+			return err
+		}
+		if err := s.accountRepo.Save(ctx, account); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RunSync makes new users customers and reports usage on every interval until the context is cancelled
+func (s *BillingService) RunSync(ctx context.Context, interval time.Duration) {
+	if s.provider == nil || interval <= 0 {
+		slog.InfoContext(ctx, "Billing sync disabled", "interval", interval.String())
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			created, err := s.SyncCustomers(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "Billing customer sync failed", "error", err)
+			}
+			if created > 0 {
+				slog.InfoContext(ctx, "Created billing customers", "count", created)
+			}
+			if err := s.ReportUsage(ctx, now); err != nil {
+				slog.ErrorContext(ctx, "Usage reporting failed", "error", err)
+			}
+		}
+	}
+}
+
+// HandleEvent applies a Stripe webhook event: subscriptions set the plan of their customer, failed payments
+// suspend them and paid invoices reinstate them. Events of customers SnapDeploy didn't create are ignored.
+func (s *BillingService) HandleEvent(ctx context.Context, event *stripe.Event) error {
+	if s.provider == nil {
+		return billing.ErrBillingDisabled
+	}
+
+	switch event.Type {
+	case stripe.EventSubscriptionCreated, stripe.EventSubscriptionUpdated, stripe.EventSubscriptionDeleted:
+		var subscription stripe.Subscription
+		if err := json.Unmarshal(event.Data.Object, &subscription); err != nil {
+			return fmt.Errorf("invalid subscription: %w", err)
+		}
+		return s.handleSubscription(ctx, event.Type, &subscription)
+
+	case stripe.EventInvoicePaymentFailed, stripe.EventInvoicePaid:
+		var invoice stripe.Invoice
+		if err := json.Unmarshal(event.Data.Object, &invoice); err != nil {
+			return fmt.Errorf("invalid invoice: %w", err)
+		}
+		return s.handleInvoice(ctx, event.Type, &invoice)
+	}
+
+	return nil
+}
+
+// handleSubscription puts the customer on the plan of their subscription's price, or back on the default plan
+// once it ends
+func (s *BillingService) handleSubscription(ctx context.Context, eventType string, subscription *stripe.Subscription) error {
+	account, ok, err := s.eventAccount(ctx, subscription.Customer)
+	if !ok {
+		return err
+	}
+
+	switch {
+	case eventType != stripe.EventSubscriptionDeleted && subscription.Entitled():
+		account.Subscribe(subscription.ID, s.planFor(ctx, subscription))
+	case subscription.ID == account.SubscriptionID():
+		account.Unsubscribe()
+	default:
+		return nil // Another subscription of the customer ended
+	}
+
+	if err := s.plans.AssignPlan(ctx, account.UserID(), account.Plan()); err != nil {
+		return fmt.Errorf("failed to assign plan: %w", err)
+	}
+	if err := s.accountRepo.Save(ctx, account); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Billing plan changed", "user_id", account.UserID().String(), "plan", account.Plan(), "subscription_status", subscription.Status)
+	return nil
+}
+
+// planFor returns the plan the first configured price of a subscription entitles to, the default plan if none is
+func (s *BillingService) planFor(ctx context.Context, subscription *stripe.Subscription) string {
+	for _, price := range subscription.PriceIDs() {
+		if plan, ok := s.pricePlans[price]; ok {
+			return plan
+		}
+	}
+	slog.WarnContext(ctx, "Subscription has no price mapped to a plan", "subscription_id", subscription.ID, "prices", subscription.PriceIDs())
+	return ""
+}
+
+// handleInvoice suspends the customer when a payment fails and reinstates them once an invoice is paid. The
+// services are stopped or started before the account is saved, so Stripe retrying a failed event does it again.
+func (s *BillingService) handleInvoice(ctx context.Context, eventType string, invoice *stripe.Invoice) error {
+	account, ok, err := s.eventAccount(ctx, invoice.Customer)
+	if !ok {
+		return err
+	}
+
+	suspend := eventType == stripe.EventInvoicePaymentFailed
+	if suspend && !account.Suspend() || !suspend && !account.Reinstate() {
+		return nil
+	}
+
+	if err := s.suspendProjects(ctx, account.UserID(), suspend); err != nil {
+		return err
+	}
+	if err := s.accountRepo.Save(ctx, account); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Billing account status changed", "user_id", account.UserID().String(), "status", account.Status().String(), "invoice_id", invoice.ID)
+	return nil
+}
+
+// eventAccount returns the account of an event's customer, and false without an error for customers
+// SnapDeploy didn't create
+func (s *BillingService) eventAccount(ctx context.Context, customerID string) (*billing.Account, bool, error) {
+	account, err := s.accountRepo.FindByCustomerID(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, billing.ErrAccountNotFound) {
+			slog.WarnContext(ctx, "Ignoring billing event of an unknown customer", "customer_id", customerID)
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return account, true, nil
+}
+
+// suspendProjects stops or starts again the services of every project of a user
+func (s *BillingService) suspendProjects(ctx context.Context, userID user.UserID, suspend bool) error {
+	if s.suspender == nil {
+		return nil
+	}
+
+	var errs []error
+	for offset := int32(0); ; offset += billingPageSize {
+		projects, err := s.projectRepo.FindByUserID(ctx, userID, billingPageSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to list projects: %w", err)
+		}
+
+		for _, proj := range projects {
+			if suspend {
+				err = s.suspender.SuspendProject(ctx, proj)
+			} else {
+				err = s.suspender.ResumeProject(ctx, proj)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("project %s: %w", proj.ID(), err))
+			}
+		}
+
+		if len(projects) < billingPageSize {
+			break
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to suspend or resume projects: %w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/billing"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/usage"
+	"snapdeploy-core/internal/domain/user"
+	"snapdeploy-core/internal/stripe"
+)
+
+// mockBillingAccounts keeps billing accounts in memory
+type mockBillingAccounts struct {
+	billing.AccountRepository
+	accounts []*billing.Account
+}
+
+func (m *mockBillingAccounts) FindByUserID(ctx context.Context, userID user.UserID) (*billing.Account, error) {
+	for _, account := range m.accounts {
+		if account.UserID() == userID {
+			return account, nil
+		}
+	}
+	return nil, billing.ErrAccountNotFound
+}
+
+func (m *mockBillingAccounts) FindByCustomerID(ctx context.Context, customerID string) (*billing.Account, error) {
+	for _, account := range m.accounts {
+		if account.CustomerID() == customerID {
+			return account, nil
+		}
+	}
+	return nil, billing.ErrAccountNotFound
+}
+
+func (m *mockBillingAccounts) List(ctx context.Context, limit, offset int32) ([]*billing.Account, error) {
+	if int(offset) >= len(m.accounts) {
+		return nil, nil
+	}
+	return m.accounts[offset:min(int(offset+limit), len(m.accounts))], nil
+}
+
+func (m *mockBillingAccounts) Save(ctx context.Context, account *billing.Account) error {
+	if _, err := m.FindByUserID(ctx, account.UserID()); err != nil {
+		m.accounts = append(m.accounts, account)
+	}
+	return nil
+}
+
+// mockBillingProvider records the usage reported to it
+type mockBillingProvider struct {
+	billing.Provider
+	reported map[usage.Metric]int64
+}
+
+func (m *mockBillingProvider) ReportUsage(ctx context.Context, customerID string, metric usage.Metric, quantity int64, identifier string, at time.Time) error {
+	m.reported[metric] += quantity
+	return nil
+}
+
+// mockPlanAssigner records the plan assigned to each user
+type mockPlanAssigner struct {
+	plans map[user.UserID]string
+}
+
+func (m *mockPlanAssigner) AssignPlan(ctx context.Context, userID user.UserID, plan string) error {
+	m.plans[userID] = plan
+	return nil
+}
+
+// mockServiceSuspender records which projects have their services stopped
+type mockServiceSuspender struct {
+	suspended map[project.ProjectID]bool
+}
+
+func (m *mockServiceSuspender) SuspendProject(ctx context.Context, proj *project.Project) error {
+	m.suspended[proj.ID()] = true
+	return nil
+}
+
+func (m *mockServiceSuspender) ResumeProject(ctx context.Context, proj *project.Project) error {
+	m.suspended[proj.ID()] = false
+	return nil
+}
+
+// newBillingService returns a billing service for the owner of account and projects, with the usage mocked
+// by mockQuotaUsage and reported to provider
+func newBillingService(account *billing.Account, projects []*project.Project, plans *mockPlanAssigner, provider *mockBillingProvider) *service.BillingService {
+	svc := service.NewBillingService(
+		&mockBillingAccounts{accounts: []*billing.Account{account}},
+		newMockUserRepository(),
+		newMockProjectRepository(projects...),
+		&mockQuotaUsage{buildMinutes: 2.5},
+		plans,
+		map[string]string{"price_pro": "pro"},
+	)
+	svc.SetBillingProvider(provider)
+	return svc
+}
+
+func billingEvent(t *testing.T, eventType string, object any) *stripe.Event {
+	t.Helper()
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	event := &stripe.Event{ID: "evt_1", Type: eventType}
+	event.Data.Object = data
+	return event
+}
+
+// newBillingAccount returns the billing account of a new user, Stripe customer cus_123
+func newBillingAccount(t *testing.T) *billing.Account {
+	t.Helper()
+	account, err := billing.NewAccount(user.NewUserID(), "cus_123")
+	if err != nil {
+		t.Fatalf("NewAccount() error = %v", err)
+	}
+	return account
+}
+
+func TestBillingService_PaymentFailureSuspends(t *testing.T) {
+	account := newBillingAccount(t)
+	projects := []*project.Project{newTestProject(t, account.UserID(), "shop"), newTestProject(t, account.UserID(), "blog")}
+	suspender := &mockServiceSuspender{suspended: map[project.ProjectID]bool{}}
+	svc := newBillingService(account, projects, &mockPlanAssigner{}, &mockBillingProvider{})
+	svc.SetServiceSuspender(suspender)
+	ctx := context.Background()
+	invoice := map[string]any{"id": "in_1", "customer": "cus_123"}
+
+	if err := svc.HandleEvent(ctx, billingEvent(t, stripe.EventInvoicePaymentFailed, invoice)); err != nil {
+		t.Fatalf("HandleEvent(payment_failed) error = %v", err)
+	}
+	for _, proj := range projects {
+		if !suspender.suspended[proj.ID()] {
+			t.Errorf("project %s was not suspended", proj.ID())
+		}
+	}
+	if err := svc.CheckAccount(ctx, account.UserID()); !errors.Is(err, billing.ErrAccountSuspended) {
+		t.Errorf("CheckAccount() error = %v, want ErrAccountSuspended", err)
+	}
+
+	if err := svc.HandleEvent(ctx, billingEvent(t, stripe.EventInvoicePaid, invoice)); err != nil {
+		t.Fatalf("HandleEvent(paid) error = %v", err)
+	}
+	for _, proj := range projects {
+		if suspender.suspended[proj.ID()] {
+			t.Errorf("project %s was not resumed", proj.ID())
+		}
+	}
+	if err := svc.CheckAccount(ctx, account.UserID()); err != nil {
+		t.Errorf("CheckAccount() error = %v after the invoice was paid, want nil", err)
+	}
+}
+
+func TestBillingService_UnknownCustomerIgnored(t *testing.T) {
+	account := newBillingAccount(t)
+	suspender := &mockServiceSuspender{suspended: map[project.ProjectID]bool{}}
+	svc := newBillingService(account, []*project.Project{newTestProject(t, account.UserID(), "shop")}, &mockPlanAssigner{}, &mockBillingProvider{})
+	svc.SetServiceSuspender(suspender)
+	invoice := map[string]any{"id": "in_1", "customer": "cus_other"}
+
+	if err := svc.HandleEvent(context.Background(), billingEvent(t, stripe.EventInvoicePaymentFailed, invoice)); err != nil {
+		t.Fatalf("HandleEvent() error = %v", err)
+	}
+	if len(suspender.suspended) != 0 || account.IsSuspended() {
+		t.Error("a payment failure of an unknown customer suspended the account")
+	}
+}
+
+func TestBillingService_SubscriptionAssignsPlan(t *testing.T) {
+	account := newBillingAccount(t)
+	plans := &mockPlanAssigner{plans: map[user.UserID]string{}}
+	svc := newBillingService(account, nil, plans, &mockBillingProvider{})
+	ctx := context.Background()
+	subscription := map[string]any{
+		"id":       "sub_1",
+		"customer": "cus_123",
+		"status":   "active",
+		"items":    map[string]any{"data": []any{map[string]any{"price": map[string]any{"id": "price_pro"}}}},
+	}
+
+	if err := svc.HandleEvent(ctx, billingEvent(t, stripe.EventSubscriptionCreated, subscription)); err != nil {
+		t.Fatalf("HandleEvent(created) error = %v", err)
+	}
+	if plan := plans.plans[account.UserID()]; plan != "pro" {
+		t.Errorf("plan = %q, want pro", plan)
+	}
+
+	if err := svc.HandleEvent(ctx, billingEvent(t, stripe.EventSubscriptionDeleted, subscription)); err != nil {
+		t.Fatalf("HandleEvent(deleted) error = %v", err)
+	}
+	if plan, ok := plans.plans[account.UserID()]; !ok || plan != "" {
+		t.Errorf("plan = %q after the subscription ended, want the default plan", plan)
+	}
+}
+
+func TestBillingService_ReportUsage(t *testing.T) {
+	account := newBillingAccount(t)
+	provider := &mockBillingProvider{reported: map[usage.Metric]int64{}}
+	svc := newBillingService(account, nil, &mockPlanAssigner{}, provider)
+
+	if err := svc.ReportUsage(context.Background(), time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("ReportUsage() error = %v", err)
+	}
+
+	// 2.5 build minutes are reported as 2 with half a minute carried to the next report
+	if got := provider.reported[usage.MetricBuildMinutes]; got != 2 {
+		t.Errorf("reported %d build minutes, want 2", got)
+	}
+	if got := provider.reported[usage.MetricVCPUHours]; got != 10 {
+		t.Errorf("reported %d vCPU hours, want 10", got)
+	}
+	meter, _ := account.Meter(usage.MetricBuildMinutes)
+	if meter.Carry != 0.5 {
+		t.Errorf("carried %v build minutes, want 0.5", meter.Carry)
+	}
+}
+
+func TestBillingService_Disabled(t *testing.T) {
+	svc := service.NewBillingService(&mockBillingAccounts{}, newMockUserRepository(), newMockProjectRepository(), &mockQuotaUsage{}, &mockPlanAssigner{}, nil)
+
+	if _, err := svc.GetPortal(context.Background(), user.NewUserID().String()); !errors.Is(err, billing.ErrBillingDisabled) {
+		t.Errorf("GetPortal() error = %v, want ErrBillingDisabled", err)
+	}
+	if err := svc.CheckAccount(context.Background(), user.NewUserID()); err != nil {
+		t.Errorf("CheckAccount() error = %v without an account, want nil", err)
+	}
+}
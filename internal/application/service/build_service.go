@@ -0,0 +1,735 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/domain/user"
+	"snapdeploy-core/internal/infrastructure/builder"
+	"snapdeploy-core/internal/infrastructure/repoconfig"
+	"snapdeploy-core/internal/logging"
+	"snapdeploy-core/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// buildJobPollInterval is how often an idle worker looks for queued builds
+	buildJobPollInterval = 2 * time.Second
+
+	// buildJobLease is how long a claimed job may take to start its build before another worker takes it over
+	buildJobLease = 10 * time.Minute
+
+	// maxBuildJobAttempts bounds how often a build is retried after its worker died
+	maxBuildJobAttempts = 3
+
+	// requeueTimeout bounds queueing the builds interrupted by a shutdown again, once the grace period is over
+	requeueTimeout = 10 * time.Second
+)
+
+// BuildLimits bounds how many builds run and wait at once
+type BuildLimits struct {
+	Workers              int // builds started at once by this instance
+	MaxConcurrentPerUser int // deployments building or deploying at once per user
+	MaxQueuedPerUser     int // deployments in progress per user before new ones are turned away
+	MaxQueued            int // builds waiting across all users before new deployments are turned away
+}
+
+// Default build limits, used for unset values
+const (
+	defaultBuildWorkers               = 4
+	defaultMaxConcurrentBuildsPerUser = 2
+	defaultMaxQueuedBuildsPerUser     = 5
+	defaultMaxQueuedBuilds            = 50
+)
+
+// ImageRepositoryManager provisions the per-project image repository before a build pushes to it
+type ImageRepositoryManager interface {
+	EnsureProjectRepository(ctx context.Context, projectID string) (string, error)
+	RepositoryURI(projectID string) string
+}
+
+// BuildVariableSource resolves the environment variables passed to a project's image build
+type BuildVariableSource interface {
+	DecryptBuild(ctx context.Context, projectID project.ProjectID, env project.Environment) (map[string]string, error)
+}
+
+// EnvVarDigestSource digests the values of a project's environment variables, so deployments can be compared
+// without storing the values
+type EnvVarDigestSource interface {
+	DigestValues(ctx context.Context, projectID project.ProjectID, env project.Environment) (map[string]string, error)
+}
+
+// RepositoryFileSource reads files of a project's repository without cloning it
+type RepositoryFileSource interface {
+	FetchRepositoryFile(ctx context.Context, proj *project.Project, ref, path string) ([]byte, error)
+}
+
+// BuildPlan is what a deployment would be built with, worked out without building it
+type BuildPlan struct {
+	Dockerfile string
+	ImageTag   string
+	BuildArgs  []string // Names of the environment variables passed to the build
+	EnvVars    []string // Names of the environment variables the deployment's processes run with
+	RepoConfig bool     // Whether the repository's snapdeploy.yaml would be applied
+	Problems   []string // Why the build would fail
+	Warnings   []string
+}
+
+// BuildService starts the builds queued by CreateDeployment.
+// Workers claim build jobs from the database, so a build queued before a restart is still started after it.
+type BuildService struct {
+	limits            BuildLimits
+	buildJobRepo      deployment.BuildJobRepository
+	deploymentRepo    deployment.DeploymentRepository
+	projectRepo       project.ProjectRepository
+	backend           builder.BuildBackend
+	templateGenerator *builder.TemplateGenerator
+	imageRepositories ImageRepositoryManager
+	buildVariables    BuildVariableSource
+	repositoryFiles   RepositoryFileSource
+	envVarRepo        project.EnvironmentVariableRepository
+	manifestRepo      deployment.ManifestRepository
+	envVarDigests     EnvVarDigestSource
+	logSecrets        LogSecretSource
+	quota             BuildQuota
+	billing           BuildBilling
+	dockerRegistry    string
+}
+
+// BuildQuota decides whether a user's plan lets them start another deployment
+type BuildQuota interface {
+	CheckBuilds(ctx context.Context, userID user.UserID) error
+}
+
+// BuildBilling decides whether a user's billing account is in good standing to start a deployment
+type BuildBilling interface {
+	CheckAccount(ctx context.Context, userID user.UserID) error
+}
+
+// NewBuildService creates a new build service
+func NewBuildService(
+	buildJobRepo deployment.BuildJobRepository,
+	deploymentRepo deployment.DeploymentRepository,
+	projectRepo project.ProjectRepository,
+	backend builder.BuildBackend,
+	templateGenerator *builder.TemplateGenerator,
+	limits BuildLimits,
+) *BuildService {
+	if limits.Workers <= 0 {
+		limits.Workers = defaultBuildWorkers
+	}
+	if limits.MaxConcurrentPerUser <= 0 {
+		limits.MaxConcurrentPerUser = defaultMaxConcurrentBuildsPerUser
+	}
+	if limits.MaxQueuedPerUser <= 0 {
+		limits.MaxQueuedPerUser = defaultMaxQueuedBuildsPerUser
+	}
+	if limits.MaxQueued <= 0 {
+		limits.MaxQueued = defaultMaxQueuedBuilds
+	}
+
+	return &BuildService{
+		limits:            limits,
+		buildJobRepo:      buildJobRepo,
+		deploymentRepo:    deploymentRepo,
+		projectRepo:       projectRepo,
+		backend:           backend,
+		templateGenerator: templateGenerator,
+	}
+}
+
+// SetDockerRegistry sets the registry images are tagged for when there is no image repository manager,
+// localhost:5000 by default
+func (s *BuildService) SetDockerRegistry(registry string) {
+	s.dockerRegistry = registry
+}
+
+// SetImageRepositoryManager sets the manager used to provision per-project image repositories
+func (s *BuildService) SetImageRepositoryManager(manager ImageRepositoryManager) {
+	s.imageRepositories = manager
+}
+
+// SetBuildVariableSource sets the source of the environment variables passed to builds as build args (optional)
+func (s *BuildService) SetBuildVariableSource(source BuildVariableSource) {
+	s.buildVariables = source
+}
+
+// SetLogSecretSource sets the source of the values masked in build logs (optional). Without it logs
+// are only masked by shape.
+func (s *BuildService) SetLogSecretSource(source LogSecretSource) {
+	s.logSecrets = source
+}
+
+// SetRepositoryConfigSource sets where the snapdeploy.yaml of a repository is read from, and the repository of the
+// environment variables the variables it lists are checked against (optional)
+func (s *BuildService) SetRepositoryConfigSource(files RepositoryFileSource, envVarRepo project.EnvironmentVariableRepository) {
+	s.repositoryFiles = files
+	s.envVarRepo = envVarRepo
+}
+
+// SetManifestRecorder sets where the image, environment variables and settings each deployment is built with are
+// recorded, for comparing deployments (optional)
+func (s *BuildService) SetManifestRecorder(manifestRepo deployment.ManifestRepository, envVarDigests EnvVarDigestSource) {
+	s.manifestRepo = manifestRepo
+	s.envVarDigests = envVarDigests
+}
+
+// SetBuildQuota sets what limits the deployments a user may start under their plan (optional)
+func (s *BuildService) SetBuildQuota(quota BuildQuota) {
+	s.quota = quota
+}
+
+// SetBuildBilling sets what stops users whose payment failed from starting deployments (optional)
+func (s *BuildService) SetBuildBilling(billing BuildBilling) {
+	s.billing = billing
+}
+
+// Admit checks whether a user may queue another build.
+// Returns ErrTooManyDeployments or ErrBuildQueueFull when the build has to be retried later, and the
+// quota's error when the user's plan doesn't allow it, or billing's while their payment is overdue.
+func (s *BuildService) Admit(ctx context.Context, userID user.UserID) error {
+	if s.billing != nil {
+		if err := s.billing.CheckAccount(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	inProgress, err := s.deploymentRepo.CountInProgressByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if inProgress >= int64(s.limits.MaxQueuedPerUser) {
+		return deployment.ErrTooManyDeployments
+	}
+
+	if s.quota != nil {
+		if err := s.quota.CheckBuilds(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	queued, err := s.buildJobRepo.CountOpen(ctx)
+	if err != nil {
+		return err
+	}
+	if queued >= int64(s.limits.MaxQueued) {
+		return deployment.ErrBuildQueueFull
+	}
+
+	return nil
+}
+
+// Run starts queued builds on a pool of workers until the context is cancelled
+func (s *BuildService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < s.limits.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runWorker(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// runWorker claims and starts queued builds one at a time
+func (s *BuildService) runWorker(ctx context.Context) {
+	ticker := time.NewTicker(buildJobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.processQueuedJobs(ctx)
+		}
+	}
+}
+
+// Shutdown gives the builds running on this instance until ctx is done to finish, then interrupts the rest
+// and queues them again, so the next build worker, on this instance or another, starts them over.
+// Run must have returned first.
+func (s *BuildService) Shutdown(ctx context.Context) {
+	interrupted := s.backend.Shutdown(ctx)
+	if len(interrupted) == 0 {
+		return
+	}
+
+	requeueCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), requeueTimeout)
+	defer cancel()
+
+	requeued := 0
+	for _, id := range interrupted {
+		if err := s.buildJobRepo.Requeue(requeueCtx, id); err != nil {
+			slog.ErrorContext(requeueCtx, "Failed to requeue interrupted build", "deployment_id", id.String(), "error", err)
+			continue
+		}
+		requeued++
+	}
+	slog.InfoContext(requeueCtx, "Interrupted builds queued again", "count", requeued)
+}
+
+// processQueuedJobs starts builds until no job is left to claim
+func (s *BuildService) processQueuedJobs(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := s.buildJobRepo.ClaimNext(ctx, time.Now().Add(-buildJobLease), s.limits.MaxConcurrentPerUser)
+		if err != nil {
+			if !errors.Is(err, deployment.ErrNoBuildJob) {
+				slog.ErrorContext(ctx, "Failed to claim build job", "error", err)
+			}
+			return
+		}
+
+		// Log lines and spans of the build continue those of the request that queued it
+		jobCtx := logging.WithCorrelationID(ctx, job.CorrelationID())
+		jobCtx = logging.With(jobCtx, "deployment_id", job.DeploymentID().String(), "project_id", job.ProjectID().String())
+		jobCtx, span := tracing.Start(tracing.WithTraceParent(jobCtx, job.TraceParent()), "build.job",
+			attribute.String("deployment.id", job.DeploymentID().String()),
+			attribute.String("project.id", job.ProjectID().String()),
+			attribute.Int("build.attempt", job.Attempts()),
+		)
+
+		s.processJob(jobCtx, job)
+
+		if err := s.buildJobRepo.Save(jobCtx, job); err != nil {
+			slog.ErrorContext(jobCtx, "Failed to save build job", "error", err)
+		}
+
+		var jobErr error
+		if job.Status() == deployment.BuildJobFailed {
+			jobErr = errors.New(job.LastError())
+		}
+		tracing.End(span, jobErr)
+	}
+}
+
+// processJob starts the build of a claimed job and records the outcome on the job
+func (s *BuildService) processJob(ctx context.Context, job *deployment.BuildJob) {
+	dep, err := s.deploymentRepo.FindByID(ctx, job.DeploymentID())
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to find deployment", "error", err)
+		if errors.Is(err, deployment.ErrDeploymentNotFound) {
+			job.Fail("deployment not found")
+		}
+		// Other errors leave the job claimed so it is retried once the lease expires
+		return
+	}
+
+	// Builds interrupted by a shutdown start over
+	if dep.Status() == deployment.StatusInterrupted {
+		if err := dep.Resume(); err != nil {
+			job.Fail(err.Error())
+			return
+		}
+		dep.AppendLog("▶️  Resuming the build interrupted by a server shutdown")
+	}
+
+	// A previous worker already started the build before dying, or the deployment was timed out
+	if dep.Status() != deployment.StatusPending {
+		job.Complete()
+		return
+	}
+
+	if job.Attempts() > maxBuildJobAttempts {
+		slog.WarnContext(ctx, "Giving up on build", "attempts", maxBuildJobAttempts)
+		s.failDeployment(ctx, dep, fmt.Sprintf("❌ Build could not be started after %d attempts", maxBuildJobAttempts))
+		job.Fail("too many attempts")
+		return
+	}
+
+	if err := s.startBuild(ctx, job, dep); err != nil {
+		job.Fail(err.Error())
+		return
+	}
+	job.Complete()
+}
+
+// startBuild generates the deployment's Dockerfile and image tag and starts its build on the configured backend
+func (s *BuildService) startBuild(ctx context.Context, job *deployment.BuildJob, dep *deployment.Deployment) error {
+	proj, err := s.projectRepo.FindByID(ctx, job.ProjectID())
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to find project", "error", err)
+		s.failDeployment(ctx, dep, "")
+		return fmt.Errorf("failed to find project: %w", err)
+	}
+
+	// Build scripts may print the project's environment variables, which are masked before logs are stored or streamed
+	maskLogSecrets(ctx, s.logSecrets, dep)
+
+	// The snapdeploy.yaml of the commit being deployed overrides the project's settings
+	if err := s.applyRepoConfig(ctx, proj, dep); err != nil {
+		return err
+	}
+
+	// Build-scoped environment variables are baked into the image, so the build fails rather than running without them
+	var buildArgs map[string]string
+	if s.buildVariables != nil {
+		buildArgs, err = s.buildVariables.DecryptBuild(ctx, proj.ID(), dep.Environment())
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to load build environment variables", "error", err)
+			s.failDeployment(ctx, dep, "❌ Could not load build environment variables")
+			return fmt.Errorf("failed to load build environment variables: %w", err)
+		}
+	}
+
+	// Generate Dockerfile
+	dockerfile, err := s.generateDockerfile(proj, builder.BuildArgNames(buildArgs))
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to generate Dockerfile", "error", err)
+		s.failDeployment(ctx, dep, "")
+		return fmt.Errorf("failed to generate Dockerfile: %w", err)
+	}
+
+	// Generate image tag
+	imageTag, err := s.generateImageTag(ctx, proj, dep)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to prepare image repository", "error", err)
+		s.failDeployment(ctx, dep, "")
+		return fmt.Errorf("failed to prepare image repository: %w", err)
+	}
+	s.recordManifest(ctx, proj, dep, imageTag)
+
+	// Trigger the build
+	buildReq := builder.BuildRequest{
+		Deployment:    dep,
+		Project:       proj,
+		RepositoryURL: proj.RepositoryURL().String(),
+		Branch:        dep.Branch().String(),
+		CommitHash:    dep.CommitHash().String(),
+		ImageTag:      imageTag,
+		Dockerfile:    dockerfile,
+		BuildArgs:     buildArgs,
+	}
+
+	slog.InfoContext(ctx, "Starting build", "attempt", job.Attempts())
+	buildID, err := s.backend.StartBuild(ctx, buildReq)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to start build", "error", err)
+		// Status is updated by the build backend
+		return err
+	}
+	slog.InfoContext(ctx, "Build started", "build_id", buildID)
+	return nil
+}
+
+// PlanBuild works out what a deployment would be built with, the way a build worker would, without saving,
+// provisioning or building anything. The snapdeploy.yaml of the commit is applied to proj, which isn't saved.
+// Reasons the build would fail are returned in the plan.
+func (s *BuildService) PlanBuild(ctx context.Context, proj *project.Project, dep *deployment.Deployment) (*BuildPlan, error) {
+	plan := &BuildPlan{}
+
+	if s.repositoryFiles != nil {
+		data, err := s.repositoryFiles.FetchRepositoryFile(ctx, proj, buildRef(dep), repoconfig.FileName)
+		switch {
+		case errors.Is(err, repo.ErrFileNotFound):
+		case err != nil:
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("Could not read %s, the deployment would build with the project's settings", repoconfig.FileName))
+		default:
+			cfg, err := repoconfig.Parse(data)
+			if err == nil {
+				_, err = cfg.Apply(proj)
+			}
+			if err != nil {
+				plan.Problems = append(plan.Problems, fmt.Sprintf("%s is invalid: %v", repoconfig.FileName, err))
+				break
+			}
+			plan.RepoConfig = true
+
+			missing, err := s.missingEnvVars(ctx, proj, dep.Environment(), cfg.Env)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load environment variables: %w", err)
+			}
+			if len(missing) > 0 {
+				plan.Problems = append(plan.Problems, fmt.Sprintf("%s lists environment variables not set for %s: %s",
+					repoconfig.FileName, dep.Environment(), strings.Join(missing, ", ")))
+			}
+		}
+	}
+
+	// Only the names of the environment variables are planned, their values are never decrypted
+	runtime := platformEnvVars(proj)
+	if s.envVarRepo != nil {
+		envVars, err := s.envVarRepo.FindByProjectID(ctx, proj.ID(), dep.Environment())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load environment variables: %w", err)
+		}
+		for _, envVar := range envVars {
+			if envVar.Scope().AtBuild() {
+				plan.BuildArgs = append(plan.BuildArgs, envVar.Key().String())
+			}
+			if envVar.Scope().AtRuntime() {
+				runtime[envVar.Key().String()] = true
+			}
+		}
+	}
+	sort.Strings(plan.BuildArgs)
+	for name, set := range runtime {
+		if set {
+			plan.EnvVars = append(plan.EnvVars, name)
+		}
+	}
+	sort.Strings(plan.EnvVars)
+
+	dockerfile, err := s.generateDockerfile(proj, plan.BuildArgs)
+	if err != nil {
+		plan.Problems = append(plan.Problems, fmt.Sprintf("Could not generate the Dockerfile: %v", err))
+	}
+	plan.Dockerfile = dockerfile
+	plan.ImageTag = s.plannedImageTag(proj, dep)
+
+	return plan, nil
+}
+
+// generateDockerfile generates the Dockerfile of a project, passed the named build args. Static sites are
+// built the same way whatever their language.
+func (s *BuildService) generateDockerfile(proj *project.Project, buildArgNames []string) (string, error) {
+	templateData := builder.TemplateData{
+		InstallCommand:  proj.InstallCommand().String(),
+		BuildCommand:    proj.BuildCommand().String(),
+		RunCommand:      proj.RunCommand().String(),
+		Port:            strconv.Itoa(proj.Port()),
+		BuildArgs:       buildArgNames,
+		OutputDirectory: proj.OutputDirectory(),
+		LambdaAdapter:   proj.DeploymentTarget() == project.TargetLambda,
+	}
+	if proj.Type() == project.TypeStatic {
+		return s.templateGenerator.GenerateStaticDockerfile(templateData)
+	}
+	return s.templateGenerator.GenerateDockerfile(proj.Language(), templateData)
+}
+
+// buildRef returns the Git ref a deployment builds, HEAD deployments build the tip of the branch
+func buildRef(dep *deployment.Deployment) string {
+	ref := dep.CommitHash().String()
+	if strings.EqualFold(ref, "HEAD") {
+		return dep.Branch().String()
+	}
+	return ref
+}
+
+// applyRepoConfig reads the snapdeploy.yaml of the commit being deployed, if the repository has one, applies it to
+// the project and checks the environment variables it lists are set. An invalid file fails the deployment.
+func (s *BuildService) applyRepoConfig(ctx context.Context, proj *project.Project, dep *deployment.Deployment) error {
+	if s.repositoryFiles == nil {
+		return nil
+	}
+
+	data, err := s.repositoryFiles.FetchRepositoryFile(ctx, proj, buildRef(dep), repoconfig.FileName)
+	if errors.Is(err, repo.ErrFileNotFound) {
+		return nil
+	}
+	if err != nil {
+		// A provider outage shouldn't hold up deployments, which go ahead with the settings the project has
+		slog.WarnContext(ctx, "Failed to read repository config", "error", err)
+		dep.AppendLog(fmt.Sprintf("⚠️  Could not read %s, building with the project's settings", repoconfig.FileName))
+		s.deploymentRepo.Save(ctx, dep)
+		return nil
+	}
+
+	cfg, err := repoconfig.Parse(data)
+	if err == nil {
+		var changed bool
+		changed, err = cfg.Apply(proj)
+		if err == nil && changed {
+			if err := s.projectRepo.Save(ctx, proj); err != nil {
+				slog.ErrorContext(ctx, "Failed to save project", "error", err)
+				s.failDeployment(ctx, dep, "")
+				return fmt.Errorf("failed to save project: %w", err)
+			}
+		}
+	}
+	if err != nil {
+		dep.AppendLog(fmt.Sprintf("❌ %s is invalid:", repoconfig.FileName))
+		for _, line := range strings.Split(err.Error(), "\n") {
+			dep.AppendLog("   " + line)
+		}
+		s.failDeployment(ctx, dep, "")
+		return fmt.Errorf("invalid %s: %w", repoconfig.FileName, err)
+	}
+	dep.AppendLog(fmt.Sprintf("📄 Applied %s", repoconfig.FileName))
+
+	missing, err := s.missingEnvVars(ctx, proj, dep.Environment(), cfg.Env)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load environment variables", "error", err)
+		s.failDeployment(ctx, dep, "❌ Could not check the environment variables listed in "+repoconfig.FileName)
+		return fmt.Errorf("failed to load environment variables: %w", err)
+	}
+	if len(missing) > 0 {
+		s.failDeployment(ctx, dep, fmt.Sprintf("❌ %s lists environment variables not set for %s: %s",
+			repoconfig.FileName, dep.Environment(), strings.Join(missing, ", ")))
+		return fmt.Errorf("missing environment variables: %s", strings.Join(missing, ", "))
+	}
+
+	s.deploymentRepo.Save(ctx, dep)
+	return nil
+}
+
+// missingEnvVars returns the names, sorted, that are neither set on the project's environment nor set by the platform
+func (s *BuildService) missingEnvVars(ctx context.Context, proj *project.Project, env project.Environment, names []string) ([]string, error) {
+	if len(names) == 0 || s.envVarRepo == nil {
+		return nil, nil
+	}
+
+	envVars, err := s.envVarRepo.FindByProjectID(ctx, proj.ID(), env)
+	if err != nil {
+		return nil, err
+	}
+
+	set := platformEnvVars(proj)
+	for _, envVar := range envVars {
+		set[envVar.Key().String()] = true
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !set[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// platformEnvVars returns the names of the environment variables the platform sets on a project's processes
+func platformEnvVars(proj *project.Project) map[string]bool {
+	return map[string]bool{
+		"PORT":         true,
+		"PROJECT_ID":   true,
+		"LANGUAGE":     true,
+		"DATABASE_URL": proj.RequireDB(),
+		"REDIS_URL":    proj.UsesDatastore(project.DatastoreRedis),
+		"MYSQL_URL":    proj.UsesDatastore(project.DatastoreMySQL),
+	}
+}
+
+// recordManifest records what a deployment is built with. Deployments go ahead without a manifest, they just
+// can't be compared with others.
+func (s *BuildService) recordManifest(ctx context.Context, proj *project.Project, dep *deployment.Deployment, imageTag string) {
+	if s.manifestRepo == nil {
+		return
+	}
+
+	manifest := &deployment.Manifest{
+		DeploymentID: dep.ID(),
+		ProjectID:    proj.ID(),
+		ImageTag:     imageTag,
+		Config:       manifestConfig(proj),
+		CreatedAt:    time.Now(),
+	}
+	if s.envVarDigests != nil {
+		digests, err := s.envVarDigests.DigestValues(ctx, proj.ID(), dep.Environment())
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to digest environment variables", "error", err)
+			return
+		}
+		manifest.EnvVars = digests
+	}
+
+	if err := s.manifestRepo.Save(ctx, manifest); err != nil {
+		slog.WarnContext(ctx, "Failed to record deployment manifest", "error", err)
+	}
+}
+
+// manifestConfig returns the project settings recorded in deployment manifests, by name
+func manifestConfig(proj *project.Project) map[string]string {
+	datastores := make([]string, len(proj.Datastores()))
+	for i, datastore := range proj.Datastores() {
+		datastores[i] = datastore.String()
+	}
+	sort.Strings(datastores)
+
+	config := map[string]string{
+		"type":                proj.Type().String(),
+		"language":            proj.Language().String(),
+		"install_command":     proj.InstallCommand().String(),
+		"build_command":       proj.BuildCommand().String(),
+		"run_command":         proj.RunCommand().String(),
+		"migration_command":   proj.MigrationCommand().String(),
+		"output_directory":    proj.OutputDirectory(),
+		"port":                strconv.Itoa(proj.Port()),
+		"health_check_path":   proj.HealthCheckPath(),
+		"cpu":                 strconv.Itoa(proj.CPU()),
+		"memory":              strconv.Itoa(proj.Memory()),
+		"require_db":          strconv.FormatBool(proj.RequireDB()),
+		"datastores":          strings.Join(datastores, ","),
+		"deployment_strategy": proj.DeploymentStrategy().String(),
+		"deployment_target":   proj.DeploymentTarget().String(),
+		"schedule":            proj.Schedule().String(),
+	}
+	if proj.DeploymentStrategy() == project.StrategyCanary {
+		config["canary_percent"] = strconv.Itoa(proj.CanaryPercent())
+		config["canary_bake_minutes"] = strconv.Itoa(proj.CanaryBakeMinutes())
+	}
+	if proj.HasVolume() {
+		config["volume"] = fmt.Sprintf("%s (%d GB)", proj.VolumeMountPath(), proj.VolumeSizeGB())
+	}
+	return config
+}
+
+// failDeployment marks a deployment failed, optionally appending a log line
+func (s *BuildService) failDeployment(ctx context.Context, dep *deployment.Deployment, message string) {
+	if message != "" {
+		dep.AppendLog(message)
+	}
+	dep.UpdateStatus(deployment.StatusFailed)
+	s.deploymentRepo.Save(ctx, dep)
+}
+
+// generateImageTag generates a Docker image tag for the deployment
+func (s *BuildService) generateImageTag(ctx context.Context, proj *project.Project, dep *deployment.Deployment) (string, error) {
+	projectName := sanitizeImageName(proj.ID().String())
+	commitHash := dep.CommitHash().ImageTag()
+
+	// Each project gets its own repository, tagged by commit
+	// Format: account.dkr.ecr.region.amazonaws.com/project-id:commit-hash
+	if s.imageRepositories != nil {
+		repositoryURI, err := s.imageRepositories.EnsureProjectRepository(ctx, projectName)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s:%s", repositoryURI, commitHash), nil
+	}
+
+	return s.registryImageTag(projectName, commitHash), nil
+}
+
+// plannedImageTag returns the image tag generateImageTag would return, without provisioning the repository
+func (s *BuildService) plannedImageTag(proj *project.Project, dep *deployment.Deployment) string {
+	projectName := sanitizeImageName(proj.ID().String())
+	commitHash := dep.CommitHash().ImageTag()
+	if s.imageRepositories != nil {
+		return fmt.Sprintf("%s:%s", s.imageRepositories.RepositoryURI(projectName), commitHash)
+	}
+	return s.registryImageTag(projectName, commitHash)
+}
+
+// registryImageTag returns the tag of a project's image in the registry set by SetDockerRegistry
+func (s *BuildService) registryImageTag(projectName, commitHash string) string {
+	// Standard registry format: registry/repo:tag
+	registry := s.dockerRegistry
+	if registry == "" {
+		registry = "localhost:5000" // Default to local registry
+	}
+	return fmt.Sprintf("%s/%s:%s", registry, projectName, commitHash)
+}
+
+// sanitizeImageName ensures the name is valid for Docker
+func sanitizeImageName(name string) string {
+	// Docker image names must be lowercase and can only contain
+	// lowercase letters, digits, and separators (., -, _)
+	// UUIDs are already valid, but we'll convert to lowercase just in case
+	return filepath.Base(name)
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/command"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// maxListedCommandRuns is how many of a project's most recent command runs are listed
+const maxListedCommandRuns = 50
+
+var (
+	// ErrCommandsUnavailable is returned when no command runner is configured
+	ErrCommandsUnavailable = errors.New("one-off commands are unavailable")
+
+	// ErrNothingDeployed is returned when a project's environment has no successful deployment to run a command with
+	ErrNothingDeployed = errors.New("environment has no successful deployment")
+)
+
+// CommandRunner runs one-off commands in the containers of projects
+type CommandRunner interface {
+	// RunCommand runs a command with the image and environment variables an environment is deployed with,
+	// passing the lines it writes on as they come, and returns its exit code
+	RunCommand(ctx context.Context, proj *project.Project, env project.Environment, args []string, output func(lines []string)) (int, error)
+}
+
+// LogBroadcaster streams log lines to the clients following them
+type LogBroadcaster interface {
+	BroadcastLog(streamID string, logLine string)
+}
+
+// CommandService runs one-off commands in the containers of projects, such as seeding a database, and keeps
+// the record of who ran what and what it wrote
+type CommandService struct {
+	projectRepo    project.ProjectRepository
+	deploymentRepo deployment.DeploymentRepository
+	runRepo        command.RunRepository
+	runner         CommandRunner
+	logSecrets     LogSecretSource
+	broadcaster    LogBroadcaster
+}
+
+// NewCommandService creates a new command service
+func NewCommandService(
+	projectRepo project.ProjectRepository,
+	deploymentRepo deployment.DeploymentRepository,
+	runRepo command.RunRepository,
+) *CommandService {
+	return &CommandService{
+		projectRepo:    projectRepo,
+		deploymentRepo: deploymentRepo,
+		runRepo:        runRepo,
+	}
+}
+
+// SetCommandRunner sets the component that runs commands (optional)
+func (s *CommandService) SetCommandRunner(runner CommandRunner) {
+	s.runner = runner
+}
+
+// SetLogSecretSource sets the source of the values masked in the output of commands (optional). Without it
+// output is only masked by the shape of secrets.
+func (s *CommandService) SetLogSecretSource(source LogSecretSource) {
+	s.logSecrets = source
+}
+
+// SetLogBroadcaster sets where the output of commands is streamed as they run, keyed by run ID (optional)
+func (s *CommandService) SetLogBroadcaster(broadcaster LogBroadcaster) {
+	s.broadcaster = broadcaster
+}
+
+// RunCommand starts a command in the container of a project's environment with the image and environment
+// variables of its latest successful deployment. The command keeps running in the background, its output
+// is recorded and streamed as it is written. The run records the user who started it.
+func (s *CommandService) RunCommand(ctx context.Context, projectID, userID string, req *dto.RunCommandRequest) (*dto.CommandRunResponse, error) {
+	if s.runner == nil {
+		return nil, ErrCommandsUnavailable
+	}
+
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+	proj, err := findProject(ctx, s.projectRepo, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if proj.IsDeleting() {
+		return nil, project.ErrProjectDeleting
+	}
+
+	env, err := project.NewEnvironment(req.Environment)
+	if err != nil || !proj.HasEnvironment(env) {
+		return nil, project.ErrEnvironmentNotFound
+	}
+
+	dep, err := s.deploymentRepo.FindLatestDeployedInEnvironment(ctx, proj.ID(), env)
+	if errors.Is(err, deployment.ErrDeploymentNotFound) {
+		return nil, ErrNothingDeployed
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest deployment: %w", err)
+	}
+
+	run, err := command.NewRun(proj, dep, uid, req.Command)
+	if err != nil {
+		return nil, err
+	}
+	s.maskSecrets(ctx, run)
+
+	if err := s.runRepo.Save(ctx, run); err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Running one-off command",
+		"project_id", proj.ID().String(),
+		"environment", env.String(),
+		"deployment_id", dep.ID().String(),
+		"run_id", run.ID().String(),
+		"user_id", uid.String(),
+	)
+
+	// The command outlives the request that started it, the run is only read before it starts
+	response := toCommandRunDTO(run, time.Now(), false)
+	go s.execute(context.WithoutCancel(ctx), proj, run)
+
+	return response, nil
+}
+
+// execute runs a command to completion, recording its output as it is written. The runner stops commands
+// that run for longer than command.Timeout.
+func (s *CommandService) execute(ctx context.Context, proj *project.Project, run *command.Run) {
+	exitCode, err := s.runner.RunCommand(ctx, proj, run.Environment(), run.Args(), func(lines []string) {
+		s.broadcast(run, run.AppendOutput(lines...))
+		if err := s.runRepo.Save(ctx, run); err != nil {
+			slog.WarnContext(ctx, "Failed to save command output", "run_id", run.ID().String(), "error", err)
+		}
+	})
+	if err != nil {
+		run.Fail(err)
+	} else {
+		run.Finish(exitCode)
+	}
+	s.broadcast(run, run.AppendOutput(run.Outcome()))
+
+	if err := s.runRepo.Save(ctx, run); err != nil {
+		slog.ErrorContext(ctx, "Failed to record command run", "run_id", run.ID().String(), "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "One-off command finished", "run_id", run.ID().String(), "status", run.Status().String())
+}
+
+// ListCommandRuns returns the most recent command runs of a project, newest first, without their output
+func (s *CommandService) ListCommandRuns(ctx context.Context, projectID string) (*dto.CommandRunListResponse, error) {
+	proj, err := findProject(ctx, s.projectRepo, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := s.runRepo.FindByProjectID(ctx, proj.ID(), maxListedCommandRuns)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	response := &dto.CommandRunListResponse{
+		ProjectID: proj.ID().String(),
+		Runs:      make([]*dto.CommandRunResponse, len(runs)),
+	}
+	for i, run := range runs {
+		response.Runs[i] = toCommandRunDTO(run, now, false)
+	}
+	return response, nil
+}
+
+// GetCommandRun returns a command run of a project with its output
+func (s *CommandService) GetCommandRun(ctx context.Context, projectID, runID string) (*dto.CommandRunResponse, error) {
+	proj, err := findProject(ctx, s.projectRepo, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	rid, err := command.ParseRunID(runID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid command run ID: %w", err)
+	}
+	run, err := s.runRepo.FindByID(ctx, rid)
+	if err != nil {
+		return nil, err
+	}
+	if !run.ProjectID().Equals(proj.ID()) {
+		return nil, command.ErrRunNotFound
+	}
+
+	return toCommandRunDTO(run, time.Now(), true), nil
+}
+
+// maskSecrets masks the values of the environment's variables in the run's output
+func (s *CommandService) maskSecrets(ctx context.Context, run *command.Run) {
+	if s.logSecrets == nil {
+		return
+	}
+
+	values, err := s.logSecrets.SecretValues(ctx, run.ProjectID(), run.Environment())
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to load the values masked in command output", "error", err)
+		return
+	}
+	run.MaskInOutput(values...)
+}
+
+// broadcast streams lines of a run's output to the clients following it
+func (s *CommandService) broadcast(run *command.Run, lines []string) {
+	if s.broadcaster == nil {
+		return
+	}
+	for _, line := range lines {
+		s.broadcaster.BroadcastLog(run.ID().String(), line)
+	}
+}
+
+func toCommandRunDTO(run *command.Run, now time.Time, withOutput bool) *dto.CommandRunResponse {
+	response := &dto.CommandRunResponse{
+		ID:              run.ID().String(),
+		ProjectID:       run.ProjectID().String(),
+		DeploymentID:    run.DeploymentID().String(),
+		UserID:          run.UserID().String(),
+		Environment:     run.Environment().String(),
+		Command:         run.Command(),
+		Status:          run.Status().String(),
+		ExitCode:        run.ExitCode(),
+		Error:           run.ErrorMessage(),
+		StartedAt:       run.StartedAt().UTC().Format(time.RFC3339),
+		DurationSeconds: int64(run.Duration(now).Seconds()),
+	}
+	if withOutput {
+		response.Output = run.Output()
+	}
+	if finishedAt := run.FinishedAt(); finishedAt != nil {
+		response.FinishedAt = finishedAt.UTC().Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,165 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/command"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// mockCommandRuns keeps runs in memory and reports the runs that finished
+type mockCommandRuns struct {
+	mu       sync.Mutex
+	runs     map[command.RunID]*command.Run
+	finished chan *command.Run
+}
+
+func (m *mockCommandRuns) Save(ctx context.Context, run *command.Run) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[run.ID()] = run
+	if run.Status().IsTerminal() {
+		m.finished <- run
+	}
+	return nil
+}
+
+func (m *mockCommandRuns) FindByID(ctx context.Context, id command.RunID) (*command.Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run, ok := m.runs[id]
+	if !ok {
+		return nil, command.ErrRunNotFound
+	}
+	return run, nil
+}
+
+func (m *mockCommandRuns) FindByProjectID(ctx context.Context, projectID project.ProjectID, limit int) ([]*command.Run, error) {
+	return nil, nil
+}
+
+// mockCommandRunner writes lines and exits with a code
+type mockCommandRunner struct {
+	lines    []string
+	exitCode int
+	args     []string
+}
+
+func (m *mockCommandRunner) RunCommand(ctx context.Context, proj *project.Project, env project.Environment, args []string, output func(lines []string)) (int, error) {
+	m.args = args
+	output(m.lines)
+	return m.exitCode, nil
+}
+
+// mockBroadcaster records the lines streamed
+type mockBroadcaster struct {
+	mu    sync.Mutex
+	lines map[string][]string
+}
+
+func (m *mockBroadcaster) BroadcastLog(streamID string, logLine string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lines[streamID] = append(m.lines[streamID], logLine)
+}
+
+func TestCommandService_RunCommand(t *testing.T) {
+	ctx := context.Background()
+	owner := user.NewUserID()
+	proj := newTestProject(t, owner, "my-app", requireDatabase)
+	deployed := newTestDeployment(t, proj, project.EnvironmentProduction, deployment.StatusDeployed)
+
+	newService := func(deployments *mockDeploymentRepository, runs *mockCommandRuns, runner *mockCommandRunner, broadcaster *mockBroadcaster) *service.CommandService {
+		svc := service.NewCommandService(newMockProjectRepository(proj), deployments, runs)
+		svc.SetCommandRunner(runner)
+		svc.SetLogSecretSource(&mockLogSecrets{values: []string{"postgres-password-42"}})
+		svc.SetLogBroadcaster(broadcaster)
+		return svc
+	}
+
+	t.Run("records and streams the output", func(t *testing.T) {
+		runs := &mockCommandRuns{runs: map[command.RunID]*command.Run{}, finished: make(chan *command.Run, 1)}
+		runner := &mockCommandRunner{lines: []string{"Seeding with postgres-password-42", "Seeded 12 users"}, exitCode: 1}
+		broadcaster := &mockBroadcaster{lines: map[string][]string{}}
+		svc := newService(newMockDeploymentRepository(deployed), runs, runner, broadcaster)
+
+		response, err := svc.RunCommand(ctx, proj.ID().String(), owner.String(), &dto.RunCommandRequest{Command: "npm run seed"})
+		if err != nil {
+			t.Fatalf("RunCommand() error = %v", err)
+		}
+		if response.Status != command.StatusRunning.String() || response.DeploymentID != deployed.ID().String() || response.UserID != owner.String() {
+			t.Errorf("response = %+v, want the run started by the owner with the deployed image", response)
+		}
+
+		var run *command.Run
+		select {
+		case run = <-runs.finished:
+		case <-time.After(time.Second):
+			t.Fatal("command run wasn't recorded as finished")
+		}
+		if run.Status() != command.StatusFailed || run.ExitCode() == nil || *run.ExitCode() != 1 {
+			t.Errorf("run = %v with exit code %v, want it failed with exit code 1", run.Status(), run.ExitCode())
+		}
+		if len(runner.args) != 3 || runner.args[2] != "seed" {
+			t.Errorf("ran %q, want the command split into arguments", runner.args)
+		}
+
+		want := []string{"Seeding with ***", "Seeded 12 users", "❌ Command exited with code 1"}
+		streamed := broadcaster.lines[run.ID().String()]
+		if len(streamed) != len(want) {
+			t.Fatalf("streamed %q, want %q", streamed, want)
+		}
+		for i := range want {
+			if streamed[i] != want[i] {
+				t.Errorf("streamed line %d = %q, want %q", i, streamed[i], want[i])
+			}
+		}
+
+		got, err := svc.GetCommandRun(ctx, proj.ID().String(), run.ID().String())
+		if err != nil {
+			t.Fatalf("GetCommandRun() error = %v", err)
+		}
+		if len(got.Output) != len(want) || got.Output[0] != want[0] {
+			t.Errorf("recorded output = %q, want %q", got.Output, want)
+		}
+	})
+
+	t.Run("records who ran it", func(t *testing.T) {
+		runs := &mockCommandRuns{runs: map[command.RunID]*command.Run{}, finished: make(chan *command.Run, 1)}
+		svc := newService(newMockDeploymentRepository(deployed), runs, &mockCommandRunner{}, &mockBroadcaster{lines: map[string][]string{}})
+
+		// Access was checked by the middleware, an operator's runs are recorded as theirs
+		operator := user.NewUserID()
+		response, err := svc.RunCommand(ctx, proj.ID().String(), operator.String(), &dto.RunCommandRequest{Command: "npm run seed"})
+		if err != nil {
+			t.Fatalf("RunCommand() error = %v", err)
+		}
+		if response.UserID != operator.String() {
+			t.Errorf("UserID = %s, want %s", response.UserID, operator)
+		}
+
+		select {
+		case <-runs.finished:
+		case <-time.After(time.Second):
+			t.Fatal("command run wasn't recorded as finished")
+		}
+	})
+
+	t.Run("needs a deployment", func(t *testing.T) {
+		runs := &mockCommandRuns{runs: map[command.RunID]*command.Run{}, finished: make(chan *command.Run, 1)}
+		svc := newService(newMockDeploymentRepository(), runs, &mockCommandRunner{}, &mockBroadcaster{lines: map[string][]string{}})
+
+		_, err := svc.RunCommand(ctx, proj.ID().String(), owner.String(), &dto.RunCommandRequest{Command: "npm run seed"})
+		if !errors.Is(err, service.ErrNothingDeployed) {
+			t.Errorf("RunCommand() without a deployment error = %v, want ErrNothingDeployed", err)
+		}
+	})
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/cronrun"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+)
+
+// maxListedRuns is how many of a deployment's most recent runs are listed
+const maxListedRuns = 100
+
+// ErrCronRunsUnavailable is returned when no scheduled task source is configured
+var ErrCronRunsUnavailable = errors.New("cron job runs are unavailable")
+
+// ScheduledTaskSource reads the tasks the schedules of projects' cron jobs started and what they logged
+type ScheduledTaskSource interface {
+	ListScheduledTasks(ctx context.Context, proj *project.Project) ([]cronrun.TaskReport, error)
+	GetRunLogs(ctx context.Context, proj *project.Project, taskID string) ([]string, error)
+}
+
+// CronRunService records the runs of CRON projects and serves their history and logs
+type CronRunService struct {
+	projectRepo    project.ProjectRepository
+	deploymentRepo deployment.DeploymentRepository
+	runRepo        cronrun.RunRepository
+	source         ScheduledTaskSource
+}
+
+// NewCronRunService creates a new cron run service
+func NewCronRunService(
+	projectRepo project.ProjectRepository,
+	deploymentRepo deployment.DeploymentRepository,
+	runRepo cronrun.RunRepository,
+) *CronRunService {
+	return &CronRunService{
+		projectRepo:    projectRepo,
+		deploymentRepo: deploymentRepo,
+		runRepo:        runRepo,
+	}
+}
+
+// SetScheduledTaskSource sets where scheduled tasks are read from (optional)
+func (s *CronRunService) SetScheduledTaskSource(source ScheduledTaskSource) {
+	s.source = source
+}
+
+// SyncRuns records the tasks the schedules of every project's cron jobs started since the last sync and how the
+// ones that stopped went. Returns the number of runs recorded or updated.
+func (s *CronRunService) SyncRuns(ctx context.Context) (int, error) {
+	if s.source == nil {
+		return 0, ErrCronRunsUnavailable
+	}
+
+	projects, err := s.projectRepo.FindWithCronJobs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list cron projects: %w", err)
+	}
+
+	synced := 0
+	var errs []error
+	for _, proj := range projects {
+		reports, err := s.source.ListScheduledTasks(ctx, proj)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("project %s: %w", proj.ID().String(), err))
+			continue
+		}
+
+		for _, report := range reports {
+			recorded, err := s.record(ctx, proj, report)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("task %s: %w", report.TaskARN, err))
+				continue
+			}
+			if recorded {
+				synced++
+			}
+		}
+	}
+
+	return synced, errors.Join(errs...)
+}
+
+// record saves a task's run unless it already recorded that the run finished
+func (s *CronRunService) record(ctx context.Context, proj *project.Project, report cronrun.TaskReport) (bool, error) {
+	run, err := s.runRepo.FindByTaskARN(ctx, report.TaskARN)
+	switch {
+	case errors.Is(err, cronrun.ErrRunNotFound):
+		did, err := deployment.ParseDeploymentID(report.DeploymentID)
+		if err != nil {
+			// Started by a schedule that predates run history
+			return false, nil
+		}
+		run = cronrun.NewRun(proj.ID(), did, report)
+	case err != nil:
+		return false, err
+	case run.Status().IsTerminal():
+		return false, nil
+	default:
+		run.Update(report)
+	}
+
+	if err := s.runRepo.Save(ctx, run); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RunSync periodically records the runs of CRON projects until the context is cancelled
+func (s *CronRunService) RunSync(ctx context.Context, interval time.Duration) {
+	if interval <= 0 || s.source == nil {
+		slog.Info("Recording cron job runs disabled", "interval", interval)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			synced, err := s.SyncRuns(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "Recording cron job runs failed", "synced", synced, "error", err)
+				continue
+			}
+			if synced > 0 {
+				slog.DebugContext(ctx, "Recorded cron job runs", "synced", synced)
+			}
+		}
+	}
+}
+
+// ListRuns returns the most recent runs of a CRON project's deployment, newest first
+func (s *CronRunService) ListRuns(ctx context.Context, deploymentID string) (*dto.CronRunListResponse, error) {
+	dep, err := findDeployment(ctx, s.deploymentRepo, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := s.runRepo.FindByDeploymentID(ctx, dep.ID(), maxListedRuns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get runs: %w", err)
+	}
+
+	now := time.Now()
+	response := &dto.CronRunListResponse{
+		DeploymentID: dep.ID().String(),
+		Runs:         make([]*dto.CronRunResponse, len(runs)),
+	}
+	for i, run := range runs {
+		response.Runs[i] = toCronRunDTO(run, now)
+	}
+
+	return response, nil
+}
+
+// GetRunLogs returns the log lines a run of a deployment wrote
+func (s *CronRunService) GetRunLogs(ctx context.Context, deploymentID, runID string) (*dto.CronRunLogsResponse, error) {
+	dep, err := findDeployment(ctx, s.deploymentRepo, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	rid, err := cronrun.ParseRunID(runID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid run ID: %w", err)
+	}
+	run, err := s.runRepo.FindByID(ctx, rid)
+	if err != nil {
+		return nil, err
+	}
+	if !run.DeploymentID().Equals(dep.ID()) {
+		return nil, cronrun.ErrRunNotFound
+	}
+
+	if s.source == nil {
+		return nil, ErrCronRunsUnavailable
+	}
+
+	proj, err := s.projectRepo.FindByID(ctx, dep.ProjectID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	lines, err := s.source.GetRunLogs(ctx, proj, run.TaskID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get run logs: %w", err)
+	}
+	if lines == nil {
+		lines = []string{}
+	}
+
+	return &dto.CronRunLogsResponse{
+		RunID:  run.ID().String(),
+		Status: run.Status().String(),
+		Lines:  lines,
+	}, nil
+}
+
+// findDeployment loads a deployment by its ID
+func findDeployment(ctx context.Context, deploymentRepo deployment.DeploymentRepository, deploymentID string) (*deployment.Deployment, error) {
+	did, err := deployment.ParseDeploymentID(deploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment ID: %w", err)
+	}
+
+	return deploymentRepo.FindByID(ctx, did)
+}
+
+func toCronRunDTO(run *cronrun.Run, now time.Time) *dto.CronRunResponse {
+	response := &dto.CronRunResponse{
+		ID:              run.ID().String(),
+		DeploymentID:    run.DeploymentID().String(),
+		TaskARN:         run.TaskARN(),
+		Status:          run.Status().String(),
+		ExitCode:        run.ExitCode(),
+		Reason:          run.Reason(),
+		StartedAt:       run.StartedAt().UTC().Format(time.RFC3339),
+		DurationSeconds: int64(run.Duration(now).Seconds()),
+	}
+	if stoppedAt := run.StoppedAt(); stoppedAt != nil {
+		response.StoppedAt = stoppedAt.UTC().Format(time.RFC3339)
+	}
+	return response
+}
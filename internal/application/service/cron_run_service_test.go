@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/cronrun"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// Mock implementations
+type mockCronRuns struct {
+	cronrun.RunRepository
+	runs  map[string]*cronrun.Run
+	saves int
+}
+
+func (m *mockCronRuns) Save(ctx context.Context, run *cronrun.Run) error {
+	m.runs[run.TaskARN()] = run
+	m.saves++
+	return nil
+}
+
+func (m *mockCronRuns) FindByTaskARN(ctx context.Context, taskARN string) (*cronrun.Run, error) {
+	if run, ok := m.runs[taskARN]; ok {
+		return run, nil
+	}
+	return nil, cronrun.ErrRunNotFound
+}
+
+func (m *mockCronRuns) FindByDeploymentID(ctx context.Context, deploymentID deployment.DeploymentID, limit int) ([]*cronrun.Run, error) {
+	var runs []*cronrun.Run
+	for _, run := range m.runs {
+		if run.DeploymentID().Equals(deploymentID) {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+type mockScheduledTasks struct {
+	reports []cronrun.TaskReport
+}
+
+func (m *mockScheduledTasks) ListScheduledTasks(ctx context.Context, proj *project.Project) ([]cronrun.TaskReport, error) {
+	return m.reports, nil
+}
+
+func (m *mockScheduledTasks) GetRunLogs(ctx context.Context, proj *project.Project, taskID string) ([]string, error) {
+	return []string{"done"}, nil
+}
+
+func TestCronRunService_SyncRuns(t *testing.T) {
+	proj := newTestProject(t, user.NewUserID(), "reports", func(proj *project.Project) error {
+		if err := proj.SetType("CRON"); err != nil {
+			return err
+		}
+		return proj.SetSchedule("rate(1 hour)")
+	})
+	dep := newTestDeployment(t, proj, project.EnvironmentProduction, deployment.StatusDeployed)
+
+	started := time.Now().Add(-time.Minute)
+	source := &mockScheduledTasks{reports: []cronrun.TaskReport{
+		{TaskARN: "arn:aws:ecs:us-east-1:123456789012:task/cluster/running", DeploymentID: dep.ID().String(), StartedAt: started},
+		// Started by a schedule that predates run history
+		{TaskARN: "arn:aws:ecs:us-east-1:123456789012:task/cluster/untagged", StartedAt: started},
+	}}
+	runs := &mockCronRuns{runs: map[string]*cronrun.Run{}}
+
+	svc := service.NewCronRunService(newMockProjectRepository(proj), newMockDeploymentRepository(dep), runs)
+	if _, err := svc.SyncRuns(context.Background()); !errors.Is(err, service.ErrCronRunsUnavailable) {
+		t.Fatalf("SyncRuns() without a source error = %v, want %v", err, service.ErrCronRunsUnavailable)
+	}
+	svc.SetScheduledTaskSource(source)
+
+	if synced, err := svc.SyncRuns(context.Background()); err != nil || synced != 1 {
+		t.Fatalf("SyncRuns() = %d, %v, want 1 run recorded", synced, err)
+	}
+
+	// The running task stops with an error
+	exitCode := 2
+	stopped := time.Now()
+	source.reports[0].Stopped = true
+	source.reports[0].ExitCode = &exitCode
+	source.reports[0].StoppedAt = &stopped
+	if synced, err := svc.SyncRuns(context.Background()); err != nil || synced != 1 {
+		t.Fatalf("SyncRuns() = %d, %v, want 1 run updated", synced, err)
+	}
+
+	// Finished runs aren't saved again
+	if synced, err := svc.SyncRuns(context.Background()); err != nil || synced != 0 {
+		t.Fatalf("SyncRuns() = %d, %v, want no runs updated", synced, err)
+	}
+	if runs.saves != 2 {
+		t.Errorf("saves = %d, want 2", runs.saves)
+	}
+
+	response, err := svc.ListRuns(context.Background(), dep.ID().String())
+	if err != nil {
+		t.Fatalf("ListRuns() error = %v", err)
+	}
+	if len(response.Runs) != 1 {
+		t.Fatalf("ListRuns() returned %d runs, want 1", len(response.Runs))
+	}
+	run := response.Runs[0]
+	if run.Status != "FAILED" || run.ExitCode == nil || *run.ExitCode != 2 || run.StoppedAt == "" {
+		t.Errorf("run = %+v, want FAILED with exit code 2", run)
+	}
+}
@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/dbbranch"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/infrastructure/database"
+)
+
+// ErrNothingToSnapshot is returned when a project's database hasn't been created by a deployment yet
+var ErrNothingToSnapshot = errors.New("project database has not been created yet")
+
+// DatabaseCopier copies project databases to snapshots and restores snapshots into branches
+type DatabaseCopier interface {
+	DatabaseExists(ctx context.Context, dbName string) (bool, error)
+	// SnapshotDatabase copies a database to a snapshot and returns the snapshot's size
+	SnapshotDatabase(ctx context.Context, dbName, snapshotName string) (int64, error)
+	RestoreSnapshot(ctx context.Context, snapshotName, branchName string) error
+	DropDatabase(ctx context.Context, dbName string) error
+}
+
+// DatabaseBranchService handles snapshots of project databases and the branches restored from them,
+// which give preview environments a copy of a project's data
+type DatabaseBranchService struct {
+	projectRepo  project.ProjectRepository
+	snapshotRepo dbbranch.SnapshotRepository
+	branchRepo   dbbranch.BranchRepository
+	copier       DatabaseCopier
+}
+
+// NewDatabaseBranchService creates a new database branch service
+func NewDatabaseBranchService(
+	projectRepo project.ProjectRepository,
+	snapshotRepo dbbranch.SnapshotRepository,
+	branchRepo dbbranch.BranchRepository,
+) *DatabaseBranchService {
+	return &DatabaseBranchService{
+		projectRepo:  projectRepo,
+		snapshotRepo: snapshotRepo,
+		branchRepo:   branchRepo,
+	}
+}
+
+// SetDatabaseCopier sets the component that copies project databases (optional)
+func (s *DatabaseBranchService) SetDatabaseCopier(copier DatabaseCopier) {
+	s.copier = copier
+}
+
+// CreateSnapshot copies a project's database to a new snapshot
+func (s *DatabaseBranchService) CreateSnapshot(ctx context.Context, projectID string, req *dto.CreateDatabaseSnapshotRequest) (*dto.DatabaseSnapshotResponse, error) {
+	proj, err := findProject(ctx, s.projectRepo, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkProject(proj); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.snapshotRepo.FindByProjectID(ctx, proj.ID())
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= dbbranch.MaxSnapshotsPerProject {
+		return nil, dbbranch.ErrSnapshotLimitReached
+	}
+
+	snapshot, err := dbbranch.NewSnapshot(proj.ID(), time.Duration(req.TTLHours)*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	dbName := database.GetDatabaseName(proj.ID().String())
+	exists, err := s.copier.DatabaseExists(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNothingToSnapshot
+	}
+
+	snapshotName := database.SnapshotDatabaseName(proj.ID().String(), snapshot.ID().String())
+	size, err := s.copier.SnapshotDatabase(ctx, dbName, snapshotName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	snapshot.Taken(snapshotName, size)
+
+	if err := s.snapshotRepo.Save(ctx, snapshot); err != nil {
+		s.drop(ctx, snapshotName)
+		return nil, err
+	}
+
+	return toSnapshotDTO(snapshot), nil
+}
+
+// ListSnapshots returns the snapshots of a project's database, newest first
+func (s *DatabaseBranchService) ListSnapshots(ctx context.Context, projectID string) ([]*dto.DatabaseSnapshotResponse, error) {
+	proj, err := findProject(ctx, s.projectRepo, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := s.snapshotRepo.FindByProjectID(ctx, proj.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.DatabaseSnapshotResponse, len(snapshots))
+	for i, snapshot := range snapshots {
+		responses[i] = toSnapshotDTO(snapshot)
+	}
+	return responses, nil
+}
+
+// DeleteSnapshot drops a snapshot. Branches restored from it are kept
+func (s *DatabaseBranchService) DeleteSnapshot(ctx context.Context, projectID, snapshotID string) error {
+	snapshot, err := s.findSnapshot(ctx, projectID, snapshotID)
+	if err != nil {
+		return err
+	}
+
+	if s.copier == nil {
+		return ErrDatabaseUnavailable
+	}
+
+	return s.deleteSnapshot(ctx, snapshot)
+}
+
+// CreateBranch restores a snapshot into a new database
+func (s *DatabaseBranchService) CreateBranch(ctx context.Context, projectID string, req *dto.CreateDatabaseBranchRequest) (*dto.DatabaseBranchResponse, error) {
+	snapshot, err := s.findSnapshot(ctx, projectID, req.SnapshotID)
+	if err != nil {
+		return nil, err
+	}
+
+	proj, err := s.projectRepo.FindByID(ctx, snapshot.ProjectID())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkProject(proj); err != nil {
+		return nil, err
+	}
+
+	branch, err := dbbranch.NewBranch(snapshot, req.Name, time.Duration(req.TTLHours)*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.branchRepo.FindByProjectID(ctx, proj.ID())
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= dbbranch.MaxBranchesPerProject {
+		return nil, dbbranch.ErrBranchLimitReached
+	}
+	for _, b := range existing {
+		if b.Name() == branch.Name() {
+			return nil, dbbranch.ErrBranchAlreadyExists
+		}
+	}
+
+	branchName := database.BranchDatabaseName(proj.ID().String(), branch.ID().String())
+	if err := s.copier.RestoreSnapshot(ctx, snapshot.DatabaseName(), branchName); err != nil {
+		return nil, fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+	branch.Restored(branchName)
+
+	if err := s.branchRepo.Save(ctx, branch); err != nil {
+		s.drop(ctx, branchName)
+		return nil, err
+	}
+
+	return toBranchDTO(branch), nil
+}
+
+// ListBranches returns the branches of a project's database, newest first
+func (s *DatabaseBranchService) ListBranches(ctx context.Context, projectID string) ([]*dto.DatabaseBranchResponse, error) {
+	proj, err := findProject(ctx, s.projectRepo, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := s.branchRepo.FindByProjectID(ctx, proj.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.DatabaseBranchResponse, len(branches))
+	for i, branch := range branches {
+		responses[i] = toBranchDTO(branch)
+	}
+	return responses, nil
+}
+
+// DeleteBranch drops a branch
+func (s *DatabaseBranchService) DeleteBranch(ctx context.Context, projectID, branchID string) error {
+	proj, err := findProject(ctx, s.projectRepo, projectID)
+	if err != nil {
+		return err
+	}
+
+	bid, err := dbbranch.ParseBranchID(branchID)
+	if err != nil {
+		return dbbranch.ErrBranchNotFound
+	}
+
+	branch, err := s.branchRepo.FindByID(ctx, bid)
+	if err != nil {
+		return err
+	}
+	if !branch.ProjectID().Equals(proj.ID()) {
+		return dbbranch.ErrBranchNotFound
+	}
+
+	if s.copier == nil {
+		return ErrDatabaseUnavailable
+	}
+
+	return s.deleteBranch(ctx, branch)
+}
+
+// CleanupExpired drops the branches and snapshots whose TTL has run out, returning how many were dropped.
+// A copy that can't be dropped is logged and retried on the next run.
+func (s *DatabaseBranchService) CleanupExpired(ctx context.Context, now time.Time) (int, error) {
+	dropped := 0
+
+	branches, err := s.branchRepo.FindExpired(ctx, now)
+	if err != nil {
+		return dropped, err
+	}
+	for _, branch := range branches {
+		if err := s.deleteBranch(ctx, branch); err != nil {
+			slog.ErrorContext(ctx, "Failed to drop expired branch", "branch_id", branch.ID().String(), "error", err)
+			continue
+		}
+		dropped++
+	}
+
+	snapshots, err := s.snapshotRepo.FindExpired(ctx, now)
+	if err != nil {
+		return dropped, err
+	}
+	for _, snapshot := range snapshots {
+		if err := s.deleteSnapshot(ctx, snapshot); err != nil {
+			slog.ErrorContext(ctx, "Failed to drop expired snapshot", "snapshot_id", snapshot.ID().String(), "error", err)
+			continue
+		}
+		dropped++
+	}
+
+	return dropped, nil
+}
+
+// RunCleanup drops expired branches and snapshots at the given interval until the context is cancelled
+func (s *DatabaseBranchService) RunCleanup(ctx context.Context, interval time.Duration) {
+	if interval <= 0 || s.copier == nil {
+		slog.Info("Cleaning up expired database branches and snapshots disabled", "interval", interval)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			dropped, err := s.CleanupExpired(ctx, now)
+			if err != nil {
+				slog.ErrorContext(ctx, "Cleaning up expired database branches and snapshots failed", "dropped", dropped, "error", err)
+				continue
+			}
+			if dropped > 0 {
+				slog.InfoContext(ctx, "Dropped expired database branches and snapshots", "dropped", dropped)
+			}
+		}
+	}
+}
+
+// checkProject checks a project's database can be copied
+func (s *DatabaseBranchService) checkProject(proj *project.Project) error {
+	if proj.IsDeleting() {
+		return project.ErrProjectDeleting
+	}
+	if !proj.RequireDB() {
+		return project.ErrDatabaseNotRequired
+	}
+	if s.copier == nil {
+		return ErrDatabaseUnavailable
+	}
+	return nil
+}
+
+// findSnapshot loads a snapshot of a project's database
+func (s *DatabaseBranchService) findSnapshot(ctx context.Context, projectID, snapshotID string) (*dbbranch.Snapshot, error) {
+	proj, err := findProject(ctx, s.projectRepo, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	sid, err := dbbranch.ParseSnapshotID(snapshotID)
+	if err != nil {
+		return nil, dbbranch.ErrSnapshotNotFound
+	}
+
+	snapshot, err := s.snapshotRepo.FindByID(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	// Expired snapshots are dropped by the next cleanup
+	if !snapshot.ProjectID().Equals(proj.ID()) || snapshot.IsExpired(time.Now()) {
+		return nil, dbbranch.ErrSnapshotNotFound
+	}
+
+	return snapshot, nil
+}
+
+// deleteSnapshot drops a snapshot's database, then forgets the snapshot
+func (s *DatabaseBranchService) deleteSnapshot(ctx context.Context, snapshot *dbbranch.Snapshot) error {
+	if err := s.copier.DropDatabase(ctx, snapshot.DatabaseName()); err != nil {
+		return err
+	}
+	return s.snapshotRepo.Delete(ctx, snapshot.ID())
+}
+
+// deleteBranch drops a branch's database, then forgets the branch
+func (s *DatabaseBranchService) deleteBranch(ctx context.Context, branch *dbbranch.Branch) error {
+	if err := s.copier.DropDatabase(ctx, branch.DatabaseName()); err != nil {
+		return err
+	}
+	return s.branchRepo.Delete(ctx, branch.ID())
+}
+
+// drop drops a copy that couldn't be recorded
+func (s *DatabaseBranchService) drop(ctx context.Context, dbName string) {
+	if err := s.copier.DropDatabase(ctx, dbName); err != nil {
+		slog.WarnContext(ctx, "Failed to drop unrecorded database copy", "database", dbName, "error", err)
+	}
+}
+
+func toSnapshotDTO(snapshot *dbbranch.Snapshot) *dto.DatabaseSnapshotResponse {
+	return &dto.DatabaseSnapshotResponse{
+		ID:           snapshot.ID().String(),
+		ProjectID:    snapshot.ProjectID().String(),
+		DatabaseName: snapshot.DatabaseName(),
+		SizeBytes:    snapshot.SizeBytes(),
+		CreatedAt:    snapshot.CreatedAt().Format(time.RFC3339),
+		ExpiresAt:    snapshot.ExpiresAt().Format(time.RFC3339),
+	}
+}
+
+func toBranchDTO(branch *dbbranch.Branch) *dto.DatabaseBranchResponse {
+	response := &dto.DatabaseBranchResponse{
+		ID:           branch.ID().String(),
+		ProjectID:    branch.ProjectID().String(),
+		Name:         branch.Name().String(),
+		DatabaseName: branch.DatabaseName(),
+		CreatedAt:    branch.CreatedAt().Format(time.RFC3339),
+		ExpiresAt:    branch.ExpiresAt().Format(time.RFC3339),
+	}
+	if id := branch.SnapshotID(); id != nil {
+		snapshotID := id.String()
+		response.SnapshotID = &snapshotID
+	}
+	return response
+}
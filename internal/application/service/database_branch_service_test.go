@@ -0,0 +1,252 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/dbbranch"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// Mock implementations
+type mockSnapshotRepository struct {
+	dbbranch.SnapshotRepository
+	snapshots []*dbbranch.Snapshot
+}
+
+func (m *mockSnapshotRepository) Save(ctx context.Context, snapshot *dbbranch.Snapshot) error {
+	m.snapshots = append(m.snapshots, snapshot)
+	return nil
+}
+
+func (m *mockSnapshotRepository) FindByID(ctx context.Context, id dbbranch.SnapshotID) (*dbbranch.Snapshot, error) {
+	for _, s := range m.snapshots {
+		if s.ID().Equals(id) {
+			return s, nil
+		}
+	}
+	return nil, dbbranch.ErrSnapshotNotFound
+}
+
+func (m *mockSnapshotRepository) FindByProjectID(ctx context.Context, projectID project.ProjectID) ([]*dbbranch.Snapshot, error) {
+	var snapshots []*dbbranch.Snapshot
+	for _, s := range m.snapshots {
+		if s.ProjectID().Equals(projectID) {
+			snapshots = append(snapshots, s)
+		}
+	}
+	return snapshots, nil
+}
+
+func (m *mockSnapshotRepository) FindExpired(ctx context.Context, before time.Time) ([]*dbbranch.Snapshot, error) {
+	var snapshots []*dbbranch.Snapshot
+	for _, s := range m.snapshots {
+		if s.ExpiresAt().Before(before) {
+			snapshots = append(snapshots, s)
+		}
+	}
+	return snapshots, nil
+}
+
+func (m *mockSnapshotRepository) Delete(ctx context.Context, id dbbranch.SnapshotID) error {
+	for i, s := range m.snapshots {
+		if s.ID().Equals(id) {
+			m.snapshots = append(m.snapshots[:i], m.snapshots[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+type mockBranchRepository struct {
+	dbbranch.BranchRepository
+	branches []*dbbranch.Branch
+}
+
+func (m *mockBranchRepository) Save(ctx context.Context, branch *dbbranch.Branch) error {
+	m.branches = append(m.branches, branch)
+	return nil
+}
+
+func (m *mockBranchRepository) FindByID(ctx context.Context, id dbbranch.BranchID) (*dbbranch.Branch, error) {
+	for _, b := range m.branches {
+		if b.ID().Equals(id) {
+			return b, nil
+		}
+	}
+	return nil, dbbranch.ErrBranchNotFound
+}
+
+func (m *mockBranchRepository) FindByProjectID(ctx context.Context, projectID project.ProjectID) ([]*dbbranch.Branch, error) {
+	var branches []*dbbranch.Branch
+	for _, b := range m.branches {
+		if b.ProjectID().Equals(projectID) {
+			branches = append(branches, b)
+		}
+	}
+	return branches, nil
+}
+
+func (m *mockBranchRepository) FindExpired(ctx context.Context, before time.Time) ([]*dbbranch.Branch, error) {
+	var branches []*dbbranch.Branch
+	for _, b := range m.branches {
+		if b.ExpiresAt().Before(before) {
+			branches = append(branches, b)
+		}
+	}
+	return branches, nil
+}
+
+func (m *mockBranchRepository) Delete(ctx context.Context, id dbbranch.BranchID) error {
+	for i, b := range m.branches {
+		if b.ID().Equals(id) {
+			m.branches = append(m.branches[:i], m.branches[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+type mockDatabaseCopier struct {
+	exists    bool
+	databases map[string]string // copy name -> source name
+	dropped   []string
+}
+
+func (m *mockDatabaseCopier) DatabaseExists(ctx context.Context, dbName string) (bool, error) {
+	return m.exists, nil
+}
+
+func (m *mockDatabaseCopier) SnapshotDatabase(ctx context.Context, dbName, snapshotName string) (int64, error) {
+	m.databases[snapshotName] = dbName
+	return 4096, nil
+}
+
+func (m *mockDatabaseCopier) RestoreSnapshot(ctx context.Context, snapshotName, branchName string) error {
+	m.databases[branchName] = snapshotName
+	return nil
+}
+
+func (m *mockDatabaseCopier) DropDatabase(ctx context.Context, dbName string) error {
+	delete(m.databases, dbName)
+	m.dropped = append(m.dropped, dbName)
+	return nil
+}
+
+func newBranchService(t *testing.T, setup ...func(*project.Project) error) (*service.DatabaseBranchService, *project.Project, *mockDatabaseCopier) {
+	t.Helper()
+	proj := newTestProject(t, user.NewUserID(), "shop", setup...)
+	copier := &mockDatabaseCopier{exists: true, databases: map[string]string{}}
+
+	svc := service.NewDatabaseBranchService(newMockProjectRepository(proj), &mockSnapshotRepository{}, &mockBranchRepository{})
+	svc.SetDatabaseCopier(copier)
+	return svc, proj, copier
+}
+
+func TestDatabaseBranchService_SnapshotAndBranch(t *testing.T) {
+	svc, proj, copier := newBranchService(t, requireDatabase)
+	ctx := context.Background()
+
+	snapshot, err := svc.CreateSnapshot(ctx, proj.ID().String(), &dto.CreateDatabaseSnapshotRequest{})
+	if err != nil {
+		t.Fatalf("CreateSnapshot() error = %v", err)
+	}
+	if snapshot.SizeBytes != 4096 {
+		t.Errorf("SizeBytes = %d, want 4096", snapshot.SizeBytes)
+	}
+	if _, ok := copier.databases[snapshot.DatabaseName]; !ok {
+		t.Fatalf("snapshot database %s was not created", snapshot.DatabaseName)
+	}
+
+	branch, err := svc.CreateBranch(ctx, proj.ID().String(), &dto.CreateDatabaseBranchRequest{
+		SnapshotID: snapshot.ID,
+		Name:       "pr-42",
+		TTLHours:   24,
+	})
+	if err != nil {
+		t.Fatalf("CreateBranch() error = %v", err)
+	}
+	if copier.databases[branch.DatabaseName] != snapshot.DatabaseName {
+		t.Errorf("branch restored from %q, want %q", copier.databases[branch.DatabaseName], snapshot.DatabaseName)
+	}
+	if branch.SnapshotID == nil || *branch.SnapshotID != snapshot.ID {
+		t.Errorf("SnapshotID = %v, want %s", branch.SnapshotID, snapshot.ID)
+	}
+
+	_, err = svc.CreateBranch(ctx, proj.ID().String(), &dto.CreateDatabaseBranchRequest{SnapshotID: snapshot.ID, Name: "PR-42"})
+	if !errors.Is(err, dbbranch.ErrBranchAlreadyExists) {
+		t.Errorf("CreateBranch() with a taken name error = %v, want %v", err, dbbranch.ErrBranchAlreadyExists)
+	}
+
+	if err := svc.DeleteBranch(ctx, proj.ID().String(), branch.ID); err != nil {
+		t.Fatalf("DeleteBranch() error = %v", err)
+	}
+	if _, ok := copier.databases[branch.DatabaseName]; ok {
+		t.Error("DeleteBranch() did not drop the branch database")
+	}
+}
+
+func TestDatabaseBranchService_RefusesUnsafeSnapshots(t *testing.T) {
+	ctx := context.Background()
+
+	svc, proj, _ := newBranchService(t)
+	if _, err := svc.CreateSnapshot(ctx, proj.ID().String(), &dto.CreateDatabaseSnapshotRequest{}); !errors.Is(err, project.ErrDatabaseNotRequired) {
+		t.Errorf("CreateSnapshot() without a database error = %v, want %v", err, project.ErrDatabaseNotRequired)
+	}
+
+	svc, proj, copier := newBranchService(t, requireDatabase)
+	copier.exists = false
+	if _, err := svc.CreateSnapshot(ctx, proj.ID().String(), &dto.CreateDatabaseSnapshotRequest{}); !errors.Is(err, service.ErrNothingToSnapshot) {
+		t.Errorf("CreateSnapshot() before the first deployment error = %v, want %v", err, service.ErrNothingToSnapshot)
+	}
+
+	copier.exists = true
+	for i := 0; i < dbbranch.MaxSnapshotsPerProject; i++ {
+		if _, err := svc.CreateSnapshot(ctx, proj.ID().String(), &dto.CreateDatabaseSnapshotRequest{}); err != nil {
+			t.Fatalf("CreateSnapshot() error = %v", err)
+		}
+	}
+	if _, err := svc.CreateSnapshot(ctx, proj.ID().String(), &dto.CreateDatabaseSnapshotRequest{}); !errors.Is(err, dbbranch.ErrSnapshotLimitReached) {
+		t.Errorf("CreateSnapshot() over the limit error = %v, want %v", err, dbbranch.ErrSnapshotLimitReached)
+	}
+
+	unavailable := service.NewDatabaseBranchService(newMockProjectRepository(proj), &mockSnapshotRepository{}, &mockBranchRepository{})
+	if _, err := unavailable.CreateSnapshot(ctx, proj.ID().String(), &dto.CreateDatabaseSnapshotRequest{}); !errors.Is(err, service.ErrDatabaseUnavailable) {
+		t.Errorf("CreateSnapshot() without a copier error = %v, want %v", err, service.ErrDatabaseUnavailable)
+	}
+}
+
+func TestDatabaseBranchService_CleanupExpired(t *testing.T) {
+	svc, proj, copier := newBranchService(t, requireDatabase)
+	ctx := context.Background()
+
+	snapshot, err := svc.CreateSnapshot(ctx, proj.ID().String(), &dto.CreateDatabaseSnapshotRequest{TTLHours: 48})
+	if err != nil {
+		t.Fatalf("CreateSnapshot() error = %v", err)
+	}
+	branch, err := svc.CreateBranch(ctx, proj.ID().String(), &dto.CreateDatabaseBranchRequest{SnapshotID: snapshot.ID, Name: "pr-1", TTLHours: 1})
+	if err != nil {
+		t.Fatalf("CreateBranch() error = %v", err)
+	}
+
+	dropped, err := svc.CleanupExpired(ctx, time.Now().Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("CleanupExpired() error = %v", err)
+	}
+	if dropped != 1 || len(copier.dropped) != 1 || copier.dropped[0] != branch.DatabaseName {
+		t.Errorf("CleanupExpired() dropped %v, want only branch %s", copier.dropped, branch.DatabaseName)
+	}
+
+	dropped, err = svc.CleanupExpired(ctx, time.Now().Add(72*time.Hour))
+	if err != nil {
+		t.Fatalf("CleanupExpired() error = %v", err)
+	}
+	if dropped != 1 || len(copier.databases) != 0 {
+		t.Errorf("CleanupExpired() left %v, want every copy dropped", copier.databases)
+	}
+}
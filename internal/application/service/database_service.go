@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/infrastructure/database"
+)
+
+// ErrDatabaseUnavailable is returned when no database manager is configured
+var ErrDatabaseUnavailable = errors.New("databases are unavailable")
+
+// ProjectDatabaseManager manages the Postgres databases of projects that require one
+type ProjectDatabaseManager interface {
+	DescribeDatabase(ctx context.Context, dbName string) (*database.DatabaseInfo, error)
+	// ResetDatabase backs up a database and replaces it with an empty one, returning the backup's name
+	ResetDatabase(ctx context.Context, dbName string) (string, error)
+}
+
+// DatabaseService handles project database use cases
+type DatabaseService struct {
+	projectRepo    project.ProjectRepository
+	deploymentRepo deployment.DeploymentRepository
+	manager        ProjectDatabaseManager
+}
+
+// NewDatabaseService creates a new database service
+func NewDatabaseService(projectRepo project.ProjectRepository, deploymentRepo deployment.DeploymentRepository) *DatabaseService {
+	return &DatabaseService{
+		projectRepo:    projectRepo,
+		deploymentRepo: deploymentRepo,
+	}
+}
+
+// SetDatabaseManager sets the manager of project databases (optional)
+func (s *DatabaseService) SetDatabaseManager(manager ProjectDatabaseManager) {
+	s.manager = manager
+}
+
+// GetProjectDatabase returns a project's database and its backups
+func (s *DatabaseService) GetProjectDatabase(ctx context.Context, projectID string) (*dto.ProjectDatabaseResponse, error) {
+	proj, err := findProject(ctx, s.projectRepo, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.manager == nil {
+		return nil, ErrDatabaseUnavailable
+	}
+
+	info, err := s.manager.DescribeDatabase(ctx, database.GetDatabaseName(proj.ID().String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe database: %w", err)
+	}
+
+	return toDatabaseDTO(proj, info), nil
+}
+
+// ResetProjectDatabase backs up a project's database and replaces it with an empty one.
+// Migrations run against the empty database on the project's next deployment.
+func (s *DatabaseService) ResetProjectDatabase(ctx context.Context, projectID string) (*dto.ResetDatabaseResponse, error) {
+	proj, err := findProject(ctx, s.projectRepo, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if proj.IsDeleting() {
+		return nil, project.ErrProjectDeleting
+	}
+	if !proj.RequireDB() {
+		return nil, project.ErrDatabaseNotRequired
+	}
+
+	if s.manager == nil {
+		return nil, ErrDatabaseUnavailable
+	}
+
+	// A deployment in progress may be running migrations against the database
+	latest, err := s.deploymentRepo.FindLatestByProjectID(ctx, proj.ID())
+	if err != nil && !errors.Is(err, deployment.ErrDeploymentNotFound) {
+		return nil, err
+	}
+	if latest != nil && !latest.Status().IsTerminal() {
+		return nil, deployment.ErrDeploymentInProgress
+	}
+
+	dbName := database.GetDatabaseName(proj.ID().String())
+	backup, err := s.manager.ResetDatabase(ctx, dbName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reset database: %w", err)
+	}
+
+	info, err := s.manager.DescribeDatabase(ctx, dbName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe database: %w", err)
+	}
+
+	return &dto.ResetDatabaseResponse{
+		Database: toDatabaseDTO(proj, info),
+		Backup:   backup,
+	}, nil
+}
+
+// findProject loads a project by its ID
+func findProject(ctx context.Context, projectRepo project.ProjectRepository, projectID string) (*project.Project, error) {
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	return projectRepo.FindByID(ctx, pid)
+}
+
+func toDatabaseDTO(proj *project.Project, info *database.DatabaseInfo) *dto.ProjectDatabaseResponse {
+	response := &dto.ProjectDatabaseResponse{
+		ProjectID: proj.ID().String(),
+		Required:  proj.RequireDB(),
+		Name:      info.Name,
+		Exists:    info.Exists,
+		SizeBytes: info.SizeBytes,
+		Backups:   make([]*dto.DatabaseBackupResponse, 0, len(info.Backups)),
+	}
+	for _, b := range info.Backups {
+		response.Backups = append(response.Backups, &dto.DatabaseBackupResponse{
+			Name:      b.Name,
+			CreatedAt: b.CreatedAt.Format(time.RFC3339),
+			SizeBytes: b.SizeBytes,
+		})
+	}
+	return response
+}
@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+	"snapdeploy-core/internal/infrastructure/database"
+)
+
+// Mock implementations
+type mockDatabaseManager struct {
+	resets []string
+}
+
+func (m *mockDatabaseManager) DescribeDatabase(ctx context.Context, dbName string) (*database.DatabaseInfo, error) {
+	info := &database.DatabaseInfo{Name: dbName, Exists: true}
+	for _, name := range m.resets {
+		info.Backups = append(info.Backups, database.Backup{Name: name + "_bak_20251124101500"})
+	}
+	return info, nil
+}
+
+func (m *mockDatabaseManager) ResetDatabase(ctx context.Context, dbName string) (string, error) {
+	m.resets = append(m.resets, dbName)
+	return dbName + "_bak_20251124101500", nil
+}
+
+func TestDatabaseService_ResetBacksUpDatabase(t *testing.T) {
+	owner := user.NewUserID()
+	proj := newTestProject(t, owner, "shop", requireDatabase)
+	manager := &mockDatabaseManager{}
+
+	svc := service.NewDatabaseService(newMockProjectRepository(proj), newMockDeploymentRepository())
+	svc.SetDatabaseManager(manager)
+
+	response, err := svc.ResetProjectDatabase(context.Background(), proj.ID().String())
+	if err != nil {
+		t.Fatalf("ResetProjectDatabase() error = %v", err)
+	}
+
+	dbName := database.GetDatabaseName(proj.ID().String())
+	if len(manager.resets) != 1 || manager.resets[0] != dbName {
+		t.Errorf("ResetProjectDatabase() reset %v, want [%s]", manager.resets, dbName)
+	}
+	if response.Backup != dbName+"_bak_20251124101500" {
+		t.Errorf("ResetProjectDatabase() backup = %q, want %q", response.Backup, dbName+"_bak_20251124101500")
+	}
+	if len(response.Database.Backups) != 1 || !response.Database.Required {
+		t.Errorf("ResetProjectDatabase() database = %+v, want a required database with 1 backup", response.Database)
+	}
+}
+
+func TestDatabaseService_ResetRefusesUnsafeResets(t *testing.T) {
+	owner := user.NewUserID()
+
+	withDB := newTestProject(t, owner, "shop", requireDatabase)
+	running := newTestDeployment(t, withDB, project.EnvironmentProduction, deployment.StatusBuilding)
+
+	tests := []struct {
+		name        string
+		proj        *project.Project
+		deployments []*deployment.Deployment
+		wantErr     error
+	}{
+		{name: "no database", proj: newTestProject(t, owner, "shop"), wantErr: project.ErrDatabaseNotRequired},
+		{name: "deployment in progress", proj: withDB, deployments: []*deployment.Deployment{running}, wantErr: deployment.ErrDeploymentInProgress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := &mockDatabaseManager{}
+			svc := service.NewDatabaseService(newMockProjectRepository(tt.proj), newMockDeploymentRepository(tt.deployments...))
+			svc.SetDatabaseManager(manager)
+
+			_, err := svc.ResetProjectDatabase(context.Background(), tt.proj.ID().String())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResetProjectDatabase() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(manager.resets) != 0 {
+				t.Errorf("ResetProjectDatabase() reset %v, want nothing reset", manager.resets)
+			}
+		})
+	}
+}
+
+func TestDatabaseService_WithoutManager(t *testing.T) {
+	owner := user.NewUserID()
+	proj := newTestProject(t, owner, "shop", requireDatabase)
+
+	svc := service.NewDatabaseService(newMockProjectRepository(proj), newMockDeploymentRepository())
+
+	if _, err := svc.GetProjectDatabase(context.Background(), proj.ID().String()); !errors.Is(err, service.ErrDatabaseUnavailable) {
+		t.Errorf("GetProjectDatabase() error = %v, want %v", err, service.ErrDatabaseUnavailable)
+	}
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/domain/validation"
+)
+
+// ErrInvalidComparison is returned when the deployments to compare aren't given as deployment IDs
+var ErrInvalidComparison = validation.New("invalid deployment comparison")
+
+// CommitComparisonSource compares commits of a project's repository
+type CommitComparisonSource interface {
+	CompareRepositoryCommits(ctx context.Context, proj *project.Project, base, head string) (*repo.CommitComparison, error)
+}
+
+// DeploymentCompareService answers what changed between two deployments of a project: the commits, the
+// environment variables and the settings they were built with
+type DeploymentCompareService struct {
+	deploymentRepo deployment.DeploymentRepository
+	projectRepo    project.ProjectRepository
+	manifestRepo   deployment.ManifestRepository
+	commits        CommitComparisonSource
+}
+
+// NewDeploymentCompareService creates a new deployment compare service
+func NewDeploymentCompareService(
+	deploymentRepo deployment.DeploymentRepository,
+	projectRepo project.ProjectRepository,
+	manifestRepo deployment.ManifestRepository,
+) *DeploymentCompareService {
+	return &DeploymentCompareService{
+		deploymentRepo: deploymentRepo,
+		projectRepo:    projectRepo,
+		manifestRepo:   manifestRepo,
+	}
+}
+
+// SetCommitComparisonSource sets where the commits between two deployments are read from (optional)
+func (s *DeploymentCompareService) SetCommitComparisonSource(source CommitComparisonSource) {
+	s.commits = source
+}
+
+// CompareDeployments returns what changed from one deployment of a project to another. Parts that can't be
+// compared, such as the commits when the Git provider is unavailable, are left out with a warning.
+// Returns deployment.ErrDeploymentNotFound if either deployment doesn't belong to the project.
+func (s *DeploymentCompareService) CompareDeployments(ctx context.Context, projectID, fromID, toID string) (*dto.DeploymentComparisonResponse, error) {
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+	from, err := s.findDeployment(ctx, pid, fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.findDeployment(ctx, pid, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	fromManifest, err := s.findManifest(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	toManifest, err := s.findManifest(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.DeploymentComparisonResponse{
+		ProjectID: pid.String(),
+		From:      toComparedDeployment(from, fromManifest),
+		To:        toComparedDeployment(to, toManifest),
+	}
+
+	commits, err := s.compareCommits(ctx, from, to)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to compare deployment commits", "project_id", pid.String(), "error", err)
+		response.Warnings = append(response.Warnings, err.Error())
+	}
+	response.Commits = commits
+
+	if fromManifest == nil || toManifest == nil {
+		response.Warnings = append(response.Warnings,
+			"environment variables and settings aren't recorded for deployments whose build never started")
+		return response, nil
+	}
+
+	changes := fromManifest.Diff(toManifest)
+	response.EnvVars = &dto.EnvVarChangesResponse{
+		Added:   nonNilStrings(changes.EnvVarsAdded),
+		Removed: nonNilStrings(changes.EnvVarsRemoved),
+		Changed: nonNilStrings(changes.EnvVarsChanged),
+	}
+	response.Config = make([]*dto.ConfigChangeResponse, len(changes.Config))
+	for i, change := range changes.Config {
+		response.Config[i] = &dto.ConfigChangeResponse{Key: change.Key, From: change.From, To: change.To}
+	}
+
+	return response, nil
+}
+
+// findDeployment retrieves a deployment of a project, archived and deleted ones included
+func (s *DeploymentCompareService) findDeployment(ctx context.Context, projectID project.ProjectID, deploymentID string) (*deployment.Deployment, error) {
+	did, err := deployment.ParseDeploymentID(deploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidComparison, err)
+	}
+
+	dep, err := s.deploymentRepo.FindByIDIncludingDeleted(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+	if !dep.BelongsToProject(projectID) {
+		return nil, deployment.ErrDeploymentNotFound
+	}
+	return dep, nil
+}
+
+// findManifest retrieves a deployment's manifest, nil if none was recorded
+func (s *DeploymentCompareService) findManifest(ctx context.Context, dep *deployment.Deployment) (*deployment.Manifest, error) {
+	manifest, err := s.manifestRepo.FindByDeploymentID(ctx, dep.ID())
+	if errors.Is(err, deployment.ErrManifestNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// compareCommits returns the commits deployed by to but not by from
+func (s *DeploymentCompareService) compareCommits(ctx context.Context, from, to *deployment.Deployment) (*dto.CommitRangeResponse, error) {
+	if from.CommitHash().Equals(to.CommitHash()) {
+		return &dto.CommitRangeResponse{Commits: []*dto.CommitResponse{}}, nil
+	}
+	// HEAD deployments record the branch they built, not the commit at its tip
+	for _, dep := range []*deployment.Deployment{from, to} {
+		if strings.EqualFold(dep.CommitHash().String(), "HEAD") {
+			return nil, fmt.Errorf("commits can't be compared, deployment %s built the tip of %s without recording its commit",
+				dep.ID(), dep.Branch())
+		}
+	}
+	if s.commits == nil {
+		return nil, errors.New("commits can't be compared, no Git provider is configured")
+	}
+
+	proj, err := s.projectRepo.FindByID(ctx, to.ProjectID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	comparison, err := s.commits.CompareRepositoryCommits(ctx, proj, from.CommitHash().String(), to.CommitHash().String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare commits: %w", err)
+	}
+
+	response := &dto.CommitRangeResponse{
+		Commits:   make([]*dto.CommitResponse, len(comparison.Commits)),
+		Truncated: comparison.Truncated,
+		URL:       comparison.HTMLURL,
+	}
+	for i, commit := range comparison.Commits {
+		response.Commits[i] = &dto.CommitResponse{
+			SHA:         commit.SHA,
+			Message:     commit.Message,
+			Author:      commit.AuthorName,
+			CommittedAt: commit.CommittedAt.Format(time.RFC3339),
+			URL:         commit.HTMLURL,
+		}
+	}
+	return response, nil
+}
+
+// toComparedDeployment converts a deployment and its manifest, if any, to one side of a comparison
+func toComparedDeployment(dep *deployment.Deployment, manifest *deployment.Manifest) *dto.ComparedDeploymentResponse {
+	response := &dto.ComparedDeploymentResponse{
+		ID:          dep.ID().String(),
+		CommitHash:  dep.CommitHash().String(),
+		Branch:      dep.Branch().String(),
+		Environment: dep.Environment().String(),
+		Status:      dep.Status().String(),
+		CreatedAt:   dep.CreatedAt().Format(time.RFC3339),
+	}
+	if manifest != nil {
+		response.ImageTag = manifest.ImageTag
+	}
+	return response
+}
+
+// nonNilStrings returns values, or an empty slice if it is nil so it is encoded as [] rather than null
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,149 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/domain/user"
+)
+
+type mockManifests struct {
+	manifests map[string]*deployment.Manifest
+}
+
+func (m *mockManifests) Save(ctx context.Context, manifest *deployment.Manifest) error {
+	m.manifests[manifest.DeploymentID.String()] = manifest
+	return nil
+}
+
+func (m *mockManifests) FindByDeploymentID(ctx context.Context, deploymentID deployment.DeploymentID) (*deployment.Manifest, error) {
+	manifest, ok := m.manifests[deploymentID.String()]
+	if !ok {
+		return nil, deployment.ErrManifestNotFound
+	}
+	return manifest, nil
+}
+
+type mockCommitComparisons struct {
+	compared string // base...head of the last comparison
+}
+
+func (m *mockCommitComparisons) CompareRepositoryCommits(ctx context.Context, proj *project.Project, base, head string) (*repo.CommitComparison, error) {
+	m.compared = base + "..." + head
+	return &repo.CommitComparison{
+		Commits: []*repo.Commit{{SHA: "def5678", Message: "Add billing"}},
+		HTMLURL: "https://github.com/acme/app/compare/" + m.compared,
+	}, nil
+}
+
+// compareFixture is a project deployed twice, with a manifest recorded for the earlier deployment only
+type compareFixture struct {
+	svc       *service.DeploymentCompareService
+	commits   *mockCommitComparisons
+	manifests *mockManifests
+	from, to  *deployment.Deployment
+}
+
+func newCompareFixture(t *testing.T, toCommit string) *compareFixture {
+	t.Helper()
+	proj := newTestProject(t, user.NewUserID(), "my-app")
+	from, err := deployment.NewDeployment(proj.ID(), proj.UserID(), "abc1234", "main", project.EnvironmentProduction)
+	if err != nil {
+		t.Fatalf("NewDeployment() error = %v", err)
+	}
+	to, err := deployment.NewDeployment(proj.ID(), proj.UserID(), toCommit, "main", project.EnvironmentProduction)
+	if err != nil {
+		t.Fatalf("NewDeployment() error = %v", err)
+	}
+
+	manifests := &mockManifests{manifests: map[string]*deployment.Manifest{
+		from.ID().String(): {
+			DeploymentID: from.ID(),
+			ImageTag:     "registry/app:abc1234",
+			EnvVars:      map[string]string{"API_KEY": "digest-1", "DEBUG": "digest-2"},
+			Config:       map[string]string{"port": "3000"},
+		},
+	}}
+	commits := &mockCommitComparisons{}
+
+	svc := service.NewDeploymentCompareService(newMockDeploymentRepository(from, to), newMockProjectRepository(proj), manifests)
+	svc.SetCommitComparisonSource(commits)
+	return &compareFixture{svc: svc, commits: commits, manifests: manifests, from: from, to: to}
+}
+
+func TestDeploymentCompareService_CompareDeployments(t *testing.T) {
+	f := newCompareFixture(t, "def5678")
+	f.manifests.Save(context.Background(), &deployment.Manifest{
+		DeploymentID: f.to.ID(),
+		ImageTag:     "registry/app:def5678",
+		EnvVars:      map[string]string{"API_KEY": "digest-3", "STRIPE_KEY": "digest-4"},
+		Config:       map[string]string{"port": "8080"},
+	})
+
+	resp, err := f.svc.CompareDeployments(context.Background(), f.from.ProjectID().String(), f.from.ID().String(), f.to.ID().String())
+	if err != nil {
+		t.Fatalf("CompareDeployments() error = %v", err)
+	}
+
+	if f.commits.compared != "abc1234...def5678" {
+		t.Errorf("compared commits %q, want abc1234...def5678", f.commits.compared)
+	}
+	if resp.Commits == nil || len(resp.Commits.Commits) != 1 || resp.Commits.Commits[0].SHA != "def5678" {
+		t.Errorf("Commits = %+v, want the commit between the deployments", resp.Commits)
+	}
+	if resp.From.ImageTag != "registry/app:abc1234" || resp.To.ImageTag != "registry/app:def5678" {
+		t.Errorf("image tags = %q, %q", resp.From.ImageTag, resp.To.ImageTag)
+	}
+	if resp.EnvVars == nil ||
+		!reflect.DeepEqual(resp.EnvVars.Added, []string{"STRIPE_KEY"}) ||
+		!reflect.DeepEqual(resp.EnvVars.Removed, []string{"DEBUG"}) ||
+		!reflect.DeepEqual(resp.EnvVars.Changed, []string{"API_KEY"}) {
+		t.Errorf("EnvVars = %+v", resp.EnvVars)
+	}
+	if len(resp.Config) != 1 || resp.Config[0].Key != "port" || resp.Config[0].From != "3000" || resp.Config[0].To != "8080" {
+		t.Errorf("Config = %+v, want port 3000 -> 8080", resp.Config)
+	}
+	if len(resp.Warnings) != 0 {
+		t.Errorf("Warnings = %v, want none", resp.Warnings)
+	}
+}
+
+func TestDeploymentCompareService_CompareDeploymentsPartial(t *testing.T) {
+	// The later deployment built the tip of its branch and never started its build
+	f := newCompareFixture(t, "HEAD")
+
+	resp, err := f.svc.CompareDeployments(context.Background(), f.from.ProjectID().String(), f.from.ID().String(), f.to.ID().String())
+	if err != nil {
+		t.Fatalf("CompareDeployments() error = %v", err)
+	}
+
+	if f.commits.compared != "" {
+		t.Errorf("compared commits %q, want no comparison of a HEAD deployment", f.commits.compared)
+	}
+	if resp.Commits != nil || resp.EnvVars != nil || resp.Config != nil {
+		t.Errorf("response = %+v, want commits, env vars and config left out", resp)
+	}
+	if len(resp.Warnings) != 2 {
+		t.Errorf("Warnings = %v, want one for the commits and one for the manifest", resp.Warnings)
+	}
+}
+
+func TestDeploymentCompareService_CompareDeploymentsOfAnotherProject(t *testing.T) {
+	f := newCompareFixture(t, "def5678")
+
+	_, err := f.svc.CompareDeployments(context.Background(), project.NewProjectID().String(), f.from.ID().String(), f.to.ID().String())
+	if !errors.Is(err, deployment.ErrDeploymentNotFound) {
+		t.Errorf("CompareDeployments() error = %v, want ErrDeploymentNotFound", err)
+	}
+
+	_, err = f.svc.CompareDeployments(context.Background(), f.from.ProjectID().String(), "latest", f.to.ID().String())
+	if !errors.Is(err, service.ErrInvalidComparison) {
+		t.Errorf("CompareDeployments() error = %v, want ErrInvalidComparison", err)
+	}
+}
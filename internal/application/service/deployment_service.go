@@ -2,77 +2,957 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
 	"snapdeploy-core/internal/application/dto"
 	"snapdeploy-core/internal/domain/deployment"
 	"snapdeploy-core/internal/domain/project"
 	"snapdeploy-core/internal/domain/user"
+	"snapdeploy-core/internal/domain/validation"
+	"snapdeploy-core/internal/infrastructure/builder"
+	"snapdeploy-core/internal/logging"
+	"snapdeploy-core/internal/tracing"
 )
 
+// archiveBatchSize bounds how many deployments are moved per statement to keep transactions short
+const archiveBatchSize = 500
+
+// reapBatchSize bounds how many stuck deployments are failed per reaper run
+const reapBatchSize = 100
+
+// ErrInvalidListFilter is returned when a list of deployments is filtered or sorted by values it doesn't support
+var ErrInvalidListFilter = validation.New("invalid list filter")
+
+// ServiceRestarter restarts the running service of a project without rebuilding its image
+type ServiceRestarter interface {
+	RestartService(ctx context.Context, proj *project.Project, dep *deployment.Deployment) error
+}
+
+// DeploymentResumer deploys the image a failed deployment already pushed again, picking up from a pipeline step
+type DeploymentResumer interface {
+	ResumeDeployment(ctx context.Context, proj *project.Project, dep *deployment.Deployment, from deployment.Step) error
+}
+
+// BuildAdmission decides whether a user may queue another build
+type BuildAdmission interface {
+	Admit(ctx context.Context, userID user.UserID) error
+}
+
+// BuildPlanner works out what a deployment would be built with, without building it
+type BuildPlanner interface {
+	PlanBuild(ctx context.Context, proj *project.Project, dep *deployment.Deployment) (*BuildPlan, error)
+}
+
+// InfrastructurePlanner works out the changes deploying an image would make to a project's cloud resources,
+// without making them
+type InfrastructurePlanner interface {
+	PlanDeployment(ctx context.Context, proj *project.Project, env project.Environment, imageURI string) ([]deployment.PlannedChange, error)
+}
+
 // DeploymentService handles deployment-related use cases
 type DeploymentService struct {
 	deploymentRepo deployment.DeploymentRepository
 	projectRepo    project.ProjectRepository
+	buildJobRepo   deployment.BuildJobRepository
+	approvalRepo   deployment.ApprovalRepository
+	uow            UnitOfWork
+	restarter      ServiceRestarter
+	resumer        DeploymentResumer
+	stepRepo       deployment.StepRepository
+	admission      BuildAdmission
+	buildPlanner   BuildPlanner
+	infraPlanner   InfrastructurePlanner
+	logSecrets     LogSecretSource
 }
 
 // NewDeploymentService creates a new deployment service
 func NewDeploymentService(
 	deploymentRepo deployment.DeploymentRepository,
 	projectRepo project.ProjectRepository,
+	buildJobRepo deployment.BuildJobRepository,
+	approvalRepo deployment.ApprovalRepository,
+	uow UnitOfWork,
 ) *DeploymentService {
 	return &DeploymentService{
 		deploymentRepo: deploymentRepo,
 		projectRepo:    projectRepo,
+		buildJobRepo:   buildJobRepo,
+		approvalRepo:   approvalRepo,
+		uow:            uow,
+	}
+}
+
+// SetServiceRestarter sets the component used to restart running services
+func (s *DeploymentService) SetServiceRestarter(restarter ServiceRestarter) {
+	s.restarter = restarter
+}
+
+// SetDeploymentResumer sets the component deploying the images of retried deployments again (optional).
+// Without one, failed deployments can only be retried by rebuilding them.
+func (s *DeploymentService) SetDeploymentResumer(resumer DeploymentResumer) {
+	s.resumer = resumer
+}
+
+// SetStepRepository sets where the pipeline steps of deployments are recorded (optional). Without one,
+// failed deployments are retried from their first step unless told otherwise.
+func (s *DeploymentService) SetStepRepository(steps deployment.StepRepository) {
+	s.stepRepo = steps
+}
+
+// SetBuildAdmission sets the check that turns deployments away when builds are saturated (optional)
+func (s *DeploymentService) SetBuildAdmission(admission BuildAdmission) {
+	s.admission = admission
+}
+
+// SetLogSecretSource sets the source of the values masked in the logs build agents report (optional)
+func (s *DeploymentService) SetLogSecretSource(source LogSecretSource) {
+	s.logSecrets = source
+}
+
+// SetDeploymentPlanners sets what works out the build and the cloud changes of dry-run deployments. The
+// infrastructure planner is nil when deployments only build images.
+func (s *DeploymentService) SetDeploymentPlanners(build BuildPlanner, infra InfrastructurePlanner) {
+	s.buildPlanner = build
+	s.infraPlanner = infra
+}
+
+// ArchiveDeployments moves finished deployments created before the cutoff out of the active table.
+// History endpoints keep returning them; each project's latest successful deployment is kept active.
+func (s *DeploymentService) ArchiveDeployments(ctx context.Context, cutoff time.Time) (int64, error) {
+	var total int64
+	for {
+		archived, err := s.deploymentRepo.ArchiveBefore(ctx, cutoff, archiveBatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += archived
+		if archived < archiveBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// RunArchiver archives deployments older than the given number of months on every interval until the context is cancelled
+func (s *DeploymentService) RunArchiver(ctx context.Context, interval time.Duration, afterMonths int) {
+	if interval <= 0 || afterMonths <= 0 {
+		slog.Info("Deployment archiving disabled", "interval", interval, "after_months", afterMonths)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			archived, err := s.ArchiveDeployments(ctx, now.AddDate(0, -afterMonths, 0))
+			if err != nil {
+				slog.ErrorContext(ctx, "Deployment archiving failed", "archived", archived, "error", err)
+				continue
+			}
+			if archived > 0 {
+				slog.InfoContext(ctx, "Archived deployments", "archived", archived, "after_months", afterMonths)
+			}
+		}
+	}
+}
+
+// ReapStuckDeployments fails in-progress deployments that have made no progress within the timeout,
+// so a crashed build or deploy no longer blocks the project's next deployment
+func (s *DeploymentService) ReapStuckDeployments(ctx context.Context, timeout time.Duration) (int, error) {
+	stuck, err := s.deploymentRepo.FindStuck(ctx, time.Now().Add(-timeout), reapBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	reaped := 0
+	for _, dep := range stuck {
+		if err := dep.TimeOut(timeout); err != nil {
+			slog.ErrorContext(ctx, "Failed to time out deployment", "deployment_id", dep.ID().String(), "error", err)
+			continue
+		}
+		if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+			slog.ErrorContext(ctx, "Failed to save timed out deployment", "deployment_id", dep.ID().String(), "error", err)
+			continue
+		}
+		slog.WarnContext(ctx, "Deployment timed out", "deployment_id", dep.ID().String(), "project_id", dep.ProjectID().String(), "timeout", timeout)
+		reaped++
+	}
+	return reaped, nil
+}
+
+// RunReaper fails deployments stuck in progress for longer than the timeout on every interval until the context is cancelled
+func (s *DeploymentService) RunReaper(ctx context.Context, interval, timeout time.Duration) {
+	if interval <= 0 || timeout <= 0 {
+		slog.Info("Stuck deployment reaper disabled", "interval", interval, "timeout", timeout)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reaped, err := s.ReapStuckDeployments(ctx, timeout)
+			if err != nil {
+				slog.ErrorContext(ctx, "Reaping stuck deployments failed", "error", err)
+				continue
+			}
+			if reaped > 0 {
+				slog.InfoContext(ctx, "Timed out stuck deployments", "count", reaped, "timeout", timeout)
+			}
+		}
 	}
 }
 
 // CreateDeployment creates a new deployment
 func (s *DeploymentService) CreateDeployment(ctx context.Context, userID string, req *dto.CreateDeploymentRequest) (*dto.DeploymentResponse, error) {
+	ctx, span := tracing.Start(ctx, "deployment.create")
+	defer span.End()
+
+	proj, dep, err := s.newDeployment(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	env := dep.Environment()
+
+	// Turn the deployment away rather than queueing builds without bound.
+	// Deployments waiting for approval queue no build until they are approved.
+	if s.admission != nil && !proj.IsProtected(env) {
+		if err := s.admission.Admit(ctx, dep.UserID()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Deployments to protected environments are only built once approved
+	if proj.IsProtected(env) {
+		if err := dep.AwaitApproval(); err != nil {
+			return nil, fmt.Errorf("failed to hold deployment for approval: %w", err)
+		}
+		if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+			return nil, fmt.Errorf("failed to save deployment: %w", err)
+		}
+		return s.toDTO(dep), nil
+	}
+
+	// Save the deployment and queue its build for a build worker together,
+	// so a deployment is never left pending without one.
+	// The job carries the request's correlation ID and trace so the build can be followed in logs and traces.
+	job := deployment.NewBuildJob(dep, logging.CorrelationID(ctx), tracing.TraceParent(ctx))
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+			return fmt.Errorf("failed to save deployment: %w", err)
+		}
+		if err := s.buildJobRepo.Enqueue(ctx, job); err != nil {
+			return fmt.Errorf("failed to queue build: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toDTO(dep), nil
+}
+
+// PlanDeployment works out what creating a deployment would do, like CreateDeployment but without saving or
+// executing anything: the Dockerfile and image tag it would be built with, the names of its environment
+// variables and the changes it would make to the project's cloud resources. Reasons the deployment would fail
+// once queued are returned as errors in the plan.
+func (s *DeploymentService) PlanDeployment(ctx context.Context, userID string, req *dto.CreateDeploymentRequest) (*dto.DeploymentPlanResponse, error) {
+	ctx, span := tracing.Start(ctx, "deployment.plan")
+	defer span.End()
+
+	if s.buildPlanner == nil {
+		return nil, errors.New("deployment planning is not configured")
+	}
+
+	proj, dep, err := s.newDeployment(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.DeploymentPlanResponse{
+		ProjectID:        proj.ID().String(),
+		CommitHash:       dep.CommitHash().String(),
+		Branch:           dep.Branch().String(),
+		Environment:      dep.Environment().String(),
+		RequiresApproval: proj.IsProtected(dep.Environment()),
+		BuildArgs:        []string{},
+		EnvVars:          []string{},
+		Changes:          []*dto.PlannedChangeResponse{},
+	}
+
+	build, err := s.buildPlanner.PlanBuild(ctx, proj, dep)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan build: %w", err)
+	}
+	response.RepoConfig = build.RepoConfig
+	response.ImageTag = build.ImageTag
+	response.Dockerfile = build.Dockerfile
+	response.BuildArgs = append(response.BuildArgs, build.BuildArgs...)
+	response.EnvVars = append(response.EnvVars, build.EnvVars...)
+	response.Errors = append(response.Errors, build.Problems...)
+	response.Warnings = append(response.Warnings, build.Warnings...)
+
+	if s.infraPlanner == nil {
+		response.Warnings = append(response.Warnings, "Deployments only build images on this platform, no cloud resources would change")
+	} else {
+		changes, err := s.infraPlanner.PlanDeployment(ctx, proj, dep.Environment(), build.ImageTag)
+		switch {
+		case errors.Is(err, deployment.ErrTargetUnavailable):
+			response.Errors = append(response.Errors, err.Error())
+		case err != nil:
+			slog.WarnContext(ctx, "Failed to plan cloud changes", "project_id", proj.ID().String(), "error", err)
+			response.Warnings = append(response.Warnings, "Could not read the current state of the project's cloud resources, their changes are unknown")
+		}
+		for _, change := range changes {
+			response.Changes = append(response.Changes, &dto.PlannedChangeResponse{
+				Action:   change.Action.String(),
+				Resource: change.Resource,
+				Name:     change.Name,
+				Detail:   change.Detail,
+			})
+		}
+	}
+
+	response.Valid = len(response.Errors) == 0
+	return response, nil
+}
+
+// newDeployment checks a user may deploy the project of a request and creates the deployment it asks for,
+// without saving it
+func (s *DeploymentService) newDeployment(ctx context.Context, userID string, req *dto.CreateDeploymentRequest) (*project.Project, *deployment.Deployment, error) {
 	// Parse user ID
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	// Parse project ID
+	pid, err := project.ParseProjectID(req.ProjectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	// Verify project exists and belongs to user
+	proj, err := s.projectRepo.FindByID(ctx, pid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("project not found: %w", err)
+	}
+
+	if !proj.BelongsToUser(uid) {
+		return nil, nil, deployment.ErrUnauthorized
+	}
+
+	if proj.IsDeleting() {
+		return nil, nil, project.ErrProjectDeleting
+	}
+
+	env, err := findEnvironment(proj, req.Environment)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create deployment entity
+	dep, err := deployment.NewDeployment(
+		pid,
+		uid,
+		req.CommitHash,
+		req.Branch,
+		env,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create deployment entity: %w", err)
+	}
+
+	if req.Title != "" || req.Description != "" || len(req.Labels) > 0 {
+		annotations, err := deployment.NewAnnotations(req.Title, req.Description, req.Labels)
+		if err != nil {
+			return nil, nil, err
+		}
+		dep.Annotate(annotations)
+	}
+	return proj, dep, nil
+}
+
+// ApproveDeployment approves a deployment waiting for approval and queues its build, recording who approved it.
+// The approvers of its environment and platform operators may decide on it, except the user who started it.
+func (s *DeploymentService) ApproveDeployment(ctx context.Context, deploymentID, userID string, operator bool, req *dto.DeploymentDecisionRequest) (*dto.DeploymentResponse, error) {
+	dep, uid, err := s.findWaitingDeployment(ctx, deploymentID, userID, operator)
+	if err != nil {
+		return nil, err
+	}
+
+	// The build is queued now, so it is turned away like a new deployment when builds are saturated
+	if s.admission != nil {
+		if err := s.admission.Admit(ctx, dep.UserID()); err != nil {
+			return nil, err
+		}
+	}
+
+	approval, err := dep.Approve(uid, req.Comment)
+	if err != nil {
+		return nil, err
+	}
+
+	job := deployment.NewBuildJob(dep, logging.CorrelationID(ctx), tracing.TraceParent(ctx))
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+			return fmt.Errorf("failed to save deployment: %w", err)
+		}
+		if err := s.approvalRepo.Save(ctx, approval); err != nil {
+			return fmt.Errorf("failed to record approval: %w", err)
+		}
+		if err := s.buildJobRepo.Enqueue(ctx, job); err != nil {
+			return fmt.Errorf("failed to queue build: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Deployment approved", "deployment_id", dep.ID().String(), "user_id", uid.String())
+	return s.toDTO(dep), nil
+}
+
+// RejectDeployment rejects a deployment waiting for approval, recording who rejected it.
+// The approvers of its environment and platform operators may decide on it.
+func (s *DeploymentService) RejectDeployment(ctx context.Context, deploymentID, userID string, operator bool, req *dto.DeploymentDecisionRequest) (*dto.DeploymentResponse, error) {
+	dep, uid, err := s.findWaitingDeployment(ctx, deploymentID, userID, operator)
+	if err != nil {
+		return nil, err
+	}
+
+	approval, err := dep.Reject(uid, req.Comment)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+			return fmt.Errorf("failed to save deployment: %w", err)
+		}
+		if err := s.approvalRepo.Save(ctx, approval); err != nil {
+			return fmt.Errorf("failed to record rejection: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Deployment rejected", "deployment_id", dep.ID().String(), "user_id", uid.String())
+	return s.toDTO(dep), nil
+}
+
+// GetDeploymentApprovals returns the decisions recorded on a deployment, oldest first
+func (s *DeploymentService) GetDeploymentApprovals(ctx context.Context, deploymentID string) (*dto.DeploymentApprovalListResponse, error) {
+	did, err := deployment.ParseDeploymentID(deploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment ID: %w", err)
+	}
+
+	approvals, err := s.approvalRepo.FindByDeploymentID(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.DeploymentApprovalListResponse{
+		Approvals: make([]*dto.DeploymentApprovalResponse, len(approvals)),
+	}
+	for i, approval := range approvals {
+		response.Approvals[i] = &dto.DeploymentApprovalResponse{
+			DeploymentID: approval.DeploymentID.String(),
+			UserID:       approval.UserID.String(),
+			Decision:     approval.Decision.String(),
+			Comment:      approval.Comment,
+			DecidedAt:    approval.DecidedAt.Format(time.RFC3339),
+		}
+	}
+
+	return response, nil
+}
+
+// findWaitingDeployment loads a deployment to decide on, checking the user is an operator or an approver of its
+// environment and its project is not being deleted
+func (s *DeploymentService) findWaitingDeployment(ctx context.Context, deploymentID, userID string, operator bool) (*deployment.Deployment, user.UserID, error) {
+	did, err := deployment.ParseDeploymentID(deploymentID)
+	if err != nil {
+		return nil, user.UserID{}, fmt.Errorf("invalid deployment ID: %w", err)
+	}
+
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, user.UserID{}, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	dep, err := s.deploymentRepo.FindByID(ctx, did)
+	if err != nil {
+		return nil, user.UserID{}, err
+	}
+
+	if !operator {
+		approvers, err := s.approvalRepo.FindApprovers(ctx, dep.ProjectID(), dep.Environment())
+		if err != nil {
+			return nil, user.UserID{}, err
+		}
+		if !slices.ContainsFunc(approvers, uid.Equals) {
+			return nil, user.UserID{}, deployment.ErrNotApprover
+		}
+	}
+
+	proj, err := s.projectRepo.FindByID(ctx, dep.ProjectID())
+	if err != nil {
+		return nil, user.UserID{}, err
+	}
+	if proj.IsDeleting() {
+		return nil, user.UserID{}, project.ErrProjectDeleting
+	}
+
+	return dep, uid, nil
+}
+
+// GetEnvironmentApprovers returns the users designated to decide on the deployments to a project's environment
+func (s *DeploymentService) GetEnvironmentApprovers(ctx context.Context, projectID, environment string) (*dto.EnvironmentApproversResponse, error) {
+	proj, env, err := s.findEnvironment(ctx, projectID, environment)
+	if err != nil {
+		return nil, err
+	}
+
+	approvers, err := s.approvalRepo.FindApprovers(ctx, proj.ID(), env)
+	if err != nil {
+		return nil, err
+	}
+	return toApproversDTO(env, approvers), nil
+}
+
+// SetEnvironmentApprovers designates the users who decide on the deployments to a protected environment of a
+// project, replacing the previous approvers. Without approvers only platform operators decide.
+func (s *DeploymentService) SetEnvironmentApprovers(ctx context.Context, projectID, environment string, req *dto.EnvironmentApproversRequest) (*dto.EnvironmentApproversResponse, error) {
+	proj, env, err := s.findEnvironment(ctx, projectID, environment)
+	if err != nil {
+		return nil, err
+	}
+
+	approvers := make([]user.UserID, len(req.UserIDs))
+	for i, id := range req.UserIDs {
+		if approvers[i], err = user.ParseUserID(id); err != nil {
+			return nil, deployment.ErrInvalidApprovers
+		}
+	}
+	approvers, err = deployment.CheckApprovers(proj, env, approvers)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		return s.approvalRepo.SetApprovers(ctx, proj.ID(), env, approvers)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Environment approvers set", "project_id", proj.ID().String(), "environment", env.String(), "approvers", len(approvers))
+	return toApproversDTO(env, approvers), nil
+}
+
+// findEnvironment loads a project and parses the name of one of its environments
+func (s *DeploymentService) findEnvironment(ctx context.Context, projectID, environment string) (*project.Project, project.Environment, error) {
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	proj, err := s.projectRepo.FindByID(ctx, pid)
+	if err != nil {
+		return nil, "", err
+	}
+
+	env, err := project.NewEnvironment(environment)
+	if err != nil {
+		return nil, "", err
+	}
+	if !proj.HasEnvironment(env) {
+		return nil, "", project.ErrEnvironmentNotFound
+	}
+	return proj, env, nil
+}
+
+// toApproversDTO converts the approvers of an environment to DTO
+func toApproversDTO(env project.Environment, approvers []user.UserID) *dto.EnvironmentApproversResponse {
+	response := &dto.EnvironmentApproversResponse{
+		Environment: env.String(),
+		UserIDs:     make([]string, len(approvers)),
+	}
+	for i, approver := range approvers {
+		response.UserIDs[i] = approver.String()
+	}
+	return response
+}
+
+// RestartProject forces a new deployment of the running image of a project's environment without a rebuild.
+// An empty environment restarts production.
+func (s *DeploymentService) RestartProject(ctx context.Context, projectID, userID, environment string) (*dto.DeploymentResponse, error) {
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	proj, err := s.projectRepo.FindByID(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	if !proj.BelongsToUser(uid) {
+		return nil, deployment.ErrUnauthorized
+	}
+
+	if proj.IsDeleting() {
+		return nil, project.ErrProjectDeleting
+	}
+
+	env, err := findEnvironment(proj, environment)
+	if err != nil {
+		return nil, err
+	}
+
+	// Restarting while a build or deploy is running would race with it
+	latest, err := s.deploymentRepo.FindLatestInEnvironment(ctx, pid, env)
+	if err != nil {
+		if errors.Is(err, deployment.ErrDeploymentNotFound) {
+			return nil, deployment.ErrNothingToRestart
+		}
+		return nil, err
+	}
+	if !latest.Status().IsTerminal() {
+		return nil, deployment.ErrDeploymentInProgress
+	}
+
+	deployed, err := s.deploymentRepo.FindLatestDeployedInEnvironment(ctx, pid, env)
+	if err != nil {
+		if errors.Is(err, deployment.ErrDeploymentNotFound) {
+			return nil, deployment.ErrNothingToRestart
+		}
+		return nil, err
+	}
+
+	dep := deployment.NewRestartDeployment(deployed, uid)
+	dep.AppendLog(fmt.Sprintf("🔁 Restarting commit %s without rebuilding...", deployed.CommitHash().String()))
+
+	if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+		return nil, fmt.Errorf("failed to save deployment: %w", err)
+	}
+
+	response := s.toDTO(dep)
+
+	// Restart in background - the request context ends with the response, its log attributes carry on
+	go s.restartService(logging.With(logging.Detach(ctx), "deployment_id", dep.ID().String(), "project_id", proj.ID().String()), proj, dep)
+
+	return response, nil
+}
+
+// restartService restarts the running service and records the outcome on the deployment
+func (s *DeploymentService) restartService(ctx context.Context, proj *project.Project, dep *deployment.Deployment) {
+	if s.restarter == nil {
+		slog.ErrorContext(ctx, "No service restarter configured, cannot restart project")
+		dep.AppendLog("❌ Restarts are not available: no deployment target is configured")
+		dep.UpdateStatus(deployment.StatusFailed)
+		if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+			slog.ErrorContext(ctx, "Failed to save deployment", "error", err)
+		}
+		return
+	}
+
+	// The restarter records progress and the final status on the deployment
+	if err := s.restarter.RestartService(ctx, proj, dep); err != nil {
+		slog.ErrorContext(ctx, "Restart failed", "error", err)
+	}
+}
+
+// RedeployEnvironment deploys the image an environment last deployed successfully again, without a rebuild,
+// so its tasks start with the project's current environment variables. Environments with a deployment in
+// progress aren't redeployed.
+func (s *DeploymentService) RedeployEnvironment(ctx context.Context, proj *project.Project, env project.Environment, userID user.UserID) (*deployment.Deployment, error) {
+	if s.resumer == nil {
+		return nil, fmt.Errorf("%w: images are not deployed on this platform", deployment.ErrTargetUnavailable)
+	}
+
+	if proj.IsDeleting() {
+		return nil, project.ErrProjectDeleting
+	}
+
+	latest, err := s.deploymentRepo.FindLatestInEnvironment(ctx, proj.ID(), env)
+	if err != nil {
+		if errors.Is(err, deployment.ErrDeploymentNotFound) {
+			return nil, deployment.ErrNothingToRestart
+		}
+		return nil, err
+	}
+	if !latest.Status().IsTerminal() {
+		return nil, deployment.ErrDeploymentInProgress
+	}
+
+	deployed, err := s.deploymentRepo.FindLatestDeployedInEnvironment(ctx, proj.ID(), env)
+	if err != nil {
+		if errors.Is(err, deployment.ErrDeploymentNotFound) {
+			return nil, deployment.ErrNothingToRestart
+		}
+		return nil, err
+	}
+	if deployed.ImageURI() == "" {
+		// Deployed before images were recorded
+		return nil, deployment.ErrNothingToRestart
+	}
+
+	dep := deployment.NewRedeployDeployment(deployed, userID)
+	dep.AppendLog(fmt.Sprintf("🔁 Environment variables changed, redeploying commit %s without rebuilding...", deployed.CommitHash().String()))
+
+	if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+		return nil, fmt.Errorf("failed to save deployment: %w", err)
+	}
+
+	// Deploy in background - the request context ends with the response, its log attributes carry on.
+	// The deployment goes through the steps after the push, as a retry from them would.
+	go s.resumeDeployment(logging.With(logging.Detach(ctx), "deployment_id", dep.ID().String(), "project_id", proj.ID().String()), proj, dep, deployment.StepDB)
+
+	return dep, nil
+}
+
+// DeployDuplicate deploys a project duplicated from another one the commit and branch its source last deployed
+// successfully to production
+func (s *DeploymentService) DeployDuplicate(ctx context.Context, source, duplicate *project.Project, userID user.UserID) (*dto.DeploymentResponse, error) {
+	deployed, err := s.deploymentRepo.FindLatestDeployedInEnvironment(ctx, source.ID(), project.EnvironmentProduction)
+	if err != nil {
+		if errors.Is(err, deployment.ErrDeploymentNotFound) {
+			return nil, deployment.ErrNothingToDeploy
+		}
+		return nil, err
+	}
+
+	return s.CreateDeployment(ctx, userID.String(), &dto.CreateDeploymentRequest{
+		ProjectID:  duplicate.ID().String(),
+		CommitHash: deployed.CommitHash().String(),
+		Branch:     deployed.Branch().String(),
+	})
+}
+
+// RetryDeployment picks a failed deployment up again from a pipeline step, by default the first one that didn't
+// complete. Retrying from a step after the push deploys the image the deployment already pushed instead of
+// rebuilding it; earlier steps queue the build again. Only the latest deployment of an environment is retried.
+func (s *DeploymentService) RetryDeployment(ctx context.Context, deploymentID, userID, from string) (*dto.DeploymentResponse, error) {
+	did, err := deployment.ParseDeploymentID(deploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment ID: %w", err)
+	}
+
 	uid, err := user.ParseUserID(userID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid user ID: %w", err)
 	}
 
-	// Parse project ID
-	pid, err := project.ParseProjectID(req.ProjectID)
+	dep, err := s.deploymentRepo.FindByID(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+
+	if !dep.BelongsToUser(uid) {
+		return nil, deployment.ErrUnauthorized
+	}
+
+	proj, err := s.projectRepo.FindByID(ctx, dep.ProjectID())
+	if err != nil {
+		return nil, err
+	}
+
+	if proj.IsDeleting() {
+		return nil, project.ErrProjectDeleting
+	}
+
+	// Retrying an older deployment would replace what a newer one deployed, or race with it
+	latest, err := s.deploymentRepo.FindLatestInEnvironment(ctx, dep.ProjectID(), dep.Environment())
+	if err != nil {
+		return nil, err
+	}
+	if !latest.ID().Equals(dep.ID()) {
+		return nil, fmt.Errorf("%w: a newer deployment of the %s environment exists", deployment.ErrNotRetryable, dep.Environment())
+	}
+
+	step, err := s.resumePoint(ctx, dep, from)
+	if err != nil {
+		return nil, err
+	}
+	if step.ReusesImage() && s.resumer == nil {
+		return nil, fmt.Errorf("%w: images are not deployed on this platform, retry from the %s step", deployment.ErrNotRetryable, deployment.StepClone)
+	}
+
+	if err := dep.Retry(step); err != nil {
+		return nil, err
+	}
+
+	if step.ReusesImage() {
+		if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+			return nil, fmt.Errorf("failed to save deployment: %w", err)
+		}
+
+		response := s.toDTO(dep)
+
+		// Deploy in background - the request context ends with the response, its log attributes carry on
+		go s.resumeDeployment(logging.With(logging.Detach(ctx), "deployment_id", dep.ID().String(), "project_id", proj.ID().String()), proj, dep, step)
+
+		return response, nil
+	}
+
+	if s.admission != nil {
+		if err := s.admission.Admit(ctx, dep.UserID()); err != nil {
+			return nil, err
+		}
+	}
+
+	// The build starts over from the step, which stays running until the image is handed over
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+			return fmt.Errorf("failed to save deployment: %w", err)
+		}
+		if s.stepRepo != nil {
+			if err := s.stepRepo.Save(ctx, deployment.StartStep(dep, step)); err != nil {
+				return fmt.Errorf("failed to record deployment step: %w", err)
+			}
+		}
+		if err := s.buildJobRepo.Requeue(ctx, dep.ID()); err != nil {
+			return fmt.Errorf("failed to queue build: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toDTO(dep), nil
+}
+
+// resumePoint works out the step a failed deployment is retried from. A requested step can't come after
+// the first step that didn't complete.
+func (s *DeploymentService) resumePoint(ctx context.Context, dep *deployment.Deployment, from string) (deployment.Step, error) {
+	var records []deployment.StepRecord
+	if s.stepRepo != nil {
+		var err error
+		if records, err = s.stepRepo.FindByDeploymentID(ctx, dep.ID()); err != nil {
+			return "", err
+		}
+	}
+	resumePoint := deployment.ResumePoint(records)
+	if from == "" {
+		return resumePoint, nil
+	}
+
+	step, err := deployment.ParseStep(from)
+	if err != nil {
+		return "", err
+	}
+	if resumePoint.Before(step) {
+		return "", fmt.Errorf("%w: the %s step didn't complete, retry from it or an earlier step", deployment.ErrNotRetryable, resumePoint)
+	}
+	return step, nil
+}
+
+// resumeDeployment deploys the image of a retried deployment and records the outcome on the deployment
+func (s *DeploymentService) resumeDeployment(ctx context.Context, proj *project.Project, dep *deployment.Deployment, from deployment.Step) {
+	// The resumer records progress and the final status on the deployment
+	if err := s.resumer.ResumeDeployment(ctx, proj, dep, from); err != nil {
+		slog.ErrorContext(ctx, "Retried deployment failed", "step", from.String(), "error", err)
+		dep.UpdateStatus(deployment.StatusFailed)
+		if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+			slog.ErrorContext(ctx, "Failed to save deployment", "error", err)
+		}
+	}
+}
+
+// GetDeploymentSteps returns the pipeline steps a deployment went through in the order they run, with the
+// step a retry would pick up from if the deployment failed
+func (s *DeploymentService) GetDeploymentSteps(ctx context.Context, deploymentID string) (*dto.DeploymentStepListResponse, error) {
+	did, err := deployment.ParseDeploymentID(deploymentID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid project ID: %w", err)
+		return nil, fmt.Errorf("invalid deployment ID: %w", err)
 	}
 
-	// Verify project exists and belongs to user
-	proj, err := s.projectRepo.FindByID(ctx, pid)
+	dep, err := s.deploymentRepo.FindByID(ctx, did)
 	if err != nil {
-		return nil, fmt.Errorf("project not found: %w", err)
+		return nil, err
 	}
 
-	if !proj.BelongsToUser(uid) {
-		return nil, deployment.ErrUnauthorized
+	var records []deployment.StepRecord
+	if s.stepRepo != nil {
+		if records, err = s.stepRepo.FindByDeploymentID(ctx, did); err != nil {
+			return nil, err
+		}
 	}
 
-	// Create deployment entity
-	dep, err := deployment.NewDeployment(
-		pid,
-		uid,
-		req.CommitHash,
-		req.Branch,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create deployment entity: %w", err)
+	response := &dto.DeploymentStepListResponse{
+		Steps: make([]*dto.DeploymentStepResponse, len(records)),
 	}
-
-	// Save deployment
-	if err := s.deploymentRepo.Save(ctx, dep); err != nil {
-		return nil, fmt.Errorf("failed to save deployment: %w", err)
+	for i, record := range records {
+		response.Steps[i] = &dto.DeploymentStepResponse{
+			Step:      record.Step.String(),
+			Status:    record.Status.String(),
+			Error:     record.Error,
+			StartedAt: record.StartedAt.Format(time.RFC3339),
+		}
+		if record.FinishedAt != nil {
+			response.Steps[i].FinishedAt = record.FinishedAt.Format(time.RFC3339)
+		}
+	}
+	if dep.Status() == deployment.StatusFailed {
+		response.ResumeFrom = deployment.ResumePoint(records).String()
 	}
 
-	return s.toDTO(dep), nil
+	return response, nil
+}
+
+// RecordBuildSteps returns a deployment callback recording the clone, build and push steps of a deployment as
+// completed once its image is built, before handing the image to next
+func (s *DeploymentService) RecordBuildSteps(next builder.DeploymentCallback) builder.DeploymentCallback {
+	return &buildStepsCallback{steps: s.stepRepo, next: next}
+}
+
+// buildStepsCallback records the steps of builds, which backends report as a whole
+type buildStepsCallback struct {
+	steps deployment.StepRepository
+	next  builder.DeploymentCallback
+}
+
+// OnBuildSuccess records the build steps, then deploys the image
+func (c *buildStepsCallback) OnBuildSuccess(ctx context.Context, dep *deployment.Deployment, proj *project.Project, imageURI string) error {
+	if c.steps != nil {
+		for _, step := range []deployment.Step{deployment.StepClone, deployment.StepBuild, deployment.StepPush} {
+			record := deployment.StartStep(dep, step)
+			record.Finish(nil)
+			if err := c.steps.Save(ctx, record); err != nil {
+				slog.WarnContext(ctx, "Failed to record deployment step", "step", step.String(), "error", err)
+			}
+		}
+	}
+	return c.next.OnBuildSuccess(ctx, dep, proj, imageURI)
 }
 
-// GetDeploymentByID retrieves a deployment by its ID
-func (s *DeploymentService) GetDeploymentByID(ctx context.Context, deploymentID string) (*dto.DeploymentResponse, error) {
+// GetDeploymentByID retrieves a deployment by its ID. Deleted deployments are only found with includeDeleted.
+func (s *DeploymentService) GetDeploymentByID(ctx context.Context, deploymentID string, includeDeleted bool) (*dto.DeploymentResponse, error) {
 	// Parse deployment ID
 	did, err := deployment.ParseDeploymentID(deploymentID)
 	if err != nil {
@@ -80,7 +960,11 @@ func (s *DeploymentService) GetDeploymentByID(ctx context.Context, deploymentID
 	}
 
 	// Get deployment
-	dep, err := s.deploymentRepo.FindByID(ctx, did)
+	find := s.deploymentRepo.FindByID
+	if includeDeleted {
+		find = s.deploymentRepo.FindByIDIncludingDeleted
+	}
+	dep, err := find(ctx, did)
 	if err != nil {
 		return nil, err
 	}
@@ -88,8 +972,9 @@ func (s *DeploymentService) GetDeploymentByID(ctx context.Context, deploymentID
 	return s.toDTO(dep), nil
 }
 
-// GetDeploymentsByProjectID retrieves all deployments for a project with pagination
-func (s *DeploymentService) GetDeploymentsByProjectID(ctx context.Context, projectID string, page, limit int32) (*dto.DeploymentListResponse, error) {
+// GetDeploymentsByProjectID retrieves the deployments for a project matching the filter with pagination, deleted ones
+// only with includeDeleted
+func (s *DeploymentService) GetDeploymentsByProjectID(ctx context.Context, projectID string, filter dto.DeploymentListFilter, page, limit int32, includeDeleted bool) (*dto.DeploymentListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -102,38 +987,33 @@ func (s *DeploymentService) GetDeploymentsByProjectID(ctx context.Context, proje
 		return nil, fmt.Errorf("invalid project ID: %w", err)
 	}
 
+	listFilter, err := parseListFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
 	offset := (page - 1) * limit
 
-	deployments, err := s.deploymentRepo.FindByProjectID(ctx, pid, limit, offset)
+	findByProjectID, countByProjectID := s.deploymentRepo.FindByProjectID, s.deploymentRepo.CountByProjectID
+	if includeDeleted {
+		findByProjectID, countByProjectID = s.deploymentRepo.FindByProjectIDIncludingDeleted, s.deploymentRepo.CountByProjectIDIncludingDeleted
+	}
+
+	deployments, err := findByProjectID(ctx, pid, listFilter, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch deployments: %w", err)
 	}
 
-	total, err := s.deploymentRepo.CountByProjectID(ctx, pid)
+	total, err := countByProjectID(ctx, pid, listFilter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count deployments: %w", err)
 	}
 
-	deploymentResponses := make([]*dto.DeploymentResponse, len(deployments))
-	for i, dep := range deployments {
-		deploymentResponses[i] = s.toDTO(dep)
-	}
-
-	totalPages := (total + int64(limit) - 1) / int64(limit)
-
-	return &dto.DeploymentListResponse{
-		Deployments: deploymentResponses,
-		Pagination: dto.PaginationResponse{
-			Page:       page,
-			Limit:      limit,
-			Total:      total,
-			TotalPages: totalPages,
-		},
-	}, nil
+	return s.toListDTO(deployments, total, page, limit, int64(page)*int64(limit) < total), nil
 }
 
-// GetDeploymentsByUserID retrieves all deployments for a user with pagination
-func (s *DeploymentService) GetDeploymentsByUserID(ctx context.Context, userID string, page, limit int32) (*dto.DeploymentListResponse, error) {
+// GetDeploymentsByUserID retrieves the deployments for a user matching the filter with pagination
+func (s *DeploymentService) GetDeploymentsByUserID(ctx context.Context, userID string, filter dto.DeploymentListFilter, page, limit int32) (*dto.DeploymentListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -146,34 +1026,198 @@ func (s *DeploymentService) GetDeploymentsByUserID(ctx context.Context, userID s
 		return nil, fmt.Errorf("invalid user ID: %w", err)
 	}
 
+	listFilter, err := parseListFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
 	offset := (page - 1) * limit
 
-	deployments, err := s.deploymentRepo.FindByUserID(ctx, uid, limit, offset)
+	deployments, err := s.deploymentRepo.FindByUserID(ctx, uid, listFilter, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch deployments: %w", err)
+	}
+
+	total, err := s.deploymentRepo.CountByUserID(ctx, uid, listFilter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count deployments: %w", err)
+	}
+
+	return s.toListDTO(deployments, total, page, limit, int64(page)*int64(limit) < total), nil
+}
+
+// GetDeploymentsByProjectIDAfter retrieves the deployments for a project matching the filter that come after the cursor
+// of a previous page, deleted ones only with includeDeleted
+func (s *DeploymentService) GetDeploymentsByProjectIDAfter(ctx context.Context, projectID string, filter dto.DeploymentListFilter, cursor string, limit int32, includeDeleted bool) (*dto.DeploymentListResponse, error) {
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	listFilter, err := parseListFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := decodeDeploymentCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	findByProjectID, countByProjectID := s.deploymentRepo.FindByProjectIDAfter, s.deploymentRepo.CountByProjectID
+	if includeDeleted {
+		findByProjectID, countByProjectID = s.deploymentRepo.FindByProjectIDIncludingDeletedAfter, s.deploymentRepo.CountByProjectIDIncludingDeleted
+	}
+
+	// One more than the page holds tells whether there is a next one
+	deployments, err := findByProjectID(ctx, pid, listFilter, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch deployments: %w", err)
+	}
+
+	total, err := countByProjectID(ctx, pid, listFilter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count deployments: %w", err)
+	}
+
+	more := len(deployments) > int(limit)
+	if more {
+		deployments = deployments[:limit]
+	}
+	return s.toListDTO(deployments, total, 0, limit, more), nil
+}
+
+// GetDeploymentsByUserIDAfter retrieves the deployments for a user matching the filter that come after the cursor of a
+// previous page
+func (s *DeploymentService) GetDeploymentsByUserIDAfter(ctx context.Context, userID string, filter dto.DeploymentListFilter, cursor string, limit int32) (*dto.DeploymentListResponse, error) {
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	listFilter, err := parseListFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := decodeDeploymentCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// One more than the page holds tells whether there is a next one
+	deployments, err := s.deploymentRepo.FindByUserIDAfter(ctx, uid, listFilter, after, limit+1)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch deployments: %w", err)
 	}
 
-	total, err := s.deploymentRepo.CountByUserID(ctx, uid)
+	total, err := s.deploymentRepo.CountByUserID(ctx, uid, listFilter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count deployments: %w", err)
 	}
 
+	more := len(deployments) > int(limit)
+	if more {
+		deployments = deployments[:limit]
+	}
+	return s.toListDTO(deployments, total, 0, limit, more), nil
+}
+
+// parseListFilter validates the filter of a list of deployments
+func parseListFilter(filter dto.DeploymentListFilter) (deployment.ListFilter, error) {
+	var listFilter deployment.ListFilter
+
+	if filter.Status != "" {
+		status, err := deployment.NewDeploymentStatus(filter.Status)
+		if err != nil {
+			return deployment.ListFilter{}, fmt.Errorf("%w: %v", ErrInvalidListFilter, err)
+		}
+		listFilter.Status = status
+	}
+
+	listFilter.Branch = strings.TrimSpace(filter.Branch)
+
+	if filter.Since != "" {
+		since, err := time.Parse(time.RFC3339, filter.Since)
+		if err != nil {
+			since, err = time.Parse(time.DateOnly, filter.Since)
+		}
+		if err != nil {
+			return deployment.ListFilter{}, fmt.Errorf("%w: since must be a date such as 2024-01-01 or an RFC 3339 time", ErrInvalidListFilter)
+		}
+		listFilter.Since = since
+	}
+
+	if len(filter.Labels) > 0 {
+		listFilter.Labels = make(map[string]string, len(filter.Labels))
+		for _, label := range filter.Labels {
+			key, value, ok := strings.Cut(label, ":")
+			key = strings.TrimSpace(key)
+			if !ok || key == "" {
+				return deployment.ListFilter{}, fmt.Errorf("%w: label must be key:value, got %q", ErrInvalidListFilter, label)
+			}
+			listFilter.Labels[key] = strings.TrimSpace(value)
+		}
+	}
+
+	switch filter.Order {
+	case "", "created_at.desc":
+	case "created_at.asc":
+		listFilter.Ascending = true
+	default:
+		return deployment.ListFilter{}, fmt.Errorf("%w: order must be created_at.desc or created_at.asc", ErrInvalidListFilter)
+	}
+
+	return listFilter, nil
+}
+
+// decodeDeploymentCursor returns the deployment a cursor continues a list after
+func decodeDeploymentCursor(cursor string) (deployment.Cursor, error) {
+	createdAt, id, err := decodeCursor(cursor)
+	if err != nil {
+		return deployment.Cursor{}, err
+	}
+	did, err := deployment.ParseDeploymentID(id)
+	if err != nil {
+		return deployment.Cursor{}, ErrInvalidCursor
+	}
+	return deployment.Cursor{CreatedAt: createdAt, ID: did}, nil
+}
+
+// toListDTO converts a page of deployments to a list response, page is 0 for pages continued from a cursor.
+// more tells whether deployments follow the page, its last deployment is the cursor of the next page then.
+func (s *DeploymentService) toListDTO(deployments []*deployment.Deployment, total int64, page, limit int32, more bool) *dto.DeploymentListResponse {
 	deploymentResponses := make([]*dto.DeploymentResponse, len(deployments))
+	version := newListVersion(total)
 	for i, dep := range deployments {
 		deploymentResponses[i] = s.toDTO(dep)
+		version.add(dep.ID().String(), dep.UpdatedAt())
 	}
 
-	totalPages := (total + int64(limit) - 1) / int64(limit)
+	pagination := dto.PaginationResponse{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: (total + int64(limit) - 1) / int64(limit),
+	}
+	if more && len(deployments) > 0 {
+		last := deployments[len(deployments)-1]
+		pagination.NextCursor = encodeCursor(last.CreatedAt(), last.ID().String())
+	}
 
 	return &dto.DeploymentListResponse{
 		Deployments: deploymentResponses,
-		Pagination: dto.PaginationResponse{
-			Page:       page,
-			Limit:      limit,
-			Total:      total,
-			TotalPages: totalPages,
-		},
-	}, nil
+		Pagination:  pagination,
+		ETag:        version.etag(),
+	}
 }
 
 // UpdateDeploymentStatus updates the status of a deployment
@@ -218,6 +1262,108 @@ func (s *DeploymentService) UpdateDeploymentStatus(ctx context.Context, deployme
 	return s.toDTO(dep), nil
 }
 
+// AnnotateDeployment changes the title, description or labels of a deployment, keeping those the request omits
+func (s *DeploymentService) AnnotateDeployment(ctx context.Context, deploymentID, userID string, req *dto.AnnotateDeploymentRequest) (*dto.DeploymentResponse, error) {
+	// Parse IDs
+	did, err := deployment.ParseDeploymentID(deploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment ID: %w", err)
+	}
+
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	// Get deployment
+	dep, err := s.deploymentRepo.FindByID(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check ownership
+	if !dep.BelongsToUser(uid) {
+		return nil, deployment.ErrUnauthorized
+	}
+
+	current := dep.Annotations()
+	title, description, labels := current.Title, current.Description, current.Labels
+	if req.Title != nil {
+		title = *req.Title
+	}
+	if req.Description != nil {
+		description = *req.Description
+	}
+	if req.Labels != nil {
+		labels = req.Labels
+	}
+
+	annotations, err := deployment.NewAnnotations(title, description, labels)
+	if err != nil {
+		return nil, err
+	}
+	dep.Annotate(annotations)
+
+	// Save updated deployment
+	if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+		return nil, fmt.Errorf("failed to save deployment: %w", err)
+	}
+
+	return s.toDTO(dep), nil
+}
+
+// PinDeployment pins a deployment so it can't be deleted and is kept from archiving and image cleanup
+func (s *DeploymentService) PinDeployment(ctx context.Context, deploymentID, userID string) (*dto.DeploymentResponse, error) {
+	return s.setPinned(ctx, deploymentID, userID, true)
+}
+
+// UnpinDeployment unpins a deployment so it can be deleted and cleaned up again
+func (s *DeploymentService) UnpinDeployment(ctx context.Context, deploymentID, userID string) (*dto.DeploymentResponse, error) {
+	return s.setPinned(ctx, deploymentID, userID, false)
+}
+
+// setPinned pins or unpins a deployment of the user
+func (s *DeploymentService) setPinned(ctx context.Context, deploymentID, userID string, pinned bool) (*dto.DeploymentResponse, error) {
+	// Parse IDs
+	did, err := deployment.ParseDeploymentID(deploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment ID: %w", err)
+	}
+
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	// Get deployment
+	dep, err := s.deploymentRepo.FindByID(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check ownership
+	if !dep.BelongsToUser(uid) {
+		return nil, deployment.ErrUnauthorized
+	}
+
+	if dep.IsPinned() == pinned {
+		return s.toDTO(dep), nil
+	}
+	if pinned {
+		dep.Pin()
+	} else {
+		dep.Unpin()
+	}
+
+	// Save updated deployment
+	if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+		return nil, fmt.Errorf("failed to save deployment: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Deployment pin changed", "deployment_id", dep.ID().String(), "pinned", pinned)
+	return s.toDTO(dep), nil
+}
+
 // AppendDeploymentLog appends a log line to a deployment
 func (s *DeploymentService) AppendDeploymentLog(ctx context.Context, deploymentID, userID string, req *dto.AppendDeploymentLogRequest) (*dto.DeploymentResponse, error) {
 	// Parse IDs
@@ -226,6 +1372,11 @@ func (s *DeploymentService) AppendDeploymentLog(ctx context.Context, deploymentI
 		return nil, fmt.Errorf("invalid deployment ID: %w", err)
 	}
 
+	// Lines of clients are rejected rather than cut off like the build's, so they know theirs wasn't kept whole
+	if len(req.LogLine) > deployment.MaxLogLineBytes {
+		return nil, deployment.ErrLogLineTooLong
+	}
+
 	uid, err := user.ParseUserID(userID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid user ID: %w", err)
@@ -253,6 +1404,66 @@ func (s *DeploymentService) AppendDeploymentLog(ctx context.Context, deploymentI
 	return s.toDTO(dep), nil
 }
 
+// ReportAgentStatus moves a deployment to a status reported by a build agent, logging the agent's message
+// with it. Agents authenticate as the platform, so ownership is not checked.
+func (s *DeploymentService) ReportAgentStatus(ctx context.Context, deploymentID, status, message string) (*dto.DeploymentResponse, error) {
+	did, err := deployment.ParseDeploymentID(deploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment ID: %w", err)
+	}
+
+	newStatus, err := deployment.NewDeploymentStatus(status)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status: %w", err)
+	}
+
+	dep, err := s.deploymentRepo.FindByID(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dep.UpdateStatus(newStatus); err != nil {
+		return nil, fmt.Errorf("failed to update status: %w", err)
+	}
+	if message != "" {
+		maskLogSecrets(ctx, s.logSecrets, dep)
+		dep.AppendLog(message)
+	}
+
+	if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+		return nil, fmt.Errorf("failed to save deployment: %w", err)
+	}
+
+	return s.toDTO(dep), nil
+}
+
+// AppendAgentLogs appends a batch of log lines pushed by a build agent to a deployment's logs,
+// saving them once, and returns the lines as recorded, with their secrets masked.
+// Agents authenticate as the platform, so ownership is not checked.
+func (s *DeploymentService) AppendAgentLogs(ctx context.Context, deploymentID string, lines []string) ([]string, error) {
+	did, err := deployment.ParseDeploymentID(deploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment ID: %w", err)
+	}
+
+	dep, err := s.deploymentRepo.FindByID(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+
+	maskLogSecrets(ctx, s.logSecrets, dep)
+	recorded := make([]string, len(lines))
+	for i, line := range lines {
+		recorded[i] = dep.RedactLog(line)
+		dep.AppendLog(line)
+	}
+
+	if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+		return nil, fmt.Errorf("failed to save deployment: %w", err)
+	}
+	return recorded, nil
+}
+
 // DeleteDeployment deletes a deployment
 func (s *DeploymentService) DeleteDeployment(ctx context.Context, deploymentID, userID string) error {
 	// Parse IDs
@@ -277,6 +1488,11 @@ func (s *DeploymentService) DeleteDeployment(ctx context.Context, deploymentID,
 		return deployment.ErrUnauthorized
 	}
 
+	// Pinned deployments are kept until they are unpinned
+	if dep.IsPinned() {
+		return deployment.ErrDeploymentPinned
+	}
+
 	// Delete deployment
 	if err := s.deploymentRepo.Delete(ctx, did); err != nil {
 		return fmt.Errorf("failed to delete deployment: %w", err)
@@ -302,16 +1518,29 @@ func (s *DeploymentService) GetLatestDeploymentByProjectID(ctx context.Context,
 
 // toDTO converts a domain deployment to DTO
 func (s *DeploymentService) toDTO(dep *deployment.Deployment) *dto.DeploymentResponse {
-	return &dto.DeploymentResponse{
-		ID:         dep.ID().String(),
-		ProjectID:  dep.ProjectID().String(),
-		UserID:     dep.UserID().String(),
-		CommitHash: dep.CommitHash().String(),
-		Branch:     dep.Branch().String(),
-		Status:     dep.Status().String(),
-		Logs:       dep.Logs().String(),
-		CreatedAt:  dep.CreatedAt().Format(time.RFC3339),
-		UpdatedAt:  dep.UpdatedAt().Format(time.RFC3339),
+	response := &dto.DeploymentResponse{
+		ID:          dep.ID().String(),
+		ProjectID:   dep.ProjectID().String(),
+		UserID:      dep.UserID().String(),
+		CommitHash:  dep.CommitHash().String(),
+		Branch:      dep.Branch().String(),
+		Environment: dep.Environment().String(),
+		Type:        dep.Type().String(),
+		Status:      dep.Status().String(),
+		Logs:        dep.Logs().String(),
+		ImageURI:    dep.ImageURI(),
+		ImageDigest: dep.ImageDigest(),
+		Title:       dep.Annotations().Title,
+		Description: dep.Annotations().Description,
+		Labels:      dep.Annotations().Labels,
+		Pinned:      dep.IsPinned(),
+		CreatedAt:   dep.CreatedAt().Format(time.RFC3339),
+		UpdatedAt:   dep.UpdatedAt().Format(time.RFC3339),
 	}
+	if dep.DeletedAt() != nil {
+		response.DeletedAt = dep.DeletedAt().Format(time.RFC3339)
+	}
+
+	return response
 }
 
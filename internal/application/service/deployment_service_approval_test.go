@@ -0,0 +1,91 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestDeploymentService_ApproveDeployment(t *testing.T) {
+	ctx := context.Background()
+	owner, approver, stranger := user.NewUserID(), user.NewUserID(), user.NewUserID()
+	proj := newTestProject(t, owner, "my-app", func(proj *project.Project) error {
+		return proj.SetProtectedEnvironments([]string{"production"})
+	})
+
+	// newService creates a service with a production deployment of the owner waiting for approval
+	newService := func(t *testing.T) (*service.DeploymentService, *deployment.Deployment, *mockApprovalRepository) {
+		t.Helper()
+		dep := newTestDeployment(t, proj, project.EnvironmentProduction, deployment.StatusPending)
+		if err := dep.AwaitApproval(); err != nil {
+			t.Fatalf("AwaitApproval() error = %v", err)
+		}
+		approvals := newMockApprovalRepository()
+		svc := service.NewDeploymentService(newMockDeploymentRepository(dep), newMockProjectRepository(proj), &mockBuildJobs{}, approvals, mockUnitOfWork{})
+		if _, err := svc.SetEnvironmentApprovers(ctx, proj.ID().String(), "production", &dto.EnvironmentApproversRequest{
+			UserIDs: []string{approver.String(), owner.String(), approver.String()},
+		}); err != nil {
+			t.Fatalf("SetEnvironmentApprovers() error = %v", err)
+		}
+		return svc, dep, approvals
+	}
+
+	t.Run("refuses the user who started the deployment", func(t *testing.T) {
+		svc, dep, approvals := newService(t)
+		for _, operator := range []bool{false, true} {
+			_, err := svc.ApproveDeployment(ctx, dep.ID().String(), owner.String(), operator, &dto.DeploymentDecisionRequest{})
+			if !errors.Is(err, deployment.ErrSelfApproval) {
+				t.Errorf("ApproveDeployment() by its author (operator %v) error = %v, want ErrSelfApproval", operator, err)
+			}
+		}
+		if dep.Status() != deployment.StatusWaitingApproval || len(approvals.saved) != 0 {
+			t.Errorf("status = %v with %d decisions, want the deployment still waiting", dep.Status(), len(approvals.saved))
+		}
+	})
+
+	t.Run("refuses users who aren't approvers", func(t *testing.T) {
+		svc, dep, _ := newService(t)
+		for name, decide := range map[string]func(context.Context, string, string, bool, *dto.DeploymentDecisionRequest) (*dto.DeploymentResponse, error){
+			"ApproveDeployment": svc.ApproveDeployment,
+			"RejectDeployment":  svc.RejectDeployment,
+		} {
+			if _, err := decide(ctx, dep.ID().String(), stranger.String(), false, &dto.DeploymentDecisionRequest{}); !errors.Is(err, deployment.ErrNotApprover) {
+				t.Errorf("%s() by a stranger error = %v, want ErrNotApprover", name, err)
+			}
+		}
+	})
+
+	t.Run("lets approvers and operators decide", func(t *testing.T) {
+		svc, dep, approvals := newService(t)
+		if _, err := svc.ApproveDeployment(ctx, dep.ID().String(), approver.String(), false, &dto.DeploymentDecisionRequest{}); err != nil {
+			t.Fatalf("ApproveDeployment() by an approver error = %v", err)
+		}
+		if dep.Status() != deployment.StatusPending || len(approvals.saved) != 1 || approvals.saved[0].UserID != approver {
+			t.Errorf("status = %v with decisions %+v, want it approved by the approver", dep.Status(), approvals.saved)
+		}
+
+		svc, dep, _ = newService(t)
+		if _, err := svc.RejectDeployment(ctx, dep.ID().String(), stranger.String(), true, &dto.DeploymentDecisionRequest{}); err != nil {
+			t.Fatalf("RejectDeployment() by an operator error = %v", err)
+		}
+	})
+
+	t.Run("only designates approvers of protected environments", func(t *testing.T) {
+		svc, _, approvals := newService(t)
+		if got, _ := approvals.FindApprovers(ctx, proj.ID(), project.EnvironmentProduction); len(got) != 2 || !slices.Contains(got, approver) {
+			t.Errorf("approvers = %v, want the approver and the owner once each", got)
+		}
+		if _, err := svc.SetEnvironmentApprovers(ctx, proj.ID().String(), "production", &dto.EnvironmentApproversRequest{
+			UserIDs: []string{"not-a-user"},
+		}); !errors.Is(err, deployment.ErrInvalidApprovers) {
+			t.Errorf("SetEnvironmentApprovers() with an invalid user error = %v, want ErrInvalidApprovers", err)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestDeploymentService_GetDeploymentsByUserIDFilter(t *testing.T) {
+	repo := newMockDeploymentRepository()
+	svc := service.NewDeploymentService(repo, nil, nil, nil, nil)
+
+	_, err := svc.GetDeploymentsByUserID(context.Background(), user.NewUserID().String(), dto.DeploymentListFilter{
+		Status: "failed",
+		Branch: "main",
+		Since:  "2024-01-01",
+		Labels: []string{"release:v2.3.0", "team: growth"},
+		Order:  "created_at.asc",
+	}, 1, 20)
+	if err != nil {
+		t.Fatalf("GetDeploymentsByUserID() error = %v", err)
+	}
+
+	want := deployment.ListFilter{
+		Status:    deployment.StatusFailed,
+		Branch:    "main",
+		Since:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Labels:    map[string]string{"release": "v2.3.0", "team": "growth"},
+		Ascending: true,
+	}
+	if !reflect.DeepEqual(repo.filter, want) {
+		t.Errorf("filter = %+v, want %+v", repo.filter, want)
+	}
+}
+
+func TestDeploymentService_GetDeploymentsByUserIDInvalidFilter(t *testing.T) {
+	svc := service.NewDeploymentService(newMockDeploymentRepository(), nil, nil, nil, nil)
+
+	tests := []struct {
+		name   string
+		filter dto.DeploymentListFilter
+	}{
+		{"unknown status", dto.DeploymentListFilter{Status: "exploded"}},
+		{"malformed since", dto.DeploymentListFilter{Since: "last week"}},
+		{"unknown order", dto.DeploymentListFilter{Order: "branch.asc"}},
+		{"label without value", dto.DeploymentListFilter{Labels: []string{"release"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.GetDeploymentsByUserID(context.Background(), user.NewUserID().String(), tt.filter, 1, 20)
+			if !errors.Is(err, service.ErrInvalidListFilter) {
+				t.Errorf("GetDeploymentsByUserID() error = %v, want ErrInvalidListFilter", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestDeploymentService_AppendDeploymentLogRejectsLongLines(t *testing.T) {
+	ctx := context.Background()
+	owner := user.NewUserID()
+	dep := newTestDeployment(t, newTestProject(t, owner, "my-app"), project.EnvironmentProduction, deployment.StatusPending)
+	svc := service.NewDeploymentService(newMockDeploymentRepository(dep), nil, nil, nil, nil)
+
+	long := &dto.AppendDeploymentLogRequest{LogLine: strings.Repeat("x", deployment.MaxLogLineBytes+1)}
+	if _, err := svc.AppendDeploymentLog(ctx, dep.ID().String(), owner.String(), long); !errors.Is(err, deployment.ErrLogLineTooLong) {
+		t.Fatalf("AppendDeploymentLog() error = %v for a line over the limit, want ErrLogLineTooLong", err)
+	}
+	if dep.Logs().String() != "" {
+		t.Errorf("AppendDeploymentLog() logged %d bytes of a rejected line", len(dep.Logs().String()))
+	}
+
+	if _, err := svc.AppendDeploymentLog(ctx, dep.ID().String(), owner.String(), &dto.AppendDeploymentLogRequest{LogLine: "Deploying"}); err != nil {
+		t.Fatalf("AppendDeploymentLog() error = %v", err)
+	}
+	if got := dep.Logs().String(); got != "Deploying" {
+		t.Errorf("logs = %q, want %q", got, "Deploying")
+	}
+}
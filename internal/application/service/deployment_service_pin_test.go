@@ -0,0 +1,58 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestDeploymentService_PinnedDeploymentCantBeDeleted(t *testing.T) {
+	ctx := context.Background()
+	owner := user.NewUserID()
+	dep := newTestDeployment(t, newTestProject(t, owner, "my-app"), project.EnvironmentProduction, deployment.StatusPending)
+	repo := newMockDeploymentRepository(dep)
+	svc := service.NewDeploymentService(repo, nil, nil, nil, nil)
+	id := dep.ID().String()
+
+	response, err := svc.PinDeployment(ctx, id, owner.String())
+	if err != nil {
+		t.Fatalf("PinDeployment() error = %v", err)
+	}
+	if !response.Pinned {
+		t.Error("PinDeployment() returned an unpinned deployment")
+	}
+
+	if err := svc.DeleteDeployment(ctx, id, owner.String()); !errors.Is(err, deployment.ErrDeploymentPinned) {
+		t.Fatalf("DeleteDeployment() error = %v for a pinned deployment, want ErrDeploymentPinned", err)
+	}
+	if _, deleted := repo.deletedAt[dep.ID()]; deleted {
+		t.Fatal("DeleteDeployment() deleted a pinned deployment")
+	}
+
+	if _, err := svc.UnpinDeployment(ctx, id, owner.String()); err != nil {
+		t.Fatalf("UnpinDeployment() error = %v", err)
+	}
+	if err := svc.DeleteDeployment(ctx, id, owner.String()); err != nil {
+		t.Fatalf("DeleteDeployment() error = %v after unpinning", err)
+	}
+	if _, deleted := repo.deletedAt[dep.ID()]; !deleted {
+		t.Error("DeleteDeployment() didn't delete the unpinned deployment")
+	}
+}
+
+func TestDeploymentService_PinDeploymentOfAnotherUser(t *testing.T) {
+	dep := newTestDeployment(t, newTestProject(t, user.NewUserID(), "my-app"), project.EnvironmentProduction, deployment.StatusPending)
+	svc := service.NewDeploymentService(newMockDeploymentRepository(dep), nil, nil, nil, nil)
+
+	if _, err := svc.PinDeployment(context.Background(), dep.ID().String(), user.NewUserID().String()); !errors.Is(err, deployment.ErrUnauthorized) {
+		t.Errorf("PinDeployment() error = %v, want ErrUnauthorized", err)
+	}
+	if dep.IsPinned() {
+		t.Error("PinDeployment() pinned the deployment of another user")
+	}
+}
@@ -0,0 +1,136 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+	"snapdeploy-core/internal/infrastructure/builder"
+)
+
+// mockInfrastructurePlanner plans the same changes for every deployment, or fails with err
+type mockInfrastructurePlanner struct {
+	changes   []deployment.PlannedChange
+	err       error
+	imageURIs []string
+}
+
+func (m *mockInfrastructurePlanner) PlanDeployment(ctx context.Context, proj *project.Project, env project.Environment, imageURI string) ([]deployment.PlannedChange, error) {
+	m.imageURIs = append(m.imageURIs, imageURI)
+	return m.changes, m.err
+}
+
+func TestDeploymentService_PlanDeployment(t *testing.T) {
+	ctx := context.Background()
+	owner := user.NewUserID()
+
+	configured, missingEnv := newTestProject(t, owner, "configured"), newTestProject(t, owner, "missing-env")
+
+	newEnvVar := func(key, scope string) *project.EnvironmentVariable {
+		envVar, err := project.NewEnvironmentVariable(configured.ID(), project.EnvironmentProduction, key, "secret", scope)
+		if err != nil {
+			t.Fatalf("NewEnvironmentVariable() error = %v", err)
+		}
+		return envVar
+	}
+	envVars := newMockEnvVarRepository(newEnvVar("STRIPE_KEY", "RUNTIME"), newEnvVar("NPM_TOKEN", "BUILD"))
+	files := &mockRepositoryFiles{files: map[string]string{
+		configured.ID().String(): "port: 3000\nenv: [STRIPE_KEY]\n",
+		missingEnv.ID().String(): "port: 3000\nenv: [STRIPE_KEY]\n",
+	}}
+	projects := newMockProjectRepository(configured, missingEnv)
+
+	templates, err := builder.NewTemplateGenerator()
+	if err != nil {
+		t.Fatalf("NewTemplateGenerator() error = %v", err)
+	}
+	builds := service.NewBuildService(nil, nil, projects, nil, templates, service.BuildLimits{})
+	builds.SetDockerRegistry("registry.example.com")
+	builds.SetRepositoryConfigSource(files, envVars)
+
+	infra := &mockInfrastructurePlanner{changes: []deployment.PlannedChange{
+		{Action: deployment.ChangeCreate, Resource: "ECS service", Name: "snapdeploy-12345678"},
+	}}
+	svc := service.NewDeploymentService(nil, projects, nil, nil, nil)
+	svc.SetDeploymentPlanners(builds, infra)
+
+	request := func(proj *project.Project) *dto.CreateDeploymentRequest {
+		return &dto.CreateDeploymentRequest{ProjectID: proj.ID().String(), CommitHash: "abcdef1", Branch: "main"}
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		plan, err := svc.PlanDeployment(ctx, owner.String(), request(configured))
+		if err != nil {
+			t.Fatalf("PlanDeployment() error = %v", err)
+		}
+		if !plan.Valid || len(plan.Errors) != 0 || !plan.RepoConfig {
+			t.Fatalf("plan valid = %v, errors = %v, repo config = %v, want a valid plan applying snapdeploy.yaml", plan.Valid, plan.Errors, plan.RepoConfig)
+		}
+
+		wantTag := fmt.Sprintf("registry.example.com/%s:abcdef1", configured.ID())
+		if plan.ImageTag != wantTag || len(infra.imageURIs) != 1 || infra.imageURIs[0] != wantTag {
+			t.Errorf("image tag = %q, planned with %v, want %q", plan.ImageTag, infra.imageURIs, wantTag)
+		}
+		if !strings.Contains(plan.Dockerfile, "EXPOSE 3000") || !strings.Contains(plan.Dockerfile, "NPM_TOKEN") {
+			t.Error("Dockerfile doesn't expose the port from snapdeploy.yaml or take the build args")
+		}
+		if want := []string{"NPM_TOKEN"}; !reflect.DeepEqual(plan.BuildArgs, want) {
+			t.Errorf("build args = %v, want %v", plan.BuildArgs, want)
+		}
+		if want := []string{"LANGUAGE", "PORT", "PROJECT_ID", "STRIPE_KEY"}; !reflect.DeepEqual(plan.EnvVars, want) {
+			t.Errorf("env vars = %v, want %v", plan.EnvVars, want)
+		}
+		if len(plan.Changes) != 1 || plan.Changes[0].Action != "CREATE" || plan.Changes[0].Resource != "ECS service" {
+			t.Errorf("changes = %+v, want the planner's", plan.Changes)
+		}
+
+		// Nothing is saved, the configuration from snapdeploy.yaml included
+		if len(projects.saved) != 0 {
+			t.Errorf("projects saved %d times, want none", len(projects.saved))
+		}
+	})
+
+	t.Run("problems", func(t *testing.T) {
+		infra.err = fmt.Errorf("%w: blue/green deployments require CodeDeploy", deployment.ErrTargetUnavailable)
+		defer func() { infra.err = nil }()
+
+		plan, err := svc.PlanDeployment(ctx, owner.String(), request(missingEnv))
+		if err != nil {
+			t.Fatalf("PlanDeployment() error = %v", err)
+		}
+		if plan.Valid || len(plan.Errors) != 2 {
+			t.Fatalf("errors = %v, want the missing variable and the unavailable target", plan.Errors)
+		}
+		if !strings.Contains(plan.Errors[0], "not set for production: STRIPE_KEY") || !strings.Contains(plan.Errors[1], "CodeDeploy") {
+			t.Errorf("errors = %v", plan.Errors)
+		}
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		infra.err = errors.New("throttled")
+		defer func() { infra.err = nil }()
+
+		plan, err := svc.PlanDeployment(ctx, owner.String(), request(configured))
+		if err != nil {
+			t.Fatalf("PlanDeployment() error = %v", err)
+		}
+		if !plan.Valid || len(plan.Warnings) != 1 {
+			t.Errorf("valid = %v, warnings = %v, want a valid plan warning the changes are unknown", plan.Valid, plan.Warnings)
+		}
+	})
+
+	t.Run("other user", func(t *testing.T) {
+		_, err := svc.PlanDeployment(ctx, user.NewUserID().String(), request(configured))
+		if !errors.Is(err, deployment.ErrUnauthorized) {
+			t.Errorf("PlanDeployment() error = %v, want ErrUnauthorized", err)
+		}
+	})
+}
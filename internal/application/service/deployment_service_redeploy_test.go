@@ -0,0 +1,93 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestDeploymentService_RedeployEnvironment(t *testing.T) {
+	ctx := context.Background()
+	owner := user.NewUserID()
+	proj := newTestProject(t, owner, "my-app")
+
+	// newDeployment creates a deployment of the project's production environment that built an image
+	newDeployment := func(t *testing.T, status deployment.DeploymentStatus) *deployment.Deployment {
+		t.Helper()
+		dep := newTestDeployment(t, proj, project.EnvironmentProduction, status)
+		dep.RecordImage("registry.example.com/app:abc123d", "sha256:0123")
+		return dep
+	}
+
+	t.Run("deploys the last successful image again", func(t *testing.T) {
+		deployed := newDeployment(t, deployment.StatusDeployed)
+		deployments := newMockDeploymentRepository(deployed, newDeployment(t, deployment.StatusFailed))
+		resumer := &mockResumer{resumed: make(chan deployment.Step, 1)}
+		svc := service.NewDeploymentService(deployments, newMockProjectRepository(proj), nil, nil, mockUnitOfWork{})
+		svc.SetDeploymentResumer(resumer)
+
+		dep, err := svc.RedeployEnvironment(ctx, proj, project.EnvironmentProduction, owner)
+		if err != nil {
+			t.Fatalf("RedeployEnvironment() error = %v", err)
+		}
+		if dep.Type() != deployment.TypeRedeploy {
+			t.Errorf("Type() = %v, want %v", dep.Type(), deployment.TypeRedeploy)
+		}
+		if dep.ImageURI() != deployed.ImageURI() {
+			t.Errorf("ImageURI() = %s, want %s", dep.ImageURI(), deployed.ImageURI())
+		}
+		if len(deployments.saved) != 1 || deployments.saved[0] != dep {
+			t.Error("redeploy wasn't saved")
+		}
+
+		select {
+		case from := <-resumer.resumed:
+			if from != deployment.StepDB {
+				t.Errorf("deployed from %s, want %s", from, deployment.StepDB)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("image wasn't deployed")
+		}
+	})
+
+	t.Run("leaves environments with a deployment in progress", func(t *testing.T) {
+		deployments := newMockDeploymentRepository(newDeployment(t, deployment.StatusDeployed), newDeployment(t, deployment.StatusBuilding))
+		svc := service.NewDeploymentService(deployments, newMockProjectRepository(proj), nil, nil, mockUnitOfWork{})
+		svc.SetDeploymentResumer(&mockResumer{})
+
+		_, err := svc.RedeployEnvironment(ctx, proj, project.EnvironmentProduction, owner)
+		if !errors.Is(err, deployment.ErrDeploymentInProgress) {
+			t.Errorf("RedeployEnvironment() error = %v, want %v", err, deployment.ErrDeploymentInProgress)
+		}
+		if len(deployments.saved) != 0 {
+			t.Error("redeploy saved, want none")
+		}
+	})
+
+	t.Run("needs an environment deployed before", func(t *testing.T) {
+		deployments := newMockDeploymentRepository(newDeployment(t, deployment.StatusFailed))
+		svc := service.NewDeploymentService(deployments, newMockProjectRepository(proj), nil, nil, mockUnitOfWork{})
+		svc.SetDeploymentResumer(&mockResumer{})
+
+		_, err := svc.RedeployEnvironment(ctx, proj, project.EnvironmentProduction, owner)
+		if !errors.Is(err, deployment.ErrNothingToRestart) {
+			t.Errorf("RedeployEnvironment() error = %v, want %v", err, deployment.ErrNothingToRestart)
+		}
+	})
+
+	t.Run("needs images to be deployed on the platform", func(t *testing.T) {
+		deployments := newMockDeploymentRepository(newDeployment(t, deployment.StatusDeployed), newDeployment(t, deployment.StatusDeployed))
+		svc := service.NewDeploymentService(deployments, newMockProjectRepository(proj), nil, nil, mockUnitOfWork{})
+
+		_, err := svc.RedeployEnvironment(ctx, proj, project.EnvironmentProduction, owner)
+		if !errors.Is(err, deployment.ErrTargetUnavailable) {
+			t.Errorf("RedeployEnvironment() error = %v, want %v", err, deployment.ErrTargetUnavailable)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// mockSteps keeps the step records of deployments in memory
+type mockSteps struct {
+	records []deployment.StepRecord
+}
+
+func (m *mockSteps) Save(ctx context.Context, record deployment.StepRecord) error {
+	m.records = append(m.records, record)
+	return nil
+}
+
+func (m *mockSteps) FindByDeploymentID(ctx context.Context, deploymentID deployment.DeploymentID) ([]deployment.StepRecord, error) {
+	return m.records, nil
+}
+
+// mockResumer reports the steps deployments are resumed from
+type mockResumer struct {
+	resumed chan deployment.Step
+}
+
+func (m *mockResumer) ResumeDeployment(ctx context.Context, proj *project.Project, dep *deployment.Deployment, from deployment.Step) error {
+	m.resumed <- from
+	return nil
+}
+
+// mockUnitOfWork runs the work without a transaction
+type mockUnitOfWork struct{}
+
+func (mockUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestDeploymentService_RetryDeployment(t *testing.T) {
+	ctx := context.Background()
+	owner := user.NewUserID()
+	proj := newTestProject(t, owner, "my-app")
+
+	// newFailed creates a deployment that pushed its image and failed at the alb step
+	newFailed := func(t *testing.T) (*deployment.Deployment, *mockSteps) {
+		t.Helper()
+		dep := newTestDeployment(t, proj, project.EnvironmentProduction, deployment.StatusFailed)
+		dep.RecordImage("registry.example.com/app:abc123d", "")
+
+		steps := &mockSteps{}
+		for _, step := range []deployment.Step{deployment.StepClone, deployment.StepBuild, deployment.StepPush, deployment.StepDB, deployment.StepMigrate} {
+			record := deployment.StartStep(dep, step)
+			record.Finish(nil)
+			steps.records = append(steps.records, record)
+		}
+		failed := deployment.StartStep(dep, deployment.StepALB)
+		failed.Finish(errors.New("listener rule limit reached"))
+		steps.records = append(steps.records, failed)
+		return dep, steps
+	}
+
+	newService := func(deployments *mockDeploymentRepository, steps *mockSteps, jobs *mockBuildJobs, resumer *mockResumer) *service.DeploymentService {
+		svc := service.NewDeploymentService(deployments, newMockProjectRepository(proj), jobs, nil, mockUnitOfWork{})
+		svc.SetStepRepository(steps)
+		svc.SetDeploymentResumer(resumer)
+		return svc
+	}
+
+	t.Run("resumes from the failed step with the pushed image", func(t *testing.T) {
+		dep, steps := newFailed(t)
+		resumer := &mockResumer{resumed: make(chan deployment.Step, 1)}
+		jobs := &mockBuildJobs{}
+		svc := newService(newMockDeploymentRepository(dep), steps, jobs, resumer)
+
+		response, err := svc.RetryDeployment(ctx, dep.ID().String(), owner.String(), "")
+		if err != nil {
+			t.Fatalf("RetryDeployment() error = %v", err)
+		}
+		if response.Status != deployment.StatusDeploying.String() {
+			t.Errorf("status = %s, want %s", response.Status, deployment.StatusDeploying)
+		}
+
+		select {
+		case from := <-resumer.resumed:
+			if from != deployment.StepALB {
+				t.Errorf("resumed from %s, want %s", from, deployment.StepALB)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("deployment wasn't resumed")
+		}
+		if len(jobs.requeued) != 0 {
+			t.Error("build queued again, want the pushed image deployed")
+		}
+	})
+
+	t.Run("rebuilds from a build step", func(t *testing.T) {
+		dep, steps := newFailed(t)
+		jobs := &mockBuildJobs{}
+		svc := newService(newMockDeploymentRepository(dep), steps, jobs, &mockResumer{})
+
+		response, err := svc.RetryDeployment(ctx, dep.ID().String(), owner.String(), "build")
+		if err != nil {
+			t.Fatalf("RetryDeployment(build) error = %v", err)
+		}
+		if response.Status != deployment.StatusPending.String() {
+			t.Errorf("status = %s, want %s", response.Status, deployment.StatusPending)
+		}
+		if len(jobs.requeued) != 1 || !jobs.requeued[0].Equals(dep.ID()) {
+			t.Errorf("requeued %v, want the deployment's build", jobs.requeued)
+		}
+		if last := steps.records[len(steps.records)-1]; last.Step != deployment.StepBuild || last.Status != deployment.StepRunning {
+			t.Errorf("last step recorded = %s %s, want the build running again", last.Step, last.Status)
+		}
+	})
+
+	t.Run("can't skip a step that didn't complete", func(t *testing.T) {
+		dep, steps := newFailed(t)
+		svc := newService(newMockDeploymentRepository(dep), steps, &mockBuildJobs{}, &mockResumer{})
+
+		if _, err := svc.RetryDeployment(ctx, dep.ID().String(), owner.String(), "dns"); !errors.Is(err, deployment.ErrNotRetryable) {
+			t.Errorf("RetryDeployment(dns) error = %v, want ErrNotRetryable", err)
+		}
+		if _, err := svc.RetryDeployment(ctx, dep.ID().String(), owner.String(), "deploy"); !errors.Is(err, deployment.ErrInvalidStep) {
+			t.Errorf("RetryDeployment(deploy) error = %v, want ErrInvalidStep", err)
+		}
+		if dep.Status() != deployment.StatusFailed {
+			t.Errorf("status = %s, want the deployment left failed", dep.Status())
+		}
+	})
+
+	t.Run("only the latest deployment", func(t *testing.T) {
+		dep, steps := newFailed(t)
+		newer, _ := newFailed(t)
+		svc := newService(newMockDeploymentRepository(dep, newer), steps, &mockBuildJobs{}, &mockResumer{})
+
+		if _, err := svc.RetryDeployment(ctx, dep.ID().String(), owner.String(), ""); !errors.Is(err, deployment.ErrNotRetryable) {
+			t.Errorf("RetryDeployment() of an older deployment error = %v, want ErrNotRetryable", err)
+		}
+	})
+}
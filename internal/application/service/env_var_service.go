@@ -2,35 +2,100 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/events"
 	"snapdeploy-core/internal/domain/project"
 	"snapdeploy-core/internal/domain/user"
 	"snapdeploy-core/internal/infrastructure/encryption"
 )
 
+// EnvironmentRedeployer deploys the image an environment last deployed successfully again, so its tasks
+// start with the project's current environment variables
+type EnvironmentRedeployer interface {
+	RedeployEnvironment(ctx context.Context, proj *project.Project, env project.Environment, userID user.UserID) (*deployment.Deployment, error)
+}
+
+// EnvVarQuota decides whether a user may add another environment variable to a project's environment
+type EnvVarQuota interface {
+	CheckEnvVars(ctx context.Context, userID user.UserID, projectID project.ProjectID, env project.Environment) error
+}
+
 // EnvVarService handles environment variable use cases
 type EnvVarService struct {
 	envVarRepo        project.EnvironmentVariableRepository
 	projectRepo       project.ProjectRepository
+	deploymentRepo    deployment.DeploymentRepository
+	changeRepo        deployment.EnvChangeRepository
 	encryptionService *encryption.EncryptionService
+	redeployer        EnvironmentRedeployer
+	quota             EnvVarQuota
 }
 
 // NewEnvVarService creates a new environment variable service
 func NewEnvVarService(
 	envVarRepo project.EnvironmentVariableRepository,
 	projectRepo project.ProjectRepository,
+	deploymentRepo deployment.DeploymentRepository,
+	changeRepo deployment.EnvChangeRepository,
 	encryptionService *encryption.EncryptionService,
 ) *EnvVarService {
 	return &EnvVarService{
 		envVarRepo:        envVarRepo,
 		projectRepo:       projectRepo,
+		deploymentRepo:    deploymentRepo,
+		changeRepo:        changeRepo,
 		encryptionService: encryptionService,
 	}
 }
 
+// SetRedeployer sets what redeploys the environments of projects redeploying on environment variable
+// changes (optional). Without one, changes wait for the next deployment.
+func (s *EnvVarService) SetRedeployer(redeployer EnvironmentRedeployer) {
+	s.redeployer = redeployer
+}
+
+// SetEnvVarQuota sets what limits how many environment variables a project's environment may have (optional)
+func (s *EnvVarService) SetEnvVarQuota(quota EnvVarQuota) {
+	s.quota = quota
+}
+
+// RegisterHandlers subscribes the service to deployment events so it records the deployments picking up
+// environment variable changes
+func (s *EnvVarService) RegisterHandlers(dispatcher *events.Dispatcher) {
+	dispatcher.Register(deployment.EventTypeDeploymentStatusChanged, s.onDeploymentStatusChanged)
+}
+
+func (s *EnvVarService) onDeploymentStatusChanged(ctx context.Context, event events.DomainEvent) error {
+	changed, ok := event.(*deployment.DeploymentStatusChanged)
+	if !ok || changed.NewStatus != deployment.StatusDeployed.String() {
+		return nil
+	}
+
+	did, err := deployment.ParseDeploymentID(changed.DeploymentID)
+	if err != nil {
+		return fmt.Errorf("invalid deployment ID in event: %w", err)
+	}
+	dep, err := s.deploymentRepo.FindByIDIncludingDeleted(ctx, did)
+	if err != nil {
+		return err
+	}
+	if !dep.PicksUpEnvChanges() {
+		return nil
+	}
+
+	// The deployment loaded the variables after it was created, so a change made before then is deployed
+	if _, err := s.changeRepo.MarkApplied(ctx, dep.ProjectID(), dep.Environment(), dep.ID(), dep.CreatedAt()); err != nil {
+		return err
+	}
+	return nil
+}
+
 // CreateOrUpdateEnvVar creates or updates an environment variable
 func (s *EnvVarService) CreateOrUpdateEnvVar(
 	ctx context.Context,
@@ -58,24 +123,45 @@ func (s *EnvVarService) CreateOrUpdateEnvVar(
 		return nil, project.ErrUnauthorized
 	}
 
+	env, err := findEnvironment(proj, req.Environment)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create environment variable entity
-	envVar, err := project.NewEnvironmentVariable(pid, req.Key, req.Value)
+	envVar, err := project.NewEnvironmentVariable(pid, env, req.Key, req.Value, req.Scope)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create environment variable: %w", err)
 	}
 
+	// Only new variables count against the limit, updating one doesn't add any
+	if s.quota != nil {
+		_, err := s.envVarRepo.FindByKey(ctx, pid, env, envVar.Key())
+		if errors.Is(err, project.ErrEnvVarNotFound) {
+			err = s.quota.CheckEnvVars(ctx, proj.UserID(), pid, env)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Save (will be encrypted in repository)
 	if err := s.envVarRepo.Save(ctx, envVar); err != nil {
 		return nil, fmt.Errorf("failed to save environment variable: %w", err)
 	}
 
-	return s.toDTO(envVar), nil
+	response := s.toDTO(envVar)
+	if redeploy := s.envChanged(ctx, proj, env, uid); redeploy != nil {
+		response.RedeployDeploymentID = redeploy.ID().String()
+	}
+	return response, nil
 }
 
-// GetProjectEnvVars retrieves all environment variables for a project
+// GetProjectEnvVars retrieves all environment variables of a project's environment.
+// An empty environment selects production.
 func (s *EnvVarService) GetProjectEnvVars(
 	ctx context.Context,
-	projectID, userID string,
+	projectID, userID, environment string,
 ) (*dto.EnvVarListResponse, error) {
 	// Parse IDs
 	pid, err := project.ParseProjectID(projectID)
@@ -98,8 +184,13 @@ func (s *EnvVarService) GetProjectEnvVars(
 		return nil, project.ErrUnauthorized
 	}
 
+	env, err := findEnvironment(proj, environment)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get all environment variables
-	envVars, err := s.envVarRepo.FindByProjectID(ctx, pid)
+	envVars, err := s.envVarRepo.FindByProjectID(ctx, pid, env)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get environment variables: %w", err)
 	}
@@ -110,18 +201,25 @@ func (s *EnvVarService) GetProjectEnvVars(
 		envVarResponses[i] = s.toDTO(envVar)
 	}
 
-	count, _ := s.envVarRepo.Count(ctx, pid)
+	count, _ := s.envVarRepo.Count(ctx, pid, env)
+
+	change, err := s.changeRepo.Find(ctx, pid, env)
+	if err != nil {
+		return nil, err
+	}
 
 	return &dto.EnvVarListResponse{
 		EnvironmentVariables: envVarResponses,
 		Count:                count,
+		LastChange:           toEnvChangeDTO(change),
 	}, nil
 }
 
-// DeleteEnvVar deletes an environment variable
+// DeleteEnvVar deletes an environment variable of a project's environment.
+// An empty environment selects production.
 func (s *EnvVarService) DeleteEnvVar(
 	ctx context.Context,
-	projectID, userID, key string,
+	projectID, userID, key, environment string,
 ) error {
 	// Parse IDs
 	pid, err := project.ParseProjectID(projectID)
@@ -149,14 +247,99 @@ func (s *EnvVarService) DeleteEnvVar(
 		return project.ErrUnauthorized
 	}
 
+	env, err := findEnvironment(proj, environment)
+	if err != nil {
+		return err
+	}
+
 	// Delete environment variable
-	if err := s.envVarRepo.Delete(ctx, pid, envKey); err != nil {
+	if err := s.envVarRepo.Delete(ctx, pid, env, envKey); err != nil {
 		return fmt.Errorf("failed to delete environment variable: %w", err)
 	}
 
+	s.envChanged(ctx, proj, env, uid)
 	return nil
 }
 
+// CopyEnvVars copies the environment variables of a project's environments to another project with the same
+// environments, such as its duplicate, returning how many were copied. Values are decrypted and encrypted again
+// for the copy.
+func (s *EnvVarService) CopyEnvVars(ctx context.Context, from, to *project.Project) (int, error) {
+	copied := 0
+	for _, env := range from.Environments() {
+		if !to.HasEnvironment(env) {
+			continue
+		}
+
+		envVars, err := s.envVarRepo.FindByProjectID(ctx, from.ID(), env)
+		if err != nil {
+			return copied, fmt.Errorf("failed to list environment variables of %s: %w", env.String(), err)
+		}
+
+		for _, envVar := range envVars {
+			value, err := s.encryptionService.Decrypt(envVar.Value().EncryptedValue())
+			if err != nil {
+				return copied, fmt.Errorf("failed to decrypt %s: %w", envVar.Key().String(), err)
+			}
+
+			// Save encrypts the value again, with the active key
+			envVarCopy, err := project.NewEnvironmentVariable(to.ID(), env, envVar.Key().String(), value, envVar.Scope().String())
+			if err != nil {
+				return copied, fmt.Errorf("failed to copy %s: %w", envVar.Key().String(), err)
+			}
+			if err := s.envVarRepo.Save(ctx, envVarCopy); err != nil {
+				return copied, fmt.Errorf("failed to save %s: %w", envVar.Key().String(), err)
+			}
+			copied++
+		}
+	}
+	return copied, nil
+}
+
+// envChanged records that an environment's variables changed and redeploys the environment if its project
+// asks for it. Returns the redeploy, or nil when the change waits for the next deployment. The change is
+// saved either way, so failing to redeploy only leaves it pending.
+func (s *EnvVarService) envChanged(ctx context.Context, proj *project.Project, env project.Environment, userID user.UserID) *deployment.Deployment {
+	if err := s.changeRepo.RecordChange(ctx, proj.ID(), env, time.Now()); err != nil {
+		slog.WarnContext(ctx, "Failed to record environment variable change", "project_id", proj.ID().String(), "environment", env.String(), "error", err)
+	}
+
+	if !proj.RedeployOnEnvChange() || s.redeployer == nil {
+		return nil
+	}
+	if proj.IsProtected(env) {
+		// Deployments of protected environments wait for approval, the change waits for the next one
+		slog.InfoContext(ctx, "Not redeploying protected environment after environment variable change", "project_id", proj.ID().String(), "environment", env.String())
+		return nil
+	}
+
+	dep, err := s.redeployer.RedeployEnvironment(ctx, proj, env, userID)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to redeploy environment after environment variable change", "project_id", proj.ID().String(), "environment", env.String(), "error", err)
+		return nil
+	}
+	return dep
+}
+
+// toEnvChangeDTO converts the latest change to an environment's variables to a DTO, nil if none was recorded
+func toEnvChangeDTO(change *deployment.EnvChange) *dto.EnvChangeResponse {
+	if change == nil {
+		return nil
+	}
+
+	response := &dto.EnvChangeResponse{
+		ChangedAt: change.ChangedAt.Format(time.RFC3339),
+		Pending:   change.Pending(),
+	}
+	if change.AppliedBy != nil {
+		response.AppliedDeploymentID = change.AppliedBy.String()
+	}
+	if change.AppliedAt != nil {
+		response.AppliedAt = change.AppliedAt.Format(time.RFC3339)
+	}
+	return response
+}
+
 // toDTO converts domain env var to DTO with masked value
 func (s *EnvVarService) toDTO(envVar *project.EnvironmentVariable) *dto.EnvVarResponse {
 	// Decrypt value to mask it properly
@@ -166,13 +349,27 @@ func (s *EnvVarService) toDTO(envVar *project.EnvironmentVariable) *dto.EnvVarRe
 	maskedValue := maskValue(decrypted, err)
 
 	return &dto.EnvVarResponse{
-		ID:        envVar.ID().String(),
-		ProjectID: envVar.ProjectID().String(),
-		Key:       envVar.Key().String(),
-		Value:     maskedValue,
-		CreatedAt: envVar.CreatedAt().Format(time.RFC3339),
-		UpdatedAt: envVar.UpdatedAt().Format(time.RFC3339),
+		ID:          envVar.ID().String(),
+		ProjectID:   envVar.ProjectID().String(),
+		Key:         envVar.Key().String(),
+		Value:       maskedValue,
+		Scope:       envVar.Scope().String(),
+		Environment: envVar.Environment().String(),
+		CreatedAt:   envVar.CreatedAt().Format(time.RFC3339),
+		UpdatedAt:   envVar.UpdatedAt().Format(time.RFC3339),
+	}
+}
+
+// findEnvironment resolves the name of one of a project's environments, an empty name selecting production
+func findEnvironment(proj *project.Project, name string) (project.Environment, error) {
+	env, err := project.NewEnvironment(name)
+	if err != nil {
+		return "", err
+	}
+	if !proj.HasEnvironment(env) {
+		return "", project.ErrEnvironmentNotFound
 	}
+	return env, nil
 }
 
 // maskValue masks a value for display: first_char*******last_char
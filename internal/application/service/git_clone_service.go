@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/domain/user"
+	"snapdeploy-core/internal/domain/validation"
+)
+
+// ErrUnsupportedGitProvider is returned for repositories that aren't hosted on a supported Git provider
+var ErrUnsupportedGitProvider = validation.New("repository isn't hosted on a supported Git provider")
+
+// OAuthTokenProvider retrieves a user's OAuth access token for a Git provider
+type OAuthTokenProvider interface {
+	GetOAuthAccessToken(ctx context.Context, clerkUserID, provider string) (string, error)
+}
+
+// CloneTokenSource issues tokens scoped to a single repository, such as GitHub App installation tokens
+type CloneTokenSource interface {
+	GetCloneToken(ctx context.Context, proj *project.Project) (string, error)
+}
+
+// OAuthProviderName returns the name Clerk uses for a Git provider's OAuth connection (e.g. oauth_gitlab)
+func OAuthProviderName(provider repo.Provider) string {
+	return "oauth_" + strings.ToLower(provider.String())
+}
+
+// GitCloneService resolves the credentials used to clone project repositories
+type GitCloneService struct {
+	userRepo    user.Repository
+	tokens      OAuthTokenProvider
+	providers   map[repo.Provider]repo.GitProvider
+	cloneTokens CloneTokenSource
+}
+
+// NewGitCloneService creates a new clone service for repositories hosted on the given Git providers
+func NewGitCloneService(userRepo user.Repository, tokens OAuthTokenProvider, providers ...repo.GitProvider) *GitCloneService {
+	return &GitCloneService{
+		userRepo:  userRepo,
+		tokens:    tokens,
+		providers: gitProviderMap(providers),
+	}
+}
+
+// SetCloneTokenSource sets the source of repository-scoped tokens used for GitHub repositories (optional)
+func (s *GitCloneService) SetCloneTokenSource(source CloneTokenSource) {
+	s.cloneTokens = source
+}
+
+// GetCloneCredentials returns the credentials for cloning a project's repository.
+// GitHub repositories prefer a token scoped to the repository and fall back to the owner's OAuth token;
+// other providers use the owner's OAuth token. Returns nil if the repository should be cloned without credentials.
+func (s *GitCloneService) GetCloneCredentials(ctx context.Context, proj *project.Project) (*repo.CloneCredentials, error) {
+	repositoryURL := proj.RepositoryURL().String()
+
+	provider, ok := repo.ProviderFromURL(repositoryURL)
+	if !ok {
+		return nil, nil
+	}
+	gitProvider, ok := s.providers[provider]
+	if !ok {
+		return nil, nil
+	}
+
+	token, err := s.token(ctx, proj, provider)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, nil
+	}
+
+	return gitProvider.CloneCredentials(repositoryURL, token)
+}
+
+// FetchRepositoryFile fetches a file of a project's repository at a ref (a branch or commit SHA), with the token
+// the repository would be cloned with. Returns repo.ErrFileNotFound if the file doesn't exist or the repository
+// isn't hosted on a supported provider.
+func (s *GitCloneService) FetchRepositoryFile(ctx context.Context, proj *project.Project, ref, path string) ([]byte, error) {
+	repositoryURL := proj.RepositoryURL().String()
+
+	provider, ok := repo.ProviderFromURL(repositoryURL)
+	if !ok {
+		return nil, repo.ErrFileNotFound
+	}
+	gitProvider, ok := s.providers[provider]
+	if !ok {
+		return nil, repo.ErrFileNotFound
+	}
+
+	// Public repositories can be read without a token, so the file is still fetched when none is available
+	token, err := s.token(ctx, proj, provider)
+	if err != nil {
+		token = ""
+	}
+
+	return gitProvider.FetchFile(ctx, token, repositoryURL, ref, path)
+}
+
+// CompareRepositoryCommits fetches the commits of a project's repository reachable from head but not from base,
+// with the token the repository would be cloned with
+func (s *GitCloneService) CompareRepositoryCommits(ctx context.Context, proj *project.Project, base, head string) (*repo.CommitComparison, error) {
+	repositoryURL := proj.RepositoryURL().String()
+
+	provider, ok := repo.ProviderFromURL(repositoryURL)
+	if !ok {
+		return nil, fmt.Errorf("%s isn't hosted on a supported Git provider", repositoryURL)
+	}
+	gitProvider, ok := s.providers[provider]
+	if !ok {
+		return nil, fmt.Errorf("%s isn't hosted on a supported Git provider", repositoryURL)
+	}
+
+	// Public repositories can be read without a token
+	token, err := s.token(ctx, proj, provider)
+	if err != nil {
+		token = ""
+	}
+
+	return gitProvider.CompareCommits(ctx, token, repositoryURL, base, head)
+}
+
+// ListRepositoryBranches fetches the branch names of a project's repository with the token it would be cloned
+// with, failing if the repository doesn't exist or the token can't read it. Returns ErrUnsupportedGitProvider
+// if the repository isn't hosted on a supported provider.
+func (s *GitCloneService) ListRepositoryBranches(ctx context.Context, proj *project.Project) ([]string, error) {
+	repositoryURL := proj.RepositoryURL().String()
+
+	provider, ok := repo.ProviderFromURL(repositoryURL)
+	if !ok {
+		return nil, ErrUnsupportedGitProvider
+	}
+	gitProvider, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrUnsupportedGitProvider
+	}
+
+	// Public repositories can be read without a token
+	token, err := s.token(ctx, proj, provider)
+	if err != nil {
+		token = ""
+	}
+
+	return gitProvider.FetchBranches(ctx, token, repositoryFullName(repositoryURL))
+}
+
+// repositoryFullName returns the path of a repository URL its provider identifies it by, such as owner/name
+// on GitHub or group/subgroup/name on GitLab
+func repositoryFullName(repositoryURL string) string {
+	path := strings.TrimPrefix(strings.TrimPrefix(repositoryURL, "https://"), "http://")
+	if i := strings.Index(path, "/"); i >= 0 {
+		path = path[i+1:]
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+}
+
+// token returns the token a project's repository is accessed with: for GitHub a token scoped to the repository
+// if available, otherwise the owner's OAuth token
+func (s *GitCloneService) token(ctx context.Context, proj *project.Project, provider repo.Provider) (string, error) {
+	if provider == repo.ProviderGitHub && s.cloneTokens != nil {
+		cloneToken, err := s.cloneTokens.GetCloneToken(ctx, proj)
+		if err != nil {
+			return "", err
+		}
+		if cloneToken != "" {
+			return cloneToken, nil
+		}
+	}
+
+	return s.ownerToken(ctx, proj, provider)
+}
+
+// ownerToken returns the project owner's OAuth token for a Git provider
+func (s *GitCloneService) ownerToken(ctx context.Context, proj *project.Project, provider repo.Provider) (string, error) {
+	owner, err := s.userRepo.FindByID(ctx, proj.UserID())
+	if err != nil {
+		return "", fmt.Errorf("failed to get project owner: %w", err)
+	}
+	token, err := s.tokens.GetOAuthAccessToken(ctx, owner.ClerkUserID().String(), OAuthProviderName(provider))
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s token: %w", provider, err)
+	}
+	return token, nil
+}
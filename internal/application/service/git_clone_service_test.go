@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/domain/user"
+)
+
+type mockOAuthTokens struct {
+	tokens map[string]string // provider -> token
+}
+
+func (m *mockOAuthTokens) GetOAuthAccessToken(ctx context.Context, clerkUserID, provider string) (string, error) {
+	token, ok := m.tokens[provider]
+	if !ok {
+		return "", errors.New("not connected")
+	}
+	return token, nil
+}
+
+type mockCloneTokens struct {
+	token string
+}
+
+func (m *mockCloneTokens) GetCloneToken(ctx context.Context, proj *project.Project) (string, error) {
+	return m.token, nil
+}
+
+func TestGitCloneService_GetCloneCredentials(t *testing.T) {
+	userRepo := newMockUserRepository()
+	owner, err := user.NewUser("owner@example.com", "owner", "user_owner")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	_ = userRepo.Save(context.Background(), owner)
+
+	tokens := &mockOAuthTokens{tokens: map[string]string{"oauth_gitlab": "gitlab-token", "oauth_github": "github-token"}}
+	svc := service.NewGitCloneService(userRepo, tokens,
+		&mockGitProvider{provider: repo.ProviderGitHub},
+		&mockGitProvider{provider: repo.ProviderGitLab},
+		&mockGitProvider{provider: repo.ProviderBitbucket},
+	)
+
+	newProject := func(repositoryURL string) *project.Project {
+		proj, err := project.NewProject(owner.ID(), repositoryURL, "npm install", "npm run build", "npm start", "NODE", "", false, "")
+		if err != nil {
+			t.Fatalf("NewProject() error = %v", err)
+		}
+		return proj
+	}
+
+	// GitLab repositories are cloned with the owner's OAuth token
+	creds, err := svc.GetCloneCredentials(context.Background(), newProject("https://gitlab.com/group/app"))
+	if err != nil || creds == nil || creds.Token != "gitlab-token" || creds.URL != "https://gitlab.com/group/app.git" {
+		t.Errorf("GetCloneCredentials(gitlab) = %+v, %v", creds, err)
+	}
+
+	// An owner without a connected Bitbucket account gets an error so the build can warn about it
+	if _, err := svc.GetCloneCredentials(context.Background(), newProject("https://bitbucket.org/workspace/app")); err == nil {
+		t.Error("GetCloneCredentials(bitbucket) without a token should fail")
+	}
+
+	// Without GitHub App installations, GitHub repositories fall back to the owner's OAuth token
+	creds, err = svc.GetCloneCredentials(context.Background(), newProject("https://github.com/acme/app"))
+	if err != nil || creds == nil || creds.Token != "github-token" {
+		t.Errorf("GetCloneCredentials(github) without installations = %+v, %v", creds, err)
+	}
+
+	// Installation tokens take precedence over the owner's token
+	svc.SetCloneTokenSource(&mockCloneTokens{token: "installation-token"})
+	creds, err = svc.GetCloneCredentials(context.Background(), newProject("https://github.com/acme/app"))
+	if err != nil || creds == nil || creds.Token != "installation-token" {
+		t.Errorf("GetCloneCredentials(github) = %+v, %v", creds, err)
+	}
+
+	// Repositories on other hosts are cloned without credentials
+	creds, err = svc.GetCloneCredentials(context.Background(), newProject("https://example.com/acme/app"))
+	if err != nil || creds != nil {
+		t.Errorf("GetCloneCredentials(other host) = %+v, %v, want no credentials", creds, err)
+	}
+}
+
+func TestGitCloneService_FetchRepositoryFile(t *testing.T) {
+	userRepo := newMockUserRepository()
+	owner, err := user.NewUser("owner@example.com", "owner", "user_owner")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	_ = userRepo.Save(context.Background(), owner)
+
+	gitlab := &mockGitProvider{provider: repo.ProviderGitLab, files: map[string][]byte{"snapdeploy.yaml": []byte("port: 3000\n")}}
+	bitbucket := &mockGitProvider{provider: repo.ProviderBitbucket}
+	tokens := &mockOAuthTokens{tokens: map[string]string{"oauth_gitlab": "gitlab-token"}}
+	svc := service.NewGitCloneService(userRepo, tokens, gitlab, bitbucket)
+
+	newProject := func(repositoryURL string) *project.Project {
+		proj, err := project.NewProject(owner.ID(), repositoryURL, "npm install", "npm run build", "npm start", "NODE", "", false, "")
+		if err != nil {
+			t.Fatalf("NewProject() error = %v", err)
+		}
+		return proj
+	}
+
+	content, err := svc.FetchRepositoryFile(context.Background(), newProject("https://gitlab.com/group/app"), "main", "snapdeploy.yaml")
+	if err != nil || string(content) != "port: 3000\n" || gitlab.fileToken != "gitlab-token" {
+		t.Errorf("FetchRepositoryFile(gitlab) = %q, %v with token %q", content, err, gitlab.fileToken)
+	}
+
+	// Without a connected account the file is still read, as public repositories need no token
+	if _, err := svc.FetchRepositoryFile(context.Background(), newProject("https://bitbucket.org/workspace/app"), "main", "snapdeploy.yaml"); !errors.Is(err, repo.ErrFileNotFound) {
+		t.Errorf("FetchRepositoryFile(bitbucket) error = %v, want ErrFileNotFound", err)
+	}
+	if bitbucket.fileToken != "" {
+		t.Errorf("FetchRepositoryFile(bitbucket) token = %q, want none", bitbucket.fileToken)
+	}
+
+	if _, err := svc.FetchRepositoryFile(context.Background(), newProject("https://example.com/acme/app"), "main", "snapdeploy.yaml"); !errors.Is(err, repo.ErrFileNotFound) {
+		t.Errorf("FetchRepositoryFile(other host) error = %v, want ErrFileNotFound", err)
+	}
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/domain/user"
+	"snapdeploy-core/internal/github"
+)
+
+// ErrGitHubAppUnavailable is returned when no GitHub App is configured
+var ErrGitHubAppUnavailable = errors.New("GitHub App is not configured")
+
+// GitHubIdentityProvider resolves the GitHub account connected to a Clerk user
+type GitHubIdentityProvider interface {
+	GetGitHubUserID(ctx context.Context, clerkUserID string) (int64, error)
+}
+
+// GitHubInstallationService handles GitHub App installations, which let SnapDeploy sync and
+// clone a user's repositories with short-lived, repository-scoped tokens instead of the user's own token
+type GitHubInstallationService struct {
+	installationRepo repo.InstallationRepo
+	identities       GitHubIdentityProvider
+	app              repo.GitHubAppService
+}
+
+// NewGitHubInstallationService creates a new GitHub installation service
+func NewGitHubInstallationService(installationRepo repo.InstallationRepo, identities GitHubIdentityProvider) *GitHubInstallationService {
+	return &GitHubInstallationService{
+		installationRepo: installationRepo,
+		identities:       identities,
+	}
+}
+
+// SetGitHubApp sets the GitHub App used to act on installations (optional)
+func (s *GitHubInstallationService) SetGitHubApp(app repo.GitHubAppService) {
+	s.app = app
+}
+
+// Enabled reports whether a GitHub App is configured
+func (s *GitHubInstallationService) Enabled() bool {
+	return s.app != nil
+}
+
+// HandleInstallationEvent records installations being created, removed, suspended and unsuspended
+func (s *GitHubInstallationService) HandleInstallationEvent(ctx context.Context, event *github.InstallationEvent) error {
+	if s.app == nil {
+		return ErrGitHubAppUnavailable
+	}
+
+	if event.Action == github.InstallationDeleted {
+		if err := s.installationRepo.Delete(ctx, event.Installation.ID); err != nil {
+			return fmt.Errorf("failed to delete installation: %w", err)
+		}
+		slog.InfoContext(ctx, "GitHub App uninstalled", "account", event.Installation.Account.Login, "installation_id", event.Installation.ID)
+		return nil
+	}
+
+	installation, err := s.installationRepo.FindByID(ctx, event.Installation.ID)
+	if err != nil {
+		if !isInstallationNotFound(err) {
+			return err
+		}
+		installation, err = repo.NewInstallation(
+			event.Installation.ID,
+			event.Installation.Account.ID,
+			event.Installation.Account.Login,
+			event.Installation.Account.Type,
+			0,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch event.Action {
+	case github.InstallationCreated:
+		installation.RecordInstaller(event.Sender.ID)
+		slog.InfoContext(ctx, "GitHub App installed", "account", event.Installation.Account.Login, "sender", event.Sender.Login, "installation_id", event.Installation.ID)
+	case github.InstallationSuspend:
+		installation.Suspend()
+	case github.InstallationUnsuspend:
+		installation.Unsuspend()
+	}
+
+	if err := s.installationRepo.Save(ctx, installation); err != nil {
+		return fmt.Errorf("failed to save installation: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimInstallation links an installation to a user. Only the GitHub user who installed the app,
+// or the owner of the personal account it was installed on, can claim it.
+func (s *GitHubInstallationService) ClaimInstallation(ctx context.Context, userID, clerkUserID string, installationID int64) (*dto.GitHubInstallationResponse, error) {
+	if s.app == nil {
+		return nil, ErrGitHubAppUnavailable
+	}
+
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	installation, err := s.installationRepo.FindByID(ctx, installationID)
+	if err != nil {
+		if !isInstallationNotFound(err) {
+			return nil, err
+		}
+		// The user can return from GitHub before the installation webhook arrives
+		installation, err = s.app.GetInstallation(ctx, installationID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	githubUserID, err := s.identities.GetGitHubUserID(ctx, clerkUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve GitHub account: %w", err)
+	}
+
+	if err := installation.Claim(uid, githubUserID); err != nil {
+		return nil, err
+	}
+
+	if err := s.installationRepo.Save(ctx, installation); err != nil {
+		return nil, fmt.Errorf("failed to save installation: %w", err)
+	}
+
+	return toInstallationDTO(installation), nil
+}
+
+// ListInstallations returns the installations a user has claimed and where to install the app
+func (s *GitHubInstallationService) ListInstallations(ctx context.Context, userID string) (*dto.GitHubInstallationListResponse, error) {
+	if s.app == nil {
+		return nil, ErrGitHubAppUnavailable
+	}
+
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	installations, err := s.installationRepo.FindByUserID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.GitHubInstallationListResponse{
+		InstallURL:    s.app.InstallURL(),
+		Installations: make([]*dto.GitHubInstallationResponse, len(installations)),
+	}
+	for i, installation := range installations {
+		response.Installations[i] = toInstallationDTO(installation)
+	}
+
+	return response, nil
+}
+
+// HasInstallations reports whether a user has claimed any installations
+func (s *GitHubInstallationService) HasInstallations(ctx context.Context, userID user.UserID) (bool, error) {
+	if s.app == nil {
+		return false, nil
+	}
+
+	installations, err := s.installationRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return len(installations) > 0, nil
+}
+
+// FetchRepositories fetches the repositories of all the active installations a user has claimed
+func (s *GitHubInstallationService) FetchRepositories(ctx context.Context, userID user.UserID) ([]*repo.RemoteRepository, error) {
+	if s.app == nil {
+		return nil, ErrGitHubAppUnavailable
+	}
+
+	installations, err := s.installationRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var repositories []*repo.RemoteRepository
+	for _, installation := range installations {
+		if installation.IsSuspended() {
+			continue
+		}
+		installationRepos, err := s.app.FetchInstallationRepositories(ctx, installation.ID())
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch repositories of %s: %w", installation.AccountLogin(), err)
+		}
+		repositories = append(repositories, installationRepos...)
+	}
+
+	return repositories, nil
+}
+
+// GetCloneToken returns a read-only token for a project's repository if the project owner
+// has claimed an active installation with access to it. Returns "" if the repository
+// should be cloned without credentials.
+func (s *GitHubInstallationService) GetCloneToken(ctx context.Context, proj *project.Project) (string, error) {
+	if s.app == nil {
+		return "", nil
+	}
+
+	owner, name, err := github.ParseRepositoryFullName(proj.RepositoryURL().String())
+	if err != nil {
+		return "", nil
+	}
+
+	installationID, err := s.app.FindRepositoryInstallation(ctx, owner, name)
+	if err != nil || installationID == 0 {
+		return "", err
+	}
+
+	// Installations only grant access to the repositories of the user who claimed them
+	installation, err := s.installationRepo.FindByID(ctx, installationID)
+	if err != nil {
+		if isInstallationNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if !installation.BelongsToUser(proj.UserID()) || installation.IsSuspended() {
+		return "", nil
+	}
+
+	return s.app.CreateCloneToken(ctx, installationID, name)
+}
+
+// isInstallationNotFound checks if an error reports a missing installation
+func isInstallationNotFound(err error) bool {
+	var domainErr *repo.DomainError
+	return errors.As(err, &domainErr) && domainErr.Code == "INSTALLATION_NOT_FOUND"
+}
+
+// toInstallationDTO converts a domain installation to a DTO
+func toInstallationDTO(installation *repo.Installation) *dto.GitHubInstallationResponse {
+	return &dto.GitHubInstallationResponse{
+		ID:           installation.ID(),
+		AccountLogin: installation.AccountLogin(),
+		AccountType:  installation.AccountType(),
+		Suspended:    installation.IsSuspended(),
+		CreatedAt:    installation.CreatedAt().Format(time.RFC3339),
+	}
+}
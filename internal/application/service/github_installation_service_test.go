@@ -0,0 +1,156 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/domain/user"
+	"snapdeploy-core/internal/github"
+)
+
+type mockInstallationRepo struct {
+	installations map[int64]*repo.Installation
+}
+
+func newMockInstallationRepo() *mockInstallationRepo {
+	return &mockInstallationRepo{installations: make(map[int64]*repo.Installation)}
+}
+
+func (m *mockInstallationRepo) Save(ctx context.Context, installation *repo.Installation) error {
+	m.installations[installation.ID()] = installation
+	return nil
+}
+
+func (m *mockInstallationRepo) FindByID(ctx context.Context, id int64) (*repo.Installation, error) {
+	installation, ok := m.installations[id]
+	if !ok {
+		return nil, repo.ErrInstallationNotFound(id)
+	}
+	return installation, nil
+}
+
+func (m *mockInstallationRepo) FindByUserID(ctx context.Context, userID user.UserID) ([]*repo.Installation, error) {
+	var result []*repo.Installation
+	for _, installation := range m.installations {
+		if installation.BelongsToUser(userID) {
+			result = append(result, installation)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockInstallationRepo) Delete(ctx context.Context, id int64) error {
+	delete(m.installations, id)
+	return nil
+}
+
+type mockGitHubIdentities struct {
+	githubUserIDs map[string]int64
+}
+
+func (m *mockGitHubIdentities) GetGitHubUserID(ctx context.Context, clerkUserID string) (int64, error) {
+	return m.githubUserIDs[clerkUserID], nil
+}
+
+// mockGitHubApp reports every repository as covered by installationID
+type mockGitHubApp struct {
+	installationID int64
+	tokenRepos     []string
+}
+
+func (m *mockGitHubApp) InstallURL() string {
+	return "https://github.com/apps/snapdeploy/installations/new"
+}
+
+func (m *mockGitHubApp) GetInstallation(ctx context.Context, installationID int64) (*repo.Installation, error) {
+	return nil, repo.ErrInstallationNotFound(installationID)
+}
+
+func (m *mockGitHubApp) FetchInstallationRepositories(ctx context.Context, installationID int64) ([]*repo.RemoteRepository, error) {
+	return nil, nil
+}
+
+func (m *mockGitHubApp) FindRepositoryInstallation(ctx context.Context, owner, name string) (int64, error) {
+	return m.installationID, nil
+}
+
+func (m *mockGitHubApp) CreateCloneToken(ctx context.Context, installationID int64, repositoryName string) (string, error) {
+	m.tokenRepos = append(m.tokenRepos, repositoryName)
+	return "ghs_token", nil
+}
+
+func TestGitHubInstallationService_CloneTokenRequiresClaim(t *testing.T) {
+	ctx := context.Background()
+	installations := newMockInstallationRepo()
+	app := &mockGitHubApp{installationID: 42}
+	identities := &mockGitHubIdentities{githubUserIDs: map[string]int64{"user_owner": 1001, "user_other": 2002}}
+
+	svc := service.NewGitHubInstallationService(installations, identities)
+	svc.SetGitHubApp(app)
+
+	// GitHub reports the app installed on an organization by GitHub user 1001
+	err := svc.HandleInstallationEvent(ctx, &github.InstallationEvent{
+		Action: github.InstallationCreated,
+		Installation: github.Installation{
+			ID:      42,
+			Account: github.Account{ID: 5000, Login: "acme", Type: repo.AccountTypeOrganization},
+		},
+		Sender: github.Account{ID: 1001, Login: "owner"},
+	})
+	if err != nil {
+		t.Fatalf("HandleInstallationEvent() error = %v", err)
+	}
+
+	owner := user.NewUserID()
+	proj, err := project.NewProject(owner, "https://github.com/acme/private-app", "npm install", "npm run build", "npm start", "NODE", "private-app", false, "")
+	if err != nil {
+		t.Fatalf("NewProject() error = %v", err)
+	}
+
+	// Unclaimed installations never grant access
+	token, err := svc.GetCloneToken(ctx, proj)
+	if err != nil || token != "" {
+		t.Fatalf("GetCloneToken() before claim = %q, %v, want no token", token, err)
+	}
+
+	// Another SnapDeploy user can't claim an installation their GitHub account didn't make
+	if _, err := svc.ClaimInstallation(ctx, user.NewUserID().String(), "user_other", 42); err == nil {
+		t.Fatal("ClaimInstallation() by another GitHub account succeeded")
+	}
+
+	if _, err := svc.ClaimInstallation(ctx, owner.String(), "user_owner", 42); err != nil {
+		t.Fatalf("ClaimInstallation() error = %v", err)
+	}
+
+	token, err = svc.GetCloneToken(ctx, proj)
+	if err != nil || token != "ghs_token" {
+		t.Fatalf("GetCloneToken() after claim = %q, %v, want ghs_token", token, err)
+	}
+	if len(app.tokenRepos) != 1 || app.tokenRepos[0] != "private-app" {
+		t.Errorf("token scoped to %v, want [private-app]", app.tokenRepos)
+	}
+
+	// Projects of other users don't get tokens from the owner's installation
+	otherProj, err := project.NewProject(user.NewUserID(), "https://github.com/acme/private-app", "npm install", "npm run build", "npm start", "NODE", "other-app", false, "")
+	if err != nil {
+		t.Fatalf("NewProject() error = %v", err)
+	}
+	if token, _ := svc.GetCloneToken(ctx, otherProj); token != "" {
+		t.Errorf("GetCloneToken() for another user's project = %q, want no token", token)
+	}
+
+	// Suspended installations stop granting access
+	err = svc.HandleInstallationEvent(ctx, &github.InstallationEvent{
+		Action:       github.InstallationSuspend,
+		Installation: github.Installation{ID: 42, Account: github.Account{ID: 5000, Login: "acme", Type: repo.AccountTypeOrganization}},
+	})
+	if err != nil {
+		t.Fatalf("HandleInstallationEvent(suspend) error = %v", err)
+	}
+	if token, _ := svc.GetCloneToken(ctx, proj); token != "" {
+		t.Errorf("GetCloneToken() for suspended installation = %q, want no token", token)
+	}
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/events"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+	"snapdeploy-core/internal/github"
+)
+
+// GitHubStatusContext is the name of the status check shown on commits and pull requests
+const GitHubStatusContext = "SnapDeploy"
+
+// githubStatusQueueSize bounds the status reports waiting to be sent
+const githubStatusQueueSize = 100
+
+// GitHubTokenProvider retrieves a user's GitHub OAuth access token
+type GitHubTokenProvider interface {
+	GetGitHubAccessToken(ctx context.Context, clerkUserID string) (string, error)
+}
+
+// GitHubStatusClient publishes commit and deployment statuses to GitHub
+type GitHubStatusClient interface {
+	CreateCommitStatus(ctx context.Context, accessToken, owner, repo, sha string, status *github.CommitStatus) error
+	CreateDeployment(ctx context.Context, accessToken, owner, repo string, req *github.DeploymentRequest) (int64, error)
+	CreateDeploymentStatus(ctx context.Context, accessToken, owner, repo string, deploymentID int64, status *github.DeploymentStatus) error
+}
+
+// statusReport is a deployment status waiting to be sent to GitHub
+type statusReport struct {
+	deploymentID string
+	status       deployment.DeploymentStatus
+}
+
+// GitHubStatusService reports deployment progress back to GitHub as a commit status check
+// and through the Deployments API, using the GitHub token of the user who deployed.
+// Reports are sent in order by a single background worker so deployments never wait on GitHub.
+type GitHubStatusService struct {
+	deploymentRepo deployment.DeploymentRepository
+	projectRepo    project.ProjectRepository
+	userRepo       user.Repository
+	tokens         GitHubTokenProvider
+	client         GitHubStatusClient
+	reports        chan statusReport
+	baseDomain     string
+
+	// githubDeployments maps SnapDeploy deployment IDs to GitHub deployment IDs (only used by Run)
+	githubDeployments map[string]int64
+}
+
+// NewGitHubStatusService creates a new GitHub status service
+func NewGitHubStatusService(
+	deploymentRepo deployment.DeploymentRepository,
+	projectRepo project.ProjectRepository,
+	userRepo user.Repository,
+	tokens GitHubTokenProvider,
+	client GitHubStatusClient,
+) *GitHubStatusService {
+	return &GitHubStatusService{
+		deploymentRepo:    deploymentRepo,
+		projectRepo:       projectRepo,
+		userRepo:          userRepo,
+		tokens:            tokens,
+		client:            client,
+		reports:           make(chan statusReport, githubStatusQueueSize),
+		githubDeployments: make(map[string]int64),
+	}
+}
+
+// SetBaseDomain sets the domain projects are served on subdomains of, snapdeploy.app by default
+func (s *GitHubStatusService) SetBaseDomain(domain string) {
+	s.baseDomain = domain
+}
+
+// RegisterHandlers subscribes the service to deployment events
+func (s *GitHubStatusService) RegisterHandlers(dispatcher *events.Dispatcher) {
+	dispatcher.Register(deployment.EventTypeDeploymentCreated, s.onDeploymentCreated)
+	dispatcher.Register(deployment.EventTypeDeploymentStatusChanged, s.onDeploymentStatusChanged)
+}
+
+// Run sends queued status reports until the context is cancelled
+func (s *GitHubStatusService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case r := <-s.reports:
+			if err := s.report(ctx, r); err != nil {
+				slog.ErrorContext(ctx, "Failed to report GitHub status", "status", r.status.String(), "deployment_id", r.deploymentID, "error", err)
+			}
+		}
+	}
+}
+
+func (s *GitHubStatusService) onDeploymentCreated(ctx context.Context, event events.DomainEvent) error {
+	created, ok := event.(*deployment.DeploymentCreated)
+	if !ok {
+		return nil
+	}
+	s.enqueue(created.DeploymentID, deployment.StatusPending)
+	return nil
+}
+
+func (s *GitHubStatusService) onDeploymentStatusChanged(ctx context.Context, event events.DomainEvent) error {
+	changed, ok := event.(*deployment.DeploymentStatusChanged)
+	if !ok {
+		return nil
+	}
+
+	status, err := deployment.NewDeploymentStatus(changed.NewStatus)
+	if err != nil {
+		return fmt.Errorf("invalid status in event: %w", err)
+	}
+	s.enqueue(changed.DeploymentID, status)
+	return nil
+}
+
+// enqueue queues a report without blocking the deployment that raised it
+func (s *GitHubStatusService) enqueue(deploymentID string, status deployment.DeploymentStatus) {
+	select {
+	case s.reports <- statusReport{deploymentID: deploymentID, status: status}:
+	default:
+		slog.Warn("GitHub status queue full, dropping report", "status", status.String(), "deployment_id", deploymentID)
+	}
+}
+
+// report sends a deployment status to GitHub
+func (s *GitHubStatusService) report(ctx context.Context, r statusReport) error {
+	did, err := deployment.ParseDeploymentID(r.deploymentID)
+	if err != nil {
+		return fmt.Errorf("invalid deployment ID: %w", err)
+	}
+
+	dep, err := s.deploymentRepo.FindByID(ctx, did)
+	if err != nil {
+		return fmt.Errorf("failed to get deployment: %w", err)
+	}
+
+	proj, err := s.projectRepo.FindByID(ctx, dep.ProjectID())
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	owner, repo, err := github.ParseRepositoryFullName(proj.RepositoryURL().String())
+	if err != nil {
+		return nil // Statuses can only be reported to GitHub repositories
+	}
+
+	usr, err := s.userRepo.FindByID(ctx, dep.UserID())
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	token, err := s.tokens.GetGitHubAccessToken(ctx, usr.ClerkUserID().String())
+	if err != nil {
+		return fmt.Errorf("failed to get GitHub token: %w", err)
+	}
+
+	environmentURL := projectDeploymentURL(s.baseDomain, proj, dep.Environment())
+	commitState, description := commitStatusFor(r.status)
+
+	commitStatus := &github.CommitStatus{
+		State:       commitState,
+		Description: description,
+		Context:     GitHubStatusContext,
+	}
+	if r.status == deployment.StatusDeployed {
+		commitStatus.TargetURL = environmentURL
+	}
+	if err := s.client.CreateCommitStatus(ctx, token, owner, repo, dep.CommitHash().String(), commitStatus); err != nil {
+		return fmt.Errorf("failed to create commit status: %w", err)
+	}
+
+	githubDeploymentID, ok := s.githubDeployments[r.deploymentID]
+	if !ok {
+		// A rollback of a deployment GitHub never saw has nothing to deactivate
+		if r.status == deployment.StatusRolledBack {
+			return nil
+		}
+
+		githubDeploymentID, err = s.client.CreateDeployment(ctx, token, owner, repo, &github.DeploymentRequest{
+			Ref:                   dep.CommitHash().String(),
+			Environment:           proj.CustomDomain().ForEnvironment(dep.Environment()).String(),
+			Description:           fmt.Sprintf("SnapDeploy %s of %s", strings.ToLower(dep.Type().String()), dep.Branch().String()),
+			RequiredContexts:      []string{}, // Don't block on the commit's other checks, the build already ran
+			ProductionEnvironment: dep.Environment().IsProduction(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create GitHub deployment: %w", err)
+		}
+		s.githubDeployments[r.deploymentID] = githubDeploymentID
+	}
+
+	deploymentStatus := &github.DeploymentStatus{
+		State:       deploymentStateFor(r.status),
+		Description: description,
+	}
+	if r.status == deployment.StatusDeployed {
+		deploymentStatus.EnvironmentURL = environmentURL
+	}
+
+	// Finished deployments won't be reported again unless retried, which starts a new GitHub deployment
+	if r.status.IsTerminal() {
+		delete(s.githubDeployments, r.deploymentID)
+	}
+
+	if err := s.client.CreateDeploymentStatus(ctx, token, owner, repo, githubDeploymentID, deploymentStatus); err != nil {
+		return fmt.Errorf("failed to create deployment status: %w", err)
+	}
+
+	return nil
+}
+
+// commitStatusFor maps a deployment status to a commit status state and description
+func commitStatusFor(status deployment.DeploymentStatus) (string, string) {
+	switch status {
+	case deployment.StatusWaitingApproval:
+		return github.StatePending, "Waiting for approval"
+	case deployment.StatusBuilding:
+		return github.StatePending, "Building"
+	case deployment.StatusDeploying:
+		return github.StatePending, "Deploying"
+	case deployment.StatusDeployed:
+		return github.StateSuccess, "Deployed"
+	case deployment.StatusFailed:
+		return github.StateFailure, "Deployment failed"
+	case deployment.StatusRolledBack:
+		return github.StateError, "Deployment rolled back"
+	case deployment.StatusRejected:
+		return github.StateError, "Deployment rejected"
+	default:
+		return github.StatePending, "Deployment queued"
+	}
+}
+
+// deploymentStateFor maps a deployment status to a GitHub deployment status state
+func deploymentStateFor(status deployment.DeploymentStatus) string {
+	switch status {
+	case deployment.StatusBuilding, deployment.StatusDeploying:
+		return github.StateInProgress
+	case deployment.StatusDeployed:
+		return github.StateSuccess
+	case deployment.StatusFailed:
+		return github.StateFailure
+	case deployment.StatusRejected:
+		return github.StateError
+	case deployment.StatusRolledBack:
+		return github.StateInactive
+	default:
+		return github.StatePending
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/idempotency"
+)
+
+// idempotencyLockTimeout is how long a request keeps its key while in progress.
+// After that the request is assumed lost, e.g. to a crashed instance, and a retry may run it again.
+const idempotencyLockTimeout = 5 * time.Minute
+
+// IdempotencyService makes requests retried with the same Idempotency-Key run only once,
+// replaying the stored response to the retries
+type IdempotencyService struct {
+	recordRepo idempotency.RecordRepository
+	ttl        time.Duration
+}
+
+// NewIdempotencyService creates a new idempotency service keeping responses for the given TTL
+func NewIdempotencyService(recordRepo idempotency.RecordRepository, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		recordRepo: recordRepo,
+		ttl:        ttl,
+	}
+}
+
+// Begin reserves a key for a request. It returns the stored response if the request was already handled,
+// or nil if the caller should handle it and then call Complete or Release.
+func (s *IdempotencyService) Begin(ctx context.Context, ownerID, key, route, requestHash string) (*dto.IdempotentResponse, error) {
+	k, err := idempotency.NewKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	reserved, err := s.recordRepo.Reserve(ctx, idempotency.NewRecord(ownerID, k, route, requestHash, s.ttl), time.Now().Add(-idempotencyLockTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	record, err := s.recordRepo.FindByKey(ctx, ownerID, k)
+	if err != nil {
+		if errors.Is(err, idempotency.ErrRecordNotFound) {
+			// Released by the first request between the two queries; the client can retry
+			return nil, idempotency.ErrRequestInProgress
+		}
+		return nil, err
+	}
+
+	if !record.Matches(route, requestHash) {
+		return nil, idempotency.ErrKeyReused
+	}
+	if !record.IsComplete() {
+		return nil, idempotency.ErrRequestInProgress
+	}
+
+	return &dto.IdempotentResponse{
+		StatusCode: record.StatusCode(),
+		Body:       record.ResponseBody(),
+	}, nil
+}
+
+// Complete stores the response to a request reserved with Begin, to be replayed to its retries
+func (s *IdempotencyService) Complete(ctx context.Context, ownerID, key string, statusCode int, body []byte) error {
+	k, err := idempotency.NewKey(key)
+	if err != nil {
+		return err
+	}
+
+	record, err := s.recordRepo.FindByKey(ctx, ownerID, k)
+	if err != nil {
+		return err
+	}
+
+	if err := record.Complete(statusCode, body); err != nil {
+		return err
+	}
+
+	return s.recordRepo.Save(ctx, record)
+}
+
+// Release forgets a request reserved with Begin, so a retry runs it again.
+// Used when the request failed in a way worth retrying, e.g. a server error.
+func (s *IdempotencyService) Release(ctx context.Context, ownerID, key string) error {
+	k, err := idempotency.NewKey(key)
+	if err != nil {
+		return err
+	}
+
+	return s.recordRepo.Delete(ctx, ownerID, k)
+}
+
+// PurgeExpired removes the responses kept for longer than the TTL
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	deleted, err := s.recordRepo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge expired idempotency keys: %w", err)
+	}
+	return deleted, nil
+}
+
+// RunPurger removes expired responses on every interval until the context is cancelled
+func (s *IdempotencyService) RunPurger(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		slog.InfoContext(ctx, "Idempotency key purging disabled", "interval", interval.String())
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.PurgeExpired(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "Idempotency key purging failed", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				slog.InfoContext(ctx, "Purged expired idempotency keys", "count", deleted)
+			}
+		}
+	}
+}
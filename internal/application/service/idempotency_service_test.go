@@ -0,0 +1,144 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/idempotency"
+)
+
+// Mock implementations
+type mockIdempotencyRepository struct {
+	records map[string]*idempotency.Record
+}
+
+func newMockIdempotencyRepository() *mockIdempotencyRepository {
+	return &mockIdempotencyRepository{
+		records: make(map[string]*idempotency.Record),
+	}
+}
+
+func (m *mockIdempotencyRepository) Reserve(ctx context.Context, record *idempotency.Record, staleBefore time.Time) (bool, error) {
+	id := record.OwnerID() + "/" + record.Key().String()
+	if existing, ok := m.records[id]; ok {
+		expired := !existing.ExpiresAt().After(record.CreatedAt())
+		stale := !existing.IsComplete() && existing.CreatedAt().Before(staleBefore)
+		if !expired && !stale {
+			return false, nil
+		}
+	}
+	m.records[id] = record
+	return true, nil
+}
+
+func (m *mockIdempotencyRepository) FindByKey(ctx context.Context, ownerID string, key idempotency.Key) (*idempotency.Record, error) {
+	record, ok := m.records[ownerID+"/"+key.String()]
+	if !ok {
+		return nil, idempotency.ErrRecordNotFound
+	}
+	return record, nil
+}
+
+func (m *mockIdempotencyRepository) Save(ctx context.Context, record *idempotency.Record) error {
+	m.records[record.OwnerID()+"/"+record.Key().String()] = record
+	return nil
+}
+
+func (m *mockIdempotencyRepository) Delete(ctx context.Context, ownerID string, key idempotency.Key) error {
+	delete(m.records, ownerID+"/"+key.String())
+	return nil
+}
+
+func (m *mockIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	for id, record := range m.records {
+		if !record.ExpiresAt().After(now) {
+			delete(m.records, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func TestIdempotencyService_ReplaysCompletedRequest(t *testing.T) {
+	ctx := context.Background()
+	svc := service.NewIdempotencyService(newMockIdempotencyRepository(), time.Hour)
+
+	replay, err := svc.Begin(ctx, "user_a", "key-1", "create_deployment", "hash-1")
+	if err != nil || replay != nil {
+		t.Fatalf("first Begin() = %v, %v; want nil, nil", replay, err)
+	}
+
+	// A retry while the first request is running must not run it again
+	if _, err := svc.Begin(ctx, "user_a", "key-1", "create_deployment", "hash-1"); !errors.Is(err, idempotency.ErrRequestInProgress) {
+		t.Errorf("Begin() during request error = %v, want ErrRequestInProgress", err)
+	}
+
+	if err := svc.Complete(ctx, "user_a", "key-1", 201, []byte(`{"id":"dep-1"}`)); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	replay, err = svc.Begin(ctx, "user_a", "key-1", "create_deployment", "hash-1")
+	if err != nil {
+		t.Fatalf("Begin() after completion error = %v", err)
+	}
+	if replay == nil || replay.StatusCode != 201 || string(replay.Body) != `{"id":"dep-1"}` {
+		t.Errorf("Begin() after completion = %+v, want the stored response", replay)
+	}
+
+	// The key was used for another request
+	if _, err := svc.Begin(ctx, "user_a", "key-1", "create_deployment", "hash-2"); !errors.Is(err, idempotency.ErrKeyReused) {
+		t.Errorf("Begin() with a different request error = %v, want ErrKeyReused", err)
+	}
+
+	// Keys are scoped per user
+	if replay, err := svc.Begin(ctx, "user_b", "key-1", "create_deployment", "hash-2"); err != nil || replay != nil {
+		t.Errorf("Begin() for another user = %v, %v; want nil, nil", replay, err)
+	}
+}
+
+func TestIdempotencyService_ReleaseAllowsRetry(t *testing.T) {
+	ctx := context.Background()
+	svc := service.NewIdempotencyService(newMockIdempotencyRepository(), time.Hour)
+
+	if _, err := svc.Begin(ctx, "user_a", "key-1", "create_project", "hash-1"); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	if err := svc.Release(ctx, "user_a", "key-1"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	replay, err := svc.Begin(ctx, "user_a", "key-1", "create_project", "hash-1")
+	if err != nil || replay != nil {
+		t.Errorf("Begin() after Release() = %v, %v; want nil, nil", replay, err)
+	}
+}
+
+func TestIdempotencyService_InvalidKey(t *testing.T) {
+	svc := service.NewIdempotencyService(newMockIdempotencyRepository(), time.Hour)
+
+	if _, err := svc.Begin(context.Background(), "user_a", "not a key", "create_project", "hash-1"); !errors.Is(err, idempotency.ErrInvalidKey) {
+		t.Errorf("Begin() error = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestIdempotencyService_PurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockIdempotencyRepository()
+	svc := service.NewIdempotencyService(repo, -time.Minute)
+
+	if _, err := svc.Begin(ctx, "user_a", "key-1", "create_project", "hash-1"); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+
+	deleted, err := svc.PurgeExpired(ctx)
+	if err != nil {
+		t.Fatalf("PurgeExpired() error = %v", err)
+	}
+	if deleted != 1 || len(repo.records) != 0 {
+		t.Errorf("PurgeExpired() deleted %d, %d records left; want 1, 0", deleted, len(repo.records))
+	}
+}
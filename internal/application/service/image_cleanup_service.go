@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+)
+
+// imageCleanupPageSize is how many projects are loaded at a time while cleaning up images
+const imageCleanupPageSize = 100
+
+// ImagePruner deletes the images of a project that carry none of the kept tags
+type ImagePruner interface {
+	PruneProjectImages(ctx context.Context, projectID string, keepTags []string, pushedBefore time.Time, dryRun bool) (int, error)
+}
+
+// ImageCleanupService deletes the images of each project that none of its recent deployments use
+type ImageCleanupService struct {
+	projectRepo       project.ProjectRepository
+	deploymentRepo    deployment.DeploymentRepository
+	pruner            ImagePruner
+	retainDeployments int
+	dryRun            bool
+}
+
+// NewImageCleanupService creates a new image cleanup service.
+// retainDeployments is how many recent deployments keep their images for projects without their own setting.
+// In dry-run mode images are only logged, not deleted.
+func NewImageCleanupService(
+	projectRepo project.ProjectRepository,
+	deploymentRepo deployment.DeploymentRepository,
+	pruner ImagePruner,
+	retainDeployments int,
+	dryRun bool,
+) *ImageCleanupService {
+	return &ImageCleanupService{
+		projectRepo:       projectRepo,
+		deploymentRepo:    deploymentRepo,
+		pruner:            pruner,
+		retainDeployments: retainDeployments,
+		dryRun:            dryRun,
+	}
+}
+
+// CleanupImages prunes the images of every project, returning how many were deleted.
+// A project whose images can't be pruned is logged and skipped so it doesn't hold up the others.
+func (s *ImageCleanupService) CleanupImages(ctx context.Context) (int, error) {
+	// Images pushed once cleanup has started may belong to deployments it hasn't seen
+	startedAt := time.Now()
+
+	total := 0
+	for offset := int32(0); ; offset += imageCleanupPageSize {
+		projects, err := s.projectRepo.FindAll(ctx, imageCleanupPageSize, offset)
+		if err != nil {
+			return total, fmt.Errorf("failed to list projects: %w", err)
+		}
+
+		for _, proj := range projects {
+			deleted, err := s.cleanupProject(ctx, proj, startedAt)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to clean up project images", "project_id", proj.ID().String(), "error", err)
+				continue
+			}
+			total += deleted
+		}
+
+		if len(projects) < imageCleanupPageSize {
+			return total, nil
+		}
+	}
+}
+
+// cleanupProject prunes the images of one project, keeping those of its recent deployments
+// and of its live and pinned deployments however old they are
+func (s *ImageCleanupService) cleanupProject(ctx context.Context, proj *project.Project, pushedBefore time.Time) (int, error) {
+	retain := proj.ImageRetention()
+	if retain == 0 {
+		retain = s.retainDeployments
+	}
+
+	recent, err := s.deploymentRepo.FindByProjectID(ctx, proj.ID(), deployment.ListFilter{}, int32(retain), 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list recent deployments: %w", err)
+	}
+
+	// Pinned deployments are kept as rollback targets, so their images are too
+	pinned, err := s.deploymentRepo.FindPinnedByProjectID(ctx, proj.ID())
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pinned deployments: %w", err)
+	}
+
+	keepTags := make([]string, 0, len(recent)+len(pinned)+len(proj.Environments()))
+	for _, dep := range append(recent, pinned...) {
+		keepTags = append(keepTags, dep.CommitHash().ImageTag())
+	}
+
+	// Each environment may run an image older than the recent deployments
+	for _, env := range proj.Environments() {
+		live, err := s.deploymentRepo.FindLatestDeployedInEnvironment(ctx, proj.ID(), env)
+		if err != nil && !errors.Is(err, deployment.ErrDeploymentNotFound) {
+			return 0, fmt.Errorf("failed to get live deployment: %w", err)
+		}
+		if live != nil {
+			keepTags = append(keepTags, live.CommitHash().ImageTag())
+		}
+	}
+
+	return s.pruner.PruneProjectImages(ctx, proj.ID().String(), keepTags, pushedBefore, s.dryRun)
+}
+
+// RunCleanup cleans up images on every interval until the context is cancelled
+func (s *ImageCleanupService) RunCleanup(ctx context.Context, interval time.Duration) {
+	if interval <= 0 || s.retainDeployments <= 0 {
+		slog.Info("Image cleanup disabled", "interval", interval, "retain_deployments", s.retainDeployments)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.CleanupImages(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "Image cleanup failed", "deleted", deleted, "error", err)
+				continue
+			}
+			if deleted > 0 {
+				slog.InfoContext(ctx, "Cleaned up unused images", "deleted", deleted, "dry_run", s.dryRun)
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// Mock implementations
+type mockImagePruner struct {
+	keepTags map[string][]string
+	dryRun   bool
+	err      map[string]error
+}
+
+func (m *mockImagePruner) PruneProjectImages(ctx context.Context, projectID string, keepTags []string, pushedBefore time.Time, dryRun bool) (int, error) {
+	if err := m.err[projectID]; err != nil {
+		return 0, err
+	}
+	m.keepTags[projectID] = keepTags
+	m.dryRun = dryRun
+	return 1, nil
+}
+
+// newImageCleanupDeployment returns a deployment of the commit to the project's production environment
+func newImageCleanupDeployment(t *testing.T, proj *project.Project, commit string) *deployment.Deployment {
+	t.Helper()
+	dep, err := deployment.NewDeployment(proj.ID(), proj.UserID(), commit, "main", project.EnvironmentProduction)
+	if err != nil {
+		t.Fatalf("NewDeployment() error = %v", err)
+	}
+	return dep
+}
+
+func TestImageCleanupService_KeepsImagesOfRecentLiveAndPinnedDeployments(t *testing.T) {
+	owner := user.NewUserID()
+	defaulted := newTestProject(t, owner, "shop")
+	custom := newTestProject(t, owner, "billing", func(proj *project.Project) error { return proj.SetImageRetention(1) })
+
+	// The live deployment and the pinned one are older than those kept as the most recent
+	live := newImageCleanupDeployment(t, defaulted, "aaaaaaa")
+	for _, status := range []deployment.DeploymentStatus{deployment.StatusBuilding, deployment.StatusDeploying, deployment.StatusDeployed} {
+		if err := live.UpdateStatus(status); err != nil {
+			t.Fatalf("UpdateStatus(%s) error = %v", status, err)
+		}
+	}
+	pinned := newImageCleanupDeployment(t, custom, "0000000")
+	pinned.Pin()
+	deployments := newMockDeploymentRepository(
+		live,
+		newImageCleanupDeployment(t, defaulted, "bbbbbbb"),
+		newImageCleanupDeployment(t, defaulted, "HEAD"),
+		newImageCleanupDeployment(t, defaulted, "ccccccc"),
+		pinned,
+		newImageCleanupDeployment(t, custom, "ddddddd"),
+		newImageCleanupDeployment(t, custom, "eeeeeee"),
+	)
+	pruner := &mockImagePruner{keepTags: map[string][]string{}}
+
+	svc := service.NewImageCleanupService(newMockProjectRepository(defaulted, custom), deployments, pruner, 2, true)
+
+	deleted, err := svc.CleanupImages(context.Background())
+	if err != nil {
+		t.Fatalf("CleanupImages() error = %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("CleanupImages() deleted %d images, want 2", deleted)
+	}
+	if !pruner.dryRun {
+		t.Error("CleanupImages() deleted images in dry-run mode")
+	}
+
+	want := map[string][]string{
+		// the platform default keeps two deployments, plus the live one
+		defaulted.ID().String(): {"aaaaaaa", "ccccccc", "latest"},
+		// the project's own setting keeps one, plus the pinned one
+		custom.ID().String(): {"0000000", "eeeeeee"},
+	}
+	for projectID, tags := range pruner.keepTags {
+		sort.Strings(tags)
+		if !reflect.DeepEqual(tags, want[projectID]) {
+			t.Errorf("CleanupImages() kept %v for project %s, want %v", tags, projectID, want[projectID])
+		}
+	}
+	if len(pruner.keepTags) != len(want) {
+		t.Errorf("CleanupImages() pruned %d projects, want %d", len(pruner.keepTags), len(want))
+	}
+}
+
+func TestImageCleanupService_SkipsProjectsThatFail(t *testing.T) {
+	owner := user.NewUserID()
+	failing := newTestProject(t, owner, "shop")
+	healthy := newTestProject(t, owner, "billing")
+
+	pruner := &mockImagePruner{
+		keepTags: map[string][]string{},
+		err:      map[string]error{failing.ID().String(): errors.New("access denied")},
+	}
+	svc := service.NewImageCleanupService(newMockProjectRepository(failing, healthy), newMockDeploymentRepository(), pruner, 10, false)
+
+	deleted, err := svc.CleanupImages(context.Background())
+	if err != nil {
+		t.Fatalf("CleanupImages() error = %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("CleanupImages() deleted %d images, want 1", deleted)
+	}
+	if _, ok := pruner.keepTags[healthy.ID().String()]; !ok {
+		t.Error("CleanupImages() skipped the project after the one that failed")
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/infrastructure/builder"
+)
+
+// SBOMGenerator lists the packages of images pushed to the registry
+type SBOMGenerator interface {
+	GenerateSBOM(ctx context.Context, imageURI string) (*deployment.SBOM, error)
+}
+
+// VulnerabilityScanner scans images pushed to the registry for known vulnerabilities, returning the
+// vulnerabilities found and the number of each severity
+type VulnerabilityScanner interface {
+	ScanImage(ctx context.Context, imageURI string) ([]deployment.Vulnerability, map[deployment.Severity]int, error)
+}
+
+// ScanPolicy holds how long deployments wait for their image's scan and which images aren't deployed
+type ScanPolicy struct {
+	Timeout     time.Duration // 0 waits as long as the deployment does
+	MaxCritical int           // images with more critical vulnerabilities aren't deployed; negative deploys any image
+}
+
+// ImageScanService scans the image of each deployment once it is built, recording its SBOM and
+// vulnerabilities, and stops deployments of images with more critical vulnerabilities than the policy allows
+type ImageScanService struct {
+	scanRepo       deployment.ScanRepository
+	deploymentRepo deployment.DeploymentRepository
+	sboms          SBOMGenerator
+	scanner        VulnerabilityScanner
+	policy         ScanPolicy
+}
+
+// NewImageScanService creates a new image scan service
+func NewImageScanService(
+	scanRepo deployment.ScanRepository,
+	deploymentRepo deployment.DeploymentRepository,
+	policy ScanPolicy,
+) *ImageScanService {
+	return &ImageScanService{
+		scanRepo:       scanRepo,
+		deploymentRepo: deploymentRepo,
+		policy:         policy,
+	}
+}
+
+// SetSBOMGenerator sets what generates the SBOMs of images (optional)
+func (s *ImageScanService) SetSBOMGenerator(sboms SBOMGenerator) {
+	s.sboms = sboms
+}
+
+// SetVulnerabilityScanner sets what scans images for vulnerabilities (optional)
+func (s *ImageScanService) SetVulnerabilityScanner(scanner VulnerabilityScanner) {
+	s.scanner = scanner
+}
+
+// Enabled reports whether images are scanned or get an SBOM
+func (s *ImageScanService) Enabled() bool {
+	return s.sboms != nil || s.scanner != nil
+}
+
+// ScanBeforeDeploying returns a deployment callback scanning built images before handing them to next
+func (s *ImageScanService) ScanBeforeDeploying(next builder.DeploymentCallback) builder.DeploymentCallback {
+	return &scanningCallback{scans: s, next: next}
+}
+
+// scanningCallback scans images before they are deployed, stopping deployments the scan policy blocks
+type scanningCallback struct {
+	scans *ImageScanService
+	next  builder.DeploymentCallback
+}
+
+// OnBuildSuccess scans the image, then deploys it unless it has too many critical vulnerabilities
+func (c *scanningCallback) OnBuildSuccess(ctx context.Context, dep *deployment.Deployment, proj *project.Project, imageURI string) error {
+	if err := c.scans.ScanImage(ctx, dep, imageURI); err != nil {
+		return err
+	}
+	return c.next.OnBuildSuccess(ctx, dep, proj, imageURI)
+}
+
+// ScanImage scans a deployment's image and records what was found. Returns ErrImageVulnerable when the
+// image has more critical vulnerabilities than the policy allows; scans that fail don't stop the deployment.
+func (s *ImageScanService) ScanImage(ctx context.Context, dep *deployment.Deployment, imageURI string) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	dep.AppendLog("🔍 Scanning image for vulnerabilities...")
+	s.saveDeployment(ctx, dep)
+
+	scanCtx := ctx
+	if s.policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		scanCtx, cancel = context.WithTimeout(ctx, s.policy.Timeout)
+		defer cancel()
+	}
+
+	report := deployment.NewScanReport(dep, imageURI)
+
+	if s.sboms != nil {
+		sbom, err := s.sboms.GenerateSBOM(scanCtx, imageURI)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to generate SBOM", "image", imageURI, "error", err)
+			report.FailSBOM(err)
+			dep.AppendLog(fmt.Sprintf("⚠️  SBOM generation failed: %v", err))
+		} else {
+			report.SBOM = sbom
+			dep.AppendLog(fmt.Sprintf("📋 SBOM generated: %d packages", sbom.Packages))
+		}
+	}
+
+	var scanErr error
+	if s.scanner == nil {
+		scanErr = errors.New("no vulnerability scanner is configured")
+	} else if vulnerabilities, counts, err := s.scanner.ScanImage(scanCtx, imageURI); err != nil {
+		scanErr = err
+	} else {
+		report.RecordVulnerabilities(vulnerabilities, counts)
+	}
+	if scanErr != nil {
+		slog.WarnContext(ctx, "Failed to scan image", "image", imageURI, "error", scanErr)
+		report.Fail(scanErr)
+		dep.AppendLog(fmt.Sprintf("⚠️  Vulnerability scan failed, deploying without it: %v", scanErr))
+	} else {
+		dep.AppendLog(fmt.Sprintf("🛡️  Vulnerability scan found %s", report.Summary()))
+	}
+
+	report.Blocked = report.Exceeds(s.policy.MaxCritical)
+	if report.Blocked {
+		dep.AppendLog(fmt.Sprintf("🚫 Deployment blocked: %d critical vulnerabilities, at most %d allowed", report.Critical(), s.policy.MaxCritical))
+	}
+	s.saveDeployment(ctx, dep)
+
+	if err := s.scanRepo.Save(ctx, report); err != nil {
+		slog.ErrorContext(ctx, "Failed to save image scan", "error", err)
+	}
+
+	if report.Blocked {
+		return fmt.Errorf("%w: %d critical, at most %d allowed", deployment.ErrImageVulnerable, report.Critical(), s.policy.MaxCritical)
+	}
+	return nil
+}
+
+// saveDeployment saves the deployment's logs, logging failures as the scan goes on regardless
+func (s *ImageScanService) saveDeployment(ctx context.Context, dep *deployment.Deployment) {
+	if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+		slog.ErrorContext(ctx, "Failed to save deployment", "error", err)
+	}
+}
+
+// GetDeploymentScan retrieves what scanning a deployment's image found
+func (s *ImageScanService) GetDeploymentScan(ctx context.Context, deploymentID string) (*dto.DeploymentScanResponse, error) {
+	report, err := s.findScan(ctx, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.DeploymentScanResponse{
+		DeploymentID:    report.DeploymentID.String(),
+		ImageURI:        report.ImageURI,
+		Status:          report.Status.String(),
+		Error:           report.Error,
+		Summary:         report.Summary(),
+		SeverityCounts:  make(map[string]int, len(report.SeverityCounts)),
+		Vulnerabilities: make([]dto.VulnerabilityResponse, len(report.Vulnerabilities)),
+		Blocked:         report.Blocked,
+		ScannedAt:       report.CreatedAt.Format(time.RFC3339),
+	}
+	for severity, count := range report.SeverityCounts {
+		response.SeverityCounts[severity.String()] = count
+	}
+	for i, v := range report.Vulnerabilities {
+		response.Vulnerabilities[i] = dto.VulnerabilityResponse{
+			ID:       v.ID,
+			Severity: v.Severity.String(),
+			Package:  v.Package,
+			Version:  v.Version,
+			URL:      v.URL,
+		}
+	}
+	if report.SBOM != nil {
+		response.SBOM = &dto.SBOMSummaryResponse{Format: report.SBOM.Format, Packages: report.SBOM.Packages}
+	}
+
+	return response, nil
+}
+
+// GetDeploymentSBOM retrieves the SBOM of a deployment's image. Returns ErrScanNotFound if none was generated.
+func (s *ImageScanService) GetDeploymentSBOM(ctx context.Context, deploymentID string) (*deployment.SBOM, error) {
+	report, err := s.findScan(ctx, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	if report.SBOM == nil {
+		return nil, fmt.Errorf("%w: no SBOM was generated for the image", deployment.ErrScanNotFound)
+	}
+	return report.SBOM, nil
+}
+
+// findScan retrieves the scan of a deployment's image
+func (s *ImageScanService) findScan(ctx context.Context, deploymentID string) (*deployment.ScanReport, error) {
+	did, err := deployment.ParseDeploymentID(deploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment ID: %w", err)
+	}
+
+	return s.scanRepo.FindByDeploymentID(ctx, did)
+}
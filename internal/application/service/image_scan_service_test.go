@@ -0,0 +1,204 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// mockScans keeps the scans saved in memory
+type mockScans struct {
+	reports map[string]*deployment.ScanReport
+}
+
+func (m *mockScans) Save(ctx context.Context, report *deployment.ScanReport) error {
+	m.reports[report.DeploymentID.String()] = report
+	return nil
+}
+
+func (m *mockScans) FindByDeploymentID(ctx context.Context, id deployment.DeploymentID) (*deployment.ScanReport, error) {
+	report, ok := m.reports[id.String()]
+	if !ok {
+		return nil, deployment.ErrScanNotFound
+	}
+	return report, nil
+}
+
+// mockVulnerabilityScanner finds the same vulnerabilities in every image, or fails with err
+type mockVulnerabilityScanner struct {
+	vulnerabilities []deployment.Vulnerability
+	err             error
+}
+
+func (m *mockVulnerabilityScanner) ScanImage(ctx context.Context, imageURI string) ([]deployment.Vulnerability, map[deployment.Severity]int, error) {
+	if m.err != nil {
+		return nil, nil, m.err
+	}
+	counts := map[deployment.Severity]int{}
+	for _, v := range m.vulnerabilities {
+		counts[v.Severity]++
+	}
+	return m.vulnerabilities, counts, nil
+}
+
+// mockSBOMGenerator generates an SBOM listing two packages for every image
+type mockSBOMGenerator struct{}
+
+func (m *mockSBOMGenerator) GenerateSBOM(ctx context.Context, imageURI string) (*deployment.SBOM, error) {
+	return &deployment.SBOM{Format: "cyclonedx-json", Packages: 2, Document: []byte(`{"components":[{},{}]}`)}, nil
+}
+
+// mockDeployer records the images deployed after the scan
+type mockDeployer struct {
+	deployed []string
+}
+
+func (m *mockDeployer) OnBuildSuccess(ctx context.Context, dep *deployment.Deployment, proj *project.Project, imageURI string) error {
+	m.deployed = append(m.deployed, imageURI)
+	return nil
+}
+
+func TestImageScanService_ScanBeforeDeploying(t *testing.T) {
+	ctx := context.Background()
+	proj := newTestProject(t, user.NewUserID(), "my-app")
+
+	critical := deployment.Vulnerability{ID: "CVE-2024-0001", Severity: deployment.SeverityCritical, Package: "openssl", Version: "3.0.1"}
+	low := deployment.Vulnerability{ID: "CVE-2024-0002", Severity: deployment.SeverityLow, Package: "zlib", Version: "1.2.11"}
+
+	tests := []struct {
+		name         string
+		scanner      *mockVulnerabilityScanner
+		maxCritical  int
+		wantBlocked  bool
+		wantStatus   deployment.ScanStatus
+		wantSeverity map[deployment.Severity]int
+	}{
+		{
+			name:         "under the threshold",
+			scanner:      &mockVulnerabilityScanner{vulnerabilities: []deployment.Vulnerability{low, critical}},
+			maxCritical:  1,
+			wantStatus:   deployment.ScanCompleted,
+			wantSeverity: map[deployment.Severity]int{deployment.SeverityCritical: 1, deployment.SeverityLow: 1},
+		},
+		{
+			name:         "over the threshold",
+			scanner:      &mockVulnerabilityScanner{vulnerabilities: []deployment.Vulnerability{low, critical}},
+			maxCritical:  0,
+			wantBlocked:  true,
+			wantStatus:   deployment.ScanCompleted,
+			wantSeverity: map[deployment.Severity]int{deployment.SeverityCritical: 1, deployment.SeverityLow: 1},
+		},
+		{
+			name:         "blocking disabled",
+			scanner:      &mockVulnerabilityScanner{vulnerabilities: []deployment.Vulnerability{critical, critical}},
+			maxCritical:  -1,
+			wantStatus:   deployment.ScanCompleted,
+			wantSeverity: map[deployment.Severity]int{deployment.SeverityCritical: 2},
+		},
+		{
+			name:         "failed scan",
+			scanner:      &mockVulnerabilityScanner{err: errors.New("image scan is FAILED")},
+			maxCritical:  0,
+			wantStatus:   deployment.ScanFailed,
+			wantSeverity: map[deployment.Severity]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := newTestDeployment(t, proj, project.EnvironmentProduction, deployment.StatusPending)
+			scans := &mockScans{reports: map[string]*deployment.ScanReport{}}
+			svc := service.NewImageScanService(scans, newMockDeploymentRepository(dep), service.ScanPolicy{MaxCritical: tt.maxCritical})
+			svc.SetVulnerabilityScanner(tt.scanner)
+			svc.SetSBOMGenerator(&mockSBOMGenerator{})
+			deployer := &mockDeployer{}
+
+			err := svc.ScanBeforeDeploying(deployer).OnBuildSuccess(ctx, dep, proj, "registry.example.com/app:abc123d")
+			if tt.wantBlocked {
+				if !errors.Is(err, deployment.ErrImageVulnerable) {
+					t.Errorf("OnBuildSuccess() error = %v, want ErrImageVulnerable", err)
+				}
+				if len(deployer.deployed) != 0 {
+					t.Error("blocked image was deployed")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("OnBuildSuccess() error = %v", err)
+				}
+				if len(deployer.deployed) != 1 {
+					t.Errorf("image deployed %d times, want once", len(deployer.deployed))
+				}
+			}
+
+			report := scans.reports[dep.ID().String()]
+			if report == nil {
+				t.Fatal("scan report not saved")
+			}
+			if report.Blocked != tt.wantBlocked || report.Status != tt.wantStatus {
+				t.Errorf("report blocked = %v, status = %s, want %v, %s", report.Blocked, report.Status, tt.wantBlocked, tt.wantStatus)
+			}
+			if len(report.SeverityCounts) != len(tt.wantSeverity) {
+				t.Errorf("severity counts = %v, want %v", report.SeverityCounts, tt.wantSeverity)
+			}
+			for severity, count := range tt.wantSeverity {
+				if report.SeverityCounts[severity] != count {
+					t.Errorf("severity counts = %v, want %v", report.SeverityCounts, tt.wantSeverity)
+				}
+			}
+			if len(report.Vulnerabilities) > 0 && report.Vulnerabilities[0].Severity != deployment.SeverityCritical {
+				t.Errorf("vulnerabilities = %v, want the most severe first", report.Vulnerabilities)
+			}
+			if report.SBOM == nil || report.SBOM.Packages != 2 {
+				t.Errorf("SBOM = %+v, want the generated SBOM", report.SBOM)
+			}
+			if !strings.Contains(dep.Logs().String(), "Scanning image") {
+				t.Error("deployment logs don't mention the scan")
+			}
+		})
+	}
+}
+
+func TestImageScanService_GetDeploymentScan(t *testing.T) {
+	ctx := context.Background()
+	proj := newTestProject(t, user.NewUserID(), "my-app")
+	dep := newTestDeployment(t, proj, project.EnvironmentProduction, deployment.StatusPending)
+	unscanned := newTestDeployment(t, proj, project.EnvironmentProduction, deployment.StatusPending)
+
+	report := deployment.NewScanReport(dep, "registry.example.com/app:abc123d")
+	report.RecordVulnerabilities([]deployment.Vulnerability{
+		{ID: "CVE-2024-0002", Severity: deployment.SeverityHigh, Package: "zlib"},
+	}, map[deployment.Severity]int{deployment.SeverityHigh: 1})
+
+	scans := &mockScans{reports: map[string]*deployment.ScanReport{dep.ID().String(): report}}
+	svc := service.NewImageScanService(scans, newMockDeploymentRepository(dep, unscanned), service.ScanPolicy{MaxCritical: -1})
+
+	t.Run("scanned", func(t *testing.T) {
+		got, err := svc.GetDeploymentScan(ctx, dep.ID().String())
+		if err != nil {
+			t.Fatalf("GetDeploymentScan() error = %v", err)
+		}
+		if got.Summary != "1 high" || got.SeverityCounts["HIGH"] != 1 || len(got.Vulnerabilities) != 1 || got.SBOM != nil {
+			t.Errorf("GetDeploymentScan() = %+v", got)
+		}
+	})
+
+	t.Run("not scanned", func(t *testing.T) {
+		_, err := svc.GetDeploymentScan(ctx, unscanned.ID().String())
+		if !errors.Is(err, deployment.ErrScanNotFound) {
+			t.Errorf("GetDeploymentScan() error = %v, want ErrScanNotFound", err)
+		}
+	})
+
+	t.Run("no SBOM", func(t *testing.T) {
+		_, err := svc.GetDeploymentSBOM(ctx, dep.ID().String())
+		if !errors.Is(err, deployment.ErrScanNotFound) {
+			t.Errorf("GetDeploymentSBOM() error = %v, want ErrScanNotFound", err)
+		}
+	})
+}
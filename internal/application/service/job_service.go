@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/job"
+	"snapdeploy-core/internal/logging"
+)
+
+// JobFunc performs the work of a background job and returns its result
+type JobFunc func(ctx context.Context) (interface{}, error)
+
+// JobLimits bounds how much background work a single user can have in flight
+type JobLimits struct {
+	MaxConcurrentPerUser int           // jobs running at once per user
+	MaxQueuedPerUser     int           // jobs queued or running per user
+	Timeout              time.Duration // maximum run time of a single job
+	Retention            time.Duration // how long finished jobs can be polled
+}
+
+// Default job limits, used for unset values
+const (
+	defaultMaxConcurrentJobsPerUser = 2
+	defaultMaxQueuedJobsPerUser     = 5
+	defaultJobTimeout               = 5 * time.Minute
+	defaultJobRetention             = time.Hour
+)
+
+// JobService runs slow operations outside of the HTTP request that started them.
+// Each user gets their own worker slots so one user's jobs can't starve another's.
+// Jobs are kept in memory: they are lost on restart and only visible on the instance that ran them.
+type JobService struct {
+	limits JobLimits
+
+	mu    sync.Mutex
+	jobs  map[string]*job.Job
+	slots map[string]chan struct{}
+}
+
+// NewJobService creates a new job service
+func NewJobService(limits JobLimits) *JobService {
+	if limits.MaxConcurrentPerUser <= 0 {
+		limits.MaxConcurrentPerUser = defaultMaxConcurrentJobsPerUser
+	}
+	if limits.MaxQueuedPerUser <= 0 {
+		limits.MaxQueuedPerUser = defaultMaxQueuedJobsPerUser
+	}
+	if limits.Timeout <= 0 {
+		limits.Timeout = defaultJobTimeout
+	}
+	if limits.Retention <= 0 {
+		limits.Retention = defaultJobRetention
+	}
+
+	return &JobService{
+		limits: limits,
+		jobs:   make(map[string]*job.Job),
+		slots:  make(map[string]chan struct{}),
+	}
+}
+
+// Submit queues a job for the owner and returns immediately.
+// If the owner already has a pending job of the same shared kind, that job is returned instead.
+// The job's log lines carry the log attributes of ctx, such as the correlation ID of the request.
+func (s *JobService) Submit(ctx context.Context, ownerID string, kind job.Kind, run JobFunc) (*dto.JobResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked(time.Now())
+
+	pending := 0
+	for _, j := range s.jobs {
+		if !j.BelongsTo(ownerID) || j.Status().IsTerminal() {
+			continue
+		}
+		// The same work is already pending - share it rather than running it twice
+		if j.Kind() == kind && kind.IsShared() {
+			return s.toDTO(j), nil
+		}
+		pending++
+	}
+
+	if pending >= s.limits.MaxQueuedPerUser {
+		return nil, job.ErrQueueFull
+	}
+
+	j := job.NewJob(ownerID, kind)
+	s.jobs[j.ID().String()] = j
+
+	slots, ok := s.slots[ownerID]
+	if !ok {
+		slots = make(chan struct{}, s.limits.MaxConcurrentPerUser)
+		s.slots[ownerID] = slots
+	}
+
+	go s.run(logging.With(logging.Detach(ctx), "job_id", j.ID().String(), "job_kind", string(j.Kind())), j, slots, run)
+
+	return s.toDTO(j), nil
+}
+
+// GetJob retrieves a job submitted by the owner
+func (s *JobService) GetJob(jobID, ownerID string) (*dto.JobResponse, error) {
+	id, err := job.ParseJobID(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job ID: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id.String()]
+	// Don't reveal other users' jobs
+	if !ok || !j.BelongsTo(ownerID) {
+		return nil, job.ErrJobNotFound
+	}
+
+	return s.toDTO(j), nil
+}
+
+// run waits for one of the owner's worker slots, then executes the job
+func (s *JobService) run(parent context.Context, j *job.Job, slots chan struct{}, run JobFunc) {
+	slots <- struct{}{}
+	defer func() { <-slots }()
+
+	s.mu.Lock()
+	j.Start()
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(parent, s.limits.Timeout)
+	defer cancel()
+
+	result, err := s.execute(ctx, run)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Job failed", "error", err)
+		j.Fail(err)
+		return
+	}
+	j.Succeed(result)
+}
+
+// execute runs the job function, converting panics into errors so a bad job can't take down the server
+func (s *JobService) execute(ctx context.Context, run JobFunc) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return run(ctx)
+}
+
+// pruneLocked removes finished jobs past their retention and idle worker slots
+func (s *JobService) pruneLocked(now time.Time) {
+	active := make(map[string]bool)
+	for id, j := range s.jobs {
+		if j.Status().IsTerminal() && now.Sub(*j.FinishedAt()) > s.limits.Retention {
+			delete(s.jobs, id)
+			continue
+		}
+		if !j.Status().IsTerminal() {
+			active[j.OwnerID()] = true
+		}
+	}
+
+	for ownerID := range s.slots {
+		if !active[ownerID] {
+			delete(s.slots, ownerID)
+		}
+	}
+}
+
+// toDTO converts a domain job to DTO
+func (s *JobService) toDTO(j *job.Job) *dto.JobResponse {
+	response := &dto.JobResponse{
+		ID:        j.ID().String(),
+		Kind:      j.Kind().String(),
+		Status:    j.Status().String(),
+		Result:    j.Result(),
+		Error:     j.ErrorMessage(),
+		CreatedAt: j.CreatedAt().Format(time.RFC3339),
+	}
+	if j.StartedAt() != nil {
+		response.StartedAt = j.StartedAt().Format(time.RFC3339)
+	}
+	if j.FinishedAt() != nil {
+		response.FinishedAt = j.FinishedAt().Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,134 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/job"
+)
+
+// waitForJob polls a job until it reaches the wanted status
+func waitForJob(t *testing.T, svc *service.JobService, jobID, ownerID string, want job.Status) *dto.JobResponse {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got, err := svc.GetJob(jobID, ownerID)
+		if err != nil {
+			t.Fatalf("GetJob() error = %v", err)
+		}
+		if got.Status == want.String() {
+			return got
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s status = %s, want %s", jobID, got.Status, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestJobService_PerUserLimits(t *testing.T) {
+	svc := service.NewJobService(service.JobLimits{
+		MaxConcurrentPerUser: 2,
+		MaxQueuedPerUser:     3,
+	})
+
+	release := make(chan struct{})
+	blocking := func(ctx context.Context) (interface{}, error) {
+		<-release
+		return "done", nil
+	}
+
+	var submitted []*dto.JobResponse
+	for _, kind := range []job.Kind{"TEST_A", "TEST_B", "TEST_C"} {
+		resp, err := svc.Submit(context.Background(), "user_a", kind, blocking)
+		if err != nil {
+			t.Fatalf("Submit(%s) error = %v", kind, err)
+		}
+		submitted = append(submitted, resp)
+	}
+
+	// Only two jobs run at once; the third waits for a slot
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		counts := map[string]int{}
+		for _, resp := range submitted {
+			got, err := svc.GetJob(resp.ID, "user_a")
+			if err != nil {
+				t.Fatalf("GetJob() error = %v", err)
+			}
+			counts[got.Status]++
+		}
+		if counts[job.StatusRunning.String()] == 2 && counts[job.StatusQueued.String()] == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job statuses = %v, want 2 running and 1 queued", counts)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if _, err := svc.Submit(context.Background(), "user_a", "TEST_D", blocking); !errors.Is(err, job.ErrQueueFull) {
+		t.Errorf("Submit() over limit error = %v, want %v", err, job.ErrQueueFull)
+	}
+
+	// Resubmitting pending work returns the existing job
+	again, err := svc.Submit(context.Background(), "user_a", "TEST_A", blocking)
+	if err != nil {
+		t.Fatalf("Submit() duplicate error = %v", err)
+	}
+	if again.ID != submitted[0].ID {
+		t.Errorf("Submit() duplicate ID = %s, want %s", again.ID, submitted[0].ID)
+	}
+
+	// Other users have their own slots
+	other, err := svc.Submit(context.Background(), "user_b", "TEST_A", func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("github unavailable")
+	})
+	if err != nil {
+		t.Fatalf("Submit() other user error = %v", err)
+	}
+	failed := waitForJob(t, svc, other.ID, "user_b", job.StatusFailed)
+	if failed.Error != "github unavailable" {
+		t.Errorf("Error = %q, want %q", failed.Error, "github unavailable")
+	}
+
+	if _, err := svc.GetJob(other.ID, "user_a"); !errors.Is(err, job.ErrJobNotFound) {
+		t.Errorf("GetJob() by another user error = %v, want %v", err, job.ErrJobNotFound)
+	}
+
+	close(release)
+	for _, resp := range submitted {
+		done := waitForJob(t, svc, resp.ID, "user_a", job.StatusSucceeded)
+		if done.Result != "done" {
+			t.Errorf("Result = %v, want done", done.Result)
+		}
+	}
+}
+
+func TestJobService_UnsharedKindsRunEachSubmission(t *testing.T) {
+	svc := service.NewJobService(service.JobLimits{MaxQueuedPerUser: 3})
+
+	release := make(chan struct{})
+	defer close(release)
+	blocking := func(ctx context.Context) (interface{}, error) {
+		<-release
+		return nil, nil
+	}
+
+	first, err := svc.Submit(context.Background(), "user_a", job.KindProjectValidation, blocking)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	second, err := svc.Submit(context.Background(), "user_a", job.KindProjectValidation, blocking)
+	if err != nil {
+		t.Fatalf("Submit() second error = %v", err)
+	}
+	if first.ID == second.ID {
+		t.Errorf("Submit() returned the pending validation %s, want a job of its own", first.ID)
+	}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+)
+
+// reencryptBatchSize bounds how many values are re-encrypted per batch
+const reencryptBatchSize = 200
+
+// ErrRotationInProgress is returned when values are already being re-encrypted
+var ErrRotationInProgress = errors.New("encryption key rotation is already in progress")
+
+// Statuses of a key rotation
+const (
+	RotationRunning   = "RUNNING"
+	RotationCompleted = "COMPLETED"
+	RotationFailed    = "FAILED"
+)
+
+// SecretReencrypter re-encrypts stored secrets with the active encryption key
+type SecretReencrypter interface {
+	ActiveKeyID() string
+	CountByKey(ctx context.Context) (map[string]int64, error)
+	ReencryptBatch(ctx context.Context, cursor string, limit int) (next string, reencrypted, failed int, err error)
+}
+
+// KeyRotationService re-encrypts the stored environment variable values with the active encryption key in
+// the background, so the keys they were encrypted with before can be retired
+type KeyRotationService struct {
+	secrets SecretReencrypter
+	trigger chan struct{}
+
+	mu   sync.Mutex
+	last *keyRotation // Latest rotation started on this instance
+}
+
+// keyRotation is a run of the re-encryption job
+type keyRotation struct {
+	keyID       string
+	status      string
+	reencrypted int
+	failed      int
+	err         error
+	startedAt   time.Time
+	finishedAt  time.Time
+}
+
+// NewKeyRotationService creates a new key rotation service
+func NewKeyRotationService(secrets SecretReencrypter) *KeyRotationService {
+	return &KeyRotationService{
+		secrets: secrets,
+		trigger: make(chan struct{}, 1),
+	}
+}
+
+// Rotate starts re-encrypting the values that aren't encrypted with the active key
+func (s *KeyRotationService) Rotate(ctx context.Context) (*dto.EncryptionStatusResponse, error) {
+	s.mu.Lock()
+	if s.last != nil && s.last.status == RotationRunning {
+		s.mu.Unlock()
+		return nil, ErrRotationInProgress
+	}
+	s.last = &keyRotation{keyID: s.secrets.ActiveKeyID(), status: RotationRunning, startedAt: time.Now()}
+	s.mu.Unlock()
+
+	s.trigger <- struct{}{}
+	return s.Status(ctx)
+}
+
+// Status reports the keys the stored values are encrypted with and the latest rotation
+func (s *KeyRotationService) Status(ctx context.Context) (*dto.EncryptionStatusResponse, error) {
+	counts, err := s.secrets.CountByKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	activeKeyID := s.secrets.ActiveKeyID()
+	response := &dto.EncryptionStatusResponse{
+		ActiveKeyID: activeKeyID,
+		Keys:        make([]dto.EncryptionKeyUsage, 0, len(counts)),
+	}
+	for keyID, count := range counts {
+		response.Keys = append(response.Keys, dto.EncryptionKeyUsage{KeyID: keyID, Values: count})
+		if keyID != activeKeyID {
+			response.PendingValues += count
+		}
+	}
+	sort.Slice(response.Keys, func(i, j int) bool { return response.Keys[i].KeyID < response.Keys[j].KeyID })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last != nil {
+		response.Rotation = s.last.toDTO()
+	}
+	return response, nil
+}
+
+// Run re-encrypts the stored values each time a rotation is started until the context is cancelled
+func (s *KeyRotationService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.trigger:
+			s.reencrypt(ctx)
+		}
+	}
+}
+
+// reencrypt goes through the values in batches, recording the progress of the rotation
+func (s *KeyRotationService) reencrypt(ctx context.Context) {
+	s.mu.Lock()
+	rotation := s.last
+	s.mu.Unlock()
+	slog.InfoContext(ctx, "Re-encrypting environment variables", "key_id", rotation.keyID)
+
+	var cursor string
+	for {
+		next, reencrypted, failed, err := s.secrets.ReencryptBatch(ctx, cursor, reencryptBatchSize)
+
+		s.mu.Lock()
+		rotation.reencrypted += reencrypted
+		rotation.failed += failed
+		if err != nil || next == "" {
+			rotation.finishedAt = time.Now()
+			rotation.status = RotationCompleted
+			if err != nil {
+				rotation.status, rotation.err = RotationFailed, err
+			}
+		}
+		s.mu.Unlock()
+
+		if err != nil {
+			slog.ErrorContext(ctx, "Re-encrypting environment variables failed", "key_id", rotation.keyID,
+				"reencrypted", rotation.reencrypted, "error", err)
+			return
+		}
+		if next == "" {
+			slog.InfoContext(ctx, "Re-encrypted environment variables", "key_id", rotation.keyID,
+				"reencrypted", rotation.reencrypted, "failed", rotation.failed)
+			return
+		}
+		cursor = next
+	}
+}
+
+// toDTO converts a rotation to its response
+func (r *keyRotation) toDTO() *dto.KeyRotationResponse {
+	response := &dto.KeyRotationResponse{
+		KeyID:       r.keyID,
+		Status:      r.status,
+		Reencrypted: r.reencrypted,
+		Failed:      r.failed,
+		StartedAt:   r.startedAt.Format(time.RFC3339),
+	}
+	if r.err != nil {
+		response.Error = r.err.Error()
+	}
+	if !r.finishedAt.IsZero() {
+		response.FinishedAt = r.finishedAt.Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,157 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+)
+
+// mockSecrets holds the ID of the key encrypting each value; cursors are indexes into them
+type mockSecrets struct {
+	mu          sync.Mutex
+	activeKey   string
+	values      []string // Key ID of each value
+	undecodable map[int]bool
+	err         error
+	batches     int
+}
+
+func (m *mockSecrets) ActiveKeyID() string {
+	return m.activeKey
+}
+
+func (m *mockSecrets) CountByKey(ctx context.Context) (map[string]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := make(map[string]int64)
+	for _, keyID := range m.values {
+		counts[keyID]++
+	}
+	return counts, nil
+}
+
+func (m *mockSecrets) ReencryptBatch(ctx context.Context, cursor string, limit int) (string, int, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches++
+	if m.err != nil {
+		return "", 0, 0, m.err
+	}
+
+	start := 0
+	if cursor != "" {
+		start, _ = strconv.Atoi(cursor)
+	}
+	var reencrypted, failed int
+	i := start
+	for ; i < len(m.values) && i < start+limit; i++ {
+		switch {
+		case m.values[i] == m.activeKey:
+		case m.undecodable[i]:
+			failed++
+		default:
+			m.values[i] = m.activeKey
+			reencrypted++
+		}
+	}
+	if i >= len(m.values) {
+		return "", reencrypted, failed, nil
+	}
+	return strconv.Itoa(i), reencrypted, failed, nil
+}
+
+// waitForRotation polls the status until the rotation is no longer running
+func waitForRotation(t *testing.T, svc *service.KeyRotationService) *dto.EncryptionStatusResponse {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		status, err := svc.Status(context.Background())
+		if err != nil {
+			t.Fatalf("Status() error = %v", err)
+		}
+		if status.Rotation != nil && status.Rotation.Status != service.RotationRunning {
+			return status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("rotation did not finish")
+	return nil
+}
+
+func TestKeyRotationService_ReencryptsInBatches(t *testing.T) {
+	values := make([]string, 450)
+	for i := range values {
+		values[i] = "default"
+	}
+	values[10] = "k2"
+	secrets := &mockSecrets{activeKey: "k2", values: values, undecodable: map[int]bool{20: true}}
+	svc := service.NewKeyRotationService(secrets)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go svc.Run(ctx)
+
+	started, err := svc.Rotate(ctx)
+	if err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if started.ActiveKeyID != "k2" || started.Rotation == nil || started.Rotation.KeyID != "k2" {
+		t.Errorf("Rotate() = %+v, want a rotation to k2", started)
+	}
+
+	status := waitForRotation(t, svc)
+	if status.Rotation.Status != service.RotationCompleted {
+		t.Errorf("rotation status = %s, want %s", status.Rotation.Status, service.RotationCompleted)
+	}
+	if status.Rotation.Reencrypted != 448 || status.Rotation.Failed != 1 {
+		t.Errorf("reencrypted, failed = %d, %d, want 448, 1", status.Rotation.Reencrypted, status.Rotation.Failed)
+	}
+	if secrets.batches != 3 {
+		t.Errorf("batches = %d, want 3", secrets.batches)
+	}
+	if status.PendingValues != 1 {
+		t.Errorf("PendingValues = %d, want the value that couldn't be decrypted", status.PendingValues)
+	}
+	if status.Rotation.FinishedAt == "" {
+		t.Error("FinishedAt is empty for a finished rotation")
+	}
+}
+
+func TestKeyRotationService_RecordsFailure(t *testing.T) {
+	secrets := &mockSecrets{activeKey: "k2", values: []string{"default"}, err: errors.New("connection refused")}
+	svc := service.NewKeyRotationService(secrets)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go svc.Run(ctx)
+
+	if _, err := svc.Rotate(ctx); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+
+	status := waitForRotation(t, svc)
+	if status.Rotation.Status != service.RotationFailed || status.Rotation.Error == "" {
+		t.Errorf("rotation = %+v, want a failure with its error", status.Rotation)
+	}
+	if status.PendingValues != 1 {
+		t.Errorf("PendingValues = %d, want 1", status.PendingValues)
+	}
+}
+
+func TestKeyRotationService_RejectsConcurrentRotation(t *testing.T) {
+	svc := service.NewKeyRotationService(&mockSecrets{activeKey: "k2", values: []string{"default"}})
+
+	// Nothing runs the job, so the first rotation stays running
+	if _, err := svc.Rotate(context.Background()); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if _, err := svc.Rotate(context.Background()); !errors.Is(err, service.ErrRotationInProgress) {
+		t.Errorf("second Rotate() error = %v, want %v", err, service.ErrRotationInProgress)
+	}
+}
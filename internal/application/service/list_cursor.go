@@ -0,0 +1,35 @@
+package service
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"snapdeploy-core/internal/domain/validation"
+)
+
+// ErrInvalidCursor is returned when a list is continued from a cursor that wasn't issued by the API
+var ErrInvalidCursor = validation.New("invalid cursor")
+
+// encodeCursor returns the opaque cursor continuing a list, sorted newest first, after the item with the given
+// creation time and ID. Unlike an offset it keeps pointing at the same item as items are added or removed.
+func encodeCursor(createdAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.UTC().Format(time.RFC3339Nano) + "," + id))
+}
+
+// decodeCursor returns the creation time and ID of the item a cursor continues a list after
+func decodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	createdAtStr, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	return createdAt, id, nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"time"
+)
+
+// listVersion derives the ETag of a page of a list from the IDs and update times of its items and the
+// list's total, so it changes whenever an item on the page is updated or items are added or removed.
+// The response doesn't have to be serialized to know whether the client already has it.
+type listVersion struct {
+	hash hash.Hash
+}
+
+// newListVersion starts the version of a page of a list with the given total number of items
+func newListVersion(total int64) *listVersion {
+	v := &listVersion{hash: sha256.New()}
+	binary.Write(v.hash, binary.BigEndian, total)
+	return v
+}
+
+// add adds an item of the page, in the order it is listed
+func (v *listVersion) add(id string, updatedAt time.Time) {
+	v.hash.Write([]byte(id))
+	binary.Write(v.hash, binary.BigEndian, updatedAt.UnixNano())
+}
+
+// etag returns the page's ETag. It is weak, as it stands for the items rather than the exact bytes of the response.
+func (v *listVersion) etag() string {
+	return `W/"` + hex.EncodeToString(v.hash.Sum(nil)[:16]) + `"`
+}
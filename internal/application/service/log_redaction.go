@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+)
+
+// LogSecretSource resolves the values masked in the logs of a project's deployments
+type LogSecretSource interface {
+	SecretValues(ctx context.Context, projectID project.ProjectID, env project.Environment) ([]string, error)
+}
+
+// maskLogSecrets masks the values of the project's environment variables in the lines appended to the
+// deployment's logs. Lines are still masked by shape when the values can't be read.
+func maskLogSecrets(ctx context.Context, source LogSecretSource, dep *deployment.Deployment) {
+	if source == nil {
+		return
+	}
+
+	values, err := source.SecretValues(ctx, dep.ProjectID(), dep.Environment())
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to load the values masked in deployment logs", "error", err)
+		return
+	}
+	dep.MaskInLogs(values...)
+}
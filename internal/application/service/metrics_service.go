@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/validation"
+	"snapdeploy-core/internal/infrastructure/cloudwatch"
+)
+
+// DefaultMetricsPeriod is used when no period is requested
+const DefaultMetricsPeriod = "1h"
+
+var (
+	// ErrInvalidMetricsPeriod is returned for periods other than those in metricsPeriods
+	ErrInvalidMetricsPeriod = validation.New("invalid metrics period")
+
+	// ErrMetricsUnavailable is returned when no metrics source is configured
+	ErrMetricsUnavailable = errors.New("metrics are unavailable")
+)
+
+// metricsPeriods maps the supported periods to their window and resolution.
+// Steps keep each chart at 60-170 points and are multiples of 60s as CloudWatch requires.
+var metricsPeriods = map[string]struct {
+	window time.Duration
+	step   time.Duration
+}{
+	"1h":  {window: time.Hour, step: time.Minute},
+	"6h":  {window: 6 * time.Hour, step: 5 * time.Minute},
+	"24h": {window: 24 * time.Hour, step: 15 * time.Minute},
+	"7d":  {window: 7 * 24 * time.Hour, step: time.Hour},
+}
+
+// ServiceMetricsSource reads the runtime metrics of a project's deployed service
+type ServiceMetricsSource interface {
+	GetServiceMetrics(ctx context.Context, proj *project.Project, start, end time.Time, step time.Duration) ([]cloudwatch.Series, error)
+}
+
+// MetricsService handles project runtime metrics use cases
+type MetricsService struct {
+	projectRepo project.ProjectRepository
+	source      ServiceMetricsSource
+}
+
+// NewMetricsService creates a new metrics service
+func NewMetricsService(projectRepo project.ProjectRepository) *MetricsService {
+	return &MetricsService{
+		projectRepo: projectRepo,
+	}
+}
+
+// SetMetricsSource sets where service metrics are read from (optional)
+func (s *MetricsService) SetMetricsSource(source ServiceMetricsSource) {
+	s.source = source
+}
+
+// GetProjectMetrics returns a project's runtime metrics over the period ending now
+func (s *MetricsService) GetProjectMetrics(ctx context.Context, projectID, period string) (*dto.ProjectMetricsResponse, error) {
+	if period == "" {
+		period = DefaultMetricsPeriod
+	}
+	window, ok := metricsPeriods[period]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (supported: 1h, 6h, 24h, 7d)", ErrInvalidMetricsPeriod, period)
+	}
+
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	proj, err := s.projectRepo.FindByID(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.source == nil {
+		return nil, ErrMetricsUnavailable
+	}
+
+	// Align the window to the step so every series shares the same buckets
+	end := time.Now().UTC().Truncate(window.step)
+	start := end.Add(-window.window)
+
+	series, err := s.source.GetServiceMetrics(ctx, proj, start, end, window.step)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service metrics: %w", err)
+	}
+
+	return toMetricsDTO(pid.String(), period, start, end, window.step, series), nil
+}
+
+// toMetricsDTO lays the series out on a common time axis for charting.
+// Intervals without data are reported as zero for counts and null for utilization.
+func toMetricsDTO(projectID, period string, start, end time.Time, step time.Duration, series []cloudwatch.Series) *dto.ProjectMetricsResponse {
+	var buckets []time.Time
+	for t := start; t.Before(end); t = t.Add(step) {
+		buckets = append(buckets, t)
+	}
+
+	timestamps := make([]string, len(buckets))
+	for i, t := range buckets {
+		timestamps[i] = t.Format(time.RFC3339)
+	}
+
+	response := &dto.ProjectMetricsResponse{
+		ProjectID:   projectID,
+		Period:      period,
+		Start:       start.Format(time.RFC3339),
+		End:         end.Format(time.RFC3339),
+		StepSeconds: int(step.Seconds()),
+		Timestamps:  timestamps,
+		Series:      make([]*dto.MetricSeries, 0, len(series)),
+	}
+
+	for _, s := range series {
+		byTime := make(map[int64]float64, len(s.Points))
+		for _, p := range s.Points {
+			byTime[p.Timestamp.Truncate(step).Unix()] = p.Value
+		}
+
+		values := make([]*float64, len(buckets))
+		for i, t := range buckets {
+			if v, ok := byTime[t.Unix()]; ok {
+				values[i] = &v
+			} else if s.Unit == cloudwatch.UnitCount {
+				zero := 0.0
+				values[i] = &zero
+			}
+		}
+
+		response.Series = append(response.Series, &dto.MetricSeries{
+			Name:   s.Name,
+			Unit:   s.Unit,
+			Values: values,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/monitor"
+	"snapdeploy-core/internal/domain/project"
+)
+
+// maxListedIncidents is how many of a project's most recent incidents are listed
+const maxListedIncidents = 100
+
+// maxConcurrentProbes bounds the health checks made at once, so a round of slow endpoints doesn't open
+// hundreds of connections
+const maxConcurrentProbes = 10
+
+// healthCheckPurgeInterval is how often health checks older than the history are removed
+const healthCheckPurgeInterval = time.Hour
+
+// HealthProber checks a project's health endpoint
+type HealthProber interface {
+	// Probe requests the URL and returns the HTTP status it answered with
+	Probe(ctx context.Context, url string) (int, error)
+}
+
+// IncidentAlerter notifies a channel, such as a chat webhook, that an incident was opened or resolved
+type IncidentAlerter interface {
+	Alert(ctx context.Context, key, title, message string)
+}
+
+// MonitoringSettings holds how projects are checked and when their failures become incidents
+type MonitoringSettings struct {
+	FailureThreshold int           // Consecutive failed checks that open an incident
+	HistoryDays      int           // Days health checks are kept for uptime
+	ProbeTimeout     time.Duration // How long a check waits for the endpoint to answer
+	BaseDomain       string        // Domain projects are served on subdomains of
+}
+
+// MonitoringService checks the health endpoints of deployed projects from the control plane, records their
+// uptime and opens incidents while their checks keep failing
+type MonitoringService struct {
+	projectRepo    project.ProjectRepository
+	deploymentRepo deployment.DeploymentRepository
+	checkRepo      monitor.CheckRepository
+	incidentRepo   monitor.IncidentRepository
+	prober         HealthProber
+	settings       MonitoringSettings
+	alerters       []IncidentAlerter
+	lastPurge      time.Time
+}
+
+// NewMonitoringService creates a new monitoring service
+func NewMonitoringService(
+	projectRepo project.ProjectRepository,
+	deploymentRepo deployment.DeploymentRepository,
+	checkRepo monitor.CheckRepository,
+	incidentRepo monitor.IncidentRepository,
+	prober HealthProber,
+	settings MonitoringSettings,
+) *MonitoringService {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = 1
+	}
+	return &MonitoringService{
+		projectRepo:    projectRepo,
+		deploymentRepo: deploymentRepo,
+		checkRepo:      checkRepo,
+		incidentRepo:   incidentRepo,
+		prober:         prober,
+		settings:       settings,
+	}
+}
+
+// AddAlerter adds a channel incidents are announced on. Incidents are always logged.
+func (s *MonitoringService) AddAlerter(alerter IncidentAlerter) {
+	s.alerters = append(s.alerters, alerter)
+}
+
+// CheckAll checks the production environment of every deployed project served on a URL once, and opens or
+// resolves their incidents. Returns the number of projects checked.
+func (s *MonitoringService) CheckAll(ctx context.Context) (int, error) {
+	deployed, err := s.deploymentRepo.FindLatestDeployed(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list deployed projects: %w", err)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		checked int
+		errs    []error
+		slots   = make(chan struct{}, maxConcurrentProbes)
+	)
+	for _, dep := range deployed {
+		if dep.Environment() != project.EnvironmentProduction {
+			continue
+		}
+
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(projectID project.ProjectID) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			ok, err := s.checkProject(ctx, projectID)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("project %s: %w", projectID.String(), err))
+			}
+			if ok {
+				checked++
+			}
+		}(dep.ProjectID())
+	}
+	wg.Wait()
+
+	return checked, errors.Join(errs...)
+}
+
+// checkProject checks a project's health endpoint and records the check. Returns false for projects that
+// aren't served on a URL of the platform.
+func (s *MonitoringService) checkProject(ctx context.Context, projectID project.ProjectID) (bool, error) {
+	proj, err := s.projectRepo.FindByID(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, project.ErrProjectNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	baseURL := projectDeploymentURL(s.settings.BaseDomain, proj, project.EnvironmentProduction)
+	if baseURL == "" || proj.IsDeleting() {
+		return false, nil
+	}
+
+	check := s.probe(ctx, proj.ID(), baseURL+proj.HealthCheckPath())
+	if err := s.checkRepo.Save(ctx, check); err != nil {
+		return true, err
+	}
+	return true, s.trackIncident(ctx, proj, check)
+}
+
+// probe makes one health check, healthy if the endpoint answers with a status below 400
+func (s *MonitoringService) probe(ctx context.Context, projectID project.ProjectID, url string) monitor.Check {
+	probeCtx, cancel := context.WithTimeout(ctx, s.settings.ProbeTimeout)
+	defer cancel()
+
+	started := time.Now()
+	status, err := s.prober.Probe(probeCtx, url)
+	check := monitor.Check{
+		ProjectID:  projectID,
+		URL:        url,
+		StatusCode: status,
+		LatencyMS:  int(time.Since(started).Milliseconds()),
+		CheckedAt:  started,
+	}
+	switch {
+	case err != nil:
+		check.Error = err.Error()
+	case status >= 400:
+		check.Error = fmt.Sprintf("health endpoint answered with HTTP %d", status)
+	default:
+		check.Healthy = true
+	}
+	return check
+}
+
+// trackIncident resolves the project's open incident once a check succeeds, and opens one once the last
+// FailureThreshold checks all failed
+func (s *MonitoringService) trackIncident(ctx context.Context, proj *project.Project, check monitor.Check) error {
+	open, err := s.incidentRepo.FindOpenByProjectID(ctx, proj.ID())
+	if err != nil && !errors.Is(err, monitor.ErrIncidentNotFound) {
+		return err
+	}
+
+	if open != nil {
+		if check.Healthy {
+			open.Resolve(check.CheckedAt)
+		} else {
+			open.RecordFailure(check)
+		}
+		if err := s.incidentRepo.Save(ctx, open); err != nil {
+			return err
+		}
+		if !open.IsOpen() {
+			s.alert(ctx, "incident-resolved:"+open.ID().String(),
+				fmt.Sprintf("Resolved: %s is healthy again", proj.CustomDomain().String()),
+				fmt.Sprintf("%s answered its health check after %s down (%d failed checks).",
+					check.URL, open.Duration(check.CheckedAt).Round(time.Second), open.FailedChecks()))
+		}
+		return nil
+	}
+	if check.Healthy {
+		return nil
+	}
+
+	recent, err := s.checkRepo.FindRecent(ctx, proj.ID(), s.settings.FailureThreshold)
+	if err != nil {
+		return err
+	}
+	if len(recent) < s.settings.FailureThreshold {
+		return nil
+	}
+	for _, c := range recent {
+		if c.Healthy {
+			return nil
+		}
+	}
+
+	// Recent checks are newest first, incidents record them oldest first
+	failed := make([]monitor.Check, len(recent))
+	for i, c := range recent {
+		failed[len(recent)-1-i] = c
+	}
+	incident := monitor.NewIncident(proj.ID(), failed)
+	if err := s.incidentRepo.Save(ctx, incident); err != nil {
+		return err
+	}
+	s.alert(ctx, "incident-opened:"+incident.ID().String(),
+		fmt.Sprintf("Incident: %s is down", proj.CustomDomain().String()),
+		fmt.Sprintf("%s failed its last %d health checks: %s", check.URL, incident.FailedChecks(), incident.Cause()))
+	return nil
+}
+
+// alert announces an incident on every channel
+func (s *MonitoringService) alert(ctx context.Context, key, title, message string) {
+	slog.WarnContext(ctx, title, "key", key, "message", message)
+	for _, alerter := range s.alerters {
+		alerter.Alert(ctx, key, title, message)
+	}
+}
+
+// RunMonitor periodically checks deployed projects until the context is cancelled, and removes the health
+// checks older than the history
+func (s *MonitoringService) RunMonitor(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		slog.Info("Uptime monitoring disabled", "interval", interval)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			checked, err := s.CheckAll(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "Checking deployed projects failed", "checked", checked, "error", err)
+			} else if checked > 0 {
+				slog.DebugContext(ctx, "Checked deployed projects", "checked", checked)
+			}
+			s.purgeHistory(ctx)
+		}
+	}
+}
+
+// purgeHistory removes the health checks older than the history, at most once per purge interval
+func (s *MonitoringService) purgeHistory(ctx context.Context) {
+	if s.settings.HistoryDays <= 0 || time.Since(s.lastPurge) < healthCheckPurgeInterval {
+		return
+	}
+	s.lastPurge = time.Now()
+
+	deleted, err := s.checkRepo.DeleteBefore(ctx, time.Now().AddDate(0, 0, -s.settings.HistoryDays))
+	if err != nil {
+		slog.ErrorContext(ctx, "Removing old health checks failed", "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.InfoContext(ctx, "Removed old health checks", "deleted", deleted)
+	}
+}
+
+// Uptime returns how many health checks of the project were made since the time, and how many succeeded
+func (s *MonitoringService) Uptime(ctx context.Context, projectID project.ProjectID, since time.Time) (int64, int64, error) {
+	return s.checkRepo.CountSince(ctx, projectID, since)
+}
+
+// ListIncidents returns the most recent incidents of a project, newest first
+func (s *MonitoringService) ListIncidents(ctx context.Context, projectID string) (*dto.ProjectIncidentListResponse, error) {
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+	if _, err := s.projectRepo.FindByID(ctx, pid); err != nil {
+		return nil, err
+	}
+
+	incidents, err := s.incidentRepo.FindByProjectID(ctx, pid, maxListedIncidents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list incidents: %w", err)
+	}
+
+	now := time.Now()
+	response := &dto.ProjectIncidentListResponse{Incidents: make([]*dto.ProjectIncidentResponse, len(incidents))}
+	for i, incident := range incidents {
+		item := &dto.ProjectIncidentResponse{
+			ID:              incident.ID().String(),
+			Status:          "RESOLVED",
+			Cause:           incident.Cause(),
+			FailedChecks:    incident.FailedChecks(),
+			StartedAt:       incident.StartedAt().Format(time.RFC3339),
+			DurationSeconds: int64(incident.Duration(now).Seconds()),
+		}
+		if incident.IsOpen() {
+			item.Status = "OPEN"
+		} else {
+			item.ResolvedAt = incident.ResolvedAt().Format(time.RFC3339)
+		}
+		response.Incidents[i] = item
+	}
+	return response, nil
+}
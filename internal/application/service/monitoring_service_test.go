@@ -0,0 +1,164 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/monitor"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// mockChecks keeps health checks in memory, oldest first
+type mockChecks struct {
+	monitor.CheckRepository
+	checks []monitor.Check
+}
+
+func (m *mockChecks) Save(ctx context.Context, check monitor.Check) error {
+	m.checks = append(m.checks, check)
+	return nil
+}
+
+func (m *mockChecks) FindRecent(ctx context.Context, projectID project.ProjectID, limit int) ([]monitor.Check, error) {
+	var recent []monitor.Check
+	for i := len(m.checks) - 1; i >= 0 && len(recent) < limit; i-- {
+		if m.checks[i].ProjectID == projectID {
+			recent = append(recent, m.checks[i])
+		}
+	}
+	return recent, nil
+}
+
+// mockIncidents keeps incidents in memory
+type mockIncidents struct {
+	monitor.IncidentRepository
+	incidents []*monitor.Incident
+}
+
+func (m *mockIncidents) Save(ctx context.Context, incident *monitor.Incident) error {
+	for _, saved := range m.incidents {
+		if saved.ID().Equals(incident.ID()) {
+			return nil
+		}
+	}
+	m.incidents = append(m.incidents, incident)
+	return nil
+}
+
+func (m *mockIncidents) FindOpenByProjectID(ctx context.Context, projectID project.ProjectID) (*monitor.Incident, error) {
+	for _, incident := range m.incidents {
+		if incident.ProjectID() == projectID && incident.IsOpen() {
+			return incident, nil
+		}
+	}
+	return nil, monitor.ErrIncidentNotFound
+}
+
+// mockProber answers every probe with the same status, or fails it
+type mockProber struct {
+	status int
+	err    error
+	urls   []string
+}
+
+func (m *mockProber) Probe(ctx context.Context, url string) (int, error) {
+	m.urls = append(m.urls, url)
+	return m.status, m.err
+}
+
+// mockAlerter records the titles of alerts
+type mockAlerter struct {
+	titles []string
+}
+
+func (m *mockAlerter) Alert(ctx context.Context, key, title, message string) {
+	m.titles = append(m.titles, title)
+}
+
+func TestMonitoringService_CheckAll(t *testing.T) {
+	owner := user.NewUserID()
+	proj := newTestProject(t, owner, "my-app", func(proj *project.Project) error {
+		return proj.SetContainer(3000, "/healthz", 0, 0)
+	})
+	dep := newTestDeployment(t, proj, project.EnvironmentProduction, deployment.StatusDeployed)
+
+	checks := &mockChecks{}
+	incidents := &mockIncidents{}
+	prober := &mockProber{err: errors.New("connection refused")}
+	alerter := &mockAlerter{}
+	svc := service.NewMonitoringService(newMockProjectRepository(proj), newMockDeploymentRepository(dep),
+		checks, incidents, prober, service.MonitoringSettings{FailureThreshold: 2, ProbeTimeout: time.Second})
+	svc.AddAlerter(alerter)
+
+	checkAll := func() {
+		t.Helper()
+		checked, err := svc.CheckAll(context.Background())
+		if err != nil || checked != 1 {
+			t.Fatalf("CheckAll() = %d, %v, want the project checked", checked, err)
+		}
+	}
+
+	checkAll()
+	if len(incidents.incidents) != 0 {
+		t.Fatalf("incident opened after 1 failed check, want it after 2")
+	}
+	if prober.urls[0] != "https://my-app.snapdeploy.app/healthz" {
+		t.Errorf("probed %q, want the health check path of the project's URL", prober.urls[0])
+	}
+
+	checkAll()
+	checkAll()
+	if len(incidents.incidents) != 1 {
+		t.Fatalf("%d incidents opened, want 1 while the checks keep failing", len(incidents.incidents))
+	}
+	incident := incidents.incidents[0]
+	if !incident.IsOpen() || incident.FailedChecks() != 3 || incident.Cause() != "connection refused" {
+		t.Errorf("incident open = %v, failed checks = %d, cause = %q", incident.IsOpen(), incident.FailedChecks(), incident.Cause())
+	}
+
+	prober.err, prober.status = nil, 200
+	checkAll()
+	if incident.IsOpen() {
+		t.Errorf("incident still open after a healthy check")
+	}
+	if len(alerter.titles) != 2 || !strings.HasPrefix(alerter.titles[0], "Incident: my-app") || !strings.HasPrefix(alerter.titles[1], "Resolved: my-app") {
+		t.Errorf("alerts = %q, want the incident opened then resolved", alerter.titles)
+	}
+
+	succeeded, total := 0, len(checks.checks)
+	for _, check := range checks.checks {
+		if check.Healthy {
+			succeeded++
+		}
+	}
+	if succeeded != 1 || total != 4 {
+		t.Errorf("recorded %d healthy checks out of %d, want 1 out of 4", succeeded, total)
+	}
+}
+
+func TestMonitoringService_CheckAll_SkipsOtherEnvironmentsAndWorkers(t *testing.T) {
+	owner := user.NewUserID()
+	worker := newTestProject(t, owner, "worker", func(proj *project.Project) error {
+		if err := proj.SetType("WORKER"); err != nil {
+			return err
+		}
+		return proj.SetEnvironments([]string{"staging"})
+	})
+	production := newTestDeployment(t, worker, project.EnvironmentProduction, deployment.StatusDeployed)
+	staging := newTestDeployment(t, worker, project.Environment("staging"), deployment.StatusDeployed)
+
+	prober := &mockProber{status: 200}
+	svc := service.NewMonitoringService(newMockProjectRepository(worker), newMockDeploymentRepository(production, staging),
+		&mockChecks{}, &mockIncidents{}, prober, service.MonitoringSettings{FailureThreshold: 3, ProbeTimeout: time.Second})
+
+	checked, err := svc.CheckAll(context.Background())
+	if err != nil || checked != 0 || len(prober.urls) != 0 {
+		t.Errorf("CheckAll() = %d, %v, probed %q, want workers and other environments skipped", checked, err, prober.urls)
+	}
+}
@@ -2,27 +2,117 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
 	"snapdeploy-core/internal/application/dto"
 	"snapdeploy-core/internal/domain/project"
 	"snapdeploy-core/internal/domain/user"
+	"snapdeploy-core/internal/logging"
 )
 
+// InfrastructureTeardown removes the cloud resources provisioned for a project or one of its environments
+type InfrastructureTeardown interface {
+	TeardownProject(ctx context.Context, proj *project.Project, report func(message string)) error
+	TeardownEnvironment(ctx context.Context, proj *project.Project, env project.Environment) error
+}
+
+// RepositoryBranchLister lists the branches of a project's repository with the credentials it would be cloned with
+type RepositoryBranchLister interface {
+	ListRepositoryBranches(ctx context.Context, proj *project.Project) ([]string, error)
+}
+
+// DomainRecordChecker checks whether a DNS record exists for a subdomain of the platform's domain
+type DomainRecordChecker interface {
+	DomainRecordExists(ctx context.Context, subdomain string) (bool, error)
+}
+
+// ProjectQuota decides whether a user may create another project under their plan
+type ProjectQuota interface {
+	CheckProjects(ctx context.Context, userID user.UserID) error
+}
+
+// EnvVarCopier copies the environment variables of a project to its duplicate, returning how many were copied
+type EnvVarCopier interface {
+	CopyEnvVars(ctx context.Context, from, to *project.Project) (int, error)
+}
+
+// DuplicateDeployer deploys a duplicated project what its source project runs in production
+type DuplicateDeployer interface {
+	DeployDuplicate(ctx context.Context, source, duplicate *project.Project, userID user.UserID) (*dto.DeploymentResponse, error)
+}
+
 // ProjectService handles project-related use cases
 type ProjectService struct {
-	projectRepo project.ProjectRepository
+	projectRepo  project.ProjectRepository
+	envVarRepo   project.EnvironmentVariableRepository
+	uow          UnitOfWork
+	teardown     InfrastructureTeardown
+	repositories RepositoryBranchLister
+	dnsRecords   DomainRecordChecker
+	quota        ProjectQuota
+	envVarCopier EnvVarCopier
+	deployer     DuplicateDeployer
+	baseDomain   string
+	database     DatabaseServer
+}
+
+// DatabaseServer is the Postgres server holding the databases of projects that require one
+type DatabaseServer struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
 }
 
 // NewProjectService creates a new project service
-func NewProjectService(projectRepo project.ProjectRepository) *ProjectService {
+func NewProjectService(projectRepo project.ProjectRepository, envVarRepo project.EnvironmentVariableRepository, uow UnitOfWork) *ProjectService {
 	return &ProjectService{
 		projectRepo: projectRepo,
+		envVarRepo:  envVarRepo,
+		uow:         uow,
 	}
 }
 
+// SetInfrastructureTeardown sets the component used to remove cloud resources on project and environment deletion
+func (s *ProjectService) SetInfrastructureTeardown(teardown InfrastructureTeardown) {
+	s.teardown = teardown
+}
+
+// SetValidationSources sets the components project validation checks repository access and DNS records with.
+// Either may be nil, the checks it makes are then skipped with a warning.
+func (s *ProjectService) SetValidationSources(repositories RepositoryBranchLister, dnsRecords DomainRecordChecker) {
+	s.repositories = repositories
+	s.dnsRecords = dnsRecords
+}
+
+// SetProjectQuota sets what limits how many projects a user may have (optional)
+func (s *ProjectService) SetProjectQuota(quota ProjectQuota) {
+	s.quota = quota
+}
+
+// SetDuplication sets what copies environment variables to and deploys duplicated projects. Without a copier,
+// duplicates start without environment variables; without a deployer, they can't be deployed on creation.
+func (s *ProjectService) SetDuplication(envVarCopier EnvVarCopier, deployer DuplicateDeployer) {
+	s.envVarCopier = envVarCopier
+	s.deployer = deployer
+}
+
+// SetBaseDomain sets the domain projects are served on subdomains of, snapdeploy.app by default
+func (s *ProjectService) SetBaseDomain(domain string) {
+	s.baseDomain = domain
+}
+
+// SetDatabaseServer sets the server the database URLs of projects requiring a database point at. Without
+// one, projects are shown without a database URL.
+func (s *ProjectService) SetDatabaseServer(server DatabaseServer) {
+	s.database = server
+}
+
 // CreateProject creates a new project
 func (s *ProjectService) CreateProject(ctx context.Context, userID string, req *dto.CreateProjectRequest) (*dto.ProjectResponse, error) {
 	// Parse user ID
@@ -31,6 +121,12 @@ func (s *ProjectService) CreateProject(ctx context.Context, userID string, req *
 		return nil, fmt.Errorf("invalid user ID: %w", err)
 	}
 
+	if s.quota != nil {
+		if err := s.quota.CheckProjects(ctx, uid); err != nil {
+			return nil, err
+		}
+	}
+
 	// Check if project with same repository URL already exists
 	repoURL, err := project.NewRepositoryURL(req.RepositoryURL)
 	if err != nil {
@@ -62,6 +158,16 @@ func (s *ProjectService) CreateProject(ctx context.Context, userID string, req *
 		return nil, fmt.Errorf("failed to create project entity: %w", err)
 	}
 
+	for _, setting := range projectSettings(req) {
+		if err := setting.apply(proj); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.checkSubdomains(ctx, proj); err != nil {
+		return nil, err
+	}
+
 	// Save project
 	if err := s.projectRepo.Save(ctx, proj); err != nil {
 		return nil, fmt.Errorf("failed to save project: %w", err)
@@ -70,8 +176,289 @@ func (s *ProjectService) CreateProject(ctx context.Context, userID string, req *
 	return s.toDTO(proj), nil
 }
 
-// GetProjectByID retrieves a project by its ID
-func (s *ProjectService) GetProjectByID(ctx context.Context, projectID string) (*dto.ProjectResponse, error) {
+// DuplicateProject creates a copy of a project, such as a staging copy, with its configuration and the
+// environment variables of its environments under a new custom domain. With req.Deploy the copy then deploys the
+// commit the project last deployed successfully to production; failing to start that deployment doesn't undo the
+// copy and is reported in the response instead.
+func (s *ProjectService) DuplicateProject(ctx context.Context, projectID, userID string, req *dto.DuplicateProjectRequest) (*dto.DuplicateProjectResponse, error) {
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	source, err := s.projectRepo.FindByID(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	if !source.BelongsToUser(uid) {
+		return nil, project.ErrUnauthorized
+	}
+
+	if source.IsDeleting() {
+		return nil, project.ErrProjectDeleting
+	}
+
+	if s.quota != nil {
+		if err := s.quota.CheckProjects(ctx, uid); err != nil {
+			return nil, err
+		}
+	}
+
+	duplicate, err := source.Duplicate(req.CustomDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkSubdomains(ctx, duplicate); err != nil {
+		return nil, err
+	}
+
+	// The copy is only kept with all of its environment variables
+	copied := 0
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.projectRepo.Save(ctx, duplicate); err != nil {
+			return fmt.Errorf("failed to save project: %w", err)
+		}
+		if s.envVarCopier == nil {
+			return nil
+		}
+		copied, err = s.envVarCopier.CopyEnvVars(ctx, source, duplicate)
+		if err != nil {
+			return fmt.Errorf("failed to copy environment variables: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Duplicated project", "project_id", source.ID().String(), "duplicate_id", duplicate.ID().String(), "env_vars_copied", copied)
+
+	response := &dto.DuplicateProjectResponse{
+		Project:       s.toDTO(duplicate),
+		EnvVarsCopied: copied,
+	}
+	if !req.Deploy {
+		return response, nil
+	}
+
+	if s.deployer == nil {
+		response.DeploymentError = "deployments can't be started on duplication"
+		return response, nil
+	}
+	response.Deployment, err = s.deployer.DeployDuplicate(ctx, source, duplicate, uid)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to deploy duplicated project", "duplicate_id", duplicate.ID().String(), "error", err)
+		response.DeploymentError = err.Error()
+	}
+	return response, nil
+}
+
+// ValidateProject checks a project configuration without creating the project, reporting every problem its
+// creation or first deployment would run into by the field causing it: invalid values, commands the language's
+// build image can't run, a repository that can't be read, a missing branch and a custom domain that is taken.
+func (s *ProjectService) ValidateProject(ctx context.Context, userID string, req *dto.ValidateProjectRequest) (*dto.ProjectValidationResponse, error) {
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	v := &dto.ProjectValidationResponse{Errors: []dto.FieldError{}, Warnings: []dto.FieldError{}}
+	fail := func(field string, err error) {
+		v.Errors = append(v.Errors, dto.FieldError{Field: field, Message: err.Error()})
+	}
+	warn := func(field, message string) {
+		v.Warnings = append(v.Warnings, dto.FieldError{Field: field, Message: message})
+	}
+
+	// The values NewProject validates are checked one by one so that each problem is reported
+	repoURL, err := project.NewRepositoryURL(req.RepositoryURL)
+	if err != nil {
+		fail("repository_url", err)
+	} else if exists, err := s.projectRepo.ExistsByRepositoryURL(ctx, uid, repoURL); err != nil {
+		return nil, fmt.Errorf("failed to check project existence: %w", err)
+	} else if exists {
+		fail("repository_url", project.ErrProjectAlreadyExists)
+	}
+
+	language, err := project.NewLanguage(req.Language)
+	if err != nil {
+		fail("language", err)
+	}
+
+	type command struct {
+		field, command string
+		required       bool
+	}
+	commands := []command{
+		{"install_command", req.InstallCommand, true},
+		{"build_command", req.BuildCommand, false},
+		{"run_command", req.RunCommand, true},
+		{"migration_command", req.MigrationCommand, false},
+	}
+	for i, svc := range req.Services {
+		commands = append(commands, command{fmt.Sprintf("services[%d].command", i), svc.Command, false})
+	}
+	for _, c := range commands {
+		if c.required {
+			if _, err := project.NewCommand(c.command); err != nil {
+				fail(c.field, err)
+				continue
+			}
+		}
+		// Builds run in an image with only the language's toolchain installed
+		if tool := language.ForeignTool(c.command); language.IsValid() && tool != "" {
+			fail(c.field, fmt.Errorf("%s isn't available in %s builds", tool, language))
+		}
+	}
+
+	if req.Port < 0 || req.Port > 65535 {
+		fail("port", project.ErrInvalidPort)
+	}
+
+	// An empty custom domain is generated at random
+	chosenDomain := strings.TrimSpace(req.CustomDomain) != ""
+	if chosenDomain {
+		if _, err := project.NewCustomDomain(req.CustomDomain); err != nil {
+			fail("custom_domain", err)
+		}
+	}
+
+	proj, err := project.NewProject(uid, req.RepositoryURL, req.InstallCommand, req.BuildCommand, req.RunCommand,
+		req.Language, req.CustomDomain, req.RequireDB, req.MigrationCommand)
+	if err != nil {
+		// The problem is reported above, and the rest of the configuration is checked against the project
+		warn("", "the rest of the configuration and the repository are checked once the errors are fixed")
+		return v, nil
+	}
+	for _, setting := range projectSettings(&req.CreateProjectRequest) {
+		if err := setting.apply(proj); err != nil {
+			fail(setting.field, err)
+		}
+	}
+
+	if err := s.checkSubdomains(ctx, proj); errors.Is(err, project.ErrCustomDomainTaken) {
+		fail("custom_domain", err)
+	} else if err != nil {
+		return nil, err
+	} else if chosenDomain {
+		s.checkDomainRecord(ctx, proj.CustomDomain(), fail, warn)
+	}
+
+	s.checkRepository(ctx, proj, req.Branch, fail, warn)
+
+	v.Valid = len(v.Errors) == 0
+	return v, nil
+}
+
+// CheckDomain reports whether a custom domain can be chosen for a new project: it must be a valid subdomain that
+// no project claims for itself, one of its environments or WEB services, and that has no DNS record yet
+func (s *ProjectService) CheckDomain(ctx context.Context, name string) (*dto.DomainAvailabilityResponse, error) {
+	response := &dto.DomainAvailabilityResponse{Name: name}
+
+	domain, err := project.NewCustomDomain(name)
+	if err != nil {
+		response.Reason = err.Error()
+		return response, nil
+	}
+	response.Name = domain.String()
+
+	claimants, err := s.projectRepo.FindByCustomDomains(ctx, project.ClaimantDomains([]string{domain.String()}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check custom domain: %w", err)
+	}
+	for _, other := range claimants {
+		if slices.Contains(other.Subdomains(), domain.String()) {
+			response.Reason = project.ErrCustomDomainTaken.Error()
+			return response, nil
+		}
+	}
+
+	if s.dnsRecords != nil {
+		exists, err := s.dnsRecords.DomainRecordExists(ctx, domain.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to check DNS record: %w", err)
+		}
+		if exists {
+			response.Reason = fmt.Sprintf("a DNS record already exists for %s", domain)
+			return response, nil
+		}
+	}
+
+	response.Available = true
+	return response, nil
+}
+
+// checkSubdomains returns project.ErrCustomDomainTaken if another project already claims one of the subdomains
+// of proj. Deploying it would otherwise take over that project's DNS record and load balancer listener rule.
+func (s *ProjectService) checkSubdomains(ctx context.Context, proj *project.Project) error {
+	claimants, err := s.projectRepo.FindByCustomDomains(ctx, project.ClaimantDomains(proj.Subdomains()))
+	if err != nil {
+		return fmt.Errorf("failed to check custom domain: %w", err)
+	}
+
+	for _, other := range claimants {
+		if other.ID().Equals(proj.ID()) {
+			continue
+		}
+		if subdomain := proj.SharedSubdomain(other); subdomain != "" {
+			return fmt.Errorf("%w: %s", project.ErrCustomDomainTaken, subdomain)
+		}
+	}
+	return nil
+}
+
+// checkDomainRecord reports whether a DNS record already exists for a custom domain no project claims, such as
+// one added by hand
+func (s *ProjectService) checkDomainRecord(ctx context.Context, domain project.CustomDomain, fail func(string, error), warn func(string, string)) {
+	if s.dnsRecords == nil {
+		warn("custom_domain", "DNS records can't be checked, the domain may already be in use")
+		return
+	}
+
+	exists, err := s.dnsRecords.DomainRecordExists(ctx, domain.String())
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to check DNS record of custom domain", "domain", domain.String(), "error", err)
+		warn("custom_domain", "DNS records couldn't be checked, the domain may already be in use")
+		return
+	}
+	if exists {
+		fail("custom_domain", fmt.Errorf("a DNS record already exists for %s", domain))
+	}
+}
+
+// checkRepository reports whether a project's repository can be read with the credentials it would be cloned
+// with, and has the branch deployments are built from
+func (s *ProjectService) checkRepository(ctx context.Context, proj *project.Project, branch string, fail func(string, error), warn func(string, string)) {
+	if s.repositories == nil {
+		warn("repository_url", "repository access can't be checked")
+		return
+	}
+
+	branches, err := s.repositories.ListRepositoryBranches(ctx, proj)
+	if errors.Is(err, ErrUnsupportedGitProvider) {
+		warn("repository_url", "access can only be checked for repositories hosted on GitHub, GitLab or Bitbucket")
+		return
+	}
+	if err != nil {
+		slog.InfoContext(ctx, "Repository of validated project can't be read", "repository_url", proj.RepositoryURL().String(), "error", err)
+		fail("repository_url", errors.New("repository doesn't exist or can't be read with your Git provider account"))
+		return
+	}
+
+	if branch != "" && !slices.Contains(branches, branch) {
+		fail("branch", fmt.Errorf("branch %s doesn't exist in the repository", branch))
+	}
+}
+
+// GetProjectByID retrieves a project by its ID. Deleted projects are only found with includeDeleted.
+func (s *ProjectService) GetProjectByID(ctx context.Context, projectID string, includeDeleted bool) (*dto.ProjectResponse, error) {
 	// Parse project ID
 	pid, err := project.ParseProjectID(projectID)
 	if err != nil {
@@ -79,7 +466,11 @@ func (s *ProjectService) GetProjectByID(ctx context.Context, projectID string) (
 	}
 
 	// Get project
-	proj, err := s.projectRepo.FindByID(ctx, pid)
+	find := s.projectRepo.FindByID
+	if includeDeleted {
+		find = s.projectRepo.FindByIDIncludingDeleted
+	}
+	proj, err := find(ctx, pid)
 	if err != nil {
 		return nil, err
 	}
@@ -87,9 +478,8 @@ func (s *ProjectService) GetProjectByID(ctx context.Context, projectID string) (
 	return s.toDTO(proj), nil
 }
 
-// GetProjectsByUserID retrieves all projects for a user with pagination
-func (s *ProjectService) GetProjectsByUserID(ctx context.Context, userID string, page, limit int32) (*dto.ProjectListResponse, error) {
-	fmt.Printf("[PERF] GetProjectsByUserID called for user: %s\n", userID)
+// GetProjectsByUserID retrieves all projects for a user with pagination, deleted ones only with includeDeleted
+func (s *ProjectService) GetProjectsByUserID(ctx context.Context, userID string, page, limit int32, includeDeleted bool) (*dto.ProjectListResponse, error) {
 	startTime := time.Now()
 
 	if page < 1 {
@@ -106,25 +496,30 @@ func (s *ProjectService) GetProjectsByUserID(ctx context.Context, userID string,
 
 	offset := (page - 1) * limit
 
-	fmt.Printf("[PERF] Fetching projects from DB...\n")
+	findByUserID, countByUserID := s.projectRepo.FindByUserID, s.projectRepo.CountByUserID
+	if includeDeleted {
+		findByUserID, countByUserID = s.projectRepo.FindByUserIDIncludingDeleted, s.projectRepo.CountByUserIDIncludingDeleted
+	}
+
 	dbStart := time.Now()
-	projects, err := s.projectRepo.FindByUserID(ctx, uid, limit, offset)
-	fmt.Printf("[PERF] FindByUserID took: %v\n", time.Since(dbStart))
+	projects, err := findByUserID(ctx, uid, limit, offset)
+	findDuration := time.Since(dbStart)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch projects: %w", err)
 	}
 
-	fmt.Printf("[PERF] Counting projects...\n")
 	countStart := time.Now()
-	total, err := s.projectRepo.CountByUserID(ctx, uid)
-	fmt.Printf("[PERF] CountByUserID took: %v\n", time.Since(countStart))
+	total, err := countByUserID(ctx, uid)
+	countDuration := time.Since(countStart)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count projects: %w", err)
 	}
 
 	projectResponses := make([]*dto.ProjectResponse, len(projects))
+	version := newListVersion(total)
 	for i, proj := range projects {
 		projectResponses[i] = s.toDTO(proj)
+		version.add(proj.ID().String(), proj.UpdatedAt())
 	}
 
 	totalPages := (total + int64(limit) - 1) / int64(limit)
@@ -137,9 +532,15 @@ func (s *ProjectService) GetProjectsByUserID(ctx context.Context, userID string,
 			Total:      total,
 			TotalPages: totalPages,
 		},
+		ETag: version.etag(),
 	}
 
-	fmt.Printf("[PERF] GetProjectsByUserID total time: %v\n", time.Since(startTime))
+	slog.DebugContext(ctx, "Listed projects",
+		"user_id", userID,
+		"find_ms", findDuration.Milliseconds(),
+		"count_ms", countDuration.Milliseconds(),
+		"total_ms", time.Since(startTime).Milliseconds(),
+	)
 	return result, nil
 }
 
@@ -167,92 +568,522 @@ func (s *ProjectService) UpdateProject(ctx context.Context, projectID, userID st
 		return nil, project.ErrUnauthorized
 	}
 
+	if proj.IsDeleting() {
+		return nil, project.ErrProjectDeleting
+	}
+
 	// Update project
 	if err := proj.Update(req.RepositoryURL, req.InstallCommand, req.BuildCommand, req.RunCommand, req.Language, req.CustomDomain, req.RequireDB, req.MigrationCommand); err != nil {
 		return nil, fmt.Errorf("failed to update project: %w", err)
 	}
 
+	if err := proj.SetImageRetention(req.ImageRetention); err != nil {
+		return nil, err
+	}
+
+	if err := proj.SetType(req.Type); err != nil {
+		return nil, err
+	}
+
+	if err := proj.SetSchedule(req.Schedule); err != nil {
+		return nil, err
+	}
+
+	if err := proj.SetOutputDirectory(req.OutputDirectory); err != nil {
+		return nil, err
+	}
+
+	if err := proj.SetDeploymentStrategy(req.DeploymentStrategy); err != nil {
+		return nil, err
+	}
+
+	if err := proj.SetCanary(req.CanaryPercent, req.CanaryBakeMinutes); err != nil {
+		return nil, err
+	}
+
+	if err := proj.SetDatastores(req.Datastores); err != nil {
+		return nil, err
+	}
+
+	if err := proj.SetVolume(req.VolumeMountPath, req.VolumeSizeGB); err != nil {
+		return nil, err
+	}
+
+	services, err := newServices(req.Services)
+	if err != nil {
+		return nil, err
+	}
+	if err := proj.SetServices(services); err != nil {
+		return nil, err
+	}
+
+	if err := proj.SetDeploymentTarget(req.DeploymentTarget, req.LambdaEndpoint); err != nil {
+		return nil, err
+	}
+
+	previous := proj.Environments()
+	if err := proj.SetEnvironments(req.Environments); err != nil {
+		return nil, err
+	}
+
+	if err := proj.SetProtectedEnvironments(req.ProtectedEnvironments); err != nil {
+		return nil, err
+	}
+
+	proj.SetPublicStatusPage(req.PublicStatusPage)
+
+	if err := proj.SetBuildLimits(req.BuildSize, req.BuildTimeoutMinutes); err != nil {
+		return nil, err
+	}
+
+	proj.SetRedeployOnEnvChange(req.RedeployOnEnvChange)
+
+	if err := s.checkSubdomains(ctx, proj); err != nil {
+		return nil, err
+	}
+
 	// Save updated project
 	if err := s.projectRepo.Save(ctx, proj); err != nil {
 		return nil, fmt.Errorf("failed to save project: %w", err)
 	}
 
+	for _, env := range previous {
+		if !proj.HasEnvironment(env) {
+			s.removeEnvironment(ctx, proj, env)
+		}
+	}
+
 	return s.toDTO(proj), nil
 }
 
-// DeleteProject deletes a project
-func (s *ProjectService) DeleteProject(ctx context.Context, projectID, userID string) error {
+// GetDeployRules returns the rules deciding which branches of a project pushes are deployed from
+func (s *ProjectService) GetDeployRules(ctx context.Context, projectID string) (*dto.DeployRulesResponse, error) {
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	proj, err := s.projectRepo.FindByID(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDeployRulesDTO(proj), nil
+}
+
+// UpdateDeployRules replaces the rules deciding which branches of a project pushes are deployed from
+func (s *ProjectService) UpdateDeployRules(ctx context.Context, projectID string, req *dto.UpdateDeployRulesRequest) (*dto.DeployRulesResponse, error) {
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	proj, err := s.projectRepo.FindByID(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	if proj.IsDeleting() {
+		return nil, project.ErrProjectDeleting
+	}
+
+	rules := make([]project.DeployRule, 0, len(req.Rules))
+	for _, r := range req.Rules {
+		rule, err := project.NewDeployRule(r.Branch, r.Environment)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	if err := proj.SetDeployRules(rules); err != nil {
+		return nil, err
+	}
+
+	if err := s.projectRepo.Save(ctx, proj); err != nil {
+		return nil, fmt.Errorf("failed to save project: %w", err)
+	}
+
+	return toDeployRulesDTO(proj), nil
+}
+
+// removeEnvironment deletes the environment variables of an environment removed from a project and tears
+// down its cloud resources in the background. A failed teardown leaves the resources behind, it doesn't
+// undo the removal.
+func (s *ProjectService) removeEnvironment(ctx context.Context, proj *project.Project, env project.Environment) {
+	if err := s.envVarRepo.DeleteEnvironment(ctx, proj.ID(), env); err != nil {
+		slog.ErrorContext(ctx, "Failed to delete environment variables of removed environment", "project_id", proj.ID().String(), "environment", env.String(), "error", err)
+	}
+
+	if s.teardown == nil {
+		slog.WarnContext(ctx, "No infrastructure teardown configured, skipping cleanup of removed environment", "environment", env.String())
+		return
+	}
+
+	// The request context ends with the response
+	go func(ctx context.Context) {
+		if err := s.teardown.TeardownEnvironment(ctx, proj, env); err != nil {
+			slog.ErrorContext(ctx, "Environment teardown failed", "error", err)
+		}
+	}(logging.With(logging.Detach(ctx), "project_id", proj.ID().String(), "environment", env.String()))
+}
+
+// DeleteProject schedules a project for deletion.
+// Cloud resources are torn down asynchronously; progress is reported on the project status.
+func (s *ProjectService) DeleteProject(ctx context.Context, projectID, userID string) (*dto.ProjectResponse, error) {
 	// Parse IDs
 	pid, err := project.ParseProjectID(projectID)
 	if err != nil {
-		return fmt.Errorf("invalid project ID: %w", err)
+		return nil, fmt.Errorf("invalid project ID: %w", err)
 	}
 
 	uid, err := user.ParseUserID(userID)
 	if err != nil {
-		return fmt.Errorf("invalid user ID: %w", err)
+		return nil, fmt.Errorf("invalid user ID: %w", err)
 	}
 
 	// Get project to check ownership
 	proj, err := s.projectRepo.FindByID(ctx, pid)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Check ownership
 	if !proj.BelongsToUser(uid) {
-		return project.ErrUnauthorized
+		return nil, project.ErrUnauthorized
 	}
 
-	// Delete project
-	if err := s.projectRepo.Delete(ctx, pid); err != nil {
-		return fmt.Errorf("failed to delete project: %w", err)
+	// Mark as deleting (a failed teardown can be retried, as can one that stalled)
+	if err := proj.MarkDeleting(); err != nil {
+		if !errors.Is(err, project.ErrProjectDeleting) || !proj.TeardownStalled(time.Now().Add(-teardownStaleAfter)) {
+			return nil, err
+		}
+		proj.ReportProgress("Teardown resumed")
+	}
+
+	if err := s.projectRepo.Save(ctx, proj); err != nil {
+		return nil, fmt.Errorf("failed to save project: %w", err)
+	}
+
+	response := s.toDTO(proj)
+
+	// Tear down in background - the request context ends with the response. Should this instance stop
+	// meanwhile, the teardown stalls and ResumeTeardowns runs it again.
+	go s.teardownProject(logging.With(logging.Detach(ctx), "project_id", proj.ID().String()), proj)
+
+	return response, nil
+}
+
+// teardownStaleAfter is how long a teardown may go without reporting progress before it is considered stalled,
+// e.g. because the instance running it stopped, and run again. Each step skips what's already removed.
+const teardownStaleAfter = 30 * time.Minute
+
+// ResumeTeardowns runs the teardowns that stalled again, one after the other, returning how many were resumed.
+// Teardowns that fail again are marked so and left for the user to retry.
+func (s *ProjectService) ResumeTeardowns(ctx context.Context) (int, error) {
+	resumed := 0
+	for {
+		proj, err := s.projectRepo.ClaimStalledTeardown(ctx, time.Now().Add(-teardownStaleAfter))
+		if errors.Is(err, project.ErrProjectNotFound) {
+			return resumed, nil
+		}
+		if err != nil {
+			return resumed, fmt.Errorf("failed to claim stalled teardown: %w", err)
+		}
+
+		resumed++
+		teardownCtx := logging.With(ctx, "project_id", proj.ID().String())
+		slog.InfoContext(teardownCtx, "Resuming stalled project teardown", "stalled_since", proj.UpdatedAt())
+		proj.ReportProgress("Teardown resumed")
+		s.teardownProject(teardownCtx, proj)
+	}
+}
+
+// RunTeardownResumer resumes the teardowns that stalled at start and then on every interval, until the context
+// is cancelled
+func (s *ProjectService) RunTeardownResumer(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		resumed, err := s.ResumeTeardowns(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Resuming project teardowns failed", "error", err)
+		} else if resumed > 0 {
+			slog.InfoContext(ctx, "Resumed stalled project teardowns", "count", resumed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// userProjectPageSize bounds how many of a user's projects are read at once when deleting them
+const userProjectPageSize = 100
+
+// DeleteUserProjects tears down every project of a user one after the other, returning once they are all
+// deleted. A failed teardown doesn't stop the others; the projects that failed are reported in the error and
+// marked so, and calling it again retries them.
+func (s *ProjectService) DeleteUserProjects(ctx context.Context, userID user.UserID) error {
+	// Read every project first - deleted projects drop out of the pages as the teardowns go
+	var projects []*project.Project
+	for offset := int32(0); ; offset += userProjectPageSize {
+		page, err := s.projectRepo.FindByUserID(ctx, userID, userProjectPageSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to list projects: %w", err)
+		}
+		projects = append(projects, page...)
+		if len(page) < userProjectPageSize {
+			break
+		}
 	}
 
+	var errs []error
+	for _, proj := range projects {
+		// A project already being torn down is torn down again; each step skips what's already removed
+		if err := proj.MarkDeleting(); err != nil && !errors.Is(err, project.ErrProjectDeleting) {
+			return err
+		}
+		if err := s.projectRepo.Save(ctx, proj); err != nil {
+			return fmt.Errorf("failed to save project: %w", err)
+		}
+
+		if err := s.teardownProject(logging.With(ctx, "project_id", proj.ID().String()), proj); err != nil {
+			errs = append(errs, fmt.Errorf("project %s: %w", proj.ID(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to delete %d of %d projects: %w", len(errs), len(projects), errors.Join(errs...))
+	}
 	return nil
 }
 
-// toDTO converts a domain project to DTO
-func (s *ProjectService) toDTO(proj *project.Project) *dto.ProjectResponse {
-	// Get base domain from environment
-	baseDomain := os.Getenv("BASE_DOMAIN")
+// teardownProject removes cloud resources, environment variables and finally the project itself
+func (s *ProjectService) teardownProject(ctx context.Context, proj *project.Project) error {
+	report := func(message string) {
+		slog.InfoContext(ctx, "Project teardown progress", "message", message)
+		proj.ReportProgress(message)
+		if err := s.projectRepo.Save(ctx, proj); err != nil {
+			slog.ErrorContext(ctx, "Failed to save teardown progress", "error", err)
+		}
+	}
+
+	fail := func(err error) error {
+		slog.ErrorContext(ctx, "Project teardown failed", "error", err)
+		proj.MarkDeleteFailed(err.Error())
+		if err := s.projectRepo.Save(ctx, proj); err != nil {
+			slog.ErrorContext(ctx, "Failed to save teardown status", "error", err)
+		}
+		return err
+	}
+
+	if s.teardown != nil {
+		if err := s.teardown.TeardownProject(ctx, proj, report); err != nil {
+			return fail(err)
+		}
+	} else {
+		slog.WarnContext(ctx, "No infrastructure teardown configured, skipping cloud cleanup")
+	}
+
+	// Environment variables are removed for good; the project and its deployments are soft deleted
+	// and purged by the retention job
+	report("Removing environment variables...")
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.envVarRepo.DeleteAll(ctx, proj.ID()); err != nil {
+			return fmt.Errorf("failed to delete environment variables: %w", err)
+		}
+		if err := s.projectRepo.Delete(ctx, proj.ID()); err != nil {
+			return fmt.Errorf("failed to delete project: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	slog.InfoContext(ctx, "Project deleted")
+	return nil
+}
+
+// projectDeploymentURL returns the public URL a project's environment is served on, e.g. https://my-app.snapdeploy.app
+// or https://my-app-staging.snapdeploy.app, or an empty string for projects that aren't served on their subdomain.
+// The function URL of a LAMBDA project is only known once it is deployed, and is shown in the deployment logs.
+func projectDeploymentURL(baseDomain string, proj *project.Project, env project.Environment) string {
+	if !proj.Type().HasURL() || proj.LambdaEndpoint() == project.EndpointFunctionURL {
+		return ""
+	}
+
+	return subdomainURL(baseDomain, proj.CustomDomain().ForEnvironment(env).String())
+}
+
+// subdomainURL returns the public URL of a subdomain of the platform's base domain
+func subdomainURL(baseDomain, subdomain string) string {
 	if baseDomain == "" {
 		baseDomain = "snapdeploy.app" // default
 	}
 
-	// Construct full deployment URL
-	deploymentURL := fmt.Sprintf("https://%s.%s", proj.CustomDomain().String(), baseDomain)
+	return fmt.Sprintf("https://%s.%s", subdomain, baseDomain)
+}
+
+// projectSetting applies the field of a create request named field to a project
+type projectSetting struct {
+	field string
+	apply func(proj *project.Project) error
+}
+
+// projectSettings returns the settings of a create request, in the order they must be applied since some
+// depend on others (the strategy on the type for instance)
+func projectSettings(req *dto.CreateProjectRequest) []projectSetting {
+	return []projectSetting{
+		{"image_retention", func(proj *project.Project) error { return proj.SetImageRetention(req.ImageRetention) }},
+		{"type", func(proj *project.Project) error { return proj.SetType(req.Type) }},
+		{"schedule", func(proj *project.Project) error { return proj.SetSchedule(req.Schedule) }},
+		{"output_directory", func(proj *project.Project) error { return proj.SetOutputDirectory(req.OutputDirectory) }},
+		{"deployment_strategy", func(proj *project.Project) error { return proj.SetDeploymentStrategy(req.DeploymentStrategy) }},
+		{"canary_percent", func(proj *project.Project) error { return proj.SetCanary(req.CanaryPercent, req.CanaryBakeMinutes) }},
+		{"datastores", func(proj *project.Project) error { return proj.SetDatastores(req.Datastores) }},
+		{"volume_mount_path", func(proj *project.Project) error { return proj.SetVolume(req.VolumeMountPath, req.VolumeSizeGB) }},
+		{"services", func(proj *project.Project) error {
+			services, err := newServices(req.Services)
+			if err != nil {
+				return err
+			}
+			return proj.SetServices(services)
+		}},
+		{"deployment_target", func(proj *project.Project) error {
+			return proj.SetDeploymentTarget(req.DeploymentTarget, req.LambdaEndpoint)
+		}},
+		{"environments", func(proj *project.Project) error { return proj.SetEnvironments(req.Environments) }},
+		{"protected_environments", func(proj *project.Project) error { return proj.SetProtectedEnvironments(req.ProtectedEnvironments) }},
+		{"public_status_page", func(proj *project.Project) error {
+			proj.SetPublicStatusPage(req.PublicStatusPage)
+			return nil
+		}},
+		{"build_size", func(proj *project.Project) error { return proj.SetBuildLimits(req.BuildSize, req.BuildTimeoutMinutes) }},
+		{"redeploy_on_env_change", func(proj *project.Project) error {
+			proj.SetRedeployOnEnvChange(req.RedeployOnEnvChange)
+			return nil
+		}},
+	}
+}
+
+// newServices validates the services a project runs besides its main one
+func newServices(requests []dto.ServiceRequest) ([]project.Service, error) {
+	services := make([]project.Service, 0, len(requests))
+	for _, r := range requests {
+		svc, err := project.NewService(r.Name, r.Type, r.Command, r.Port, r.Replicas, r.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, svc)
+	}
+	return services, nil
+}
+
+// toDTO converts a domain project to DTO
+func (s *ProjectService) toDTO(proj *project.Project) *dto.ProjectResponse {
+	deploymentURL := projectDeploymentURL(s.baseDomain, proj, project.EnvironmentProduction)
 
 	// Construct database URL if database is required
 	databaseURL := ""
 	if proj.RequireDB() {
 		// Database name is based on project ID (sanitized)
 		dbName := fmt.Sprintf("proj_%s", proj.ID().String()[:8])
-		dbHost := os.Getenv("RDS_HOST")
-		dbPort := os.Getenv("RDS_PORT")
-		dbUser := os.Getenv("RDS_USER")
-		dbPassword := os.Getenv("RDS_PASSWORD")
-		
-		if dbHost != "" && dbPort != "" && dbUser != "" && dbPassword != "" {
+		db := s.database
+
+		if db.Host != "" && db.Port != "" && db.User != "" && db.Password != "" {
 			databaseURL = fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=require",
-				dbUser, dbPassword, dbHost, dbPort, dbName)
-		}
-	}
-
-	return &dto.ProjectResponse{
-		ID:               proj.ID().String(),
-		UserID:           proj.UserID().String(),
-		RepositoryURL:    proj.RepositoryURL().String(),
-		InstallCommand:   proj.InstallCommand().String(),
-		BuildCommand:     proj.BuildCommand().String(),
-		RunCommand:       proj.RunCommand().String(),
-		Language:         proj.Language().String(),
-		CustomDomain:     proj.CustomDomain().String(),
-		DeploymentURL:    deploymentURL,
-		RequireDB:        proj.RequireDB(),
-		MigrationCommand: proj.MigrationCommand().String(),
-		DatabaseURL:      databaseURL,
-		CreatedAt:        proj.CreatedAt().Format(time.RFC3339),
-		UpdatedAt:        proj.UpdatedAt().Format(time.RFC3339),
+				db.User, db.Password, db.Host, db.Port, dbName)
+		}
+	}
+
+	datastores := make([]string, 0, len(proj.Datastores()))
+	for _, d := range proj.Datastores() {
+		datastores = append(datastores, d.String())
+	}
+
+	services := make([]*dto.ServiceResponse, 0, len(proj.Services()))
+	for _, svc := range proj.Services() {
+		response := &dto.ServiceResponse{
+			Name:     svc.Name(),
+			Type:     svc.Type().String(),
+			Command:  svc.Command().String(),
+			Port:     svc.Port(),
+			Replicas: svc.Replicas(),
+			Schedule: svc.Schedule().String(),
+		}
+		if svc.Type().ServesTraffic() {
+			response.URL = subdomainURL(s.baseDomain, svc.Subdomain(proj.CustomDomain()))
+		}
+		services = append(services, response)
+	}
+
+	environments := make([]*dto.EnvironmentResponse, 0, len(proj.Environments()))
+	for _, env := range proj.Environments() {
+		environments = append(environments, &dto.EnvironmentResponse{
+			Name:      env.String(),
+			URL:       projectDeploymentURL(s.baseDomain, proj, env),
+			Protected: proj.IsProtected(env),
+		})
+	}
+
+	response := &dto.ProjectResponse{
+		ID:                  proj.ID().String(),
+		UserID:              proj.UserID().String(),
+		RepositoryURL:       proj.RepositoryURL().String(),
+		InstallCommand:      proj.InstallCommand().String(),
+		BuildCommand:        proj.BuildCommand().String(),
+		RunCommand:          proj.RunCommand().String(),
+		Language:            proj.Language().String(),
+		CustomDomain:        proj.CustomDomain().String(),
+		DeploymentURL:       deploymentURL,
+		RequireDB:           proj.RequireDB(),
+		MigrationCommand:    proj.MigrationCommand().String(),
+		DatabaseURL:         databaseURL,
+		Status:              proj.Status().String(),
+		StatusMessage:       proj.StatusMessage(),
+		ImageRetention:      proj.ImageRetention(),
+		Type:                proj.Type().String(),
+		Schedule:            proj.Schedule().String(),
+		OutputDirectory:     proj.OutputDirectory(),
+		DeploymentTarget:    proj.DeploymentTarget().String(),
+		LambdaEndpoint:      proj.LambdaEndpoint().String(),
+		PublicStatusPage:    proj.PublicStatusPage(),
+		BuildSize:           proj.BuildSize().String(),
+		BuildTimeoutMinutes: proj.BuildTimeoutMinutes(),
+		RedeployOnEnvChange: proj.RedeployOnEnvChange(),
+		DeploymentStrategy:  proj.DeploymentStrategy().String(),
+		CanaryPercent:       proj.CanaryPercent(),
+		CanaryBakeMinutes:   proj.CanaryBakeMinutes(),
+		Datastores:          datastores,
+		VolumeMountPath:     proj.VolumeMountPath(),
+		VolumeSizeGB:        proj.VolumeSizeGB(),
+		Services:            services,
+		Environments:        environments,
+		Port:                proj.Port(),
+		HealthCheckPath:     proj.HealthCheckPath(),
+		CPU:                 proj.CPU(),
+		Memory:              proj.Memory(),
+		CreatedAt:           proj.CreatedAt().Format(time.RFC3339),
+		UpdatedAt:           proj.UpdatedAt().Format(time.RFC3339),
+	}
+	if proj.DeletedAt() != nil {
+		response.DeletedAt = proj.DeletedAt().Format(time.RFC3339)
+	}
+
+	return response
+}
+
+// toDeployRulesDTO converts a project's deploy rules to DTO
+func toDeployRulesDTO(proj *project.Project) *dto.DeployRulesResponse {
+	rules := make([]*dto.DeployRuleResponse, 0, len(proj.DeployRules()))
+	for _, rule := range proj.DeployRules() {
+		rules = append(rules, &dto.DeployRuleResponse{
+			Branch:      rule.Branch(),
+			Environment: rule.Environment().String(),
+		})
 	}
+	return &dto.DeployRulesResponse{ProjectID: proj.ID().String(), Rules: rules}
 }
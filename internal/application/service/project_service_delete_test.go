@@ -0,0 +1,118 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// mockTeardown removes the cloud resources of projects, failing for those in failFor
+type mockTeardown struct {
+	service.InfrastructureTeardown
+	tornDown []project.ProjectID
+	failFor  map[project.ProjectID]bool
+}
+
+func (m *mockTeardown) TeardownProject(ctx context.Context, proj *project.Project, report func(message string)) error {
+	if m.failFor[proj.ID()] {
+		return errors.New("load balancer still in use")
+	}
+	m.tornDown = append(m.tornDown, proj.ID())
+	return nil
+}
+
+func TestProjectService_DeleteUserProjects(t *testing.T) {
+	owner := user.NewUserID()
+	owned := []*project.Project{newTestProject(t, owner, "shop"), newTestProject(t, owner, "blog")}
+	other := newTestProject(t, user.NewUserID(), "docs")
+	projects := newMockProjectRepository(other, owned[0], owned[1])
+	envVars := newMockEnvVarRepository()
+	for _, proj := range []*project.Project{other, owned[0], owned[1]} {
+		envVar, err := project.NewEnvironmentVariable(proj.ID(), project.EnvironmentProduction, "API_KEY", "secret", "")
+		if err != nil {
+			t.Fatalf("NewEnvironmentVariable() error = %v", err)
+		}
+		envVars.Save(context.Background(), envVar)
+	}
+	teardown := &mockTeardown{}
+
+	svc := service.NewProjectService(projects, envVars, mockUnitOfWork{})
+	svc.SetInfrastructureTeardown(teardown)
+
+	if err := svc.DeleteUserProjects(context.Background(), owner); err != nil {
+		t.Fatalf("DeleteUserProjects() error = %v", err)
+	}
+	if len(teardown.tornDown) != 2 || len(envVars.envVars) != 1 || !envVars.envVars[0].ProjectID().Equals(other.ID()) {
+		t.Errorf("tore down %d projects and kept %d environment variables, want 2 torn down and the other user's variable kept", len(teardown.tornDown), len(envVars.envVars))
+	}
+	if remaining, _ := projects.FindAll(context.Background(), 10, 0); len(remaining) != 1 || !remaining[0].ID().Equals(other.ID()) {
+		t.Errorf("remaining projects = %d, want only the other user's", len(remaining))
+	}
+}
+
+func TestProjectService_DeleteUserProjectsContinuesAfterFailure(t *testing.T) {
+	owner := user.NewUserID()
+	failing := newTestProject(t, owner, "shop")
+	projects := newMockProjectRepository(failing, newTestProject(t, owner, "blog"))
+	teardown := &mockTeardown{failFor: map[project.ProjectID]bool{failing.ID(): true}}
+
+	svc := service.NewProjectService(projects, newMockEnvVarRepository(), mockUnitOfWork{})
+	svc.SetInfrastructureTeardown(teardown)
+
+	if err := svc.DeleteUserProjects(context.Background(), owner); err == nil {
+		t.Fatal("DeleteUserProjects() error = nil, want the failed teardown")
+	}
+	if remaining, _ := projects.FindAll(context.Background(), 10, 0); len(remaining) != 1 || !remaining[0].ID().Equals(failing.ID()) {
+		t.Errorf("remaining projects = %d, want only the one that failed", len(remaining))
+	}
+	if failing.Status() != project.StatusDeleteFailed {
+		t.Errorf("Status() = %v, want %v", failing.Status(), project.StatusDeleteFailed)
+	}
+}
+
+func TestProjectService_ResumeTeardowns(t *testing.T) {
+	owner := user.NewUserID()
+	stalled, failing := newTestProject(t, owner, "shop"), newTestProject(t, owner, "blog")
+	for _, proj := range []*project.Project{stalled, failing} {
+		if err := proj.MarkDeleting(); err != nil {
+			t.Fatalf("MarkDeleting() error = %v", err)
+		}
+	}
+	projects := newMockProjectRepository(stalled, failing)
+	teardown := &mockTeardown{failFor: map[project.ProjectID]bool{failing.ID(): true}}
+
+	svc := service.NewProjectService(projects, newMockEnvVarRepository(), mockUnitOfWork{})
+	svc.SetInfrastructureTeardown(teardown)
+
+	resumed, err := svc.ResumeTeardowns(context.Background())
+	if err != nil {
+		t.Fatalf("ResumeTeardowns() error = %v", err)
+	}
+	if resumed != 2 {
+		t.Errorf("ResumeTeardowns() = %d, want both stalled teardowns resumed", resumed)
+	}
+	if remaining, _ := projects.FindAll(context.Background(), 10, 0); len(remaining) != 1 || !remaining[0].ID().Equals(failing.ID()) {
+		t.Errorf("remaining projects = %d, want only the one that failed again", len(remaining))
+	}
+	if failing.Status() != project.StatusDeleteFailed {
+		t.Errorf("Status() = %v, want %v so the user can retry", failing.Status(), project.StatusDeleteFailed)
+	}
+}
+
+func TestProjectService_DeleteProjectBeingTornDown(t *testing.T) {
+	owner := user.NewUserID()
+	proj := newTestProject(t, owner, "shop")
+	if err := proj.MarkDeleting(); err != nil {
+		t.Fatalf("MarkDeleting() error = %v", err)
+	}
+	svc := service.NewProjectService(newMockProjectRepository(proj), newMockEnvVarRepository(), mockUnitOfWork{})
+
+	// The teardown just started, so it isn't run a second time
+	if _, err := svc.DeleteProject(context.Background(), proj.ID().String(), owner.String()); !errors.Is(err, project.ErrProjectDeleting) {
+		t.Errorf("DeleteProject() error = %v, want %v", err, project.ErrProjectDeleting)
+	}
+}
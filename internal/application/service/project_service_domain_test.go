@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestProjectService_CreateProjectDomainTaken(t *testing.T) {
+	ctx := context.Background()
+	projects := newMockProjectRepository(newTestProject(t, user.NewUserID(), "shop", withEnvironments("staging")))
+	svc := service.NewProjectService(projects, nil, nil)
+
+	tests := []struct {
+		name         string
+		domain       string
+		environments []string
+		wantErr      error
+	}{
+		{name: "same domain", domain: "shop", wantErr: project.ErrCustomDomainTaken},
+		{name: "domain of an environment", domain: "shop-staging", wantErr: project.ErrCustomDomainTaken},
+		{name: "environment on a domain", domain: "my", environments: []string{"shop"}},
+		{name: "available", domain: "shop-preview"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateProject(ctx, user.NewUserID().String(), &dto.CreateProjectRequest{
+				RepositoryURL:  "https://github.com/acme/" + tt.domain,
+				InstallCommand: "npm ci",
+				RunCommand:     "npm start",
+				Language:       "NODE",
+				CustomDomain:   tt.domain,
+				Environments:   tt.environments,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateProject() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProjectService_UpdateProjectKeepsOwnDomain(t *testing.T) {
+	ctx := context.Background()
+	proj := newTestProject(t, user.NewUserID(), "shop", withEnvironments("staging"))
+	projects := newMockProjectRepository(proj, newTestProject(t, user.NewUserID(), "blog"))
+	svc := service.NewProjectService(projects, nil, nil)
+
+	update := func(domain string) error {
+		_, err := svc.UpdateProject(ctx, proj.ID().String(), proj.UserID().String(), &dto.UpdateProjectRequest{
+			RepositoryURL:  proj.RepositoryURL().String(),
+			InstallCommand: "npm ci",
+			RunCommand:     "npm start",
+			Language:       "NODE",
+			CustomDomain:   domain,
+			Environments:   []string{"staging"},
+		})
+		return err
+	}
+
+	if err := update("shop"); err != nil {
+		t.Errorf("UpdateProject() keeping its own domain error = %v", err)
+	}
+	if err := update("blog"); !errors.Is(err, project.ErrCustomDomainTaken) {
+		t.Errorf("UpdateProject() to another project's domain error = %v, want ErrCustomDomainTaken", err)
+	}
+}
+
+func TestProjectService_CheckDomain(t *testing.T) {
+	ctx := context.Background()
+	projects := newMockProjectRepository(newTestProject(t, user.NewUserID(), "shop", withEnvironments("staging")))
+	svc := service.NewProjectService(projects, nil, nil)
+	svc.SetValidationSources(nil, &mockDomainRecords{records: map[string]bool{"legacy": true}})
+
+	tests := []struct {
+		name          string
+		wantAvailable bool
+	}{
+		{name: "my-app", wantAvailable: true},
+		{name: "Shop-Preview", wantAvailable: true},
+		{name: "shop"},
+		{name: "shop-staging"},
+		{name: "legacy"},
+		{name: "admin"},
+		{name: "-invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.CheckDomain(ctx, tt.name)
+			if err != nil {
+				t.Fatalf("CheckDomain() error = %v", err)
+			}
+			if got.Available != tt.wantAvailable || (got.Reason == "") != tt.wantAvailable {
+				t.Errorf("CheckDomain(%q) = %+v, want available %v", tt.name, got, tt.wantAvailable)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// mockEnvVarCopier records the projects environment variables are copied between, or fails with err
+type mockEnvVarCopier struct {
+	from, to *project.Project
+	err      error
+}
+
+func (m *mockEnvVarCopier) CopyEnvVars(ctx context.Context, from, to *project.Project) (int, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	m.from, m.to = from, to
+	return 3, nil
+}
+
+// mockDuplicateDeployer records the projects it deploys, or fails with err
+type mockDuplicateDeployer struct {
+	deployed []*project.Project
+	err      error
+}
+
+func (m *mockDuplicateDeployer) DeployDuplicate(ctx context.Context, source, duplicate *project.Project, userID user.UserID) (*dto.DeploymentResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.deployed = append(m.deployed, duplicate)
+	return &dto.DeploymentResponse{ID: "deployment", ProjectID: duplicate.ID().String()}, nil
+}
+
+func TestProjectService_DuplicateProject(t *testing.T) {
+	ctx := context.Background()
+	source := newTestProject(t, user.NewUserID(), "shop", withEnvironments("staging"))
+	projects := newMockProjectRepository(source)
+	copier := &mockEnvVarCopier{}
+	deployer := &mockDuplicateDeployer{}
+	svc := service.NewProjectService(projects, nil, mockUnitOfWork{})
+	svc.SetDuplication(copier, deployer)
+
+	response, err := svc.DuplicateProject(ctx, source.ID().String(), source.UserID().String(), &dto.DuplicateProjectRequest{CustomDomain: "shop-copy", Deploy: true})
+	if err != nil {
+		t.Fatalf("DuplicateProject() error = %v", err)
+	}
+
+	if len(projects.saved) != 1 {
+		t.Fatalf("saved %d projects, want 1", len(projects.saved))
+	}
+	duplicate := projects.saved[0]
+	if duplicate.ID().Equals(source.ID()) || duplicate.CustomDomain().String() != "shop-copy" {
+		t.Errorf("saved project %s on %s, want a new project on shop-copy", duplicate.ID(), duplicate.CustomDomain())
+	}
+	if response.Project.ID != duplicate.ID().String() || response.Project.RepositoryURL != source.RepositoryURL().String() {
+		t.Errorf("Project = %+v, want the copy of the source", response.Project)
+	}
+	if copier.from != source || copier.to != duplicate || response.EnvVarsCopied != 3 {
+		t.Errorf("environment variables copied from %v to %v (%d), want from the source to the copy", copier.from, copier.to, response.EnvVarsCopied)
+	}
+	if len(deployer.deployed) != 1 || response.Deployment == nil || response.DeploymentError != "" {
+		t.Errorf("deployment = %+v (%q), want the copy deployed", response.Deployment, response.DeploymentError)
+	}
+}
+
+func TestProjectService_DuplicateProjectReportsDeploymentError(t *testing.T) {
+	ctx := context.Background()
+	source := newTestProject(t, user.NewUserID(), "shop")
+	projects := newMockProjectRepository(source)
+	svc := service.NewProjectService(projects, nil, mockUnitOfWork{})
+	svc.SetDuplication(&mockEnvVarCopier{}, &mockDuplicateDeployer{err: deployment.ErrNothingToDeploy})
+
+	response, err := svc.DuplicateProject(ctx, source.ID().String(), source.UserID().String(), &dto.DuplicateProjectRequest{Deploy: true})
+	if err != nil {
+		t.Fatalf("DuplicateProject() error = %v", err)
+	}
+
+	// The copy is kept without a deployment
+	if len(projects.saved) != 1 || response.Deployment != nil || response.DeploymentError == "" {
+		t.Errorf("saved %d projects, deployment %+v (%q), want the copy kept and the error reported", len(projects.saved), response.Deployment, response.DeploymentError)
+	}
+	if response.Project.CustomDomain == "" || response.Project.CustomDomain == "shop" {
+		t.Errorf("CustomDomain = %q, want a generated one", response.Project.CustomDomain)
+	}
+}
+
+func TestProjectService_DuplicateProjectErrors(t *testing.T) {
+	ctx := context.Background()
+	source := newTestProject(t, user.NewUserID(), "shop")
+	other := newTestProject(t, user.NewUserID(), "blog")
+
+	tests := []struct {
+		name    string
+		userID  string
+		domain  string
+		copier  *mockEnvVarCopier
+		wantErr error
+	}{
+		{name: "other user's project", userID: user.NewUserID().String(), copier: &mockEnvVarCopier{}, wantErr: project.ErrUnauthorized},
+		{name: "domain taken", userID: source.UserID().String(), domain: "blog", copier: &mockEnvVarCopier{}, wantErr: project.ErrCustomDomainTaken},
+		{name: "copy fails", userID: source.UserID().String(), copier: &mockEnvVarCopier{err: errors.New("decryption failed")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projects := newMockProjectRepository(source, other)
+			deployer := &mockDuplicateDeployer{}
+			svc := service.NewProjectService(projects, nil, mockUnitOfWork{})
+			svc.SetDuplication(tt.copier, deployer)
+
+			_, err := svc.DuplicateProject(ctx, source.ID().String(), tt.userID, &dto.DuplicateProjectRequest{CustomDomain: tt.domain, Deploy: true})
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("DuplicateProject() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(deployer.deployed) != 0 {
+				t.Errorf("deployed %d projects, want none", len(deployer.deployed))
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// mockRepositoryBranches lists the same branches for every repository, or fails with err
+type mockRepositoryBranches struct {
+	branches []string
+	err      error
+}
+
+func (m *mockRepositoryBranches) ListRepositoryBranches(ctx context.Context, proj *project.Project) ([]string, error) {
+	return m.branches, m.err
+}
+
+// mockDomainRecords knows of the DNS records of the platform's domain
+type mockDomainRecords struct {
+	records map[string]bool
+}
+
+func (m *mockDomainRecords) DomainRecordExists(ctx context.Context, subdomain string) (bool, error) {
+	return m.records[subdomain], nil
+}
+
+func TestProjectService_ValidateProject(t *testing.T) {
+	ctx := context.Background()
+	ownerID := user.NewUserID()
+	owner := ownerID.String()
+
+	projects := newMockProjectRepository(
+		newTestProject(t, ownerID, "existing"),
+		newTestProject(t, user.NewUserID(), "taken", withEnvironments("staging")),
+		newTestProject(t, user.NewUserID(), "acme-app-preview"),
+	)
+	branches := &mockRepositoryBranches{branches: []string{"main", "develop"}}
+	svc := service.NewProjectService(projects, nil, nil)
+	svc.SetValidationSources(branches, &mockDomainRecords{records: map[string]bool{"legacy": true}})
+
+	valid := func() *dto.ValidateProjectRequest {
+		return &dto.ValidateProjectRequest{
+			CreateProjectRequest: dto.CreateProjectRequest{
+				RepositoryURL:  "https://github.com/acme/app",
+				InstallCommand: "npm ci",
+				RunCommand:     "npm start",
+				Language:       "NODE",
+				CustomDomain:   "acme-app",
+			},
+			Branch: "main",
+			Port:   8080,
+		}
+	}
+
+	fields := func(errs []dto.FieldError) []string {
+		names := make([]string, len(errs))
+		for i, e := range errs {
+			names[i] = e.Field
+		}
+		return names
+	}
+
+	tests := []struct {
+		name       string
+		modify     func(req *dto.ValidateProjectRequest)
+		wantFields []string
+	}{
+		{name: "valid", modify: func(req *dto.ValidateProjectRequest) {}},
+		{name: "existing repository", modify: func(req *dto.ValidateProjectRequest) {
+			req.RepositoryURL = "https://github.com/acme/existing"
+		}, wantFields: []string{"repository_url"}},
+		{name: "missing branch", modify: func(req *dto.ValidateProjectRequest) {
+			req.Branch = "release"
+		}, wantFields: []string{"branch"}},
+		{name: "foreign commands", modify: func(req *dto.ValidateProjectRequest) {
+			req.InstallCommand = "pip install -r requirements.txt"
+			req.Services = []dto.ServiceRequest{{Name: "worker", Type: "WORKER", Command: "python worker.py"}}
+		}, wantFields: []string{"install_command", "services[0].command"}},
+		{name: "invalid port", modify: func(req *dto.ValidateProjectRequest) {
+			req.Port = 70000
+		}, wantFields: []string{"port"}},
+		{name: "domain of another project", modify: func(req *dto.ValidateProjectRequest) {
+			req.CustomDomain = "taken"
+		}, wantFields: []string{"custom_domain"}},
+		{name: "domain of another project's environment", modify: func(req *dto.ValidateProjectRequest) {
+			req.CustomDomain = "taken-staging"
+		}, wantFields: []string{"custom_domain"}},
+		{name: "environment on another project's domain", modify: func(req *dto.ValidateProjectRequest) {
+			req.Environments = []string{"preview"}
+		}, wantFields: []string{"custom_domain"}},
+		{name: "domain with a DNS record", modify: func(req *dto.ValidateProjectRequest) {
+			req.CustomDomain = "legacy"
+		}, wantFields: []string{"custom_domain"}},
+		{name: "invalid settings", modify: func(req *dto.ValidateProjectRequest) {
+			req.Type = "CRON"
+			req.DeploymentStrategy = "CANARY"
+		}, wantFields: []string{"schedule", "deployment_strategy"}},
+		{name: "every problem at once", modify: func(req *dto.ValidateProjectRequest) {
+			req.Language = "RUBY"
+			req.CustomDomain = "admin"
+			req.Port = -1
+		}, wantFields: []string{"language", "port", "custom_domain"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(req)
+
+			got, err := svc.ValidateProject(ctx, owner, req)
+			if err != nil {
+				t.Fatalf("ValidateProject() error = %v", err)
+			}
+			if got.Valid != (len(tt.wantFields) == 0) {
+				t.Errorf("valid = %v with errors %v", got.Valid, got.Errors)
+			}
+			gotFields := fields(got.Errors)
+			if len(gotFields) != len(tt.wantFields) {
+				t.Fatalf("errors = %v, want errors for %v", got.Errors, tt.wantFields)
+			}
+			for i := range gotFields {
+				if gotFields[i] != tt.wantFields[i] {
+					t.Errorf("errors = %v, want errors for %v", got.Errors, tt.wantFields)
+				}
+			}
+		})
+	}
+
+	t.Run("unreadable repository", func(t *testing.T) {
+		branches.err = errors.New("404 Not Found")
+		defer func() { branches.err = nil }()
+
+		got, err := svc.ValidateProject(ctx, owner, valid())
+		if err != nil {
+			t.Fatalf("ValidateProject() error = %v", err)
+		}
+		if got.Valid || len(got.Errors) != 1 || got.Errors[0].Field != "repository_url" {
+			t.Errorf("errors = %v, want the repository to be reported unreadable", got.Errors)
+		}
+	})
+
+	t.Run("unsupported provider", func(t *testing.T) {
+		branches.err = service.ErrUnsupportedGitProvider
+		defer func() { branches.err = nil }()
+
+		got, err := svc.ValidateProject(ctx, owner, valid())
+		if err != nil {
+			t.Fatalf("ValidateProject() error = %v", err)
+		}
+		if !got.Valid || len(got.Warnings) != 1 || got.Warnings[0].Field != "repository_url" {
+			t.Errorf("valid = %v, warnings = %v, want a valid configuration warning access wasn't checked", got.Valid, got.Warnings)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/github"
+)
+
+// PushDeployer creates the deployments triggered by pushes
+type PushDeployer interface {
+	CreateDeployment(ctx context.Context, userID string, req *dto.CreateDeploymentRequest) (*dto.DeploymentResponse, error)
+}
+
+// PushDeployService deploys the projects of a repository when branches matching their deploy rules are pushed to
+type PushDeployService struct {
+	projectRepo      project.ProjectRepository
+	installationRepo repo.InstallationRepo
+	deployer         PushDeployer
+}
+
+// NewPushDeployService creates a new push deploy service
+func NewPushDeployService(projectRepo project.ProjectRepository, installationRepo repo.InstallationRepo, deployer PushDeployer) *PushDeployService {
+	return &PushDeployService{
+		projectRepo:      projectRepo,
+		installationRepo: installationRepo,
+		deployer:         deployer,
+	}
+}
+
+// HandlePushEvent deploys the pushed commit to every project of the repository with a deploy rule matching the
+// branch. Only projects of users who claimed the installation the push came through are deployed, the same
+// users whose builds can clone the repository. A deployment that fails to be created doesn't stop the others.
+func (s *PushDeployService) HandlePushEvent(ctx context.Context, event *github.PushEvent) error {
+	branch, ok := event.Branch()
+	if !ok || event.Deleted {
+		return nil
+	}
+
+	repoURL, err := project.NewRepositoryURL(event.Repository.HTMLURL)
+	if err != nil {
+		return fmt.Errorf("invalid repository URL: %w", err)
+	}
+
+	projects, err := s.projectRepo.FindAllByRepositoryURL(ctx, repoURL)
+	if err != nil {
+		return fmt.Errorf("failed to find projects of repository: %w", err)
+	}
+	if len(projects) == 0 {
+		return nil
+	}
+
+	installation, err := s.installationRepo.FindByID(ctx, event.Installation.ID)
+	if err != nil {
+		if isInstallationNotFound(err) {
+			slog.InfoContext(ctx, "Ignoring push through unclaimed installation", "repository", event.Repository.FullName)
+			return nil
+		}
+		return fmt.Errorf("failed to get installation: %w", err)
+	}
+	if installation.IsSuspended() {
+		return nil
+	}
+
+	for _, proj := range projects {
+		if proj.IsDeleting() || !installation.BelongsToUser(proj.UserID()) {
+			continue
+		}
+		env, ok := proj.DeployEnvironmentFor(branch)
+		if !ok {
+			continue
+		}
+
+		_, err := s.deployer.CreateDeployment(ctx, proj.UserID().String(), &dto.CreateDeploymentRequest{
+			ProjectID:   proj.ID().String(),
+			CommitHash:  event.After,
+			Branch:      branch,
+			Environment: env.String(),
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to deploy push", "project_id", proj.ID().String(), "branch", branch, "environment", env.String(), "error", err)
+			continue
+		}
+		slog.InfoContext(ctx, "Deploying push", "project_id", proj.ID().String(), "branch", branch, "environment", env.String(), "commit", event.After)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/domain/user"
+	"snapdeploy-core/internal/github"
+)
+
+// mockPushDeployer records the deployments it is asked to create
+type mockPushDeployer struct {
+	requests []*dto.CreateDeploymentRequest
+}
+
+func (m *mockPushDeployer) CreateDeployment(ctx context.Context, userID string, req *dto.CreateDeploymentRequest) (*dto.DeploymentResponse, error) {
+	m.requests = append(m.requests, req)
+	return &dto.DeploymentResponse{}, nil
+}
+
+func TestPushDeployService_HandlePushEvent(t *testing.T) {
+	ctx := context.Background()
+	owner, other := user.NewUserID(), user.NewUserID()
+
+	installations := newMockInstallationRepo()
+	installations.installations[42] = repo.ReconstituteInstallation(42, 5000, "acme", repo.AccountTypeOrganization, 1001, &owner, false, time.Now(), time.Now())
+
+	newRuledProject := func(userID user.UserID, rules ...[2]string) *project.Project {
+		return newTestProject(t, userID, "my-app", withEnvironments("staging"), func(proj *project.Project) error {
+			deployRules := make([]project.DeployRule, 0, len(rules))
+			for _, r := range rules {
+				rule, err := project.NewDeployRule(r[0], r[1])
+				if err != nil {
+					return err
+				}
+				deployRules = append(deployRules, rule)
+			}
+			return proj.SetDeployRules(deployRules)
+		})
+	}
+
+	ruled := newRuledProject(owner, [2]string{"main", "production"}, [2]string{"release/*", "staging"})
+	unruled := newRuledProject(owner)
+	// Projects of users who didn't claim the installation aren't deployed, whatever their rules
+	foreign := newRuledProject(other, [2]string{"*", "production"})
+
+	deployer := &mockPushDeployer{}
+	svc := service.NewPushDeployService(newMockProjectRepository(ruled, unruled, foreign), installations, deployer)
+
+	push := func(ref string, deleted bool) *github.PushEvent {
+		event := &github.PushEvent{Ref: ref, After: "0123456789abcdef0123456789abcdef01234567", Deleted: deleted}
+		event.Repository.HTMLURL = "https://github.com/acme/my-app"
+		event.Installation.ID = 42
+		return event
+	}
+
+	tests := []struct {
+		name  string
+		event *github.PushEvent
+		want  string // Environment the ruled project is deployed to, empty for none
+	}{
+		{"exact branch", push("refs/heads/main", false), "production"},
+		{"pattern", push("refs/heads/release/2.0", false), "staging"},
+		{"unmatched branch", push("refs/heads/feature/login", false), ""},
+		{"deleted branch", push("refs/heads/main", true), ""},
+		{"tag", push("refs/tags/v1.0.0", false), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployer.requests = nil
+			if err := svc.HandlePushEvent(ctx, tt.event); err != nil {
+				t.Fatalf("HandlePushEvent() error = %v", err)
+			}
+
+			if tt.want == "" {
+				if len(deployer.requests) != 0 {
+					t.Errorf("deployments = %+v, want none", deployer.requests)
+				}
+				return
+			}
+			if len(deployer.requests) != 1 {
+				t.Fatalf("deployments = %+v, want one for the ruled project", deployer.requests)
+			}
+			req := deployer.requests[0]
+			if req.ProjectID != ruled.ID().String() || req.Environment != tt.want || req.CommitHash != tt.event.After {
+				t.Errorf("deployment = %+v, want %s of project %s", req, tt.want, ruled.ID())
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/quota"
+	"snapdeploy-core/internal/domain/usage"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// QuotaService enforces the limits of the plans users are on and reports them.
+// Limits are checked before work is admitted, so concurrent requests may go over a limit by a little.
+type QuotaService struct {
+	quotaRepo      quota.Repository
+	plans          quota.Plans
+	userRepo       user.Repository
+	projectRepo    project.ProjectRepository
+	deploymentRepo deployment.DeploymentRepository
+	envVarRepo     project.EnvironmentVariableRepository
+	usageRepo      usage.RecordRepository
+}
+
+// NewQuotaService creates a new quota service
+func NewQuotaService(
+	quotaRepo quota.Repository,
+	plans quota.Plans,
+	userRepo user.Repository,
+	projectRepo project.ProjectRepository,
+	deploymentRepo deployment.DeploymentRepository,
+	envVarRepo project.EnvironmentVariableRepository,
+	usageRepo usage.RecordRepository,
+) *QuotaService {
+	return &QuotaService{
+		quotaRepo:      quotaRepo,
+		plans:          plans,
+		userRepo:       userRepo,
+		projectRepo:    projectRepo,
+		deploymentRepo: deploymentRepo,
+		envVarRepo:     envVarRepo,
+		usageRepo:      usageRepo,
+	}
+}
+
+// CheckProjects returns an ExceededError if the user can't create another project
+func (s *QuotaService) CheckProjects(ctx context.Context, userID user.UserID) error {
+	limits, _, err := s.limits(ctx, userID)
+	if err != nil || limits.Projects == 0 {
+		return err
+	}
+
+	count, err := s.projectRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to count projects: %w", err)
+	}
+	return limits.Check(quota.ResourceProjects, float64(count))
+}
+
+// CheckBuilds returns an ExceededError if the user can't start another deployment, because too many are in
+// progress or the month's build minutes are used up
+func (s *QuotaService) CheckBuilds(ctx context.Context, userID user.UserID) error {
+	limits, _, err := s.limits(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if limits.ConcurrentBuilds > 0 {
+		inProgress, err := s.deploymentRepo.CountInProgressByUserID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to count deployments in progress: %w", err)
+		}
+		if err := limits.Check(quota.ResourceConcurrentBuilds, float64(inProgress)); err != nil {
+			return err
+		}
+	}
+
+	if limits.BuildMinutesPerMonth > 0 {
+		minutes, err := s.buildMinutesThisMonth(ctx, userID, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := limits.Check(quota.ResourceBuildMinutes, minutes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckEnvVars returns an ExceededError if the user can't add another environment variable to a project's
+// environment
+func (s *QuotaService) CheckEnvVars(ctx context.Context, userID user.UserID, projectID project.ProjectID, env project.Environment) error {
+	limits, _, err := s.limits(ctx, userID)
+	if err != nil || limits.EnvVars == 0 {
+		return err
+	}
+
+	count, err := s.envVarRepo.Count(ctx, projectID, env)
+	if err != nil {
+		return fmt.Errorf("failed to count environment variables: %w", err)
+	}
+	return limits.Check(quota.ResourceEnvVars, float64(count))
+}
+
+// GetUserLimits returns the plan a user is on with their limits and how much of each they use
+func (s *QuotaService) GetUserLimits(ctx context.Context, id string) (*dto.UserLimitsResponse, error) {
+	userID, err := user.ParseUserID(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	limits, q, err := s.limits(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := s.projectRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count projects: %w", err)
+	}
+	inProgress, err := s.deploymentRepo.CountInProgressByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count deployments in progress: %w", err)
+	}
+	buildMinutes, err := s.buildMinutesThisMonth(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	used := func(value float64) *float64 { return &value }
+	overrides := q.Overrides()
+	return &dto.UserLimitsResponse{
+		UserID: userID.String(),
+		Plan:   q.PlanName(s.plans),
+		Limits: []*dto.LimitUsage{
+			{Resource: quota.ResourceProjects.String(), Limit: limits.Projects, Used: used(float64(projects)), Overridden: overrides.Projects != nil},
+			{Resource: quota.ResourceConcurrentBuilds.String(), Limit: limits.ConcurrentBuilds, Used: used(float64(inProgress)), Overridden: overrides.ConcurrentBuilds != nil},
+			{Resource: quota.ResourceEnvVars.String(), Limit: limits.EnvVars, Overridden: overrides.EnvVars != nil},
+			{Resource: quota.ResourceBuildMinutes.String(), Limit: limits.BuildMinutesPerMonth, Used: used(buildMinutes), Overridden: overrides.BuildMinutesPerMonth != nil},
+		},
+	}, nil
+}
+
+// SetUserLimits puts a user on a plan and overrides some of its limits, replacing what was set for them before
+func (s *QuotaService) SetUserLimits(ctx context.Context, id string, req *dto.UpdateUserLimitsRequest) (*dto.UserLimitsResponse, error) {
+	userID, err := user.ParseUserID(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	q, err := s.quotaRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if q == nil {
+		q = quota.NewQuota(userID)
+	}
+
+	if err := q.Assign(s.plans, req.Plan); err != nil {
+		return nil, err
+	}
+	err = q.Override(quota.Overrides{
+		Projects:             req.Projects,
+		ConcurrentBuilds:     req.ConcurrentBuilds,
+		EnvVars:              req.EnvVars,
+		BuildMinutesPerMonth: req.BuildMinutesPerMonth,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.quotaRepo.Save(ctx, q); err != nil {
+		return nil, err
+	}
+
+	return s.GetUserLimits(ctx, id)
+}
+
+// AssignPlan puts a user on a plan, the default plan for an empty name, keeping the limits overridden for them
+func (s *QuotaService) AssignPlan(ctx context.Context, userID user.UserID, plan string) error {
+	q, err := s.quotaRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if q == nil {
+		q = quota.NewQuota(userID)
+	}
+
+	if err := q.Assign(s.plans, plan); err != nil {
+		return err
+	}
+	return s.quotaRepo.Save(ctx, q)
+}
+
+// limits returns the limits of a user and their quota, a new one on the default plan if none was saved
+func (s *QuotaService) limits(ctx context.Context, userID user.UserID) (quota.Limits, *quota.Quota, error) {
+	q, err := s.quotaRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return quota.Limits{}, nil, err
+	}
+	if q == nil {
+		q = quota.NewQuota(userID)
+	}
+
+	limits, err := q.Limits(s.plans)
+	if err != nil {
+		return quota.Limits{}, nil, err
+	}
+	return limits, q, nil
+}
+
+// buildMinutesThisMonth sums the build minutes a user's projects used since the start of the month (UTC)
+func (s *QuotaService) buildMinutesThisMonth(ctx context.Context, userID user.UserID, now time.Time) (float64, error) {
+	now = now.UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	byProject, err := s.usageRepo.SumByUserID(ctx, userID, monthStart, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum build minutes: %w", err)
+	}
+
+	var minutes float64
+	for _, totals := range byProject {
+		minutes += totals[usage.MetricBuildMinutes]
+	}
+	return minutes, nil
+}
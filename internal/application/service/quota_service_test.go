@@ -0,0 +1,215 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"fmt"
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/quota"
+	"snapdeploy-core/internal/domain/usage"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// mockQuotas holds the quotas saved for users
+type mockQuotas struct {
+	quotas map[string]*quota.Quota
+}
+
+func (m *mockQuotas) FindByUserID(ctx context.Context, userID user.UserID) (*quota.Quota, error) {
+	return m.quotas[userID.String()], nil
+}
+
+func (m *mockQuotas) Save(ctx context.Context, q *quota.Quota) error {
+	m.quotas[q.UserID().String()] = q
+	return nil
+}
+
+// mockQuotaUsage reports the same usage totals for every period, split over two projects
+type mockQuotaUsage struct {
+	usage.RecordRepository
+	buildMinutes float64
+	from         time.Time
+}
+
+func (m *mockQuotaUsage) SumByUserID(ctx context.Context, userID user.UserID, from, to time.Time) (map[string]usage.Totals, error) {
+	m.from = from
+	return map[string]usage.Totals{
+		"a": {usage.MetricBuildMinutes: m.buildMinutes / 2, usage.MetricVCPUHours: 10},
+		"b": {usage.MetricBuildMinutes: m.buildMinutes / 2},
+	}, nil
+}
+
+var quotaTestPlans = quota.Plans{
+	Default: "free",
+	Limits: map[string]quota.Limits{
+		"free": {Projects: 3, ConcurrentBuilds: 1, EnvVars: 20, BuildMinutesPerMonth: 100},
+		"pro":  {Projects: 25, ConcurrentBuilds: 4},
+	},
+}
+
+type quotaTestFixture struct {
+	svc         *service.QuotaService
+	quotas      *mockQuotas
+	users       *mockUserRepository
+	projects    *mockProjectRepository
+	deployments *mockDeploymentRepository
+	envVars     *mockEnvVarRepository
+	usage       *mockQuotaUsage
+}
+
+func newQuotaTestFixture() *quotaTestFixture {
+	f := &quotaTestFixture{
+		quotas:      &mockQuotas{quotas: make(map[string]*quota.Quota)},
+		users:       newMockUserRepository(),
+		projects:    newMockProjectRepository(),
+		deployments: newMockDeploymentRepository(),
+		envVars:     newMockEnvVarRepository(),
+		usage:       &mockQuotaUsage{},
+	}
+	f.svc = service.NewQuotaService(f.quotas, quotaTestPlans, f.users, f.projects, f.deployments, f.envVars, f.usage)
+	return f
+}
+
+// addProjects gives the user n more projects
+func (f *quotaTestFixture) addProjects(t *testing.T, userID user.UserID, n int) {
+	t.Helper()
+	for range n {
+		f.projects.projects = append(f.projects.projects, newTestProject(t, userID, fmt.Sprintf("app-%d", len(f.projects.projects))))
+	}
+}
+
+func TestQuotaService_Checks(t *testing.T) {
+	ctx := context.Background()
+	userID := user.NewUserID()
+	proj := newTestProject(t, userID, "my-app")
+
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, f *quotaTestFixture)
+		check    func(svc *service.QuotaService) error
+		resource quota.Resource // Empty when the check passes
+	}{
+		{
+			name:  "project below limit",
+			setup: func(t *testing.T, f *quotaTestFixture) { f.addProjects(t, userID, 2) },
+			check: func(svc *service.QuotaService) error { return svc.CheckProjects(ctx, userID) },
+		},
+		{
+			name:     "project limit reached",
+			setup:    func(t *testing.T, f *quotaTestFixture) { f.addProjects(t, userID, 3) },
+			check:    func(svc *service.QuotaService) error { return svc.CheckProjects(ctx, userID) },
+			resource: quota.ResourceProjects,
+		},
+		{
+			name: "build in progress",
+			setup: func(t *testing.T, f *quotaTestFixture) {
+				f.deployments.deployments = append(f.deployments.deployments, newTestDeployment(t, proj, project.EnvironmentProduction, deployment.StatusBuilding))
+			},
+			check:    func(svc *service.QuotaService) error { return svc.CheckBuilds(ctx, userID) },
+			resource: quota.ResourceConcurrentBuilds,
+		},
+		{
+			name:     "build minutes used up",
+			setup:    func(t *testing.T, f *quotaTestFixture) { f.usage.buildMinutes = 100 },
+			check:    func(svc *service.QuotaService) error { return svc.CheckBuilds(ctx, userID) },
+			resource: quota.ResourceBuildMinutes,
+		},
+		{
+			name: "environment variable limit reached",
+			setup: func(t *testing.T, f *quotaTestFixture) {
+				for i := range 20 {
+					envVar, err := project.NewEnvironmentVariable(proj.ID(), project.EnvironmentProduction, fmt.Sprintf("KEY_%d", i), "value", "")
+					if err != nil {
+						t.Fatalf("NewEnvironmentVariable() error = %v", err)
+					}
+					f.envVars.envVars = append(f.envVars.envVars, envVar)
+				}
+			},
+			check: func(svc *service.QuotaService) error {
+				return svc.CheckEnvVars(ctx, userID, proj.ID(), project.EnvironmentProduction)
+			},
+			resource: quota.ResourceEnvVars,
+		},
+		{
+			name: "plan without the limit",
+			setup: func(t *testing.T, f *quotaTestFixture) {
+				q := quota.NewQuota(userID)
+				_ = q.Assign(quotaTestPlans, "pro")
+				f.quotas.quotas[userID.String()] = q
+				f.usage.buildMinutes = 10000
+			},
+			check: func(svc *service.QuotaService) error { return svc.CheckBuilds(ctx, userID) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newQuotaTestFixture()
+			tt.setup(t, f)
+
+			err := tt.check(f.svc)
+			if tt.resource == "" {
+				if err != nil {
+					t.Fatalf("check error = %v, want nil", err)
+				}
+				return
+			}
+			var exceeded *quota.ExceededError
+			if !errors.As(err, &exceeded) || exceeded.Resource != tt.resource {
+				t.Fatalf("check error = %v, want the %s limit exceeded", err, tt.resource)
+			}
+		})
+	}
+}
+
+func TestQuotaService_SetUserLimits(t *testing.T) {
+	ctx := context.Background()
+	f := newQuotaTestFixture()
+	usr, _ := user.NewUser("test@example.com", "testuser", "user_123")
+	_ = f.users.Save(ctx, usr)
+	f.addProjects(t, usr.ID(), 4)
+	f.usage.buildMinutes = 30
+
+	projects := 50
+	response, err := f.svc.SetUserLimits(ctx, usr.ID().String(), &dto.UpdateUserLimitsRequest{Plan: "pro", Projects: &projects})
+	if err != nil {
+		t.Fatalf("SetUserLimits() error = %v", err)
+	}
+	if response.Plan != "pro" {
+		t.Errorf("Plan = %s, want pro", response.Plan)
+	}
+
+	want := map[string]dto.LimitUsage{
+		"projects":          {Limit: 50, Overridden: true},
+		"concurrent_builds": {Limit: 4},
+		"env_vars":          {Limit: 0},
+		"build_minutes":     {Limit: 0},
+	}
+	for _, limit := range response.Limits {
+		if limit.Limit != want[limit.Resource].Limit || limit.Overridden != want[limit.Resource].Overridden {
+			t.Errorf("%s = %d (overridden %v), want %+v", limit.Resource, limit.Limit, limit.Overridden, want[limit.Resource])
+		}
+		if limit.Resource == "build_minutes" && (limit.Used == nil || *limit.Used != 30) {
+			t.Errorf("build_minutes used = %v, want 30", limit.Used)
+		}
+	}
+	if f.usage.from.Day() != 1 || f.usage.from.Hour() != 0 {
+		t.Errorf("build minutes summed from %v, want the start of the month", f.usage.from)
+	}
+
+	// Going over the override is refused, the plan's limit no longer applies
+	f.addProjects(t, usr.ID(), 46)
+	if err := f.svc.CheckProjects(ctx, usr.ID()); !errors.Is(err, quota.ErrQuotaExceeded) {
+		t.Errorf("CheckProjects() error = %v, want %v", err, quota.ErrQuotaExceeded)
+	}
+
+	if _, err := f.svc.SetUserLimits(ctx, usr.ID().String(), &dto.UpdateUserLimitsRequest{Plan: "enterprise"}); !errors.Is(err, quota.ErrUnknownPlan) {
+		t.Errorf("SetUserLimits() error = %v, want %v", err, quota.ErrUnknownPlan)
+	}
+}
@@ -0,0 +1,647 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/domain/agent"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// In-memory repositories shared by the service tests. Records are kept in the order they were added,
+// the last one added being the newest. Methods no test needs yet panic, naming the method.
+
+var errRepository = errors.New("repository error")
+
+func notImplemented(method string) {
+	panic(method + " is not implemented by the in-memory repository, add it to repository_mocks_test.go")
+}
+
+// mockDeploymentRepository keeps deployments in memory
+type mockDeploymentRepository struct {
+	deployments []*deployment.Deployment
+	deletedAt   map[deployment.DeploymentID]time.Time
+	saved       []*deployment.Deployment // Deployments passed to Save, in order
+	filter      deployment.ListFilter    // Filter of the last list query
+	lists       int                      // Number of list queries
+	purges      int                      // Number of PurgeDeletedBefore calls
+	shouldError bool
+}
+
+func newMockDeploymentRepository(deployments ...*deployment.Deployment) *mockDeploymentRepository {
+	return &mockDeploymentRepository{
+		deployments: deployments,
+		deletedAt:   make(map[deployment.DeploymentID]time.Time),
+	}
+}
+
+func (m *mockDeploymentRepository) Save(ctx context.Context, dep *deployment.Deployment) error {
+	if m.shouldError {
+		return errRepository
+	}
+	m.saved = append(m.saved, dep)
+	if !slices.Contains(m.deployments, dep) {
+		m.deployments = append(m.deployments, dep)
+	}
+	return nil
+}
+
+func (m *mockDeploymentRepository) FindByID(ctx context.Context, id deployment.DeploymentID) (*deployment.Deployment, error) {
+	if _, deleted := m.deletedAt[id]; deleted {
+		return nil, deployment.ErrDeploymentNotFound
+	}
+	return m.FindByIDIncludingDeleted(ctx, id)
+}
+
+func (m *mockDeploymentRepository) FindByIDIncludingDeleted(ctx context.Context, id deployment.DeploymentID) (*deployment.Deployment, error) {
+	if m.shouldError {
+		return nil, errRepository
+	}
+	for _, dep := range m.deployments {
+		if dep.ID().Equals(id) {
+			return dep, nil
+		}
+	}
+	return nil, deployment.ErrDeploymentNotFound
+}
+
+func (m *mockDeploymentRepository) FindByProjectID(ctx context.Context, projectID project.ProjectID, filter deployment.ListFilter, limit, offset int32) ([]*deployment.Deployment, error) {
+	return m.list(filter, limit, offset, false, func(dep *deployment.Deployment) bool { return dep.BelongsToProject(projectID) })
+}
+
+func (m *mockDeploymentRepository) FindByProjectIDIncludingDeleted(ctx context.Context, projectID project.ProjectID, filter deployment.ListFilter, limit, offset int32) ([]*deployment.Deployment, error) {
+	return m.list(filter, limit, offset, true, func(dep *deployment.Deployment) bool { return dep.BelongsToProject(projectID) })
+}
+
+func (m *mockDeploymentRepository) FindByUserID(ctx context.Context, userID user.UserID, filter deployment.ListFilter, limit, offset int32) ([]*deployment.Deployment, error) {
+	return m.list(filter, limit, offset, false, func(dep *deployment.Deployment) bool { return dep.BelongsToUser(userID) })
+}
+
+func (m *mockDeploymentRepository) FindByProjectIDAfter(ctx context.Context, projectID project.ProjectID, filter deployment.ListFilter, after deployment.Cursor, limit int32) ([]*deployment.Deployment, error) {
+	notImplemented("FindByProjectIDAfter")
+	return nil, nil
+}
+
+func (m *mockDeploymentRepository) FindByProjectIDIncludingDeletedAfter(ctx context.Context, projectID project.ProjectID, filter deployment.ListFilter, after deployment.Cursor, limit int32) ([]*deployment.Deployment, error) {
+	notImplemented("FindByProjectIDIncludingDeletedAfter")
+	return nil, nil
+}
+
+func (m *mockDeploymentRepository) FindByUserIDAfter(ctx context.Context, userID user.UserID, filter deployment.ListFilter, after deployment.Cursor, limit int32) ([]*deployment.Deployment, error) {
+	notImplemented("FindByUserIDAfter")
+	return nil, nil
+}
+
+func (m *mockDeploymentRepository) CountByProjectID(ctx context.Context, projectID project.ProjectID, filter deployment.ListFilter) (int64, error) {
+	return m.count(filter, false, func(dep *deployment.Deployment) bool { return dep.BelongsToProject(projectID) })
+}
+
+func (m *mockDeploymentRepository) CountByProjectIDIncludingDeleted(ctx context.Context, projectID project.ProjectID, filter deployment.ListFilter) (int64, error) {
+	return m.count(filter, true, func(dep *deployment.Deployment) bool { return dep.BelongsToProject(projectID) })
+}
+
+func (m *mockDeploymentRepository) CountByUserID(ctx context.Context, userID user.UserID, filter deployment.ListFilter) (int64, error) {
+	return m.count(filter, false, func(dep *deployment.Deployment) bool { return dep.BelongsToUser(userID) })
+}
+
+func (m *mockDeploymentRepository) CountInProgressByUserID(ctx context.Context, userID user.UserID) (int64, error) {
+	inProgress := []deployment.DeploymentStatus{deployment.StatusPending, deployment.StatusBuilding, deployment.StatusDeploying, deployment.StatusInterrupted}
+	return m.count(deployment.ListFilter{}, false, func(dep *deployment.Deployment) bool {
+		return dep.BelongsToUser(userID) && slices.Contains(inProgress, dep.Status())
+	})
+}
+
+func (m *mockDeploymentRepository) Delete(ctx context.Context, id deployment.DeploymentID) error {
+	if _, err := m.FindByID(ctx, id); err != nil {
+		return err
+	}
+	m.deletedAt[id] = time.Now()
+	return nil
+}
+
+func (m *mockDeploymentRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time, limit int32) (int64, error) {
+	m.purges++
+	if m.shouldError {
+		return 0, errRepository
+	}
+	var purged int64
+	m.deployments = slices.DeleteFunc(m.deployments, func(dep *deployment.Deployment) bool {
+		deletedAt, deleted := m.deletedAt[dep.ID()]
+		if !deleted || !deletedAt.Before(cutoff) || purged == int64(limit) {
+			return false
+		}
+		delete(m.deletedAt, dep.ID())
+		purged++
+		return true
+	})
+	return purged, nil
+}
+
+func (m *mockDeploymentRepository) FindLatestByProjectID(ctx context.Context, projectID project.ProjectID) (*deployment.Deployment, error) {
+	return m.latest(func(dep *deployment.Deployment) bool { return dep.BelongsToProject(projectID) })
+}
+
+func (m *mockDeploymentRepository) FindLatestInEnvironment(ctx context.Context, projectID project.ProjectID, env project.Environment) (*deployment.Deployment, error) {
+	return m.latest(func(dep *deployment.Deployment) bool {
+		return dep.BelongsToProject(projectID) && dep.Environment() == env
+	})
+}
+
+func (m *mockDeploymentRepository) FindLatestDeployedInEnvironment(ctx context.Context, projectID project.ProjectID, env project.Environment) (*deployment.Deployment, error) {
+	return m.latest(func(dep *deployment.Deployment) bool {
+		return dep.BelongsToProject(projectID) && dep.Environment() == env && dep.Status() == deployment.StatusDeployed
+	})
+}
+
+func (m *mockDeploymentRepository) FindLatestDeployed(ctx context.Context) ([]*deployment.Deployment, error) {
+	if m.shouldError {
+		return nil, errRepository
+	}
+	var found []*deployment.Deployment
+	for _, dep := range m.deployments {
+		latest, err := m.FindLatestDeployedInEnvironment(ctx, dep.ProjectID(), dep.Environment())
+		if err == nil && latest == dep {
+			found = append(found, dep)
+		}
+	}
+	return found, nil
+}
+
+func (m *mockDeploymentRepository) FindPinnedByProjectID(ctx context.Context, projectID project.ProjectID) ([]*deployment.Deployment, error) {
+	if m.shouldError {
+		return nil, errRepository
+	}
+	return m.matching(deployment.ListFilter{}, false, func(dep *deployment.Deployment) bool {
+		return dep.BelongsToProject(projectID) && dep.IsPinned()
+	}), nil
+}
+
+func (m *mockDeploymentRepository) FindStuck(ctx context.Context, cutoff time.Time, limit int32) ([]*deployment.Deployment, error) {
+	notImplemented("FindStuck")
+	return nil, nil
+}
+
+func (m *mockDeploymentRepository) ArchiveBefore(ctx context.Context, cutoff time.Time, limit int32) (int64, error) {
+	notImplemented("ArchiveBefore")
+	return 0, nil
+}
+
+// matching returns the deployments the filter and match select, newest first unless the filter asks otherwise
+func (m *mockDeploymentRepository) matching(filter deployment.ListFilter, includeDeleted bool, match func(*deployment.Deployment) bool) []*deployment.Deployment {
+	var found []*deployment.Deployment
+	for _, dep := range m.deployments {
+		if _, deleted := m.deletedAt[dep.ID()]; deleted && !includeDeleted {
+			continue
+		}
+		if !match(dep) ||
+			(filter.Status != "" && dep.Status() != filter.Status) ||
+			(filter.Branch != "" && dep.Branch().String() != filter.Branch) ||
+			(!filter.Since.IsZero() && dep.CreatedAt().Before(filter.Since)) {
+			continue
+		}
+		labels := dep.Annotations().Labels
+		if !hasLabels(labels, filter.Labels) {
+			continue
+		}
+		found = append(found, dep)
+	}
+	if !filter.Ascending {
+		slices.Reverse(found)
+	}
+	return found
+}
+
+func (m *mockDeploymentRepository) list(filter deployment.ListFilter, limit, offset int32, includeDeleted bool, match func(*deployment.Deployment) bool) ([]*deployment.Deployment, error) {
+	m.lists++
+	m.filter = filter
+	if m.shouldError {
+		return nil, errRepository
+	}
+	return paginate(m.matching(filter, includeDeleted, match), limit, offset), nil
+}
+
+func (m *mockDeploymentRepository) count(filter deployment.ListFilter, includeDeleted bool, match func(*deployment.Deployment) bool) (int64, error) {
+	if m.shouldError {
+		return 0, errRepository
+	}
+	return int64(len(m.matching(filter, includeDeleted, match))), nil
+}
+
+func (m *mockDeploymentRepository) latest(match func(*deployment.Deployment) bool) (*deployment.Deployment, error) {
+	if m.shouldError {
+		return nil, errRepository
+	}
+	found := m.matching(deployment.ListFilter{}, false, match)
+	if len(found) == 0 {
+		return nil, deployment.ErrDeploymentNotFound
+	}
+	return found[0], nil
+}
+
+func hasLabels(labels, want map[string]string) bool {
+	for key, value := range want {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func paginate[T any](items []T, limit, offset int32) []T {
+	if int(offset) >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if len(items) > int(limit) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// mockProjectRepository keeps projects in memory
+type mockProjectRepository struct {
+	projects    []*project.Project
+	deletedAt   map[project.ProjectID]time.Time
+	claimed     map[project.ProjectID]bool // Projects whose stalled teardown was claimed
+	saved       []*project.Project         // Projects passed to Save, in order
+	shouldError bool
+}
+
+func newMockProjectRepository(projects ...*project.Project) *mockProjectRepository {
+	return &mockProjectRepository{
+		projects:  projects,
+		deletedAt: make(map[project.ProjectID]time.Time),
+		claimed:   make(map[project.ProjectID]bool),
+	}
+}
+
+func (m *mockProjectRepository) Save(ctx context.Context, proj *project.Project) error {
+	if m.shouldError {
+		return errRepository
+	}
+	m.saved = append(m.saved, proj)
+	if !slices.Contains(m.projects, proj) {
+		m.projects = append(m.projects, proj)
+	}
+	return nil
+}
+
+func (m *mockProjectRepository) FindByID(ctx context.Context, id project.ProjectID) (*project.Project, error) {
+	if _, deleted := m.deletedAt[id]; deleted {
+		return nil, project.ErrProjectNotFound
+	}
+	return m.FindByIDIncludingDeleted(ctx, id)
+}
+
+func (m *mockProjectRepository) FindByIDIncludingDeleted(ctx context.Context, id project.ProjectID) (*project.Project, error) {
+	if m.shouldError {
+		return nil, errRepository
+	}
+	for _, proj := range m.projects {
+		if proj.ID().Equals(id) {
+			return proj, nil
+		}
+	}
+	return nil, project.ErrProjectNotFound
+}
+
+func (m *mockProjectRepository) FindByUserID(ctx context.Context, userID user.UserID, limit, offset int32) ([]*project.Project, error) {
+	return m.list(limit, offset, false, true, func(proj *project.Project) bool { return proj.BelongsToUser(userID) })
+}
+
+func (m *mockProjectRepository) FindByUserIDIncludingDeleted(ctx context.Context, userID user.UserID, limit, offset int32) ([]*project.Project, error) {
+	return m.list(limit, offset, true, true, func(proj *project.Project) bool { return proj.BelongsToUser(userID) })
+}
+
+func (m *mockProjectRepository) FindAll(ctx context.Context, limit, offset int32) ([]*project.Project, error) {
+	return m.list(limit, offset, false, false, func(*project.Project) bool { return true })
+}
+
+func (m *mockProjectRepository) FindWithCronJobs(ctx context.Context) ([]*project.Project, error) {
+	return m.list(int32(len(m.projects)), 0, false, false, (*project.Project).RunsCronJob)
+}
+
+func (m *mockProjectRepository) FindByRepositoryURL(ctx context.Context, userID user.UserID, repoURL project.RepositoryURL) (*project.Project, error) {
+	found, err := m.list(1, 0, false, false, func(proj *project.Project) bool {
+		return proj.BelongsToUser(userID) && proj.RepositoryURL().Equals(repoURL)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, project.ErrProjectNotFound
+	}
+	return found[0], nil
+}
+
+func (m *mockProjectRepository) FindAllByRepositoryURL(ctx context.Context, repoURL project.RepositoryURL) ([]*project.Project, error) {
+	trimmed := strings.TrimSuffix(repoURL.String(), ".git")
+	return m.list(int32(len(m.projects)), 0, false, false, func(proj *project.Project) bool {
+		return strings.TrimSuffix(proj.RepositoryURL().String(), ".git") == trimmed
+	})
+}
+
+func (m *mockProjectRepository) CountByUserID(ctx context.Context, userID user.UserID) (int64, error) {
+	found, err := m.FindByUserID(ctx, userID, int32(len(m.projects)), 0)
+	return int64(len(found)), err
+}
+
+func (m *mockProjectRepository) CountByUserIDIncludingDeleted(ctx context.Context, userID user.UserID) (int64, error) {
+	found, err := m.FindByUserIDIncludingDeleted(ctx, userID, int32(len(m.projects)), 0)
+	return int64(len(found)), err
+}
+
+func (m *mockProjectRepository) Delete(ctx context.Context, id project.ProjectID) error {
+	if _, err := m.FindByID(ctx, id); err != nil {
+		return err
+	}
+	m.deletedAt[id] = time.Now()
+	return nil
+}
+
+// ClaimStalledTeardown hands out every project being deleted once, however recently its teardown made progress
+func (m *mockProjectRepository) ClaimStalledTeardown(ctx context.Context, staleBefore time.Time) (*project.Project, error) {
+	found, err := m.list(int32(len(m.projects)), 0, false, false, func(proj *project.Project) bool {
+		return proj.IsDeleting() && !m.claimed[proj.ID()]
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, project.ErrProjectNotFound
+	}
+	m.claimed[found[0].ID()] = true
+	return found[0], nil
+}
+
+func (m *mockProjectRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time, limit int32) (int64, error) {
+	if m.shouldError {
+		return 0, errRepository
+	}
+	var purged int64
+	m.projects = slices.DeleteFunc(m.projects, func(proj *project.Project) bool {
+		deletedAt, deleted := m.deletedAt[proj.ID()]
+		if !deleted || !deletedAt.Before(cutoff) || purged == int64(limit) {
+			return false
+		}
+		delete(m.deletedAt, proj.ID())
+		purged++
+		return true
+	})
+	return purged, nil
+}
+
+func (m *mockProjectRepository) ExistsByRepositoryURL(ctx context.Context, userID user.UserID, repoURL project.RepositoryURL) (bool, error) {
+	_, err := m.FindByRepositoryURL(ctx, userID, repoURL)
+	if errors.Is(err, project.ErrProjectNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (m *mockProjectRepository) FindByCustomDomains(ctx context.Context, domains []string) ([]*project.Project, error) {
+	return m.list(int32(len(m.projects)), 0, false, false, func(proj *project.Project) bool {
+		return slices.Contains(domains, proj.CustomDomain().String())
+	})
+}
+
+// list returns the projects match selects, oldest first unless newestFirst
+func (m *mockProjectRepository) list(limit, offset int32, includeDeleted, newestFirst bool, match func(*project.Project) bool) ([]*project.Project, error) {
+	if m.shouldError {
+		return nil, errRepository
+	}
+	var found []*project.Project
+	for _, proj := range m.projects {
+		if _, deleted := m.deletedAt[proj.ID()]; (!deleted || includeDeleted) && match(proj) {
+			found = append(found, proj)
+		}
+	}
+	if newestFirst {
+		slices.Reverse(found)
+	}
+	return paginate(found, limit, offset), nil
+}
+
+// mockEnvVarRepository keeps environment variables in memory
+type mockEnvVarRepository struct {
+	envVars     []*project.EnvironmentVariable
+	shouldError bool
+}
+
+func newMockEnvVarRepository(envVars ...*project.EnvironmentVariable) *mockEnvVarRepository {
+	return &mockEnvVarRepository{envVars: envVars}
+}
+
+func (m *mockEnvVarRepository) Save(ctx context.Context, envVar *project.EnvironmentVariable) error {
+	if m.shouldError {
+		return errRepository
+	}
+	m.envVars = slices.DeleteFunc(m.envVars, func(existing *project.EnvironmentVariable) bool {
+		return existing.ProjectID().Equals(envVar.ProjectID()) && existing.Environment() == envVar.Environment() && existing.Key().Equals(envVar.Key())
+	})
+	m.envVars = append(m.envVars, envVar)
+	return nil
+}
+
+func (m *mockEnvVarRepository) FindByProjectID(ctx context.Context, projectID project.ProjectID, env project.Environment) ([]*project.EnvironmentVariable, error) {
+	if m.shouldError {
+		return nil, errRepository
+	}
+	var envVars []*project.EnvironmentVariable
+	for _, envVar := range m.envVars {
+		if envVar.ProjectID().Equals(projectID) && envVar.Environment() == env {
+			envVars = append(envVars, envVar)
+		}
+	}
+	return envVars, nil
+}
+
+func (m *mockEnvVarRepository) FindByKey(ctx context.Context, projectID project.ProjectID, env project.Environment, key project.EnvVarKey) (*project.EnvironmentVariable, error) {
+	envVars, err := m.FindByProjectID(ctx, projectID, env)
+	if err != nil {
+		return nil, err
+	}
+	for _, envVar := range envVars {
+		if envVar.Key().Equals(key) {
+			return envVar, nil
+		}
+	}
+	return nil, project.ErrEnvVarNotFound
+}
+
+func (m *mockEnvVarRepository) Delete(ctx context.Context, projectID project.ProjectID, env project.Environment, key project.EnvVarKey) error {
+	if _, err := m.FindByKey(ctx, projectID, env, key); err != nil {
+		return err
+	}
+	m.delete(func(envVar *project.EnvironmentVariable) bool {
+		return envVar.ProjectID().Equals(projectID) && envVar.Environment() == env && envVar.Key().Equals(key)
+	})
+	return nil
+}
+
+func (m *mockEnvVarRepository) DeleteEnvironment(ctx context.Context, projectID project.ProjectID, env project.Environment) error {
+	if m.shouldError {
+		return errRepository
+	}
+	m.delete(func(envVar *project.EnvironmentVariable) bool {
+		return envVar.ProjectID().Equals(projectID) && envVar.Environment() == env
+	})
+	return nil
+}
+
+func (m *mockEnvVarRepository) DeleteAll(ctx context.Context, projectID project.ProjectID) error {
+	if m.shouldError {
+		return errRepository
+	}
+	m.delete(func(envVar *project.EnvironmentVariable) bool { return envVar.ProjectID().Equals(projectID) })
+	return nil
+}
+
+func (m *mockEnvVarRepository) Count(ctx context.Context, projectID project.ProjectID, env project.Environment) (int64, error) {
+	envVars, err := m.FindByProjectID(ctx, projectID, env)
+	return int64(len(envVars)), err
+}
+
+func (m *mockEnvVarRepository) delete(match func(*project.EnvironmentVariable) bool) {
+	m.envVars = slices.DeleteFunc(m.envVars, match)
+}
+
+// mockApprovalRepository keeps the approvers of every project's environments and the decisions recorded
+type mockApprovalRepository struct {
+	approvers map[string][]user.UserID // By project ID and environment, joined by a slash
+	saved     []deployment.Approval
+}
+
+func newMockApprovalRepository() *mockApprovalRepository {
+	return &mockApprovalRepository{approvers: make(map[string][]user.UserID)}
+}
+
+func (m *mockApprovalRepository) Save(ctx context.Context, approval deployment.Approval) error {
+	m.saved = append(m.saved, approval)
+	return nil
+}
+
+func (m *mockApprovalRepository) FindByDeploymentID(ctx context.Context, deploymentID deployment.DeploymentID) ([]deployment.Approval, error) {
+	var approvals []deployment.Approval
+	for _, approval := range m.saved {
+		if approval.DeploymentID.Equals(deploymentID) {
+			approvals = append(approvals, approval)
+		}
+	}
+	return approvals, nil
+}
+
+func (m *mockApprovalRepository) FindApprovers(ctx context.Context, projectID project.ProjectID, env project.Environment) ([]user.UserID, error) {
+	return m.approvers[projectID.String()+"/"+env.String()], nil
+}
+
+func (m *mockApprovalRepository) SetApprovers(ctx context.Context, projectID project.ProjectID, env project.Environment, approvers []user.UserID) error {
+	m.approvers[projectID.String()+"/"+env.String()] = approvers
+	return nil
+}
+
+// mockAgentTokenRepository keeps agent tokens by their hash
+type mockAgentTokenRepository struct {
+	tokens map[string]*agent.Token
+}
+
+func newMockAgentTokenRepository() *mockAgentTokenRepository {
+	return &mockAgentTokenRepository{tokens: make(map[string]*agent.Token)}
+}
+
+func (m *mockAgentTokenRepository) Save(ctx context.Context, token *agent.Token) error {
+	m.tokens[token.TokenHash()] = token
+	return nil
+}
+
+func (m *mockAgentTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*agent.Token, error) {
+	token, ok := m.tokens[tokenHash]
+	if !ok {
+		return nil, agent.ErrTokenNotFound
+	}
+	return token, nil
+}
+
+func (m *mockAgentTokenRepository) FindByUserID(ctx context.Context, userID user.UserID) ([]*agent.Token, error) {
+	var tokens []*agent.Token
+	for _, token := range m.tokens {
+		if token.UserID().Equals(userID) {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (m *mockAgentTokenRepository) Delete(ctx context.Context, id agent.TokenID, userID user.UserID) error {
+	for hash, token := range m.tokens {
+		if token.ID().Equals(id) && token.UserID().Equals(userID) {
+			delete(m.tokens, hash)
+			return nil
+		}
+	}
+	return agent.ErrTokenNotFound
+}
+
+// newTestProject returns a NODE project of the owner deployed from github.com/acme/<name> on the name as custom
+// domain, after applying the setup
+func newTestProject(t testing.TB, owner user.UserID, name string, setup ...func(*project.Project) error) *project.Project {
+	t.Helper()
+	proj, err := project.NewProject(owner, "https://github.com/acme/"+name, "npm install", "npm run build", "npm start", "NODE", name, false, "")
+	if err != nil {
+		t.Fatalf("NewProject() error = %v", err)
+	}
+	for _, apply := range setup {
+		if err := apply(proj); err != nil {
+			t.Fatalf("setting up project %s: %v", name, err)
+		}
+	}
+	return proj
+}
+
+// withEnvironments sets a project up to deploy to the environments besides production
+func withEnvironments(environments ...string) func(*project.Project) error {
+	return func(proj *project.Project) error {
+		return proj.SetEnvironments(environments)
+	}
+}
+
+// requireDatabase sets a project up to need a database, migrated with npm run migrate
+func requireDatabase(proj *project.Project) error {
+	return proj.Update(proj.RepositoryURL().String(), proj.InstallCommand().String(), proj.BuildCommand().String(),
+		proj.RunCommand().String(), proj.Language().String(), proj.CustomDomain().String(), true, "npm run migrate")
+}
+
+// newTestDeployment returns a deployment of the main branch to the project's environment, started by its owner,
+// taken through the status transitions that lead to status
+func newTestDeployment(t testing.TB, proj *project.Project, env project.Environment, status deployment.DeploymentStatus) *deployment.Deployment {
+	t.Helper()
+	dep, err := deployment.NewDeployment(proj.ID(), proj.UserID(), "abc123def456", "main", env)
+	if err != nil {
+		t.Fatalf("NewDeployment() error = %v", err)
+	}
+	transitions := map[deployment.DeploymentStatus][]deployment.DeploymentStatus{
+		deployment.StatusPending:     nil,
+		deployment.StatusBuilding:    {deployment.StatusBuilding},
+		deployment.StatusInterrupted: {deployment.StatusBuilding, deployment.StatusInterrupted},
+		deployment.StatusDeploying:   {deployment.StatusBuilding, deployment.StatusDeploying},
+		deployment.StatusDeployed:    {deployment.StatusBuilding, deployment.StatusDeploying, deployment.StatusDeployed},
+		deployment.StatusRolledBack:  {deployment.StatusBuilding, deployment.StatusDeploying, deployment.StatusDeployed, deployment.StatusRolledBack},
+		deployment.StatusFailed:      {deployment.StatusFailed},
+	}
+	path, ok := transitions[status]
+	if !ok {
+		t.Fatalf("newTestDeployment() can't take a deployment to %s", status)
+	}
+	for _, next := range path {
+		if err := dep.UpdateStatus(next); err != nil {
+			t.Fatalf("UpdateStatus(%s) error = %v", next, err)
+		}
+	}
+	return dep
+}
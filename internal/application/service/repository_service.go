@@ -10,100 +10,339 @@ import (
 	"snapdeploy-core/internal/domain/user"
 )
 
+// repositoryCommitsLimit is the number of recent commits listed for a branch
+const repositoryCommitsLimit = 30
+
+// InstallationRepositorySource fetches repositories through the GitHub App installations a user has claimed
+type InstallationRepositorySource interface {
+	HasInstallations(ctx context.Context, userID user.UserID) (bool, error)
+	FetchRepositories(ctx context.Context, userID user.UserID) ([]*repo.RemoteRepository, error)
+}
+
 // RepositoryService handles repository-related use cases
 type RepositoryService struct {
 	repoRepo      repo.RepositoryRepo
-	githubService repo.GitHubService
+	providers     map[repo.Provider]repo.GitProvider
+	installations InstallationRepositorySource
+	uow           UnitOfWork
 }
 
-// NewRepositoryService creates a new repository service
-func NewRepositoryService(repoRepo repo.RepositoryRepo, githubService repo.GitHubService) *RepositoryService {
+// NewRepositoryService creates a new repository service that syncs repositories from the given Git providers
+func NewRepositoryService(repoRepo repo.RepositoryRepo, providers ...repo.GitProvider) *RepositoryService {
 	return &RepositoryService{
-		repoRepo:      repoRepo,
-		githubService: githubService,
+		repoRepo:  repoRepo,
+		providers: gitProviderMap(providers),
+	}
+}
+
+// SetInstallationRepositorySource sets the GitHub App installations used to sync repositories (optional)
+func (s *RepositoryService) SetInstallationRepositorySource(source InstallationRepositorySource) {
+	s.installations = source
+}
+
+// SetUnitOfWork sets the unit of work syncs save repositories and their sync state in (optional)
+func (s *RepositoryService) SetUnitOfWork(uow UnitOfWork) {
+	s.uow = uow
+}
+
+// UsesGitHubApp reports whether a user's repositories are synced through GitHub App installations
+// rather than their GitHub OAuth token
+func (s *RepositoryService) UsesGitHubApp(ctx context.Context, userID string) (bool, error) {
+	if s.installations == nil {
+		return false, nil
+	}
+
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return false, fmt.Errorf("invalid user ID: %w", err)
 	}
+
+	return s.installations.HasInstallations(ctx, uid)
 }
 
-// SyncRepositoriesFromGitHub fetches repositories from GitHub and syncs them
-func (s *RepositoryService) SyncRepositoriesFromGitHub(ctx context.Context, userID string, githubAccessToken string) (*dto.RepositorySyncResponse, error) {
+// SyncRepositoriesFromProvider fetches a user's repositories from a Git provider with their access token and syncs them.
+// Providers that support it fetch only the repositories that changed since the user's last sync, and every
+// repository once every repo.FullSyncInterval.
+func (s *RepositoryService) SyncRepositoriesFromProvider(ctx context.Context, userID, provider, accessToken string) (*dto.RepositorySyncResponse, error) {
 	// Parse user ID
 	uid, err := user.ParseUserID(userID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid user ID: %w", err)
 	}
 
-	// Fetch repositories from GitHub
-	githubRepos, err := s.githubService.FetchUserRepositories(ctx, githubAccessToken)
+	gitProvider, err := s.provider(provider)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch repositories from GitHub: %w", err)
+		return nil, err
 	}
 
-	var repositories []*repo.Repository
+	if fetcher, ok := gitProvider.(repo.IncrementalRepositoryFetcher); ok {
+		return s.syncChangedRepositories(ctx, uid, gitProvider.Provider(), fetcher, accessToken)
+	}
+
+	// Fetch repositories from the provider
+	remoteRepos, err := gitProvider.FetchUserRepositories(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repositories from %s: %w", gitProvider.Provider(), err)
+	}
+
+	return s.syncRepositories(ctx, uid, gitProvider.Provider(), remoteRepos, nil)
+}
 
-	// Process each GitHub repository
-	for _, ghRepo := range githubRepos {
-		// Try to find existing repository by URL
-		repoURL, err := repo.NewURL(ghRepo.URL)
+// SyncRepositoriesFromInstallations fetches the repositories of a user's GitHub App installations and syncs them
+func (s *RepositoryService) SyncRepositoriesFromInstallations(ctx context.Context, userID string) (*dto.RepositorySyncResponse, error) {
+	if s.installations == nil {
+		return nil, ErrGitHubAppUnavailable
+	}
+
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	githubRepos, err := s.installations.FetchRepositories(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repositories from GitHub App installations: %w", err)
+	}
+
+	return s.syncRepositories(ctx, uid, repo.ProviderGitHub, githubRepos, nil)
+}
+
+// syncChangedRepositories syncs the repositories that changed at a provider since the user's last sync from it
+func (s *RepositoryService) syncChangedRepositories(ctx context.Context, uid user.UserID, provider repo.Provider, fetcher repo.IncrementalRepositoryFetcher, accessToken string) (*dto.RepositorySyncResponse, error) {
+	state, err := s.repoRepo.FindSyncState(ctx, uid, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	startedAt := time.Now()
+	full := state.NeedsFullSync(startedAt)
+	var since time.Time
+	var etag string
+	if !full {
+		since, etag = state.SyncedAt, state.ETag
+	}
+
+	changes, err := fetcher.FetchUserRepositoriesSince(ctx, accessToken, since, etag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repositories from %s: %w", provider, err)
+	}
+
+	next := state.Next(uid, provider, startedAt, changes.ETag, full)
+	return s.syncRepositories(ctx, uid, provider, changes.Repositories, next)
+}
+
+// syncRepositories creates or updates the user's repositories from a provider in one transaction,
+// together with the state of the sync if set
+func (s *RepositoryService) syncRepositories(ctx context.Context, uid user.UserID, provider repo.Provider, remoteRepos []*repo.RemoteRepository, state *repo.SyncState) (*dto.RepositorySyncResponse, error) {
+	// Pages fetched concurrently can list a repository twice if it is updated during the sync
+	var urls []repo.URL
+	byURL := make(map[string]*repo.RemoteRepository, len(remoteRepos))
+	for _, remoteRepo := range remoteRepos {
+		repoURL, err := repo.NewURL(remoteRepo.URL)
 		if err != nil {
 			continue // Skip invalid URLs
 		}
+		if _, ok := byURL[repoURL.String()]; !ok {
+			urls = append(urls, repoURL)
+		}
+		byURL[repoURL.String()] = remoteRepo
+	}
 
-		existingRepo, err := s.repoRepo.FindByURL(ctx, repoURL)
-		if err == nil {
-			// Update existing repository
-			existingRepo.UpdateMetadata(
-				ghRepo.Description,
-				&ghRepo.HTMLURL,
-				ghRepo.Private,
-				ghRepo.Fork,
-				ghRepo.StargazersCount,
-				ghRepo.WatchersCount,
-				ghRepo.ForksCount,
-				&ghRepo.DefaultBranch,
-				ghRepo.Language,
-			)
-			if err := s.repoRepo.Save(ctx, existingRepo); err != nil {
-				return nil, fmt.Errorf("failed to update repository: %w", err)
-			}
-			repositories = append(repositories, existingRepo)
-		} else {
+	existingRepos := make(map[string]*repo.Repository)
+	if len(urls) > 0 {
+		found, err := s.repoRepo.FindByURLs(ctx, urls)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find repositories: %w", err)
+		}
+		for _, existingRepo := range found {
+			existingRepos[existingRepo.URL().String()] = existingRepo
+		}
+	}
+
+	repositories := make([]*repo.Repository, 0, len(urls))
+	for _, repoURL := range urls {
+		remoteRepo := byURL[repoURL.String()]
+
+		repository, ok := existingRepos[repoURL.String()]
+		if !ok {
 			// Create new repository
-			newRepo, err := repo.NewRepository(
+			var err error
+			repository, err = repo.NewRepository(
 				uid,
-				ghRepo.ID,
-				ghRepo.Name,
-				ghRepo.FullName,
-				ghRepo.URL,
+				provider,
+				remoteRepo.ID,
+				remoteRepo.Name,
+				remoteRepo.FullName,
+				remoteRepo.URL,
 			)
 			if err != nil {
 				return nil, fmt.Errorf("failed to create repository entity: %w", err)
 			}
+		}
 
-			// Update metadata
-			newRepo.UpdateMetadata(
-				ghRepo.Description,
-				&ghRepo.HTMLURL,
-				ghRepo.Private,
-				ghRepo.Fork,
-				ghRepo.StargazersCount,
-				ghRepo.WatchersCount,
-				ghRepo.ForksCount,
-				&ghRepo.DefaultBranch,
-				ghRepo.Language,
-			)
+		repository.UpdateMetadata(
+			remoteRepo.Description,
+			&remoteRepo.HTMLURL,
+			remoteRepo.Private,
+			remoteRepo.Fork,
+			remoteRepo.StargazersCount,
+			remoteRepo.WatchersCount,
+			remoteRepo.ForksCount,
+			&remoteRepo.DefaultBranch,
+			remoteRepo.Language,
+		)
+		repositories = append(repositories, repository)
+	}
 
-			if err := s.repoRepo.Save(ctx, newRepo); err != nil {
-				return nil, fmt.Errorf("failed to save repository: %w", err)
+	err := s.atomically(ctx, func(ctx context.Context) error {
+		if len(repositories) > 0 {
+			if err := s.repoRepo.SaveAll(ctx, repositories); err != nil {
+				return fmt.Errorf("failed to save repositories: %w", err)
+			}
+		}
+		if state != nil {
+			if err := s.repoRepo.SaveSyncState(ctx, state); err != nil {
+				return fmt.Errorf("failed to save sync state: %w", err)
 			}
-			repositories = append(repositories, newRepo)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &dto.RepositorySyncResponse{
 		Message: "success",
+		Synced:  len(repositories),
+	}, nil
+}
+
+// atomically runs fn in the unit of work if one is set, or directly otherwise
+func (s *RepositoryService) atomically(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.uow == nil {
+		return fn(ctx)
+	}
+	return s.uow.Do(ctx, fn)
+}
+
+// GetRepository retrieves one of a user's repositories
+func (s *RepositoryService) GetRepository(ctx context.Context, userID, repositoryID string) (*dto.RepositoryResponse, error) {
+	repository, err := s.findUserRepository(ctx, userID, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toDTO(repository), nil
+}
+
+// GetRepositoryBranches lists the branches of one of a user's repositories from its provider
+func (s *RepositoryService) GetRepositoryBranches(ctx context.Context, userID, repositoryID, accessToken string) (*dto.RepositoryBranchesResponse, error) {
+	repository, err := s.findUserRepository(ctx, userID, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	gitProvider, err := s.provider(repository.Provider().String())
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := gitProvider.FetchBranches(ctx, accessToken, repository.FullName())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch branches: %w", err)
+	}
+
+	var defaultBranch string
+	if repository.DefaultBranch() != nil {
+		defaultBranch = *repository.DefaultBranch()
+	}
+
+	return &dto.RepositoryBranchesResponse{
+		RepositoryID:  repository.ID().String(),
+		Provider:      repository.Provider().String(),
+		DefaultBranch: defaultBranch,
+		Branches:      branches,
 	}, nil
 }
 
+// GetRepositoryCommits lists the most recent commits on a branch of one of a user's repositories.
+// An empty branch lists the repository's default branch.
+func (s *RepositoryService) GetRepositoryCommits(ctx context.Context, userID, repositoryID, branch, accessToken string) (*dto.RepositoryCommitsResponse, error) {
+	repository, err := s.findUserRepository(ctx, userID, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	gitProvider, err := s.provider(repository.Provider().String())
+	if err != nil {
+		return nil, err
+	}
+
+	if branch == "" && repository.DefaultBranch() != nil {
+		branch = *repository.DefaultBranch()
+	}
+
+	commits, err := gitProvider.FetchCommits(ctx, accessToken, repository.FullName(), branch, repositoryCommitsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch commits: %w", err)
+	}
+
+	commitResponses := make([]*dto.CommitResponse, len(commits))
+	for i, commit := range commits {
+		commitResponses[i] = &dto.CommitResponse{
+			SHA:         commit.SHA,
+			Message:     commit.Message,
+			Author:      commit.AuthorName,
+			CommittedAt: commit.CommittedAt.Format(time.RFC3339),
+			URL:         commit.HTMLURL,
+		}
+	}
+
+	return &dto.RepositoryCommitsResponse{
+		RepositoryID: repository.ID().String(),
+		Branch:       branch,
+		Commits:      commitResponses,
+	}, nil
+}
+
+// findUserRepository loads a repository and checks it belongs to the user
+func (s *RepositoryService) findUserRepository(ctx context.Context, userID, repositoryID string) (*repo.Repository, error) {
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	rid, err := repo.ParseRepositoryID(repositoryID)
+	if err != nil {
+		return nil, repo.ErrInvalidRepositoryData("repository ID", err)
+	}
+
+	repository, err := s.repoRepo.FindByID(ctx, rid)
+	if err != nil {
+		return nil, err
+	}
+
+	if !repository.BelongsToUser(uid) {
+		return nil, repo.ErrUnauthorizedAccess(userID, repositoryID)
+	}
+
+	return repository, nil
+}
+
+// provider returns the Git provider implementation for a provider name
+func (s *RepositoryService) provider(name string) (repo.GitProvider, error) {
+	provider, err := repo.NewProvider(name)
+	if err != nil {
+		return nil, repo.ErrInvalidRepositoryData("provider", err)
+	}
+
+	gitProvider, ok := s.providers[provider]
+	if !ok {
+		return nil, repo.ErrProviderNotSupported(provider)
+	}
+	return gitProvider, nil
+}
+
 // GetRepositoriesByUserID retrieves repositories for a user with pagination and optional search
 func (s *RepositoryService) GetRepositoriesByUserID(ctx context.Context, userID string, searchQuery string, page, limit int32) (*dto.RepositoryListResponse, error) {
 	if page < 1 {
@@ -146,28 +385,97 @@ func (s *RepositoryService) GetRepositoriesByUserID(ctx context.Context, userID
 		}
 	}
 
+	return s.toListDTO(repositories, total, page, limit, int64(page)*int64(limit) < total), nil
+}
+
+// GetRepositoriesByUserIDAfter retrieves the repositories for a user that come after the cursor of a previous page,
+// with optional search
+func (s *RepositoryService) GetRepositoriesByUserIDAfter(ctx context.Context, userID, searchQuery, cursor string, limit int32) (*dto.RepositoryListResponse, error) {
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	createdAt, id, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	rid, err := repo.ParseRepositoryID(id)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	after := repo.Cursor{CreatedAt: createdAt, ID: rid}
+
+	var repositories []*repo.Repository
+	var total int64
+
+	// One more than the page holds tells whether there is a next one
+	if searchQuery != "" {
+		repositories, err = s.repoRepo.SearchByUserIDAfter(ctx, uid, searchQuery, after, limit+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search repositories: %w", err)
+		}
+
+		total, err = s.repoRepo.CountSearchByUserID(ctx, uid, searchQuery)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count search repositories: %w", err)
+		}
+	} else {
+		repositories, err = s.repoRepo.FindByUserIDAfter(ctx, uid, after, limit+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch repositories: %w", err)
+		}
+
+		total, err = s.repoRepo.CountByUserID(ctx, uid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count repositories: %w", err)
+		}
+	}
+
+	more := len(repositories) > int(limit)
+	if more {
+		repositories = repositories[:limit]
+	}
+	return s.toListDTO(repositories, total, 0, limit, more), nil
+}
+
+// toListDTO converts a page of repositories to a list response, page is 0 for pages continued from a cursor.
+// more tells whether repositories follow the page, its last repository is the cursor of the next page then.
+func (s *RepositoryService) toListDTO(repositories []*repo.Repository, total int64, page, limit int32, more bool) *dto.RepositoryListResponse {
 	repoResponses := make([]*dto.RepositoryResponse, len(repositories))
+	version := newListVersion(total)
 	for i, repository := range repositories {
 		repoResponses[i] = s.toDTO(repository)
+		version.add(repository.ID().String(), repository.UpdatedAt())
 	}
 
-	totalPages := (total + int64(limit) - 1) / int64(limit)
+	pagination := dto.PaginationResponse{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: (total + int64(limit) - 1) / int64(limit),
+	}
+	if more && len(repositories) > 0 {
+		last := repositories[len(repositories)-1]
+		pagination.NextCursor = encodeCursor(last.CreatedAt(), last.ID().String())
+	}
 
 	return &dto.RepositoryListResponse{
 		Repositories: repoResponses,
-		Pagination: dto.PaginationResponse{
-			Page:       page,
-			Limit:      limit,
-			Total:      total,
-			TotalPages: totalPages,
-		},
-	}, nil
+		Pagination:   pagination,
+		ETag:         version.etag(),
+	}
 }
 
 // toDTO converts a domain repository to DTO
 func (s *RepositoryService) toDTO(r *repo.Repository) *dto.RepositoryResponse {
 	return &dto.RepositoryResponse{
 		ID:          r.ID().String(),
+		Provider:    r.Provider().String(),
 		Name:        r.Name().String(),
 		FullName:    r.FullName(),
 		Description: r.Description(),
@@ -182,3 +490,12 @@ func (s *RepositoryService) toDTO(r *repo.Repository) *dto.RepositoryResponse {
 		CreatedAt:   r.CreatedAt().Format(time.RFC3339),
 	}
 }
+
+// gitProviderMap indexes Git providers by the hosting service they talk to
+func gitProviderMap(providers []repo.GitProvider) map[repo.Provider]repo.GitProvider {
+	byProvider := make(map[repo.Provider]repo.GitProvider, len(providers))
+	for _, provider := range providers {
+		byProvider[provider.Provider()] = provider
+	}
+	return byProvider
+}
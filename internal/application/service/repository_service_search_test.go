@@ -11,15 +11,15 @@ import (
 
 func TestRepositoryService_SearchRepositories(t *testing.T) {
 	repoRepo := newMockRepositoryRepo()
-	githubSvc := &mockGitHubService{}
+	githubSvc := &mockGitProvider{}
 	svc := service.NewRepositoryService(repoRepo, githubSvc)
 
 	userID := user.NewUserID()
 
 	// Create test repositories
-	r1, _ := repo.NewRepository(userID, 12345, "my-react-app", "user/my-react-app", "https://github.com/user/my-react-app")
-	r2, _ := repo.NewRepository(userID, 67890, "golang-service", "user/golang-service", "https://github.com/user/golang-service")
-	r3, _ := repo.NewRepository(userID, 11111, "python-script", "user/python-script", "https://github.com/user/python-script")
+	r1, _ := repo.NewRepository(userID, repo.ProviderGitHub, "12345", "my-react-app", "user/my-react-app", "https://github.com/user/my-react-app")
+	r2, _ := repo.NewRepository(userID, repo.ProviderGitHub, "67890", "golang-service", "user/golang-service", "https://github.com/user/golang-service")
+	r3, _ := repo.NewRepository(userID, repo.ProviderGitHub, "11111", "python-script", "user/python-script", "https://github.com/user/python-script")
 	_ = repoRepo.Save(context.Background(), r1)
 	_ = repoRepo.Save(context.Background(), r2)
 	_ = repoRepo.Save(context.Background(), r3)
@@ -39,13 +39,13 @@ func TestRepositoryService_SearchRepositories(t *testing.T) {
 
 func TestRepositoryService_EmptySearch(t *testing.T) {
 	repoRepo := newMockRepositoryRepo()
-	githubSvc := &mockGitHubService{}
+	githubSvc := &mockGitProvider{}
 	svc := service.NewRepositoryService(repoRepo, githubSvc)
 
 	userID := user.NewUserID()
 
 	// Create test repositories
-	r1, _ := repo.NewRepository(userID, 12345, "repo1", "user/repo1", "https://github.com/user/repo1")
+	r1, _ := repo.NewRepository(userID, repo.ProviderGitHub, "12345", "repo1", "user/repo1", "https://github.com/user/repo1")
 	_ = repoRepo.Save(context.Background(), r1)
 
 	// Test with empty search query (should behave like regular list)
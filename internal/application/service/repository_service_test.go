@@ -3,7 +3,9 @@ package service_test
 import (
 	"context"
 	"errors"
+	"sort"
 	"testing"
+	"time"
 
 	"snapdeploy-core/internal/application/service"
 	"snapdeploy-core/internal/domain/repo"
@@ -14,13 +16,16 @@ import (
 type mockRepositoryRepo struct {
 	repos       map[string]*repo.Repository
 	urlIndex    map[string]*repo.Repository
+	syncStates  map[string]*repo.SyncState
+	batches     int // Number of SaveAll calls
 	shouldError bool
 }
 
 func newMockRepositoryRepo() *mockRepositoryRepo {
 	return &mockRepositoryRepo{
-		repos:    make(map[string]*repo.Repository),
-		urlIndex: make(map[string]*repo.Repository),
+		repos:      make(map[string]*repo.Repository),
+		urlIndex:   make(map[string]*repo.Repository),
+		syncStates: make(map[string]*repo.SyncState),
 	}
 }
 
@@ -54,9 +59,54 @@ func (m *mockRepositoryRepo) FindByUserID(ctx context.Context, userID user.UserI
 			result = append(result, repository)
 		}
 	}
+	sortNewestFirst(result)
+	if int(offset) >= len(result) {
+		return nil, nil
+	}
+	result = result[offset:]
+	if len(result) > int(limit) {
+		result = result[:limit]
+	}
 	return result, nil
 }
 
+func (m *mockRepositoryRepo) FindByUserIDAfter(ctx context.Context, userID user.UserID, after repo.Cursor, limit int32) ([]*repo.Repository, error) {
+	if m.shouldError {
+		return nil, errors.New("repository error")
+	}
+	var result []*repo.Repository
+	for _, repository := range m.repos {
+		if !repository.UserID().Equals(userID) {
+			continue
+		}
+		// Same order as sortNewestFirst
+		if repository.CreatedAt().Before(after.CreatedAt) ||
+			(repository.CreatedAt().Equal(after.CreatedAt) && repository.ID().String() < after.ID.String()) {
+			result = append(result, repository)
+		}
+	}
+	sortNewestFirst(result)
+	if len(result) > int(limit) {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *mockRepositoryRepo) SearchByUserIDAfter(ctx context.Context, userID user.UserID, searchQuery string, after repo.Cursor, limit int32) ([]*repo.Repository, error) {
+	// Simple mock: search matches every repository
+	return m.FindByUserIDAfter(ctx, userID, after, limit)
+}
+
+// sortNewestFirst sorts repositories like the list queries, newest first with the ID breaking ties
+func sortNewestFirst(repositories []*repo.Repository) {
+	sort.Slice(repositories, func(i, j int) bool {
+		if !repositories[i].CreatedAt().Equal(repositories[j].CreatedAt()) {
+			return repositories[i].CreatedAt().After(repositories[j].CreatedAt())
+		}
+		return repositories[i].ID().String() > repositories[j].ID().String()
+	})
+}
+
 func (m *mockRepositoryRepo) CountByUserID(ctx context.Context, userID user.UserID) (int64, error) {
 	if m.shouldError {
 		return 0, errors.New("repository error")
@@ -108,6 +158,38 @@ func (m *mockRepositoryRepo) FindByURL(ctx context.Context, url repo.URL) (*repo
 	return repository, nil
 }
 
+func (m *mockRepositoryRepo) FindByURLs(ctx context.Context, urls []repo.URL) ([]*repo.Repository, error) {
+	if m.shouldError {
+		return nil, errors.New("repository error")
+	}
+	var result []*repo.Repository
+	for _, url := range urls {
+		if repository, ok := m.urlIndex[url.String()]; ok {
+			result = append(result, repository)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepositoryRepo) SaveAll(ctx context.Context, repositories []*repo.Repository) error {
+	m.batches++
+	for _, repository := range repositories {
+		if err := m.Save(ctx, repository); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockRepositoryRepo) FindSyncState(ctx context.Context, userID user.UserID, provider repo.Provider) (*repo.SyncState, error) {
+	return m.syncStates[userID.String()+provider.String()], nil
+}
+
+func (m *mockRepositoryRepo) SaveSyncState(ctx context.Context, state *repo.SyncState) error {
+	m.syncStates[state.UserID.String()+state.Provider.String()] = state
+	return nil
+}
+
 func (m *mockRepositoryRepo) Delete(ctx context.Context, id repo.RepositoryID) error {
 	if m.shouldError {
 		return errors.New("repository error")
@@ -116,21 +198,34 @@ func (m *mockRepositoryRepo) Delete(ctx context.Context, id repo.RepositoryID) e
 	return nil
 }
 
-type mockGitHubService struct {
-	repos       []*repo.GitHubRepository
-	shouldError bool
+type mockGitProvider struct {
+	provider     repo.Provider
+	repos        []*repo.RemoteRepository
+	branches     []string
+	commits      []*repo.Commit
+	commitBranch string // Branch of the last FetchCommits call
+	files        map[string][]byte
+	fileToken    string // Token of the last FetchFile call
+	shouldError  bool
 }
 
-func (m *mockGitHubService) FetchUserRepositories(ctx context.Context, accessToken string) ([]*repo.GitHubRepository, error) {
+func (m *mockGitProvider) Provider() repo.Provider {
+	if m.provider == "" {
+		return repo.ProviderGitHub
+	}
+	return m.provider
+}
+
+func (m *mockGitProvider) FetchUserRepositories(ctx context.Context, accessToken string) ([]*repo.RemoteRepository, error) {
 	if m.shouldError {
 		return nil, errors.New("github error")
 	}
 	if m.repos == nil {
 		desc := "Test repository"
 		lang := "Go"
-		return []*repo.GitHubRepository{
+		return []*repo.RemoteRepository{
 			{
-				ID:              12345,
+				ID:              "12345",
 				Name:            "test-repo",
 				FullName:        "user/test-repo",
 				Description:     &desc,
@@ -149,16 +244,54 @@ func (m *mockGitHubService) FetchUserRepositories(ctx context.Context, accessTok
 	return m.repos, nil
 }
 
-func TestRepositoryService_SyncRepositoriesFromGitHub(t *testing.T) {
+func (m *mockGitProvider) FetchBranches(ctx context.Context, accessToken, fullName string) ([]string, error) {
+	if m.shouldError {
+		return nil, errors.New("provider error")
+	}
+	return m.branches, nil
+}
+
+func (m *mockGitProvider) FetchCommits(ctx context.Context, accessToken, fullName, branch string, limit int) ([]*repo.Commit, error) {
+	if m.shouldError {
+		return nil, errors.New("provider error")
+	}
+	m.commitBranch = branch
+	return m.commits, nil
+}
+
+func (m *mockGitProvider) FetchFile(ctx context.Context, accessToken, repositoryURL, ref, path string) ([]byte, error) {
+	if m.shouldError {
+		return nil, errors.New("provider error")
+	}
+	m.fileToken = accessToken
+	content, ok := m.files[path]
+	if !ok {
+		return nil, repo.ErrFileNotFound
+	}
+	return content, nil
+}
+
+func (m *mockGitProvider) CompareCommits(ctx context.Context, accessToken, repositoryURL, base, head string) (*repo.CommitComparison, error) {
+	if m.shouldError {
+		return nil, errors.New("provider error")
+	}
+	return &repo.CommitComparison{Commits: m.commits}, nil
+}
+
+func (m *mockGitProvider) CloneCredentials(repositoryURL, accessToken string) (*repo.CloneCredentials, error) {
+	return &repo.CloneCredentials{URL: repositoryURL + ".git", Username: "token", Token: accessToken}, nil
+}
+
+func TestRepositoryService_SyncRepositoriesFromProvider(t *testing.T) {
 	repoRepo := newMockRepositoryRepo()
-	githubSvc := &mockGitHubService{}
+	githubSvc := &mockGitProvider{}
 	svc := service.NewRepositoryService(repoRepo, githubSvc)
 
 	userID := user.NewUserID()
 
-	resp, err := svc.SyncRepositoriesFromGitHub(context.Background(), userID.String(), "token")
+	resp, err := svc.SyncRepositoriesFromProvider(context.Background(), userID.String(), "github", "token")
 	if err != nil {
-		t.Fatalf("SyncRepositoriesFromGitHub() error = %v", err)
+		t.Fatalf("SyncRepositoriesFromProvider() error = %v", err)
 	}
 
 	if resp.Message != "success" {
@@ -168,15 +301,15 @@ func TestRepositoryService_SyncRepositoriesFromGitHub(t *testing.T) {
 
 func TestRepositoryService_SyncRepositoriesUpdate(t *testing.T) {
 	repoRepo := newMockRepositoryRepo()
-	githubSvc := &mockGitHubService{}
+	githubSvc := &mockGitProvider{}
 	svc := service.NewRepositoryService(repoRepo, githubSvc)
 
 	userID := user.NewUserID()
 
 	// First sync
-	resp1, err := svc.SyncRepositoriesFromGitHub(context.Background(), userID.String(), "token")
+	resp1, err := svc.SyncRepositoriesFromProvider(context.Background(), userID.String(), "github", "token")
 	if err != nil {
-		t.Fatalf("SyncRepositoriesFromGitHub() error = %v", err)
+		t.Fatalf("SyncRepositoriesFromProvider() error = %v", err)
 	}
 
 	if resp1.Message != "success" {
@@ -186,9 +319,9 @@ func TestRepositoryService_SyncRepositoriesUpdate(t *testing.T) {
 	// Update GitHub data
 	desc := "Updated repository"
 	lang := "Go"
-	githubSvc.repos = []*repo.GitHubRepository{
+	githubSvc.repos = []*repo.RemoteRepository{
 		{
-			ID:              12345,
+			ID:              "12345",
 			Name:            "test-repo",
 			FullName:        "user/test-repo",
 			Description:     &desc,
@@ -205,9 +338,9 @@ func TestRepositoryService_SyncRepositoriesUpdate(t *testing.T) {
 	}
 
 	// Second sync should update
-	resp2, err := svc.SyncRepositoriesFromGitHub(context.Background(), userID.String(), "token")
+	resp2, err := svc.SyncRepositoriesFromProvider(context.Background(), userID.String(), "github", "token")
 	if err != nil {
-		t.Fatalf("SyncRepositoriesFromGitHub() error = %v", err)
+		t.Fatalf("SyncRepositoriesFromProvider() error = %v", err)
 	}
 
 	if resp2.Message != "success" {
@@ -229,16 +362,100 @@ func TestRepositoryService_SyncRepositoriesUpdate(t *testing.T) {
 	}
 }
 
+// mockIncrementalGitProvider lists the repositories updated after since, or answers that nothing changed
+// when the ETag matches
+type mockIncrementalGitProvider struct {
+	mockGitProvider
+	etag      string
+	updatedAt map[string]time.Time // Last update of each repository, by URL
+	since     []time.Time          // Since of each fetch
+}
+
+func (m *mockIncrementalGitProvider) FetchUserRepositoriesSince(ctx context.Context, accessToken string, since time.Time, etag string) (*repo.RepositoryChanges, error) {
+	m.since = append(m.since, since)
+	if etag == m.etag {
+		return &repo.RepositoryChanges{ETag: etag, NotModified: true}, nil
+	}
+
+	changes := &repo.RepositoryChanges{ETag: m.etag}
+	for _, remoteRepo := range m.repos {
+		if m.updatedAt[remoteRepo.URL].After(since) {
+			changes.Repositories = append(changes.Repositories, remoteRepo)
+		}
+	}
+	return changes, nil
+}
+
+func TestRepositoryService_SyncRepositoriesIncrementally(t *testing.T) {
+	repoRepo := newMockRepositoryRepo()
+	startedAt := time.Now()
+	provider := &mockIncrementalGitProvider{
+		mockGitProvider: mockGitProvider{repos: []*repo.RemoteRepository{
+			{ID: "1", Name: "api", FullName: "user/api", URL: "https://github.com/user/api", DefaultBranch: "main"},
+			{ID: "2", Name: "web", FullName: "user/web", URL: "https://github.com/user/web", DefaultBranch: "main"},
+			// Listed twice, as when it is updated while pages are fetched
+			{ID: "2", Name: "web", FullName: "user/web", URL: "https://github.com/user/web", DefaultBranch: "main"},
+		}},
+		etag: `"v1"`,
+		updatedAt: map[string]time.Time{
+			"https://github.com/user/api": startedAt.Add(-time.Hour),
+			"https://github.com/user/web": startedAt.Add(-time.Hour),
+		},
+	}
+	svc := service.NewRepositoryService(repoRepo, provider)
+	userID := user.NewUserID()
+
+	sync := func() int {
+		t.Helper()
+		resp, err := svc.SyncRepositoriesFromProvider(context.Background(), userID.String(), "github", "token")
+		if err != nil {
+			t.Fatalf("SyncRepositoriesFromProvider() error = %v", err)
+		}
+		return resp.Synced
+	}
+
+	// The first sync fetches every repository
+	if synced := sync(); synced != 2 || len(repoRepo.repos) != 2 || repoRepo.batches != 1 {
+		t.Fatalf("first sync saved %d repositories in %d batches, %d stored, want 2 in 1 batch", synced, repoRepo.batches, len(repoRepo.repos))
+	}
+	if !provider.since[0].IsZero() {
+		t.Errorf("first sync fetched repositories since %v, want all of them", provider.since[0])
+	}
+
+	// Nothing changed
+	if synced := sync(); synced != 0 {
+		t.Errorf("sync with a matching ETag saved %d repositories, want 0", synced)
+	}
+
+	// Only the updated repository is fetched
+	provider.etag = `"v2"`
+	provider.updatedAt["https://github.com/user/web"] = time.Now().Add(time.Minute)
+	if synced := sync(); synced != 1 {
+		t.Errorf("incremental sync saved %d repositories, want the updated one", synced)
+	}
+	if provider.since[2].Before(startedAt) {
+		t.Errorf("incremental sync fetched repositories since %v, want since the last sync", provider.since[2])
+	}
+	if len(repoRepo.repos) != 2 {
+		t.Errorf("%d repositories stored, want 2 (updated, not duplicated)", len(repoRepo.repos))
+	}
+
+	state := repoRepo.syncStates[userID.String()+repo.ProviderGitHub.String()]
+	if state == nil || state.ETag != `"v2"` || !state.FullSyncedAt.Before(state.SyncedAt) {
+		t.Errorf("sync state = %+v, want the last ETag and the first sync as the last full one", state)
+	}
+}
+
 func TestRepositoryService_GetRepositoriesByUserID(t *testing.T) {
 	repoRepo := newMockRepositoryRepo()
-	githubSvc := &mockGitHubService{}
+	githubSvc := &mockGitProvider{}
 	svc := service.NewRepositoryService(repoRepo, githubSvc)
 
 	userID := user.NewUserID()
 
 	// Create repositories
-	r1, _ := repo.NewRepository(userID, 12345, "repo1", "user/repo1", "https://github.com/user/repo1")
-	r2, _ := repo.NewRepository(userID, 67890, "repo2", "user/repo2", "https://github.com/user/repo2")
+	r1, _ := repo.NewRepository(userID, repo.ProviderGitHub, "12345", "repo1", "user/repo1", "https://github.com/user/repo1")
+	r2, _ := repo.NewRepository(userID, repo.ProviderGitHub, "67890", "repo2", "user/repo2", "https://github.com/user/repo2")
 	_ = repoRepo.Save(context.Background(), r1)
 	_ = repoRepo.Save(context.Background(), r2)
 
@@ -255,9 +472,112 @@ func TestRepositoryService_GetRepositoriesByUserID(t *testing.T) {
 	}
 }
 
+func TestRepositoryService_GetRepositoriesETag(t *testing.T) {
+	repoRepo := newMockRepositoryRepo()
+	githubSvc := &mockGitProvider{}
+	svc := service.NewRepositoryService(repoRepo, githubSvc)
+
+	userID := user.NewUserID()
+
+	r1, _ := repo.NewRepository(userID, repo.ProviderGitHub, "12345", "repo1", "user/repo1", "https://github.com/user/repo1")
+	_ = repoRepo.Save(context.Background(), r1)
+
+	first, err := svc.GetRepositoriesByUserID(context.Background(), userID.String(), "", 1, 10)
+	if err != nil {
+		t.Fatalf("GetRepositoriesByUserID() error = %v", err)
+	}
+	if first.ETag == "" {
+		t.Fatal("ETag is empty")
+	}
+
+	// Unchanged list keeps its ETag
+	again, _ := svc.GetRepositoriesByUserID(context.Background(), userID.String(), "", 1, 10)
+	if again.ETag != first.ETag {
+		t.Errorf("ETag = %v, want %v for an unchanged list", again.ETag, first.ETag)
+	}
+
+	// Updating an item changes it
+	r1.UpdateMetadata(nil, nil, false, false, 5, 0, 0, nil, nil)
+	_ = repoRepo.Save(context.Background(), r1)
+	updated, _ := svc.GetRepositoriesByUserID(context.Background(), userID.String(), "", 1, 10)
+	if updated.ETag == first.ETag {
+		t.Error("ETag unchanged after a repository was updated")
+	}
+
+	// Adding an item changes it
+	r2, _ := repo.NewRepository(userID, repo.ProviderGitHub, "67890", "repo2", "user/repo2", "https://github.com/user/repo2")
+	_ = repoRepo.Save(context.Background(), r2)
+	added, _ := svc.GetRepositoriesByUserID(context.Background(), userID.String(), "", 1, 10)
+	if added.ETag == updated.ETag {
+		t.Error("ETag unchanged after a repository was added")
+	}
+}
+
+func TestRepositoryService_GetRepositoriesByUserIDAfter(t *testing.T) {
+	repoRepo := newMockRepositoryRepo()
+	githubSvc := &mockGitProvider{}
+	svc := service.NewRepositoryService(repoRepo, githubSvc)
+
+	userID := user.NewUserID()
+	for _, name := range []string{"repo1", "repo2", "repo3", "repo4", "repo5"} {
+		r, _ := repo.NewRepository(userID, repo.ProviderGitHub, name, name, "user/"+name, "https://github.com/user/"+name)
+		_ = repoRepo.Save(context.Background(), r)
+	}
+
+	resp, err := svc.GetRepositoriesByUserID(context.Background(), userID.String(), "", 1, 2)
+	if err != nil {
+		t.Fatalf("GetRepositoriesByUserID() error = %v", err)
+	}
+	if resp.Pagination.NextCursor == "" {
+		t.Fatal("NextCursor is empty on the first of three pages")
+	}
+
+	seen := make(map[string]bool)
+	for _, r := range resp.Repositories {
+		seen[r.ID] = true
+	}
+
+	// Walk the remaining pages from the cursors
+	pages := 1
+	for cursor := resp.Pagination.NextCursor; cursor != ""; cursor = resp.Pagination.NextCursor {
+		resp, err = svc.GetRepositoriesByUserIDAfter(context.Background(), userID.String(), "", cursor, 2)
+		if err != nil {
+			t.Fatalf("GetRepositoriesByUserIDAfter() error = %v", err)
+		}
+		if resp.Pagination.Page != 0 {
+			t.Errorf("Page = %v, want 0 for a page continued from a cursor", resp.Pagination.Page)
+		}
+		for _, r := range resp.Repositories {
+			if seen[r.ID] {
+				t.Errorf("repository %s listed twice", r.Name)
+			}
+			seen[r.ID] = true
+		}
+		pages++
+	}
+
+	if pages != 3 {
+		t.Errorf("pages = %v, want 3", pages)
+	}
+	if len(seen) != 5 {
+		t.Errorf("listed %v repositories, want 5", len(seen))
+	}
+}
+
+func TestRepositoryService_GetRepositoriesByUserIDAfterInvalidCursor(t *testing.T) {
+	svc := service.NewRepositoryService(newMockRepositoryRepo(), &mockGitProvider{})
+
+	for _, cursor := range []string{"not base64!", "bm8tY29tbWE", "MjAyNS0wMS0wMVQwMDowMDowMFosbm90LWEtdXVpZA"} {
+		_, err := svc.GetRepositoriesByUserIDAfter(context.Background(), user.NewUserID().String(), "", cursor, 20)
+		if !errors.Is(err, service.ErrInvalidCursor) {
+			t.Errorf("GetRepositoriesByUserIDAfter(%q) error = %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+}
+
 func TestRepositoryService_GetRepositoriesWithPagination(t *testing.T) {
 	repoRepo := newMockRepositoryRepo()
-	githubSvc := &mockGitHubService{}
+	githubSvc := &mockGitProvider{}
 	svc := service.NewRepositoryService(repoRepo, githubSvc)
 
 	userID := user.NewUserID()
@@ -285,3 +605,98 @@ func TestRepositoryService_GetRepositoriesWithPagination(t *testing.T) {
 		t.Errorf("Limit = %v, want 20 (clamped)", resp.Pagination.Limit)
 	}
 }
+
+func TestRepositoryService_SyncRepositoriesFromGitLab(t *testing.T) {
+	repoRepo := newMockRepositoryRepo()
+	gitlabProvider := &mockGitProvider{
+		provider: repo.ProviderGitLab,
+		repos: []*repo.RemoteRepository{
+			{ID: "42", Name: "app", FullName: "group/subgroup/app", URL: "https://gitlab.com/group/subgroup/app", HTMLURL: "https://gitlab.com/group/subgroup/app", DefaultBranch: "main"},
+		},
+	}
+	svc := service.NewRepositoryService(repoRepo, &mockGitProvider{}, gitlabProvider)
+
+	userID := user.NewUserID()
+
+	if _, err := svc.SyncRepositoriesFromProvider(context.Background(), userID.String(), "gitlab", "token"); err != nil {
+		t.Fatalf("SyncRepositoriesFromProvider() error = %v", err)
+	}
+
+	resp, err := svc.GetRepositoriesByUserID(context.Background(), userID.String(), "", 1, 10)
+	if err != nil {
+		t.Fatalf("GetRepositoriesByUserID() error = %v", err)
+	}
+	if len(resp.Repositories) != 1 || resp.Repositories[0].Provider != "GITLAB" {
+		t.Errorf("Repositories = %+v, want one GITLAB repository", resp.Repositories)
+	}
+}
+
+func TestRepositoryService_SyncRepositoriesFromUnsupportedProvider(t *testing.T) {
+	svc := service.NewRepositoryService(newMockRepositoryRepo(), &mockGitProvider{})
+
+	_, err := svc.SyncRepositoriesFromProvider(context.Background(), user.NewUserID().String(), "bitbucket", "token")
+
+	var domainErr *repo.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != "PROVIDER_NOT_SUPPORTED" {
+		t.Errorf("SyncRepositoriesFromProvider() error = %v, want PROVIDER_NOT_SUPPORTED", err)
+	}
+}
+
+func TestRepositoryService_GetRepositoryBranches(t *testing.T) {
+	repoRepo := newMockRepositoryRepo()
+	bitbucketProvider := &mockGitProvider{provider: repo.ProviderBitbucket, branches: []string{"main", "develop"}}
+	svc := service.NewRepositoryService(repoRepo, bitbucketProvider)
+
+	owner := user.NewUserID()
+	r, _ := repo.NewRepository(owner, repo.ProviderBitbucket, "{6d3c1e2a-8f4b-4c5d-9e6f-7a8b9c0d1e2f}", "app", "workspace/app", "https://bitbucket.org/workspace/app")
+	_ = repoRepo.Save(context.Background(), r)
+
+	resp, err := svc.GetRepositoryBranches(context.Background(), owner.String(), r.ID().String(), "token")
+	if err != nil {
+		t.Fatalf("GetRepositoryBranches() error = %v", err)
+	}
+	if resp.Provider != "BITBUCKET" || len(resp.Branches) != 2 {
+		t.Errorf("GetRepositoryBranches() = %+v, want 2 BITBUCKET branches", resp)
+	}
+
+	var domainErr *repo.DomainError
+	_, err = svc.GetRepositoryBranches(context.Background(), user.NewUserID().String(), r.ID().String(), "token")
+	if !errors.As(err, &domainErr) || domainErr.Code != "UNAUTHORIZED_ACCESS" {
+		t.Errorf("GetRepositoryBranches() by another user error = %v, want UNAUTHORIZED_ACCESS", err)
+	}
+}
+
+func TestRepositoryService_GetRepositoryCommits(t *testing.T) {
+	repoRepo := newMockRepositoryRepo()
+	githubProvider := &mockGitProvider{
+		commits: []*repo.Commit{
+			{SHA: "abc123", Message: "Fix login", AuthorName: "Jane", CommittedAt: time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)},
+		},
+	}
+	svc := service.NewRepositoryService(repoRepo, githubProvider)
+
+	owner := user.NewUserID()
+	r, _ := repo.NewRepository(owner, repo.ProviderGitHub, "12345", "app", "user/app", "https://github.com/user/app")
+	defaultBranch := "main"
+	r.UpdateMetadata(nil, nil, false, false, 0, 0, 0, &defaultBranch, nil)
+	_ = repoRepo.Save(context.Background(), r)
+
+	// Without a branch the default branch is listed
+	resp, err := svc.GetRepositoryCommits(context.Background(), owner.String(), r.ID().String(), "", "token")
+	if err != nil {
+		t.Fatalf("GetRepositoryCommits() error = %v", err)
+	}
+	if resp.Branch != "main" || githubProvider.commitBranch != "main" {
+		t.Errorf("Branch = %q (fetched %q), want main", resp.Branch, githubProvider.commitBranch)
+	}
+	if len(resp.Commits) != 1 || resp.Commits[0].SHA != "abc123" || resp.Commits[0].CommittedAt != "2025-11-01T12:00:00Z" {
+		t.Errorf("Commits = %+v", resp.Commits)
+	}
+
+	if _, err := svc.GetRepositoryCommits(context.Background(), owner.String(), r.ID().String(), "feature/x", "token"); err != nil {
+		t.Fatalf("GetRepositoryCommits() error = %v", err)
+	}
+	if githubProvider.commitBranch != "feature/x" {
+		t.Errorf("fetched branch = %q, want feature/x", githubProvider.commitBranch)
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+)
+
+// purgeBatchSize bounds how many deleted rows are removed per statement to keep transactions short
+const purgeBatchSize = 500
+
+// RetentionService permanently removes projects and deployments once they have been deleted for long enough
+type RetentionService struct {
+	projectRepo    project.ProjectRepository
+	deploymentRepo deployment.DeploymentRepository
+}
+
+// NewRetentionService creates a new retention service
+func NewRetentionService(projectRepo project.ProjectRepository, deploymentRepo deployment.DeploymentRepository) *RetentionService {
+	return &RetentionService{
+		projectRepo:    projectRepo,
+		deploymentRepo: deploymentRepo,
+	}
+}
+
+// PurgeDeleted permanently removes the projects and deployments deleted before the cutoff.
+// Deployments go first, so the logs and timeline of a deleted project's deployments go with them.
+func (s *RetentionService) PurgeDeleted(ctx context.Context, cutoff time.Time) (projects, deployments int64, err error) {
+	deployments, err = purgeInBatches(ctx, cutoff, s.deploymentRepo.PurgeDeletedBefore)
+	if err != nil {
+		return 0, deployments, fmt.Errorf("failed to purge deleted deployments: %w", err)
+	}
+
+	projects, err = purgeInBatches(ctx, cutoff, s.projectRepo.PurgeDeletedBefore)
+	if err != nil {
+		return projects, deployments, fmt.Errorf("failed to purge deleted projects: %w", err)
+	}
+
+	return projects, deployments, nil
+}
+
+// RunPurger purges projects and deployments deleted more than the given number of days ago
+// on every interval until the context is cancelled
+func (s *RetentionService) RunPurger(ctx context.Context, interval time.Duration, afterDays int) {
+	if interval <= 0 || afterDays <= 0 {
+		slog.Info("Purging deleted projects and deployments disabled", "interval", interval, "after_days", afterDays)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			projects, deployments, err := s.PurgeDeleted(ctx, now.AddDate(0, 0, -afterDays))
+			if err != nil {
+				slog.ErrorContext(ctx, "Purging deleted projects and deployments failed",
+					"projects", projects, "deployments", deployments, "error", err)
+				continue
+			}
+			if projects > 0 || deployments > 0 {
+				slog.InfoContext(ctx, "Purged deleted projects and deployments",
+					"projects", projects, "deployments", deployments, "after_days", afterDays)
+			}
+		}
+	}
+}
+
+// purgeInBatches calls purge until a batch comes back short, returning the total purged
+func purgeInBatches(ctx context.Context, cutoff time.Time, purge func(ctx context.Context, cutoff time.Time, limit int32) (int64, error)) (int64, error) {
+	var total int64
+	for {
+		purged, err := purge(ctx, cutoff, purgeBatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += purged
+		if purged < purgeBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestRetentionService_PurgesDeploymentsInBatches(t *testing.T) {
+	now := time.Now()
+	old := now.AddDate(0, 0, -40)
+	recent := now.AddDate(0, 0, -1)
+
+	oldProject := newTestProject(t, user.NewUserID(), "old")
+	recentProject := newTestProject(t, user.NewUserID(), "recent")
+	projects := newMockProjectRepository(oldProject, recentProject)
+	projects.deletedAt[oldProject.ID()] = old
+	projects.deletedAt[recentProject.ID()] = recent
+
+	deployments := newMockDeploymentRepository()
+	for i := 0; i < 600; i++ {
+		dep := newTestDeployment(t, oldProject, project.EnvironmentProduction, deployment.StatusDeployed)
+		deployments.deployments = append(deployments.deployments, dep)
+		deployments.deletedAt[dep.ID()] = old
+	}
+	kept := newTestDeployment(t, recentProject, project.EnvironmentProduction, deployment.StatusDeployed)
+	deployments.deployments = append(deployments.deployments, kept)
+	deployments.deletedAt[kept.ID()] = recent
+	svc := service.NewRetentionService(projects, deployments)
+
+	purgedProjects, purged, err := svc.PurgeDeleted(context.Background(), now.AddDate(0, 0, -30))
+	if err != nil {
+		t.Fatalf("PurgeDeleted() error = %v", err)
+	}
+	if purgedProjects != 1 {
+		t.Errorf("PurgeDeleted() purged %d projects, want 1", purgedProjects)
+	}
+	if purged != 600 {
+		t.Errorf("PurgeDeleted() purged %d deployments, want 600", purged)
+	}
+	if deployments.purges != 2 {
+		t.Errorf("PurgeDeleted() ran %d batches, want 2", deployments.purges)
+	}
+	if len(deployments.deployments) != 1 || deployments.deployments[0] != kept {
+		t.Errorf("PurgeDeleted() kept %d deployments, want only the one deleted within the retention period", len(deployments.deployments))
+	}
+}
+
+func TestRetentionService_ReportsProjectPurgeFailure(t *testing.T) {
+	proj := newTestProject(t, user.NewUserID(), "my-app")
+	dep := newTestDeployment(t, proj, project.EnvironmentProduction, deployment.StatusDeployed)
+	deployments := newMockDeploymentRepository(dep)
+	deployments.deletedAt[dep.ID()] = time.Now().AddDate(0, 0, -40)
+	projects := newMockProjectRepository()
+	projects.shouldError = true
+	svc := service.NewRetentionService(projects, deployments)
+
+	_, purged, err := svc.PurgeDeleted(context.Background(), time.Now().AddDate(0, 0, -30))
+	if !errors.Is(err, errRepository) {
+		t.Errorf("PurgeDeleted() error = %v, want %v", err, errRepository)
+	}
+	if purged != 1 {
+		t.Errorf("PurgeDeleted() purged %d deployments before failing, want 1", purged)
+	}
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/shell"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// maxListedShellSessions is how many of a project's most recent shell sessions are listed
+const maxListedShellSessions = 50
+
+// ErrShellsUnavailable is returned when no shell broker is configured
+var ErrShellsUnavailable = errors.New("interactive shells are unavailable")
+
+// ShellBroker opens interactive shells in the running containers of projects
+type ShellBroker interface {
+	// OpenShell opens a shell in a running task of an environment, returning it with the task's ARN
+	OpenShell(ctx context.Context, proj *project.Project, env project.Environment) (shell.Conn, string, error)
+}
+
+// Terminal is the user's end of a shell session
+type Terminal interface {
+	// ReadInput blocks until the user types, returning what they typed, or resizes their terminal,
+	// returning its new size with no input
+	ReadInput() (input []byte, cols, rows int, err error)
+
+	// Write shows what the shell wrote
+	io.Writer
+}
+
+// ShellLimits bounds how long shell sessions last
+type ShellLimits struct {
+	MaxDuration time.Duration // Sessions are ended after this long
+	IdleTimeout time.Duration // Sessions are ended when nothing is typed for this long
+}
+
+// ShellService brokers interactive shells in the running containers of projects, so users can debug them
+// without access to AWS, and keeps the record of who opened which shell
+type ShellService struct {
+	projectRepo project.ProjectRepository
+	sessionRepo shell.SessionRepository
+	broker      ShellBroker
+	limits      ShellLimits
+}
+
+// NewShellService creates a new shell service
+func NewShellService(projectRepo project.ProjectRepository, sessionRepo shell.SessionRepository, limits ShellLimits) *ShellService {
+	return &ShellService{
+		projectRepo: projectRepo,
+		sessionRepo: sessionRepo,
+		limits:      limits,
+	}
+}
+
+// SetShellBroker sets the component that opens shells (optional)
+func (s *ShellService) SetShellBroker(broker ShellBroker) {
+	s.broker = broker
+}
+
+// StartSession creates a session for the user to open a shell in an environment of a project. The user's
+// terminal then connects to the session's WebSocket with the returned token within shell.ConnectWindow.
+// The session records the user who opened it.
+func (s *ShellService) StartSession(ctx context.Context, projectID, userID string, req *dto.StartShellRequest) (*dto.StartShellResponse, error) {
+	if s.broker == nil {
+		return nil, ErrShellsUnavailable
+	}
+
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+	proj, err := findProject(ctx, s.projectRepo, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if proj.IsDeleting() {
+		return nil, project.ErrProjectDeleting
+	}
+
+	env, err := project.NewEnvironment(req.Environment)
+	if err != nil || !proj.HasEnvironment(env) {
+		return nil, project.ErrEnvironmentNotFound
+	}
+
+	session, token, err := shell.NewSession(proj, uid, env)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.Save(ctx, session); err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Shell session created",
+		"project_id", proj.ID().String(),
+		"environment", env.String(),
+		"session_id", session.ID().String(),
+		"user_id", uid.String(),
+	)
+
+	now := time.Now()
+	return &dto.StartShellResponse{
+		Session:    toShellSessionDTO(session, now),
+		Token:      token,
+		ConnectURL: fmt.Sprintf("/api/v1/shell/sessions/%s/connect?token=%s", session.ID().String(), url.QueryEscape(token)),
+		ExpiresAt:  session.CreatedAt().Add(shell.ConnectWindow).UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// AttachSession connects the user's terminal to a session's shell and relays between them until either
+// side closes, nothing is typed for the idle timeout or the session reaches its maximum duration. The
+// token is used up, so a session is only ever connected to once. attach is called once the shell is
+// open to get the user's terminal; errors returned before it was called are returned to the caller
+// rather than shown in the terminal.
+func (s *ShellService) AttachSession(ctx context.Context, sessionID, token string, attach func() (Terminal, error)) (*dto.ShellSessionResponse, error) {
+	if s.broker == nil {
+		return nil, ErrShellsUnavailable
+	}
+
+	sid, err := shell.ParseSessionID(sessionID)
+	if err != nil {
+		return nil, shell.ErrSessionNotFound
+	}
+	session, err := s.sessionRepo.FindByID(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	if err := session.Claim(token, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.Claim(ctx, session); err != nil {
+		return nil, err
+	}
+
+	proj, err := s.projectRepo.FindByID(ctx, session.ProjectID())
+	if err != nil {
+		s.end(ctx, session, shell.EndFailed, 0, 0, err)
+		return nil, err
+	}
+
+	conn, taskArn, err := s.broker.OpenShell(ctx, proj, session.Environment())
+	if err != nil {
+		s.end(ctx, session, shell.EndFailed, 0, 0, err)
+		return nil, err
+	}
+	session.Open(taskArn)
+	if err := s.sessionRepo.Save(ctx, session); err != nil {
+		slog.WarnContext(ctx, "Failed to record shell session task", "session_id", session.ID().String(), "error", err)
+	}
+
+	slog.InfoContext(ctx, "Shell session opened",
+		"project_id", proj.ID().String(),
+		"environment", session.Environment().String(),
+		"session_id", session.ID().String(),
+		"user_id", session.UserID().String(),
+		"task_arn", taskArn,
+	)
+
+	terminal, err := attach()
+	if err != nil {
+		conn.Close()
+		s.end(ctx, session, shell.EndFailed, 0, 0, err)
+		return nil, err
+	}
+
+	reason, bytesIn, bytesOut, err := s.relay(ctx, conn, terminal)
+	s.end(ctx, session, reason, bytesIn, bytesOut, err)
+	return toShellSessionDTO(session, time.Now()), nil
+}
+
+// relay copies what the user types into the shell and what the shell writes to the terminal until the
+// session ends, and reports why it did and how many bytes went each way
+func (s *ShellService) relay(ctx context.Context, conn shell.Conn, terminal Terminal) (shell.EndReason, int64, int64, error) {
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, s.limits.MaxDuration)
+	defer cancel()
+
+	type ending struct {
+		reason shell.EndReason
+		err    error
+	}
+	var bytesIn, bytesOut atomic.Int64
+	ended := make(chan ending, 2)
+	typed := make(chan struct{}, 1)
+
+	go func() {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := conn.Read(buf)
+			if n > 0 {
+				bytesOut.Add(int64(n))
+				if _, werr := terminal.Write(buf[:n]); werr != nil {
+					ended <- ending{reason: shell.EndClosed}
+					return
+				}
+			}
+			if errors.Is(err, io.EOF) {
+				ended <- ending{reason: shell.EndExited}
+				return
+			}
+			if err != nil {
+				ended <- ending{reason: shell.EndFailed, err: err}
+				return
+			}
+		}
+	}()
+
+	go func() {
+		for {
+			input, cols, rows, err := terminal.ReadInput()
+			if err != nil {
+				ended <- ending{reason: shell.EndClosed}
+				return
+			}
+			if len(input) == 0 {
+				if err := conn.Resize(cols, rows); err != nil {
+					ended <- ending{reason: shell.EndFailed, err: err}
+					return
+				}
+				continue
+			}
+			if _, err := conn.Write(input); err != nil {
+				ended <- ending{reason: shell.EndFailed, err: err}
+				return
+			}
+			bytesIn.Add(int64(len(input)))
+			select {
+			case typed <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	idle := time.NewTimer(s.limits.IdleTimeout)
+	defer idle.Stop()
+
+	for {
+		select {
+		case e := <-ended:
+			return e.reason, bytesIn.Load(), bytesOut.Load(), e.err
+		case <-typed:
+			idle.Reset(s.limits.IdleTimeout)
+		case <-idle.C:
+			return shell.EndIdleTimeout, bytesIn.Load(), bytesOut.Load(), nil
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return shell.EndTimeLimit, bytesIn.Load(), bytesOut.Load(), nil
+			}
+			return shell.EndClosed, bytesIn.Load(), bytesOut.Load(), nil
+		}
+	}
+}
+
+// end records how a session ended. It is saved even if the request that attached it was cancelled.
+func (s *ShellService) end(ctx context.Context, session *shell.Session, reason shell.EndReason, bytesIn, bytesOut int64, err error) {
+	session.End(reason, bytesIn, bytesOut, err)
+
+	ctx = context.WithoutCancel(ctx)
+	if err := s.sessionRepo.Save(ctx, session); err != nil {
+		slog.ErrorContext(ctx, "Failed to record shell session", "session_id", session.ID().String(), "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "Shell session ended",
+		"session_id", session.ID().String(),
+		"user_id", session.UserID().String(),
+		"end_reason", reason.String(),
+		"duration_seconds", int64(session.Duration(time.Now()).Seconds()),
+	)
+}
+
+// ListSessions returns the most recent shell sessions of a project, newest first
+func (s *ShellService) ListSessions(ctx context.Context, projectID string) (*dto.ShellSessionListResponse, error) {
+	proj, err := findProject(ctx, s.projectRepo, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessionRepo.FindByProjectID(ctx, proj.ID(), maxListedShellSessions)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	response := &dto.ShellSessionListResponse{
+		ProjectID: proj.ID().String(),
+		Sessions:  make([]*dto.ShellSessionResponse, len(sessions)),
+	}
+	for i, session := range sessions {
+		response.Sessions[i] = toShellSessionDTO(session, now)
+	}
+	return response, nil
+}
+
+func toShellSessionDTO(session *shell.Session, now time.Time) *dto.ShellSessionResponse {
+	status := session.Status().String()
+	if session.IsExpired(now) {
+		status = "EXPIRED"
+	}
+
+	response := &dto.ShellSessionResponse{
+		ID:              session.ID().String(),
+		ProjectID:       session.ProjectID().String(),
+		UserID:          session.UserID().String(),
+		Environment:     session.Environment().String(),
+		Status:          status,
+		TaskArn:         session.TaskArn(),
+		EndReason:       session.EndReason().String(),
+		Error:           session.ErrorMessage(),
+		BytesIn:         session.BytesIn(),
+		BytesOut:        session.BytesOut(),
+		CreatedAt:       session.CreatedAt().UTC().Format(time.RFC3339),
+		DurationSeconds: int64(session.Duration(now).Seconds()),
+	}
+	if startedAt := session.StartedAt(); startedAt != nil {
+		response.StartedAt = startedAt.UTC().Format(time.RFC3339)
+	}
+	if endedAt := session.EndedAt(); endedAt != nil {
+		response.EndedAt = endedAt.UTC().Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,192 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/shell"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// mockShellSessions keeps sessions in memory
+type mockShellSessions struct {
+	mu       sync.Mutex
+	sessions map[shell.SessionID]*shell.Session
+}
+
+func (m *mockShellSessions) Save(ctx context.Context, session *shell.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID()] = session
+	return nil
+}
+
+func (m *mockShellSessions) Claim(ctx context.Context, session *shell.Session) error {
+	return nil
+}
+
+func (m *mockShellSessions) FindByID(ctx context.Context, id shell.SessionID) (*shell.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[id]
+	if !ok {
+		return nil, shell.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (m *mockShellSessions) FindByProjectID(ctx context.Context, projectID project.ProjectID, limit int) ([]*shell.Session, error) {
+	return nil, nil
+}
+
+// mockShellConn writes its output then exits, or blocks until closed when it has none
+type mockShellConn struct {
+	output io.Reader
+	closed chan struct{}
+	once   sync.Once
+	typed  strings.Builder
+}
+
+func (m *mockShellConn) Read(p []byte) (int, error) {
+	if m.output != nil {
+		return m.output.Read(p)
+	}
+	<-m.closed
+	return 0, errors.New("closed")
+}
+
+func (m *mockShellConn) Write(p []byte) (int, error) {
+	return m.typed.Write(p)
+}
+
+func (m *mockShellConn) Resize(cols, rows int) error {
+	return nil
+}
+
+func (m *mockShellConn) Close() error {
+	m.once.Do(func() { close(m.closed) })
+	return nil
+}
+
+// mockShellBroker opens its conn in a task
+type mockShellBroker struct {
+	conn *mockShellConn
+}
+
+func (m *mockShellBroker) OpenShell(ctx context.Context, proj *project.Project, env project.Environment) (shell.Conn, string, error) {
+	return m.conn, "arn:aws:ecs:us-east-1:123456789012:task/snapdeploy/abc123", nil
+}
+
+// mockTerminal records what it is shown and blocks for input until closed
+type mockTerminal struct {
+	mu     sync.Mutex
+	shown  strings.Builder
+	closed chan struct{}
+}
+
+func (m *mockTerminal) ReadInput() ([]byte, int, int, error) {
+	<-m.closed
+	return nil, 0, 0, io.EOF
+}
+
+func (m *mockTerminal) Write(p []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.shown.Write(p)
+}
+
+func TestShellService_AttachSession(t *testing.T) {
+	ctx := context.Background()
+	owner := user.NewUserID()
+	proj, err := project.NewProject(owner, "https://github.com/acme/app", "npm install", "", "npm start", "NODE", "", true, "")
+	if err != nil {
+		t.Fatalf("NewProject() error = %v", err)
+	}
+
+	newService := func(conn *mockShellConn, limits service.ShellLimits) (*service.ShellService, *mockShellSessions) {
+		sessions := &mockShellSessions{sessions: map[shell.SessionID]*shell.Session{}}
+		svc := service.NewShellService(newMockProjectRepository(proj), sessions, limits)
+		svc.SetShellBroker(&mockShellBroker{conn: conn})
+		return svc, sessions
+	}
+	limits := service.ShellLimits{MaxDuration: time.Minute, IdleTimeout: time.Minute}
+
+	t.Run("relays until the shell exits", func(t *testing.T) {
+		conn := &mockShellConn{output: strings.NewReader("root@app:/app# "), closed: make(chan struct{})}
+		svc, _ := newService(conn, limits)
+
+		started, err := svc.StartSession(ctx, proj.ID().String(), owner.String(), &dto.StartShellRequest{})
+		if err != nil {
+			t.Fatalf("StartSession() error = %v", err)
+		}
+		if started.Session.Status != shell.StatusPending.String() || !strings.Contains(started.ConnectURL, started.Session.ID) {
+			t.Errorf("StartSession() = %+v, want a pending session to connect to", started)
+		}
+
+		terminal := &mockTerminal{closed: make(chan struct{})}
+		defer close(terminal.closed)
+		attach := func() (service.Terminal, error) { return terminal, nil }
+
+		ended, err := svc.AttachSession(ctx, started.Session.ID, started.Token, attach)
+		if err != nil {
+			t.Fatalf("AttachSession() error = %v", err)
+		}
+		if ended.Status != shell.StatusEnded.String() || ended.EndReason != shell.EndExited.String() || ended.TaskArn == "" {
+			t.Errorf("session = %+v, want it ended when the shell exited", ended)
+		}
+		if terminal.shown.String() != "root@app:/app# " || ended.BytesOut != int64(len("root@app:/app# ")) {
+			t.Errorf("terminal shown %q (%d bytes recorded), want the shell's output", terminal.shown.String(), ended.BytesOut)
+		}
+
+		if _, err := svc.AttachSession(ctx, started.Session.ID, started.Token, attach); !errors.Is(err, shell.ErrInvalidToken) {
+			t.Errorf("second AttachSession() error = %v, want the token used up", err)
+		}
+	})
+
+	t.Run("ends idle sessions", func(t *testing.T) {
+		conn := &mockShellConn{closed: make(chan struct{})}
+		svc, _ := newService(conn, service.ShellLimits{MaxDuration: time.Minute, IdleTimeout: 20 * time.Millisecond})
+
+		started, err := svc.StartSession(ctx, proj.ID().String(), owner.String(), &dto.StartShellRequest{})
+		if err != nil {
+			t.Fatalf("StartSession() error = %v", err)
+		}
+		terminal := &mockTerminal{closed: make(chan struct{})}
+		defer close(terminal.closed)
+
+		ended, err := svc.AttachSession(ctx, started.Session.ID, started.Token, func() (service.Terminal, error) { return terminal, nil })
+		if err != nil {
+			t.Fatalf("AttachSession() error = %v", err)
+		}
+		if ended.EndReason != shell.EndIdleTimeout.String() {
+			t.Errorf("end reason = %s, want IDLE_TIMEOUT", ended.EndReason)
+		}
+		select {
+		case <-conn.closed:
+		default:
+			t.Error("shell left open, want it closed when the session ends")
+		}
+	})
+
+	t.Run("records who opened it", func(t *testing.T) {
+		svc, _ := newService(&mockShellConn{closed: make(chan struct{})}, limits)
+
+		// Access was checked by the middleware, an operator's sessions are recorded as theirs
+		operator := user.NewUserID()
+		started, err := svc.StartSession(ctx, proj.ID().String(), operator.String(), &dto.StartShellRequest{})
+		if err != nil {
+			t.Fatalf("StartSession() error = %v", err)
+		}
+		if started.Session.UserID != operator.String() {
+			t.Errorf("UserID = %s, want %s", started.Session.UserID, operator)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+)
+
+// UptimeSource reports how a project's health checks went
+type UptimeSource interface {
+	// Uptime returns how many health checks of the project were made since the time, and how many succeeded
+	Uptime(ctx context.Context, projectID project.ProjectID, since time.Time) (succeeded, total int64, err error)
+}
+
+// uptimePeriod is a period the uptime of status pages is shown over
+type uptimePeriod struct {
+	name     string
+	duration time.Duration
+}
+
+// uptimePeriods are the periods status pages show the uptime over, shortest first
+var uptimePeriods = []uptimePeriod{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// StatusPageService serves the public status pages projects opt in to
+type StatusPageService struct {
+	projectRepo    project.ProjectRepository
+	deploymentRepo deployment.DeploymentRepository
+	uptime         UptimeSource
+	baseDomain     string
+}
+
+// NewStatusPageService creates a new status page service
+func NewStatusPageService(projectRepo project.ProjectRepository, deploymentRepo deployment.DeploymentRepository) *StatusPageService {
+	return &StatusPageService{
+		projectRepo:    projectRepo,
+		deploymentRepo: deploymentRepo,
+	}
+}
+
+// SetBaseDomain sets the domain projects are served on subdomains of, snapdeploy.app by default
+func (s *StatusPageService) SetBaseDomain(domain string) {
+	s.baseDomain = domain
+}
+
+// SetUptimeSource sets where the uptime of status pages comes from. Without one, status pages show no uptime.
+func (s *StatusPageService) SetUptimeSource(uptime UptimeSource) {
+	s.uptime = uptime
+}
+
+// GetStatusPage returns the status page of the project with the custom domain. Returns project.ErrProjectNotFound
+// for projects that didn't opt in too, so status pages don't tell which domains are taken.
+func (s *StatusPageService) GetStatusPage(ctx context.Context, slug string) (*dto.ProjectStatusPageResponse, error) {
+	if slug == "" || len(slug) > 63 {
+		return nil, project.ErrProjectNotFound
+	}
+	projects, err := s.projectRepo.FindByCustomDomains(ctx, []string{slug})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find project: %w", err)
+	}
+	if len(projects) == 0 || !projects[0].PublicStatusPage() {
+		return nil, project.ErrProjectNotFound
+	}
+	proj := projects[0]
+
+	response := &dto.ProjectStatusPageResponse{
+		Name:   proj.CustomDomain().String(),
+		URL:    projectDeploymentURL(s.baseDomain, proj, project.EnvironmentProduction),
+		Status: "NONE",
+		Uptime: []*dto.UptimeResponse{},
+	}
+
+	latest, err := s.deploymentRepo.FindLatestInEnvironment(ctx, proj.ID(), project.EnvironmentProduction)
+	if err != nil && !errors.Is(err, deployment.ErrDeploymentNotFound) {
+		return nil, fmt.Errorf("failed to find latest deployment: %w", err)
+	}
+	if latest != nil {
+		response.Status = latest.Status().String()
+	}
+
+	deployed, err := s.deploymentRepo.FindLatestDeployedInEnvironment(ctx, proj.ID(), project.EnvironmentProduction)
+	if err != nil && !errors.Is(err, deployment.ErrDeploymentNotFound) {
+		return nil, fmt.Errorf("failed to find latest successful deployment: %w", err)
+	}
+	if deployed != nil {
+		response.LastDeployedAt = deployed.UpdatedAt().Format(time.RFC3339)
+	}
+
+	if s.uptime != nil {
+		now := time.Now()
+		for _, period := range uptimePeriods {
+			succeeded, total, err := s.uptime.Uptime(ctx, proj.ID(), now.Add(-period.duration))
+			if err != nil {
+				// The rest of the page is still worth showing
+				slog.WarnContext(ctx, "Failed to compute uptime for the status page", "project_id", proj.ID().String(), "error", err)
+				break
+			}
+			if total == 0 {
+				continue
+			}
+			response.Uptime = append(response.Uptime, &dto.UptimeResponse{
+				Period:  period.name,
+				Percent: math.Round(float64(succeeded)/float64(total)*10000) / 100,
+				Checks:  total,
+			})
+		}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// mockUptime reports one failed health check every day, out of 100 a day
+type mockUptime struct{}
+
+func (mockUptime) Uptime(ctx context.Context, projectID project.ProjectID, since time.Time) (int64, int64, error) {
+	days := int64(time.Since(since).Round(time.Hour) / (24 * time.Hour))
+	return 99 * days, 100 * days, nil
+}
+
+// publishStatusPage sets a project up to publish its status page
+func publishStatusPage(proj *project.Project) error {
+	proj.SetPublicStatusPage(true)
+	return nil
+}
+
+func TestStatusPageService_GetStatusPage_OnlyPublicProjects(t *testing.T) {
+	projects := newMockProjectRepository(newTestProject(t, user.NewUserID(), "private-app"))
+	svc := service.NewStatusPageService(projects, newMockDeploymentRepository())
+
+	for _, slug := range []string{"private-app", "unknown-app", ""} {
+		if _, err := svc.GetStatusPage(context.Background(), slug); !errors.Is(err, project.ErrProjectNotFound) {
+			t.Errorf("GetStatusPage(%q) error = %v, want ErrProjectNotFound", slug, err)
+		}
+	}
+}
+
+func TestStatusPageService_GetStatusPage(t *testing.T) {
+	proj := newTestProject(t, user.NewUserID(), "my-app", publishStatusPage)
+	projects := newMockProjectRepository(proj)
+	deployments := newMockDeploymentRepository()
+	svc := service.NewStatusPageService(projects, deployments)
+
+	page, err := svc.GetStatusPage(context.Background(), "my-app")
+	if err != nil {
+		t.Fatalf("GetStatusPage() error = %v", err)
+	}
+	if page.Status != "NONE" || page.LastDeployedAt != "" || len(page.Uptime) != 0 {
+		t.Errorf("GetStatusPage() before the first deployment = %+v", page)
+	}
+	if page.Name != "my-app" || page.URL != "https://my-app.snapdeploy.app" {
+		t.Errorf("Name, URL = %q, %q", page.Name, page.URL)
+	}
+
+	deployed := newTestDeployment(t, proj, project.EnvironmentProduction, deployment.StatusDeployed)
+	building := newTestDeployment(t, proj, project.EnvironmentProduction, deployment.StatusBuilding)
+	deployments.deployments = append(deployments.deployments, deployed, building)
+	svc.SetUptimeSource(mockUptime{})
+
+	page, err = svc.GetStatusPage(context.Background(), "my-app")
+	if err != nil {
+		t.Fatalf("GetStatusPage() error = %v", err)
+	}
+	if page.Status != "BUILDING" {
+		t.Errorf("Status = %q, want the latest deployment's BUILDING", page.Status)
+	}
+	if page.LastDeployedAt != deployed.UpdatedAt().Format(time.RFC3339) {
+		t.Errorf("LastDeployedAt = %q, want when the deployed deployment was updated", page.LastDeployedAt)
+	}
+	want := []*dto.UptimeResponse{
+		{Period: "24h", Percent: 99, Checks: 100},
+		{Period: "7d", Percent: 99, Checks: 700},
+		{Period: "30d", Percent: 99, Checks: 3000},
+	}
+	if !reflect.DeepEqual(page.Uptime, want) {
+		for _, u := range page.Uptime {
+			t.Logf("uptime %+v", *u)
+		}
+		t.Errorf("Uptime differs from the health checks")
+	}
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/incident"
+)
+
+const (
+	// resolvedHistoryWindow is how far back resolved incidents are shown on the status page
+	resolvedHistoryWindow = 7 * 24 * time.Hour
+
+	// bannerCacheTTL bounds how often the banner is reloaded, since it is attached to every API response
+	bannerCacheTTL = 30 * time.Second
+)
+
+// Overall platform statuses reported on the status page
+const (
+	SystemStatusOperational = "operational"
+	SystemStatusMaintenance = "maintenance"
+	SystemStatusDegraded    = "degraded"
+	SystemStatusMajorOutage = "major_outage"
+)
+
+// SystemStatusService handles platform status and incident use cases
+type SystemStatusService struct {
+	incidentRepo incident.IncidentRepository
+
+	mu               sync.Mutex
+	banner           *dto.SystemBanner
+	bannerLoadedAt   time.Time
+	bannerLoading    bool // A request is reloading the banner, the others keep showing the last one meanwhile
+	bannerGeneration int  // Bumped when incidents change, so a reload started before isn't cached
+}
+
+// NewSystemStatusService creates a new system status service
+func NewSystemStatusService(incidentRepo incident.IncidentRepository) *SystemStatusService {
+	return &SystemStatusService{
+		incidentRepo: incidentRepo,
+	}
+}
+
+// GetStatus returns the current platform status with active, scheduled and recently resolved incidents
+func (s *SystemStatusService) GetStatus(ctx context.Context) (*dto.SystemStatusResponse, error) {
+	now := time.Now()
+
+	unresolved, err := s.incidentRepo.FindUnresolved(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unresolved incidents: %w", err)
+	}
+
+	resolved, err := s.incidentRepo.FindResolvedSince(ctx, now.Add(-resolvedHistoryWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resolved incidents: %w", err)
+	}
+
+	active := activeIncidents(unresolved, now)
+
+	response := &dto.SystemStatusResponse{
+		Status:    overallStatus(active),
+		Banner:    s.toBanner(mostSevere(active)),
+		Active:    make([]*dto.IncidentResponse, 0, len(active)),
+		Scheduled: []*dto.IncidentResponse{},
+		Resolved:  make([]*dto.IncidentResponse, 0, len(resolved)),
+		UpdatedAt: now.Format(time.RFC3339),
+	}
+
+	for _, inc := range active {
+		response.Active = append(response.Active, s.toDTO(inc))
+	}
+	for _, inc := range unresolved {
+		if inc.IsScheduled(now) {
+			response.Scheduled = append(response.Scheduled, s.toDTO(inc))
+		}
+	}
+	for _, inc := range resolved {
+		response.Resolved = append(response.Resolved, s.toDTO(inc))
+	}
+
+	return response, nil
+}
+
+// CurrentBanner returns the banner for the most severe active incident, or nil if there is none
+// Results are cached briefly; on lookup failure the last known banner is returned. The lookup runs without
+// holding the lock, and requests arriving while one reloads the banner get the last known one.
+func (s *SystemStatusService) CurrentBanner(ctx context.Context) *dto.SystemBanner {
+	s.mu.Lock()
+	if s.bannerLoading || (!s.bannerLoadedAt.IsZero() && time.Since(s.bannerLoadedAt) < bannerCacheTTL) {
+		defer s.mu.Unlock()
+		return s.banner
+	}
+	s.bannerLoading = true
+	generation := s.bannerGeneration
+	s.mu.Unlock()
+
+	unresolved, err := s.incidentRepo.FindUnresolved(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bannerLoading = false
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load incidents for banner", "error", err)
+		return s.banner
+	}
+
+	banner := s.toBanner(mostSevere(activeIncidents(unresolved, time.Now())))
+	if generation == s.bannerGeneration {
+		s.banner = banner
+		s.bannerLoadedAt = time.Now()
+	}
+	return banner
+}
+
+// CreateIncident posts a new incident or maintenance notice
+func (s *SystemStatusService) CreateIncident(ctx context.Context, operatorID string, req *dto.CreateIncidentRequest) (*dto.IncidentResponse, error) {
+	var startsAt time.Time
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+
+	inc, err := incident.NewIncident(req.Kind, req.Severity, req.Title, req.Message, startsAt, req.EndsAt, operatorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create incident entity: %w", err)
+	}
+
+	if err := s.incidentRepo.Save(ctx, inc); err != nil {
+		return nil, fmt.Errorf("failed to save incident: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Incident posted", "incident_id", inc.ID().String(), "operator_id", operatorID, "title", inc.Title().String())
+	s.invalidateBanner()
+
+	return s.toDTO(inc), nil
+}
+
+// UpdateIncident updates the details of an unresolved incident
+func (s *SystemStatusService) UpdateIncident(ctx context.Context, incidentID string, req *dto.UpdateIncidentRequest) (*dto.IncidentResponse, error) {
+	id, err := incident.ParseIncidentID(incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid incident ID: %w", err)
+	}
+
+	inc, err := s.incidentRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := inc.Update(req.Severity, req.Title, req.Message, req.EndsAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.incidentRepo.Save(ctx, inc); err != nil {
+		return nil, fmt.Errorf("failed to save incident: %w", err)
+	}
+
+	s.invalidateBanner()
+
+	return s.toDTO(inc), nil
+}
+
+// ResolveIncident marks an incident as resolved
+func (s *SystemStatusService) ResolveIncident(ctx context.Context, incidentID string) (*dto.IncidentResponse, error) {
+	id, err := incident.ParseIncidentID(incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid incident ID: %w", err)
+	}
+
+	inc, err := s.incidentRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := inc.Resolve(); err != nil {
+		return nil, err
+	}
+
+	if err := s.incidentRepo.Save(ctx, inc); err != nil {
+		return nil, fmt.Errorf("failed to save incident: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Incident resolved", "incident_id", inc.ID().String())
+	s.invalidateBanner()
+
+	return s.toDTO(inc), nil
+}
+
+// invalidateBanner forces the next CurrentBanner call to reload from the repository
+func (s *SystemStatusService) invalidateBanner() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bannerLoadedAt = time.Time{}
+	s.bannerGeneration++
+}
+
+// activeIncidents filters incidents that are currently affecting the platform
+func activeIncidents(incidents []*incident.Incident, now time.Time) []*incident.Incident {
+	active := make([]*incident.Incident, 0, len(incidents))
+	for _, inc := range incidents {
+		if inc.IsActive(now) {
+			active = append(active, inc)
+		}
+	}
+	return active
+}
+
+// mostSevere returns the most severe incident, preferring the most recently started on ties
+func mostSevere(incidents []*incident.Incident) *incident.Incident {
+	var worst *incident.Incident
+	for _, inc := range incidents {
+		if worst == nil ||
+			inc.Severity().Rank() > worst.Severity().Rank() ||
+			(inc.Severity() == worst.Severity() && inc.StartsAt().After(worst.StartsAt())) {
+			worst = inc
+		}
+	}
+	return worst
+}
+
+// overallStatus derives the platform status from the active incidents
+func overallStatus(active []*incident.Incident) string {
+	status := SystemStatusOperational
+	for _, inc := range active {
+		if inc.Kind() == incident.KindMaintenance {
+			if status == SystemStatusOperational {
+				status = SystemStatusMaintenance
+			}
+			continue
+		}
+		if inc.Severity() == incident.SeverityCritical {
+			return SystemStatusMajorOutage
+		}
+		status = SystemStatusDegraded
+	}
+	return status
+}
+
+// toBanner converts an incident to the banner shown in API responses
+func (s *SystemStatusService) toBanner(inc *incident.Incident) *dto.SystemBanner {
+	if inc == nil {
+		return nil
+	}
+
+	return &dto.SystemBanner{
+		IncidentID: inc.ID().String(),
+		Kind:       inc.Kind().String(),
+		Severity:   inc.Severity().String(),
+		Title:      inc.Title().String(),
+		Message:    inc.Message().String(),
+	}
+}
+
+// toDTO converts domain incident to DTO
+func (s *SystemStatusService) toDTO(inc *incident.Incident) *dto.IncidentResponse {
+	response := &dto.IncidentResponse{
+		ID:        inc.ID().String(),
+		Kind:      inc.Kind().String(),
+		Severity:  inc.Severity().String(),
+		Title:     inc.Title().String(),
+		Message:   inc.Message().String(),
+		StartsAt:  inc.StartsAt().Format(time.RFC3339),
+		CreatedAt: inc.CreatedAt().Format(time.RFC3339),
+		UpdatedAt: inc.UpdatedAt().Format(time.RFC3339),
+	}
+
+	if inc.EndsAt() != nil {
+		response.EndsAt = inc.EndsAt().Format(time.RFC3339)
+	}
+	if inc.ResolvedAt() != nil {
+		response.ResolvedAt = inc.ResolvedAt().Format(time.RFC3339)
+	}
+
+	return response
+}
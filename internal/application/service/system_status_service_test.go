@@ -0,0 +1,228 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/incident"
+)
+
+// Mock implementations
+type mockIncidentRepository struct {
+	incidents   map[string]*incident.Incident
+	shouldError bool
+}
+
+func newMockIncidentRepository() *mockIncidentRepository {
+	return &mockIncidentRepository{
+		incidents: make(map[string]*incident.Incident),
+	}
+}
+
+func (m *mockIncidentRepository) Save(ctx context.Context, inc *incident.Incident) error {
+	if m.shouldError {
+		return errors.New("repository error")
+	}
+	m.incidents[inc.ID().String()] = inc
+	return nil
+}
+
+func (m *mockIncidentRepository) FindByID(ctx context.Context, id incident.IncidentID) (*incident.Incident, error) {
+	if m.shouldError {
+		return nil, errors.New("repository error")
+	}
+	inc, ok := m.incidents[id.String()]
+	if !ok {
+		return nil, incident.ErrIncidentNotFound
+	}
+	return inc, nil
+}
+
+func (m *mockIncidentRepository) FindUnresolved(ctx context.Context) ([]*incident.Incident, error) {
+	if m.shouldError {
+		return nil, errors.New("repository error")
+	}
+	var result []*incident.Incident
+	for _, inc := range m.incidents {
+		if !inc.IsResolved() {
+			result = append(result, inc)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockIncidentRepository) FindResolvedSince(ctx context.Context, since time.Time) ([]*incident.Incident, error) {
+	if m.shouldError {
+		return nil, errors.New("repository error")
+	}
+	var result []*incident.Incident
+	for _, inc := range m.incidents {
+		if inc.IsResolved() && !inc.ResolvedAt().Before(since) {
+			result = append(result, inc)
+		}
+	}
+	return result, nil
+}
+
+func TestSystemStatusService_GetStatus(t *testing.T) {
+	ctx := context.Background()
+	future := time.Now().Add(24 * time.Hour)
+
+	tests := []struct {
+		name          string
+		incidents     []*dto.CreateIncidentRequest
+		wantStatus    string
+		wantBanner    string
+		wantActive    int
+		wantScheduled int
+	}{
+		{
+			name:       "no incidents",
+			wantStatus: service.SystemStatusOperational,
+		},
+		{
+			name: "active maintenance",
+			incidents: []*dto.CreateIncidentRequest{
+				{Kind: "MAINTENANCE", Severity: "INFO", Title: "Database upgrade", Message: "Deployments are paused"},
+			},
+			wantStatus: service.SystemStatusMaintenance,
+			wantBanner: "Database upgrade",
+			wantActive: 1,
+		},
+		{
+			name: "critical incident wins banner",
+			incidents: []*dto.CreateIncidentRequest{
+				{Kind: "INCIDENT", Severity: "WARNING", Title: "Slow logs", Message: "Log streaming is delayed"},
+				{Kind: "INCIDENT", Severity: "CRITICAL", Title: "Builds delayed", Message: "Builds delayed due to AWS incident"},
+			},
+			wantStatus: service.SystemStatusMajorOutage,
+			wantBanner: "Builds delayed",
+			wantActive: 2,
+		},
+		{
+			name: "scheduled maintenance is not active",
+			incidents: []*dto.CreateIncidentRequest{
+				{Kind: "MAINTENANCE", Severity: "INFO", Title: "Planned upgrade", Message: "Upgrade window", StartsAt: &future},
+			},
+			wantStatus:    service.SystemStatusOperational,
+			wantScheduled: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := service.NewSystemStatusService(newMockIncidentRepository())
+
+			for _, req := range tt.incidents {
+				if _, err := svc.CreateIncident(ctx, "user_operator", req); err != nil {
+					t.Fatalf("CreateIncident() error = %v", err)
+				}
+			}
+
+			status, err := svc.GetStatus(ctx)
+			if err != nil {
+				t.Fatalf("GetStatus() error = %v", err)
+			}
+
+			if status.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", status.Status, tt.wantStatus)
+			}
+			if len(status.Active) != tt.wantActive {
+				t.Errorf("len(Active) = %d, want %d", len(status.Active), tt.wantActive)
+			}
+			if len(status.Scheduled) != tt.wantScheduled {
+				t.Errorf("len(Scheduled) = %d, want %d", len(status.Scheduled), tt.wantScheduled)
+			}
+
+			switch {
+			case tt.wantBanner == "" && status.Banner != nil:
+				t.Errorf("Banner = %v, want nil", status.Banner.Title)
+			case tt.wantBanner != "" && (status.Banner == nil || status.Banner.Title != tt.wantBanner):
+				t.Errorf("Banner = %v, want %v", status.Banner, tt.wantBanner)
+			}
+		})
+	}
+}
+
+func TestSystemStatusService_ResolveIncidentClearsBanner(t *testing.T) {
+	ctx := context.Background()
+	svc := service.NewSystemStatusService(newMockIncidentRepository())
+
+	created, err := svc.CreateIncident(ctx, "user_operator", &dto.CreateIncidentRequest{
+		Kind:     "INCIDENT",
+		Severity: "WARNING",
+		Title:    "Builds delayed",
+		Message:  "Builds delayed due to AWS incident",
+	})
+	if err != nil {
+		t.Fatalf("CreateIncident() error = %v", err)
+	}
+
+	if banner := svc.CurrentBanner(ctx); banner == nil || banner.IncidentID != created.ID {
+		t.Fatalf("CurrentBanner() = %v, want incident %s", banner, created.ID)
+	}
+
+	if _, err := svc.ResolveIncident(ctx, created.ID); err != nil {
+		t.Fatalf("ResolveIncident() error = %v", err)
+	}
+
+	if banner := svc.CurrentBanner(ctx); banner != nil {
+		t.Errorf("CurrentBanner() after resolve = %v, want nil", banner)
+	}
+
+	if _, err := svc.ResolveIncident(ctx, created.ID); !errors.Is(err, incident.ErrIncidentResolved) {
+		t.Errorf("ResolveIncident() second call error = %v, want %v", err, incident.ErrIncidentResolved)
+	}
+}
+
+// blockingIncidentRepository holds FindUnresolved until released, reporting when it is called
+type blockingIncidentRepository struct {
+	*mockIncidentRepository
+	called, release chan struct{}
+}
+
+func (m *blockingIncidentRepository) FindUnresolved(ctx context.Context) ([]*incident.Incident, error) {
+	m.called <- struct{}{}
+	<-m.release
+	return m.mockIncidentRepository.FindUnresolved(ctx)
+}
+
+func TestSystemStatusService_CurrentBannerDoesNotWaitForReload(t *testing.T) {
+	ctx := context.Background()
+	incidents := newMockIncidentRepository()
+	inc, err := incident.NewIncident("INCIDENT", "WARNING", "Builds delayed", "Builds delayed due to AWS incident", time.Time{}, nil, "user_operator")
+	if err != nil {
+		t.Fatalf("NewIncident() error = %v", err)
+	}
+	_ = incidents.Save(ctx, inc)
+	repo := &blockingIncidentRepository{mockIncidentRepository: incidents, called: make(chan struct{}), release: make(chan struct{})}
+	svc := service.NewSystemStatusService(repo)
+
+	reloaded := make(chan *dto.SystemBanner)
+	go func() { reloaded <- svc.CurrentBanner(ctx) }()
+	<-repo.called
+
+	// Requests arriving during the reload get the last known banner, none yet, instead of waiting for it
+	done := make(chan *dto.SystemBanner)
+	go func() { done <- svc.CurrentBanner(ctx) }()
+	select {
+	case banner := <-done:
+		if banner != nil {
+			t.Errorf("CurrentBanner() during the reload = %v, want nil", banner)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("CurrentBanner() waited for the reload")
+	}
+
+	close(repo.release)
+	if banner := <-reloaded; banner == nil || banner.IncidentID != inc.ID().String() {
+		t.Errorf("CurrentBanner() = %v, want incident %s", banner, inc.ID())
+	}
+	if banner := svc.CurrentBanner(ctx); banner == nil || banner.IncidentID != inc.ID().String() {
+		t.Errorf("CurrentBanner() after the reload = %v, want the cached incident %s", banner, inc.ID())
+	}
+}
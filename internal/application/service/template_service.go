@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/template"
+)
+
+var (
+	// ErrRepositoryCopyUnavailable is returned when creating a project from a template with a repository of its own
+	// without a way to copy template repositories
+	ErrRepositoryCopyUnavailable = errors.New("copying template repositories is not configured")
+
+	// ErrRepositoryCopyFailed is returned when a template repository couldn't be copied into the user's account
+	ErrRepositoryCopyFailed = errors.New("failed to copy template repository")
+)
+
+// TemplateRepositoryCopier copies a template repository into a new repository of the user an access token
+// belongs to, returning the new repository's URL
+type TemplateRepositoryCopier interface {
+	CopyTemplateRepository(ctx context.Context, accessToken, templateURL, name string, private bool) (string, error)
+}
+
+// TemplateService handles the template catalog and creating projects from templates
+type TemplateService struct {
+	templateRepo   template.TemplateRepository
+	projectService *ProjectService
+	envVarService  *EnvVarService
+	uow            UnitOfWork
+	copier         TemplateRepositoryCopier
+}
+
+// NewTemplateService creates a new template service creating projects and their environment variables
+// with the given services
+func NewTemplateService(templateRepo template.TemplateRepository, projectService *ProjectService, envVarService *EnvVarService, uow UnitOfWork) *TemplateService {
+	return &TemplateService{
+		templateRepo:   templateRepo,
+		projectService: projectService,
+		envVarService:  envVarService,
+		uow:            uow,
+	}
+}
+
+// SetRepositoryCopier sets what copies template repositories into users' accounts (optional).
+// Without one, projects can only be created from templates deploying the template repository itself.
+func (s *TemplateService) SetRepositoryCopier(copier TemplateRepositoryCopier) {
+	s.copier = copier
+}
+
+// ListTemplates retrieves the template catalog
+func (s *TemplateService) ListTemplates(ctx context.Context) (*dto.TemplateListResponse, error) {
+	templates, err := s.templateRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.TemplateResponse, len(templates))
+	for i, t := range templates {
+		responses[i] = s.toDTO(t)
+	}
+	return &dto.TemplateListResponse{Templates: responses}, nil
+}
+
+// GetTemplate retrieves a template of the catalog
+func (s *TemplateService) GetTemplate(ctx context.Context, templateID string) (*dto.TemplateResponse, error) {
+	t, err := s.templateRepo.FindByID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toDTO(t), nil
+}
+
+// CreateProjectFromTemplate creates a project with the commands of a template and its environment variables
+// pre-filled, the request's values taking the place of their defaults. With a repository name the template
+// repository is first copied into the user's GitHub account with the access token, and the project deploys the
+// copy; otherwise it deploys the template repository.
+func (s *TemplateService) CreateProjectFromTemplate(ctx context.Context, userID, templateID, accessToken string, req *dto.CreateProjectFromTemplateRequest) (*dto.ProjectResponse, error) {
+	t, err := s.templateRepo.FindByID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Variables are checked before a repository is copied for a project that can't be created
+	variables, err := t.ResolveVariables(req.EnvVars)
+	if err != nil {
+		return nil, err
+	}
+
+	repositoryURL := t.RepositoryURL()
+	if req.RepositoryName != "" {
+		if s.copier == nil {
+			return nil, ErrRepositoryCopyUnavailable
+		}
+		repositoryURL, err = s.copier.CopyTemplateRepository(ctx, accessToken, t.RepositoryURL(), req.RepositoryName, req.Private)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrRepositoryCopyFailed, err)
+		}
+		slog.InfoContext(ctx, "Copied template repository", "template_id", t.ID(), "repository_url", repositoryURL)
+	}
+
+	var created *dto.ProjectResponse
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		created, err = s.projectService.CreateProject(ctx, userID, &dto.CreateProjectRequest{
+			RepositoryURL:    repositoryURL,
+			InstallCommand:   t.InstallCommand(),
+			BuildCommand:     t.BuildCommand(),
+			RunCommand:       t.RunCommand(),
+			Language:         t.Language(),
+			Type:             t.ProjectType(),
+			CustomDomain:     req.CustomDomain,
+			RequireDB:        t.RequireDB(),
+			MigrationCommand: t.MigrationCommand(),
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, variable := range variables {
+			_, err := s.envVarService.CreateOrUpdateEnvVar(ctx, created.ID, userID, &dto.CreateEnvVarRequest{
+				Key:   variable.Key,
+				Value: variable.Value,
+				Scope: variable.Scope,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to set %s: %w", variable.Key, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if repositoryURL != t.RepositoryURL() {
+			slog.WarnContext(ctx, "Project of a copied template repository wasn't created, the repository is kept",
+				"template_id", t.ID(), "repository_url", repositoryURL, "error", err)
+		}
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Created project from template", "template_id", t.ID(), "project_id", created.ID)
+	return created, nil
+}
+
+// toDTO converts a domain template to DTO
+func (s *TemplateService) toDTO(t *template.Template) *dto.TemplateResponse {
+	variables := t.Variables()
+	envVars := make([]*dto.TemplateVariableResponse, len(variables))
+	for i, v := range variables {
+		scope := v.Scope
+		if scope == "" {
+			scope = project.ScopeRuntime.String()
+		}
+		envVars[i] = &dto.TemplateVariableResponse{
+			Key:         v.Key,
+			Value:       v.Value,
+			Description: v.Description,
+			Scope:       scope,
+			Required:    v.Required,
+		}
+	}
+
+	return &dto.TemplateResponse{
+		ID:               t.ID(),
+		Name:             t.Name(),
+		Description:      t.Description(),
+		RepositoryURL:    t.RepositoryURL(),
+		Language:         t.Language(),
+		Type:             t.ProjectType(),
+		InstallCommand:   t.InstallCommand(),
+		BuildCommand:     t.BuildCommand(),
+		RunCommand:       t.RunCommand(),
+		RequireDB:        t.RequireDB(),
+		MigrationCommand: t.MigrationCommand(),
+		EnvVars:          envVars,
+	}
+}
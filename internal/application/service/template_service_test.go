@@ -0,0 +1,137 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/template"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// mockTemplates holds the template catalog
+type mockTemplates struct {
+	template.TemplateRepository
+	templates []*template.Template
+}
+
+func (m *mockTemplates) FindByID(ctx context.Context, id string) (*template.Template, error) {
+	for _, t := range m.templates {
+		if t.ID() == id {
+			return t, nil
+		}
+	}
+	return nil, template.ErrTemplateNotFound
+}
+
+// mockTemplateCopier records the repositories it copies, or fails with err
+type mockTemplateCopier struct {
+	copied []string
+	err    error
+}
+
+func (m *mockTemplateCopier) CopyTemplateRepository(ctx context.Context, accessToken, templateURL, name string, private bool) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	m.copied = append(m.copied, name)
+	return "https://github.com/someone/" + name, nil
+}
+
+func newTemplateService(projects *mockProjectRepository, copier service.TemplateRepositoryCopier) *service.TemplateService {
+	templates := &mockTemplates{templates: []*template.Template{
+		template.Reconstitute("nextjs-starter", "Next.js starter", "", "https://github.com/SnapDeploy/nextjs-starter", "NEXTJS", "WEB",
+			"npm ci", "npm run build", "npm start", false, "", nil, time.Now()),
+		template.Reconstitute("go-api-starter", "Go API starter", "", "https://github.com/SnapDeploy/go-api-starter", "GO", "WEB",
+			"go mod download", "go build -o server ./cmd/server", "./server", true, "./server migrate",
+			[]template.Variable{{Key: "API_KEY", Required: true}}, time.Now()),
+	}}
+	svc := service.NewTemplateService(templates, service.NewProjectService(projects, nil, mockUnitOfWork{}), nil, mockUnitOfWork{})
+	if copier != nil {
+		svc.SetRepositoryCopier(copier)
+	}
+	return svc
+}
+
+func TestTemplateService_CreateProjectFromTemplate(t *testing.T) {
+	ctx := context.Background()
+	projects := newMockProjectRepository()
+	svc := newTemplateService(projects, &mockTemplateCopier{})
+
+	created, err := svc.CreateProjectFromTemplate(ctx, user.NewUserID().String(), "nextjs-starter", "", &dto.CreateProjectFromTemplateRequest{})
+	if err != nil {
+		t.Fatalf("CreateProjectFromTemplate() error = %v", err)
+	}
+
+	// Without a repository name the project deploys the template repository with its commands
+	if created.RepositoryURL != "https://github.com/SnapDeploy/nextjs-starter" {
+		t.Errorf("RepositoryURL = %q, want the template repository", created.RepositoryURL)
+	}
+	if created.BuildCommand != "npm run build" || created.Language != "NEXTJS" {
+		t.Errorf("project = %+v, want the template's commands and language", created)
+	}
+	if len(projects.saved) != 1 {
+		t.Errorf("saved %d projects, want 1", len(projects.saved))
+	}
+}
+
+func TestTemplateService_CreateProjectFromTemplateCopiesRepository(t *testing.T) {
+	ctx := context.Background()
+	copier := &mockTemplateCopier{}
+	svc := newTemplateService(newMockProjectRepository(), copier)
+
+	created, err := svc.CreateProjectFromTemplate(ctx, user.NewUserID().String(), "nextjs-starter", "token", &dto.CreateProjectFromTemplateRequest{RepositoryName: "my-site"})
+	if err != nil {
+		t.Fatalf("CreateProjectFromTemplate() error = %v", err)
+	}
+
+	if len(copier.copied) != 1 || copier.copied[0] != "my-site" {
+		t.Errorf("copied = %v, want [my-site]", copier.copied)
+	}
+	if created.RepositoryURL != "https://github.com/someone/my-site" {
+		t.Errorf("RepositoryURL = %q, want the copied repository", created.RepositoryURL)
+	}
+}
+
+func TestTemplateService_CreateProjectFromTemplateErrors(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		templateID string
+		req        dto.CreateProjectFromTemplateRequest
+		copier     *mockTemplateCopier
+		wantErr    error
+	}{
+		{name: "unknown template", templateID: "rails-starter", wantErr: template.ErrTemplateNotFound},
+		{name: "missing required variable", templateID: "go-api-starter", req: dto.CreateProjectFromTemplateRequest{RepositoryName: "api"}, copier: &mockTemplateCopier{}, wantErr: template.ErrMissingVariable},
+		{name: "no copier", templateID: "nextjs-starter", req: dto.CreateProjectFromTemplateRequest{RepositoryName: "my-site"}, wantErr: service.ErrRepositoryCopyUnavailable},
+		{name: "copy fails", templateID: "nextjs-starter", req: dto.CreateProjectFromTemplateRequest{RepositoryName: "my-site"}, copier: &mockTemplateCopier{err: errors.New("name already exists")}, wantErr: service.ErrRepositoryCopyFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projects := newMockProjectRepository()
+			var copier service.TemplateRepositoryCopier
+			if tt.copier != nil {
+				copier = tt.copier
+			}
+			svc := newTemplateService(projects, copier)
+
+			_, err := svc.CreateProjectFromTemplate(ctx, user.NewUserID().String(), tt.templateID, "token", &tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateProjectFromTemplate() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(projects.saved) != 0 {
+				t.Errorf("saved %d projects, want none", len(projects.saved))
+			}
+			// Repositories aren't copied for projects that can't be created
+			if tt.copier != nil && tt.wantErr == template.ErrMissingVariable && len(tt.copier.copied) != 0 {
+				t.Errorf("copied = %v, want none", tt.copier.copied)
+			}
+		})
+	}
+}
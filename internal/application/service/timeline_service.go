@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/events"
+	"snapdeploy-core/internal/domain/project"
+)
+
+// timelinePadding widens the window read from AWS so events reported just before or after the deployment are included
+const timelinePadding = 2 * time.Minute
+
+// TimelineEventSource reads the infrastructure events of the deployed service of a project's environment
+type TimelineEventSource interface {
+	GetTimelineEvents(ctx context.Context, proj *project.Project, env project.Environment, start, end time.Time) ([]deployment.TimelineEntry, error)
+}
+
+// TimelineService builds a deployment's timeline from the platform and migration events
+// it records and the ECS and load balancer events read from AWS
+type TimelineService struct {
+	deploymentRepo deployment.DeploymentRepository
+	projectRepo    project.ProjectRepository
+	timelineRepo   deployment.TimelineRepository
+	source         TimelineEventSource
+}
+
+// NewTimelineService creates a new timeline service
+func NewTimelineService(
+	deploymentRepo deployment.DeploymentRepository,
+	projectRepo project.ProjectRepository,
+	timelineRepo deployment.TimelineRepository,
+) *TimelineService {
+	return &TimelineService{
+		deploymentRepo: deploymentRepo,
+		projectRepo:    projectRepo,
+		timelineRepo:   timelineRepo,
+	}
+}
+
+// SetTimelineEventSource sets where infrastructure events are read from (optional)
+func (s *TimelineService) SetTimelineEventSource(source TimelineEventSource) {
+	s.source = source
+}
+
+// RegisterHandlers subscribes the service to deployment events so they are recorded on the timeline
+func (s *TimelineService) RegisterHandlers(dispatcher *events.Dispatcher) {
+	dispatcher.Register(deployment.EventTypeDeploymentCreated, s.onDeploymentCreated)
+	dispatcher.Register(deployment.EventTypeDeploymentStatusChanged, s.onDeploymentStatusChanged)
+	dispatcher.Register(deployment.EventTypeMigrationFinished, s.onMigrationFinished)
+}
+
+func (s *TimelineService) onDeploymentCreated(ctx context.Context, event events.DomainEvent) error {
+	created, ok := event.(*deployment.DeploymentCreated)
+	if !ok {
+		return nil
+	}
+	return s.append(ctx, created.DeploymentID, created.ProjectID, deployment.TimelineEntry{
+		OccurredAt: created.OccurredAt(),
+		Source:     deployment.TimelineSourcePlatform,
+		Type:       "created",
+		Message:    fmt.Sprintf("Deployment of %s (%s) queued", created.Branch, created.CommitHash),
+	})
+}
+
+func (s *TimelineService) onDeploymentStatusChanged(ctx context.Context, event events.DomainEvent) error {
+	changed, ok := event.(*deployment.DeploymentStatusChanged)
+	if !ok {
+		return nil
+	}
+	return s.append(ctx, changed.DeploymentID, changed.ProjectID, deployment.TimelineEntry{
+		OccurredAt: changed.OccurredAt(),
+		Source:     deployment.TimelineSourcePlatform,
+		Type:       "status_changed",
+		Message:    fmt.Sprintf("Status changed from %s to %s", changed.OldStatus, changed.NewStatus),
+	})
+}
+
+func (s *TimelineService) onMigrationFinished(ctx context.Context, event events.DomainEvent) error {
+	finished, ok := event.(*deployment.MigrationFinished)
+	if !ok {
+		return nil
+	}
+
+	entryType := "migration_succeeded"
+	if !finished.Succeeded {
+		entryType = "migration_failed"
+	}
+	return s.append(ctx, finished.DeploymentID, finished.ProjectID, deployment.TimelineEntry{
+		OccurredAt: finished.OccurredAt(),
+		Source:     deployment.TimelineSourceMigration,
+		Type:       entryType,
+		Message:    finished.Detail,
+	})
+}
+
+// append records an entry on a deployment's timeline
+func (s *TimelineService) append(ctx context.Context, deploymentID, projectID string, entry deployment.TimelineEntry) error {
+	did, err := deployment.ParseDeploymentID(deploymentID)
+	if err != nil {
+		return fmt.Errorf("invalid deployment ID in event: %w", err)
+	}
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return fmt.Errorf("invalid project ID in event: %w", err)
+	}
+
+	if err := s.timelineRepo.Append(ctx, did, pid, entry); err != nil {
+		return fmt.Errorf("failed to record timeline entry: %w", err)
+	}
+	return nil
+}
+
+// GetDeploymentTimeline returns a deployment's events from all sources in chronological order
+func (s *TimelineService) GetDeploymentTimeline(ctx context.Context, deploymentID string) (*dto.DeploymentTimelineResponse, error) {
+	did, err := deployment.ParseDeploymentID(deploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment ID: %w", err)
+	}
+
+	dep, err := s.deploymentRepo.FindByID(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.timelineRepo.FindByDeploymentID(ctx, did)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timeline: %w", err)
+	}
+
+	var warnings []string
+	if s.source != nil {
+		infraEntries, err := s.infrastructureEvents(ctx, dep)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to read infrastructure events", "deployment_id", did.String(), "error", err)
+			warnings = append(warnings, err.Error())
+		}
+		entries = append(entries, infraEntries...)
+	}
+
+	deployment.SortTimeline(entries)
+
+	response := &dto.DeploymentTimelineResponse{
+		DeploymentID: did.String(),
+		ProjectID:    dep.ProjectID().String(),
+		Status:       dep.Status().String(),
+		Entries:      make([]*dto.TimelineEntryResponse, len(entries)),
+		Warnings:     warnings,
+	}
+	for i, entry := range entries {
+		response.Entries[i] = &dto.TimelineEntryResponse{
+			Timestamp: entry.OccurredAt.UTC().Format(time.RFC3339),
+			Source:    entry.Source.String(),
+			Type:      entry.Type,
+			Message:   entry.Message,
+		}
+	}
+
+	return response, nil
+}
+
+// infrastructureEvents reads the service's AWS events over the lifetime of the deployment
+func (s *TimelineService) infrastructureEvents(ctx context.Context, dep *deployment.Deployment) ([]deployment.TimelineEntry, error) {
+	proj, err := s.projectRepo.FindByID(ctx, dep.ProjectID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	now := time.Now()
+	end := now
+	if dep.Status().IsTerminal() {
+		end = dep.UpdatedAt().Add(timelinePadding)
+		if end.After(now) {
+			end = now
+		}
+	}
+	start := dep.CreatedAt().Add(-timelinePadding)
+
+	return s.source.GetTimelineEvents(ctx, proj, dep.Environment(), start, end)
+}
@@ -0,0 +1,9 @@
+package service
+
+import "context"
+
+// UnitOfWork runs use cases that write through several repositories atomically.
+// Repositories called with the context passed to fn share its transaction; an error from fn rolls it back.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
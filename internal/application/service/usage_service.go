@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/usage"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// minMeterPeriod skips compute periods too short to be worth a record
+const minMeterPeriod = time.Minute
+
+// UsagePricing holds the prices used to estimate usage cost (USD)
+type UsagePricing struct {
+	PerVCPUHour    float64
+	PerGBHour      float64
+	PerBuildMinute float64
+	PerEgressGB    float64
+}
+
+// EgressSource reports the bytes the service of a project's environment sent over a period
+type EgressSource interface {
+	GetServiceEgress(ctx context.Context, projectID project.ProjectID, env project.Environment, start, end time.Time) (float64, error)
+}
+
+// UsageService handles usage metering and reporting use cases
+type UsageService struct {
+	usageRepo      usage.RecordRepository
+	deploymentRepo deployment.DeploymentRepository
+	projectRepo    project.ProjectRepository
+	pricing        UsagePricing
+	egressSource   EgressSource
+}
+
+// NewUsageService creates a new usage service
+func NewUsageService(
+	usageRepo usage.RecordRepository,
+	deploymentRepo deployment.DeploymentRepository,
+	projectRepo project.ProjectRepository,
+	pricing UsagePricing,
+) *UsageService {
+	return &UsageService{
+		usageRepo:      usageRepo,
+		deploymentRepo: deploymentRepo,
+		projectRepo:    projectRepo,
+		pricing:        pricing,
+	}
+}
+
+// SetEgressSource sets where the bytes sent by project services are read from. Without one, egress isn't metered.
+func (s *UsageService) SetEgressSource(source EgressSource) {
+	s.egressSource = source
+}
+
+// RecordBuild records the build minutes consumed by a deployment's build
+// CodeBuild bills per started minute, so the duration is rounded up
+func (s *UsageService) RecordBuild(ctx context.Context, dep *deployment.Deployment, startedAt, endedAt time.Time) error {
+	minutes := math.Ceil(endedAt.Sub(startedAt).Minutes())
+	depID := dep.ID()
+
+	record, err := usage.NewRecord(dep.ProjectID(), dep.UserID(), &depID, usage.MetricBuildMinutes, minutes, startedAt, endedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create usage record: %w", err)
+	}
+
+	if err := s.usageRepo.Save(ctx, record); err != nil {
+		return fmt.Errorf("failed to save usage record: %w", err)
+	}
+
+	return nil
+}
+
+// MeterCompute records Fargate usage for every running project service since it was last metered
+func (s *UsageService) MeterCompute(ctx context.Context, now time.Time) error {
+	deployments, err := s.deploymentRepo.FindLatestDeployed(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get running deployments: %w", err)
+	}
+
+	metered := 0
+	for _, dep := range deployments {
+		periodStart := dep.UpdatedAt() // Deployments stop changing once DEPLOYED
+
+		lastEnd, err := s.usageRepo.LastPeriodEnd(ctx, dep.ProjectID(), usage.MetricVCPUHours)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get last metered period", "project_id", dep.ProjectID().String(), "error", err)
+			continue
+		}
+		if lastEnd != nil && lastEnd.After(periodStart) {
+			periodStart = *lastEnd
+		}
+
+		if now.Sub(periodStart) < minMeterPeriod {
+			continue
+		}
+
+		records, err := usage.NewComputeRecords(dep.ProjectID(), dep.UserID(), dep.ID(), periodStart, now)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to create compute records", "project_id", dep.ProjectID().String(), "error", err)
+			continue
+		}
+
+		if egress := s.egressRecord(ctx, dep, periodStart, now); egress != nil {
+			records = append(records, egress)
+		}
+
+		for _, record := range records {
+			if err := s.usageRepo.Save(ctx, record); err != nil {
+				slog.ErrorContext(ctx, "Failed to save compute record", "project_id", dep.ProjectID().String(), "error", err)
+			}
+		}
+		metered++
+	}
+
+	slog.InfoContext(ctx, "Metered compute", "services", metered)
+	return nil
+}
+
+// egressRecord returns the record of the gigabytes a deployment's service sent over the period,
+// or nil when egress isn't metered or couldn't be read. The period is metered once, with the compute.
+func (s *UsageService) egressRecord(ctx context.Context, dep *deployment.Deployment, periodStart, periodEnd time.Time) *usage.Record {
+	if s.egressSource == nil {
+		return nil
+	}
+
+	bytes, err := s.egressSource.GetServiceEgress(ctx, dep.ProjectID(), dep.Environment(), periodStart, periodEnd)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to get service egress", "project_id", dep.ProjectID().String(), "error", err)
+		return nil
+	}
+
+	depID := dep.ID()
+	record, err := usage.NewRecord(dep.ProjectID(), dep.UserID(), &depID, usage.MetricEgressGB, bytes/1e9, periodStart, periodEnd)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create egress record", "project_id", dep.ProjectID().String(), "error", err)
+		return nil
+	}
+
+	return record
+}
+
+// RunMeter meters compute usage on every interval until the context is cancelled
+func (s *UsageService) RunMeter(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		slog.InfoContext(ctx, "Compute metering disabled", "interval", interval.String())
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.MeterCompute(ctx, now); err != nil {
+				slog.ErrorContext(ctx, "Compute metering failed", "error", err)
+			}
+		}
+	}
+}
+
+// GetProjectUsage returns a project's usage summary for periods ending in (from, to]
+func (s *UsageService) GetProjectUsage(ctx context.Context, projectID string, from, to time.Time) (*dto.ProjectUsageResponse, error) {
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	totals, err := s.usageRepo.SumByProjectID(ctx, pid, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project usage: %w", err)
+	}
+
+	return &dto.ProjectUsageResponse{
+		ProjectID:   pid.String(),
+		PeriodStart: from.Format(time.RFC3339),
+		PeriodEnd:   to.Format(time.RFC3339),
+		UsageTotals: s.toTotalsDTO(totals),
+	}, nil
+}
+
+// GetUserUsage returns a user's usage summary across all of their projects for periods ending in (from, to]
+func (s *UsageService) GetUserUsage(ctx context.Context, userID string, from, to time.Time) (*dto.UserUsageResponse, error) {
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	byProject, err := s.usageRepo.SumByUserID(ctx, uid, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user usage: %w", err)
+	}
+
+	response := &dto.UserUsageResponse{
+		UserID:      uid.String(),
+		PeriodStart: from.Format(time.RFC3339),
+		PeriodEnd:   to.Format(time.RFC3339),
+		Projects:    make([]*dto.ProjectUsageResponse, 0, len(byProject)),
+	}
+
+	total := usage.Totals{}
+	for projectID, totals := range byProject {
+		total.Add(totals)
+		response.Projects = append(response.Projects, &dto.ProjectUsageResponse{
+			ProjectID:   projectID,
+			PeriodStart: response.PeriodStart,
+			PeriodEnd:   response.PeriodEnd,
+			UsageTotals: s.toTotalsDTO(totals),
+		})
+	}
+
+	sort.Slice(response.Projects, func(i, j int) bool {
+		return response.Projects[i].ProjectID < response.Projects[j].ProjectID
+	})
+	response.UsageTotals = s.toTotalsDTO(total)
+
+	return response, nil
+}
+
+// toTotalsDTO converts usage totals to DTO with an estimated cost
+func (s *UsageService) toTotalsDTO(totals usage.Totals) dto.UsageTotals {
+	vcpuHours := totals[usage.MetricVCPUHours]
+	memoryGBHours := totals[usage.MetricMemoryGBHours]
+	buildMinutes := totals[usage.MetricBuildMinutes]
+	egressGB := totals[usage.MetricEgressGB]
+
+	cost := vcpuHours*s.pricing.PerVCPUHour +
+		memoryGBHours*s.pricing.PerGBHour +
+		buildMinutes*s.pricing.PerBuildMinute +
+		egressGB*s.pricing.PerEgressGB
+
+	return dto.UsageTotals{
+		VCPUHours:        roundTo(vcpuHours, 4),
+		MemoryGBHours:    roundTo(memoryGBHours, 4),
+		BuildMinutes:     roundTo(buildMinutes, 2),
+		EgressGB:         roundTo(egressGB, 4),
+		EstimatedCostUSD: roundTo(cost, 2),
+	}
+}
+
+// roundTo rounds a value to the given number of decimal places
+func roundTo(value float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	return math.Round(value*factor) / factor
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// exportPageSize bounds how many records are read at once while exporting a user's data
+const exportPageSize = 100
+
+// UserExportService gathers everything stored about a user into a single archive they can download, reusing
+// the responses of the services owning each kind of data
+type UserExportService struct {
+	users            *UserService
+	repositories     *RepositoryService
+	installationRepo repo.InstallationRepo
+	projects         *ProjectService
+	deployments      *DeploymentService
+	envVars          *EnvVarService
+}
+
+// NewUserExportService creates a new user export service
+func NewUserExportService(
+	users *UserService,
+	repositories *RepositoryService,
+	installationRepo repo.InstallationRepo,
+	projects *ProjectService,
+	deployments *DeploymentService,
+	envVars *EnvVarService,
+) *UserExportService {
+	return &UserExportService{
+		users:            users,
+		repositories:     repositories,
+		installationRepo: installationRepo,
+		projects:         projects,
+		deployments:      deployments,
+		envVars:          envVars,
+	}
+}
+
+// ExportUserData returns the profile of a user with their repositories, GitHub App installations and projects,
+// deleted projects that haven't been purged yet included, each with its environment variables and deployments.
+// Environment variable values stay masked like everywhere else in the API.
+func (s *UserExportService) ExportUserData(ctx context.Context, id string) (*dto.UserDataExport, error) {
+	profile, err := s.users.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := user.ParseUserID(profile.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	export := &dto.UserDataExport{
+		ExportedAt:          time.Now().UTC().Format(time.RFC3339),
+		User:                profile,
+		Repositories:        []*dto.RepositoryResponse{},
+		GitHubInstallations: []*dto.GitHubInstallationResponse{},
+		Projects:            []*dto.ProjectDataExport{},
+	}
+
+	for offset := int32(0); ; offset += exportPageSize {
+		repositories, err := s.repositories.repoRepo.FindByUserID(ctx, userID, exportPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories: %w", err)
+		}
+		for _, r := range repositories {
+			export.Repositories = append(export.Repositories, s.repositories.toDTO(r))
+		}
+		if len(repositories) < exportPageSize {
+			break
+		}
+	}
+
+	installations, err := s.installationRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list GitHub installations: %w", err)
+	}
+	for _, installation := range installations {
+		export.GitHubInstallations = append(export.GitHubInstallations, toInstallationDTO(installation))
+	}
+
+	for offset := int32(0); ; offset += exportPageSize {
+		projects, err := s.projects.projectRepo.FindByUserIDIncludingDeleted(ctx, userID, exportPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list projects: %w", err)
+		}
+		for _, proj := range projects {
+			projectExport, err := s.exportProject(ctx, proj)
+			if err != nil {
+				return nil, err
+			}
+			export.Projects = append(export.Projects, projectExport)
+		}
+		if len(projects) < exportPageSize {
+			break
+		}
+	}
+
+	return export, nil
+}
+
+// exportProject returns a project with the environment variables of each of its environments and all its
+// deployments, oldest first
+func (s *UserExportService) exportProject(ctx context.Context, proj *project.Project) (*dto.ProjectDataExport, error) {
+	export := &dto.ProjectDataExport{
+		Project:              s.projects.toDTO(proj),
+		EnvironmentVariables: []*dto.EnvVarResponse{},
+		Deployments:          []*dto.DeploymentResponse{},
+	}
+
+	for _, env := range proj.Environments() {
+		envVars, err := s.envVars.envVarRepo.FindByProjectID(ctx, proj.ID(), env)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list environment variables: %w", err)
+		}
+		for _, envVar := range envVars {
+			export.EnvironmentVariables = append(export.EnvironmentVariables, s.envVars.toDTO(envVar))
+		}
+	}
+
+	filter := deployment.ListFilter{Ascending: true}
+	for offset := int32(0); ; offset += exportPageSize {
+		deployments, err := s.deployments.deploymentRepo.FindByProjectIDIncludingDeleted(ctx, proj.ID(), filter, exportPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deployments: %w", err)
+		}
+		for _, dep := range deployments {
+			export.Deployments = append(export.Deployments, s.deployments.toDTO(dep))
+		}
+		if len(deployments) < exportPageSize {
+			break
+		}
+	}
+
+	return export, nil
+}
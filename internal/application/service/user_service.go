@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"snapdeploy-core/internal/application/dto"
 	"snapdeploy-core/internal/domain/repo"
@@ -21,11 +22,17 @@ type ClerkService interface {
 	GetUser(ctx context.Context, clerkUserID string) (*ClerkUserData, error)
 }
 
+// UserProjectRemover tears down every project of a user, with their cloud resources and environment variables
+type UserProjectRemover interface {
+	DeleteUserProjects(ctx context.Context, userID user.UserID) error
+}
+
 // UserService handles user-related use cases
 type UserService struct {
 	userRepo    user.Repository
 	repoRepo    repo.RepositoryRepo
 	clerkClient ClerkService
+	projects    UserProjectRemover
 }
 
 // NewUserService creates a new user service
@@ -37,6 +44,11 @@ func NewUserService(userRepo user.Repository, repoRepo repo.RepositoryRepo, cler
 	}
 }
 
+// SetProjectRemover sets what tears down a user's projects when the user is deleted
+func (s *UserService) SetProjectRemover(projects UserProjectRemover) {
+	s.projects = projects
+}
+
 // CreateUser creates a new user
 func (s *UserService) CreateUser(ctx context.Context, req *dto.CreateUserRequest) (*dto.UserResponse, error) {
 	// Check if user already exists by email
@@ -151,7 +163,7 @@ func (s *UserService) UpdateUser(ctx context.Context, id string, req *dto.Update
 
 		existingUser, err := s.userRepo.FindByEmail(ctx, email)
 		if err == nil && !existingUser.ID().Equals(domainUser.ID()) {
-			return nil, fmt.Errorf("email %s is already taken", *req.Email)
+			return nil, user.ErrUserAlreadyExists(*req.Email)
 		}
 
 		if err := domainUser.UpdateEmail(*req.Email); err != nil {
@@ -172,7 +184,9 @@ func (s *UserService) UpdateUser(ctx context.Context, id string, req *dto.Update
 	return s.toDTO(ctx, domainUser), nil
 }
 
-// DeleteUser deletes a user
+// DeleteUser deletes a user and everything they own. Their projects are torn down first, removing their cloud
+// resources and environment variables; if any teardown fails the user is kept so the deletion can be retried.
+// Deleting the user then removes the rest of their data, deployments and repositories included.
 func (s *UserService) DeleteUser(ctx context.Context, id string) error {
 	userID, err := user.ParseUserID(id)
 	if err != nil {
@@ -185,6 +199,14 @@ func (s *UserService) DeleteUser(ctx context.Context, id string) error {
 		return fmt.Errorf("failed to find user: %w", err)
 	}
 
+	if s.projects != nil {
+		if err := s.projects.DeleteUserProjects(ctx, userID); err != nil {
+			return fmt.Errorf("failed to delete projects: %w", err)
+		}
+	} else {
+		slog.WarnContext(ctx, "No project remover configured, deleting the user without tearing down their projects")
+	}
+
 	if err := s.userRepo.Delete(ctx, userID); err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
@@ -265,6 +265,57 @@ func TestUserService_DeleteUser(t *testing.T) {
 	}
 }
 
+// mockProjectRemover records the users whose projects were deleted, failing with err
+type mockProjectRemover struct {
+	deleted []user.UserID
+	err     error
+}
+
+func (m *mockProjectRemover) DeleteUserProjects(ctx context.Context, userID user.UserID) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.deleted = append(m.deleted, userID)
+	return nil
+}
+
+func TestUserService_DeleteUserTearsDownProjects(t *testing.T) {
+	repo := newMockUserRepository()
+	svc := service.NewUserService(repo, newMockRepositoryRepo(), &mockClerkService{})
+	projects := &mockProjectRemover{}
+	svc.SetProjectRemover(projects)
+
+	usr, _ := user.NewUser("test@example.com", "testuser", "user_123")
+	_ = repo.Save(context.Background(), usr)
+
+	if err := svc.DeleteUser(context.Background(), usr.ID().String()); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+	if len(projects.deleted) != 1 || !projects.deleted[0].Equals(usr.ID()) {
+		t.Errorf("deleted projects of %v, want those of the user", projects.deleted)
+	}
+	if _, err := repo.FindByID(context.Background(), usr.ID()); err == nil {
+		t.Error("User should be deleted")
+	}
+}
+
+func TestUserService_DeleteUserKeepsUserWhenTeardownFails(t *testing.T) {
+	repo := newMockUserRepository()
+	svc := service.NewUserService(repo, newMockRepositoryRepo(), &mockClerkService{})
+	teardownErr := errors.New("failed to delete 1 of 2 projects")
+	svc.SetProjectRemover(&mockProjectRemover{err: teardownErr})
+
+	usr, _ := user.NewUser("test@example.com", "testuser", "user_123")
+	_ = repo.Save(context.Background(), usr)
+
+	if err := svc.DeleteUser(context.Background(), usr.ID().String()); !errors.Is(err, teardownErr) {
+		t.Fatalf("DeleteUser() error = %v, want %v", err, teardownErr)
+	}
+	if _, err := repo.FindByID(context.Background(), usr.ID()); err != nil {
+		t.Error("User should be kept so the deletion can be retried")
+	}
+}
+
 func TestUserService_ListUsers(t *testing.T) {
 	repo := newMockUserRepository()
 	repoRepo := newMockRepositoryRepo()
@@ -0,0 +1,258 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when the Bitbucket API responds with 404
+var ErrNotFound = errors.New("not found on Bitbucket")
+
+// Host is the Bitbucket Cloud host repositories are cloned from
+const Host = "bitbucket.org"
+
+// Client handles Bitbucket Cloud API interactions
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewClient creates a new Bitbucket API client
+func NewClient() *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		baseURL: "https://api.bitbucket.org/2.0",
+	}
+}
+
+// Link is a hypermedia link in Bitbucket API responses
+type Link struct {
+	Href string `json:"href"`
+}
+
+// Repository represents a Bitbucket repository from the API
+type Repository struct {
+	UUID        string          `json:"uuid"`
+	Name        string          `json:"name"`
+	FullName    string          `json:"full_name"` // workspace/repo-slug
+	Description string          `json:"description"`
+	IsPrivate   bool            `json:"is_private"`
+	Parent      json.RawMessage `json:"parent"` // Set when the repository is a fork
+	Language    string          `json:"language"`
+	MainBranch  *struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+	Links struct {
+		Self Link `json:"self"`
+		HTML Link `json:"html"`
+	} `json:"links"`
+}
+
+// IsFork reports whether the repository was forked from another repository
+func (r Repository) IsFork() bool {
+	return len(r.Parent) > 0 && string(r.Parent) != "null"
+}
+
+// Branch represents a Bitbucket branch from the API
+type Branch struct {
+	Name string `json:"name"`
+}
+
+// Commit represents a Bitbucket commit from the API
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Message string    `json:"message"`
+	Date    time.Time `json:"date"`
+	Author  struct {
+		Raw  string `json:"raw"` // e.g. "Jane Doe <jane@example.com>"
+		User *struct {
+			DisplayName string `json:"display_name"`
+		} `json:"user"`
+	} `json:"author"`
+	Links struct {
+		HTML Link `json:"html"`
+	} `json:"links"`
+}
+
+// AuthorName returns the name of the commit author
+func (c Commit) AuthorName() string {
+	if c.Author.User != nil && c.Author.User.DisplayName != "" {
+		return c.Author.User.DisplayName
+	}
+	if idx := strings.Index(c.Author.Raw, " <"); idx != -1 {
+		return c.Author.Raw[:idx]
+	}
+	return c.Author.Raw
+}
+
+// GetUserRepositories fetches the repositories a user is a member of using their Bitbucket access token
+func (c *Client) GetUserRepositories(ctx context.Context, accessToken string) ([]Repository, error) {
+	var repos []Repository
+	next := c.baseURL + "/repositories?role=member&sort=-updated_on&pagelen=100"
+	for next != "" {
+		var resp struct {
+			Values []Repository `json:"values"`
+			Next   string       `json:"next"`
+		}
+		if err := c.get(ctx, accessToken, next, &resp); err != nil {
+			return nil, fmt.Errorf("failed to list repositories: %w", err)
+		}
+
+		repos = append(repos, resp.Values...)
+		next = resp.Next
+	}
+	return repos, nil
+}
+
+// GetBranches fetches all branches of a repository, identified by its full name (workspace/repo-slug)
+func (c *Client) GetBranches(ctx context.Context, accessToken, fullName string) ([]Branch, error) {
+	var branches []Branch
+	next := fmt.Sprintf("%s/repositories/%s/refs/branches?pagelen=100", c.baseURL, fullName)
+	for next != "" {
+		var resp struct {
+			Values []Branch `json:"values"`
+			Next   string   `json:"next"`
+		}
+		if err := c.get(ctx, accessToken, next, &resp); err != nil {
+			return nil, fmt.Errorf("failed to list branches: %w", err)
+		}
+
+		branches = append(branches, resp.Values...)
+		next = resp.Next
+	}
+	return branches, nil
+}
+
+// GetCommits fetches the most recent commits on a branch (or the main branch if empty), newest first
+func (c *Client) GetCommits(ctx context.Context, accessToken, fullName, branch string, limit int) ([]Commit, error) {
+	url := fmt.Sprintf("%s/repositories/%s/commits", c.baseURL, fullName)
+	if branch != "" {
+		url += "/" + neturl.PathEscape(branch)
+	}
+	url += fmt.Sprintf("?pagelen=%d", limit)
+
+	var resp struct {
+		Values []Commit `json:"values"`
+	}
+	if err := c.get(ctx, accessToken, url, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+	return resp.Values, nil
+}
+
+// CompareCommits fetches up to limit commits reachable from head but not from base, newest first, and whether
+// there are more
+func (c *Client) CompareCommits(ctx context.Context, accessToken, fullName, base, head string, limit int) ([]Commit, bool, error) {
+	query := neturl.Values{"include": {head}, "exclude": {base}, "pagelen": {strconv.Itoa(limit)}}
+	url := fmt.Sprintf("%s/repositories/%s/commits?%s", c.baseURL, fullName, query.Encode())
+
+	var resp struct {
+		Values []Commit `json:"values"`
+		Next   string   `json:"next"`
+	}
+	if err := c.get(ctx, accessToken, url, &resp); err != nil {
+		return nil, false, fmt.Errorf("failed to compare commits: %w", err)
+	}
+	return resp.Values, resp.Next != "", nil
+}
+
+// GetFileContent fetches a file of a repository at a ref (or the main branch if empty)
+func (c *Client) GetFileContent(ctx context.Context, accessToken, fullName, ref, filePath string) ([]byte, error) {
+	if ref == "" {
+		var repository Repository
+		if err := c.get(ctx, accessToken, fmt.Sprintf("%s/repositories/%s", c.baseURL, fullName), &repository); err != nil {
+			return nil, fmt.Errorf("failed to get repository: %w", err)
+		}
+		if repository.MainBranch == nil {
+			return nil, ErrNotFound
+		}
+		ref = repository.MainBranch.Name
+	}
+
+	url := fmt.Sprintf("%s/repositories/%s/src/%s/%s", c.baseURL, fullName, neturl.PathEscape(ref), (&neturl.URL{Path: filePath}).EscapedPath())
+	content, err := c.getRaw(ctx, accessToken, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	return content, nil
+}
+
+// ParseRepositoryFullName extracts the workspace and repository slug from a Bitbucket repository URL
+// e.g. https://bitbucket.org/workspace/repo.git -> workspace, repo
+func ParseRepositoryFullName(repoURL string) (string, string, error) {
+	path := repoURL
+	if idx := strings.Index(path, Host); idx != -1 {
+		path = path[idx+len(Host):]
+	} else {
+		return "", "", fmt.Errorf("not a Bitbucket repository URL: %s", repoURL)
+	}
+
+	path = strings.TrimPrefix(strings.TrimPrefix(path, ":"), "/")
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("not a Bitbucket repository URL: %s", repoURL)
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// get sends a GET request to a Bitbucket API URL and decodes the response into out.
+// Bitbucket paginates with absolute next links, so unlike the other clients it takes a full URL.
+func (c *Client) get(ctx context.Context, accessToken, url string, out interface{}) error {
+	body, err := c.getRaw(ctx, accessToken, url)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// getRaw sends a GET request to a Bitbucket API URL and returns the response body as is
+func (c *Client) getRaw(ctx context.Context, accessToken, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Public repositories are read without a token
+	if accessToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call bitbucket API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("bitbucket API returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, nil
+}
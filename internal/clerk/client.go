@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"snapdeploy-core/internal/config"
@@ -207,11 +208,23 @@ type UserWithExternalAccounts struct {
 	ExternalAccounts []ExternalAccount `json:"external_accounts"`
 }
 
+// OAuth providers users can connect in Clerk
+const (
+	ProviderGitHub    = "oauth_github"
+	ProviderGitLab    = "oauth_gitlab"
+	ProviderBitbucket = "oauth_bitbucket"
+)
+
 // GetGitHubAccessToken fetches the GitHub OAuth access token for a user
 func (c *Client) GetGitHubAccessToken(ctx context.Context, userID string) (string, error) {
+	return c.GetOAuthAccessToken(ctx, userID, ProviderGitHub)
+}
+
+// GetOAuthAccessToken fetches a user's OAuth access token for a provider (e.g. oauth_gitlab)
+func (c *Client) GetOAuthAccessToken(ctx context.Context, userID, provider string) (string, error) {
 	// Use the correct Clerk API endpoint for OAuth access tokens
 	// https://api.clerk.com/v1/users/{user_id}/oauth_access_tokens/{provider}
-	url := fmt.Sprintf("%s/users/%s/oauth_access_tokens/oauth_github", c.apiURL, userID)
+	url := fmt.Sprintf("%s/users/%s/oauth_access_tokens/%s", c.apiURL, userID, provider)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -249,13 +262,55 @@ func (c *Client) GetGitHubAccessToken(ctx context.Context, userID string) (strin
 	}
 
 	if len(tokensResp) == 0 {
-		return "", fmt.Errorf("no GitHub OAuth token found for user")
+		return "", fmt.Errorf("no %s token found for user", provider)
 	}
 
 	// Return the first token (most recent)
 	return tokensResp[0].Token, nil
 }
 
+// GetGitHubUserID returns the ID of the GitHub account a user connected
+func (c *Client) GetGitHubUserID(ctx context.Context, userID string) (int64, error) {
+	url := fmt.Sprintf("%s/users/%s", c.apiURL, userID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.secretKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("clerk API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var clerkUser UserWithExternalAccounts
+	if err := json.NewDecoder(resp.Body).Decode(&clerkUser); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for _, account := range clerkUser.ExternalAccounts {
+		if account.Provider != ProviderGitHub {
+			continue
+		}
+		githubUserID, err := strconv.ParseInt(account.ProviderUserID, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid GitHub user ID %q: %w", account.ProviderUserID, err)
+		}
+		return githubUserID, nil
+	}
+
+	return 0, fmt.Errorf("no GitHub account connected for user")
+}
+
 // getOAuthAccessToken fetches the OAuth access token for an external account
 func (c *Client) getOAuthAccessToken(ctx context.Context, externalAccountID string) (string, error) {
 	url := fmt.Sprintf("%s/oauth_access_tokens/%s", c.apiURL, externalAccountID)
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// requiredSettings holds the config file settings Load requires
+const requiredSettings = `
+clerk:
+  publishable_key: pk_test_snapdeploy
+  secret_key: sk_test_snapdeploy
+  jwks_url: https://clerk.snapdeploy.app/.well-known/jwks.json
+  issuer: https://clerk.snapdeploy.app
+encryption:
+  key: MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=
+build:
+  backend: docker
+`
+
+// loadWithFile loads the configuration with CONFIG_FILE pointing at a YAML file of content
+func loadWithFile(t *testing.T, content string) (*Config, error) {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", writeFile(t, "snapdeploy.yaml", content))
+	return Load()
+}
+
+func TestLoad_ConfigFile(t *testing.T) {
+	t.Setenv("SERVER_HOST", "127.0.0.1")
+
+	cfg, err := loadWithFile(t, requiredSettings+`
+server:
+  port: 9090
+  host: 0.0.0.0
+base_domain: apps.example.com
+docker_registry: registry.example.com
+cors:
+  allowed_origins: [https://app.example.com, https://admin.example.com]
+`)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Server.Port != "9090" {
+		t.Errorf("Server.Port = %q, want the config file's 9090", cfg.Server.Port)
+	}
+	if cfg.Server.Host != "127.0.0.1" {
+		t.Errorf("Server.Host = %q, want the environment's 127.0.0.1", cfg.Server.Host)
+	}
+	if cfg.AWS.DNS.BaseDomain != "apps.example.com" {
+		t.Errorf("AWS.DNS.BaseDomain = %q, want apps.example.com", cfg.AWS.DNS.BaseDomain)
+	}
+	if cfg.AWS.DockerRegistry != "registry.example.com" {
+		t.Errorf("AWS.DockerRegistry = %q, want registry.example.com", cfg.AWS.DockerRegistry)
+	}
+	if got := strings.Join(cfg.CORS.AllowedOrigins, ","); got != "https://app.example.com,https://admin.example.com" {
+		t.Errorf("CORS.AllowedOrigins = %v, want both origins of the config file", cfg.CORS.AllowedOrigins)
+	}
+	if _, set := os.LookupEnv("BASE_DOMAIN"); set {
+		t.Error("Load() set BASE_DOMAIN in the environment, want the config file to only feed the config")
+	}
+}
+
+func TestLoad_ConfigFileUnknownSettings(t *testing.T) {
+	_, err := loadWithFile(t, requiredSettings+`
+path: /tmp/evil
+aws:
+  region: us-east-1
+  secret_access_key: hunter2
+server:
+  prot: 9090
+`)
+	if err == nil {
+		t.Fatal("Load() error = nil, want the config file's unknown settings reported")
+	}
+	for _, want := range []string{
+		"unknown setting PATH in the config file",
+		"unknown setting AWS_SECRET_ACCESS_KEY in the config file",
+		"unknown setting SERVER_PROT in the config file",
+		"AWS_REGION can't be set in the config file, set it in the environment",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Load() error = %v, want it to contain %q", err, want)
+		}
+	}
+	if os.Getenv("PATH") == "/tmp/evil" {
+		t.Error("Load() set PATH from the config file")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(c *Config)
+		wantErr string
+	}{
+		{"valid", func(c *Config) {}, ""},
+		{"missing Clerk key", func(c *Config) { c.Clerk.SecretKey = "" }, "CLERK_SECRET_KEY is required"},
+		{"short encryption key", func(c *Config) { c.Encryption.Key = "c2hvcnQ=" }, "ENCRYPTION_KEY must be a base64-encoded 32-byte key"},
+		{"no encryption key", func(c *Config) { c.Encryption.Key = "" }, "ENCRYPTION_KEY is required unless"},
+		{"unknown build backend", func(c *Config) { c.Builds.Backend = "jenkins" }, "BUILD_BACKEND must be codebuild, docker or agent"},
+		{"CodeBuild without a project", func(c *Config) { c.Builds.Backend = "codebuild" }, "CODEBUILD_PROJECT_NAME is required"},
+		{"wildcard origin", func(c *Config) { c.CORS.AllowedOrigins = []string{"https://*.example.com"} }, "CORS_ALLOWED_ORIGINS must list http(s) origins"},
+		{"half-configured Postgres", func(c *Config) { c.Datastores.Postgres.Host = "db.example.com" }, "RDS_PASSWORD is required for Project Postgres databases"},
+		{"negative body limit", func(c *Config) { c.Server.MaxBodyBytes = -1 }, "SERVER_MAX_BODY_BYTES must not be negative"},
+		{"canary error rate above 100", func(c *Config) { c.AWS.Canary.MaxErrorPercent = 150 }, "CANARY_MAX_ERROR_PERCENT must be between 0 and 100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadWithFile(t, requiredSettings)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			tt.change(cfg)
+
+			err = cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateReportsEveryProblem(t *testing.T) {
+	cfg, err := loadWithFile(t, requiredSettings)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cfg.Clerk.Issuer = ""
+	cfg.Logs.FanOut = "kafka"
+
+	err = cfg.Validate()
+	for _, want := range []string{"CLERK_ISSUER is required", "LOG_FANOUT must be memory or postgres"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want it to contain %q", err, want)
+		}
+	}
+}
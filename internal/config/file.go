@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// loadFile reads the settings of a YAML or TOML file by the names of the environment variables they stand
+// for. Nested keys are joined with underscores and upper-cased, so
+//
+//	alb:
+//	  listener_arn: arn:aws:elasticloadbalancing:...
+//
+// is read as ALB_LISTENER_ARN, and lists are joined with commas. The settings only feed the config, the
+// process environment is left alone.
+func loadFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var settings map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &settings)
+	case ".toml":
+		err = toml.Unmarshal(data, &settings)
+	default:
+		return nil, fmt.Errorf("config file %s must be a .yaml, .yml or .toml file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	if err := flattenSettings("", settings, values); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// flattenSettings adds the settings to values under their environment variable names
+func flattenSettings(prefix string, settings map[string]any, values map[string]string) error {
+	for key, value := range settings {
+		name := strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		switch v := value.(type) {
+		case nil:
+			// Keys without a value leave the setting unset
+		case map[string]any:
+			if err := flattenSettings(name, v, values); err != nil {
+				return err
+			}
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := scalarSetting(item)
+				if !ok {
+					return fmt.Errorf("%s must list strings, numbers or booleans", name)
+				}
+				items = append(items, s)
+			}
+			values[name] = strings.Join(items, ",")
+		default:
+			s, ok := scalarSetting(v)
+			if !ok {
+				return fmt.Errorf("%s must be a string, number, boolean, list or table", name)
+			}
+			values[name] = s
+		}
+	}
+	return nil
+}
+
+// scalarSetting returns a setting's value as an environment variable would hold it
+func scalarSetting(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
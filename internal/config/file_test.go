@@ -0,0 +1,154 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFile writes a config file named name to a temporary directory, returning its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	want := map[string]string{
+		"SERVER_PORT":          "9090",
+		"ALB_LISTENER_ARN":     "arn:aws:elasticloadbalancing:us-east-1:123456789012:listener/app/snapdeploy/abc",
+		"SUBNET_IDS":           "subnet-abc123,subnet-def456",
+		"LOG_FLUSH_LINES":      "50",
+		"CORS_MAX_AGE_SECONDS": "600",
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"yaml", "snapdeploy.yaml", `
+server:
+  port: 9090
+alb:
+  listener_arn: arn:aws:elasticloadbalancing:us-east-1:123456789012:listener/app/snapdeploy/abc
+subnet_ids: [subnet-abc123, subnet-def456]
+log:
+  flush-lines: 50
+cors:
+  max_age_seconds: 600
+`},
+		{"yml", "snapdeploy.yml", `
+server: {port: 9090}
+alb: {listener_arn: "arn:aws:elasticloadbalancing:us-east-1:123456789012:listener/app/snapdeploy/abc"}
+subnet_ids: [subnet-abc123, subnet-def456]
+log: {flush_lines: 50}
+cors: {max_age_seconds: 600}
+`},
+		{"toml", "snapdeploy.toml", `
+subnet_ids = ["subnet-abc123", "subnet-def456"]
+
+[server]
+port = 9090
+
+[alb]
+listener_arn = "arn:aws:elasticloadbalancing:us-east-1:123456789012:listener/app/snapdeploy/abc"
+
+[log]
+flush-lines = 50
+
+[cors]
+max_age_seconds = 600
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadFile(writeFile(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatalf("loadFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("loadFile() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadFile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    func(t *testing.T) string
+		wantErr string
+	}{
+		{"missing file", func(t *testing.T) string { return filepath.Join(t.TempDir(), "missing.yaml") }, "failed to read config file"},
+		{"unsupported extension", func(t *testing.T) string { return writeFile(t, "snapdeploy.json", `{"server": {"port": 9090}}`) }, "must be a .yaml, .yml or .toml file"},
+		{"invalid yaml", func(t *testing.T) string { return writeFile(t, "snapdeploy.yaml", "server: [port: 9090") }, "failed to parse config file"},
+		{"invalid toml", func(t *testing.T) string { return writeFile(t, "snapdeploy.toml", "[server\nport = 9090") }, "failed to parse config file"},
+		{"invalid setting", func(t *testing.T) string { return writeFile(t, "snapdeploy.yaml", "subnet_ids: [{id: subnet-abc123}]") }, "SUBNET_IDS must list strings, numbers or booleans"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadFile(tt.path(t))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadFile() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFlattenSettings(t *testing.T) {
+	settings := map[string]any{
+		"server": map[string]any{
+			"port":         8080,
+			"read-timeout": int64(30),
+			"idle_timeout": nil,
+		},
+		"cors": map[string]any{
+			"allow_credentials": false,
+			"allowed_origins":   []any{"https://app.snapdeploy.app", "https://admin.snapdeploy.app"},
+		},
+		"canary":             map[string]any{"max": map[string]any{"error_percent": 2.5}},
+		"quota_max_projects": uint64(10),
+	}
+
+	got := map[string]string{}
+	if err := flattenSettings("", settings, got); err != nil {
+		t.Fatalf("flattenSettings() error = %v", err)
+	}
+	want := map[string]string{
+		"SERVER_PORT":              "8080",
+		"SERVER_READ_TIMEOUT":      "30",
+		"CORS_ALLOW_CREDENTIALS":   "false",
+		"CORS_ALLOWED_ORIGINS":     "https://app.snapdeploy.app,https://admin.snapdeploy.app",
+		"CANARY_MAX_ERROR_PERCENT": "2.5",
+		"QUOTA_MAX_PROJECTS":       "10",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("flattenSettings() = %v, want %v", got, want)
+	}
+}
+
+func TestFlattenSettings_InvalidValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]any
+		wantErr  string
+	}{
+		{"table in a list", map[string]any{"subnet_ids": []any{map[string]any{"id": "subnet-abc123"}}}, "SUBNET_IDS must list strings, numbers or booleans"},
+		{"list in a list", map[string]any{"cors": map[string]any{"allowed_origins": []any{[]any{"https://app.snapdeploy.app"}}}}, "CORS_ALLOWED_ORIGINS must list strings, numbers or booleans"},
+		{"unsupported value", map[string]any{"server": map[string]any{"port": struct{}{}}}, "SERVER_PORT must be a string, number, boolean, list or table"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := flattenSettings("", tt.settings, map[string]string{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("flattenSettings() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// advisoryLockRetry is how often a lock held elsewhere is tried again
+const advisoryLockRetry = 2 * time.Second
+
+// AdvisoryLock takes a session-level advisory lock on a key, shared by every server instance using the
+// database, waiting until it is free or ctx is done. The lock holds a pooled connection until unlock is
+// called; if the connection is lost, Postgres releases the lock with it.
+func (db *DB) AdvisoryLock(ctx context.Context, key string) (unlock func(), err error) {
+	for {
+		conn, err := db.pool.Acquire(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire connection: %w", err)
+		}
+
+		var locked bool
+		if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtextextended($1, 0))", key).Scan(&locked); err != nil {
+			conn.Release()
+			return nil, fmt.Errorf("failed to take advisory lock: %w", err)
+		}
+		if locked {
+			return func() {
+				var unlocked bool
+				err := conn.QueryRow(context.Background(), "SELECT pg_advisory_unlock(hashtextextended($1, 0))", key).Scan(&unlocked)
+				if err != nil || !unlocked {
+					// Closing the connection is the only other way to release the lock
+					slog.Warn("Failed to release advisory lock, closing its connection", "key", key, "error", err)
+					conn.Hijack().Close(context.Background())
+					return
+				}
+				conn.Release()
+			}, nil
+		}
+		// Waiting holds no connection
+		conn.Release()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(advisoryLockRetry):
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package database_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/config"
+	"snapdeploy-core/internal/database"
+)
+
+// testDB connects to the database in TEST_DATABASE_URL, skipping the test without one
+func testDB(t *testing.T) *database.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := database.NewConnection(&config.DatabaseConfig{DSN: dsn, MaxConns: 4, MinConns: 0, HealthCheckSeconds: 60})
+	if err != nil {
+		t.Fatalf("NewConnection() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestAdvisoryLock_WaitsUntilUnlocked(t *testing.T) {
+	db := testDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	key := "test-advisory-lock-" + time.Now().Format(time.RFC3339Nano)
+
+	unlock, err := db.AdvisoryLock(ctx, key)
+	if err != nil {
+		t.Fatalf("AdvisoryLock() error = %v", err)
+	}
+
+	acquired := make(chan func(), 1)
+	go func() {
+		second, err := db.AdvisoryLock(ctx, key)
+		if err != nil {
+			t.Errorf("second AdvisoryLock() error = %v", err)
+			close(acquired)
+			return
+		}
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second AdvisoryLock() acquired the lock while it was held")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case second, ok := <-acquired:
+		if ok {
+			second()
+		}
+	case <-ctx.Done():
+		t.Fatal("second AdvisoryLock() didn't acquire the lock once it was released")
+	}
+}
+
+func TestAdvisoryLock_GivesUpWhenContextIsDone(t *testing.T) {
+	db := testDB(t)
+	key := "test-advisory-lock-" + time.Now().Format(time.RFC3339Nano)
+
+	unlock, err := db.AdvisoryLock(context.Background(), key)
+	if err != nil {
+		t.Fatalf("AdvisoryLock() error = %v", err)
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := db.AdvisoryLock(ctx, key); err == nil {
+		t.Error("AdvisoryLock() took a lock that was held")
+	}
+}
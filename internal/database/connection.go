@@ -1,50 +1,109 @@
 package database
 
 import (
-	"database/sql"
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"snapdeploy-core/internal/config"
+	"snapdeploy-core/internal/tracing"
 )
 
-// DB wraps the database connection and provides methods for database operations
+// pingTimeout bounds the connection check made on startup and by Ping
+const pingTimeout = 5 * time.Second
+
+// DB wraps the connection pool and provides methods for database operations
 type DB struct {
-	conn *sql.DB
+	pool    *pgxpool.Pool
+	queries *Queries
 }
 
-// NewConnection creates a new database connection
+// NewConnection creates a new connection pool.
+// Every query runs in a span, named after the sqlc query in its leading comment.
 func NewConnection(cfg *config.DatabaseConfig) (*DB, error) {
-	conn, err := sql.Open(cfg.Driver, cfg.DSN)
+	poolCfg, err := pgxpool.ParseConfig(cfg.DSN)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, fmt.Errorf("failed to parse database DSN: %w", err)
 	}
 
 	// Configure connection pool
-	conn.SetMaxOpenConns(cfg.MaxConns)
-	conn.SetMaxIdleConns(cfg.MinConns)
-	conn.SetConnMaxLifetime(time.Hour)
+	poolCfg.MaxConns = int32(cfg.MaxConns)
+	poolCfg.MinConns = int32(cfg.MinConns)
+	poolCfg.HealthCheckPeriod = time.Duration(cfg.HealthCheckSeconds) * time.Second
+	poolCfg.MaxConnLifetime = time.Duration(cfg.MaxConnLifetimeMinutes) * time.Minute
+	poolCfg.MaxConnIdleTime = time.Duration(cfg.MaxConnIdleMinutes) * time.Minute
+	if cfg.StatementTimeoutSeconds > 0 {
+		poolCfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.Itoa(cfg.StatementTimeoutSeconds * 1000)
+	}
+	poolCfg.ConnConfig.Tracer = queryTracer{}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	db := &DB{pool: pool, queries: New(pool)}
 
 	// Test the connection
-	if err := conn.Ping(); err != nil {
+	if err := db.Ping(); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &DB{conn: conn}, nil
+	return db, nil
 }
 
-// Close closes the database connection
+// Close closes every connection in the pool
 func (db *DB) Close() error {
-	return db.conn.Close()
-}
-
-// GetConnection returns the underlying database connection
-func (db *DB) GetConnection() *sql.DB {
-	return db.conn
+	db.pool.Close()
+	return nil
 }
 
 // Ping tests the database connection
 func (db *DB) Ping() error {
-	return db.conn.Ping()
+	return db.PingContext(context.Background())
+}
+
+// PingContext tests the database connection, giving up when ctx is done or after the ping timeout
+func (db *DB) PingContext(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	return db.pool.Ping(ctx)
+}
+
+// queryTracer runs every query in a span
+type queryTracer struct{}
+
+// TraceQueryStart starts the span of a query
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = tracing.Start(ctx, querySpanName(data.SQL), attribute.String("db.system", "postgresql"))
+	return ctx
+}
+
+// TraceQueryEnd ends the span of a query. A query finding no rows is not an error.
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	err := data.Err
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	tracing.End(span, err)
+}
+
+// querySpanName names a query's span after its sqlc name, e.g. "-- name: GetDeploymentByID :one"
+// becomes "db GetDeploymentByID". Other statements are named "db query".
+func querySpanName(query string) string {
+	if rest, ok := strings.CutPrefix(strings.TrimSpace(query), "-- name: "); ok {
+		if fields := strings.Fields(rest); len(fields) > 0 {
+			return "db " + fields[0]
+		}
+	}
+	return "db query"
 }
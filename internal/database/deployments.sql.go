@@ -8,29 +8,162 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+const ArchiveDeployments = `-- name: ArchiveDeployments :execrows
+WITH archived AS (
+    DELETE FROM deployments
+    WHERE deployments.id IN (
+        SELECT d.id FROM deployments d
+        WHERE d.created_at < $1
+          AND d.status IN ('DEPLOYED', 'FAILED', 'ROLLED_BACK', 'REJECTED')
+          AND d.deleted_at IS NULL
+          AND NOT d.pinned
+          AND d.id NOT IN (
+              SELECT DISTINCT ON (latest.project_id, latest.environment) latest.id FROM deployments latest
+              WHERE latest.status = 'DEPLOYED' AND latest.deleted_at IS NULL
+              ORDER BY latest.project_id, latest.environment, latest.created_at DESC
+          )
+        ORDER BY d.created_at
+        LIMIT $2
+    )
+    RETURNING id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned
+)
+INSERT INTO deployments_archive (id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned)
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM archived
+`
+
+type ArchiveDeploymentsParams struct {
+	CreatedAt sql.NullTime `json:"created_at"`
+	Limit     int32        `json:"limit"`
+}
+
+func (q *Queries) ArchiveDeployments(ctx context.Context, arg *ArchiveDeploymentsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, ArchiveDeployments, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const CountDeploymentsByProjectID = `-- name: CountDeploymentsByProjectID :one
-SELECT COUNT(*) FROM deployments
-WHERE project_id = $1
+SELECT (
+    (SELECT COUNT(*) FROM deployments WHERE deployments.project_id = $1 AND deployments.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments.status = $2)
+        AND ($3::varchar IS NULL OR deployments.branch = $3)
+        AND ($4::timestamp IS NULL OR deployments.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments.labels @> $5)) +
+    (SELECT COUNT(*) FROM deployments_archive WHERE deployments_archive.project_id = $1 AND deployments_archive.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments_archive.status = $2)
+        AND ($3::varchar IS NULL OR deployments_archive.branch = $3)
+        AND ($4::timestamptz IS NULL OR deployments_archive.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments_archive.labels @> $5))
+)::bigint AS count
+`
+
+type CountDeploymentsByProjectIDParams struct {
+	ProjectID uuid.UUID      `json:"project_id"`
+	Status    sql.NullString `json:"status"`
+	Branch    sql.NullString `json:"branch"`
+	Since     sql.NullTime   `json:"since"`
+	Labels    []byte         `json:"labels"`
+}
+
+func (q *Queries) CountDeploymentsByProjectID(ctx context.Context, arg *CountDeploymentsByProjectIDParams) (int64, error) {
+	row := q.db.QueryRow(ctx, CountDeploymentsByProjectID,
+		arg.ProjectID,
+		arg.Status,
+		arg.Branch,
+		arg.Since,
+		arg.Labels,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountDeploymentsByProjectIDIncludingDeleted = `-- name: CountDeploymentsByProjectIDIncludingDeleted :one
+SELECT (
+    (SELECT COUNT(*) FROM deployments WHERE deployments.project_id = $1
+        AND ($2::varchar IS NULL OR deployments.status = $2)
+        AND ($3::varchar IS NULL OR deployments.branch = $3)
+        AND ($4::timestamp IS NULL OR deployments.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments.labels @> $5)) +
+    (SELECT COUNT(*) FROM deployments_archive WHERE deployments_archive.project_id = $1
+        AND ($2::varchar IS NULL OR deployments_archive.status = $2)
+        AND ($3::varchar IS NULL OR deployments_archive.branch = $3)
+        AND ($4::timestamptz IS NULL OR deployments_archive.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments_archive.labels @> $5))
+)::bigint AS count
 `
 
-func (q *Queries) CountDeploymentsByProjectID(ctx context.Context, projectID uuid.UUID) (int64, error) {
-	row := q.db.QueryRowContext(ctx, CountDeploymentsByProjectID, projectID)
+type CountDeploymentsByProjectIDIncludingDeletedParams struct {
+	ProjectID uuid.UUID      `json:"project_id"`
+	Status    sql.NullString `json:"status"`
+	Branch    sql.NullString `json:"branch"`
+	Since     sql.NullTime   `json:"since"`
+	Labels    []byte         `json:"labels"`
+}
+
+func (q *Queries) CountDeploymentsByProjectIDIncludingDeleted(ctx context.Context, arg *CountDeploymentsByProjectIDIncludingDeletedParams) (int64, error) {
+	row := q.db.QueryRow(ctx, CountDeploymentsByProjectIDIncludingDeleted,
+		arg.ProjectID,
+		arg.Status,
+		arg.Branch,
+		arg.Since,
+		arg.Labels,
+	)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
 const CountDeploymentsByUserID = `-- name: CountDeploymentsByUserID :one
+SELECT (
+    (SELECT COUNT(*) FROM deployments WHERE deployments.user_id = $1 AND deployments.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments.status = $2)
+        AND ($3::varchar IS NULL OR deployments.branch = $3)
+        AND ($4::timestamp IS NULL OR deployments.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments.labels @> $5)) +
+    (SELECT COUNT(*) FROM deployments_archive WHERE deployments_archive.user_id = $1 AND deployments_archive.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments_archive.status = $2)
+        AND ($3::varchar IS NULL OR deployments_archive.branch = $3)
+        AND ($4::timestamptz IS NULL OR deployments_archive.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments_archive.labels @> $5))
+)::bigint AS count
+`
+
+type CountDeploymentsByUserIDParams struct {
+	UserID uuid.UUID      `json:"user_id"`
+	Status sql.NullString `json:"status"`
+	Branch sql.NullString `json:"branch"`
+	Since  sql.NullTime   `json:"since"`
+	Labels []byte         `json:"labels"`
+}
+
+func (q *Queries) CountDeploymentsByUserID(ctx context.Context, arg *CountDeploymentsByUserIDParams) (int64, error) {
+	row := q.db.QueryRow(ctx, CountDeploymentsByUserID,
+		arg.UserID,
+		arg.Status,
+		arg.Branch,
+		arg.Since,
+		arg.Labels,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountInProgressDeploymentsByUserID = `-- name: CountInProgressDeploymentsByUserID :one
 SELECT COUNT(*) FROM deployments
-WHERE user_id = $1
+WHERE user_id = $1 AND status IN ('PENDING', 'BUILDING', 'DEPLOYING', 'INTERRUPTED') AND deleted_at IS NULL
 `
 
-func (q *Queries) CountDeploymentsByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
-	row := q.db.QueryRowContext(ctx, CountDeploymentsByUserID, userID)
+func (q *Queries) CountInProgressDeploymentsByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, CountInProgressDeploymentsByUserID, userID)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -46,27 +179,41 @@ INSERT INTO deployments (
     status,
     logs,
     created_at,
-    updated_at
+    updated_at,
+    type,
+    environment,
+    image_uri,
+    image_digest,
+    title,
+    description,
+    labels
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
 )
-RETURNING id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at
+RETURNING id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned
 `
 
 type CreateDeploymentParams struct {
-	ID         uuid.UUID      `json:"id"`
-	ProjectID  uuid.UUID      `json:"project_id"`
-	UserID     uuid.UUID      `json:"user_id"`
-	CommitHash string         `json:"commit_hash"`
-	Branch     string         `json:"branch"`
-	Status     string         `json:"status"`
-	Logs       sql.NullString `json:"logs"`
-	CreatedAt  sql.NullTime   `json:"created_at"`
-	UpdatedAt  sql.NullTime   `json:"updated_at"`
+	ID          uuid.UUID      `json:"id"`
+	ProjectID   uuid.UUID      `json:"project_id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	CommitHash  string         `json:"commit_hash"`
+	Branch      string         `json:"branch"`
+	Status      string         `json:"status"`
+	Logs        sql.NullString `json:"logs"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	Type        string         `json:"type"`
+	Environment string         `json:"environment"`
+	ImageUri    string         `json:"image_uri"`
+	ImageDigest string         `json:"image_digest"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Labels      []byte         `json:"labels"`
 }
 
 func (q *Queries) CreateDeployment(ctx context.Context, arg *CreateDeploymentParams) (*Deployment, error) {
-	row := q.db.QueryRowContext(ctx, CreateDeployment,
+	row := q.db.QueryRow(ctx, CreateDeployment,
 		arg.ID,
 		arg.ProjectID,
 		arg.UserID,
@@ -76,6 +223,13 @@ func (q *Queries) CreateDeployment(ctx context.Context, arg *CreateDeploymentPar
 		arg.Logs,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.Type,
+		arg.Environment,
+		arg.ImageUri,
+		arg.ImageDigest,
+		arg.Title,
+		arg.Description,
+		arg.Labels,
 	)
 	var i Deployment
 	err := row.Scan(
@@ -88,27 +242,71 @@ func (q *Queries) CreateDeployment(ctx context.Context, arg *CreateDeploymentPar
 		&i.Logs,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Type,
+		&i.DeletedAt,
+		&i.Environment,
+		&i.ImageUri,
+		&i.ImageDigest,
+		&i.Title,
+		&i.Description,
+		&i.Labels,
+		&i.Pinned,
 	)
 	return &i, err
 }
 
-const DeleteDeployment = `-- name: DeleteDeployment :exec
-DELETE FROM deployments
-WHERE id = $1
+const ExistsDeploymentByID = `-- name: ExistsDeploymentByID :one
+SELECT EXISTS(
+    SELECT 1 FROM deployments
+    WHERE id = $1
+)
 `
 
-func (q *Queries) DeleteDeployment(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, DeleteDeployment, id)
-	return err
+func (q *Queries) ExistsDeploymentByID(ctx context.Context, id uuid.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, ExistsDeploymentByID, id)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const GetArchivedDeploymentByID = `-- name: GetArchivedDeploymentByID :one
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) GetArchivedDeploymentByID(ctx context.Context, id uuid.UUID) (*DeploymentsArchive, error) {
+	row := q.db.QueryRow(ctx, GetArchivedDeploymentByID, id)
+	var i DeploymentsArchive
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.CommitHash,
+		&i.Branch,
+		&i.Status,
+		&i.Logs,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Type,
+		&i.DeletedAt,
+		&i.Environment,
+		&i.ImageUri,
+		&i.ImageDigest,
+		&i.Title,
+		&i.Description,
+		&i.Labels,
+		&i.Pinned,
+	)
+	return &i, err
 }
 
 const GetDeploymentByID = `-- name: GetDeploymentByID :one
-SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at FROM deployments
-WHERE id = $1
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetDeploymentByID(ctx context.Context, id uuid.UUID) (*Deployment, error) {
-	row := q.db.QueryRowContext(ctx, GetDeploymentByID, id)
+	row := q.db.QueryRow(ctx, GetDeploymentByID, id)
 	var i Deployment
 	err := row.Scan(
 		&i.ID,
@@ -120,32 +318,139 @@ func (q *Queries) GetDeploymentByID(ctx context.Context, id uuid.UUID) (*Deploym
 		&i.Logs,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Type,
+		&i.DeletedAt,
+		&i.Environment,
+		&i.ImageUri,
+		&i.ImageDigest,
+		&i.Title,
+		&i.Description,
+		&i.Labels,
+		&i.Pinned,
+	)
+	return &i, err
+}
+
+const GetDeploymentByIDIncludingDeleted = `-- name: GetDeploymentByIDIncludingDeleted :one
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.id = $1
+UNION ALL
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.id = $1
+LIMIT 1
+`
+
+type GetDeploymentByIDIncludingDeletedRow struct {
+	ID          uuid.UUID      `json:"id"`
+	ProjectID   uuid.UUID      `json:"project_id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	CommitHash  string         `json:"commit_hash"`
+	Branch      string         `json:"branch"`
+	Status      string         `json:"status"`
+	Logs        sql.NullString `json:"logs"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	Type        string         `json:"type"`
+	DeletedAt   sql.NullTime   `json:"deleted_at"`
+	Environment string         `json:"environment"`
+	ImageUri    string         `json:"image_uri"`
+	ImageDigest string         `json:"image_digest"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Labels      []byte         `json:"labels"`
+	Pinned      bool           `json:"pinned"`
+}
+
+func (q *Queries) GetDeploymentByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (*GetDeploymentByIDIncludingDeletedRow, error) {
+	row := q.db.QueryRow(ctx, GetDeploymentByIDIncludingDeleted, id)
+	var i GetDeploymentByIDIncludingDeletedRow
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.CommitHash,
+		&i.Branch,
+		&i.Status,
+		&i.Logs,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Type,
+		&i.DeletedAt,
+		&i.Environment,
+		&i.ImageUri,
+		&i.ImageDigest,
+		&i.Title,
+		&i.Description,
+		&i.Labels,
+		&i.Pinned,
 	)
 	return &i, err
 }
 
 const GetDeploymentsByProjectID = `-- name: GetDeploymentsByProjectID :many
-SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at FROM deployments
-WHERE project_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.project_id = $1 AND deployments.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments.status = $2)
+        AND ($3::varchar IS NULL OR deployments.branch = $3)
+        AND ($4::timestamp IS NULL OR deployments.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments.labels @> $5)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.project_id = $1 AND deployments_archive.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments_archive.status = $2)
+        AND ($3::varchar IS NULL OR deployments_archive.branch = $3)
+        AND ($4::timestamptz IS NULL OR deployments_archive.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments_archive.labels @> $5)
+) AS history
+ORDER BY created_at DESC, id DESC
+LIMIT $6 OFFSET $7
 `
 
 type GetDeploymentsByProjectIDParams struct {
-	ProjectID uuid.UUID `json:"project_id"`
-	Limit     int32     `json:"limit"`
-	Offset    int32     `json:"offset"`
+	ProjectID  uuid.UUID      `json:"project_id"`
+	Status     sql.NullString `json:"status"`
+	Branch     sql.NullString `json:"branch"`
+	Since      sql.NullTime   `json:"since"`
+	Labels     []byte         `json:"labels"`
+	PageSize   int32          `json:"page_size"`
+	PageOffset int32          `json:"page_offset"`
+}
+
+type GetDeploymentsByProjectIDRow struct {
+	ID          uuid.UUID      `json:"id"`
+	ProjectID   uuid.UUID      `json:"project_id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	CommitHash  string         `json:"commit_hash"`
+	Branch      string         `json:"branch"`
+	Status      string         `json:"status"`
+	Logs        sql.NullString `json:"logs"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	Type        string         `json:"type"`
+	DeletedAt   sql.NullTime   `json:"deleted_at"`
+	Environment string         `json:"environment"`
+	ImageUri    string         `json:"image_uri"`
+	ImageDigest string         `json:"image_digest"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Labels      []byte         `json:"labels"`
+	Pinned      bool           `json:"pinned"`
 }
 
-func (q *Queries) GetDeploymentsByProjectID(ctx context.Context, arg *GetDeploymentsByProjectIDParams) ([]*Deployment, error) {
-	rows, err := q.db.QueryContext(ctx, GetDeploymentsByProjectID, arg.ProjectID, arg.Limit, arg.Offset)
+func (q *Queries) GetDeploymentsByProjectID(ctx context.Context, arg *GetDeploymentsByProjectIDParams) ([]*GetDeploymentsByProjectIDRow, error) {
+	rows, err := q.db.Query(ctx, GetDeploymentsByProjectID,
+		arg.ProjectID,
+		arg.Status,
+		arg.Branch,
+		arg.Since,
+		arg.Labels,
+		arg.PageSize,
+		arg.PageOffset,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []*Deployment{}
+	items := []*GetDeploymentsByProjectIDRow{}
 	for rows.Next() {
-		var i Deployment
+		var i GetDeploymentsByProjectIDRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.ProjectID,
@@ -156,42 +461,196 @@ func (q *Queries) GetDeploymentsByProjectID(ctx context.Context, arg *GetDeploym
 			&i.Logs,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Type,
+			&i.DeletedAt,
+			&i.Environment,
+			&i.ImageUri,
+			&i.ImageDigest,
+			&i.Title,
+			&i.Description,
+			&i.Labels,
+			&i.Pinned,
 		); err != nil {
 			return nil, err
 		}
 		items = append(items, &i)
 	}
-	if err := rows.Close(); err != nil {
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetDeploymentsByProjectIDAfter = `-- name: GetDeploymentsByProjectIDAfter :many
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.project_id = $1 AND deployments.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments.status = $2)
+        AND ($3::varchar IS NULL OR deployments.branch = $3)
+        AND ($4::timestamp IS NULL OR deployments.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments.labels @> $5)
+        AND (deployments.created_at, deployments.id) < ($6::timestamp, $7::uuid)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.project_id = $1 AND deployments_archive.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments_archive.status = $2)
+        AND ($3::varchar IS NULL OR deployments_archive.branch = $3)
+        AND ($4::timestamptz IS NULL OR deployments_archive.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments_archive.labels @> $5)
+        AND (deployments_archive.created_at, deployments_archive.id) < ($6::timestamptz, $7::uuid)
+) AS history
+ORDER BY created_at DESC, id DESC
+LIMIT $8
+`
+
+type GetDeploymentsByProjectIDAfterParams struct {
+	ProjectID      uuid.UUID      `json:"project_id"`
+	Status         sql.NullString `json:"status"`
+	Branch         sql.NullString `json:"branch"`
+	Since          sql.NullTime   `json:"since"`
+	Labels         []byte         `json:"labels"`
+	AfterCreatedAt time.Time      `json:"after_created_at"`
+	AfterID        uuid.UUID      `json:"after_id"`
+	PageSize       int32          `json:"page_size"`
+}
+
+type GetDeploymentsByProjectIDAfterRow struct {
+	ID          uuid.UUID      `json:"id"`
+	ProjectID   uuid.UUID      `json:"project_id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	CommitHash  string         `json:"commit_hash"`
+	Branch      string         `json:"branch"`
+	Status      string         `json:"status"`
+	Logs        sql.NullString `json:"logs"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	Type        string         `json:"type"`
+	DeletedAt   sql.NullTime   `json:"deleted_at"`
+	Environment string         `json:"environment"`
+	ImageUri    string         `json:"image_uri"`
+	ImageDigest string         `json:"image_digest"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Labels      []byte         `json:"labels"`
+	Pinned      bool           `json:"pinned"`
+}
+
+func (q *Queries) GetDeploymentsByProjectIDAfter(ctx context.Context, arg *GetDeploymentsByProjectIDAfterParams) ([]*GetDeploymentsByProjectIDAfterRow, error) {
+	rows, err := q.db.Query(ctx, GetDeploymentsByProjectIDAfter,
+		arg.ProjectID,
+		arg.Status,
+		arg.Branch,
+		arg.Since,
+		arg.Labels,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+	items := []*GetDeploymentsByProjectIDAfterRow{}
+	for rows.Next() {
+		var i GetDeploymentsByProjectIDAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.UserID,
+			&i.CommitHash,
+			&i.Branch,
+			&i.Status,
+			&i.Logs,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Type,
+			&i.DeletedAt,
+			&i.Environment,
+			&i.ImageUri,
+			&i.ImageDigest,
+			&i.Title,
+			&i.Description,
+			&i.Labels,
+			&i.Pinned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
-const GetDeploymentsByUserID = `-- name: GetDeploymentsByUserID :many
-SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at FROM deployments
-WHERE user_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3
+const GetDeploymentsByProjectIDAfterAscending = `-- name: GetDeploymentsByProjectIDAfterAscending :many
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.project_id = $1 AND deployments.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments.status = $2)
+        AND ($3::varchar IS NULL OR deployments.branch = $3)
+        AND ($4::timestamp IS NULL OR deployments.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments.labels @> $5)
+        AND (deployments.created_at, deployments.id) > ($6::timestamp, $7::uuid)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.project_id = $1 AND deployments_archive.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments_archive.status = $2)
+        AND ($3::varchar IS NULL OR deployments_archive.branch = $3)
+        AND ($4::timestamptz IS NULL OR deployments_archive.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments_archive.labels @> $5)
+        AND (deployments_archive.created_at, deployments_archive.id) > ($6::timestamptz, $7::uuid)
+) AS history
+ORDER BY created_at ASC, id ASC
+LIMIT $8
 `
 
-type GetDeploymentsByUserIDParams struct {
-	UserID uuid.UUID `json:"user_id"`
-	Limit  int32     `json:"limit"`
-	Offset int32     `json:"offset"`
+type GetDeploymentsByProjectIDAfterAscendingParams struct {
+	ProjectID      uuid.UUID      `json:"project_id"`
+	Status         sql.NullString `json:"status"`
+	Branch         sql.NullString `json:"branch"`
+	Since          sql.NullTime   `json:"since"`
+	Labels         []byte         `json:"labels"`
+	AfterCreatedAt time.Time      `json:"after_created_at"`
+	AfterID        uuid.UUID      `json:"after_id"`
+	PageSize       int32          `json:"page_size"`
+}
+
+type GetDeploymentsByProjectIDAfterAscendingRow struct {
+	ID          uuid.UUID      `json:"id"`
+	ProjectID   uuid.UUID      `json:"project_id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	CommitHash  string         `json:"commit_hash"`
+	Branch      string         `json:"branch"`
+	Status      string         `json:"status"`
+	Logs        sql.NullString `json:"logs"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	Type        string         `json:"type"`
+	DeletedAt   sql.NullTime   `json:"deleted_at"`
+	Environment string         `json:"environment"`
+	ImageUri    string         `json:"image_uri"`
+	ImageDigest string         `json:"image_digest"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Labels      []byte         `json:"labels"`
+	Pinned      bool           `json:"pinned"`
 }
 
-func (q *Queries) GetDeploymentsByUserID(ctx context.Context, arg *GetDeploymentsByUserIDParams) ([]*Deployment, error) {
-	rows, err := q.db.QueryContext(ctx, GetDeploymentsByUserID, arg.UserID, arg.Limit, arg.Offset)
+func (q *Queries) GetDeploymentsByProjectIDAfterAscending(ctx context.Context, arg *GetDeploymentsByProjectIDAfterAscendingParams) ([]*GetDeploymentsByProjectIDAfterAscendingRow, error) {
+	rows, err := q.db.Query(ctx, GetDeploymentsByProjectIDAfterAscending,
+		arg.ProjectID,
+		arg.Status,
+		arg.Branch,
+		arg.Since,
+		arg.Labels,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []*Deployment{}
+	items := []*GetDeploymentsByProjectIDAfterAscendingRow{}
 	for rows.Next() {
-		var i Deployment
+		var i GetDeploymentsByProjectIDAfterAscendingRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.ProjectID,
@@ -202,66 +661,1291 @@ func (q *Queries) GetDeploymentsByUserID(ctx context.Context, arg *GetDeployment
 			&i.Logs,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Type,
+			&i.DeletedAt,
+			&i.Environment,
+			&i.ImageUri,
+			&i.ImageDigest,
+			&i.Title,
+			&i.Description,
+			&i.Labels,
+			&i.Pinned,
 		); err != nil {
 			return nil, err
 		}
 		items = append(items, &i)
 	}
-	if err := rows.Close(); err != nil {
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetDeploymentsByProjectIDAscending = `-- name: GetDeploymentsByProjectIDAscending :many
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.project_id = $1 AND deployments.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments.status = $2)
+        AND ($3::varchar IS NULL OR deployments.branch = $3)
+        AND ($4::timestamp IS NULL OR deployments.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments.labels @> $5)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.project_id = $1 AND deployments_archive.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments_archive.status = $2)
+        AND ($3::varchar IS NULL OR deployments_archive.branch = $3)
+        AND ($4::timestamptz IS NULL OR deployments_archive.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments_archive.labels @> $5)
+) AS history
+ORDER BY created_at ASC, id ASC
+LIMIT $6 OFFSET $7
+`
+
+type GetDeploymentsByProjectIDAscendingParams struct {
+	ProjectID  uuid.UUID      `json:"project_id"`
+	Status     sql.NullString `json:"status"`
+	Branch     sql.NullString `json:"branch"`
+	Since      sql.NullTime   `json:"since"`
+	Labels     []byte         `json:"labels"`
+	PageSize   int32          `json:"page_size"`
+	PageOffset int32          `json:"page_offset"`
+}
+
+type GetDeploymentsByProjectIDAscendingRow struct {
+	ID          uuid.UUID      `json:"id"`
+	ProjectID   uuid.UUID      `json:"project_id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	CommitHash  string         `json:"commit_hash"`
+	Branch      string         `json:"branch"`
+	Status      string         `json:"status"`
+	Logs        sql.NullString `json:"logs"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	Type        string         `json:"type"`
+	DeletedAt   sql.NullTime   `json:"deleted_at"`
+	Environment string         `json:"environment"`
+	ImageUri    string         `json:"image_uri"`
+	ImageDigest string         `json:"image_digest"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Labels      []byte         `json:"labels"`
+	Pinned      bool           `json:"pinned"`
+}
+
+func (q *Queries) GetDeploymentsByProjectIDAscending(ctx context.Context, arg *GetDeploymentsByProjectIDAscendingParams) ([]*GetDeploymentsByProjectIDAscendingRow, error) {
+	rows, err := q.db.Query(ctx, GetDeploymentsByProjectIDAscending,
+		arg.ProjectID,
+		arg.Status,
+		arg.Branch,
+		arg.Since,
+		arg.Labels,
+		arg.PageSize,
+		arg.PageOffset,
+	)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+	items := []*GetDeploymentsByProjectIDAscendingRow{}
+	for rows.Next() {
+		var i GetDeploymentsByProjectIDAscendingRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.UserID,
+			&i.CommitHash,
+			&i.Branch,
+			&i.Status,
+			&i.Logs,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Type,
+			&i.DeletedAt,
+			&i.Environment,
+			&i.ImageUri,
+			&i.ImageDigest,
+			&i.Title,
+			&i.Description,
+			&i.Labels,
+			&i.Pinned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
-const GetLatestDeploymentByProjectID = `-- name: GetLatestDeploymentByProjectID :one
-SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at FROM deployments
-WHERE project_id = $1
-ORDER BY created_at DESC
-LIMIT 1
+const GetDeploymentsByProjectIDIncludingDeleted = `-- name: GetDeploymentsByProjectIDIncludingDeleted :many
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.project_id = $1
+        AND ($2::varchar IS NULL OR deployments.status = $2)
+        AND ($3::varchar IS NULL OR deployments.branch = $3)
+        AND ($4::timestamp IS NULL OR deployments.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments.labels @> $5)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.project_id = $1
+        AND ($2::varchar IS NULL OR deployments_archive.status = $2)
+        AND ($3::varchar IS NULL OR deployments_archive.branch = $3)
+        AND ($4::timestamptz IS NULL OR deployments_archive.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments_archive.labels @> $5)
+) AS history
+ORDER BY created_at DESC, id DESC
+LIMIT $6 OFFSET $7
 `
 
-func (q *Queries) GetLatestDeploymentByProjectID(ctx context.Context, projectID uuid.UUID) (*Deployment, error) {
-	row := q.db.QueryRowContext(ctx, GetLatestDeploymentByProjectID, projectID)
-	var i Deployment
-	err := row.Scan(
-		&i.ID,
-		&i.ProjectID,
-		&i.UserID,
-		&i.CommitHash,
-		&i.Branch,
-		&i.Status,
-		&i.Logs,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+type GetDeploymentsByProjectIDIncludingDeletedParams struct {
+	ProjectID  uuid.UUID      `json:"project_id"`
+	Status     sql.NullString `json:"status"`
+	Branch     sql.NullString `json:"branch"`
+	Since      sql.NullTime   `json:"since"`
+	Labels     []byte         `json:"labels"`
+	PageSize   int32          `json:"page_size"`
+	PageOffset int32          `json:"page_offset"`
+}
+
+type GetDeploymentsByProjectIDIncludingDeletedRow struct {
+	ID          uuid.UUID      `json:"id"`
+	ProjectID   uuid.UUID      `json:"project_id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	CommitHash  string         `json:"commit_hash"`
+	Branch      string         `json:"branch"`
+	Status      string         `json:"status"`
+	Logs        sql.NullString `json:"logs"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	Type        string         `json:"type"`
+	DeletedAt   sql.NullTime   `json:"deleted_at"`
+	Environment string         `json:"environment"`
+	ImageUri    string         `json:"image_uri"`
+	ImageDigest string         `json:"image_digest"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Labels      []byte         `json:"labels"`
+	Pinned      bool           `json:"pinned"`
+}
+
+func (q *Queries) GetDeploymentsByProjectIDIncludingDeleted(ctx context.Context, arg *GetDeploymentsByProjectIDIncludingDeletedParams) ([]*GetDeploymentsByProjectIDIncludingDeletedRow, error) {
+	rows, err := q.db.Query(ctx, GetDeploymentsByProjectIDIncludingDeleted,
+		arg.ProjectID,
+		arg.Status,
+		arg.Branch,
+		arg.Since,
+		arg.Labels,
+		arg.PageSize,
+		arg.PageOffset,
 	)
-	return &i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetDeploymentsByProjectIDIncludingDeletedRow{}
+	for rows.Next() {
+		var i GetDeploymentsByProjectIDIncludingDeletedRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.UserID,
+			&i.CommitHash,
+			&i.Branch,
+			&i.Status,
+			&i.Logs,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Type,
+			&i.DeletedAt,
+			&i.Environment,
+			&i.ImageUri,
+			&i.ImageDigest,
+			&i.Title,
+			&i.Description,
+			&i.Labels,
+			&i.Pinned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const UpdateDeployment = `-- name: UpdateDeployment :exec
-UPDATE deployments
-SET
-    status = $2,
-    logs = $3,
-    updated_at = $4
-WHERE id = $1
+const GetDeploymentsByProjectIDIncludingDeletedAfter = `-- name: GetDeploymentsByProjectIDIncludingDeletedAfter :many
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.project_id = $1
+        AND ($2::varchar IS NULL OR deployments.status = $2)
+        AND ($3::varchar IS NULL OR deployments.branch = $3)
+        AND ($4::timestamp IS NULL OR deployments.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments.labels @> $5)
+        AND (deployments.created_at, deployments.id) < ($6::timestamp, $7::uuid)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.project_id = $1
+        AND ($2::varchar IS NULL OR deployments_archive.status = $2)
+        AND ($3::varchar IS NULL OR deployments_archive.branch = $3)
+        AND ($4::timestamptz IS NULL OR deployments_archive.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments_archive.labels @> $5)
+        AND (deployments_archive.created_at, deployments_archive.id) < ($6::timestamptz, $7::uuid)
+) AS history
+ORDER BY created_at DESC, id DESC
+LIMIT $8
 `
 
-type UpdateDeploymentParams struct {
-	ID        uuid.UUID      `json:"id"`
-	Status    string         `json:"status"`
-	Logs      sql.NullString `json:"logs"`
-	UpdatedAt sql.NullTime   `json:"updated_at"`
+type GetDeploymentsByProjectIDIncludingDeletedAfterParams struct {
+	ProjectID      uuid.UUID      `json:"project_id"`
+	Status         sql.NullString `json:"status"`
+	Branch         sql.NullString `json:"branch"`
+	Since          sql.NullTime   `json:"since"`
+	Labels         []byte         `json:"labels"`
+	AfterCreatedAt time.Time      `json:"after_created_at"`
+	AfterID        uuid.UUID      `json:"after_id"`
+	PageSize       int32          `json:"page_size"`
 }
 
-func (q *Queries) UpdateDeployment(ctx context.Context, arg *UpdateDeploymentParams) error {
-	_, err := q.db.ExecContext(ctx, UpdateDeployment,
-		arg.ID,
+type GetDeploymentsByProjectIDIncludingDeletedAfterRow struct {
+	ID          uuid.UUID      `json:"id"`
+	ProjectID   uuid.UUID      `json:"project_id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	CommitHash  string         `json:"commit_hash"`
+	Branch      string         `json:"branch"`
+	Status      string         `json:"status"`
+	Logs        sql.NullString `json:"logs"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	Type        string         `json:"type"`
+	DeletedAt   sql.NullTime   `json:"deleted_at"`
+	Environment string         `json:"environment"`
+	ImageUri    string         `json:"image_uri"`
+	ImageDigest string         `json:"image_digest"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Labels      []byte         `json:"labels"`
+	Pinned      bool           `json:"pinned"`
+}
+
+func (q *Queries) GetDeploymentsByProjectIDIncludingDeletedAfter(ctx context.Context, arg *GetDeploymentsByProjectIDIncludingDeletedAfterParams) ([]*GetDeploymentsByProjectIDIncludingDeletedAfterRow, error) {
+	rows, err := q.db.Query(ctx, GetDeploymentsByProjectIDIncludingDeletedAfter,
+		arg.ProjectID,
 		arg.Status,
-		arg.Logs,
-		arg.UpdatedAt,
+		arg.Branch,
+		arg.Since,
+		arg.Labels,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
 	)
-	return err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetDeploymentsByProjectIDIncludingDeletedAfterRow{}
+	for rows.Next() {
+		var i GetDeploymentsByProjectIDIncludingDeletedAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.UserID,
+			&i.CommitHash,
+			&i.Branch,
+			&i.Status,
+			&i.Logs,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Type,
+			&i.DeletedAt,
+			&i.Environment,
+			&i.ImageUri,
+			&i.ImageDigest,
+			&i.Title,
+			&i.Description,
+			&i.Labels,
+			&i.Pinned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetDeploymentsByProjectIDIncludingDeletedAfterAscending = `-- name: GetDeploymentsByProjectIDIncludingDeletedAfterAscending :many
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.project_id = $1
+        AND ($2::varchar IS NULL OR deployments.status = $2)
+        AND ($3::varchar IS NULL OR deployments.branch = $3)
+        AND ($4::timestamp IS NULL OR deployments.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments.labels @> $5)
+        AND (deployments.created_at, deployments.id) > ($6::timestamp, $7::uuid)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.project_id = $1
+        AND ($2::varchar IS NULL OR deployments_archive.status = $2)
+        AND ($3::varchar IS NULL OR deployments_archive.branch = $3)
+        AND ($4::timestamptz IS NULL OR deployments_archive.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments_archive.labels @> $5)
+        AND (deployments_archive.created_at, deployments_archive.id) > ($6::timestamptz, $7::uuid)
+) AS history
+ORDER BY created_at ASC, id ASC
+LIMIT $8
+`
+
+type GetDeploymentsByProjectIDIncludingDeletedAfterAscendingParams struct {
+	ProjectID      uuid.UUID      `json:"project_id"`
+	Status         sql.NullString `json:"status"`
+	Branch         sql.NullString `json:"branch"`
+	Since          sql.NullTime   `json:"since"`
+	Labels         []byte         `json:"labels"`
+	AfterCreatedAt time.Time      `json:"after_created_at"`
+	AfterID        uuid.UUID      `json:"after_id"`
+	PageSize       int32          `json:"page_size"`
+}
+
+type GetDeploymentsByProjectIDIncludingDeletedAfterAscendingRow struct {
+	ID          uuid.UUID      `json:"id"`
+	ProjectID   uuid.UUID      `json:"project_id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	CommitHash  string         `json:"commit_hash"`
+	Branch      string         `json:"branch"`
+	Status      string         `json:"status"`
+	Logs        sql.NullString `json:"logs"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	Type        string         `json:"type"`
+	DeletedAt   sql.NullTime   `json:"deleted_at"`
+	Environment string         `json:"environment"`
+	ImageUri    string         `json:"image_uri"`
+	ImageDigest string         `json:"image_digest"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Labels      []byte         `json:"labels"`
+	Pinned      bool           `json:"pinned"`
+}
+
+func (q *Queries) GetDeploymentsByProjectIDIncludingDeletedAfterAscending(ctx context.Context, arg *GetDeploymentsByProjectIDIncludingDeletedAfterAscendingParams) ([]*GetDeploymentsByProjectIDIncludingDeletedAfterAscendingRow, error) {
+	rows, err := q.db.Query(ctx, GetDeploymentsByProjectIDIncludingDeletedAfterAscending,
+		arg.ProjectID,
+		arg.Status,
+		arg.Branch,
+		arg.Since,
+		arg.Labels,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetDeploymentsByProjectIDIncludingDeletedAfterAscendingRow{}
+	for rows.Next() {
+		var i GetDeploymentsByProjectIDIncludingDeletedAfterAscendingRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.UserID,
+			&i.CommitHash,
+			&i.Branch,
+			&i.Status,
+			&i.Logs,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Type,
+			&i.DeletedAt,
+			&i.Environment,
+			&i.ImageUri,
+			&i.ImageDigest,
+			&i.Title,
+			&i.Description,
+			&i.Labels,
+			&i.Pinned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetDeploymentsByProjectIDIncludingDeletedAscending = `-- name: GetDeploymentsByProjectIDIncludingDeletedAscending :many
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.project_id = $1
+        AND ($2::varchar IS NULL OR deployments.status = $2)
+        AND ($3::varchar IS NULL OR deployments.branch = $3)
+        AND ($4::timestamp IS NULL OR deployments.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments.labels @> $5)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.project_id = $1
+        AND ($2::varchar IS NULL OR deployments_archive.status = $2)
+        AND ($3::varchar IS NULL OR deployments_archive.branch = $3)
+        AND ($4::timestamptz IS NULL OR deployments_archive.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments_archive.labels @> $5)
+) AS history
+ORDER BY created_at ASC, id ASC
+LIMIT $6 OFFSET $7
+`
+
+type GetDeploymentsByProjectIDIncludingDeletedAscendingParams struct {
+	ProjectID  uuid.UUID      `json:"project_id"`
+	Status     sql.NullString `json:"status"`
+	Branch     sql.NullString `json:"branch"`
+	Since      sql.NullTime   `json:"since"`
+	Labels     []byte         `json:"labels"`
+	PageSize   int32          `json:"page_size"`
+	PageOffset int32          `json:"page_offset"`
+}
+
+type GetDeploymentsByProjectIDIncludingDeletedAscendingRow struct {
+	ID          uuid.UUID      `json:"id"`
+	ProjectID   uuid.UUID      `json:"project_id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	CommitHash  string         `json:"commit_hash"`
+	Branch      string         `json:"branch"`
+	Status      string         `json:"status"`
+	Logs        sql.NullString `json:"logs"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	Type        string         `json:"type"`
+	DeletedAt   sql.NullTime   `json:"deleted_at"`
+	Environment string         `json:"environment"`
+	ImageUri    string         `json:"image_uri"`
+	ImageDigest string         `json:"image_digest"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Labels      []byte         `json:"labels"`
+	Pinned      bool           `json:"pinned"`
+}
+
+func (q *Queries) GetDeploymentsByProjectIDIncludingDeletedAscending(ctx context.Context, arg *GetDeploymentsByProjectIDIncludingDeletedAscendingParams) ([]*GetDeploymentsByProjectIDIncludingDeletedAscendingRow, error) {
+	rows, err := q.db.Query(ctx, GetDeploymentsByProjectIDIncludingDeletedAscending,
+		arg.ProjectID,
+		arg.Status,
+		arg.Branch,
+		arg.Since,
+		arg.Labels,
+		arg.PageSize,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetDeploymentsByProjectIDIncludingDeletedAscendingRow{}
+	for rows.Next() {
+		var i GetDeploymentsByProjectIDIncludingDeletedAscendingRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.UserID,
+			&i.CommitHash,
+			&i.Branch,
+			&i.Status,
+			&i.Logs,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Type,
+			&i.DeletedAt,
+			&i.Environment,
+			&i.ImageUri,
+			&i.ImageDigest,
+			&i.Title,
+			&i.Description,
+			&i.Labels,
+			&i.Pinned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetDeploymentsByUserID = `-- name: GetDeploymentsByUserID :many
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.user_id = $1 AND deployments.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments.status = $2)
+        AND ($3::varchar IS NULL OR deployments.branch = $3)
+        AND ($4::timestamp IS NULL OR deployments.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments.labels @> $5)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.user_id = $1 AND deployments_archive.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments_archive.status = $2)
+        AND ($3::varchar IS NULL OR deployments_archive.branch = $3)
+        AND ($4::timestamptz IS NULL OR deployments_archive.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments_archive.labels @> $5)
+) AS history
+ORDER BY created_at DESC, id DESC
+LIMIT $6 OFFSET $7
+`
+
+type GetDeploymentsByUserIDParams struct {
+	UserID     uuid.UUID      `json:"user_id"`
+	Status     sql.NullString `json:"status"`
+	Branch     sql.NullString `json:"branch"`
+	Since      sql.NullTime   `json:"since"`
+	Labels     []byte         `json:"labels"`
+	PageSize   int32          `json:"page_size"`
+	PageOffset int32          `json:"page_offset"`
+}
+
+type GetDeploymentsByUserIDRow struct {
+	ID          uuid.UUID      `json:"id"`
+	ProjectID   uuid.UUID      `json:"project_id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	CommitHash  string         `json:"commit_hash"`
+	Branch      string         `json:"branch"`
+	Status      string         `json:"status"`
+	Logs        sql.NullString `json:"logs"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	Type        string         `json:"type"`
+	DeletedAt   sql.NullTime   `json:"deleted_at"`
+	Environment string         `json:"environment"`
+	ImageUri    string         `json:"image_uri"`
+	ImageDigest string         `json:"image_digest"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Labels      []byte         `json:"labels"`
+	Pinned      bool           `json:"pinned"`
+}
+
+func (q *Queries) GetDeploymentsByUserID(ctx context.Context, arg *GetDeploymentsByUserIDParams) ([]*GetDeploymentsByUserIDRow, error) {
+	rows, err := q.db.Query(ctx, GetDeploymentsByUserID,
+		arg.UserID,
+		arg.Status,
+		arg.Branch,
+		arg.Since,
+		arg.Labels,
+		arg.PageSize,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetDeploymentsByUserIDRow{}
+	for rows.Next() {
+		var i GetDeploymentsByUserIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.UserID,
+			&i.CommitHash,
+			&i.Branch,
+			&i.Status,
+			&i.Logs,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Type,
+			&i.DeletedAt,
+			&i.Environment,
+			&i.ImageUri,
+			&i.ImageDigest,
+			&i.Title,
+			&i.Description,
+			&i.Labels,
+			&i.Pinned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetDeploymentsByUserIDAfter = `-- name: GetDeploymentsByUserIDAfter :many
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.user_id = $1 AND deployments.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments.status = $2)
+        AND ($3::varchar IS NULL OR deployments.branch = $3)
+        AND ($4::timestamp IS NULL OR deployments.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments.labels @> $5)
+        AND (deployments.created_at, deployments.id) < ($6::timestamp, $7::uuid)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.user_id = $1 AND deployments_archive.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments_archive.status = $2)
+        AND ($3::varchar IS NULL OR deployments_archive.branch = $3)
+        AND ($4::timestamptz IS NULL OR deployments_archive.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments_archive.labels @> $5)
+        AND (deployments_archive.created_at, deployments_archive.id) < ($6::timestamptz, $7::uuid)
+) AS history
+ORDER BY created_at DESC, id DESC
+LIMIT $8
+`
+
+type GetDeploymentsByUserIDAfterParams struct {
+	UserID         uuid.UUID      `json:"user_id"`
+	Status         sql.NullString `json:"status"`
+	Branch         sql.NullString `json:"branch"`
+	Since          sql.NullTime   `json:"since"`
+	Labels         []byte         `json:"labels"`
+	AfterCreatedAt time.Time      `json:"after_created_at"`
+	AfterID        uuid.UUID      `json:"after_id"`
+	PageSize       int32          `json:"page_size"`
+}
+
+type GetDeploymentsByUserIDAfterRow struct {
+	ID          uuid.UUID      `json:"id"`
+	ProjectID   uuid.UUID      `json:"project_id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	CommitHash  string         `json:"commit_hash"`
+	Branch      string         `json:"branch"`
+	Status      string         `json:"status"`
+	Logs        sql.NullString `json:"logs"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	Type        string         `json:"type"`
+	DeletedAt   sql.NullTime   `json:"deleted_at"`
+	Environment string         `json:"environment"`
+	ImageUri    string         `json:"image_uri"`
+	ImageDigest string         `json:"image_digest"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Labels      []byte         `json:"labels"`
+	Pinned      bool           `json:"pinned"`
+}
+
+func (q *Queries) GetDeploymentsByUserIDAfter(ctx context.Context, arg *GetDeploymentsByUserIDAfterParams) ([]*GetDeploymentsByUserIDAfterRow, error) {
+	rows, err := q.db.Query(ctx, GetDeploymentsByUserIDAfter,
+		arg.UserID,
+		arg.Status,
+		arg.Branch,
+		arg.Since,
+		arg.Labels,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetDeploymentsByUserIDAfterRow{}
+	for rows.Next() {
+		var i GetDeploymentsByUserIDAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.UserID,
+			&i.CommitHash,
+			&i.Branch,
+			&i.Status,
+			&i.Logs,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Type,
+			&i.DeletedAt,
+			&i.Environment,
+			&i.ImageUri,
+			&i.ImageDigest,
+			&i.Title,
+			&i.Description,
+			&i.Labels,
+			&i.Pinned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetDeploymentsByUserIDAfterAscending = `-- name: GetDeploymentsByUserIDAfterAscending :many
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.user_id = $1 AND deployments.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments.status = $2)
+        AND ($3::varchar IS NULL OR deployments.branch = $3)
+        AND ($4::timestamp IS NULL OR deployments.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments.labels @> $5)
+        AND (deployments.created_at, deployments.id) > ($6::timestamp, $7::uuid)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.user_id = $1 AND deployments_archive.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments_archive.status = $2)
+        AND ($3::varchar IS NULL OR deployments_archive.branch = $3)
+        AND ($4::timestamptz IS NULL OR deployments_archive.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments_archive.labels @> $5)
+        AND (deployments_archive.created_at, deployments_archive.id) > ($6::timestamptz, $7::uuid)
+) AS history
+ORDER BY created_at ASC, id ASC
+LIMIT $8
+`
+
+type GetDeploymentsByUserIDAfterAscendingParams struct {
+	UserID         uuid.UUID      `json:"user_id"`
+	Status         sql.NullString `json:"status"`
+	Branch         sql.NullString `json:"branch"`
+	Since          sql.NullTime   `json:"since"`
+	Labels         []byte         `json:"labels"`
+	AfterCreatedAt time.Time      `json:"after_created_at"`
+	AfterID        uuid.UUID      `json:"after_id"`
+	PageSize       int32          `json:"page_size"`
+}
+
+type GetDeploymentsByUserIDAfterAscendingRow struct {
+	ID          uuid.UUID      `json:"id"`
+	ProjectID   uuid.UUID      `json:"project_id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	CommitHash  string         `json:"commit_hash"`
+	Branch      string         `json:"branch"`
+	Status      string         `json:"status"`
+	Logs        sql.NullString `json:"logs"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	Type        string         `json:"type"`
+	DeletedAt   sql.NullTime   `json:"deleted_at"`
+	Environment string         `json:"environment"`
+	ImageUri    string         `json:"image_uri"`
+	ImageDigest string         `json:"image_digest"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Labels      []byte         `json:"labels"`
+	Pinned      bool           `json:"pinned"`
+}
+
+func (q *Queries) GetDeploymentsByUserIDAfterAscending(ctx context.Context, arg *GetDeploymentsByUserIDAfterAscendingParams) ([]*GetDeploymentsByUserIDAfterAscendingRow, error) {
+	rows, err := q.db.Query(ctx, GetDeploymentsByUserIDAfterAscending,
+		arg.UserID,
+		arg.Status,
+		arg.Branch,
+		arg.Since,
+		arg.Labels,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetDeploymentsByUserIDAfterAscendingRow{}
+	for rows.Next() {
+		var i GetDeploymentsByUserIDAfterAscendingRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.UserID,
+			&i.CommitHash,
+			&i.Branch,
+			&i.Status,
+			&i.Logs,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Type,
+			&i.DeletedAt,
+			&i.Environment,
+			&i.ImageUri,
+			&i.ImageDigest,
+			&i.Title,
+			&i.Description,
+			&i.Labels,
+			&i.Pinned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetDeploymentsByUserIDAscending = `-- name: GetDeploymentsByUserIDAscending :many
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.user_id = $1 AND deployments.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments.status = $2)
+        AND ($3::varchar IS NULL OR deployments.branch = $3)
+        AND ($4::timestamp IS NULL OR deployments.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments.labels @> $5)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.user_id = $1 AND deployments_archive.deleted_at IS NULL
+        AND ($2::varchar IS NULL OR deployments_archive.status = $2)
+        AND ($3::varchar IS NULL OR deployments_archive.branch = $3)
+        AND ($4::timestamptz IS NULL OR deployments_archive.created_at >= $4)
+        AND ($5::jsonb IS NULL OR deployments_archive.labels @> $5)
+) AS history
+ORDER BY created_at ASC, id ASC
+LIMIT $6 OFFSET $7
+`
+
+type GetDeploymentsByUserIDAscendingParams struct {
+	UserID     uuid.UUID      `json:"user_id"`
+	Status     sql.NullString `json:"status"`
+	Branch     sql.NullString `json:"branch"`
+	Since      sql.NullTime   `json:"since"`
+	Labels     []byte         `json:"labels"`
+	PageSize   int32          `json:"page_size"`
+	PageOffset int32          `json:"page_offset"`
+}
+
+type GetDeploymentsByUserIDAscendingRow struct {
+	ID          uuid.UUID      `json:"id"`
+	ProjectID   uuid.UUID      `json:"project_id"`
+	UserID      uuid.UUID      `json:"user_id"`
+	CommitHash  string         `json:"commit_hash"`
+	Branch      string         `json:"branch"`
+	Status      string         `json:"status"`
+	Logs        sql.NullString `json:"logs"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	Type        string         `json:"type"`
+	DeletedAt   sql.NullTime   `json:"deleted_at"`
+	Environment string         `json:"environment"`
+	ImageUri    string         `json:"image_uri"`
+	ImageDigest string         `json:"image_digest"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Labels      []byte         `json:"labels"`
+	Pinned      bool           `json:"pinned"`
+}
+
+func (q *Queries) GetDeploymentsByUserIDAscending(ctx context.Context, arg *GetDeploymentsByUserIDAscendingParams) ([]*GetDeploymentsByUserIDAscendingRow, error) {
+	rows, err := q.db.Query(ctx, GetDeploymentsByUserIDAscending,
+		arg.UserID,
+		arg.Status,
+		arg.Branch,
+		arg.Since,
+		arg.Labels,
+		arg.PageSize,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*GetDeploymentsByUserIDAscendingRow{}
+	for rows.Next() {
+		var i GetDeploymentsByUserIDAscendingRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.UserID,
+			&i.CommitHash,
+			&i.Branch,
+			&i.Status,
+			&i.Logs,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Type,
+			&i.DeletedAt,
+			&i.Environment,
+			&i.ImageUri,
+			&i.ImageDigest,
+			&i.Title,
+			&i.Description,
+			&i.Labels,
+			&i.Pinned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetLatestDeployedDeploymentInEnvironment = `-- name: GetLatestDeployedDeploymentInEnvironment :one
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments
+WHERE project_id = $1 AND environment = $2 AND status = 'DEPLOYED' AND deleted_at IS NULL
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+type GetLatestDeployedDeploymentInEnvironmentParams struct {
+	ProjectID   uuid.UUID `json:"project_id"`
+	Environment string    `json:"environment"`
+}
+
+func (q *Queries) GetLatestDeployedDeploymentInEnvironment(ctx context.Context, arg *GetLatestDeployedDeploymentInEnvironmentParams) (*Deployment, error) {
+	row := q.db.QueryRow(ctx, GetLatestDeployedDeploymentInEnvironment, arg.ProjectID, arg.Environment)
+	var i Deployment
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.CommitHash,
+		&i.Branch,
+		&i.Status,
+		&i.Logs,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Type,
+		&i.DeletedAt,
+		&i.Environment,
+		&i.ImageUri,
+		&i.ImageDigest,
+		&i.Title,
+		&i.Description,
+		&i.Labels,
+		&i.Pinned,
+	)
+	return &i, err
+}
+
+const GetLatestDeployedDeployments = `-- name: GetLatestDeployedDeployments :many
+SELECT DISTINCT ON (project_id, environment) id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments
+WHERE status = 'DEPLOYED' AND deleted_at IS NULL
+ORDER BY project_id, environment, created_at DESC
+`
+
+func (q *Queries) GetLatestDeployedDeployments(ctx context.Context) ([]*Deployment, error) {
+	rows, err := q.db.Query(ctx, GetLatestDeployedDeployments)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Deployment{}
+	for rows.Next() {
+		var i Deployment
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.UserID,
+			&i.CommitHash,
+			&i.Branch,
+			&i.Status,
+			&i.Logs,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Type,
+			&i.DeletedAt,
+			&i.Environment,
+			&i.ImageUri,
+			&i.ImageDigest,
+			&i.Title,
+			&i.Description,
+			&i.Labels,
+			&i.Pinned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetLatestDeploymentByProjectID = `-- name: GetLatestDeploymentByProjectID :one
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments
+WHERE project_id = $1 AND deleted_at IS NULL
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestDeploymentByProjectID(ctx context.Context, projectID uuid.UUID) (*Deployment, error) {
+	row := q.db.QueryRow(ctx, GetLatestDeploymentByProjectID, projectID)
+	var i Deployment
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.CommitHash,
+		&i.Branch,
+		&i.Status,
+		&i.Logs,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Type,
+		&i.DeletedAt,
+		&i.Environment,
+		&i.ImageUri,
+		&i.ImageDigest,
+		&i.Title,
+		&i.Description,
+		&i.Labels,
+		&i.Pinned,
+	)
+	return &i, err
+}
+
+const GetLatestDeploymentInEnvironment = `-- name: GetLatestDeploymentInEnvironment :one
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments
+WHERE project_id = $1 AND environment = $2 AND deleted_at IS NULL
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+type GetLatestDeploymentInEnvironmentParams struct {
+	ProjectID   uuid.UUID `json:"project_id"`
+	Environment string    `json:"environment"`
+}
+
+func (q *Queries) GetLatestDeploymentInEnvironment(ctx context.Context, arg *GetLatestDeploymentInEnvironmentParams) (*Deployment, error) {
+	row := q.db.QueryRow(ctx, GetLatestDeploymentInEnvironment, arg.ProjectID, arg.Environment)
+	var i Deployment
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.CommitHash,
+		&i.Branch,
+		&i.Status,
+		&i.Logs,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Type,
+		&i.DeletedAt,
+		&i.Environment,
+		&i.ImageUri,
+		&i.ImageDigest,
+		&i.Title,
+		&i.Description,
+		&i.Labels,
+		&i.Pinned,
+	)
+	return &i, err
+}
+
+const GetPinnedDeploymentsByProjectID = `-- name: GetPinnedDeploymentsByProjectID :many
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments
+WHERE project_id = $1 AND pinned AND deleted_at IS NULL
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetPinnedDeploymentsByProjectID(ctx context.Context, projectID uuid.UUID) ([]*Deployment, error) {
+	rows, err := q.db.Query(ctx, GetPinnedDeploymentsByProjectID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Deployment{}
+	for rows.Next() {
+		var i Deployment
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.UserID,
+			&i.CommitHash,
+			&i.Branch,
+			&i.Status,
+			&i.Logs,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Type,
+			&i.DeletedAt,
+			&i.Environment,
+			&i.ImageUri,
+			&i.ImageDigest,
+			&i.Title,
+			&i.Description,
+			&i.Labels,
+			&i.Pinned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetStuckDeployments = `-- name: GetStuckDeployments :many
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments
+WHERE status IN ('PENDING', 'BUILDING', 'DEPLOYING', 'INTERRUPTED')
+  AND updated_at < $1
+  AND deleted_at IS NULL
+ORDER BY updated_at
+LIMIT $2
+`
+
+type GetStuckDeploymentsParams struct {
+	UpdatedAt sql.NullTime `json:"updated_at"`
+	Limit     int32        `json:"limit"`
+}
+
+func (q *Queries) GetStuckDeployments(ctx context.Context, arg *GetStuckDeploymentsParams) ([]*Deployment, error) {
+	rows, err := q.db.Query(ctx, GetStuckDeployments, arg.UpdatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Deployment{}
+	for rows.Next() {
+		var i Deployment
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.UserID,
+			&i.CommitHash,
+			&i.Branch,
+			&i.Status,
+			&i.Logs,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Type,
+			&i.DeletedAt,
+			&i.Environment,
+			&i.ImageUri,
+			&i.ImageDigest,
+			&i.Title,
+			&i.Description,
+			&i.Labels,
+			&i.Pinned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const PurgeDeletedDeployments = `-- name: PurgeDeletedDeployments :one
+WITH purged AS (
+    DELETE FROM deployments
+    WHERE deployments.id IN (
+        SELECT d.id FROM deployments d
+        WHERE d.deleted_at < $1
+        ORDER BY d.deleted_at
+        LIMIT $2
+    )
+    RETURNING deployments.id
+), purged_archive AS (
+    DELETE FROM deployments_archive
+    WHERE deployments_archive.id IN (
+        SELECT a.id FROM deployments_archive a
+        WHERE a.deleted_at < $1
+        ORDER BY a.deleted_at
+        LIMIT $2
+    )
+    RETURNING deployments_archive.id
+), timeline AS (
+    DELETE FROM deployment_events
+    WHERE deployment_events.deployment_id IN (SELECT id FROM purged UNION ALL SELECT id FROM purged_archive)
+), approvals AS (
+    DELETE FROM deployment_approvals
+    WHERE deployment_approvals.deployment_id IN (SELECT id FROM purged UNION ALL SELECT id FROM purged_archive)
+), manifests AS (
+    DELETE FROM deployment_manifests
+    WHERE deployment_manifests.deployment_id IN (SELECT id FROM purged UNION ALL SELECT id FROM purged_archive)
+), steps AS (
+    DELETE FROM deployment_steps
+    WHERE deployment_steps.deployment_id IN (SELECT id FROM purged UNION ALL SELECT id FROM purged_archive)
+)
+SELECT (
+    (SELECT COUNT(*) FROM purged) + (SELECT COUNT(*) FROM purged_archive)
+)::bigint AS count
+`
+
+type PurgeDeletedDeploymentsParams struct {
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	Limit     int32        `json:"limit"`
+}
+
+func (q *Queries) PurgeDeletedDeployments(ctx context.Context, arg *PurgeDeletedDeploymentsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, PurgeDeletedDeployments, arg.DeletedAt, arg.Limit)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const SoftDeleteDeployment = `-- name: SoftDeleteDeployment :exec
+WITH archived AS (
+    UPDATE deployments_archive SET deleted_at = $2
+    WHERE deployments_archive.id = $1 AND deployments_archive.deleted_at IS NULL
+), cancelled_build AS (
+    DELETE FROM build_jobs WHERE build_jobs.deployment_id = $1 AND build_jobs.status = 'PENDING'
+)
+UPDATE deployments
+SET deleted_at = $2
+WHERE deployments.id = $1 AND deployments.deleted_at IS NULL
+`
+
+type SoftDeleteDeploymentParams struct {
+	ID        uuid.UUID    `json:"id"`
+	DeletedAt sql.NullTime `json:"deleted_at"`
+}
+
+func (q *Queries) SoftDeleteDeployment(ctx context.Context, arg *SoftDeleteDeploymentParams) error {
+	_, err := q.db.Exec(ctx, SoftDeleteDeployment, arg.ID, arg.DeletedAt)
+	return err
+}
+
+const UpdateDeployment = `-- name: UpdateDeployment :exec
+UPDATE deployments
+SET
+    status = $2,
+    logs = $3,
+    updated_at = $4,
+    image_uri = $5,
+    image_digest = $6,
+    title = $7,
+    description = $8,
+    labels = $9,
+    pinned = $10
+WHERE id = $1
+`
+
+type UpdateDeploymentParams struct {
+	ID          uuid.UUID      `json:"id"`
+	Status      string         `json:"status"`
+	Logs        sql.NullString `json:"logs"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	ImageUri    string         `json:"image_uri"`
+	ImageDigest string         `json:"image_digest"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Labels      []byte         `json:"labels"`
+	Pinned      bool           `json:"pinned"`
+}
+
+func (q *Queries) UpdateDeployment(ctx context.Context, arg *UpdateDeploymentParams) error {
+	_, err := q.db.Exec(ctx, UpdateDeployment,
+		arg.ID,
+		arg.Status,
+		arg.Logs,
+		arg.UpdatedAt,
+		arg.ImageUri,
+		arg.ImageDigest,
+		arg.Title,
+		arg.Description,
+		arg.Labels,
+		arg.Pinned,
+	)
+	return err
+}
+
+const UpdateDeploymentLogs = `-- name: UpdateDeploymentLogs :execrows
+UPDATE deployments
+SET
+    logs = $2,
+    updated_at = $3
+WHERE id = $1 AND (updated_at IS NULL OR updated_at < $3)
+`
+
+type UpdateDeploymentLogsParams struct {
+	ID        uuid.UUID      `json:"id"`
+	Logs      sql.NullString `json:"logs"`
+	UpdatedAt sql.NullTime   `json:"updated_at"`
+}
+
+func (q *Queries) UpdateDeploymentLogs(ctx context.Context, arg *UpdateDeploymentLogsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, UpdateDeploymentLogs, arg.ID, arg.Logs, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
@@ -11,6 +11,132 @@ import (
 	"github.com/google/uuid"
 )
 
+// Credentials of users' self-hosted build agents, which only run the builds of their user's projects
+type AgentToken struct {
+	ID uuid.UUID `json:"id"`
+	// User whose builds agents presenting the token run
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+	// SHA-256 of the token, which is only shown when it is created
+	TokenHash string    `json:"token_hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Stripe customers of users, their subscription and how much of their usage was reported
+type BillingAccount struct {
+	UserID           uuid.UUID `json:"user_id"`
+	StripeCustomerID string    `json:"stripe_customer_id"`
+	// Latest subscription of the customer, NULL before they subscribe
+	StripeSubscriptionID sql.NullString `json:"stripe_subscription_id"`
+	// Quota plan of the subscription's price, NULL for the default plan
+	Plan sql.NullString `json:"plan"`
+	// SUSPENDED while a payment failed: services are stopped and no builds start
+	Status      string       `json:"status"`
+	SuspendedAt sql.NullTime `json:"suspended_at"`
+	// Build minutes of periods ending up to this time were reported to Stripe
+	BuildMinutesReportedUntil time.Time `json:"build_minutes_reported_until"`
+	// Fraction of a build minute not reported yet
+	BuildMinutesCarry float64 `json:"build_minutes_carry"`
+	// Fargate vCPU hours of periods ending up to this time were reported to Stripe
+	VcpuHoursReportedUntil time.Time `json:"vcpu_hours_reported_until"`
+	// Fraction of a Fargate vCPU hour not reported yet
+	VcpuHoursCarry float64   `json:"vcpu_hours_carry"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Builds waiting to be started, claimed by build workers so a restart never loses a deployment
+type BuildJob struct {
+	DeploymentID uuid.UUID `json:"deployment_id"`
+	ProjectID    uuid.UUID `json:"project_id"`
+	// Job status (PENDING, RUNNING, DONE, FAILED); DONE once the build has been started
+	Status string `json:"status"`
+	// Number of times a worker has claimed the job
+	Attempts  int32  `json:"attempts"`
+	LastError string `json:"last_error"`
+	// When a worker last claimed the job; RUNNING jobs with an expired claim are claimed again
+	ClaimedAt sql.NullTime `json:"claimed_at"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	// Correlation ID of the request that queued the build
+	CorrelationID string `json:"correlation_id"`
+	// W3C traceparent of the request that queued the build
+	TraceParent string `json:"trace_parent"`
+}
+
+// One-off commands run in the containers of projects, the audit trail of who ran what
+type CommandRun struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	// Deployment whose image and environment variables the command ran with (no foreign key so runs survive archiving)
+	DeploymentID uuid.UUID `json:"deployment_id"`
+	// User who ran the command
+	UserID      uuid.UUID `json:"user_id"`
+	Environment string    `json:"environment"`
+	Command     string    `json:"command"`
+	Status      string    `json:"status"`
+	// Exit code of the command, NULL while running or if it never started
+	ExitCode sql.NullInt32 `json:"exit_code"`
+	// Why the command failed
+	Error string `json:"error"`
+	// Last lines the command wrote, with secrets masked
+	Output     string       `json:"output"`
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt sql.NullTime `json:"finished_at"`
+}
+
+// Scheduled task runs of CRON projects
+type CronRun struct {
+	ID           uuid.UUID `json:"id"`
+	ProjectID    uuid.UUID `json:"project_id"`
+	DeploymentID uuid.UUID `json:"deployment_id"`
+	// ARN of the ECS task the schedule started
+	TaskArn string `json:"task_arn"`
+	Status  string `json:"status"`
+	// Exit code of the container, NULL while running or if it never started
+	ExitCode sql.NullInt32 `json:"exit_code"`
+	// Why ECS stopped the task, if it failed
+	Reason    string       `json:"reason"`
+	StartedAt time.Time    `json:"started_at"`
+	StoppedAt sql.NullTime `json:"stopped_at"`
+}
+
+// Ephemeral databases restored from snapshots for preview environments, dropped once they expire
+type DatabaseBranch struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	// Snapshot the branch was restored from, NULL once the snapshot is deleted
+	SnapshotID uuid.NullUUID `json:"snapshot_id"`
+	// Name of the branch within its project, e.g. pr-42
+	Name         string    `json:"name"`
+	DatabaseName string    `json:"database_name"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Frozen copies of project databases, dropped once they expire
+type DatabaseSnapshot struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	// Postgres database holding the copy, which refuses connections
+	DatabaseName string    `json:"database_name"`
+	SizeBytes    int64     `json:"size_bytes"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Accounts of projects on shared datastore servers, each only granted access to its project's database
+type DatastoreCredential struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	// Datastore the account is on, such as MYSQL
+	Datastore string `json:"datastore"`
+	// Name of the account on the datastore server
+	Username string `json:"username"`
+	// Password of the account, encrypted with the platform encryption key
+	PasswordEncrypted string    `json:"password_encrypted"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
 type Deployment struct {
 	ID         uuid.UUID      `json:"id"`
 	ProjectID  uuid.UUID      `json:"project_id"`
@@ -21,6 +147,203 @@ type Deployment struct {
 	Logs       sql.NullString `json:"logs"`
 	CreatedAt  sql.NullTime   `json:"created_at"`
 	UpdatedAt  sql.NullTime   `json:"updated_at"`
+	// Kind of deployment (BUILD, RESTART)
+	Type string `json:"type"`
+	// When the deployment was deleted, NULL while it exists; only operators can read deleted deployments
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	// Environment of the project the deployment runs in
+	Environment string `json:"environment"`
+	// Image the deployment runs, empty until it starts running one
+	ImageUri string `json:"image_uri"`
+	// Digest of the image the deployment runs, empty if the registry did not report one
+	ImageDigest string `json:"image_digest"`
+	// Short description of the deployment, such as the release it ships
+	Title string `json:"title"`
+	// Release notes of the deployment
+	Description string `json:"description"`
+	// Arbitrary key/value labels deployments can be listed by
+	Labels []byte `json:"labels"`
+	// Whether the deployment is kept: it cannot be deleted, archived or have its image cleaned up
+	Pinned bool `json:"pinned"`
+}
+
+// Audit records of the approval or rejection of deployments to protected environments
+type DeploymentApproval struct {
+	ID uuid.UUID `json:"id"`
+	// Deployment decided on (no foreign key so records survive archiving)
+	DeploymentID uuid.UUID `json:"deployment_id"`
+	ProjectID    uuid.UUID `json:"project_id"`
+	UserID       uuid.UUID `json:"user_id"`
+	Decision     string    `json:"decision"`
+	Comment      string    `json:"comment"`
+	DecidedAt    time.Time `json:"decided_at"`
+}
+
+// Platform timeline of deployments (phase changes and migration results)
+type DeploymentEvent struct {
+	ID uuid.UUID `json:"id"`
+	// Deployment the event belongs to (no foreign key so events survive archiving)
+	DeploymentID uuid.UUID `json:"deployment_id"`
+	ProjectID    uuid.UUID `json:"project_id"`
+	Source       string    `json:"source"`
+	Type         string    `json:"type"`
+	Message      string    `json:"message"`
+	OccurredAt   time.Time `json:"occurred_at"`
+}
+
+// Image, environment variables and settings each deployment was built with, for comparing deployments
+type DeploymentManifest struct {
+	// Deployment the manifest belongs to (no foreign key so manifests survive archiving)
+	DeploymentID uuid.UUID `json:"deployment_id"`
+	ProjectID    uuid.UUID `json:"project_id"`
+	ImageTag     string    `json:"image_tag"`
+	// Keyed digest of each environment variable value by name, values themselves are never stored
+	EnvVars []byte `json:"env_vars"`
+	// Project settings the deployment was built with, by name
+	Config    []byte    `json:"config"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Vulnerabilities and SBOM of the image of each deployment, found after its build and before it is deployed
+type DeploymentScan struct {
+	// Deployment whose image was scanned (no foreign key so scans survive archiving)
+	DeploymentID uuid.UUID `json:"deployment_id"`
+	ProjectID    uuid.UUID `json:"project_id"`
+	ImageUri     string    `json:"image_uri"`
+	// COMPLETED, or FAILED when the vulnerabilities could not be read and the deployment went ahead without them
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	// Number of vulnerabilities found by severity, e.g. {"CRITICAL": 1, "HIGH": 4}
+	SeverityCounts []byte `json:"severity_counts"`
+	// Most severe vulnerabilities found, with their package and version
+	Vulnerabilities []byte `json:"vulnerabilities"`
+	SbomFormat      string `json:"sbom_format"`
+	SbomPackages    int32  `json:"sbom_packages"`
+	// SBOM document generated by syft, NULL if none could be generated
+	Sbom []byte `json:"sbom"`
+	// Whether the deployment was stopped for having too many critical vulnerabilities
+	Blocked   bool      `json:"blocked"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Outcome of each pipeline step of a deployment, used to retry failed deployments from the step that failed
+type DeploymentStep struct {
+	// Deployment the step belongs to (no foreign key so steps survive archiving)
+	DeploymentID uuid.UUID `json:"deployment_id"`
+	ProjectID    uuid.UUID `json:"project_id"`
+	Step         string    `json:"step"`
+	// RUNNING, SUCCEEDED, FAILED, or SKIPPED when a retried deployment carried the result of an earlier attempt over
+	Status    string    `json:"status"`
+	Error     string    `json:"error"`
+	StartedAt time.Time `json:"started_at"`
+	// When the step finished, NULL while it is running
+	FinishedAt sql.NullTime `json:"finished_at"`
+}
+
+// Finished deployments older than DEPLOYMENT_ARCHIVE_AFTER_MONTHS, read only by history queries
+type DeploymentsArchive struct {
+	ID         uuid.UUID      `json:"id"`
+	ProjectID  uuid.UUID      `json:"project_id"`
+	UserID     uuid.UUID      `json:"user_id"`
+	CommitHash string         `json:"commit_hash"`
+	Branch     string         `json:"branch"`
+	Status     string         `json:"status"`
+	Logs       sql.NullString `json:"logs"`
+	CreatedAt  sql.NullTime   `json:"created_at"`
+	UpdatedAt  sql.NullTime   `json:"updated_at"`
+	Type       string         `json:"type"`
+	// When the deployment was deleted, NULL while it exists
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	// Environment of the project the deployment runs in
+	Environment string `json:"environment"`
+	// Image the deployment runs, empty until it starts running one
+	ImageUri string `json:"image_uri"`
+	// Digest of the image the deployment runs, empty if the registry did not report one
+	ImageDigest string `json:"image_digest"`
+	// Short description of the deployment, such as the release it ships
+	Title string `json:"title"`
+	// Release notes of the deployment
+	Description string `json:"description"`
+	// Arbitrary key/value labels deployments can be listed by
+	Labels []byte `json:"labels"`
+	// Always false, pinned deployments are never archived
+	Pinned bool `json:"pinned"`
+}
+
+// Users designated to approve or reject deployments to a project's protected environment
+type EnvironmentApprover struct {
+	ProjectID   uuid.UUID `json:"project_id"`
+	Environment string    `json:"environment"`
+	// Approver, who may not approve the deployments they started
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Latest change to the environment variables of each project environment, pending until a deployment picks it up
+type EnvironmentVariableChange struct {
+	ProjectID   uuid.UUID `json:"project_id"`
+	Environment string    `json:"environment"`
+	ChangedAt   time.Time `json:"changed_at"`
+	// First deployment started after the change that succeeded, NULL while the change is not deployed (no foreign key so it survives archiving)
+	AppliedDeploymentID uuid.NullUUID `json:"applied_deployment_id"`
+	// When the deployment that picked the change up succeeded
+	AppliedAt sql.NullTime `json:"applied_at"`
+}
+
+// GitHub App installations used to sync and clone repositories without user tokens
+type GithubInstallation struct {
+	// GitHub installation ID
+	ID int64 `json:"id"`
+	// SnapDeploy user who claimed the installation, NULL until claimed
+	UserID       uuid.NullUUID `json:"user_id"`
+	AccountID    int64         `json:"account_id"`
+	AccountLogin string        `json:"account_login"`
+	AccountType  string        `json:"account_type"`
+	// GitHub user ID of who installed the app, 0 until the installation webhook arrives
+	InstalledByID int64     `json:"installed_by_id"`
+	Suspended     bool      `json:"suspended"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Health checks of deployed projects, probed from the control plane
+type HealthCheck struct {
+	ID        int64     `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Url       string    `json:"url"`
+	Healthy   bool      `json:"healthy"`
+	// HTTP status the health endpoint answered with, 0 if it did not answer
+	StatusCode int32 `json:"status_code"`
+	LatencyMs  int32 `json:"latency_ms"`
+	// Why the check failed, empty for healthy checks
+	Error     string    `json:"error"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Responses to requests made with an Idempotency-Key, replayed when the client retries
+type IdempotencyKey struct {
+	// Clerk user ID of the caller; keys are scoped per user
+	OwnerID        string `json:"owner_id"`
+	IdempotencyKey string `json:"idempotency_key"`
+	Route          string `json:"route"`
+	// SHA-256 of the request method, path and body; a retry must match it
+	RequestHash string `json:"request_hash"`
+	// Response status, NULL while the original request is in flight
+	StatusCode   sql.NullInt32 `json:"status_code"`
+	ResponseBody []byte        `json:"response_body"`
+	CreatedAt    time.Time     `json:"created_at"`
+	ExpiresAt    time.Time     `json:"expires_at"`
+}
+
+// Priorities allocated to the listener rules of services, so concurrent deployments never pick the same one and deleted rules free theirs
+type ListenerRulePriority struct {
+	// Listener the rule is on
+	ListenerArn string `json:"listener_arn"`
+	// Priority of the rule, unique on its listener
+	Priority int32 `json:"priority"`
+	// Service the rule routes to, with at most one rule per listener
+	ServiceName string    `json:"service_name"`
+	AllocatedAt time.Time `json:"allocated_at"`
 }
 
 type Project struct {
@@ -39,6 +362,60 @@ type Project struct {
 	RequireDb bool `json:"require_db"`
 	// Optional command to run database migrations (e.g., "npm run migrate")
 	MigrationCommand sql.NullString `json:"migration_command"`
+	// Lifecycle status of the project (ACTIVE, DELETING, DELETE_FAILED)
+	Status string `json:"status"`
+	// Human readable progress or error message for the current status
+	StatusMessage sql.NullString `json:"status_message"`
+	// When the project was deleted, NULL while it exists; only operators can read deleted projects
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	// Number of recent deployments whose images are kept by image cleanup, 0 for the platform default
+	ImageRetention int32 `json:"image_retention"`
+	// How new versions replace running ones (ROLLING, BLUE_GREEN, RECREATE)
+	DeploymentStrategy string `json:"deployment_strategy"`
+	// Percentage of traffic routed to the canary of a CANARY deployment
+	CanaryPercent int32 `json:"canary_percent"`
+	// Minutes the canary of a CANARY deployment is watched before it is promoted
+	CanaryBakeMinutes int32 `json:"canary_bake_minutes"`
+	// Datastores provisioned besides the Postgres database (REDIS, MYSQL)
+	Datastores []string `json:"datastores"`
+	// Where the persistent volume is mounted in the container, empty for none
+	VolumeMountPath string `json:"volume_mount_path"`
+	// Size in GB the persistent volume is expected to stay within
+	VolumeSizeGb int32 `json:"volume_size_gb"`
+	// How the project runs (WEB serves traffic through the load balancer, WORKER runs in the background, CRON runs on a schedule, STATIC is served from S3 through CloudFront)
+	ProjectType string `json:"project_type"`
+	// EventBridge schedule expression a CRON project runs on, empty for other types
+	Schedule string `json:"schedule"`
+	// Processes run besides the main one, as a JSON array of {name, type, command, port, replicas, schedule}
+	Services []byte `json:"services"`
+	// Environments deployed to besides production, e.g. staging
+	Environments []string `json:"environments"`
+	// Environments whose deployments wait for approval before they are built
+	ProtectedEnvironments []string `json:"protected_environments"`
+	// Port the container listens on, unless a PORT environment variable overrides it
+	ContainerPort int32 `json:"container_port"`
+	// Path the load balancer requests to check the container is healthy
+	HealthCheckPath string `json:"health_check_path"`
+	// CPU units of the project's tasks (1024 is one vCPU)
+	Cpu int32 `json:"cpu"`
+	// Memory in MiB of the project's tasks
+	Memory int32 `json:"memory"`
+	// Directory a STATIC project's build writes its files to, empty for other types
+	OutputDirectory string `json:"output_directory"`
+	// Compute the project runs on (ECS or LAMBDA)
+	DeploymentTarget string `json:"deployment_target"`
+	// How requests reach a LAMBDA project (API_GATEWAY or FUNCTION_URL), empty for ECS projects
+	LambdaEndpoint string `json:"lambda_endpoint"`
+	// Branch rules pushes are deployed by, as a JSON array of {branch, environment} where the first rule matching the pushed branch wins
+	DeployRules []byte `json:"deploy_rules"`
+	// Whether anyone may read the status page of the project, found by its custom domain
+	PublicStatusPage bool `json:"public_status_page"`
+	// CPU and memory of the project's builds (SMALL, MEDIUM or LARGE)
+	BuildSize string `json:"build_size"`
+	// How long a build of the project may run before it is stopped and the deployment fails
+	BuildTimeoutMinutes int32 `json:"build_timeout_minutes"`
+	// Whether changing an environment variable redeploys the last successful image of its environment
+	RedeployOnEnvChange bool `json:"redeploy_on_env_change"`
 }
 
 // Stores encrypted environment variables for projects
@@ -50,12 +427,49 @@ type ProjectEnvironmentVariable struct {
 	Value     string    `json:"value"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// Where the variable is passed (BUILD as a Docker build arg, RUNTIME to the containers, BOTH to either)
+	Scope string `json:"scope"`
+	// Environment of the project the variable is passed to
+	Environment string `json:"environment"`
+}
+
+// Periods a project's health checks kept failing
+type ProjectIncident struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	// Why the last failed check of the incident failed
+	Cause        string    `json:"cause"`
+	FailedChecks int32     `json:"failed_checks"`
+	StartedAt    time.Time `json:"started_at"`
+	// When a check succeeded again, NULL while the incident is open
+	ResolvedAt sql.NullTime `json:"resolved_at"`
+}
+
+// Curated sample apps users can create a project from
+type ProjectTemplate struct {
+	// Slug identifying the template in the API, such as nextjs-starter
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Sample repository projects deploy, or copy when they are created with a repository of their own
+	RepositoryUrl    string `json:"repository_url"`
+	Language         string `json:"language"`
+	Type             string `json:"type"`
+	InstallCommand   string `json:"install_command"`
+	BuildCommand     string `json:"build_command"`
+	RunCommand       string `json:"run_command"`
+	RequireDb        bool   `json:"require_db"`
+	MigrationCommand string `json:"migration_command"`
+	// Environment variables pre-filled on created projects: key, value, description, scope and whether a value is required
+	EnvVars []byte `json:"env_vars"`
+	// Order of the template in the catalog, lowest first
+	Position  int32     `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Repository struct {
 	ID              uuid.UUID      `json:"id"`
 	UserID          uuid.UUID      `json:"user_id"`
-	GithubID        int64          `json:"github_id"`
 	Name            string         `json:"name"`
 	FullName        string         `json:"full_name"`
 	Description     sql.NullString `json:"description"`
@@ -70,6 +484,78 @@ type Repository struct {
 	Language        sql.NullString `json:"language"`
 	CreatedAt       sql.NullTime   `json:"created_at"`
 	UpdatedAt       sql.NullTime   `json:"updated_at"`
+	// Git hosting service: GITHUB, GITLAB or BITBUCKET
+	Provider string `json:"provider"`
+	// Repository ID at the provider
+	ExternalID string `json:"external_id"`
+}
+
+// Last sync of each user's repositories from each Git provider, so the next one fetches only what changed
+type RepositorySync struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Provider string    `json:"provider"`
+	// Validator of the provider's list of repositories at the last sync
+	Etag     string    `json:"etag"`
+	SyncedAt time.Time `json:"synced_at"`
+	// When the last sync fetching every repository started
+	FullSyncedAt time.Time `json:"full_synced_at"`
+}
+
+// Interactive shells opened in the running tasks of projects, the audit trail of who opened them
+type ShellSession struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	// User who opened the shell
+	UserID      uuid.UUID `json:"user_id"`
+	Environment string    `json:"environment"`
+	// SHA-256 of the one-time token the user's terminal connects with
+	TokenHash string `json:"token_hash"`
+	Status    string `json:"status"`
+	// ECS task the shell ran in
+	TaskArn string `json:"task_arn"`
+	// Why the session ended, empty until it has
+	EndReason string `json:"end_reason"`
+	Error     string `json:"error"`
+	// Bytes typed into the shell
+	BytesIn int64 `json:"bytes_in"`
+	// Bytes the shell wrote
+	BytesOut  int64     `json:"bytes_out"`
+	CreatedAt time.Time `json:"created_at"`
+	// When the user's terminal connected, NULL if it never did
+	StartedAt sql.NullTime `json:"started_at"`
+	EndedAt   sql.NullTime `json:"ended_at"`
+}
+
+// Platform incidents and maintenance notices shown on the status page
+type SystemIncident struct {
+	ID       uuid.UUID `json:"id"`
+	Kind     string    `json:"kind"`
+	Severity string    `json:"severity"`
+	Title    string    `json:"title"`
+	Message  string    `json:"message"`
+	StartsAt time.Time `json:"starts_at"`
+	// Scheduled end of a maintenance window (NULL if open-ended)
+	EndsAt     sql.NullTime `json:"ends_at"`
+	ResolvedAt sql.NullTime `json:"resolved_at"`
+	// Clerk user ID of the operator who posted the notice
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Metered compute and build usage, kept after project deletion for billing
+type UsageRecord struct {
+	ID uuid.UUID `json:"id"`
+	// Project the usage belongs to (no foreign key so usage survives project deletion)
+	ProjectID    uuid.UUID     `json:"project_id"`
+	UserID       uuid.UUID     `json:"user_id"`
+	DeploymentID uuid.NullUUID `json:"deployment_id"`
+	Metric       string        `json:"metric"`
+	// Amount of the metric consumed between period_start and period_end
+	Quantity    float64   `json:"quantity"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type User struct {
@@ -80,3 +566,19 @@ type User struct {
 	CreatedAt   sql.NullTime `json:"created_at"`
 	UpdatedAt   sql.NullTime `json:"updated_at"`
 }
+
+// Plan and limit overrides of users; users without a row are on the default plan
+type UserQuota struct {
+	UserID uuid.UUID `json:"user_id"`
+	// Name of a plan configured with QUOTA_PLANS, NULL for the default plan
+	Plan sql.NullString `json:"plan"`
+	// Overrides the plan's project limit, NULL keeps it and 0 is unlimited
+	MaxProjects sql.NullInt32 `json:"max_projects"`
+	// Overrides the plan's limit of deployments in progress at once, NULL keeps it and 0 is unlimited
+	MaxConcurrentBuilds sql.NullInt32 `json:"max_concurrent_builds"`
+	// Overrides the plan's limit of environment variables per project environment, NULL keeps it and 0 is unlimited
+	MaxEnvVars sql.NullInt32 `json:"max_env_vars"`
+	// Overrides the plan's monthly build minutes, NULL keeps it and 0 is unlimited
+	BuildMinutesPerMonth sql.NullInt32 `json:"build_minutes_per_month"`
+	UpdatedAt            time.Time     `json:"updated_at"`
+}
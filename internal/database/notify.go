@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Notify sends a payload to every connection listening on a channel, on any server instance
+func (db *DB) Notify(ctx context.Context, channel, payload string) error {
+	if _, err := db.pool.Exec(ctx, "SELECT pg_notify($1, $2)", channel, payload); err != nil {
+		return fmt.Errorf("failed to notify %s: %w", channel, err)
+	}
+	return nil
+}
+
+// Listen passes the payloads sent to a channel to handle until ctx is done or the connection fails.
+// It takes a connection out of the pool for as long as it listens.
+func (db *DB) Listen(ctx context.Context, channel string, handle func(payload string)) error {
+	pooled, err := db.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	// A listening connection must never be handed to queries again
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", channel, err)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		handle(notification.Payload)
+	}
+}
@@ -13,35 +13,84 @@ import (
 
 const CountProjectEnvVars = `-- name: CountProjectEnvVars :one
 SELECT COUNT(*) FROM project_environment_variables
-WHERE project_id = $1
+WHERE project_id = $1 AND environment = $2
 `
 
-func (q *Queries) CountProjectEnvVars(ctx context.Context, projectID uuid.UUID) (int64, error) {
-	row := q.db.QueryRowContext(ctx, CountProjectEnvVars, projectID)
+type CountProjectEnvVarsParams struct {
+	ProjectID   uuid.UUID `json:"project_id"`
+	Environment string    `json:"environment"`
+}
+
+func (q *Queries) CountProjectEnvVars(ctx context.Context, arg *CountProjectEnvVarsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, CountProjectEnvVars, arg.ProjectID, arg.Environment)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
+const CountProjectEnvVarsByKeyID = `-- name: CountProjectEnvVarsByKeyID :many
+SELECT (CASE WHEN strpos(value, ':') = 0 THEN 'default' ELSE split_part(value, ':', 1) END)::text AS key_id,
+    COUNT(*) AS count
+FROM project_environment_variables
+WHERE value <> ''
+GROUP BY 1
+ORDER BY 1
+`
+
+type CountProjectEnvVarsByKeyIDRow struct {
+	KeyID string `json:"key_id"`
+	Count int64  `json:"count"`
+}
+
+func (q *Queries) CountProjectEnvVarsByKeyID(ctx context.Context) ([]*CountProjectEnvVarsByKeyIDRow, error) {
+	rows, err := q.db.Query(ctx, CountProjectEnvVarsByKeyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*CountProjectEnvVarsByKeyIDRow{}
+	for rows.Next() {
+		var i CountProjectEnvVarsByKeyIDRow
+		if err := rows.Scan(&i.KeyID, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const CreateProjectEnvVar = `-- name: CreateProjectEnvVar :one
 INSERT INTO project_environment_variables (
     project_id,
     key,
-    value
+    value,
+    scope,
+    environment
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5
 )
-RETURNING id, project_id, key, value, created_at, updated_at
+RETURNING id, project_id, key, value, created_at, updated_at, scope, environment
 `
 
 type CreateProjectEnvVarParams struct {
-	ProjectID uuid.UUID `json:"project_id"`
-	Key       string    `json:"key"`
-	Value     string    `json:"value"`
+	ProjectID   uuid.UUID `json:"project_id"`
+	Key         string    `json:"key"`
+	Value       string    `json:"value"`
+	Scope       string    `json:"scope"`
+	Environment string    `json:"environment"`
 }
 
 func (q *Queries) CreateProjectEnvVar(ctx context.Context, arg *CreateProjectEnvVarParams) (*ProjectEnvironmentVariable, error) {
-	row := q.db.QueryRowContext(ctx, CreateProjectEnvVar, arg.ProjectID, arg.Key, arg.Value)
+	row := q.db.QueryRow(ctx, CreateProjectEnvVar,
+		arg.ProjectID,
+		arg.Key,
+		arg.Value,
+		arg.Scope,
+		arg.Environment,
+	)
 	var i ProjectEnvironmentVariable
 	err := row.Scan(
 		&i.ID,
@@ -50,6 +99,8 @@ func (q *Queries) CreateProjectEnvVar(ctx context.Context, arg *CreateProjectEnv
 		&i.Value,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Scope,
+		&i.Environment,
 	)
 	return &i, err
 }
@@ -60,37 +111,54 @@ WHERE project_id = $1
 `
 
 func (q *Queries) DeleteAllProjectEnvVars(ctx context.Context, projectID uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, DeleteAllProjectEnvVars, projectID)
+	_, err := q.db.Exec(ctx, DeleteAllProjectEnvVars, projectID)
 	return err
 }
 
 const DeleteProjectEnvVar = `-- name: DeleteProjectEnvVar :exec
 DELETE FROM project_environment_variables
-WHERE project_id = $1 AND key = $2
+WHERE project_id = $1 AND key = $2 AND environment = $3
 `
 
 type DeleteProjectEnvVarParams struct {
-	ProjectID uuid.UUID `json:"project_id"`
-	Key       string    `json:"key"`
+	ProjectID   uuid.UUID `json:"project_id"`
+	Key         string    `json:"key"`
+	Environment string    `json:"environment"`
 }
 
 func (q *Queries) DeleteProjectEnvVar(ctx context.Context, arg *DeleteProjectEnvVarParams) error {
-	_, err := q.db.ExecContext(ctx, DeleteProjectEnvVar, arg.ProjectID, arg.Key)
+	_, err := q.db.Exec(ctx, DeleteProjectEnvVar, arg.ProjectID, arg.Key, arg.Environment)
+	return err
+}
+
+const DeleteProjectEnvironmentEnvVars = `-- name: DeleteProjectEnvironmentEnvVars :exec
+DELETE FROM project_environment_variables
+WHERE project_id = $1 AND environment = $2
+`
+
+type DeleteProjectEnvironmentEnvVarsParams struct {
+	ProjectID   uuid.UUID `json:"project_id"`
+	Environment string    `json:"environment"`
+}
+
+func (q *Queries) DeleteProjectEnvironmentEnvVars(ctx context.Context, arg *DeleteProjectEnvironmentEnvVarsParams) error {
+	_, err := q.db.Exec(ctx, DeleteProjectEnvironmentEnvVars, arg.ProjectID, arg.Environment)
 	return err
 }
 
 const GetProjectEnvVar = `-- name: GetProjectEnvVar :one
-SELECT id, project_id, key, value, created_at, updated_at FROM project_environment_variables
-WHERE project_id = $1 AND key = $2
+SELECT id, project_id, key, value, created_at, updated_at, scope, environment FROM project_environment_variables
+WHERE project_id = $1 AND key = $2 AND environment = $3
 `
 
 type GetProjectEnvVarParams struct {
-	ProjectID uuid.UUID `json:"project_id"`
-	Key       string    `json:"key"`
+	ProjectID   uuid.UUID `json:"project_id"`
+	Key         string    `json:"key"`
+	Environment string    `json:"environment"`
 }
 
 func (q *Queries) GetProjectEnvVar(ctx context.Context, arg *GetProjectEnvVarParams) (*ProjectEnvironmentVariable, error) {
-	row := q.db.QueryRowContext(ctx, GetProjectEnvVar, arg.ProjectID, arg.Key)
+	row := q.db.QueryRow(ctx, GetProjectEnvVar, arg.ProjectID, arg.Key, arg.Environment)
 	var i ProjectEnvironmentVariable
 	err := row.Scan(
 		&i.ID,
@@ -99,18 +167,25 @@ func (q *Queries) GetProjectEnvVar(ctx context.Context, arg *GetProjectEnvVarPar
 		&i.Value,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Scope,
+		&i.Environment,
 	)
 	return &i, err
 }
 
 const GetProjectEnvVars = `-- name: GetProjectEnvVars :many
-SELECT id, project_id, key, value, created_at, updated_at FROM project_environment_variables
-WHERE project_id = $1
+SELECT id, project_id, key, value, created_at, updated_at, scope, environment FROM project_environment_variables
+WHERE project_id = $1 AND environment = $2
 ORDER BY key ASC
 `
 
-func (q *Queries) GetProjectEnvVars(ctx context.Context, projectID uuid.UUID) ([]*ProjectEnvironmentVariable, error) {
-	rows, err := q.db.QueryContext(ctx, GetProjectEnvVars, projectID)
+type GetProjectEnvVarsParams struct {
+	ProjectID   uuid.UUID `json:"project_id"`
+	Environment string    `json:"environment"`
+}
+
+func (q *Queries) GetProjectEnvVars(ctx context.Context, arg *GetProjectEnvVarsParams) ([]*ProjectEnvironmentVariable, error) {
+	rows, err := q.db.Query(ctx, GetProjectEnvVars, arg.ProjectID, arg.Environment)
 	if err != nil {
 		return nil, err
 	}
@@ -125,37 +200,105 @@ func (q *Queries) GetProjectEnvVars(ctx context.Context, projectID uuid.UUID) ([
 			&i.Value,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Scope,
+			&i.Environment,
 		); err != nil {
 			return nil, err
 		}
 		items = append(items, &i)
 	}
-	if err := rows.Close(); err != nil {
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListProjectEnvVarsToReencrypt = `-- name: ListProjectEnvVarsToReencrypt :many
+SELECT id, value FROM project_environment_variables
+WHERE value <> ''
+  AND id > $1::uuid
+  AND (CASE WHEN strpos(value, ':') = 0 THEN 'default' ELSE split_part(value, ':', 1) END) <> $2::text
+ORDER BY id
+LIMIT $3
+`
+
+type ListProjectEnvVarsToReencryptParams struct {
+	AfterID   uuid.UUID `json:"after_id"`
+	KeyID     string    `json:"key_id"`
+	BatchSize int32     `json:"batch_size"`
+}
+
+type ListProjectEnvVarsToReencryptRow struct {
+	ID    uuid.UUID `json:"id"`
+	Value string    `json:"value"`
+}
+
+func (q *Queries) ListProjectEnvVarsToReencrypt(ctx context.Context, arg *ListProjectEnvVarsToReencryptParams) ([]*ListProjectEnvVarsToReencryptRow, error) {
+	rows, err := q.db.Query(ctx, ListProjectEnvVarsToReencrypt, arg.AfterID, arg.KeyID, arg.BatchSize)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+	items := []*ListProjectEnvVarsToReencryptRow{}
+	for rows.Next() {
+		var i ListProjectEnvVarsToReencryptRow
+		if err := rows.Scan(&i.ID, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
+const ReencryptProjectEnvVar = `-- name: ReencryptProjectEnvVar :execrows
+UPDATE project_environment_variables
+SET value = $1
+WHERE id = $2 AND value = $3
+`
+
+type ReencryptProjectEnvVarParams struct {
+	Value         string    `json:"value"`
+	ID            uuid.UUID `json:"id"`
+	PreviousValue string    `json:"previous_value"`
+}
+
+func (q *Queries) ReencryptProjectEnvVar(ctx context.Context, arg *ReencryptProjectEnvVarParams) (int64, error) {
+	result, err := q.db.Exec(ctx, ReencryptProjectEnvVar, arg.Value, arg.ID, arg.PreviousValue)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const UpdateProjectEnvVar = `-- name: UpdateProjectEnvVar :one
 UPDATE project_environment_variables
 SET 
     value = $3,
+    scope = $4,
     updated_at = CURRENT_TIMESTAMP
-WHERE project_id = $1 AND key = $2
-RETURNING id, project_id, key, value, created_at, updated_at
+WHERE project_id = $1 AND key = $2 AND environment = $5
+RETURNING id, project_id, key, value, created_at, updated_at, scope, environment
 `
 
 type UpdateProjectEnvVarParams struct {
-	ProjectID uuid.UUID `json:"project_id"`
-	Key       string    `json:"key"`
-	Value     string    `json:"value"`
+	ProjectID   uuid.UUID `json:"project_id"`
+	Key         string    `json:"key"`
+	Value       string    `json:"value"`
+	Scope       string    `json:"scope"`
+	Environment string    `json:"environment"`
 }
 
 func (q *Queries) UpdateProjectEnvVar(ctx context.Context, arg *UpdateProjectEnvVarParams) (*ProjectEnvironmentVariable, error) {
-	row := q.db.QueryRowContext(ctx, UpdateProjectEnvVar, arg.ProjectID, arg.Key, arg.Value)
+	row := q.db.QueryRow(ctx, UpdateProjectEnvVar,
+		arg.ProjectID,
+		arg.Key,
+		arg.Value,
+		arg.Scope,
+		arg.Environment,
+	)
 	var i ProjectEnvironmentVariable
 	err := row.Scan(
 		&i.ID,
@@ -164,6 +307,8 @@ func (q *Queries) UpdateProjectEnvVar(ctx context.Context, arg *UpdateProjectEnv
 		&i.Value,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Scope,
+		&i.Environment,
 	)
 	return &i, err
 }
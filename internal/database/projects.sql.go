@@ -12,13 +12,144 @@ import (
 	"github.com/google/uuid"
 )
 
+const BackfillProjectCustomDomain = `-- name: BackfillProjectCustomDomain :one
+UPDATE projects
+SET custom_domain = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND custom_domain = ''
+RETURNING id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command, status, status_message, deleted_at, image_retention, deployment_strategy, canary_percent, canary_bake_minutes, datastores, volume_mount_path, volume_size_gb, project_type, schedule, services, environments, protected_environments, container_port, health_check_path, cpu, memory, output_directory, deployment_target, lambda_endpoint, deploy_rules, public_status_page, build_size, build_timeout_minutes, redeploy_on_env_change
+`
+
+type BackfillProjectCustomDomainParams struct {
+	ID           uuid.UUID `json:"id"`
+	CustomDomain string    `json:"custom_domain"`
+}
+
+func (q *Queries) BackfillProjectCustomDomain(ctx context.Context, arg *BackfillProjectCustomDomainParams) (*Project, error) {
+	row := q.db.QueryRow(ctx, BackfillProjectCustomDomain, arg.ID, arg.CustomDomain)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RepositoryUrl,
+		&i.BuildCommand,
+		&i.RunCommand,
+		&i.Language,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.InstallCommand,
+		&i.CustomDomain,
+		&i.RequireDb,
+		&i.MigrationCommand,
+		&i.Status,
+		&i.StatusMessage,
+		&i.DeletedAt,
+		&i.ImageRetention,
+		&i.DeploymentStrategy,
+		&i.CanaryPercent,
+		&i.CanaryBakeMinutes,
+		&i.Datastores,
+		&i.VolumeMountPath,
+		&i.VolumeSizeGb,
+		&i.ProjectType,
+		&i.Schedule,
+		&i.Services,
+		&i.Environments,
+		&i.ProtectedEnvironments,
+		&i.ContainerPort,
+		&i.HealthCheckPath,
+		&i.Cpu,
+		&i.Memory,
+		&i.OutputDirectory,
+		&i.DeploymentTarget,
+		&i.LambdaEndpoint,
+		&i.DeployRules,
+		&i.PublicStatusPage,
+		&i.BuildSize,
+		&i.BuildTimeoutMinutes,
+		&i.RedeployOnEnvChange,
+	)
+	return &i, err
+}
+
+const ClaimStalledProjectTeardown = `-- name: ClaimStalledProjectTeardown :one
+UPDATE projects
+SET updated_at = CURRENT_TIMESTAMP
+WHERE id = (
+    SELECT candidate.id FROM projects candidate
+    WHERE candidate.status = 'DELETING' AND candidate.deleted_at IS NULL AND candidate.updated_at < $1
+    ORDER BY candidate.updated_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command, status, status_message, deleted_at, image_retention, deployment_strategy, canary_percent, canary_bake_minutes, datastores, volume_mount_path, volume_size_gb, project_type, schedule, services, environments, protected_environments, container_port, health_check_path, cpu, memory, output_directory, deployment_target, lambda_endpoint, deploy_rules, public_status_page, build_size, build_timeout_minutes, redeploy_on_env_change
+`
+
+func (q *Queries) ClaimStalledProjectTeardown(ctx context.Context, updatedAt sql.NullTime) (*Project, error) {
+	row := q.db.QueryRow(ctx, ClaimStalledProjectTeardown, updatedAt)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RepositoryUrl,
+		&i.BuildCommand,
+		&i.RunCommand,
+		&i.Language,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.InstallCommand,
+		&i.CustomDomain,
+		&i.RequireDb,
+		&i.MigrationCommand,
+		&i.Status,
+		&i.StatusMessage,
+		&i.DeletedAt,
+		&i.ImageRetention,
+		&i.DeploymentStrategy,
+		&i.CanaryPercent,
+		&i.CanaryBakeMinutes,
+		&i.Datastores,
+		&i.VolumeMountPath,
+		&i.VolumeSizeGb,
+		&i.ProjectType,
+		&i.Schedule,
+		&i.Services,
+		&i.Environments,
+		&i.ProtectedEnvironments,
+		&i.ContainerPort,
+		&i.HealthCheckPath,
+		&i.Cpu,
+		&i.Memory,
+		&i.OutputDirectory,
+		&i.DeploymentTarget,
+		&i.LambdaEndpoint,
+		&i.DeployRules,
+		&i.PublicStatusPage,
+		&i.BuildSize,
+		&i.BuildTimeoutMinutes,
+		&i.RedeployOnEnvChange,
+	)
+	return &i, err
+}
+
 const CountProjectsByUserID = `-- name: CountProjectsByUserID :one
 SELECT COUNT(*) FROM projects
-WHERE user_id = $1
+WHERE user_id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) CountProjectsByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
-	row := q.db.QueryRowContext(ctx, CountProjectsByUserID, userID)
+	row := q.db.QueryRow(ctx, CountProjectsByUserID, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountProjectsByUserIDIncludingDeleted = `-- name: CountProjectsByUserIDIncludingDeleted :one
+SELECT COUNT(*) FROM projects
+WHERE user_id = $1
+`
+
+func (q *Queries) CountProjectsByUserIDIncludingDeleted(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, CountProjectsByUserIDIncludingDeleted, userID)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -34,27 +165,75 @@ INSERT INTO projects (
     language,
     custom_domain,
     require_db,
-    migration_command
+    migration_command,
+    image_retention,
+    deployment_strategy,
+    canary_percent,
+    canary_bake_minutes,
+    datastores,
+    volume_mount_path,
+    volume_size_gb,
+    project_type,
+    schedule,
+    services,
+    environments,
+    protected_environments,
+    container_port,
+    health_check_path,
+    cpu,
+    memory,
+    output_directory,
+    deployment_target,
+    lambda_endpoint,
+    deploy_rules,
+    public_status_page,
+    build_size,
+    build_timeout_minutes,
+    redeploy_on_env_change
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33
 )
-RETURNING id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command
+RETURNING id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command, status, status_message, deleted_at, image_retention, deployment_strategy, canary_percent, canary_bake_minutes, datastores, volume_mount_path, volume_size_gb, project_type, schedule, services, environments, protected_environments, container_port, health_check_path, cpu, memory, output_directory, deployment_target, lambda_endpoint, deploy_rules, public_status_page, build_size, build_timeout_minutes, redeploy_on_env_change
 `
 
 type CreateProjectParams struct {
-	UserID           uuid.UUID      `json:"user_id"`
-	RepositoryUrl    string         `json:"repository_url"`
-	InstallCommand   string         `json:"install_command"`
-	BuildCommand     sql.NullString `json:"build_command"`
-	RunCommand       string         `json:"run_command"`
-	Language         string         `json:"language"`
-	CustomDomain     string         `json:"custom_domain"`
-	RequireDb        bool           `json:"require_db"`
-	MigrationCommand sql.NullString `json:"migration_command"`
+	UserID                uuid.UUID      `json:"user_id"`
+	RepositoryUrl         string         `json:"repository_url"`
+	InstallCommand        string         `json:"install_command"`
+	BuildCommand          sql.NullString `json:"build_command"`
+	RunCommand            string         `json:"run_command"`
+	Language              string         `json:"language"`
+	CustomDomain          string         `json:"custom_domain"`
+	RequireDb             bool           `json:"require_db"`
+	MigrationCommand      sql.NullString `json:"migration_command"`
+	ImageRetention        int32          `json:"image_retention"`
+	DeploymentStrategy    string         `json:"deployment_strategy"`
+	CanaryPercent         int32          `json:"canary_percent"`
+	CanaryBakeMinutes     int32          `json:"canary_bake_minutes"`
+	Datastores            []string       `json:"datastores"`
+	VolumeMountPath       string         `json:"volume_mount_path"`
+	VolumeSizeGb          int32          `json:"volume_size_gb"`
+	ProjectType           string         `json:"project_type"`
+	Schedule              string         `json:"schedule"`
+	Services              []byte         `json:"services"`
+	Environments          []string       `json:"environments"`
+	ProtectedEnvironments []string       `json:"protected_environments"`
+	ContainerPort         int32          `json:"container_port"`
+	HealthCheckPath       string         `json:"health_check_path"`
+	Cpu                   int32          `json:"cpu"`
+	Memory                int32          `json:"memory"`
+	OutputDirectory       string         `json:"output_directory"`
+	DeploymentTarget      string         `json:"deployment_target"`
+	LambdaEndpoint        string         `json:"lambda_endpoint"`
+	DeployRules           []byte         `json:"deploy_rules"`
+	PublicStatusPage      bool           `json:"public_status_page"`
+	BuildSize             string         `json:"build_size"`
+	BuildTimeoutMinutes   int32          `json:"build_timeout_minutes"`
+	RedeployOnEnvChange   bool           `json:"redeploy_on_env_change"`
 }
 
 func (q *Queries) CreateProject(ctx context.Context, arg *CreateProjectParams) (*Project, error) {
-	row := q.db.QueryRowContext(ctx, CreateProject,
+	row := q.db.QueryRow(ctx, CreateProject,
 		arg.UserID,
 		arg.RepositoryUrl,
 		arg.InstallCommand,
@@ -64,6 +243,30 @@ func (q *Queries) CreateProject(ctx context.Context, arg *CreateProjectParams) (
 		arg.CustomDomain,
 		arg.RequireDb,
 		arg.MigrationCommand,
+		arg.ImageRetention,
+		arg.DeploymentStrategy,
+		arg.CanaryPercent,
+		arg.CanaryBakeMinutes,
+		arg.Datastores,
+		arg.VolumeMountPath,
+		arg.VolumeSizeGb,
+		arg.ProjectType,
+		arg.Schedule,
+		arg.Services,
+		arg.Environments,
+		arg.ProtectedEnvironments,
+		arg.ContainerPort,
+		arg.HealthCheckPath,
+		arg.Cpu,
+		arg.Memory,
+		arg.OutputDirectory,
+		arg.DeploymentTarget,
+		arg.LambdaEndpoint,
+		arg.DeployRules,
+		arg.PublicStatusPage,
+		arg.BuildSize,
+		arg.BuildTimeoutMinutes,
+		arg.RedeployOnEnvChange,
 	)
 	var i Project
 	err := row.Scan(
@@ -79,29 +282,46 @@ func (q *Queries) CreateProject(ctx context.Context, arg *CreateProjectParams) (
 		&i.CustomDomain,
 		&i.RequireDb,
 		&i.MigrationCommand,
+		&i.Status,
+		&i.StatusMessage,
+		&i.DeletedAt,
+		&i.ImageRetention,
+		&i.DeploymentStrategy,
+		&i.CanaryPercent,
+		&i.CanaryBakeMinutes,
+		&i.Datastores,
+		&i.VolumeMountPath,
+		&i.VolumeSizeGb,
+		&i.ProjectType,
+		&i.Schedule,
+		&i.Services,
+		&i.Environments,
+		&i.ProtectedEnvironments,
+		&i.ContainerPort,
+		&i.HealthCheckPath,
+		&i.Cpu,
+		&i.Memory,
+		&i.OutputDirectory,
+		&i.DeploymentTarget,
+		&i.LambdaEndpoint,
+		&i.DeployRules,
+		&i.PublicStatusPage,
+		&i.BuildSize,
+		&i.BuildTimeoutMinutes,
+		&i.RedeployOnEnvChange,
 	)
 	return &i, err
 }
 
-const DeleteProject = `-- name: DeleteProject :exec
-DELETE FROM projects
-WHERE id = $1
-`
-
-func (q *Queries) DeleteProject(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, DeleteProject, id)
-	return err
-}
-
 const ExistsProjectByCustomDomain = `-- name: ExistsProjectByCustomDomain :one
 SELECT EXISTS(
     SELECT 1 FROM projects
-    WHERE custom_domain = $1 AND custom_domain != ''
+    WHERE custom_domain = $1 AND custom_domain != '' AND deleted_at IS NULL
 )
 `
 
 func (q *Queries) ExistsProjectByCustomDomain(ctx context.Context, customDomain string) (bool, error) {
-	row := q.db.QueryRowContext(ctx, ExistsProjectByCustomDomain, customDomain)
+	row := q.db.QueryRow(ctx, ExistsProjectByCustomDomain, customDomain)
 	var exists bool
 	err := row.Scan(&exists)
 	return exists, err
@@ -110,7 +330,7 @@ func (q *Queries) ExistsProjectByCustomDomain(ctx context.Context, customDomain
 const ExistsProjectByRepositoryURL = `-- name: ExistsProjectByRepositoryURL :one
 SELECT EXISTS(
     SELECT 1 FROM projects
-    WHERE user_id = $1 AND repository_url = $2
+    WHERE user_id = $1 AND repository_url = $2 AND deleted_at IS NULL
 )
 `
 
@@ -120,19 +340,19 @@ type ExistsProjectByRepositoryURLParams struct {
 }
 
 func (q *Queries) ExistsProjectByRepositoryURL(ctx context.Context, arg *ExistsProjectByRepositoryURLParams) (bool, error) {
-	row := q.db.QueryRowContext(ctx, ExistsProjectByRepositoryURL, arg.UserID, arg.RepositoryUrl)
+	row := q.db.QueryRow(ctx, ExistsProjectByRepositoryURL, arg.UserID, arg.RepositoryUrl)
 	var exists bool
 	err := row.Scan(&exists)
 	return exists, err
 }
 
 const GetProjectByCustomDomain = `-- name: GetProjectByCustomDomain :one
-SELECT id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command FROM projects
-WHERE custom_domain = $1 AND custom_domain != ''
+SELECT id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command, status, status_message, deleted_at, image_retention, deployment_strategy, canary_percent, canary_bake_minutes, datastores, volume_mount_path, volume_size_gb, project_type, schedule, services, environments, protected_environments, container_port, health_check_path, cpu, memory, output_directory, deployment_target, lambda_endpoint, deploy_rules, public_status_page, build_size, build_timeout_minutes, redeploy_on_env_change FROM projects
+WHERE custom_domain = $1 AND custom_domain != '' AND deleted_at IS NULL
 `
 
 func (q *Queries) GetProjectByCustomDomain(ctx context.Context, customDomain string) (*Project, error) {
-	row := q.db.QueryRowContext(ctx, GetProjectByCustomDomain, customDomain)
+	row := q.db.QueryRow(ctx, GetProjectByCustomDomain, customDomain)
 	var i Project
 	err := row.Scan(
 		&i.ID,
@@ -147,17 +367,44 @@ func (q *Queries) GetProjectByCustomDomain(ctx context.Context, customDomain str
 		&i.CustomDomain,
 		&i.RequireDb,
 		&i.MigrationCommand,
+		&i.Status,
+		&i.StatusMessage,
+		&i.DeletedAt,
+		&i.ImageRetention,
+		&i.DeploymentStrategy,
+		&i.CanaryPercent,
+		&i.CanaryBakeMinutes,
+		&i.Datastores,
+		&i.VolumeMountPath,
+		&i.VolumeSizeGb,
+		&i.ProjectType,
+		&i.Schedule,
+		&i.Services,
+		&i.Environments,
+		&i.ProtectedEnvironments,
+		&i.ContainerPort,
+		&i.HealthCheckPath,
+		&i.Cpu,
+		&i.Memory,
+		&i.OutputDirectory,
+		&i.DeploymentTarget,
+		&i.LambdaEndpoint,
+		&i.DeployRules,
+		&i.PublicStatusPage,
+		&i.BuildSize,
+		&i.BuildTimeoutMinutes,
+		&i.RedeployOnEnvChange,
 	)
 	return &i, err
 }
 
 const GetProjectByID = `-- name: GetProjectByID :one
-SELECT id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command FROM projects
-WHERE id = $1
+SELECT id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command, status, status_message, deleted_at, image_retention, deployment_strategy, canary_percent, canary_bake_minutes, datastores, volume_mount_path, volume_size_gb, project_type, schedule, services, environments, protected_environments, container_port, health_check_path, cpu, memory, output_directory, deployment_target, lambda_endpoint, deploy_rules, public_status_page, build_size, build_timeout_minutes, redeploy_on_env_change FROM projects
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetProjectByID(ctx context.Context, id uuid.UUID) (*Project, error) {
-	row := q.db.QueryRowContext(ctx, GetProjectByID, id)
+	row := q.db.QueryRow(ctx, GetProjectByID, id)
 	var i Project
 	err := row.Scan(
 		&i.ID,
@@ -172,13 +419,94 @@ func (q *Queries) GetProjectByID(ctx context.Context, id uuid.UUID) (*Project, e
 		&i.CustomDomain,
 		&i.RequireDb,
 		&i.MigrationCommand,
+		&i.Status,
+		&i.StatusMessage,
+		&i.DeletedAt,
+		&i.ImageRetention,
+		&i.DeploymentStrategy,
+		&i.CanaryPercent,
+		&i.CanaryBakeMinutes,
+		&i.Datastores,
+		&i.VolumeMountPath,
+		&i.VolumeSizeGb,
+		&i.ProjectType,
+		&i.Schedule,
+		&i.Services,
+		&i.Environments,
+		&i.ProtectedEnvironments,
+		&i.ContainerPort,
+		&i.HealthCheckPath,
+		&i.Cpu,
+		&i.Memory,
+		&i.OutputDirectory,
+		&i.DeploymentTarget,
+		&i.LambdaEndpoint,
+		&i.DeployRules,
+		&i.PublicStatusPage,
+		&i.BuildSize,
+		&i.BuildTimeoutMinutes,
+		&i.RedeployOnEnvChange,
+	)
+	return &i, err
+}
+
+const GetProjectByIDIncludingDeleted = `-- name: GetProjectByIDIncludingDeleted :one
+SELECT id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command, status, status_message, deleted_at, image_retention, deployment_strategy, canary_percent, canary_bake_minutes, datastores, volume_mount_path, volume_size_gb, project_type, schedule, services, environments, protected_environments, container_port, health_check_path, cpu, memory, output_directory, deployment_target, lambda_endpoint, deploy_rules, public_status_page, build_size, build_timeout_minutes, redeploy_on_env_change FROM projects
+WHERE id = $1
+`
+
+func (q *Queries) GetProjectByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (*Project, error) {
+	row := q.db.QueryRow(ctx, GetProjectByIDIncludingDeleted, id)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RepositoryUrl,
+		&i.BuildCommand,
+		&i.RunCommand,
+		&i.Language,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.InstallCommand,
+		&i.CustomDomain,
+		&i.RequireDb,
+		&i.MigrationCommand,
+		&i.Status,
+		&i.StatusMessage,
+		&i.DeletedAt,
+		&i.ImageRetention,
+		&i.DeploymentStrategy,
+		&i.CanaryPercent,
+		&i.CanaryBakeMinutes,
+		&i.Datastores,
+		&i.VolumeMountPath,
+		&i.VolumeSizeGb,
+		&i.ProjectType,
+		&i.Schedule,
+		&i.Services,
+		&i.Environments,
+		&i.ProtectedEnvironments,
+		&i.ContainerPort,
+		&i.HealthCheckPath,
+		&i.Cpu,
+		&i.Memory,
+		&i.OutputDirectory,
+		&i.DeploymentTarget,
+		&i.LambdaEndpoint,
+		&i.DeployRules,
+		&i.PublicStatusPage,
+		&i.BuildSize,
+		&i.BuildTimeoutMinutes,
+		&i.RedeployOnEnvChange,
 	)
 	return &i, err
 }
 
 const GetProjectByRepositoryURL = `-- name: GetProjectByRepositoryURL :one
-SELECT id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command FROM projects
-WHERE user_id = $1 AND repository_url = $2
+SELECT id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command, status, status_message, deleted_at, image_retention, deployment_strategy, canary_percent, canary_bake_minutes, datastores, volume_mount_path, volume_size_gb, project_type, schedule, services, environments, protected_environments, container_port, health_check_path, cpu, memory, output_directory, deployment_target, lambda_endpoint, deploy_rules, public_status_page, build_size, build_timeout_minutes, redeploy_on_env_change FROM projects
+WHERE user_id = $1 AND repository_url = $2 AND deleted_at IS NULL
+ORDER BY created_at, id
+LIMIT 1
 `
 
 type GetProjectByRepositoryURLParams struct {
@@ -187,7 +515,7 @@ type GetProjectByRepositoryURLParams struct {
 }
 
 func (q *Queries) GetProjectByRepositoryURL(ctx context.Context, arg *GetProjectByRepositoryURLParams) (*Project, error) {
-	row := q.db.QueryRowContext(ctx, GetProjectByRepositoryURL, arg.UserID, arg.RepositoryUrl)
+	row := q.db.QueryRow(ctx, GetProjectByRepositoryURL, arg.UserID, arg.RepositoryUrl)
 	var i Project
 	err := row.Scan(
 		&i.ID,
@@ -202,13 +530,40 @@ func (q *Queries) GetProjectByRepositoryURL(ctx context.Context, arg *GetProject
 		&i.CustomDomain,
 		&i.RequireDb,
 		&i.MigrationCommand,
+		&i.Status,
+		&i.StatusMessage,
+		&i.DeletedAt,
+		&i.ImageRetention,
+		&i.DeploymentStrategy,
+		&i.CanaryPercent,
+		&i.CanaryBakeMinutes,
+		&i.Datastores,
+		&i.VolumeMountPath,
+		&i.VolumeSizeGb,
+		&i.ProjectType,
+		&i.Schedule,
+		&i.Services,
+		&i.Environments,
+		&i.ProtectedEnvironments,
+		&i.ContainerPort,
+		&i.HealthCheckPath,
+		&i.Cpu,
+		&i.Memory,
+		&i.OutputDirectory,
+		&i.DeploymentTarget,
+		&i.LambdaEndpoint,
+		&i.DeployRules,
+		&i.PublicStatusPage,
+		&i.BuildSize,
+		&i.BuildTimeoutMinutes,
+		&i.RedeployOnEnvChange,
 	)
 	return &i, err
 }
 
 const GetProjectsByUserID = `-- name: GetProjectsByUserID :many
-SELECT id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command FROM projects
-WHERE user_id = $1
+SELECT id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command, status, status_message, deleted_at, image_retention, deployment_strategy, canary_percent, canary_bake_minutes, datastores, volume_mount_path, volume_size_gb, project_type, schedule, services, environments, protected_environments, container_port, health_check_path, cpu, memory, output_directory, deployment_target, lambda_endpoint, deploy_rules, public_status_page, build_size, build_timeout_minutes, redeploy_on_env_change FROM projects
+WHERE user_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
 `
@@ -220,7 +575,7 @@ type GetProjectsByUserIDParams struct {
 }
 
 func (q *Queries) GetProjectsByUserID(ctx context.Context, arg *GetProjectsByUserIDParams) ([]*Project, error) {
-	rows, err := q.db.QueryContext(ctx, GetProjectsByUserID, arg.UserID, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, GetProjectsByUserID, arg.UserID, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -241,20 +596,435 @@ func (q *Queries) GetProjectsByUserID(ctx context.Context, arg *GetProjectsByUse
 			&i.CustomDomain,
 			&i.RequireDb,
 			&i.MigrationCommand,
+			&i.Status,
+			&i.StatusMessage,
+			&i.DeletedAt,
+			&i.ImageRetention,
+			&i.DeploymentStrategy,
+			&i.CanaryPercent,
+			&i.CanaryBakeMinutes,
+			&i.Datastores,
+			&i.VolumeMountPath,
+			&i.VolumeSizeGb,
+			&i.ProjectType,
+			&i.Schedule,
+			&i.Services,
+			&i.Environments,
+			&i.ProtectedEnvironments,
+			&i.ContainerPort,
+			&i.HealthCheckPath,
+			&i.Cpu,
+			&i.Memory,
+			&i.OutputDirectory,
+			&i.DeploymentTarget,
+			&i.LambdaEndpoint,
+			&i.DeployRules,
+			&i.PublicStatusPage,
+			&i.BuildSize,
+			&i.BuildTimeoutMinutes,
+			&i.RedeployOnEnvChange,
 		); err != nil {
 			return nil, err
 		}
 		items = append(items, &i)
 	}
-	if err := rows.Close(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	return items, nil
+}
+
+const GetProjectsByUserIDIncludingDeleted = `-- name: GetProjectsByUserIDIncludingDeleted :many
+SELECT id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command, status, status_message, deleted_at, image_retention, deployment_strategy, canary_percent, canary_bake_minutes, datastores, volume_mount_path, volume_size_gb, project_type, schedule, services, environments, protected_environments, container_port, health_check_path, cpu, memory, output_directory, deployment_target, lambda_endpoint, deploy_rules, public_status_page, build_size, build_timeout_minutes, redeploy_on_env_change FROM projects
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type GetProjectsByUserIDIncludingDeletedParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Limit  int32     `json:"limit"`
+	Offset int32     `json:"offset"`
+}
+
+func (q *Queries) GetProjectsByUserIDIncludingDeleted(ctx context.Context, arg *GetProjectsByUserIDIncludingDeletedParams) ([]*Project, error) {
+	rows, err := q.db.Query(ctx, GetProjectsByUserIDIncludingDeleted, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Project{}
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.RepositoryUrl,
+			&i.BuildCommand,
+			&i.RunCommand,
+			&i.Language,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.InstallCommand,
+			&i.CustomDomain,
+			&i.RequireDb,
+			&i.MigrationCommand,
+			&i.Status,
+			&i.StatusMessage,
+			&i.DeletedAt,
+			&i.ImageRetention,
+			&i.DeploymentStrategy,
+			&i.CanaryPercent,
+			&i.CanaryBakeMinutes,
+			&i.Datastores,
+			&i.VolumeMountPath,
+			&i.VolumeSizeGb,
+			&i.ProjectType,
+			&i.Schedule,
+			&i.Services,
+			&i.Environments,
+			&i.ProtectedEnvironments,
+			&i.ContainerPort,
+			&i.HealthCheckPath,
+			&i.Cpu,
+			&i.Memory,
+			&i.OutputDirectory,
+			&i.DeploymentTarget,
+			&i.LambdaEndpoint,
+			&i.DeployRules,
+			&i.PublicStatusPage,
+			&i.BuildSize,
+			&i.BuildTimeoutMinutes,
+			&i.RedeployOnEnvChange,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
+const ListProjects = `-- name: ListProjects :many
+SELECT id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command, status, status_message, deleted_at, image_retention, deployment_strategy, canary_percent, canary_bake_minutes, datastores, volume_mount_path, volume_size_gb, project_type, schedule, services, environments, protected_environments, container_port, health_check_path, cpu, memory, output_directory, deployment_target, lambda_endpoint, deploy_rules, public_status_page, build_size, build_timeout_minutes, redeploy_on_env_change FROM projects
+WHERE deleted_at IS NULL
+ORDER BY created_at, id
+LIMIT $1 OFFSET $2
+`
+
+type ListProjectsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+const ListProjectsByCustomDomains = `-- name: ListProjectsByCustomDomains :many
+SELECT id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command, status, status_message, deleted_at, image_retention, deployment_strategy, canary_percent, canary_bake_minutes, datastores, volume_mount_path, volume_size_gb, project_type, schedule, services, environments, protected_environments, container_port, health_check_path, cpu, memory, output_directory, deployment_target, lambda_endpoint, deploy_rules, public_status_page, build_size, build_timeout_minutes, redeploy_on_env_change FROM projects
+WHERE custom_domain = ANY($1::text[]) AND custom_domain != '' AND deleted_at IS NULL
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListProjectsByCustomDomains(ctx context.Context, customDomains []string) ([]*Project, error) {
+	rows, err := q.db.Query(ctx, ListProjectsByCustomDomains, customDomains)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Project{}
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.RepositoryUrl,
+			&i.BuildCommand,
+			&i.RunCommand,
+			&i.Language,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.InstallCommand,
+			&i.CustomDomain,
+			&i.RequireDb,
+			&i.MigrationCommand,
+			&i.Status,
+			&i.StatusMessage,
+			&i.DeletedAt,
+			&i.ImageRetention,
+			&i.DeploymentStrategy,
+			&i.CanaryPercent,
+			&i.CanaryBakeMinutes,
+			&i.Datastores,
+			&i.VolumeMountPath,
+			&i.VolumeSizeGb,
+			&i.ProjectType,
+			&i.Schedule,
+			&i.Services,
+			&i.Environments,
+			&i.ProtectedEnvironments,
+			&i.ContainerPort,
+			&i.HealthCheckPath,
+			&i.Cpu,
+			&i.Memory,
+			&i.OutputDirectory,
+			&i.DeploymentTarget,
+			&i.LambdaEndpoint,
+			&i.DeployRules,
+			&i.PublicStatusPage,
+			&i.BuildSize,
+			&i.BuildTimeoutMinutes,
+			&i.RedeployOnEnvChange,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListProjectsByRepositoryURL = `-- name: ListProjectsByRepositoryURL :many
+SELECT id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command, status, status_message, deleted_at, image_retention, deployment_strategy, canary_percent, canary_bake_minutes, datastores, volume_mount_path, volume_size_gb, project_type, schedule, services, environments, protected_environments, container_port, health_check_path, cpu, memory, output_directory, deployment_target, lambda_endpoint, deploy_rules, public_status_page, build_size, build_timeout_minutes, redeploy_on_env_change FROM projects
+WHERE repository_url IN ($1::text, $1::text || '.git') AND deleted_at IS NULL
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListProjectsByRepositoryURL(ctx context.Context, repositoryUrl string) ([]*Project, error) {
+	rows, err := q.db.Query(ctx, ListProjectsByRepositoryURL, repositoryUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Project{}
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.RepositoryUrl,
+			&i.BuildCommand,
+			&i.RunCommand,
+			&i.Language,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.InstallCommand,
+			&i.CustomDomain,
+			&i.RequireDb,
+			&i.MigrationCommand,
+			&i.Status,
+			&i.StatusMessage,
+			&i.DeletedAt,
+			&i.ImageRetention,
+			&i.DeploymentStrategy,
+			&i.CanaryPercent,
+			&i.CanaryBakeMinutes,
+			&i.Datastores,
+			&i.VolumeMountPath,
+			&i.VolumeSizeGb,
+			&i.ProjectType,
+			&i.Schedule,
+			&i.Services,
+			&i.Environments,
+			&i.ProtectedEnvironments,
+			&i.ContainerPort,
+			&i.HealthCheckPath,
+			&i.Cpu,
+			&i.Memory,
+			&i.OutputDirectory,
+			&i.DeploymentTarget,
+			&i.LambdaEndpoint,
+			&i.DeployRules,
+			&i.PublicStatusPage,
+			&i.BuildSize,
+			&i.BuildTimeoutMinutes,
+			&i.RedeployOnEnvChange,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListProjectsWithCronJobs = `-- name: ListProjectsWithCronJobs :many
+SELECT id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command, status, status_message, deleted_at, image_retention, deployment_strategy, canary_percent, canary_bake_minutes, datastores, volume_mount_path, volume_size_gb, project_type, schedule, services, environments, protected_environments, container_port, health_check_path, cpu, memory, output_directory, deployment_target, lambda_endpoint, deploy_rules, public_status_page, build_size, build_timeout_minutes, redeploy_on_env_change FROM projects
+WHERE (project_type = 'CRON' OR services @> '[{"type": "CRON"}]') AND deleted_at IS NULL
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListProjectsWithCronJobs(ctx context.Context) ([]*Project, error) {
+	rows, err := q.db.Query(ctx, ListProjectsWithCronJobs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Project{}
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.RepositoryUrl,
+			&i.BuildCommand,
+			&i.RunCommand,
+			&i.Language,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.InstallCommand,
+			&i.CustomDomain,
+			&i.RequireDb,
+			&i.MigrationCommand,
+			&i.Status,
+			&i.StatusMessage,
+			&i.DeletedAt,
+			&i.ImageRetention,
+			&i.DeploymentStrategy,
+			&i.CanaryPercent,
+			&i.CanaryBakeMinutes,
+			&i.Datastores,
+			&i.VolumeMountPath,
+			&i.VolumeSizeGb,
+			&i.ProjectType,
+			&i.Schedule,
+			&i.Services,
+			&i.Environments,
+			&i.ProtectedEnvironments,
+			&i.ContainerPort,
+			&i.HealthCheckPath,
+			&i.Cpu,
+			&i.Memory,
+			&i.OutputDirectory,
+			&i.DeploymentTarget,
+			&i.LambdaEndpoint,
+			&i.DeployRules,
+			&i.PublicStatusPage,
+			&i.BuildSize,
+			&i.BuildTimeoutMinutes,
+			&i.RedeployOnEnvChange,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (q *Queries) ListProjects(ctx context.Context, arg *ListProjectsParams) ([]*Project, error) {
+	rows, err := q.db.Query(ctx, ListProjects, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Project{}
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.RepositoryUrl,
+			&i.BuildCommand,
+			&i.RunCommand,
+			&i.Language,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.InstallCommand,
+			&i.CustomDomain,
+			&i.RequireDb,
+			&i.MigrationCommand,
+			&i.Status,
+			&i.StatusMessage,
+			&i.DeletedAt,
+			&i.ImageRetention,
+			&i.DeploymentStrategy,
+			&i.CanaryPercent,
+			&i.CanaryBakeMinutes,
+			&i.Datastores,
+			&i.VolumeMountPath,
+			&i.VolumeSizeGb,
+			&i.ProjectType,
+			&i.Schedule,
+			&i.Services,
+			&i.Environments,
+			&i.ProtectedEnvironments,
+			&i.ContainerPort,
+			&i.HealthCheckPath,
+			&i.Cpu,
+			&i.Memory,
+			&i.OutputDirectory,
+			&i.DeploymentTarget,
+			&i.LambdaEndpoint,
+			&i.DeployRules,
+			&i.PublicStatusPage,
+			&i.BuildSize,
+			&i.BuildTimeoutMinutes,
+			&i.RedeployOnEnvChange,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const PurgeDeletedProjects = `-- name: PurgeDeletedProjects :execrows
+DELETE FROM projects
+WHERE projects.id IN (
+    SELECT p.id FROM projects p
+    WHERE p.deleted_at < $1
+    ORDER BY p.deleted_at
+    LIMIT $2
+)
+`
+
+type PurgeDeletedProjectsParams struct {
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	Limit     int32        `json:"limit"`
+}
+
+func (q *Queries) PurgeDeletedProjects(ctx context.Context, arg *PurgeDeletedProjectsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, PurgeDeletedProjects, arg.DeletedAt, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const SoftDeleteProject = `-- name: SoftDeleteProject :exec
+WITH deleted_deployments AS (
+    UPDATE deployments SET deleted_at = $2
+    WHERE deployments.project_id = $1 AND deployments.deleted_at IS NULL
+), deleted_archive AS (
+    UPDATE deployments_archive SET deleted_at = $2
+    WHERE deployments_archive.project_id = $1 AND deployments_archive.deleted_at IS NULL
+), cancelled_builds AS (
+    DELETE FROM build_jobs WHERE build_jobs.project_id = $1 AND build_jobs.status = 'PENDING'
+)
+UPDATE projects
+SET deleted_at = $2
+WHERE projects.id = $1 AND projects.deleted_at IS NULL
+`
+
+type SoftDeleteProjectParams struct {
+	ID        uuid.UUID    `json:"id"`
+	DeletedAt sql.NullTime `json:"deleted_at"`
+}
+
+func (q *Queries) SoftDeleteProject(ctx context.Context, arg *SoftDeleteProjectParams) error {
+	_, err := q.db.Exec(ctx, SoftDeleteProject, arg.ID, arg.DeletedAt)
+	return err
+}
+
 const UpdateProject = `-- name: UpdateProject :one
 UPDATE projects
 SET
@@ -266,25 +1036,77 @@ SET
     custom_domain = $7,
     require_db = $8,
     migration_command = $9,
+    status = $10,
+    status_message = $11,
+    image_retention = $12,
+    deployment_strategy = $13,
+    canary_percent = $14,
+    canary_bake_minutes = $15,
+    datastores = $16,
+    volume_mount_path = $17,
+    volume_size_gb = $18,
+    project_type = $19,
+    schedule = $20,
+    services = $21,
+    environments = $22,
+    protected_environments = $23,
+    container_port = $24,
+    health_check_path = $25,
+    cpu = $26,
+    memory = $27,
+    output_directory = $28,
+    deployment_target = $29,
+    lambda_endpoint = $30,
+    deploy_rules = $31,
+    public_status_page = $32,
+    build_size = $33,
+    build_timeout_minutes = $34,
+    redeploy_on_env_change = $35,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
-RETURNING id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command
+RETURNING id, user_id, repository_url, build_command, run_command, language, created_at, updated_at, install_command, custom_domain, require_db, migration_command, status, status_message, deleted_at, image_retention, deployment_strategy, canary_percent, canary_bake_minutes, datastores, volume_mount_path, volume_size_gb, project_type, schedule, services, environments, protected_environments, container_port, health_check_path, cpu, memory, output_directory, deployment_target, lambda_endpoint, deploy_rules, public_status_page, build_size, build_timeout_minutes, redeploy_on_env_change
 `
 
 type UpdateProjectParams struct {
-	ID               uuid.UUID      `json:"id"`
-	RepositoryUrl    string         `json:"repository_url"`
-	InstallCommand   string         `json:"install_command"`
-	BuildCommand     sql.NullString `json:"build_command"`
-	RunCommand       string         `json:"run_command"`
-	Language         string         `json:"language"`
-	CustomDomain     string         `json:"custom_domain"`
-	RequireDb        bool           `json:"require_db"`
-	MigrationCommand sql.NullString `json:"migration_command"`
+	ID                    uuid.UUID      `json:"id"`
+	RepositoryUrl         string         `json:"repository_url"`
+	InstallCommand        string         `json:"install_command"`
+	BuildCommand          sql.NullString `json:"build_command"`
+	RunCommand            string         `json:"run_command"`
+	Language              string         `json:"language"`
+	CustomDomain          string         `json:"custom_domain"`
+	RequireDb             bool           `json:"require_db"`
+	MigrationCommand      sql.NullString `json:"migration_command"`
+	Status                string         `json:"status"`
+	StatusMessage         sql.NullString `json:"status_message"`
+	ImageRetention        int32          `json:"image_retention"`
+	DeploymentStrategy    string         `json:"deployment_strategy"`
+	CanaryPercent         int32          `json:"canary_percent"`
+	CanaryBakeMinutes     int32          `json:"canary_bake_minutes"`
+	Datastores            []string       `json:"datastores"`
+	VolumeMountPath       string         `json:"volume_mount_path"`
+	VolumeSizeGb          int32          `json:"volume_size_gb"`
+	ProjectType           string         `json:"project_type"`
+	Schedule              string         `json:"schedule"`
+	Services              []byte         `json:"services"`
+	Environments          []string       `json:"environments"`
+	ProtectedEnvironments []string       `json:"protected_environments"`
+	ContainerPort         int32          `json:"container_port"`
+	HealthCheckPath       string         `json:"health_check_path"`
+	Cpu                   int32          `json:"cpu"`
+	Memory                int32          `json:"memory"`
+	OutputDirectory       string         `json:"output_directory"`
+	DeploymentTarget      string         `json:"deployment_target"`
+	LambdaEndpoint        string         `json:"lambda_endpoint"`
+	DeployRules           []byte         `json:"deploy_rules"`
+	PublicStatusPage      bool           `json:"public_status_page"`
+	BuildSize             string         `json:"build_size"`
+	BuildTimeoutMinutes   int32          `json:"build_timeout_minutes"`
+	RedeployOnEnvChange   bool           `json:"redeploy_on_env_change"`
 }
 
 func (q *Queries) UpdateProject(ctx context.Context, arg *UpdateProjectParams) (*Project, error) {
-	row := q.db.QueryRowContext(ctx, UpdateProject,
+	row := q.db.QueryRow(ctx, UpdateProject,
 		arg.ID,
 		arg.RepositoryUrl,
 		arg.InstallCommand,
@@ -294,6 +1116,32 @@ func (q *Queries) UpdateProject(ctx context.Context, arg *UpdateProjectParams) (
 		arg.CustomDomain,
 		arg.RequireDb,
 		arg.MigrationCommand,
+		arg.Status,
+		arg.StatusMessage,
+		arg.ImageRetention,
+		arg.DeploymentStrategy,
+		arg.CanaryPercent,
+		arg.CanaryBakeMinutes,
+		arg.Datastores,
+		arg.VolumeMountPath,
+		arg.VolumeSizeGb,
+		arg.ProjectType,
+		arg.Schedule,
+		arg.Services,
+		arg.Environments,
+		arg.ProtectedEnvironments,
+		arg.ContainerPort,
+		arg.HealthCheckPath,
+		arg.Cpu,
+		arg.Memory,
+		arg.OutputDirectory,
+		arg.DeploymentTarget,
+		arg.LambdaEndpoint,
+		arg.DeployRules,
+		arg.PublicStatusPage,
+		arg.BuildSize,
+		arg.BuildTimeoutMinutes,
+		arg.RedeployOnEnvChange,
 	)
 	var i Project
 	err := row.Scan(
@@ -309,6 +1157,33 @@ func (q *Queries) UpdateProject(ctx context.Context, arg *UpdateProjectParams) (
 		&i.CustomDomain,
 		&i.RequireDb,
 		&i.MigrationCommand,
+		&i.Status,
+		&i.StatusMessage,
+		&i.DeletedAt,
+		&i.ImageRetention,
+		&i.DeploymentStrategy,
+		&i.CanaryPercent,
+		&i.CanaryBakeMinutes,
+		&i.Datastores,
+		&i.VolumeMountPath,
+		&i.VolumeSizeGb,
+		&i.ProjectType,
+		&i.Schedule,
+		&i.Services,
+		&i.Environments,
+		&i.ProtectedEnvironments,
+		&i.ContainerPort,
+		&i.HealthCheckPath,
+		&i.Cpu,
+		&i.Memory,
+		&i.OutputDirectory,
+		&i.DeploymentTarget,
+		&i.LambdaEndpoint,
+		&i.DeployRules,
+		&i.PublicStatusPage,
+		&i.BuildSize,
+		&i.BuildTimeoutMinutes,
+		&i.RedeployOnEnvChange,
 	)
 	return &i, err
 }
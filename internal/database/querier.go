@@ -6,52 +6,185 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
-	CountDeploymentsByProjectID(ctx context.Context, projectID uuid.UUID) (int64, error)
-	CountDeploymentsByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
-	CountProjectEnvVars(ctx context.Context, projectID uuid.UUID) (int64, error)
+	ApplyEnvironmentVariableChange(ctx context.Context, arg *ApplyEnvironmentVariableChangeParams) (int64, error)
+	ArchiveDeployments(ctx context.Context, arg *ArchiveDeploymentsParams) (int64, error)
+	BackfillProjectCustomDomain(ctx context.Context, arg *BackfillProjectCustomDomainParams) (*Project, error)
+	ClaimBuildJob(ctx context.Context, arg *ClaimBuildJobParams) (*BuildJob, error)
+	ClaimShellSession(ctx context.Context, arg *ClaimShellSessionParams) (int64, error)
+	ClaimStalledProjectTeardown(ctx context.Context, updatedAt sql.NullTime) (*Project, error)
+	CompleteIdempotencyKey(ctx context.Context, arg *CompleteIdempotencyKeyParams) error
+	CountDeploymentsByProjectID(ctx context.Context, arg *CountDeploymentsByProjectIDParams) (int64, error)
+	CountDeploymentsByProjectIDIncludingDeleted(ctx context.Context, arg *CountDeploymentsByProjectIDIncludingDeletedParams) (int64, error)
+	CountDeploymentsByUserID(ctx context.Context, arg *CountDeploymentsByUserIDParams) (int64, error)
+	CountHealthChecksSince(ctx context.Context, arg *CountHealthChecksSinceParams) (*CountHealthChecksSinceRow, error)
+	CountInProgressDeploymentsByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountOpenBuildJobs(ctx context.Context) (int64, error)
+	CountProjectEnvVars(ctx context.Context, arg *CountProjectEnvVarsParams) (int64, error)
+	CountProjectEnvVarsByKeyID(ctx context.Context) ([]*CountProjectEnvVarsByKeyIDRow, error)
 	CountProjectsByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountProjectsByUserIDIncludingDeleted(ctx context.Context, userID uuid.UUID) (int64, error)
 	CountRepositoriesByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
 	CountSearchRepositoriesByUserID(ctx context.Context, arg *CountSearchRepositoriesByUserIDParams) (int64, error)
 	CountUsers(ctx context.Context) (int64, error)
+	CreateAgentToken(ctx context.Context, arg *CreateAgentTokenParams) error
+	CreateBuildJob(ctx context.Context, arg *CreateBuildJobParams) error
+	CreateDatabaseBranch(ctx context.Context, arg *CreateDatabaseBranchParams) (*DatabaseBranch, error)
+	CreateDatabaseSnapshot(ctx context.Context, arg *CreateDatabaseSnapshotParams) (*DatabaseSnapshot, error)
+	CreateDatastoreCredential(ctx context.Context, arg *CreateDatastoreCredentialParams) (int64, error)
 	CreateDeployment(ctx context.Context, arg *CreateDeploymentParams) (*Deployment, error)
+	CreateDeploymentApproval(ctx context.Context, arg *CreateDeploymentApprovalParams) error
+	CreateDeploymentEvent(ctx context.Context, arg *CreateDeploymentEventParams) error
+	CreateEnvironmentApprovers(ctx context.Context, arg *CreateEnvironmentApproversParams) (int64, error)
+	CreateHealthCheck(ctx context.Context, arg *CreateHealthCheckParams) error
 	CreateProject(ctx context.Context, arg *CreateProjectParams) (*Project, error)
 	CreateProjectEnvVar(ctx context.Context, arg *CreateProjectEnvVarParams) (*ProjectEnvironmentVariable, error)
+	CreateSystemIncident(ctx context.Context, arg *CreateSystemIncidentParams) (*SystemIncident, error)
+	CreateUsageRecord(ctx context.Context, arg *CreateUsageRecordParams) (*UsageRecord, error)
 	CreateUser(ctx context.Context, arg *CreateUserParams) (*User, error)
+	DeleteAgentToken(ctx context.Context, arg *DeleteAgentTokenParams) (int64, error)
 	DeleteAllProjectEnvVars(ctx context.Context, projectID uuid.UUID) error
-	DeleteDeployment(ctx context.Context, id uuid.UUID) error
-	DeleteProject(ctx context.Context, id uuid.UUID) error
+	DeleteDatabaseBranch(ctx context.Context, id uuid.UUID) error
+	DeleteDatabaseSnapshot(ctx context.Context, id uuid.UUID) error
+	DeleteDatastoreCredential(ctx context.Context, arg *DeleteDatastoreCredentialParams) error
+	DeleteEnvironmentApprovers(ctx context.Context, arg *DeleteEnvironmentApproversParams) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, expiresAt time.Time) (int64, error)
+	DeleteGitHubInstallation(ctx context.Context, id int64) error
+	DeleteHealthChecksBefore(ctx context.Context, checkedAt time.Time) (int64, error)
+	DeleteIdempotencyKey(ctx context.Context, arg *DeleteIdempotencyKeyParams) error
 	DeleteProjectEnvVar(ctx context.Context, arg *DeleteProjectEnvVarParams) error
+	DeleteProjectEnvironmentEnvVars(ctx context.Context, arg *DeleteProjectEnvironmentEnvVarsParams) error
 	DeleteRepository(ctx context.Context, id uuid.UUID) error
 	DeleteUser(ctx context.Context, id uuid.UUID) error
+	ExistsDeploymentByID(ctx context.Context, id uuid.UUID) (bool, error)
 	ExistsProjectByCustomDomain(ctx context.Context, customDomain string) (bool, error)
 	ExistsProjectByRepositoryURL(ctx context.Context, arg *ExistsProjectByRepositoryURLParams) (bool, error)
+	GetAgentTokenByHash(ctx context.Context, tokenHash string) (*AgentToken, error)
+	GetArchivedDeploymentByID(ctx context.Context, id uuid.UUID) (*DeploymentsArchive, error)
+	GetBillingAccountByCustomerID(ctx context.Context, stripeCustomerID string) (*BillingAccount, error)
+	GetBillingAccountByUserID(ctx context.Context, userID uuid.UUID) (*BillingAccount, error)
+	GetCommandRunByID(ctx context.Context, id uuid.UUID) (*CommandRun, error)
+	GetCronRunByID(ctx context.Context, id uuid.UUID) (*CronRun, error)
+	GetCronRunByTaskARN(ctx context.Context, taskArn string) (*CronRun, error)
+	GetDatabaseBranchByID(ctx context.Context, id uuid.UUID) (*DatabaseBranch, error)
+	GetDatabaseSnapshotByID(ctx context.Context, id uuid.UUID) (*DatabaseSnapshot, error)
+	GetDatastoreCredential(ctx context.Context, arg *GetDatastoreCredentialParams) (*DatastoreCredential, error)
 	GetDeploymentByID(ctx context.Context, id uuid.UUID) (*Deployment, error)
-	GetDeploymentsByProjectID(ctx context.Context, arg *GetDeploymentsByProjectIDParams) ([]*Deployment, error)
-	GetDeploymentsByUserID(ctx context.Context, arg *GetDeploymentsByUserIDParams) ([]*Deployment, error)
+	GetDeploymentByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (*GetDeploymentByIDIncludingDeletedRow, error)
+	GetDeploymentManifest(ctx context.Context, deploymentID uuid.UUID) (*DeploymentManifest, error)
+	GetDeploymentScan(ctx context.Context, deploymentID uuid.UUID) (*DeploymentScan, error)
+	GetDeploymentSteps(ctx context.Context, deploymentID uuid.UUID) ([]*DeploymentStep, error)
+	GetDeploymentsByProjectID(ctx context.Context, arg *GetDeploymentsByProjectIDParams) ([]*GetDeploymentsByProjectIDRow, error)
+	GetDeploymentsByProjectIDAfter(ctx context.Context, arg *GetDeploymentsByProjectIDAfterParams) ([]*GetDeploymentsByProjectIDAfterRow, error)
+	GetDeploymentsByProjectIDAfterAscending(ctx context.Context, arg *GetDeploymentsByProjectIDAfterAscendingParams) ([]*GetDeploymentsByProjectIDAfterAscendingRow, error)
+	GetDeploymentsByProjectIDAscending(ctx context.Context, arg *GetDeploymentsByProjectIDAscendingParams) ([]*GetDeploymentsByProjectIDAscendingRow, error)
+	GetDeploymentsByProjectIDIncludingDeleted(ctx context.Context, arg *GetDeploymentsByProjectIDIncludingDeletedParams) ([]*GetDeploymentsByProjectIDIncludingDeletedRow, error)
+	GetDeploymentsByProjectIDIncludingDeletedAfter(ctx context.Context, arg *GetDeploymentsByProjectIDIncludingDeletedAfterParams) ([]*GetDeploymentsByProjectIDIncludingDeletedAfterRow, error)
+	GetDeploymentsByProjectIDIncludingDeletedAfterAscending(ctx context.Context, arg *GetDeploymentsByProjectIDIncludingDeletedAfterAscendingParams) ([]*GetDeploymentsByProjectIDIncludingDeletedAfterAscendingRow, error)
+	GetDeploymentsByProjectIDIncludingDeletedAscending(ctx context.Context, arg *GetDeploymentsByProjectIDIncludingDeletedAscendingParams) ([]*GetDeploymentsByProjectIDIncludingDeletedAscendingRow, error)
+	GetDeploymentsByUserID(ctx context.Context, arg *GetDeploymentsByUserIDParams) ([]*GetDeploymentsByUserIDRow, error)
+	GetDeploymentsByUserIDAfter(ctx context.Context, arg *GetDeploymentsByUserIDAfterParams) ([]*GetDeploymentsByUserIDAfterRow, error)
+	GetDeploymentsByUserIDAfterAscending(ctx context.Context, arg *GetDeploymentsByUserIDAfterAscendingParams) ([]*GetDeploymentsByUserIDAfterAscendingRow, error)
+	GetDeploymentsByUserIDAscending(ctx context.Context, arg *GetDeploymentsByUserIDAscendingParams) ([]*GetDeploymentsByUserIDAscendingRow, error)
+	GetEnvironmentVariableChange(ctx context.Context, arg *GetEnvironmentVariableChangeParams) (*EnvironmentVariableChange, error)
+	GetGitHubInstallation(ctx context.Context, id int64) (*GithubInstallation, error)
+	GetIdempotencyKey(ctx context.Context, arg *GetIdempotencyKeyParams) (*IdempotencyKey, error)
+	GetLastUsagePeriodEnd(ctx context.Context, arg *GetLastUsagePeriodEndParams) (sql.NullTime, error)
+	GetLatestDeployedDeploymentInEnvironment(ctx context.Context, arg *GetLatestDeployedDeploymentInEnvironmentParams) (*Deployment, error)
+	GetLatestDeployedDeployments(ctx context.Context) ([]*Deployment, error)
 	GetLatestDeploymentByProjectID(ctx context.Context, projectID uuid.UUID) (*Deployment, error)
+	GetLatestDeploymentInEnvironment(ctx context.Context, arg *GetLatestDeploymentInEnvironmentParams) (*Deployment, error)
+	GetListenerRulePriority(ctx context.Context, arg *GetListenerRulePriorityParams) (int32, error)
+	GetOpenProjectIncident(ctx context.Context, projectID uuid.UUID) (*ProjectIncident, error)
+	GetPinnedDeploymentsByProjectID(ctx context.Context, projectID uuid.UUID) ([]*Deployment, error)
 	GetProjectByCustomDomain(ctx context.Context, customDomain string) (*Project, error)
 	GetProjectByID(ctx context.Context, id uuid.UUID) (*Project, error)
+	GetProjectByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (*Project, error)
 	GetProjectByRepositoryURL(ctx context.Context, arg *GetProjectByRepositoryURLParams) (*Project, error)
 	GetProjectEnvVar(ctx context.Context, arg *GetProjectEnvVarParams) (*ProjectEnvironmentVariable, error)
-	GetProjectEnvVars(ctx context.Context, projectID uuid.UUID) ([]*ProjectEnvironmentVariable, error)
+	GetProjectEnvVars(ctx context.Context, arg *GetProjectEnvVarsParams) ([]*ProjectEnvironmentVariable, error)
+	GetProjectTemplateByID(ctx context.Context, id string) (*ProjectTemplate, error)
 	GetProjectsByUserID(ctx context.Context, arg *GetProjectsByUserIDParams) ([]*Project, error)
+	GetProjectsByUserIDIncludingDeleted(ctx context.Context, arg *GetProjectsByUserIDIncludingDeletedParams) ([]*Project, error)
+	GetRepositoriesByURLs(ctx context.Context, urls []string) ([]*Repository, error)
 	GetRepositoriesByUserID(ctx context.Context, arg *GetRepositoriesByUserIDParams) ([]*Repository, error)
+	GetRepositoriesByUserIDAfter(ctx context.Context, arg *GetRepositoriesByUserIDAfterParams) ([]*Repository, error)
+	GetRepositoryByID(ctx context.Context, id uuid.UUID) (*Repository, error)
 	GetRepositoryByURL(ctx context.Context, url string) (*Repository, error)
+	GetRepositorySync(ctx context.Context, arg *GetRepositorySyncParams) (*RepositorySync, error)
+	GetShellSessionByID(ctx context.Context, id uuid.UUID) (*ShellSession, error)
+	GetStuckDeployments(ctx context.Context, arg *GetStuckDeploymentsParams) ([]*Deployment, error)
+	GetSystemIncidentByID(ctx context.Context, id uuid.UUID) (*SystemIncident, error)
 	GetUserByClerkID(ctx context.Context, clerkUserID string) (*User, error)
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (*User, error)
+	GetUserQuota(ctx context.Context, userID uuid.UUID) (*UserQuota, error)
+	ListAgentTokensByUserID(ctx context.Context, userID uuid.UUID) ([]*AgentToken, error)
+	ListBillingAccounts(ctx context.Context, arg *ListBillingAccountsParams) ([]*BillingAccount, error)
+	ListCommandRunsByProjectID(ctx context.Context, arg *ListCommandRunsByProjectIDParams) ([]*CommandRun, error)
+	ListCronRunsByDeploymentID(ctx context.Context, arg *ListCronRunsByDeploymentIDParams) ([]*CronRun, error)
+	ListDatabaseBranchesByProjectID(ctx context.Context, projectID uuid.UUID) ([]*DatabaseBranch, error)
+	ListDatabaseSnapshotsByProjectID(ctx context.Context, projectID uuid.UUID) ([]*DatabaseSnapshot, error)
+	ListDeploymentApprovals(ctx context.Context, deploymentID uuid.UUID) ([]*DeploymentApproval, error)
+	ListDeploymentEvents(ctx context.Context, deploymentID uuid.UUID) ([]*DeploymentEvent, error)
+	ListEnvironmentApprovers(ctx context.Context, arg *ListEnvironmentApproversParams) ([]uuid.UUID, error)
+	ListExpiredDatabaseBranches(ctx context.Context, expiresAt time.Time) ([]*DatabaseBranch, error)
+	ListExpiredDatabaseSnapshots(ctx context.Context, expiresAt time.Time) ([]*DatabaseSnapshot, error)
+	ListGitHubInstallationsByUserID(ctx context.Context, userID uuid.NullUUID) ([]*GithubInstallation, error)
+	ListListenerRulePriorities(ctx context.Context, listenerArn string) ([]int32, error)
+	ListProjectEnvVarsToReencrypt(ctx context.Context, arg *ListProjectEnvVarsToReencryptParams) ([]*ListProjectEnvVarsToReencryptRow, error)
+	ListProjectIncidents(ctx context.Context, arg *ListProjectIncidentsParams) ([]*ProjectIncident, error)
+	ListProjectTemplates(ctx context.Context) ([]*ProjectTemplate, error)
+	ListProjects(ctx context.Context, arg *ListProjectsParams) ([]*Project, error)
+	ListProjectsByCustomDomains(ctx context.Context, customDomains []string) ([]*Project, error)
+	ListProjectsByRepositoryURL(ctx context.Context, repositoryUrl string) ([]*Project, error)
+	ListProjectsWithCronJobs(ctx context.Context) ([]*Project, error)
+	ListRecentHealthChecks(ctx context.Context, arg *ListRecentHealthChecksParams) ([]*HealthCheck, error)
+	ListShellSessionsByProjectID(ctx context.Context, arg *ListShellSessionsByProjectIDParams) ([]*ShellSession, error)
+	ListSystemIncidentsResolvedSince(ctx context.Context, resolvedAt sql.NullTime) ([]*SystemIncident, error)
+	ListUnbilledUserIDs(ctx context.Context, limit int32) ([]uuid.UUID, error)
+	ListUnresolvedSystemIncidents(ctx context.Context) ([]*SystemIncident, error)
 	ListUsers(ctx context.Context, arg *ListUsersParams) ([]*User, error)
+	PurgeDeletedDeployments(ctx context.Context, arg *PurgeDeletedDeploymentsParams) (int64, error)
+	PurgeDeletedProjects(ctx context.Context, arg *PurgeDeletedProjectsParams) (int64, error)
+	ReencryptProjectEnvVar(ctx context.Context, arg *ReencryptProjectEnvVarParams) (int64, error)
+	ReleaseListenerRulePriority(ctx context.Context, arg *ReleaseListenerRulePriorityParams) error
+	RequeueBuildJob(ctx context.Context, deploymentID uuid.UUID) error
+	ReserveIdempotencyKey(ctx context.Context, arg *ReserveIdempotencyKeyParams) (int64, error)
+	ReserveListenerRulePriority(ctx context.Context, arg *ReserveListenerRulePriorityParams) (int64, error)
 	SearchRepositoriesByUserID(ctx context.Context, arg *SearchRepositoriesByUserIDParams) ([]*Repository, error)
+	SearchRepositoriesByUserIDAfter(ctx context.Context, arg *SearchRepositoriesByUserIDAfterParams) ([]*Repository, error)
+	SoftDeleteDeployment(ctx context.Context, arg *SoftDeleteDeploymentParams) error
+	SoftDeleteProject(ctx context.Context, arg *SoftDeleteProjectParams) error
+	SumUsageByProjectID(ctx context.Context, arg *SumUsageByProjectIDParams) ([]*SumUsageByProjectIDRow, error)
+	SumUsageByUserID(ctx context.Context, arg *SumUsageByUserIDParams) ([]*SumUsageByUserIDRow, error)
+	UpdateBuildJob(ctx context.Context, arg *UpdateBuildJobParams) error
 	UpdateDeployment(ctx context.Context, arg *UpdateDeploymentParams) error
+	UpdateDeploymentLogs(ctx context.Context, arg *UpdateDeploymentLogsParams) (int64, error)
 	UpdateProject(ctx context.Context, arg *UpdateProjectParams) (*Project, error)
 	UpdateProjectEnvVar(ctx context.Context, arg *UpdateProjectEnvVarParams) (*ProjectEnvironmentVariable, error)
+	UpdateSystemIncident(ctx context.Context, arg *UpdateSystemIncidentParams) error
 	UpdateUser(ctx context.Context, arg *UpdateUserParams) (*User, error)
+	UpsertBillingAccount(ctx context.Context, arg *UpsertBillingAccountParams) error
+	UpsertCommandRun(ctx context.Context, arg *UpsertCommandRunParams) error
+	UpsertCronRun(ctx context.Context, arg *UpsertCronRunParams) (*CronRun, error)
+	UpsertDeploymentManifest(ctx context.Context, arg *UpsertDeploymentManifestParams) error
+	UpsertDeploymentScan(ctx context.Context, arg *UpsertDeploymentScanParams) error
+	UpsertDeploymentStep(ctx context.Context, arg *UpsertDeploymentStepParams) error
+	UpsertEnvironmentVariableChange(ctx context.Context, arg *UpsertEnvironmentVariableChangeParams) error
+	UpsertGitHubInstallation(ctx context.Context, arg *UpsertGitHubInstallationParams) error
+	UpsertProjectIncident(ctx context.Context, arg *UpsertProjectIncidentParams) (*ProjectIncident, error)
+	UpsertRepositories(ctx context.Context, arg *UpsertRepositoriesParams) error
 	UpsertRepository(ctx context.Context, arg *UpsertRepositoryParams) (*Repository, error)
+	UpsertRepositorySync(ctx context.Context, arg *UpsertRepositorySyncParams) error
+	UpsertShellSession(ctx context.Context, arg *UpsertShellSessionParams) error
+	UpsertUserQuota(ctx context.Context, arg *UpsertUserQuotaParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -8,6 +8,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -18,7 +19,7 @@ WHERE user_id = $1
 `
 
 func (q *Queries) CountRepositoriesByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
-	row := q.db.QueryRowContext(ctx, CountRepositoriesByUserID, userID)
+	row := q.db.QueryRow(ctx, CountRepositoriesByUserID, userID)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -40,7 +41,7 @@ type CountSearchRepositoriesByUserIDParams struct {
 }
 
 func (q *Queries) CountSearchRepositoriesByUserID(ctx context.Context, arg *CountSearchRepositoriesByUserIDParams) (int64, error) {
-	row := q.db.QueryRowContext(ctx, CountSearchRepositoriesByUserID, arg.UserID, arg.Column2)
+	row := q.db.QueryRow(ctx, CountSearchRepositoriesByUserID, arg.UserID, arg.Column2)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -52,14 +53,58 @@ WHERE id = $1
 `
 
 func (q *Queries) DeleteRepository(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, DeleteRepository, id)
+	_, err := q.db.Exec(ctx, DeleteRepository, id)
 	return err
 }
 
+const GetRepositoriesByURLs = `-- name: GetRepositoriesByURLs :many
+SELECT id, user_id, name, full_name, description, url, html_url, private, fork, stargazers_count, watchers_count, forks_count, default_branch, language, created_at, updated_at, provider, external_id FROM repositories
+WHERE url = ANY($1::text[])
+`
+
+func (q *Queries) GetRepositoriesByURLs(ctx context.Context, urls []string) ([]*Repository, error) {
+	rows, err := q.db.Query(ctx, GetRepositoriesByURLs, urls)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Repository{}
+	for rows.Next() {
+		var i Repository
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.FullName,
+			&i.Description,
+			&i.Url,
+			&i.HtmlUrl,
+			&i.Private,
+			&i.Fork,
+			&i.StargazersCount,
+			&i.WatchersCount,
+			&i.ForksCount,
+			&i.DefaultBranch,
+			&i.Language,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Provider,
+			&i.ExternalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const GetRepositoriesByUserID = `-- name: GetRepositoriesByUserID :many
-SELECT id, user_id, github_id, name, full_name, description, url, html_url, private, fork, stargazers_count, watchers_count, forks_count, default_branch, language, created_at, updated_at FROM repositories
+SELECT id, user_id, name, full_name, description, url, html_url, private, fork, stargazers_count, watchers_count, forks_count, default_branch, language, created_at, updated_at, provider, external_id FROM repositories
 WHERE user_id = $1
-ORDER BY created_at DESC
+ORDER BY created_at DESC, id DESC
 LIMIT $2 OFFSET $3
 `
 
@@ -70,7 +115,7 @@ type GetRepositoriesByUserIDParams struct {
 }
 
 func (q *Queries) GetRepositoriesByUserID(ctx context.Context, arg *GetRepositoriesByUserIDParams) ([]*Repository, error) {
-	rows, err := q.db.QueryContext(ctx, GetRepositoriesByUserID, arg.UserID, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, GetRepositoriesByUserID, arg.UserID, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -81,7 +126,6 @@ func (q *Queries) GetRepositoriesByUserID(ctx context.Context, arg *GetRepositor
 		if err := rows.Scan(
 			&i.ID,
 			&i.UserID,
-			&i.GithubID,
 			&i.Name,
 			&i.FullName,
 			&i.Description,
@@ -96,32 +140,120 @@ func (q *Queries) GetRepositoriesByUserID(ctx context.Context, arg *GetRepositor
 			&i.Language,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Provider,
+			&i.ExternalID,
 		); err != nil {
 			return nil, err
 		}
 		items = append(items, &i)
 	}
-	if err := rows.Close(); err != nil {
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetRepositoriesByUserIDAfter = `-- name: GetRepositoriesByUserIDAfter :many
+SELECT id, user_id, name, full_name, description, url, html_url, private, fork, stargazers_count, watchers_count, forks_count, default_branch, language, created_at, updated_at, provider, external_id FROM repositories
+WHERE user_id = $1
+  AND (created_at, id) < ($2::timestamp, $3::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type GetRepositoriesByUserIDAfterParams struct {
+	UserID         uuid.UUID `json:"user_id"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        uuid.UUID `json:"after_id"`
+	PageSize       int32     `json:"page_size"`
+}
+
+func (q *Queries) GetRepositoriesByUserIDAfter(ctx context.Context, arg *GetRepositoriesByUserIDAfterParams) ([]*Repository, error) {
+	rows, err := q.db.Query(ctx, GetRepositoriesByUserIDAfter,
+		arg.UserID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+	items := []*Repository{}
+	for rows.Next() {
+		var i Repository
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.FullName,
+			&i.Description,
+			&i.Url,
+			&i.HtmlUrl,
+			&i.Private,
+			&i.Fork,
+			&i.StargazersCount,
+			&i.WatchersCount,
+			&i.ForksCount,
+			&i.DefaultBranch,
+			&i.Language,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Provider,
+			&i.ExternalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
+const GetRepositoryByID = `-- name: GetRepositoryByID :one
+SELECT id, user_id, name, full_name, description, url, html_url, private, fork, stargazers_count, watchers_count, forks_count, default_branch, language, created_at, updated_at, provider, external_id FROM repositories
+WHERE id = $1
+`
+
+func (q *Queries) GetRepositoryByID(ctx context.Context, id uuid.UUID) (*Repository, error) {
+	row := q.db.QueryRow(ctx, GetRepositoryByID, id)
+	var i Repository
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.FullName,
+		&i.Description,
+		&i.Url,
+		&i.HtmlUrl,
+		&i.Private,
+		&i.Fork,
+		&i.StargazersCount,
+		&i.WatchersCount,
+		&i.ForksCount,
+		&i.DefaultBranch,
+		&i.Language,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Provider,
+		&i.ExternalID,
+	)
+	return &i, err
+}
+
 const GetRepositoryByURL = `-- name: GetRepositoryByURL :one
-SELECT id, user_id, github_id, name, full_name, description, url, html_url, private, fork, stargazers_count, watchers_count, forks_count, default_branch, language, created_at, updated_at FROM repositories
+SELECT id, user_id, name, full_name, description, url, html_url, private, fork, stargazers_count, watchers_count, forks_count, default_branch, language, created_at, updated_at, provider, external_id FROM repositories
 WHERE url = $1
 `
 
 func (q *Queries) GetRepositoryByURL(ctx context.Context, url string) (*Repository, error) {
-	row := q.db.QueryRowContext(ctx, GetRepositoryByURL, url)
+	row := q.db.QueryRow(ctx, GetRepositoryByURL, url)
 	var i Repository
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
-		&i.GithubID,
 		&i.Name,
 		&i.FullName,
 		&i.Description,
@@ -136,19 +268,21 @@ func (q *Queries) GetRepositoryByURL(ctx context.Context, url string) (*Reposito
 		&i.Language,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Provider,
+		&i.ExternalID,
 	)
 	return &i, err
 }
 
 const SearchRepositoriesByUserID = `-- name: SearchRepositoriesByUserID :many
-SELECT id, user_id, github_id, name, full_name, description, url, html_url, private, fork, stargazers_count, watchers_count, forks_count, default_branch, language, created_at, updated_at FROM repositories
+SELECT id, user_id, name, full_name, description, url, html_url, private, fork, stargazers_count, watchers_count, forks_count, default_branch, language, created_at, updated_at, provider, external_id FROM repositories
 WHERE user_id = $1
   AND (
     name LIKE '%' || $2 || '%' OR
     full_name LIKE '%' || $2 || '%' OR
     description LIKE '%' || $2 || '%'
   )
-ORDER BY created_at DESC
+ORDER BY created_at DESC, id DESC
 LIMIT $3 OFFSET $4
 `
 
@@ -160,7 +294,7 @@ type SearchRepositoriesByUserIDParams struct {
 }
 
 func (q *Queries) SearchRepositoriesByUserID(ctx context.Context, arg *SearchRepositoriesByUserIDParams) ([]*Repository, error) {
-	rows, err := q.db.QueryContext(ctx, SearchRepositoriesByUserID,
+	rows, err := q.db.Query(ctx, SearchRepositoriesByUserID,
 		arg.UserID,
 		arg.Column2,
 		arg.Limit,
@@ -176,7 +310,6 @@ func (q *Queries) SearchRepositoriesByUserID(ctx context.Context, arg *SearchRep
 		if err := rows.Scan(
 			&i.ID,
 			&i.UserID,
-			&i.GithubID,
 			&i.Name,
 			&i.FullName,
 			&i.Description,
@@ -191,24 +324,196 @@ func (q *Queries) SearchRepositoriesByUserID(ctx context.Context, arg *SearchRep
 			&i.Language,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Provider,
+			&i.ExternalID,
 		); err != nil {
 			return nil, err
 		}
 		items = append(items, &i)
 	}
-	if err := rows.Close(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	return items, nil
+}
+
+const SearchRepositoriesByUserIDAfter = `-- name: SearchRepositoriesByUserIDAfter :many
+SELECT id, user_id, name, full_name, description, url, html_url, private, fork, stargazers_count, watchers_count, forks_count, default_branch, language, created_at, updated_at, provider, external_id FROM repositories
+WHERE user_id = $1
+  AND (
+    name LIKE '%' || $2::text || '%' OR
+    full_name LIKE '%' || $2::text || '%' OR
+    description LIKE '%' || $2::text || '%'
+  )
+  AND (created_at, id) < ($3::timestamp, $4::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT $5
+`
+
+type SearchRepositoriesByUserIDAfterParams struct {
+	UserID         uuid.UUID `json:"user_id"`
+	Search         string    `json:"search"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        uuid.UUID `json:"after_id"`
+	PageSize       int32     `json:"page_size"`
+}
+
+func (q *Queries) SearchRepositoriesByUserIDAfter(ctx context.Context, arg *SearchRepositoriesByUserIDAfterParams) ([]*Repository, error) {
+	rows, err := q.db.Query(ctx, SearchRepositoriesByUserIDAfter,
+		arg.UserID,
+		arg.Search,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Repository{}
+	for rows.Next() {
+		var i Repository
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.FullName,
+			&i.Description,
+			&i.Url,
+			&i.HtmlUrl,
+			&i.Private,
+			&i.Fork,
+			&i.StargazersCount,
+			&i.WatchersCount,
+			&i.ForksCount,
+			&i.DefaultBranch,
+			&i.Language,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Provider,
+			&i.ExternalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
+const UpsertRepositories = `-- name: UpsertRepositories :exec
+INSERT INTO repositories (
+    user_id,
+    provider,
+    external_id,
+    name,
+    full_name,
+    description,
+    url,
+    html_url,
+    private,
+    fork,
+    stargazers_count,
+    watchers_count,
+    forks_count,
+    default_branch,
+    language
+)
+SELECT
+    r.user_id,
+    r.provider,
+    r.external_id,
+    r.name,
+    r.full_name,
+    NULLIF(r.description, ''),
+    r.url,
+    NULLIF(r.html_url, ''),
+    r.private,
+    r.fork,
+    r.stargazers_count,
+    r.watchers_count,
+    r.forks_count,
+    NULLIF(r.default_branch, ''),
+    NULLIF(r.language, '')
+FROM unnest(
+    $1::uuid[],
+    $2::text[],
+    $3::text[],
+    $4::text[],
+    $5::text[],
+    $6::text[],
+    $7::text[],
+    $8::text[],
+    $9::boolean[],
+    $10::boolean[],
+    $11::integer[],
+    $12::integer[],
+    $13::integer[],
+    $14::text[],
+    $15::text[]
+) AS r(user_id, provider, external_id, name, full_name, description, url, html_url, private, fork, stargazers_count, watchers_count, forks_count, default_branch, language)
+ON CONFLICT (url)
+DO UPDATE SET
+    name = EXCLUDED.name,
+    full_name = EXCLUDED.full_name,
+    description = EXCLUDED.description,
+    html_url = EXCLUDED.html_url,
+    private = EXCLUDED.private,
+    fork = EXCLUDED.fork,
+    stargazers_count = EXCLUDED.stargazers_count,
+    watchers_count = EXCLUDED.watchers_count,
+    forks_count = EXCLUDED.forks_count,
+    default_branch = EXCLUDED.default_branch,
+    language = EXCLUDED.language,
+    updated_at = CURRENT_TIMESTAMP
+`
+
+type UpsertRepositoriesParams struct {
+	UserIds          []uuid.UUID `json:"user_ids"`
+	Providers        []string    `json:"providers"`
+	ExternalIds      []string    `json:"external_ids"`
+	Names            []string    `json:"names"`
+	FullNames        []string    `json:"full_names"`
+	Descriptions     []string    `json:"descriptions"`
+	Urls             []string    `json:"urls"`
+	HtmlUrls         []string    `json:"html_urls"`
+	Privates         []bool      `json:"privates"`
+	Forks            []bool      `json:"forks"`
+	StargazersCounts []int32     `json:"stargazers_counts"`
+	WatchersCounts   []int32     `json:"watchers_counts"`
+	ForksCounts      []int32     `json:"forks_counts"`
+	DefaultBranches  []string    `json:"default_branches"`
+	Languages        []string    `json:"languages"`
+}
+
+func (q *Queries) UpsertRepositories(ctx context.Context, arg *UpsertRepositoriesParams) error {
+	_, err := q.db.Exec(ctx, UpsertRepositories,
+		arg.UserIds,
+		arg.Providers,
+		arg.ExternalIds,
+		arg.Names,
+		arg.FullNames,
+		arg.Descriptions,
+		arg.Urls,
+		arg.HtmlUrls,
+		arg.Privates,
+		arg.Forks,
+		arg.StargazersCounts,
+		arg.WatchersCounts,
+		arg.ForksCounts,
+		arg.DefaultBranches,
+		arg.Languages,
+	)
+	return err
+}
+
 const UpsertRepository = `-- name: UpsertRepository :one
 INSERT INTO repositories (
     user_id,
-    github_id,
+    provider,
+    external_id,
     name,
     full_name,
     description,
@@ -222,7 +527,7 @@ INSERT INTO repositories (
     default_branch,
     language
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
 )
 ON CONFLICT (url) 
 DO UPDATE SET
@@ -238,12 +543,13 @@ DO UPDATE SET
     default_branch = EXCLUDED.default_branch,
     language = EXCLUDED.language,
     updated_at = CURRENT_TIMESTAMP
-RETURNING id, user_id, github_id, name, full_name, description, url, html_url, private, fork, stargazers_count, watchers_count, forks_count, default_branch, language, created_at, updated_at
+RETURNING id, user_id, name, full_name, description, url, html_url, private, fork, stargazers_count, watchers_count, forks_count, default_branch, language, created_at, updated_at, provider, external_id
 `
 
 type UpsertRepositoryParams struct {
 	UserID          uuid.UUID      `json:"user_id"`
-	GithubID        int64          `json:"github_id"`
+	Provider        string         `json:"provider"`
+	ExternalID      string         `json:"external_id"`
 	Name            string         `json:"name"`
 	FullName        string         `json:"full_name"`
 	Description     sql.NullString `json:"description"`
@@ -259,9 +565,10 @@ type UpsertRepositoryParams struct {
 }
 
 func (q *Queries) UpsertRepository(ctx context.Context, arg *UpsertRepositoryParams) (*Repository, error) {
-	row := q.db.QueryRowContext(ctx, UpsertRepository,
+	row := q.db.QueryRow(ctx, UpsertRepository,
 		arg.UserID,
-		arg.GithubID,
+		arg.Provider,
+		arg.ExternalID,
 		arg.Name,
 		arg.FullName,
 		arg.Description,
@@ -279,7 +586,6 @@ func (q *Queries) UpsertRepository(ctx context.Context, arg *UpsertRepositoryPar
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
-		&i.GithubID,
 		&i.Name,
 		&i.FullName,
 		&i.Description,
@@ -294,6 +600,8 @@ func (q *Queries) UpsertRepository(ctx context.Context, arg *UpsertRepositoryPar
 		&i.Language,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Provider,
+		&i.ExternalID,
 	)
 	return &i, err
 }
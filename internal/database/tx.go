@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// txKey is the context key of the transaction started by InTx
+type txKey struct{}
+
+// txState is a transaction in progress and the work waiting for it to commit
+type txState struct {
+	tx          pgx.Tx
+	queries     *Queries
+	afterCommit []func(ctx context.Context)
+}
+
+// Queries returns the queries of the transaction started by InTx if the context carries one,
+// or the queries shared by everything running on the pool.
+// Repositories use it so they take part in the caller's transaction.
+func (db *DB) Queries(ctx context.Context) *Queries {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.queries
+	}
+	return db.queries
+}
+
+// InTx runs fn in a transaction, committed if fn returns nil and rolled back otherwise.
+// Queries got from Queries with the context passed to fn run in the transaction.
+// If the context already carries a transaction, fn joins it instead of starting another one.
+func (db *DB) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	state := &txState{tx: tx, queries: db.queries.WithTx(tx)}
+	if err := fn(context.WithValue(ctx, txKey{}, state)); err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+			slog.ErrorContext(ctx, "Failed to roll back transaction", "error", rollbackErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for _, f := range state.afterCommit {
+		f(ctx)
+	}
+
+	return nil
+}
+
+// AfterCommit runs f once the transaction carried by the context commits, or right away outside a transaction.
+// It is dropped if the transaction is rolled back, e.g. so events aren't published for changes that never happened.
+// f gets a context without the transaction, so its own queries run on their own.
+func AfterCommit(ctx context.Context, f func(ctx context.Context)) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.afterCommit = append(state.afterCommit, f)
+		return
+	}
+	f(ctx)
+}
@@ -16,7 +16,7 @@ SELECT COUNT(*) FROM users
 `
 
 func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, CountUsers)
+	row := q.db.QueryRow(ctx, CountUsers)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -36,7 +36,7 @@ type CreateUserParams struct {
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg *CreateUserParams) (*User, error) {
-	row := q.db.QueryRowContext(ctx, CreateUser,
+	row := q.db.QueryRow(ctx, CreateUser,
 		arg.ID,
 		arg.Email,
 		arg.Username,
@@ -60,7 +60,7 @@ WHERE id = $1
 `
 
 func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, DeleteUser, id)
+	_, err := q.db.Exec(ctx, DeleteUser, id)
 	return err
 }
 
@@ -70,7 +70,7 @@ WHERE clerk_user_id = $1 LIMIT 1
 `
 
 func (q *Queries) GetUserByClerkID(ctx context.Context, clerkUserID string) (*User, error) {
-	row := q.db.QueryRowContext(ctx, GetUserByClerkID, clerkUserID)
+	row := q.db.QueryRow(ctx, GetUserByClerkID, clerkUserID)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -89,7 +89,7 @@ WHERE email = $1 LIMIT 1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (*User, error) {
-	row := q.db.QueryRowContext(ctx, GetUserByEmail, email)
+	row := q.db.QueryRow(ctx, GetUserByEmail, email)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -108,7 +108,7 @@ WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (*User, error) {
-	row := q.db.QueryRowContext(ctx, GetUserByID, id)
+	row := q.db.QueryRow(ctx, GetUserByID, id)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -133,7 +133,7 @@ type ListUsersParams struct {
 }
 
 func (q *Queries) ListUsers(ctx context.Context, arg *ListUsersParams) ([]*User, error) {
-	rows, err := q.db.QueryContext(ctx, ListUsers, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, ListUsers, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -153,9 +153,6 @@ func (q *Queries) ListUsers(ctx context.Context, arg *ListUsersParams) ([]*User,
 		}
 		items = append(items, &i)
 	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
@@ -176,7 +173,7 @@ type UpdateUserParams struct {
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg *UpdateUserParams) (*User, error) {
-	row := q.db.QueryRowContext(ctx, UpdateUser, arg.Email, arg.Username, arg.ID)
+	row := q.db.QueryRow(ctx, UpdateUser, arg.Email, arg.Username, arg.ID)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -0,0 +1,99 @@
+// Package agent holds the credentials users' self-hosted build agents authenticate with
+package agent
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"snapdeploy-core/internal/domain/user"
+)
+
+// TokenPrefix starts every agent token, so leaked tokens are easy to recognise
+const TokenPrefix = "sdagent_"
+
+// maxNameLength bounds the name a token is given
+const maxNameLength = 100
+
+// Token is a domain entity representing a credential a user's self-hosted build agents present. Agents
+// presenting it only run the builds of the user's projects. Only the hash of the token is kept.
+type Token struct {
+	id        TokenID
+	userID    user.UserID // User whose builds the agents run
+	name      string
+	tokenHash string // SHA-256 of the token
+	createdAt time.Time
+}
+
+// NewToken creates a token for the user's agents, and the secret they present. Only its hash is kept.
+func NewToken(userID user.UserID, name string) (*Token, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		return nil, "", ErrInvalidTokenName
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", fmt.Errorf("failed to generate agent token: %w", err)
+	}
+	secret := TokenPrefix + hex.EncodeToString(raw)
+
+	return &Token{
+		id:        NewTokenID(),
+		userID:    userID,
+		name:      name,
+		tokenHash: HashToken(secret),
+		createdAt: time.Now(),
+	}, secret, nil
+}
+
+// ReconstituteToken recreates a Token entity from persistence
+func ReconstituteToken(id, userID, name, tokenHash string, createdAt time.Time) (*Token, error) {
+	tid, err := ParseTokenID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	return &Token{
+		id:        tid,
+		userID:    uid,
+		name:      name,
+		tokenHash: tokenHash,
+		createdAt: createdAt,
+	}, nil
+}
+
+// HashToken returns the hash a token is looked up by. Tokens are random, so an unkeyed hash is enough.
+func HashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func (t *Token) ID() TokenID {
+	return t.id
+}
+
+func (t *Token) UserID() user.UserID {
+	return t.userID
+}
+
+func (t *Token) Name() string {
+	return t.name
+}
+
+func (t *Token) TokenHash() string {
+	return t.tokenHash
+}
+
+func (t *Token) CreatedAt() time.Time {
+	return t.createdAt
+}
@@ -0,0 +1,44 @@
+package agent_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"snapdeploy-core/internal/domain/agent"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestNewToken(t *testing.T) {
+	owner := user.NewUserID()
+	token, secret, err := agent.NewToken(owner, "  build-01 ")
+	if err != nil {
+		t.Fatalf("NewToken() error = %v", err)
+	}
+
+	if !strings.HasPrefix(secret, agent.TokenPrefix) {
+		t.Errorf("secret = %q, want it to start with %q", secret, agent.TokenPrefix)
+	}
+	if token.TokenHash() == secret || token.TokenHash() != agent.HashToken(secret) {
+		t.Error("token keeps the secret, want only its hash")
+	}
+	if token.Name() != "build-01" || !token.UserID().Equals(owner) {
+		t.Errorf("token = %q of %s, want build-01 of %s", token.Name(), token.UserID(), owner)
+	}
+
+	_, other, err := agent.NewToken(owner, "build-02")
+	if err != nil {
+		t.Fatalf("NewToken() error = %v", err)
+	}
+	if other == secret {
+		t.Error("NewToken() returned the same secret twice")
+	}
+}
+
+func TestNewToken_InvalidName(t *testing.T) {
+	for _, name := range []string{"", "   ", strings.Repeat("a", 101)} {
+		if _, _, err := agent.NewToken(user.NewUserID(), name); !errors.Is(err, agent.ErrInvalidTokenName) {
+			t.Errorf("NewToken(%q) error = %v, want ErrInvalidTokenName", name, err)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package agent
+
+import (
+	"errors"
+
+	"snapdeploy-core/internal/domain/validation"
+)
+
+var (
+	// ErrTokenNotFound is returned when an agent token is not found, or was presented but doesn't exist
+	ErrTokenNotFound = errors.New("agent token not found")
+
+	// ErrInvalidTokenName is returned when a token is given an empty or overly long name
+	ErrInvalidTokenName = validation.New("agent token name must be between 1 and 100 characters")
+)
@@ -0,0 +1,22 @@
+package agent
+
+import (
+	"context"
+
+	"snapdeploy-core/internal/domain/user"
+)
+
+// TokenRepository defines the interface for agent token persistence
+type TokenRepository interface {
+	// Save persists a new token
+	Save(ctx context.Context, token *Token) error
+
+	// FindByHash retrieves the token with the given hash, failing with ErrTokenNotFound if there is none
+	FindByHash(ctx context.Context, tokenHash string) (*Token, error)
+
+	// FindByUserID retrieves a user's tokens, newest first
+	FindByUserID(ctx context.Context, userID user.UserID) ([]*Token, error)
+
+	// Delete revokes one of a user's tokens, failing with ErrTokenNotFound if the user has no such token
+	Delete(ctx context.Context, id TokenID, userID user.UserID) error
+}
@@ -0,0 +1,38 @@
+package agent
+
+import (
+	"github.com/google/uuid"
+
+	"snapdeploy-core/internal/domain/validation"
+)
+
+// TokenID is a value object representing an agent token's unique identifier
+type TokenID struct {
+	value uuid.UUID
+}
+
+// NewTokenID creates a new TokenID
+func NewTokenID() TokenID {
+	return TokenID{value: uuid.New()}
+}
+
+// ParseTokenID parses a string into a TokenID
+func ParseTokenID(id string) (TokenID, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return TokenID{}, validation.Errorf("invalid agent token ID format: %w", err)
+	}
+	return TokenID{value: uid}, nil
+}
+
+func (id TokenID) String() string {
+	return id.value.String()
+}
+
+func (id TokenID) UUID() uuid.UUID {
+	return id.value
+}
+
+func (id TokenID) Equals(other TokenID) bool {
+	return id.value == other.value
+}
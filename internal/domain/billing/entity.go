@@ -0,0 +1,168 @@
+package billing
+
+import (
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/domain/usage"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// Account links a user to the Stripe customer they are billed as
+type Account struct {
+	userID         user.UserID
+	customerID     string
+	subscriptionID string // Empty before the customer subscribes
+	plan           string // Quota plan of the subscription, empty for the default plan
+	status         Status
+	suspendedAt    *time.Time
+	buildMinutes   Meter
+	vcpuHours      Meter
+	createdAt      time.Time
+	updatedAt      time.Time
+}
+
+// NewAccount creates the billing account of a user's new customer. Only usage from now on is reported.
+func NewAccount(userID user.UserID, customerID string) (*Account, error) {
+	if customerID == "" {
+		return nil, fmt.Errorf("customer ID is required")
+	}
+
+	now := time.Now()
+	return &Account{
+		userID:       userID,
+		customerID:   customerID,
+		status:       StatusActive,
+		buildMinutes: Meter{ReportedUntil: now},
+		vcpuHours:    Meter{ReportedUntil: now},
+		createdAt:    now,
+		updatedAt:    now,
+	}, nil
+}
+
+// ReconstructAccount recreates a billing account from persistence
+func ReconstructAccount(
+	userID user.UserID,
+	customerID, subscriptionID, plan string,
+	status Status,
+	suspendedAt *time.Time,
+	buildMinutes, vcpuHours Meter,
+	createdAt, updatedAt time.Time,
+) *Account {
+	return &Account{
+		userID:         userID,
+		customerID:     customerID,
+		subscriptionID: subscriptionID,
+		plan:           plan,
+		status:         status,
+		suspendedAt:    suspendedAt,
+		buildMinutes:   buildMinutes,
+		vcpuHours:      vcpuHours,
+		createdAt:      createdAt,
+		updatedAt:      updatedAt,
+	}
+}
+
+// Subscribe records the customer's subscription and the quota plan it entitles them to
+func (a *Account) Subscribe(subscriptionID, plan string) {
+	a.subscriptionID = subscriptionID
+	a.plan = plan
+	a.updatedAt = time.Now()
+}
+
+// Unsubscribe records that the customer's subscription ended, putting them back on the default plan
+func (a *Account) Unsubscribe() {
+	a.plan = ""
+	a.updatedAt = time.Now()
+}
+
+// Suspend suspends the account after a failed payment and reports whether it was active until now
+func (a *Account) Suspend() bool {
+	if a.status == StatusSuspended {
+		return false
+	}
+
+	now := time.Now()
+	a.status = StatusSuspended
+	a.suspendedAt = &now
+	a.updatedAt = now
+	return true
+}
+
+// Reinstate reactivates the account once the overdue payment is made and reports whether it was suspended
+func (a *Account) Reinstate() bool {
+	if a.status != StatusSuspended {
+		return false
+	}
+
+	a.status = StatusActive
+	a.suspendedAt = nil
+	a.updatedAt = time.Now()
+	return true
+}
+
+// IsSuspended reports whether a payment failed and the account's services are stopped
+func (a *Account) IsSuspended() bool {
+	return a.status == StatusSuspended
+}
+
+// Meter returns how much of a metric was reported. Only BUILD_MINUTES and VCPU_HOURS are billed.
+func (a *Account) Meter(metric usage.Metric) (Meter, error) {
+	switch metric {
+	case usage.MetricBuildMinutes:
+		return a.buildMinutes, nil
+	case usage.MetricVCPUHours:
+		return a.vcpuHours, nil
+	default:
+		return Meter{}, fmt.Errorf("metric %s is not billed", metric)
+	}
+}
+
+// Reported records that a metric's usage of periods ending up to until was reported, carry left over
+func (a *Account) Reported(metric usage.Metric, until time.Time, carry float64) error {
+	meter := Meter{ReportedUntil: until, Carry: carry}
+	switch metric {
+	case usage.MetricBuildMinutes:
+		a.buildMinutes = meter
+	case usage.MetricVCPUHours:
+		a.vcpuHours = meter
+	default:
+		return fmt.Errorf("metric %s is not billed", metric)
+	}
+	a.updatedAt = time.Now()
+	return nil
+}
+
+// Getters
+
+func (a *Account) UserID() user.UserID {
+	return a.userID
+}
+
+func (a *Account) CustomerID() string {
+	return a.customerID
+}
+
+func (a *Account) SubscriptionID() string {
+	return a.subscriptionID
+}
+
+func (a *Account) Plan() string {
+	return a.plan
+}
+
+func (a *Account) Status() Status {
+	return a.status
+}
+
+func (a *Account) SuspendedAt() *time.Time {
+	return a.suspendedAt
+}
+
+func (a *Account) CreatedAt() time.Time {
+	return a.createdAt
+}
+
+func (a *Account) UpdatedAt() time.Time {
+	return a.updatedAt
+}
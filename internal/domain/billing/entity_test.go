@@ -0,0 +1,82 @@
+package billing_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/domain/billing"
+	"snapdeploy-core/internal/domain/usage"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestAccount_SuspendAndReinstate(t *testing.T) {
+	account, err := billing.NewAccount(user.NewUserID(), "cus_123")
+	if err != nil {
+		t.Fatalf("NewAccount() error = %v", err)
+	}
+
+	if !account.Suspend() || !account.IsSuspended() || account.SuspendedAt() == nil {
+		t.Fatal("Suspend() didn't suspend an active account")
+	}
+	if account.Suspend() {
+		t.Error("Suspend() = true for a suspended account, want false")
+	}
+
+	if !account.Reinstate() || account.IsSuspended() || account.SuspendedAt() != nil {
+		t.Fatal("Reinstate() didn't reactivate a suspended account")
+	}
+	if account.Reinstate() {
+		t.Error("Reinstate() = true for an active account, want false")
+	}
+}
+
+func TestNewAccount_RequiresCustomer(t *testing.T) {
+	if _, err := billing.NewAccount(user.NewUserID(), ""); err == nil {
+		t.Error("NewAccount() error = nil, want an error without a customer ID")
+	}
+}
+
+func TestAccount_Meter(t *testing.T) {
+	account, _ := billing.NewAccount(user.NewUserID(), "cus_123")
+	until := time.Now().Add(time.Hour)
+
+	if err := account.Reported(usage.MetricVCPUHours, until, 0.75); err != nil {
+		t.Fatalf("Reported() error = %v", err)
+	}
+	meter, err := account.Meter(usage.MetricVCPUHours)
+	if err != nil {
+		t.Fatalf("Meter() error = %v", err)
+	}
+	if !meter.ReportedUntil.Equal(until) || meter.Carry != 0.75 {
+		t.Errorf("Meter() = %+v, want reported until %v with 0.75 carried", meter, until)
+	}
+
+	if _, err := account.Meter(usage.MetricMemoryGBHours); err == nil {
+		t.Error("Meter(MEMORY_GB_HOURS) error = nil, want an error for a metric that isn't billed")
+	}
+}
+
+func TestMeter_Split(t *testing.T) {
+	tests := []struct {
+		name      string
+		carry     float64
+		quantity  float64
+		wantWhole int64
+		wantCarry float64
+	}{
+		{name: "whole units", quantity: 3, wantWhole: 3},
+		{name: "fraction carried", quantity: 2.5, wantWhole: 2, wantCarry: 0.5},
+		{name: "carry completes a unit", carry: 0.75, quantity: 0.5, wantWhole: 1, wantCarry: 0.25},
+		{name: "less than a unit", carry: 0.25, quantity: 0.5, wantWhole: 0, wantCarry: 0.75},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			whole, carry := billing.Meter{Carry: tt.carry}.Split(tt.quantity)
+			if whole != tt.wantWhole || math.Abs(carry-tt.wantCarry) > 1e-9 {
+				t.Errorf("Split(%v) = %d, %v, want %d, %v", tt.quantity, whole, carry, tt.wantWhole, tt.wantCarry)
+			}
+		})
+	}
+}
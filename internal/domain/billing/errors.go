@@ -0,0 +1,14 @@
+package billing
+
+import "errors"
+
+var (
+	// ErrAccountNotFound is returned when a user or Stripe customer has no billing account
+	ErrAccountNotFound = errors.New("billing account not found")
+
+	// ErrAccountSuspended is returned when a user whose payment failed starts a deployment
+	ErrAccountSuspended = errors.New("billing account is suspended until the overdue payment is made")
+
+	// ErrBillingDisabled is returned when no Stripe account is configured
+	ErrBillingDisabled = errors.New("billing is not configured")
+)
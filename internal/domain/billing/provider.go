@@ -0,0 +1,23 @@
+package billing
+
+import (
+	"context"
+	"time"
+
+	"snapdeploy-core/internal/domain/usage"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// Provider is the payment provider users are billed through
+type Provider interface {
+	// CreateCustomer creates a customer for a user and returns its ID
+	CreateCustomer(ctx context.Context, userID user.UserID, email, name string) (string, error)
+
+	// ReportUsage adds quantity units of a metric to a customer's meter. Reports with the same identifier are
+	// only counted once.
+	ReportUsage(ctx context.Context, customerID string, metric usage.Metric, quantity int64, identifier string, at time.Time) error
+
+	// CreatePortalSession returns a short-lived link to the page where a customer manages their subscription,
+	// payment methods and invoices
+	CreatePortalSession(ctx context.Context, customerID string) (string, error)
+}
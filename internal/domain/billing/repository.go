@@ -0,0 +1,25 @@
+package billing
+
+import (
+	"context"
+
+	"snapdeploy-core/internal/domain/user"
+)
+
+// AccountRepository defines the interface for billing account persistence
+type AccountRepository interface {
+	// FindByUserID retrieves the billing account of a user
+	FindByUserID(ctx context.Context, userID user.UserID) (*Account, error)
+
+	// FindByCustomerID retrieves the billing account of a Stripe customer
+	FindByCustomerID(ctx context.Context, customerID string) (*Account, error)
+
+	// List retrieves billing accounts with pagination, oldest first
+	List(ctx context.Context, limit, offset int32) ([]*Account, error)
+
+	// FindUnbilledUserIDs returns up to limit users without a billing account, oldest first
+	FindUnbilledUserIDs(ctx context.Context, limit int32) ([]user.UserID, error)
+
+	// Save persists a billing account (create or update)
+	Save(ctx context.Context, account *Account) error
+}
@@ -0,0 +1,46 @@
+package billing
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Status represents whether a billing account is in good standing
+type Status string
+
+const (
+	StatusActive    Status = "ACTIVE"
+	StatusSuspended Status = "SUSPENDED" // A payment failed, services are stopped until it is made
+)
+
+// NewStatus creates a new Status with validation
+func NewStatus(status string) (Status, error) {
+	status = strings.ToUpper(strings.TrimSpace(status))
+
+	switch Status(status) {
+	case StatusActive, StatusSuspended:
+		return Status(status), nil
+	default:
+		return "", fmt.Errorf("invalid billing status: %s (must be one of: ACTIVE, SUSPENDED)", status)
+	}
+}
+
+func (s Status) String() string {
+	return string(s)
+}
+
+// Meter tracks how much of a metric was reported to Stripe. Stripe meters whole units, so the fraction
+// left over is carried into the next report.
+type Meter struct {
+	ReportedUntil time.Time // Usage of periods ending up to this time was reported
+	Carry         float64   // Fraction of a unit not reported yet
+}
+
+// Split adds quantity to the carried fraction, returning the whole units to report and the fraction left
+func (m Meter) Split(quantity float64) (int64, float64) {
+	total := m.Carry + quantity
+	whole := math.Floor(total)
+	return int64(whole), total - whole
+}
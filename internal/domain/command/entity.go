@@ -0,0 +1,240 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+const (
+	// MaxLength is the longest command that can be run
+	MaxLength = 1024
+	// MaxOutputLines bounds the output kept for a run, its oldest lines are dropped past it
+	MaxOutputLines = 2000
+	// Timeout is how long a command may run before it is stopped
+	Timeout = 30 * time.Minute
+)
+
+// Run is a domain entity representing a one-off command run in the container of a project's environment,
+// kept as the audit record of who ran what
+type Run struct {
+	id           RunID
+	projectID    project.ProjectID
+	deploymentID deployment.DeploymentID // Deployment whose image and environment variables the command ran with
+	userID       user.UserID             // User who ran the command
+	environment  project.Environment
+	command      string
+	status       RunStatus
+	exitCode     *int
+	errorMessage string // Why the command failed
+	output       []string
+	redactor     deployment.LogRedactor
+	startedAt    time.Time
+	finishedAt   *time.Time
+}
+
+// NewRun starts a run of a command in the container of a project's deployment. The command is split on
+// whitespace into the container's command, it isn't run by a shell.
+func NewRun(proj *project.Project, dep *deployment.Deployment, userID user.UserID, command string) (*Run, error) {
+	if proj.Type() == project.TypeStatic || proj.DeploymentTarget() == project.TargetLambda {
+		return nil, ErrUnsupportedProject
+	}
+
+	command = strings.TrimSpace(command)
+	if command == "" || len(command) > MaxLength || strings.ContainsFunc(command, unicode.IsControl) {
+		return nil, ErrInvalidCommand
+	}
+
+	return &Run{
+		id:           NewRunID(),
+		projectID:    proj.ID(),
+		deploymentID: dep.ID(),
+		userID:       userID,
+		environment:  dep.Environment(),
+		command:      command,
+		status:       StatusRunning,
+		startedAt:    time.Now(),
+	}, nil
+}
+
+// ReconstituteRun recreates a Run entity from persistence
+func ReconstituteRun(
+	id, projectID, deploymentID, userID, environment, command, status string,
+	exitCode *int,
+	errorMessage, output string,
+	startedAt time.Time,
+	finishedAt *time.Time,
+) (*Run, error) {
+	runID, err := ParseRunID(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid command run ID: %w", err)
+	}
+
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	did, err := deployment.ParseDeploymentID(deploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment ID: %w", err)
+	}
+
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	env, err := project.NewEnvironment(environment)
+	if err != nil {
+		return nil, err
+	}
+
+	runStatus, err := NewRunStatus(status)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	if output != "" {
+		lines = strings.Split(output, "\n")
+	}
+
+	return &Run{
+		id:           runID,
+		projectID:    pid,
+		deploymentID: did,
+		userID:       uid,
+		environment:  env,
+		command:      command,
+		status:       runStatus,
+		exitCode:     exitCode,
+		errorMessage: errorMessage,
+		output:       lines,
+		startedAt:    startedAt,
+		finishedAt:   finishedAt,
+	}, nil
+}
+
+// Args returns the command split into the arguments the container runs
+func (r *Run) Args() []string {
+	return strings.Fields(r.command)
+}
+
+// MaskInOutput masks values in the output appended from now on, such as the project's environment variables
+func (r *Run) MaskInOutput(values ...string) {
+	r.redactor.Add(values...)
+}
+
+// AppendOutput records lines the command wrote, with their secrets masked, and returns them as recorded
+func (r *Run) AppendOutput(lines ...string) []string {
+	recorded := make([]string, len(lines))
+	for i, line := range lines {
+		recorded[i] = r.redactor.Redact(line)
+	}
+
+	r.output = append(r.output, recorded...)
+	if len(r.output) > MaxOutputLines {
+		r.output = r.output[len(r.output)-MaxOutputLines:]
+	}
+	return recorded
+}
+
+// Finish records that the command exited, succeeded only if it exited with 0
+func (r *Run) Finish(exitCode int) {
+	now := time.Now()
+	r.finishedAt = &now
+	r.exitCode = &exitCode
+	if exitCode == 0 {
+		r.status = StatusSucceeded
+		return
+	}
+	r.status = StatusFailed
+	r.errorMessage = fmt.Sprintf("Command exited with code %d", exitCode)
+}
+
+// Fail records that the command couldn't be run or didn't finish in time
+func (r *Run) Fail(err error) {
+	now := time.Now()
+	r.finishedAt = &now
+	r.status = StatusFailed
+	r.errorMessage = r.redactor.Redact(err.Error())
+}
+
+// Outcome describes how the run ended, for the last line of its output
+func (r *Run) Outcome() string {
+	switch {
+	case r.status == StatusSucceeded:
+		return "✅ Command exited with code 0"
+	case r.status == StatusFailed && r.exitCode != nil:
+		return fmt.Sprintf("❌ Command exited with code %d", *r.exitCode)
+	case r.status == StatusFailed:
+		return "❌ " + r.errorMessage
+	default:
+		return ""
+	}
+}
+
+// Duration returns how long the run took, or has been running for
+func (r *Run) Duration(now time.Time) time.Duration {
+	if r.finishedAt != nil {
+		return r.finishedAt.Sub(r.startedAt)
+	}
+	return now.Sub(r.startedAt)
+}
+
+// Getters
+
+func (r *Run) ID() RunID {
+	return r.id
+}
+
+func (r *Run) ProjectID() project.ProjectID {
+	return r.projectID
+}
+
+func (r *Run) DeploymentID() deployment.DeploymentID {
+	return r.deploymentID
+}
+
+func (r *Run) UserID() user.UserID {
+	return r.userID
+}
+
+func (r *Run) Environment() project.Environment {
+	return r.environment
+}
+
+func (r *Run) Command() string {
+	return r.command
+}
+
+func (r *Run) Status() RunStatus {
+	return r.status
+}
+
+func (r *Run) ExitCode() *int {
+	return r.exitCode
+}
+
+func (r *Run) ErrorMessage() string {
+	return r.errorMessage
+}
+
+// Output returns the lines the command wrote, oldest first
+func (r *Run) Output() []string {
+	return append([]string(nil), r.output...)
+}
+
+func (r *Run) StartedAt() time.Time {
+	return r.startedAt
+}
+
+func (r *Run) FinishedAt() *time.Time {
+	return r.finishedAt
+}
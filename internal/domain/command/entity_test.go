@@ -0,0 +1,116 @@
+package command_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"snapdeploy-core/internal/domain/command"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func newDeployedProject(t *testing.T) (*project.Project, *deployment.Deployment) {
+	t.Helper()
+	owner := user.NewUserID()
+	proj, err := project.NewProject(owner, "https://github.com/acme/app", "pip install -r requirements.txt", "", "gunicorn app:app", "PYTHON", "", true, "")
+	if err != nil {
+		t.Fatalf("NewProject() error = %v", err)
+	}
+	dep, err := deployment.NewDeployment(proj.ID(), owner, "abc123def456", "main", project.EnvironmentProduction)
+	if err != nil {
+		t.Fatalf("NewDeployment() error = %v", err)
+	}
+	return proj, dep
+}
+
+func TestNewRun(t *testing.T) {
+	proj, dep := newDeployedProject(t)
+
+	run, err := command.NewRun(proj, dep, user.NewUserID(), "  python manage.py  loaddata seed.json ")
+	if err != nil {
+		t.Fatalf("NewRun() error = %v", err)
+	}
+	if got := strings.Join(run.Args(), "|"); got != "python|manage.py|loaddata|seed.json" {
+		t.Errorf("Args() = %q, want the command split on whitespace", got)
+	}
+	if run.Status() != command.StatusRunning || !run.DeploymentID().Equals(dep.ID()) {
+		t.Errorf("run = %v of %v, want it running with the deployment's image", run.Status(), run.DeploymentID())
+	}
+
+	for _, invalid := range []string{"", "   ", "python manage.py migrate\nrm -rf /", strings.Repeat("x", command.MaxLength+1)} {
+		if _, err := command.NewRun(proj, dep, user.NewUserID(), invalid); !errors.Is(err, command.ErrInvalidCommand) {
+			t.Errorf("NewRun(%.20q) error = %v, want ErrInvalidCommand", invalid, err)
+		}
+	}
+
+	if err := proj.SetType("STATIC"); err != nil {
+		t.Fatalf("SetType(STATIC) error = %v", err)
+	}
+	if _, err := command.NewRun(proj, dep, user.NewUserID(), "ls"); !errors.Is(err, command.ErrUnsupportedProject) {
+		t.Errorf("NewRun() in a STATIC project error = %v, want ErrUnsupportedProject", err)
+	}
+}
+
+func TestRunOutput(t *testing.T) {
+	proj, dep := newDeployedProject(t)
+	run, err := command.NewRun(proj, dep, user.NewUserID(), "python manage.py loaddata seed.json")
+	if err != nil {
+		t.Fatalf("NewRun() error = %v", err)
+	}
+
+	run.MaskInOutput("s3cr3t-database-password")
+	recorded := run.AppendOutput("Connecting with s3cr3t-database-password", "Seeded 12 users")
+	if recorded[0] != "Connecting with ***" {
+		t.Errorf("AppendOutput() = %q, want the secret masked", recorded[0])
+	}
+
+	for i := 0; i < command.MaxOutputLines; i++ {
+		run.AppendOutput(fmt.Sprintf("line %d", i))
+	}
+	output := run.Output()
+	if len(output) != command.MaxOutputLines || output[len(output)-1] != fmt.Sprintf("line %d", command.MaxOutputLines-1) {
+		t.Errorf("Output() kept %d lines ending with %q, want the last %d", len(output), output[len(output)-1], command.MaxOutputLines)
+	}
+}
+
+func TestRunFinish(t *testing.T) {
+	proj, dep := newDeployedProject(t)
+
+	tests := []struct {
+		name        string
+		finish      func(run *command.Run)
+		wantStatus  command.RunStatus
+		wantOutcome string
+	}{
+		{name: "exited cleanly", finish: func(run *command.Run) { run.Finish(0) }, wantStatus: command.StatusSucceeded, wantOutcome: "✅ Command exited with code 0"},
+		{name: "exited with error", finish: func(run *command.Run) { run.Finish(1) }, wantStatus: command.StatusFailed, wantOutcome: "❌ Command exited with code 1"},
+		{
+			name:        "never ran",
+			finish:      func(run *command.Run) { run.Fail(errors.New("no tasks were started: RESOURCE:MEMORY")) },
+			wantStatus:  command.StatusFailed,
+			wantOutcome: "❌ no tasks were started: RESOURCE:MEMORY",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run, err := command.NewRun(proj, dep, user.NewUserID(), "python manage.py loaddata seed.json")
+			if err != nil {
+				t.Fatalf("NewRun() error = %v", err)
+			}
+			tt.finish(run)
+			if run.Status() != tt.wantStatus {
+				t.Errorf("Status() = %v, want %v", run.Status(), tt.wantStatus)
+			}
+			if run.Outcome() != tt.wantOutcome {
+				t.Errorf("Outcome() = %q, want %q", run.Outcome(), tt.wantOutcome)
+			}
+			if run.FinishedAt() == nil {
+				t.Error("FinishedAt() = nil, want the run finished")
+			}
+		})
+	}
+}
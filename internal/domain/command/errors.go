@@ -0,0 +1,18 @@
+package command
+
+import (
+	"errors"
+
+	"snapdeploy-core/internal/domain/validation"
+)
+
+var (
+	// ErrRunNotFound is returned when a command run is not found
+	ErrRunNotFound = errors.New("command run not found")
+
+	// ErrInvalidCommand is returned when a command is empty, too long or spans several lines
+	ErrInvalidCommand = validation.New("command must be a single line of at most 1024 characters")
+
+	// ErrUnsupportedProject is returned when a project doesn't run in a container commands can run in
+	ErrUnsupportedProject = errors.New("commands can only run in projects deployed as ECS containers, not STATIC or LAMBDA projects")
+)
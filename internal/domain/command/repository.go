@@ -0,0 +1,19 @@
+package command
+
+import (
+	"context"
+
+	"snapdeploy-core/internal/domain/project"
+)
+
+// RunRepository defines the interface for command run persistence
+type RunRepository interface {
+	// Save persists a run, replacing its recorded state and output
+	Save(ctx context.Context, run *Run) error
+
+	// FindByID retrieves a run by its ID
+	FindByID(ctx context.Context, id RunID) (*Run, error)
+
+	// FindByProjectID retrieves the most recent runs of a project, newest first
+	FindByProjectID(ctx context.Context, projectID project.ProjectID, limit int) ([]*Run, error)
+}
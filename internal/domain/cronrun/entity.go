@@ -0,0 +1,162 @@
+package cronrun
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+)
+
+// TaskReport is what ECS reports about a task a CRON project's schedule started
+type TaskReport struct {
+	TaskARN      string
+	DeploymentID string // Deployment whose task definition the task runs, read from the task's tags
+	Stopped      bool
+	ExitCode     *int // Exit code of the project's container, nil while running or if it never started
+	Reason       string
+	StartedAt    time.Time
+	StoppedAt    *time.Time
+}
+
+// Run is a domain entity representing one scheduled run of a CRON project
+type Run struct {
+	id           RunID
+	projectID    project.ProjectID
+	deploymentID deployment.DeploymentID
+	taskARN      string
+	status       RunStatus
+	exitCode     *int
+	reason       string // Why the task stopped, if it failed
+	startedAt    time.Time
+	stoppedAt    *time.Time
+}
+
+// NewRun records a run of a deployment from what ECS reports about its task
+func NewRun(projectID project.ProjectID, deploymentID deployment.DeploymentID, report TaskReport) *Run {
+	run := &Run{
+		id:           NewRunID(),
+		projectID:    projectID,
+		deploymentID: deploymentID,
+		taskARN:      report.TaskARN,
+	}
+	run.Update(report)
+	return run
+}
+
+// ReconstituteRun recreates a Run entity from persistence
+func ReconstituteRun(
+	id, projectID, deploymentID, taskARN, status string,
+	exitCode *int,
+	reason string,
+	startedAt time.Time,
+	stoppedAt *time.Time,
+) (*Run, error) {
+	runID, err := ParseRunID(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid run ID: %w", err)
+	}
+
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	did, err := deployment.ParseDeploymentID(deploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment ID: %w", err)
+	}
+
+	runStatus, err := NewRunStatus(status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Run{
+		id:           runID,
+		projectID:    pid,
+		deploymentID: did,
+		taskARN:      taskARN,
+		status:       runStatus,
+		exitCode:     exitCode,
+		reason:       reason,
+		startedAt:    startedAt,
+		stoppedAt:    stoppedAt,
+	}, nil
+}
+
+// Update records the latest state ECS reports for the run's task. A stopped task succeeded
+// only if its container exited with 0.
+func (r *Run) Update(report TaskReport) {
+	r.startedAt = report.StartedAt
+	if !report.Stopped {
+		r.status = StatusRunning
+		return
+	}
+
+	r.exitCode = report.ExitCode
+	r.stoppedAt = report.StoppedAt
+	if report.ExitCode != nil && *report.ExitCode == 0 {
+		r.status = StatusSucceeded
+		r.reason = ""
+		return
+	}
+
+	r.status = StatusFailed
+	r.reason = report.Reason
+	if r.reason == "" && report.ExitCode != nil {
+		r.reason = fmt.Sprintf("Container exited with code %d", *report.ExitCode)
+	}
+}
+
+// TaskID returns the ID of the run's task, the last part of its ARN
+func (r *Run) TaskID() string {
+	return r.taskARN[strings.LastIndex(r.taskARN, "/")+1:]
+}
+
+// Duration returns how long the run took, or has been running for
+func (r *Run) Duration(now time.Time) time.Duration {
+	if r.stoppedAt != nil {
+		return r.stoppedAt.Sub(r.startedAt)
+	}
+	return now.Sub(r.startedAt)
+}
+
+// Getters
+
+func (r *Run) ID() RunID {
+	return r.id
+}
+
+func (r *Run) ProjectID() project.ProjectID {
+	return r.projectID
+}
+
+func (r *Run) DeploymentID() deployment.DeploymentID {
+	return r.deploymentID
+}
+
+func (r *Run) TaskARN() string {
+	return r.taskARN
+}
+
+func (r *Run) Status() RunStatus {
+	return r.status
+}
+
+func (r *Run) ExitCode() *int {
+	return r.exitCode
+}
+
+func (r *Run) Reason() string {
+	return r.reason
+}
+
+func (r *Run) StartedAt() time.Time {
+	return r.startedAt
+}
+
+func (r *Run) StoppedAt() *time.Time {
+	return r.stoppedAt
+}
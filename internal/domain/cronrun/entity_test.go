@@ -0,0 +1,59 @@
+package cronrun_test
+
+import (
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/domain/cronrun"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+)
+
+func TestRunUpdate(t *testing.T) {
+	started := time.Date(2025, 11, 28, 3, 0, 0, 0, time.UTC)
+	stopped := started.Add(90 * time.Second)
+	zero, one := 0, 1
+
+	tests := []struct {
+		name       string
+		report     cronrun.TaskReport
+		wantStatus cronrun.RunStatus
+		wantReason string
+	}{
+		{name: "running", report: cronrun.TaskReport{}, wantStatus: cronrun.StatusRunning},
+		{name: "exited cleanly", report: cronrun.TaskReport{Stopped: true, ExitCode: &zero, StoppedAt: &stopped}, wantStatus: cronrun.StatusSucceeded},
+		{
+			name:       "exited with error",
+			report:     cronrun.TaskReport{Stopped: true, ExitCode: &one, StoppedAt: &stopped},
+			wantStatus: cronrun.StatusFailed,
+			wantReason: "Container exited with code 1",
+		},
+		{
+			name:       "never started",
+			report:     cronrun.TaskReport{Stopped: true, Reason: "CannotPullContainerError", StoppedAt: &stopped},
+			wantStatus: cronrun.StatusFailed,
+			wantReason: "CannotPullContainerError",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.report.TaskARN = "arn:aws:ecs:us-east-1:123456789012:task/snapdeploy-cluster/0f1e2d3c4b5a"
+			tt.report.StartedAt = started
+
+			run := cronrun.NewRun(project.NewProjectID(), deployment.NewDeploymentID(), tt.report)
+			if run.Status() != tt.wantStatus {
+				t.Errorf("Status() = %v, want %v", run.Status(), tt.wantStatus)
+			}
+			if run.Reason() != tt.wantReason {
+				t.Errorf("Reason() = %q, want %q", run.Reason(), tt.wantReason)
+			}
+			if run.Status().IsTerminal() && run.Duration(time.Now()) != 90*time.Second {
+				t.Errorf("Duration() = %v, want %v", run.Duration(time.Now()), 90*time.Second)
+			}
+			if run.TaskID() != "0f1e2d3c4b5a" {
+				t.Errorf("TaskID() = %q, want %q", run.TaskID(), "0f1e2d3c4b5a")
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package cronrun
+
+import "errors"
+
+var (
+	// ErrRunNotFound is returned when a run is not found
+	ErrRunNotFound = errors.New("run not found")
+)
@@ -0,0 +1,22 @@
+package cronrun
+
+import (
+	"context"
+
+	"snapdeploy-core/internal/domain/deployment"
+)
+
+// RunRepository defines the interface for run persistence
+type RunRepository interface {
+	// Save persists a run, replacing the recorded state of its task
+	Save(ctx context.Context, run *Run) error
+
+	// FindByID retrieves a run by its ID
+	FindByID(ctx context.Context, id RunID) (*Run, error)
+
+	// FindByTaskARN retrieves the run of an ECS task
+	FindByTaskARN(ctx context.Context, taskARN string) (*Run, error)
+
+	// FindByDeploymentID retrieves the most recent runs of a deployment, newest first
+	FindByDeploymentID(ctx context.Context, deploymentID deployment.DeploymentID, limit int) ([]*Run, error)
+}
@@ -0,0 +1,68 @@
+package cronrun
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"snapdeploy-core/internal/domain/validation"
+)
+
+// RunID is a value object representing a run's unique identifier
+type RunID struct {
+	value uuid.UUID
+}
+
+// NewRunID creates a new RunID
+func NewRunID() RunID {
+	return RunID{value: uuid.New()}
+}
+
+// ParseRunID parses a string into a RunID
+func ParseRunID(id string) (RunID, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return RunID{}, validation.Errorf("invalid run ID format: %w", err)
+	}
+	return RunID{value: uid}, nil
+}
+
+func (id RunID) String() string {
+	return id.value.String()
+}
+
+func (id RunID) UUID() uuid.UUID {
+	return id.value
+}
+
+func (id RunID) Equals(other RunID) bool {
+	return id.value == other.value
+}
+
+// RunStatus represents how a scheduled run went
+type RunStatus string
+
+const (
+	StatusRunning   RunStatus = "RUNNING"
+	StatusSucceeded RunStatus = "SUCCEEDED"
+	StatusFailed    RunStatus = "FAILED"
+)
+
+// NewRunStatus creates a new RunStatus with validation
+func NewRunStatus(status string) (RunStatus, error) {
+	switch RunStatus(status) {
+	case StatusRunning, StatusSucceeded, StatusFailed:
+		return RunStatus(status), nil
+	default:
+		return "", fmt.Errorf("invalid run status: %s", status)
+	}
+}
+
+func (s RunStatus) String() string {
+	return string(s)
+}
+
+// IsTerminal checks if the run has finished
+func (s RunStatus) IsTerminal() bool {
+	return s != StatusRunning
+}
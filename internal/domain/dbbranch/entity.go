@@ -0,0 +1,230 @@
+package dbbranch
+
+import (
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/domain/project"
+)
+
+const (
+	// DefaultSnapshotTTL is how long a snapshot is kept when no TTL is given
+	DefaultSnapshotTTL = 7 * 24 * time.Hour
+	// DefaultBranchTTL is how long a branch is kept when no TTL is given
+	DefaultBranchTTL = 72 * time.Hour
+	// MaxTTL is the longest a snapshot or branch can be kept
+	MaxTTL = 30 * 24 * time.Hour
+
+	// MaxSnapshotsPerProject and MaxBranchesPerProject bound the databases a project can copy its own into
+	MaxSnapshotsPerProject = 10
+	MaxBranchesPerProject  = 10
+)
+
+// Snapshot is a domain entity representing a frozen copy of a project's database that branches are restored from
+type Snapshot struct {
+	id           SnapshotID
+	projectID    project.ProjectID
+	databaseName string
+	sizeBytes    int64
+	createdAt    time.Time
+	expiresAt    time.Time
+}
+
+// NewSnapshot creates a snapshot of a project's database, kept for the given TTL (zero means the default)
+func NewSnapshot(projectID project.ProjectID, ttl time.Duration) (*Snapshot, error) {
+	ttl, err := validateTTL(ttl, DefaultSnapshotTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Snapshot{
+		id:        NewSnapshotID(),
+		projectID: projectID,
+		createdAt: now,
+		expiresAt: now.Add(ttl),
+	}, nil
+}
+
+// ReconstituteSnapshot recreates a Snapshot entity from persistence
+func ReconstituteSnapshot(id, projectID, databaseName string, sizeBytes int64, createdAt, expiresAt time.Time) (*Snapshot, error) {
+	snapshotID, err := ParseSnapshotID(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot ID: %w", err)
+	}
+
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	return &Snapshot{
+		id:           snapshotID,
+		projectID:    pid,
+		databaseName: databaseName,
+		sizeBytes:    sizeBytes,
+		createdAt:    createdAt,
+		expiresAt:    expiresAt,
+	}, nil
+}
+
+// Taken records the database the snapshot was copied to
+func (s *Snapshot) Taken(databaseName string, sizeBytes int64) {
+	s.databaseName = databaseName
+	s.sizeBytes = sizeBytes
+}
+
+// IsExpired checks if the snapshot's TTL has run out
+func (s *Snapshot) IsExpired(now time.Time) bool {
+	return !now.Before(s.expiresAt)
+}
+
+// Getters
+
+func (s *Snapshot) ID() SnapshotID {
+	return s.id
+}
+
+func (s *Snapshot) ProjectID() project.ProjectID {
+	return s.projectID
+}
+
+func (s *Snapshot) DatabaseName() string {
+	return s.databaseName
+}
+
+func (s *Snapshot) SizeBytes() int64 {
+	return s.sizeBytes
+}
+
+func (s *Snapshot) CreatedAt() time.Time {
+	return s.createdAt
+}
+
+func (s *Snapshot) ExpiresAt() time.Time {
+	return s.expiresAt
+}
+
+// Branch is a domain entity representing an ephemeral database restored from a snapshot,
+// such as the database of a preview environment
+type Branch struct {
+	id           BranchID
+	projectID    project.ProjectID
+	snapshotID   *SnapshotID // Nil once the snapshot has been deleted
+	name         BranchName
+	databaseName string
+	createdAt    time.Time
+	expiresAt    time.Time
+}
+
+// NewBranch creates a branch of a snapshot, kept for the given TTL (zero means the default)
+func NewBranch(snapshot *Snapshot, name string, ttl time.Duration) (*Branch, error) {
+	branchName, err := NewBranchName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl, err = validateTTL(ttl, DefaultBranchTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotID := snapshot.ID()
+	now := time.Now()
+	return &Branch{
+		id:         NewBranchID(),
+		projectID:  snapshot.ProjectID(),
+		snapshotID: &snapshotID,
+		name:       branchName,
+		createdAt:  now,
+		expiresAt:  now.Add(ttl),
+	}, nil
+}
+
+// ReconstituteBranch recreates a Branch entity from persistence
+func ReconstituteBranch(id, projectID string, snapshotID *string, name, databaseName string, createdAt, expiresAt time.Time) (*Branch, error) {
+	branchID, err := ParseBranchID(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid branch ID: %w", err)
+	}
+
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	var sid *SnapshotID
+	if snapshotID != nil {
+		parsed, err := ParseSnapshotID(*snapshotID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot ID: %w", err)
+		}
+		sid = &parsed
+	}
+
+	branchName, err := NewBranchName(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid branch name: %w", err)
+	}
+
+	return &Branch{
+		id:           branchID,
+		projectID:    pid,
+		snapshotID:   sid,
+		name:         branchName,
+		databaseName: databaseName,
+		createdAt:    createdAt,
+		expiresAt:    expiresAt,
+	}, nil
+}
+
+// Restored records the database the snapshot was restored into
+func (b *Branch) Restored(databaseName string) {
+	b.databaseName = databaseName
+}
+
+// IsExpired checks if the branch's TTL has run out
+func (b *Branch) IsExpired(now time.Time) bool {
+	return !now.Before(b.expiresAt)
+}
+
+// Getters
+
+func (b *Branch) ID() BranchID {
+	return b.id
+}
+
+func (b *Branch) ProjectID() project.ProjectID {
+	return b.projectID
+}
+
+func (b *Branch) SnapshotID() *SnapshotID {
+	return b.snapshotID
+}
+
+func (b *Branch) Name() BranchName {
+	return b.name
+}
+
+func (b *Branch) DatabaseName() string {
+	return b.databaseName
+}
+
+func (b *Branch) CreatedAt() time.Time {
+	return b.createdAt
+}
+
+func (b *Branch) ExpiresAt() time.Time {
+	return b.expiresAt
+}
+
+// validateTTL returns the TTL to keep a snapshot or branch for, using the default for zero
+func validateTTL(ttl, defaultTTL time.Duration) (time.Duration, error) {
+	if ttl == 0 {
+		return defaultTTL, nil
+	}
+	if ttl < time.Hour || ttl > MaxTTL {
+		return 0, ErrInvalidTTL
+	}
+	return ttl, nil
+}
@@ -0,0 +1,102 @@
+package dbbranch_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/domain/dbbranch"
+	"snapdeploy-core/internal/domain/project"
+)
+
+func TestNewSnapshot_TTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		want    time.Duration
+		wantErr error
+	}{
+		{name: "default", ttl: 0, want: dbbranch.DefaultSnapshotTTL},
+		{name: "custom", ttl: 12 * time.Hour, want: 12 * time.Hour},
+		{name: "maximum", ttl: dbbranch.MaxTTL, want: dbbranch.MaxTTL},
+		{name: "too short", ttl: time.Minute, wantErr: dbbranch.ErrInvalidTTL},
+		{name: "too long", ttl: dbbranch.MaxTTL + time.Hour, wantErr: dbbranch.ErrInvalidTTL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot, err := dbbranch.NewSnapshot(project.NewProjectID(), tt.ttl)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("NewSnapshot() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewSnapshot() error = %v", err)
+			}
+			if got := snapshot.ExpiresAt().Sub(snapshot.CreatedAt()); got != tt.want {
+				t.Errorf("TTL = %v, want %v", got, tt.want)
+			}
+			if snapshot.IsExpired(snapshot.CreatedAt()) {
+				t.Error("IsExpired() = true for a new snapshot")
+			}
+			if !snapshot.IsExpired(snapshot.ExpiresAt()) {
+				t.Error("IsExpired() = false once the TTL has run out")
+			}
+		})
+	}
+}
+
+func TestNewBranch(t *testing.T) {
+	snapshot, err := dbbranch.NewSnapshot(project.NewProjectID(), 0)
+	if err != nil {
+		t.Fatalf("NewSnapshot() error = %v", err)
+	}
+
+	branch, err := dbbranch.NewBranch(snapshot, "PR-42", 0)
+	if err != nil {
+		t.Fatalf("NewBranch() error = %v", err)
+	}
+	if branch.Name().String() != "pr-42" {
+		t.Errorf("Name() = %q, want %q", branch.Name().String(), "pr-42")
+	}
+	if !branch.ProjectID().Equals(snapshot.ProjectID()) {
+		t.Error("branch does not belong to the snapshot's project")
+	}
+	if branch.SnapshotID() == nil || !branch.SnapshotID().Equals(snapshot.ID()) {
+		t.Error("branch does not reference its snapshot")
+	}
+	if got := branch.ExpiresAt().Sub(branch.CreatedAt()); got != dbbranch.DefaultBranchTTL {
+		t.Errorf("TTL = %v, want %v", got, dbbranch.DefaultBranchTTL)
+	}
+}
+
+func TestNewBranchName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "pull request", input: "pr-42"},
+		{name: "single character", input: "a"},
+		{name: "uppercase", input: "Feature-Login"},
+		{name: "empty", input: "", wantErr: true},
+		{name: "leading hyphen", input: "-pr", wantErr: true},
+		{name: "trailing hyphen", input: "pr-", wantErr: true},
+		{name: "underscore", input: "pr_42", wantErr: true},
+		{name: "too long", input: "a234567890123456789012345678901234567890a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := dbbranch.NewBranchName(tt.input)
+			if tt.wantErr && !errors.Is(err, dbbranch.ErrInvalidBranchName) {
+				t.Errorf("NewBranchName(%q) error = %v, want %v", tt.input, err, dbbranch.ErrInvalidBranchName)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("NewBranchName(%q) error = %v", tt.input, err)
+			}
+		})
+	}
+}
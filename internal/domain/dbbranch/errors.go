@@ -0,0 +1,30 @@
+package dbbranch
+
+import (
+	"errors"
+
+	"snapdeploy-core/internal/domain/validation"
+)
+
+var (
+	// ErrSnapshotNotFound is returned when a snapshot is not found
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+
+	// ErrBranchNotFound is returned when a branch is not found
+	ErrBranchNotFound = errors.New("branch not found")
+
+	// ErrBranchAlreadyExists is returned when a project already has a branch with the same name
+	ErrBranchAlreadyExists = errors.New("branch with this name already exists")
+
+	// ErrInvalidBranchName is returned when a branch name is not a valid DNS label
+	ErrInvalidBranchName = validation.New("branch name must be 1-40 lowercase letters, digits or hyphens, starting and ending with a letter or digit")
+
+	// ErrInvalidTTL is returned when a snapshot or branch would be kept for longer than allowed
+	ErrInvalidTTL = validation.New("ttl must be between 1 and 720 hours")
+
+	// ErrSnapshotLimitReached is returned when a project has as many snapshots as it may keep
+	ErrSnapshotLimitReached = errors.New("project has reached its snapshot limit")
+
+	// ErrBranchLimitReached is returned when a project has as many branches as it may keep
+	ErrBranchLimitReached = errors.New("project has reached its branch limit")
+)
@@ -0,0 +1,44 @@
+package dbbranch
+
+import (
+	"context"
+	"time"
+
+	"snapdeploy-core/internal/domain/project"
+)
+
+// SnapshotRepository defines the interface for snapshot persistence
+type SnapshotRepository interface {
+	// Save persists a new snapshot
+	Save(ctx context.Context, snapshot *Snapshot) error
+
+	// FindByID retrieves a snapshot by its ID
+	FindByID(ctx context.Context, id SnapshotID) (*Snapshot, error)
+
+	// FindByProjectID retrieves the snapshots of a project, newest first
+	FindByProjectID(ctx context.Context, projectID project.ProjectID) ([]*Snapshot, error)
+
+	// FindExpired retrieves snapshots that expired before the given time
+	FindExpired(ctx context.Context, before time.Time) ([]*Snapshot, error)
+
+	// Delete removes a snapshot
+	Delete(ctx context.Context, id SnapshotID) error
+}
+
+// BranchRepository defines the interface for branch persistence
+type BranchRepository interface {
+	// Save persists a new branch
+	Save(ctx context.Context, branch *Branch) error
+
+	// FindByID retrieves a branch by its ID
+	FindByID(ctx context.Context, id BranchID) (*Branch, error)
+
+	// FindByProjectID retrieves the branches of a project, newest first
+	FindByProjectID(ctx context.Context, projectID project.ProjectID) ([]*Branch, error)
+
+	// FindExpired retrieves branches that expired before the given time
+	FindExpired(ctx context.Context, before time.Time) ([]*Branch, error)
+
+	// Delete removes a branch
+	Delete(ctx context.Context, id BranchID) error
+}
@@ -0,0 +1,93 @@
+package dbbranch
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"snapdeploy-core/internal/domain/validation"
+)
+
+// SnapshotID is a value object representing a snapshot's unique identifier
+type SnapshotID struct {
+	value uuid.UUID
+}
+
+// NewSnapshotID creates a new SnapshotID
+func NewSnapshotID() SnapshotID {
+	return SnapshotID{value: uuid.New()}
+}
+
+// ParseSnapshotID parses a string into a SnapshotID
+func ParseSnapshotID(id string) (SnapshotID, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return SnapshotID{}, validation.Errorf("invalid snapshot ID format: %w", err)
+	}
+	return SnapshotID{value: uid}, nil
+}
+
+func (id SnapshotID) String() string {
+	return id.value.String()
+}
+
+func (id SnapshotID) UUID() uuid.UUID {
+	return id.value
+}
+
+func (id SnapshotID) Equals(other SnapshotID) bool {
+	return id.value == other.value
+}
+
+// BranchID is a value object representing a branch's unique identifier
+type BranchID struct {
+	value uuid.UUID
+}
+
+// NewBranchID creates a new BranchID
+func NewBranchID() BranchID {
+	return BranchID{value: uuid.New()}
+}
+
+// ParseBranchID parses a string into a BranchID
+func ParseBranchID(id string) (BranchID, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return BranchID{}, validation.Errorf("invalid branch ID format: %w", err)
+	}
+	return BranchID{value: uid}, nil
+}
+
+func (id BranchID) String() string {
+	return id.value.String()
+}
+
+func (id BranchID) UUID() uuid.UUID {
+	return id.value
+}
+
+func (id BranchID) Equals(other BranchID) bool {
+	return id.value == other.value
+}
+
+// branchNamePattern allows the names preview environments are given, such as pr-42 or feature-login
+var branchNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,38}[a-z0-9])?$`)
+
+// BranchName is a value object representing the name a branch is known by within its project
+type BranchName struct {
+	value string
+}
+
+// NewBranchName creates a new BranchName with validation
+func NewBranchName(name string) (BranchName, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !branchNamePattern.MatchString(name) {
+		return BranchName{}, ErrInvalidBranchName
+	}
+	return BranchName{value: name}, nil
+}
+
+func (n BranchName) String() string {
+	return n.value
+}
@@ -0,0 +1,68 @@
+package deployment
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits of the annotations of a deployment
+const (
+	MaxTitleLength       = 120
+	MaxDescriptionLength = 5000
+	MaxLabels            = 20
+	MaxLabelKeyLength    = 63
+	MaxLabelValueLength  = 255
+)
+
+// labelKeyPattern matches label keys such as release, team or app.kubernetes.io/version
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]*[A-Za-z0-9])?$`)
+
+// Annotations describe what a deployment ships for the people looking at it, such as
+// "v2.3.0 – pricing page launch", beyond its commit
+type Annotations struct {
+	Title       string
+	Description string            // Release notes
+	Labels      map[string]string // Deployments can be listed by them
+}
+
+// NewAnnotations creates the annotations of a deployment with validation
+func NewAnnotations(title, description string, labels map[string]string) (Annotations, error) {
+	title = strings.TrimSpace(title)
+	if strings.ContainsAny(title, "\r\n") {
+		return Annotations{}, fmt.Errorf("%w: title must be a single line", ErrInvalidAnnotations)
+	}
+	if utf8.RuneCountInString(title) > MaxTitleLength {
+		return Annotations{}, fmt.Errorf("%w: title is longer than %d characters", ErrInvalidAnnotations, MaxTitleLength)
+	}
+
+	description = strings.TrimSpace(description)
+	if utf8.RuneCountInString(description) > MaxDescriptionLength {
+		return Annotations{}, fmt.Errorf("%w: description is longer than %d characters", ErrInvalidAnnotations, MaxDescriptionLength)
+	}
+
+	if len(labels) > MaxLabels {
+		return Annotations{}, fmt.Errorf("%w: at most %d labels are allowed", ErrInvalidAnnotations, MaxLabels)
+	}
+	for key, value := range labels {
+		if len(key) > MaxLabelKeyLength || !labelKeyPattern.MatchString(key) {
+			return Annotations{}, fmt.Errorf("%w: label key %q must be up to %d letters, digits, '.', '_', '/' or '-', starting and ending with a letter or digit", ErrInvalidAnnotations, key, MaxLabelKeyLength)
+		}
+		if utf8.RuneCountInString(value) > MaxLabelValueLength || strings.ContainsAny(value, "\r\n") {
+			return Annotations{}, fmt.Errorf("%w: value of label %s must be a single line of up to %d characters", ErrInvalidAnnotations, key, MaxLabelValueLength)
+		}
+	}
+
+	return Annotations{
+		Title:       title,
+		Description: description,
+		Labels:      maps.Clone(labels),
+	}, nil
+}
+
+// IsZero reports whether the deployment is not annotated
+func (a Annotations) IsZero() bool {
+	return a.Title == "" && a.Description == "" && len(a.Labels) == 0
+}
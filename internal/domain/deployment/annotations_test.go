@@ -0,0 +1,74 @@
+package deployment_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestNewAnnotations(t *testing.T) {
+	labels := map[string]string{"release": "v2.3.0"}
+	annotations, err := deployment.NewAnnotations("  v2.3.0 – pricing page launch ", "New pricing page\n\nFixes checkout", labels)
+	if err != nil {
+		t.Fatalf("NewAnnotations() error = %v", err)
+	}
+	if annotations.Title != "v2.3.0 – pricing page launch" {
+		t.Errorf("Title = %q, want it trimmed", annotations.Title)
+	}
+
+	// The annotations keep their own copy of the labels
+	labels["release"] = "v2.4.0"
+	if annotations.Labels["release"] != "v2.3.0" {
+		t.Errorf("Labels changed with the map they were created from")
+	}
+}
+
+func TestNewAnnotations_Invalid(t *testing.T) {
+	tooMany := make(map[string]string)
+	for i := range deployment.MaxLabels + 1 {
+		tooMany[strings.Repeat("k", i+1)] = "v"
+	}
+
+	tests := []struct {
+		name        string
+		title       string
+		description string
+		labels      map[string]string
+	}{
+		{name: "multi-line title", title: "v2.3.0\npricing"},
+		{name: "title too long", title: strings.Repeat("t", deployment.MaxTitleLength+1)},
+		{name: "description too long", description: strings.Repeat("d", deployment.MaxDescriptionLength+1)},
+		{name: "too many labels", labels: tooMany},
+		{name: "invalid label key", labels: map[string]string{"-release": "v2"}},
+		{name: "multi-line label value", labels: map[string]string{"release": "v2\nv3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := deployment.NewAnnotations(tt.title, tt.description, tt.labels)
+			if !errors.Is(err, deployment.ErrInvalidAnnotations) {
+				t.Errorf("NewAnnotations() error = %v, want ErrInvalidAnnotations", err)
+			}
+		})
+	}
+}
+
+func TestDeployment_Annotate(t *testing.T) {
+	dep, err := deployment.NewDeployment(project.NewProjectID(), user.NewUserID(), "abc123def456", "main", project.EnvironmentProduction)
+	if err != nil {
+		t.Fatalf("NewDeployment() error = %v", err)
+	}
+	if !dep.Annotations().IsZero() {
+		t.Fatal("a new deployment is annotated")
+	}
+
+	annotations, _ := deployment.NewAnnotations("v2.3.0", "", map[string]string{"team": "growth"})
+	dep.Annotate(annotations)
+	if got := dep.Annotations(); got.Title != "v2.3.0" || got.Labels["team"] != "growth" {
+		t.Errorf("Annotations() = %+v after Annotate()", got)
+	}
+}
@@ -0,0 +1,75 @@
+package deployment
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+	"snapdeploy-core/internal/domain/validation"
+)
+
+// MaxApprovalCommentLength bounds the comment left with an approval or rejection
+const MaxApprovalCommentLength = 500
+
+// MaxApprovers bounds the users designated to decide on the deployments to an environment
+const MaxApprovers = 20
+
+// ApprovalDecision is the outcome of reviewing a deployment to a protected environment
+type ApprovalDecision string
+
+const (
+	DecisionApproved ApprovalDecision = "APPROVED"
+	DecisionRejected ApprovalDecision = "REJECTED"
+)
+
+func (d ApprovalDecision) String() string {
+	return string(d)
+}
+
+// Approval is the audit record of who approved or rejected a deployment to a protected environment
+type Approval struct {
+	DeploymentID DeploymentID
+	ProjectID    project.ProjectID
+	UserID       user.UserID
+	Decision     ApprovalDecision
+	Comment      string
+	DecidedAt    time.Time
+}
+
+// NewApproval records a user's decision on a deployment
+func NewApproval(dep *Deployment, userID user.UserID, decision ApprovalDecision, comment string) (Approval, error) {
+	comment = strings.TrimSpace(comment)
+	if len(comment) > MaxApprovalCommentLength {
+		return Approval{}, validation.Errorf("comment must be at most %d characters", MaxApprovalCommentLength)
+	}
+
+	return Approval{
+		DeploymentID: dep.id,
+		ProjectID:    dep.projectID,
+		UserID:       userID,
+		Decision:     decision,
+		Comment:      comment,
+		DecidedAt:    time.Now(),
+	}, nil
+}
+
+// CheckApprovers checks users may be designated to decide on the deployments to an environment of a project,
+// returning them without duplicates. Only protected environments have approvers.
+func CheckApprovers(proj *project.Project, env project.Environment, approvers []user.UserID) ([]user.UserID, error) {
+	if !proj.IsProtected(env) {
+		return nil, ErrInvalidApprovers
+	}
+
+	unique := make([]user.UserID, 0, len(approvers))
+	for _, approver := range approvers {
+		if !slices.ContainsFunc(unique, approver.Equals) {
+			unique = append(unique, approver)
+		}
+	}
+	if len(unique) > MaxApprovers {
+		return nil, ErrInvalidApprovers
+	}
+	return unique, nil
+}
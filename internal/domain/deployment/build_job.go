@@ -0,0 +1,143 @@
+package deployment
+
+import (
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/domain/project"
+)
+
+// BuildJobStatus represents the status of a build job
+type BuildJobStatus string
+
+const (
+	BuildJobPending BuildJobStatus = "PENDING"
+	BuildJobRunning BuildJobStatus = "RUNNING" // claimed by a worker that is starting the build
+	BuildJobDone    BuildJobStatus = "DONE"    // the build has been started
+	BuildJobFailed  BuildJobStatus = "FAILED"
+)
+
+func (s BuildJobStatus) String() string {
+	return string(s)
+}
+
+// BuildJob records that a deployment's build still has to be started.
+// It is persisted with the deployment and claimed by a build worker, so builds survive server restarts.
+type BuildJob struct {
+	deploymentID  DeploymentID
+	projectID     project.ProjectID
+	status        BuildJobStatus
+	attempts      int
+	lastError     string
+	claimedAt     *time.Time
+	correlationID string // ID of the request that queued the build, carried into the build's logs
+	traceParent   string // trace context of the request that queued the build, continued by the build worker
+	createdAt     time.Time
+	updatedAt     time.Time
+}
+
+// NewBuildJob creates a pending build job for a deployment
+func NewBuildJob(dep *Deployment, correlationID, traceParent string) *BuildJob {
+	now := time.Now()
+	return &BuildJob{
+		deploymentID:  dep.ID(),
+		projectID:     dep.ProjectID(),
+		status:        BuildJobPending,
+		correlationID: correlationID,
+		traceParent:   traceParent,
+		createdAt:     now,
+		updatedAt:     now,
+	}
+}
+
+// ReconstituteBuildJob recreates a build job from persistence
+func ReconstituteBuildJob(
+	deploymentID string,
+	projectID project.ProjectID,
+	status string,
+	attempts int,
+	lastError string,
+	claimedAt *time.Time,
+	correlationID, traceParent string,
+	createdAt, updatedAt time.Time,
+) (*BuildJob, error) {
+	depID, err := ParseDeploymentID(deploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment ID: %w", err)
+	}
+
+	switch BuildJobStatus(status) {
+	case BuildJobPending, BuildJobRunning, BuildJobDone, BuildJobFailed:
+	default:
+		return nil, fmt.Errorf("invalid build job status: %s", status)
+	}
+
+	return &BuildJob{
+		deploymentID:  depID,
+		projectID:     projectID,
+		status:        BuildJobStatus(status),
+		attempts:      attempts,
+		lastError:     lastError,
+		claimedAt:     claimedAt,
+		correlationID: correlationID,
+		traceParent:   traceParent,
+		createdAt:     createdAt,
+		updatedAt:     updatedAt,
+	}, nil
+}
+
+// Complete marks the job done once its build has been started
+func (j *BuildJob) Complete() {
+	j.status = BuildJobDone
+	j.updatedAt = time.Now()
+}
+
+// Fail marks the job failed; it won't be claimed again
+func (j *BuildJob) Fail(reason string) {
+	j.status = BuildJobFailed
+	j.lastError = reason
+	j.updatedAt = time.Now()
+}
+
+// Getters
+
+func (j *BuildJob) DeploymentID() DeploymentID {
+	return j.deploymentID
+}
+
+func (j *BuildJob) ProjectID() project.ProjectID {
+	return j.projectID
+}
+
+func (j *BuildJob) Status() BuildJobStatus {
+	return j.status
+}
+
+// Attempts returns how many times a worker has claimed the job
+func (j *BuildJob) Attempts() int {
+	return j.attempts
+}
+
+func (j *BuildJob) LastError() string {
+	return j.lastError
+}
+
+func (j *BuildJob) ClaimedAt() *time.Time {
+	return j.claimedAt
+}
+
+func (j *BuildJob) CorrelationID() string {
+	return j.correlationID
+}
+
+func (j *BuildJob) TraceParent() string {
+	return j.traceParent
+}
+
+func (j *BuildJob) CreatedAt() time.Time {
+	return j.createdAt
+}
+
+func (j *BuildJob) UpdatedAt() time.Time {
+	return j.updatedAt
+}
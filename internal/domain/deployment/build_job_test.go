@@ -0,0 +1,53 @@
+package deployment_test
+
+import (
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestNewBuildJob(t *testing.T) {
+	dep, err := deployment.NewDeployment(project.NewProjectID(), user.NewUserID(), "abc123def456", "main", project.EnvironmentProduction)
+	if err != nil {
+		t.Fatalf("NewDeployment() error = %v", err)
+	}
+
+	job := deployment.NewBuildJob(dep, "req-1", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if !job.DeploymentID().Equals(dep.ID()) || !job.ProjectID().Equals(dep.ProjectID()) {
+		t.Error("build job should reference the deployment and its project")
+	}
+	if job.CorrelationID() != "req-1" {
+		t.Errorf("CorrelationID() = %q, want %q", job.CorrelationID(), "req-1")
+	}
+	if job.TraceParent() != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("TraceParent() = %q", job.TraceParent())
+	}
+	if job.Status() != deployment.BuildJobPending {
+		t.Errorf("Status() = %v, want %v", job.Status(), deployment.BuildJobPending)
+	}
+
+	job.Fail("boom")
+	if job.Status() != deployment.BuildJobFailed || job.LastError() != "boom" {
+		t.Errorf("after Fail() status = %v, error = %q", job.Status(), job.LastError())
+	}
+}
+
+func TestReconstituteBuildJob(t *testing.T) {
+	now := time.Now()
+	depID := deployment.NewDeploymentID().String()
+
+	job, err := deployment.ReconstituteBuildJob(depID, project.NewProjectID(), "RUNNING", 2, "", &now, "req-1", "", now, now)
+	if err != nil {
+		t.Fatalf("ReconstituteBuildJob() error = %v", err)
+	}
+	if job.Status() != deployment.BuildJobRunning || job.Attempts() != 2 {
+		t.Errorf("job = %v with %d attempts, want RUNNING with 2", job.Status(), job.Attempts())
+	}
+
+	if _, err := deployment.ReconstituteBuildJob(depID, project.NewProjectID(), "UNKNOWN", 0, "", nil, "", "", now, now); err == nil {
+		t.Error("ReconstituteBuildJob() with an unknown status should fail")
+	}
+}
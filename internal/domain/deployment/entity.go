@@ -2,30 +2,42 @@ package deployment
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"snapdeploy-core/internal/domain/events"
 	"snapdeploy-core/internal/domain/project"
 	"snapdeploy-core/internal/domain/user"
 )
 
 // Deployment is a domain entity representing a deployment of a project
 type Deployment struct {
-	id         DeploymentID
-	projectID  project.ProjectID
-	userID     user.UserID
-	commitHash CommitHash
-	branch     Branch
-	status     DeploymentStatus
-	logs       DeploymentLog
-	createdAt  time.Time
-	updatedAt  time.Time
-}
-
-// NewDeployment creates a new Deployment entity
+	id          DeploymentID
+	projectID   project.ProjectID
+	userID      user.UserID
+	commitHash  CommitHash
+	branch      Branch
+	deployType  DeploymentType
+	env         project.Environment
+	status      DeploymentStatus
+	logs        DeploymentLog
+	imageURI    string // Image deployed, set once the deployment starts running it
+	imageDigest string // Digest of the image, empty if the registry doesn't report one
+	annotations Annotations
+	pinned      bool // Pinned deployments can't be deleted and are never archived
+	createdAt   time.Time
+	updatedAt   time.Time
+	deletedAt   *time.Time  // Set once the deployment is soft deleted
+	redactor    LogRedactor // Masks secrets in the lines appended to the logs, not persisted
+	events      []events.DomainEvent
+}
+
+// NewDeployment creates a new Deployment entity of a project's environment
 func NewDeployment(
 	projectID project.ProjectID,
 	userID user.UserID,
 	commitHash, branch string,
+	environment project.Environment,
 ) (*Deployment, error) {
 	hash, err := NewCommitHash(commitHash)
 	if err != nil {
@@ -38,17 +50,56 @@ func NewDeployment(
 	}
 
 	now := time.Now()
-	return &Deployment{
+	d := &Deployment{
 		id:         NewDeploymentID(),
 		projectID:  projectID,
 		userID:     userID,
 		commitHash: hash,
 		branch:     br,
+		deployType: TypeBuild,
+		env:        environment,
 		status:     StatusPending,
 		logs:       NewDeploymentLog(""),
 		createdAt:  now,
 		updatedAt:  now,
-	}, nil
+	}
+	d.recordCreated()
+	return d, nil
+}
+
+// NewRestartDeployment creates a deployment that restarts the running image of a previous deployment
+// without rebuilding it, in the same environment. Restarts skip the build and start in the deploying state.
+func NewRestartDeployment(previous *Deployment, userID user.UserID) *Deployment {
+	return newDeploymentOfImage(previous, userID, TypeRestart)
+}
+
+// NewRedeployDeployment creates a deployment that deploys the image of a previous deployment again, in the
+// same environment, so the project's current environment variables are picked up without a rebuild.
+// Redeploys skip the build and start in the deploying state.
+func NewRedeployDeployment(previous *Deployment, userID user.UserID) *Deployment {
+	return newDeploymentOfImage(previous, userID, TypeRedeploy)
+}
+
+// newDeploymentOfImage creates a deployment of the image a previous deployment ran
+func newDeploymentOfImage(previous *Deployment, userID user.UserID, deployType DeploymentType) *Deployment {
+	now := time.Now()
+	d := &Deployment{
+		id:          NewDeploymentID(),
+		projectID:   previous.projectID,
+		userID:      userID,
+		commitHash:  previous.commitHash,
+		branch:      previous.branch,
+		deployType:  deployType,
+		env:         previous.env,
+		status:      StatusDeploying,
+		logs:        NewDeploymentLog(""),
+		imageURI:    previous.imageURI,
+		imageDigest: previous.imageDigest,
+		createdAt:   now,
+		updatedAt:   now,
+	}
+	d.recordCreated()
+	return d
 }
 
 // Reconstitute recreates a Deployment entity from persistence
@@ -56,8 +107,12 @@ func Reconstitute(
 	id string,
 	projectID project.ProjectID,
 	userID user.UserID,
-	commitHash, branch, status, logs string,
+	commitHash, branch, deploymentType, status, logs string,
 	createdAt, updatedAt time.Time,
+	deletedAt *time.Time,
+	environment, imageURI, imageDigest string,
+	annotations Annotations,
+	pinned bool,
 ) (*Deployment, error) {
 	deploymentID, err := ParseDeploymentID(id)
 	if err != nil {
@@ -74,21 +129,38 @@ func Reconstitute(
 		return nil, fmt.Errorf("invalid branch: %w", err)
 	}
 
+	dtype, err := NewDeploymentType(deploymentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid type: %w", err)
+	}
+
 	stat, err := NewDeploymentStatus(status)
 	if err != nil {
 		return nil, fmt.Errorf("invalid status: %w", err)
 	}
 
+	env, err := project.NewEnvironment(environment)
+	if err != nil {
+		return nil, fmt.Errorf("invalid environment: %w", err)
+	}
+
 	return &Deployment{
-		id:         deploymentID,
-		projectID:  projectID,
-		userID:     userID,
-		commitHash: hash,
-		branch:     br,
-		status:     stat,
-		logs:       NewDeploymentLog(logs),
-		createdAt:  createdAt,
-		updatedAt:  updatedAt,
+		id:          deploymentID,
+		projectID:   projectID,
+		userID:      userID,
+		commitHash:  hash,
+		branch:      br,
+		deployType:  dtype,
+		env:         env,
+		status:      stat,
+		logs:        NewDeploymentLog(logs),
+		imageURI:    imageURI,
+		imageDigest: imageDigest,
+		annotations: annotations,
+		pinned:      pinned,
+		createdAt:   createdAt,
+		updatedAt:   updatedAt,
+		deletedAt:   deletedAt,
 	}, nil
 }
 
@@ -98,17 +170,196 @@ func (d *Deployment) UpdateStatus(newStatus DeploymentStatus) error {
 		return fmt.Errorf("%w: cannot transition from %s to %s", ErrInvalidStatusTransition, d.status, newStatus)
 	}
 
+	d.setStatus(newStatus)
+	return nil
+}
+
+// AwaitApproval holds a new deployment to a protected environment until it is approved or rejected
+func (d *Deployment) AwaitApproval() error {
+	if d.status != StatusPending {
+		return fmt.Errorf("%w: cannot transition from %s to %s", ErrInvalidStatusTransition, d.status, StatusWaitingApproval)
+	}
+
+	d.setStatus(StatusWaitingApproval)
+	d.AppendLog(fmt.Sprintf("🔒 %s is protected, waiting for the deployment to be approved...", d.env))
+	return nil
+}
+
+// Approve releases a deployment waiting for approval to be built, returning the record of the decision.
+// The user who started the deployment can't approve it.
+func (d *Deployment) Approve(userID user.UserID, comment string) (Approval, error) {
+	if userID.Equals(d.userID) {
+		return Approval{}, ErrSelfApproval
+	}
+	return d.decide(userID, DecisionApproved, comment)
+}
+
+// Reject ends a deployment waiting for approval without building it, returning the record of the decision
+func (d *Deployment) Reject(userID user.UserID, comment string) (Approval, error) {
+	return d.decide(userID, DecisionRejected, comment)
+}
+
+// decide records the approval or rejection of a deployment waiting for approval.
+// Only decisions move a deployment out of the waiting state, status updates can't.
+func (d *Deployment) decide(userID user.UserID, decision ApprovalDecision, comment string) (Approval, error) {
+	if d.status != StatusWaitingApproval {
+		return Approval{}, fmt.Errorf("%w: deployment is %s", ErrNotWaitingApproval, d.status)
+	}
+
+	approval, err := NewApproval(d, userID, decision, comment)
+	if err != nil {
+		return Approval{}, err
+	}
+
+	if decision == DecisionApproved {
+		d.setStatus(StatusPending)
+		d.AppendLog(fmt.Sprintf("✅ Approved by user %s", userID.String()))
+	} else {
+		d.setStatus(StatusRejected)
+		d.AppendLog(fmt.Sprintf("🚫 Rejected by user %s", userID.String()))
+	}
+	if approval.Comment != "" {
+		d.AppendLog(fmt.Sprintf("💬 %s", approval.Comment))
+	}
+	return approval, nil
+}
+
+// setStatus changes the status, recording the change
+func (d *Deployment) setStatus(newStatus DeploymentStatus) {
+	if d.status != newStatus {
+		d.events = append(d.events, NewDeploymentStatusChanged(d.id.String(), d.projectID.String(), d.status.String(), newStatus.String()))
+	}
+
 	d.status = newStatus
 	d.updatedAt = time.Now()
+}
+
+// TimeOut fails a deployment that has made no progress for longer than the timeout
+func (d *Deployment) TimeOut(timeout time.Duration) error {
+	if d.status.IsTerminal() {
+		return fmt.Errorf("%w: %s deployment can't time out", ErrInvalidStatusTransition, d.status)
+	}
+
+	stuckIn := d.status
+	if err := d.UpdateStatus(StatusFailed); err != nil {
+		return err
+	}
+	d.AppendLog(fmt.Sprintf("⏱️ Deployment timed out: no progress in %s for %s", stuckIn, timeout))
+	return nil
+}
+
+// Interrupt records that the deployment's build was cut off by a server shutdown. Its build job is
+// queued again, and the next build worker to claim it resumes the deployment.
+func (d *Deployment) Interrupt() error {
+	if d.status != StatusBuilding {
+		return fmt.Errorf("%w: cannot transition from %s to %s", ErrInvalidStatusTransition, d.status, StatusInterrupted)
+	}
+	d.setStatus(StatusInterrupted)
+	return nil
+}
+
+// Resume moves an interrupted deployment back to pending, so its build starts over
+func (d *Deployment) Resume() error {
+	if d.status != StatusInterrupted {
+		return fmt.Errorf("%w: cannot transition from %s to %s", ErrInvalidStatusTransition, d.status, StatusPending)
+	}
+	d.setStatus(StatusPending)
+	return nil
+}
+
+// Retry picks a failed deployment up again from a pipeline step. Steps after the push deploy the image
+// the deployment already built, earlier ones build it again.
+func (d *Deployment) Retry(from Step) error {
+	if d.status != StatusFailed {
+		return fmt.Errorf("%w: only failed deployments can be retried, this one is %s", ErrNotRetryable, d.status)
+	}
+	if d.deployType != TypeBuild {
+		return fmt.Errorf("%w: %s deployments are started again with a new one", ErrNotRetryable, d.deployType)
+	}
+	if !from.ReusesImage() {
+		d.setStatus(StatusPending)
+		d.AppendLog(fmt.Sprintf("🔁 Retrying from the %s step, rebuilding the image...", from))
+		return nil
+	}
+	if d.imageURI == "" {
+		return fmt.Errorf("%w: no image was pushed, retry from the %s step", ErrNotRetryable, StepClone)
+	}
+	d.setStatus(StatusDeploying)
+	d.AppendLog(fmt.Sprintf("🔁 Retrying from the %s step with the image already pushed...", from))
 	return nil
 }
 
-// AppendLog appends a line to the deployment logs
+// RecordMigration records the result of the deployment's database migration
+func (d *Deployment) RecordMigration(succeeded bool, detail string) {
+	d.events = append(d.events, NewMigrationFinished(d.id.String(), d.projectID.String(), succeeded, detail))
+}
+
+// RecordImage records the image the deployment runs and its digest, if known
+func (d *Deployment) RecordImage(imageURI, digest string) {
+	d.imageURI = imageURI
+	d.imageDigest = digest
+	d.updatedAt = time.Now()
+}
+
+// Annotate replaces the title, description and labels of the deployment
+func (d *Deployment) Annotate(annotations Annotations) {
+	d.annotations = annotations
+	d.updatedAt = time.Now()
+}
+
+// Pin keeps the deployment, such as a known-good rollback target: it can't be deleted until it is unpinned,
+// and is neither archived nor has its image cleaned up
+func (d *Deployment) Pin() {
+	d.pinned = true
+	d.updatedAt = time.Now()
+}
+
+// Unpin lets the deployment be deleted, archived and have its image cleaned up again
+func (d *Deployment) Unpin() {
+	d.pinned = false
+	d.updatedAt = time.Now()
+}
+
+// HasEvents reports whether the deployment recorded domain events that haven't been pulled yet
+func (d *Deployment) HasEvents() bool {
+	return len(d.events) > 0
+}
+
+// PullEvents returns the domain events recorded since the last call and clears them
+func (d *Deployment) PullEvents() []events.DomainEvent {
+	pulled := d.events
+	d.events = nil
+	return pulled
+}
+
+// recordCreated records the creation of a new deployment
+func (d *Deployment) recordCreated() {
+	d.events = append(d.events, NewDeploymentCreated(
+		d.id.String(),
+		d.projectID.String(),
+		d.userID.String(),
+		d.commitHash.String(),
+		d.branch.String(),
+	))
+}
+
+// AppendLog appends a line to the deployment logs, masking the secrets in it and cutting it off at MaxLogLineBytes
 func (d *Deployment) AppendLog(line string) {
-	d.logs.AppendLine(line)
+	d.logs.AppendLine(d.RedactLog(line))
 	d.updatedAt = time.Now()
 }
 
+// MaskInLogs masks values in the lines appended to the logs from now on, such as the project's
+// environment variables and clone tokens. Only this instance of the deployment masks them.
+func (d *Deployment) MaskInLogs(values ...string) {
+	d.redactor.Add(values...)
+}
+
+// RedactLog returns a line the way AppendLog records it, for streaming lines as they are logged
+func (d *Deployment) RedactLog(line string) string {
+	return limitLogLine(d.redactor.Redact(line))
+}
+
 // SetLogs sets the deployment logs (useful for bulk updates)
 func (d *Deployment) SetLogs(logs string) {
 	d.logs = NewDeploymentLog(logs)
@@ -133,12 +384,13 @@ func isValidStatusTransition(from, to DeploymentStatus) bool {
 	}
 
 	transitions := map[DeploymentStatus][]DeploymentStatus{
-		StatusPending:    {StatusBuilding, StatusFailed},
-		StatusBuilding:   {StatusDeploying, StatusFailed},
-		StatusDeploying:  {StatusDeployed, StatusFailed},
-		StatusDeployed:   {StatusRolledBack},
-		StatusFailed:     {StatusPending}, // Allow retry
-		StatusRolledBack: {StatusPending}, // Allow redeployment
+		StatusPending:     {StatusBuilding, StatusFailed},
+		StatusBuilding:    {StatusDeploying, StatusFailed, StatusInterrupted},
+		StatusDeploying:   {StatusDeployed, StatusFailed},
+		StatusDeployed:    {StatusRolledBack},
+		StatusFailed:      {StatusPending}, // Allow retry
+		StatusRolledBack:  {StatusPending}, // Allow redeployment
+		StatusInterrupted: {StatusPending, StatusFailed},
 	}
 
 	allowedTransitions, exists := transitions[from]
@@ -177,6 +429,15 @@ func (d *Deployment) Branch() Branch {
 	return d.branch
 }
 
+func (d *Deployment) Type() DeploymentType {
+	return d.deployType
+}
+
+// Environment returns the environment of the project the deployment runs in
+func (d *Deployment) Environment() project.Environment {
+	return d.env
+}
+
 func (d *Deployment) Status() DeploymentStatus {
 	return d.status
 }
@@ -185,6 +446,41 @@ func (d *Deployment) Logs() DeploymentLog {
 	return d.logs
 }
 
+// ImageURI returns the image the deployment runs, or "" if it didn't get to run one
+func (d *Deployment) ImageURI() string {
+	return d.imageURI
+}
+
+// ImageDigest returns the digest of the image the deployment runs, or "" if it isn't known
+func (d *Deployment) ImageDigest() string {
+	return d.imageDigest
+}
+
+// ImageReference returns the reference pinning the exact image the deployment runs: the repository with the
+// digest when it is known, the image URI otherwise
+func (d *Deployment) ImageReference() string {
+	if d.imageDigest == "" {
+		return d.imageURI
+	}
+	repository := d.imageURI
+	if at := strings.Index(repository, "@"); at >= 0 {
+		repository = repository[:at]
+	} else if colon := strings.LastIndex(repository, ":"); colon > strings.LastIndex(repository, "/") {
+		repository = repository[:colon]
+	}
+	return repository + "@" + d.imageDigest
+}
+
+// Annotations returns the title, description and labels of the deployment
+func (d *Deployment) Annotations() Annotations {
+	return d.annotations
+}
+
+// IsPinned reports whether the deployment is kept from deletion and cleanup
+func (d *Deployment) IsPinned() bool {
+	return d.pinned
+}
+
 func (d *Deployment) CreatedAt() time.Time {
 	return d.createdAt
 }
@@ -193,9 +489,13 @@ func (d *Deployment) UpdatedAt() time.Time {
 	return d.updatedAt
 }
 
+// DeletedAt returns when the deployment was deleted, or nil if it hasn't been
+func (d *Deployment) DeletedAt() *time.Time {
+	return d.deletedAt
+}
+
 // String returns string representation (for debugging)
 func (d *Deployment) String() string {
 	return fmt.Sprintf("Deployment{id: %s, projectID: %s, status: %s}",
 		d.id.String(), d.projectID.String(), d.status.String())
 }
-
@@ -0,0 +1,333 @@
+package deployment_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestNewRestartDeployment(t *testing.T) {
+	previous, err := deployment.NewDeployment(project.NewProjectID(), user.NewUserID(), "abc123def456", "main", project.Environment("staging"))
+	if err != nil {
+		t.Fatalf("NewDeployment() error = %v", err)
+	}
+	if previous.Type() != deployment.TypeBuild {
+		t.Errorf("Type() = %v, want %v", previous.Type(), deployment.TypeBuild)
+	}
+	previous.RecordImage("registry.example.com/app:abc123d", "sha256:0123")
+
+	restartedBy := user.NewUserID()
+	restart := deployment.NewRestartDeployment(previous, restartedBy)
+
+	if restart.Type() != deployment.TypeRestart {
+		t.Errorf("Type() = %v, want %v", restart.Type(), deployment.TypeRestart)
+	}
+	if restart.ID().Equals(previous.ID()) {
+		t.Error("restart should get a new deployment ID")
+	}
+	if !restart.BelongsToProject(previous.ProjectID()) {
+		t.Error("restart should belong to the same project")
+	}
+	if !restart.BelongsToUser(restartedBy) {
+		t.Error("restart should belong to the user who requested it")
+	}
+	if restart.CommitHash() != previous.CommitHash() || restart.Branch() != previous.Branch() {
+		t.Errorf("restart commit = %s@%s, want %s@%s",
+			restart.Branch(), restart.CommitHash(), previous.Branch(), previous.CommitHash())
+	}
+	if restart.Environment() != previous.Environment() {
+		t.Errorf("Environment() = %v, want %v", restart.Environment(), previous.Environment())
+	}
+	if restart.ImageURI() != previous.ImageURI() || restart.ImageDigest() != previous.ImageDigest() {
+		t.Errorf("restart image = %s (%s), want the previous deployment's", restart.ImageURI(), restart.ImageDigest())
+	}
+
+	// Restarts skip the build
+	if restart.Status() != deployment.StatusDeploying {
+		t.Errorf("Status() = %v, want %v", restart.Status(), deployment.StatusDeploying)
+	}
+	if err := restart.UpdateStatus(deployment.StatusDeployed); err != nil {
+		t.Errorf("UpdateStatus(DEPLOYED) error = %v", err)
+	}
+}
+
+func TestNewRedeployDeployment(t *testing.T) {
+	previous, err := deployment.NewDeployment(project.NewProjectID(), user.NewUserID(), "abc123def456", "main", project.Environment("staging"))
+	if err != nil {
+		t.Fatalf("NewDeployment() error = %v", err)
+	}
+	previous.RecordImage("registry.example.com/app:abc123d", "sha256:0123")
+
+	redeploy := deployment.NewRedeployDeployment(previous, user.NewUserID())
+
+	if redeploy.Type() != deployment.TypeRedeploy {
+		t.Errorf("Type() = %v, want %v", redeploy.Type(), deployment.TypeRedeploy)
+	}
+	if redeploy.Environment() != previous.Environment() {
+		t.Errorf("Environment() = %v, want %v", redeploy.Environment(), previous.Environment())
+	}
+	if redeploy.ImageURI() != previous.ImageURI() {
+		t.Errorf("ImageURI() = %s, want %s", redeploy.ImageURI(), previous.ImageURI())
+	}
+	if redeploy.Status() != deployment.StatusDeploying {
+		t.Errorf("Status() = %v, want %v", redeploy.Status(), deployment.StatusDeploying)
+	}
+
+	// Redeploys start new tasks with the current variables, restarts keep the task definition they run
+	if !redeploy.PicksUpEnvChanges() {
+		t.Error("PicksUpEnvChanges() = false for a redeploy, want true")
+	}
+	if !previous.PicksUpEnvChanges() {
+		t.Error("PicksUpEnvChanges() = false for a build, want true")
+	}
+	if deployment.NewRestartDeployment(previous, user.NewUserID()).PicksUpEnvChanges() {
+		t.Error("PicksUpEnvChanges() = true for a restart, want false")
+	}
+}
+
+func TestDeployment_ImageReference(t *testing.T) {
+	tests := []struct {
+		imageURI string
+		digest   string
+		want     string
+	}{
+		{imageURI: "", digest: "", want: ""},
+		{imageURI: "registry.example.com/app:abc123d", digest: "", want: "registry.example.com/app:abc123d"},
+		{imageURI: "registry.example.com/app:abc123d", digest: "sha256:0123", want: "registry.example.com/app@sha256:0123"},
+		{imageURI: "localhost:5000/app:abc123d", digest: "sha256:0123", want: "localhost:5000/app@sha256:0123"},
+		{imageURI: "localhost:5000/app", digest: "sha256:0123", want: "localhost:5000/app@sha256:0123"},
+		{imageURI: "registry.example.com/app@sha256:0123", digest: "sha256:0123", want: "registry.example.com/app@sha256:0123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.imageURI, func(t *testing.T) {
+			dep, err := deployment.NewDeployment(project.NewProjectID(), user.NewUserID(), "abc123def456", "main", project.EnvironmentProduction)
+			if err != nil {
+				t.Fatalf("NewDeployment() error = %v", err)
+			}
+			dep.RecordImage(tt.imageURI, tt.digest)
+
+			if got := dep.ImageReference(); got != tt.want {
+				t.Errorf("ImageReference() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDeploymentType(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    deployment.DeploymentType
+		wantErr bool
+	}{
+		{input: "", want: deployment.TypeBuild},
+		{input: "build", want: deployment.TypeBuild},
+		{input: "RESTART", want: deployment.TypeRestart},
+		{input: "ROLLBACK", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := deployment.NewDeploymentType(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewDeploymentType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NewDeploymentType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeployment_PullEvents(t *testing.T) {
+	dep, err := deployment.NewDeployment(project.NewProjectID(), user.NewUserID(), "abc123def456", "main", project.EnvironmentProduction)
+	if err != nil {
+		t.Fatalf("NewDeployment() error = %v", err)
+	}
+
+	if err := dep.UpdateStatus(deployment.StatusBuilding); err != nil {
+		t.Fatalf("UpdateStatus(BUILDING) error = %v", err)
+	}
+	// Idempotent updates don't record a change
+	if err := dep.UpdateStatus(deployment.StatusBuilding); err != nil {
+		t.Fatalf("UpdateStatus(BUILDING) error = %v", err)
+	}
+
+	pulled := dep.PullEvents()
+	if len(pulled) != 2 {
+		t.Fatalf("PullEvents() returned %d events, want 2", len(pulled))
+	}
+	if pulled[0].EventType() != deployment.EventTypeDeploymentCreated {
+		t.Errorf("first event = %s, want %s", pulled[0].EventType(), deployment.EventTypeDeploymentCreated)
+	}
+	changed, ok := pulled[1].(*deployment.DeploymentStatusChanged)
+	if !ok {
+		t.Fatalf("second event = %T, want *deployment.DeploymentStatusChanged", pulled[1])
+	}
+	if changed.OldStatus != "PENDING" || changed.NewStatus != "BUILDING" {
+		t.Errorf("status change = %s -> %s, want PENDING -> BUILDING", changed.OldStatus, changed.NewStatus)
+	}
+
+	if again := dep.PullEvents(); len(again) != 0 {
+		t.Errorf("PullEvents() after pull returned %d events, want 0", len(again))
+	}
+}
+
+func TestDeployment_HasEvents(t *testing.T) {
+	dep, err := deployment.NewDeployment(project.NewProjectID(), user.NewUserID(), "abc123def456", "main", project.EnvironmentProduction)
+	if err != nil {
+		t.Fatalf("NewDeployment() error = %v", err)
+	}
+	if !dep.HasEvents() {
+		t.Fatal("HasEvents() = false for a new deployment, want true")
+	}
+
+	dep.PullEvents()
+	// Log lines alone record nothing
+	dep.AppendLog("Building...")
+	if dep.HasEvents() {
+		t.Error("HasEvents() = true after appending a log line, want false")
+	}
+
+	if err := dep.UpdateStatus(deployment.StatusBuilding); err != nil {
+		t.Fatalf("UpdateStatus(BUILDING) error = %v", err)
+	}
+	if !dep.HasEvents() {
+		t.Error("HasEvents() = false after a status change, want true")
+	}
+}
+
+func TestDeployment_TimeOut(t *testing.T) {
+	dep, err := deployment.NewDeployment(project.NewProjectID(), user.NewUserID(), "abc123def456", "main", project.EnvironmentProduction)
+	if err != nil {
+		t.Fatalf("NewDeployment() error = %v", err)
+	}
+	if err := dep.UpdateStatus(deployment.StatusBuilding); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+
+	if err := dep.TimeOut(30 * time.Minute); err != nil {
+		t.Fatalf("TimeOut() error = %v", err)
+	}
+	if dep.Status() != deployment.StatusFailed {
+		t.Errorf("Status() = %v, want %v", dep.Status(), deployment.StatusFailed)
+	}
+	if !strings.Contains(dep.Logs().String(), "timed out") {
+		t.Errorf("Logs() = %q, want a timeout line", dep.Logs().String())
+	}
+
+	// Finished deployments can't time out
+	if err := dep.TimeOut(30 * time.Minute); !errors.Is(err, deployment.ErrInvalidStatusTransition) {
+		t.Errorf("TimeOut() on failed deployment error = %v, want %v", err, deployment.ErrInvalidStatusTransition)
+	}
+}
+
+func TestDeployment_InterruptAndResume(t *testing.T) {
+	dep, err := deployment.NewDeployment(project.NewProjectID(), user.NewUserID(), "abc123def456", "main", project.EnvironmentProduction)
+	if err != nil {
+		t.Fatalf("NewDeployment() error = %v", err)
+	}
+
+	// Only running builds are interrupted
+	if err := dep.Interrupt(); !errors.Is(err, deployment.ErrInvalidStatusTransition) {
+		t.Errorf("Interrupt() on pending deployment error = %v, want %v", err, deployment.ErrInvalidStatusTransition)
+	}
+	if err := dep.Resume(); !errors.Is(err, deployment.ErrInvalidStatusTransition) {
+		t.Errorf("Resume() on pending deployment error = %v, want %v", err, deployment.ErrInvalidStatusTransition)
+	}
+
+	if err := dep.UpdateStatus(deployment.StatusBuilding); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	if err := dep.Interrupt(); err != nil {
+		t.Fatalf("Interrupt() error = %v", err)
+	}
+	if dep.Status() != deployment.StatusInterrupted || dep.Status().IsTerminal() {
+		t.Errorf("Status() = %v, want non-terminal %v", dep.Status(), deployment.StatusInterrupted)
+	}
+
+	// Interrupted builds start over from the queue
+	if err := dep.Resume(); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if dep.Status() != deployment.StatusPending {
+		t.Errorf("Status() after Resume() = %v, want %v", dep.Status(), deployment.StatusPending)
+	}
+}
+
+func TestDeployment_Approval(t *testing.T) {
+	newWaiting := func(t *testing.T) *deployment.Deployment {
+		t.Helper()
+		dep, err := deployment.NewDeployment(project.NewProjectID(), user.NewUserID(), "abc123def456", "main", project.EnvironmentProduction)
+		if err != nil {
+			t.Fatalf("NewDeployment() error = %v", err)
+		}
+		if err := dep.AwaitApproval(); err != nil {
+			t.Fatalf("AwaitApproval() error = %v", err)
+		}
+		return dep
+	}
+
+	dep := newWaiting(t)
+	if dep.Status() != deployment.StatusWaitingApproval {
+		t.Errorf("Status() = %v, want %v", dep.Status(), deployment.StatusWaitingApproval)
+	}
+
+	// Only a decision moves the deployment on
+	if err := dep.UpdateStatus(deployment.StatusBuilding); !errors.Is(err, deployment.ErrInvalidStatusTransition) {
+		t.Errorf("UpdateStatus(BUILDING) while waiting error = %v, want %v", err, deployment.ErrInvalidStatusTransition)
+	}
+
+	approver := user.NewUserID()
+	approval, err := dep.Approve(approver, " Looks good ")
+	if err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	if dep.Status() != deployment.StatusPending {
+		t.Errorf("Status() after approval = %v, want %v", dep.Status(), deployment.StatusPending)
+	}
+	if approval.Decision != deployment.DecisionApproved || approval.UserID != approver || approval.Comment != "Looks good" {
+		t.Errorf("Approve() = %+v", approval)
+	}
+	if !approval.DeploymentID.Equals(dep.ID()) {
+		t.Errorf("approval deployment = %v, want %v", approval.DeploymentID, dep.ID())
+	}
+
+	// A decision is final
+	if _, err := dep.Reject(approver, ""); !errors.Is(err, deployment.ErrNotWaitingApproval) {
+		t.Errorf("Reject() after approval error = %v, want %v", err, deployment.ErrNotWaitingApproval)
+	}
+
+	rejected := newWaiting(t)
+	approval, err = rejected.Reject(approver, "")
+	if err != nil {
+		t.Fatalf("Reject() error = %v", err)
+	}
+	if rejected.Status() != deployment.StatusRejected || !rejected.Status().IsTerminal() {
+		t.Errorf("Status() after rejection = %v, want terminal %v", rejected.Status(), deployment.StatusRejected)
+	}
+	if approval.Decision != deployment.DecisionRejected {
+		t.Errorf("Decision = %v, want %v", approval.Decision, deployment.DecisionRejected)
+	}
+
+	if _, err := newWaiting(t).Approve(approver, strings.Repeat("a", deployment.MaxApprovalCommentLength+1)); err == nil {
+		t.Error("Approve() with a long comment should fail")
+	}
+
+	// Nobody approves their own deployment, while withdrawing it is fine
+	own := newWaiting(t)
+	if _, err := own.Approve(own.UserID(), ""); !errors.Is(err, deployment.ErrSelfApproval) {
+		t.Errorf("Approve() by the user who started it error = %v, want %v", err, deployment.ErrSelfApproval)
+	}
+	if own.Status() != deployment.StatusWaitingApproval {
+		t.Errorf("Status() after self-approval = %v, want it still waiting", own.Status())
+	}
+	if _, err := own.Reject(own.UserID(), ""); err != nil {
+		t.Errorf("Reject() by the user who started it error = %v", err)
+	}
+}
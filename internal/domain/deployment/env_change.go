@@ -0,0 +1,30 @@
+package deployment
+
+import (
+	"time"
+
+	"snapdeploy-core/internal/domain/project"
+)
+
+// EnvChange is the latest change to the environment variables of a project's environment. Running tasks
+// keep the variables they started with, so the change is pending until a deployment picks it up.
+type EnvChange struct {
+	ProjectID   project.ProjectID
+	Environment project.Environment
+	ChangedAt   time.Time
+
+	AppliedBy *DeploymentID // Deployment that picked the change up, nil while it's pending
+	AppliedAt *time.Time    // When that deployment succeeded
+}
+
+// Pending reports whether no deployment picked the change up yet
+func (c *EnvChange) Pending() bool {
+	return c.AppliedBy == nil
+}
+
+// PicksUpEnvChanges reports whether a deployment that succeeds deployed the environment variables of its
+// environment as they were when it was created. Restarts keep running the task definition the variables
+// were last deployed with.
+func (d *Deployment) PicksUpEnvChanges() bool {
+	return d.deployType != TypeRestart
+}
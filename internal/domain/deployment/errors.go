@@ -1,6 +1,11 @@
 package deployment
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"snapdeploy-core/internal/domain/validation"
+)
 
 var (
 	// ErrDeploymentNotFound is returned when a deployment is not found
@@ -14,5 +19,65 @@ var (
 
 	// ErrProjectNotFound is returned when the associated project is not found
 	ErrProjectNotFound = errors.New("project not found for deployment")
+
+	// ErrNothingToRestart is returned when restarting a project that has never been deployed
+	ErrNothingToRestart = errors.New("project has no deployment to restart")
+
+	// ErrDeploymentInProgress is returned when a project already has a deployment in progress
+	ErrDeploymentInProgress = errors.New("a deployment is already in progress for this project")
+
+	// ErrDeploymentArchived is returned when modifying a deployment that has been moved to the archive
+	ErrDeploymentArchived = errors.New("deployment is archived and can no longer be modified")
+
+	// ErrDeploymentPinned is returned when deleting a deployment that is pinned
+	ErrDeploymentPinned = errors.New("deployment is pinned and can't be deleted")
+
+	// ErrBuildQueueFull is returned when too many builds are queued to accept another deployment
+	ErrBuildQueueFull = errors.New("build queue is full")
+
+	// ErrTooManyDeployments is returned when a user already has the maximum number of deployments in progress
+	ErrTooManyDeployments = errors.New("too many deployments in progress")
+
+	// ErrNoBuildJob is returned when there is no build job waiting to be claimed
+	ErrNoBuildJob = errors.New("no build job to claim")
+
+	// ErrNotWaitingApproval is returned when approving or rejecting a deployment that isn't waiting for approval
+	ErrNotWaitingApproval = errors.New("deployment is not waiting for approval")
+
+	// ErrSelfApproval is returned when the user who started a deployment approves it
+	ErrSelfApproval = errors.New("deployments can't be approved by the user who started them")
+
+	// ErrNotApprover is returned when a user who isn't an approver of its environment decides on a deployment
+	ErrNotApprover = errors.New("user is not an approver of the deployment's environment")
+
+	// ErrInvalidApprovers is returned when designating approvers of an environment that isn't protected, or users that don't exist
+	ErrInvalidApprovers = validation.Errorf("approvers must be at most %d existing users of a protected environment", MaxApprovers)
+
+	// ErrManifestNotFound is returned when no manifest was recorded for a deployment
+	ErrManifestNotFound = errors.New("deployment manifest not found")
+
+	// ErrScanNotFound is returned when a deployment's image wasn't scanned
+	ErrScanNotFound = errors.New("deployment image scan not found")
+
+	// ErrImageVulnerable is returned when a deployment is stopped because its image has too many critical vulnerabilities
+	ErrImageVulnerable = errors.New("image has more critical vulnerabilities than allowed")
+
+	// ErrTargetUnavailable is returned when a project is deployed in a way this platform isn't configured for
+	ErrTargetUnavailable = errors.New("deployment target is not available on this platform")
+
+	// ErrInvalidStep is returned when a pipeline step isn't one deployments go through
+	ErrInvalidStep = validation.New("invalid deployment step")
+
+	// ErrNotRetryable is returned when retrying a deployment that can't pick up from the requested step
+	ErrNotRetryable = errors.New("deployment cannot be retried")
+
+	// ErrInvalidAnnotations is returned when the title, description or labels of a deployment are invalid
+	ErrInvalidAnnotations = validation.New("invalid deployment annotations")
+
+	// ErrLogLineTooLong is returned when a client appends a line longer than MaxLogLineBytes to a deployment's logs
+	ErrLogLineTooLong = validation.New(fmt.Sprintf("log line must be at most %d bytes", MaxLogLineBytes))
+
+	// ErrNothingToDeploy is returned when deploying what a project runs and it has no successful deployment
+	ErrNothingToDeploy = errors.New("project has no successful deployment to deploy")
 )
 
@@ -10,6 +10,7 @@ const (
 	EventTypeDeploymentStatusChanged = "deployment.status_changed"
 	EventTypeDeploymentCompleted     = "deployment.completed"
 	EventTypeDeploymentFailed        = "deployment.failed"
+	EventTypeMigrationFinished       = "deployment.migration_finished"
 )
 
 // DeploymentCreated is raised when a new deployment is created
@@ -37,14 +38,16 @@ func NewDeploymentCreated(deploymentID, projectID, userID, commitHash, branch st
 type DeploymentStatusChanged struct {
 	events.BaseEvent
 	DeploymentID string
+	ProjectID    string
 	OldStatus    string
 	NewStatus    string
 }
 
-func NewDeploymentStatusChanged(deploymentID, oldStatus, newStatus string) *DeploymentStatusChanged {
+func NewDeploymentStatusChanged(deploymentID, projectID, oldStatus, newStatus string) *DeploymentStatusChanged {
 	return &DeploymentStatusChanged{
 		BaseEvent:    events.NewBaseEvent(EventTypeDeploymentStatusChanged, deploymentID),
 		DeploymentID: deploymentID,
+		ProjectID:    projectID,
 		OldStatus:    oldStatus,
 		NewStatus:    newStatus,
 	}
@@ -82,3 +85,21 @@ func NewDeploymentFailed(deploymentID, projectID, errMsg string) *DeploymentFail
 	}
 }
 
+// MigrationFinished is raised when a deployment's database migration task finishes
+type MigrationFinished struct {
+	events.BaseEvent
+	DeploymentID string
+	ProjectID    string
+	Succeeded    bool
+	Detail       string
+}
+
+func NewMigrationFinished(deploymentID, projectID string, succeeded bool, detail string) *MigrationFinished {
+	return &MigrationFinished{
+		BaseEvent:    events.NewBaseEvent(EventTypeMigrationFinished, deploymentID),
+		DeploymentID: deploymentID,
+		ProjectID:    projectID,
+		Succeeded:    succeeded,
+		Detail:       detail,
+	}
+}
@@ -0,0 +1,72 @@
+package deployment
+
+import (
+	"sort"
+	"time"
+
+	"snapdeploy-core/internal/domain/project"
+)
+
+// Manifest records what a deployment was built from besides its commit, so two deployments can be compared
+type Manifest struct {
+	DeploymentID DeploymentID
+	ProjectID    project.ProjectID
+	ImageTag     string
+	EnvVars      map[string]string // Keyed digest of each environment variable's value by name, never the value
+	Config       map[string]string // Project settings the deployment was built with, by name
+	CreatedAt    time.Time
+}
+
+// ConfigChange is a project setting that differs between two deployments. From or To is empty when the
+// setting was only set on one of them.
+type ConfigChange struct {
+	Key  string
+	From string
+	To   string
+}
+
+// ManifestChanges are the differences between the manifests of two deployments
+type ManifestChanges struct {
+	EnvVarsAdded   []string // Names set on the later deployment only
+	EnvVarsRemoved []string // Names set on the earlier deployment only
+	EnvVarsChanged []string // Names set on both with different values
+	Config         []ConfigChange
+}
+
+// Diff returns what changed from the manifest m to the manifest to, with names sorted
+func (m *Manifest) Diff(to *Manifest) ManifestChanges {
+	var changes ManifestChanges
+	for key, digest := range to.EnvVars {
+		previous, ok := m.EnvVars[key]
+		switch {
+		case !ok:
+			changes.EnvVarsAdded = append(changes.EnvVarsAdded, key)
+		case previous != digest:
+			changes.EnvVarsChanged = append(changes.EnvVarsChanged, key)
+		}
+	}
+	for key := range m.EnvVars {
+		if _, ok := to.EnvVars[key]; !ok {
+			changes.EnvVarsRemoved = append(changes.EnvVarsRemoved, key)
+		}
+	}
+
+	for key, value := range to.Config {
+		if previous := m.Config[key]; previous != value {
+			changes.Config = append(changes.Config, ConfigChange{Key: key, From: previous, To: value})
+		}
+	}
+	for key, value := range m.Config {
+		if _, ok := to.Config[key]; !ok && value != "" {
+			changes.Config = append(changes.Config, ConfigChange{Key: key, From: value})
+		}
+	}
+
+	sort.Strings(changes.EnvVarsAdded)
+	sort.Strings(changes.EnvVarsRemoved)
+	sort.Strings(changes.EnvVarsChanged)
+	sort.Slice(changes.Config, func(i, j int) bool {
+		return changes.Config[i].Key < changes.Config[j].Key
+	})
+	return changes
+}
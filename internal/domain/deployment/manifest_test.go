@@ -0,0 +1,52 @@
+package deployment_test
+
+import (
+	"reflect"
+	"testing"
+
+	"snapdeploy-core/internal/domain/deployment"
+)
+
+func TestManifest_Diff(t *testing.T) {
+	from := &deployment.Manifest{
+		EnvVars: map[string]string{"API_KEY": "a1", "DEBUG": "b1", "SENTRY_DSN": "c1"},
+		Config:  map[string]string{"port": "3000", "memory": "512", "volume": "/data (1 GB)"},
+	}
+	to := &deployment.Manifest{
+		EnvVars: map[string]string{"API_KEY": "a2", "SENTRY_DSN": "c1", "STRIPE_KEY": "d1"},
+		Config:  map[string]string{"port": "8080", "memory": "512", "canary_percent": "10"},
+	}
+
+	changes := from.Diff(to)
+
+	if !reflect.DeepEqual(changes.EnvVarsAdded, []string{"STRIPE_KEY"}) {
+		t.Errorf("EnvVarsAdded = %v", changes.EnvVarsAdded)
+	}
+	if !reflect.DeepEqual(changes.EnvVarsRemoved, []string{"DEBUG"}) {
+		t.Errorf("EnvVarsRemoved = %v", changes.EnvVarsRemoved)
+	}
+	if !reflect.DeepEqual(changes.EnvVarsChanged, []string{"API_KEY"}) {
+		t.Errorf("EnvVarsChanged = %v", changes.EnvVarsChanged)
+	}
+
+	wantConfig := []deployment.ConfigChange{
+		{Key: "canary_percent", To: "10"},
+		{Key: "port", From: "3000", To: "8080"},
+		{Key: "volume", From: "/data (1 GB)"},
+	}
+	if !reflect.DeepEqual(changes.Config, wantConfig) {
+		t.Errorf("Config = %+v, want %+v", changes.Config, wantConfig)
+	}
+}
+
+func TestManifest_DiffUnchanged(t *testing.T) {
+	manifest := &deployment.Manifest{
+		EnvVars: map[string]string{"API_KEY": "a1"},
+		Config:  map[string]string{"port": "3000"},
+	}
+
+	changes := manifest.Diff(manifest)
+	if len(changes.EnvVarsAdded)+len(changes.EnvVarsRemoved)+len(changes.EnvVarsChanged)+len(changes.Config) != 0 {
+		t.Errorf("Diff() of a manifest with itself = %+v, want no changes", changes)
+	}
+}
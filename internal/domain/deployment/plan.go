@@ -0,0 +1,24 @@
+package deployment
+
+// ChangeAction is what a deployment would do to a cloud resource
+type ChangeAction string
+
+const (
+	ChangeCreate  ChangeAction = "CREATE"
+	ChangeUpdate  ChangeAction = "UPDATE"
+	ChangeReplace ChangeAction = "REPLACE"
+	ChangeDelete  ChangeAction = "DELETE"
+	ChangeRun     ChangeAction = "RUN"
+)
+
+func (a ChangeAction) String() string {
+	return string(a)
+}
+
+// PlannedChange is a change a deployment would make to a cloud resource, reported by dry runs
+type PlannedChange struct {
+	Action   ChangeAction
+	Resource string // Kind of resource, such as "ECS service" or "DNS record"
+	Name     string
+	Detail   string
+}
@@ -0,0 +1,78 @@
+package deployment
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// redactedPlaceholder replaces the secrets masked in logs
+const redactedPlaceholder = "***"
+
+// minSecretLength is the length under which known values aren't masked, so values like "true" or "3000"
+// don't blank out every line mentioning them
+const minSecretLength = 8
+
+// secretPatterns match secrets by their shape, masked whether or not their value is known. Patterns with a
+// group only mask the group, keeping what identifies the secret readable.
+var secretPatterns = []*regexp.Regexp{
+	// AWS access key IDs
+	regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`),
+	// AWS secret access keys and session tokens assigned to their usual names
+	regexp.MustCompile(`(?i)aws_(?:secret_access_key|session_token)["']?\s*[=:]\s*["']?([A-Za-z0-9/+=]{16,})`),
+	// GitHub, GitLab and Bitbucket tokens
+	regexp.MustCompile(`\b((?:gh[pousr]_[A-Za-z0-9]{36,})|(?:github_pat_[A-Za-z0-9_]{22,})|(?:glpat-[A-Za-z0-9_-]{20,})|(?:ATBB[A-Za-z0-9]{24,}))\b`),
+	// Stripe and Slack keys
+	regexp.MustCompile(`\b((?:[sr]k_(?:live|test)_[A-Za-z0-9]{16,})|(?:xox[abpors]-[A-Za-z0-9-]{10,}))\b`),
+	// Bearer tokens of Authorization headers
+	regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/-]{16,}=*)`),
+	// Passwords in URLs, such as database connection strings
+	regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@]+:([^/\s@]+)@`),
+}
+
+// LogRedactor masks secrets in deployment logs before they are stored or streamed: the values it was
+// given, such as the project's environment variables, and anything shaped like a token or AWS key.
+// The zero value only masks by shape.
+type LogRedactor struct {
+	secrets []string // Longest first, so a secret containing another is masked whole
+}
+
+// Add adds values to mask. Empty and short values are ignored.
+func (r *LogRedactor) Add(values ...string) {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if len(value) < minSecretLength || r.has(value) {
+			continue
+		}
+		r.secrets = append(r.secrets, value)
+	}
+	sort.SliceStable(r.secrets, func(i, j int) bool {
+		return len(r.secrets[i]) > len(r.secrets[j])
+	})
+}
+
+func (r *LogRedactor) has(value string) bool {
+	for _, secret := range r.secrets {
+		if secret == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns the line with its secrets masked
+func (r *LogRedactor) Redact(line string) string {
+	for _, secret := range r.secrets {
+		line = strings.ReplaceAll(line, secret, redactedPlaceholder)
+	}
+	for _, pattern := range secretPatterns {
+		line = pattern.ReplaceAllStringFunc(line, func(match string) string {
+			groups := pattern.FindStringSubmatchIndex(match)
+			if len(groups) < 4 || groups[2] < 0 {
+				return redactedPlaceholder
+			}
+			return match[:groups[2]] + redactedPlaceholder + match[groups[3]:]
+		})
+	}
+	return line
+}
@@ -2,6 +2,7 @@ package deployment
 
 import (
 	"context"
+	"time"
 
 	"snapdeploy-core/internal/domain/project"
 	"snapdeploy-core/internal/domain/user"
@@ -15,22 +16,156 @@ type DeploymentRepository interface {
 	// FindByID retrieves a deployment by its ID
 	FindByID(ctx context.Context, id DeploymentID) (*Deployment, error)
 
-	// FindByProjectID retrieves all deployments for a project with pagination
-	FindByProjectID(ctx context.Context, projectID project.ProjectID, limit, offset int32) ([]*Deployment, error)
+	// FindByIDIncludingDeleted retrieves a deployment by its ID, even if it was deleted
+	FindByIDIncludingDeleted(ctx context.Context, id DeploymentID) (*Deployment, error)
 
-	// FindByUserID retrieves all deployments for a user with pagination
-	FindByUserID(ctx context.Context, userID user.UserID, limit, offset int32) ([]*Deployment, error)
+	// FindByProjectID retrieves the deployments for a project matching the filter with pagination
+	FindByProjectID(ctx context.Context, projectID project.ProjectID, filter ListFilter, limit, offset int32) ([]*Deployment, error)
 
-	// CountByProjectID counts total deployments for a project
-	CountByProjectID(ctx context.Context, projectID project.ProjectID) (int64, error)
+	// FindByProjectIDIncludingDeleted retrieves the deployments for a project matching the filter with pagination, deleted ones included
+	FindByProjectIDIncludingDeleted(ctx context.Context, projectID project.ProjectID, filter ListFilter, limit, offset int32) ([]*Deployment, error)
 
-	// CountByUserID counts total deployments for a user
-	CountByUserID(ctx context.Context, userID user.UserID) (int64, error)
+	// FindByUserID retrieves the deployments for a user matching the filter with pagination
+	FindByUserID(ctx context.Context, userID user.UserID, filter ListFilter, limit, offset int32) ([]*Deployment, error)
 
-	// Delete removes a deployment
+	// FindByProjectIDAfter retrieves up to limit deployments for a project matching the filter that come after the cursor
+	FindByProjectIDAfter(ctx context.Context, projectID project.ProjectID, filter ListFilter, after Cursor, limit int32) ([]*Deployment, error)
+
+	// FindByProjectIDIncludingDeletedAfter retrieves up to limit deployments for a project matching the filter that come after the cursor, deleted ones included
+	FindByProjectIDIncludingDeletedAfter(ctx context.Context, projectID project.ProjectID, filter ListFilter, after Cursor, limit int32) ([]*Deployment, error)
+
+	// FindByUserIDAfter retrieves up to limit deployments for a user matching the filter that come after the cursor
+	FindByUserIDAfter(ctx context.Context, userID user.UserID, filter ListFilter, after Cursor, limit int32) ([]*Deployment, error)
+
+	// CountByProjectID counts the deployments for a project matching the filter
+	CountByProjectID(ctx context.Context, projectID project.ProjectID, filter ListFilter) (int64, error)
+
+	// CountByProjectIDIncludingDeleted counts the deployments for a project matching the filter, deleted ones included
+	CountByProjectIDIncludingDeleted(ctx context.Context, projectID project.ProjectID, filter ListFilter) (int64, error)
+
+	// CountByUserID counts the deployments for a user matching the filter
+	CountByUserID(ctx context.Context, userID user.UserID, filter ListFilter) (int64, error)
+
+	// CountInProgressByUserID counts a user's deployments that are pending, building or deploying
+	CountInProgressByUserID(ctx context.Context, userID user.UserID) (int64, error)
+
+	// Delete soft deletes a deployment and cancels its build if it hasn't started.
+	// Other queries no longer find it, and PurgeDeletedBefore removes it for good once the retention period is over.
 	Delete(ctx context.Context, id DeploymentID) error
 
-	// FindLatestByProjectID retrieves the most recent deployment for a project
+	// PurgeDeletedBefore permanently removes up to limit deployments deleted before the cutoff, with their logs and timeline
+	PurgeDeletedBefore(ctx context.Context, cutoff time.Time, limit int32) (int64, error)
+
+	// FindLatestByProjectID retrieves the most recent deployment for a project, in any environment
 	FindLatestByProjectID(ctx context.Context, projectID project.ProjectID) (*Deployment, error)
+
+	// FindLatestInEnvironment retrieves the most recent deployment of a project's environment
+	FindLatestInEnvironment(ctx context.Context, projectID project.ProjectID, env project.Environment) (*Deployment, error)
+
+	// FindLatestDeployedInEnvironment retrieves the most recent successful deployment of a project's environment
+	FindLatestDeployedInEnvironment(ctx context.Context, projectID project.ProjectID, env project.Environment) (*Deployment, error)
+
+	// FindLatestDeployed retrieves the most recent successful deployment of every project's environments
+	FindLatestDeployed(ctx context.Context) ([]*Deployment, error)
+
+	// FindPinnedByProjectID retrieves the pinned deployments of a project, newest first
+	FindPinnedByProjectID(ctx context.Context, projectID project.ProjectID) ([]*Deployment, error)
+
+	// FindStuck retrieves up to limit in-progress or interrupted deployments that haven't been updated since the cutoff, oldest first
+	FindStuck(ctx context.Context, cutoff time.Time, limit int32) ([]*Deployment, error)
+
+	// ArchiveBefore moves up to limit finished deployments created before the cutoff out of the active set,
+	// keeping pinned deployments and the latest successful deployment of each project's environments.
+	// Archived deployments remain readable.
+	ArchiveBefore(ctx context.Context, cutoff time.Time, limit int32) (int64, error)
+}
+
+// BuildJobRepository defines the interface for the outbox of builds waiting to be started
+type BuildJobRepository interface {
+	// Enqueue persists a new build job
+	Enqueue(ctx context.Context, job *BuildJob) error
+
+	// ClaimNext claims the oldest pending build job, or a running one whose claim is older than staleBefore
+	// because its worker died. Jobs of users with maxRunningPerUser deployments building or deploying are skipped.
+	// Concurrent workers never claim the same job. Returns ErrNoBuildJob if none is waiting.
+	ClaimNext(ctx context.Context, staleBefore time.Time, maxRunningPerUser int) (*BuildJob, error)
+
+	// CountOpen counts the build jobs that are pending or being started
+	CountOpen(ctx context.Context) (int64, error)
+
+	// Save persists a build job's status
+	Save(ctx context.Context, job *BuildJob) error
+
+	// Requeue queues the build job of a deployment whose build was interrupted, or which is retried, again with
+	// no attempt counted, so a worker starts it over
+	Requeue(ctx context.Context, deploymentID DeploymentID) error
 }
 
+// ApprovalRepository defines the interface for persisting the decisions on deployments to protected environments
+type ApprovalRepository interface {
+	// Save records a decision on a deployment
+	Save(ctx context.Context, approval Approval) error
+
+	// FindByDeploymentID retrieves the decisions on a deployment in chronological order
+	FindByDeploymentID(ctx context.Context, deploymentID DeploymentID) ([]Approval, error)
+
+	// FindApprovers retrieves the users designated to decide on the deployments to a project's environment
+	FindApprovers(ctx context.Context, projectID project.ProjectID, env project.Environment) ([]user.UserID, error)
+
+	// SetApprovers replaces the approvers of a project's environment.
+	// Returns ErrInvalidApprovers when one of the users doesn't exist.
+	SetApprovers(ctx context.Context, projectID project.ProjectID, env project.Environment, approvers []user.UserID) error
+}
+
+// TimelineRepository defines the interface for persisting a deployment's platform timeline
+type TimelineRepository interface {
+	// Append adds an entry to a deployment's timeline
+	Append(ctx context.Context, deploymentID DeploymentID, projectID project.ProjectID, entry TimelineEntry) error
+
+	// FindByDeploymentID retrieves a deployment's timeline in chronological order
+	FindByDeploymentID(ctx context.Context, deploymentID DeploymentID) ([]TimelineEntry, error)
+}
+
+// ManifestRepository defines the interface for persisting what deployments were built from
+type ManifestRepository interface {
+	// Save records a deployment's manifest, replacing the one recorded by an earlier attempt of its build
+	Save(ctx context.Context, manifest *Manifest) error
+
+	// FindByDeploymentID retrieves a deployment's manifest. Returns ErrManifestNotFound for deployments
+	// whose build never started.
+	FindByDeploymentID(ctx context.Context, deploymentID DeploymentID) (*Manifest, error)
+}
+
+// ScanRepository defines the interface for persisting what scanning deployment images found
+type ScanRepository interface {
+	// Save records the scan of a deployment's image, replacing the one recorded by an earlier attempt
+	Save(ctx context.Context, report *ScanReport) error
+
+	// FindByDeploymentID retrieves the scan of a deployment's image. Returns ErrScanNotFound for deployments
+	// whose image wasn't scanned.
+	FindByDeploymentID(ctx context.Context, deploymentID DeploymentID) (*ScanReport, error)
+}
+
+// StepRepository defines the interface for persisting the pipeline steps of deployments
+type StepRepository interface {
+	// Save records a deployment's step, replacing the record of an earlier attempt of the step
+	Save(ctx context.Context, record StepRecord) error
+
+	// FindByDeploymentID retrieves a deployment's step records in pipeline order
+	FindByDeploymentID(ctx context.Context, deploymentID DeploymentID) ([]StepRecord, error)
+}
+
+// EnvChangeRepository defines the interface for recording changes to the environment variables of
+// project environments and the deployments that pick them up
+type EnvChangeRepository interface {
+	// RecordChange records that an environment's variables changed, pending until a deployment picks it up
+	RecordChange(ctx context.Context, projectID project.ProjectID, env project.Environment, changedAt time.Time) error
+
+	// MarkApplied records that a deployment created at createdAt succeeded, picking up the environment's
+	// pending change if it was made before then. Reports whether a change was picked up.
+	MarkApplied(ctx context.Context, projectID project.ProjectID, env project.Environment, deploymentID DeploymentID, createdAt time.Time) (bool, error)
+
+	// Find retrieves the latest change to an environment's variables, or nil if they never changed
+	// since changes are recorded
+	Find(ctx context.Context, projectID project.ProjectID, env project.Environment) (*EnvChange, error)
+}
@@ -0,0 +1,173 @@
+package deployment
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"snapdeploy-core/internal/domain/project"
+)
+
+// maxReportedVulnerabilities bounds the vulnerabilities kept on a scan report, the most severe first.
+// Severity counts always cover all of them.
+const maxReportedVulnerabilities = 100
+
+// Severity is how severe a vulnerability is, as rated by the scanner
+type Severity string
+
+const (
+	SeverityCritical      Severity = "CRITICAL"
+	SeverityHigh          Severity = "HIGH"
+	SeverityMedium        Severity = "MEDIUM"
+	SeverityLow           Severity = "LOW"
+	SeverityInformational Severity = "INFORMATIONAL"
+	SeverityUndefined     Severity = "UNDEFINED"
+)
+
+// Severities lists the severities from the most to the least severe
+var Severities = []Severity{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityInformational, SeverityUndefined}
+
+// NewSeverity normalizes a scanner's severity, unknown ones are UNDEFINED
+func NewSeverity(value string) Severity {
+	severity := Severity(strings.ToUpper(strings.TrimSpace(value)))
+	for _, known := range Severities {
+		if severity == known {
+			return severity
+		}
+	}
+	return SeverityUndefined
+}
+
+// rank orders severities, the most severe first
+func (s Severity) rank() int {
+	for i, severity := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return len(Severities)
+}
+
+func (s Severity) String() string {
+	return string(s)
+}
+
+// Vulnerability is a known vulnerability found in a package of an image
+type Vulnerability struct {
+	ID       string // CVE or advisory ID
+	Severity Severity
+	Package  string
+	Version  string
+	URL      string // Where the vulnerability is described
+}
+
+// SBOM is the software bill of materials of an image: the packages it contains
+type SBOM struct {
+	Format   string // e.g. cyclonedx-json
+	Packages int
+	Document []byte
+}
+
+// ScanStatus is how the scan of a deployment's image ended
+type ScanStatus string
+
+const (
+	// ScanCompleted scans found the image's vulnerabilities
+	ScanCompleted ScanStatus = "COMPLETED"
+	// ScanFailed scans couldn't read the image's vulnerabilities, the deployment goes ahead without them
+	ScanFailed ScanStatus = "FAILED"
+)
+
+func (s ScanStatus) String() string {
+	return string(s)
+}
+
+// ScanReport is what scanning a deployment's image found, recorded before the image is deployed
+type ScanReport struct {
+	DeploymentID    DeploymentID
+	ProjectID       project.ProjectID
+	ImageURI        string
+	Status          ScanStatus
+	Error           string // Why the vulnerabilities or the SBOM couldn't be read
+	SeverityCounts  map[Severity]int
+	Vulnerabilities []Vulnerability // The most severe first, at most maxReportedVulnerabilities
+	SBOM            *SBOM           // Nil if no SBOM could be generated
+	Blocked         bool            // Whether the deployment was stopped for the vulnerabilities found
+	CreatedAt       time.Time
+}
+
+// NewScanReport starts the report of scanning a deployment's image
+func NewScanReport(dep *Deployment, imageURI string) *ScanReport {
+	return &ScanReport{
+		DeploymentID:   dep.ID(),
+		ProjectID:      dep.ProjectID(),
+		ImageURI:       imageURI,
+		Status:         ScanCompleted,
+		SeverityCounts: map[Severity]int{},
+		CreatedAt:      time.Now(),
+	}
+}
+
+// RecordVulnerabilities records the vulnerabilities found and the counts of each severity, keeping the
+// most severe vulnerabilities if there are too many to keep them all
+func (r *ScanReport) RecordVulnerabilities(vulnerabilities []Vulnerability, counts map[Severity]int) {
+	sorted := append([]Vulnerability(nil), vulnerabilities...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Severity.rank() < sorted[j].Severity.rank()
+	})
+	if len(sorted) > maxReportedVulnerabilities {
+		sorted = sorted[:maxReportedVulnerabilities]
+	}
+
+	r.Vulnerabilities = sorted
+	r.SeverityCounts = map[Severity]int{}
+	for severity, count := range counts {
+		if count > 0 {
+			r.SeverityCounts[severity] += count
+		}
+	}
+}
+
+// Fail records that the image's vulnerabilities couldn't be read
+func (r *ScanReport) Fail(err error) {
+	r.Status = ScanFailed
+	r.addError(fmt.Sprintf("scan failed: %v", err))
+}
+
+// FailSBOM records that the image's SBOM couldn't be generated. The scan itself isn't failed.
+func (r *ScanReport) FailSBOM(err error) {
+	r.addError(fmt.Sprintf("SBOM generation failed: %v", err))
+}
+
+func (r *ScanReport) addError(message string) {
+	if r.Error != "" {
+		r.Error += "; "
+	}
+	r.Error += message
+}
+
+// Critical returns the number of critical vulnerabilities found
+func (r *ScanReport) Critical() int {
+	return r.SeverityCounts[SeverityCritical]
+}
+
+// Exceeds reports whether the scan found more critical vulnerabilities than maxCritical.
+// A negative maxCritical allows any number, and failed scans never exceed it.
+func (r *ScanReport) Exceeds(maxCritical int) bool {
+	return maxCritical >= 0 && r.Status == ScanCompleted && r.Critical() > maxCritical
+}
+
+// Summary describes the vulnerabilities found by severity, e.g. "2 critical, 5 high"
+func (r *ScanReport) Summary() string {
+	var parts []string
+	for _, severity := range Severities {
+		if count := r.SeverityCounts[severity]; count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, strings.ToLower(severity.String())))
+		}
+	}
+	if len(parts) == 0 {
+		return "no vulnerabilities"
+	}
+	return strings.Join(parts, ", ")
+}
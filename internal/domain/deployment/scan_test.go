@@ -0,0 +1,76 @@
+package deployment_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"snapdeploy-core/internal/domain/deployment"
+)
+
+func TestScanReport_RecordVulnerabilities(t *testing.T) {
+	var vulnerabilities []deployment.Vulnerability
+	for i := 0; i < 150; i++ {
+		vulnerabilities = append(vulnerabilities, deployment.Vulnerability{ID: fmt.Sprintf("CVE-2024-%04d", i), Severity: deployment.SeverityLow})
+	}
+	vulnerabilities = append(vulnerabilities,
+		deployment.Vulnerability{ID: "CVE-2024-9001", Severity: deployment.NewSeverity("high")},
+		deployment.Vulnerability{ID: "CVE-2024-9002", Severity: deployment.SeverityCritical},
+	)
+
+	report := &deployment.ScanReport{Status: deployment.ScanCompleted}
+	report.RecordVulnerabilities(vulnerabilities, map[deployment.Severity]int{
+		deployment.SeverityCritical: 1,
+		deployment.SeverityHigh:     1,
+		deployment.SeverityMedium:   0,
+		deployment.SeverityLow:      150,
+	})
+
+	if len(report.Vulnerabilities) != 100 {
+		t.Fatalf("len(Vulnerabilities) = %d, want 100", len(report.Vulnerabilities))
+	}
+	if report.Vulnerabilities[0].ID != "CVE-2024-9002" || report.Vulnerabilities[1].ID != "CVE-2024-9001" {
+		t.Errorf("Vulnerabilities start with %s, %s, want the critical then the high one", report.Vulnerabilities[0].ID, report.Vulnerabilities[1].ID)
+	}
+	if report.Vulnerabilities[2].ID != "CVE-2024-0000" {
+		t.Errorf("Vulnerabilities[2] = %s, want the order of equally severe ones kept", report.Vulnerabilities[2].ID)
+	}
+	if got := report.Summary(); got != "1 critical, 1 high, 150 low" {
+		t.Errorf("Summary() = %q", got)
+	}
+}
+
+func TestScanReport_Exceeds(t *testing.T) {
+	report := &deployment.ScanReport{Status: deployment.ScanCompleted}
+	report.RecordVulnerabilities(nil, map[deployment.Severity]int{deployment.SeverityCritical: 2})
+
+	tests := []struct {
+		maxCritical int
+		want        bool
+	}{
+		{-1, false},
+		{0, true},
+		{1, true},
+		{2, false},
+	}
+	for _, tt := range tests {
+		if got := report.Exceeds(tt.maxCritical); got != tt.want {
+			t.Errorf("Exceeds(%d) = %v, want %v", tt.maxCritical, got, tt.want)
+		}
+	}
+
+	report.Fail(errors.New("timed out"))
+	if report.Exceeds(0) {
+		t.Error("failed scan exceeds the threshold")
+	}
+	if report.Status != deployment.ScanFailed || report.Error != "scan failed: timed out" {
+		t.Errorf("Status = %s, Error = %q", report.Status, report.Error)
+	}
+}
+
+func TestScanReport_Summary(t *testing.T) {
+	report := &deployment.ScanReport{Status: deployment.ScanCompleted}
+	if got := report.Summary(); got != "no vulnerabilities" {
+		t.Errorf("Summary() = %q, want %q", got, "no vulnerabilities")
+	}
+}
@@ -0,0 +1,157 @@
+package deployment
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"snapdeploy-core/internal/domain/project"
+)
+
+// Step is a stage of the pipeline taking a commit to a running deployment
+type Step string
+
+const (
+	StepClone   Step = "clone"
+	StepBuild   Step = "build"
+	StepPush    Step = "push"
+	StepDB      Step = "db"
+	StepMigrate Step = "migrate"
+	StepALB     Step = "alb"
+	StepECS     Step = "ecs"
+	StepDNS     Step = "dns"
+)
+
+// Steps lists the pipeline steps in the order they run
+var Steps = []Step{StepClone, StepBuild, StepPush, StepDB, StepMigrate, StepALB, StepECS, StepDNS}
+
+// ParseStep parses the name of a pipeline step
+func ParseStep(value string) (Step, error) {
+	step := Step(strings.ToLower(strings.TrimSpace(value)))
+	for _, known := range Steps {
+		if step == known {
+			return step, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q, must be one of %s", ErrInvalidStep, value, joinSteps(Steps))
+}
+
+// ReusesImage reports whether the step runs after the image is pushed, so a deployment resumed from it
+// deploys the image it already built
+func (s Step) ReusesImage() bool {
+	return s.index() > StepPush.index()
+}
+
+// Before reports whether the step runs before another one
+func (s Step) Before(other Step) bool {
+	return s.index() < other.index()
+}
+
+// index is the position of the step in the pipeline
+func (s Step) index() int {
+	for i, step := range Steps {
+		if s == step {
+			return i
+		}
+	}
+	return len(Steps)
+}
+
+func (s Step) String() string {
+	return string(s)
+}
+
+// joinSteps lists steps for error messages
+func joinSteps(steps []Step) string {
+	names := make([]string, len(steps))
+	for i, step := range steps {
+		names[i] = step.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// StepStatus is where a pipeline step of a deployment is at
+type StepStatus string
+
+const (
+	StepRunning   StepStatus = "RUNNING"
+	StepSucceeded StepStatus = "SUCCEEDED"
+	StepFailed    StepStatus = "FAILED"
+	StepSkipped   StepStatus = "SKIPPED" // Carried over from an earlier attempt of a resumed deployment
+)
+
+func (s StepStatus) String() string {
+	return string(s)
+}
+
+// Done reports whether the step's result can be carried over when the deployment is resumed after it
+func (s StepStatus) Done() bool {
+	return s == StepSucceeded || s == StepSkipped
+}
+
+// StepRecord is the outcome of a pipeline step of a deployment. A deployment keeps one record per step,
+// which the latest attempt of the step replaces.
+type StepRecord struct {
+	DeploymentID DeploymentID
+	ProjectID    project.ProjectID
+	Step         Step
+	Status       StepStatus
+	Error        string
+	StartedAt    time.Time
+	FinishedAt   *time.Time // Nil while the step is running
+}
+
+// StartStep records that a deployment's step is running
+func StartStep(dep *Deployment, step Step) StepRecord {
+	return StepRecord{
+		DeploymentID: dep.id,
+		ProjectID:    dep.projectID,
+		Step:         step,
+		Status:       StepRunning,
+		StartedAt:    time.Now(),
+	}
+}
+
+// SkipStep records that a resumed deployment carried a step's result over from its earlier attempt
+func SkipStep(dep *Deployment, step Step) StepRecord {
+	record := StartStep(dep, step)
+	record.Status = StepSkipped
+	record.FinishedAt = &record.StartedAt
+	return record
+}
+
+// Finish records the outcome of a running step, failed if err isn't nil
+func (r *StepRecord) Finish(err error) {
+	now := time.Now()
+	r.FinishedAt = &now
+	r.Status = StepSucceeded
+	if err != nil {
+		r.Status = StepFailed
+		r.Error = err.Error()
+	}
+}
+
+// ResumePoint returns the step a failed deployment with these step records picks up from when retried: the
+// first step that failed or never finished, or else the one after the last completed step. Steps that don't
+// apply to a project, such as migrations of a project without a migration command, have no record.
+func ResumePoint(records []StepRecord) Step {
+	statuses := make(map[Step]StepStatus, len(records))
+	for _, record := range records {
+		statuses[record.Step] = record.Status
+	}
+
+	resume := Steps[0]
+	for i, step := range Steps {
+		status, ok := statuses[step]
+		if !ok {
+			continue
+		}
+		if !status.Done() {
+			return step
+		}
+		if i+1 < len(Steps) {
+			resume = Steps[i+1]
+		}
+	}
+	return resume
+}
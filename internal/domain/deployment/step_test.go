@@ -0,0 +1,112 @@
+package deployment_test
+
+import (
+	"errors"
+	"testing"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestParseStep(t *testing.T) {
+	step, err := deployment.ParseStep(" ECS ")
+	if err != nil || step != deployment.StepECS {
+		t.Errorf("ParseStep(ECS) = %v, %v, want %v", step, err, deployment.StepECS)
+	}
+	if _, err := deployment.ParseStep("deploy"); !errors.Is(err, deployment.ErrInvalidStep) {
+		t.Errorf("ParseStep(deploy) error = %v, want ErrInvalidStep", err)
+	}
+	if deployment.StepPush.ReusesImage() || !deployment.StepDB.ReusesImage() {
+		t.Error("steps after the push should reuse the image, earlier ones shouldn't")
+	}
+}
+
+func TestResumePoint(t *testing.T) {
+	dep, err := deployment.NewDeployment(project.NewProjectID(), user.NewUserID(), "abc123def456", "main", project.EnvironmentProduction)
+	if err != nil {
+		t.Fatalf("NewDeployment() error = %v", err)
+	}
+
+	if got := deployment.ResumePoint(nil); got != deployment.StepClone {
+		t.Errorf("ResumePoint() without records = %v, want %v", got, deployment.StepClone)
+	}
+
+	var records []deployment.StepRecord
+	for _, step := range []deployment.Step{deployment.StepClone, deployment.StepBuild, deployment.StepPush, deployment.StepDB} {
+		record := deployment.StartStep(dep, step)
+		record.Finish(nil)
+		records = append(records, record)
+	}
+	records = append(records, deployment.SkipStep(dep, deployment.StepMigrate))
+	failed := deployment.StartStep(dep, deployment.StepALB)
+	failed.Finish(errors.New("listener rule limit reached"))
+	records = append(records, failed)
+
+	if got := deployment.ResumePoint(records); got != deployment.StepALB {
+		t.Errorf("ResumePoint() = %v, want the failed %v step", got, deployment.StepALB)
+	}
+
+	// Steps that don't apply to the project have no record
+	built := records[:3:3]
+	if got := deployment.ResumePoint(append(built, failed)); got != deployment.StepALB {
+		t.Errorf("ResumePoint() without db and migrate records = %v, want %v", got, deployment.StepALB)
+	}
+	if got := deployment.ResumePoint(built); got != deployment.StepDB {
+		t.Errorf("ResumePoint() after the push = %v, want %v", got, deployment.StepDB)
+	}
+	if failed.Status != deployment.StepFailed || failed.Error != "listener rule limit reached" || failed.FinishedAt == nil {
+		t.Errorf("failed step = %+v, want it finished with its error", failed)
+	}
+}
+
+func TestDeployment_Retry(t *testing.T) {
+	newFailed := func(t *testing.T, imageURI string) *deployment.Deployment {
+		t.Helper()
+		dep, err := deployment.NewDeployment(project.NewProjectID(), user.NewUserID(), "abc123def456", "main", project.EnvironmentProduction)
+		if err != nil {
+			t.Fatalf("NewDeployment() error = %v", err)
+		}
+		if imageURI != "" {
+			dep.RecordImage(imageURI, "")
+		}
+		if err := dep.UpdateStatus(deployment.StatusFailed); err != nil {
+			t.Fatalf("UpdateStatus(FAILED) error = %v", err)
+		}
+		return dep
+	}
+
+	t.Run("reuses the pushed image", func(t *testing.T) {
+		dep := newFailed(t, "registry.example.com/app:abc123d")
+		if err := dep.Retry(deployment.StepECS); err != nil {
+			t.Fatalf("Retry(ecs) error = %v", err)
+		}
+		if dep.Status() != deployment.StatusDeploying {
+			t.Errorf("Status() = %v, want %v", dep.Status(), deployment.StatusDeploying)
+		}
+	})
+
+	t.Run("rebuilds from a build step", func(t *testing.T) {
+		dep := newFailed(t, "")
+		if err := dep.Retry(deployment.StepBuild); err != nil {
+			t.Fatalf("Retry(build) error = %v", err)
+		}
+		if dep.Status() != deployment.StatusPending {
+			t.Errorf("Status() = %v, want %v", dep.Status(), deployment.StatusPending)
+		}
+	})
+
+	t.Run("needs a pushed image to skip the build", func(t *testing.T) {
+		dep := newFailed(t, "")
+		if err := dep.Retry(deployment.StepMigrate); !errors.Is(err, deployment.ErrNotRetryable) {
+			t.Errorf("Retry(migrate) error = %v, want ErrNotRetryable", err)
+		}
+	})
+
+	t.Run("only failed deployments", func(t *testing.T) {
+		dep, _ := deployment.NewDeployment(project.NewProjectID(), user.NewUserID(), "abc123def456", "main", project.EnvironmentProduction)
+		if err := dep.Retry(deployment.StepClone); !errors.Is(err, deployment.ErrNotRetryable) {
+			t.Errorf("Retry() of a pending deployment error = %v, want ErrNotRetryable", err)
+		}
+	})
+}
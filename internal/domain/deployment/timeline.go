@@ -0,0 +1,35 @@
+package deployment
+
+import (
+	"sort"
+	"time"
+)
+
+// TimelineSource identifies where a timeline entry came from
+type TimelineSource string
+
+const (
+	TimelineSourcePlatform     TimelineSource = "PLATFORM"
+	TimelineSourceMigration    TimelineSource = "MIGRATION"
+	TimelineSourceECS          TimelineSource = "ECS"
+	TimelineSourceLoadBalancer TimelineSource = "LOAD_BALANCER"
+)
+
+func (s TimelineSource) String() string {
+	return string(s)
+}
+
+// TimelineEntry is a single event in the history of a deployment
+type TimelineEntry struct {
+	OccurredAt time.Time
+	Source     TimelineSource
+	Type       string
+	Message    string
+}
+
+// SortTimeline orders entries chronologically, keeping simultaneous entries in their original order
+func SortTimeline(entries []TimelineEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].OccurredAt.Before(entries[j].OccurredAt)
+	})
+}
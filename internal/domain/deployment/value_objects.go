@@ -1,10 +1,13 @@
 package deployment
 
 import (
-	"fmt"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+
+	"snapdeploy-core/internal/domain/validation"
 )
 
 // DeploymentID is a value object representing a deployment's unique identifier
@@ -21,7 +24,7 @@ func NewDeploymentID() DeploymentID {
 func ParseDeploymentID(id string) (DeploymentID, error) {
 	uid, err := uuid.Parse(id)
 	if err != nil {
-		return DeploymentID{}, fmt.Errorf("invalid deployment ID format: %w", err)
+		return DeploymentID{}, validation.Errorf("invalid deployment ID format: %w", err)
 	}
 	return DeploymentID{value: uid}, nil
 }
@@ -38,16 +41,65 @@ func (id DeploymentID) Equals(other DeploymentID) bool {
 	return id.value == other.value
 }
 
+// Cursor is the position of a deployment in a list sorted by creation time, with the ID breaking ties between
+// deployments created at the same time. Listing after a cursor continues the list past it.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        DeploymentID
+}
+
+// ListFilter narrows a list of deployments and sets its order. The zero value lists all of them, newest first.
+type ListFilter struct {
+	Status    DeploymentStatus  // Only deployments in this status, empty for any
+	Branch    string            // Only deployments of this branch, empty for any
+	Since     time.Time         // Only deployments created at or after it, zero for no lower bound
+	Labels    map[string]string // Only deployments with all these labels, empty for any
+	Ascending bool              // Oldest first instead of newest first
+}
+
+// DeploymentType distinguishes full build deployments from restarts of the running image and redeploys of it
+type DeploymentType string
+
+const (
+	TypeBuild    DeploymentType = "BUILD"
+	TypeRestart  DeploymentType = "RESTART"
+	TypeRedeploy DeploymentType = "REDEPLOY" // Deploys the image again with the current environment variables
+)
+
+// NewDeploymentType creates a new DeploymentType with validation
+func NewDeploymentType(deploymentType string) (DeploymentType, error) {
+	deploymentType = strings.ToUpper(strings.TrimSpace(deploymentType))
+
+	// Deployments persisted before types existed were builds
+	if deploymentType == "" {
+		return TypeBuild, nil
+	}
+
+	switch DeploymentType(deploymentType) {
+	case TypeBuild, TypeRestart, TypeRedeploy:
+		return DeploymentType(deploymentType), nil
+	default:
+		return "", validation.Errorf("invalid deployment type: %s (must be one of: BUILD, RESTART, REDEPLOY)", deploymentType)
+	}
+}
+
+func (t DeploymentType) String() string {
+	return string(t)
+}
+
 // DeploymentStatus represents the status of a deployment
 type DeploymentStatus string
 
 const (
-	StatusPending    DeploymentStatus = "PENDING"
-	StatusBuilding   DeploymentStatus = "BUILDING"
-	StatusDeploying  DeploymentStatus = "DEPLOYING"
-	StatusDeployed   DeploymentStatus = "DEPLOYED"
-	StatusFailed     DeploymentStatus = "FAILED"
-	StatusRolledBack DeploymentStatus = "ROLLED_BACK"
+	StatusPending         DeploymentStatus = "PENDING"
+	StatusWaitingApproval DeploymentStatus = "WAITING_APPROVAL" // Deployment to a protected environment not yet approved
+	StatusBuilding        DeploymentStatus = "BUILDING"
+	StatusDeploying       DeploymentStatus = "DEPLOYING"
+	StatusDeployed        DeploymentStatus = "DEPLOYED"
+	StatusFailed          DeploymentStatus = "FAILED"
+	StatusRolledBack      DeploymentStatus = "ROLLED_BACK"
+	StatusRejected        DeploymentStatus = "REJECTED"    // Deployment to a protected environment that was rejected
+	StatusInterrupted     DeploymentStatus = "INTERRUPTED" // Build cut off by a server shutdown, started over by the next build worker
 )
 
 // NewDeploymentStatus creates a new DeploymentStatus with validation
@@ -55,10 +107,10 @@ func NewDeploymentStatus(status string) (DeploymentStatus, error) {
 	status = strings.ToUpper(strings.TrimSpace(status))
 
 	switch DeploymentStatus(status) {
-	case StatusPending, StatusBuilding, StatusDeploying, StatusDeployed, StatusFailed, StatusRolledBack:
+	case StatusPending, StatusWaitingApproval, StatusBuilding, StatusDeploying, StatusDeployed, StatusFailed, StatusRolledBack, StatusRejected, StatusInterrupted:
 		return DeploymentStatus(status), nil
 	default:
-		return "", fmt.Errorf("invalid deployment status: %s (must be one of: PENDING, BUILDING, DEPLOYING, DEPLOYED, FAILED, ROLLED_BACK)", status)
+		return "", validation.Errorf("invalid deployment status: %s (must be one of: PENDING, WAITING_APPROVAL, BUILDING, DEPLOYING, DEPLOYED, FAILED, ROLLED_BACK, REJECTED, INTERRUPTED)", status)
 	}
 }
 
@@ -68,7 +120,7 @@ func (s DeploymentStatus) String() string {
 
 func (s DeploymentStatus) IsValid() bool {
 	switch s {
-	case StatusPending, StatusBuilding, StatusDeploying, StatusDeployed, StatusFailed, StatusRolledBack:
+	case StatusPending, StatusWaitingApproval, StatusBuilding, StatusDeploying, StatusDeployed, StatusFailed, StatusRolledBack, StatusRejected, StatusInterrupted:
 		return true
 	default:
 		return false
@@ -76,7 +128,7 @@ func (s DeploymentStatus) IsValid() bool {
 }
 
 func (s DeploymentStatus) IsTerminal() bool {
-	return s == StatusDeployed || s == StatusFailed || s == StatusRolledBack
+	return s == StatusDeployed || s == StatusFailed || s == StatusRolledBack || s == StatusRejected
 }
 
 // CommitHash represents a Git commit hash
@@ -89,7 +141,7 @@ func NewCommitHash(hash string) (CommitHash, error) {
 	hash = strings.TrimSpace(hash)
 
 	if hash == "" {
-		return CommitHash{}, fmt.Errorf("commit hash cannot be empty")
+		return CommitHash{}, validation.Errorf("commit hash cannot be empty")
 	}
 
 	// Allow special Git references like HEAD, main, etc.
@@ -103,13 +155,13 @@ func NewCommitHash(hash string) (CommitHash, error) {
 
 	// Git commit hashes are typically 7-40 characters (short or full SHA-1)
 	if len(hash) < 7 || len(hash) > 40 {
-		return CommitHash{}, fmt.Errorf("commit hash must be between 7 and 40 characters (or use HEAD, main, master, develop)")
+		return CommitHash{}, validation.Errorf("commit hash must be between 7 and 40 characters (or use HEAD, main, master, develop)")
 	}
 
 	// Check if it contains only hexadecimal characters
 	for _, c := range hash {
 		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
-			return CommitHash{}, fmt.Errorf("commit hash must contain only hexadecimal characters")
+			return CommitHash{}, validation.Errorf("commit hash must contain only hexadecimal characters")
 		}
 	}
 
@@ -120,6 +172,14 @@ func (h CommitHash) String() string {
 	return h.value
 }
 
+// ImageTag returns the tag of the image built from the commit; HEAD builds keep moving the "latest" tag
+func (h CommitHash) ImageTag() string {
+	if h.value == "HEAD" || h.value == "head" {
+		return "latest"
+	}
+	return h.value
+}
+
 func (h CommitHash) Equals(other CommitHash) bool {
 	return h.value == other.value
 }
@@ -134,11 +194,11 @@ func NewBranch(branch string) (Branch, error) {
 	branch = strings.TrimSpace(branch)
 
 	if branch == "" {
-		return Branch{}, fmt.Errorf("branch name cannot be empty")
+		return Branch{}, validation.Errorf("branch name cannot be empty")
 	}
 
 	if len(branch) > 255 {
-		return Branch{}, fmt.Errorf("branch name too long (max 255 characters)")
+		return Branch{}, validation.Errorf("branch name too long (max 255 characters)")
 	}
 
 	return Branch{value: branch}, nil
@@ -152,6 +212,22 @@ func (b Branch) Equals(other Branch) bool {
 	return b.value == other.value
 }
 
+// MaxLogLineBytes bounds a line of deployment logs. Longer lines, such as a minified bundle printed by a build,
+// are cut off, so a single line can't balloon the logs stored and streamed.
+const MaxLogLineBytes = 16 << 10
+
+// limitLogLine cuts a line off at MaxLogLineBytes, without splitting a character, and marks it as cut off
+func limitLogLine(line string) string {
+	if len(line) <= MaxLogLineBytes {
+		return line
+	}
+	size := MaxLogLineBytes
+	for size > 0 && !utf8.RuneStart(line[size]) {
+		size--
+	}
+	return line[:size] + "…"
+}
+
 // DeploymentLog represents the deployment logs
 type DeploymentLog struct {
 	value string
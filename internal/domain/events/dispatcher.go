@@ -3,7 +3,7 @@ package events
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
 )
 
@@ -49,8 +49,8 @@ func (d *Dispatcher) Dispatch(ctx context.Context, event DomainEvent) error {
 		go func(h EventHandler) {
 			defer wg.Done()
 			if err := h(ctx, event); err != nil {
-				log.Printf("Error handling event %s (ID: %s): %v",
-					event.EventType(), event.EventID(), err)
+				slog.ErrorContext(ctx, "Error handling event",
+					"event_type", event.EventType(), "event_id", event.EventID(), "error", err)
 				errChan <- err
 			}
 		}(handler)
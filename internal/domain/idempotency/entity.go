@@ -0,0 +1,115 @@
+package idempotency
+
+import (
+	"fmt"
+	"time"
+)
+
+// Record is a domain entity remembering the response to a request made with an idempotency key,
+// so a client retrying the request gets the same response instead of repeating its side effects
+type Record struct {
+	ownerID      string
+	key          Key
+	route        string
+	requestHash  string
+	statusCode   int
+	responseBody []byte
+	createdAt    time.Time
+	expiresAt    time.Time
+}
+
+// NewRecord creates a record for a request that is about to be handled, kept for the given TTL
+func NewRecord(ownerID string, key Key, route, requestHash string, ttl time.Duration) *Record {
+	now := time.Now()
+	return &Record{
+		ownerID:     ownerID,
+		key:         key,
+		route:       route,
+		requestHash: requestHash,
+		createdAt:   now,
+		expiresAt:   now.Add(ttl),
+	}
+}
+
+// Reconstitute recreates a Record entity from persistence.
+// A zero statusCode means the request is still in progress.
+func Reconstitute(
+	ownerID, key, route, requestHash string,
+	statusCode int,
+	responseBody []byte,
+	createdAt, expiresAt time.Time,
+) (*Record, error) {
+	k, err := NewKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Record{
+		ownerID:      ownerID,
+		key:          k,
+		route:        route,
+		requestHash:  requestHash,
+		statusCode:   statusCode,
+		responseBody: responseBody,
+		createdAt:    createdAt,
+		expiresAt:    expiresAt,
+	}, nil
+}
+
+// Matches checks if a request is the one the key was first used for
+func (r *Record) Matches(route, requestHash string) bool {
+	return r.route == route && r.requestHash == requestHash
+}
+
+// IsComplete checks if the response to the request has been stored
+func (r *Record) IsComplete() bool {
+	return r.statusCode != 0
+}
+
+// Complete stores the response to the request
+func (r *Record) Complete(statusCode int, body []byte) error {
+	if r.IsComplete() {
+		return ErrAlreadyCompleted
+	}
+	if statusCode < 100 || statusCode > 599 {
+		return fmt.Errorf("invalid response status code: %d", statusCode)
+	}
+
+	r.statusCode = statusCode
+	r.responseBody = body
+	return nil
+}
+
+// Getters
+
+func (r *Record) OwnerID() string {
+	return r.ownerID
+}
+
+func (r *Record) Key() Key {
+	return r.key
+}
+
+func (r *Record) Route() string {
+	return r.route
+}
+
+func (r *Record) RequestHash() string {
+	return r.requestHash
+}
+
+func (r *Record) StatusCode() int {
+	return r.statusCode
+}
+
+func (r *Record) ResponseBody() []byte {
+	return r.responseBody
+}
+
+func (r *Record) CreatedAt() time.Time {
+	return r.createdAt
+}
+
+func (r *Record) ExpiresAt() time.Time {
+	return r.expiresAt
+}
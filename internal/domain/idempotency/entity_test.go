@@ -0,0 +1,89 @@
+package idempotency_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/domain/idempotency"
+)
+
+func TestNewKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{"uuid", "8e0f6f2c-3a5b-4c1d-9e7f-0a1b2c3d4e5f", false},
+		{"empty", "", true},
+		{"too long", strings.Repeat("k", 256), true},
+		{"space", "retry 1", true},
+		{"non ascii", "clé", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := idempotency.NewKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewKey(%q) error = %v, wantErr %v", tt.key, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, idempotency.ErrInvalidKey) {
+				t.Errorf("NewKey(%q) error = %v, want ErrInvalidKey", tt.key, err)
+			}
+		})
+	}
+}
+
+func TestRecord_Complete(t *testing.T) {
+	key, err := idempotency.NewKey("key-1")
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+
+	record := idempotency.NewRecord("user_1", key, "create_deployment", "hash", 24*time.Hour)
+	if record.IsComplete() {
+		t.Fatal("a new record should be in progress")
+	}
+	if got := record.ExpiresAt().Sub(record.CreatedAt()); got != 24*time.Hour {
+		t.Errorf("record expires after %v, want 24h", got)
+	}
+	if !record.Matches("create_deployment", "hash") {
+		t.Error("record should match the request it was created for")
+	}
+	if record.Matches("create_deployment", "other") || record.Matches("create_project", "hash") {
+		t.Error("record should not match a different request")
+	}
+
+	if err := record.Complete(0, nil); err == nil {
+		t.Error("Complete() should reject an invalid status code")
+	}
+	if err := record.Complete(201, []byte(`{"id":"1"}`)); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if !record.IsComplete() || record.StatusCode() != 201 || string(record.ResponseBody()) != `{"id":"1"}` {
+		t.Errorf("after Complete() status = %d, body = %s", record.StatusCode(), record.ResponseBody())
+	}
+	if err := record.Complete(201, nil); !errors.Is(err, idempotency.ErrAlreadyCompleted) {
+		t.Errorf("second Complete() error = %v, want ErrAlreadyCompleted", err)
+	}
+}
+
+func TestReconstitute(t *testing.T) {
+	now := time.Now()
+
+	record, err := idempotency.Reconstitute("user_1", "key-1", "create_project", "hash", 0, nil, now, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Reconstitute() error = %v", err)
+	}
+	if record.IsComplete() {
+		t.Error("a record without a status code should be in progress")
+	}
+	if record.Key().String() != "key-1" || record.OwnerID() != "user_1" || record.Route() != "create_project" {
+		t.Errorf("Reconstitute() = %+v", record)
+	}
+
+	if _, err := idempotency.Reconstitute("user_1", "", "create_project", "hash", 0, nil, now, now); err == nil {
+		t.Error("Reconstitute() should reject an invalid key")
+	}
+}
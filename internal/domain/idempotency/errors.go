@@ -0,0 +1,24 @@
+package idempotency
+
+import (
+	"errors"
+
+	"snapdeploy-core/internal/domain/validation"
+)
+
+var (
+	// ErrRecordNotFound is returned when no request was made with an idempotency key
+	ErrRecordNotFound = errors.New("idempotency key not found")
+
+	// ErrInvalidKey is returned when an idempotency key is empty, too long or not printable ASCII
+	ErrInvalidKey = validation.New("invalid idempotency key")
+
+	// ErrRequestInProgress is returned when a request with the same key has not finished yet
+	ErrRequestInProgress = errors.New("a request with this idempotency key is still in progress")
+
+	// ErrKeyReused is returned when a key is sent again with a different request
+	ErrKeyReused = errors.New("idempotency key was already used for a different request")
+
+	// ErrAlreadyCompleted is returned when storing a response for a request that already has one
+	ErrAlreadyCompleted = errors.New("idempotent request already completed")
+)
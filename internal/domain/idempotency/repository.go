@@ -0,0 +1,25 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// RecordRepository defines the interface for idempotency record persistence
+type RecordRepository interface {
+	// Reserve persists a new in-progress record, returning false if the owner already has one for the key.
+	// Expired records, and records still in progress that were created before staleBefore, are replaced.
+	Reserve(ctx context.Context, record *Record, staleBefore time.Time) (bool, error)
+
+	// FindByKey retrieves the owner's record for a key
+	FindByKey(ctx context.Context, ownerID string, key Key) (*Record, error)
+
+	// Save persists a record's response
+	Save(ctx context.Context, record *Record) error
+
+	// Delete removes the owner's record for a key
+	Delete(ctx context.Context, ownerID string, key Key) error
+
+	// DeleteExpired removes records that expired at or before the given time, returning how many were removed
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
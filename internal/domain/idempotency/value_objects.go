@@ -0,0 +1,31 @@
+package idempotency
+
+import "fmt"
+
+// maxKeyLength is the longest idempotency key accepted
+const maxKeyLength = 255
+
+// Key is a value object representing a client-chosen idempotency key, e.g. a UUID
+type Key struct {
+	value string
+}
+
+// NewKey creates a new Key with validation
+func NewKey(key string) (Key, error) {
+	if key == "" {
+		return Key{}, fmt.Errorf("%w: must not be empty", ErrInvalidKey)
+	}
+	if len(key) > maxKeyLength {
+		return Key{}, fmt.Errorf("%w: must be at most %d characters", ErrInvalidKey, maxKeyLength)
+	}
+	for _, r := range key {
+		if r < '!' || r > '~' {
+			return Key{}, fmt.Errorf("%w: must only contain printable ASCII characters", ErrInvalidKey)
+		}
+	}
+	return Key{value: key}, nil
+}
+
+func (k Key) String() string {
+	return k.value
+}
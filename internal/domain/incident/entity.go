@@ -0,0 +1,229 @@
+package incident
+
+import (
+	"fmt"
+	"time"
+)
+
+// Incident is a domain entity representing an operator-posted incident or maintenance notice
+type Incident struct {
+	id         IncidentID
+	kind       Kind
+	severity   Severity
+	title      Title
+	message    Message
+	startsAt   time.Time
+	endsAt     *time.Time // Scheduled end of a maintenance window
+	resolvedAt *time.Time
+	createdBy  string // Clerk user ID of the operator
+	createdAt  time.Time
+	updatedAt  time.Time
+}
+
+// NewIncident creates a new Incident entity
+// A zero startsAt means the incident starts immediately
+func NewIncident(
+	kind, severity, title, message string,
+	startsAt time.Time,
+	endsAt *time.Time,
+	createdBy string,
+) (*Incident, error) {
+	k, err := NewKind(kind)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kind: %w", err)
+	}
+
+	sev, err := NewSeverity(severity)
+	if err != nil {
+		return nil, fmt.Errorf("invalid severity: %w", err)
+	}
+
+	t, err := NewTitle(title)
+	if err != nil {
+		return nil, fmt.Errorf("invalid title: %w", err)
+	}
+
+	msg, err := NewMessage(message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+
+	now := time.Now()
+	if startsAt.IsZero() {
+		startsAt = now
+	}
+
+	if endsAt != nil && !endsAt.After(startsAt) {
+		return nil, ErrInvalidWindow
+	}
+
+	return &Incident{
+		id:        NewIncidentID(),
+		kind:      k,
+		severity:  sev,
+		title:     t,
+		message:   msg,
+		startsAt:  startsAt,
+		endsAt:    endsAt,
+		createdBy: createdBy,
+		createdAt: now,
+		updatedAt: now,
+	}, nil
+}
+
+// Reconstitute recreates an Incident entity from persistence
+func Reconstitute(
+	id, kind, severity, title, message string,
+	startsAt time.Time,
+	endsAt, resolvedAt *time.Time,
+	createdBy string,
+	createdAt, updatedAt time.Time,
+) (*Incident, error) {
+	incidentID, err := ParseIncidentID(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid incident ID: %w", err)
+	}
+
+	k, err := NewKind(kind)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kind: %w", err)
+	}
+
+	sev, err := NewSeverity(severity)
+	if err != nil {
+		return nil, fmt.Errorf("invalid severity: %w", err)
+	}
+
+	t, err := NewTitle(title)
+	if err != nil {
+		return nil, fmt.Errorf("invalid title: %w", err)
+	}
+
+	msg, err := NewMessage(message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+
+	return &Incident{
+		id:         incidentID,
+		kind:       k,
+		severity:   sev,
+		title:      t,
+		message:    msg,
+		startsAt:   startsAt,
+		endsAt:     endsAt,
+		resolvedAt: resolvedAt,
+		createdBy:  createdBy,
+		createdAt:  createdAt,
+		updatedAt:  updatedAt,
+	}, nil
+}
+
+// Update updates the incident details posted to users
+func (i *Incident) Update(severity, title, message string, endsAt *time.Time) error {
+	if i.IsResolved() {
+		return ErrIncidentResolved
+	}
+
+	sev, err := NewSeverity(severity)
+	if err != nil {
+		return fmt.Errorf("invalid severity: %w", err)
+	}
+
+	t, err := NewTitle(title)
+	if err != nil {
+		return fmt.Errorf("invalid title: %w", err)
+	}
+
+	msg, err := NewMessage(message)
+	if err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+
+	if endsAt != nil && !endsAt.After(i.startsAt) {
+		return ErrInvalidWindow
+	}
+
+	i.severity = sev
+	i.title = t
+	i.message = msg
+	i.endsAt = endsAt
+	i.updatedAt = time.Now()
+	return nil
+}
+
+// Resolve marks the incident as resolved
+func (i *Incident) Resolve() error {
+	if i.IsResolved() {
+		return ErrIncidentResolved
+	}
+
+	now := time.Now()
+	i.resolvedAt = &now
+	i.updatedAt = now
+	return nil
+}
+
+// IsResolved checks if the incident has been resolved
+func (i *Incident) IsResolved() bool {
+	return i.resolvedAt != nil
+}
+
+// IsActive checks if the incident is currently affecting the platform
+func (i *Incident) IsActive(now time.Time) bool {
+	if i.IsResolved() || now.Before(i.startsAt) {
+		return false
+	}
+	return i.endsAt == nil || now.Before(*i.endsAt)
+}
+
+// IsScheduled checks if the incident is a notice for a window that has not started yet
+func (i *Incident) IsScheduled(now time.Time) bool {
+	return !i.IsResolved() && now.Before(i.startsAt)
+}
+
+// Getters
+
+func (i *Incident) ID() IncidentID {
+	return i.id
+}
+
+func (i *Incident) Kind() Kind {
+	return i.kind
+}
+
+func (i *Incident) Severity() Severity {
+	return i.severity
+}
+
+func (i *Incident) Title() Title {
+	return i.title
+}
+
+func (i *Incident) Message() Message {
+	return i.message
+}
+
+func (i *Incident) StartsAt() time.Time {
+	return i.startsAt
+}
+
+func (i *Incident) EndsAt() *time.Time {
+	return i.endsAt
+}
+
+func (i *Incident) ResolvedAt() *time.Time {
+	return i.resolvedAt
+}
+
+func (i *Incident) CreatedBy() string {
+	return i.createdBy
+}
+
+func (i *Incident) CreatedAt() time.Time {
+	return i.createdAt
+}
+
+func (i *Incident) UpdatedAt() time.Time {
+	return i.updatedAt
+}
@@ -0,0 +1,18 @@
+package incident
+
+import (
+	"errors"
+
+	"snapdeploy-core/internal/domain/validation"
+)
+
+var (
+	// ErrIncidentNotFound is returned when an incident is not found
+	ErrIncidentNotFound = errors.New("incident not found")
+
+	// ErrIncidentResolved is returned when trying to change an incident that is already resolved
+	ErrIncidentResolved = errors.New("incident is already resolved")
+
+	// ErrInvalidWindow is returned when a maintenance window ends before it starts
+	ErrInvalidWindow = validation.New("incident must end after it starts")
+)
@@ -0,0 +1,21 @@
+package incident
+
+import (
+	"context"
+	"time"
+)
+
+// IncidentRepository defines the interface for incident persistence
+type IncidentRepository interface {
+	// Save persists an incident (create or update)
+	Save(ctx context.Context, incident *Incident) error
+
+	// FindByID retrieves an incident by its ID
+	FindByID(ctx context.Context, id IncidentID) (*Incident, error)
+
+	// FindUnresolved retrieves all incidents that have not been resolved, including scheduled ones
+	FindUnresolved(ctx context.Context) ([]*Incident, error)
+
+	// FindResolvedSince retrieves incidents resolved at or after the given time
+	FindResolvedSince(ctx context.Context, since time.Time) ([]*Incident, error)
+}
@@ -0,0 +1,151 @@
+package incident
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+
+	"snapdeploy-core/internal/domain/validation"
+)
+
+// IncidentID is a value object representing an incident's unique identifier
+type IncidentID struct {
+	value uuid.UUID
+}
+
+// NewIncidentID creates a new IncidentID
+func NewIncidentID() IncidentID {
+	return IncidentID{value: uuid.New()}
+}
+
+// ParseIncidentID parses a string into an IncidentID
+func ParseIncidentID(id string) (IncidentID, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return IncidentID{}, validation.Errorf("invalid incident ID format: %w", err)
+	}
+	return IncidentID{value: uid}, nil
+}
+
+func (id IncidentID) String() string {
+	return id.value.String()
+}
+
+func (id IncidentID) UUID() uuid.UUID {
+	return id.value
+}
+
+func (id IncidentID) Equals(other IncidentID) bool {
+	return id.value == other.value
+}
+
+// Kind distinguishes unplanned incidents from scheduled maintenance
+type Kind string
+
+const (
+	KindIncident    Kind = "INCIDENT"
+	KindMaintenance Kind = "MAINTENANCE"
+)
+
+// NewKind creates a new Kind with validation
+func NewKind(kind string) (Kind, error) {
+	kind = strings.ToUpper(strings.TrimSpace(kind))
+
+	switch Kind(kind) {
+	case KindIncident, KindMaintenance:
+		return Kind(kind), nil
+	default:
+		return "", validation.Errorf("invalid kind: %s (must be one of: INCIDENT, MAINTENANCE)", kind)
+	}
+}
+
+func (k Kind) String() string {
+	return string(k)
+}
+
+// Severity represents how strongly an incident affects the platform
+type Severity string
+
+const (
+	SeverityInfo     Severity = "INFO"
+	SeverityWarning  Severity = "WARNING"
+	SeverityCritical Severity = "CRITICAL"
+)
+
+// NewSeverity creates a new Severity with validation
+func NewSeverity(severity string) (Severity, error) {
+	severity = strings.ToUpper(strings.TrimSpace(severity))
+
+	switch Severity(severity) {
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+		return Severity(severity), nil
+	default:
+		return "", validation.Errorf("invalid severity: %s (must be one of: INFO, WARNING, CRITICAL)", severity)
+	}
+}
+
+func (s Severity) String() string {
+	return string(s)
+}
+
+// Rank orders severities so the most severe incident can be picked for the banner
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityCritical:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Title is a value object representing a short incident headline
+type Title struct {
+	value string
+}
+
+// NewTitle creates a new Title with validation
+func NewTitle(title string) (Title, error) {
+	title = strings.TrimSpace(title)
+
+	if title == "" {
+		return Title{}, validation.Errorf("title cannot be empty")
+	}
+
+	if len(title) > 200 {
+		return Title{}, validation.Errorf("title too long (max 200 characters)")
+	}
+
+	return Title{value: title}, nil
+}
+
+func (t Title) String() string {
+	return t.value
+}
+
+// Message is a value object representing the user-facing incident description
+type Message struct {
+	value string
+}
+
+// NewMessage creates a new Message with validation
+func NewMessage(message string) (Message, error) {
+	message = strings.TrimSpace(message)
+
+	if message == "" {
+		return Message{}, validation.Errorf("message cannot be empty")
+	}
+
+	if len(message) > 2000 {
+		return Message{}, validation.Errorf("message too long (max 2000 characters)")
+	}
+
+	return Message{value: message}, nil
+}
+
+func (m Message) String() string {
+	return m.value
+}
@@ -0,0 +1,111 @@
+package job
+
+import (
+	"fmt"
+	"time"
+)
+
+// Job is a domain entity representing a slow operation run outside the request that started it
+type Job struct {
+	id         JobID
+	ownerID    string
+	kind       Kind
+	status     Status
+	result     interface{}
+	errMessage string
+	createdAt  time.Time
+	startedAt  *time.Time
+	finishedAt *time.Time
+}
+
+// NewJob creates a new queued Job owned by the given principal
+func NewJob(ownerID string, kind Kind) *Job {
+	return &Job{
+		id:        NewJobID(),
+		ownerID:   ownerID,
+		kind:      kind,
+		status:    StatusQueued,
+		createdAt: time.Now(),
+	}
+}
+
+// Start marks the job as running
+func (j *Job) Start() error {
+	if j.status != StatusQueued {
+		return fmt.Errorf("%w: cannot start a %s job", ErrInvalidStatusTransition, j.status)
+	}
+
+	now := time.Now()
+	j.status = StatusRunning
+	j.startedAt = &now
+	return nil
+}
+
+// Succeed marks the job as finished with a result
+func (j *Job) Succeed(result interface{}) error {
+	if j.status != StatusRunning {
+		return fmt.Errorf("%w: cannot complete a %s job", ErrInvalidStatusTransition, j.status)
+	}
+
+	now := time.Now()
+	j.status = StatusSucceeded
+	j.result = result
+	j.finishedAt = &now
+	return nil
+}
+
+// Fail marks the job as finished with an error
+func (j *Job) Fail(err error) error {
+	if j.status.IsTerminal() {
+		return fmt.Errorf("%w: cannot fail a %s job", ErrInvalidStatusTransition, j.status)
+	}
+
+	now := time.Now()
+	j.status = StatusFailed
+	j.errMessage = err.Error()
+	j.finishedAt = &now
+	return nil
+}
+
+// BelongsTo checks if the job was submitted by the given principal
+func (j *Job) BelongsTo(ownerID string) bool {
+	return j.ownerID == ownerID
+}
+
+// Getters
+
+func (j *Job) ID() JobID {
+	return j.id
+}
+
+func (j *Job) OwnerID() string {
+	return j.ownerID
+}
+
+func (j *Job) Kind() Kind {
+	return j.kind
+}
+
+func (j *Job) Status() Status {
+	return j.status
+}
+
+func (j *Job) Result() interface{} {
+	return j.result
+}
+
+func (j *Job) ErrorMessage() string {
+	return j.errMessage
+}
+
+func (j *Job) CreatedAt() time.Time {
+	return j.createdAt
+}
+
+func (j *Job) StartedAt() *time.Time {
+	return j.startedAt
+}
+
+func (j *Job) FinishedAt() *time.Time {
+	return j.finishedAt
+}
@@ -0,0 +1,14 @@
+package job
+
+import "errors"
+
+var (
+	// ErrJobNotFound is returned when a job is not found or belongs to another user
+	ErrJobNotFound = errors.New("job not found")
+
+	// ErrQueueFull is returned when a user already has the maximum number of pending jobs
+	ErrQueueFull = errors.New("too many pending jobs")
+
+	// ErrInvalidStatusTransition is returned when a job is moved to a status it can't reach
+	ErrInvalidStatusTransition = errors.New("invalid job status transition")
+)
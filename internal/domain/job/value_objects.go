@@ -0,0 +1,72 @@
+package job
+
+import (
+	"github.com/google/uuid"
+
+	"snapdeploy-core/internal/domain/validation"
+)
+
+// JobID is a value object representing a job's unique identifier
+type JobID struct {
+	value uuid.UUID
+}
+
+// NewJobID creates a new JobID
+func NewJobID() JobID {
+	return JobID{value: uuid.New()}
+}
+
+// ParseJobID parses a string into a JobID
+func ParseJobID(id string) (JobID, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return JobID{}, validation.Errorf("invalid job ID format: %w", err)
+	}
+	return JobID{value: uid}, nil
+}
+
+func (id JobID) String() string {
+	return id.value.String()
+}
+
+func (id JobID) Equals(other JobID) bool {
+	return id.value == other.value
+}
+
+// Kind identifies the operation a job performs
+type Kind string
+
+const (
+	KindRepositorySync    Kind = "REPOSITORY_SYNC"
+	KindUserDeletion      Kind = "USER_DELETION"
+	KindProjectValidation Kind = "PROJECT_VALIDATION"
+)
+
+func (k Kind) String() string {
+	return string(k)
+}
+
+// IsShared reports whether a pending job of the kind does the work of every later submission of it.
+// Validations check the configuration they were submitted with, so each one runs on its own.
+func (k Kind) IsShared() bool {
+	return k != KindProjectValidation
+}
+
+// Status represents the lifecycle status of a job
+type Status string
+
+const (
+	StatusQueued    Status = "QUEUED"
+	StatusRunning   Status = "RUNNING"
+	StatusSucceeded Status = "SUCCEEDED"
+	StatusFailed    Status = "FAILED"
+)
+
+func (s Status) String() string {
+	return string(s)
+}
+
+// IsTerminal reports whether the job has finished
+func (s Status) IsTerminal() bool {
+	return s == StatusSucceeded || s == StatusFailed
+}
@@ -0,0 +1,125 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/domain/project"
+)
+
+// Check is the result of one probe of a project's health endpoint from the control plane
+type Check struct {
+	ProjectID  project.ProjectID
+	URL        string
+	Healthy    bool
+	StatusCode int    // HTTP status the endpoint answered with, 0 if it didn't answer
+	LatencyMS  int    // How long the endpoint took to answer
+	Error      string // Why the check failed, empty for healthy checks
+	CheckedAt  time.Time
+}
+
+// Incident is a domain entity representing a period a project's health checks kept failing
+type Incident struct {
+	id           IncidentID
+	projectID    project.ProjectID
+	cause        string // Why the last failed check failed
+	failedChecks int
+	startedAt    time.Time
+	resolvedAt   *time.Time
+}
+
+// NewIncident opens an incident for a project from the failed checks that started it, oldest first
+func NewIncident(projectID project.ProjectID, failed []Check) *Incident {
+	incident := &Incident{
+		id:        NewIncidentID(),
+		projectID: projectID,
+		startedAt: time.Now(),
+	}
+	if len(failed) > 0 {
+		incident.startedAt = failed[0].CheckedAt
+	}
+	for _, check := range failed {
+		incident.RecordFailure(check)
+	}
+	return incident
+}
+
+// ReconstituteIncident recreates an Incident entity from persistence
+func ReconstituteIncident(
+	id, projectID, cause string,
+	failedChecks int,
+	startedAt time.Time,
+	resolvedAt *time.Time,
+) (*Incident, error) {
+	incidentID, err := ParseIncidentID(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid incident ID: %w", err)
+	}
+
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	return &Incident{
+		id:           incidentID,
+		projectID:    pid,
+		cause:        cause,
+		failedChecks: failedChecks,
+		startedAt:    startedAt,
+		resolvedAt:   resolvedAt,
+	}, nil
+}
+
+// RecordFailure counts a failed check of an open incident
+func (i *Incident) RecordFailure(check Check) {
+	i.failedChecks++
+	i.cause = check.Error
+}
+
+// Resolve closes the incident when a check succeeds again. Resolving a resolved incident does nothing.
+func (i *Incident) Resolve(at time.Time) {
+	if i.resolvedAt != nil {
+		return
+	}
+	i.resolvedAt = &at
+}
+
+// IsOpen checks if the project's checks are still failing
+func (i *Incident) IsOpen() bool {
+	return i.resolvedAt == nil
+}
+
+// Duration returns how long the incident lasted, or has lasted so far
+func (i *Incident) Duration(now time.Time) time.Duration {
+	if i.resolvedAt != nil {
+		return i.resolvedAt.Sub(i.startedAt)
+	}
+	return now.Sub(i.startedAt)
+}
+
+// Getters
+
+func (i *Incident) ID() IncidentID {
+	return i.id
+}
+
+func (i *Incident) ProjectID() project.ProjectID {
+	return i.projectID
+}
+
+func (i *Incident) Cause() string {
+	return i.cause
+}
+
+func (i *Incident) FailedChecks() int {
+	return i.failedChecks
+}
+
+func (i *Incident) StartedAt() time.Time {
+	return i.startedAt
+}
+
+func (i *Incident) ResolvedAt() *time.Time {
+	return i.resolvedAt
+}
@@ -0,0 +1,41 @@
+package monitor_test
+
+import (
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/domain/monitor"
+	"snapdeploy-core/internal/domain/project"
+)
+
+func TestIncident(t *testing.T) {
+	started := time.Date(2025, 12, 13, 9, 0, 0, 0, time.UTC)
+	projectID := project.NewProjectID()
+	failed := []monitor.Check{
+		{ProjectID: projectID, Error: "connection refused", CheckedAt: started},
+		{ProjectID: projectID, Error: "health endpoint answered with HTTP 502", CheckedAt: started.Add(time.Minute)},
+	}
+
+	incident := monitor.NewIncident(projectID, failed)
+	if !incident.IsOpen() || !incident.StartedAt().Equal(started) {
+		t.Fatalf("NewIncident() open = %v, started at %v, want open since the first failed check", incident.IsOpen(), incident.StartedAt())
+	}
+	if incident.FailedChecks() != 2 || incident.Cause() != "health endpoint answered with HTTP 502" {
+		t.Errorf("FailedChecks(), Cause() = %d, %q, want the checks and the last failure", incident.FailedChecks(), incident.Cause())
+	}
+
+	incident.RecordFailure(monitor.Check{ProjectID: projectID, Error: "timeout", CheckedAt: started.Add(2 * time.Minute)})
+	if incident.FailedChecks() != 3 || incident.Cause() != "timeout" {
+		t.Errorf("after RecordFailure() FailedChecks(), Cause() = %d, %q", incident.FailedChecks(), incident.Cause())
+	}
+
+	resolved := started.Add(3 * time.Minute)
+	incident.Resolve(resolved)
+	incident.Resolve(resolved.Add(time.Minute))
+	if incident.IsOpen() || !incident.ResolvedAt().Equal(resolved) {
+		t.Errorf("after Resolve() open = %v, resolved at %v, want resolved at %v", incident.IsOpen(), incident.ResolvedAt(), resolved)
+	}
+	if got := incident.Duration(time.Now()); got != 3*time.Minute {
+		t.Errorf("Duration() = %v, want 3m", got)
+	}
+}
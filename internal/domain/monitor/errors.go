@@ -0,0 +1,8 @@
+package monitor
+
+import "errors"
+
+var (
+	// ErrIncidentNotFound is returned when an incident is not found
+	ErrIncidentNotFound = errors.New("incident not found")
+)
@@ -0,0 +1,35 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"snapdeploy-core/internal/domain/project"
+)
+
+// CheckRepository defines the interface for health check persistence
+type CheckRepository interface {
+	// Save records a health check
+	Save(ctx context.Context, check Check) error
+
+	// FindRecent retrieves the most recent health checks of a project, newest first
+	FindRecent(ctx context.Context, projectID project.ProjectID, limit int) ([]Check, error)
+
+	// CountSince counts the health checks of a project made since the time, and those that succeeded
+	CountSince(ctx context.Context, projectID project.ProjectID, since time.Time) (succeeded, total int64, err error)
+
+	// DeleteBefore removes the health checks of every project made before the cutoff
+	DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// IncidentRepository defines the interface for incident persistence
+type IncidentRepository interface {
+	// Save persists an incident (create or update)
+	Save(ctx context.Context, incident *Incident) error
+
+	// FindOpenByProjectID retrieves the open incident of a project
+	FindOpenByProjectID(ctx context.Context, projectID project.ProjectID) (*Incident, error)
+
+	// FindByProjectID retrieves the most recent incidents of a project, newest first
+	FindByProjectID(ctx context.Context, projectID project.ProjectID, limit int) ([]*Incident, error)
+}
@@ -0,0 +1,38 @@
+package monitor
+
+import (
+	"github.com/google/uuid"
+
+	"snapdeploy-core/internal/domain/validation"
+)
+
+// IncidentID is a value object representing an incident's unique identifier
+type IncidentID struct {
+	value uuid.UUID
+}
+
+// NewIncidentID creates a new IncidentID
+func NewIncidentID() IncidentID {
+	return IncidentID{value: uuid.New()}
+}
+
+// ParseIncidentID parses a string into an IncidentID
+func ParseIncidentID(id string) (IncidentID, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return IncidentID{}, validation.Errorf("invalid incident ID format: %w", err)
+	}
+	return IncidentID{value: uid}, nil
+}
+
+func (id IncidentID) String() string {
+	return id.value.String()
+}
+
+func (id IncidentID) UUID() uuid.UUID {
+	return id.value
+}
+
+func (id IncidentID) Equals(other IncidentID) bool {
+	return id.value == other.value
+}
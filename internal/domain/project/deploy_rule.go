@@ -0,0 +1,50 @@
+package project
+
+import (
+	"path"
+	"strings"
+)
+
+// MaxDeployRules bounds the branch rules of a project
+const MaxDeployRules = 20
+
+// DeployRule is a value object mapping the branches matching a pattern to the environment pushes to them are
+// deployed to. Patterns are branch names in which * matches any characters but /, e.g. release/*.
+type DeployRule struct {
+	branch      string
+	environment Environment
+}
+
+// NewDeployRule creates a new DeployRule with validation
+func NewDeployRule(branch, environment string) (DeployRule, error) {
+	branch = strings.TrimSpace(branch)
+	if branch == "" || len(branch) > 255 || strings.ContainsAny(branch, " \t\n") {
+		return DeployRule{}, ErrInvalidDeployRules
+	}
+	if _, err := path.Match(branch, ""); err != nil {
+		return DeployRule{}, ErrInvalidDeployRules
+	}
+
+	env, err := NewEnvironment(environment)
+	if err != nil {
+		return DeployRule{}, ErrInvalidDeployRules
+	}
+
+	return DeployRule{branch: branch, environment: env}, nil
+}
+
+// Branch returns the pattern of the branches the rule deploys
+func (r DeployRule) Branch() string {
+	return r.branch
+}
+
+// Environment returns the environment the rule deploys to
+func (r DeployRule) Environment() Environment {
+	return r.environment
+}
+
+// Matches reports whether pushes to a branch are deployed by the rule
+func (r DeployRule) Matches(branch string) bool {
+	matched, _ := path.Match(r.branch, branch)
+	return matched
+}
@@ -2,11 +2,68 @@ package project
 
 import (
 	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"snapdeploy-core/internal/domain/user"
 )
 
+// MaxImageRetention is the most deployments a project can keep the images of
+const MaxImageRetention = 100
+
+// Canary settings of projects that don't choose their own, and the largest they can choose
+const (
+	DefaultCanaryPercent     = 10
+	MaxCanaryPercent         = 50
+	DefaultCanaryBakeMinutes = 10
+	MaxCanaryBakeMinutes     = 60
+)
+
+// Size of a project's persistent volume when it doesn't choose one, and the largest it can choose
+const (
+	DefaultVolumeSizeGB = 10
+	MaxVolumeSizeGB     = 100
+)
+
+// Container settings of projects that don't choose their own
+const (
+	DefaultPort            = 8080
+	DefaultHealthCheckPath = "/"
+	DefaultCPU             = 256 // 0.25 vCPU
+	DefaultMemory          = 512 // MiB
+)
+
+// Build timeout of projects that don't choose their own, and the range they can choose from
+const (
+	DefaultBuildTimeoutMinutes = 30
+	MinBuildTimeoutMinutes     = 5
+	MaxBuildTimeoutMinutes     = 480
+)
+
+// DefaultOutputDirectory is where the build of a STATIC project writes its files when it doesn't choose
+const DefaultOutputDirectory = "dist"
+
+// outputDirectoryPattern matches relative paths of letters, numbers, dots, dashes and underscores
+var outputDirectoryPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+(/[A-Za-z0-9._-]+)*$`)
+
+// taskMemory lists the memory sizes in MiB Fargate supports for each CPU size
+var taskMemory = map[int][2]int{
+	256:  {512, 2048},
+	512:  {1024, 4096},
+	1024: {2048, 8192},
+	2048: {4096, 16384},
+	4096: {8192, 30720},
+}
+
+// reservedMountPaths are directories of the container image a volume must not hide
+var reservedMountPaths = map[string]bool{
+	"/": true, "/bin": true, "/boot": true, "/dev": true, "/etc": true, "/lib": true, "/lib64": true,
+	"/proc": true, "/root": true, "/sbin": true, "/sys": true, "/usr": true,
+}
+
 // Project is a domain entity representing a deployment project
 type Project struct {
 	id               ProjectID
@@ -19,8 +76,35 @@ type Project struct {
 	customDomain     CustomDomain
 	requireDB        bool
 	migrationCommand Command // Optional database migration command
+	status           ProjectStatus
+	statusMessage    string // Progress or error detail for the current status
+	imageRetention   int    // Recent deployments whose images are kept, 0 for the platform default
+	projectType      ProjectType
+	schedule         Schedule // When a CRON project runs, empty for other types
+	strategy         DeploymentStrategy
+	canaryPercent    int           // Share of traffic a canary receives
+	canaryBake       int           // Minutes a canary is watched before it is promoted
+	datastores       []Datastore   // Datastores provisioned besides the Postgres database
+	volumeMountPath  string        // Where the persistent volume is mounted, empty for none
+	volumeSizeGB     int           // Size the persistent volume is expected to stay within
+	services         []Service     // Processes run besides the main one, from the same image
+	environments     []Environment // Environments deployed to besides production
+	protectedEnvs    []Environment // Environments whose deployments wait for approval
+	deployRules      []DeployRule  // Branches pushes are deployed from, the first matching rule wins
+	publicStatus     bool          // Whether anyone may read the project's status page
+	envRedeploy      bool          // Whether changing an environment variable redeploys the environment
+	port             int           // Port the container listens on
+	healthCheckPath  string        // Path the load balancer checks
+	cpu              int           // CPU units of the project's tasks
+	memory           int           // Memory in MiB of the project's tasks
+	outputDirectory  string        // Directory a STATIC project's build writes its files to, empty for other types
+	buildSize        BuildSize     // CPU and memory of the project's builds
+	buildTimeout     int           // Minutes a build may run before it is stopped
+	target           DeploymentTarget
+	lambdaEndpoint   LambdaEndpoint // How requests reach a LAMBDA project, empty for ECS ones
 	createdAt        time.Time
 	updatedAt        time.Time
+	deletedAt        *time.Time // Set once the project is soft deleted
 }
 
 // NewProject creates a new Project entity
@@ -74,6 +158,18 @@ func NewProject(
 		customDomain:     domain,
 		requireDB:        requireDB,
 		migrationCommand: migrationCmd,
+		status:           StatusActive,
+		projectType:      TypeWeb,
+		strategy:         StrategyRolling,
+		canaryPercent:    DefaultCanaryPercent,
+		canaryBake:       DefaultCanaryBakeMinutes,
+		port:             DefaultPort,
+		healthCheckPath:  DefaultHealthCheckPath,
+		cpu:              DefaultCPU,
+		memory:           DefaultMemory,
+		buildSize:        BuildSmall,
+		buildTimeout:     DefaultBuildTimeoutMinutes,
+		target:           TargetECS,
 		createdAt:        now,
 		updatedAt:        now,
 	}, nil
@@ -86,7 +182,29 @@ func Reconstitute(
 	repositoryURL, installCommand, buildCommand, runCommand, language, customDomain string,
 	requireDB bool,
 	migrationCommand string,
+	status, statusMessage string,
+	imageRetention int,
+	deploymentStrategy string,
+	canaryPercent, canaryBakeMinutes int,
+	datastores []string,
+	volumeMountPath string,
+	volumeSizeGB int,
+	projectType string,
+	schedule string,
+	services []Service,
+	environments, protectedEnvironments []string,
+	port int,
+	healthCheckPath string,
+	cpu, memory int,
+	outputDirectory string,
+	deploymentTarget, lambdaEndpoint string,
+	deployRules []DeployRule,
+	publicStatusPage bool,
+	buildSize string,
+	buildTimeoutMinutes int,
+	redeployOnEnvChange bool,
 	createdAt, updatedAt time.Time,
+	deletedAt *time.Time,
 ) (*Project, error) {
 	projectID, err := ParseProjectID(id)
 	if err != nil {
@@ -125,6 +243,72 @@ func Reconstitute(
 	// Migration command is optional
 	migrationCmd := NewOptionalCommand(migrationCommand)
 
+	projectStatus, err := NewProjectStatus(status)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status: %w", err)
+	}
+
+	strategy, err := NewDeploymentStrategy(deploymentStrategy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment strategy: %w", err)
+	}
+
+	stores, err := newDatastores(datastores)
+	if err != nil {
+		return nil, fmt.Errorf("invalid datastores: %w", err)
+	}
+
+	pType, err := NewProjectType(projectType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project type: %w", err)
+	}
+
+	// Only CRON projects have a schedule
+	var cronSchedule Schedule
+	if schedule != "" {
+		cronSchedule, err = NewSchedule(schedule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule: %w", err)
+		}
+	}
+
+	target, err := NewDeploymentTarget(deploymentTarget)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment target: %w", err)
+	}
+
+	// Only LAMBDA projects have an endpoint
+	var endpoint LambdaEndpoint
+	if target == TargetLambda {
+		endpoint, err = NewLambdaEndpoint(lambdaEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Lambda endpoint: %w", err)
+		}
+	}
+
+	size, err := NewBuildSize(buildSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid build size: %w", err)
+	}
+
+	envs := make([]Environment, 0, len(environments))
+	for _, name := range environments {
+		env, err := NewEnvironment(name)
+		if err != nil {
+			return nil, err
+		}
+		envs = append(envs, env)
+	}
+
+	protected := make([]Environment, 0, len(protectedEnvironments))
+	for _, name := range protectedEnvironments {
+		env, err := NewEnvironment(name)
+		if err != nil {
+			return nil, err
+		}
+		protected = append(protected, env)
+	}
+
 	return &Project{
 		id:               projectID,
 		userID:           userID,
@@ -136,8 +320,35 @@ func Reconstitute(
 		customDomain:     domain,
 		requireDB:        requireDB,
 		migrationCommand: migrationCmd,
+		status:           projectStatus,
+		statusMessage:    statusMessage,
+		imageRetention:   imageRetention,
+		projectType:      pType,
+		schedule:         cronSchedule,
+		strategy:         strategy,
+		canaryPercent:    canaryPercent,
+		canaryBake:       canaryBakeMinutes,
+		datastores:       stores,
+		volumeMountPath:  volumeMountPath,
+		volumeSizeGB:     volumeSizeGB,
+		services:         services,
+		environments:     envs,
+		protectedEnvs:    protected,
+		deployRules:      deployRules,
+		publicStatus:     publicStatusPage,
+		envRedeploy:      redeployOnEnvChange,
+		port:             port,
+		healthCheckPath:  healthCheckPath,
+		cpu:              cpu,
+		memory:           memory,
+		outputDirectory:  outputDirectory,
+		buildSize:        size,
+		buildTimeout:     buildTimeoutMinutes,
+		target:           target,
+		lambdaEndpoint:   endpoint,
 		createdAt:        createdAt,
 		updatedAt:        updatedAt,
+		deletedAt:        deletedAt,
 	}, nil
 }
 
@@ -179,6 +390,10 @@ func (p *Project) Update(
 	// Migration command is optional
 	migrationCmd := NewOptionalCommand(migrationCommand)
 
+	if err := checkServiceDomains(domain, p.Environments(), p.services); err != nil {
+		return err
+	}
+
 	p.repositoryURL = repoURL
 	p.installCommand = installCmd
 	p.buildCommand = buildCmd
@@ -192,6 +407,440 @@ func (p *Project) Update(
 	return nil
 }
 
+// SetImageRetention sets how many recent deployments keep their images, 0 for the platform default
+func (p *Project) SetImageRetention(deployments int) error {
+	if deployments < 0 || deployments > MaxImageRetention {
+		return ErrInvalidImageRetention
+	}
+
+	p.imageRetention = deployments
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// SetType sets how the project's containers are run. The deployment strategy, schedule and output
+// directory must be set afterwards, since they are checked against the type.
+func (p *Project) SetType(projectType string) error {
+	pType, err := NewProjectType(projectType)
+	if err != nil {
+		return ErrInvalidProjectType
+	}
+
+	p.projectType = pType
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// SetSchedule sets when a CRON project runs. Other projects run continuously, so their schedule must be empty.
+func (p *Project) SetSchedule(expression string) error {
+	if p.projectType != TypeCron {
+		if strings.TrimSpace(expression) != "" {
+			return ErrInvalidSchedule
+		}
+		p.schedule = Schedule{}
+		p.updatedAt = time.Now()
+		return nil
+	}
+
+	schedule, err := NewSchedule(expression)
+	if err != nil {
+		return ErrInvalidSchedule
+	}
+
+	p.schedule = schedule
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// SetOutputDirectory sets the directory a STATIC project's build writes its files to, relative to the root
+// of the repository. An empty directory selects the default. Other projects serve from a container, so their
+// output directory must be empty.
+func (p *Project) SetOutputDirectory(dir string) error {
+	dir = strings.TrimSuffix(strings.TrimSpace(dir), "/")
+	if p.projectType != TypeStatic {
+		if dir != "" {
+			return ErrInvalidOutputDirectory
+		}
+		p.outputDirectory = ""
+		p.updatedAt = time.Now()
+		return nil
+	}
+
+	if dir == "" {
+		dir = DefaultOutputDirectory
+	}
+	if len(dir) > 255 || !outputDirectoryPattern.MatchString(dir) {
+		return ErrInvalidOutputDirectory
+	}
+	for _, part := range strings.Split(dir, "/") {
+		if part == "." || part == ".." {
+			return ErrInvalidOutputDirectory
+		}
+	}
+
+	p.outputDirectory = dir
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// SetDeploymentStrategy sets how new versions of the project replace the running one
+func (p *Project) SetDeploymentStrategy(strategy string) error {
+	deploymentStrategy, err := NewDeploymentStrategy(strategy)
+	if err != nil {
+		return ErrInvalidDeploymentStrategy
+	}
+
+	// Blue/green and canary deployments move traffic between versions through the load balancer
+	shiftsTraffic := deploymentStrategy == StrategyBlueGreen || deploymentStrategy == StrategyCanary
+	if shiftsTraffic && !p.projectType.ServesTraffic() {
+		return ErrStrategyNeedsTraffic
+	}
+
+	p.strategy = deploymentStrategy
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// SetCanary sets the share of traffic a canary receives and how many minutes it is watched before
+// it is promoted. Zero values select the defaults.
+func (p *Project) SetCanary(percent, bakeMinutes int) error {
+	if percent == 0 {
+		percent = DefaultCanaryPercent
+	}
+	if bakeMinutes == 0 {
+		bakeMinutes = DefaultCanaryBakeMinutes
+	}
+	if percent < 1 || percent > MaxCanaryPercent || bakeMinutes < 1 || bakeMinutes > MaxCanaryBakeMinutes {
+		return ErrInvalidCanary
+	}
+
+	p.canaryPercent = percent
+	p.canaryBake = bakeMinutes
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// SetDatastores sets the datastores provisioned for the project besides its Postgres database
+func (p *Project) SetDatastores(datastores []string) error {
+	stores, err := newDatastores(datastores)
+	if err != nil {
+		return ErrInvalidDatastore
+	}
+
+	p.datastores = stores
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// SetVolume sets where the project's persistent volume is mounted and how large it may grow.
+// An empty mount path removes the volume, a zero size selects the default.
+func (p *Project) SetVolume(mountPath string, sizeGB int) error {
+	if mountPath == "" {
+		if sizeGB != 0 {
+			return ErrInvalidVolume
+		}
+		p.volumeMountPath = ""
+		p.volumeSizeGB = 0
+		p.updatedAt = time.Now()
+		return nil
+	}
+
+	if sizeGB == 0 {
+		sizeGB = DefaultVolumeSizeGB
+	}
+	if !path.IsAbs(mountPath) || path.Clean(mountPath) != mountPath || reservedMountPaths[mountPath] ||
+		len(mountPath) > 255 || sizeGB < 1 || sizeGB > MaxVolumeSizeGB {
+		return ErrInvalidVolume
+	}
+
+	p.volumeMountPath = mountPath
+	p.volumeSizeGB = sizeGB
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// SetServices sets the processes the project runs besides its main one
+func (p *Project) SetServices(services []Service) error {
+	if len(services) > MaxServices {
+		return ErrInvalidServices
+	}
+	seen := make(map[string]bool, len(services))
+	for _, s := range services {
+		if seen[s.Name()] {
+			return ErrInvalidServices
+		}
+		seen[s.Name()] = true
+	}
+	if err := checkEnvironmentNames(p.environments, services); err != nil {
+		return err
+	}
+	if err := checkServiceDomains(p.customDomain, p.Environments(), services); err != nil {
+		return err
+	}
+
+	p.services = append([]Service(nil), services...)
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// SetDeploymentTarget sets the compute the project runs on and, for LAMBDA projects, how requests reach it.
+// An empty target selects ECS and an empty endpoint API_GATEWAY. Lambda runs a single process answering
+// HTTP requests, so LAMBDA projects must be WEB projects without services, a volume or a Redis sidecar.
+func (p *Project) SetDeploymentTarget(target, endpoint string) error {
+	deploymentTarget, err := NewDeploymentTarget(target)
+	if err != nil {
+		return ErrInvalidDeploymentTarget
+	}
+
+	if deploymentTarget != TargetLambda {
+		if strings.TrimSpace(endpoint) != "" {
+			return ErrInvalidDeploymentTarget
+		}
+		p.target = deploymentTarget
+		p.lambdaEndpoint = ""
+		p.updatedAt = time.Now()
+		return nil
+	}
+
+	lambdaEndpoint, err := NewLambdaEndpoint(endpoint)
+	if err != nil {
+		return ErrInvalidDeploymentTarget
+	}
+	if p.projectType != TypeWeb || len(p.services) > 0 || p.HasVolume() || p.UsesDatastore(DatastoreRedis) {
+		return ErrLambdaUnsupported
+	}
+
+	p.target = deploymentTarget
+	p.lambdaEndpoint = lambdaEndpoint
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// SetEnvironments sets the environments the project is deployed to besides production
+func (p *Project) SetEnvironments(names []string) error {
+	if len(names) > MaxEnvironments {
+		return ErrInvalidEnvironments
+	}
+	envs := make([]Environment, 0, len(names))
+	seen := make(map[Environment]bool, len(names))
+	for _, name := range names {
+		env, err := NewEnvironment(name)
+		if err != nil || env.IsProduction() || seen[env] {
+			return ErrInvalidEnvironments
+		}
+		seen[env] = true
+		envs = append(envs, env)
+	}
+	if err := checkEnvironmentNames(envs, p.services); err != nil {
+		return err
+	}
+	if err := checkServiceDomains(p.customDomain, append([]Environment{EnvironmentProduction}, envs...), p.services); err != nil {
+		return err
+	}
+
+	p.environments = envs
+
+	// Removed environments are no longer protected
+	protected := make([]Environment, 0, len(p.protectedEnvs))
+	for _, env := range p.protectedEnvs {
+		if env.IsProduction() || seen[env] {
+			protected = append(protected, env)
+		}
+	}
+	p.protectedEnvs = protected
+
+	// Pushes are no longer deployed to removed environments
+	rules := make([]DeployRule, 0, len(p.deployRules))
+	for _, rule := range p.deployRules {
+		if rule.Environment().IsProduction() || seen[rule.Environment()] {
+			rules = append(rules, rule)
+		}
+	}
+	p.deployRules = rules
+
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// SetContainer sets the port the project's container listens on, the path the load balancer checks
+// and the CPU and memory of its tasks. Zero values select the defaults.
+func (p *Project) SetContainer(port int, healthCheckPath string, cpu, memory int) error {
+	if port == 0 {
+		port = DefaultPort
+	}
+	if port < 1 || port > 65535 {
+		return ErrInvalidPort
+	}
+
+	if healthCheckPath == "" {
+		healthCheckPath = DefaultHealthCheckPath
+	}
+	if !strings.HasPrefix(healthCheckPath, "/") || len(healthCheckPath) > 255 || strings.ContainsAny(healthCheckPath, " \t\n") {
+		return ErrInvalidHealthCheckPath
+	}
+
+	if cpu == 0 {
+		cpu = DefaultCPU
+	}
+	limits, ok := taskMemory[cpu]
+	if !ok {
+		return ErrInvalidTaskSize
+	}
+	if memory == 0 {
+		memory = limits[0]
+	}
+	if memory < limits[0] || memory > limits[1] || (cpu > 256 && memory%1024 != 0) ||
+		(cpu == 256 && memory != 512 && memory != 1024 && memory != 2048) {
+		return ErrInvalidTaskSize
+	}
+
+	p.port = port
+	p.healthCheckPath = healthCheckPath
+	p.cpu = cpu
+	p.memory = memory
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// SetBuildLimits sets the CPU and memory of the project's builds and how many minutes a build may run before
+// it is stopped and its deployment fails. Zero values select the defaults.
+func (p *Project) SetBuildLimits(size string, timeoutMinutes int) error {
+	buildSize, err := NewBuildSize(size)
+	if err != nil {
+		return ErrInvalidBuildLimits
+	}
+	if timeoutMinutes == 0 {
+		timeoutMinutes = DefaultBuildTimeoutMinutes
+	}
+	if timeoutMinutes < MinBuildTimeoutMinutes || timeoutMinutes > MaxBuildTimeoutMinutes {
+		return ErrInvalidBuildLimits
+	}
+
+	p.buildSize = buildSize
+	p.buildTimeout = timeoutMinutes
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// SetProtectedEnvironments sets the environments, production included, whose deployments wait for approval
+// before they are built
+func (p *Project) SetProtectedEnvironments(names []string) error {
+	protected := make([]Environment, 0, len(names))
+	seen := make(map[Environment]bool, len(names))
+	for _, name := range names {
+		env, err := NewEnvironment(name)
+		if err != nil || !p.HasEnvironment(env) {
+			return ErrInvalidProtectedEnvironments
+		}
+		if seen[env] {
+			continue
+		}
+		seen[env] = true
+		protected = append(protected, env)
+	}
+
+	p.protectedEnvs = protected
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// SetDeployRules sets the rules deciding which branches pushes are deployed from, and to which environment.
+// The first rule matching a pushed branch wins; pushes to branches no rule matches aren't deployed.
+func (p *Project) SetDeployRules(rules []DeployRule) error {
+	if len(rules) > MaxDeployRules {
+		return ErrInvalidDeployRules
+	}
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if seen[rule.Branch()] || !p.HasEnvironment(rule.Environment()) {
+			return ErrInvalidDeployRules
+		}
+		seen[rule.Branch()] = true
+	}
+
+	p.deployRules = rules
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// SetPublicStatusPage sets whether anyone, without signing in, may read the project's status page: its URL,
+// the status of its latest production deployment and its uptime
+func (p *Project) SetPublicStatusPage(enabled bool) {
+	p.publicStatus = enabled
+	p.updatedAt = time.Now()
+}
+
+// SetRedeployOnEnvChange sets whether changing an environment variable redeploys the image the environment
+// last deployed successfully, so the change reaches running tasks without waiting for the next deployment
+func (p *Project) SetRedeployOnEnvChange(enabled bool) {
+	p.envRedeploy = enabled
+	p.updatedAt = time.Now()
+}
+
+// Duplicate returns a new project of the same user and repository with the project's configuration, such as a
+// staging copy of it, under the given custom domain, generated when empty. The copy starts out active.
+func (p *Project) Duplicate(customDomain string) (*Project, error) {
+	domain, err := NewCustomDomain(customDomain)
+	if err != nil {
+		return nil, fmt.Errorf("invalid custom domain: %w", err)
+	}
+	if err := checkServiceDomains(domain, p.environments, p.services); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	duplicate := *p
+	duplicate.id = NewProjectID()
+	duplicate.customDomain = domain
+	duplicate.status = StatusActive
+	duplicate.statusMessage = ""
+	duplicate.datastores = slices.Clone(p.datastores)
+	duplicate.services = slices.Clone(p.services)
+	duplicate.environments = slices.Clone(p.environments)
+	duplicate.protectedEnvs = slices.Clone(p.protectedEnvs)
+	duplicate.deployRules = slices.Clone(p.deployRules)
+	duplicate.createdAt = now
+	duplicate.updatedAt = now
+	duplicate.deletedAt = nil
+	return &duplicate, nil
+}
+
+// MarkDeleting flags the project as being torn down
+func (p *Project) MarkDeleting() error {
+	if p.status == StatusDeleting {
+		return ErrProjectDeleting
+	}
+
+	p.status = StatusDeleting
+	p.statusMessage = "Teardown scheduled"
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// TeardownStalled checks if the project is being torn down but made no progress since staleBefore, e.g. because
+// the instance running the teardown stopped
+func (p *Project) TeardownStalled(staleBefore time.Time) bool {
+	return p.status == StatusDeleting && p.updatedAt.Before(staleBefore)
+}
+
+// ReportProgress records a progress message for the current status
+func (p *Project) ReportProgress(message string) {
+	p.statusMessage = message
+	p.updatedAt = time.Now()
+}
+
+// MarkDeleteFailed records that teardown failed so it can be retried
+func (p *Project) MarkDeleteFailed(reason string) {
+	p.status = StatusDeleteFailed
+	p.statusMessage = reason
+	p.updatedAt = time.Now()
+}
+
+// IsDeleting checks if the project is currently being torn down
+func (p *Project) IsDeleting() bool {
+	return p.status == StatusDeleting
+}
+
 // BelongsToUser checks if the project belongs to the specified user
 func (p *Project) BelongsToUser(userID user.UserID) bool {
 	return p.userID.Equals(userID)
@@ -239,6 +888,33 @@ func (p *Project) CustomDomain() CustomDomain {
 	return p.customDomain
 }
 
+// Subdomains returns every subdomain of the platform's domain the project claims: its custom domain and those
+// of its environments and WEB services, whether it is deployed to them yet or not
+func (p *Project) Subdomains() []string {
+	var subdomains []string
+	for _, env := range p.Environments() {
+		envDomain := p.customDomain.ForEnvironment(env)
+		subdomains = append(subdomains, envDomain.String())
+		for _, s := range p.services {
+			if s.Type().ServesTraffic() {
+				subdomains = append(subdomains, s.Subdomain(envDomain))
+			}
+		}
+	}
+	return subdomains
+}
+
+// SharedSubdomain returns a subdomain both projects claim, or an empty string if they claim none in common
+func (p *Project) SharedSubdomain(other *Project) string {
+	claimed := p.Subdomains()
+	for _, subdomain := range other.Subdomains() {
+		if slices.Contains(claimed, subdomain) {
+			return subdomain
+		}
+	}
+	return ""
+}
+
 func (p *Project) RequireDB() bool {
 	return p.requireDB
 }
@@ -247,8 +923,244 @@ func (p *Project) MigrationCommand() Command {
 	return p.migrationCommand
 }
 
+func (p *Project) Status() ProjectStatus {
+	return p.status
+}
+
+func (p *Project) StatusMessage() string {
+	return p.statusMessage
+}
+
+func (p *Project) ImageRetention() int {
+	return p.imageRetention
+}
+
+func (p *Project) Type() ProjectType {
+	return p.projectType
+}
+
+func (p *Project) Schedule() Schedule {
+	return p.schedule
+}
+
+func (p *Project) DeploymentStrategy() DeploymentStrategy {
+	return p.strategy
+}
+
+func (p *Project) CanaryPercent() int {
+	return p.canaryPercent
+}
+
+func (p *Project) CanaryBakeMinutes() int {
+	return p.canaryBake
+}
+
+func (p *Project) Datastores() []Datastore {
+	return append([]Datastore(nil), p.datastores...)
+}
+
+// UsesDatastore checks if the project has a datastore provisioned
+func (p *Project) UsesDatastore(datastore Datastore) bool {
+	for _, d := range p.datastores {
+		if d == datastore {
+			return true
+		}
+	}
+	return false
+}
+
+// HasVolume checks if the project mounts a persistent volume into its containers
+func (p *Project) HasVolume() bool {
+	return p.volumeMountPath != ""
+}
+
+func (p *Project) VolumeMountPath() string {
+	return p.volumeMountPath
+}
+
+func (p *Project) VolumeSizeGB() int {
+	return p.volumeSizeGB
+}
+
+func (p *Project) Services() []Service {
+	return append([]Service(nil), p.services...)
+}
+
+// Environments returns every environment the project is deployed to, production first
+func (p *Project) Environments() []Environment {
+	return append([]Environment{EnvironmentProduction}, p.environments...)
+}
+
+// HasEnvironment checks if the project is deployed to an environment
+func (p *Project) HasEnvironment(env Environment) bool {
+	for _, e := range p.Environments() {
+		if e == env {
+			return true
+		}
+	}
+	return false
+}
+
+// ProtectedEnvironments returns the environments whose deployments wait for approval
+func (p *Project) ProtectedEnvironments() []Environment {
+	return append([]Environment(nil), p.protectedEnvs...)
+}
+
+// IsProtected checks if deployments to an environment wait for approval
+func (p *Project) IsProtected(env Environment) bool {
+	for _, e := range p.protectedEnvs {
+		if e == env {
+			return true
+		}
+	}
+	return false
+}
+
+// DeployRules returns the rules deciding which branches pushes are deployed from, in the order they are matched
+func (p *Project) DeployRules() []DeployRule {
+	return append([]DeployRule(nil), p.deployRules...)
+}
+
+// PublicStatusPage checks if anyone may read the project's status page
+func (p *Project) PublicStatusPage() bool {
+	return p.publicStatus
+}
+
+// RedeployOnEnvChange checks if changing an environment variable redeploys its environment
+func (p *Project) RedeployOnEnvChange() bool {
+	return p.envRedeploy
+}
+
+// DeployEnvironmentFor returns the environment a push to a branch is deployed to, and false if pushes to the
+// branch aren't deployed
+func (p *Project) DeployEnvironmentFor(branch string) (Environment, bool) {
+	for _, rule := range p.deployRules {
+		if rule.Matches(branch) {
+			return rule.Environment(), true
+		}
+	}
+	return "", false
+}
+
+// RunsCronJob checks if the project runs on a schedule, as its main service or another one
+func (p *Project) RunsCronJob() bool {
+	if p.projectType == TypeCron {
+		return true
+	}
+	for _, s := range p.services {
+		if s.Type() == TypeCron {
+			return true
+		}
+	}
+	return false
+}
+
+// Port returns the port the project's container listens on
+func (p *Project) Port() int {
+	return p.port
+}
+
+// HealthCheckPath returns the path the load balancer checks the project's containers on
+func (p *Project) HealthCheckPath() string {
+	return p.healthCheckPath
+}
+
+// CPU returns the CPU units of the project's tasks, 1024 being one vCPU
+func (p *Project) CPU() int {
+	return p.cpu
+}
+
+// Memory returns the memory in MiB of the project's tasks
+func (p *Project) Memory() int {
+	return p.memory
+}
+
+// OutputDirectory returns the directory a STATIC project's build writes its files to, empty for other types
+func (p *Project) OutputDirectory() string {
+	return p.outputDirectory
+}
+
+// BuildSize returns the CPU and memory of the project's builds
+func (p *Project) BuildSize() BuildSize {
+	return p.buildSize
+}
+
+// BuildTimeoutMinutes returns how many minutes a build of the project may run before it is stopped
+func (p *Project) BuildTimeoutMinutes() int {
+	return p.buildTimeout
+}
+
+// BuildTimeout returns how long a build of the project may run before it is stopped
+func (p *Project) BuildTimeout() time.Duration {
+	return time.Duration(p.buildTimeout) * time.Minute
+}
+
+// DeploymentTarget returns the compute the project runs on
+func (p *Project) DeploymentTarget() DeploymentTarget {
+	return p.target
+}
+
+// LambdaEndpoint returns how requests reach a LAMBDA project, empty for ECS ones
+func (p *Project) LambdaEndpoint() LambdaEndpoint {
+	return p.lambdaEndpoint
+}
+
+// DeletedAt returns when the project was deleted, or nil if it hasn't been
+func (p *Project) DeletedAt() *time.Time {
+	return p.deletedAt
+}
+
 // String returns string representation (for debugging)
 func (p *Project) String() string {
 	return fmt.Sprintf("Project{id: %s, userID: %s, language: %s, domain: %s}",
 		p.id.String(), p.userID.String(), p.language.String(), p.customDomain.String())
 }
+
+// checkServiceDomains checks that the subdomains of a project's WEB services are valid DNS labels
+func checkServiceDomains(domain CustomDomain, environments []Environment, services []Service) error {
+	for _, env := range environments {
+		envDomain := domain.ForEnvironment(env)
+		if len(envDomain.String()) > 63 {
+			return ErrEnvironmentDomainTooLong
+		}
+		for _, s := range services {
+			if s.Type().ServesTraffic() && len(s.Subdomain(envDomain)) > 63 {
+				if env.IsProduction() {
+					return ErrServiceDomainTooLong
+				}
+				return ErrEnvironmentDomainTooLong
+			}
+		}
+	}
+	return nil
+}
+
+// checkEnvironmentNames makes sure no service subdomain can be mistaken for an environment's,
+// e.g. a staging service of my-app would be reachable at my-app-staging like the staging environment
+func checkEnvironmentNames(environments []Environment, services []Service) error {
+	for _, env := range environments {
+		for _, s := range services {
+			if s.Name() == env.String() || strings.HasPrefix(s.Name(), env.String()+"-") {
+				return ErrInvalidEnvironments
+			}
+		}
+	}
+	return nil
+}
+
+// newDatastores validates a list of datastores, dropping duplicates
+func newDatastores(datastores []string) ([]Datastore, error) {
+	stores := make([]Datastore, 0, len(datastores))
+	seen := make(map[Datastore]bool, len(datastores))
+	for _, name := range datastores {
+		datastore, err := NewDatastore(name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[datastore] {
+			seen[datastore] = true
+			stores = append(stores, datastore)
+		}
+	}
+	return stores, nil
+}
@@ -0,0 +1,946 @@
+package project_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func newTestProject(t *testing.T) *project.Project {
+	t.Helper()
+
+	proj, err := project.NewProject(
+		user.NewUserID(),
+		"https://github.com/user/my-app",
+		"npm install",
+		"npm run build",
+		"npm start",
+		"NODE",
+		"my-app",
+		false,
+		"",
+	)
+	if err != nil {
+		t.Fatalf("NewProject() error = %v", err)
+	}
+	return proj
+}
+
+func TestNewProjectIsActive(t *testing.T) {
+	proj := newTestProject(t)
+
+	if proj.Status() != project.StatusActive {
+		t.Errorf("Status() = %v, want %v", proj.Status(), project.StatusActive)
+	}
+	if proj.IsDeleting() {
+		t.Error("IsDeleting() = true, want false")
+	}
+}
+
+func TestMarkDeleting(t *testing.T) {
+	proj := newTestProject(t)
+
+	if err := proj.MarkDeleting(); err != nil {
+		t.Fatalf("MarkDeleting() error = %v", err)
+	}
+	if !proj.IsDeleting() {
+		t.Error("IsDeleting() = false, want true")
+	}
+
+	if err := proj.MarkDeleting(); !errors.Is(err, project.ErrProjectDeleting) {
+		t.Errorf("MarkDeleting() second call error = %v, want %v", err, project.ErrProjectDeleting)
+	}
+
+	if proj.TeardownStalled(time.Now().Add(-time.Minute)) {
+		t.Error("TeardownStalled() = true for a teardown that just started")
+	}
+	if !proj.TeardownStalled(time.Now().Add(time.Minute)) {
+		t.Error("TeardownStalled() = false for a teardown without progress since")
+	}
+}
+
+func TestMarkDeleteFailedAllowsRetry(t *testing.T) {
+	proj := newTestProject(t)
+
+	if err := proj.MarkDeleting(); err != nil {
+		t.Fatalf("MarkDeleting() error = %v", err)
+	}
+	proj.MarkDeleteFailed("failed to delete load balancer rule")
+
+	if proj.Status() != project.StatusDeleteFailed {
+		t.Errorf("Status() = %v, want %v", proj.Status(), project.StatusDeleteFailed)
+	}
+	if proj.StatusMessage() != "failed to delete load balancer rule" {
+		t.Errorf("StatusMessage() = %q", proj.StatusMessage())
+	}
+
+	if err := proj.MarkDeleting(); err != nil {
+		t.Errorf("MarkDeleting() after failure error = %v", err)
+	}
+}
+
+func TestNewProjectStatus(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    project.ProjectStatus
+		wantErr bool
+	}{
+		{input: "", want: project.StatusActive},
+		{input: "active", want: project.StatusActive},
+		{input: "DELETING", want: project.StatusDeleting},
+		{input: "DELETE_FAILED", want: project.StatusDeleteFailed},
+		{input: "ARCHIVED", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := project.NewProjectStatus(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewProjectStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NewProjectStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewProjectRolls(t *testing.T) {
+	proj := newTestProject(t)
+
+	if proj.DeploymentStrategy() != project.StrategyRolling {
+		t.Errorf("DeploymentStrategy() = %v, want %v", proj.DeploymentStrategy(), project.StrategyRolling)
+	}
+}
+
+func TestSetDeploymentStrategy(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    project.DeploymentStrategy
+		wantErr bool
+	}{
+		{input: "", want: project.StrategyRolling},
+		{input: "blue_green", want: project.StrategyBlueGreen},
+		{input: "RECREATE", want: project.StrategyRecreate},
+		{input: "CANARY", want: project.StrategyCanary},
+		{input: "SHADOW", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			proj := newTestProject(t)
+
+			err := proj.SetDeploymentStrategy(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, project.ErrInvalidDeploymentStrategy) {
+					t.Fatalf("SetDeploymentStrategy() error = %v, want %v", err, project.ErrInvalidDeploymentStrategy)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetDeploymentStrategy() error = %v", err)
+			}
+			if proj.DeploymentStrategy() != tt.want {
+				t.Errorf("DeploymentStrategy() = %v, want %v", proj.DeploymentStrategy(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSetType(t *testing.T) {
+	proj := newTestProject(t)
+	if proj.Type() != project.TypeWeb {
+		t.Fatalf("Type() = %v, want %v", proj.Type(), project.TypeWeb)
+	}
+
+	if err := proj.SetType("RPC"); !errors.Is(err, project.ErrInvalidProjectType) {
+		t.Fatalf("SetType() error = %v, want %v", err, project.ErrInvalidProjectType)
+	}
+	if err := proj.SetType("worker"); err != nil {
+		t.Fatalf("SetType() error = %v", err)
+	}
+	if proj.Type() != project.TypeWorker || proj.Type().ServesTraffic() {
+		t.Errorf("Type() = %v, want %v without traffic", proj.Type(), project.TypeWorker)
+	}
+
+	// Workers receive no traffic to shift between versions
+	for _, strategy := range []string{"BLUE_GREEN", "CANARY"} {
+		if err := proj.SetDeploymentStrategy(strategy); !errors.Is(err, project.ErrStrategyNeedsTraffic) {
+			t.Errorf("SetDeploymentStrategy(%s) error = %v, want %v", strategy, err, project.ErrStrategyNeedsTraffic)
+		}
+	}
+	if err := proj.SetDeploymentStrategy("RECREATE"); err != nil {
+		t.Errorf("SetDeploymentStrategy(RECREATE) error = %v", err)
+	}
+}
+
+func TestSetSchedule(t *testing.T) {
+	tests := []struct {
+		name        string
+		projectType string
+		schedule    string
+		wantErr     bool
+	}{
+		{name: "cron expression", projectType: "CRON", schedule: "cron(0 3 * * ? *)"},
+		{name: "rate of one", projectType: "CRON", schedule: "rate(1 hour)"},
+		{name: "rate of many", projectType: "CRON", schedule: "rate(15 minutes)"},
+		{name: "cron without schedule", projectType: "CRON", wantErr: true},
+		{name: "cron with five fields", projectType: "CRON", schedule: "cron(0 3 * * ?)", wantErr: true},
+		{name: "singular unit for many", projectType: "CRON", schedule: "rate(5 minute)", wantErr: true},
+		{name: "plural unit for one", projectType: "CRON", schedule: "rate(1 days)", wantErr: true},
+		{name: "web without schedule", projectType: "WEB"},
+		{name: "web with schedule", projectType: "WEB", schedule: "rate(1 hour)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proj := newTestProject(t)
+			if err := proj.SetType(tt.projectType); err != nil {
+				t.Fatalf("SetType() error = %v", err)
+			}
+
+			err := proj.SetSchedule(tt.schedule)
+			if tt.wantErr {
+				if !errors.Is(err, project.ErrInvalidSchedule) {
+					t.Fatalf("SetSchedule() error = %v, want %v", err, project.ErrInvalidSchedule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetSchedule() error = %v", err)
+			}
+			if proj.Schedule().String() != tt.schedule {
+				t.Errorf("Schedule() = %q, want %q", proj.Schedule(), tt.schedule)
+			}
+		})
+	}
+}
+
+func TestSetOutputDirectory(t *testing.T) {
+	tests := []struct {
+		name        string
+		projectType string
+		dir         string
+		want        string
+		wantErr     bool
+	}{
+		{name: "static default", projectType: "STATIC", want: project.DefaultOutputDirectory},
+		{name: "static directory", projectType: "STATIC", dir: "build", want: "build"},
+		{name: "nested with trailing slash", projectType: "STATIC", dir: " apps/web/out/ ", want: "apps/web/out"},
+		{name: "absolute path", projectType: "STATIC", dir: "/var/www", wantErr: true},
+		{name: "parent directory", projectType: "STATIC", dir: "../secrets", wantErr: true},
+		{name: "repository root", projectType: "STATIC", dir: ".", wantErr: true},
+		{name: "space in name", projectType: "STATIC", dir: "my site", wantErr: true},
+		{name: "web without directory", projectType: "WEB"},
+		{name: "web with directory", projectType: "WEB", dir: "dist", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proj := newTestProject(t)
+			if err := proj.SetType(tt.projectType); err != nil {
+				t.Fatalf("SetType() error = %v", err)
+			}
+
+			err := proj.SetOutputDirectory(tt.dir)
+			if tt.wantErr {
+				if !errors.Is(err, project.ErrInvalidOutputDirectory) {
+					t.Fatalf("SetOutputDirectory() error = %v, want %v", err, project.ErrInvalidOutputDirectory)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetOutputDirectory() error = %v", err)
+			}
+			if proj.OutputDirectory() != tt.want {
+				t.Errorf("OutputDirectory() = %q, want %q", proj.OutputDirectory(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSetDeploymentTarget(t *testing.T) {
+	tests := []struct {
+		name         string
+		projectType  string
+		datastores   []string
+		target       string
+		endpoint     string
+		wantTarget   project.DeploymentTarget
+		wantEndpoint project.LambdaEndpoint
+		wantErr      error
+	}{
+		{name: "default", projectType: "WEB", wantTarget: project.TargetECS},
+		{name: "lambda default endpoint", projectType: "WEB", target: "lambda", wantTarget: project.TargetLambda, wantEndpoint: project.EndpointAPIGateway},
+		{name: "lambda function url", projectType: "WEB", target: "LAMBDA", endpoint: "FUNCTION_URL", wantTarget: project.TargetLambda, wantEndpoint: project.EndpointFunctionURL},
+		{name: "lambda with mysql", projectType: "WEB", datastores: []string{"MYSQL"}, target: "LAMBDA", wantTarget: project.TargetLambda, wantEndpoint: project.EndpointAPIGateway},
+		{name: "unknown target", projectType: "WEB", target: "EC2", wantErr: project.ErrInvalidDeploymentTarget},
+		{name: "unknown endpoint", projectType: "WEB", target: "LAMBDA", endpoint: "ALB", wantErr: project.ErrInvalidDeploymentTarget},
+		{name: "endpoint without lambda", projectType: "WEB", target: "ECS", endpoint: "FUNCTION_URL", wantErr: project.ErrInvalidDeploymentTarget},
+		{name: "lambda worker", projectType: "WORKER", target: "LAMBDA", wantErr: project.ErrLambdaUnsupported},
+		{name: "lambda with redis", projectType: "WEB", datastores: []string{"REDIS"}, target: "LAMBDA", wantErr: project.ErrLambdaUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proj := newTestProject(t)
+			if err := proj.SetType(tt.projectType); err != nil {
+				t.Fatalf("SetType() error = %v", err)
+			}
+			if err := proj.SetDatastores(tt.datastores); err != nil {
+				t.Fatalf("SetDatastores() error = %v", err)
+			}
+
+			err := proj.SetDeploymentTarget(tt.target, tt.endpoint)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SetDeploymentTarget() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetDeploymentTarget() error = %v", err)
+			}
+			if proj.DeploymentTarget() != tt.wantTarget {
+				t.Errorf("DeploymentTarget() = %q, want %q", proj.DeploymentTarget(), tt.wantTarget)
+			}
+			if proj.LambdaEndpoint() != tt.wantEndpoint {
+				t.Errorf("LambdaEndpoint() = %q, want %q", proj.LambdaEndpoint(), tt.wantEndpoint)
+			}
+		})
+	}
+}
+
+func TestSetCanary(t *testing.T) {
+	tests := []struct {
+		name            string
+		percent, bake   int
+		wantPercent     int
+		wantBakeMinutes int
+		wantErr         bool
+	}{
+		{name: "defaults", wantPercent: project.DefaultCanaryPercent, wantBakeMinutes: project.DefaultCanaryBakeMinutes},
+		{name: "custom", percent: 25, bake: 30, wantPercent: 25, wantBakeMinutes: 30},
+		{name: "percent too high", percent: project.MaxCanaryPercent + 1, wantErr: true},
+		{name: "negative bake", bake: -1, wantErr: true},
+		{name: "bake too long", bake: project.MaxCanaryBakeMinutes + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proj := newTestProject(t)
+
+			err := proj.SetCanary(tt.percent, tt.bake)
+			if tt.wantErr {
+				if !errors.Is(err, project.ErrInvalidCanary) {
+					t.Fatalf("SetCanary() error = %v, want %v", err, project.ErrInvalidCanary)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetCanary() error = %v", err)
+			}
+			if proj.CanaryPercent() != tt.wantPercent || proj.CanaryBakeMinutes() != tt.wantBakeMinutes {
+				t.Errorf("SetCanary() = %d%%, %d minutes, want %d%%, %d minutes",
+					proj.CanaryPercent(), proj.CanaryBakeMinutes(), tt.wantPercent, tt.wantBakeMinutes)
+			}
+		})
+	}
+}
+
+func TestSetDatastores(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []string
+		want    []project.Datastore
+		wantErr bool
+	}{
+		{name: "none", input: nil, want: []project.Datastore{}},
+		{name: "redis", input: []string{"redis"}, want: []project.Datastore{project.DatastoreRedis}},
+		{name: "duplicates", input: []string{"MYSQL", "REDIS", "mysql"}, want: []project.Datastore{project.DatastoreMySQL, project.DatastoreRedis}},
+		{name: "unsupported", input: []string{"REDIS", "MONGODB"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proj := newTestProject(t)
+
+			err := proj.SetDatastores(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, project.ErrInvalidDatastore) {
+					t.Fatalf("SetDatastores() error = %v, want %v", err, project.ErrInvalidDatastore)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetDatastores() error = %v", err)
+			}
+
+			got := proj.Datastores()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Datastores() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Datastores() = %v, want %v", got, tt.want)
+				}
+				if !proj.UsesDatastore(tt.want[i]) {
+					t.Errorf("UsesDatastore(%v) = false", tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSetVolume(t *testing.T) {
+	tests := []struct {
+		name      string
+		mountPath string
+		sizeGB    int
+		wantSize  int
+		wantErr   bool
+	}{
+		{name: "none", mountPath: "", sizeGB: 0, wantSize: 0},
+		{name: "default size", mountPath: "/data", sizeGB: 0, wantSize: project.DefaultVolumeSizeGB},
+		{name: "custom size", mountPath: "/app/uploads", sizeGB: 25, wantSize: 25},
+		{name: "relative path", mountPath: "data", sizeGB: 5, wantErr: true},
+		{name: "unclean path", mountPath: "/data/../etc", sizeGB: 5, wantErr: true},
+		{name: "system directory", mountPath: "/etc", sizeGB: 5, wantErr: true},
+		{name: "too large", mountPath: "/data", sizeGB: project.MaxVolumeSizeGB + 1, wantErr: true},
+		{name: "size without path", mountPath: "", sizeGB: 5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proj := newTestProject(t)
+
+			err := proj.SetVolume(tt.mountPath, tt.sizeGB)
+			if tt.wantErr {
+				if !errors.Is(err, project.ErrInvalidVolume) {
+					t.Fatalf("SetVolume() error = %v, want %v", err, project.ErrInvalidVolume)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetVolume() error = %v", err)
+			}
+
+			if proj.HasVolume() != (tt.mountPath != "") || proj.VolumeMountPath() != tt.mountPath || proj.VolumeSizeGB() != tt.wantSize {
+				t.Errorf("volume = (%q, %d), want (%q, %d)", proj.VolumeMountPath(), proj.VolumeSizeGB(), tt.mountPath, tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestNewService(t *testing.T) {
+	tests := []struct {
+		name         string
+		serviceName  string
+		serviceType  string
+		port         int
+		replicas     int
+		schedule     string
+		wantPort     int
+		wantReplicas int
+		wantErr      bool
+	}{
+		{name: "web defaults", serviceName: "api", serviceType: "WEB", wantPort: project.DefaultServicePort, wantReplicas: 1},
+		{name: "web with port", serviceName: "admin-ui", serviceType: "WEB", port: 3000, replicas: 3, wantPort: 3000, wantReplicas: 3},
+		{name: "worker", serviceName: "jobs", serviceType: "WORKER", replicas: 2, wantReplicas: 2},
+		{name: "cron", serviceName: "cleanup", serviceType: "CRON", schedule: "rate(1 hour)", wantReplicas: 1},
+		{name: "worker with port", serviceName: "jobs", serviceType: "WORKER", port: 8080, wantErr: true},
+		{name: "worker with schedule", serviceName: "jobs", serviceType: "WORKER", schedule: "rate(1 hour)", wantErr: true},
+		{name: "cron without schedule", serviceName: "cleanup", serviceType: "CRON", wantErr: true},
+		{name: "cron with replicas", serviceName: "cleanup", serviceType: "CRON", replicas: 2, schedule: "rate(1 hour)", wantErr: true},
+		{name: "too many replicas", serviceName: "jobs", serviceType: "WORKER", replicas: project.MaxServiceReplicas + 1, wantErr: true},
+		{name: "reserved name", serviceName: "canary", serviceType: "WORKER", wantErr: true},
+		{name: "name too long", serviceName: "a-very-long-name", serviceType: "WORKER", wantErr: true},
+		{name: "name starting with digit", serviceName: "1jobs", serviceType: "WORKER", wantErr: true},
+		{name: "unknown type", serviceName: "jobs", serviceType: "BATCH", wantErr: true},
+		{name: "static", serviceName: "site", serviceType: "STATIC", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := project.NewService(tt.serviceName, tt.serviceType, "node jobs.js", tt.port, tt.replicas, tt.schedule)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("NewService() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewService() error = %v", err)
+			}
+
+			if svc.Port() != tt.wantPort || svc.Replicas() != tt.wantReplicas || svc.Schedule().String() != tt.schedule {
+				t.Errorf("service = (%d, %d, %q), want (%d, %d, %q)",
+					svc.Port(), svc.Replicas(), svc.Schedule(), tt.wantPort, tt.wantReplicas, tt.schedule)
+			}
+		})
+	}
+}
+
+func TestSetServices(t *testing.T) {
+	newService := func(name, serviceType, schedule string) project.Service {
+		t.Helper()
+		svc, err := project.NewService(name, serviceType, "node "+name+".js", 0, 0, schedule)
+		if err != nil {
+			t.Fatalf("NewService() error = %v", err)
+		}
+		return svc
+	}
+
+	proj := newTestProject(t)
+	if proj.RunsCronJob() {
+		t.Error("RunsCronJob() = true before any CRON service was added")
+	}
+
+	services := []project.Service{newService("api", "WEB", ""), newService("cleanup", "CRON", "rate(1 day)")}
+	if err := proj.SetServices(services); err != nil {
+		t.Fatalf("SetServices() error = %v", err)
+	}
+	if got := proj.Services(); len(got) != 2 || got[0].Name() != "api" || got[1].Name() != "cleanup" {
+		t.Errorf("Services() = %v", got)
+	}
+	if !proj.RunsCronJob() {
+		t.Error("RunsCronJob() = false with a CRON service")
+	}
+	if got := proj.Services()[0].Subdomain(proj.CustomDomain()); got != "my-app-api" {
+		t.Errorf("Subdomain() = %q, want %q", got, "my-app-api")
+	}
+
+	duplicate := []project.Service{newService("jobs", "WORKER", ""), newService("jobs", "WORKER", "")}
+	if err := proj.SetServices(duplicate); !errors.Is(err, project.ErrInvalidServices) {
+		t.Errorf("SetServices() with duplicate names error = %v, want %v", err, project.ErrInvalidServices)
+	}
+
+	tooMany := make([]project.Service, 0, project.MaxServices+1)
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		tooMany = append(tooMany, newService(name, "WORKER", ""))
+	}
+	if err := proj.SetServices(tooMany); !errors.Is(err, project.ErrInvalidServices) {
+		t.Errorf("SetServices() with %d services error = %v, want %v", len(tooMany), err, project.ErrInvalidServices)
+	}
+
+	longDomain := strings.Repeat("a", 60)
+	if err := proj.Update("https://github.com/user/my-app", "npm install", "", "npm start", "NODE", longDomain, false, ""); !errors.Is(err, project.ErrServiceDomainTooLong) {
+		t.Errorf("Update() with a long custom domain error = %v, want %v", err, project.ErrServiceDomainTooLong)
+	}
+
+	if err := proj.SetServices(nil); err != nil {
+		t.Fatalf("SetServices(nil) error = %v", err)
+	}
+	if len(proj.Services()) != 0 || proj.RunsCronJob() {
+		t.Errorf("Services() = %v after clearing them", proj.Services())
+	}
+}
+
+func TestNewEnvVarScope(t *testing.T) {
+	tests := []struct {
+		scope     string
+		want      project.EnvVarScope
+		atBuild   bool
+		atRuntime bool
+		wantErr   bool
+	}{
+		{scope: "", want: project.ScopeRuntime, atRuntime: true},
+		{scope: "RUNTIME", want: project.ScopeRuntime, atRuntime: true},
+		{scope: "build", want: project.ScopeBuild, atBuild: true},
+		{scope: " Both ", want: project.ScopeBoth, atBuild: true, atRuntime: true},
+		{scope: "DEPLOY", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			got, err := project.NewEnvVarScope(tt.scope)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewEnvVarScope(%q) error = %v, wantErr %v", tt.scope, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("NewEnvVarScope(%q) = %v, want %v", tt.scope, got, tt.want)
+			}
+			if got.AtBuild() != tt.atBuild || got.AtRuntime() != tt.atRuntime {
+				t.Errorf("%v: AtBuild() = %v, AtRuntime() = %v", got, got.AtBuild(), got.AtRuntime())
+			}
+		})
+	}
+}
+
+func TestSetEnvironments(t *testing.T) {
+	proj := newTestProject(t)
+	if got := proj.Environments(); len(got) != 1 || got[0] != project.EnvironmentProduction {
+		t.Errorf("Environments() = %v, want only production", got)
+	}
+
+	if err := proj.SetEnvironments([]string{"staging", " Preview "}); err != nil {
+		t.Fatalf("SetEnvironments() error = %v", err)
+	}
+	if got := proj.Environments(); len(got) != 3 || got[1] != "staging" || got[2] != "preview" {
+		t.Errorf("Environments() = %v", got)
+	}
+	if !proj.HasEnvironment("staging") || proj.HasEnvironment("qa") {
+		t.Error("HasEnvironment() doesn't match the environments that were set")
+	}
+	if got := proj.CustomDomain().ForEnvironment("staging").String(); got != "my-app-staging" {
+		t.Errorf("ForEnvironment(staging) = %q, want %q", got, "my-app-staging")
+	}
+	if got := proj.CustomDomain().ForEnvironment(project.EnvironmentProduction).String(); got != "my-app" {
+		t.Errorf("ForEnvironment(production) = %q, want %q", got, "my-app")
+	}
+
+	invalid := map[string][]string{
+		"production":   {"production"},
+		"duplicates":   {"staging", "staging"},
+		"invalid name": {"Staging_1"},
+		"too many":     {"a", "b", "c", "d", "e", "f"},
+	}
+	for name, envs := range invalid {
+		if err := proj.SetEnvironments(envs); !errors.Is(err, project.ErrInvalidEnvironments) {
+			t.Errorf("SetEnvironments() with %s error = %v, want %v", name, err, project.ErrInvalidEnvironments)
+		}
+	}
+
+	// A service named after an environment would share its subdomain
+	api, err := project.NewService("staging-api", "WEB", "node api.js", 0, 0, "")
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	if err := proj.SetServices([]project.Service{api}); !errors.Is(err, project.ErrInvalidEnvironments) {
+		t.Errorf("SetServices() with a service named after an environment error = %v, want %v", err, project.ErrInvalidEnvironments)
+	}
+
+	longDomain := strings.Repeat("a", 60)
+	if err := proj.Update("https://github.com/user/my-app", "npm install", "", "npm start", "NODE", longDomain, false, ""); !errors.Is(err, project.ErrEnvironmentDomainTooLong) {
+		t.Errorf("Update() with a long custom domain error = %v, want %v", err, project.ErrEnvironmentDomainTooLong)
+	}
+
+	if err := proj.SetEnvironments(nil); err != nil {
+		t.Fatalf("SetEnvironments(nil) error = %v", err)
+	}
+	if len(proj.Environments()) != 1 {
+		t.Errorf("Environments() = %v after clearing them", proj.Environments())
+	}
+}
+
+func TestSetProtectedEnvironments(t *testing.T) {
+	proj := newTestProject(t)
+	if err := proj.SetEnvironments([]string{"staging"}); err != nil {
+		t.Fatalf("SetEnvironments() error = %v", err)
+	}
+
+	if err := proj.SetProtectedEnvironments([]string{"production", "production"}); err != nil {
+		t.Fatalf("SetProtectedEnvironments() error = %v", err)
+	}
+	if got := proj.ProtectedEnvironments(); len(got) != 1 || got[0] != project.EnvironmentProduction {
+		t.Errorf("ProtectedEnvironments() = %v, want only production", got)
+	}
+	if !proj.IsProtected(project.EnvironmentProduction) || proj.IsProtected("staging") {
+		t.Error("IsProtected() doesn't match the environments that were protected")
+	}
+
+	if err := proj.SetProtectedEnvironments([]string{"qa"}); !errors.Is(err, project.ErrInvalidProtectedEnvironments) {
+		t.Errorf("SetProtectedEnvironments() with an unknown environment error = %v, want %v", err, project.ErrInvalidProtectedEnvironments)
+	}
+
+	// Removing an environment drops its protection
+	if err := proj.SetProtectedEnvironments([]string{"staging"}); err != nil {
+		t.Fatalf("SetProtectedEnvironments() error = %v", err)
+	}
+	if err := proj.SetEnvironments(nil); err != nil {
+		t.Fatalf("SetEnvironments(nil) error = %v", err)
+	}
+	if proj.IsProtected("staging") || len(proj.ProtectedEnvironments()) != 0 {
+		t.Errorf("ProtectedEnvironments() = %v after removing staging", proj.ProtectedEnvironments())
+	}
+}
+
+func TestNewDeployRule(t *testing.T) {
+	tests := []struct {
+		name        string
+		branch      string
+		environment string
+		wantErr     bool
+	}{
+		{"branch", "main", "production", false},
+		{"pattern", "release/*", "staging", false},
+		{"empty environment is production", "main", "", false},
+		{"empty branch", " ", "production", true},
+		{"branch with spaces", "my branch", "production", true},
+		{"malformed pattern", "release/[", "staging", true},
+		{"invalid environment", "main", "Prod!", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := project.NewDeployRule(tt.branch, tt.environment)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewDeployRule(%q, %q) error = %v, wantErr %v", tt.branch, tt.environment, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetDeployRules(t *testing.T) {
+	proj := newTestProject(t)
+	if err := proj.SetEnvironments([]string{"staging"}); err != nil {
+		t.Fatalf("SetEnvironments() error = %v", err)
+	}
+
+	rules := make([]project.DeployRule, 0, 3)
+	for _, r := range [][2]string{{"main", "production"}, {"develop", "staging"}, {"release/*", "staging"}} {
+		rule, err := project.NewDeployRule(r[0], r[1])
+		if err != nil {
+			t.Fatalf("NewDeployRule(%q, %q) error = %v", r[0], r[1], err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := proj.SetDeployRules(rules); err != nil {
+		t.Fatalf("SetDeployRules() error = %v", err)
+	}
+
+	tests := []struct {
+		branch string
+		want   project.Environment
+		ok     bool
+	}{
+		{"main", project.EnvironmentProduction, true},
+		{"develop", "staging", true},
+		{"release/1.2", "staging", true},
+		{"release/1.2/hotfix", "", false},
+		{"feature/login", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := proj.DeployEnvironmentFor(tt.branch); got != tt.want || ok != tt.ok {
+			t.Errorf("DeployEnvironmentFor(%q) = %q, %v, want %q, %v", tt.branch, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	qa, _ := project.NewDeployRule("qa", "qa")
+	if err := proj.SetDeployRules([]project.DeployRule{qa}); !errors.Is(err, project.ErrInvalidDeployRules) {
+		t.Errorf("SetDeployRules() with an unknown environment error = %v, want %v", err, project.ErrInvalidDeployRules)
+	}
+	if err := proj.SetDeployRules([]project.DeployRule{rules[0], rules[0]}); !errors.Is(err, project.ErrInvalidDeployRules) {
+		t.Errorf("SetDeployRules() with a repeated branch error = %v, want %v", err, project.ErrInvalidDeployRules)
+	}
+
+	// Removing an environment drops the rules deploying to it
+	if err := proj.SetEnvironments(nil); err != nil {
+		t.Fatalf("SetEnvironments(nil) error = %v", err)
+	}
+	if got := proj.DeployRules(); len(got) != 1 || got[0].Branch() != "main" {
+		t.Errorf("DeployRules() = %v after removing staging, want only main", got)
+	}
+}
+
+func TestSetContainer(t *testing.T) {
+	proj := newTestProject(t)
+	if proj.Port() != project.DefaultPort || proj.HealthCheckPath() != "/" || proj.CPU() != 256 || proj.Memory() != 512 {
+		t.Fatalf("new project container = %d %s %d/%d, want the defaults", proj.Port(), proj.HealthCheckPath(), proj.CPU(), proj.Memory())
+	}
+
+	if err := proj.SetContainer(3000, "/healthz", 1024, 0); err != nil {
+		t.Fatalf("SetContainer() error = %v", err)
+	}
+	if proj.Port() != 3000 || proj.HealthCheckPath() != "/healthz" || proj.CPU() != 1024 || proj.Memory() != 2048 {
+		t.Errorf("container = %d %s %d/%d, want 3000 /healthz 1024/2048", proj.Port(), proj.HealthCheckPath(), proj.CPU(), proj.Memory())
+	}
+
+	tests := []struct {
+		name            string
+		port            int
+		healthCheckPath string
+		cpu, memory     int
+		wantErr         error
+	}{
+		{"port out of range", 70000, "", 0, 0, project.ErrInvalidPort},
+		{"relative health check path", 0, "healthz", 0, 0, project.ErrInvalidHealthCheckPath},
+		{"health check path with spaces", 0, "/health check", 0, 0, project.ErrInvalidHealthCheckPath},
+		{"unsupported cpu", 0, "", 300, 0, project.ErrInvalidTaskSize},
+		{"too little memory", 0, "", 2048, 2048, project.ErrInvalidTaskSize},
+		{"memory between steps", 0, "", 512, 1536, project.ErrInvalidTaskSize},
+		{"smallest size", 0, "", 256, 2048, nil},
+		{"largest size", 0, "", 4096, 30720, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := proj.SetContainer(tt.port, tt.healthCheckPath, tt.cpu, tt.memory)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SetContainer() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetBuildLimits(t *testing.T) {
+	proj := newTestProject(t)
+	if proj.BuildSize() != project.BuildSmall || proj.BuildTimeoutMinutes() != project.DefaultBuildTimeoutMinutes {
+		t.Fatalf("new project builds %s for %d minutes, want the defaults", proj.BuildSize(), proj.BuildTimeoutMinutes())
+	}
+
+	if err := proj.SetBuildLimits("large", 90); err != nil {
+		t.Fatalf("SetBuildLimits() error = %v", err)
+	}
+	if proj.BuildSize() != project.BuildLarge || proj.BuildTimeout() != 90*time.Minute {
+		t.Errorf("builds %s for %s, want LARGE for 1h30m", proj.BuildSize(), proj.BuildTimeout())
+	}
+	if proj.BuildSize().VCPUs() != 8 || proj.BuildSize().MemoryMiB() != 15360 {
+		t.Errorf("LARGE builds get %d vCPUs and %d MiB, want 8 and 15360", proj.BuildSize().VCPUs(), proj.BuildSize().MemoryMiB())
+	}
+
+	tests := []struct {
+		name    string
+		size    string
+		timeout int
+		wantErr error
+	}{
+		{"defaults", "", 0, nil},
+		{"unsupported size", "HUGE", 0, project.ErrInvalidBuildLimits},
+		{"timeout too short", "", project.MinBuildTimeoutMinutes - 1, project.ErrInvalidBuildLimits},
+		{"timeout too long", "", project.MaxBuildTimeoutMinutes + 1, project.ErrInvalidBuildLimits},
+		{"longest timeout", "MEDIUM", project.MaxBuildTimeoutMinutes, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := proj.SetBuildLimits(tt.size, tt.timeout)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SetBuildLimits() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLanguageForeignTool(t *testing.T) {
+	tests := []struct {
+		language project.Language
+		command  string
+		want     string
+	}{
+		{language: project.LanguageNode, command: "npm ci", want: ""},
+		{language: project.LanguageNextJS, command: "NODE_ENV=production yarn build", want: ""},
+		{language: project.LanguageNode, command: "pip install -r requirements.txt", want: "pip"},
+		{language: project.LanguagePython, command: "pip install -r requirements.txt && npm run build", want: "npm"},
+		{language: project.LanguagePython, command: "gunicorn app:app", want: ""},
+		{language: project.LanguageGo, command: "go build -o app . && ./app", want: ""},
+		{language: project.LanguageGo, command: "make build", want: ""},
+		{language: project.LanguageGo, command: "python3 main.py", want: "python3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := tt.language.ForeignTool(tt.command); got != tt.want {
+				t.Errorf("%v.ForeignTool(%q) = %q, want %q", tt.language, tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSharedSubdomain(t *testing.T) {
+	newProject := func(domain string, environments []string, services ...project.Service) *project.Project {
+		proj, err := project.NewProject(user.NewUserID(), "https://github.com/user/"+domain, "npm ci", "", "npm start", "NODE", domain, false, "")
+		if err != nil {
+			t.Fatalf("NewProject() error = %v", err)
+		}
+		if err := proj.SetServices(services); err != nil {
+			t.Fatalf("SetServices() error = %v", err)
+		}
+		if err := proj.SetEnvironments(environments); err != nil {
+			t.Fatalf("SetEnvironments() error = %v", err)
+		}
+		return proj
+	}
+	api, err := project.NewService("api", "WEB", "npm run api", 0, 0, "")
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	shop := newProject("shop", []string{"staging"}, api)
+	if want := []string{"shop", "shop-api", "shop-staging", "shop-staging-api"}; !slices.Equal(shop.Subdomains(), want) {
+		t.Errorf("Subdomains() = %v, want %v", shop.Subdomains(), want)
+	}
+
+	tests := []struct {
+		other *project.Project
+		want  string
+	}{
+		{other: newProject("shop-staging", nil), want: "shop-staging"},
+		{other: newProject("shop", []string{"api"}), want: "shop"},
+		{other: newProject("my", []string{"shop"}), want: ""},
+		{other: newProject("shop-preview", nil), want: ""},
+	}
+	for _, tt := range tests {
+		got := shop.SharedSubdomain(tt.other)
+		if got != tt.want {
+			t.Errorf("SharedSubdomain(%v) = %q, want %q", tt.other.Subdomains(), got, tt.want)
+		}
+		// Each project is found among the claimants of the other's subdomains
+		if got != "" && !slices.Contains(project.ClaimantDomains(shop.Subdomains()), tt.other.CustomDomain().String()) {
+			t.Errorf("ClaimantDomains(%v) misses %s", shop.Subdomains(), tt.other.CustomDomain())
+		}
+	}
+}
+
+func TestDuplicate(t *testing.T) {
+	proj := newTestProject(t)
+	if err := proj.SetEnvironments([]string{"staging"}); err != nil {
+		t.Fatalf("SetEnvironments() error = %v", err)
+	}
+	if err := proj.SetContainer(3000, "/health", 512, 1024); err != nil {
+		t.Fatalf("SetContainer() error = %v", err)
+	}
+	if err := proj.MarkDeleting(); err != nil {
+		t.Fatalf("MarkDeleting() error = %v", err)
+	}
+
+	duplicate, err := proj.Duplicate("my-app-copy")
+	if err != nil {
+		t.Fatalf("Duplicate() error = %v", err)
+	}
+
+	if duplicate.ID().Equals(proj.ID()) {
+		t.Error("Duplicate() kept the project ID")
+	}
+	if duplicate.CustomDomain().String() != "my-app-copy" {
+		t.Errorf("CustomDomain() = %s, want my-app-copy", duplicate.CustomDomain())
+	}
+	if duplicate.UserID() != proj.UserID() || duplicate.RepositoryURL().String() != proj.RepositoryURL().String() {
+		t.Error("Duplicate() changed the user or repository")
+	}
+	if duplicate.Port() != 3000 || duplicate.HealthCheckPath() != "/health" || duplicate.Memory() != 1024 {
+		t.Errorf("Duplicate() container = %d %s %d, want the project's", duplicate.Port(), duplicate.HealthCheckPath(), duplicate.Memory())
+	}
+	if !slices.Equal(duplicate.Environments(), proj.Environments()) {
+		t.Errorf("Environments() = %v, want %v", duplicate.Environments(), proj.Environments())
+	}
+	if duplicate.Status() != project.StatusActive {
+		t.Errorf("Status() = %v, want %v", duplicate.Status(), project.StatusActive)
+	}
+
+	// Changing the copy leaves the project alone
+	if err := duplicate.SetEnvironments(nil); err != nil {
+		t.Fatalf("SetEnvironments() error = %v", err)
+	}
+	if len(proj.Environments()) != 2 {
+		t.Errorf("Environments() = %v after changing the copy", proj.Environments())
+	}
+
+	generated, err := proj.Duplicate("")
+	if err != nil {
+		t.Fatalf("Duplicate() error = %v", err)
+	}
+	if generated.CustomDomain().String() == "" || generated.CustomDomain().Equals(proj.CustomDomain()) {
+		t.Errorf("CustomDomain() = %q, want a generated one", generated.CustomDomain())
+	}
+
+	if _, err := proj.Duplicate("Not A Domain"); err == nil {
+		t.Error("Duplicate() with an invalid domain should fail")
+	}
+}
@@ -6,22 +6,27 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"snapdeploy-core/internal/domain/validation"
 )
 
-// EnvironmentVariable represents a project environment variable
+// EnvironmentVariable represents a variable of one of a project's environments
 type EnvironmentVariable struct {
 	id        EnvVarID
 	projectID ProjectID
+	env       Environment
 	key       EnvVarKey
 	value     EnvVarValue
+	scope     EnvVarScope
 	createdAt time.Time
 	updatedAt time.Time
 }
 
-// NewEnvironmentVariable creates a new environment variable
+// NewEnvironmentVariable creates a new environment variable. An empty scope makes it a runtime variable.
 func NewEnvironmentVariable(
 	projectID ProjectID,
-	key, value string,
+	env Environment,
+	key, value, scope string,
 ) (*EnvironmentVariable, error) {
 	envKey, err := NewEnvVarKey(key)
 	if err != nil {
@@ -33,12 +38,19 @@ func NewEnvironmentVariable(
 		return nil, fmt.Errorf("invalid value: %w", err)
 	}
 
+	envScope, err := NewEnvVarScope(scope)
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 	return &EnvironmentVariable{
 		id:        NewEnvVarID(),
 		projectID: projectID,
+		env:       env,
 		key:       envKey,
 		value:     envValue,
+		scope:     envScope,
 		createdAt: now,
 		updatedAt: now,
 	}, nil
@@ -48,7 +60,7 @@ func NewEnvironmentVariable(
 func ReconstituteEnvVar(
 	id string,
 	projectID ProjectID,
-	key, encryptedValue string,
+	key, encryptedValue, scope, environment string,
 	createdAt, updatedAt time.Time,
 ) (*EnvironmentVariable, error) {
 	envID, err := ParseEnvVarID(id)
@@ -63,11 +75,23 @@ func ReconstituteEnvVar(
 
 	envValue := NewEnvVarValueFromEncrypted(encryptedValue)
 
+	envScope, err := NewEnvVarScope(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := NewEnvironment(environment)
+	if err != nil {
+		return nil, err
+	}
+
 	return &EnvironmentVariable{
 		id:        envID,
 		projectID: projectID,
+		env:       env,
 		key:       envKey,
 		value:     envValue,
+		scope:     envScope,
 		createdAt: createdAt,
 		updatedAt: updatedAt,
 	}, nil
@@ -95,6 +119,10 @@ func (e *EnvironmentVariable) ProjectID() ProjectID {
 	return e.projectID
 }
 
+func (e *EnvironmentVariable) Environment() Environment {
+	return e.env
+}
+
 func (e *EnvironmentVariable) Key() EnvVarKey {
 	return e.key
 }
@@ -103,6 +131,10 @@ func (e *EnvironmentVariable) Value() EnvVarValue {
 	return e.value
 }
 
+func (e *EnvironmentVariable) Scope() EnvVarScope {
+	return e.scope
+}
+
 func (e *EnvironmentVariable) CreatedAt() time.Time {
 	return e.createdAt
 }
@@ -123,7 +155,7 @@ func NewEnvVarID() EnvVarID {
 func ParseEnvVarID(id string) (EnvVarID, error) {
 	uid, err := uuid.Parse(id)
 	if err != nil {
-		return EnvVarID{}, fmt.Errorf("invalid env var ID format: %w", err)
+		return EnvVarID{}, validation.Errorf("invalid env var ID format: %w", err)
 	}
 	return EnvVarID{value: uid}, nil
 }
@@ -145,17 +177,17 @@ func NewEnvVarKey(key string) (EnvVarKey, error) {
 	key = strings.TrimSpace(key)
 
 	if key == "" {
-		return EnvVarKey{}, fmt.Errorf("environment variable key cannot be empty")
+		return EnvVarKey{}, validation.Errorf("environment variable key cannot be empty")
 	}
 
 	// Validate key format (Unix env var rules)
 	// Must start with letter or underscore, contain only alphanumeric and underscores
 	if !isValidEnvVarKey(key) {
-		return EnvVarKey{}, fmt.Errorf("invalid key format: must start with letter/underscore and contain only alphanumeric and underscores")
+		return EnvVarKey{}, validation.Errorf("invalid key format: must start with letter/underscore and contain only alphanumeric and underscores")
 	}
 
 	if len(key) > 255 {
-		return EnvVarKey{}, fmt.Errorf("key too long (max 255 characters)")
+		return EnvVarKey{}, validation.Errorf("key too long (max 255 characters)")
 	}
 
 	return EnvVarKey{value: key}, nil
@@ -191,13 +223,64 @@ func isValidEnvVarKey(key string) bool {
 	return true
 }
 
+// EnvVarScope is the stage of a deployment an environment variable is passed to
+type EnvVarScope string
+
+const (
+	// ScopeBuild passes the variable to the image build as a Docker build arg only
+	ScopeBuild EnvVarScope = "BUILD"
+	// ScopeRuntime passes the variable to the running containers only
+	ScopeRuntime EnvVarScope = "RUNTIME"
+	// ScopeBoth passes the variable to the image build and the running containers
+	ScopeBoth EnvVarScope = "BOTH"
+)
+
+// NewEnvVarScope creates a new EnvVarScope with validation
+func NewEnvVarScope(scope string) (EnvVarScope, error) {
+	scope = strings.ToUpper(strings.TrimSpace(scope))
+
+	// Variables were only passed to containers before they had a scope
+	if scope == "" {
+		return ScopeRuntime, nil
+	}
+
+	switch EnvVarScope(scope) {
+	case ScopeBuild, ScopeRuntime, ScopeBoth:
+		return EnvVarScope(scope), nil
+	default:
+		return "", validation.Errorf("invalid scope: %s (must be one of: BUILD, RUNTIME, BOTH)", scope)
+	}
+}
+
+func (s EnvVarScope) String() string {
+	return string(s)
+}
+
+// AtBuild reports whether variables of this scope are passed to the image build
+func (s EnvVarScope) AtBuild() bool {
+	return s == ScopeBuild || s == ScopeBoth
+}
+
+// AtRuntime reports whether variables of this scope are passed to the running containers
+func (s EnvVarScope) AtRuntime() bool {
+	return s == ScopeRuntime || s == ScopeBoth
+}
+
 // EnvVarValue is a value object for environment variable value
 // Stores encrypted value and provides masked representation
 type EnvVarValue struct {
 	encryptedValue string
 }
 
+// MaxEnvVarValueBytes bounds the value of an environment variable, every variable of an environment ends up in
+// the task definitions deployed with it
+const MaxEnvVarValueBytes = 32 << 10
+
 func NewEnvVarValue(plaintext string) (EnvVarValue, error) {
+	if len(plaintext) > MaxEnvVarValueBytes {
+		return EnvVarValue{}, validation.Errorf("value too long (max %d bytes)", MaxEnvVarValueBytes)
+	}
+
 	// Value will be encrypted by the application service before storage
 	// This constructor is used when creating/updating
 	return EnvVarValue{encryptedValue: plaintext}, nil
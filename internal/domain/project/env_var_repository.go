@@ -7,19 +7,22 @@ type EnvironmentVariableRepository interface {
 	// Save persists an environment variable (create or update)
 	Save(ctx context.Context, envVar *EnvironmentVariable) error
 
-	// FindByProjectID retrieves all environment variables for a project
-	FindByProjectID(ctx context.Context, projectID ProjectID) ([]*EnvironmentVariable, error)
+	// FindByProjectID retrieves all environment variables of a project's environment
+	FindByProjectID(ctx context.Context, projectID ProjectID, env Environment) ([]*EnvironmentVariable, error)
 
-	// FindByKey retrieves a specific environment variable by project and key
-	FindByKey(ctx context.Context, projectID ProjectID, key EnvVarKey) (*EnvironmentVariable, error)
+	// FindByKey retrieves a specific environment variable by project, environment and key
+	FindByKey(ctx context.Context, projectID ProjectID, env Environment, key EnvVarKey) (*EnvironmentVariable, error)
 
 	// Delete removes an environment variable
-	Delete(ctx context.Context, projectID ProjectID, key EnvVarKey) error
+	Delete(ctx context.Context, projectID ProjectID, env Environment, key EnvVarKey) error
+
+	// DeleteEnvironment removes all environment variables of a project's environment
+	DeleteEnvironment(ctx context.Context, projectID ProjectID, env Environment) error
 
 	// DeleteAll removes all environment variables for a project
 	DeleteAll(ctx context.Context, projectID ProjectID) error
 
-	// Count returns the number of environment variables for a project
-	Count(ctx context.Context, projectID ProjectID) (int64, error)
+	// Count returns the number of environment variables of a project's environment
+	Count(ctx context.Context, projectID ProjectID, env Environment) (int64, error)
 }
 
@@ -1,6 +1,10 @@
 package project
 
-import "errors"
+import (
+	"errors"
+
+	"snapdeploy-core/internal/domain/validation"
+)
 
 var (
 	// ErrProjectNotFound is returned when a project is not found
@@ -9,9 +13,87 @@ var (
 	// ErrProjectAlreadyExists is returned when a project with the same repository URL already exists for a user
 	ErrProjectAlreadyExists = errors.New("project with this repository URL already exists")
 
+	// ErrCustomDomainTaken is returned when a project would claim a subdomain another project already claims,
+	// with its custom domain or those of its environments and WEB services
+	ErrCustomDomainTaken = errors.New("custom domain is already used by another project")
+
 	// ErrUnauthorized is returned when a user tries to access a project they don't own
 	ErrUnauthorized = errors.New("unauthorized to access this project")
 
+	// ErrInvalidImageRetention is returned when a project's image retention is out of range
+	ErrInvalidImageRetention = validation.New("image retention must be between 0 and 100 deployments")
+
+	// ErrInvalidDeploymentStrategy is returned when a project's deployment strategy is not supported
+	ErrInvalidDeploymentStrategy = validation.New("deployment strategy must be one of ROLLING, BLUE_GREEN, RECREATE, CANARY")
+
+	// ErrInvalidProjectType is returned when a project's type is not supported
+	ErrInvalidProjectType = validation.New("project type must be one of WEB, WORKER, CRON, STATIC")
+
+	// ErrInvalidSchedule is returned when a CRON project has no valid schedule or another project has one
+	ErrInvalidSchedule = validation.New("CRON projects need a schedule such as cron(0 3 * * ? *) or rate(1 hour), other projects can't have one")
+
+	// ErrInvalidOutputDirectory is returned when a STATIC project's output directory is not a path inside
+	// the repository, or another project has one
+	ErrInvalidOutputDirectory = validation.New("output_directory must be a relative path inside the repository such as dist or build, and only STATIC projects have one")
+
+	// ErrStrategyNeedsTraffic is returned when a project that receives no traffic chooses a strategy that shifts it
+	ErrStrategyNeedsTraffic = validation.New("BLUE_GREEN and CANARY deployments shift traffic, WORKER, CRON and STATIC projects must use ROLLING or RECREATE")
+
+	// ErrInvalidDeploymentTarget is returned when a project's deployment target or Lambda endpoint is not supported
+	ErrInvalidDeploymentTarget = validation.New("deployment target must be ECS or LAMBDA, and only LAMBDA projects choose a lambda_endpoint of API_GATEWAY or FUNCTION_URL")
+
+	// ErrLambdaUnsupported is returned when a project deployed to Lambda uses a feature only ECS provides
+	ErrLambdaUnsupported = validation.New("LAMBDA projects must be WEB projects without services, a persistent volume or a REDIS datastore")
+
+	// ErrInvalidServices is returned when a project defines too many services, or two with the same name
+	ErrInvalidServices = validation.New("projects can run at most 5 services besides their main one, each with a unique name")
+
+	// ErrServiceDomainTooLong is returned when a WEB service's subdomain would be longer than DNS allows
+	ErrServiceDomainTooLong = validation.New("custom domain and WEB service name together must be at most 62 characters")
+
+	// ErrInvalidEnvironments is returned when a project defines too many environments, or ones whose names clash
+	ErrInvalidEnvironments = validation.New("projects can have at most 5 environments besides production, each with a unique name no service is named after")
+
+	// ErrEnvironmentNotFound is returned when an operation targets an environment the project doesn't have
+	ErrEnvironmentNotFound = errors.New("environment not found")
+
+	// ErrInvalidProtectedEnvironments is returned when protecting an environment the project isn't deployed to
+	ErrInvalidProtectedEnvironments = validation.New("protected environments must be production or one of the project's environments")
+
+	// ErrInvalidDeployRules is returned when a project defines too many branch rules, or ones that are malformed,
+	// repeat a branch or deploy to an environment the project doesn't have
+	ErrInvalidDeployRules = validation.New("projects can have at most 20 deploy rules, each with a unique branch pattern and one of the project's environments")
+
+	// ErrEnvironmentDomainTooLong is returned when an environment's subdomain would be longer than DNS allows
+	ErrEnvironmentDomainTooLong = validation.New("custom domain, environment and WEB service names together must be at most 63 characters")
+
+	// ErrInvalidCanary is returned when a project's canary traffic share or bake time is out of range
+	ErrInvalidCanary = validation.New("canary must receive between 1 and 50 percent of traffic and bake for between 1 and 60 minutes")
+
+	// ErrInvalidDatastore is returned when a project asks for a datastore that is not supported
+	ErrInvalidDatastore = validation.New("datastores must be REDIS or MYSQL")
+
+	// ErrInvalidVolume is returned when a project's persistent volume has an unusable mount path or size
+	ErrInvalidVolume = validation.New("volume must be mounted at an absolute path outside system directories and be between 1 and 100 GB")
+
+	// ErrInvalidPort is returned when a project's container port is out of range
+	ErrInvalidPort = validation.New("port must be between 1 and 65535")
+
+	// ErrInvalidHealthCheckPath is returned when a project's health check path is not an absolute URL path
+	ErrInvalidHealthCheckPath = validation.New("health check path must start with / and be at most 255 characters without spaces")
+
+	// ErrInvalidTaskSize is returned when a project's CPU and memory are not a combination Fargate supports
+	ErrInvalidTaskSize = validation.New("cpu must be 256, 512, 1024, 2048 or 4096 units with a memory size Fargate supports for it")
+
+	// ErrInvalidBuildLimits is returned when a project's build size is not supported or its build timeout is out of range
+	ErrInvalidBuildLimits = validation.New("build_size must be SMALL, MEDIUM or LARGE and build_timeout_minutes between 5 and 480")
+
+	// ErrDatabaseNotRequired is returned for database operations on a project that doesn't require a database
+	ErrDatabaseNotRequired = errors.New("project does not require a database")
+
+	// ErrProjectDeleting is returned when an operation targets a project that is being torn down
+	ErrProjectDeleting = errors.New("project is being deleted")
+
 	// ErrEnvVarNotFound is returned when an environment variable is not found
 	ErrEnvVarNotFound = errors.New("environment variable not found")
 )
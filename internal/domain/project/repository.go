@@ -2,6 +2,7 @@ package project
 
 import (
 	"context"
+	"time"
 
 	"snapdeploy-core/internal/domain/user"
 )
@@ -14,18 +15,52 @@ type ProjectRepository interface {
 	// FindByID retrieves a project by its ID
 	FindByID(ctx context.Context, id ProjectID) (*Project, error)
 
+	// FindByIDIncludingDeleted retrieves a project by its ID, even if it was deleted
+	FindByIDIncludingDeleted(ctx context.Context, id ProjectID) (*Project, error)
+
 	// FindByUserID retrieves all projects for a user with pagination
 	FindByUserID(ctx context.Context, userID user.UserID, limit, offset int32) ([]*Project, error)
 
-	// FindByRepositoryURL retrieves a project by repository URL and user ID
+	// FindByUserIDIncludingDeleted retrieves all projects for a user with pagination, deleted ones included
+	FindByUserIDIncludingDeleted(ctx context.Context, userID user.UserID, limit, offset int32) ([]*Project, error)
+
+	// FindAll retrieves the projects of every user with pagination, oldest first
+	FindAll(ctx context.Context, limit, offset int32) ([]*Project, error)
+
+	// FindWithCronJobs retrieves the projects of every user that run a cron job, as their main service
+	// or another one, oldest first
+	FindWithCronJobs(ctx context.Context) ([]*Project, error)
+
+	// FindByRepositoryURL retrieves the oldest project of a user deploying a repository URL
 	FindByRepositoryURL(ctx context.Context, userID user.UserID, repoURL RepositoryURL) (*Project, error)
 
+	// FindAllByRepositoryURL retrieves the projects of every user deployed from a repository, whether their URL
+	// ends in .git or not, oldest first
+	FindAllByRepositoryURL(ctx context.Context, repoURL RepositoryURL) ([]*Project, error)
+
 	// CountByUserID counts total projects for a user
 	CountByUserID(ctx context.Context, userID user.UserID) (int64, error)
 
-	// Delete removes a project
+	// CountByUserIDIncludingDeleted counts total projects for a user, deleted ones included
+	CountByUserIDIncludingDeleted(ctx context.Context, userID user.UserID) (int64, error)
+
+	// Delete soft deletes a project and its deployments. Other queries no longer find them,
+	// and PurgeDeletedBefore removes them for good once the retention period is over.
 	Delete(ctx context.Context, id ProjectID) error
 
+	// ClaimStalledTeardown takes over the teardown of a project being deleted that made no progress since
+	// staleBefore, e.g. because the instance running it stopped. Its update time is bumped so no other instance
+	// takes it over meanwhile. Returns ErrProjectNotFound when no teardown stalled.
+	ClaimStalledTeardown(ctx context.Context, staleBefore time.Time) (*Project, error)
+
+	// PurgeDeletedBefore permanently removes up to limit projects deleted before the cutoff,
+	// together with everything that belongs to them
+	PurgeDeletedBefore(ctx context.Context, cutoff time.Time, limit int32) (int64, error)
+
 	// ExistsByRepositoryURL checks if a project with the given repository URL exists for a user
 	ExistsByRepositoryURL(ctx context.Context, userID user.UserID, repoURL RepositoryURL) (bool, error)
+
+	// FindByCustomDomains retrieves the projects of every user, deleted ones excepted, whose custom domain is one
+	// of the given domains
+	FindByCustomDomains(ctx context.Context, domains []string) ([]*Project, error)
 }
@@ -0,0 +1,133 @@
+package project
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"snapdeploy-core/internal/domain/validation"
+)
+
+// Limits of the services a project runs besides its main one
+const (
+	MaxServices        = 5
+	MaxServiceReplicas = 10
+	// DefaultServicePort is the port WEB services listen on when they don't choose one
+	DefaultServicePort = 8080
+)
+
+// serviceNamePattern matches service names: lowercase letters, numbers and hyphens, starting with a letter.
+// Names are kept short since they are appended to the names of load balancer target groups.
+var serviceNamePattern = regexp.MustCompile(`^[a-z]([a-z0-9-]{0,10}[a-z0-9])?$`)
+
+// reservedServiceNames would collide with the resources named after a project's main service
+var reservedServiceNames = map[string]bool{"canary": true, "green": true, "cron": true, "migration": true}
+
+// Service is a value object representing a process a project runs besides its main one. Services are
+// built from the same repository and share the project's environment variables, but each runs its own
+// command in its own ECS service.
+type Service struct {
+	name        string
+	serviceType ProjectType
+	command     Command
+	port        int      // Port WEB services listen on, 0 for others
+	replicas    int      // Tasks kept running, 1 for CRON services which start one task per run
+	schedule    Schedule // When a CRON service runs, empty for others
+}
+
+// NewService creates a new Service with validation. A zero port or replica count selects the default.
+func NewService(name, serviceType, command string, port, replicas int, schedule string) (Service, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !serviceNamePattern.MatchString(name) || reservedServiceNames[name] {
+		return Service{}, validation.Errorf("invalid service name: %q (must be 1-12 lowercase letters, numbers or hyphens, starting with a letter)", name)
+	}
+
+	sType, err := NewProjectType(serviceType)
+	if err != nil {
+		return Service{}, err
+	}
+	if sType == TypeStatic {
+		return Service{}, validation.Errorf("service %s runs a command, it can't be STATIC", name)
+	}
+
+	cmd, err := NewCommand(command)
+	if err != nil {
+		return Service{}, fmt.Errorf("invalid command of service %s: %w", name, err)
+	}
+
+	if sType.ServesTraffic() {
+		if port == 0 {
+			port = DefaultServicePort
+		}
+		if port < 1 || port > 65535 {
+			return Service{}, validation.Errorf("invalid port of service %s: %d", name, port)
+		}
+	} else if port != 0 {
+		return Service{}, validation.Errorf("service %s receives no traffic, it can't have a port", name)
+	}
+
+	if replicas == 0 {
+		replicas = 1
+	}
+	maxReplicas := MaxServiceReplicas
+	if sType == TypeCron {
+		maxReplicas = 1
+	}
+	if replicas < 1 || replicas > maxReplicas {
+		return Service{}, validation.Errorf("invalid replicas of service %s: %d (must be between 1 and %d)", name, replicas, maxReplicas)
+	}
+
+	var cronSchedule Schedule
+	if sType == TypeCron {
+		cronSchedule, err = NewSchedule(schedule)
+		if err != nil {
+			return Service{}, fmt.Errorf("invalid schedule of service %s: %w", name, err)
+		}
+	} else if strings.TrimSpace(schedule) != "" {
+		return Service{}, validation.Errorf("service %s runs continuously, it can't have a schedule", name)
+	}
+
+	return Service{
+		name:        name,
+		serviceType: sType,
+		command:     cmd,
+		port:        port,
+		replicas:    replicas,
+		schedule:    cronSchedule,
+	}, nil
+}
+
+func (s Service) Name() string {
+	return s.name
+}
+
+func (s Service) Type() ProjectType {
+	return s.serviceType
+}
+
+func (s Service) Command() Command {
+	return s.command
+}
+
+func (s Service) Port() int {
+	return s.port
+}
+
+func (s Service) Replicas() int {
+	return s.replicas
+}
+
+func (s Service) Schedule() Schedule {
+	return s.schedule
+}
+
+// Subdomain returns the subdomain a WEB service of a project with the given custom domain is reachable at
+func (s Service) Subdomain(customDomain CustomDomain) string {
+	return ServiceSubdomain(customDomain, s.name)
+}
+
+// ServiceSubdomain returns the subdomain a project's WEB service with the given name is reachable at,
+// e.g. my-app-api for the api service of my-app
+func ServiceSubdomain(customDomain CustomDomain, serviceName string) string {
+	return customDomain.String() + "-" + serviceName
+}
@@ -2,9 +2,13 @@ package project
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/google/uuid"
+
+	"snapdeploy-core/internal/domain/validation"
 )
 
 // ProjectID is a value object representing a project's unique identifier
@@ -21,7 +25,7 @@ func NewProjectID() ProjectID {
 func ParseProjectID(id string) (ProjectID, error) {
 	uid, err := uuid.Parse(id)
 	if err != nil {
-		return ProjectID{}, fmt.Errorf("invalid project ID format: %w", err)
+		return ProjectID{}, validation.Errorf("invalid project ID format: %w", err)
 	}
 	return ProjectID{value: uid}, nil
 }
@@ -48,11 +52,11 @@ func NewRepositoryURL(url string) (RepositoryURL, error) {
 	url = strings.TrimSpace(url)
 
 	if url == "" {
-		return RepositoryURL{}, fmt.Errorf("repository URL cannot be empty")
+		return RepositoryURL{}, validation.Errorf("repository URL cannot be empty")
 	}
 
 	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
-		return RepositoryURL{}, fmt.Errorf("repository URL must be a valid HTTP(S) URL")
+		return RepositoryURL{}, validation.Errorf("repository URL must be a valid HTTP(S) URL")
 	}
 
 	return RepositoryURL{value: url}, nil
@@ -85,7 +89,7 @@ func NewLanguage(lang string) (Language, error) {
 	case LanguageNode, LanguageNodeTS, LanguageNextJS, LanguageGo, LanguagePython:
 		return Language(lang), nil
 	default:
-		return "", fmt.Errorf("invalid language: %s (must be one of: NODE, NODE_TS, NEXTJS, GO, PYTHON)", lang)
+		return "", validation.Errorf("invalid language: %s (must be one of: NODE, NODE_TS, NEXTJS, GO, PYTHON)", lang)
 	}
 }
 
@@ -102,6 +106,54 @@ func (l Language) IsValid() bool {
 	}
 }
 
+// nodeLanguages are the languages built in a Node.js image
+var nodeLanguages = []Language{LanguageNode, LanguageNodeTS, LanguageNextJS}
+
+// languageTools maps the tools commands start with to the languages whose build image provides them
+var languageTools = map[string][]Language{
+	"npm":          nodeLanguages,
+	"npx":          nodeLanguages,
+	"yarn":         nodeLanguages,
+	"pnpm":         nodeLanguages,
+	"node":         nodeLanguages,
+	"next":         nodeLanguages,
+	"tsc":          nodeLanguages,
+	"ts-node":      nodeLanguages,
+	"go":           {LanguageGo},
+	"python":       {LanguagePython},
+	"python3":      {LanguagePython},
+	"pip":          {LanguagePython},
+	"pip3":         {LanguagePython},
+	"pipenv":       {LanguagePython},
+	"poetry":       {LanguagePython},
+	"gunicorn":     {LanguagePython},
+	"uvicorn":      {LanguagePython},
+	"flask":        {LanguagePython},
+	"django-admin": {LanguagePython},
+}
+
+// ForeignTool returns the first tool a command runs that only other languages' build images provide, such as
+// pip in a NODE project, or an empty string if there is none. Tools no language is known for, such as make or
+// the repository's own scripts, are assumed to be available.
+func (l Language) ForeignTool(command string) string {
+	for _, step := range strings.FieldsFunc(command, func(r rune) bool { return r == '&' || r == ';' || r == '|' }) {
+		fields := strings.Fields(step)
+		// Skip environment variable assignments such as NODE_ENV=production
+		for len(fields) > 0 && strings.Contains(fields[0], "=") {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		languages, known := languageTools[fields[0]]
+		if known && !slices.Contains(languages, l) {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
 // Command is a value object representing a build or run command
 type Command struct {
 	value string
@@ -112,11 +164,11 @@ func NewCommand(cmd string) (Command, error) {
 	cmd = strings.TrimSpace(cmd)
 
 	if cmd == "" {
-		return Command{}, fmt.Errorf("command cannot be empty")
+		return Command{}, validation.Errorf("command cannot be empty")
 	}
 
 	if len(cmd) > 500 {
-		return Command{}, fmt.Errorf("command too long (max 500 characters)")
+		return Command{}, validation.Errorf("command too long (max 500 characters)")
 	}
 
 	return Command{value: cmd}, nil
@@ -167,18 +219,18 @@ func NewCustomDomain(domain string) (CustomDomain, error) {
 	// Validate subdomain format (RFC 1123)
 	// Must be lowercase alphanumeric with hyphens, start/end with alphanumeric
 	if len(domain) < 1 || len(domain) > 63 {
-		return CustomDomain{}, fmt.Errorf("custom domain must be between 1 and 63 characters")
+		return CustomDomain{}, validation.Errorf("custom domain must be between 1 and 63 characters")
 	}
 
 	// Check first and last character
 	if !isAlphanumeric(rune(domain[0])) || !isAlphanumeric(rune(domain[len(domain)-1])) {
-		return CustomDomain{}, fmt.Errorf("custom domain must start and end with alphanumeric characters")
+		return CustomDomain{}, validation.Errorf("custom domain must start and end with alphanumeric characters")
 	}
 
 	// Check all characters are valid
 	for _, c := range domain {
 		if !isAlphanumeric(c) && c != '-' {
-			return CustomDomain{}, fmt.Errorf("custom domain can only contain lowercase letters, numbers, and hyphens")
+			return CustomDomain{}, validation.Errorf("custom domain can only contain lowercase letters, numbers, and hyphens")
 		}
 	}
 
@@ -186,7 +238,7 @@ func NewCustomDomain(domain string) (CustomDomain, error) {
 	reserved := []string{"www", "api", "admin", "app", "dashboard", "console", "staging", "prod", "production", "dev", "development", "test", "testing"}
 	for _, r := range reserved {
 		if domain == r {
-			return CustomDomain{}, fmt.Errorf("subdomain '%s' is reserved", domain)
+			return CustomDomain{}, validation.Errorf("subdomain '%s' is reserved", domain)
 		}
 	}
 
@@ -226,7 +278,352 @@ func (d CustomDomain) Equals(other CustomDomain) bool {
 	return d.value == other.value
 }
 
+// ForEnvironment returns the subdomain the project is reachable at in an environment,
+// e.g. my-app-staging for the staging environment of my-app. Production uses the domain itself.
+func (d CustomDomain) ForEnvironment(env Environment) CustomDomain {
+	if env.IsProduction() {
+		return d
+	}
+	return CustomDomain{value: d.value + "-" + env.String()}
+}
+
 // IsEmpty checks if the custom domain is empty
 func (d CustomDomain) IsEmpty() bool {
 	return d.value == ""
 }
+
+// ClaimantDomains returns the custom domains of the projects that could claim any of the subdomains: each
+// subdomain itself and its prefixes ending before a hyphen, since environment and service subdomains extend
+// their project's custom domain with a hyphen and their name
+func ClaimantDomains(subdomains []string) []string {
+	var domains []string
+	for _, subdomain := range subdomains {
+		for i, c := range subdomain {
+			if c == '-' && i > 0 && !slices.Contains(domains, subdomain[:i]) {
+				domains = append(domains, subdomain[:i])
+			}
+		}
+		if !slices.Contains(domains, subdomain) {
+			domains = append(domains, subdomain)
+		}
+	}
+	return domains
+}
+
+// ProjectStatus represents the lifecycle status of a project
+type ProjectStatus string
+
+const (
+	StatusActive       ProjectStatus = "ACTIVE"
+	StatusDeleting     ProjectStatus = "DELETING"
+	StatusDeleteFailed ProjectStatus = "DELETE_FAILED"
+)
+
+// NewProjectStatus creates a new ProjectStatus with validation
+func NewProjectStatus(status string) (ProjectStatus, error) {
+	status = strings.ToUpper(strings.TrimSpace(status))
+
+	// Projects persisted before statuses existed are active
+	if status == "" {
+		return StatusActive, nil
+	}
+
+	switch ProjectStatus(status) {
+	case StatusActive, StatusDeleting, StatusDeleteFailed:
+		return ProjectStatus(status), nil
+	default:
+		return "", fmt.Errorf("invalid project status: %s (must be one of: ACTIVE, DELETING, DELETE_FAILED)", status)
+	}
+}
+
+func (s ProjectStatus) String() string {
+	return string(s)
+}
+
+// DeploymentStrategy is how a new version of a project replaces the running one
+type DeploymentStrategy string
+
+const (
+	// StrategyRolling replaces tasks a few at a time, rolling back if the new ones never become healthy
+	StrategyRolling DeploymentStrategy = "ROLLING"
+	// StrategyBlueGreen starts the new version alongside the old one and shifts traffic once it passes health checks
+	StrategyBlueGreen DeploymentStrategy = "BLUE_GREEN"
+	// StrategyRecreate stops the running tasks before starting the new ones
+	StrategyRecreate DeploymentStrategy = "RECREATE"
+	// StrategyCanary sends a share of the traffic to the new version and promotes it if it stays healthy
+	StrategyCanary DeploymentStrategy = "CANARY"
+)
+
+// NewDeploymentStrategy creates a new DeploymentStrategy with validation
+func NewDeploymentStrategy(strategy string) (DeploymentStrategy, error) {
+	strategy = strings.ToUpper(strings.TrimSpace(strategy))
+
+	// Projects that never chose a strategy roll
+	if strategy == "" {
+		return StrategyRolling, nil
+	}
+
+	switch DeploymentStrategy(strategy) {
+	case StrategyRolling, StrategyBlueGreen, StrategyRecreate, StrategyCanary:
+		return DeploymentStrategy(strategy), nil
+	default:
+		return "", validation.Errorf("invalid deployment strategy: %s (must be one of: ROLLING, BLUE_GREEN, RECREATE, CANARY)", strategy)
+	}
+}
+
+func (s DeploymentStrategy) String() string {
+	return string(s)
+}
+
+// ProjectType is how a project's containers are run
+type ProjectType string
+
+const (
+	// TypeWeb serves HTTP traffic on its own subdomain through the load balancer
+	TypeWeb ProjectType = "WEB"
+	// TypeWorker runs in the background without receiving traffic
+	TypeWorker ProjectType = "WORKER"
+	// TypeCron runs to completion on a schedule without receiving traffic
+	TypeCron ProjectType = "CRON"
+	// TypeStatic builds to files served from S3 through CloudFront, without running a container
+	TypeStatic ProjectType = "STATIC"
+)
+
+// NewProjectType creates a new ProjectType with validation
+func NewProjectType(projectType string) (ProjectType, error) {
+	projectType = strings.ToUpper(strings.TrimSpace(projectType))
+
+	// Projects that never chose a type serve traffic
+	if projectType == "" {
+		return TypeWeb, nil
+	}
+
+	switch ProjectType(projectType) {
+	case TypeWeb, TypeWorker, TypeCron, TypeStatic:
+		return ProjectType(projectType), nil
+	default:
+		return "", validation.Errorf("invalid project type: %s (must be one of: WEB, WORKER, CRON, STATIC)", projectType)
+	}
+}
+
+func (t ProjectType) String() string {
+	return string(t)
+}
+
+// ServesTraffic checks if projects of the type are routed traffic through the load balancer
+func (t ProjectType) ServesTraffic() bool {
+	return t == TypeWeb
+}
+
+// HasURL checks if projects of the type are reachable on their own subdomain, through the load balancer
+// or, for STATIC projects, through CloudFront
+func (t ProjectType) HasURL() bool {
+	return t == TypeWeb || t == TypeStatic
+}
+
+// DeploymentTarget is the compute a project's image runs on
+type DeploymentTarget string
+
+const (
+	// TargetECS runs the project as Fargate services behind the load balancer
+	TargetECS DeploymentTarget = "ECS"
+	// TargetLambda runs the project as a container image Lambda function, started per request
+	TargetLambda DeploymentTarget = "LAMBDA"
+)
+
+// NewDeploymentTarget creates a new DeploymentTarget with validation
+func NewDeploymentTarget(target string) (DeploymentTarget, error) {
+	target = strings.ToUpper(strings.TrimSpace(target))
+
+	// Projects that never chose a target run on ECS
+	if target == "" {
+		return TargetECS, nil
+	}
+
+	switch DeploymentTarget(target) {
+	case TargetECS, TargetLambda:
+		return DeploymentTarget(target), nil
+	default:
+		return "", validation.Errorf("invalid deployment target: %s (must be one of: ECS, LAMBDA)", target)
+	}
+}
+
+func (t DeploymentTarget) String() string {
+	return string(t)
+}
+
+// BuildSize is the CPU and memory a project's builds get, matching CodeBuild's general purpose compute types
+type BuildSize string
+
+const (
+	// BuildSmall builds with 2 vCPUs and 3 GiB of memory
+	BuildSmall BuildSize = "SMALL"
+	// BuildMedium builds with 4 vCPUs and 7 GiB of memory
+	BuildMedium BuildSize = "MEDIUM"
+	// BuildLarge builds with 8 vCPUs and 15 GiB of memory
+	BuildLarge BuildSize = "LARGE"
+)
+
+// NewBuildSize creates a new BuildSize with validation
+func NewBuildSize(size string) (BuildSize, error) {
+	size = strings.ToUpper(strings.TrimSpace(size))
+
+	// Projects that never chose a size build small
+	if size == "" {
+		return BuildSmall, nil
+	}
+
+	switch BuildSize(size) {
+	case BuildSmall, BuildMedium, BuildLarge:
+		return BuildSize(size), nil
+	default:
+		return "", validation.Errorf("invalid build size: %s (must be one of: SMALL, MEDIUM, LARGE)", size)
+	}
+}
+
+func (s BuildSize) String() string {
+	return string(s)
+}
+
+// VCPUs returns the vCPUs a build of this size gets
+func (s BuildSize) VCPUs() int {
+	switch s {
+	case BuildMedium:
+		return 4
+	case BuildLarge:
+		return 8
+	default:
+		return 2
+	}
+}
+
+// MemoryMiB returns the memory in MiB a build of this size gets
+func (s BuildSize) MemoryMiB() int {
+	switch s {
+	case BuildMedium:
+		return 7168
+	case BuildLarge:
+		return 15360
+	default:
+		return 3072
+	}
+}
+
+// LambdaEndpoint is how requests reach a project deployed to Lambda
+type LambdaEndpoint string
+
+const (
+	// EndpointAPIGateway serves the function through an HTTP API on the project's subdomain
+	EndpointAPIGateway LambdaEndpoint = "API_GATEWAY"
+	// EndpointFunctionURL serves the function on its own lambda-url.on.aws URL, without a custom domain
+	EndpointFunctionURL LambdaEndpoint = "FUNCTION_URL"
+)
+
+// NewLambdaEndpoint creates a new LambdaEndpoint with validation
+func NewLambdaEndpoint(endpoint string) (LambdaEndpoint, error) {
+	endpoint = strings.ToUpper(strings.TrimSpace(endpoint))
+
+	if endpoint == "" {
+		return EndpointAPIGateway, nil
+	}
+
+	switch LambdaEndpoint(endpoint) {
+	case EndpointAPIGateway, EndpointFunctionURL:
+		return LambdaEndpoint(endpoint), nil
+	default:
+		return "", validation.Errorf("invalid Lambda endpoint: %s (must be one of: API_GATEWAY, FUNCTION_URL)", endpoint)
+	}
+}
+
+func (e LambdaEndpoint) String() string {
+	return string(e)
+}
+
+// schedulePattern matches the EventBridge schedule expressions CRON projects run on:
+// cron() with six fields, or rate() with a singular unit for a value of 1 and a plural one otherwise
+var schedulePattern = regexp.MustCompile(`^(cron\((\S+ ){5}\S+\)|rate\((1 (minute|hour|day)|([2-9]|[1-9][0-9]+) (minutes|hours|days))\))$`)
+
+// Schedule is a value object representing the EventBridge schedule expression a CRON project runs on,
+// such as cron(0 3 * * ? *) or rate(15 minutes)
+type Schedule struct {
+	value string
+}
+
+// NewSchedule creates a new Schedule with validation
+func NewSchedule(expression string) (Schedule, error) {
+	expression = strings.TrimSpace(expression)
+	if len(expression) > 256 || !schedulePattern.MatchString(expression) {
+		return Schedule{}, validation.Errorf("invalid schedule: %q (must be cron(...) with six fields or rate(...))", expression)
+	}
+	return Schedule{value: expression}, nil
+}
+
+func (s Schedule) String() string {
+	return s.value
+}
+
+// IsEmpty checks if no schedule is set
+func (s Schedule) IsEmpty() bool {
+	return s.value == ""
+}
+
+// Datastore is a kind of managed datastore a project uses besides its Postgres database
+type Datastore string
+
+const (
+	// DatastoreRedis runs Redis next to the project in each of its tasks
+	DatastoreRedis Datastore = "REDIS"
+	// DatastoreMySQL creates a MySQL database for the project on the platform's MySQL server
+	DatastoreMySQL Datastore = "MYSQL"
+)
+
+// NewDatastore creates a new Datastore with validation
+func NewDatastore(datastore string) (Datastore, error) {
+	datastore = strings.ToUpper(strings.TrimSpace(datastore))
+
+	switch Datastore(datastore) {
+	case DatastoreRedis, DatastoreMySQL:
+		return Datastore(datastore), nil
+	default:
+		return "", validation.Errorf("invalid datastore: %s (must be one of: REDIS, MYSQL)", datastore)
+	}
+}
+
+func (d Datastore) String() string {
+	return string(d)
+}
+
+// environmentPattern matches environment names: lowercase letters, numbers and hyphens, starting with a letter.
+// Names are kept short since they are appended to the project's subdomain.
+var environmentPattern = regexp.MustCompile(`^[a-z]([a-z0-9-]{0,18}[a-z0-9])?$`)
+
+// Environment is a value object naming a set of deployments of a project, such as production or staging.
+// Each environment has its own environment variables, subdomain and ECS services.
+type Environment string
+
+// EnvironmentProduction is the environment every project has, deployed to when none is chosen
+const EnvironmentProduction Environment = "production"
+
+// MaxEnvironments is the most environments a project can have besides production
+const MaxEnvironments = 5
+
+// NewEnvironment creates a new Environment with validation. An empty name selects production.
+func NewEnvironment(name string) (Environment, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return EnvironmentProduction, nil
+	}
+	if !environmentPattern.MatchString(name) {
+		return "", validation.Errorf("invalid environment: %q (must be 1-20 lowercase letters, numbers or hyphens, starting with a letter)", name)
+	}
+	return Environment(name), nil
+}
+
+func (e Environment) String() string {
+	return string(e)
+}
+
+// IsProduction checks if the environment is the project's production environment
+func (e Environment) IsProduction() bool {
+	return e == EnvironmentProduction
+}
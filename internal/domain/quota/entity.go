@@ -0,0 +1,88 @@
+package quota
+
+import (
+	"time"
+
+	"snapdeploy-core/internal/domain/user"
+)
+
+// Quota is the plan a user is on and the limits overridden for them
+type Quota struct {
+	userID    user.UserID
+	plan      string // Empty for the default plan
+	overrides Overrides
+	updatedAt time.Time
+}
+
+// NewQuota creates the quota of a user on the default plan without overrides
+func NewQuota(userID user.UserID) *Quota {
+	return &Quota{
+		userID:    userID,
+		updatedAt: time.Now(),
+	}
+}
+
+// Reconstruct recreates a quota from persistence
+func Reconstruct(userID user.UserID, plan string, overrides Overrides, updatedAt time.Time) *Quota {
+	return &Quota{
+		userID:    userID,
+		plan:      plan,
+		overrides: overrides,
+		updatedAt: updatedAt,
+	}
+}
+
+// Assign puts the user on a plan, the default plan for an empty name
+func (q *Quota) Assign(plans Plans, plan string) error {
+	if _, err := plans.Find(plan); err != nil {
+		return err
+	}
+	q.plan = plan
+	q.updatedAt = time.Now()
+	return nil
+}
+
+// Override replaces the limits of the user's plan with those set in overrides, keeping the others
+func (q *Quota) Override(overrides Overrides) error {
+	if err := overrides.Apply(Limits{}).Validate(); err != nil {
+		return err
+	}
+	q.overrides = overrides
+	q.updatedAt = time.Now()
+	return nil
+}
+
+// Limits returns the limits of the user: those of their plan with the overrides applied
+func (q *Quota) Limits(plans Plans) (Limits, error) {
+	limits, err := plans.Find(q.plan)
+	if err != nil {
+		return Limits{}, err
+	}
+	return q.overrides.Apply(limits), nil
+}
+
+// PlanName returns the name of the user's plan, resolving the default plan
+func (q *Quota) PlanName(plans Plans) string {
+	if q.plan == "" {
+		return plans.Default
+	}
+	return q.plan
+}
+
+// Getters
+
+func (q *Quota) UserID() user.UserID {
+	return q.userID
+}
+
+func (q *Quota) Plan() string {
+	return q.plan
+}
+
+func (q *Quota) Overrides() Overrides {
+	return q.overrides
+}
+
+func (q *Quota) UpdatedAt() time.Time {
+	return q.updatedAt
+}
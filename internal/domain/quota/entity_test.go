@@ -0,0 +1,100 @@
+package quota_test
+
+import (
+	"errors"
+	"testing"
+
+	"snapdeploy-core/internal/domain/quota"
+	"snapdeploy-core/internal/domain/user"
+)
+
+var testPlans = quota.Plans{
+	Default: "free",
+	Limits: map[string]quota.Limits{
+		"free": {Projects: 3, ConcurrentBuilds: 1, EnvVars: 50, BuildMinutesPerMonth: 300},
+		"pro":  {Projects: 20, ConcurrentBuilds: 4},
+	},
+}
+
+func TestQuota_Limits(t *testing.T) {
+	q := quota.NewQuota(user.NewUserID())
+
+	limits, err := q.Limits(testPlans)
+	if err != nil {
+		t.Fatalf("Limits() error = %v", err)
+	}
+	if limits != testPlans.Limits["free"] || q.PlanName(testPlans) != "free" {
+		t.Errorf("Limits() = %+v on %s, want the default plan's", limits, q.PlanName(testPlans))
+	}
+
+	if err := q.Assign(testPlans, "pro"); err != nil {
+		t.Fatalf("Assign() error = %v", err)
+	}
+	projects := 50
+	if err := q.Override(quota.Overrides{Projects: &projects}); err != nil {
+		t.Fatalf("Override() error = %v", err)
+	}
+
+	limits, err = q.Limits(testPlans)
+	if err != nil {
+		t.Fatalf("Limits() error = %v", err)
+	}
+	want := quota.Limits{Projects: 50, ConcurrentBuilds: 4}
+	if limits != want {
+		t.Errorf("Limits() = %+v, want %+v", limits, want)
+	}
+}
+
+func TestQuota_AssignUnknownPlan(t *testing.T) {
+	q := quota.NewQuota(user.NewUserID())
+
+	if err := q.Assign(testPlans, "enterprise"); !errors.Is(err, quota.ErrUnknownPlan) {
+		t.Errorf("Assign() error = %v, want %v", err, quota.ErrUnknownPlan)
+	}
+}
+
+func TestQuota_OverrideNegative(t *testing.T) {
+	q := quota.NewQuota(user.NewUserID())
+	negative := -1
+
+	if err := q.Override(quota.Overrides{EnvVars: &negative}); !errors.Is(err, quota.ErrInvalidLimit) {
+		t.Errorf("Override() error = %v, want %v", err, quota.ErrInvalidLimit)
+	}
+}
+
+func TestLimits_Check(t *testing.T) {
+	limits := quota.Limits{Projects: 3, ConcurrentBuilds: 2}
+
+	tests := []struct {
+		name      string
+		resource  quota.Resource
+		used      float64
+		wantErr   bool
+		temporary bool
+	}{
+		{name: "below limit", resource: quota.ResourceProjects, used: 2},
+		{name: "at limit", resource: quota.ResourceProjects, used: 3, wantErr: true},
+		{name: "concurrent builds", resource: quota.ResourceConcurrentBuilds, used: 2, wantErr: true, temporary: true},
+		{name: "unlimited", resource: quota.ResourceEnvVars, used: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := limits.Check(tt.resource, tt.used)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+
+			var exceeded *quota.ExceededError
+			if !errors.As(err, &exceeded) || !errors.Is(err, quota.ErrQuotaExceeded) {
+				t.Fatalf("Check() error = %v, want an ExceededError", err)
+			}
+			if exceeded.Temporary() != tt.temporary {
+				t.Errorf("Temporary() = %v, want %v", exceeded.Temporary(), tt.temporary)
+			}
+		})
+	}
+}
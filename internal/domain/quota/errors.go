@@ -0,0 +1,39 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+
+	"snapdeploy-core/internal/domain/validation"
+)
+
+var (
+	// ErrQuotaExceeded is returned when an operation would take a user over one of their limits
+	ErrQuotaExceeded = errors.New("quota exceeded")
+
+	// ErrUnknownPlan is returned when a user is assigned a plan that isn't configured
+	ErrUnknownPlan = validation.New("unknown plan")
+
+	// ErrInvalidLimit is returned when a limit is negative
+	ErrInvalidLimit = validation.New("invalid limit")
+)
+
+// ExceededError reports the limit an operation would exceed. It matches ErrQuotaExceeded.
+type ExceededError struct {
+	Resource Resource
+	Limit    int
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s limit of %d reached", e.Resource.description(), e.Limit)
+}
+
+func (e *ExceededError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// Temporary reports whether the limit frees up on its own, once running work finishes, rather than
+// needing a larger plan or the next month
+func (e *ExceededError) Temporary() bool {
+	return e.Resource == ResourceConcurrentBuilds
+}
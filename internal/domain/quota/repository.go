@@ -0,0 +1,16 @@
+package quota
+
+import (
+	"context"
+
+	"snapdeploy-core/internal/domain/user"
+)
+
+// Repository defines the interface for quota persistence
+type Repository interface {
+	// FindByUserID retrieves the quota of a user, or nil if they are on the default plan without overrides
+	FindByUserID(ctx context.Context, userID user.UserID) (*Quota, error)
+
+	// Save persists a user's quota (create or update)
+	Save(ctx context.Context, quota *Quota) error
+}
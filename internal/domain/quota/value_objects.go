@@ -0,0 +1,114 @@
+package quota
+
+import "fmt"
+
+// Resource identifies what a limit bounds
+type Resource string
+
+const (
+	ResourceProjects         Resource = "projects"
+	ResourceConcurrentBuilds Resource = "concurrent_builds"
+	ResourceEnvVars          Resource = "env_vars"
+	ResourceBuildMinutes     Resource = "build_minutes"
+)
+
+func (r Resource) String() string {
+	return string(r)
+}
+
+// description names the resource in error messages
+func (r Resource) description() string {
+	switch r {
+	case ResourceProjects:
+		return "project"
+	case ResourceConcurrentBuilds:
+		return "concurrent build"
+	case ResourceEnvVars:
+		return "environment variable"
+	case ResourceBuildMinutes:
+		return "monthly build minute"
+	default:
+		return string(r)
+	}
+}
+
+// Limits bounds what a user can use. A limit of 0 is unlimited.
+type Limits struct {
+	Projects             int // Projects owned at once, deleted projects excluded
+	ConcurrentBuilds     int // Deployments in progress at once
+	EnvVars              int // Environment variables per project environment
+	BuildMinutesPerMonth int // Build minutes per calendar month (UTC)
+}
+
+// Validate checks that no limit is negative
+func (l Limits) Validate() error {
+	for resource, limit := range l.byResource() {
+		if limit < 0 {
+			return fmt.Errorf("%w: %s must not be negative", ErrInvalidLimit, resource)
+		}
+	}
+	return nil
+}
+
+// Check returns an ExceededError if using one more of a resource would exceed its limit, used being what is
+// used already
+func (l Limits) Check(resource Resource, used float64) error {
+	limit := l.byResource()[resource]
+	if limit > 0 && used >= float64(limit) {
+		return &ExceededError{Resource: resource, Limit: limit}
+	}
+	return nil
+}
+
+// byResource indexes the limits by the resource they bound
+func (l Limits) byResource() map[Resource]int {
+	return map[Resource]int{
+		ResourceProjects:         l.Projects,
+		ResourceConcurrentBuilds: l.ConcurrentBuilds,
+		ResourceEnvVars:          l.EnvVars,
+		ResourceBuildMinutes:     l.BuildMinutesPerMonth,
+	}
+}
+
+// Overrides replaces some of the limits of a user's plan; nil fields keep the plan's limit
+type Overrides struct {
+	Projects             *int
+	ConcurrentBuilds     *int
+	EnvVars              *int
+	BuildMinutesPerMonth *int
+}
+
+// Apply returns the limits with the overridden ones replaced
+func (o Overrides) Apply(limits Limits) Limits {
+	if o.Projects != nil {
+		limits.Projects = *o.Projects
+	}
+	if o.ConcurrentBuilds != nil {
+		limits.ConcurrentBuilds = *o.ConcurrentBuilds
+	}
+	if o.EnvVars != nil {
+		limits.EnvVars = *o.EnvVars
+	}
+	if o.BuildMinutesPerMonth != nil {
+		limits.BuildMinutesPerMonth = *o.BuildMinutesPerMonth
+	}
+	return limits
+}
+
+// Plans are the named sets of limits users can be on, such as billing tiers
+type Plans struct {
+	Default string            // Plan of users who aren't assigned one
+	Limits  map[string]Limits // Limits of each plan by name
+}
+
+// Find returns the limits of a plan, the default plan for an empty name
+func (p Plans) Find(name string) (Limits, error) {
+	if name == "" {
+		name = p.Default
+	}
+	limits, ok := p.Limits[name]
+	if !ok {
+		return Limits{}, fmt.Errorf("%w: %s", ErrUnknownPlan, name)
+	}
+	return limits, nil
+}
@@ -7,11 +7,12 @@ import (
 	"snapdeploy-core/internal/domain/user"
 )
 
-// Repository is a domain entity representing a GitHub repository
+// Repository is a domain entity representing a repository hosted on a Git provider
 type Repository struct {
 	id              RepositoryID
 	userID          user.UserID
-	githubID        GitHubID
+	provider        Provider
+	externalID      ExternalID
 	name            Name
 	fullName        string
 	description     *string
@@ -31,7 +32,8 @@ type Repository struct {
 // NewRepository creates a new Repository entity
 func NewRepository(
 	userID user.UserID,
-	githubID int64,
+	provider Provider,
+	externalID string,
 	name, fullName, url string,
 ) (*Repository, error) {
 	repoName, err := NewName(name)
@@ -44,21 +46,27 @@ func NewRepository(
 		return nil, fmt.Errorf("invalid repository URL: %w", err)
 	}
 
-	githubIDVO, err := NewGitHubID(githubID)
+	providerVO, err := NewProvider(provider.String())
 	if err != nil {
-		return nil, fmt.Errorf("invalid GitHub ID: %w", err)
+		return nil, fmt.Errorf("invalid provider: %w", err)
+	}
+
+	externalIDVO, err := NewExternalID(externalID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid external ID: %w", err)
 	}
 
 	now := time.Now()
 	return &Repository{
-		id:        NewRepositoryID(),
-		userID:    userID,
-		githubID:  githubIDVO,
-		name:      repoName,
-		fullName:  fullName,
-		url:       repoURL,
-		createdAt: now,
-		updatedAt: now,
+		id:         NewRepositoryID(),
+		userID:     userID,
+		provider:   providerVO,
+		externalID: externalIDVO,
+		name:       repoName,
+		fullName:   fullName,
+		url:        repoURL,
+		createdAt:  now,
+		updatedAt:  now,
 	}, nil
 }
 
@@ -66,7 +74,7 @@ func NewRepository(
 func Reconstitute(
 	id string,
 	userID user.UserID,
-	githubID int64,
+	provider, externalID string,
 	name, fullName string,
 	description *string,
 	url string,
@@ -91,15 +99,21 @@ func Reconstitute(
 		return nil, fmt.Errorf("invalid repository URL: %w", err)
 	}
 
-	githubIDVO, err := NewGitHubID(githubID)
+	providerVO, err := NewProvider(provider)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provider: %w", err)
+	}
+
+	externalIDVO, err := NewExternalID(externalID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid GitHub ID: %w", err)
+		return nil, fmt.Errorf("invalid external ID: %w", err)
 	}
 
 	return &Repository{
 		id:              repoID,
 		userID:          userID,
-		githubID:        githubIDVO,
+		provider:        providerVO,
+		externalID:      externalIDVO,
 		name:            repoName,
 		fullName:        fullName,
 		description:     description,
@@ -117,7 +131,7 @@ func Reconstitute(
 	}, nil
 }
 
-// UpdateMetadata updates repository metadata from GitHub sync
+// UpdateMetadata updates repository metadata from a provider sync
 func (r *Repository) UpdateMetadata(
 	description *string,
 	htmlURL *string,
@@ -152,8 +166,12 @@ func (r *Repository) UserID() user.UserID {
 	return r.userID
 }
 
-func (r *Repository) GitHubID() GitHubID {
-	return r.githubID
+func (r *Repository) Provider() Provider {
+	return r.provider
+}
+
+func (r *Repository) ExternalID() ExternalID {
+	return r.externalID
 }
 
 func (r *Repository) Name() Name {
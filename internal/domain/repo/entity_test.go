@@ -12,50 +12,64 @@ func TestNewRepository(t *testing.T) {
 	userID := user.NewUserID()
 
 	tests := []struct {
-		name     string
-		githubID int64
-		repoName string
-		fullName string
-		url      string
-		wantErr  bool
+		name       string
+		provider   repo.Provider
+		externalID string
+		repoName   string
+		fullName   string
+		url        string
+		wantErr    bool
 	}{
 		{
-			name:     "valid repository",
-			githubID: 12345,
-			repoName: "my-repo",
-			fullName: "user/my-repo",
-			url:      "https://github.com/user/repo",
-			wantErr:  false,
+			name:       "valid repository",
+			provider:   repo.ProviderGitHub,
+			externalID: "12345",
+			repoName:   "my-repo",
+			fullName:   "user/my-repo",
+			url:        "https://github.com/user/repo",
+			wantErr:    false,
 		},
 		{
-			name:     "invalid repository name",
-			githubID: 12345,
-			repoName: "",
-			fullName: "user/my-repo",
-			url:      "https://github.com/user/repo",
-			wantErr:  true,
+			name:       "invalid repository name",
+			provider:   repo.ProviderGitHub,
+			externalID: "12345",
+			repoName:   "",
+			fullName:   "user/my-repo",
+			url:        "https://github.com/user/repo",
+			wantErr:    true,
 		},
 		{
-			name:     "invalid GitHub ID",
-			githubID: 0,
-			repoName: "my-repo",
-			fullName: "user/my-repo",
-			url:      "https://github.com/user/repo",
-			wantErr:  true,
+			name:       "invalid external ID",
+			provider:   repo.ProviderGitHub,
+			externalID: "",
+			repoName:   "my-repo",
+			fullName:   "user/my-repo",
+			url:        "https://github.com/user/repo",
+			wantErr:    true,
 		},
 		{
-			name:     "invalid URL",
-			githubID: 12345,
-			repoName: "my-repo",
-			fullName: "user/my-repo",
-			url:      "invalid",
-			wantErr:  true,
+			name:       "invalid provider",
+			provider:   repo.Provider("GITEA"),
+			externalID: "12345",
+			repoName:   "my-repo",
+			fullName:   "user/my-repo",
+			url:        "https://gitea.com/user/repo",
+			wantErr:    true,
+		},
+		{
+			name:       "invalid URL",
+			provider:   repo.ProviderGitHub,
+			externalID: "12345",
+			repoName:   "my-repo",
+			fullName:   "user/my-repo",
+			url:        "invalid",
+			wantErr:    true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			repository, err := repo.NewRepository(userID, tt.githubID, tt.repoName, tt.fullName, tt.url)
+			repository, err := repo.NewRepository(userID, tt.provider, tt.externalID, tt.repoName, tt.fullName, tt.url)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewRepository() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -78,7 +92,7 @@ func TestNewRepository(t *testing.T) {
 
 func TestUpdateMetadata(t *testing.T) {
 	userID := user.NewUserID()
-	repository, _ := repo.NewRepository(userID, 12345, "my-repo", "user/my-repo", "https://github.com/user/repo")
+	repository, _ := repo.NewRepository(userID, repo.ProviderGitHub, "12345", "my-repo", "user/my-repo", "https://github.com/user/repo")
 
 	desc := "A test repository"
 	htmlURL := "https://github.com/user/repo"
@@ -114,7 +128,7 @@ func TestBelongsToUser(t *testing.T) {
 	userID1 := user.NewUserID()
 	userID2 := user.NewUserID()
 
-	repository, _ := repo.NewRepository(userID1, 12345, "my-repo", "user/my-repo", "https://github.com/user/repo")
+	repository, _ := repo.NewRepository(userID1, repo.ProviderGitHub, "12345", "my-repo", "user/my-repo", "https://github.com/user/repo")
 
 	if !repository.BelongsToUser(userID1) {
 		t.Error("Repository should belong to userID1")
@@ -135,7 +149,7 @@ func TestReconstitute(t *testing.T) {
 	updatedAt := time.Now()
 
 	repository, err := repo.Reconstitute(
-		id, userID, 12345, name, fullName, nil, url, nil,
+		id, userID, "GITHUB", "12345", name, fullName, nil, url, nil,
 		false, false, 0, 0, 0, nil, nil, createdAt, updatedAt,
 	)
 
@@ -152,4 +166,7 @@ func TestReconstitute(t *testing.T) {
 	if repository.FullName() != fullName {
 		t.Errorf("FullName = %v, want %v", repository.FullName(), fullName)
 	}
+	if repository.Provider() != repo.ProviderGitHub {
+		t.Errorf("Provider = %v, want %v", repository.Provider(), repo.ProviderGitHub)
+	}
 }
@@ -1,6 +1,9 @@
 package repo
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // Domain errors
 
@@ -44,3 +47,39 @@ func ErrUnauthorizedAccess(userID, repoID string) *DomainError {
 		Message: fmt.Sprintf("user %s not authorized to access repository %s", userID, repoID),
 	}
 }
+
+func ErrProviderNotSupported(provider Provider) *DomainError {
+	return &DomainError{
+		Code:    "PROVIDER_NOT_SUPPORTED",
+		Message: fmt.Sprintf("repository provider %s is not supported", provider),
+	}
+}
+
+func ErrInstallationNotFound(id int64) *DomainError {
+	return &DomainError{
+		Code:    "INSTALLATION_NOT_FOUND",
+		Message: fmt.Sprintf("GitHub App installation %d not found", id),
+	}
+}
+
+func ErrInstallationClaimed(id int64) *DomainError {
+	return &DomainError{
+		Code:    "INSTALLATION_CLAIMED",
+		Message: fmt.Sprintf("GitHub App installation %d is linked to another user", id),
+	}
+}
+
+func ErrInstallationNotClaimable(id int64) *DomainError {
+	return &DomainError{
+		Code:    "INSTALLATION_NOT_CLAIMABLE",
+		Message: fmt.Sprintf("GitHub App installation %d was not installed by this user's GitHub account", id),
+	}
+}
+
+func ErrProviderRateLimited(provider Provider, retryAt time.Time, err error) *DomainError {
+	return &DomainError{
+		Code:    "PROVIDER_RATE_LIMITED",
+		Message: fmt.Sprintf("%s rate limit exhausted, retry after %s", provider, retryAt.UTC().Format(time.RFC3339)),
+		Err:     err,
+	}
+}
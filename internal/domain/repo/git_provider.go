@@ -0,0 +1,94 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrFileNotFound is returned when a file doesn't exist in a repository at the requested ref
+var ErrFileNotFound = errors.New("file not found in repository")
+
+// RemoteRepository represents a repository fetched from a Git provider's API
+type RemoteRepository struct {
+	ID              string // ID at the provider, numeric for GitHub and GitLab and a UUID for Bitbucket
+	Name            string
+	FullName        string
+	Description     *string
+	URL             string
+	HTMLURL         string
+	Private         bool
+	Fork            bool
+	StargazersCount int32
+	WatchersCount   int32
+	ForksCount      int32
+	DefaultBranch   string
+	Language        *string
+}
+
+// Commit represents a commit fetched from a Git provider's API
+type Commit struct {
+	SHA         string
+	Message     string
+	AuthorName  string
+	CommittedAt time.Time
+	HTMLURL     string
+}
+
+// CommitComparison is the range of commits between two commits of a repository
+type CommitComparison struct {
+	Commits   []*Commit // Commits reachable from head but not from base, newest first
+	Truncated bool      // Whether the provider returned only part of a long range
+	HTMLURL   string    // Page comparing the two commits at the provider, empty if it has none
+}
+
+// CloneCredentials are the credentials used to clone a private repository over HTTPS.
+// The token is kept out of the URL so it never shows up in remotes, process lists or logs.
+type CloneCredentials struct {
+	URL      string // HTTPS clone URL without credentials
+	Username string
+	Token    string
+}
+
+// RepositoryChanges are the repositories that changed at a Git provider since a previous sync
+type RepositoryChanges struct {
+	Repositories []*RemoteRepository // Repositories updated since the previous sync, or all of them for a full sync
+	ETag         string              // Validator of the provider's list of repositories, passed to the next sync
+	NotModified  bool                // Whether nothing changed since the previous sync
+}
+
+// GitProvider is a domain service interface for interacting with a Git hosting service
+// Implementations will be in infrastructure layer
+type GitProvider interface {
+	// Provider returns the hosting service this implementation talks to
+	Provider() Provider
+
+	// FetchUserRepositories fetches all repositories a user can access
+	FetchUserRepositories(ctx context.Context, accessToken string) ([]*RemoteRepository, error)
+
+	// FetchBranches fetches the branch names of a repository, identified by its full name (e.g. owner/name)
+	FetchBranches(ctx context.Context, accessToken, fullName string) ([]string, error)
+
+	// FetchCommits fetches the most recent commits on a branch, newest first. An empty branch means the default branch.
+	FetchCommits(ctx context.Context, accessToken, fullName, branch string, limit int) ([]*Commit, error)
+
+	// FetchFile fetches the contents of a file of a repository at a ref (a branch, tag or commit SHA, empty for the
+	// default branch). An empty token reads public repositories. Returns ErrFileNotFound if the file doesn't exist.
+	FetchFile(ctx context.Context, accessToken, repositoryURL, ref, path string) ([]byte, error)
+
+	// CompareCommits fetches the commits of a repository reachable from head but not from base (commit SHAs, branches
+	// or tags). An empty token reads public repositories.
+	CompareCommits(ctx context.Context, accessToken, repositoryURL, base, head string) (*CommitComparison, error)
+
+	// CloneCredentials returns the credentials for cloning a repository over HTTPS with the given token
+	CloneCredentials(repositoryURL, accessToken string) (*CloneCredentials, error)
+}
+
+// IncrementalRepositoryFetcher is implemented by Git providers that can fetch only the repositories that changed since
+// a previous sync, so syncing accounts with hundreds of repositories doesn't list all of them every time
+type IncrementalRepositoryFetcher interface {
+	// FetchUserRepositoriesSince fetches the repositories a user can access that were updated after since, or all of
+	// them if since is zero. etag is the validator returned by the previous fetch, if any, letting the provider answer
+	// that nothing changed without listing anything.
+	FetchUserRepositoriesSince(ctx context.Context, accessToken string, since time.Time, etag string) (*RepositoryChanges, error)
+}
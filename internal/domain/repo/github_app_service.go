@@ -0,0 +1,24 @@
+package repo
+
+import (
+	"context"
+)
+
+// GitHubAppService is a domain service interface for acting as the SnapDeploy GitHub App
+// Implementation will be in infrastructure layer
+type GitHubAppService interface {
+	// InstallURL returns the page where users install the app
+	InstallURL() string
+
+	// GetInstallation fetches an installation from GitHub as a new, unclaimed Installation
+	GetInstallation(ctx context.Context, installationID int64) (*Installation, error)
+
+	// FetchInstallationRepositories fetches the repositories an installation has access to
+	FetchInstallationRepositories(ctx context.Context, installationID int64) ([]*RemoteRepository, error)
+
+	// FindRepositoryInstallation returns the ID of the installation with access to a repository, or 0 if there is none
+	FindRepositoryInstallation(ctx context.Context, owner, name string) (int64, error)
+
+	// CreateCloneToken creates a short-lived token that can only read the contents of one repository
+	CreateCloneToken(ctx context.Context, installationID int64, repositoryName string) (string, error)
+}
@@ -0,0 +1,155 @@
+package repo
+
+import (
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/domain/user"
+)
+
+// Installation account types
+const (
+	AccountTypeUser         = "User"
+	AccountTypeOrganization = "Organization"
+)
+
+// Installation is the SnapDeploy GitHub App installed on a GitHub user or organization.
+// Its repositories can be synced and cloned once a SnapDeploy user has claimed it.
+type Installation struct {
+	id            int64
+	accountID     int64
+	accountLogin  string
+	accountType   string
+	installedByID int64 // GitHub user who installed the app, 0 if unknown
+	userID        *user.UserID
+	suspended     bool
+	createdAt     time.Time
+	updatedAt     time.Time
+}
+
+// NewInstallation creates a new unclaimed Installation
+func NewInstallation(id, accountID int64, accountLogin, accountType string, installedByID int64) (*Installation, error) {
+	if id <= 0 {
+		return nil, ErrInvalidRepositoryData("installation ID", fmt.Errorf("must be positive"))
+	}
+	if accountLogin == "" {
+		return nil, ErrInvalidRepositoryData("installation account", fmt.Errorf("login cannot be empty"))
+	}
+
+	now := time.Now()
+	return &Installation{
+		id:            id,
+		accountID:     accountID,
+		accountLogin:  accountLogin,
+		accountType:   accountType,
+		installedByID: installedByID,
+		createdAt:     now,
+		updatedAt:     now,
+	}, nil
+}
+
+// ReconstituteInstallation recreates an Installation from persistence
+func ReconstituteInstallation(
+	id, accountID int64,
+	accountLogin, accountType string,
+	installedByID int64,
+	userID *user.UserID,
+	suspended bool,
+	createdAt, updatedAt time.Time,
+) *Installation {
+	return &Installation{
+		id:            id,
+		accountID:     accountID,
+		accountLogin:  accountLogin,
+		accountType:   accountType,
+		installedByID: installedByID,
+		userID:        userID,
+		suspended:     suspended,
+		createdAt:     createdAt,
+		updatedAt:     updatedAt,
+	}
+}
+
+// Getters
+
+func (i *Installation) ID() int64 {
+	return i.id
+}
+
+func (i *Installation) AccountID() int64 {
+	return i.accountID
+}
+
+func (i *Installation) AccountLogin() string {
+	return i.accountLogin
+}
+
+func (i *Installation) AccountType() string {
+	return i.accountType
+}
+
+func (i *Installation) InstalledByID() int64 {
+	return i.installedByID
+}
+
+func (i *Installation) UserID() *user.UserID {
+	return i.userID
+}
+
+func (i *Installation) IsSuspended() bool {
+	return i.suspended
+}
+
+func (i *Installation) CreatedAt() time.Time {
+	return i.createdAt
+}
+
+func (i *Installation) UpdatedAt() time.Time {
+	return i.updatedAt
+}
+
+// Business methods
+
+// Claim links the installation to the SnapDeploy user whose GitHub account installed it.
+// Personal accounts can also be claimed by their owner before the installation webhook arrives.
+func (i *Installation) Claim(userID user.UserID, githubUserID int64) error {
+	if i.userID != nil {
+		if i.userID.Equals(userID) {
+			return nil
+		}
+		return ErrInstallationClaimed(i.id)
+	}
+
+	installedBy := i.installedByID != 0 && githubUserID == i.installedByID
+	ownsAccount := i.accountType == AccountTypeUser && githubUserID == i.accountID
+	if !installedBy && !ownsAccount {
+		return ErrInstallationNotClaimable(i.id)
+	}
+
+	i.userID = &userID
+	i.updatedAt = time.Now()
+	return nil
+}
+
+// RecordInstaller sets the GitHub user who installed the app, once reported by GitHub
+func (i *Installation) RecordInstaller(githubUserID int64) {
+	i.installedByID = githubUserID
+	i.updatedAt = time.Now()
+}
+
+// Suspend stops SnapDeploy from using the installation until it is unsuspended
+func (i *Installation) Suspend() {
+	i.suspended = true
+	i.updatedAt = time.Now()
+}
+
+// Unsuspend allows SnapDeploy to use the installation again
+func (i *Installation) Unsuspend() {
+	i.suspended = false
+	i.updatedAt = time.Now()
+}
+
+// BelongsToUser checks if the installation was claimed by the given user
+func (i *Installation) BelongsToUser(userID user.UserID) bool {
+	return i.userID != nil && i.userID.Equals(userID)
+}
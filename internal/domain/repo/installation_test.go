@@ -0,0 +1,72 @@
+package repo_test
+
+import (
+	"errors"
+	"testing"
+
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestInstallation_Claim(t *testing.T) {
+	const (
+		accountID   = int64(100)
+		installerID = int64(200)
+		strangerID  = int64(300)
+	)
+
+	tests := []struct {
+		name         string
+		accountType  string
+		installedBy  int64
+		githubUserID int64
+		wantErr      bool
+	}{
+		{"installer claims organization installation", repo.AccountTypeOrganization, installerID, installerID, false},
+		{"stranger cannot claim organization installation", repo.AccountTypeOrganization, installerID, strangerID, true},
+		{"organization cannot be claimed before the installer is known", repo.AccountTypeOrganization, 0, accountID, true},
+		{"owner claims personal installation before the installer is known", repo.AccountTypeUser, 0, accountID, false},
+		{"stranger cannot claim personal installation", repo.AccountTypeUser, 0, strangerID, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installation, err := repo.NewInstallation(1, accountID, "octocat", tt.accountType, tt.installedBy)
+			if err != nil {
+				t.Fatalf("NewInstallation() error = %v", err)
+			}
+
+			userID := user.NewUserID()
+			err = installation.Claim(userID, tt.githubUserID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Claim() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := installation.BelongsToUser(userID); got != !tt.wantErr {
+				t.Errorf("BelongsToUser() = %v, want %v", got, !tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInstallation_ClaimedByAnotherUser(t *testing.T) {
+	installation, err := repo.NewInstallation(1, 100, "octocat", repo.AccountTypeUser, 100)
+	if err != nil {
+		t.Fatalf("NewInstallation() error = %v", err)
+	}
+
+	owner := user.NewUserID()
+	if err := installation.Claim(owner, 100); err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+
+	// Claiming again as the same user is a no-op
+	if err := installation.Claim(owner, 100); err != nil {
+		t.Errorf("Claim() by owner again error = %v", err)
+	}
+
+	var domainErr *repo.DomainError
+	err = installation.Claim(user.NewUserID(), 100)
+	if !errors.As(err, &domainErr) || domainErr.Code != "INSTALLATION_CLAIMED" {
+		t.Errorf("Claim() by another user error = %v, want INSTALLATION_CLAIMED", err)
+	}
+}
@@ -21,6 +21,12 @@ type RepositoryRepo interface {
 	// SearchByUserID searches repositories for a user with optional search query
 	SearchByUserID(ctx context.Context, userID user.UserID, searchQuery string, limit, offset int32) ([]*Repository, error)
 
+	// FindByUserIDAfter retrieves up to limit repositories for a user that come after the cursor, newest first
+	FindByUserIDAfter(ctx context.Context, userID user.UserID, after Cursor, limit int32) ([]*Repository, error)
+
+	// SearchByUserIDAfter searches up to limit repositories for a user that come after the cursor, newest first
+	SearchByUserIDAfter(ctx context.Context, userID user.UserID, searchQuery string, after Cursor, limit int32) ([]*Repository, error)
+
 	// CountByUserID returns the total number of repositories for a user
 	CountByUserID(ctx context.Context, userID user.UserID) (int64, error)
 
@@ -30,6 +36,34 @@ type RepositoryRepo interface {
 	// FindByURL retrieves a repository by its URL
 	FindByURL(ctx context.Context, url URL) (*Repository, error)
 
+	// FindByURLs retrieves the repositories with any of the URLs, in no particular order
+	FindByURLs(ctx context.Context, urls []URL) ([]*Repository, error)
+
+	// SaveAll persists repositories (create or update) in batches rather than one by one
+	SaveAll(ctx context.Context, repositories []*Repository) error
+
+	// FindSyncState retrieves the state of the last sync of a user's repositories from a provider,
+	// or nil if they never synced from it
+	FindSyncState(ctx context.Context, userID user.UserID, provider Provider) (*SyncState, error)
+
+	// SaveSyncState persists the state of the last sync of a user's repositories from a provider
+	SaveSyncState(ctx context.Context, state *SyncState) error
+
 	// Delete removes a repository from persistence
 	Delete(ctx context.Context, id RepositoryID) error
 }
+
+// InstallationRepo defines the interface for GitHub App installation persistence
+type InstallationRepo interface {
+	// Save persists an installation (create or update)
+	Save(ctx context.Context, installation *Installation) error
+
+	// FindByID retrieves an installation by its GitHub installation ID
+	FindByID(ctx context.Context, id int64) (*Installation, error)
+
+	// FindByUserID retrieves the installations claimed by a user
+	FindByUserID(ctx context.Context, userID user.UserID) ([]*Installation, error)
+
+	// Delete removes an installation from persistence
+	Delete(ctx context.Context, id int64) error
+}
@@ -0,0 +1,35 @@
+package repo
+
+import (
+	"time"
+
+	"snapdeploy-core/internal/domain/user"
+)
+
+// FullSyncInterval is how often a sync fetches every repository from a provider even though it could fetch only the
+// changed ones, picking up anything an incremental sync missed
+const FullSyncInterval = 24 * time.Hour
+
+// SyncState records the last sync of a user's repositories from a provider, so the next one fetches only what changed
+type SyncState struct {
+	UserID       user.UserID
+	Provider     Provider
+	ETag         string    // Validator of the provider's list of repositories at the last sync
+	SyncedAt     time.Time // When the last sync started
+	FullSyncedAt time.Time // When the last sync fetching every repository started
+}
+
+// NeedsFullSync reports whether a sync starting at now should fetch every repository.
+// It is true for users who never synced from the provider, whose state is nil.
+func (s *SyncState) NeedsFullSync(now time.Time) bool {
+	return s == nil || s.FullSyncedAt.IsZero() || now.Sub(s.FullSyncedAt) >= FullSyncInterval
+}
+
+// Next returns the state after a sync that started at startedAt and got the given ETag
+func (s *SyncState) Next(userID user.UserID, provider Provider, startedAt time.Time, etag string, full bool) *SyncState {
+	next := &SyncState{UserID: userID, Provider: provider, ETag: etag, SyncedAt: startedAt, FullSyncedAt: startedAt}
+	if !full && s != nil {
+		next.FullSyncedAt = s.FullSyncedAt
+	}
+	return next
+}
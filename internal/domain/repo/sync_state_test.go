@@ -0,0 +1,45 @@
+package repo_test
+
+import (
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestSyncState_NeedsFullSync(t *testing.T) {
+	now := time.Now()
+
+	var never *repo.SyncState
+	if !never.NeedsFullSync(now) {
+		t.Error("NeedsFullSync() = false for a user who never synced, want true")
+	}
+
+	recent := &repo.SyncState{SyncedAt: now.Add(-time.Hour), FullSyncedAt: now.Add(-time.Hour)}
+	if recent.NeedsFullSync(now) {
+		t.Error("NeedsFullSync() = true an hour after a full sync, want false")
+	}
+
+	stale := &repo.SyncState{SyncedAt: now.Add(-time.Hour), FullSyncedAt: now.Add(-repo.FullSyncInterval)}
+	if !stale.NeedsFullSync(now) {
+		t.Error("NeedsFullSync() = false a day after a full sync, want true")
+	}
+}
+
+func TestSyncState_Next(t *testing.T) {
+	userID := user.NewUserID()
+	lastFull := time.Now().Add(-time.Hour)
+	state := &repo.SyncState{UserID: userID, Provider: repo.ProviderGitHub, ETag: `"v1"`, SyncedAt: lastFull, FullSyncedAt: lastFull}
+	now := time.Now()
+
+	incremental := state.Next(userID, repo.ProviderGitHub, now, `"v2"`, false)
+	if incremental.ETag != `"v2"` || !incremental.SyncedAt.Equal(now) || !incremental.FullSyncedAt.Equal(lastFull) {
+		t.Errorf("Next() after an incremental sync = %+v, want the last full sync kept", incremental)
+	}
+
+	full := state.Next(userID, repo.ProviderGitHub, now, `"v2"`, true)
+	if !full.FullSyncedAt.Equal(now) {
+		t.Errorf("Next() after a full sync = %+v, want it recorded as the last full sync", full)
+	}
+}
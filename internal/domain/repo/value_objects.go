@@ -1,10 +1,12 @@
 package repo
 
 import (
-	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+
+	"snapdeploy-core/internal/domain/validation"
 )
 
 // RepositoryID is a value object representing a repository's unique identifier
@@ -21,7 +23,7 @@ func NewRepositoryID() RepositoryID {
 func ParseRepositoryID(id string) (RepositoryID, error) {
 	uid, err := uuid.Parse(id)
 	if err != nil {
-		return RepositoryID{}, fmt.Errorf("invalid repository ID format: %w", err)
+		return RepositoryID{}, validation.Errorf("invalid repository ID format: %w", err)
 	}
 	return RepositoryID{value: uid}, nil
 }
@@ -38,25 +40,84 @@ func (id RepositoryID) Equals(other RepositoryID) bool {
 	return id.value == other.value
 }
 
-// GitHubID is a value object representing a GitHub repository ID
-type GitHubID struct {
-	value int64
+// Cursor is the position of a repository in a list sorted newest first, with the ID breaking ties between
+// repositorys created at the same time. Listing after a cursor continues the list past it.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        RepositoryID
+}
+
+// ExternalID is a value object representing a repository's ID at its Git provider
+type ExternalID struct {
+	value string
 }
 
-// NewGitHubID creates a new GitHubID with validation
-func NewGitHubID(id int64) (GitHubID, error) {
-	if id <= 0 {
-		return GitHubID{}, fmt.Errorf("GitHub ID must be positive")
+// NewExternalID creates a new ExternalID with validation
+func NewExternalID(id string) (ExternalID, error) {
+	id = strings.TrimSpace(id)
+
+	if id == "" {
+		return ExternalID{}, validation.Errorf("external ID cannot be empty")
 	}
-	return GitHubID{value: id}, nil
+
+	if len(id) > 255 {
+		return ExternalID{}, validation.Errorf("external ID too long (max 255 characters)")
+	}
+
+	return ExternalID{value: id}, nil
 }
 
-func (g GitHubID) Int64() int64 {
-	return g.value
+func (e ExternalID) String() string {
+	return e.value
+}
+
+func (e ExternalID) Equals(other ExternalID) bool {
+	return e.value == other.value
+}
+
+// Provider represents the Git hosting service a repository lives on
+type Provider string
+
+const (
+	ProviderGitHub    Provider = "GITHUB"
+	ProviderGitLab    Provider = "GITLAB"
+	ProviderBitbucket Provider = "BITBUCKET"
+)
+
+// NewProvider creates a new Provider with validation
+func NewProvider(provider string) (Provider, error) {
+	provider = strings.ToUpper(strings.TrimSpace(provider))
+
+	switch Provider(provider) {
+	case ProviderGitHub, ProviderGitLab, ProviderBitbucket:
+		return Provider(provider), nil
+	default:
+		return "", validation.Errorf("invalid provider: %s (must be one of: GITHUB, GITLAB, BITBUCKET)", provider)
+	}
+}
+
+// ProviderFromURL detects the provider hosting a repository from its URL
+func ProviderFromURL(repoURL string) (Provider, bool) {
+	host := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(repoURL), "https://"), "http://")
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+
+	switch host {
+	case "github.com", "api.github.com":
+		return ProviderGitHub, true
+	case "gitlab.com":
+		return ProviderGitLab, true
+	case "bitbucket.org", "api.bitbucket.org":
+		return ProviderBitbucket, true
+	default:
+		return "", false
+	}
 }
 
-func (g GitHubID) Equals(other GitHubID) bool {
-	return g.value == other.value
+func (p Provider) String() string {
+	return string(p)
 }
 
 // Name is a value object representing a repository name
@@ -69,11 +130,11 @@ func NewName(name string) (Name, error) {
 	name = strings.TrimSpace(name)
 
 	if name == "" {
-		return Name{}, fmt.Errorf("repository name cannot be empty")
+		return Name{}, validation.Errorf("repository name cannot be empty")
 	}
 
 	if len(name) > 100 {
-		return Name{}, fmt.Errorf("repository name too long (max 100 characters)")
+		return Name{}, validation.Errorf("repository name too long (max 100 characters)")
 	}
 
 	return Name{value: name}, nil
@@ -97,11 +158,11 @@ func NewURL(url string) (URL, error) {
 	url = strings.TrimSpace(url)
 
 	if url == "" {
-		return URL{}, fmt.Errorf("repository URL cannot be empty")
+		return URL{}, validation.Errorf("repository URL cannot be empty")
 	}
 
 	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
-		return URL{}, fmt.Errorf("repository URL must be a valid HTTP(S) URL")
+		return URL{}, validation.Errorf("repository URL must be a valid HTTP(S) URL")
 	}
 
 	return URL{value: url}, nil
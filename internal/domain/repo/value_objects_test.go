@@ -60,27 +60,77 @@ func TestNewURL(t *testing.T) {
 	}
 }
 
-func TestNewGitHubID(t *testing.T) {
+func TestNewExternalID(t *testing.T) {
 	tests := []struct {
 		name    string
-		id      int64
+		id      string
 		wantErr bool
 	}{
-		{"valid positive ID", 12345, false},
-		{"valid large ID", 999999999, false},
-		{"invalid zero", 0, true},
-		{"invalid negative", -1, true},
+		{"valid numeric ID", "12345", false},
+		{"valid UUID", "{6d3c1e2a-8f4b-4c5d-9e6f-7a8b9c0d1e2f}", false},
+		{"invalid empty", "", true},
+		{"invalid whitespace", "   ", true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ghID, err := repo.NewGitHubID(tt.id)
+			externalID, err := repo.NewExternalID(tt.id)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("NewGitHubID() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("NewExternalID() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !tt.wantErr && ghID.Int64() != tt.id {
-				t.Errorf("NewGitHubID() = %v, want %v", ghID.Int64(), tt.id)
+			if !tt.wantErr && externalID.String() != tt.id {
+				t.Errorf("NewExternalID() = %v, want %v", externalID.String(), tt.id)
+			}
+		})
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		want     repo.Provider
+		wantErr  bool
+	}{
+		{"GitHub", "GITHUB", repo.ProviderGitHub, false},
+		{"lowercase GitLab", "gitlab", repo.ProviderGitLab, false},
+		{"Bitbucket", "Bitbucket", repo.ProviderBitbucket, false},
+		{"invalid", "gitea", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := repo.NewProvider(tt.provider)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewProvider() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if provider != tt.want {
+				t.Errorf("NewProvider() = %v, want %v", provider, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderFromURL(t *testing.T) {
+	tests := []struct {
+		url    string
+		want   repo.Provider
+		wantOK bool
+	}{
+		{"https://github.com/user/repo", repo.ProviderGitHub, true},
+		{"https://api.github.com/repos/user/repo", repo.ProviderGitHub, true},
+		{"https://gitlab.com/group/subgroup/repo", repo.ProviderGitLab, true},
+		{"https://bitbucket.org/workspace/repo", repo.ProviderBitbucket, true},
+		{"https://example.com/user/repo", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			provider, ok := repo.ProviderFromURL(tt.url)
+			if provider != tt.want || ok != tt.wantOK {
+				t.Errorf("ProviderFromURL() = %v, %v, want %v, %v", provider, ok, tt.want, tt.wantOK)
 			}
 		})
 	}
@@ -0,0 +1,12 @@
+package shell
+
+import "io"
+
+// Conn is an open shell in a project's container. Reads return what the shell writes, writes are typed
+// into it. Reads return io.EOF once the shell exits.
+type Conn interface {
+	io.ReadWriteCloser
+
+	// Resize sets the size of the shell's terminal
+	Resize(cols, rows int) error
+}
@@ -0,0 +1,228 @@
+package shell
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// ConnectWindow is how long after a session is created its token can be used to connect to it
+const ConnectWindow = time.Minute
+
+// Session is a domain entity representing an interactive shell opened in a running task of a project's
+// environment, kept as the audit record of who opened it, when and for how long
+type Session struct {
+	id           SessionID
+	projectID    project.ProjectID
+	userID       user.UserID // User who opened the shell
+	environment  project.Environment
+	tokenHash    string // SHA-256 of the token the user's terminal connects with
+	status       SessionStatus
+	taskArn      string // Task the shell ran in
+	endReason    EndReason
+	errorMessage string
+	bytesIn      int64 // Bytes typed into the shell
+	bytesOut     int64 // Bytes the shell wrote
+	createdAt    time.Time
+	startedAt    *time.Time
+	endedAt      *time.Time
+}
+
+// NewSession creates a session for the user to open a shell in an environment of a project, and the token
+// their terminal connects to it with. Only the token's hash is kept.
+func NewSession(proj *project.Project, userID user.UserID, env project.Environment) (*Session, string, error) {
+	if proj.Type() == project.TypeStatic || proj.Type() == project.TypeCron || proj.DeploymentTarget() == project.TargetLambda {
+		return nil, "", ErrUnsupportedProject
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", fmt.Errorf("failed to generate shell session token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	return &Session{
+		id:          NewSessionID(),
+		projectID:   proj.ID(),
+		userID:      userID,
+		environment: env,
+		tokenHash:   hashToken(token),
+		status:      StatusPending,
+		createdAt:   time.Now(),
+	}, token, nil
+}
+
+// ReconstituteSession recreates a Session entity from persistence
+func ReconstituteSession(
+	id, projectID, userID, environment, tokenHash, status, taskArn, endReason, errorMessage string,
+	bytesIn, bytesOut int64,
+	createdAt time.Time,
+	startedAt, endedAt *time.Time,
+) (*Session, error) {
+	sid, err := ParseSessionID(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shell session ID: %w", err)
+	}
+
+	pid, err := project.ParseProjectID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	uid, err := user.ParseUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	env, err := project.NewEnvironment(environment)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionStatus, err := NewSessionStatus(status)
+	if err != nil {
+		return nil, err
+	}
+
+	reason, err := NewEndReason(endReason)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		id:           sid,
+		projectID:    pid,
+		userID:       uid,
+		environment:  env,
+		tokenHash:    tokenHash,
+		status:       sessionStatus,
+		taskArn:      taskArn,
+		endReason:    reason,
+		errorMessage: errorMessage,
+		bytesIn:      bytesIn,
+		bytesOut:     bytesOut,
+		createdAt:    createdAt,
+		startedAt:    startedAt,
+		endedAt:      endedAt,
+	}, nil
+}
+
+// Claim checks the token a terminal connects with and uses it up, so a session is only ever connected to
+// once and only within ConnectWindow of being created
+func (s *Session) Claim(token string, now time.Time) error {
+	if s.status != StatusPending || now.Sub(s.createdAt) > ConnectWindow {
+		return ErrInvalidToken
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(s.tokenHash)) != 1 {
+		return ErrInvalidToken
+	}
+
+	s.status = StatusActive
+	s.startedAt = &now
+	return nil
+}
+
+// Open records the task the shell was opened in
+func (s *Session) Open(taskArn string) {
+	s.taskArn = taskArn
+}
+
+// End records why the session ended and how much went through it
+func (s *Session) End(reason EndReason, bytesIn, bytesOut int64, err error) {
+	now := time.Now()
+	s.status = StatusEnded
+	s.endReason = reason
+	s.bytesIn = bytesIn
+	s.bytesOut = bytesOut
+	s.endedAt = &now
+	if err != nil {
+		s.errorMessage = err.Error()
+	}
+}
+
+// IsExpired reports whether the session was never connected to and its token can no longer be used
+func (s *Session) IsExpired(now time.Time) bool {
+	return s.status == StatusPending && now.Sub(s.createdAt) > ConnectWindow
+}
+
+// Duration returns how long the shell was open, or has been open for
+func (s *Session) Duration(now time.Time) time.Duration {
+	switch {
+	case s.startedAt == nil:
+		return 0
+	case s.endedAt != nil:
+		return s.endedAt.Sub(*s.startedAt)
+	default:
+		return now.Sub(*s.startedAt)
+	}
+}
+
+// hashToken returns the hash a session keeps of its token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Getters
+
+func (s *Session) ID() SessionID {
+	return s.id
+}
+
+func (s *Session) ProjectID() project.ProjectID {
+	return s.projectID
+}
+
+func (s *Session) UserID() user.UserID {
+	return s.userID
+}
+
+func (s *Session) Environment() project.Environment {
+	return s.environment
+}
+
+func (s *Session) TokenHash() string {
+	return s.tokenHash
+}
+
+func (s *Session) Status() SessionStatus {
+	return s.status
+}
+
+func (s *Session) TaskArn() string {
+	return s.taskArn
+}
+
+func (s *Session) EndReason() EndReason {
+	return s.endReason
+}
+
+func (s *Session) ErrorMessage() string {
+	return s.errorMessage
+}
+
+func (s *Session) BytesIn() int64 {
+	return s.bytesIn
+}
+
+func (s *Session) BytesOut() int64 {
+	return s.bytesOut
+}
+
+func (s *Session) CreatedAt() time.Time {
+	return s.createdAt
+}
+
+func (s *Session) StartedAt() *time.Time {
+	return s.startedAt
+}
+
+func (s *Session) EndedAt() *time.Time {
+	return s.endedAt
+}
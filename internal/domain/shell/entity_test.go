@@ -0,0 +1,64 @@
+package shell_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/shell"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func newServiceProject(t *testing.T) *project.Project {
+	t.Helper()
+	proj, err := project.NewProject(user.NewUserID(), "https://github.com/acme/app", "npm install", "", "npm start", "NODE", "", true, "")
+	if err != nil {
+		t.Fatalf("NewProject() error = %v", err)
+	}
+	return proj
+}
+
+func TestSessionClaim(t *testing.T) {
+	proj := newServiceProject(t)
+	session, token, err := shell.NewSession(proj, proj.UserID(), project.EnvironmentProduction)
+	if err != nil {
+		t.Fatalf("NewSession() error = %v", err)
+	}
+	if session.TokenHash() == token || session.Status() != shell.StatusPending {
+		t.Fatalf("session = %v, want it pending with only the token's hash kept", session.Status())
+	}
+
+	if err := session.Claim("not-the-token", time.Now()); !errors.Is(err, shell.ErrInvalidToken) {
+		t.Errorf("Claim() with a wrong token error = %v, want ErrInvalidToken", err)
+	}
+	if err := session.Claim(token, time.Now().Add(shell.ConnectWindow+time.Second)); !errors.Is(err, shell.ErrInvalidToken) {
+		t.Errorf("Claim() after the connect window error = %v, want ErrInvalidToken", err)
+	}
+	if err := session.Claim(token, time.Now()); err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if session.Status() != shell.StatusActive || session.StartedAt() == nil {
+		t.Errorf("session = %v, want it active once claimed", session.Status())
+	}
+	if err := session.Claim(token, time.Now()); !errors.Is(err, shell.ErrInvalidToken) {
+		t.Errorf("second Claim() error = %v, want the token used up", err)
+	}
+
+	session.End(shell.EndIdleTimeout, 12, 340, nil)
+	if session.Status() != shell.StatusEnded || session.EndReason() != shell.EndIdleTimeout || session.BytesOut() != 340 {
+		t.Errorf("session = %v (%v, %d bytes out), want it ended idle", session.Status(), session.EndReason(), session.BytesOut())
+	}
+}
+
+func TestNewSession_UnsupportedProjects(t *testing.T) {
+	for _, projectType := range []string{"STATIC", "CRON"} {
+		proj := newServiceProject(t)
+		if err := proj.SetType(projectType); err != nil {
+			t.Fatalf("SetType(%s) error = %v", projectType, err)
+		}
+		if _, _, err := shell.NewSession(proj, proj.UserID(), project.EnvironmentProduction); !errors.Is(err, shell.ErrUnsupportedProject) {
+			t.Errorf("NewSession() in a %s project error = %v, want ErrUnsupportedProject", projectType, err)
+		}
+	}
+}
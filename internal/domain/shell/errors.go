@@ -0,0 +1,20 @@
+package shell
+
+import "errors"
+
+var (
+	// ErrSessionNotFound is returned when a shell session is not found
+	ErrSessionNotFound = errors.New("shell session not found")
+
+	// ErrInvalidToken is returned when a session is connected to with a wrong or expired token, or a second time
+	ErrInvalidToken = errors.New("shell session token is invalid or expired")
+
+	// ErrUnsupportedProject is returned when a project has no long-running container to open a shell in
+	ErrUnsupportedProject = errors.New("shells can only be opened in projects running as ECS services, not STATIC, LAMBDA or CRON projects")
+
+	// ErrNoRunningTask is returned when the environment has no running task to open a shell in
+	ErrNoRunningTask = errors.New("environment has no running task")
+
+	// ErrExecDisabled is returned when the running task was started before shells were enabled for its service
+	ErrExecDisabled = errors.New("shells aren't enabled on the running task, redeploy the environment to enable them")
+)
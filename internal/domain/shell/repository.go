@@ -0,0 +1,23 @@
+package shell
+
+import (
+	"context"
+
+	"snapdeploy-core/internal/domain/project"
+)
+
+// SessionRepository defines the interface for shell session persistence
+type SessionRepository interface {
+	// Save persists a session, replacing its recorded state
+	Save(ctx context.Context, session *Session) error
+
+	// Claim records that a claimed session was connected to, failing with ErrInvalidToken if another
+	// connection already claimed it
+	Claim(ctx context.Context, session *Session) error
+
+	// FindByID retrieves a session by its ID
+	FindByID(ctx context.Context, id SessionID) (*Session, error)
+
+	// FindByProjectID retrieves the most recent sessions of a project, newest first
+	FindByProjectID(ctx context.Context, projectID project.ProjectID, limit int) ([]*Session, error)
+}
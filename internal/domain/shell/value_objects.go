@@ -0,0 +1,88 @@
+package shell
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"snapdeploy-core/internal/domain/validation"
+)
+
+// SessionID is a value object representing a shell session's unique identifier
+type SessionID struct {
+	value uuid.UUID
+}
+
+// NewSessionID creates a new SessionID
+func NewSessionID() SessionID {
+	return SessionID{value: uuid.New()}
+}
+
+// ParseSessionID parses a string into a SessionID
+func ParseSessionID(id string) (SessionID, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return SessionID{}, validation.Errorf("invalid shell session ID format: %w", err)
+	}
+	return SessionID{value: uid}, nil
+}
+
+func (id SessionID) String() string {
+	return id.value.String()
+}
+
+func (id SessionID) UUID() uuid.UUID {
+	return id.value
+}
+
+func (id SessionID) Equals(other SessionID) bool {
+	return id.value == other.value
+}
+
+// SessionStatus represents where a shell session is in its life
+type SessionStatus string
+
+const (
+	StatusPending SessionStatus = "PENDING" // Created, waiting for the user's terminal to connect
+	StatusActive  SessionStatus = "ACTIVE"
+	StatusEnded   SessionStatus = "ENDED"
+)
+
+// NewSessionStatus creates a new SessionStatus with validation
+func NewSessionStatus(status string) (SessionStatus, error) {
+	switch SessionStatus(status) {
+	case StatusPending, StatusActive, StatusEnded:
+		return SessionStatus(status), nil
+	default:
+		return "", fmt.Errorf("invalid shell session status: %s", status)
+	}
+}
+
+func (s SessionStatus) String() string {
+	return string(s)
+}
+
+// EndReason represents why a shell session ended
+type EndReason string
+
+const (
+	EndClosed      EndReason = "CLOSED"       // The user closed their terminal
+	EndExited      EndReason = "EXITED"       // The shell exited
+	EndIdleTimeout EndReason = "IDLE_TIMEOUT" // Nothing was typed for too long
+	EndTimeLimit   EndReason = "TIME_LIMIT"   // The session reached its maximum duration
+	EndFailed      EndReason = "FAILED"       // The shell couldn't be opened or its connection broke
+)
+
+// NewEndReason creates a new EndReason with validation, empty for sessions that haven't ended
+func NewEndReason(reason string) (EndReason, error) {
+	switch EndReason(reason) {
+	case "", EndClosed, EndExited, EndIdleTimeout, EndTimeLimit, EndFailed:
+		return EndReason(reason), nil
+	default:
+		return "", fmt.Errorf("invalid shell session end reason: %s", reason)
+	}
+}
+
+func (r EndReason) String() string {
+	return string(r)
+}
@@ -0,0 +1,150 @@
+package template
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Variable is an environment variable a template pre-fills on the projects created from it
+type Variable struct {
+	Key         string
+	Value       string // Default value, empty if the user supplies it
+	Description string
+	Scope       string // BUILD, RUNTIME or BOTH, empty for RUNTIME
+	Required    bool   // Projects can't be created from the template without a value
+}
+
+// Template is a curated sample app, such as a Next.js starter, that projects can be created from
+type Template struct {
+	id               string
+	name             string
+	description      string
+	repositoryURL    string // Sample repository projects deploy, or copy into a repository of their own
+	language         string
+	projectType      string
+	installCommand   string
+	buildCommand     string
+	runCommand       string
+	requireDB        bool
+	migrationCommand string
+	variables        []Variable
+	createdAt        time.Time
+}
+
+// Reconstitute recreates a Template entity from persistence
+func Reconstitute(
+	id, name, description, repositoryURL, language, projectType string,
+	installCommand, buildCommand, runCommand string,
+	requireDB bool,
+	migrationCommand string,
+	variables []Variable,
+	createdAt time.Time,
+) *Template {
+	return &Template{
+		id:               id,
+		name:             name,
+		description:      description,
+		repositoryURL:    repositoryURL,
+		language:         language,
+		projectType:      projectType,
+		installCommand:   installCommand,
+		buildCommand:     buildCommand,
+		runCommand:       runCommand,
+		requireDB:        requireDB,
+		migrationCommand: migrationCommand,
+		variables:        variables,
+		createdAt:        createdAt,
+	}
+}
+
+// ResolveVariables returns the environment variables of a project created from the template: the template's
+// variables with the given values taking the place of their defaults, followed by the given values the template
+// doesn't define in key order. Variables left without a value are omitted.
+func (t *Template) ResolveVariables(values map[string]string) ([]Variable, error) {
+	resolved := make([]Variable, 0, len(t.variables)+len(values))
+	defined := make(map[string]bool, len(t.variables))
+
+	for _, variable := range t.variables {
+		defined[variable.Key] = true
+		if value, ok := values[variable.Key]; ok {
+			variable.Value = value
+		}
+		if strings.TrimSpace(variable.Value) == "" {
+			if variable.Required {
+				return nil, fmt.Errorf("%w: %s", ErrMissingVariable, variable.Key)
+			}
+			continue
+		}
+		resolved = append(resolved, variable)
+	}
+
+	extra := make([]string, 0, len(values))
+	for key, value := range values {
+		if !defined[key] && strings.TrimSpace(value) != "" {
+			extra = append(extra, key)
+		}
+	}
+	slices.Sort(extra)
+	for _, key := range extra {
+		resolved = append(resolved, Variable{Key: key, Value: values[key]})
+	}
+
+	return resolved, nil
+}
+
+// Getters
+
+func (t *Template) ID() string {
+	return t.id
+}
+
+func (t *Template) Name() string {
+	return t.name
+}
+
+func (t *Template) Description() string {
+	return t.description
+}
+
+func (t *Template) RepositoryURL() string {
+	return t.repositoryURL
+}
+
+func (t *Template) Language() string {
+	return t.language
+}
+
+func (t *Template) ProjectType() string {
+	return t.projectType
+}
+
+func (t *Template) InstallCommand() string {
+	return t.installCommand
+}
+
+func (t *Template) BuildCommand() string {
+	return t.buildCommand
+}
+
+func (t *Template) RunCommand() string {
+	return t.runCommand
+}
+
+func (t *Template) RequireDB() bool {
+	return t.requireDB
+}
+
+func (t *Template) MigrationCommand() string {
+	return t.migrationCommand
+}
+
+// Variables returns the environment variables the template pre-fills
+func (t *Template) Variables() []Variable {
+	return slices.Clone(t.variables)
+}
+
+func (t *Template) CreatedAt() time.Time {
+	return t.createdAt
+}
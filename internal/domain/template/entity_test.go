@@ -0,0 +1,64 @@
+package template_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/domain/template"
+)
+
+func newTestTemplate() *template.Template {
+	return template.Reconstitute(
+		"go-api-starter", "Go API starter", "", "https://github.com/SnapDeploy/go-api-starter", "GO", "WEB",
+		"go mod download", "go build -o server ./cmd/server", "./server",
+		true, "./server migrate",
+		[]template.Variable{
+			{Key: "LOG_LEVEL", Value: "info"},
+			{Key: "API_KEY", Required: true},
+			{Key: "SENTRY_DSN"},
+		},
+		time.Now(),
+	)
+}
+
+func TestTemplate_ResolveVariables(t *testing.T) {
+	resolved, err := newTestTemplate().ResolveVariables(map[string]string{
+		"API_KEY":  "secret",
+		"TZ":       "UTC",
+		"FEATURES": "beta",
+	})
+	if err != nil {
+		t.Fatalf("ResolveVariables() error = %v", err)
+	}
+
+	// Defaults are kept, variables without a value left out and values the template doesn't define added in key order
+	want := []template.Variable{
+		{Key: "LOG_LEVEL", Value: "info"},
+		{Key: "API_KEY", Value: "secret", Required: true},
+		{Key: "FEATURES", Value: "beta"},
+		{Key: "TZ", Value: "UTC"},
+	}
+	if !reflect.DeepEqual(resolved, want) {
+		t.Errorf("ResolveVariables() = %+v, want %+v", resolved, want)
+	}
+}
+
+func TestTemplate_ResolveVariablesOverridesDefaults(t *testing.T) {
+	resolved, err := newTestTemplate().ResolveVariables(map[string]string{"API_KEY": "secret", "LOG_LEVEL": "debug"})
+	if err != nil {
+		t.Fatalf("ResolveVariables() error = %v", err)
+	}
+	if resolved[0].Key != "LOG_LEVEL" || resolved[0].Value != "debug" {
+		t.Errorf("ResolveVariables()[0] = %+v, want LOG_LEVEL=debug", resolved[0])
+	}
+}
+
+func TestTemplate_ResolveVariablesRequiresValues(t *testing.T) {
+	for _, values := range []map[string]string{nil, {"API_KEY": "  "}} {
+		if _, err := newTestTemplate().ResolveVariables(values); !errors.Is(err, template.ErrMissingVariable) {
+			t.Errorf("ResolveVariables(%v) error = %v, want ErrMissingVariable", values, err)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package template
+
+import "errors"
+
+var (
+	// ErrTemplateNotFound is returned when a template is not found
+	ErrTemplateNotFound = errors.New("template not found")
+
+	// ErrMissingVariable is returned when creating a project from a template without a value for one of its
+	// required environment variables
+	ErrMissingVariable = errors.New("template environment variable requires a value")
+)
@@ -0,0 +1,12 @@
+package template
+
+import "context"
+
+// TemplateRepository defines the interface for reading the template catalog
+type TemplateRepository interface {
+	// FindAll retrieves every template in catalog order
+	FindAll(ctx context.Context) ([]*Template, error)
+
+	// FindByID retrieves a template by its ID. Returns ErrTemplateNotFound if there is none.
+	FindByID(ctx context.Context, id string) (*Template, error)
+}
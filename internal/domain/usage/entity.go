@@ -0,0 +1,112 @@
+package usage
+
+import (
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// Record is a domain entity representing metered usage of a project over a period
+type Record struct {
+	id           RecordID
+	projectID    project.ProjectID
+	userID       user.UserID
+	deploymentID *deployment.DeploymentID // Deployment that consumed the usage, if any
+	metric       Metric
+	quantity     float64
+	periodStart  time.Time
+	periodEnd    time.Time
+	createdAt    time.Time
+}
+
+// NewRecord creates a new usage Record entity
+func NewRecord(
+	projectID project.ProjectID,
+	userID user.UserID,
+	deploymentID *deployment.DeploymentID,
+	metric Metric,
+	quantity float64,
+	periodStart, periodEnd time.Time,
+) (*Record, error) {
+	if quantity < 0 {
+		return nil, ErrNegativeQuantity
+	}
+
+	if !periodEnd.After(periodStart) {
+		return nil, ErrInvalidPeriod
+	}
+
+	return &Record{
+		id:           NewRecordID(),
+		projectID:    projectID,
+		userID:       userID,
+		deploymentID: deploymentID,
+		metric:       metric,
+		quantity:     quantity,
+		periodStart:  periodStart,
+		periodEnd:    periodEnd,
+		createdAt:    time.Now(),
+	}, nil
+}
+
+// NewComputeRecords creates the vCPU and memory records for a Fargate task running over a period
+func NewComputeRecords(
+	projectID project.ProjectID,
+	userID user.UserID,
+	deploymentID deployment.DeploymentID,
+	periodStart, periodEnd time.Time,
+) ([]*Record, error) {
+	hours := periodEnd.Sub(periodStart).Hours()
+
+	vcpu, err := NewRecord(projectID, userID, &deploymentID, MetricVCPUHours, hours*TaskVCPU, periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	memory, err := NewRecord(projectID, userID, &deploymentID, MetricMemoryGBHours, hours*TaskMemoryGB, periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*Record{vcpu, memory}, nil
+}
+
+// Getters
+
+func (r *Record) ID() RecordID {
+	return r.id
+}
+
+func (r *Record) ProjectID() project.ProjectID {
+	return r.projectID
+}
+
+func (r *Record) UserID() user.UserID {
+	return r.userID
+}
+
+func (r *Record) DeploymentID() *deployment.DeploymentID {
+	return r.deploymentID
+}
+
+func (r *Record) Metric() Metric {
+	return r.metric
+}
+
+func (r *Record) Quantity() float64 {
+	return r.quantity
+}
+
+func (r *Record) PeriodStart() time.Time {
+	return r.periodStart
+}
+
+func (r *Record) PeriodEnd() time.Time {
+	return r.periodEnd
+}
+
+func (r *Record) CreatedAt() time.Time {
+	return r.createdAt
+}
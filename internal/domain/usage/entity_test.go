@@ -0,0 +1,64 @@
+package usage_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/usage"
+	"snapdeploy-core/internal/domain/user"
+)
+
+func TestNewComputeRecords(t *testing.T) {
+	start := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(4 * time.Hour)
+
+	records, err := usage.NewComputeRecords(project.NewProjectID(), user.NewUserID(), deployment.NewDeploymentID(), start, end)
+	if err != nil {
+		t.Fatalf("NewComputeRecords() error = %v", err)
+	}
+
+	want := map[usage.Metric]float64{
+		usage.MetricVCPUHours:     1.0, // 4h x 0.25 vCPU
+		usage.MetricMemoryGBHours: 2.0, // 4h x 0.5 GB
+	}
+
+	if len(records) != len(want) {
+		t.Fatalf("len(records) = %d, want %d", len(records), len(want))
+	}
+
+	for _, record := range records {
+		if record.Quantity() != want[record.Metric()] {
+			t.Errorf("%s quantity = %v, want %v", record.Metric(), record.Quantity(), want[record.Metric()])
+		}
+		if record.DeploymentID() == nil {
+			t.Errorf("%s DeploymentID() = nil", record.Metric())
+		}
+	}
+}
+
+func TestNewRecordValidation(t *testing.T) {
+	start := time.Now()
+
+	tests := []struct {
+		name     string
+		quantity float64
+		end      time.Time
+		wantErr  error
+	}{
+		{name: "valid record", quantity: 3, end: start.Add(3 * time.Minute)},
+		{name: "negative quantity", quantity: -1, end: start.Add(time.Minute), wantErr: usage.ErrNegativeQuantity},
+		{name: "period ends before start", quantity: 1, end: start.Add(-time.Minute), wantErr: usage.ErrInvalidPeriod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := usage.NewRecord(project.NewProjectID(), user.NewUserID(), nil, usage.MetricBuildMinutes, tt.quantity, start, tt.end)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewRecord() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package usage
+
+import "snapdeploy-core/internal/domain/validation"
+
+var (
+	// ErrInvalidPeriod is returned when a usage period ends before it starts
+	ErrInvalidPeriod = validation.New("usage period must end after it starts")
+
+	// ErrNegativeQuantity is returned when a usage quantity is negative
+	ErrNegativeQuantity = validation.New("usage quantity cannot be negative")
+)
@@ -0,0 +1,24 @@
+package usage
+
+import (
+	"context"
+	"time"
+
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// RecordRepository defines the interface for usage record persistence
+type RecordRepository interface {
+	// Save persists a usage record
+	Save(ctx context.Context, record *Record) error
+
+	// LastPeriodEnd returns the end of the last metered period for a project and metric, or nil if none
+	LastPeriodEnd(ctx context.Context, projectID project.ProjectID, metric Metric) (*time.Time, error)
+
+	// SumByProjectID sums usage for a project over periods ending in (from, to]
+	SumByProjectID(ctx context.Context, projectID project.ProjectID, from, to time.Time) (Totals, error)
+
+	// SumByUserID sums usage per project for a user over periods ending in (from, to]
+	SumByUserID(ctx context.Context, userID user.UserID, from, to time.Time) (map[string]Totals, error)
+}
@@ -0,0 +1,78 @@
+package usage
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+
+	"snapdeploy-core/internal/domain/validation"
+)
+
+// Fargate task size used by the ECS orchestrator for every project service
+const (
+	TaskVCPU     = 0.25 // 256 CPU units
+	TaskMemoryGB = 0.5  // 512 MB
+)
+
+// RecordID is a value object representing a usage record's unique identifier
+type RecordID struct {
+	value uuid.UUID
+}
+
+// NewRecordID creates a new RecordID
+func NewRecordID() RecordID {
+	return RecordID{value: uuid.New()}
+}
+
+// ParseRecordID parses a string into a RecordID
+func ParseRecordID(id string) (RecordID, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return RecordID{}, validation.Errorf("invalid usage record ID format: %w", err)
+	}
+	return RecordID{value: uid}, nil
+}
+
+func (id RecordID) String() string {
+	return id.value.String()
+}
+
+func (id RecordID) UUID() uuid.UUID {
+	return id.value
+}
+
+// Metric represents a metered resource
+type Metric string
+
+const (
+	MetricVCPUHours     Metric = "VCPU_HOURS"
+	MetricMemoryGBHours Metric = "MEMORY_GB_HOURS"
+	MetricBuildMinutes  Metric = "BUILD_MINUTES"
+	MetricEgressGB      Metric = "EGRESS_GB"
+)
+
+// NewMetric creates a new Metric with validation
+func NewMetric(metric string) (Metric, error) {
+	metric = strings.ToUpper(strings.TrimSpace(metric))
+
+	switch Metric(metric) {
+	case MetricVCPUHours, MetricMemoryGBHours, MetricBuildMinutes, MetricEgressGB:
+		return Metric(metric), nil
+	default:
+		return "", validation.Errorf("invalid metric: %s (must be one of: VCPU_HOURS, MEMORY_GB_HOURS, BUILD_MINUTES, EGRESS_GB)", metric)
+	}
+}
+
+func (m Metric) String() string {
+	return string(m)
+}
+
+// Totals holds the summed quantity of each metric over a period
+type Totals map[Metric]float64
+
+// Add adds the quantities of other to the totals
+func (t Totals) Add(other Totals) {
+	for metric, quantity := range other {
+		t[metric] += quantity
+	}
+}
@@ -1,11 +1,12 @@
 package user
 
 import (
-	"fmt"
 	"regexp"
 	"strings"
 
 	"github.com/google/uuid"
+
+	"snapdeploy-core/internal/domain/validation"
 )
 
 var (
@@ -26,7 +27,7 @@ func NewUserID() UserID {
 func ParseUserID(id string) (UserID, error) {
 	uid, err := uuid.Parse(id)
 	if err != nil {
-		return UserID{}, fmt.Errorf("invalid user ID format: %w", err)
+		return UserID{}, validation.Errorf("invalid user ID format: %w", err)
 	}
 	return UserID{value: uid}, nil
 }
@@ -53,15 +54,15 @@ func NewEmail(email string) (Email, error) {
 	email = strings.TrimSpace(strings.ToLower(email))
 
 	if email == "" {
-		return Email{}, fmt.Errorf("email cannot be empty")
+		return Email{}, validation.Errorf("email cannot be empty")
 	}
 
 	if len(email) > 255 {
-		return Email{}, fmt.Errorf("email too long (max 255 characters)")
+		return Email{}, validation.Errorf("email too long (max 255 characters)")
 	}
 
 	if !emailRegex.MatchString(email) {
-		return Email{}, fmt.Errorf("invalid email format")
+		return Email{}, validation.Errorf("invalid email format")
 	}
 
 	return Email{value: email}, nil
@@ -85,21 +86,21 @@ func NewUsername(username string) (Username, error) {
 	username = strings.TrimSpace(username)
 
 	if username == "" {
-		return Username{}, fmt.Errorf("username cannot be empty")
+		return Username{}, validation.Errorf("username cannot be empty")
 	}
 
 	if len(username) < 3 {
-		return Username{}, fmt.Errorf("username too short (min 3 characters)")
+		return Username{}, validation.Errorf("username too short (min 3 characters)")
 	}
 
 	if len(username) > 50 {
-		return Username{}, fmt.Errorf("username too long (max 50 characters)")
+		return Username{}, validation.Errorf("username too long (max 50 characters)")
 	}
 
 	// Username should contain only alphanumeric characters, underscores, and hyphens
 	validUsername := regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
 	if !validUsername.MatchString(username) {
-		return Username{}, fmt.Errorf("username can only contain letters, numbers, underscores, and hyphens")
+		return Username{}, validation.Errorf("username can only contain letters, numbers, underscores, and hyphens")
 	}
 
 	return Username{value: username}, nil
@@ -123,11 +124,11 @@ func NewClerkUserID(clerkID string) (ClerkUserID, error) {
 	clerkID = strings.TrimSpace(clerkID)
 
 	if clerkID == "" {
-		return ClerkUserID{}, fmt.Errorf("clerk user ID cannot be empty")
+		return ClerkUserID{}, validation.Errorf("clerk user ID cannot be empty")
 	}
 
 	if len(clerkID) > 255 {
-		return ClerkUserID{}, fmt.Errorf("clerk user ID too long")
+		return ClerkUserID{}, validation.Errorf("clerk user ID too long")
 	}
 
 	return ClerkUserID{value: clerkID}, nil
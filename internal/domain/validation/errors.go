@@ -0,0 +1,31 @@
+// Package validation holds the error the domain packages report broken rules of their input with
+package validation
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error reports input that breaks a rule of the domain, such as an empty name or a malformed URL, as opposed to a
+// failure of the system. Its message is meant for whoever sent the input.
+type Error struct {
+	err error
+}
+
+// New returns an Error with a fixed message, for the sentinel errors of the domain packages
+func New(message string) error {
+	return &Error{err: errors.New(message)}
+}
+
+// Errorf formats an Error like fmt.Errorf, errors wrapped with %w stay reachable with errors.Is and errors.As
+func Errorf(format string, args ...any) error {
+	return &Error{err: fmt.Errorf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,34 @@
+package validation_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/validation"
+)
+
+func TestErrorf(t *testing.T) {
+	err := fmt.Errorf("failed to update project: %w", validation.Errorf("invalid port: %w", project.ErrInvalidPort))
+
+	var invalid *validation.Error
+	if !errors.As(err, &invalid) {
+		t.Fatalf("errors.As(%v) found no validation error", err)
+	}
+	if invalid.Error() != "invalid port: "+project.ErrInvalidPort.Error() {
+		t.Errorf("Error() = %q, want the formatted message", invalid.Error())
+	}
+	if !errors.Is(err, project.ErrInvalidPort) {
+		t.Errorf("errors.Is(%v, ErrInvalidPort) = false, want the wrapped error reachable", err)
+	}
+}
+
+func TestValueObjects_ReportValidationErrors(t *testing.T) {
+	_, err := project.NewRepositoryURL("")
+
+	var invalid *validation.Error
+	if !errors.As(err, &invalid) {
+		t.Errorf("NewRepositoryURL(\"\") error = %v, want a validation error", err)
+	}
+}
@@ -0,0 +1,189 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"time"
+)
+
+// ClonePermissions are the permissions requested for tokens used to clone a repository
+var ClonePermissions = map[string]string{"contents": "read", "metadata": "read"}
+
+// App authenticates as a GitHub App to act on the accounts that installed it.
+// Installation tokens expire after an hour and are scoped to the repositories and permissions requested.
+type App struct {
+	client     *Client
+	appID      int64
+	slug       string
+	privateKey *rsa.PrivateKey
+}
+
+// Account is the user or organization a GitHub App is installed on
+type Account struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Type  string `json:"type"`
+}
+
+// Installation is a GitHub App installed on an account
+type Installation struct {
+	ID                  int64      `json:"id"`
+	Account             Account    `json:"account"`
+	RepositorySelection string     `json:"repository_selection"` // "all" or "selected"
+	SuspendedAt         *time.Time `json:"suspended_at"`
+}
+
+// InstallationToken is a short-lived token acting as an installation
+type InstallationToken struct {
+	Token       string            `json:"token"`
+	ExpiresAt   time.Time         `json:"expires_at"`
+	Permissions map[string]string `json:"permissions"`
+}
+
+// NewApp creates a GitHub App client from the app's ID, URL slug and PEM encoded private key
+func NewApp(client *Client, appID int64, slug string, privateKeyPEM []byte) (*App, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key is not PEM encoded")
+	}
+
+	// GitHub issues PKCS#1 keys, but accept PKCS#8 for keys that were converted
+	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		key, pkcs8Err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if pkcs8Err != nil {
+			return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+		}
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("GitHub App private key is not an RSA key")
+		}
+		privateKey = rsaKey
+	}
+
+	return &App{
+		client:     client,
+		appID:      appID,
+		slug:       slug,
+		privateKey: privateKey,
+	}, nil
+}
+
+// InstallURL returns the page where users install the app on their account or organizations
+func (a *App) InstallURL() string {
+	return fmt.Sprintf("https://github.com/apps/%s/installations/new", a.slug)
+}
+
+// GetInstallation returns an installation of the app
+func (a *App) GetInstallation(ctx context.Context, installationID int64) (*Installation, error) {
+	jwt, err := a.jwt(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var installation Installation
+	if err := a.client.getUncached(ctx, jwt, fmt.Sprintf("/app/installations/%d", installationID), &installation); err != nil {
+		return nil, err
+	}
+
+	return &installation, nil
+}
+
+// GetRepositoryInstallation returns the installation that has access to a repository.
+// Returns ErrNotFound if the app is not installed on the repository.
+func (a *App) GetRepositoryInstallation(ctx context.Context, owner, repo string) (*Installation, error) {
+	jwt, err := a.jwt(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var installation Installation
+	if err := a.client.getUncached(ctx, jwt, fmt.Sprintf("/repos/%s/%s/installation", owner, repo), &installation); err != nil {
+		return nil, err
+	}
+
+	return &installation, nil
+}
+
+// CreateInstallationToken creates a token acting as an installation.
+// Repositories (names, without the owner) and permissions narrow the token; nil keeps the installation's full access.
+func (a *App) CreateInstallationToken(ctx context.Context, installationID int64, repositories []string, permissions map[string]string) (*InstallationToken, error) {
+	jwt, err := a.jwt(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	body := struct {
+		Repositories []string          `json:"repositories,omitempty"`
+		Permissions  map[string]string `json:"permissions,omitempty"`
+	}{
+		Repositories: repositories,
+		Permissions:  permissions,
+	}
+
+	var token InstallationToken
+	path := fmt.Sprintf("/app/installations/%d/access_tokens", installationID)
+	if err := a.client.post(ctx, jwt, path, body, &token); err != nil {
+		return nil, fmt.Errorf("failed to create installation token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// ListInstallationRepositories returns the repositories an installation has access to
+func (a *App) ListInstallationRepositories(ctx context.Context, installationID int64) ([]Repository, error) {
+	token, err := a.CreateInstallationToken(ctx, installationID, nil, map[string]string{"metadata": "read"})
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []Repository
+	for page := 1; ; page++ {
+		var resp struct {
+			TotalCount   int          `json:"total_count"`
+			Repositories []Repository `json:"repositories"`
+		}
+		path := fmt.Sprintf("/installation/repositories?per_page=100&page=%d", page)
+		if err := a.client.getUncached(ctx, token.Token, path, &resp); err != nil {
+			return nil, fmt.Errorf("failed to list installation repositories: %w", err)
+		}
+
+		repos = append(repos, resp.Repositories...)
+		if len(resp.Repositories) == 0 || len(repos) >= resp.TotalCount {
+			return repos, nil
+		}
+	}
+}
+
+// jwt creates the short-lived token that authenticates as the app itself
+func (a *App) jwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]int64{
+		"iat": now.Add(-time.Minute).Unix(), // Allow for clock drift
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": a.appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App token: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
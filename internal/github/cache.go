@@ -0,0 +1,89 @@
+package github
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// Cache stores responses of the GitHub API with their ETags. The client revalidates cached responses with
+// If-None-Match, and GitHub doesn't count requests answered with 304 Not Modified against the rate limit, so
+// repeated syncs and branch listings cost nothing while nothing changed.
+// Keys are derived from the token and path of a request, so a cache can be shared by every user and server instance.
+type Cache interface {
+	// Get returns the response cached under key
+	Get(ctx context.Context, key string) (*CachedResponse, bool)
+
+	// Set caches a response under key
+	Set(ctx context.Context, key string, response *CachedResponse)
+}
+
+// CachedResponse is a response of the GitHub API kept to be revalidated
+type CachedResponse struct {
+	ETag string `json:"etag"`
+	Link string `json:"link,omitempty"` // Pagination links of list responses
+	Body []byte `json:"body"`
+}
+
+// tokenKey identifies a token in cache keys and rate limits without keeping the token itself
+func tokenKey(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return hex.EncodeToString(sum[:16])
+}
+
+// MemoryCache is a Cache kept in memory, evicting the least recently used responses beyond its capacity
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List // Most recently used first
+}
+
+// memoryCacheEntry is a response in a MemoryCache
+type memoryCacheEntry struct {
+	key      string
+	response *CachedResponse
+}
+
+// NewMemoryCache creates a cache holding at most maxEntries responses
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get returns the response cached under key
+func (c *MemoryCache) Get(ctx context.Context, key string) (*CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*memoryCacheEntry).response, true
+}
+
+// Set caches a response under key, evicting the least recently used one if the cache is full
+func (c *MemoryCache) Set(ctx context.Context, key string, response *CachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*memoryCacheEntry).response = response
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryCacheEntry{key: key, response: response})
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
@@ -1,18 +1,30 @@
 package github
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
+// ErrNotFound is matched by errors of requests the GitHub API answered with 404
+var ErrNotFound = errors.New("not found on GitHub")
+
 // Client handles GitHub API interactions
 type Client struct {
 	httpClient *http.Client
 	baseURL    string
+	cache      Cache
+	limits     rateLimits
 }
 
 // NewClient creates a new GitHub API client
@@ -25,50 +37,540 @@ func NewClient() *Client {
 	}
 }
 
+// SetCache sets the cache GET responses are kept in and revalidated from (optional)
+func (c *Client) SetCache(cache Cache) {
+	c.cache = cache
+}
+
+// RateLimit returns the last request budget GitHub reported for a token, false if it hasn't reported one yet
+func (c *Client) RateLimit(accessToken string) (RateLimit, bool) {
+	return c.limits.get(tokenKey(accessToken))
+}
+
 // Repository represents a GitHub repository from the API
 type Repository struct {
-	ID              int64   `json:"id"`
-	Name            string  `json:"name"`
-	FullName        string  `json:"full_name"`
-	Description     *string `json:"description"`
-	URL             string  `json:"url"`
-	HTMLURL         string  `json:"html_url"`
-	Private         bool    `json:"private"`
-	Fork            bool    `json:"fork"`
-	StargazersCount int32   `json:"stargazers_count"`
-	WatchersCount   int32   `json:"watchers_count"`
-	ForksCount      int32   `json:"forks_count"`
-	DefaultBranch   string  `json:"default_branch"`
-	Language        *string `json:"language"`
-}
-
-// GetUserRepositories fetches repositories for a user using their GitHub access token
-func (c *Client) GetUserRepositories(ctx context.Context, accessToken string) ([]Repository, error) {
-	url := fmt.Sprintf("%s/user/repos?per_page=100&sort=updated", c.baseURL)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	ID              int64     `json:"id"`
+	Name            string    `json:"name"`
+	FullName        string    `json:"full_name"`
+	Description     *string   `json:"description"`
+	URL             string    `json:"url"`
+	HTMLURL         string    `json:"html_url"`
+	Private         bool      `json:"private"`
+	Fork            bool      `json:"fork"`
+	StargazersCount int32     `json:"stargazers_count"`
+	WatchersCount   int32     `json:"watchers_count"`
+	ForksCount      int32     `json:"forks_count"`
+	DefaultBranch   string    `json:"default_branch"`
+	Language        *string   `json:"language"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// repositoryPageSize is the number of repositories listed per page, the most GitHub allows
+const repositoryPageSize = 100
+
+// repositoryPageWorkers is the number of pages of repositories fetched at once
+const repositoryPageWorkers = 4
+
+// RepositoryList is the result of listing a user's repositories
+type RepositoryList struct {
+	Repositories []Repository
+	ETag         string // Validator of the list, sent back to find out cheaply whether it changed
+	NotModified  bool   // Whether the list hasn't changed since the ETag it was requested with
+}
+
+// ListUserRepositories fetches the repositories a user can access using their GitHub access token, most recently
+// updated first. The pages after the first are fetched concurrently.
+// A non-zero since lists only the repositories updated after it. A non-empty etag, returned by an earlier call,
+// skips the listing if nothing changed since; GitHub doesn't count such requests against the rate limit.
+func (c *Client) ListUserRepositories(ctx context.Context, accessToken string, since time.Time, etag string) (*RepositoryList, error) {
+	query := url.Values{
+		"per_page":  {strconv.Itoa(repositoryPageSize)},
+		"sort":      {"updated"},
+		"direction": {"desc"},
+	}
+
+	// The first page of the unfiltered list changes whenever any repository does, so its ETag stands for the whole list
+	first, err := c.getRepositoryPage(ctx, accessToken, query, 1, etag)
+	if err != nil {
+		return nil, err
+	}
+	if first.notModified {
+		return &RepositoryList{ETag: etag, NotModified: true}, nil
+	}
+
+	list := &RepositoryList{ETag: first.etag}
+	if since.IsZero() {
+		list.Repositories, err = c.getRemainingRepositoryPages(ctx, accessToken, query, first)
+		if err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+
+	// Recently updated repositories come first, usually all of them on the first page
+	for _, repository := range first.repositories {
+		if !repository.UpdatedAt.After(since) {
+			return list, nil
+		}
+		list.Repositories = append(list.Repositories, repository)
+	}
+	if first.lastPage <= 1 {
+		return list, nil
+	}
+
+	// More repositories changed than fit on a page: let GitHub filter the rest
+	query.Set("since", since.UTC().Format(time.RFC3339))
+	first, err = c.getRepositoryPage(ctx, accessToken, query, 1, "")
+	if err != nil {
+		return nil, err
+	}
+	list.Repositories, err = c.getRemainingRepositoryPages(ctx, accessToken, query, first)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// repositoryPage is a page of a user's repositories
+type repositoryPage struct {
+	repositories []Repository
+	etag         string
+	lastPage     int  // Number of the last page of the list
+	notModified  bool // Whether the page matched the ETag it was requested with
+}
+
+// getRepositoryPage fetches a page of a user's repositories, conditionally if etag is set
+func (c *Client) getRepositoryPage(ctx context.Context, accessToken string, query url.Values, page int, etag string) (*repositoryPage, error) {
+	pageQuery := url.Values{"page": {strconv.Itoa(page)}}
+	for key, values := range query {
+		pageQuery[key] = values
+	}
+
+	resp, err := c.fetch(ctx, accessToken, "/user/repos?"+pageQuery.Encode(), etag, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repositories: %w", err)
+	}
+	if etag != "" && resp.ETag == etag {
+		return &repositoryPage{etag: etag, lastPage: page, notModified: true}, nil
+	}
+
+	result := &repositoryPage{etag: resp.ETag, lastPage: lastPage(resp.Link, page)}
+	if err := json.Unmarshal(resp.Body, &result.repositories); err != nil {
+		return nil, fmt.Errorf("failed to decode repositories: %w", err)
+	}
+
+	return result, nil
+}
+
+// getRemainingRepositoryPages fetches the pages of a list of repositories after the first on a pool of workers,
+// and returns the repositories of every page in order
+func (c *Client) getRemainingRepositoryPages(ctx context.Context, accessToken string, query url.Values, first *repositoryPage) ([]Repository, error) {
+	pages := make([][]Repository, first.lastPage)
+	pages[0] = first.repositories
+
+	if first.lastPage > 1 {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		numbers := make(chan int)
+		errs := make(chan error, repositoryPageWorkers)
+		var wg sync.WaitGroup
+		for i := 0; i < min(repositoryPageWorkers, first.lastPage-1); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for number := range numbers {
+					page, err := c.getRepositoryPage(ctx, accessToken, query, number, "")
+					if err != nil {
+						// Stop the other workers, the list is incomplete anyway
+						errs <- err
+						cancel()
+						return
+					}
+					pages[number-1] = page.repositories
+				}
+			}()
+		}
+
+	feed:
+		for number := 2; number <= first.lastPage; number++ {
+			select {
+			case numbers <- number:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+		close(numbers)
+		wg.Wait()
+		close(errs)
+
+		if err := <-errs; err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	var repositories []Repository
+	for _, page := range pages {
+		repositories = append(repositories, page...)
+	}
+	return repositories, nil
+}
+
+// lastPage returns the number of the last page of a list from the Link header of one of its pages,
+// or the page itself if it is the only one or the last
+func lastPage(link string, page int) int {
+	for _, part := range strings.Split(link, ",") {
+		target, rel, ok := strings.Cut(part, ";")
+		if !ok || !strings.Contains(rel, `rel="last"`) {
+			continue
+		}
+
+		u, err := url.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+		if err != nil {
+			continue
+		}
+		if last, err := strconv.Atoi(u.Query().Get("page")); err == nil && last > page {
+			return last
+		}
+	}
+	return page
+}
+
+// Branch represents a GitHub branch from the API
+type Branch struct {
+	Name string `json:"name"`
+}
+
+// GetBranches fetches all branches of a repository
+func (c *Client) GetBranches(ctx context.Context, accessToken, owner, repo string) ([]Branch, error) {
+	var branches []Branch
+	for page := 1; ; page++ {
+		var resp []Branch
+		path := fmt.Sprintf("/repos/%s/%s/branches?per_page=100&page=%d", owner, repo, page)
+		if err := c.get(ctx, accessToken, path, &resp); err != nil {
+			return nil, fmt.Errorf("failed to list branches: %w", err)
+		}
+
+		branches = append(branches, resp...)
+		if len(resp) < 100 {
+			return branches, nil
+		}
+	}
+}
+
+// Commit represents a GitHub commit from the API
+type Commit struct {
+	SHA     string `json:"sha"`
+	HTMLURL string `json:"html_url"`
+	Commit  struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name string    `json:"name"`
+			Date time.Time `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+// GetCommits fetches the most recent commits on a branch (or the default branch if empty), newest first
+func (c *Client) GetCommits(ctx context.Context, accessToken, owner, repo, branch string, limit int) ([]Commit, error) {
+	query := url.Values{"per_page": {strconv.Itoa(limit)}}
+	if branch != "" {
+		query.Set("sha", branch)
+	}
+
+	var commits []Commit
+	path := fmt.Sprintf("/repos/%s/%s/commits?%s", owner, repo, query.Encode())
+	if err := c.get(ctx, accessToken, path, &commits); err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+	return commits, nil
+}
+
+// Comparison represents the comparison of two commits from the API
+type Comparison struct {
+	HTMLURL      string   `json:"html_url"`
+	TotalCommits int      `json:"total_commits"`
+	Commits      []Commit `json:"commits"` // Oldest first, at most 250
+}
+
+// CompareCommits fetches the commits reachable from head but not from base
+func (c *Client) CompareCommits(ctx context.Context, accessToken, owner, repo, base, head string) (*Comparison, error) {
+	var comparison Comparison
+	path := fmt.Sprintf("/repos/%s/%s/compare/%s...%s", owner, repo, url.PathEscape(base), url.PathEscape(head))
+	if err := c.get(ctx, accessToken, path, &comparison); err != nil {
+		return nil, fmt.Errorf("failed to compare commits: %w", err)
+	}
+	return &comparison, nil
+}
+
+// fileContent is a file returned by the contents API
+type fileContent struct {
+	Type     string `json:"type"`
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+}
+
+// GetFileContent fetches a file of a repository at a ref (or the default branch if empty)
+func (c *Client) GetFileContent(ctx context.Context, accessToken, owner, repo, ref, filePath string) ([]byte, error) {
+	path := fmt.Sprintf("/repos/%s/%s/contents/%s", owner, repo, (&url.URL{Path: filePath}).EscapedPath())
+	if ref != "" {
+		path += "?" + url.Values{"ref": {ref}}.Encode()
+	}
+
+	var file fileContent
+	if err := c.get(ctx, accessToken, path, &file); err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	if file.Type != "file" || file.Encoding != "base64" {
+		return nil, fmt.Errorf("%s is not a file", filePath)
+	}
+
+	return base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+}
+
+// Commit status states
+const (
+	StatePending = "pending"
+	StateSuccess = "success"
+	StateFailure = "failure"
+	StateError   = "error"
+)
+
+// Deployment status states (in addition to the commit status states)
+const (
+	StateQueued     = "queued"
+	StateInProgress = "in_progress"
+	StateInactive   = "inactive"
+)
+
+// CommitStatus is a status check shown on a commit and its pull requests
+type CommitStatus struct {
+	State       string `json:"state"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Context     string `json:"context"`
+}
+
+// DeploymentRequest creates a deployment of a ref to an environment
+type DeploymentRequest struct {
+	Ref                   string   `json:"ref"`
+	Environment           string   `json:"environment"`
+	Description           string   `json:"description,omitempty"`
+	AutoMerge             bool     `json:"auto_merge"`
+	RequiredContexts      []string `json:"required_contexts"`
+	ProductionEnvironment bool     `json:"production_environment"`
+}
+
+// DeploymentStatus is the state of a deployment shown on its environment
+type DeploymentStatus struct {
+	State          string `json:"state"`
+	EnvironmentURL string `json:"environment_url,omitempty"`
+	Description    string `json:"description,omitempty"`
+}
+
+// ParseRepositoryFullName extracts the owner and name from a GitHub repository URL
+// e.g. https://github.com/octocat/hello-world.git -> octocat, hello-world
+func ParseRepositoryFullName(repoURL string) (string, string, error) {
+	path := repoURL
+	if idx := strings.Index(path, "github.com"); idx != -1 {
+		path = path[idx+len("github.com"):]
+	} else {
+		return "", "", fmt.Errorf("not a GitHub repository URL: %s", repoURL)
+	}
+
+	path = strings.TrimPrefix(strings.TrimPrefix(path, ":"), "/")
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("not a GitHub repository URL: %s", repoURL)
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// CreateCommitStatus sets a status check on a commit
+func (c *Client) CreateCommitStatus(ctx context.Context, accessToken, owner, repo, sha string, status *CommitStatus) error {
+	path := fmt.Sprintf("/repos/%s/%s/statuses/%s", owner, repo, sha)
+	return c.post(ctx, accessToken, path, status, nil)
+}
+
+// CreateDeployment creates a deployment and returns its ID
+func (c *Client) CreateDeployment(ctx context.Context, accessToken, owner, repo string, req *DeploymentRequest) (int64, error) {
+	path := fmt.Sprintf("/repos/%s/%s/deployments", owner, repo)
+
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	if err := c.post(ctx, accessToken, path, req, &created); err != nil {
+		return 0, err
+	}
+	if created.ID == 0 {
+		return 0, fmt.Errorf("github API did not create a deployment")
+	}
+
+	return created.ID, nil
+}
+
+// CreateDeploymentStatus adds a status to a deployment
+func (c *Client) CreateDeploymentStatus(ctx context.Context, accessToken, owner, repo string, deploymentID int64, status *DeploymentStatus) error {
+	path := fmt.Sprintf("/repos/%s/%s/deployments/%d/statuses", owner, repo, deploymentID)
+	return c.post(ctx, accessToken, path, status, nil)
+}
+
+// GenerateRepositoryRequest creates a repository from a template repository
+type GenerateRepositoryRequest struct {
+	Owner       string `json:"owner,omitempty"` // Defaults to the user of the access token
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Private     bool   `json:"private"`
+}
+
+// GenerateRepository creates a repository with the files of a template repository, without its history
+func (c *Client) GenerateRepository(ctx context.Context, accessToken, templateOwner, templateRepo string, req *GenerateRepositoryRequest) (*Repository, error) {
+	path := fmt.Sprintf("/repos/%s/%s/generate", templateOwner, templateRepo)
+
+	var created Repository
+	if err := c.post(ctx, accessToken, path, req, &created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// post sends a JSON request to the GitHub API and decodes the response into out if set
+func (c *Client) post(ctx context.Context, accessToken, path string, body interface{}, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.send(req, accessToken, http.StatusCreated)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// get sends a GET request to the GitHub API and decodes the response into out, revalidating a cached response
+func (c *Client) get(ctx context.Context, accessToken, path string, out interface{}) error {
+	return c.getJSON(ctx, accessToken, path, out, true)
+}
+
+// getUncached sends a GET request to the GitHub API and decodes the response into out, for requests made with
+// short-lived tokens whose responses would never be read from the cache again
+func (c *Client) getUncached(ctx context.Context, accessToken, path string, out interface{}) error {
+	return c.getJSON(ctx, accessToken, path, out, false)
+}
+
+// getJSON sends a GET request to the GitHub API and decodes the response into out
+func (c *Client) getJSON(ctx context.Context, accessToken, path string, out interface{}, cached bool) error {
+	resp, err := c.fetch(ctx, accessToken, path, "", cached)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(resp.Body, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// fetch sends a GET request to the GitHub API and returns the response.
+// If cached is set and the client has a cache, a cached response is revalidated with its ETag and returned if
+// GitHub answers that it still matches, and fresh responses are cached. Otherwise a non-empty etag is sent instead,
+// and the response has no body if it still matches.
+func (c *Client) fetch(ctx context.Context, accessToken, path, etag string, cached bool) (*CachedResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	key := tokenKey(accessToken) + ":" + path
+	var hit *CachedResponse
+	if cached && c.cache != nil {
+		if response, ok := c.cache.Get(ctx, key); ok {
+			hit, etag = response, response.ETag
+		}
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := c.send(req, accessToken, http.StatusOK, http.StatusNotModified)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		if hit != nil {
+			return hit, nil
+		}
+		return &CachedResponse{ETag: etag}, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	response := &CachedResponse{ETag: resp.Header.Get("ETag"), Link: resp.Header.Get("Link"), Body: body}
+	if cached && c.cache != nil && response.ETag != "" {
+		c.cache.Set(ctx, key, response)
+	}
+	return response, nil
+}
+
+// send sends a request to the GitHub API with the token (none for public reads) and records the budget GitHub
+// reports with the response. Responses with a status other than the expected ones are returned as an *APIError.
+// Requests with a token known to have run out of its rate limit fail without being sent.
+func (c *Client) send(req *http.Request, accessToken string, expected ...int) (*http.Response, error) {
+	key := tokenKey(accessToken)
+	if limit, ok := c.limits.get(key); ok && limit.Exhausted(time.Now()) {
+		return nil, &APIError{StatusCode: http.StatusTooManyRequests, Message: "request not sent, the rate limit is exhausted", RateLimit: limit}
+	}
+
+	if accessToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	}
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch repositories: %w", err)
+		return nil, fmt.Errorf("failed to call github API: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("github API returned status %d: %s", resp.StatusCode, string(body))
+	limit, ok := parseRateLimit(resp.Header)
+	if ok {
+		c.limits.set(key, limit)
 	}
 
-	var repos []Repository
-	if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
-		return nil, fmt.Errorf("failed to decode repositories: %w", err)
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			return resp, nil
+		}
 	}
 
-	return repos, nil
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return nil, newAPIError(resp, body, limit)
 }
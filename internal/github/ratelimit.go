@@ -0,0 +1,136 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is matched by errors of requests refused because the token ran out of its GitHub rate limit
+var ErrRateLimited = errors.New("github API rate limit exceeded")
+
+// maxTrackedRateLimits is the number of tokens whose rate limit is remembered before the ones past their reset are forgotten
+const maxTrackedRateLimits = 1000
+
+// RateLimit is the request budget of a token GitHub reported with a response
+type RateLimit struct {
+	Limit     int       // Requests allowed per window
+	Remaining int       // Requests left in the current window
+	Reset     time.Time // When the window resets
+}
+
+// Exhausted reports whether no request is left in the window at now
+func (r RateLimit) Exhausted(now time.Time) bool {
+	return r.Limit > 0 && r.Remaining <= 0 && now.Before(r.Reset)
+}
+
+// String formats the budget for error messages
+func (r RateLimit) String() string {
+	return fmt.Sprintf("%d of %d requests left, resets at %s", r.Remaining, r.Limit, r.Reset.UTC().Format(time.RFC3339))
+}
+
+// parseRateLimit reads the rate limit headers of a response, returning false if it has none
+func parseRateLimit(header http.Header) (RateLimit, bool) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return RateLimit{}, false
+	}
+	return RateLimit{Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}, true
+}
+
+// APIError is an error response of the GitHub API, with the budget the token had left when it was returned
+type APIError struct {
+	StatusCode int
+	Message    string
+	RateLimit  RateLimit     // Zero if GitHub didn't report one
+	RetryAfter time.Duration // Wait GitHub asked for when hitting a secondary rate limit
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("github API returned status %d: %s", e.StatusCode, e.Message)
+	if e.RateLimit.Limit > 0 {
+		msg += fmt.Sprintf(" (rate limit: %s)", e.RateLimit)
+	}
+	return msg
+}
+
+// Is matches ErrNotFound for 404 responses and ErrRateLimited for requests refused by a rate limit
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.RateLimited()
+	}
+	return false
+}
+
+// RateLimited reports whether GitHub refused the request because of a primary or secondary rate limit
+func (e *APIError) RateLimited() bool {
+	if e.StatusCode != http.StatusForbidden && e.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	return e.RetryAfter > 0 || (e.RateLimit.Limit > 0 && e.RateLimit.Remaining <= 0)
+}
+
+// RetryAt returns when a request refused by a rate limit can be retried
+func (e *APIError) RetryAt(now time.Time) time.Time {
+	if e.RetryAfter > 0 {
+		return now.Add(e.RetryAfter)
+	}
+	return e.RateLimit.Reset
+}
+
+// newAPIError creates the error of a response with an unexpected status
+func newAPIError(resp *http.Response, body []byte, limit RateLimit) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: string(body), RateLimit: limit}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
+
+// rateLimits remembers the last budget GitHub reported for each token, by token key
+type rateLimits struct {
+	mu     sync.Mutex
+	limits map[string]RateLimit
+}
+
+// get returns the last budget reported for a token
+func (r *rateLimits) get(key string) (RateLimit, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	limit, ok := r.limits[key]
+	return limit, ok
+}
+
+// set records the budget reported for a token
+func (r *rateLimits) set(key string, limit RateLimit) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.limits == nil {
+		r.limits = make(map[string]RateLimit)
+	}
+	// Short-lived tokens would pile up otherwise; a budget past its reset says nothing anymore
+	if len(r.limits) >= maxTrackedRateLimits {
+		now := time.Now()
+		for k, l := range r.limits {
+			if !now.Before(l.Reset) {
+				delete(r.limits, k)
+			}
+		}
+	}
+	r.limits[key] = limit
+}
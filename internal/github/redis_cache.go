@@ -0,0 +1,64 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisCacheKeyPrefix namespaces the keys of cached responses in Redis
+const redisCacheKeyPrefix = "github:cache:"
+
+// RedisCache is a Cache in Redis, shared by every server instance.
+// Responses expire after a TTL; Redis errors are logged and treated as cache misses.
+type RedisCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisCache creates a cache in the Redis server at redisURL (e.g. redis://host:6379/0)
+// keeping responses for ttl
+func NewRedisCache(redisURL string, ttl time.Duration) (*RedisCache, error) {
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisCache{client: redis.NewClient(options), ttl: ttl}, nil
+}
+
+// Get returns the response cached under key
+func (c *RedisCache) Get(ctx context.Context, key string) (*CachedResponse, bool) {
+	data, err := c.client.Get(ctx, redisCacheKeyPrefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			slog.WarnContext(ctx, "Failed to read GitHub response from Redis", "error", err)
+		}
+		return nil, false
+	}
+
+	var response CachedResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, false
+	}
+	return &response, true
+}
+
+// Set caches a response under key
+func (c *RedisCache) Set(ctx context.Context, key string, response *CachedResponse) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	if err := c.client.Set(ctx, redisCacheKeyPrefix+key, data, c.ttl).Err(); err != nil {
+		slog.WarnContext(ctx, "Failed to cache GitHub response in Redis", "error", err)
+	}
+}
+
+// Close closes the connections to Redis
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,79 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// Webhook event names sent in the X-GitHub-Event header
+const (
+	EventPing         = "ping"
+	EventInstallation = "installation"
+	EventPush         = "push"
+)
+
+// Installation event actions
+const (
+	InstallationCreated     = "created"
+	InstallationDeleted     = "deleted"
+	InstallationSuspend     = "suspend"
+	InstallationUnsuspend   = "unsuspend"
+	InstallationPermissions = "new_permissions_accepted"
+)
+
+// ErrInvalidSignature is returned when a webhook payload was not signed with the webhook secret
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// InstallationEvent is sent when the app is installed, uninstalled, suspended or unsuspended
+type InstallationEvent struct {
+	Action       string       `json:"action"`
+	Installation Installation `json:"installation"`
+	Sender       Account      `json:"sender"` // The GitHub user who performed the action
+}
+
+// PushEvent is sent when commits are pushed to, or a branch or tag is created or deleted in, a repository the app
+// is installed on
+type PushEvent struct {
+	Ref        string `json:"ref"`     // Full ref pushed to, such as refs/heads/main
+	After      string `json:"after"`   // Commit the ref points to after the push
+	Deleted    bool   `json:"deleted"` // Whether the push deleted the ref
+	Repository struct {
+		FullName string `json:"full_name"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repository"`
+	Installation struct {
+		ID int64 `json:"id"`
+	} `json:"installation"`
+}
+
+// Branch returns the branch pushed to, and false if the push was to a tag
+func (e *PushEvent) Branch() (string, bool) {
+	return strings.CutPrefix(e.Ref, "refs/heads/")
+}
+
+// VerifyWebhookSignature checks the X-Hub-Signature-256 header of a webhook against its payload
+func VerifyWebhookSignature(secret string, payload []byte, signature string) error {
+	if secret == "" {
+		return ErrInvalidSignature
+	}
+
+	hexSignature, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return ErrInvalidSignature
+	}
+	received, err := hex.DecodeString(hexSignature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	if !hmac.Equal(received, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when the GitLab API responds with 404
+var ErrNotFound = errors.New("not found on GitLab")
+
+// Host is the GitLab instance repositories are cloned from
+const Host = "gitlab.com"
+
+// Client handles GitLab API interactions
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewClient creates a new GitLab API client
+func NewClient() *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		baseURL: "https://" + Host + "/api/v4",
+	}
+}
+
+// Project represents a GitLab project (repository) from the API
+type Project struct {
+	ID                int64           `json:"id"`
+	Name              string          `json:"name"`
+	PathWithNamespace string          `json:"path_with_namespace"`
+	Description       *string         `json:"description"`
+	WebURL            string          `json:"web_url"`
+	HTTPURLToRepo     string          `json:"http_url_to_repo"`
+	Visibility        string          `json:"visibility"`
+	ForkedFromProject json.RawMessage `json:"forked_from_project"`
+	StarCount         int32           `json:"star_count"`
+	ForksCount        int32           `json:"forks_count"`
+	DefaultBranch     string          `json:"default_branch"`
+}
+
+// IsPrivate reports whether the project is not publicly visible
+func (p Project) IsPrivate() bool {
+	return p.Visibility != "public"
+}
+
+// IsFork reports whether the project was forked from another project
+func (p Project) IsFork() bool {
+	return len(p.ForkedFromProject) > 0 && string(p.ForkedFromProject) != "null"
+}
+
+// Branch represents a GitLab branch from the API
+type Branch struct {
+	Name string `json:"name"`
+}
+
+// Commit represents a GitLab commit from the API
+type Commit struct {
+	ID            string    `json:"id"`
+	Title         string    `json:"title"`
+	Message       string    `json:"message"`
+	AuthorName    string    `json:"author_name"`
+	CommittedDate time.Time `json:"committed_date"`
+	WebURL        string    `json:"web_url"`
+}
+
+// GetUserProjects fetches the projects a user is a member of using their GitLab access token
+func (c *Client) GetUserProjects(ctx context.Context, accessToken string) ([]Project, error) {
+	var projects []Project
+	for page := 1; ; page++ {
+		var resp []Project
+		path := fmt.Sprintf("/projects?membership=true&order_by=last_activity_at&per_page=100&page=%d", page)
+		if err := c.get(ctx, accessToken, path, &resp); err != nil {
+			return nil, fmt.Errorf("failed to list projects: %w", err)
+		}
+
+		projects = append(projects, resp...)
+		if len(resp) < 100 {
+			return projects, nil
+		}
+	}
+}
+
+// GetBranches fetches all branches of a project, identified by its path (e.g. group/project)
+func (c *Client) GetBranches(ctx context.Context, accessToken, projectPath string) ([]Branch, error) {
+	var branches []Branch
+	for page := 1; ; page++ {
+		var resp []Branch
+		path := fmt.Sprintf("/projects/%s/repository/branches?per_page=100&page=%d", url.PathEscape(projectPath), page)
+		if err := c.get(ctx, accessToken, path, &resp); err != nil {
+			return nil, fmt.Errorf("failed to list branches: %w", err)
+		}
+
+		branches = append(branches, resp...)
+		if len(resp) < 100 {
+			return branches, nil
+		}
+	}
+}
+
+// GetCommits fetches the most recent commits on a branch (or the default branch if empty), newest first
+func (c *Client) GetCommits(ctx context.Context, accessToken, projectPath, branch string, limit int) ([]Commit, error) {
+	query := url.Values{"per_page": {strconv.Itoa(limit)}}
+	if branch != "" {
+		query.Set("ref_name", branch)
+	}
+
+	var commits []Commit
+	path := fmt.Sprintf("/projects/%s/repository/commits?%s", url.PathEscape(projectPath), query.Encode())
+	if err := c.get(ctx, accessToken, path, &commits); err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+	return commits, nil
+}
+
+// Comparison represents the comparison of two commits from the API
+type Comparison struct {
+	Commits []Commit `json:"commits"`
+	WebURL  string   `json:"web_url"`
+}
+
+// CompareCommits fetches the commits reachable from head but not from base
+func (c *Client) CompareCommits(ctx context.Context, accessToken, projectPath, base, head string) (*Comparison, error) {
+	var comparison Comparison
+	query := url.Values{"from": {base}, "to": {head}}
+	path := fmt.Sprintf("/projects/%s/repository/compare?%s", url.PathEscape(projectPath), query.Encode())
+	if err := c.get(ctx, accessToken, path, &comparison); err != nil {
+		return nil, fmt.Errorf("failed to compare commits: %w", err)
+	}
+	return &comparison, nil
+}
+
+// repositoryFile is a file returned by the repository files API
+type repositoryFile struct {
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+}
+
+// GetFileContent fetches a file of a project at a ref (or the default branch if empty)
+func (c *Client) GetFileContent(ctx context.Context, accessToken, projectPath, ref, filePath string) ([]byte, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	var file repositoryFile
+	path := fmt.Sprintf("/projects/%s/repository/files/%s?%s", url.PathEscape(projectPath), url.PathEscape(filePath), url.Values{"ref": {ref}}.Encode())
+	if err := c.get(ctx, accessToken, path, &file); err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	if file.Encoding != "base64" {
+		return nil, fmt.Errorf("unexpected encoding %q of %s", file.Encoding, filePath)
+	}
+
+	return base64.StdEncoding.DecodeString(file.Content)
+}
+
+// ParseProjectPath extracts the project path from a GitLab repository URL. Projects can be
+// nested in subgroups, e.g. https://gitlab.com/group/subgroup/project.git -> group/subgroup/project
+func ParseProjectPath(repoURL string) (string, error) {
+	path := repoURL
+	if idx := strings.Index(path, Host); idx != -1 {
+		path = path[idx+len(Host):]
+	} else {
+		return "", fmt.Errorf("not a GitLab repository URL: %s", repoURL)
+	}
+
+	path = strings.TrimPrefix(strings.TrimPrefix(path, ":"), "/")
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+
+	// Links to files and merge requests continue after /-/
+	if idx := strings.Index(path, "/-/"); idx != -1 {
+		path = path[:idx]
+	}
+
+	if strings.Count(path, "/") < 1 || strings.Contains(path, "//") {
+		return "", fmt.Errorf("not a GitLab repository URL: %s", repoURL)
+	}
+
+	return path, nil
+}
+
+// get sends a GET request to the GitLab API and decodes the response into out
+func (c *Client) get(ctx context.Context, accessToken, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Public projects are read without a token
+	if accessToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call gitlab API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("gitlab API returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
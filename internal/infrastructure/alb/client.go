@@ -3,9 +3,11 @@ package alb
 import (
 	"context"
 	"fmt"
-	"log"
-	"os"
-	"strconv"
+	"log/slog"
+	"sync"
+
+	"snapdeploy-core/internal/infrastructure/awsretry"
+	"snapdeploy-core/internal/tracing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -15,46 +17,50 @@ import (
 
 // ALBClient wraps AWS Application Load Balancer operations
 type ALBClient struct {
-	client      *elasticloadbalancingv2.Client
-	listenerArn string
-	vpcID       string
+	client          *elasticloadbalancingv2.Client
+	listenerArn     string
+	testListenerArn string // Optional listener that reaches the new version of a blue/green service before traffic shifts
+	vpcID           string
+	priorities      PriorityStore
+	createRuleMu    sync.Mutex // Creates rules one at a time when priorities aren't reserved in a store
 }
 
-// NewALBClient creates a new ALB client
-func NewALBClient() (*ALBClient, error) {
+// NewALBClient creates a new ALB client routing traffic from the listener to target groups in the VPC.
+// testListenerArn is optional.
+func NewALBClient(listenerArn, testListenerArn, vpcID string) (*ALBClient, error) {
 	cfg, err := config.LoadDefaultConfig(context.TODO())
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
+	tracing.InstrumentAWS(&cfg)
+	awsretry.Configure(&cfg, "ELB")
 
-	listenerArn := os.Getenv("ALB_LISTENER_ARN")
 	if listenerArn == "" {
-		return nil, fmt.Errorf("ALB_LISTENER_ARN environment variable is not set")
+		return nil, fmt.Errorf("ALB listener ARN is not set")
 	}
-
-	vpcID := os.Getenv("VPC_ID")
 	if vpcID == "" {
-		return nil, fmt.Errorf("VPC_ID environment variable is not set")
+		return nil, fmt.Errorf("VPC ID is not set")
 	}
 
 	return &ALBClient{
-		client:      elasticloadbalancingv2.NewFromConfig(cfg),
-		listenerArn: listenerArn,
-		vpcID:       vpcID,
+		client:          elasticloadbalancingv2.NewFromConfig(cfg),
+		listenerArn:     listenerArn,
+		testListenerArn: testListenerArn,
+		vpcID:           vpcID,
 	}, nil
 }
 
 // CreateTargetGroupAndRule creates a target group and listener rule for a deployment
-func (c *ALBClient) CreateTargetGroupAndRule(ctx context.Context, serviceName, customDomain, baseDomain string, containerPort int32) (string, error) {
+func (c *ALBClient) CreateTargetGroupAndRule(ctx context.Context, serviceName, customDomain, baseDomain string, containerPort int32, healthCheckPath string) (string, error) {
 	// Create target group
-	targetGroupArn, err := c.createTargetGroup(ctx, serviceName, containerPort)
+	targetGroupArn, err := c.createTargetGroup(ctx, serviceName, containerPort, healthCheckPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to create target group: %w", err)
 	}
 
 	// Create listener rule for the subdomain
 	fullDomain := fmt.Sprintf("%s.%s", customDomain, baseDomain)
-	if err := c.createListenerRule(ctx, fullDomain, targetGroupArn, serviceName); err != nil {
+	if err := c.createListenerRule(ctx, c.listenerArn, fullDomain, targetGroupArn, serviceName); err != nil {
 		// If rule creation fails, try to clean up target group
 		c.deleteTargetGroup(ctx, targetGroupArn)
 		return "", fmt.Errorf("failed to create listener rule: %w", err)
@@ -63,8 +69,8 @@ func (c *ALBClient) CreateTargetGroupAndRule(ctx context.Context, serviceName, c
 	return targetGroupArn, nil
 }
 
-// createTargetGroup creates or updates a target group for a service
-func (c *ALBClient) createTargetGroup(ctx context.Context, serviceName string, port int32) (string, error) {
+// createTargetGroup creates or updates a target group for a service, checking the health of its targets on a path
+func (c *ALBClient) createTargetGroup(ctx context.Context, serviceName string, port int32, healthCheckPath string) (string, error) {
 	// Check if target group already exists
 	existingGroups, err := c.findTargetGroupsByName(ctx, serviceName)
 	if err != nil {
@@ -78,17 +84,27 @@ func (c *ALBClient) createTargetGroup(ctx context.Context, serviceName string, p
 
 		if existingPort == port {
 			// Port matches, reuse existing target group
-			log.Printf("[ALB] Reusing existing target group %s with port %d", serviceName, port)
+			slog.DebugContext(ctx, "Reusing existing target group", "service", serviceName, "port", port)
+			if aws.ToString(existingTG.HealthCheckPath) != healthCheckPath {
+				_, err := c.client.ModifyTargetGroup(ctx, &elasticloadbalancingv2.ModifyTargetGroupInput{
+					TargetGroupArn:  existingTG.TargetGroupArn,
+					HealthCheckPath: aws.String(healthCheckPath),
+				})
+				if err != nil {
+					return "", fmt.Errorf("failed to update health check path: %w", err)
+				}
+				slog.InfoContext(ctx, "Updated target group health check", "service", serviceName, "path", healthCheckPath)
+			}
 			return *existingTG.TargetGroupArn, nil
 		}
 
 		// Port doesn't match, need to recreate with new port
 		// IMPORTANT: Must delete listener rules FIRST, then target group
-		log.Printf("[ALB] Target group %s exists with port %d, but need port %d. Recreating...", serviceName, existingPort, port)
+		slog.InfoContext(ctx, "Recreating target group for new port", "service", serviceName, "old_port", existingPort, "port", port)
 
 		// Step 1: Delete all listener rules using this target group
-		log.Printf("[ALB] Deleting listener rules for %s...", serviceName)
-		rules, err := c.findRulesByServiceName(ctx, serviceName)
+		slog.InfoContext(ctx, "Deleting listener rules", "service", serviceName)
+		rules, err := c.findRulesByServiceName(ctx, c.listenerArn, serviceName)
 		if err != nil {
 			return "", fmt.Errorf("failed to find listener rules: %w", err)
 		}
@@ -97,19 +113,19 @@ func (c *ALBClient) createTargetGroup(ctx context.Context, serviceName string, p
 			isDefault := rule.IsDefault != nil && *rule.IsDefault
 			if rule.RuleArn != nil && !isDefault {
 				if err := c.deleteListenerRule(ctx, *rule.RuleArn); err != nil {
-					log.Printf("[ALB] Warning: failed to delete listener rule: %v", err)
+					slog.WarnContext(ctx, "Failed to delete listener rule", "service", serviceName, "error", err)
 				} else {
-					log.Printf("[ALB] Deleted listener rule: %s", *rule.RuleArn)
+					slog.InfoContext(ctx, "Deleted listener rule", "rule_arn", *rule.RuleArn)
 				}
 			}
 		}
 
 		// Step 2: Now delete the target group
-		log.Printf("[ALB] Deleting old target group %s...", serviceName)
+		slog.InfoContext(ctx, "Deleting old target group", "service", serviceName)
 		if err := c.deleteTargetGroup(ctx, *existingTG.TargetGroupArn); err != nil {
 			return "", fmt.Errorf("failed to delete old target group: %w", err)
 		}
-		log.Printf("[ALB] Successfully deleted old target group")
+		slog.InfoContext(ctx, "Deleted old target group", "service", serviceName)
 	}
 
 	// Create new target group
@@ -120,7 +136,7 @@ func (c *ALBClient) createTargetGroup(ctx context.Context, serviceName string, p
 		VpcId:                      aws.String(c.vpcID),
 		TargetType:                 types.TargetTypeEnumIp,
 		HealthCheckEnabled:         aws.Bool(true),
-		HealthCheckPath:            aws.String("/"),
+		HealthCheckPath:            aws.String(healthCheckPath),
 		HealthCheckProtocol:        types.ProtocolEnumHttp,
 		HealthCheckIntervalSeconds: aws.Int32(30),
 		HealthCheckTimeoutSeconds:  aws.Int32(5),
@@ -140,14 +156,14 @@ func (c *ALBClient) createTargetGroup(ctx context.Context, serviceName string, p
 		return "", fmt.Errorf("no target group created")
 	}
 
-	log.Printf("[ALB] Created new target group %s with port %d", serviceName, port)
+	slog.InfoContext(ctx, "Created target group", "service", serviceName, "port", port)
 	return *result.TargetGroups[0].TargetGroupArn, nil
 }
 
 // createListenerRule creates or updates an ALB listener rule for host-based routing
-func (c *ALBClient) createListenerRule(ctx context.Context, hostHeader, targetGroupArn, serviceName string) error {
+func (c *ALBClient) createListenerRule(ctx context.Context, listenerArn, hostHeader, targetGroupArn, serviceName string) error {
 	// Check if a rule already exists for this service
-	existingRules, err := c.findRulesByServiceName(ctx, serviceName)
+	existingRules, err := c.findRulesByServiceName(ctx, listenerArn, serviceName)
 	if err != nil {
 		return fmt.Errorf("failed to check existing rules: %w", err)
 	}
@@ -172,22 +188,15 @@ func (c *ALBClient) createListenerRule(ctx context.Context, hostHeader, targetGr
 					return fmt.Errorf("failed to update listener rule: %w", err)
 				}
 
-				log.Printf("[ALB] Updated existing listener rule for %s", serviceName)
+				slog.InfoContext(ctx, "Updated listener rule", "service", serviceName)
 				return nil
 			}
 		}
 	}
 
-	// Find the next available priority
-	priority, err := c.findNextPriority(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to find available priority: %w", err)
-	}
-
 	// Create new rule
 	input := &elasticloadbalancingv2.CreateRuleInput{
-		ListenerArn: aws.String(c.listenerArn),
-		Priority:    aws.Int32(priority),
+		ListenerArn: aws.String(listenerArn),
 		Conditions: []types.RuleCondition{
 			{
 				Field: aws.String("host-header"),
@@ -214,51 +223,91 @@ func (c *ALBClient) createListenerRule(ctx context.Context, hostHeader, targetGr
 		},
 	}
 
-	_, err = c.client.CreateRule(ctx, input)
-	if err != nil {
-		return fmt.Errorf("failed to create listener rule: %w", err)
+	if c.priorities == nil {
+		c.createRuleMu.Lock()
+		defer c.createRuleMu.Unlock()
 	}
 
-	log.Printf("[ALB] Created new listener rule for %s", serviceName)
-	return nil
+	for attempt := 1; ; attempt++ {
+		priority, err := c.allocatePriority(ctx, listenerArn, serviceName)
+		if err != nil {
+			return fmt.Errorf("failed to allocate rule priority: %w", err)
+		}
+		input.Priority = aws.Int32(priority)
+
+		_, err = c.client.CreateRule(ctx, input)
+		if err == nil {
+			slog.InfoContext(ctx, "Created listener rule", "service", serviceName, "priority", priority)
+			return nil
+		}
+
+		// The reservation goes either way, a rule that took the priority since is skipped on the next attempt
+		if releaseErr := c.releasePriority(ctx, listenerArn, serviceName); releaseErr != nil {
+			slog.WarnContext(ctx, "Failed to release rule priority", "service", serviceName, "priority", priority, "error", releaseErr)
+		}
+		if !isPriorityInUse(err) || attempt == maxPriorityAttempts {
+			return fmt.Errorf("failed to create listener rule: %w", err)
+		}
+	}
 }
 
-// findNextPriority finds the next available priority for a listener rule
-func (c *ALBClient) findNextPriority(ctx context.Context) (int32, error) {
-	input := &elasticloadbalancingv2.DescribeRulesInput{
-		ListenerArn: aws.String(c.listenerArn),
+// DeleteTargetGroupAndRule deletes the target groups and listener rules for a service,
+// including those of a blue/green deployment
+func (c *ALBClient) DeleteTargetGroupAndRule(ctx context.Context, serviceName string) error {
+	// Rules go first, a target group can't be deleted while a rule forwards to it
+	if err := c.deleteRules(ctx, c.listenerArn, serviceName); err != nil {
+		return err
+	}
+	if c.testListenerArn != "" {
+		if err := c.deleteRules(ctx, c.testListenerArn, serviceName); err != nil {
+			return err
+		}
 	}
 
-	result, err := c.client.DescribeRules(ctx, input)
-	if err != nil {
-		return 0, err
+	return c.deleteTargetGroupsByName(ctx, serviceName, GreenTargetGroupName(serviceName), CanaryTargetGroupName(serviceName))
+}
+
+// DeleteBlueGreenRouting deletes what a service only needs for blue/green deployments: its rule on the
+// test listener and its green target group. The production rule must no longer forward to the green target group.
+func (c *ALBClient) DeleteBlueGreenRouting(ctx context.Context, serviceName string) error {
+	if c.testListenerArn != "" {
+		if err := c.deleteRules(ctx, c.testListenerArn, serviceName); err != nil {
+			return err
+		}
 	}
 
-	// Find the highest priority (priorities 1-50000, lower number = higher priority)
-	// We'll start from 1000 and go up to leave room for manual rules
-	maxPriority := int32(999)
-	for _, rule := range result.Rules {
-		if rule.Priority != nil {
-			if priority, err := strconv.Atoi(*rule.Priority); err == nil {
-				if int32(priority) > maxPriority && int32(priority) < 50000 {
-					maxPriority = int32(priority)
+	return c.deleteTargetGroupsByName(ctx, GreenTargetGroupName(serviceName))
+}
+
+// deleteTargetGroupsByName deletes the target groups with the given names
+func (c *ALBClient) deleteTargetGroupsByName(ctx context.Context, names ...string) error {
+	for _, name := range names {
+		targetGroups, err := c.findTargetGroupsByName(ctx, name)
+		if err != nil {
+			return fmt.Errorf("failed to find target groups: %w", err)
+		}
+
+		for _, tg := range targetGroups {
+			if tg.TargetGroupArn != nil {
+				err := c.deleteTargetGroup(ctx, *tg.TargetGroupArn)
+				if err != nil {
+					return fmt.Errorf("failed to delete target group: %w", err)
 				}
 			}
 		}
 	}
 
-	return maxPriority + 1, nil
+	return nil
 }
 
-// DeleteTargetGroupAndRule deletes the target group and listener rule for a service
-func (c *ALBClient) DeleteTargetGroupAndRule(ctx context.Context, serviceName string) error {
+// deleteRules deletes a service's rules on a listener
+func (c *ALBClient) deleteRules(ctx context.Context, listenerArn, serviceName string) error {
 	// Find listener rule by tags
-	rules, err := c.findRulesByServiceName(ctx, serviceName)
+	rules, err := c.findRulesByServiceName(ctx, listenerArn, serviceName)
 	if err != nil {
 		return fmt.Errorf("failed to find listener rules: %w", err)
 	}
 
-	// Delete listener rules
 	for _, rule := range rules {
 		// Skip default rule
 		isDefault := rule.IsDefault != nil && *rule.IsDefault
@@ -270,37 +319,22 @@ func (c *ALBClient) DeleteTargetGroupAndRule(ctx context.Context, serviceName st
 		}
 	}
 
-	// Find and delete target group
-	targetGroups, err := c.findTargetGroupsByName(ctx, serviceName)
-	if err != nil {
-		return fmt.Errorf("failed to find target groups: %w", err)
-	}
-
-	for _, tg := range targetGroups {
-		if tg.TargetGroupArn != nil {
-			err := c.deleteTargetGroup(ctx, *tg.TargetGroupArn)
-			if err != nil {
-				return fmt.Errorf("failed to delete target group: %w", err)
-			}
-		}
+	if err := c.releasePriority(ctx, listenerArn, serviceName); err != nil {
+		return fmt.Errorf("failed to release rule priority: %w", err)
 	}
 
 	return nil
 }
 
-// findRulesByServiceName finds listener rules by service name tag
-func (c *ALBClient) findRulesByServiceName(ctx context.Context, serviceName string) ([]types.Rule, error) {
-	input := &elasticloadbalancingv2.DescribeRulesInput{
-		ListenerArn: aws.String(c.listenerArn),
-	}
-
-	result, err := c.client.DescribeRules(ctx, input)
+// findRulesByServiceName finds rules on a listener by service name tag
+func (c *ALBClient) findRulesByServiceName(ctx context.Context, listenerArn, serviceName string) ([]types.Rule, error) {
+	rules, err := c.listRules(ctx, listenerArn)
 	if err != nil {
 		return nil, err
 	}
 
 	var matchingRules []types.Rule
-	for _, rule := range result.Rules {
+	for _, rule := range rules {
 		if rule.RuleArn != nil {
 			// Get tags for this rule
 			tagsInput := &elasticloadbalancingv2.DescribeTagsInput{
@@ -327,6 +361,188 @@ func (c *ALBClient) findRulesByServiceName(ctx context.Context, serviceName stri
 	return matchingRules, nil
 }
 
+// GreenTargetGroupName returns the name of the target group a blue/green service's replacement tasks
+// are registered with, alternating with the target group named after the service
+func GreenTargetGroupName(serviceName string) string {
+	return serviceName + "-green"
+}
+
+// CreateBlueGreenRouting creates the pair of target groups a blue/green service alternates between and routes
+// the subdomain to them on the production listener and, if one is configured, the test listener.
+// Rules that already forward to either target group are left alone since CodeDeploy moves them between the two.
+// Returns the ARN of the target group production traffic is routed to.
+func (c *ALBClient) CreateBlueGreenRouting(ctx context.Context, serviceName, customDomain, baseDomain string, containerPort int32, healthCheckPath string) (string, error) {
+	blueName, greenName := serviceName, GreenTargetGroupName(serviceName)
+
+	// Either target group can be live, so a new port means starting over with both
+	for _, name := range []string{blueName, greenName} {
+		groups, err := c.findTargetGroupsByName(ctx, name)
+		if err != nil {
+			return "", fmt.Errorf("failed to check existing target groups: %w", err)
+		}
+		if len(groups) > 0 && aws.ToInt32(groups[0].Port) != containerPort {
+			slog.InfoContext(ctx, "Recreating blue/green routing for new port", "service", serviceName, "port", containerPort)
+			if err := c.DeleteTargetGroupAndRule(ctx, serviceName); err != nil {
+				return "", fmt.Errorf("failed to delete old routing: %w", err)
+			}
+			break
+		}
+	}
+
+	blueArn, err := c.createTargetGroup(ctx, blueName, containerPort, healthCheckPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create blue target group: %w", err)
+	}
+	greenArn, err := c.createTargetGroup(ctx, greenName, containerPort, healthCheckPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create green target group: %w", err)
+	}
+
+	fullDomain := fmt.Sprintf("%s.%s", customDomain, baseDomain)
+	liveArn := blueArn
+	for _, listenerArn := range []string{c.listenerArn, c.testListenerArn} {
+		if listenerArn == "" {
+			continue
+		}
+
+		rules, err := c.findRulesByServiceName(ctx, listenerArn, serviceName)
+		if err != nil {
+			return "", fmt.Errorf("failed to check existing rules: %w", err)
+		}
+		if len(rules) > 0 && (forwardsTo(rules[0], blueArn) || forwardsTo(rules[0], greenArn)) {
+			if listenerArn == c.listenerArn && forwardsTo(rules[0], greenArn) {
+				liveArn = greenArn
+			}
+			continue
+		}
+
+		if err := c.createListenerRule(ctx, listenerArn, fullDomain, blueArn, serviceName); err != nil {
+			return "", fmt.Errorf("failed to create listener rule: %w", err)
+		}
+	}
+
+	return liveArn, nil
+}
+
+// CanaryTargetGroupName returns the name of the target group a service's canary is registered with
+func CanaryTargetGroupName(serviceName string) string {
+	return serviceName + "-canary"
+}
+
+// CreateCanaryTargetGroup creates or updates the target group of a service's canary
+func (c *ALBClient) CreateCanaryTargetGroup(ctx context.Context, serviceName string, containerPort int32, healthCheckPath string) (string, error) {
+	return c.createTargetGroup(ctx, CanaryTargetGroupName(serviceName), containerPort, healthCheckPath)
+}
+
+// SetCanaryWeight splits a service's production traffic between its own target group and its canary's,
+// sending the canary the given percentage. A percentage of 0 sends all traffic to the service's own target group.
+func (c *ALBClient) SetCanaryWeight(ctx context.Context, serviceName, targetGroupArn, canaryTargetGroupArn string, canaryPercent int32) error {
+	rules, err := c.findRulesByServiceName(ctx, c.listenerArn, serviceName)
+	if err != nil {
+		return fmt.Errorf("failed to find listener rules: %w", err)
+	}
+	if len(rules) == 0 || rules[0].RuleArn == nil {
+		return fmt.Errorf("no listener rule found for service %s", serviceName)
+	}
+
+	action := types.Action{
+		Type:           types.ActionTypeEnumForward,
+		TargetGroupArn: aws.String(targetGroupArn),
+	}
+	if canaryPercent > 0 {
+		action = types.Action{
+			Type: types.ActionTypeEnumForward,
+			ForwardConfig: &types.ForwardActionConfig{
+				TargetGroups: []types.TargetGroupTuple{
+					{TargetGroupArn: aws.String(targetGroupArn), Weight: aws.Int32(100 - canaryPercent)},
+					{TargetGroupArn: aws.String(canaryTargetGroupArn), Weight: aws.Int32(canaryPercent)},
+				},
+			},
+		}
+	}
+
+	_, err = c.client.ModifyRule(ctx, &elasticloadbalancingv2.ModifyRuleInput{
+		RuleArn: rules[0].RuleArn,
+		Actions: []types.Action{action},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update listener rule: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Updated canary traffic weight", "service", serviceName, "canary_percent", canaryPercent)
+	return nil
+}
+
+// forwardsTo reports whether a rule forwards to a target group
+func forwardsTo(rule types.Rule, targetGroupArn string) bool {
+	for _, action := range rule.Actions {
+		if aws.ToString(action.TargetGroupArn) == targetGroupArn {
+			return true
+		}
+		if action.ForwardConfig == nil {
+			continue
+		}
+		for _, tg := range action.ForwardConfig.TargetGroups {
+			if aws.ToString(tg.TargetGroupArn) == targetGroupArn {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FindTargetGroupARN returns the ARN of a service's target group, or an empty string if it doesn't exist
+func (c *ALBClient) FindTargetGroupARN(ctx context.Context, serviceName string) (string, error) {
+	groups, err := c.findTargetGroupsByName(ctx, serviceName)
+	if err != nil {
+		return "", err
+	}
+	if len(groups) == 0 {
+		return "", nil
+	}
+	return aws.ToString(groups[0].TargetGroupArn), nil
+}
+
+// Routing is how a service is currently routed on the load balancer
+type Routing struct {
+	TargetGroupExists bool
+	Port              int32
+	HealthCheckPath   string
+	RuleExists        bool
+}
+
+// DescribeRouting returns how a service is currently routed, without changing anything
+func (c *ALBClient) DescribeRouting(ctx context.Context, serviceName string) (*Routing, error) {
+	routing := &Routing{}
+	groups, err := c.findTargetGroupsByName(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) > 0 {
+		routing.TargetGroupExists = true
+		routing.Port = aws.ToInt32(groups[0].Port)
+		routing.HealthCheckPath = aws.ToString(groups[0].HealthCheckPath)
+	}
+
+	rules, err := c.findRulesByServiceName(ctx, c.listenerArn, serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find listener rules: %w", err)
+	}
+	routing.RuleExists = len(rules) > 0
+	return routing, nil
+}
+
+// ListenerARN returns the ARN of the listener that routes to deployments
+func (c *ALBClient) ListenerARN() string {
+	return c.listenerArn
+}
+
+// TestListenerARN returns the ARN of the listener that routes to the new version of a blue/green
+// service before traffic shifts, or an empty string if there is none
+func (c *ALBClient) TestListenerARN() string {
+	return c.testListenerArn
+}
+
 // findTargetGroupsByName finds target groups by name
 func (c *ALBClient) findTargetGroupsByName(ctx context.Context, name string) ([]types.TargetGroup, error) {
 	input := &elasticloadbalancingv2.DescribeTargetGroupsInput{
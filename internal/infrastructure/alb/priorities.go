@@ -0,0 +1,181 @@
+package alb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"snapdeploy-core/internal/infrastructure/quota"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+)
+
+const (
+	// minManagedPriority is the lowest priority given to the rules of services, lower ones are left for manual rules
+	minManagedPriority = 1000
+	// maxRulePriority is the highest priority a listener rule can have
+	maxRulePriority = 50000
+	// priorityHeadroomWarning is how few priorities can be left on a listener before allocating them warns operators
+	priorityHeadroomWarning = 2500
+	// maxPriorityAttempts bounds the priorities tried for a rule when concurrent deployments take them first
+	maxPriorityAttempts = 5
+)
+
+// PriorityStore keeps the table of the priorities allocated to the rules of services on listeners, shared by
+// every server instance. A priority is reserved before its rule is created, so concurrent deployments never
+// pick the same one, and released once the rule is deleted, so a later rule reuses it.
+type PriorityStore interface {
+	// FindPriority returns the priority allocated to a service's rule on a listener, 0 if it has none
+	FindPriority(ctx context.Context, listenerArn, serviceName string) (int32, error)
+
+	// AllocatedPriorities returns the priorities allocated on a listener
+	AllocatedPriorities(ctx context.Context, listenerArn string) ([]int32, error)
+
+	// ReservePriority allocates a priority to a service's rule. It returns the priority the service holds: this
+	// one, the one a concurrent deployment of the service reserved first, or 0 if another service took this one.
+	ReservePriority(ctx context.Context, listenerArn string, priority int32, serviceName string) (int32, error)
+
+	// ReleasePriority frees the priority allocated to a service's rule on a listener
+	ReleasePriority(ctx context.Context, listenerArn, serviceName string) error
+}
+
+// SetPriorityStore sets the table rule priorities are allocated from (optional). Without one, rules created by
+// this instance are created one at a time so they don't pick the same priority, but other instances may still.
+func (c *ALBClient) SetPriorityStore(store PriorityStore) {
+	c.priorities = store
+}
+
+// allocatePriority returns the lowest priority from minManagedPriority up that no rule on the listener has and
+// no other deployment has reserved, reserving it for the service's rule. A priority the service was allocated
+// without its rule being created is reused. It returns an error wrapping quota.ErrRulePrioritiesExhausted when
+// none are left.
+func (c *ALBClient) allocatePriority(ctx context.Context, listenerArn, serviceName string) (int32, error) {
+	inUse, err := c.rulePriorities(ctx, listenerArn)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list listener rules: %w", err)
+	}
+	if c.priorities == nil {
+		return lowestFreePriority(ctx, listenerArn, inUse)
+	}
+	return c.reservePriority(ctx, listenerArn, serviceName, inUse)
+}
+
+// reservePriority reserves the lowest priority that isn't in use and no other deployment has reserved for the
+// service's rule, retrying when a concurrent deployment takes it first
+func (c *ALBClient) reservePriority(ctx context.Context, listenerArn, serviceName string, inUse map[int32]bool) (int32, error) {
+	existing, err := c.priorities.FindPriority(ctx, listenerArn, serviceName)
+	if err != nil {
+		return 0, err
+	}
+	if existing != 0 {
+		if !inUse[existing] {
+			return existing, nil
+		}
+		// A rule created outside SnapDeploy took it since
+		if err := c.priorities.ReleasePriority(ctx, listenerArn, serviceName); err != nil {
+			return 0, err
+		}
+	}
+
+	for range maxPriorityAttempts {
+		allocated, err := c.priorities.AllocatedPriorities(ctx, listenerArn)
+		if err != nil {
+			return 0, err
+		}
+		for _, priority := range allocated {
+			inUse[priority] = true
+		}
+
+		priority, err := lowestFreePriority(ctx, listenerArn, inUse)
+		if err != nil {
+			return 0, err
+		}
+		held, err := c.priorities.ReservePriority(ctx, listenerArn, priority, serviceName)
+		if err != nil {
+			return 0, err
+		}
+		// A concurrent deployment of the service may have reserved another priority for it first
+		if held != 0 {
+			return held, nil
+		}
+		inUse[priority] = true
+	}
+
+	return 0, fmt.Errorf("priorities on listener %s kept being taken by concurrent deployments", listenerArn)
+}
+
+// releasePriority frees the priority allocated to a service's rule on a listener
+func (c *ALBClient) releasePriority(ctx context.Context, listenerArn, serviceName string) error {
+	if c.priorities == nil {
+		return nil
+	}
+	return c.priorities.ReleasePriority(ctx, listenerArn, serviceName)
+}
+
+// lowestFreePriority returns the lowest priority from minManagedPriority up that isn't in use, warning when few
+// are left
+func lowestFreePriority(ctx context.Context, listenerArn string, inUse map[int32]bool) (int32, error) {
+	var lowest int32
+	free := 0
+	for priority := int32(minManagedPriority); priority <= maxRulePriority; priority++ {
+		if inUse[priority] {
+			continue
+		}
+		if lowest == 0 {
+			lowest = priority
+		}
+		free++
+	}
+
+	if lowest == 0 {
+		return 0, fmt.Errorf("%w on listener %s", quota.ErrRulePrioritiesExhausted, listenerArn)
+	}
+	if free <= priorityHeadroomWarning {
+		slog.WarnContext(ctx, "Listener is running out of rule priorities", "listener_arn", listenerArn, "free", free)
+	}
+	return lowest, nil
+}
+
+// rulePriorities returns the priorities of the rules on a listener
+func (c *ALBClient) rulePriorities(ctx context.Context, listenerArn string) (map[int32]bool, error) {
+	rules, err := c.listRules(ctx, listenerArn)
+	if err != nil {
+		return nil, err
+	}
+
+	inUse := make(map[int32]bool, len(rules))
+	for _, rule := range rules {
+		// The default rule's priority is "default"
+		if priority, err := strconv.Atoi(aws.ToString(rule.Priority)); err == nil {
+			inUse[int32(priority)] = true
+		}
+	}
+	return inUse, nil
+}
+
+// listRules returns every rule on a listener
+func (c *ALBClient) listRules(ctx context.Context, listenerArn string) ([]types.Rule, error) {
+	paginator := elasticloadbalancingv2.NewDescribeRulesPaginator(c.client, &elasticloadbalancingv2.DescribeRulesInput{
+		ListenerArn: aws.String(listenerArn),
+	})
+
+	var rules []types.Rule
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, page.Rules...)
+	}
+	return rules, nil
+}
+
+// isPriorityInUse reports whether a rule couldn't be created because another rule has its priority
+func isPriorityInUse(err error) bool {
+	var inUse *types.PriorityInUseException
+	return errors.As(err, &inUse)
+}
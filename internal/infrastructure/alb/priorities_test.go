@@ -0,0 +1,221 @@
+package alb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"snapdeploy-core/internal/infrastructure/quota"
+)
+
+const testListenerArn = "arn:aws:elasticloadbalancing:us-east-1:123456789012:listener/app/snapdeploy/abc/def"
+
+// fakePriorityStore allocates priorities in memory, unique per listener like the table. beforeReserve runs
+// before every reservation, standing in for concurrent deployments.
+type fakePriorityStore struct {
+	allocated     map[int32]string // Service of each priority allocated on the listener
+	reservations  int
+	beforeReserve func(s *fakePriorityStore)
+}
+
+func newFakePriorityStore() *fakePriorityStore {
+	return &fakePriorityStore{allocated: make(map[int32]string)}
+}
+
+func (s *fakePriorityStore) FindPriority(ctx context.Context, listenerArn, serviceName string) (int32, error) {
+	for priority, service := range s.allocated {
+		if service == serviceName {
+			return priority, nil
+		}
+	}
+	return 0, nil
+}
+
+func (s *fakePriorityStore) AllocatedPriorities(ctx context.Context, listenerArn string) ([]int32, error) {
+	priorities := make([]int32, 0, len(s.allocated))
+	for priority := range s.allocated {
+		priorities = append(priorities, priority)
+	}
+	return priorities, nil
+}
+
+func (s *fakePriorityStore) ReservePriority(ctx context.Context, listenerArn string, priority int32, serviceName string) (int32, error) {
+	s.reservations++
+	if s.beforeReserve != nil {
+		s.beforeReserve(s)
+	}
+	held, _ := s.FindPriority(ctx, listenerArn, serviceName)
+	if _, taken := s.allocated[priority]; !taken && held == 0 {
+		s.allocated[priority] = serviceName
+		return priority, nil
+	}
+	return held, nil
+}
+
+func (s *fakePriorityStore) ReleasePriority(ctx context.Context, listenerArn, serviceName string) error {
+	for priority, service := range s.allocated {
+		if service == serviceName {
+			delete(s.allocated, priority)
+		}
+	}
+	return nil
+}
+
+// captureLogs sends the default logger's output to a buffer for the rest of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &logs
+}
+
+// usedPriorities returns the priorities from minManagedPriority up to last, in use
+func usedPriorities(last int32) map[int32]bool {
+	inUse := make(map[int32]bool)
+	for priority := int32(minManagedPriority); priority <= last; priority++ {
+		inUse[priority] = true
+	}
+	return inUse
+}
+
+func TestLowestFreePriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		inUse    map[int32]bool
+		want     int32
+		wantWarn bool
+	}{
+		{name: "empty listener", inUse: map[int32]bool{}, want: minManagedPriority},
+		{name: "manual rules below the managed range", inUse: map[int32]bool{1: true, 999: true}, want: minManagedPriority},
+		{name: "next after the rules", inUse: usedPriorities(1002), want: 1003},
+		{name: "released priority reused", inUse: map[int32]bool{1000: true, 1002: true}, want: 1001},
+		{name: "headroom left", inUse: usedPriorities(maxRulePriority - priorityHeadroomWarning - 1), want: maxRulePriority - priorityHeadroomWarning},
+		{name: "running out", inUse: usedPriorities(maxRulePriority - priorityHeadroomWarning), want: maxRulePriority - priorityHeadroomWarning + 1, wantWarn: true},
+		{name: "last one", inUse: usedPriorities(maxRulePriority - 1), want: maxRulePriority, wantWarn: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+
+			got, err := lowestFreePriority(context.Background(), testListenerArn, tt.inUse)
+			if err != nil {
+				t.Fatalf("lowestFreePriority() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("lowestFreePriority() = %d, want %d", got, tt.want)
+			}
+			if warned := strings.Contains(logs.String(), "running out of rule priorities"); warned != tt.wantWarn {
+				t.Errorf("warned = %v, want %v", warned, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestLowestFreePriority_Exhausted(t *testing.T) {
+	_, err := lowestFreePriority(context.Background(), testListenerArn, usedPriorities(maxRulePriority))
+	if !errors.Is(err, quota.ErrRulePrioritiesExhausted) {
+		t.Errorf("lowestFreePriority() error = %v, want %v", err, quota.ErrRulePrioritiesExhausted)
+	}
+}
+
+func TestALBClient_ReservePriority(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name             string
+		allocated        map[int32]string
+		inUse            map[int32]bool
+		beforeReserve    func(s *fakePriorityStore)
+		want             int32
+		wantReservations int
+	}{
+		{
+			name:             "skips rules and reservations",
+			allocated:        map[int32]string{1001: "other"},
+			inUse:            map[int32]bool{1000: true},
+			want:             1002,
+			wantReservations: 1,
+		},
+		{
+			name:      "reuses its reservation",
+			allocated: map[int32]string{1005: "web"},
+			want:      1005,
+		},
+		{
+			name:             "replaces a reservation a manual rule took",
+			allocated:        map[int32]string{1005: "web"},
+			inUse:            map[int32]bool{1005: true},
+			want:             1000,
+			wantReservations: 1,
+		},
+		{
+			name: "retries when another service takes the priority first",
+			beforeReserve: func(s *fakePriorityStore) {
+				if s.reservations == 1 {
+					s.allocated[1000] = "other"
+				}
+			},
+			want:             1001,
+			wantReservations: 2,
+		},
+		{
+			name: "reuses the priority a concurrent deployment of the service reserved",
+			beforeReserve: func(s *fakePriorityStore) {
+				s.allocated[1007] = "web"
+			},
+			want:             1007,
+			wantReservations: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakePriorityStore()
+			for priority, service := range tt.allocated {
+				store.allocated[priority] = service
+			}
+			store.beforeReserve = tt.beforeReserve
+			inUse := tt.inUse
+			if inUse == nil {
+				inUse = map[int32]bool{}
+			}
+			c := &ALBClient{priorities: store}
+
+			got, err := c.reservePriority(ctx, testListenerArn, "web", inUse)
+			if err != nil {
+				t.Fatalf("reservePriority() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("reservePriority() = %d, want %d", got, tt.want)
+			}
+			if store.allocated[got] != "web" {
+				t.Errorf("priority %d is allocated to %q, want web", got, store.allocated[got])
+			}
+			if store.reservations != tt.wantReservations {
+				t.Errorf("made %d reservations, want %d", store.reservations, tt.wantReservations)
+			}
+		})
+	}
+}
+
+func TestALBClient_ReservePriorityGivesUp(t *testing.T) {
+	store := newFakePriorityStore()
+	// Every priority tried is taken by another service first
+	store.beforeReserve = func(s *fakePriorityStore) {
+		s.allocated[int32(minManagedPriority+s.reservations-1)] = "other"
+	}
+	c := &ALBClient{priorities: store}
+
+	if _, err := c.reservePriority(context.Background(), testListenerArn, "web", map[int32]bool{}); err == nil {
+		t.Fatal("reservePriority() error = nil, want the reservations to give up")
+	}
+	if store.reservations != maxPriorityAttempts {
+		t.Errorf("made %d reservations, want %d", store.reservations, maxPriorityAttempts)
+	}
+}
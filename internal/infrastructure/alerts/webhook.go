@@ -0,0 +1,89 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultCooldown suppresses repeated alerts with the same key
+const defaultCooldown = 15 * time.Minute
+
+// WebhookAlerter sends operator alerts to a Slack-compatible incoming webhook.
+// Alerts are always logged; the webhook is optional.
+type WebhookAlerter struct {
+	webhookURL string
+	httpClient *http.Client
+	cooldown   time.Duration
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time
+}
+
+// NewWebhookAlerter creates a new webhook alerter
+func NewWebhookAlerter(webhookURL string) *WebhookAlerter {
+	return &WebhookAlerter{
+		webhookURL: webhookURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		cooldown:   defaultCooldown,
+		lastSent:   make(map[string]time.Time),
+	}
+}
+
+// Alert notifies operators. Alerts sharing a key are sent at most once per cooldown.
+func (a *WebhookAlerter) Alert(ctx context.Context, key, title, message string) {
+	if !a.shouldSend(key, time.Now()) {
+		return
+	}
+
+	slog.WarnContext(ctx, "Alert raised", "key", key, "title", title, "message", message)
+
+	if a.webhookURL == "" {
+		return
+	}
+
+	if err := a.post(ctx, fmt.Sprintf("*%s*\n%s", title, message)); err != nil {
+		slog.ErrorContext(ctx, "Failed to deliver alert", "key", key, "error", err)
+	}
+}
+
+// shouldSend records the alert and reports whether it is outside the cooldown
+func (a *WebhookAlerter) shouldSend(key string, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if last, ok := a.lastSent[key]; ok && now.Sub(last) < a.cooldown {
+		return false
+	}
+	a.lastSent[key] = now
+	return true
+}
+
+func (a *WebhookAlerter) post(ctx context.Context, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package awsretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+)
+
+const (
+	// maxAttempts bounds the attempts of a call, the first one included
+	maxAttempts = 6
+	// maxBackoff bounds the jittered exponential delay between attempts
+	maxBackoff = 20 * time.Second
+	// breakerThreshold is the number of consecutive failed attempts after which a service's calls are paused
+	breakerThreshold = 10
+	// breakerCooldown is how long calls are paused before one is let through to check the service recovered
+	breakerCooldown = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned without calling AWS while calls to a service that keeps failing are paused
+var ErrCircuitOpen = errors.New("AWS service keeps failing, calls are paused")
+
+// reporterKey is the context key of the function retries are reported to
+type reporterKey struct{}
+
+// WithReporter returns a context whose AWS calls pass a message to report before each retry, e.g. to add it
+// to the logs of the deployment making them
+func WithReporter(ctx context.Context, report func(message string)) context.Context {
+	return context.WithValue(ctx, reporterKey{}, report)
+}
+
+// Configure makes the clients created from cfg retry throttling and transient failures with jittered
+// exponential backoff. Once a service keeps failing, its calls are paused for a while rather than piling up
+// retries; name identifies the service in logs and errors.
+func Configure(cfg *aws.Config, name string) {
+	b := &breaker{name: name, now: time.Now}
+	cfg.Retryer = func() aws.Retryer {
+		return &retryer{
+			RetryerV2: retry.NewStandard(func(o *retry.StandardOptions) {
+				o.MaxAttempts = maxAttempts
+				o.MaxBackoff = maxBackoff
+				// The SDK's retry quota fails throttled calls early under load, the breaker bounds retries instead
+				o.RateLimiter = ratelimit.None
+			}),
+			breaker: b,
+		}
+	}
+}
+
+// retryer is the SDK's standard retryer, reporting retries and gated by the breaker of its service
+type retryer struct {
+	aws.RetryerV2
+	breaker *breaker
+}
+
+// GetAttemptToken fails while the service's calls are paused, and records the outcome of each attempt
+func (r *retryer) GetAttemptToken(ctx context.Context) (func(error) error, error) {
+	if err := r.breaker.allow(); err != nil {
+		return nil, err
+	}
+
+	release, err := r.RetryerV2.GetAttemptToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(opErr error) error {
+		// Errors that aren't retried, such as validation errors, show the service is up
+		r.breaker.record(opErr == nil || !r.IsErrorRetryable(opErr))
+		return release(opErr)
+	}, nil
+}
+
+// GetRetryToken reports a retry to the context's reporter, if any, before it is made
+func (r *retryer) GetRetryToken(ctx context.Context, opErr error) (func(error) error, error) {
+	if report, ok := ctx.Value(reporterKey{}).(func(string)); ok {
+		report(fmt.Sprintf("AWS %s %s failed with %s, retrying",
+			awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx), errorCode(opErr)))
+	}
+	return r.RetryerV2.GetRetryToken(ctx, opErr)
+}
+
+// errorCode returns the AWS error code of a failed attempt, such as ThrottlingException
+func errorCode(err error) string {
+	var apiErr interface{ ErrorCode() string }
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return "a connection error"
+}
+
+// breaker pauses the calls to a service after too many consecutive failed attempts
+type breaker struct {
+	name      string
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool             // Whether the attempt checking the service recovered is in progress
+	now       func() time.Time // Tests replace it to end cooldowns
+}
+
+// allow returns ErrCircuitOpen while calls are paused. Once the cooldown is over a single attempt is let
+// through, and calls resume if it succeeds.
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < breakerThreshold {
+		return nil
+	}
+	if b.probing || b.now().Before(b.openUntil) {
+		return fmt.Errorf("%s: %w", b.name, ErrCircuitOpen)
+	}
+	b.probing = true
+	return nil
+}
+
+// record counts an attempt's outcome, pausing calls when failures reach the threshold
+func (b *breaker) record(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if ok {
+		if b.failures >= breakerThreshold {
+			slog.Info("AWS service recovered, resuming calls", "service", b.name)
+		}
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= breakerThreshold {
+		if b.failures == breakerThreshold {
+			slog.Warn("AWS service keeps failing, pausing calls", "service", b.name, "cooldown", breakerCooldown.String())
+		}
+		b.openUntil = b.now().Add(breakerCooldown)
+	}
+}
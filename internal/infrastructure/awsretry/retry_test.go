@@ -0,0 +1,152 @@
+package awsretry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+)
+
+// fakeClock is a clock tests move by hand
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// openBreaker returns a breaker whose calls were just paused
+func openBreaker(t *testing.T) (*breaker, *fakeClock) {
+	t.Helper()
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := &breaker{name: "ecs", now: clock.Now}
+	for i := 0; i < breakerThreshold; i++ {
+		if err := b.allow(); err != nil {
+			t.Fatalf("allow() after %d failures error = %v", i, err)
+		}
+		b.record(false)
+	}
+	return b, clock
+}
+
+func TestBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := &breaker{name: "ecs", now: clock.Now}
+
+	// A success in between starts the count over
+	for i := 0; i < breakerThreshold-1; i++ {
+		b.record(false)
+	}
+	b.record(true)
+	for i := 0; i < breakerThreshold-1; i++ {
+		b.record(false)
+	}
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() error = %v below the threshold", err)
+	}
+
+	b.record(false)
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("allow() error = %v at the threshold, want ErrCircuitOpen", err)
+	}
+	clock.Advance(breakerCooldown - time.Second)
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("allow() error = %v before the cooldown is over, want ErrCircuitOpen", err)
+	}
+}
+
+func TestBreaker_LetsASingleProbeThrough(t *testing.T) {
+	b, clock := openBreaker(t)
+	clock.Advance(breakerCooldown)
+
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() error = %v after the cooldown, want a probe let through", err)
+	}
+	// Calls wait for the probe's outcome, however long it takes
+	for _, wait := range []time.Duration{0, breakerCooldown} {
+		clock.Advance(wait)
+		if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("allow() error = %v while probing, want ErrCircuitOpen", err)
+		}
+	}
+}
+
+func TestBreaker_ClosesWhenProbeSucceeds(t *testing.T) {
+	b, clock := openBreaker(t)
+	clock.Advance(breakerCooldown)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() error = %v, want a probe let through", err)
+	}
+
+	b.record(true)
+	for i := 0; i < 3; i++ {
+		if err := b.allow(); err != nil {
+			t.Errorf("allow() error = %v after the probe succeeded, want calls resumed", err)
+		}
+	}
+	// The failures were forgotten, a single one doesn't pause calls again
+	b.record(false)
+	if err := b.allow(); err != nil {
+		t.Errorf("allow() error = %v after one failure, want calls allowed", err)
+	}
+}
+
+func TestBreaker_ReopensWhenProbeFails(t *testing.T) {
+	b, clock := openBreaker(t)
+	clock.Advance(breakerCooldown)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() error = %v, want a probe let through", err)
+	}
+
+	b.record(false)
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() error = %v after the probe failed, want ErrCircuitOpen", err)
+	}
+	// Calls are paused for another cooldown from the probe's failure
+	clock.Advance(breakerCooldown - time.Second)
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("allow() error = %v during the new cooldown, want ErrCircuitOpen", err)
+	}
+	clock.Advance(time.Second)
+	if err := b.allow(); err != nil {
+		t.Errorf("allow() error = %v after the new cooldown, want another probe let through", err)
+	}
+}
+
+func TestRetryer_CountsNonRetryableErrorsAsSuccesses(t *testing.T) {
+	b, clock := openBreaker(t)
+	clock.Advance(breakerCooldown)
+	r := &retryer{RetryerV2: retry.NewStandard(), breaker: b}
+
+	release, err := r.GetAttemptToken(context.Background())
+	if err != nil {
+		t.Fatalf("GetAttemptToken() error = %v, want a probe let through", err)
+	}
+	if _, err := r.GetAttemptToken(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("GetAttemptToken() error = %v while probing, want ErrCircuitOpen", err)
+	}
+
+	// An error that isn't retried, such as a validation error, shows the service is up
+	_ = release(errors.New("invalid task definition"))
+	if _, err := r.GetAttemptToken(context.Background()); err != nil {
+		t.Errorf("GetAttemptToken() error = %v after a validation error, want calls resumed", err)
+	}
+}
+
+func TestConfigure_SharesBreakerAcrossRetryers(t *testing.T) {
+	cfg := aws.Config{}
+	Configure(&cfg, "ecs")
+
+	first, second := cfg.Retryer().(*retryer), cfg.Retryer().(*retryer)
+	if first.breaker != second.breaker {
+		t.Error("retryers of one service have breakers of their own, want them to share one")
+	}
+}
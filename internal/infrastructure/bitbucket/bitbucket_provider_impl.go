@@ -0,0 +1,166 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"snapdeploy-core/internal/bitbucket"
+	"snapdeploy-core/internal/domain/repo"
+)
+
+// compareCommitLimit bounds the commits fetched when comparing two commits, the largest page Bitbucket returns
+const compareCommitLimit = 100
+
+// BitbucketProviderImpl implements the domain repo.GitProvider interface for Bitbucket Cloud
+type BitbucketProviderImpl struct {
+	client *bitbucket.Client
+}
+
+// NewBitbucketProvider creates a new Bitbucket provider implementation
+func NewBitbucketProvider(client *bitbucket.Client) repo.GitProvider {
+	return &BitbucketProviderImpl{client: client}
+}
+
+// Provider returns repo.ProviderBitbucket
+func (b *BitbucketProviderImpl) Provider() repo.Provider {
+	return repo.ProviderBitbucket
+}
+
+// FetchUserRepositories fetches the repositories a user is a member of from Bitbucket
+func (b *BitbucketProviderImpl) FetchUserRepositories(ctx context.Context, accessToken string) ([]*repo.RemoteRepository, error) {
+	bbRepos, err := b.client.GetUserRepositories(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repositories from Bitbucket: %w", err)
+	}
+
+	domainRepos := make([]*repo.RemoteRepository, len(bbRepos))
+	for i, bbRepo := range bbRepos {
+		var description, language *string
+		if bbRepo.Description != "" {
+			description = &bbRepo.Description
+		}
+		if bbRepo.Language != "" {
+			language = &bbRepo.Language
+		}
+
+		var defaultBranch string
+		if bbRepo.MainBranch != nil {
+			defaultBranch = bbRepo.MainBranch.Name
+		}
+
+		domainRepos[i] = &repo.RemoteRepository{
+			ID:            bbRepo.UUID,
+			Name:          bbRepo.Name,
+			FullName:      bbRepo.FullName,
+			Description:   description,
+			URL:           bbRepo.Links.HTML.Href,
+			HTMLURL:       bbRepo.Links.HTML.Href,
+			Private:       bbRepo.IsPrivate,
+			Fork:          bbRepo.IsFork(),
+			DefaultBranch: defaultBranch,
+			Language:      language,
+		}
+	}
+
+	return domainRepos, nil
+}
+
+// FetchBranches fetches the branch names of a Bitbucket repository
+func (b *BitbucketProviderImpl) FetchBranches(ctx context.Context, accessToken, fullName string) ([]string, error) {
+	branches, err := b.client.GetBranches(ctx, accessToken, fullName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch branches from Bitbucket: %w", err)
+	}
+
+	names := make([]string, len(branches))
+	for i, branch := range branches {
+		names[i] = branch.Name
+	}
+	return names, nil
+}
+
+// FetchCommits fetches the most recent commits on a branch of a Bitbucket repository
+func (b *BitbucketProviderImpl) FetchCommits(ctx context.Context, accessToken, fullName, branch string, limit int) ([]*repo.Commit, error) {
+	commits, err := b.client.GetCommits(ctx, accessToken, fullName, branch, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch commits from Bitbucket: %w", err)
+	}
+
+	domainCommits := make([]*repo.Commit, len(commits))
+	for i, commit := range commits {
+		domainCommits[i] = &repo.Commit{
+			SHA:         commit.Hash,
+			Message:     commit.Message,
+			AuthorName:  commit.AuthorName(),
+			CommittedAt: commit.Date,
+			HTMLURL:     commit.Links.HTML.Href,
+		}
+	}
+	return domainCommits, nil
+}
+
+// FetchFile fetches a file of a Bitbucket repository at a ref
+func (b *BitbucketProviderImpl) FetchFile(ctx context.Context, accessToken, repositoryURL, ref, path string) ([]byte, error) {
+	workspace, slug, err := bitbucket.ParseRepositoryFullName(repositoryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := b.client.GetFileContent(ctx, accessToken, workspace+"/"+slug, ref, path)
+	if errors.Is(err, bitbucket.ErrNotFound) {
+		return nil, repo.ErrFileNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s from Bitbucket: %w", path, err)
+	}
+	return content, nil
+}
+
+// CompareCommits fetches the commits between two commits of a Bitbucket repository
+func (b *BitbucketProviderImpl) CompareCommits(ctx context.Context, accessToken, repositoryURL, base, head string) (*repo.CommitComparison, error) {
+	workspace, slug, err := bitbucket.ParseRepositoryFullName(repositoryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, more, err := b.client.CompareCommits(ctx, accessToken, workspace+"/"+slug, base, head, compareCommitLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare commits on Bitbucket: %w", err)
+	}
+
+	comparison := &repo.CommitComparison{
+		Commits:   make([]*repo.Commit, len(commits)),
+		Truncated: more,
+	}
+	for i, commit := range commits {
+		comparison.Commits[i] = &repo.Commit{
+			SHA:         commit.Hash,
+			Message:     commit.Message,
+			AuthorName:  commit.AuthorName(),
+			CommittedAt: commit.Date,
+			HTMLURL:     commit.Links.HTML.Href,
+		}
+	}
+	return comparison, nil
+}
+
+// CloneCredentials returns the credentials for cloning a repository with an OAuth token
+func (b *BitbucketProviderImpl) CloneCredentials(repositoryURL, accessToken string) (*repo.CloneCredentials, error) {
+	workspace, slug, err := bitbucket.ParseRepositoryFullName(repositoryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	cloneURL := &url.URL{
+		Scheme: "https",
+		Host:   bitbucket.Host,
+		Path:   fmt.Sprintf("/%s/%s.git", workspace, slug),
+	}
+	return &repo.CloneCredentials{
+		URL:      cloneURL.String(),
+		Username: "x-token-auth",
+		Token:    accessToken,
+	}, nil
+}
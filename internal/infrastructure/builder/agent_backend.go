@@ -0,0 +1,464 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/repo"
+)
+
+// agentQueueTimeout is how long a build may wait for an agent before it is given up. Once claimed, it may run
+// on the agent for its project's build timeout.
+const agentQueueTimeout = time.Hour
+
+var (
+	// ErrNoQueuedBuild is returned when no build was queued for agents before the claim gave up waiting
+	ErrNoQueuedBuild = errors.New("no build queued")
+	// ErrBuildNotFound is returned for builds that were never started or have already been cleaned up
+	ErrBuildNotFound = errors.New("build not found")
+	// ErrBuildNotClaimed is returned when an agent reports the outcome of a build no agent has claimed
+	ErrBuildNotClaimed = errors.New("build has not been claimed by an agent")
+	// ErrBuildFinished is returned when an agent reports the outcome of a build that was stopped or timed out
+	ErrBuildFinished = errors.New("build has already finished")
+	// ErrBuildClaimedByOtherAgent is returned when an agent reports on a build another agent claimed
+	ErrBuildClaimedByOtherAgent = errors.New("build was claimed by another agent")
+)
+
+// PlatformCredential identifies agents presenting the platform's AGENT_TOKEN
+const PlatformCredential = "platform"
+
+// Agent is a build agent, identified by the credential it authenticated with. Agents presenting the same
+// credential can report on each other's builds.
+type Agent struct {
+	Name       string
+	Credential string // PlatformCredential, or the ID of the agent token the agent presented
+	UserID     string // User whose builds agents presenting an agent token run
+	Platform   bool   // Whether the agent presented AGENT_TOKEN, running every user's builds like the server
+}
+
+// mayRun reports whether the agent is handed the builds of a project
+func (a Agent) mayRun(proj *project.Project) bool {
+	return a.Platform || (a.UserID != "" && proj.UserID().String() == a.UserID)
+}
+
+// AgentBuild is a build handed to a self-hosted build agent, with everything needed to run it without the database
+type AgentBuild struct {
+	ID               string
+	Request          BuildRequest
+	CloneCredentials *repo.CloneCredentials // nil for public repositories
+}
+
+// AgentBackend queues builds for self-hosted build agents, which claim them over the gRPC API, run them
+// on their own hardware and push their output and outcome back. Agents presenting a user's agent token are only
+// handed the builds of that user's projects; agents presenting the platform's AGENT_TOKEN see the source and
+// build variables of every project and have to be trusted like the server.
+type AgentBackend struct {
+	deploymentRepo     deployment.DeploymentRepository
+	projectRepo        project.ProjectRepository
+	sseManager         SSEBroadcaster
+	deploymentCallback DeploymentCallback
+	cloneCredentials   CloneCredentialsProvider
+	tracker            *BuildTracker
+
+	mu     sync.Mutex
+	queue  []string // IDs of builds waiting for an agent, oldest first
+	builds map[string]*agentBuild
+	queued chan struct{} // closed and replaced whenever a build is queued, waking waiting agents
+}
+
+var _ BuildBackend = (*AgentBackend)(nil)
+
+// agentBuild is a build waiting for or running on an agent
+type agentBuild struct {
+	build   AgentBuild
+	status  BuildStatus
+	agent   *Agent // agent that claimed the build, nil while it is queued
+	reason  string // why the agent failed the build
+	claimed chan struct{}
+	done    chan struct{}
+}
+
+// NewAgentBackend creates a new backend handing builds to self-hosted agents
+func NewAgentBackend(deploymentRepo deployment.DeploymentRepository, projectRepo project.ProjectRepository) *AgentBackend {
+	return &AgentBackend{
+		deploymentRepo: deploymentRepo,
+		projectRepo:    projectRepo,
+		tracker:        NewBuildTracker(),
+		builds:         make(map[string]*agentBuild),
+		queued:         make(chan struct{}),
+	}
+}
+
+// SetSSEManager sets the SSE manager for real-time log streaming
+func (s *AgentBackend) SetSSEManager(manager interface{}) {
+	if m, ok := manager.(SSEBroadcaster); ok {
+		s.sseManager = m
+	}
+}
+
+// SetDeploymentCallback sets the callback to be invoked after successful build
+func (s *AgentBackend) SetDeploymentCallback(callback DeploymentCallback) {
+	s.deploymentCallback = callback
+}
+
+// SetCloneCredentialsProvider sets the provider of credentials used to clone private repositories
+func (s *AgentBackend) SetCloneCredentialsProvider(provider CloneCredentialsProvider) {
+	s.cloneCredentials = provider
+}
+
+// StartBuild queues a deployment's build until an agent claims it.
+// Builds run on the agents' hardware, so they are not metered as build minutes.
+func (s *AgentBackend) StartBuild(ctx context.Context, req BuildRequest) (string, error) {
+	dep := req.Deployment
+	proj := req.Project
+
+	// Update status to BUILDING
+	if err := dep.UpdateStatus(deployment.StatusBuilding); err != nil {
+		return "", fmt.Errorf("failed to update status: %w", err)
+	}
+	if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+		return "", fmt.Errorf("failed to save deployment: %w", err)
+	}
+
+	// Private repositories are cloned with credentials from the repository's provider, handed to the agent
+	var creds *repo.CloneCredentials
+	if s.cloneCredentials != nil {
+		var err error
+		creds, err = s.cloneCredentials.GetCloneCredentials(ctx, proj)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to get clone credentials", "project_id", proj.ID().String(), "error", err)
+			s.logAndUpdate(ctx, dep.ID(), "⚠️ Could not get access to the repository, cloning without credentials")
+			creds = nil
+		}
+	}
+
+	buildID := fmt.Sprintf("agent-%s-%d", dep.ID().String(), time.Now().Unix())
+	build := &agentBuild{
+		build: AgentBuild{
+			ID:               buildID,
+			Request:          req,
+			CloneCredentials: creds,
+		},
+		status:  BuildInProgress,
+		claimed: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	s.logAndUpdate(ctx, dep.ID(), "⏳ Waiting for a build agent...")
+
+	s.mu.Lock()
+	s.builds[buildID] = build
+	s.queue = append(s.queue, buildID)
+	close(s.queued)
+	s.queued = make(chan struct{})
+	s.mu.Unlock()
+
+	// Start monitoring build status in background, until the build is over or a shutdown interrupts it
+	followCtx, done := s.tracker.Follow(ctx)
+	go func() {
+		defer done()
+		s.monitorBuild(followCtx, dep.ID(), proj.ID(), req.ImageTag, buildID)
+	}()
+
+	return buildID, nil
+}
+
+// Shutdown waits for the builds handed to agents to finish until ctx is done, then stops following the rest.
+// Their agents can no longer report them to this instance, so they are built again.
+func (s *AgentBackend) Shutdown(ctx context.Context) []deployment.DeploymentID {
+	return s.tracker.Shutdown(ctx)
+}
+
+// Claim hands the oldest queued build the agent may run to it, waiting for one to be queued until the context
+// is done. Returns ErrNoQueuedBuild when none was queued in time.
+func (s *AgentBackend) Claim(ctx context.Context, agent Agent) (*AgentBuild, error) {
+	for {
+		s.mu.Lock()
+		for i, id := range s.queue {
+			build := s.builds[id]
+			if !agent.mayRun(build.build.Request.Project) {
+				continue
+			}
+			s.queue = append(s.queue[:i:i], s.queue[i+1:]...)
+			build.agent = &agent
+			close(build.claimed)
+			claimed := build.build
+			s.mu.Unlock()
+
+			s.logAndUpdate(ctx, claimed.Request.Deployment.ID(), fmt.Sprintf("🛠️ Build picked up by agent %s", agent.Name))
+			return &claimed, nil
+		}
+		queued := s.queued
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ErrNoQueuedBuild
+		case <-queued:
+		}
+	}
+}
+
+// Complete records the outcome an agent reports for a build it claimed
+func (s *AgentBackend) Complete(ctx context.Context, agent Agent, buildID string, succeeded bool, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	build, ok := s.builds[buildID]
+	if !ok {
+		return ErrBuildNotFound
+	}
+	if err := build.claimedBy(agent); err != nil {
+		return err
+	}
+	if build.status.Done() {
+		return ErrBuildFinished
+	}
+
+	build.status = BuildFailed
+	if succeeded {
+		build.status = BuildSucceeded
+	}
+	build.reason = reason
+	close(build.done)
+	return nil
+}
+
+// AuthorizeReport checks an agent may report the status and push the logs of a deployment: only the agent that
+// claimed its build may while it runs. Deployments built elsewhere can only be reported on by platform agents.
+func (s *AgentBackend) AuthorizeReport(agent Agent, deploymentID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, build := range s.builds {
+		if build.build.Request.Deployment.ID().String() == deploymentID && !build.status.Done() {
+			return build.claimedBy(agent)
+		}
+	}
+	if !agent.Platform {
+		return ErrBuildNotFound
+	}
+	return nil
+}
+
+// claimedBy checks the build was claimed by an agent presenting the same credential as agent
+func (b *agentBuild) claimedBy(agent Agent) error {
+	if b.agent == nil {
+		return ErrBuildNotClaimed
+	}
+	if b.agent.Credential != agent.Credential {
+		return ErrBuildClaimedByOtherAgent
+	}
+	return nil
+}
+
+// StreamLogs waits for the build to finish. Agents push their output through the log ingestion API,
+// so no lines are passed on here.
+func (s *AgentBackend) StreamLogs(ctx context.Context, buildID string, onLines func(lines []string)) error {
+	build, err := s.build(buildID)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-build.done:
+		return nil
+	}
+}
+
+// Cancel stops a build. A queued build is taken off the queue; an agent running the build
+// is told it was stopped when it reports the outcome.
+func (s *AgentBackend) Cancel(ctx context.Context, buildID string) error {
+	return s.finish(buildID, BuildStopped)
+}
+
+// Status reports where a build is at
+func (s *AgentBackend) Status(ctx context.Context, buildID string) (BuildStatus, error) {
+	build, err := s.build(buildID)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return build.status, nil
+}
+
+// build looks up a build started by this instance
+func (s *AgentBackend) build(buildID string) (*agentBuild, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	build, ok := s.builds[buildID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrBuildNotFound, buildID)
+	}
+	return build, nil
+}
+
+// finish ends a build that hasn't finished yet with the given status, taking it off the queue
+func (s *AgentBackend) finish(buildID string, status BuildStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	build, ok := s.builds[buildID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrBuildNotFound, buildID)
+	}
+	if build.status.Done() {
+		return nil
+	}
+
+	for i, id := range s.queue {
+		if id == buildID {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			break
+		}
+	}
+	build.status = status
+	close(build.done)
+	return nil
+}
+
+// monitorBuild waits for the build to finish and updates the deployment accordingly.
+// The deployment is reloaded, since agents append to its logs while the build runs.
+func (s *AgentBackend) monitorBuild(ctx context.Context, deploymentID deployment.DeploymentID, projectID project.ProjectID, imageTag, buildID string) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.builds, buildID)
+		s.mu.Unlock()
+	}()
+
+	build, err := s.build(buildID)
+	if err != nil {
+		return
+	}
+
+	// The build waits for an agent until the queue timeout, then runs on it until the project's build timeout
+	buildTimeout := build.build.Request.Project.BuildTimeout()
+	limit := time.NewTimer(agentQueueTimeout)
+	defer limit.Stop()
+	claimed := build.claimed
+	for waiting := true; waiting; {
+		select {
+		case <-build.done:
+			waiting = false
+		case <-claimed:
+			claimed = nil
+			limit.Reset(buildTimeout)
+		case <-limit.C:
+			s.finish(buildID, BuildTimedOut)
+			waiting = false
+		case <-ctx.Done():
+			s.finish(buildID, BuildStopped)
+			waiting = false
+		}
+	}
+
+	if s.tracker.Interrupted() {
+		s.checkpoint(ctx, deploymentID)
+		return
+	}
+
+	s.mu.Lock()
+	status, reason := build.status, build.reason
+	agent := ""
+	if build.agent != nil {
+		agent = build.agent.Name
+	}
+	s.mu.Unlock()
+
+	dep, err := s.deploymentRepo.FindByID(ctx, deploymentID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to find deployment", "deployment_id", deploymentID.String(), "error", err)
+		return
+	}
+
+	if status != BuildSucceeded {
+		message := fmt.Sprintf("❌ Build failed with status: %s", status)
+		switch {
+		case status == BuildTimedOut && agent == "":
+			message = "❌ No build agent picked up the build"
+		case status == BuildTimedOut:
+			message = TimedOutMessage(buildTimeout)
+		case reason != "":
+			message = fmt.Sprintf("❌ Build failed on agent %s: %s", agent, reason)
+		}
+		s.appendLogs(ctx, dep, []string{message})
+		dep.UpdateStatus(deployment.StatusFailed)
+		s.deploymentRepo.Save(ctx, dep)
+		return
+	}
+
+	s.appendLogs(ctx, dep, []string{"✅ Build completed successfully!", "📦 Image pushed to registry successfully"})
+
+	// Fetch fresh project data to ensure we have the latest configuration
+	proj, err := s.projectRepo.FindByID(ctx, projectID)
+	if err != nil {
+		s.appendLogs(ctx, dep, []string{fmt.Sprintf("❌ Failed to fetch project data: %v", err)})
+		dep.UpdateStatus(deployment.StatusFailed)
+		s.deploymentRepo.Save(ctx, dep)
+		return
+	}
+
+	if s.deploymentCallback != nil {
+		s.appendLogs(ctx, dep, []string{"🚀 Triggering deployment to ECS..."})
+		if err := s.deploymentCallback.OnBuildSuccess(ctx, dep, proj, imageTag); err != nil {
+			s.appendLogs(ctx, dep, []string{fmt.Sprintf("❌ Deployment to ECS failed: %v", err)})
+			dep.UpdateStatus(deployment.StatusFailed)
+		}
+		// Note: status will be updated to DEPLOYED by the deployment callback
+	} else {
+		dep.UpdateStatus(deployment.StatusDeployed)
+	}
+
+	s.deploymentRepo.Save(ctx, dep)
+}
+
+// appendLogs appends lines to the deployment logs and broadcasts them, saving once for the whole batch
+func (s *AgentBackend) appendLogs(ctx context.Context, dep *deployment.Deployment, lines []string) {
+	for _, line := range lines {
+		dep.AppendLog(line)
+		if s.sseManager != nil {
+			s.sseManager.BroadcastLog(dep.ID().String(), dep.RedactLog(line))
+		}
+	}
+
+	s.deploymentRepo.Save(ctx, dep)
+}
+
+// checkpoint records that a shutdown interrupted the deployment's build, so the next build worker starts it over
+func (s *AgentBackend) checkpoint(ctx context.Context, deploymentID deployment.DeploymentID) {
+	saveCtx, cancel := s.tracker.Checkpoint(ctx, deploymentID)
+	defer cancel()
+
+	dep, err := s.deploymentRepo.FindByID(saveCtx, deploymentID)
+	if err != nil {
+		slog.ErrorContext(saveCtx, "Failed to find deployment", "deployment_id", deploymentID.String(), "error", err)
+		return
+	}
+	if err := dep.Interrupt(); err != nil {
+		slog.ErrorContext(saveCtx, "Failed to interrupt deployment", "deployment_id", deploymentID.String(), "error", err)
+		return
+	}
+	s.appendLogs(saveCtx, dep, []string{InterruptedMessage})
+}
+
+// logAndUpdate reloads a deployment and appends a message to its logs.
+// Agents append to the logs of the builds they run, so the deployment is never saved from a stale copy.
+func (s *AgentBackend) logAndUpdate(ctx context.Context, deploymentID deployment.DeploymentID, message string) {
+	dep, err := s.deploymentRepo.FindByID(ctx, deploymentID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to find deployment", "deployment_id", deploymentID.String(), "error", err)
+		return
+	}
+	s.appendLogs(ctx, dep, []string{message})
+}
@@ -0,0 +1,122 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+)
+
+// unknownDeployments is a deployment repository without deployments, so claims log nothing
+type unknownDeployments struct {
+	deployment.DeploymentRepository
+}
+
+func (unknownDeployments) FindByID(ctx context.Context, id deployment.DeploymentID) (*deployment.Deployment, error) {
+	return nil, deployment.ErrDeploymentNotFound
+}
+
+// queueBuild queues a build of a project of owner, as StartBuild does without running it
+func queueBuild(t *testing.T, s *AgentBackend, owner user.UserID, buildID string) *agentBuild {
+	t.Helper()
+	proj, err := project.NewProject(owner, "https://github.com/acme/app", "npm ci", "npm run build", "npm start", "NODE", "", false, "")
+	if err != nil {
+		t.Fatalf("NewProject() error = %v", err)
+	}
+	dep, err := deployment.NewDeployment(proj.ID(), owner, "abc123def456", "main", project.EnvironmentProduction)
+	if err != nil {
+		t.Fatalf("NewDeployment() error = %v", err)
+	}
+
+	build := &agentBuild{
+		build:   AgentBuild{ID: buildID, Request: BuildRequest{Project: proj, Deployment: dep}},
+		status:  BuildInProgress,
+		claimed: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	s.builds[buildID] = build
+	s.queue = append(s.queue, buildID)
+	return build
+}
+
+func TestAgentBackend_ClaimOnlyHandsOutTheUsersBuilds(t *testing.T) {
+	s := NewAgentBackend(unknownDeployments{}, nil)
+	alice, bob := user.NewUserID(), user.NewUserID()
+	queueBuild(t, s, alice, "alice-build")
+	queueBuild(t, s, bob, "bob-build")
+
+	bobsAgent := Agent{Name: "bob-01", Credential: "bob-token", UserID: bob.String()}
+	claimed, err := s.Claim(context.Background(), bobsAgent)
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if claimed.ID != "bob-build" {
+		t.Errorf("Claim() = %s, want the build of the agent's user", claimed.ID)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.Claim(ctx, bobsAgent); !errors.Is(err, ErrNoQueuedBuild) {
+		t.Errorf("Claim() error = %v, want ErrNoQueuedBuild with only another user's build queued", err)
+	}
+
+	claimed, err = s.Claim(context.Background(), Agent{Name: "shared", Credential: PlatformCredential, Platform: true})
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if claimed.ID != "alice-build" {
+		t.Errorf("Claim() = %s, want platform agents handed any build", claimed.ID)
+	}
+}
+
+func TestAgentBackend_OnlyTheClaimingAgentReports(t *testing.T) {
+	s := NewAgentBackend(unknownDeployments{}, nil)
+	owner := user.NewUserID()
+	build := queueBuild(t, s, owner, "build")
+	deploymentID := build.build.Request.Deployment.ID().String()
+
+	claimant := Agent{Name: "build-01", Credential: "token-1", UserID: owner.String()}
+	other := Agent{Name: "build-02", Credential: "token-2", UserID: owner.String()}
+	platform := Agent{Credential: PlatformCredential, Platform: true}
+
+	if err := s.AuthorizeReport(claimant, deploymentID); !errors.Is(err, ErrBuildNotClaimed) {
+		t.Errorf("AuthorizeReport() before the claim error = %v, want ErrBuildNotClaimed", err)
+	}
+	if _, err := s.Claim(context.Background(), claimant); err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+
+	if err := s.AuthorizeReport(claimant, deploymentID); err != nil {
+		t.Errorf("AuthorizeReport() by the claimant error = %v", err)
+	}
+	for name, agent := range map[string]Agent{"another agent token": other, "the platform token": platform} {
+		if err := s.AuthorizeReport(agent, deploymentID); !errors.Is(err, ErrBuildClaimedByOtherAgent) {
+			t.Errorf("AuthorizeReport() with %s error = %v, want ErrBuildClaimedByOtherAgent", name, err)
+		}
+		if err := s.Complete(context.Background(), agent, "build", true, ""); !errors.Is(err, ErrBuildClaimedByOtherAgent) {
+			t.Errorf("Complete() with %s error = %v, want ErrBuildClaimedByOtherAgent", name, err)
+		}
+	}
+
+	if err := s.Complete(context.Background(), claimant, "build", true, ""); err != nil {
+		t.Fatalf("Complete() by the claimant error = %v", err)
+	}
+	if build.status != BuildSucceeded {
+		t.Errorf("status = %s, want %s", build.status, BuildSucceeded)
+	}
+}
+
+func TestAgentBackend_AuthorizeReportOfUntrackedDeployment(t *testing.T) {
+	s := NewAgentBackend(unknownDeployments{}, nil)
+	deploymentID := deployment.NewDeploymentID().String()
+
+	if err := s.AuthorizeReport(Agent{Credential: "token", UserID: user.NewUserID().String()}, deploymentID); !errors.Is(err, ErrBuildNotFound) {
+		t.Errorf("AuthorizeReport() with an agent token error = %v, want ErrBuildNotFound", err)
+	}
+	if err := s.AuthorizeReport(Agent{Credential: PlatformCredential, Platform: true}, deploymentID); err != nil {
+		t.Errorf("AuthorizeReport() with the platform token error = %v, want deployments built elsewhere reported on", err)
+	}
+}
@@ -0,0 +1,102 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/repo"
+)
+
+// Build backends selectable with BUILD_BACKEND
+const (
+	BackendCodeBuild = "codebuild"
+	BackendDocker    = "docker"
+	BackendAgent     = "agent"
+)
+
+// BuildBackend builds deployment images. CodeBuild, local Docker and self-hosted agent builds are interchangeable behind it.
+type BuildBackend interface {
+	// StartBuild starts building a deployment's image and returns the build ID. The backend
+	// moves the deployment through the build and hands the image to the deployment callback.
+	StartBuild(ctx context.Context, req BuildRequest) (string, error)
+	// StreamLogs passes the build's output to onLines as it is written and returns once the build has finished
+	StreamLogs(ctx context.Context, buildID string, onLines func(lines []string)) error
+	// Cancel stops a running build
+	Cancel(ctx context.Context, buildID string) error
+	// Status reports where a build is at
+	Status(ctx context.Context, buildID string) (BuildStatus, error)
+	// Shutdown gives the builds followed by this instance until ctx is done to finish, then interrupts the
+	// rest. Returns the deployments whose build was interrupted.
+	Shutdown(ctx context.Context) []deployment.DeploymentID
+}
+
+// BuildRequest contains all information needed to build a deployment
+type BuildRequest struct {
+	Deployment    *deployment.Deployment
+	Project       *project.Project
+	RepositoryURL string
+	Branch        string
+	CommitHash    string
+	ImageTag      string
+	Dockerfile    string
+	BuildArgs     map[string]string // Build-scoped environment variables, passed to the Dockerfile as build args
+	CPUs          int               // vCPUs the docker build may use, 0 for no limit
+	MemoryMiB     int               // Memory in MiB the docker build may use, 0 for no limit
+}
+
+// TimedOutMessage is logged when a build runs longer than its project's build timeout
+func TimedOutMessage(timeout time.Duration) string {
+	return fmt.Sprintf("⏱️ Build timed out after %d minutes and was stopped. Speed up the build or raise the project's build_timeout_minutes.",
+		int(timeout.Minutes()))
+}
+
+// BuildArgNames returns the names of build args in a stable order
+func BuildArgNames(args map[string]string) []string {
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BuildStatus is where a build is at
+type BuildStatus string
+
+const (
+	BuildInProgress BuildStatus = "IN_PROGRESS"
+	BuildSucceeded  BuildStatus = "SUCCEEDED"
+	BuildFailed     BuildStatus = "FAILED"
+	BuildStopped    BuildStatus = "STOPPED"
+	BuildTimedOut   BuildStatus = "TIMED_OUT"
+)
+
+// Done reports whether the build has finished, successfully or not
+func (s BuildStatus) Done() bool {
+	return s != BuildInProgress
+}
+
+// SSEBroadcaster interface for broadcasting logs (avoid circular dependency)
+type SSEBroadcaster interface {
+	BroadcastLog(deploymentID string, logLine string)
+}
+
+// DeploymentCallback is called after a successful build to trigger deployment
+type DeploymentCallback interface {
+	OnBuildSuccess(ctx context.Context, dep *deployment.Deployment, proj *project.Project, imageURI string) error
+}
+
+// UsageRecorder records build minutes consumed by a deployment
+type UsageRecorder interface {
+	RecordBuild(ctx context.Context, dep *deployment.Deployment, startedAt, endedAt time.Time) error
+}
+
+// CloneCredentialsProvider resolves the credentials used to clone private repositories.
+// Nil credentials mean the repository is cloned without credentials.
+type CloneCredentialsProvider interface {
+	GetCloneCredentials(ctx context.Context, proj *project.Project) (*repo.CloneCredentials, error)
+}
@@ -0,0 +1,209 @@
+package builder
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"snapdeploy-core/internal/domain/repo"
+)
+
+const (
+	// cacheDirName is the directory of the workspace the caches of repositories are kept in
+	cacheDirName = "cache"
+
+	// cacheExpiry is how long the cache of a repository is kept without a build using it
+	cacheExpiry = 7 * 24 * time.Hour
+)
+
+// cloneRepository clones the repository of a build into dir/repo and checks out the build's commit, returning the
+// directory of the clone. Clones are blobless: only the files of the commit built are downloaded, not every version
+// of them, while the whole history of the branch is there, so any commit of the branch can be checked out and
+// commits off it are fetched by themselves. With the clone cache of the workspace, the history is borrowed from a
+// cache of the repository each build brings up to date, so builds after the first only fetch what was pushed since.
+func cloneRepository(ctx context.Context, workspace *Workspace, dir string, req BuildRequest, creds *repo.CloneCredentials, onLine func(line string)) (string, error) {
+	cloneURL := req.RepositoryURL
+	var gitArgs, gitEnv []string
+	if creds != nil {
+		cloneURL = creds.URL
+		gitArgs = []string{"-c", "credential.helper=" + gitCredentialHelper}
+		gitEnv = []string{"GIT_CLONE_USERNAME=" + creds.Username, "GIT_CLONE_TOKEN=" + creds.Token}
+	}
+	git := func(dir string, args ...string) error {
+		return runStep(ctx, onLine, dir, gitEnv, "git", append(append([]string(nil), gitArgs...), args...)...)
+	}
+
+	detached := req.CommitHash != "" && req.CommitHash != "HEAD"
+	cloneArgs := []string{"clone", "--filter=blob:none", "--branch", req.Branch}
+	if detached {
+		// Checking out the branch first would download files the build doesn't use
+		cloneArgs = append(cloneArgs, "--no-checkout")
+	}
+	if owner, ok := cacheOwner(req, creds); ok {
+		if cache, ok := workspace.updateCache(dir, owner, req.RepositoryURL, cloneURL, req.Branch, git, onLine); ok {
+			cloneArgs = append(cloneArgs, "--reference", cache)
+		}
+	}
+
+	onLine("Cloning repository...")
+	if err := git(dir, append(cloneArgs, cloneURL, "repo")...); err != nil {
+		return "", fmt.Errorf("failed to clone repository: %w", err)
+	}
+	repoDir := filepath.Join(dir, "repo")
+	if !detached {
+		return repoDir, nil
+	}
+
+	onLine(fmt.Sprintf("Checking out commit %s", req.CommitHash))
+	if !hasCommit(ctx, repoDir, req.CommitHash) {
+		if err := git(repoDir, "fetch", "--filter=blob:none", "origin", req.CommitHash); err != nil {
+			return "", fmt.Errorf("failed to fetch commit %s, which isn't on branch %s: %w", req.CommitHash, req.Branch, err)
+		}
+	}
+	// The files of the commit are downloaded as they are checked out, with the credentials of the clone
+	if err := git(repoDir, "checkout", "--detach", req.CommitHash); err != nil {
+		return "", fmt.Errorf("failed to check out commit: %w", err)
+	}
+	return repoDir, nil
+}
+
+// hasCommit reports whether a clone has a commit already
+func hasCommit(ctx context.Context, repoDir, commit string) bool {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", commit+"^{commit}")
+	cmd.Dir = repoDir
+	return cmd.Run() == nil
+}
+
+// SetCloneCache sets whether the history of repositories is cached in the workspace for the next builds
+// of the same repository
+func (w *Workspace) SetCloneCache(enabled bool) {
+	w.cloneCache = enabled
+}
+
+// cacheOwner returns whose cache of the repository a build uses. Each owner has caches of their own, so objects
+// fetched with one owner's credentials are never served to the builds of another. Builds of self-hosted agents
+// don't know their owner, they only use the cache for public repositories.
+func cacheOwner(req BuildRequest, creds *repo.CloneCredentials) (string, bool) {
+	if req.Project != nil {
+		return req.Project.UserID().String(), true
+	}
+	return "", creds == nil
+}
+
+// repoCache guards the cache of a repository
+type repoCache struct {
+	update sync.Mutex   // Builds take turns updating the cache
+	use    sync.RWMutex // Held for reading by the builds borrowing the cache's objects, for writing to remove it
+}
+
+// updateCache brings the owner's cache of a repository up to date with a branch, creating the cache for the
+// repository's first build, and returns its directory. The cache is a bare, blobless clone that keeps the history
+// of the branches built. The build in dir borrows its objects until its directory is released: the cache is never
+// garbage collected, nor removed while builds borrow from it, so their clones can count on its objects. Builds go
+// on without the cache when it can't be updated.
+func (w *Workspace) updateCache(dir, owner, repositoryURL, cloneURL, branch string, git func(dir string, args ...string) error, onLine func(line string)) (string, bool) {
+	if !w.cloneCache {
+		return "", false
+	}
+
+	sum := sha256.Sum256([]byte(owner + "\x00" + repositoryURL))
+	cache := filepath.Join(w.dir, cacheDirName, hex.EncodeToString(sum[:8])+".git")
+
+	rc := w.repoCache(cache)
+	rc.use.RLock()
+	rc.update.Lock()
+	defer rc.update.Unlock()
+
+	var err error
+	if _, statErr := os.Stat(cache); errors.Is(statErr, fs.ErrNotExist) {
+		onLine("Caching the repository's history for later builds...")
+		if err = os.MkdirAll(filepath.Dir(cache), 0o755); err == nil {
+			err = git(filepath.Dir(cache), "clone", "--bare", "--filter=blob:none", "--no-tags", "--single-branch",
+				"--branch", branch, "--config", "gc.auto=0", cloneURL, cache)
+		}
+		if err != nil {
+			// No build borrowed from a cache that was never created, the next build starts it over
+			if removeErr := os.RemoveAll(cache); removeErr != nil {
+				slog.Warn("Failed to remove repository cache", "dir", cache, "error", removeErr)
+			}
+		}
+	} else {
+		// A failed fetch leaves the objects the cache had, which other builds may be borrowing, as they were
+		onLine("Updating the repository's cached history...")
+		if err = git(cache, "remote", "set-url", "origin", cloneURL); err == nil {
+			err = git(cache, "fetch", "--filter=blob:none", "--no-tags", "origin", "+refs/heads/"+branch+":refs/heads/"+branch)
+		}
+	}
+	if err != nil {
+		rc.use.RUnlock()
+		slog.Warn("Failed to update repository cache", "repository", repositoryURL, "error", err)
+		onLine("⚠️ Could not update the repository's cached history, cloning without it")
+		return "", false
+	}
+
+	w.mu.Lock()
+	w.borrowed[dir] = append(w.borrowed[dir], rc)
+	w.mu.Unlock()
+
+	now := time.Now()
+	_ = os.Chtimes(cache, now, now)
+	return cache, true
+}
+
+// repoCache returns the locks of a repository's cache
+func (w *Workspace) repoCache(cache string) *repoCache {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	rc, ok := w.caches[cache]
+	if !ok {
+		rc = &repoCache{}
+		w.caches[cache] = rc
+	}
+	return rc
+}
+
+// cleanupCaches removes the caches of repositories no build used for cacheExpiry and returns how many it removed
+func (w *Workspace) cleanupCaches() (int, error) {
+	dir := filepath.Join(w.dir, cacheDirName)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to list repository caches: %w", err)
+	}
+
+	removed := 0
+	var errs []error
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !entry.IsDir() || time.Since(info.ModTime()) < cacheExpiry {
+			continue
+		}
+
+		// Caches builds borrow from, or are about to, are kept
+		cache := filepath.Join(dir, entry.Name())
+		rc := w.repoCache(cache)
+		if !rc.use.TryLock() {
+			continue
+		}
+		err = os.RemoveAll(cache)
+		rc.use.Unlock()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package builder
+
+import "syscall"
+
+// freeBytes returns the space left to unprivileged users on the file system of path
+func freeBytes(path string) (uint64, bool, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, false, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), true, nil
+}
@@ -0,0 +1,478 @@
+package builder
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/repo"
+)
+
+const (
+	// localLogPollInterval is how often StreamLogs passes on new lines of a local build
+	localLogPollInterval = time.Second
+
+	// gitCredentialHelper hands the clone token to git from the environment,
+	// so it never appears in the clone URL, the remote or the build logs
+	gitCredentialHelper = `!f() { echo "username=$GIT_CLONE_USERNAME"; echo "password=$GIT_CLONE_TOKEN"; }; f`
+)
+
+// BuilderService builds images with the Docker daemon of the host running the server,
+// for development and self-hosted setups without CodeBuild
+type BuilderService struct {
+	workspace          *Workspace
+	deploymentRepo     deployment.DeploymentRepository
+	projectRepo        project.ProjectRepository
+	sseManager         SSEBroadcaster
+	deploymentCallback DeploymentCallback
+	usageRecorder      UsageRecorder
+	cloneCredentials   CloneCredentialsProvider
+	tracker            *BuildTracker
+
+	mu     sync.Mutex
+	builds map[string]*localBuild
+}
+
+var _ BuildBackend = (*BuilderService)(nil)
+
+// localBuild is a build running on this host
+type localBuild struct {
+	mu        sync.Mutex
+	status    BuildStatus
+	lines     []string
+	startedAt time.Time
+	endedAt   time.Time
+	stopped   bool
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewBuilderService creates a new local Docker builder running its builds in the given workspace
+func NewBuilderService(workspace *Workspace, deploymentRepo deployment.DeploymentRepository, projectRepo project.ProjectRepository) *BuilderService {
+	return &BuilderService{
+		workspace:      workspace,
+		deploymentRepo: deploymentRepo,
+		projectRepo:    projectRepo,
+		tracker:        NewBuildTracker(),
+		builds:         make(map[string]*localBuild),
+	}
+}
+
+// SetSSEManager sets the SSE manager for real-time log streaming
+func (s *BuilderService) SetSSEManager(manager interface{}) {
+	if m, ok := manager.(SSEBroadcaster); ok {
+		s.sseManager = m
+	}
+}
+
+// SetDeploymentCallback sets the callback to be invoked after successful build
+func (s *BuilderService) SetDeploymentCallback(callback DeploymentCallback) {
+	s.deploymentCallback = callback
+}
+
+// SetUsageRecorder sets the recorder used to meter build minutes
+func (s *BuilderService) SetUsageRecorder(recorder UsageRecorder) {
+	s.usageRecorder = recorder
+}
+
+// SetCloneCredentialsProvider sets the provider of credentials used to clone private repositories
+func (s *BuilderService) SetCloneCredentialsProvider(provider CloneCredentialsProvider) {
+	s.cloneCredentials = provider
+}
+
+// StartBuild starts a local Docker build for a deployment
+func (s *BuilderService) StartBuild(ctx context.Context, req BuildRequest) (string, error) {
+	dep := req.Deployment
+	proj := req.Project
+
+	// Update status to BUILDING
+	if err := dep.UpdateStatus(deployment.StatusBuilding); err != nil {
+		return "", fmt.Errorf("failed to update status: %w", err)
+	}
+	if err := s.deploymentRepo.Save(ctx, dep); err != nil {
+		return "", fmt.Errorf("failed to save deployment: %w", err)
+	}
+
+	s.logAndUpdate(ctx, dep, "Starting build process with local Docker...")
+
+	// Private repositories are cloned with credentials from the repository's provider
+	var creds *repo.CloneCredentials
+	if s.cloneCredentials != nil {
+		var err error
+		creds, err = s.cloneCredentials.GetCloneCredentials(ctx, proj)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to get clone credentials", "project_id", proj.ID().String(), "error", err)
+			s.logAndUpdate(ctx, dep, "⚠️ Could not get access to the repository, cloning without credentials")
+			creds = nil
+		} else if creds != nil {
+			dep.MaskInLogs(creds.Token)
+			s.logAndUpdate(ctx, dep, "🔑 Cloning with repository access")
+		}
+	}
+
+	// The docker build is held to the CPU and memory of the project's build size
+	req.CPUs = proj.BuildSize().VCPUs()
+	req.MemoryMiB = proj.BuildSize().MemoryMiB()
+
+	// The build runs until it is over, times out or a shutdown interrupts it, whatever happens to the worker that started it
+	followCtx, done := s.tracker.Follow(ctx)
+
+	buildID := fmt.Sprintf("local-%s-%d", dep.ID().String(), time.Now().Unix())
+	runCtx, cancel := context.WithTimeout(followCtx, proj.BuildTimeout())
+	build := &localBuild{
+		status:    BuildInProgress,
+		startedAt: time.Now(),
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	s.builds[buildID] = build
+	s.mu.Unlock()
+
+	go s.run(runCtx, build, req, creds)
+
+	s.logAndUpdate(ctx, dep, fmt.Sprintf("Local build started: %s", buildID))
+	s.logAndUpdate(ctx, dep, fmt.Sprintf("Building with %d vCPUs and %d MiB of memory for at most %d minutes",
+		req.CPUs, req.MemoryMiB, proj.BuildTimeoutMinutes()))
+
+	// Start monitoring build status in background
+	go func() {
+		defer done()
+		s.monitorBuild(followCtx, dep, proj.ID(), req.ImageTag, buildID, proj.BuildTimeout())
+	}()
+
+	return buildID, nil
+}
+
+// Shutdown waits for the local builds to finish until ctx is done, then kills the rest
+func (s *BuilderService) Shutdown(ctx context.Context) []deployment.DeploymentID {
+	return s.tracker.Shutdown(ctx)
+}
+
+// StreamLogs passes the output of a local build to onLines until the build has finished
+func (s *BuilderService) StreamLogs(ctx context.Context, buildID string, onLines func(lines []string)) error {
+	build, err := s.build(buildID)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(localLogPollInterval)
+	defer ticker.Stop()
+
+	offset := 0
+	for {
+		// Lines and status are read together, so once the build is done every line has been passed on
+		build.mu.Lock()
+		lines := append([]string(nil), build.lines[offset:]...)
+		status := build.status
+		build.mu.Unlock()
+
+		offset += len(lines)
+		if len(lines) > 0 {
+			onLines(lines)
+		}
+		if status.Done() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-build.done:
+		case <-ticker.C:
+		}
+	}
+}
+
+// Cancel stops a running local build
+func (s *BuilderService) Cancel(ctx context.Context, buildID string) error {
+	build, err := s.build(buildID)
+	if err != nil {
+		return err
+	}
+
+	build.mu.Lock()
+	build.stopped = true
+	build.mu.Unlock()
+	build.cancel()
+	return nil
+}
+
+// Status reports where a local build is at
+func (s *BuilderService) Status(ctx context.Context, buildID string) (BuildStatus, error) {
+	build, err := s.build(buildID)
+	if err != nil {
+		return "", err
+	}
+
+	build.mu.Lock()
+	defer build.mu.Unlock()
+	return build.status, nil
+}
+
+// build looks up a build started by this instance
+func (s *BuilderService) build(buildID string) (*localBuild, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	build, ok := s.builds[buildID]
+	if !ok {
+		return nil, fmt.Errorf("build not found: %s", buildID)
+	}
+	return build, nil
+}
+
+// run clones the repository, then builds and pushes the image, recording the output of every step
+func (s *BuilderService) run(ctx context.Context, build *localBuild, req BuildRequest, creds *repo.CloneCredentials) {
+	defer build.cancel()
+
+	err := RunBuild(ctx, s.workspace, req, creds, build.appendLine)
+
+	build.mu.Lock()
+	defer build.mu.Unlock()
+	build.endedAt = time.Now()
+	switch {
+	case err == nil:
+		build.status = BuildSucceeded
+	case build.stopped:
+		build.status = BuildStopped
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		build.status = BuildTimedOut
+	default:
+		build.lines = append(build.lines, err.Error())
+		build.status = BuildFailed
+	}
+	close(build.done)
+}
+
+// RunBuild clones the repository, then builds and pushes the image in a fresh directory of the workspace,
+// removed once it is done. The build fails right away when the disk is nearly full, and is stopped once it uses more
+// than the workspace allows a build. Each line of output is passed to onLine. Self-hosted build agents run their
+// builds with it too.
+func RunBuild(ctx context.Context, workspace *Workspace, req BuildRequest, creds *repo.CloneCredentials, onLine func(line string)) error {
+	dir, release, err := workspace.create()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	watchCtx, stop := workspace.watch(ctx, dir)
+	err = runBuild(watchCtx, workspace, dir, req, creds, onLine)
+	if exceeded := stop(); exceeded != nil {
+		return exceeded
+	}
+	return err
+}
+
+// runBuild runs the steps of a build in dir
+func runBuild(ctx context.Context, workspace *Workspace, dir string, req BuildRequest, creds *repo.CloneCredentials, onLine func(line string)) error {
+	repoDir, err := cloneRepository(ctx, workspace, dir, req, creds, onLine)
+	if err != nil {
+		return err
+	}
+
+	onLine("Writing Dockerfile...")
+	if err := os.WriteFile(filepath.Join(repoDir, "Dockerfile.snapdeploy"), []byte(req.Dockerfile), 0o644); err != nil {
+		return fmt.Errorf("failed to write Dockerfile: %w", err)
+	}
+
+	onLine(fmt.Sprintf("Building Docker image - %s", req.ImageTag))
+	buildArgs := []string{"build"}
+	if req.CPUs > 0 {
+		buildArgs = append(buildArgs, "--cpu-period", "100000", "--cpu-quota", fmt.Sprint(req.CPUs*100000))
+	}
+	if req.MemoryMiB > 0 {
+		buildArgs = append(buildArgs, "--memory", fmt.Sprintf("%dm", req.MemoryMiB))
+	}
+	for _, name := range BuildArgNames(req.BuildArgs) {
+		buildArgs = append(buildArgs, "--build-arg", name+"="+req.BuildArgs[name])
+	}
+	buildArgs = append(buildArgs, "-f", "Dockerfile.snapdeploy", "-t", req.ImageTag, ".")
+	if err := runStep(ctx, onLine, repoDir, nil, "docker", buildArgs...); err != nil {
+		return fmt.Errorf("failed to build image: %w", err)
+	}
+
+	onLine("Pushing image to registry...")
+	if err := runStep(ctx, onLine, repoDir, nil, "docker", "push", req.ImageTag); err != nil {
+		return fmt.Errorf("failed to push image: %w", err)
+	}
+
+	onLine("Build completed successfully!")
+	return nil
+}
+
+// PingDocker returns an error unless the Docker daemon builds would run on answers
+func PingDocker(ctx context.Context) error {
+	output, err := exec.CommandContext(ctx, "docker", "version", "--format", "{{.Server.Version}}").CombinedOutput()
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("docker daemon unavailable: %s", message)
+		}
+		return fmt.Errorf("docker daemon unavailable: %w", err)
+	}
+	return nil
+}
+
+// runStep runs a command in dir with extra environment variables, passing its output on line by line
+func runStep(ctx context.Context, onLine func(line string), dir string, env []string, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
+	scanned := make(chan struct{})
+	go func() {
+		defer close(scanned)
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			onLine(scanner.Text())
+		}
+		// Keep draining so a line too long to scan doesn't block the command
+		io.Copy(io.Discard, reader)
+	}()
+
+	err := cmd.Run()
+	writer.Close()
+	<-scanned
+	return err
+}
+
+// appendLine records a line of build output
+func (b *localBuild) appendLine(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines = append(b.lines, line)
+}
+
+// monitorBuild follows the build until it finishes and updates the deployment accordingly.
+// Only the project ID is kept, so the deployment picks up the latest project configuration (e.g., updated custom_domain).
+func (s *BuilderService) monitorBuild(ctx context.Context, dep *deployment.Deployment, projectID project.ProjectID, imageTag, buildID string, timeout time.Duration) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.builds, buildID)
+		s.mu.Unlock()
+	}()
+
+	err := s.StreamLogs(ctx, buildID, func(lines []string) {
+		s.appendLogs(ctx, dep, lines)
+	})
+	// The shutdown killed the build along with its docker process
+	if s.tracker.Interrupted() {
+		s.checkpoint(ctx, dep)
+		return
+	}
+	var status BuildStatus
+	if err == nil {
+		status, err = s.Status(ctx, buildID)
+	}
+	if err != nil {
+		s.logAndUpdate(ctx, dep, fmt.Sprintf("Error monitoring build: %v", err))
+		dep.UpdateStatus(deployment.StatusFailed)
+		s.deploymentRepo.Save(ctx, dep)
+		return
+	}
+
+	// Meter build minutes whatever the outcome, failed builds are billed too
+	s.recordBuildUsage(ctx, dep, buildID)
+
+	if status != BuildSucceeded {
+		message := fmt.Sprintf("❌ Build failed with status: %s", status)
+		if status == BuildTimedOut {
+			message = TimedOutMessage(timeout)
+		}
+		s.logAndUpdate(ctx, dep, message)
+		dep.UpdateStatus(deployment.StatusFailed)
+		s.deploymentRepo.Save(ctx, dep)
+		return
+	}
+
+	s.logAndUpdate(ctx, dep, "✅ Build completed successfully!")
+	s.logAndUpdate(ctx, dep, "📦 Image pushed to registry successfully")
+
+	// Fetch fresh project data to ensure we have the latest configuration
+	proj, err := s.projectRepo.FindByID(ctx, projectID)
+	if err != nil {
+		s.logAndUpdate(ctx, dep, fmt.Sprintf("❌ Failed to fetch project data: %v", err))
+		dep.UpdateStatus(deployment.StatusFailed)
+		s.deploymentRepo.Save(ctx, dep)
+		return
+	}
+
+	if s.deploymentCallback != nil {
+		s.logAndUpdate(ctx, dep, "🚀 Triggering deployment to ECS...")
+		if err := s.deploymentCallback.OnBuildSuccess(ctx, dep, proj, imageTag); err != nil {
+			s.logAndUpdate(ctx, dep, fmt.Sprintf("❌ Deployment to ECS failed: %v", err))
+			dep.UpdateStatus(deployment.StatusFailed)
+		}
+		// Note: status will be updated to DEPLOYED by the deployment callback
+	} else {
+		dep.UpdateStatus(deployment.StatusDeployed)
+	}
+
+	s.deploymentRepo.Save(ctx, dep)
+}
+
+// checkpoint records that a shutdown interrupted the deployment's build, so the next build worker starts it over
+func (s *BuilderService) checkpoint(ctx context.Context, dep *deployment.Deployment) {
+	if err := dep.Interrupt(); err != nil {
+		slog.ErrorContext(ctx, "Failed to interrupt deployment", "deployment_id", dep.ID().String(), "error", err)
+		return
+	}
+
+	saveCtx, cancel := s.tracker.Checkpoint(ctx, dep.ID())
+	defer cancel()
+	s.appendLogs(saveCtx, dep, []string{InterruptedMessage})
+}
+
+// recordBuildUsage records the build minutes consumed by a finished build
+func (s *BuilderService) recordBuildUsage(ctx context.Context, dep *deployment.Deployment, buildID string) {
+	if s.usageRecorder == nil {
+		return
+	}
+
+	build, err := s.build(buildID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get build times", "build_id", buildID, "error", err)
+		return
+	}
+
+	build.mu.Lock()
+	startedAt, endedAt := build.startedAt, build.endedAt
+	build.mu.Unlock()
+
+	if err := s.usageRecorder.RecordBuild(ctx, dep, startedAt, endedAt); err != nil {
+		slog.ErrorContext(ctx, "Failed to record build usage", "deployment_id", dep.ID().String(), "error", err)
+	}
+}
+
+// appendLogs appends build output to the deployment logs and broadcasts it, saving once for the whole batch
+func (s *BuilderService) appendLogs(ctx context.Context, dep *deployment.Deployment, lines []string) {
+	for _, line := range lines {
+		dep.AppendLog(line)
+		if s.sseManager != nil {
+			s.sseManager.BroadcastLog(dep.ID().String(), dep.RedactLog(line))
+		}
+	}
+
+	s.deploymentRepo.Save(ctx, dep)
+}
+
+// logAndUpdate logs a message and updates the deployment
+func (s *BuilderService) logAndUpdate(ctx context.Context, dep *deployment.Deployment, message string) {
+	s.appendLogs(ctx, dep, []string{message})
+}
@@ -12,9 +12,13 @@ import (
 //go:embed templates/*.tmpl
 var templateFiles embed.FS
 
+// staticTemplate builds STATIC projects, whatever their language
+const staticTemplate = "templates/static.Dockerfile.tmpl"
+
 // TemplateGenerator generates Dockerfiles from templates
 type TemplateGenerator struct {
 	templates map[project.Language]string
+	static    string
 }
 
 // NewTemplateGenerator creates a new template generator
@@ -40,15 +44,24 @@ func NewTemplateGenerator() (*TemplateGenerator, error) {
 		tg.templates[lang] = string(content)
 	}
 
+	content, err := templateFiles.ReadFile(staticTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template %s: %w", staticTemplate, err)
+	}
+	tg.static = string(content)
+
 	return tg, nil
 }
 
 // TemplateData holds the data for Dockerfile template generation
 type TemplateData struct {
-	InstallCommand string
-	BuildCommand   string
-	RunCommand     string
-	Port           string
+	InstallCommand  string
+	BuildCommand    string
+	RunCommand      string
+	Port            string
+	BuildArgs       []string // Names of the build args declared before the build command
+	OutputDirectory string   // Directory the build of a STATIC project writes the site to
+	LambdaAdapter   bool     // Whether the image runs on Lambda, through the Lambda Web Adapter extension
 }
 
 // GenerateDockerfile generates a Dockerfile from a template
@@ -63,6 +76,21 @@ func (tg *TemplateGenerator) GenerateDockerfile(lang project.Language, data Temp
 		data.Port = "8080"
 	}
 
+	return render(templateStr, data)
+}
+
+// GenerateStaticDockerfile generates the Dockerfile of a STATIC project. Its image holds the built site
+// and uploads it to S3 when run.
+func (tg *TemplateGenerator) GenerateStaticDockerfile(data TemplateData) (string, error) {
+	if data.OutputDirectory == "" {
+		data.OutputDirectory = project.DefaultOutputDirectory
+	}
+
+	return render(tg.static, data)
+}
+
+// render executes a Dockerfile template
+func render(templateStr string, data TemplateData) (string, error) {
 	tmpl, err := template.New("dockerfile").Parse(templateStr)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template: %w", err)
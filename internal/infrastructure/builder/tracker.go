@@ -0,0 +1,89 @@
+package builder
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+)
+
+const (
+	// checkpointTimeout is how long interrupted builds get to record the interruption once shutdown cancels them
+	checkpointTimeout = 10 * time.Second
+
+	// InterruptedMessage is logged for the builds interrupted by a shutdown
+	InterruptedMessage = "⏸️  Build interrupted by a server shutdown, it starts over once a build worker picks it up again"
+)
+
+// BuildTracker tracks the builds followed by this instance, so shutdown can give them a grace period to
+// finish and interrupt those still running after it
+type BuildTracker struct {
+	wg        sync.WaitGroup
+	interrupt context.Context
+	cancel    context.CancelFunc
+
+	mu          sync.Mutex
+	interrupted []deployment.DeploymentID
+}
+
+// NewBuildTracker creates a tracker with no build followed
+func NewBuildTracker() *BuildTracker {
+	interrupt, cancel := context.WithCancel(context.Background())
+	return &BuildTracker{interrupt: interrupt, cancel: cancel}
+}
+
+// Follow starts tracking a build. The returned context keeps the values of ctx, such as the correlation ID
+// and trace of the build, but is only cancelled when the build is interrupted. done is called once the
+// build is over.
+func (t *BuildTracker) Follow(ctx context.Context) (followCtx context.Context, done func()) {
+	t.wg.Add(1)
+	followCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(t.interrupt, cancel)
+	return followCtx, func() {
+		stop()
+		cancel()
+		t.wg.Done()
+	}
+}
+
+// Interrupted reports whether the builds still running were interrupted by a shutdown
+func (t *BuildTracker) Interrupted() bool {
+	return t.interrupt.Err() != nil
+}
+
+// Checkpoint records that a deployment's build was interrupted, returning a context that the interruption
+// doesn't cancel to save the deployment with
+func (t *BuildTracker) Checkpoint(ctx context.Context, deploymentID deployment.DeploymentID) (context.Context, context.CancelFunc) {
+	t.mu.Lock()
+	t.interrupted = append(t.interrupted, deploymentID)
+	t.mu.Unlock()
+	return context.WithTimeout(context.WithoutCancel(ctx), checkpointTimeout)
+}
+
+// Shutdown waits for the followed builds to finish until ctx is done, then interrupts the rest and waits
+// for them to record it. Returns the deployments whose build was interrupted. No build may be followed
+// once it is called.
+func (t *BuildTracker) Shutdown(ctx context.Context) []deployment.DeploymentID {
+	finished := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+	}
+
+	t.cancel()
+	select {
+	case <-finished:
+	case <-time.After(checkpointTimeout):
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]deployment.DeploymentID(nil), t.interrupted...)
+}
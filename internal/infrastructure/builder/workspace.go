@@ -0,0 +1,264 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// buildDirPrefix starts the names of the directories builds run in, cleanup leaves other files alone
+	buildDirPrefix = "build-"
+
+	// orphanAfter is how long a build directory goes unchecked before it is taken for the leftover of a build whose
+	// process crashed. Running builds touch theirs every workspaceCheckInterval, whichever process runs them.
+	orphanAfter = 10 * time.Minute
+)
+
+// workspaceCheckInterval is how often the directory of a running build is measured. Tests shorten it.
+var workspaceCheckInterval = 5 * time.Second
+
+var (
+	// ErrDiskPressure is returned when the disk of the workspace is too full to start or go on with a build
+	ErrDiskPressure = errors.New("not enough free disk space to build")
+
+	// ErrBuildTooLarge is returned when the directory of a build grows past the size a build may use
+	ErrBuildTooLarge = errors.New("build exceeded its disk quota")
+)
+
+// Workspace is the directory builds clone repositories into, a directory of their own per build. It keeps builds
+// from filling the disk of the machine they run on: builds don't start while the disk is nearly full, and are
+// stopped once their directory grows past maxBuildBytes or the disk fills up meanwhile. Directories left behind by
+// builds that never cleaned up, such as when their process crashed, are removed by Cleanup, along with the caches of
+// repositories no build used for a week.
+type Workspace struct {
+	dir           string
+	maxBuildBytes int64 // 0 doesn't limit builds
+	minFreeBytes  int64 // 0 doesn't check the free space
+	cloneCache    bool  // Whether the history of repositories is cached for their next builds
+
+	mu       sync.Mutex
+	active   map[string]bool         // Directories of the builds this process runs
+	caches   map[string]*repoCache   // Locks of the repository caches, by directory
+	borrowed map[string][]*repoCache // Caches the builds borrow objects from, by build directory
+}
+
+// NewWorkspace creates a workspace in dir, created if it doesn't exist yet
+func NewWorkspace(dir string, maxBuildBytes, minFreeBytes int64) *Workspace {
+	return &Workspace{
+		dir:           dir,
+		maxBuildBytes: maxBuildBytes,
+		minFreeBytes:  minFreeBytes,
+		active:        make(map[string]bool),
+		caches:        make(map[string]*repoCache),
+		borrowed:      make(map[string][]*repoCache),
+	}
+}
+
+// create creates the directory of a new build, once the disk has room for it. release removes it.
+func (w *Workspace) create() (dir string, release func(), err error) {
+	if err := os.MkdirAll(w.dir, 0o755); err != nil {
+		return "", nil, fmt.Errorf("failed to create work directory: %w", err)
+	}
+	if err := w.checkFreeSpace(); err != nil {
+		return "", nil, err
+	}
+
+	dir, err = os.MkdirTemp(w.dir, buildDirPrefix)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create build directory: %w", err)
+	}
+
+	w.mu.Lock()
+	w.active[dir] = true
+	w.mu.Unlock()
+
+	return dir, func() {
+		if err := os.RemoveAll(dir); err != nil {
+			slog.Warn("Failed to remove build directory, cleanup will retry", "dir", dir, "error", err)
+		}
+		w.mu.Lock()
+		delete(w.active, dir)
+		borrowed := w.borrowed[dir]
+		delete(w.borrowed, dir)
+		w.mu.Unlock()
+
+		// The clone is gone, the caches it borrowed from can be removed
+		for _, rc := range borrowed {
+			rc.use.RUnlock()
+		}
+	}, nil
+}
+
+// watch checks the directory of a running build until stop is called, cancelling the returned context once the
+// build uses more than its quota or the disk fills up. stop returns why the build was cancelled, if it was.
+func (w *Workspace) watch(ctx context.Context, dir string) (watchCtx context.Context, stop func() error) {
+	watchCtx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(workspaceCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-watchCtx.Done():
+				return
+			case now := <-ticker.C:
+				// The modification time tells cleanup in other processes sharing the workspace that the build is running
+				_ = os.Chtimes(dir, now, now)
+
+				if err := w.check(dir); err != nil {
+					cancel(err)
+					return
+				}
+			}
+		}
+	}()
+
+	return watchCtx, func() error {
+		close(done)
+		<-stopped
+		cause := context.Cause(watchCtx)
+		cancel(nil)
+		if errors.Is(cause, ErrBuildTooLarge) || errors.Is(cause, ErrDiskPressure) {
+			return cause
+		}
+		return nil
+	}
+}
+
+// check returns an error if a build's directory is over its quota or the disk is nearly full
+func (w *Workspace) check(dir string) error {
+	if w.maxBuildBytes > 0 {
+		size, err := dirSize(dir)
+		if err != nil {
+			slog.Warn("Failed to measure build directory", "dir", dir, "error", err)
+		} else if size > w.maxBuildBytes {
+			return fmt.Errorf("%w: the build directory holds %d MB, builds may use %d MB",
+				ErrBuildTooLarge, size>>20, w.maxBuildBytes>>20)
+		}
+	}
+	return w.checkFreeSpace()
+}
+
+// checkFreeSpace returns ErrDiskPressure if the disk of the workspace has less than minFreeBytes left
+func (w *Workspace) checkFreeSpace() error {
+	if w.minFreeBytes <= 0 {
+		return nil
+	}
+	free, ok, err := freeBytes(w.dir)
+	if err != nil {
+		return fmt.Errorf("failed to check free disk space: %w", err)
+	}
+	if ok && free < uint64(w.minFreeBytes) {
+		return fmt.Errorf("%w: %d MB left on the disk of %s, builds need %d MB",
+			ErrDiskPressure, free>>20, w.dir, w.minFreeBytes>>20)
+	}
+	return nil
+}
+
+// Cleanup removes the build directories no build runs in anymore, left behind by builds whose process crashed or
+// was killed before it removed them, and the repository caches that expired, and returns how many it removed
+func (w *Workspace) Cleanup() (int, error) {
+	removed, err := w.cleanupBuilds()
+	removedCaches, cacheErr := w.cleanupCaches()
+	return removed + removedCaches, errors.Join(err, cacheErr)
+}
+
+// cleanupBuilds removes the orphaned build directories and returns how many it removed
+func (w *Workspace) cleanupBuilds() (int, error) {
+	entries, err := os.ReadDir(w.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to list work directory: %w", err)
+	}
+
+	// Removing whole clones takes a while, builds starting meanwhile don't wait for it
+	var orphans []string
+	w.mu.Lock()
+	for _, entry := range entries {
+		dir := filepath.Join(w.dir, entry.Name())
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), buildDirPrefix) || w.active[dir] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < orphanAfter {
+			continue
+		}
+		orphans = append(orphans, dir)
+	}
+	w.mu.Unlock()
+
+	removed := 0
+	var errs []error
+	for _, dir := range orphans {
+		if err := os.RemoveAll(dir); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
+
+// RunCleanup removes orphaned build directories and expired repository caches right away, then every interval
+// until the context is cancelled
+func (w *Workspace) RunCleanup(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		slog.Info("Cleaning up build directories disabled", "interval", interval)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		removed, err := w.Cleanup()
+		if err != nil {
+			slog.ErrorContext(ctx, "Cleaning up build directories failed", "removed", removed, "error", err)
+		} else if removed > 0 {
+			slog.InfoContext(ctx, "Removed orphaned build directories and expired repository caches", "removed", removed, "work_dir", w.dir)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dirSize returns the size of the files in a directory and its subdirectories
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Files the build removes while they are counted are skipped
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,146 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// makeDir creates a directory of the workspace, last modified at modified
+func makeDir(t *testing.T, w *Workspace, name string, modified time.Time) string {
+	t.Helper()
+	dir := filepath.Join(w.dir, name)
+	if err := os.MkdirAll(filepath.Join(dir, "repo"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(dir, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestWorkspace_CleanupBuilds(t *testing.T) {
+	w := NewWorkspace(t.TempDir(), 0, 0)
+	stale := time.Now().Add(-2 * orphanAfter)
+
+	orphaned := makeDir(t, w, buildDirPrefix+"orphaned", stale)
+	active := makeDir(t, w, buildDirPrefix+"active", stale)
+	recent := makeDir(t, w, buildDirPrefix+"recent", time.Now())
+	other := makeDir(t, w, "other", stale)
+	w.active[active] = true
+
+	removed, err := w.cleanupBuilds()
+	if err != nil {
+		t.Fatalf("cleanupBuilds() error = %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("cleanupBuilds() removed %d directories, want 1", removed)
+	}
+	if _, err := os.Stat(orphaned); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("orphaned build directory was kept")
+	}
+	for name, dir := range map[string]string{"active": active, "recent": recent, "without the prefix": other} {
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("%s directory was removed: %v", name, err)
+		}
+	}
+}
+
+func TestWorkspace_CleanupBuildsWithoutWorkDir(t *testing.T) {
+	w := NewWorkspace(filepath.Join(t.TempDir(), "missing"), 0, 0)
+	if removed, err := w.cleanupBuilds(); removed != 0 || err != nil {
+		t.Errorf("cleanupBuilds() = %d, %v, want nothing to clean up", removed, err)
+	}
+}
+
+func TestWorkspace_Check(t *testing.T) {
+	w := NewWorkspace(t.TempDir(), 1024, 0)
+	dir, release, err := w.create()
+	if err != nil {
+		t.Fatalf("create() error = %v", err)
+	}
+	defer release()
+
+	if err := os.WriteFile(filepath.Join(dir, "small"), make([]byte, 512), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.check(dir); err != nil {
+		t.Errorf("check() error = %v under the quota", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "large"), make([]byte, 1024), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.check(dir); !errors.Is(err, ErrBuildTooLarge) {
+		t.Errorf("check() error = %v over the quota, want ErrBuildTooLarge", err)
+	}
+}
+
+func TestWorkspace_WatchCancelsBuildOverQuota(t *testing.T) {
+	interval := workspaceCheckInterval
+	workspaceCheckInterval = 10 * time.Millisecond
+	t.Cleanup(func() { workspaceCheckInterval = interval })
+
+	w := NewWorkspace(t.TempDir(), 1024, 0)
+	dir, release, err := w.create()
+	if err != nil {
+		t.Fatalf("create() error = %v", err)
+	}
+	defer release()
+
+	watchCtx, stop := w.watch(context.Background(), dir)
+	if err := os.WriteFile(filepath.Join(dir, "large"), make([]byte, 2048), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-watchCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch() didn't cancel the build over its quota")
+	}
+	if err := stop(); !errors.Is(err, ErrBuildTooLarge) {
+		t.Errorf("stop() error = %v, want ErrBuildTooLarge", err)
+	}
+}
+
+func TestWorkspace_WatchStopsWithoutCause(t *testing.T) {
+	w := NewWorkspace(t.TempDir(), 1024, 0)
+	dir, release, err := w.create()
+	if err != nil {
+		t.Fatalf("create() error = %v", err)
+	}
+	defer release()
+
+	watchCtx, stop := w.watch(context.Background(), dir)
+	if err := stop(); err != nil {
+		t.Errorf("stop() error = %v for a build within its quota", err)
+	}
+	if watchCtx.Err() == nil {
+		t.Error("stop() left the build's context running")
+	}
+}
+
+func TestWorkspace_CleanupCachesKeepsBorrowedCaches(t *testing.T) {
+	w := NewWorkspace(t.TempDir(), 0, 0)
+	expired := time.Now().Add(-2 * cacheExpiry)
+	unused := makeDir(t, w, filepath.Join(cacheDirName, "unused.git"), expired)
+	borrowed := makeDir(t, w, filepath.Join(cacheDirName, "borrowed.git"), expired)
+	w.repoCache(borrowed).use.RLock()
+
+	removed, err := w.cleanupCaches()
+	if err != nil {
+		t.Fatalf("cleanupCaches() error = %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("cleanupCaches() removed %d caches, want 1", removed)
+	}
+	if _, err := os.Stat(unused); !errors.Is(err, os.ErrNotExist) {
+		t.Error("expired cache no build borrows from was kept")
+	}
+	if _, err := os.Stat(borrowed); err != nil {
+		t.Errorf("cache a build borrows from was removed: %v", err)
+	}
+}
@@ -0,0 +1,324 @@
+package cloudfront
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"snapdeploy-core/internal/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// cachingOptimizedPolicyID is the AWS managed cache policy for static content. Every deployment
+	// invalidates the whole site, so files are cached until they change.
+	cachingOptimizedPolicyID = "658327ea-f89d-4fab-a63d-7e88639e58f6"
+
+	// commentPrefix names the distributions of static sites, which are found again by their comment
+	commentPrefix = "snapdeploy:"
+
+	// distributionDeployTimeout is how long a disabled distribution may take to deploy before it can be deleted
+	distributionDeployTimeout = 30 * time.Minute
+)
+
+// CloudFrontClient wraps the CloudFront and S3 operations used to serve STATIC projects. The sites of
+// all projects share one bucket, each under its own prefix, and each is served by its own distribution.
+type CloudFrontClient struct {
+	client                *cloudfront.Client
+	s3                    *s3.Client
+	bucket                string
+	region                string
+	certificateArn        string
+	originAccessControlID string
+}
+
+// NewCloudFrontClient creates a new CloudFront client serving sites uploaded to the bucket, with the
+// certificate and the origin access control CloudFront reads the bucket with
+func NewCloudFrontClient(bucket, certificateArn, originAccessControlID string) (*CloudFrontClient, error) {
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	tracing.InstrumentAWS(&cfg)
+
+	if bucket == "" || certificateArn == "" || originAccessControlID == "" {
+		return nil, fmt.Errorf("STATIC_SITES_BUCKET, CLOUDFRONT_CERTIFICATE_ARN and CLOUDFRONT_ORIGIN_ACCESS_CONTROL_ID must be set")
+	}
+
+	return &CloudFrontClient{
+		client:                cloudfront.NewFromConfig(cfg),
+		s3:                    s3.NewFromConfig(cfg),
+		bucket:                bucket,
+		region:                cfg.Region,
+		certificateArn:        certificateArn,
+		originAccessControlID: originAccessControlID,
+	}, nil
+}
+
+// Site is the distribution a static site is served from
+type Site struct {
+	DistributionID string
+	DomainName     string // e.g., d111111abcdef8.cloudfront.net, the target of the site's DNS record
+}
+
+// Bucket returns the bucket the sites are uploaded to
+func (c *CloudFrontClient) Bucket() string {
+	return c.bucket
+}
+
+// SitePrefix returns the prefix of the bucket a site is uploaded under
+func SitePrefix(name string) string {
+	return "sites/" + name
+}
+
+// EnsureDistribution creates the distribution serving a site on a domain, or brings an existing one up to
+// date, e.g. after the project's domain changed or it was stopped
+func (c *CloudFrontClient) EnsureDistribution(ctx context.Context, name, domain string) (*Site, error) {
+	summary, err := c.findDistribution(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if summary == nil {
+		result, err := c.client.CreateDistribution(ctx, &cloudfront.CreateDistributionInput{
+			DistributionConfig: c.distributionConfig(name, domain),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create distribution: %w", err)
+		}
+		slog.InfoContext(ctx, "Created CloudFront distribution", "site", name, "distribution_id", aws.ToString(result.Distribution.Id))
+		return &Site{
+			DistributionID: aws.ToString(result.Distribution.Id),
+			DomainName:     aws.ToString(result.Distribution.DomainName),
+		}, nil
+	}
+
+	site := &Site{
+		DistributionID: aws.ToString(summary.Id),
+		DomainName:     aws.ToString(summary.DomainName),
+	}
+	if aws.ToBool(summary.Enabled) && summary.Aliases != nil && slices.Equal(summary.Aliases.Items, []string{domain}) {
+		return site, nil
+	}
+
+	current, err := c.client.GetDistributionConfig(ctx, &cloudfront.GetDistributionConfigInput{Id: summary.Id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get distribution config: %w", err)
+	}
+	distributionConfig := current.DistributionConfig
+	distributionConfig.Enabled = aws.Bool(true)
+	distributionConfig.Aliases = &types.Aliases{Quantity: aws.Int32(1), Items: []string{domain}}
+
+	if _, err := c.client.UpdateDistribution(ctx, &cloudfront.UpdateDistributionInput{
+		Id:                 summary.Id,
+		IfMatch:            current.ETag,
+		DistributionConfig: distributionConfig,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to update distribution: %w", err)
+	}
+	return site, nil
+}
+
+// distributionConfig describes the distribution of a new site: files are read from the site's prefix of
+// the bucket through origin access control, and paths without a file are answered with index.html so
+// single-page apps can route them
+func (c *CloudFrontClient) distributionConfig(name, domain string) *types.DistributionConfig {
+	originID := "s3-" + name
+	notFound := func(code int32) types.CustomErrorResponse {
+		return types.CustomErrorResponse{
+			ErrorCode:          aws.Int32(code),
+			ResponseCode:       aws.String("200"),
+			ResponsePagePath:   aws.String("/index.html"),
+			ErrorCachingMinTTL: aws.Int64(10),
+		}
+	}
+
+	return &types.DistributionConfig{
+		// The reference only has to be unique, the distribution is found again by its comment
+		CallerReference:   aws.String(name + "-" + strconv.FormatInt(time.Now().UnixNano(), 10)),
+		Comment:           aws.String(commentPrefix + name),
+		Enabled:           aws.Bool(true),
+		DefaultRootObject: aws.String("index.html"),
+		Aliases:           &types.Aliases{Quantity: aws.Int32(1), Items: []string{domain}},
+		Origins: &types.Origins{
+			Quantity: aws.Int32(1),
+			Items: []types.Origin{
+				{
+					Id:                    aws.String(originID),
+					DomainName:            aws.String(fmt.Sprintf("%s.s3.%s.amazonaws.com", c.bucket, c.region)),
+					OriginPath:            aws.String("/" + SitePrefix(name)),
+					OriginAccessControlId: aws.String(c.originAccessControlID),
+					S3OriginConfig:        &types.S3OriginConfig{OriginAccessIdentity: aws.String("")},
+				},
+			},
+		},
+		DefaultCacheBehavior: &types.DefaultCacheBehavior{
+			TargetOriginId:       aws.String(originID),
+			ViewerProtocolPolicy: types.ViewerProtocolPolicyRedirectToHttps,
+			CachePolicyId:        aws.String(cachingOptimizedPolicyID),
+			Compress:             aws.Bool(true),
+		},
+		// S3 answers 403 rather than 404 for missing files when the bucket can't be listed
+		CustomErrorResponses: &types.CustomErrorResponses{
+			Quantity: aws.Int32(2),
+			Items:    []types.CustomErrorResponse{notFound(403), notFound(404)},
+		},
+		ViewerCertificate: &types.ViewerCertificate{
+			ACMCertificateArn:      aws.String(c.certificateArn),
+			SSLSupportMethod:       types.SSLSupportMethodSniOnly,
+			MinimumProtocolVersion: types.MinimumProtocolVersionTLSv122021,
+		},
+		HttpVersion: types.HttpVersionHttp2and3,
+		PriceClass:  types.PriceClassPriceClass100,
+	}
+}
+
+// Invalidate drops every file of a site from the CloudFront cache, so a new deployment is served right away.
+// The reference, such as the deployment ID, makes retrying the same invalidation harmless.
+func (c *CloudFrontClient) Invalidate(ctx context.Context, distributionID, reference string) error {
+	_, err := c.client.CreateInvalidation(ctx, &cloudfront.CreateInvalidationInput{
+		DistributionId: aws.String(distributionID),
+		InvalidationBatch: &types.InvalidationBatch{
+			CallerReference: aws.String(reference),
+			Paths:           &types.Paths{Quantity: aws.Int32(1), Items: []string{"/*"}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create invalidation: %w", err)
+	}
+	return nil
+}
+
+// DisableDistribution stops serving a site, keeping its distribution and files so it can be enabled again.
+// Returns whether the site has a distribution.
+func (c *CloudFrontClient) DisableDistribution(ctx context.Context, name string) (bool, error) {
+	summary, err := c.findDistribution(ctx, name)
+	if err != nil || summary == nil {
+		return false, err
+	}
+	_, err = c.disable(ctx, summary.Id)
+	return true, err
+}
+
+// DeleteSite deletes the distribution and files of a site. A distribution must be disabled and the change
+// deployed before it can be deleted, which takes several minutes.
+func (c *CloudFrontClient) DeleteSite(ctx context.Context, name string) error {
+	summary, err := c.findDistribution(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if summary != nil {
+		etag, err := c.disable(ctx, summary.Id)
+		if err != nil {
+			return err
+		}
+
+		waiter := cloudfront.NewDistributionDeployedWaiter(c.client)
+		if err := waiter.Wait(ctx, &cloudfront.GetDistributionInput{Id: summary.Id}, distributionDeployTimeout); err != nil {
+			return fmt.Errorf("failed waiting for distribution to be disabled: %w", err)
+		}
+
+		_, err = c.client.DeleteDistribution(ctx, &cloudfront.DeleteDistributionInput{
+			Id:      summary.Id,
+			IfMatch: etag,
+		})
+		var noSuchDistribution *types.NoSuchDistribution
+		if err != nil && !errors.As(err, &noSuchDistribution) {
+			return fmt.Errorf("failed to delete distribution: %w", err)
+		}
+	}
+
+	return c.deleteFiles(ctx, SitePrefix(name)+"/")
+}
+
+// disable disables a distribution unless it already is, and returns the ETag of its latest config
+func (c *CloudFrontClient) disable(ctx context.Context, id *string) (*string, error) {
+	current, err := c.client.GetDistributionConfig(ctx, &cloudfront.GetDistributionConfigInput{Id: id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get distribution config: %w", err)
+	}
+	if !aws.ToBool(current.DistributionConfig.Enabled) {
+		return current.ETag, nil
+	}
+
+	current.DistributionConfig.Enabled = aws.Bool(false)
+	result, err := c.client.UpdateDistribution(ctx, &cloudfront.UpdateDistributionInput{
+		Id:                 id,
+		IfMatch:            current.ETag,
+		DistributionConfig: current.DistributionConfig,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to disable distribution: %w", err)
+	}
+	return result.ETag, nil
+}
+
+// deleteFiles deletes every object under a prefix of the bucket
+func (c *CloudFrontClient) deleteFiles(ctx context.Context, prefix string) error {
+	var token *string
+	for {
+		page, err := c.s3.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:            aws.String(c.bucket),
+			Prefix:            aws.String(prefix),
+			ContinuationToken: token,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list site files: %w", err)
+		}
+
+		if len(page.Contents) > 0 {
+			objects := make([]s3types.ObjectIdentifier, len(page.Contents))
+			for i, object := range page.Contents {
+				objects[i] = s3types.ObjectIdentifier{Key: object.Key}
+			}
+			if _, err := c.s3.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+				Bucket: aws.String(c.bucket),
+				Delete: &s3types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+			}); err != nil {
+				return fmt.Errorf("failed to delete site files: %w", err)
+			}
+		}
+
+		if !aws.ToBool(page.IsTruncated) {
+			return nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// findDistribution returns the distribution of a site, or nil if it has none
+func (c *CloudFrontClient) findDistribution(ctx context.Context, name string) (*types.DistributionSummary, error) {
+	var marker *string
+	for {
+		result, err := c.client.ListDistributions(ctx, &cloudfront.ListDistributionsInput{Marker: marker})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list distributions: %w", err)
+		}
+		list := result.DistributionList
+		if list == nil {
+			return nil, nil
+		}
+
+		for i := range list.Items {
+			if aws.ToString(list.Items[i].Comment) == commentPrefix+name {
+				return &list.Items[i], nil
+			}
+		}
+
+		if !aws.ToBool(list.IsTruncated) {
+			return nil, nil
+		}
+		marker = list.NextMarker
+	}
+}
@@ -0,0 +1,353 @@
+package cloudwatch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"snapdeploy-core/internal/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// Metric names returned by GetServiceMetrics
+const (
+	MetricCPUUtilization    = "cpu_utilization"
+	MetricMemoryUtilization = "memory_utilization"
+	MetricRequestCount      = "request_count"
+	MetricHTTP5xxCount      = "http_5xx_count"
+)
+
+// Units of the returned metrics
+const (
+	UnitPercent = "Percent"
+	UnitCount   = "Count"
+)
+
+// MetricsClient wraps AWS CloudWatch metric queries
+type MetricsClient struct {
+	client *cloudwatch.Client
+}
+
+// ServiceDimensions identifies the ECS service and ALB target group of a deployment
+type ServiceDimensions struct {
+	ClusterName  string
+	ServiceName  string
+	LoadBalancer string // e.g. app/snapdeploy-alb/50dc6c495c0c9188; ALB metrics are skipped if empty
+	TargetGroup  string // e.g. targetgroup/sd-1234/6d0ecf831eec9f09; ALB metrics are skipped if empty
+}
+
+// Point is a single metric value
+type Point struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// Series is a metric's values ordered by time
+type Series struct {
+	Name   string
+	Unit   string
+	Points []Point
+}
+
+// NewMetricsClient creates a new CloudWatch metrics client
+func NewMetricsClient() (*MetricsClient, error) {
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	tracing.InstrumentAWS(&cfg)
+
+	return &MetricsClient{
+		client: cloudwatch.NewFromConfig(cfg),
+	}, nil
+}
+
+// GetServiceMetrics returns CPU and memory utilization of an ECS service and, when the
+// load balancer dimensions are known, the request and 5xx counts of its target group
+func (c *MetricsClient) GetServiceMetrics(ctx context.Context, dims ServiceDimensions, start, end time.Time, step time.Duration) ([]Series, error) {
+	period := int32(step.Seconds())
+
+	serviceDimensions := []types.Dimension{
+		{Name: aws.String("ClusterName"), Value: aws.String(dims.ClusterName)},
+		{Name: aws.String("ServiceName"), Value: aws.String(dims.ServiceName)},
+	}
+
+	queries := []types.MetricDataQuery{
+		metricQuery(MetricCPUUtilization, "AWS/ECS", "CPUUtilization", "Average", serviceDimensions, period),
+		metricQuery(MetricMemoryUtilization, "AWS/ECS", "MemoryUtilization", "Average", serviceDimensions, period),
+	}
+	units := map[string]string{
+		MetricCPUUtilization:    UnitPercent,
+		MetricMemoryUtilization: UnitPercent,
+	}
+
+	if dims.LoadBalancer != "" && dims.TargetGroup != "" {
+		targetGroupDimensions := []types.Dimension{
+			{Name: aws.String("LoadBalancer"), Value: aws.String(dims.LoadBalancer)},
+			{Name: aws.String("TargetGroup"), Value: aws.String(dims.TargetGroup)},
+		}
+		queries = append(queries,
+			metricQuery(MetricRequestCount, "AWS/ApplicationELB", "RequestCount", "Sum", targetGroupDimensions, period),
+			metricQuery(MetricHTTP5xxCount, "AWS/ApplicationELB", "HTTPCode_Target_5XX_Count", "Sum", targetGroupDimensions, period),
+		)
+		units[MetricRequestCount] = UnitCount
+		units[MetricHTTP5xxCount] = UnitCount
+	}
+
+	points, err := c.getMetricData(ctx, queries, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make([]Series, 0, len(queries))
+	for _, query := range queries {
+		id := aws.ToString(query.Id)
+		series = append(series, Series{Name: id, Unit: units[id], Points: points[id]})
+	}
+
+	return series, nil
+}
+
+// TargetHealthPoint is the number of healthy and unhealthy targets in a target group at a point in time
+type TargetHealthPoint struct {
+	Timestamp time.Time
+	Healthy   int
+	Unhealthy int
+}
+
+// GetTargetHealth returns the healthy and unhealthy target counts of a target group per minute,
+// ordered by time. Minutes without data are omitted.
+func (c *MetricsClient) GetTargetHealth(ctx context.Context, loadBalancer, targetGroup string, start, end time.Time) ([]TargetHealthPoint, error) {
+	dimensions := []types.Dimension{
+		{Name: aws.String("LoadBalancer"), Value: aws.String(loadBalancer)},
+		{Name: aws.String("TargetGroup"), Value: aws.String(targetGroup)},
+	}
+
+	queries := []types.MetricDataQuery{
+		metricQuery("healthy", "AWS/ApplicationELB", "HealthyHostCount", "Maximum", dimensions, 60),
+		metricQuery("unhealthy", "AWS/ApplicationELB", "UnHealthyHostCount", "Maximum", dimensions, 60),
+	}
+
+	points, err := c.getMetricData(ctx, queries, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	byTime := make(map[int64]*TargetHealthPoint)
+	for _, p := range points["healthy"] {
+		byTime[p.Timestamp.Unix()] = &TargetHealthPoint{Timestamp: p.Timestamp, Healthy: int(p.Value)}
+	}
+	for _, p := range points["unhealthy"] {
+		if hp, ok := byTime[p.Timestamp.Unix()]; ok {
+			hp.Unhealthy = int(p.Value)
+		} else {
+			byTime[p.Timestamp.Unix()] = &TargetHealthPoint{Timestamp: p.Timestamp, Unhealthy: int(p.Value)}
+		}
+	}
+
+	health := make([]TargetHealthPoint, 0, len(byTime))
+	for _, hp := range byTime {
+		health = append(health, *hp)
+	}
+	sort.Slice(health, func(i, j int) bool {
+		return health[i].Timestamp.Before(health[j].Timestamp)
+	})
+
+	return health, nil
+}
+
+// Traffic summarizes the requests a target group's targets or a function version answered within a window
+type Traffic struct {
+	Requests     float64
+	Errors       float64       // Requests answered with a 5xx status by the targets, or that the function failed
+	ResponseTime time.Duration // Average time targets or the function took to respond
+}
+
+// ErrorRate returns the share of requests that failed, 0 when there were none
+func (t *Traffic) ErrorRate() float64 {
+	if t.Requests == 0 {
+		return 0
+	}
+	return t.Errors / t.Requests
+}
+
+// GetTargetGroupTraffic returns the requests, 5xx responses and average response time of a target group within a window
+func (c *MetricsClient) GetTargetGroupTraffic(ctx context.Context, loadBalancer, targetGroup string, start, end time.Time) (*Traffic, error) {
+	dimensions := []types.Dimension{
+		{Name: aws.String("LoadBalancer"), Value: aws.String(loadBalancer)},
+		{Name: aws.String("TargetGroup"), Value: aws.String(targetGroup)},
+	}
+
+	queries := []types.MetricDataQuery{
+		metricQuery("requests", "AWS/ApplicationELB", "RequestCount", "Sum", dimensions, 60),
+		metricQuery("errors", "AWS/ApplicationELB", "HTTPCode_Target_5XX_Count", "Sum", dimensions, 60),
+		metricQuery("response_time", "AWS/ApplicationELB", "TargetResponseTime", "Average", dimensions, 60),
+	}
+
+	points, err := c.getMetricData(ctx, queries, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return summarizeTraffic(points, time.Second), nil
+}
+
+// GetFunctionTraffic returns the invocations, errors and average duration of a version of a function within a
+// window, counting only the invocations that went through one of its aliases
+func (c *MetricsClient) GetFunctionTraffic(ctx context.Context, functionName, alias, version string, start, end time.Time) (*Traffic, error) {
+	dimensions := []types.Dimension{
+		{Name: aws.String("FunctionName"), Value: aws.String(functionName)},
+		{Name: aws.String("Resource"), Value: aws.String(functionName + ":" + alias)},
+		{Name: aws.String("ExecutedVersion"), Value: aws.String(version)},
+	}
+
+	queries := []types.MetricDataQuery{
+		metricQuery("requests", "AWS/Lambda", "Invocations", "Sum", dimensions, 60),
+		metricQuery("errors", "AWS/Lambda", "Errors", "Sum", dimensions, 60),
+		metricQuery("response_time", "AWS/Lambda", "Duration", "Average", dimensions, 60),
+	}
+
+	points, err := c.getMetricData(ctx, queries, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return summarizeTraffic(points, time.Millisecond), nil
+}
+
+// summarizeTraffic adds up the requests and errors of a traffic query's points and averages their response
+// time, which is measured in the given unit
+func summarizeTraffic(points map[string][]Point, unit time.Duration) *Traffic {
+	traffic := &Traffic{}
+	requestsAt := make(map[int64]float64, len(points["requests"]))
+	for _, p := range points["requests"] {
+		traffic.Requests += p.Value
+		requestsAt[p.Timestamp.Unix()] = p.Value
+	}
+	for _, p := range points["errors"] {
+		traffic.Errors += p.Value
+	}
+
+	// Weight each minute's average response time by the requests answered in it
+	var weighted, weight float64
+	for _, p := range points["response_time"] {
+		requests := requestsAt[p.Timestamp.Unix()]
+		weighted += p.Value * requests
+		weight += requests
+	}
+	if weight > 0 {
+		traffic.ResponseTime = time.Duration(weighted / weight * float64(unit))
+	}
+
+	return traffic
+}
+
+// getMetricData runs the queries over the window and returns each query's points ordered by time
+func (c *MetricsClient) getMetricData(ctx context.Context, queries []types.MetricDataQuery, start, end time.Time) (map[string][]Point, error) {
+	points := make(map[string][]Point, len(queries))
+
+	paginator := cloudwatch.NewGetMetricDataPaginator(c.client, &cloudwatch.GetMetricDataInput{
+		MetricDataQueries: queries,
+		StartTime:         aws.Time(start),
+		EndTime:           aws.Time(end),
+		ScanBy:            types.ScanByTimestampAscending,
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get metric data: %w", err)
+		}
+
+		for _, result := range page.MetricDataResults {
+			id := aws.ToString(result.Id)
+			for i, timestamp := range result.Timestamps {
+				if i < len(result.Values) {
+					points[id] = append(points[id], Point{Timestamp: timestamp, Value: result.Values[i]})
+				}
+			}
+		}
+	}
+
+	for _, seriesPoints := range points {
+		sort.Slice(seriesPoints, func(i, j int) bool {
+			return seriesPoints[i].Timestamp.Before(seriesPoints[j].Timestamp)
+		})
+	}
+
+	return points, nil
+}
+
+// GetServiceBytesSent returns the bytes the tasks of an ECS service sent over the network within a window.
+// ALB ProcessedBytes is only reported per load balancer, not per target group, so it can't be split between
+// the projects sharing the load balancer; the service's own Container Insights metric is used instead, which
+// requires Container Insights on the cluster and returns 0 without it.
+func (c *MetricsClient) GetServiceBytesSent(ctx context.Context, clusterName, serviceName string, start, end time.Time) (float64, error) {
+	dimensions := []types.Dimension{
+		{Name: aws.String("ClusterName"), Value: aws.String(clusterName)},
+		{Name: aws.String("ServiceName"), Value: aws.String(serviceName)},
+	}
+
+	queries := []types.MetricDataQuery{
+		metricQuery("bytes_sent", "ECS/ContainerInsights", "NetworkTxBytes", "Sum", dimensions, 60),
+	}
+
+	points, err := c.getMetricData(ctx, queries, start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	// NetworkTxBytes is a rate in bytes per second, so each minute sent 60 times its value
+	var bytes float64
+	for _, p := range points["bytes_sent"] {
+		bytes += p.Value * 60
+	}
+
+	return bytes, nil
+}
+
+// LoadBalancerDimension extracts the CloudWatch LoadBalancer dimension from a load balancer or listener ARN
+// e.g. arn:aws:elasticloadbalancing:...:listener/app/my-alb/50dc6c495c0c9188/f2f7dc8efc522ab2 -> app/my-alb/50dc6c495c0c9188
+func LoadBalancerDimension(arn string) string {
+	for _, prefix := range []string{":loadbalancer/", ":listener/"} {
+		if idx := strings.Index(arn, prefix); idx != -1 {
+			parts := strings.Split(arn[idx+len(prefix):], "/")
+			if len(parts) >= 3 {
+				return strings.Join(parts[:3], "/")
+			}
+		}
+	}
+	return ""
+}
+
+// TargetGroupDimension extracts the CloudWatch TargetGroup dimension from a target group ARN
+// e.g. arn:aws:elasticloadbalancing:...:targetgroup/my-tg/6d0ecf831eec9f09 -> targetgroup/my-tg/6d0ecf831eec9f09
+func TargetGroupDimension(arn string) string {
+	if idx := strings.Index(arn, ":targetgroup/"); idx != -1 {
+		return arn[idx+1:]
+	}
+	return ""
+}
+
+// metricQuery builds a query for a single metric statistic
+func metricQuery(id, namespace, metricName, stat string, dimensions []types.Dimension, period int32) types.MetricDataQuery {
+	return types.MetricDataQuery{
+		Id: aws.String(id),
+		MetricStat: &types.MetricStat{
+			Metric: &types.Metric{
+				Namespace:  aws.String(namespace),
+				MetricName: aws.String(metricName),
+				Dimensions: dimensions,
+			},
+			Period: aws.Int32(period),
+			Stat:   aws.String(stat),
+		},
+		ReturnData: aws.Bool(true),
+	}
+}
@@ -2,32 +2,56 @@ package codebuild
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"strings"
 	"time"
 
+	appconfig "snapdeploy-core/internal/config"
+	"snapdeploy-core/internal/infrastructure/awsretry"
+	"snapdeploy-core/internal/infrastructure/builder"
+	"snapdeploy-core/internal/tracing"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	logstypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
 	"github.com/aws/aws-sdk-go-v2/service/codebuild"
 	"github.com/aws/aws-sdk-go-v2/service/codebuild/types"
 )
 
+// buildArgPrefix prefixes the CodeBuild environment variables holding build args
+const buildArgPrefix = "BUILD_ARG_"
+
 // CodeBuildClient wraps AWS CodeBuild operations
 type CodeBuildClient struct {
 	client    *codebuild.Client
+	logs      *cloudwatchlogs.Client
 	projectName string
+
+	// Passed to builds, which push images to the registry
+	region         string
+	accountID      string
+	dockerRegistry string
 }
 
-// NewCodeBuildClient creates a new CodeBuild client
-func NewCodeBuildClient(projectName string) (*CodeBuildClient, error) {
+// NewCodeBuildClient creates a new CodeBuild client starting builds of the CodeBuild project in settings
+func NewCodeBuildClient(settings *appconfig.AWSConfig) (*CodeBuildClient, error) {
 	cfg, err := config.LoadDefaultConfig(context.TODO())
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
+	tracing.InstrumentAWS(&cfg)
+	awsretry.Configure(&cfg, "CodeBuild")
 
 	return &CodeBuildClient{
 		client:      codebuild.NewFromConfig(cfg),
-		projectName: projectName,
+		logs:        cloudwatchlogs.NewFromConfig(cfg),
+		projectName: settings.CodeBuildProject,
+
+		region:         settings.Region,
+		accountID:      settings.AccountID,
+		dockerRegistry: settings.DockerRegistry,
 	}, nil
 }
 
@@ -42,15 +66,16 @@ type BuildRequest struct {
 	InstallCmd    string
 	BuildCmd      string
 	RunCmd        string
+	CloneURL      string // HTTPS clone URL for private repositories, empty for public ones
+	CloneUsername string
+	CloneToken    string            // Temporary token for private repositories, passed to git through a credential helper
+	BuildArgs     map[string]string // Build-scoped environment variables, passed to docker build as build args
+	ComputeType   types.ComputeType // CPU and memory of the build, the CodeBuild project's own when empty
+	Timeout       int32             // Minutes the build may run before CodeBuild stops it, the CodeBuild project's own when 0
 }
 
 // StartBuild starts a CodeBuild build and returns the build ID
 func (c *CodeBuildClient) StartBuild(ctx context.Context, req BuildRequest) (string, error) {
-	// Get environment variables
-	region := os.Getenv("AWS_REGION")
-	accountID := os.Getenv("AWS_ACCOUNT_ID")
-	dockerRegistry := os.Getenv("DOCKER_REGISTRY")
-
 	// Build environment variables
 	envVars := []types.EnvironmentVariable{
 		{
@@ -91,18 +116,50 @@ func (c *CodeBuildClient) StartBuild(ctx context.Context, req BuildRequest) (str
 		},
 		{
 			Name:  aws.String("AWS_REGION"),
-			Value: aws.String(region),
+			Value: aws.String(c.region),
 		},
 		{
 			Name:  aws.String("AWS_ACCOUNT_ID"),
-			Value: aws.String(accountID),
+			Value: aws.String(c.accountID),
 		},
 		{
 			Name:  aws.String("DOCKER_REGISTRY"),
-			Value: aws.String(dockerRegistry),
+			Value: aws.String(c.dockerRegistry),
 		},
 	}
 
+	if req.CloneToken != "" {
+		envVars = append(envVars,
+			types.EnvironmentVariable{
+				Name:  aws.String("GIT_CLONE_URL"),
+				Value: aws.String(req.CloneURL),
+			},
+			types.EnvironmentVariable{
+				Name:  aws.String("GIT_CLONE_USERNAME"),
+				Value: aws.String(req.CloneUsername),
+			},
+			types.EnvironmentVariable{
+				Name:  aws.String("GIT_CLONE_TOKEN"),
+				Value: aws.String(req.CloneToken),
+			},
+		)
+	}
+
+	// Build args are prefixed so they can't shadow the variables the buildspec relies on
+	if len(req.BuildArgs) > 0 {
+		names := builder.BuildArgNames(req.BuildArgs)
+		for _, name := range names {
+			envVars = append(envVars, types.EnvironmentVariable{
+				Name:  aws.String(buildArgPrefix + name),
+				Value: aws.String(req.BuildArgs[name]),
+			})
+		}
+		envVars = append(envVars, types.EnvironmentVariable{
+			Name:  aws.String("BUILD_ARG_NAMES"),
+			Value: aws.String(strings.Join(names, " ")),
+		})
+	}
+
 	// Generate inline buildspec
 	buildspec := generateBuildspec()
 
@@ -112,6 +169,12 @@ func (c *CodeBuildClient) StartBuild(ctx context.Context, req BuildRequest) (str
 		EnvironmentVariablesOverride: envVars,
 		BuildspecOverride:        aws.String(buildspec),
 	}
+	if req.ComputeType != "" {
+		input.ComputeTypeOverride = req.ComputeType
+	}
+	if req.Timeout > 0 {
+		input.TimeoutInMinutesOverride = aws.Int32(req.Timeout)
+	}
 
 	result, err := c.client.StartBuild(ctx, input)
 	if err != nil {
@@ -128,14 +191,30 @@ phases:
   pre_build:
     commands:
       - echo "Cloning repository..."
-      - git clone --depth 1 --branch "$BRANCH" "$REPOSITORY_URL" /tmp/repo
-      - cd /tmp/repo
       - |
+        # The token is handed to git by a credential helper reading the environment,
+        # so it never appears in the clone URL, the remote or the build logs
+        if [ -n "$GIT_CLONE_TOKEN" ]; then
+          git config --global credential.helper '!f() { echo "username=$GIT_CLONE_USERNAME"; echo "password=$GIT_CLONE_TOKEN"; }; f'
+        fi
+      - |
+        # Blobless clones download only the files of the commit built, and have the branch's history to check it out
+        # from; commits off the branch are fetched by themselves
         if [ "$COMMIT_HASH" != "HEAD" ] && [ -n "$COMMIT_HASH" ]; then
+          git clone --filter=blob:none --no-checkout --branch "$BRANCH" "${GIT_CLONE_URL:-$REPOSITORY_URL}" /tmp/repo
+          cd /tmp/repo
           echo "Checking out commit $COMMIT_HASH"
-          git fetch origin "$COMMIT_HASH"
-          git checkout "$COMMIT_HASH"
+          git rev-parse --verify --quiet "$COMMIT_HASH^{commit}" > /dev/null || git fetch --filter=blob:none origin "$COMMIT_HASH"
+          git checkout --detach "$COMMIT_HASH"
+        else
+          git clone --filter=blob:none --branch "$BRANCH" "${GIT_CLONE_URL:-$REPOSITORY_URL}" /tmp/repo
         fi
+      - cd /tmp/repo
+      - |
+        # Forget the temporary token before any user code runs
+        git config --global --unset credential.helper || true
+        unset GIT_CLONE_TOKEN GIT_CLONE_USERNAME
+        git remote set-url origin "$REPOSITORY_URL"
       - echo "Writing Dockerfile..."
       - printf "%s" "$DOCKERFILE_CONTENT" > Dockerfile.snapdeploy
       - echo "Logging in to ECR..."
@@ -143,7 +222,13 @@ phases:
   build:
     commands:
       - echo "Building Docker image - $IMAGE_TAG"
-      - docker build -f Dockerfile.snapdeploy -t "$IMAGE_TAG" .
+      - |
+        # Build args are read back from their prefixed variables, names are valid shell identifiers
+        set --
+        for name in $BUILD_ARG_NAMES; do
+          set -- "$@" --build-arg "$name=$(printenv "BUILD_ARG_$name")"
+        done
+        docker build "$@" -f Dockerfile.snapdeploy -t "$IMAGE_TAG" .
   post_build:
     commands:
       - echo "Pushing image to ECR..."
@@ -170,41 +255,86 @@ func (c *CodeBuildClient) GetBuildStatus(ctx context.Context, buildID string) (t
 	return result.Builds[0].BuildStatus, nil
 }
 
-// WaitForBuild waits for a build to complete and returns the final status
-func (c *CodeBuildClient) WaitForBuild(ctx context.Context, buildID string, timeout time.Duration) (types.StatusType, error) {
-	deadline := time.Now().Add(timeout)
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return "", ctx.Err()
-		case <-ticker.C:
-			if time.Now().After(deadline) {
-				return "", fmt.Errorf("timeout waiting for build %s", buildID)
-			}
-
-			status, err := c.GetBuildStatus(ctx, buildID)
-			if err != nil {
-				return "", err
-			}
-
-			// Check if build is complete
-			switch status {
-			case types.StatusTypeSucceeded:
-				return status, nil
-			case types.StatusTypeFailed:
-				return status, nil
-			case types.StatusTypeFault:
-				return status, nil
-			case types.StatusTypeTimedOut:
-				return status, nil
-			case types.StatusTypeStopped:
-				return status, nil
-			}
-			// Otherwise continue waiting
+// GetBuildTimes gets the start and end time of a completed build
+func (c *CodeBuildClient) GetBuildTimes(ctx context.Context, buildID string) (time.Time, time.Time, error) {
+	result, err := c.client.BatchGetBuilds(ctx, &codebuild.BatchGetBuildsInput{
+		Ids: []string{buildID},
+	})
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to get build: %w", err)
+	}
+
+	if len(result.Builds) == 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("build not found: %s", buildID)
+	}
+
+	build := result.Builds[0]
+	if build.StartTime == nil || build.EndTime == nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("build %s has not finished", buildID)
+	}
+
+	return *build.StartTime, *build.EndTime, nil
+}
+
+// GetBuildLogStream gets the CloudWatch Logs group and stream a build writes to.
+// ok is false until CodeBuild has provisioned the build and created the stream.
+func (c *CodeBuildClient) GetBuildLogStream(ctx context.Context, buildID string) (group, stream string, ok bool, err error) {
+	result, err := c.client.BatchGetBuilds(ctx, &codebuild.BatchGetBuildsInput{
+		Ids: []string{buildID},
+	})
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to get build: %w", err)
+	}
+
+	if len(result.Builds) == 0 {
+		return "", "", false, fmt.Errorf("build not found: %s", buildID)
+	}
+
+	logs := result.Builds[0].Logs
+	if logs == nil || aws.ToString(logs.GroupName) == "" || aws.ToString(logs.StreamName) == "" {
+		return "", "", false, nil
+	}
+
+	return *logs.GroupName, *logs.StreamName, true, nil
+}
+
+// GetBuildLogLines gets the log lines written to a build's stream after the given token.
+// Pass an empty token to read from the start; the returned token continues where this call stopped.
+func (c *CodeBuildClient) GetBuildLogLines(ctx context.Context, group, stream, token string) ([]string, string, error) {
+	input := &cloudwatchlogs.GetLogEventsInput{
+		LogGroupName:  aws.String(group),
+		LogStreamName: aws.String(stream),
+		StartFromHead: aws.Bool(true),
+	}
+	if token != "" {
+		input.NextToken = aws.String(token)
+	}
+
+	result, err := c.logs.GetLogEvents(ctx, input)
+	if err != nil {
+		// The stream is only created once the build writes its first line
+		var notFound *logstypes.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return nil, token, nil
 		}
+		return nil, token, fmt.Errorf("failed to get build logs: %w", err)
 	}
+
+	lines := make([]string, 0, len(result.Events))
+	for _, event := range result.Events {
+		lines = append(lines, strings.TrimRight(aws.ToString(event.Message), "\r\n"))
+	}
+
+	return lines, aws.ToString(result.NextForwardToken), nil
 }
 
+// StopBuild stops a running build
+func (c *CodeBuildClient) StopBuild(ctx context.Context, buildID string) error {
+	_, err := c.client.StopBuild(ctx, &codebuild.StopBuildInput{
+		Id: aws.String(buildID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to stop build: %w", err)
+	}
+	return nil
+}
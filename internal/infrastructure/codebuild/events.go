@@ -0,0 +1,220 @@
+package codebuild
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"snapdeploy-core/internal/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const (
+	// buildReconcileInterval is how often a build followed with events is checked in case one was missed
+	buildReconcileInterval = time.Minute
+
+	eventReceiveWaitSeconds = 20 // Long polling, so an idle queue costs one request every 20 seconds
+	eventRetryDelay         = 5 * time.Second
+	eventSubscriberBuffer   = 16
+)
+
+// Detail types of the EventBridge events CodeBuild sends about builds
+const (
+	detailBuildStateChange = "CodeBuild Build State Change"
+	detailBuildPhaseChange = "CodeBuild Build Phase Change"
+)
+
+// BuildEvent is a change of a build's state, or a phase of the build completing
+type BuildEvent struct {
+	BuildID string
+	Status  string // Build status, e.g. IN_PROGRESS or SUCCEEDED; empty for phase changes
+
+	Phase         string // Completed phase, e.g. DOWNLOAD_SOURCE; empty for state changes
+	PhaseStatus   string
+	PhaseDuration time.Duration
+}
+
+// Done reports whether the event says the build has finished
+func (e BuildEvent) Done() bool {
+	return e.Status != "" && e.Status != "IN_PROGRESS"
+}
+
+// String describes a completed phase for the deployment logs
+func (e BuildEvent) String() string {
+	phase := strings.ToLower(strings.ReplaceAll(e.Phase, "_", " "))
+	if e.PhaseStatus == "SUCCEEDED" {
+		return fmt.Sprintf("⏱️  Build phase %s finished in %s", phase, e.PhaseDuration)
+	}
+	return fmt.Sprintf("⚠️  Build phase %s ended with %s", phase, e.PhaseStatus)
+}
+
+// EventConsumer receives the build events EventBridge sends to an SQS queue and passes them to the monitors
+// of their builds. Events of builds this instance doesn't follow are dropped, so monitors check their
+// builds themselves now and then in case another instance sharing the queue got their events.
+type EventConsumer struct {
+	client      *sqs.Client
+	queueURL    string
+	projectName string
+
+	mu          sync.Mutex
+	subscribers map[string]map[chan BuildEvent]struct{}
+}
+
+// NewEventConsumer creates a consumer of the queue, passing on the events of the CodeBuild project's builds
+func NewEventConsumer(queueURL, projectName string) (*EventConsumer, error) {
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	tracing.InstrumentAWS(&cfg)
+
+	return &EventConsumer{
+		client:      sqs.NewFromConfig(cfg),
+		queueURL:    queueURL,
+		projectName: projectName,
+		subscribers: make(map[string]map[chan BuildEvent]struct{}),
+	}, nil
+}
+
+// Subscribe returns a channel receiving the events of a build from now on, and a function ending the
+// subscription. Events arriving while the channel is full are dropped.
+func (c *EventConsumer) Subscribe(buildID string) (<-chan BuildEvent, func()) {
+	ch := make(chan BuildEvent, eventSubscriberBuffer)
+
+	c.mu.Lock()
+	if c.subscribers[buildID] == nil {
+		c.subscribers[buildID] = make(map[chan BuildEvent]struct{})
+	}
+	c.subscribers[buildID][ch] = struct{}{}
+	c.mu.Unlock()
+
+	return ch, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.subscribers[buildID], ch)
+		if len(c.subscribers[buildID]) == 0 {
+			delete(c.subscribers, buildID)
+		}
+	}
+}
+
+// Run receives events until the context is cancelled
+func (c *EventConsumer) Run(ctx context.Context) {
+	slog.Info("CodeBuild event consumer started", "queue_url", c.queueURL)
+	for ctx.Err() == nil {
+		if err := c.receive(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("Failed to receive CodeBuild events", "queue_url", c.queueURL, "error", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(eventRetryDelay):
+			}
+		}
+	}
+}
+
+// receive passes on one batch of events and deletes their messages
+func (c *EventConsumer) receive(ctx context.Context) error {
+	result, err := c.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(c.queueURL),
+		MaxNumberOfMessages: 10,
+		WaitTimeSeconds:     eventReceiveWaitSeconds,
+	})
+	if err != nil {
+		return err
+	}
+	if len(result.Messages) == 0 {
+		return nil
+	}
+
+	entries := make([]sqstypes.DeleteMessageBatchRequestEntry, 0, len(result.Messages))
+	for i, msg := range result.Messages {
+		// Messages that can't be parsed never will be, so they're deleted too
+		event, project, err := parseBuildEvent([]byte(aws.ToString(msg.Body)))
+		if err != nil {
+			slog.Warn("Dropping malformed CodeBuild event", "message_id", aws.ToString(msg.MessageId), "error", err)
+		} else if event != nil && project == c.projectName {
+			c.dispatch(*event)
+		}
+		entries = append(entries, sqstypes.DeleteMessageBatchRequestEntry{
+			Id:            aws.String(fmt.Sprintf("%d", i)),
+			ReceiptHandle: msg.ReceiptHandle,
+		})
+	}
+
+	deleted, err := c.client.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+		QueueUrl: aws.String(c.queueURL),
+		Entries:  entries,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete CodeBuild event messages: %w", err)
+	}
+	for _, failed := range deleted.Failed {
+		slog.Warn("Failed to delete CodeBuild event message", "code", aws.ToString(failed.Code), "error", aws.ToString(failed.Message))
+	}
+	return nil
+}
+
+// dispatch passes an event to the monitors of its build without waiting for them
+func (c *EventConsumer) dispatch(event BuildEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for ch := range c.subscribers[event.BuildID] {
+		select {
+		case ch <- event:
+		default:
+			slog.Warn("Dropping CodeBuild event, monitor is behind", "build_id", event.BuildID)
+		}
+	}
+}
+
+// parseBuildEvent parses an EventBridge event of a CodeBuild build and returns it with the build's project,
+// or nil for other events. Builds are identified as StartBuild returns them, project:uuid rather than by ARN.
+func parseBuildEvent(body []byte) (*BuildEvent, string, error) {
+	var envelope struct {
+		Source     string `json:"source"`
+		DetailType string `json:"detail-type"`
+		Detail     struct {
+			BuildID                       string `json:"build-id"`
+			ProjectName                   string `json:"project-name"`
+			BuildStatus                   string `json:"build-status"`
+			CompletedPhase                string `json:"completed-phase"`
+			CompletedPhaseStatus          string `json:"completed-phase-status"`
+			CompletedPhaseDurationSeconds int64  `json:"completed-phase-duration-seconds"`
+		} `json:"detail"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, "", err
+	}
+	if envelope.Source != "aws.codebuild" {
+		return nil, "", nil
+	}
+
+	detail := envelope.Detail
+	_, buildID, ok := strings.Cut(detail.BuildID, ":build/")
+	if !ok {
+		return nil, "", fmt.Errorf("unexpected build ARN %q", detail.BuildID)
+	}
+
+	switch envelope.DetailType {
+	case detailBuildStateChange:
+		return &BuildEvent{BuildID: buildID, Status: detail.BuildStatus}, detail.ProjectName, nil
+	case detailBuildPhaseChange:
+		return &BuildEvent{
+			BuildID:       buildID,
+			Phase:         detail.CompletedPhase,
+			PhaseStatus:   detail.CompletedPhaseStatus,
+			PhaseDuration: time.Duration(detail.CompletedPhaseDurationSeconds) * time.Second,
+		}, detail.ProjectName, nil
+	default:
+		return nil, "", nil
+	}
+}
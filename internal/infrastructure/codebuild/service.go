@@ -2,41 +2,66 @@ package codebuild
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
+	appconfig "snapdeploy-core/internal/config"
 	"snapdeploy-core/internal/domain/deployment"
 	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/infrastructure/awsretry"
+	"snapdeploy-core/internal/infrastructure/builder"
+	"snapdeploy-core/internal/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/service/codebuild/types"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-// SSEBroadcaster interface for broadcasting logs (avoid circular dependency)
-type SSEBroadcaster interface {
-	BroadcastLog(deploymentID string, logLine string)
-}
+const (
+	// buildLogPollInterval is how often a running build's status and CloudWatch log stream are polled
+	buildLogPollInterval = 3 * time.Second
+
+	// buildQueueAllowance is how long past its timeout a build is waited for, covering the time it is queued
+	// and provisioned before CodeBuild starts counting. CodeBuild stops builds at their timeout itself.
+	buildQueueAllowance = 15 * time.Minute
+)
 
-// DeploymentCallback is called after a successful build to trigger deployment
-type DeploymentCallback interface {
-	OnBuildSuccess(ctx context.Context, dep *deployment.Deployment, proj *project.Project, imageURI string) error
+// computeTypes maps the build sizes of projects to CodeBuild's general purpose compute types
+var computeTypes = map[project.BuildSize]types.ComputeType{
+	project.BuildSmall:  types.ComputeTypeBuildGeneral1Small,
+	project.BuildMedium: types.ComputeTypeBuildGeneral1Medium,
+	project.BuildLarge:  types.ComputeTypeBuildGeneral1Large,
 }
 
 // CodeBuildService orchestrates builds using AWS CodeBuild
 type CodeBuildService struct {
-	client            *CodeBuildClient
-	deploymentRepo    deployment.DeploymentRepository
-	projectRepo       project.ProjectRepository
-	sseManager        SSEBroadcaster
-	deploymentCallback DeploymentCallback
-	currentImageTag   string       // Store image tag for callback
-	currentProjectID  project.ProjectID // Store project ID to fetch fresh data on deployment
+	client             *CodeBuildClient
+	deploymentRepo     deployment.DeploymentRepository
+	projectRepo        project.ProjectRepository
+	sseManager         builder.SSEBroadcaster
+	deploymentCallback builder.DeploymentCallback
+	usageRecorder      builder.UsageRecorder
+	cloneCredentials   builder.CloneCredentialsProvider
+	tracker            *builder.BuildTracker
+	events             BuildEventSource // Builds' statuses are polled without it
+}
+
+// BuildEventSource passes on the events CodeBuild sends about builds as they happen
+type BuildEventSource interface {
+	Subscribe(buildID string) (<-chan BuildEvent, func())
 }
 
-// NewCodeBuildService creates a new CodeBuild service
+var _ builder.BuildBackend = (*CodeBuildService)(nil)
+
+// NewCodeBuildService creates a new CodeBuild service building with the CodeBuild project in settings
 func NewCodeBuildService(
-	projectName string,
+	settings *appconfig.AWSConfig,
 	deploymentRepo deployment.DeploymentRepository,
 	projectRepo project.ProjectRepository,
 ) (*CodeBuildService, error) {
-	client, err := NewCodeBuildClient(projectName)
+	client, err := NewCodeBuildClient(settings)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create CodeBuild client: %w", err)
 	}
@@ -45,34 +70,40 @@ func NewCodeBuildService(
 		client:         client,
 		deploymentRepo: deploymentRepo,
 		projectRepo:    projectRepo,
+		tracker:        builder.NewBuildTracker(),
 	}, nil
 }
 
 // SetSSEManager sets the SSE manager for real-time log streaming
 func (s *CodeBuildService) SetSSEManager(manager interface{}) {
-	if m, ok := manager.(SSEBroadcaster); ok {
+	if m, ok := manager.(builder.SSEBroadcaster); ok {
 		s.sseManager = m
 	}
 }
 
 // SetDeploymentCallback sets the callback to be invoked after successful build
-func (s *CodeBuildService) SetDeploymentCallback(callback DeploymentCallback) {
+func (s *CodeBuildService) SetDeploymentCallback(callback builder.DeploymentCallback) {
 	s.deploymentCallback = callback
 }
 
-// ServiceBuildRequest contains all information needed to build a deployment
-type ServiceBuildRequest struct {
-	Deployment     *deployment.Deployment
-	Project        *project.Project
-	RepositoryURL  string
-	Branch         string
-	CommitHash     string
-	ImageTag       string
-	Dockerfile     string
+// SetUsageRecorder sets the recorder used to meter build minutes
+func (s *CodeBuildService) SetUsageRecorder(recorder builder.UsageRecorder) {
+	s.usageRecorder = recorder
+}
+
+// SetBuildEvents sets the source of the build events builds are followed with instead of polling their
+// status (optional). Their output is still read from CloudWatch Logs as it is written.
+func (s *CodeBuildService) SetBuildEvents(events BuildEventSource) {
+	s.events = events
+}
+
+// SetCloneCredentialsProvider sets the provider of credentials used to clone private repositories
+func (s *CodeBuildService) SetCloneCredentialsProvider(provider builder.CloneCredentialsProvider) {
+	s.cloneCredentials = provider
 }
 
 // StartBuild starts a CodeBuild build for a deployment
-func (s *CodeBuildService) StartBuild(ctx context.Context, req ServiceBuildRequest) (string, error) {
+func (s *CodeBuildService) StartBuild(ctx context.Context, req builder.BuildRequest) (string, error) {
 	dep := req.Deployment
 	proj := req.Project
 
@@ -87,6 +118,10 @@ func (s *CodeBuildService) StartBuild(ctx context.Context, req ServiceBuildReque
 	// Log initial message
 	s.logAndUpdate(ctx, dep, "Starting build process with AWS CodeBuild...")
 
+	// Throttled or failing CodeBuild calls are retried, show the retries in the build logs
+	logCtx := ctx
+	ctx = awsretry.WithReporter(ctx, func(message string) { s.logAndUpdate(logCtx, dep, "🔁 "+message) })
+
 	// Prepare CodeBuild request
 	buildReq := BuildRequest{
 		RepositoryURL: req.RepositoryURL,
@@ -98,36 +133,90 @@ func (s *CodeBuildService) StartBuild(ctx context.Context, req ServiceBuildReque
 		InstallCmd:    proj.InstallCommand().String(),
 		BuildCmd:      proj.BuildCommand().String(),
 		RunCmd:        proj.RunCommand().String(),
+		BuildArgs:     req.BuildArgs,
+		ComputeType:   computeTypes[proj.BuildSize()],
+		Timeout:       int32(proj.BuildTimeoutMinutes()),
+	}
+
+	// Private repositories are cloned with credentials from the repository's provider
+	if s.cloneCredentials != nil {
+		creds, err := s.cloneCredentials.GetCloneCredentials(ctx, proj)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to get clone credentials", "project_id", proj.ID().String(), "error", err)
+			s.logAndUpdate(ctx, dep, "⚠️ Could not get access to the repository, cloning without credentials")
+		} else if creds != nil {
+			buildReq.CloneURL = creds.URL
+			buildReq.CloneUsername = creds.Username
+			buildReq.CloneToken = creds.Token
+			dep.MaskInLogs(creds.Token)
+			s.logAndUpdate(ctx, dep, "🔑 Cloning with repository access")
+		}
 	}
 
 	// Start the build
 	buildID, err := s.client.StartBuild(ctx, buildReq)
 	if err != nil {
-		s.logAndUpdate(ctx, dep, fmt.Sprintf("Failed to start CodeBuild: %v", err))
+		// The error may echo the request's environment, so the clone token is masked before it reaches any log
+		message := redact(err.Error(), buildReq.CloneToken)
+		s.logAndUpdate(ctx, dep, fmt.Sprintf("Failed to start CodeBuild: %s", message))
 		dep.UpdateStatus(deployment.StatusFailed)
 		s.deploymentRepo.Save(ctx, dep)
-		return "", fmt.Errorf("failed to start CodeBuild: %w", err)
+		return "", fmt.Errorf("failed to start CodeBuild: %s", message)
 	}
 
 	s.logAndUpdate(ctx, dep, fmt.Sprintf("CodeBuild build started: %s", buildID))
-	s.logAndUpdate(ctx, dep, "Build is running in isolated environment...")
-
-	// Store image tag and project ID for deployment callback
-	// We store only the ID and fetch fresh project data when deploying
-	// This ensures we always use the latest project configuration (e.g., updated custom_domain)
-	s.currentImageTag = req.ImageTag
-	s.currentProjectID = proj.ID()
+	s.logAndUpdate(ctx, dep, fmt.Sprintf("Build is running in isolated environment (%s, at most %d minutes)...",
+		buildReq.ComputeType, buildReq.Timeout))
 
-	// Start monitoring build status in background
-	go s.monitorBuild(ctx, dep, buildID)
+	// Start monitoring build status in background, until the build is over or a shutdown interrupts it
+	followCtx, done := s.tracker.Follow(ctx)
+	go func() {
+		defer done()
+		s.monitorBuild(followCtx, dep, proj.ID(), req.ImageTag, buildID, proj.BuildTimeout())
+	}()
 
 	return buildID, nil
 }
 
-// monitorBuild monitors the build status and updates deployment accordingly
-func (s *CodeBuildService) monitorBuild(ctx context.Context, dep *deployment.Deployment, buildID string) {
-	// Wait for build to complete (with 30 minute timeout)
-	status, err := s.client.WaitForBuild(ctx, buildID, 30*time.Minute)
+// Shutdown waits for the CodeBuild builds followed by this instance to finish until ctx is done, then stops the rest
+func (s *CodeBuildService) Shutdown(ctx context.Context) []deployment.DeploymentID {
+	return s.tracker.Shutdown(ctx)
+}
+
+// monitorBuild follows the build until it finishes and updates the deployment accordingly.
+// Only the project ID is kept, so the deployment picks up the latest project configuration (e.g., updated custom_domain).
+func (s *CodeBuildService) monitorBuild(ctx context.Context, dep *deployment.Deployment, projectID project.ProjectID, imageTag, buildID string, timeout time.Duration) {
+	// Forward the build's own output to the deployment logs until it finishes
+	waitCtx, span := tracing.Start(ctx, "codebuild.build", attribute.String("codebuild.build_id", buildID))
+	waitCtx, cancel := context.WithTimeout(waitCtx, timeout+buildQueueAllowance)
+	err := s.StreamLogs(waitCtx, buildID, func(lines []string) {
+		s.appendLogs(ctx, dep, lines)
+	})
+	cancel()
+
+	if s.tracker.Interrupted() {
+		tracing.End(span, ctx.Err())
+		s.checkpoint(ctx, dep, buildID)
+		return
+	}
+
+	var status builder.BuildStatus
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		// A build still running well past its timeout never left CodeBuild's queue, or is stuck
+		if cancelErr := s.Cancel(ctx, buildID); cancelErr != nil {
+			slog.ErrorContext(ctx, "Failed to stop build", "build_id", buildID, "error", cancelErr)
+		}
+		status, err = builder.BuildTimedOut, nil
+	} else if err == nil {
+		status, err = s.Status(ctx, buildID)
+	}
+
+	span.SetAttributes(attribute.String("codebuild.status", string(status)))
+	if err == nil && status != builder.BuildSucceeded {
+		tracing.End(span, fmt.Errorf("build ended with status %s", status))
+	} else {
+		tracing.End(span, err)
+	}
 	if err != nil {
 		s.logAndUpdate(ctx, dep, fmt.Sprintf("Error monitoring build: %v", err))
 		dep.UpdateStatus(deployment.StatusFailed)
@@ -135,28 +224,31 @@ func (s *CodeBuildService) monitorBuild(ctx context.Context, dep *deployment.Dep
 		return
 	}
 
+	// Meter build minutes whatever the outcome, failed builds are billed too
+	s.recordBuildUsage(ctx, dep, buildID)
+
 	// Update deployment status based on build result
 	switch status {
-	case "SUCCEEDED":
+	case builder.BuildSucceeded:
 		s.logAndUpdate(ctx, dep, "✅ Build completed successfully!")
 		s.logAndUpdate(ctx, dep, "📦 Image pushed to registry successfully")
-		
+
 		// Fetch fresh project data to ensure we have the latest configuration
 		// This is critical for picking up changes like updated custom_domain
-		proj, err := s.projectRepo.FindByID(ctx, s.currentProjectID)
+		proj, err := s.projectRepo.FindByID(ctx, projectID)
 		if err != nil {
 			s.logAndUpdate(ctx, dep, fmt.Sprintf("❌ Failed to fetch project data: %v", err))
 			dep.UpdateStatus(deployment.StatusFailed)
 			s.deploymentRepo.Save(ctx, dep)
 			return
 		}
-		
+
 		// Trigger ECS deployment if callback is set
 		if s.deploymentCallback != nil {
 			s.logAndUpdate(ctx, dep, "🚀 Triggering deployment to ECS...")
 			s.deploymentRepo.Save(ctx, dep)
-			
-			if err := s.deploymentCallback.OnBuildSuccess(ctx, dep, proj, s.currentImageTag); err != nil {
+
+			if err := s.deploymentCallback.OnBuildSuccess(ctx, dep, proj, imageTag); err != nil {
 				s.logAndUpdate(ctx, dep, fmt.Sprintf("❌ Deployment to ECS failed: %v", err))
 				dep.UpdateStatus(deployment.StatusFailed)
 			}
@@ -165,17 +257,168 @@ func (s *CodeBuildService) monitorBuild(ctx context.Context, dep *deployment.Dep
 			// Fallback to old behavior if no callback is set
 			dep.UpdateStatus(deployment.StatusDeployed)
 		}
-	case "FAILED", "FAULT", "TIMED_OUT", "STOPPED":
-		s.logAndUpdate(ctx, dep, fmt.Sprintf("❌ Build failed with status: %s", status))
+	case builder.BuildTimedOut:
+		s.logAndUpdate(ctx, dep, builder.TimedOutMessage(timeout))
 		dep.UpdateStatus(deployment.StatusFailed)
 	default:
-		s.logAndUpdate(ctx, dep, fmt.Sprintf("⚠️  Build ended with status: %s", status))
+		s.logAndUpdate(ctx, dep, fmt.Sprintf("❌ Build failed with status: %s", status))
 		dep.UpdateStatus(deployment.StatusFailed)
 	}
 
 	s.deploymentRepo.Save(ctx, dep)
 }
 
+// StreamLogs passes the lines the build writes to CloudWatch Logs to onLines until the build has finished.
+// Followed with build events, the phases the build completes are passed on too, and its status is only
+// read when it is said to have finished, or now and then in case an event was missed.
+func (s *CodeBuildService) StreamLogs(ctx context.Context, buildID string, onLines func(lines []string)) error {
+	ticker := time.NewTicker(buildLogPollInterval)
+	defer ticker.Stop()
+
+	var events <-chan BuildEvent
+	if s.events != nil {
+		ch, unsubscribe := s.events.Subscribe(buildID)
+		defer unsubscribe()
+		events = ch
+	}
+	lastChecked := time.Now()
+
+	var group, stream, token string
+	poll := func() {
+		if stream == "" {
+			logGroup, logStream, ok, err := s.client.GetBuildLogStream(ctx, buildID)
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to get build log stream", "build_id", buildID, "error", err)
+				return
+			}
+			if !ok {
+				return
+			}
+			group, stream = logGroup, logStream
+		}
+
+		// A stream can hold more lines than one call returns; the token stops moving once it is drained
+		for {
+			lines, next, err := s.client.GetBuildLogLines(ctx, group, stream, token)
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to get build logs", "build_id", buildID, "error", err)
+				return
+			}
+			if len(lines) > 0 {
+				onLines(lines)
+			}
+			if len(lines) == 0 || next == token {
+				token = next
+				return
+			}
+			token = next
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event := <-events:
+			if event.Phase != "" {
+				onLines([]string{event.String()})
+			}
+			if event.Done() {
+				// Whatever the build wrote just before finishing is read once more
+				poll()
+				return nil
+			}
+		case <-ticker.C:
+			if events != nil && time.Since(lastChecked) < buildReconcileInterval {
+				poll()
+				continue
+			}
+			lastChecked = time.Now()
+
+			// The status is read first, so the last poll also gets what the build wrote just before finishing
+			status, err := s.Status(ctx, buildID)
+			if err != nil {
+				return err
+			}
+			poll()
+			if status.Done() {
+				return nil
+			}
+		}
+	}
+}
+
+// Cancel stops a running CodeBuild build
+func (s *CodeBuildService) Cancel(ctx context.Context, buildID string) error {
+	return s.client.StopBuild(ctx, buildID)
+}
+
+// Status reports where a CodeBuild build is at
+func (s *CodeBuildService) Status(ctx context.Context, buildID string) (builder.BuildStatus, error) {
+	status, err := s.client.GetBuildStatus(ctx, buildID)
+	if err != nil {
+		return "", err
+	}
+
+	switch status {
+	case types.StatusTypeSucceeded:
+		return builder.BuildSucceeded, nil
+	case types.StatusTypeFailed, types.StatusTypeFault:
+		return builder.BuildFailed, nil
+	case types.StatusTypeTimedOut:
+		return builder.BuildTimedOut, nil
+	case types.StatusTypeStopped:
+		return builder.BuildStopped, nil
+	default:
+		return builder.BuildInProgress, nil
+	}
+}
+
+// appendLogs appends build output to the deployment logs and broadcasts it, saving once for the whole batch
+func (s *CodeBuildService) appendLogs(ctx context.Context, dep *deployment.Deployment, lines []string) {
+	for _, line := range lines {
+		dep.AppendLog(line)
+		if s.sseManager != nil {
+			s.sseManager.BroadcastLog(dep.ID().String(), dep.RedactLog(line))
+		}
+	}
+
+	s.deploymentRepo.Save(ctx, dep)
+}
+
+// checkpoint stops a build a shutdown interrupted and records it, so the next build worker starts it over.
+// The build is stopped rather than left running, as nothing would deploy the image it pushes.
+func (s *CodeBuildService) checkpoint(ctx context.Context, dep *deployment.Deployment, buildID string) {
+	saveCtx, cancel := s.tracker.Checkpoint(ctx, dep.ID())
+	defer cancel()
+
+	if err := s.Cancel(saveCtx, buildID); err != nil {
+		slog.ErrorContext(saveCtx, "Failed to stop build", "build_id", buildID, "error", err)
+	}
+	if err := dep.Interrupt(); err != nil {
+		slog.ErrorContext(saveCtx, "Failed to interrupt deployment", "deployment_id", dep.ID().String(), "error", err)
+		return
+	}
+	s.appendLogs(saveCtx, dep, []string{builder.InterruptedMessage})
+}
+
+// recordBuildUsage records the build minutes consumed by a finished build
+func (s *CodeBuildService) recordBuildUsage(ctx context.Context, dep *deployment.Deployment, buildID string) {
+	if s.usageRecorder == nil {
+		return
+	}
+
+	startedAt, endedAt, err := s.client.GetBuildTimes(ctx, buildID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get build times", "build_id", buildID, "error", err)
+		return
+	}
+
+	if err := s.usageRecorder.RecordBuild(ctx, dep, startedAt, endedAt); err != nil {
+		slog.ErrorContext(ctx, "Failed to record build usage", "deployment_id", dep.ID().String(), "error", err)
+	}
+}
+
 // logAndUpdate logs a message and updates the deployment
 func (s *CodeBuildService) logAndUpdate(ctx context.Context, dep *deployment.Deployment, message string) {
 	// Append to deployment logs
@@ -183,10 +426,17 @@ func (s *CodeBuildService) logAndUpdate(ctx context.Context, dep *deployment.Dep
 
 	// Broadcast to SSE clients (real-time)
 	if s.sseManager != nil {
-		s.sseManager.BroadcastLog(dep.ID().String(), message)
+		s.sseManager.BroadcastLog(dep.ID().String(), dep.RedactLog(message))
 	}
 
 	// Save to database
 	s.deploymentRepo.Save(ctx, dep)
 }
 
+// redact masks every occurrence of a secret in a message
+func redact(message, secret string) string {
+	if secret == "" {
+		return message
+	}
+	return strings.ReplaceAll(message, secret, "***")
+}
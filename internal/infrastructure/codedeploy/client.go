@@ -0,0 +1,295 @@
+package codedeploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"snapdeploy-core/internal/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy/types"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// deploymentConfigName shifts all traffic to the new task set at once, once it passes health checks
+	deploymentConfigName = "CodeDeployDefault.ECSAllAtOnce"
+	// blueTerminationWait is how long the old task set is kept after traffic has shifted,
+	// so a deployment can still be rolled back without starting tasks
+	blueTerminationWait = 5 // minutes
+)
+
+// CodeDeployClient wraps AWS CodeDeploy operations used for blue/green ECS deployments
+type CodeDeployClient struct {
+	client         *codedeploy.Client
+	serviceRoleArn string
+}
+
+// NewCodeDeployClient creates a new CodeDeploy client shifting traffic with the service role
+func NewCodeDeployClient(serviceRoleArn string) (*CodeDeployClient, error) {
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	tracing.InstrumentAWS(&cfg)
+
+	if serviceRoleArn == "" {
+		return nil, fmt.Errorf("CODEDEPLOY_SERVICE_ROLE_ARN is not set")
+	}
+
+	return &CodeDeployClient{
+		client:         codedeploy.NewFromConfig(cfg),
+		serviceRoleArn: serviceRoleArn,
+	}, nil
+}
+
+// BlueGreenRequest contains information needed to deploy an ECS service blue/green.
+// The application and deployment group are both named after the service.
+type BlueGreenRequest struct {
+	ClusterName          string
+	ServiceName          string
+	TaskDefinitionArn    string
+	ContainerName        string
+	ContainerPort        int32
+	BlueTargetGroupName  string
+	GreenTargetGroupName string
+	ProdListenerArn      string
+	TestListenerArn      string // Optional, lets the new task set be reached before traffic shifts
+}
+
+// EnsureDeploymentGroup creates the service's CodeDeploy application and deployment group,
+// or brings an existing deployment group up to date
+func (c *CodeDeployClient) EnsureDeploymentGroup(ctx context.Context, req BlueGreenRequest) error {
+	_, err := c.client.GetApplication(ctx, &codedeploy.GetApplicationInput{
+		ApplicationName: aws.String(req.ServiceName),
+	})
+	if err != nil {
+		var notFound *types.ApplicationDoesNotExistException
+		if !errors.As(err, &notFound) {
+			return fmt.Errorf("failed to get application: %w", err)
+		}
+
+		_, err = c.client.CreateApplication(ctx, &codedeploy.CreateApplicationInput{
+			ApplicationName: aws.String(req.ServiceName),
+			ComputePlatform: types.ComputePlatformEcs,
+			Tags: []types.Tag{
+				{Key: aws.String("ManagedBy"), Value: aws.String("SnapDeploy")},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create application: %w", err)
+		}
+		slog.InfoContext(ctx, "Created CodeDeploy application", "service", req.ServiceName)
+	}
+
+	style := &types.DeploymentStyle{
+		DeploymentType:   types.DeploymentTypeBlueGreen,
+		DeploymentOption: types.DeploymentOptionWithTrafficControl,
+	}
+	blueGreen := &types.BlueGreenDeploymentConfiguration{
+		DeploymentReadyOption: &types.DeploymentReadyOption{
+			ActionOnTimeout: types.DeploymentReadyActionContinueDeployment,
+		},
+		TerminateBlueInstancesOnDeploymentSuccess: &types.BlueInstanceTerminationOption{
+			Action:                       types.InstanceActionTerminate,
+			TerminationWaitTimeInMinutes: blueTerminationWait,
+		},
+	}
+	rollback := &types.AutoRollbackConfiguration{
+		Enabled: true,
+		Events: []types.AutoRollbackEvent{
+			types.AutoRollbackEventDeploymentFailure,
+			types.AutoRollbackEventDeploymentStopOnRequest,
+		},
+	}
+	services := []types.ECSService{
+		{ClusterName: aws.String(req.ClusterName), ServiceName: aws.String(req.ServiceName)},
+	}
+	targetGroups := types.TargetGroupPairInfo{
+		TargetGroups: []types.TargetGroupInfo{
+			{Name: aws.String(req.BlueTargetGroupName)},
+			{Name: aws.String(req.GreenTargetGroupName)},
+		},
+		ProdTrafficRoute: &types.TrafficRoute{ListenerArns: []string{req.ProdListenerArn}},
+	}
+	if req.TestListenerArn != "" {
+		targetGroups.TestTrafficRoute = &types.TrafficRoute{ListenerArns: []string{req.TestListenerArn}}
+	}
+	loadBalancer := &types.LoadBalancerInfo{
+		TargetGroupPairInfoList: []types.TargetGroupPairInfo{targetGroups},
+	}
+
+	_, err = c.client.GetDeploymentGroup(ctx, &codedeploy.GetDeploymentGroupInput{
+		ApplicationName:     aws.String(req.ServiceName),
+		DeploymentGroupName: aws.String(req.ServiceName),
+	})
+	if err == nil {
+		_, err = c.client.UpdateDeploymentGroup(ctx, &codedeploy.UpdateDeploymentGroupInput{
+			ApplicationName:                  aws.String(req.ServiceName),
+			CurrentDeploymentGroupName:       aws.String(req.ServiceName),
+			ServiceRoleArn:                   aws.String(c.serviceRoleArn),
+			DeploymentConfigName:             aws.String(deploymentConfigName),
+			DeploymentStyle:                  style,
+			BlueGreenDeploymentConfiguration: blueGreen,
+			AutoRollbackConfiguration:        rollback,
+			EcsServices:                      services,
+			LoadBalancerInfo:                 loadBalancer,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update deployment group: %w", err)
+		}
+		return nil
+	}
+
+	var notFound *types.DeploymentGroupDoesNotExistException
+	if !errors.As(err, &notFound) {
+		return fmt.Errorf("failed to get deployment group: %w", err)
+	}
+
+	_, err = c.client.CreateDeploymentGroup(ctx, &codedeploy.CreateDeploymentGroupInput{
+		ApplicationName:                  aws.String(req.ServiceName),
+		DeploymentGroupName:              aws.String(req.ServiceName),
+		ServiceRoleArn:                   aws.String(c.serviceRoleArn),
+		DeploymentConfigName:             aws.String(deploymentConfigName),
+		DeploymentStyle:                  style,
+		BlueGreenDeploymentConfiguration: blueGreen,
+		AutoRollbackConfiguration:        rollback,
+		EcsServices:                      services,
+		LoadBalancerInfo:                 loadBalancer,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create deployment group: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Created CodeDeploy deployment group", "service", req.ServiceName)
+	return nil
+}
+
+// appSpec is the AppSpec file CodeDeploy reads to replace an ECS service's task set
+type appSpec struct {
+	Version   int               `json:"version"`
+	Resources []appSpecResource `json:"Resources"`
+}
+
+type appSpecResource struct {
+	TargetService appSpecTargetService `json:"TargetService"`
+}
+
+type appSpecTargetService struct {
+	Type       string            `json:"Type"`
+	Properties appSpecProperties `json:"Properties"`
+}
+
+type appSpecProperties struct {
+	TaskDefinition   string                  `json:"TaskDefinition"`
+	LoadBalancerInfo appSpecLoadBalancerInfo `json:"LoadBalancerInfo"`
+}
+
+type appSpecLoadBalancerInfo struct {
+	ContainerName string `json:"ContainerName"`
+	ContainerPort int32  `json:"ContainerPort"`
+}
+
+// CreateDeployment starts a blue/green deployment of the task definition and returns its ID.
+// CodeDeploy starts a replacement task set, shifts traffic to it once it is healthy and rolls back if it fails.
+func (c *CodeDeployClient) CreateDeployment(ctx context.Context, req BlueGreenRequest, description string) (string, error) {
+	content, err := json.Marshal(appSpec{
+		Version: 1,
+		Resources: []appSpecResource{{
+			TargetService: appSpecTargetService{
+				Type: "AWS::ECS::Service",
+				Properties: appSpecProperties{
+					TaskDefinition: req.TaskDefinitionArn,
+					LoadBalancerInfo: appSpecLoadBalancerInfo{
+						ContainerName: req.ContainerName,
+						ContainerPort: req.ContainerPort,
+					},
+				},
+			},
+		}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode AppSpec: %w", err)
+	}
+
+	result, err := c.client.CreateDeployment(ctx, &codedeploy.CreateDeploymentInput{
+		ApplicationName:     aws.String(req.ServiceName),
+		DeploymentGroupName: aws.String(req.ServiceName),
+		Description:         aws.String(description),
+		Revision: &types.RevisionLocation{
+			RevisionType: types.RevisionLocationTypeAppSpecContent,
+			AppSpecContent: &types.AppSpecContent{
+				Content: aws.String(string(content)),
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create deployment: %w", err)
+	}
+
+	return aws.ToString(result.DeploymentId), nil
+}
+
+// WaitForDeployment waits until a deployment has shifted traffic to the new task set.
+// A deployment that doesn't get there in time is stopped, which rolls traffic back to the old task set.
+func (c *CodeDeployClient) WaitForDeployment(ctx context.Context, deploymentID string, timeout time.Duration) (err error) {
+	ctx, span := tracing.Start(ctx, "codedeploy.wait", attribute.String("codedeploy.deployment_id", deploymentID))
+	defer func() { tracing.End(span, err) }()
+
+	deadline := time.Now().Add(timeout)
+
+	for time.Now().Before(deadline) {
+		result, err := c.client.GetDeployment(ctx, &codedeploy.GetDeploymentInput{
+			DeploymentId: aws.String(deploymentID),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get deployment: %w", err)
+		}
+
+		info := result.DeploymentInfo
+		switch info.Status {
+		case types.DeploymentStatusSucceeded:
+			return nil
+		case types.DeploymentStatusFailed, types.DeploymentStatusStopped:
+			reason := string(info.Status)
+			if info.ErrorInformation != nil && info.ErrorInformation.Message != nil {
+				reason = *info.ErrorInformation.Message
+			}
+			return fmt.Errorf("deployment %s: %s", deploymentID, reason)
+		}
+
+		// Wait before checking again
+		time.Sleep(10 * time.Second)
+	}
+
+	if _, err := c.client.StopDeployment(ctx, &codedeploy.StopDeploymentInput{
+		DeploymentId:        aws.String(deploymentID),
+		AutoRollbackEnabled: aws.Bool(true),
+	}); err != nil {
+		slog.WarnContext(ctx, "Failed to stop timed out deployment", "deployment_id", deploymentID, "error", err)
+	}
+
+	return fmt.Errorf("timeout waiting for deployment %s, it was stopped and rolled back", deploymentID)
+}
+
+// DeleteApplication deletes a service's CodeDeploy application along with its deployment group
+func (c *CodeDeployClient) DeleteApplication(ctx context.Context, serviceName string) error {
+	_, err := c.client.DeleteApplication(ctx, &codedeploy.DeleteApplicationInput{
+		ApplicationName: aws.String(serviceName),
+	})
+	if err != nil {
+		var notFound *types.ApplicationDoesNotExistException
+		if errors.As(err, &notFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete application: %w", err)
+	}
+
+	return nil
+}
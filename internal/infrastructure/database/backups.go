@@ -0,0 +1,168 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// backupSuffix separates a database's name from the time its backup was taken
+	backupSuffix     = "_bak_"
+	backupTimeFormat = "20060102150405"
+	// maxBackups is how many backups are kept per project database, older ones are dropped
+	maxBackups = 3
+)
+
+// Backup is a copy of a project database taken before a destructive operation
+type Backup struct {
+	Name      string
+	CreatedAt time.Time
+	SizeBytes int64
+}
+
+// DatabaseInfo describes a project database and its backups
+type DatabaseInfo struct {
+	Name      string
+	Exists    bool
+	SizeBytes int64
+	Backups   []Backup // Newest first
+}
+
+// DescribeDatabase returns the size of a project database and the backups taken of it
+func (m *PostgresManager) DescribeDatabase(ctx context.Context, dbName string) (*DatabaseInfo, error) {
+	info := &DatabaseInfo{Name: dbName}
+
+	err := m.masterDB.QueryRowContext(ctx,
+		"SELECT pg_database_size(datname) FROM pg_database WHERE datname = $1", dbName,
+	).Scan(&info.SizeBytes)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return nil, fmt.Errorf("failed to get database size: %w", err)
+	default:
+		info.Exists = true
+	}
+
+	backups, err := m.ListBackups(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+	info.Backups = backups
+
+	return info, nil
+}
+
+// BackupDatabase copies a database to a new database named after it and the current time, and returns
+// the copy's name. Only the newest backups are kept.
+func (m *PostgresManager) BackupDatabase(ctx context.Context, dbName string) (string, error) {
+	backup := dbName + backupSuffix + time.Now().UTC().Format(backupTimeFormat)
+	slog.InfoContext(ctx, "Backing up database", "database", dbName, "backup", backup)
+
+	if err := m.copyDatabase(ctx, dbName, backup); err != nil {
+		return "", fmt.Errorf("failed to back up database %s: %w", dbName, err)
+	}
+
+	m.pruneBackups(ctx, dbName)
+	return backup, nil
+}
+
+// copyDatabase copies a database that may be in use to a new database
+func (m *PostgresManager) copyDatabase(ctx context.Context, source, target string) error {
+	// A database can only be copied while nothing is connected to it, so new connections are
+	// refused until the copy is done
+	if _, err := m.masterDB.ExecContext(ctx, fmt.Sprintf("ALTER DATABASE %s ALLOW_CONNECTIONS false", source)); err != nil {
+		return fmt.Errorf("failed to lock database %s: %w", source, err)
+	}
+	defer func() {
+		if _, err := m.masterDB.ExecContext(ctx, fmt.Sprintf("ALTER DATABASE %s ALLOW_CONNECTIONS true", source)); err != nil {
+			slog.ErrorContext(ctx, "Failed to unlock database", "database", source, "error", err)
+		}
+	}()
+	m.terminateConnections(ctx, source)
+
+	return m.createDatabase(ctx, target, source)
+}
+
+// ListBackups returns the backups of a database, newest first
+func (m *PostgresManager) ListBackups(ctx context.Context, dbName string) ([]Backup, error) {
+	prefix := dbName + backupSuffix
+	rows, err := m.masterDB.QueryContext(ctx,
+		"SELECT datname, pg_database_size(datname) FROM pg_database WHERE starts_with(datname, $1) ORDER BY datname DESC", prefix,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	defer rows.Close()
+
+	var backups []Backup
+	for rows.Next() {
+		var b Backup
+		if err := rows.Scan(&b.Name, &b.SizeBytes); err != nil {
+			return nil, fmt.Errorf("failed to scan backup: %w", err)
+		}
+		createdAt, err := time.Parse(backupTimeFormat, strings.TrimPrefix(b.Name, prefix))
+		if err != nil {
+			// Not one of ours
+			continue
+		}
+		b.CreatedAt = createdAt
+		backups = append(backups, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	return backups, nil
+}
+
+// DropCopies drops every backup, snapshot and branch taken of a database
+func (m *PostgresManager) DropCopies(ctx context.Context, dbName string) error {
+	rows, err := m.masterDB.QueryContext(ctx,
+		"SELECT datname FROM pg_database WHERE starts_with(datname, $1)", dbName+"_",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to list copies: %w", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan copy: %w", err)
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list copies: %w", err)
+	}
+
+	for _, name := range names {
+		if err := m.DropDatabase(ctx, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneBackups drops all but the newest backups of a database
+func (m *PostgresManager) pruneBackups(ctx context.Context, dbName string) {
+	backups, err := m.ListBackups(ctx, dbName)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to list backups to prune", "database", dbName, "error", err)
+		return
+	}
+	if len(backups) <= maxBackups {
+		return
+	}
+	for _, b := range backups[maxBackups:] {
+		if err := m.DropDatabase(ctx, b.Name); err != nil {
+			slog.WarnContext(ctx, "Failed to drop old backup", "database", dbName, "backup", b.Name, "error", err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// SnapshotDatabaseName generates the name of the database holding a snapshot of a project's database
+func SnapshotDatabaseName(projectID, snapshotID string) string {
+	return fmt.Sprintf("%s_snap_%s", GetDatabaseName(projectID), snapshotID[:8])
+}
+
+// BranchDatabaseName generates the name of a database restored from a snapshot of a project's database
+func BranchDatabaseName(projectID, branchID string) string {
+	return fmt.Sprintf("%s_br_%s", GetDatabaseName(projectID), branchID[:8])
+}
+
+// SnapshotDatabase copies a database to a snapshot and returns the snapshot's size.
+// The snapshot refuses connections so it stays as it was taken.
+func (m *PostgresManager) SnapshotDatabase(ctx context.Context, dbName, snapshotName string) (int64, error) {
+	slog.InfoContext(ctx, "Taking database snapshot", "database", dbName, "snapshot", snapshotName)
+
+	if err := m.copyDatabase(ctx, dbName, snapshotName); err != nil {
+		return 0, fmt.Errorf("failed to snapshot database %s: %w", dbName, err)
+	}
+
+	if _, err := m.masterDB.ExecContext(ctx, fmt.Sprintf("ALTER DATABASE %s ALLOW_CONNECTIONS false", snapshotName)); err != nil {
+		if dropErr := m.DropDatabase(ctx, snapshotName); dropErr != nil {
+			slog.WarnContext(ctx, "Failed to drop unfinished snapshot", "snapshot", snapshotName, "error", dropErr)
+		}
+		return 0, fmt.Errorf("failed to lock snapshot %s: %w", snapshotName, err)
+	}
+
+	var size int64
+	if err := m.masterDB.QueryRowContext(ctx, "SELECT pg_database_size($1)", snapshotName).Scan(&size); err != nil {
+		return 0, fmt.Errorf("failed to get snapshot size: %w", err)
+	}
+
+	return size, nil
+}
+
+// RestoreSnapshot creates a database from a snapshot
+func (m *PostgresManager) RestoreSnapshot(ctx context.Context, snapshotName, branchName string) error {
+	slog.InfoContext(ctx, "Restoring database snapshot", "snapshot", snapshotName, "branch", branchName)
+
+	// Nothing connects to a snapshot, so it can be used as a template as it is
+	if err := m.createDatabase(ctx, branchName, snapshotName); err != nil {
+		return fmt.Errorf("failed to restore snapshot %s: %w", snapshotName, err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"snapdeploy-core/internal/config"
+
+	"github.com/XSAM/otelsql"
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// mysqlDatastore identifies MySQL accounts in the credential store
+const mysqlDatastore = "MYSQL"
+
+// MySQLManager handles creation and deletion of project databases on the platform's MySQL server. Each project
+// connects with an account of its own, only granted access to its database; the server's admin account never
+// leaves the control plane.
+type MySQLManager struct {
+	masterDB    *sql.DB
+	host        string
+	port        string
+	credentials CredentialStore
+}
+
+// NewMySQLManager creates a new MySQL database manager administering the server
+func NewMySQLManager(server config.DatastoreServerConfig) (*MySQLManager, error) {
+	host, port, user, password := server.Host, server.Port, server.User, server.Password
+	if host == "" || port == "" || user == "" || password == "" {
+		return nil, fmt.Errorf("MySQL server settings are not set")
+	}
+
+	// Connect without selecting a database to create/drop project databases
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/?tls=preferred", user, password, host, port)
+
+	db, err := otelsql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MySQL server: %w", err)
+	}
+
+	// Test connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping MySQL server: %w", err)
+	}
+
+	slog.Info("Connected to MySQL server", "host", host, "port", port)
+
+	return &MySQLManager{
+		masterDB: db,
+		host:     host,
+		port:     port,
+	}, nil
+}
+
+// SetCredentialStore sets where the passwords of the projects' accounts are kept. Without one, databases can't
+// be provisioned, as projects would have nothing but the admin account to connect with.
+func (m *MySQLManager) SetCredentialStore(store CredentialStore) {
+	m.credentials = store
+}
+
+// Provision creates a project's MySQL database and account unless they already exist
+func (m *MySQLManager) Provision(ctx context.Context, projectID string) (*Provisioned, error) {
+	if m.credentials == nil {
+		return nil, fmt.Errorf("MySQL credential store is not configured")
+	}
+	dbName := GetDatabaseName(projectID)
+
+	var name string
+	err := m.masterDB.QueryRowContext(ctx,
+		"SELECT SCHEMA_NAME FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME = ?", dbName,
+	).Scan(&name)
+	created := false
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		slog.InfoContext(ctx, "Creating MySQL database", "database", dbName)
+		if _, err := m.masterDB.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", dbName)); err != nil {
+			return nil, fmt.Errorf("failed to create MySQL database %s: %w", dbName, err)
+		}
+		created = true
+	case err != nil:
+		return nil, fmt.Errorf("failed to check MySQL database existence: %w", err)
+	}
+
+	user, password, err := m.ensureUser(ctx, projectID, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Provisioned{
+		Name:    "MySQL database " + dbName,
+		Created: created,
+		EnvVars: map[string]string{"MYSQL_URL": m.databaseURL(user, password, dbName)},
+	}, nil
+}
+
+// ensureUser creates the project's account, only granted access to its database, and returns its credentials.
+// The password is saved before the account is created, so no account has a password that was lost.
+func (m *MySQLManager) ensureUser(ctx context.Context, projectID, dbName string) (string, string, error) {
+	user, password, err := m.credentials.FindCredential(ctx, projectID, mysqlDatastore)
+	if err != nil {
+		return "", "", err
+	}
+	if user == "" {
+		user = dbName
+		if password, err = generatePassword(); err != nil {
+			return "", "", err
+		}
+		saved, err := m.credentials.CreateCredential(ctx, projectID, mysqlDatastore, user, password)
+		if err != nil {
+			return "", "", err
+		}
+		// Another instance is provisioning the project too, use the password it saved
+		if !saved {
+			if user, password, err = m.credentials.FindCredential(ctx, projectID, mysqlDatastore); err != nil {
+				return "", "", err
+			}
+		}
+	}
+
+	// Passwords are hex, and user and database names are derived from UUIDs, so they can be quoted as is.
+	// Setting the password of an existing account keeps it in step with the stored one.
+	for _, statement := range []string{
+		fmt.Sprintf("CREATE USER IF NOT EXISTS '%s'@'%%' IDENTIFIED BY '%s'", user, password),
+		fmt.Sprintf("ALTER USER '%s'@'%%' IDENTIFIED BY '%s'", user, password),
+		fmt.Sprintf("GRANT ALL PRIVILEGES ON `%s`.* TO '%s'@'%%'", dbName, user),
+	} {
+		if _, err := m.masterDB.ExecContext(ctx, statement); err != nil {
+			return "", "", fmt.Errorf("failed to set up MySQL user %s: %w", user, err)
+		}
+	}
+	return user, password, nil
+}
+
+// Deprovision drops a project's MySQL database and account
+func (m *MySQLManager) Deprovision(ctx context.Context, projectID string) error {
+	dbName := GetDatabaseName(projectID)
+	slog.InfoContext(ctx, "Dropping MySQL database", "database", dbName)
+
+	if _, err := m.masterDB.ExecContext(ctx, fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", dbName)); err != nil {
+		return fmt.Errorf("failed to drop MySQL database %s: %w", dbName, err)
+	}
+	if _, err := m.masterDB.ExecContext(ctx, fmt.Sprintf("DROP USER IF EXISTS '%s'@'%%'", dbName)); err != nil {
+		return fmt.Errorf("failed to drop MySQL user %s: %w", dbName, err)
+	}
+	if m.credentials != nil {
+		return m.credentials.DeleteCredential(ctx, projectID, mysqlDatastore)
+	}
+	return nil
+}
+
+// databaseURL returns the connection string of a project's account to its MySQL database
+func (m *MySQLManager) databaseURL(user, password, dbName string) string {
+	return fmt.Sprintf("mysql://%s:%s@%s:%s/%s", user, password, m.host, m.port, dbName)
+}
+
+// generatePassword returns a random hex password for a project's account
+func generatePassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Close closes the connection to the MySQL server
+func (m *MySQLManager) Close() error {
+	if m.masterDB != nil {
+		return m.masterDB.Close()
+	}
+	return nil
+}
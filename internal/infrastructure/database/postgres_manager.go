@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
 
+	"snapdeploy-core/internal/config"
+
+	"github.com/XSAM/otelsql"
 	_ "github.com/lib/pq"
 )
 
@@ -19,23 +21,18 @@ type PostgresManager struct {
 	password string
 }
 
-// NewPostgresManager creates a new PostgreSQL database manager
-func NewPostgresManager() (*PostgresManager, error) {
-	host := os.Getenv("RDS_HOST")
-	port := os.Getenv("RDS_PORT")
-	user := os.Getenv("RDS_USER")
-	password := os.Getenv("RDS_PASSWORD")
-	masterDBName := os.Getenv("RDS_DATABASE")
-
+// NewPostgresManager creates a new PostgreSQL database manager administering the server
+func NewPostgresManager(server config.DatastoreServerConfig) (*PostgresManager, error) {
+	host, port, user, password, masterDBName := server.Host, server.Port, server.User, server.Password, server.Database
 	if host == "" || port == "" || user == "" || password == "" || masterDBName == "" {
-		return nil, fmt.Errorf("missing required RDS environment variables")
+		return nil, fmt.Errorf("RDS server settings are not set")
 	}
 
 	// Connect to master database (postgres) to create/drop other databases
 	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=require",
 		host, port, user, password, masterDBName)
 
-	db, err := sql.Open("postgres", connStr)
+	db, err := otelsql.Open("postgres", connStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to master database: %w", err)
 	}
@@ -46,7 +43,7 @@ func NewPostgresManager() (*PostgresManager, error) {
 		return nil, fmt.Errorf("failed to ping master database: %w", err)
 	}
 
-	log.Printf("[PostgresManager] Connected to master database at %s:%s", host, port)
+	slog.Info("Connected to master database", "host", host, "port", port)
 
 	return &PostgresManager{
 		masterDB: db,
@@ -57,57 +54,95 @@ func NewPostgresManager() (*PostgresManager, error) {
 	}, nil
 }
 
-// CreateDatabase creates a new database for a project
-// If the database already exists, it will be dropped and recreated (fresh state)
-func (m *PostgresManager) CreateDatabase(ctx context.Context, dbName string) error {
-	log.Printf("[PostgresManager] Creating database: %s", dbName)
+// EnsureDatabase creates a project's database unless it already exists, keeping its data across deployments.
+// It reports whether the database was created.
+func (m *PostgresManager) EnsureDatabase(ctx context.Context, dbName string) (bool, error) {
+	exists, err := m.DatabaseExists(ctx, dbName)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
 
-	// First, drop the database if it exists (we want fresh database on each deployment)
-	if err := m.DropDatabase(ctx, dbName); err != nil {
-		log.Printf("[PostgresManager] Warning: failed to drop existing database %s: %v", dbName, err)
-		// Continue anyway - database might not exist
+	if err := m.createDatabase(ctx, dbName, ""); err != nil {
+		return false, err
 	}
+	return true, nil
+}
 
-	// Create the database
-	query := fmt.Sprintf("CREATE DATABASE %s", dbName)
-	_, err := m.masterDB.ExecContext(ctx, query)
+// ResetDatabase backs up a project's database and replaces it with an empty one.
+// It returns the name of the backup, which is empty if there was no database to back up.
+func (m *PostgresManager) ResetDatabase(ctx context.Context, dbName string) (string, error) {
+	exists, err := m.DatabaseExists(ctx, dbName)
 	if err != nil {
+		return "", err
+	}
+
+	var backup string
+	if exists {
+		if backup, err = m.BackupDatabase(ctx, dbName); err != nil {
+			return "", err
+		}
+		if err := m.DropDatabase(ctx, dbName); err != nil {
+			return "", err
+		}
+	}
+
+	if err := m.createDatabase(ctx, dbName, ""); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
+
+// createDatabase creates a database, as a copy of template if one is given
+func (m *PostgresManager) createDatabase(ctx context.Context, dbName, template string) error {
+	slog.InfoContext(ctx, "Creating database", "database", dbName, "template", template)
+
+	query := fmt.Sprintf("CREATE DATABASE %s", dbName)
+	if template != "" {
+		query += fmt.Sprintf(" TEMPLATE %s", template)
+	}
+	if _, err := m.masterDB.ExecContext(ctx, query); err != nil {
 		return fmt.Errorf("failed to create database %s: %w", dbName, err)
 	}
 
-	log.Printf("[PostgresManager] Successfully created database: %s", dbName)
+	slog.InfoContext(ctx, "Created database", "database", dbName)
 	return nil
 }
 
 // DropDatabase drops a database
 func (m *PostgresManager) DropDatabase(ctx context.Context, dbName string) error {
-	log.Printf("[PostgresManager] Dropping database: %s", dbName)
+	slog.InfoContext(ctx, "Dropping database", "database", dbName)
 
 	// Terminate all connections to the database first
-	terminateQuery := fmt.Sprintf(`
-		SELECT pg_terminate_backend(pg_stat_activity.pid)
-		FROM pg_stat_activity
-		WHERE pg_stat_activity.datname = '%s'
-		AND pid <> pg_backend_pid()
-	`, dbName)
-
-	_, err := m.masterDB.ExecContext(ctx, terminateQuery)
-	if err != nil {
-		log.Printf("[PostgresManager] Warning: failed to terminate connections for %s: %v", dbName, err)
-		// Continue anyway
-	}
+	m.terminateConnections(ctx, dbName)
 
 	// Drop the database
 	query := fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbName)
-	_, err = m.masterDB.ExecContext(ctx, query)
+	_, err := m.masterDB.ExecContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to drop database %s: %w", dbName, err)
 	}
 
-	log.Printf("[PostgresManager] Successfully dropped database: %s", dbName)
+	slog.InfoContext(ctx, "Dropped database", "database", dbName)
 	return nil
 }
 
+// terminateConnections closes every other session connected to a database
+func (m *PostgresManager) terminateConnections(ctx context.Context, dbName string) {
+	terminateQuery := `
+		SELECT pg_terminate_backend(pg_stat_activity.pid)
+		FROM pg_stat_activity
+		WHERE pg_stat_activity.datname = $1
+		AND pid <> pg_backend_pid()
+	`
+	if _, err := m.masterDB.ExecContext(ctx, terminateQuery, dbName); err != nil {
+		slog.WarnContext(ctx, "Failed to terminate connections", "database", dbName, "error", err)
+		// Continue anyway
+	}
+}
+
 // DatabaseExists checks if a database exists
 func (m *PostgresManager) DatabaseExists(ctx context.Context, dbName string) (bool, error) {
 	query := "SELECT 1 FROM pg_database WHERE datname = $1"
@@ -0,0 +1,38 @@
+package database
+
+import "context"
+
+// Provisioner creates and drops one kind of datastore for projects
+type Provisioner interface {
+	// Provision creates a project's datastore unless it already exists, keeping its data across deployments
+	Provision(ctx context.Context, projectID string) (*Provisioned, error)
+	// Deprovision drops a project's datastore and its data
+	Deprovision(ctx context.Context, projectID string) error
+}
+
+// Provisioned describes how a project's services reach one of its datastores
+type Provisioned struct {
+	Name    string // Name of the datastore, for deployment logs
+	Created bool
+	EnvVars map[string]string
+	// Sidecar is set for datastores that run next to the project in each of its tasks
+	Sidecar *Sidecar
+}
+
+// Sidecar is a container run next to a project's own container in each of its tasks
+type Sidecar struct {
+	Name  string
+	Image string
+	Port  int32
+}
+
+// CredentialStore keeps the accounts projects connect to shared datastore servers with, shared by every server
+// instance so they all hand out the same password
+type CredentialStore interface {
+	// FindCredential returns a project's account on a datastore, an empty username if it has none
+	FindCredential(ctx context.Context, projectID, datastore string) (username, password string, err error)
+	// CreateCredential saves a project's account on a datastore. It returns false if the project already has one.
+	CreateCredential(ctx context.Context, projectID, datastore, username, password string) (bool, error)
+	// DeleteCredential forgets a project's account on a datastore
+	DeleteCredential(ctx context.Context, projectID, datastore string) error
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// defaultRedisImage is pulled from the ECR Public mirror of Docker Hub to avoid its rate limits
+	defaultRedisImage = "public.ecr.aws/docker/library/redis:7-alpine"
+	redisPort         = 6379
+)
+
+// RedisSidecar runs Redis in each of a project's tasks, next to the project's own container.
+// Its data lives as long as the task, so it suits caches and sessions rather than durable data.
+type RedisSidecar struct {
+	image string
+}
+
+// NewRedisSidecar creates a provisioner of Redis sidecars running image, or the default image if it is empty
+func NewRedisSidecar(image string) *RedisSidecar {
+	if image == "" {
+		image = defaultRedisImage
+	}
+	return &RedisSidecar{image: image}
+}
+
+// Provision returns the sidecar to run next to the project, which starts empty with every task
+func (r *RedisSidecar) Provision(ctx context.Context, projectID string) (*Provisioned, error) {
+	return &Provisioned{
+		Name:    "Redis sidecar",
+		Created: true,
+		EnvVars: map[string]string{"REDIS_URL": fmt.Sprintf("redis://localhost:%d", redisPort)},
+		Sidecar: &Sidecar{
+			Name:  "redis",
+			Image: r.image,
+			Port:  redisPort,
+		},
+	}, nil
+}
+
+// Deprovision does nothing, a sidecar's data is gone once its task stops
+func (r *RedisSidecar) Deprovision(ctx context.Context, projectID string) error {
+	return nil
+}
@@ -0,0 +1,318 @@
+package ecr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"snapdeploy-core/internal/infrastructure/awsretry"
+	"snapdeploy-core/internal/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// maxBatchDeleteSize is the maximum number of image IDs accepted by BatchDeleteImage
+const maxBatchDeleteSize = 100
+
+// ECRClient wraps AWS Elastic Container Registry operations
+type ECRClient struct {
+	client           *ecr.Client
+	registry         string
+	pullPrincipalARN string
+}
+
+// IsECRRegistry reports whether a registry URL points at Amazon ECR
+func IsECRRegistry(registry string) bool {
+	return strings.Contains(registry, ".ecr.") && strings.Contains(registry, ".amazonaws.com")
+}
+
+// NewECRClient creates a new ECR client managing the repositories of the registry, which the principal
+// (the deployment execution role) is allowed to pull from
+func NewECRClient(registry, pullPrincipalARN string) (*ECRClient, error) {
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	tracing.InstrumentAWS(&cfg)
+	awsretry.Configure(&cfg, "ECR")
+
+	if registry == "" {
+		return nil, fmt.Errorf("DOCKER_REGISTRY is not set")
+	}
+
+	return &ECRClient{
+		client:           ecr.NewFromConfig(cfg),
+		registry:         registry,
+		pullPrincipalARN: pullPrincipalARN,
+	}, nil
+}
+
+// EnsureProjectRepository creates the project's repository if needed and applies
+// its lifecycle and access policies. Returns the repository URI.
+func (c *ECRClient) EnsureProjectRepository(ctx context.Context, projectID string) (string, error) {
+	repositoryName := projectID
+
+	_, err := c.client.CreateRepository(ctx, &ecr.CreateRepositoryInput{
+		RepositoryName: aws.String(repositoryName),
+		// Mutable so HEAD builds can keep moving the "latest" tag
+		ImageTagMutability: types.ImageTagMutabilityMutable,
+		ImageScanningConfiguration: &types.ImageScanningConfiguration{
+			ScanOnPush: true,
+		},
+		Tags: []types.Tag{
+			{Key: aws.String("snapdeploy:project-id"), Value: aws.String(projectID)},
+			{Key: aws.String("snapdeploy:managed-by"), Value: aws.String("snapdeploy-core")},
+		},
+	})
+	if err != nil && !isRepositoryAlreadyExists(err) {
+		return "", fmt.Errorf("failed to create repository: %w", err)
+	}
+	if err == nil {
+		slog.InfoContext(ctx, "Created ECR repository", "repository", repositoryName)
+	}
+
+	// Policies are re-applied on every build so configuration changes reach existing repositories
+	if err := c.putLifecyclePolicy(ctx, repositoryName); err != nil {
+		return "", err
+	}
+	if err := c.setRepositoryPolicy(ctx, repositoryName); err != nil {
+		return "", err
+	}
+
+	return c.RepositoryURI(projectID), nil
+}
+
+// RepositoryURI returns the URI of a project's repository
+func (c *ECRClient) RepositoryURI(projectID string) string {
+	return fmt.Sprintf("%s/%s", c.registryHost(), projectID)
+}
+
+// ImageDigest returns the digest of an image pushed to the registry, or "" for images hosted elsewhere
+func (c *ECRClient) ImageDigest(ctx context.Context, imageURI string) (string, error) {
+	repository, imageID, ok := c.imageIdentifier(imageURI)
+	if !ok {
+		return "", nil
+	}
+	if imageID.ImageDigest != nil {
+		// The image is already pinned by its digest
+		return *imageID.ImageDigest, nil
+	}
+
+	result, err := c.client.DescribeImages(ctx, &ecr.DescribeImagesInput{
+		RepositoryName: aws.String(repository),
+		ImageIds:       []types.ImageIdentifier{imageID},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe image: %w", err)
+	}
+	if len(result.ImageDetails) == 0 {
+		return "", fmt.Errorf("image %s not found", imageURI)
+	}
+	return aws.ToString(result.ImageDetails[0].ImageDigest), nil
+}
+
+// imageIdentifier splits the URI of an image pushed to the registry into its repository and the tag or
+// digest identifying it. ok is false for images hosted elsewhere.
+func (c *ECRClient) imageIdentifier(imageURI string) (repository string, imageID types.ImageIdentifier, ok bool) {
+	repository, found := strings.CutPrefix(imageURI, c.registryHost()+"/")
+	if !found {
+		return "", types.ImageIdentifier{}, false
+	}
+
+	if name, digest, pinned := strings.Cut(repository, "@"); pinned {
+		return name, types.ImageIdentifier{ImageDigest: aws.String(digest)}, true
+	}
+	if colon := strings.LastIndex(repository, ":"); colon >= 0 {
+		return repository[:colon], types.ImageIdentifier{ImageTag: aws.String(repository[colon+1:])}, true
+	}
+	return repository, types.ImageIdentifier{ImageTag: aws.String("latest")}, true
+}
+
+// DeleteProjectImages deletes all images pushed for a project
+func (c *ECRClient) DeleteProjectImages(ctx context.Context, projectID string) error {
+	// Projects built before the per-project layout pushed <project-id>-<commit> tags
+	// into the shared repository; clean those up as well
+	if repositoryName, ok := c.sharedRepositoryName(); ok {
+		if err := c.deleteTaggedImages(ctx, repositoryName, projectID+"-"); err != nil {
+			return err
+		}
+	}
+
+	return c.deleteRepository(ctx, projectID)
+}
+
+// registryHost returns the registry host without any repository path
+func (c *ECRClient) registryHost() string {
+	return strings.SplitN(c.registry, "/", 2)[0]
+}
+
+// putLifecyclePolicy expires untagged images. Tagged images are left to PruneProjectImages,
+// which knows which of them deployments still use.
+func (c *ECRClient) putLifecyclePolicy(ctx context.Context, repositoryName string) error {
+	policy := map[string]interface{}{
+		"rules": []map[string]interface{}{
+			{
+				"rulePriority": 1,
+				"description":  "Expire untagged images after 1 day",
+				"selection": map[string]interface{}{
+					"tagStatus":   "untagged",
+					"countType":   "sinceImagePushed",
+					"countUnit":   "days",
+					"countNumber": 1,
+				},
+				"action": map[string]string{"type": "expire"},
+			},
+		},
+	}
+
+	policyText, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("failed to encode lifecycle policy: %w", err)
+	}
+
+	_, err = c.client.PutLifecyclePolicy(ctx, &ecr.PutLifecyclePolicyInput{
+		RepositoryName:      aws.String(repositoryName),
+		LifecyclePolicyText: aws.String(string(policyText)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put lifecycle policy: %w", err)
+	}
+	return nil
+}
+
+// setRepositoryPolicy grants the deployment execution role pull access to the repository
+func (c *ECRClient) setRepositoryPolicy(ctx context.Context, repositoryName string) error {
+	if c.pullPrincipalARN == "" {
+		return nil
+	}
+
+	policy := map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []map[string]interface{}{
+			{
+				"Sid":       "AllowDeploymentPull",
+				"Effect":    "Allow",
+				"Principal": map[string]string{"AWS": c.pullPrincipalARN},
+				"Action": []string{
+					"ecr:BatchCheckLayerAvailability",
+					"ecr:BatchGetImage",
+					"ecr:GetDownloadUrlForLayer",
+				},
+			},
+		},
+	}
+
+	policyText, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("failed to encode repository policy: %w", err)
+	}
+
+	_, err = c.client.SetRepositoryPolicy(ctx, &ecr.SetRepositoryPolicyInput{
+		RepositoryName: aws.String(repositoryName),
+		PolicyText:     aws.String(string(policyText)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set repository policy: %w", err)
+	}
+	return nil
+}
+
+// sharedRepositoryName returns the repository name if DOCKER_REGISTRY points at a single repository
+func (c *ECRClient) sharedRepositoryName() (string, bool) {
+	parts := strings.SplitN(c.registry, "/", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// deleteTaggedImages deletes images in a repository whose tag starts with the given prefix
+func (c *ECRClient) deleteTaggedImages(ctx context.Context, repositoryName, tagPrefix string) error {
+	var imageIDs []types.ImageIdentifier
+
+	paginator := ecr.NewListImagesPaginator(c.client, &ecr.ListImagesInput{
+		RepositoryName: aws.String(repositoryName),
+		Filter: &types.ListImagesFilter{
+			TagStatus: types.TagStatusTagged,
+		},
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			if isRepositoryNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to list images: %w", err)
+		}
+
+		for _, image := range page.ImageIds {
+			if image.ImageTag != nil && strings.HasPrefix(*image.ImageTag, tagPrefix) {
+				imageIDs = append(imageIDs, image)
+			}
+		}
+	}
+
+	deleted := 0
+	for start := 0; start < len(imageIDs); start += maxBatchDeleteSize {
+		end := start + maxBatchDeleteSize
+		if end > len(imageIDs) {
+			end = len(imageIDs)
+		}
+
+		result, err := c.client.BatchDeleteImage(ctx, &ecr.BatchDeleteImageInput{
+			RepositoryName: aws.String(repositoryName),
+			ImageIds:       imageIDs[start:end],
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete images: %w", err)
+		}
+
+		deleted += len(result.ImageIds)
+		for _, failure := range result.Failures {
+			tag := ""
+			if failure.ImageId != nil {
+				tag = aws.ToString(failure.ImageId.ImageTag)
+			}
+			slog.WarnContext(ctx, "Failed to delete image", "tag", tag, "reason", aws.ToString(failure.FailureReason))
+		}
+	}
+
+	slog.InfoContext(ctx, "Deleted images", "count", deleted, "tag_prefix", tagPrefix, "repository", repositoryName)
+	return nil
+}
+
+// deleteRepository deletes a repository together with all of its images
+func (c *ECRClient) deleteRepository(ctx context.Context, repositoryName string) error {
+	_, err := c.client.DeleteRepository(ctx, &ecr.DeleteRepositoryInput{
+		RepositoryName: aws.String(repositoryName),
+		Force:          true,
+	})
+	if err != nil {
+		if isRepositoryNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete repository: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Deleted ECR repository", "repository", repositoryName)
+	return nil
+}
+
+// isRepositoryAlreadyExists checks if the error indicates the repository already exists
+func isRepositoryAlreadyExists(err error) bool {
+	var exists *types.RepositoryAlreadyExistsException
+	return errors.As(err, &exists)
+}
+
+// isRepositoryNotFound checks if the error indicates the repository doesn't exist
+func isRepositoryNotFound(err error) bool {
+	var notFound *types.RepositoryNotFoundException
+	return errors.As(err, &notFound)
+}
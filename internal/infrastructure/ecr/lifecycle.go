@@ -0,0 +1,98 @@
+package ecr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// PruneProjectImages deletes the tagged images in a project's repository that carry none of the kept tags.
+// Images pushed at or after pushedBefore are left alone, since a build may have pushed them for a
+// deployment the caller didn't know about yet. In dry-run mode images are only logged.
+// Returns how many images were (or would have been) deleted.
+func (c *ECRClient) PruneProjectImages(ctx context.Context, projectID string, keepTags []string, pushedBefore time.Time, dryRun bool) (int, error) {
+	repositoryName := projectID
+
+	kept := make(map[string]bool, len(keepTags))
+	for _, tag := range keepTags {
+		kept[tag] = true
+	}
+
+	var imageIDs []types.ImageIdentifier
+
+	paginator := ecr.NewDescribeImagesPaginator(c.client, &ecr.DescribeImagesInput{
+		RepositoryName: aws.String(repositoryName),
+		Filter: &types.DescribeImagesFilter{
+			TagStatus: types.TagStatusTagged,
+		},
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			if isRepositoryNotFound(err) {
+				return 0, nil
+			}
+			return 0, fmt.Errorf("failed to describe images: %w", err)
+		}
+
+		for _, image := range page.ImageDetails {
+			if image.ImagePushedAt == nil || !image.ImagePushedAt.Before(pushedBefore) || hasKeptTag(image.ImageTags, kept) {
+				continue
+			}
+
+			if dryRun {
+				slog.InfoContext(ctx, "Would delete unused image", "repository", repositoryName, "tags", image.ImageTags,
+					"pushed_at", *image.ImagePushedAt)
+			}
+			imageIDs = append(imageIDs, types.ImageIdentifier{ImageDigest: image.ImageDigest})
+		}
+	}
+
+	if dryRun || len(imageIDs) == 0 {
+		return len(imageIDs), nil
+	}
+
+	deleted := 0
+	for start := 0; start < len(imageIDs); start += maxBatchDeleteSize {
+		end := start + maxBatchDeleteSize
+		if end > len(imageIDs) {
+			end = len(imageIDs)
+		}
+
+		result, err := c.client.BatchDeleteImage(ctx, &ecr.BatchDeleteImageInput{
+			RepositoryName: aws.String(repositoryName),
+			ImageIds:       imageIDs[start:end],
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete images: %w", err)
+		}
+
+		deleted += len(result.ImageIds)
+		for _, failure := range result.Failures {
+			digest := ""
+			if failure.ImageId != nil {
+				digest = aws.ToString(failure.ImageId.ImageDigest)
+			}
+			slog.WarnContext(ctx, "Failed to delete image", "digest", digest, "reason", aws.ToString(failure.FailureReason))
+		}
+	}
+
+	slog.InfoContext(ctx, "Deleted unused images", "count", deleted, "repository", repositoryName)
+	return deleted, nil
+}
+
+// hasKeptTag reports whether any of an image's tags is kept
+func hasKeptTag(tags []string, kept map[string]bool) bool {
+	for _, tag := range tags {
+		if kept[tag] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package ecr
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+// scanPollInterval is how often the status of an image scan is checked while waiting for its findings
+const scanPollInterval = 5 * time.Second
+
+// ErrNotInRegistry is returned when scanning an image that wasn't pushed to the ECR registry
+var ErrNotInRegistry = errors.New("image is not in the ECR registry")
+
+// ScanImage starts a scan of an image pushed to the registry, unless one already ran on push, and waits for
+// its findings until the context is done. Returns the vulnerabilities found and the number of each severity.
+func (c *ECRClient) ScanImage(ctx context.Context, imageURI string) ([]deployment.Vulnerability, map[deployment.Severity]int, error) {
+	repository, imageID, ok := c.imageIdentifier(imageURI)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotInRegistry, imageURI)
+	}
+
+	_, err := c.client.StartImageScan(ctx, &ecr.StartImageScanInput{
+		RepositoryName: aws.String(repository),
+		ImageId:        &imageID,
+	})
+	// Images are scanned on push, and ECR only scans an image once a day
+	if err != nil && !isScanLimitExceeded(err) {
+		return nil, nil, fmt.Errorf("failed to start image scan: %w", err)
+	}
+
+	if err := c.waitForScan(ctx, repository, imageID); err != nil {
+		return nil, nil, err
+	}
+	return c.scanFindings(ctx, repository, imageID)
+}
+
+// waitForScan waits until the scan of an image has finished
+func (c *ECRClient) waitForScan(ctx context.Context, repository string, imageID types.ImageIdentifier) error {
+	for {
+		result, err := c.client.DescribeImageScanFindings(ctx, &ecr.DescribeImageScanFindingsInput{
+			RepositoryName: aws.String(repository),
+			ImageId:        &imageID,
+			MaxResults:     aws.Int32(1),
+		})
+		if err != nil && !isScanNotFound(err) {
+			return fmt.Errorf("failed to get image scan status: %w", err)
+		}
+		if err == nil && result.ImageScanStatus != nil {
+			switch status := result.ImageScanStatus.Status; status {
+			// Enhanced scanning keeps monitoring images, ACTIVE once the findings are in
+			case types.ScanStatusComplete, types.ScanStatusActive:
+				return nil
+			case types.ScanStatusInProgress, types.ScanStatusPending:
+			default:
+				return fmt.Errorf("image scan is %s: %s", status, aws.ToString(result.ImageScanStatus.Description))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for the image scan: %w", ctx.Err())
+		case <-time.After(scanPollInterval):
+		}
+	}
+}
+
+// scanFindings reads all the findings of an image's finished scan
+func (c *ECRClient) scanFindings(ctx context.Context, repository string, imageID types.ImageIdentifier) ([]deployment.Vulnerability, map[deployment.Severity]int, error) {
+	var vulnerabilities []deployment.Vulnerability
+	counts := map[deployment.Severity]int{}
+
+	paginator := ecr.NewDescribeImageScanFindingsPaginator(c.client, &ecr.DescribeImageScanFindingsInput{
+		RepositoryName: aws.String(repository),
+		ImageId:        &imageID,
+	})
+	for first := true; paginator.HasMorePages(); first = false {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get image scan findings: %w", err)
+		}
+		if page.ImageScanFindings == nil {
+			continue
+		}
+
+		// Every page repeats the counts of all findings
+		if first {
+			for severity, count := range page.ImageScanFindings.FindingSeverityCounts {
+				counts[deployment.NewSeverity(severity)] += int(count)
+			}
+		}
+		for _, finding := range page.ImageScanFindings.Findings {
+			vulnerabilities = append(vulnerabilities, basicVulnerability(finding))
+		}
+		for _, finding := range page.ImageScanFindings.EnhancedFindings {
+			vulnerabilities = append(vulnerabilities, enhancedVulnerability(finding))
+		}
+	}
+
+	return vulnerabilities, counts, nil
+}
+
+// basicVulnerability converts a finding of ECR's basic scanning
+func basicVulnerability(finding types.ImageScanFinding) deployment.Vulnerability {
+	v := deployment.Vulnerability{
+		ID:       aws.ToString(finding.Name),
+		Severity: deployment.NewSeverity(string(finding.Severity)),
+		URL:      aws.ToString(finding.Uri),
+	}
+	for _, attribute := range finding.Attributes {
+		switch aws.ToString(attribute.Key) {
+		case "package_name":
+			v.Package = aws.ToString(attribute.Value)
+		case "package_version":
+			v.Version = aws.ToString(attribute.Value)
+		}
+	}
+	return v
+}
+
+// enhancedVulnerability converts a finding of Amazon Inspector's enhanced scanning
+func enhancedVulnerability(finding types.EnhancedImageScanFinding) deployment.Vulnerability {
+	v := deployment.Vulnerability{
+		ID:       aws.ToString(finding.Title),
+		Severity: deployment.NewSeverity(aws.ToString(finding.Severity)),
+	}
+	if details := finding.PackageVulnerabilityDetails; details != nil {
+		v.ID = aws.ToString(details.VulnerabilityId)
+		v.URL = aws.ToString(details.SourceUrl)
+		if len(details.VulnerablePackages) > 0 {
+			v.Package = aws.ToString(details.VulnerablePackages[0].Name)
+			v.Version = aws.ToString(details.VulnerablePackages[0].Version)
+		}
+	}
+	return v
+}
+
+// RegistryCredentials returns the host of the registry and short-lived credentials to pull its images with
+func (c *ECRClient) RegistryCredentials(ctx context.Context) (host, username, password string, err error) {
+	result, err := c.client.GetAuthorizationToken(ctx, &ecr.GetAuthorizationTokenInput{})
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to get registry authorization token: %w", err)
+	}
+	if len(result.AuthorizationData) == 0 {
+		return "", "", "", errors.New("no registry authorization token returned")
+	}
+
+	token, err := base64.StdEncoding.DecodeString(aws.ToString(result.AuthorizationData[0].AuthorizationToken))
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to decode registry authorization token: %w", err)
+	}
+	username, password, ok := strings.Cut(string(token), ":")
+	if !ok {
+		return "", "", "", errors.New("malformed registry authorization token")
+	}
+	return c.registryHost(), username, password, nil
+}
+
+// isScanLimitExceeded checks if the error indicates the image was already scanned today
+func isScanLimitExceeded(err error) bool {
+	var limit *types.LimitExceededException
+	return errors.As(err, &limit)
+}
+
+// isScanNotFound checks if the error indicates the image's scan hasn't started
+func isScanNotFound(err error) bool {
+	var notFound *types.ScanNotFoundException
+	return errors.As(err, &notFound)
+}
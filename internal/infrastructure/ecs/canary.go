@@ -0,0 +1,175 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/infrastructure/cloudwatch"
+)
+
+const (
+	// canaryCheckInterval is how often a canary's traffic is checked while it bakes
+	canaryCheckInterval = time.Minute
+	// canaryMinRequests is how many requests a canary must have answered before its error rate is judged
+	canaryMinRequests = 10
+)
+
+// canaryServiceName returns the name of the service a project's canary runs in
+func canaryServiceName(serviceName string) string {
+	return serviceName + "-canary"
+}
+
+// deployCanary starts the new version in a canary service next to the running one, sends it the project's
+// share of the traffic and watches its 5xx rate and response time for the project's bake time.
+// A canary that stays within the thresholds is promoted by rolling the project's service onto the new version.
+// One that doesn't is taken out of the load balancer and the running version keeps all traffic.
+func (o *DeploymentOrchestrator) deployCanary(ctx context.Context, proj *project.Project, dep *deployment.Deployment, req DeploymentRequest) error {
+	serviceName := req.ServiceName
+	canaryName := canaryServiceName(serviceName)
+	percent := int32(proj.CanaryPercent())
+	bake := time.Duration(proj.CanaryBakeMinutes()) * time.Minute
+
+	fail := func(step string, err error) error {
+		o.appendFailure(ctx, proj, dep, step, err)
+		dep.UpdateStatus(deployment.StatusFailed)
+		o.deploymentRepo.Save(ctx, dep)
+		return fmt.Errorf("%s: %w", strings.ToLower(step), err)
+	}
+
+	// The canary service stays registered with its target group, which is recreated when the port changes
+	if _, _, err := o.ecsClient.RemoveIncompatibleService(ctx, canaryName, project.StrategyCanary, req.ContainerPort); err != nil {
+		return fail("Failed to replace canary service", err)
+	}
+
+	canaryTargetGroupArn, err := o.albClient.CreateCanaryTargetGroup(ctx, serviceName, req.ContainerPort, proj.HealthCheckPath())
+	if err != nil {
+		return fail("Failed to create canary target group", err)
+	}
+
+	dep.AppendLog(fmt.Sprintf("🐤 Starting canary service: %s", canaryName))
+	o.deploymentRepo.Save(ctx, dep)
+
+	canaryReq := req
+	canaryReq.ServiceName = canaryName
+	canaryReq.TargetGroupArn = canaryTargetGroupArn
+	result, err := o.ecsClient.DeployService(ctx, canaryReq)
+	if err != nil {
+		return fail("Canary deployment failed", err)
+	}
+
+	// Only a canary whose tasks are healthy gets traffic
+	err = waitUnlocked(ctx, func() error {
+		return o.ecsClient.WaitForServiceStable(ctx, canaryName, 5*time.Minute, o.rolloutReporter(ctx, dep))
+	})
+	if err != nil {
+		o.stopCanary(ctx, canaryName)
+		return fail("Canary failed to start", err)
+	}
+	if err := o.ecsClient.CheckRollout(ctx, canaryName, result.TaskDefinitionArn); err != nil {
+		o.stopCanary(ctx, canaryName)
+		return fail("Canary failed to start", err)
+	}
+
+	if err := o.albClient.SetCanaryWeight(ctx, serviceName, req.TargetGroupArn, canaryTargetGroupArn, percent); err != nil {
+		o.endCanary(ctx, serviceName, req.TargetGroupArn, canaryName)
+		return fail("Failed to route traffic to canary", err)
+	}
+
+	dep.AppendLog(fmt.Sprintf("🔀 Sending %d%% of traffic to the canary for %s", percent, bake))
+	o.deploymentRepo.Save(ctx, dep)
+
+	loadBalancer := cloudwatch.LoadBalancerDimension(o.albClient.ListenerARN())
+	targetGroup := cloudwatch.TargetGroupDimension(canaryTargetGroupArn)
+	canaryTraffic := func(start, end time.Time) (*cloudwatch.Traffic, error) {
+		return o.metricsClient.GetTargetGroupTraffic(ctx, loadBalancer, targetGroup, start, end)
+	}
+	err = waitUnlocked(ctx, func() error { return o.bakeCanary(ctx, dep, canaryTraffic, bake) })
+	if err != nil {
+		o.endCanary(ctx, serviceName, req.TargetGroupArn, canaryName)
+		return fail("Canary rolled back, traffic stays on the previous version", err)
+	}
+
+	dep.AppendLog("✅ Canary stayed healthy, promoting the new version")
+	o.deploymentRepo.Save(ctx, dep)
+
+	err = o.rollOut(ctx, proj, dep, req)
+	o.endCanary(ctx, serviceName, req.TargetGroupArn, canaryName)
+	return err
+}
+
+// bakeCanary watches a canary's traffic, as reported by canaryTraffic, until the bake time is up. It returns
+// an error as soon as the canary's error rate or response time exceed the thresholds.
+func (o *DeploymentOrchestrator) bakeCanary(ctx context.Context, dep *deployment.Deployment, canaryTraffic func(start, end time.Time) (*cloudwatch.Traffic, error), bake time.Duration) error {
+	if o.metricsClient == nil {
+		dep.AppendLog("⚠️  Warning: CloudWatch is unavailable, the canary is promoted once its bake time is up")
+		o.deploymentRepo.Save(ctx, dep)
+	}
+
+	startedAt := time.Now()
+	deadline := startedAt.Add(bake)
+
+	ticker := time.NewTicker(canaryCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		if o.metricsClient != nil {
+			traffic, err := canaryTraffic(startedAt, time.Now())
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to get canary traffic", "deployment_id", dep.ID().String(), "error", err)
+			} else {
+				dep.AppendLog(fmt.Sprintf("📊 Canary: %.0f requests, %.1f%% failed, %s average response time",
+					traffic.Requests, traffic.ErrorRate()*100, traffic.ResponseTime.Round(time.Millisecond)))
+				o.deploymentRepo.Save(ctx, dep)
+
+				if err := o.judgeCanary(traffic); err != nil {
+					return err
+				}
+			}
+		}
+
+		if !time.Now().Before(deadline) {
+			return nil
+		}
+	}
+}
+
+// judgeCanary returns why a canary's traffic is outside the thresholds, or nil if it is within them
+func (o *DeploymentOrchestrator) judgeCanary(traffic *cloudwatch.Traffic) error {
+	if traffic.Requests >= canaryMinRequests && traffic.ErrorRate() > o.canaryMaxErrorRate {
+		return fmt.Errorf("%.1f%% of requests failed, more than the %.1f%% allowed",
+			traffic.ErrorRate()*100, o.canaryMaxErrorRate*100)
+	}
+	if traffic.ResponseTime > o.canaryMaxResponseTime {
+		return fmt.Errorf("average response time was %s, more than the %s allowed",
+			traffic.ResponseTime.Round(time.Millisecond), o.canaryMaxResponseTime)
+	}
+	return nil
+}
+
+// endCanary sends all traffic back to the project's service and scales its canary down.
+// A canary that can't be taken out of the load balancer is left running so its share of traffic is still answered.
+func (o *DeploymentOrchestrator) endCanary(ctx context.Context, serviceName, targetGroupArn, canaryName string) {
+	if err := o.albClient.SetCanaryWeight(ctx, serviceName, targetGroupArn, "", 0); err != nil {
+		slog.ErrorContext(ctx, "Failed to take canary out of the load balancer", "service", serviceName, "error", err)
+		return
+	}
+	o.stopCanary(ctx, canaryName)
+}
+
+// stopCanary scales a canary service down to 0 tasks, keeping it for the next canary deployment
+func (o *DeploymentOrchestrator) stopCanary(ctx context.Context, canaryName string) {
+	if err := o.ecsClient.StopService(ctx, canaryName); err != nil {
+		slog.WarnContext(ctx, "Failed to stop canary service", "service", canaryName, "error", err)
+	}
+}
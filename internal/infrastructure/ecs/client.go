@@ -2,42 +2,104 @@ package ecs
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+	"strings"
+	"sync"
 	"time"
 
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/shell"
+	"snapdeploy-core/internal/infrastructure/awsretry"
+	"snapdeploy-core/internal/infrastructure/database"
+	"snapdeploy-core/internal/infrastructure/ecsevents"
+	"snapdeploy-core/internal/infrastructure/quota"
+	"snapdeploy-core/internal/tracing"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrDeploymentRolledBack is returned when ECS rolled a service back because the new tasks never became healthy
+var ErrDeploymentRolledBack = errors.New("new tasks failed to become healthy, the service was rolled back")
+
+const (
+	stablePollInterval      = 10 * time.Second // How often rollouts are checked without service events
+	stableReconcileInterval = time.Minute      // How often rollouts are checked in case service events were missed
 )
 
 // ECSClient wraps AWS ECS operations
 type ECSClient struct {
 	client      *ecs.Client
+	logs        *cloudwatchlogs.Client
 	clusterName string
+	region      string
+
+	// Roles shared by the tasks of every user deployment
+	taskRoleArn      string
+	executionRoleArn string
+
+	roles        RoleManager
+	projectRoles bool // Each project's tasks run with a role of its own rather than taskRoleArn
+
+	logRetentionDays int32    // 0 keeps logs forever
+	ensuredLogGroups sync.Map // Log groups created or updated with the retention by this instance
+
+	events ServiceEventSource // Rollouts are followed by polling the service without it
+}
+
+// ServiceEventSource passes on the events ECS sends about services as they happen
+type ServiceEventSource interface {
+	Subscribe(serviceName string) (<-chan ecsevents.Event, func())
 }
 
-// NewECSClient creates a new ECS client
-func NewECSClient() (*ECSClient, error) {
+// RoleManager checks the roles tasks run with exist and creates the roles of projects that get their own
+type RoleManager interface {
+	ValidateRole(ctx context.Context, roleArn string) error
+	EnsureProjectRole(ctx context.Context, projectID string) (string, error)
+}
+
+// NewECSClient creates a new ECS client running services on the cluster, whose tasks run with the shared roles
+func NewECSClient(clusterName, region, taskRoleArn, executionRoleArn string) (*ECSClient, error) {
 	cfg, err := config.LoadDefaultConfig(context.TODO())
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
+	tracing.InstrumentAWS(&cfg)
+	awsretry.Configure(&cfg, "ECS")
 
-	clusterName := os.Getenv("ECS_CLUSTER_NAME")
-	if clusterName == "" {
-		clusterName = "snapdeploy-cluster"
+	if region == "" {
+		region = cfg.Region
 	}
 
 	return &ECSClient{
-		client:      ecs.NewFromConfig(cfg),
-		clusterName: clusterName,
+		client:           ecs.NewFromConfig(cfg),
+		logs:             cloudwatchlogs.NewFromConfig(cfg),
+		clusterName:      clusterName,
+		region:           region,
+		taskRoleArn:      taskRoleArn,
+		executionRoleArn: executionRoleArn,
 	}, nil
 }
 
+// SetServiceEvents sets the source of service events rollouts are followed with instead of polling (optional)
+func (c *ECSClient) SetServiceEvents(events ServiceEventSource) {
+	c.events = events
+}
+
+// SetRoleManager sets the component checking task roles exist before task definitions are registered
+// (optional). With projectRoles, each project's tasks run with a role created for the project rather than the
+// role shared by user deployments.
+func (c *ECSClient) SetRoleManager(roles RoleManager, projectRoles bool) {
+	c.roles = roles
+	c.projectRoles = projectRoles
+}
+
 // DeploymentRequest contains information needed to deploy a service
 type DeploymentRequest struct {
 	ServiceName     string
@@ -47,45 +109,116 @@ type DeploymentRequest struct {
 	CPU             string // e.g., "256"
 	Memory          string // e.g., "512"
 	DesiredCount    int32
-	ContainerPort   int32
-	TargetGroupArn  string // ALB target group
+	ContainerPort   int32  // 0 for services that receive no traffic
+	TargetGroupArn  string // ALB target group, empty for services that receive no traffic
 	SubnetIDs       []string
 	SecurityGroupID string
 	EnvVars         map[string]string
+	Command         []string // Run in place of the image's command, empty to keep it
+	Strategy        project.DeploymentStrategy
+	Sidecars        []database.Sidecar // Datastores run next to the service's container
+	Volume          *VolumeMount       // Persistent volume mounted into the service's container
+	TaskRoleArn     string             // Role the tasks run with, empty for the project's or the shared role
+}
+
+// VolumeMount is an EFS file system mounted into a service's container
+type VolumeMount struct {
+	FileSystemID  string
+	AccessPointID string
+	ContainerPath string
+}
+
+// volumeName is the name a project's persistent volume has in its task definitions
+const volumeName = "data"
+
+// DeploymentResult describes what DeployService did
+type DeploymentResult struct {
+	TaskDefinitionArn string
+	// Created is set when the service was created, in which case it starts on the new task definition.
+	// An existing blue/green service is left for CodeDeploy to replace its tasks.
+	Created bool
 }
 
 // DeployService creates or updates an ECS service
-func (c *ECSClient) DeployService(ctx context.Context, req DeploymentRequest) error {
+func (c *ECSClient) DeployService(ctx context.Context, req DeploymentRequest) (*DeploymentResult, error) {
 	// Check if service exists
 	service, err := c.getService(ctx, req.ServiceName)
 	if err != nil && !isServiceNotFoundError(err) {
-		return fmt.Errorf("failed to check service existence: %w", err)
+		return nil, fmt.Errorf("failed to check service existence: %w", err)
 	}
 
 	// Create or update task definition
 	taskDefArn, err := c.createTaskDefinition(ctx, req)
 	if err != nil {
-		return fmt.Errorf("failed to create task definition: %w", err)
+		return nil, fmt.Errorf("failed to create task definition: %w", err)
 	}
 
 	if service == nil {
 		// Service doesn't exist - create it
-		return c.createService(ctx, req, taskDefArn)
+		if err := c.createService(ctx, req, taskDefArn); err != nil {
+			return nil, err
+		}
+		return &DeploymentResult{TaskDefinitionArn: taskDefArn, Created: true}, nil
+	}
+
+	if req.Strategy == project.StrategyBlueGreen {
+		return &DeploymentResult{TaskDefinitionArn: taskDefArn}, nil
 	}
 
 	// Service exists - update it
-	return c.updateService(ctx, req.ServiceName, taskDefArn, req.DesiredCount)
+	if err := c.updateService(ctx, req.ServiceName, taskDefArn, req.DesiredCount, deploymentConfiguration(req.Strategy)); err != nil {
+		return nil, err
+	}
+	return &DeploymentResult{TaskDefinitionArn: taskDefArn}, nil
+}
+
+// deploymentConfiguration returns how ECS replaces the tasks of a service deployed with a strategy.
+// Blue/green services are replaced by CodeDeploy instead.
+func deploymentConfiguration(strategy project.DeploymentStrategy) *types.DeploymentConfiguration {
+	// Roll back to the previous task definition if the new tasks keep failing
+	circuitBreaker := &types.DeploymentCircuitBreaker{Enable: true, Rollback: true}
+
+	switch strategy {
+	case project.StrategyBlueGreen:
+		return nil
+	case project.StrategyRecreate:
+		// Stop the running tasks before starting new ones
+		return &types.DeploymentConfiguration{
+			MinimumHealthyPercent:    aws.Int32(0),
+			MaximumPercent:           aws.Int32(100),
+			DeploymentCircuitBreaker: circuitBreaker,
+		}
+	default:
+		// Start new tasks next to the running ones and only stop those once the new ones are healthy
+		return &types.DeploymentConfiguration{
+			MinimumHealthyPercent:    aws.Int32(100),
+			MaximumPercent:           aws.Int32(200),
+			DeploymentCircuitBreaker: circuitBreaker,
+		}
+	}
+}
+
+// deploymentController returns the controller that replaces the tasks of a service deployed with a strategy
+func deploymentController(strategy project.DeploymentStrategy) types.DeploymentControllerType {
+	if strategy == project.StrategyBlueGreen {
+		return types.DeploymentControllerTypeCodeDeploy
+	}
+	return types.DeploymentControllerTypeEcs
+}
+
+// isBlueGreen reports whether CodeDeploy replaces a service's tasks
+func isBlueGreen(service *types.Service) bool {
+	return service.DeploymentController != nil && service.DeploymentController.Type == types.DeploymentControllerTypeCodeDeploy
 }
 
 // createTaskDefinition creates a new task definition revision
 func (c *ECSClient) createTaskDefinition(ctx context.Context, req DeploymentRequest) (string, error) {
-	region := os.Getenv("AWS_REGION")
+	region := c.region
 
-	// Create CloudWatch log group if it doesn't exist
-	logGroupName := fmt.Sprintf("/ecs/%s", req.ServiceName)
-	if err := c.ensureLogGroupExists(ctx, logGroupName, region); err != nil {
-		log.Printf("[ECS] Warning: failed to create log group %s: %v", logGroupName, err)
-		// Don't fail the deployment, just log the warning
+	// Tasks can't start without their log group
+	logGroupName := serviceLogGroup(req.ServiceName)
+	if err := c.ensureLogGroup(ctx, logGroupName, req.ProjectID); err != nil {
+		return "", err
 	}
 
 	// Build environment variables
@@ -99,36 +232,98 @@ func (c *ECSClient) createTaskDefinition(ctx context.Context, req DeploymentRequ
 
 	// Create container definition
 	containerDef := types.ContainerDefinition{
-		Name:      aws.String(req.ServiceName),
-		Image:     aws.String(req.ImageURI),
-		Cpu:       0, // Let Fargate manage
-		Memory:    nil,
-		Essential: aws.Bool(true),
-		PortMappings: []types.PortMapping{
-			{
-				ContainerPort: aws.Int32(req.ContainerPort),
-				HostPort:      aws.Int32(req.ContainerPort),
-				Protocol:      types.TransportProtocolTcp,
-			},
-		},
+		Name:        aws.String(req.ServiceName),
+		Image:       aws.String(req.ImageURI),
+		Cpu:         0, // Let Fargate manage
+		Memory:      nil,
+		Essential:   aws.Bool(true),
 		Environment: envVars,
 		LogConfiguration: &types.LogConfiguration{
 			LogDriver: types.LogDriverAwslogs,
 			Options: map[string]string{
-				"awslogs-group":         fmt.Sprintf("/ecs/%s", req.ServiceName),
+				"awslogs-group":         logGroupName,
 				"awslogs-region":        region,
 				"awslogs-stream-prefix": "ecs",
 			},
 		},
 	}
 
-	// Get shared user deployment role ARNs from environment
-	// These roles are shared across all user deployments for security and simplicity
-	taskRoleArn := os.Getenv("USER_DEPLOYMENT_TASK_ROLE_ARN")
-	executionRoleArn := os.Getenv("USER_DEPLOYMENT_EXECUTION_ROLE_ARN")
+	if len(req.Command) > 0 {
+		containerDef.Command = req.Command
+	}
 
-	if taskRoleArn == "" || executionRoleArn == "" {
-		return "", fmt.Errorf("USER_DEPLOYMENT_TASK_ROLE_ARN and USER_DEPLOYMENT_EXECUTION_ROLE_ARN environment variables must be set")
+	if req.ContainerPort > 0 {
+		containerDef.PortMappings = []types.PortMapping{
+			{
+				ContainerPort: aws.Int32(req.ContainerPort),
+				HostPort:      aws.Int32(req.ContainerPort),
+				Protocol:      types.TransportProtocolTcp,
+			},
+		}
+	}
+
+	var volumes []types.Volume
+	if req.Volume != nil {
+		containerDef.MountPoints = []types.MountPoint{
+			{
+				SourceVolume:  aws.String(volumeName),
+				ContainerPath: aws.String(req.Volume.ContainerPath),
+				ReadOnly:      aws.Bool(false),
+			},
+		}
+		volumes = append(volumes, types.Volume{
+			Name: aws.String(volumeName),
+			EfsVolumeConfiguration: &types.EFSVolumeConfiguration{
+				FileSystemId: aws.String(req.Volume.FileSystemID),
+				// Mounting through an access point requires encryption in transit
+				TransitEncryption: types.EFSTransitEncryptionEnabled,
+				AuthorizationConfig: &types.EFSAuthorizationConfig{
+					AccessPointId: aws.String(req.Volume.AccessPointID),
+					Iam:           types.EFSAuthorizationConfigIAMDisabled,
+				},
+			},
+		})
+	}
+
+	containerDefs := []types.ContainerDefinition{containerDef}
+	for _, sidecar := range req.Sidecars {
+		// The service's container connects to its sidecars as soon as it starts
+		containerDefs[0].DependsOn = append(containerDefs[0].DependsOn, types.ContainerDependency{
+			ContainerName: aws.String(sidecar.Name),
+			Condition:     types.ContainerConditionStart,
+		})
+		containerDefs = append(containerDefs, types.ContainerDefinition{
+			Name:      aws.String(sidecar.Name),
+			Image:     aws.String(sidecar.Image),
+			Essential: aws.Bool(true),
+			PortMappings: []types.PortMapping{
+				{
+					ContainerPort: aws.Int32(sidecar.Port),
+					Protocol:      types.TransportProtocolTcp,
+				},
+			},
+			LogConfiguration: &types.LogConfiguration{
+				LogDriver: types.LogDriverAwslogs,
+				Options: map[string]string{
+					"awslogs-group":         logGroupName,
+					"awslogs-region":        region,
+					"awslogs-stream-prefix": sidecar.Name,
+				},
+			},
+		})
+	}
+
+	taskRoleArn, err := c.taskRole(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	executionRoleArn := c.executionRoleArn
+	if c.roles != nil {
+		for _, roleArn := range []string{taskRoleArn, executionRoleArn} {
+			if err := c.roles.ValidateRole(ctx, roleArn); err != nil {
+				return "", err
+			}
+		}
 	}
 
 	// Register task definition
@@ -140,7 +335,8 @@ func (c *ECSClient) createTaskDefinition(ctx context.Context, req DeploymentRequ
 		RequiresCompatibilities: []types.Compatibility{types.CompatibilityFargate},
 		Cpu:                     aws.String(req.CPU),
 		Memory:                  aws.String(req.Memory),
-		ContainerDefinitions:    []types.ContainerDefinition{containerDef},
+		ContainerDefinitions:    containerDefs,
+		Volumes:                 volumes,
 	}
 
 	result, err := c.client.RegisterTaskDefinition(ctx, input)
@@ -151,6 +347,32 @@ func (c *ECSClient) createTaskDefinition(ctx context.Context, req DeploymentRequ
 	return *result.TaskDefinition.TaskDefinitionArn, nil
 }
 
+// taskRole returns the role a task definition's tasks run with: the role the request asks for, the project's
+// own role when projects get one, or the role shared by user deployments
+func (c *ECSClient) taskRole(ctx context.Context, req DeploymentRequest) (string, error) {
+	if c.executionRoleArn == "" {
+		return "", fmt.Errorf("USER_DEPLOYMENT_EXECUTION_ROLE_ARN must be set")
+	}
+
+	switch {
+	case req.TaskRoleArn != "":
+		return req.TaskRoleArn, nil
+	case c.projectRoles:
+		if c.roles == nil {
+			return "", fmt.Errorf("project task roles are unavailable, the IAM client could not be initialized")
+		}
+		roleArn, err := c.roles.EnsureProjectRole(ctx, req.ProjectID)
+		if err != nil {
+			return "", fmt.Errorf("failed to set up the project's task role: %w", err)
+		}
+		return roleArn, nil
+	case c.taskRoleArn == "":
+		return "", fmt.Errorf("USER_DEPLOYMENT_TASK_ROLE_ARN must be set")
+	default:
+		return c.taskRoleArn, nil
+	}
+}
+
 // createService creates a new ECS service
 func (c *ECSClient) createService(ctx context.Context, req DeploymentRequest, taskDefArn string) error {
 	input := &ecs.CreateServiceInput{
@@ -166,14 +388,24 @@ func (c *ECSClient) createService(ctx context.Context, req DeploymentRequest, ta
 				AssignPublicIp: types.AssignPublicIpEnabled,
 			},
 		},
-		LoadBalancers: []types.LoadBalancer{
+		DeploymentController: &types.DeploymentController{
+			Type: deploymentController(req.Strategy),
+		},
+		DeploymentConfiguration: deploymentConfiguration(req.Strategy),
+		// Lets users open shells in the service's tasks
+		EnableExecuteCommand: true,
+	}
+
+	// Services that receive no traffic are healthy as long as their tasks keep running
+	if req.TargetGroupArn != "" {
+		input.LoadBalancers = []types.LoadBalancer{
 			{
 				TargetGroupArn: aws.String(req.TargetGroupArn),
 				ContainerName:  aws.String(req.ServiceName),
 				ContainerPort:  aws.Int32(req.ContainerPort),
 			},
-		},
-		HealthCheckGracePeriodSeconds: aws.Int32(60),
+		}
+		input.HealthCheckGracePeriodSeconds = aws.Int32(60)
 	}
 
 	_, err := c.client.CreateService(ctx, input)
@@ -185,13 +417,16 @@ func (c *ECSClient) createService(ctx context.Context, req DeploymentRequest, ta
 }
 
 // updateService updates an existing ECS service with a new task definition
-func (c *ECSClient) updateService(ctx context.Context, serviceName, taskDefArn string, desiredCount int32) error {
+func (c *ECSClient) updateService(ctx context.Context, serviceName, taskDefArn string, desiredCount int32, deploymentConfig *types.DeploymentConfiguration) error {
 	input := &ecs.UpdateServiceInput{
-		Service:            aws.String(serviceName),
-		Cluster:            aws.String(c.clusterName),
-		TaskDefinition:     aws.String(taskDefArn),
-		DesiredCount:       aws.Int32(desiredCount),
-		ForceNewDeployment: true,
+		Service:                 aws.String(serviceName),
+		Cluster:                 aws.String(c.clusterName),
+		TaskDefinition:          aws.String(taskDefArn),
+		DesiredCount:            aws.Int32(desiredCount),
+		ForceNewDeployment:      true,
+		DeploymentConfiguration: deploymentConfig,
+		// Services created before shells were available get them with their next deployment
+		EnableExecuteCommand: aws.Bool(true),
 	}
 
 	_, err := c.client.UpdateService(ctx, input)
@@ -228,31 +463,332 @@ func (c *ECSClient) getService(ctx context.Context, serviceName string) (*types.
 	return service, nil
 }
 
-// WaitForServiceStable waits for the service to reach a stable state
-func (c *ECSClient) WaitForServiceStable(ctx context.Context, serviceName string, timeout time.Duration) error {
-	deadline := time.Now().Add(timeout)
+// WaitForServiceStable waits for the service to reach a stable state, reporting what ECS says about the
+// rollout along the way if report is set. With a source of service events the service is checked when
+// they say it may have settled, and only now and then otherwise in case an event was missed.
+func (c *ECSClient) WaitForServiceStable(ctx context.Context, serviceName string, timeout time.Duration, report func(message string)) (err error) {
+	ctx, span := tracing.Start(ctx, "ecs.wait_stable", attribute.String("ecs.service", serviceName))
+	defer func() { tracing.End(span, err) }()
+
+	var events <-chan ecsevents.Event
+	checkInterval := stablePollInterval
+	if c.events != nil {
+		// Subscribed before the first check so nothing happening in between is missed
+		ch, unsubscribe := c.events.Subscribe(serviceName)
+		defer unsubscribe()
+		events = ch
+		checkInterval = stableReconcileInterval
+	}
 
-	for time.Now().Before(deadline) {
-		service, err := c.getService(ctx, serviceName)
-		if err != nil {
-			return err
+	startedAt := time.Now()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for check := true; ; {
+		if check {
+			if stable, err := c.isServiceStable(ctx, serviceName, startedAt); err != nil || stable {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			return fmt.Errorf("timeout waiting for service to stabilize")
+		case <-ticker.C:
+			check = true
+		case event := <-events:
+			if report != nil && (event.Name == ecsevents.DeploymentInProgress || event.Problem()) {
+				report(event.String())
+			}
+			if event.Name == ecsevents.DeploymentFailed {
+				return fmt.Errorf("service deployment failed: %s", event.Reason)
+			}
+			check = event.Settled() || event.Problem()
+		}
+	}
+}
+
+// isServiceStable reports whether a service runs all its tasks on a single deployment, and returns the quota
+// error keeping its tasks from being placed since the rollout started, which will never let it stabilize
+func (c *ECSClient) isServiceStable(ctx context.Context, serviceName string, since time.Time) (bool, error) {
+	service, err := c.getService(ctx, serviceName)
+	if err != nil {
+		return false, err
+	}
+
+	// Blue/green services have task sets instead of deployments
+	if service.RunningCount == service.DesiredCount && len(service.Deployments) <= 1 && len(service.TaskSets) <= 1 {
+		return true, nil
+	}
+	return false, quotaErrorFromEvents(service.Events, since)
+}
+
+// CheckRollout returns ErrDeploymentRolledBack if ECS gave up on a service's task definition and
+// rolled the service back to the previous one
+func (c *ECSClient) CheckRollout(ctx context.Context, serviceName, taskDefArn string) error {
+	service, err := c.getService(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range service.Deployments {
+		if aws.ToString(d.TaskDefinition) == taskDefArn && d.RolloutState == types.DeploymentRolloutStateFailed {
+			return fmt.Errorf("%w: %s", ErrDeploymentRolledBack, aws.ToString(d.RolloutStateReason))
+		}
+	}
+
+	// Once the rollback has finished the failed deployment is gone
+	if current := primaryTaskDefinition(service); current != "" && current != taskDefArn {
+		return ErrDeploymentRolledBack
+	}
+
+	return nil
+}
+
+// CurrentTaskDefinition returns the task definition a service's tasks are running
+func (c *ECSClient) CurrentTaskDefinition(ctx context.Context, serviceName string) (string, error) {
+	service, err := c.getService(ctx, serviceName)
+	if err != nil {
+		return "", err
+	}
+	return primaryTaskDefinition(service), nil
+}
+
+// RunningTask returns the ARN of a running task of a service
+func (c *ECSClient) RunningTask(ctx context.Context, serviceName string) (string, error) {
+	result, err := c.client.ListTasks(ctx, &ecs.ListTasksInput{
+		Cluster:       aws.String(c.clusterName),
+		ServiceName:   aws.String(serviceName),
+		DesiredStatus: types.DesiredStatusRunning,
+		MaxResults:    aws.Int32(1),
+	})
+	if err != nil {
+		var notFound *types.ServiceNotFoundException
+		if errors.As(err, &notFound) {
+			return "", shell.ErrNoRunningTask
+		}
+		return "", fmt.Errorf("failed to list tasks: %w", err)
+	}
+	if len(result.TaskArns) == 0 {
+		return "", shell.ErrNoRunningTask
+	}
+	return result.TaskArns[0], nil
+}
+
+// ExecuteCommand starts an interactive ECS Exec session running a command in the container of a task,
+// named after its service, and returns the session to connect to
+func (c *ECSClient) ExecuteCommand(ctx context.Context, taskArn, containerName, command string) (*types.Session, error) {
+	result, err := c.client.ExecuteCommand(ctx, &ecs.ExecuteCommandInput{
+		Cluster:     aws.String(c.clusterName),
+		Task:        aws.String(taskArn),
+		Container:   aws.String(containerName),
+		Command:     aws.String(command),
+		Interactive: true,
+	})
+	if err != nil {
+		// Tasks started before exec was enabled for their service, or whose agent isn't up, can't be exec'd into
+		var invalid *types.InvalidParameterException
+		var notConnected *types.TargetNotConnectedException
+		if errors.As(err, &invalid) || errors.As(err, &notConnected) {
+			return nil, fmt.Errorf("%w: %v", shell.ErrExecDisabled, err)
+		}
+		return nil, fmt.Errorf("failed to execute command: %w", err)
+	}
+	if result.Session == nil {
+		return nil, fmt.Errorf("ECS returned no exec session for task %s", taskArn)
+	}
+	return result.Session, nil
+}
+
+// primaryTaskDefinition returns the task definition of the deployment or task set serving a service's traffic
+func primaryTaskDefinition(service *types.Service) string {
+	for _, d := range service.Deployments {
+		if aws.ToString(d.Status) == "PRIMARY" {
+			return aws.ToString(d.TaskDefinition)
 		}
+	}
+	for _, ts := range service.TaskSets {
+		if aws.ToString(ts.Status) == "PRIMARY" {
+			return aws.ToString(ts.TaskDefinition)
+		}
+	}
+	return aws.ToString(service.TaskDefinition)
+}
 
-		// Check if deployment is stable
-		if service.RunningCount == service.DesiredCount && len(service.Deployments) == 1 {
-			return nil
+// RemoveIncompatibleService deletes a service that can't be updated to be deployed with a strategy on a port,
+// since ECS can't change the deployment controller or the load balancer of a service. The port is 0 for
+// services that receive no traffic. Returns whether the service was deleted and whether it was a blue/green service.
+func (c *ECSClient) RemoveIncompatibleService(ctx context.Context, serviceName string, strategy project.DeploymentStrategy, containerPort int32) (removed, wasBlueGreen bool, err error) {
+	service, err := c.getService(ctx, serviceName)
+	if err != nil {
+		if isServiceNotFoundError(err) {
+			return false, false, nil
 		}
+		return false, false, fmt.Errorf("failed to check service existence: %w", err)
+	}
+
+	wasBlueGreen = isBlueGreen(service)
+	if !isIncompatible(service, strategy, containerPort) {
+		return false, wasBlueGreen, nil
+	}
 
-		// Wait before checking again
-		time.Sleep(10 * time.Second)
+	slog.InfoContext(ctx, "Replacing service", "service", serviceName, "strategy", strategy, "was_blue_green", wasBlueGreen)
+	if err := c.DeleteService(ctx, serviceName); err != nil {
+		return false, wasBlueGreen, err
 	}
 
-	return fmt.Errorf("timeout waiting for service to stabilize")
+	// A service with the same name can only be created once the old one is gone
+	waiter := ecs.NewServicesInactiveWaiter(c.client)
+	if err := waiter.Wait(ctx, &ecs.DescribeServicesInput{
+		Cluster:  aws.String(c.clusterName),
+		Services: []string{serviceName},
+	}, 10*time.Minute); err != nil {
+		return true, wasBlueGreen, fmt.Errorf("failed waiting for old service to be deleted: %w", err)
+	}
+
+	return true, wasBlueGreen, nil
+}
+
+// isIncompatible returns whether a service must be replaced to be deployed with a strategy on a port
+func isIncompatible(service *types.Service, strategy project.DeploymentStrategy, containerPort int32) bool {
+	hasLoadBalancer := len(service.LoadBalancers) > 0
+	portChanged := hasLoadBalancer != (containerPort > 0) ||
+		hasLoadBalancer && aws.ToInt32(service.LoadBalancers[0].ContainerPort) != containerPort
+	return isBlueGreen(service) != (strategy == project.StrategyBlueGreen) || portChanged
+}
+
+// quotaErrorFromEvents returns a quota error reported in service events since the given time
+func quotaErrorFromEvents(events []types.ServiceEvent, since time.Time) error {
+	for _, event := range events {
+		if event.CreatedAt == nil || event.CreatedAt.Before(since) {
+			continue
+		}
+		message := aws.ToString(event.Message)
+		if quotaErr := quota.FromMessage(message, fmt.Errorf("service event: %s", message)); quotaErr != nil {
+			return quotaErr
+		}
+	}
+	return nil
+}
+
+// ServiceEvent is a message ECS recorded for a service, such as a task being started or the service reaching a steady state
+type ServiceEvent struct {
+	CreatedAt time.Time
+	Message   string
+}
+
+// GetServiceEvents returns the service's events recorded within the window, oldest first.
+// ECS only keeps the 100 most recent events of a service.
+func (c *ECSClient) GetServiceEvents(ctx context.Context, serviceName string, start, end time.Time) ([]ServiceEvent, error) {
+	service, err := c.getService(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []ServiceEvent
+	for i := len(service.Events) - 1; i >= 0; i-- { // ECS returns the newest event first
+		event := service.Events[i]
+		if event.CreatedAt == nil || event.CreatedAt.Before(start) || event.CreatedAt.After(end) {
+			continue
+		}
+		events = append(events, ServiceEvent{
+			CreatedAt: *event.CreatedAt,
+			Message:   aws.ToString(event.Message),
+		})
+	}
+
+	return events, nil
+}
+
+// RestartService replaces the running tasks of a service using its current task definition
+func (c *ECSClient) RestartService(ctx context.Context, serviceName string) error {
+	input := &ecs.UpdateServiceInput{
+		Service:            aws.String(serviceName),
+		Cluster:            aws.String(c.clusterName),
+		ForceNewDeployment: true,
+	}
+
+	_, err := c.client.UpdateService(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to restart service: %w", err)
+	}
+
+	return nil
+}
+
+// RevertService rolls a service back to a task definition it ran before, keeping its task count
+func (c *ECSClient) RevertService(ctx context.Context, serviceName, taskDefArn string) error {
+	service, err := c.getService(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+
+	return c.updateService(ctx, serviceName, taskDefArn, service.DesiredCount, deploymentConfiguration(project.StrategyRolling))
+}
+
+// ListServiceNames returns the names of the services in the cluster whose names start with a prefix
+func (c *ECSClient) ListServiceNames(ctx context.Context, prefix string) ([]string, error) {
+	paginator := ecs.NewListServicesPaginator(c.client, &ecs.ListServicesInput{
+		Cluster: aws.String(c.clusterName),
+	})
+
+	var names []string
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list services: %w", err)
+		}
+		for _, arn := range page.ServiceArns {
+			// Service ARNs end with the service's name
+			name := arn[strings.LastIndex(arn, "/")+1:]
+			if strings.HasPrefix(name, prefix) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names, nil
 }
 
 // StopService scales a service down to 0 tasks
 func (c *ECSClient) StopService(ctx context.Context, serviceName string) error {
-	return c.updateService(ctx, serviceName, "", 0)
+	input := &ecs.UpdateServiceInput{
+		Service:      aws.String(serviceName),
+		Cluster:      aws.String(c.clusterName),
+		DesiredCount: aws.Int32(0),
+	}
+
+	_, err := c.client.UpdateService(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to stop service: %w", err)
+	}
+
+	return nil
+}
+
+// ScaleService sets how many tasks a service runs, and reports false if the service doesn't exist
+func (c *ECSClient) ScaleService(ctx context.Context, serviceName string, count int32) (bool, error) {
+	input := &ecs.UpdateServiceInput{
+		Service:      aws.String(serviceName),
+		Cluster:      aws.String(c.clusterName),
+		DesiredCount: aws.Int32(count),
+	}
+
+	_, err := c.client.UpdateService(ctx, input)
+	if err != nil {
+		var notFound *types.ServiceNotFoundException
+		var notActive *types.ServiceNotActiveException
+		if errors.As(err, &notFound) || errors.As(err, &notActive) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to scale service: %w", err)
+	}
+
+	return true, nil
 }
 
 // DeleteService deletes an ECS service
@@ -287,33 +823,3 @@ func isServiceNotFoundError(err error) bool {
 	}
 	return err.Error() == "service not found"
 }
-
-// ensureLogGroupExists creates a CloudWatch log group if it doesn't already exist
-func (c *ECSClient) ensureLogGroupExists(ctx context.Context, logGroupName, region string) error {
-	// Create CloudWatch Logs client
-	cfg, err := config.LoadDefaultConfig(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to load AWS config: %w", err)
-	}
-
-	logsClient := cloudwatchlogs.NewFromConfig(cfg)
-
-	// Try to create the log group
-	_, err = logsClient.CreateLogGroup(ctx, &cloudwatchlogs.CreateLogGroupInput{
-		LogGroupName: aws.String(logGroupName),
-	})
-
-	if err != nil {
-		// Check if it's because the log group already exists
-		if err.Error() != "" && (err.Error() == "ResourceAlreadyExistsException" ||
-			err.Error() == "The specified log group already exists") {
-			// Log group already exists, this is fine
-			log.Printf("[ECS] Log group %s already exists", logGroupName)
-			return nil
-		}
-		return fmt.Errorf("failed to create log group: %w", err)
-	}
-
-	log.Printf("[ECS] Created CloudWatch log group: %s", logGroupName)
-	return nil
-}
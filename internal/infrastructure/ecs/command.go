@@ -0,0 +1,46 @@
+package ecs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"snapdeploy-core/internal/domain/command"
+	"snapdeploy-core/internal/domain/project"
+)
+
+// RunCommand runs a one-off command in a project's container with the task definition its environment is
+// deployed with, so with the image and environment variables of its current deployment. The lines the
+// command writes are passed to output as they come. Returns the command's exit code.
+func (o *DeploymentOrchestrator) RunCommand(ctx context.Context, proj *project.Project, env project.Environment, args []string, output func(lines []string)) (int, error) {
+	serviceName := environmentServiceName(proj.ID().String(), env)
+
+	// CRON projects have no service, their schedule runs the latest task definition of their family
+	taskDef := serviceName
+	if proj.Type() != project.TypeCron {
+		current, err := o.ecsClient.CurrentTaskDefinition(ctx, serviceName)
+		if err != nil {
+			return 0, fmt.Errorf("failed to find the task definition the environment runs: %w", err)
+		}
+		taskDef = current
+	}
+
+	err := o.taskRunner.RunTask(ctx, RunTaskRequest{
+		TaskDefinition: taskDef,
+		Command:        args,
+		TaskName:       serviceName,
+		Purpose:        "Command",
+		OnLogLines:     output,
+		Timeout:        command.Timeout,
+	})
+
+	// Exiting with an error is how the command ended, not a failure to run it
+	var failed *TaskFailedError
+	if errors.As(err, &failed) {
+		return int(failed.ExitCode), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
@@ -0,0 +1,261 @@
+package ecs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/infrastructure/route53"
+)
+
+// containerDeploy is a deployment of a project running on ECS, going through the stages of its pipeline
+type containerDeploy struct {
+	o     *DeploymentOrchestrator
+	proj  *project.Project
+	dep   *deployment.Deployment
+	steps *stepTracker
+
+	imageURI    string
+	serviceName string
+	domain      project.CustomDomain
+
+	// Worked out by the stages as they run
+	runtime        *projectRuntime
+	containerPort  int32
+	strategy       project.DeploymentStrategy
+	rollout        *serviceRollout
+	replaced       bool
+	wasBlueGreen   bool
+	targetGroupArn string
+}
+
+// stages lists the pipeline of the deployment. Workers and cron jobs receive no traffic, so they go
+// without routing and DNS, and without a stage completing the deployment: deployWorker and deployCron mark
+// it deployed once their service is, and finishing the other services after that only logs failures.
+func (d *containerDeploy) stages() []stage {
+	stages := []stage{
+		{name: "Prepare runtime", run: d.prepareRuntime},
+		{name: "Configure service", step: deployment.StepECS, failure: "Blue/green deployment unavailable", run: d.configure},
+		{name: "Deploy other services", step: deployment.StepECS, locked: true, run: d.rollOutServices, compensate: d.revertServices},
+		{name: "Replace incompatible service", step: deployment.StepECS, failure: "Failed to replace service", locked: true, run: d.replaceIncompatibleService},
+	}
+
+	if !d.proj.Type().ServesTraffic() {
+		return append(stages,
+			stage{name: "Deploy service", step: deployment.StepECS, locked: true, run: d.deployWithoutTraffic},
+			stage{name: "Finish other services", locked: true, run: d.finishServices},
+		)
+	}
+
+	return append(stages,
+		stage{name: "Create routing", step: deployment.StepALB, failure: "Failed to create ALB routing", attempts: 3, locked: true, run: d.createRouting},
+		stage{name: "Deploy service", step: deployment.StepECS, locked: true, run: d.deployService},
+		stage{name: "Configure DNS", step: deployment.StepDNS, failure: "DNS configuration failed", optional: true, attempts: 3, locked: true, run: d.configureDNS},
+		stage{name: "Retire previous hosting", locked: true, run: d.retirePreviousHosting},
+		stage{name: "Finish other services", locked: true, run: d.finishServices},
+		stage{name: "Complete deployment", run: d.complete},
+	)
+}
+
+// prepareRuntime loads the environment variables and provisions the datastores, volume and database the
+// project runs with, running its migrations
+func (d *containerDeploy) prepareRuntime(ctx context.Context) error {
+	runtime, err := d.o.prepareRuntime(ctx, d.proj, d.dep, d.serviceName, d.imageURI, d.steps)
+	if err != nil {
+		return err
+	}
+	d.runtime = runtime
+	return nil
+}
+
+// configure works out the port the service's container listens on and checks its strategy can be deployed.
+// The port comes from the PORT environment variable if set, otherwise from the project.
+func (d *containerDeploy) configure(ctx context.Context) error {
+	d.containerPort = int32(d.proj.Port())
+	if !d.proj.Type().ServesTraffic() {
+		d.containerPort = 0
+		d.dep.AppendLog(fmt.Sprintf("⚙️  %s project: skipping load balancer, DNS and health checks", d.proj.Type()))
+	} else if portStr, ok := d.runtime.envVars["PORT"]; ok {
+		if port, err := parsePort(portStr); err == nil {
+			d.containerPort = port
+			d.dep.AppendLog(fmt.Sprintf("🔌 Using custom PORT: %d", d.containerPort))
+		}
+	}
+
+	d.strategy = d.proj.DeploymentStrategy()
+	if d.strategy == project.StrategyBlueGreen && d.o.codeDeploy == nil {
+		return errors.New("CodeDeploy is not configured on this platform")
+	}
+	d.dep.AppendLog(fmt.Sprintf("🧭 Deployment strategy: %s", d.strategy))
+	return nil
+}
+
+// rollOutServices deploys the project's other services first, so one that fails leaves the main service
+// untouched
+func (d *containerDeploy) rollOutServices(ctx context.Context) error {
+	rollout, err := d.o.rollOutServices(ctx, d.proj, d.dep, d.request())
+	if err != nil {
+		return err
+	}
+	d.rollout = rollout
+	return nil
+}
+
+// revertServices puts the project's other services back when the main service fails
+func (d *containerDeploy) revertServices(ctx context.Context) {
+	d.o.finishServices(ctx, d.proj, d.dep, d.serviceName, d.rollout, false)
+}
+
+// finishServices schedules and routes the project's other services once the main service is deployed,
+// and removes those the project no longer defines
+func (d *containerDeploy) finishServices(ctx context.Context) error {
+	d.o.finishServices(ctx, d.proj, d.dep, d.serviceName, d.rollout, true)
+	return nil
+}
+
+// replaceIncompatibleService removes the existing service if ECS can't move it to the strategy or port, and
+// the schedule of a project that ran as a cron job before
+func (d *containerDeploy) replaceIncompatibleService(ctx context.Context) error {
+	replaced, wasBlueGreen, err := d.o.ecsClient.RemoveIncompatibleService(ctx, d.serviceName, d.strategy, d.containerPort)
+	if err != nil {
+		return err
+	}
+	d.replaced, d.wasBlueGreen = replaced, wasBlueGreen
+	if replaced {
+		d.dep.AppendLog("♻️  Removed the existing service, ECS can't move it to the new deployment strategy or port")
+	}
+
+	if d.proj.Type() != project.TypeCron {
+		d.o.removeSchedule(ctx, d.proj, d.serviceName)
+	}
+	return nil
+}
+
+// deployWithoutTraffic deploys a worker or cron job, removing what served the project's traffic before
+func (d *containerDeploy) deployWithoutTraffic(ctx context.Context) error {
+	if d.replaced {
+		// The project served traffic before it became a worker or cron job
+		d.o.removeRouting(ctx, d.proj, d.serviceName, d.domain)
+	}
+	retiredSite := d.o.retireStaticSite(ctx, d.proj, d.serviceName)
+	retiredFunction := d.o.retireFunction(ctx, d.proj, d.serviceName)
+	if retiredSite || retiredFunction {
+		// The project was a static site or ran on Lambda before, its subdomain no longer points anywhere
+		if err := d.o.route53Client.DeleteRecord(ctx, d.domain.String(), "A"); err != nil && !errors.Is(err, route53.ErrRecordNotFound) {
+			slog.WarnContext(ctx, "Failed to delete DNS record", "project_id", d.proj.ID().String(), "error", err)
+		}
+	}
+
+	req := d.request()
+	req.DesiredCount = 1
+	req.Strategy = d.strategy
+	if d.proj.Type() == project.TypeCron {
+		return d.o.deployCron(ctx, d.proj, d.dep, req)
+	}
+	return d.o.deployWorker(ctx, d.proj, d.dep, req)
+}
+
+// createRouting creates the target group and listener rule routing the project's subdomain to the service
+func (d *containerDeploy) createRouting(ctx context.Context) error {
+	d.dep.AppendLog("🔧 Creating ALB target group and routing rule...")
+	d.o.deploymentRepo.Save(ctx, d.dep)
+
+	var err error
+	if d.strategy == project.StrategyBlueGreen {
+		d.targetGroupArn, err = d.o.albClient.CreateBlueGreenRouting(ctx, d.serviceName, d.domain.String(), d.o.baseDomain, d.containerPort, d.proj.HealthCheckPath())
+	} else {
+		d.targetGroupArn, err = d.o.albClient.CreateTargetGroupAndRule(ctx, d.serviceName, d.domain.String(), d.o.baseDomain, d.containerPort, d.proj.HealthCheckPath())
+	}
+	if err != nil {
+		return err
+	}
+	d.dep.AppendLog("✅ ALB routing configured")
+
+	if d.replaced && d.wasBlueGreen && d.strategy != project.StrategyBlueGreen {
+		// Production traffic is routed to the service's own target group again
+		if err := d.o.albClient.DeleteBlueGreenRouting(ctx, d.serviceName); err != nil {
+			slog.WarnContext(ctx, "Failed to delete blue/green routing", "project_id", d.proj.ID().String(), "error", err)
+		}
+		if d.o.codeDeploy != nil {
+			if err := d.o.codeDeploy.DeleteApplication(ctx, d.serviceName); err != nil {
+				slog.WarnContext(ctx, "Failed to delete CodeDeploy application", "project_id", d.proj.ID().String(), "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+// deployService rolls the service out the way its strategy replaces tasks. A canary is compared against
+// the running version, so the first deployment goes out without one.
+func (d *containerDeploy) deployService(ctx context.Context) error {
+	req := d.request()
+	req.DesiredCount = 1
+	req.ContainerPort = d.containerPort
+	req.TargetGroupArn = d.targetGroupArn
+	req.Strategy = d.strategy
+
+	_, serviceErr := d.o.ecsClient.getService(ctx, d.serviceName)
+	if d.strategy == project.StrategyCanary && serviceErr == nil {
+		return d.o.deployCanary(ctx, d.proj, d.dep, req)
+	}
+	return d.o.rollOut(ctx, d.proj, d.dep, req)
+}
+
+// configureDNS points the project's subdomain at the load balancer
+func (d *containerDeploy) configureDNS(ctx context.Context) error {
+	d.dep.AppendLog(fmt.Sprintf("🌐 Configuring DNS for %s.%s...", d.domain.String(), d.o.baseDomain))
+	d.o.deploymentRepo.Save(ctx, d.dep)
+
+	err := d.o.route53Client.CreateOrUpdateRecord(ctx, route53.DNSRecordRequest{
+		Subdomain: d.domain.String(),
+		Target:    d.o.albDNS,
+		Type:      "ALIAS",
+	})
+	if err != nil {
+		return err
+	}
+	d.dep.AppendLog("✅ DNS configured successfully")
+	d.dep.AppendLog(fmt.Sprintf("🌍 Your app is live at: https://%s.%s", d.domain.String(), d.o.baseDomain))
+	return nil
+}
+
+// retirePreviousHosting deletes the distribution or API the subdomain pointed at while the project was a
+// static site or ran on Lambda
+func (d *containerDeploy) retirePreviousHosting(ctx context.Context) error {
+	d.o.retireStaticSite(ctx, d.proj, d.serviceName)
+	d.o.retireFunction(ctx, d.proj, d.serviceName)
+	return nil
+}
+
+// complete marks the deployment as deployed
+func (d *containerDeploy) complete(ctx context.Context) error {
+	if err := d.dep.UpdateStatus(deployment.StatusDeployed); err != nil {
+		return fmt.Errorf("failed to update deployment status: %w", err)
+	}
+	if err := d.o.deploymentRepo.Save(ctx, d.dep); err != nil {
+		return fmt.Errorf("failed to save deployment: %w", err)
+	}
+	d.dep.AppendLog("🎉 Deployment completed successfully!")
+	return nil
+}
+
+// request returns what every service of the deployment is deployed with
+func (d *containerDeploy) request() DeploymentRequest {
+	return DeploymentRequest{
+		ServiceName:     d.serviceName,
+		ImageURI:        d.imageURI,
+		ProjectID:       d.proj.ID().String(),
+		CustomDomain:    d.domain.String(),
+		CPU:             strconv.Itoa(d.proj.CPU()),
+		Memory:          strconv.Itoa(d.proj.Memory()),
+		SubnetIDs:       d.o.subnetIDs,
+		SecurityGroupID: d.o.securityGroupID,
+		EnvVars:         d.runtime.envVars,
+		Sidecars:        d.runtime.sidecars,
+		Volume:          d.runtime.volume,
+	}
+}
@@ -0,0 +1,283 @@
+package ecs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"snapdeploy-core/internal/domain/cronrun"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/infrastructure/eventbridge"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	logstypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+const (
+	// deploymentIDTag is the tag EventBridge adds to scheduled tasks, naming the deployment they run
+	deploymentIDTag = "SnapDeployDeploymentId"
+	// scheduledTaskPrefix starts the startedBy of tasks EventBridge rules run
+	scheduledTaskPrefix = "events-rule/"
+	// maxRunLogLines bounds the log lines read for a single run
+	maxRunLogLines = 10000
+)
+
+// cronRuleName returns the name of the EventBridge rule that starts the tasks of a CRON project's service
+func cronRuleName(serviceName string) string {
+	return serviceName + "-cron"
+}
+
+// deployCron registers a CRON project's task definition and schedules it. The deployment succeeds once
+// the schedule is in place, its runs are recorded as they happen.
+func (o *DeploymentOrchestrator) deployCron(ctx context.Context, proj *project.Project, dep *deployment.Deployment, req DeploymentRequest) error {
+	fail := func(step string, err error) error {
+		o.appendFailure(ctx, proj, dep, step, err)
+		dep.UpdateStatus(deployment.StatusFailed)
+		o.deploymentRepo.Save(ctx, dep)
+		return fmt.Errorf("%s: %w", strings.ToLower(step), err)
+	}
+
+	if o.scheduler == nil {
+		return fail("Scheduled tasks unavailable", errors.New("EventBridge is not configured on this platform"))
+	}
+
+	// A project that ran continuously before it became a cron job has a service to remove
+	if _, err := o.ecsClient.getService(ctx, req.ServiceName); err == nil {
+		dep.AppendLog("♻️  Removing the existing service, cron jobs only run on their schedule")
+		o.deploymentRepo.Save(ctx, dep)
+		if err := o.ecsClient.DeleteService(ctx, req.ServiceName); err != nil {
+			return fail("Failed to remove service", err)
+		}
+	} else if !isServiceNotFoundError(err) {
+		return fail("Failed to check service", err)
+	}
+
+	taskDefArn, err := o.ecsClient.createTaskDefinition(ctx, req)
+	if err != nil {
+		return fail("Failed to register task definition", err)
+	}
+
+	clusterArn, err := o.ecsClient.ClusterARN(ctx)
+	if err != nil {
+		return fail("Failed to find cluster", err)
+	}
+
+	if err := o.scheduler.PutSchedule(ctx, eventbridge.ScheduleRequest{
+		RuleName:          cronRuleName(req.ServiceName),
+		Schedule:          proj.Schedule().String(),
+		Description:       fmt.Sprintf("SnapDeploy cron job of project %s", proj.ID().String()),
+		ClusterArn:        clusterArn,
+		TaskDefinitionArn: taskDefArn,
+		SubnetIDs:         req.SubnetIDs,
+		SecurityGroupID:   req.SecurityGroupID,
+		Tags:              map[string]string{deploymentIDTag: dep.ID().String()},
+	}); err != nil {
+		return fail("Failed to schedule task", err)
+	}
+
+	dep.AppendLog(fmt.Sprintf("⏰ Scheduled to run on %s", proj.Schedule()))
+	if err := dep.UpdateStatus(deployment.StatusDeployed); err != nil {
+		return fmt.Errorf("failed to update deployment status: %w", err)
+	}
+	if err := o.deploymentRepo.Save(ctx, dep); err != nil {
+		return fmt.Errorf("failed to save deployment: %w", err)
+	}
+
+	dep.AppendLog("🎉 Cron job deployed successfully! Its runs are listed with the deployment.")
+	o.deploymentRepo.Save(ctx, dep)
+
+	slog.InfoContext(ctx, "ECS cron job deployment completed", "project_id", proj.ID().String())
+	return nil
+}
+
+// removeSchedule deletes the schedule of a project that no longer runs as a cron job
+func (o *DeploymentOrchestrator) removeSchedule(ctx context.Context, proj *project.Project, serviceName string) {
+	if o.scheduler == nil {
+		return
+	}
+	if err := o.scheduler.DeleteSchedule(ctx, cronRuleName(serviceName)); err != nil {
+		slog.WarnContext(ctx, "Failed to delete schedule", "project_id", proj.ID().String(), "error", err)
+	}
+}
+
+// cronFamilies returns the task definition families of a project's cron jobs in each of its environments:
+// its main service if the project is a CRON project, and its CRON services
+func cronFamilies(proj *project.Project) []string {
+	var families []string
+	for _, env := range proj.Environments() {
+		serviceName := environmentServiceName(proj.ID().String(), env)
+		if proj.Type() == project.TypeCron {
+			families = append(families, serviceName)
+		}
+		for _, svc := range proj.Services() {
+			if svc.Type() == project.TypeCron {
+				families = append(families, processServiceName(serviceName, svc))
+			}
+		}
+	}
+	return families
+}
+
+// ListScheduledTasks reports the tasks the schedules of a project's cron jobs started that ECS still
+// knows about. ECS forgets stopped tasks about an hour after they stop.
+func (o *DeploymentOrchestrator) ListScheduledTasks(ctx context.Context, proj *project.Project) ([]cronrun.TaskReport, error) {
+	var reports []cronrun.TaskReport
+	for _, family := range cronFamilies(proj) {
+		tasks, err := o.ecsClient.ListFamilyTasks(ctx, family)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, task := range tasks {
+			if !strings.HasPrefix(aws.ToString(task.StartedBy), scheduledTaskPrefix) {
+				continue
+			}
+			reports = append(reports, taskReport(task, family))
+		}
+	}
+
+	return reports, nil
+}
+
+// taskReport describes a scheduled task whose main container is named after its family
+func taskReport(task types.Task, family string) cronrun.TaskReport {
+	report := cronrun.TaskReport{
+		TaskARN: aws.ToString(task.TaskArn),
+		Stopped: aws.ToString(task.LastStatus) == string(types.DesiredStatusStopped),
+		Reason:  aws.ToString(task.StoppedReason),
+	}
+	for _, tag := range task.Tags {
+		if aws.ToString(tag.Key) == deploymentIDTag {
+			report.DeploymentID = aws.ToString(tag.Value)
+		}
+	}
+	switch {
+	case task.StartedAt != nil:
+		report.StartedAt = *task.StartedAt
+	case task.CreatedAt != nil:
+		report.StartedAt = *task.CreatedAt
+	}
+	if report.Stopped {
+		report.StoppedAt = task.StoppedAt
+	}
+	for _, container := range task.Containers {
+		if aws.ToString(container.Name) != family {
+			continue
+		}
+		if container.ExitCode != nil {
+			code := int(*container.ExitCode)
+			report.ExitCode = &code
+		}
+		if report.Reason == "" {
+			report.Reason = aws.ToString(container.Reason)
+		}
+	}
+	return report
+}
+
+// GetRunLogs returns the log lines the main container of one of a project's scheduled tasks wrote.
+// Each cron job logs to its own group, so the task's stream is looked for in each of them.
+func (o *DeploymentOrchestrator) GetRunLogs(ctx context.Context, proj *project.Project, taskID string) ([]string, error) {
+	for _, family := range cronFamilies(proj) {
+		lines, err := o.ecsClient.GetTaskLogLines(ctx, family, taskID)
+		if err != nil {
+			return nil, err
+		}
+		if len(lines) > 0 {
+			return lines, nil
+		}
+	}
+	return nil, nil
+}
+
+// ClusterARN returns the ARN of the cluster services and tasks run in
+func (c *ECSClient) ClusterARN(ctx context.Context) (string, error) {
+	result, err := c.client.DescribeClusters(ctx, &ecs.DescribeClustersInput{
+		Clusters: []string{c.clusterName},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe cluster: %w", err)
+	}
+	if len(result.Clusters) == 0 {
+		return "", fmt.Errorf("cluster not found: %s", c.clusterName)
+	}
+	return aws.ToString(result.Clusters[0].ClusterArn), nil
+}
+
+// ListFamilyTasks returns the running and recently stopped tasks of a task definition family, with their tags
+func (c *ECSClient) ListFamilyTasks(ctx context.Context, family string) ([]types.Task, error) {
+	var arns []string
+	for _, status := range []types.DesiredStatus{types.DesiredStatusRunning, types.DesiredStatusStopped} {
+		paginator := ecs.NewListTasksPaginator(c.client, &ecs.ListTasksInput{
+			Cluster:       aws.String(c.clusterName),
+			Family:        aws.String(family),
+			DesiredStatus: status,
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list tasks: %w", err)
+			}
+			arns = append(arns, page.TaskArns...)
+		}
+	}
+
+	// Tasks are described 100 at a time
+	var tasks []types.Task
+	for start := 0; start < len(arns); start += 100 {
+		end := min(start+100, len(arns))
+		result, err := c.client.DescribeTasks(ctx, &ecs.DescribeTasksInput{
+			Cluster: aws.String(c.clusterName),
+			Tasks:   arns[start:end],
+			Include: []types.TaskField{types.TaskFieldTags},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe tasks: %w", err)
+		}
+		tasks = append(tasks, result.Tasks...)
+	}
+
+	return tasks, nil
+}
+
+// GetTaskLogLines returns the log lines a task's main container wrote, oldest first
+func (c *ECSClient) GetTaskLogLines(ctx context.Context, serviceName, taskID string) ([]string, error) {
+	input := &cloudwatchlogs.GetLogEventsInput{
+		LogGroupName:  aws.String(serviceLogGroup(serviceName)),
+		LogStreamName: aws.String(fmt.Sprintf("ecs/%s/%s", serviceName, taskID)),
+		StartFromHead: aws.Bool(true),
+	}
+
+	var lines []string
+	for len(lines) < maxRunLogLines {
+		result, err := c.logs.GetLogEvents(ctx, input)
+		if err != nil {
+			// The stream is only created once the container writes its first line
+			var notFound *logstypes.ResourceNotFoundException
+			if errors.As(err, &notFound) {
+				return lines, nil
+			}
+			return nil, fmt.Errorf("failed to get task logs: %w", err)
+		}
+		for _, event := range result.Events {
+			lines = append(lines, strings.TrimRight(aws.ToString(event.Message), "\r\n"))
+		}
+
+		// The same token is returned once the end of the stream is reached
+		if len(result.Events) == 0 || aws.ToString(result.NextForwardToken) == aws.ToString(input.NextToken) {
+			break
+		}
+		input.NextToken = result.NextForwardToken
+	}
+
+	if len(lines) > maxRunLogLines {
+		lines = lines[:maxRunLogLines]
+	}
+	return lines, nil
+}
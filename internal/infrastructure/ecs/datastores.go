@@ -0,0 +1,53 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/infrastructure/database"
+)
+
+// provisionDatastores provisions the datastores a project uses besides its Postgres database, adding the
+// variables its services connect to them with to envVars. It returns the sidecars to run next to the service.
+func (o *DeploymentOrchestrator) provisionDatastores(
+	ctx context.Context,
+	proj *project.Project,
+	dep *deployment.Deployment,
+	envVars map[string]string,
+) ([]database.Sidecar, error) {
+	var sidecars []database.Sidecar
+	for _, datastore := range proj.Datastores() {
+		provisioner, ok := o.datastores[datastore]
+		if !ok {
+			dep.AppendLog(fmt.Sprintf("❌ %s datastore required but not available on this platform", datastore))
+			return nil, fmt.Errorf("%s datastore required but not available", datastore)
+		}
+
+		provisioned, err := provisioner.Provision(ctx, proj.ID().String())
+		if err != nil {
+			dep.AppendLog(fmt.Sprintf("❌ Failed to provision %s datastore: %v", datastore, err))
+			return nil, fmt.Errorf("failed to provision %s datastore: %w", datastore, err)
+		}
+
+		for key, value := range provisioned.EnvVars {
+			envVars[key] = value
+		}
+
+		switch {
+		case provisioned.Sidecar != nil:
+			sidecars = append(sidecars, *provisioned.Sidecar)
+			dep.AppendLog(fmt.Sprintf("🧩 Running %s next to the service", provisioned.Name))
+		case provisioned.Created:
+			dep.AppendLog(fmt.Sprintf("✅ Created %s", provisioned.Name))
+		default:
+			dep.AppendLog(fmt.Sprintf("🗄️  Using existing %s", provisioned.Name))
+		}
+	}
+
+	if len(proj.Datastores()) > 0 {
+		o.deploymentRepo.Save(ctx, dep)
+	}
+	return sidecars, nil
+}
@@ -2,17 +2,35 @@ package ecs
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+	"strconv"
 	"strings"
 	"time"
 
+	appconfig "snapdeploy-core/internal/config"
 	"snapdeploy-core/internal/domain/deployment"
 	"snapdeploy-core/internal/domain/project"
 	"snapdeploy-core/internal/infrastructure/alb"
+	"snapdeploy-core/internal/infrastructure/awsretry"
+	"snapdeploy-core/internal/infrastructure/cloudfront"
+	"snapdeploy-core/internal/infrastructure/cloudwatch"
+	"snapdeploy-core/internal/infrastructure/codedeploy"
 	"snapdeploy-core/internal/infrastructure/database"
+	"snapdeploy-core/internal/infrastructure/ecr"
+	"snapdeploy-core/internal/infrastructure/efs"
+	"snapdeploy-core/internal/infrastructure/eventbridge"
+	"snapdeploy-core/internal/infrastructure/iam"
+	"snapdeploy-core/internal/infrastructure/lambda"
+	"snapdeploy-core/internal/infrastructure/quota"
 	"snapdeploy-core/internal/infrastructure/route53"
+	"snapdeploy-core/internal/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // DeploymentOrchestrator orchestrates the full deployment process
@@ -20,81 +38,291 @@ type DeploymentOrchestrator struct {
 	ecsClient       *ECSClient
 	albClient       *alb.ALBClient
 	route53Client   *route53.Route53Client
+	ecrClient       *ecr.ECRClient
+	metricsClient   *cloudwatch.MetricsClient
+	codeDeploy      *codedeploy.CodeDeployClient
 	deploymentRepo  deployment.DeploymentRepository
 	envVarRepo      project.EnvironmentVariableRepository
 	dbManager       *database.PostgresManager
+	datastores      map[project.Datastore]database.Provisioner
+	efsClient       *efs.EFSClient
+	roles           *iam.RoleClient
+	scheduler       *eventbridge.SchedulerClient
 	taskRunner      *TaskRunner
+	cdn             *cloudfront.CloudFrontClient
+	siteUploadRole  string // Role of the tasks uploading static sites, the only ones allowed to write to the bucket
+	functions       *lambda.LambdaClient
 	clusterName     string
 	albDNS          string
 	baseDomain      string
 	subnetIDs       []string
 	securityGroupID string
+	alerter         AdminAlerter
+	locker          ProjectLocker
+	steps           deployment.StepRepository
+
+	// Thresholds a canary's traffic must stay within to be promoted
+	canaryMaxErrorRate    float64
+	canaryMaxResponseTime time.Duration
+}
+
+// AdminAlerter notifies platform operators about problems they need to act on
+type AdminAlerter interface {
+	Alert(ctx context.Context, key, title, message string)
+}
+
+// ProjectLocker serializes the changes made to a project's cloud resources across deployments and server instances
+type ProjectLocker interface {
+	LockProject(ctx context.Context, projectID string) (unlock func(), err error)
+}
+
+// projectLockKey marks a context as holding the lock of a project, the *projectLock stored under it
+type projectLockKey struct{}
+
+// projectLock is the lock of a project a context holds, see lockProject
+type projectLock struct {
+	projectID string
+	locker    ProjectLocker
+	unlock    func() // Nil while the lock is released
+}
+
+// release releases the lock unless it is released already
+func (l *projectLock) release() {
+	if l.unlock != nil {
+		l.unlock()
+		l.unlock = nil
+	}
 }
 
-// NewDeploymentOrchestrator creates a new deployment orchestrator
+// NewDeploymentOrchestrator creates a new deployment orchestrator deploying to the AWS resources in settings,
+// with project databases on the datastore servers
 func NewDeploymentOrchestrator(
+	settings *appconfig.AWSConfig,
+	datastoreSettings *appconfig.DatastoresConfig,
 	deploymentRepo deployment.DeploymentRepository,
 	envVarRepo project.EnvironmentVariableRepository,
 ) (*DeploymentOrchestrator, error) {
-	ecsClient, err := NewECSClient()
+	ecsClient, err := NewECSClient(settings.ECS.ClusterName, settings.Region, settings.ECS.TaskRoleARN, settings.ECS.ExecutionRoleARN)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ECS client: %w", err)
 	}
+	ecsClient.SetLogRetention(int32(settings.ECS.LogRetentionDays))
 
-	albClient, err := alb.NewALBClient()
+	albClient, err := alb.NewALBClient(settings.ALB.ListenerARN, settings.ALB.TestListenerARN, settings.Network.VPCID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ALB client: %w", err)
 	}
 
-	route53Client, err := route53.NewRoute53Client()
+	route53Client, err := route53.NewRoute53Client(settings.DNS.HostedZoneID, settings.DNS.BaseDomain)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Route53 client: %w", err)
 	}
 
-	// Create database manager (may fail if RDS env vars not set, which is OK)
-	dbManager, err := database.NewPostgresManager()
+	// Create ECR client (used to clean up images when a project is deleted)
+	ecrClient, err := ecr.NewECRClient(settings.DockerRegistry, settings.ECS.ExecutionRoleARN)
+	if err != nil {
+		slog.Warn("Could not initialize ECR client, images will not be cleaned up on project deletion", "error", err)
+	}
+
+	// Create CloudWatch client (used to report runtime metrics of deployed services)
+	metricsClient, err := cloudwatch.NewMetricsClient()
 	if err != nil {
-		log.Printf("[ECS] Warning: Could not initialize database manager: %v", err)
-		log.Printf("[ECS] Database features will be unavailable")
+		slog.Warn("Could not initialize CloudWatch client, service metrics will be unavailable", "error", err)
+	}
+
+	// Create CodeDeploy client (used to deploy projects with the blue/green strategy)
+	codeDeployClient, err := codedeploy.NewCodeDeployClient(settings.CodeDeploy.ServiceRoleARN)
+	if err != nil {
+		slog.Warn("Could not initialize CodeDeploy client, blue/green deployments will be unavailable", "error", err)
+	}
+
+	// Create database manager (may fail if RDS settings are not set, which is OK)
+	dbManager, err := database.NewPostgresManager(datastoreSettings.Postgres)
+	if err != nil {
+		slog.Warn("Could not initialize database manager, database features will be unavailable", "error", err)
 		// Don't fail - database is optional
 	}
 
-	// Get infrastructure configuration from environment
-	clusterName := os.Getenv("ECS_CLUSTER_NAME")
-	if clusterName == "" {
-		clusterName = "snapdeploy-dev-cluster" // default
+	// Create EFS client (used to mount persistent volumes into services)
+	efsClient, err := efs.NewEFSClient(settings.Network.SubnetIDs, settings.Network.EFSSecurityGroupID)
+	if err != nil {
+		slog.Warn("Could not initialize EFS client, persistent volumes will be unavailable", "error", err)
 	}
-	albDNS := os.Getenv("ALB_DNS_NAME")
-	baseDomain := os.Getenv("BASE_DOMAIN")
-	if baseDomain == "" {
-		baseDomain = "snap-deploy.com"
+
+	// Create IAM client (used to check task roles exist and to create the roles of projects)
+	roles, err := iam.NewRoleClient(settings.ECS.TaskRolePolicyARNs, settings.ECS.TaskRoleBoundaryARN)
+	if err != nil {
+		slog.Warn("Could not initialize IAM client, task roles will not be checked before deployments", "error", err)
+	} else {
+		ecsClient.SetRoleManager(roles, settings.ECS.ProjectTaskRoles())
+	}
+
+	// Create EventBridge client (used to run CRON projects on their schedule)
+	scheduler, err := eventbridge.NewSchedulerClient(settings.ECS.ScheduledTaskRoleARN)
+	if err != nil {
+		slog.Warn("Could not initialize EventBridge client, cron jobs will be unavailable", "error", err)
 	}
-	subnetIDs := strings.Split(os.Getenv("SUBNET_IDS"), ",")
-	securityGroupID := os.Getenv("SECURITY_GROUP_ID")
 
+	// Create CloudFront client (used to serve STATIC projects from S3)
+	sites := settings.StaticSites
+	cdn, err := cloudfront.NewCloudFrontClient(sites.Bucket, sites.CertificateARN, sites.OriginAccessControlID)
+	if err == nil && sites.UploadRoleARN == "" {
+		err = fmt.Errorf("STATIC_SITE_UPLOAD_ROLE_ARN is not set")
+	}
+	if err != nil {
+		slog.Warn("Could not initialize CloudFront client, static sites will be unavailable", "error", err)
+		cdn = nil
+	}
+
+	// Create Lambda client (used to run LAMBDA projects)
+	functions, err := lambda.NewLambdaClient(settings.Lambda.ExecutionRoleARN, settings.Lambda.APIGatewayCertificateARN)
+	if err != nil {
+		slog.Warn("Could not initialize Lambda client, Lambda deployments will be unavailable", "error", err)
+	}
+
+	// Create provisioners of the other datastores projects can use
+	datastores := map[project.Datastore]database.Provisioner{
+		project.DatastoreRedis: database.NewRedisSidecar(datastoreSettings.RedisImage),
+	}
+	mysqlManager, err := database.NewMySQLManager(datastoreSettings.MySQL)
+	if err != nil {
+		slog.Warn("Could not initialize MySQL manager, MySQL datastores will be unavailable", "error", err)
+	} else {
+		datastores[project.DatastoreMySQL] = mysqlManager
+	}
+
+	clusterName := settings.ECS.ClusterName
+	albDNS := settings.ALB.DNSName
+	baseDomain := settings.DNS.BaseDomain
+	subnetIDs := settings.Network.SubnetIDs
+	securityGroupID := settings.Network.SecurityGroupID
 	if albDNS == "" || len(subnetIDs) == 0 || securityGroupID == "" {
-		return nil, fmt.Errorf("missing required environment variables (ALB_DNS_NAME, SUBNET_IDS, SECURITY_GROUP_ID)")
+		return nil, fmt.Errorf("missing required settings (ALB_DNS_NAME, SUBNET_IDS, SECURITY_GROUP_ID)")
 	}
 
+	canaryMaxErrorRate := settings.Canary.MaxErrorPercent / 100
+	canaryMaxResponseTime := time.Duration(settings.Canary.MaxResponseTimeMS) * time.Millisecond
+
 	// Create task runner for running one-off tasks (migrations)
-	taskRunner := NewTaskRunner(ecsClient.client, clusterName, subnetIDs, securityGroupID)
+	taskRunner := NewTaskRunner(ecsClient.client, ecsClient.logs, clusterName, subnetIDs, securityGroupID)
 
 	return &DeploymentOrchestrator{
 		ecsClient:       ecsClient,
 		albClient:       albClient,
 		route53Client:   route53Client,
+		ecrClient:       ecrClient,
+		metricsClient:   metricsClient,
+		codeDeploy:      codeDeployClient,
 		deploymentRepo:  deploymentRepo,
 		envVarRepo:      envVarRepo,
 		dbManager:       dbManager,
+		datastores:      datastores,
+		efsClient:       efsClient,
+		roles:           roles,
+		scheduler:       scheduler,
 		taskRunner:      taskRunner,
+		cdn:             cdn,
+		siteUploadRole:  sites.UploadRoleARN,
+		functions:       functions,
 		clusterName:     clusterName,
 		albDNS:          albDNS,
 		baseDomain:      baseDomain,
 		subnetIDs:       subnetIDs,
 		securityGroupID: securityGroupID,
+
+		canaryMaxErrorRate:    canaryMaxErrorRate,
+		canaryMaxResponseTime: canaryMaxResponseTime,
 	}, nil
 }
 
+// SetAdminAlerter sets the alerter notified when deployments hit AWS quotas
+func (o *DeploymentOrchestrator) SetAdminAlerter(alerter AdminAlerter) {
+	o.alerter = alerter
+}
+
+// SetProjectLocker sets the lock taken around changes to a project's target groups, DNS records and services
+// (optional). Without one, concurrent deployments of a project may interleave their changes.
+func (o *DeploymentOrchestrator) SetProjectLocker(locker ProjectLocker) {
+	o.locker = locker
+}
+
+// SetPriorityStore sets the table the priorities of listener rules are allocated from (optional). Without one,
+// deployments on other server instances may pick the same priority and deleted rules' priorities are only reused
+// once no rule has them.
+func (o *DeploymentOrchestrator) SetPriorityStore(store alb.PriorityStore) {
+	o.albClient.SetPriorityStore(store)
+}
+
+// SetDatastoreCredentials sets where the passwords of projects' accounts on shared datastore servers are kept.
+// Without one, MySQL datastores can't be provisioned.
+func (o *DeploymentOrchestrator) SetDatastoreCredentials(store database.CredentialStore) {
+	if mysqlManager, ok := o.datastores[project.DatastoreMySQL].(*database.MySQLManager); ok {
+		mysqlManager.SetCredentialStore(store)
+	}
+}
+
+// SetServiceEvents sets the source of the service events rollouts are followed with instead of polling
+// the services (optional)
+func (o *DeploymentOrchestrator) SetServiceEvents(events ServiceEventSource) {
+	o.ecsClient.SetServiceEvents(events)
+}
+
+// lockProject waits until no other change to a project's cloud resources is in progress, and returns a context
+// marking the lock as held so operations calling each other only take it once. The lock is held while a
+// project's load balancer routing, DNS records and services are changed, not while waiting for the changes to
+// take effect, see waitUnlocked.
+func (o *DeploymentOrchestrator) lockProject(ctx context.Context, proj *project.Project) (context.Context, func(), error) {
+	projectID := proj.ID().String()
+	if held, ok := ctx.Value(projectLockKey{}).(*projectLock); o.locker == nil || ok && held.projectID == projectID {
+		return ctx, func() {}, nil
+	}
+
+	unlock, err := o.locker.LockProject(ctx, projectID)
+	if err != nil {
+		return ctx, nil, fmt.Errorf("failed to lock project infrastructure: %w", err)
+	}
+	lock := &projectLock{projectID: projectID, locker: o.locker, unlock: unlock}
+	return context.WithValue(ctx, projectLockKey{}, lock), lock.release, nil
+}
+
+// waitUnlocked runs wait, which waits for changes to take effect without making any, releasing the project
+// lock the context holds meanwhile: waiting for a service to stabilize or a canary to bake takes minutes, which
+// would hold up every other change to the project and keep the lock's database connection. The lock is taken
+// again once wait returns.
+func waitUnlocked(ctx context.Context, wait func() error) error {
+	lock, ok := ctx.Value(projectLockKey{}).(*projectLock)
+	if !ok || lock.unlock == nil {
+		return wait()
+	}
+
+	lock.release()
+	waitErr := wait()
+	unlock, err := lock.locker.LockProject(ctx, lock.projectID)
+	if err != nil {
+		return errors.Join(waitErr, fmt.Errorf("failed to lock project infrastructure: %w", err))
+	}
+	lock.unlock = unlock
+	return waitErr
+}
+
+// recordImage records on a deployment the image it runs, with the digest the registry reports for it.
+// The deployment goes ahead without the digest if it can't be looked up.
+func (o *DeploymentOrchestrator) recordImage(ctx context.Context, dep *deployment.Deployment, imageURI string) {
+	var digest string
+	if o.ecrClient != nil {
+		var err error
+		if digest, err = o.ecrClient.ImageDigest(ctx, imageURI); err != nil {
+			slog.WarnContext(ctx, "Failed to look up image digest", "image", imageURI, "error", err)
+		}
+	}
+	dep.RecordImage(imageURI, digest)
+}
+
+// DatabaseManager returns the manager of project databases, or nil if databases are unavailable
+func (o *DeploymentOrchestrator) DatabaseManager() *database.PostgresManager {
+	return o.dbManager
+}
+
 // DeployToECS deploys a built image to ECS
 func (o *DeploymentOrchestrator) DeployToECS(
 	ctx context.Context,
@@ -102,7 +330,51 @@ func (o *DeploymentOrchestrator) DeployToECS(
 	proj *project.Project,
 	imageURI string,
 ) error {
-	log.Printf("[ECS] Starting ECS deployment for project %s", proj.ID().String())
+	return o.deploy(ctx, dep, proj, imageURI, "")
+}
+
+// deploy deploys an image, recording the pipeline steps it goes through. A deployment retried from a step
+// carries over the outcome of the steps before it that it can skip.
+func (o *DeploymentOrchestrator) deploy(
+	ctx context.Context,
+	dep *deployment.Deployment,
+	proj *project.Project,
+	imageURI string,
+	from deployment.Step,
+) (err error) {
+	ctx, span := tracing.Start(ctx, "ecs.deploy", attribute.String("deployment.id", dep.ID().String()))
+	defer func() { tracing.End(span, err) }()
+
+	// Container deployments hold the project lock in the stages changing its services, routing and DNS,
+	// static sites and functions throughout
+	if proj.Type() == project.TypeStatic || proj.DeploymentTarget() == project.TargetLambda {
+		var unlock func()
+		ctx, unlock, err = o.lockProject(ctx, proj)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	// Throttled or failing AWS calls are retried, show the retries in the deployment logs
+	ctx = awsretry.WithReporter(ctx, func(message string) { dep.AppendLog("🔁 " + message) })
+
+	o.recordImage(ctx, dep, imageURI)
+
+	steps := o.trackSteps(ctx, dep, from)
+	defer func() { steps.close(ctx, err) }()
+
+	// Static sites are uploaded once and served by CloudFront, no container keeps running
+	if proj.Type() == project.TypeStatic {
+		return o.deployStatic(ctx, proj, dep, imageURI)
+	}
+
+	// LAMBDA projects run as a function, served without ECS services or the load balancer
+	if proj.DeploymentTarget() == project.TargetLambda {
+		return o.deployLambda(ctx, proj, dep, imageURI, steps)
+	}
+
+	slog.InfoContext(ctx, "Starting ECS deployment", "project_id", proj.ID().String())
 
 	// Update deployment status
 	if err := dep.UpdateStatus(deployment.StatusDeploying); err != nil {
@@ -115,13 +387,55 @@ func (o *DeploymentOrchestrator) DeployToECS(
 	dep.AppendLog("🚀 Starting ECS deployment...")
 	o.deploymentRepo.Save(ctx, dep)
 
-	// Generate service name based on project ID
-	serviceName := generateServiceName(proj.ID().String())
+	// Generate service name based on project ID and environment
+	serviceName := environmentServiceName(proj.ID().String(), dep.Environment())
+	domain := proj.CustomDomain().ForEnvironment(dep.Environment())
 
+	if !dep.Environment().IsProduction() {
+		dep.AppendLog(fmt.Sprintf("🌱 Environment: %s", dep.Environment()))
+	}
 	dep.AppendLog(fmt.Sprintf("📦 Deploying service: %s", serviceName))
 	dep.AppendLog(fmt.Sprintf("🖼️  Image: %s", imageURI))
 	o.deploymentRepo.Save(ctx, dep)
 
+	run := &containerDeploy{
+		o:           o,
+		proj:        proj,
+		dep:         dep,
+		steps:       steps,
+		imageURI:    imageURI,
+		serviceName: serviceName,
+		domain:      domain,
+	}
+	if err := o.newPipeline(proj, dep, steps).run(ctx, run.stages()); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "ECS deployment completed", "project_id", proj.ID().String())
+	return nil
+}
+
+// projectRuntime is what the processes of a project's deployment run with
+type projectRuntime struct {
+	envVars  map[string]string
+	sidecars []database.Sidecar
+	volume   *VolumeMount
+}
+
+// prepareRuntime loads the environment variables of a deployment's environment, provisions the project's
+// datastores, volume and database, and runs its migrations with the deployment's image. Failures are
+// recorded on the deployment.
+func (o *DeploymentOrchestrator) prepareRuntime(
+	ctx context.Context,
+	proj *project.Project,
+	dep *deployment.Deployment,
+	serviceName string,
+	imageURI string,
+	steps *stepTracker,
+) (*projectRuntime, error) {
+	// The db step provisions everything the project's processes run with
+	steps.start(ctx, deployment.StepDB)
+
 	// Load and decrypt project environment variables FIRST
 	dep.AppendLog("🔐 Loading environment variables...")
 	o.deploymentRepo.Save(ctx, dep)
@@ -130,15 +444,15 @@ func (o *DeploymentOrchestrator) DeployToECS(
 	projectEnvVars := map[string]string{
 		"PROJECT_ID": proj.ID().String(),
 		"LANGUAGE":   proj.Language().String(),
-		"PORT":       "8080", // Default port, can be overridden by user
+		"PORT":       strconv.Itoa(proj.Port()), // Can be overridden by user
 	}
 
-	// Get decrypted user env vars from repository
+	// Get decrypted user env vars from repository, build-only ones were already passed to the build
 	userEnvCount := 0
 	if envVarRepoImpl, ok := o.envVarRepo.(interface {
-		DecryptAll(ctx context.Context, projectID project.ProjectID) (map[string]string, error)
+		DecryptRuntime(ctx context.Context, projectID project.ProjectID, env project.Environment) (map[string]string, error)
 	}); ok {
-		userEnvVars, err := envVarRepoImpl.DecryptAll(ctx, proj.ID())
+		userEnvVars, err := envVarRepoImpl.DecryptRuntime(ctx, proj.ID(), dep.Environment())
 		if err != nil {
 			dep.AppendLog(fmt.Sprintf("⚠️  Warning: Could not load env vars: %v", err))
 		} else if len(userEnvVars) > 0 {
@@ -157,38 +471,72 @@ func (o *DeploymentOrchestrator) DeployToECS(
 	}
 	o.deploymentRepo.Save(ctx, dep)
 
+	// Provision the datastores the project uses besides its Postgres database
+	sidecars, err := o.provisionDatastores(ctx, proj, dep, projectEnvVars)
+	if err != nil {
+		dep.UpdateStatus(deployment.StatusFailed)
+		o.deploymentRepo.Save(ctx, dep)
+		return nil, err
+	}
+
+	// Provision the persistent volume before migrations, which may need to write to it
+	volume, err := o.provisionVolume(ctx, proj, dep)
+	if err != nil {
+		dep.UpdateStatus(deployment.StatusFailed)
+		o.deploymentRepo.Save(ctx, dep)
+		return nil, err
+	}
+
+	// Environments share the project's database unless they set their own DATABASE_URL, e.g. to a database branch
+	_, ownDatabase := projectEnvVars["DATABASE_URL"]
+	ownDatabase = ownDatabase && !dep.Environment().IsProduction()
+	if ownDatabase && proj.RequireDB() {
+		dep.AppendLog("🗄️  Using the environment's own DATABASE_URL")
+		o.deploymentRepo.Save(ctx, dep)
+	}
+
 	// Handle database creation if required
-	if proj.RequireDB() {
+	if proj.RequireDB() && !ownDatabase {
 		if o.dbManager == nil {
 			dep.AppendLog("❌ Database required but database manager not available")
 			dep.UpdateStatus(deployment.StatusFailed)
 			o.deploymentRepo.Save(ctx, dep)
-			return fmt.Errorf("database required but database manager not initialized")
+			return nil, fmt.Errorf("database required but database manager not initialized")
 		}
 
-		dep.AppendLog("🗄️  Database required - creating fresh database...")
-		o.deploymentRepo.Save(ctx, dep)
-
 		// Generate database name from project ID
 		dbName := database.GetDatabaseName(proj.ID().String())
 
-		// Create/reset database (drops if exists, then creates fresh)
-		if err := o.dbManager.CreateDatabase(ctx, dbName); err != nil {
+		// The database is created by the first deployment and keeps its data across later ones
+		created, err := o.dbManager.EnsureDatabase(ctx, dbName)
+		if err != nil {
 			dep.AppendLog(fmt.Sprintf("❌ Failed to create database: %v", err))
 			dep.UpdateStatus(deployment.StatusFailed)
 			o.deploymentRepo.Save(ctx, dep)
-			return fmt.Errorf("failed to create database: %w", err)
+			return nil, fmt.Errorf("failed to create database: %w", err)
 		}
 
 		// Get database URL and add to environment variables
 		databaseURL := o.dbManager.GetDatabaseURL(dbName)
 		projectEnvVars["DATABASE_URL"] = databaseURL
 
-		dep.AppendLog(fmt.Sprintf("✅ Database created: %s", dbName))
+		if created {
+			dep.AppendLog(fmt.Sprintf("✅ Database created: %s", dbName))
+		} else {
+			dep.AppendLog(fmt.Sprintf("🗄️  Using existing database: %s", dbName))
+		}
 		o.deploymentRepo.Save(ctx, dep)
+	}
+
+	steps.finish(ctx, deployment.StepDB, nil)
 
-		// Run migrations if migration command is specified
-		if !proj.MigrationCommand().IsEmpty() {
+	// Run migrations if migration command is specified
+	if proj.RequireDB() || proj.UsesDatastore(project.DatastoreMySQL) || proj.HasVolume() {
+		if !proj.MigrationCommand().IsEmpty() && steps.carryOver(ctx, deployment.StepMigrate) {
+			dep.AppendLog("⏭️  Skipping database migrations, they completed in the earlier attempt")
+			o.deploymentRepo.Save(ctx, dep)
+		} else if !proj.MigrationCommand().IsEmpty() {
+			steps.start(ctx, deployment.StepMigrate)
 			dep.AppendLog(fmt.Sprintf("🔄 Running database migrations: %s", proj.MigrationCommand().String()))
 			o.deploymentRepo.Save(ctx, dep)
 
@@ -197,112 +545,214 @@ func (o *DeploymentOrchestrator) DeployToECS(
 			migrationTaskDef := fmt.Sprintf("%s-migration", serviceName)
 
 			// The migration will use the same image that we're about to deploy
-			// and will have access to DATABASE_URL
-			err := o.runMigration(ctx, dep, migrationTaskDef, serviceName, imageURI, proj.MigrationCommand().String(), projectEnvVars)
+			// and will have access to DATABASE_URL, MYSQL_URL and the persistent volume
+			err := o.runMigration(ctx, dep, migrationTaskDef, serviceName, imageURI, proj.MigrationCommand().String(), projectEnvVars, volume)
 			if err != nil {
-				dep.AppendLog(fmt.Sprintf("❌ Migration failed: %v", err))
+				o.appendFailure(ctx, proj, dep, "Migration failed", err)
+				dep.RecordMigration(false, err.Error())
 				dep.UpdateStatus(deployment.StatusFailed)
 				o.deploymentRepo.Save(ctx, dep)
 				// Database stays created but migrations failed - user can retry
-				return fmt.Errorf("migration failed: %w", err)
+				return nil, fmt.Errorf("migration failed: %w", err)
 			}
 
 			dep.AppendLog("✅ Database migrations completed successfully")
+			dep.RecordMigration(true, "Database migrations completed")
 			o.deploymentRepo.Save(ctx, dep)
+			steps.finish(ctx, deployment.StepMigrate, nil)
 		}
 	}
 
-	// Determine container port (from PORT env var if set, otherwise default 8080)
-	containerPort := int32(8080)
-	if portStr, ok := projectEnvVars["PORT"]; ok {
-		if port, err := parsePort(portStr); err == nil {
-			containerPort = port
-			dep.AppendLog(fmt.Sprintf("🔌 Using custom PORT: %d", containerPort))
-			o.deploymentRepo.Save(ctx, dep)
+	return &projectRuntime{envVars: projectEnvVars, sidecars: sidecars, volume: volume}, nil
+}
+
+// rollOut deploys a task definition to a project's service the way its strategy replaces tasks
+// and waits until the new version serves traffic. Failures are recorded on the deployment.
+func (o *DeploymentOrchestrator) rollOut(ctx context.Context, proj *project.Project, dep *deployment.Deployment, req DeploymentRequest) error {
+	// Deploy to ECS
+	result, err := o.ecsClient.DeployService(ctx, req)
+	if err != nil {
+		o.appendFailure(ctx, proj, dep, "ECS deployment failed", err)
+		dep.UpdateStatus(deployment.StatusFailed)
+		o.deploymentRepo.Save(ctx, dep)
+		// Clean up ALB resources
+		if req.TargetGroupArn != "" {
+			o.albClient.DeleteTargetGroupAndRule(ctx, req.ServiceName)
 		}
+		return fmt.Errorf("failed to deploy to ECS: %w", err)
 	}
 
-	// Create ALB target group and listener rule with the correct port
-	dep.AppendLog("🔧 Creating ALB target group and routing rule...")
+	dep.AppendLog("✅ ECS service created/updated successfully")
 	o.deploymentRepo.Save(ctx, dep)
 
-	targetGroupArn, err := o.albClient.CreateTargetGroupAndRule(
-		ctx,
-		serviceName,
-		proj.CustomDomain().String(),
-		o.baseDomain,
-		containerPort,
-	)
-	if err != nil {
-		dep.AppendLog(fmt.Sprintf("❌ Failed to create ALB routing: %v", err))
-		dep.UpdateStatus(deployment.StatusFailed)
+	if req.Strategy == project.StrategyBlueGreen {
+		bgReq := o.blueGreenRequest(req.ServiceName, result.TaskDefinitionArn, req.ContainerPort)
+		if err := o.codeDeploy.EnsureDeploymentGroup(ctx, bgReq); err != nil {
+			o.appendFailure(ctx, proj, dep, "Failed to configure CodeDeploy", err)
+			dep.UpdateStatus(deployment.StatusFailed)
+			o.deploymentRepo.Save(ctx, dep)
+			return fmt.Errorf("failed to configure CodeDeploy: %w", err)
+		}
+
+		// A new service starts on the new task definition, an existing one gets a replacement task set
+		if !result.Created {
+			if err := o.shiftTraffic(ctx, dep, bgReq); err != nil {
+				o.appendFailure(ctx, proj, dep, "Blue/green deployment failed, traffic stays on the previous version", err)
+				dep.UpdateStatus(deployment.StatusFailed)
+				o.deploymentRepo.Save(ctx, dep)
+				return fmt.Errorf("blue/green deployment failed: %w", err)
+			}
+			dep.AppendLog("✅ Traffic shifted to the new version")
+			o.deploymentRepo.Save(ctx, dep)
+		}
+	}
+
+	if req.Strategy != project.StrategyBlueGreen || result.Created {
+		// Wait for service to stabilize
+		dep.AppendLog("⏳ Waiting for service to become stable...")
+		o.deploymentRepo.Save(ctx, dep)
+
+		waitErr := waitUnlocked(ctx, func() error {
+			return o.ecsClient.WaitForServiceStable(ctx, req.ServiceName, 5*time.Minute, o.rolloutReporter(ctx, dep))
+		})
+		if waitErr != nil && quota.Classify(waitErr) != nil {
+			// Tasks can't be placed until capacity is freed
+			o.appendFailure(ctx, proj, dep, "Service failed to start", waitErr)
+			dep.UpdateStatus(deployment.StatusFailed)
+			o.deploymentRepo.Save(ctx, dep)
+			return fmt.Errorf("failed to start ECS service: %w", waitErr)
+		}
+
+		// The circuit breaker puts the previous version back if the new tasks keep failing
+		if err := o.ecsClient.CheckRollout(ctx, req.ServiceName, result.TaskDefinitionArn); errors.Is(err, ErrDeploymentRolledBack) {
+			o.appendFailure(ctx, proj, dep, "Deployment rolled back", err)
+			dep.UpdateStatus(deployment.StatusFailed)
+			o.deploymentRepo.Save(ctx, dep)
+			return fmt.Errorf("deployment rolled back: %w", err)
+		}
+
+		if waitErr != nil {
+			dep.AppendLog(fmt.Sprintf("⚠️  Warning: Service may not be fully stable: %v", waitErr))
+			// Don't fail the deployment, just log the warning
+		} else {
+			dep.AppendLog("✅ Service is running and stable")
+		}
 		o.deploymentRepo.Save(ctx, dep)
-		return fmt.Errorf("failed to create ALB routing: %w", err)
 	}
 
-	dep.AppendLog("✅ ALB routing configured")
+	return nil
+}
+
+// deployWorker rolls out a project that receives no traffic. The deployment succeeds once the service's
+// tasks keep running, which the circuit breaker checks in place of load balancer health checks.
+func (o *DeploymentOrchestrator) deployWorker(ctx context.Context, proj *project.Project, dep *deployment.Deployment, req DeploymentRequest) error {
+	if err := o.rollOut(ctx, proj, dep, req); err != nil {
+		return err
+	}
+
+	if err := dep.UpdateStatus(deployment.StatusDeployed); err != nil {
+		return fmt.Errorf("failed to update deployment status: %w", err)
+	}
+	if err := o.deploymentRepo.Save(ctx, dep); err != nil {
+		return fmt.Errorf("failed to save deployment: %w", err)
+	}
+
+	dep.AppendLog("🎉 Worker deployed successfully!")
 	o.deploymentRepo.Save(ctx, dep)
 
-	// Prepare deployment request
-	deployReq := DeploymentRequest{
-		ServiceName:     serviceName,
-		ImageURI:        imageURI,
-		ProjectID:       proj.ID().String(),
-		CustomDomain:    proj.CustomDomain().String(),
-		CPU:             "256", // 0.25 vCPU
-		Memory:          "512", // 512 MB
-		DesiredCount:    1,
-		ContainerPort:   containerPort,
-		TargetGroupArn:  targetGroupArn,
-		SubnetIDs:       o.subnetIDs,
-		SecurityGroupID: o.securityGroupID,
-		EnvVars:         projectEnvVars,
+	slog.InfoContext(ctx, "ECS worker deployment completed", "project_id", proj.ID().String())
+	return nil
+}
+
+// removeRouting deletes the load balancer routing, blue/green application and DNS record of a project's
+// environment that no longer receives traffic
+func (o *DeploymentOrchestrator) removeRouting(ctx context.Context, proj *project.Project, serviceName string, domain project.CustomDomain) {
+	if err := o.albClient.DeleteTargetGroupAndRule(ctx, serviceName); err != nil {
+		slog.WarnContext(ctx, "Failed to delete ALB routing", "project_id", proj.ID().String(), "error", err)
+	}
+	if o.codeDeploy != nil {
+		if err := o.codeDeploy.DeleteApplication(ctx, serviceName); err != nil {
+			slog.WarnContext(ctx, "Failed to delete CodeDeploy application", "project_id", proj.ID().String(), "error", err)
+		}
+	}
+	if err := o.route53Client.DeleteRecord(ctx, domain.String(), "A"); err != nil && !errors.Is(err, route53.ErrRecordNotFound) {
+		slog.WarnContext(ctx, "Failed to delete DNS record", "project_id", proj.ID().String(), "error", err)
 	}
+}
 
-	// Deploy to ECS
-	if err := o.ecsClient.DeployService(ctx, deployReq); err != nil {
-		dep.AppendLog(fmt.Sprintf("❌ ECS deployment failed: %v", err))
+// RestartService forces a new deployment of a project's current task definition without a rebuild
+func (o *DeploymentOrchestrator) RestartService(ctx context.Context, proj *project.Project, dep *deployment.Deployment) (err error) {
+	ctx, span := tracing.Start(ctx, "ecs.restart", attribute.String("deployment.id", dep.ID().String()))
+	defer func() { tracing.End(span, err) }()
+
+	var unlock func()
+	ctx, unlock, err = o.lockProject(ctx, proj)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Throttled or failing AWS calls are retried, show the retries in the deployment logs
+	ctx = awsretry.WithReporter(ctx, func(message string) { dep.AppendLog("🔁 " + message) })
+
+	serviceName := environmentServiceName(proj.ID().String(), dep.Environment())
+
+	fail := func(step string, err error) error {
+		o.appendFailure(ctx, proj, dep, step, err)
 		dep.UpdateStatus(deployment.StatusFailed)
 		o.deploymentRepo.Save(ctx, dep)
-		// Clean up ALB resources
-		o.albClient.DeleteTargetGroupAndRule(ctx, serviceName)
-		return fmt.Errorf("failed to deploy to ECS: %w", err)
+		return fmt.Errorf("%s: %w", strings.ToLower(step), err)
 	}
 
-	dep.AppendLog("✅ ECS service created/updated successfully")
-	o.deploymentRepo.Save(ctx, dep)
-
-	// Wait for service to stabilize
-	dep.AppendLog("⏳ Waiting for service to become stable...")
-	o.deploymentRepo.Save(ctx, dep)
+	if proj.Type() == project.TypeStatic {
+		return fail("Restart failed", errors.New("static sites run no service, redeploy to publish the site again"))
+	}
+	if proj.DeploymentTarget() == project.TargetLambda {
+		return fail("Restart failed", errors.New("Lambda functions start on demand and run no service, redeploy to replace them"))
+	}
 
-	if err := o.ecsClient.WaitForServiceStable(ctx, serviceName, 5*time.Minute); err != nil {
-		dep.AppendLog(fmt.Sprintf("⚠️  Warning: Service may not be fully stable: %v", err))
-		// Don't fail the deployment, just log the warning
-	} else {
-		dep.AppendLog("✅ Service is running and stable")
+	service, err := o.ecsClient.getService(ctx, serviceName)
+	if err != nil {
+		return fail("Service not found", err)
 	}
-	o.deploymentRepo.Save(ctx, dep)
 
-	// Create/Update DNS record
-	dep.AppendLog(fmt.Sprintf("🌐 Configuring DNS for %s.%s...", proj.CustomDomain().String(), o.baseDomain))
+	dep.AppendLog(fmt.Sprintf("🔄 Restarting service: %s", serviceName))
 	o.deploymentRepo.Save(ctx, dep)
 
-	if err := o.route53Client.CreateOrUpdateRecord(ctx, route53.DNSRecordRequest{
-		Subdomain: proj.CustomDomain().String(),
-		Target:    o.albDNS,
-		Type:      "ALIAS",
-	}); err != nil {
-		dep.AppendLog(fmt.Sprintf("⚠️  Warning: DNS configuration failed: %v", err))
-		// Don't fail deployment if DNS fails
+	if isBlueGreen(service) {
+		// CodeDeploy owns the tasks of a blue/green service, so they are replaced with a deployment of the running version
+		if o.codeDeploy == nil {
+			return fail("Restart failed", errors.New("CodeDeploy is not configured on this platform"))
+		}
+		if len(service.LoadBalancers) == 0 {
+			return fail("Restart failed", errors.New("service has no load balancer"))
+		}
+
+		bgReq := o.blueGreenRequest(serviceName, primaryTaskDefinition(service), aws.ToInt32(service.LoadBalancers[0].ContainerPort))
+		if err := o.shiftTraffic(ctx, dep, bgReq); err != nil {
+			return fail("Service failed to restart", err)
+		}
 	} else {
-		deploymentURL := fmt.Sprintf("https://%s.%s", proj.CustomDomain().String(), o.baseDomain)
-		dep.AppendLog(fmt.Sprintf("✅ DNS configured successfully"))
-		dep.AppendLog(fmt.Sprintf("🌍 Your app is live at: %s", deploymentURL))
+		if err := o.ecsClient.RestartService(ctx, serviceName); err != nil {
+			return fail("Restart failed", err)
+		}
+
+		dep.AppendLog("⏳ Waiting for new tasks to become stable...")
+		o.deploymentRepo.Save(ctx, dep)
+
+		err := waitUnlocked(ctx, func() error {
+			return o.ecsClient.WaitForServiceStable(ctx, serviceName, 5*time.Minute, o.rolloutReporter(ctx, dep))
+		})
+		if err != nil {
+			return fail("Service failed to restart", err)
+		}
+	}
+
+	if err := o.restartServices(ctx, proj, dep, serviceName); err != nil {
+		return fail("Service failed to restart", err)
 	}
-	o.deploymentRepo.Save(ctx, dep)
 
-	// Mark deployment as successful
+	dep.AppendLog("✅ Service restarted successfully")
 	if err := dep.UpdateStatus(deployment.StatusDeployed); err != nil {
 		return fmt.Errorf("failed to update deployment status: %w", err)
 	}
@@ -310,11 +760,162 @@ func (o *DeploymentOrchestrator) DeployToECS(
 		return fmt.Errorf("failed to save deployment: %w", err)
 	}
 
-	dep.AppendLog("🎉 Deployment completed successfully!")
+	slog.InfoContext(ctx, "ECS restart completed", "project_id", proj.ID().String())
+	return nil
+}
+
+// blueGreenRequest describes a blue/green deployment of a task definition to a service
+func (o *DeploymentOrchestrator) blueGreenRequest(serviceName, taskDefArn string, containerPort int32) codedeploy.BlueGreenRequest {
+	return codedeploy.BlueGreenRequest{
+		ClusterName:          o.ecsClient.clusterName,
+		ServiceName:          serviceName,
+		TaskDefinitionArn:    taskDefArn,
+		ContainerName:        serviceName,
+		ContainerPort:        containerPort,
+		BlueTargetGroupName:  serviceName,
+		GreenTargetGroupName: alb.GreenTargetGroupName(serviceName),
+		ProdListenerArn:      o.albClient.ListenerARN(),
+		TestListenerArn:      o.albClient.TestListenerARN(),
+	}
+}
+
+// shiftTraffic deploys a task definition to a blue/green service through CodeDeploy, which starts a replacement
+// task set, moves traffic to it once it passes health checks and moves it back if the deployment fails
+func (o *DeploymentOrchestrator) shiftTraffic(ctx context.Context, dep *deployment.Deployment, req codedeploy.BlueGreenRequest) error {
+	deploymentID, err := o.codeDeploy.CreateDeployment(ctx, req, fmt.Sprintf("SnapDeploy deployment %s", dep.ID().String()))
+	if err != nil {
+		return err
+	}
+
+	dep.AppendLog(fmt.Sprintf("🔀 Started blue/green deployment %s", deploymentID))
+	if req.TestListenerArn != "" {
+		dep.AppendLog("🧪 The new version is reachable on the test listener until traffic shifts")
+	}
+	dep.AppendLog("⏳ Waiting for the new tasks to pass health checks before shifting traffic...")
 	o.deploymentRepo.Save(ctx, dep)
 
-	log.Printf("[ECS] Deployment completed successfully for project %s", proj.ID().String())
-	return nil
+	return waitUnlocked(ctx, func() error {
+		return o.codeDeploy.WaitForDeployment(ctx, deploymentID, 15*time.Minute)
+	})
+}
+
+// GetServiceMetrics returns runtime metrics of a project's ECS service and its load balancer target group
+func (o *DeploymentOrchestrator) GetServiceMetrics(ctx context.Context, proj *project.Project, start, end time.Time, step time.Duration) ([]cloudwatch.Series, error) {
+	if o.metricsClient == nil {
+		return nil, fmt.Errorf("CloudWatch client not initialized")
+	}
+
+	serviceName := generateServiceName(proj.ID().String())
+	dims := cloudwatch.ServiceDimensions{
+		ClusterName: o.clusterName,
+		ServiceName: serviceName,
+	}
+
+	// Request metrics are only available once the service is routed through the load balancer
+	targetGroupArn, err := o.albClient.FindTargetGroupARN(ctx, serviceName)
+	if err != nil {
+		slog.WarnContext(ctx, "Could not find target group", "service", serviceName, "error", err)
+	} else if targetGroupArn != "" {
+		dims.LoadBalancer = cloudwatch.LoadBalancerDimension(o.albClient.ListenerARN())
+		dims.TargetGroup = cloudwatch.TargetGroupDimension(targetGroupArn)
+	}
+
+	return o.metricsClient.GetServiceMetrics(ctx, dims, start, end, step)
+}
+
+// GetServiceEgress returns the bytes the service of a project's environment sent within the window
+func (o *DeploymentOrchestrator) GetServiceEgress(ctx context.Context, projectID project.ProjectID, env project.Environment, start, end time.Time) (float64, error) {
+	if o.metricsClient == nil {
+		return 0, fmt.Errorf("CloudWatch client not initialized")
+	}
+
+	serviceName := environmentServiceName(projectID.String(), env)
+	return o.metricsClient.GetServiceBytesSent(ctx, o.clusterName, serviceName, start, end)
+}
+
+// GetTimelineEvents returns the ECS service events and load balancer target health changes of the service of a
+// project's environment within the window. Whatever could be read is returned alongside the errors of the sources that failed.
+func (o *DeploymentOrchestrator) GetTimelineEvents(ctx context.Context, proj *project.Project, env project.Environment, start, end time.Time) ([]deployment.TimelineEntry, error) {
+	serviceName := environmentServiceName(proj.ID().String(), env)
+
+	var entries []deployment.TimelineEntry
+	var errs []error
+
+	serviceEvents, err := o.ecsClient.GetServiceEvents(ctx, serviceName, start, end)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to get ECS service events: %w", err))
+	}
+	for _, event := range serviceEvents {
+		entries = append(entries, deployment.TimelineEntry{
+			OccurredAt: event.CreatedAt,
+			Source:     deployment.TimelineSourceECS,
+			Type:       "service_event",
+			Message:    event.Message,
+		})
+	}
+
+	if o.metricsClient == nil {
+		return entries, errors.Join(errs...)
+	}
+
+	targetGroupArn, err := o.albClient.FindTargetGroupARN(ctx, serviceName)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to find target group: %w", err))
+	} else if targetGroupArn != "" {
+		health, err := o.metricsClient.GetTargetHealth(ctx,
+			cloudwatch.LoadBalancerDimension(o.albClient.ListenerARN()),
+			cloudwatch.TargetGroupDimension(targetGroupArn),
+			start, end,
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get target health: %w", err))
+		}
+
+		// Only report the minutes where the counts changed
+		for i, point := range health {
+			if i > 0 && point.Healthy == health[i-1].Healthy && point.Unhealthy == health[i-1].Unhealthy {
+				continue
+			}
+			entries = append(entries, deployment.TimelineEntry{
+				OccurredAt: point.Timestamp,
+				Source:     deployment.TimelineSourceLoadBalancer,
+				Type:       "target_health",
+				Message:    fmt.Sprintf("%d healthy, %d unhealthy targets", point.Healthy, point.Unhealthy),
+			})
+		}
+	}
+
+	return entries, errors.Join(errs...)
+}
+
+// appendFailure logs a deployment failure. AWS quota errors are translated into
+// actionable guidance for the user and raised to operators.
+func (o *DeploymentOrchestrator) appendFailure(ctx context.Context, proj *project.Project, dep *deployment.Deployment, step string, err error) {
+	quotaErr := quota.Classify(err)
+	if quotaErr == nil {
+		dep.AppendLog(fmt.Sprintf("❌ %s: %v", step, err))
+		return
+	}
+
+	dep.AppendLog(fmt.Sprintf("❌ %s: %s", step, quotaErr.Message))
+	dep.AppendLog(fmt.Sprintf("💡 %s", quotaErr.Remediation))
+
+	slog.WarnContext(ctx, "AWS quota reached", "limit", quotaErr.Limit, "project_id", proj.ID().String(), "error", quotaErr.Err)
+	if o.alerter != nil {
+		o.alerter.Alert(ctx,
+			"quota:"+string(quotaErr.Limit),
+			fmt.Sprintf("AWS quota reached: %s", quotaErr.Limit),
+			fmt.Sprintf("Deployment %s of project %s failed: %v\n%s", dep.ID().String(), proj.ID().String(), quotaErr.Err, quotaErr.OperatorAction),
+		)
+	}
+}
+
+// rolloutReporter returns a function logging what ECS says about a rollout to the deployment
+func (o *DeploymentOrchestrator) rolloutReporter(ctx context.Context, dep *deployment.Deployment) func(string) {
+	return func(message string) {
+		dep.AppendLog(fmt.Sprintf("📡 %s", message))
+		o.deploymentRepo.Save(ctx, dep)
+	}
 }
 
 // runMigration runs database migrations as a one-off ECS task
@@ -326,8 +927,9 @@ func (o *DeploymentOrchestrator) runMigration(
 	imageURI string,
 	migrationCommand string,
 	envVars map[string]string,
+	volume *VolumeMount,
 ) error {
-	log.Printf("[ECS] Running migration task for service %s", serviceName)
+	slog.InfoContext(ctx, "Running migration task", "service", serviceName)
 
 	// Parse migration command (e.g., "npm run migrate" -> ["npm", "run", "migrate"])
 	commandParts := strings.Fields(migrationCommand)
@@ -340,12 +942,13 @@ func (o *DeploymentOrchestrator) runMigration(
 	taskDefArn, err := o.ecsClient.createTaskDefinition(ctx, DeploymentRequest{
 		ServiceName:   serviceName,
 		ImageURI:      imageURI,
-		ProjectID:     serviceName, // Not used in task def
+		ProjectID:     dep.ProjectID().String(),
 		CustomDomain:  serviceName, // Not used in task def
 		CPU:           "256",
 		Memory:        "512",
 		ContainerPort: 8080, // Not used for migration task
 		EnvVars:       envVars,
+		Volume:        volume,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to register migration task definition: %w", err)
@@ -354,48 +957,230 @@ func (o *DeploymentOrchestrator) runMigration(
 	dep.AppendLog(fmt.Sprintf("📝 Registered migration task definition: %s", taskDefArn))
 	o.deploymentRepo.Save(ctx, dep)
 
-	// Run the migration task
+	// Run the migration task, its output goes to the deployment logs as it is written
 	err = o.taskRunner.RunTask(ctx, RunTaskRequest{
 		TaskDefinition: taskDefArn,
 		Command:        commandParts,
 		EnvVars:        envVars,
 		TaskName:       serviceName,
+		OnLogLines: func(lines []string) {
+			for _, line := range lines {
+				dep.AppendLog("   " + line)
+			}
+			o.deploymentRepo.Save(ctx, dep)
+		},
 	})
 
 	if err != nil {
+		// The failing line ends up in the deployment's error, masked like the logs
+		var failed *TaskFailedError
+		if errors.As(err, &failed) {
+			failed.Output = dep.RedactLog(failed.Output)
+		}
 		return fmt.Errorf("migration task failed: %w", err)
 	}
 
 	return nil
 }
 
-// StopDeployment stops a running deployment
-func (o *DeploymentOrchestrator) StopDeployment(ctx context.Context, proj *project.Project) error {
-	serviceName := generateServiceName(proj.ID().String())
+// StopDeployment stops the running deployment of a project's environment
+func (o *DeploymentOrchestrator) StopDeployment(ctx context.Context, proj *project.Project, env project.Environment) error {
+	ctx, unlock, err := o.lockProject(ctx, proj)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	serviceName := environmentServiceName(proj.ID().String(), env)
+
+	// Static sites stop being served, their distribution and files are kept until the project is deleted
+	if proj.Type() == project.TypeStatic {
+		if o.cdn == nil {
+			return fmt.Errorf("CloudFront client not initialized")
+		}
+		_, err := o.cdn.DisableDistribution(ctx, serviceName)
+		return err
+	}
+
+	// Functions stop taking requests, the next deployment resumes them
+	if proj.DeploymentTarget() == project.TargetLambda {
+		if o.functions == nil {
+			return fmt.Errorf("Lambda client not initialized")
+		}
+		return o.functions.StopFunction(ctx, serviceName)
+	}
+
+	if err := o.stopServices(ctx, serviceName); err != nil {
+		return fmt.Errorf("failed to stop services: %w", err)
+	}
+
+	// Cron jobs have no service, they stop once no more runs are scheduled
+	if proj.Type() == project.TypeCron {
+		if o.scheduler == nil {
+			return fmt.Errorf("EventBridge client not initialized")
+		}
+		return o.scheduler.DeleteSchedule(ctx, cronRuleName(serviceName))
+	}
+
 	return o.ecsClient.StopService(ctx, serviceName)
 }
 
-// DeleteDeployment removes a deployment completely
-func (o *DeploymentOrchestrator) DeleteDeployment(ctx context.Context, proj *project.Project) error {
-	serviceName := generateServiceName(proj.ID().String())
+// DeleteDeployment removes the deployment of a project's environment completely
+func (o *DeploymentOrchestrator) DeleteDeployment(ctx context.Context, proj *project.Project, env project.Environment) error {
+	ctx, unlock, err := o.lockProject(ctx, proj)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	serviceName := environmentServiceName(proj.ID().String(), env)
 
 	// Delete DNS record
-	if err := o.route53Client.DeleteRecord(ctx, proj.CustomDomain().String(), "A"); err != nil {
-		log.Printf("[ECS] Warning: failed to delete DNS record: %v", err)
+	if err := o.route53Client.DeleteRecord(ctx, proj.CustomDomain().ForEnvironment(env).String(), "A"); err != nil && !errors.Is(err, route53.ErrRecordNotFound) {
+		slog.WarnContext(ctx, "Failed to delete DNS record", "project_id", proj.ID().String(), "error", err)
 		// Continue with service deletion even if DNS deletion fails
 	}
 
-	// Delete ECS service
-	if err := o.ecsClient.DeleteService(ctx, serviceName); err != nil {
-		return fmt.Errorf("failed to delete ECS service: %w", err)
+	if err := o.removeContainers(ctx, proj, env, serviceName); err != nil {
+		return err
+	}
+
+	// Delete the logs of the environment's services and tasks, which would otherwise be kept with no project
+	if err := o.ecsClient.DeleteLogGroups(ctx, serviceName); err != nil {
+		return fmt.Errorf("failed to delete log groups: %w", err)
+	}
+
+	// Delete the distribution and files of static sites, or of projects that were one before
+	if o.cdn != nil {
+		if err := o.cdn.DeleteSite(ctx, serviceName); err != nil {
+			return fmt.Errorf("failed to delete static site: %w", err)
+		}
+	}
+
+	// Delete the function and API of LAMBDA projects, or of projects that ran on Lambda before
+	if o.functions != nil {
+		if _, err := o.functions.DeleteFunction(ctx, serviceName); err != nil {
+			return fmt.Errorf("failed to delete function: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// removeContainers removes the ECS services, load balancer routing, blue/green application and schedule of
+// a project's environment
+func (o *DeploymentOrchestrator) removeContainers(ctx context.Context, proj *project.Project, env project.Environment, serviceName string) error {
+	// Delete ECS service (projects that were never deployed have no service)
+	if _, err := o.ecsClient.getService(ctx, serviceName); err == nil {
+		if err := o.ecsClient.DeleteService(ctx, serviceName); err != nil {
+			return fmt.Errorf("failed to delete ECS service: %w", err)
+		}
+	} else if !isServiceNotFoundError(err) {
+		return fmt.Errorf("failed to check service existence: %w", err)
+	}
+
+	// Delete the canary service of canary projects
+	canaryName := canaryServiceName(serviceName)
+	if _, err := o.ecsClient.getService(ctx, canaryName); err == nil {
+		if err := o.ecsClient.DeleteService(ctx, canaryName); err != nil {
+			return fmt.Errorf("failed to delete canary service: %w", err)
+		}
 	}
 
 	// Delete ALB target group and listener rule
 	if err := o.albClient.DeleteTargetGroupAndRule(ctx, serviceName); err != nil {
-		log.Printf("[ECS] Warning: failed to delete ALB routing: %v", err)
+		slog.WarnContext(ctx, "Failed to delete ALB routing", "project_id", proj.ID().String(), "error", err)
 		// Continue even if ALB cleanup fails
 	}
 
+	// Delete the CodeDeploy application of blue/green projects
+	if o.codeDeploy != nil {
+		if err := o.codeDeploy.DeleteApplication(ctx, serviceName); err != nil {
+			slog.WarnContext(ctx, "Failed to delete CodeDeploy application", "project_id", proj.ID().String(), "error", err)
+		}
+	}
+
+	// Delete the schedule of cron jobs
+	o.removeSchedule(ctx, proj, serviceName)
+
+	// Delete the project's other services
+	if err := o.removeServices(ctx, proj, env, serviceName, nil); err != nil {
+		return fmt.Errorf("failed to delete services: %w", err)
+	}
+
+	return nil
+}
+
+// TeardownEnvironment removes the ECS services, schedules, load balancer routing, static site and DNS records
+// of an environment removed from a project. The database, datastores and volume are shared with the project's
+// other environments and are kept.
+func (o *DeploymentOrchestrator) TeardownEnvironment(ctx context.Context, proj *project.Project, env project.Environment) error {
+	slog.InfoContext(ctx, "Tearing down environment resources", "project_id", proj.ID().String(), "environment", env.String())
+	return o.DeleteDeployment(ctx, proj, env)
+}
+
+// TeardownProject removes every cloud resource provisioned for a project.
+// Progress messages are passed to report as each step starts.
+func (o *DeploymentOrchestrator) TeardownProject(ctx context.Context, proj *project.Project, report func(message string)) error {
+	ctx, unlock, err := o.lockProject(ctx, proj)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	slog.InfoContext(ctx, "Tearing down project resources", "project_id", proj.ID().String())
+
+	report("Removing ECS services, schedules, load balancer routing, static sites and DNS records...")
+	for _, env := range proj.Environments() {
+		if err := o.DeleteDeployment(ctx, proj, env); err != nil {
+			return err
+		}
+	}
+
+	if o.ecrClient != nil {
+		report("Deleting container images...")
+		if err := o.ecrClient.DeleteProjectImages(ctx, proj.ID().String()); err != nil {
+			return fmt.Errorf("failed to delete container images: %w", err)
+		}
+	}
+
+	// Databases outlive deployments, so one may be left from before the project stopped requiring it
+	if o.dbManager != nil {
+		report("Dropping project database, its backups, snapshots and branches...")
+		dbName := database.GetDatabaseName(proj.ID().String())
+		if err := o.dbManager.DropDatabase(ctx, dbName); err != nil {
+			return fmt.Errorf("failed to drop database: %w", err)
+		}
+		if err := o.dbManager.DropCopies(ctx, dbName); err != nil {
+			return fmt.Errorf("failed to drop database copies: %w", err)
+		}
+	}
+
+	if len(o.datastores) > 0 {
+		report("Dropping project datastores...")
+		for datastore, provisioner := range o.datastores {
+			if err := provisioner.Deprovision(ctx, proj.ID().String()); err != nil {
+				return fmt.Errorf("failed to drop %s datastore: %w", datastore, err)
+			}
+		}
+	}
+
+	if o.efsClient != nil {
+		report("Deleting persistent volume...")
+		if err := o.efsClient.DeleteProjectVolume(ctx, proj.ID().String()); err != nil {
+			return fmt.Errorf("failed to delete persistent volume: %w", err)
+		}
+	}
+
+	// Projects whose tasks ran with the shared role have none to delete
+	if o.roles != nil {
+		report("Deleting task role...")
+		if err := o.roles.DeleteProjectRole(ctx, proj.ID().String()); err != nil {
+			return fmt.Errorf("failed to delete task role: %w", err)
+		}
+	}
+
+	slog.InfoContext(ctx, "Project teardown completed", "project_id", proj.ID().String())
 	return nil
 }
 
@@ -410,6 +1195,17 @@ func generateServiceName(projectID string) string {
 	return fmt.Sprintf("snapdeploy-%s", shortID)
 }
 
+// environmentServiceName returns the service name of a project's environment. Production runs under the
+// project's service name, other environments under a hash of the project and environment, which keeps their
+// names as short and never extends the name of another environment's service.
+func environmentServiceName(projectID string, env project.Environment) string {
+	if env.IsProduction() {
+		return generateServiceName(projectID)
+	}
+	sum := sha256.Sum256([]byte(projectID + ":" + env.String()))
+	return fmt.Sprintf("snapdeploy-%s", hex.EncodeToString(sum[:])[:8])
+}
+
 // parsePort parses a port string to int32
 func parsePort(portStr string) (int32, error) {
 	var port int
@@ -0,0 +1,367 @@
+package ecs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// ECS Exec sessions are Session Manager data channels: a WebSocket carrying binary agent messages,
+// the protocol the session-manager-plugin speaks. Only what an interactive shell needs is implemented.
+
+const (
+	agentHeaderLength  = 116 // Bytes before the payload length
+	agentPayloadOffset = agentHeaderLength + 4
+
+	messageInputStream  = "input_stream_data"
+	messageOutputStream = "output_stream_data"
+	messageAcknowledge  = "acknowledge"
+	messageChannelClose = "channel_closed"
+
+	payloadOutput            = 1
+	payloadSize              = 3
+	payloadHandshakeRequest  = 5
+	payloadHandshakeResponse = 6
+	payloadStdErr            = 11
+
+	execClientVersion = "1.2.0.0"
+	execInputChunk    = 1024             // Largest input sent in one message, as the plugin does
+	execPingInterval  = 5 * time.Minute  // Keeps the data channel open while nothing is typed
+	maxOutOfOrder     = 1000             // Output messages buffered ahead of a missing one
+	execDialTimeout   = 30 * time.Second // How long opening the data channel may take
+)
+
+// agentMessage is a message of a Session Manager data channel
+type agentMessage struct {
+	messageType    string
+	sequenceNumber int64
+	flags          uint64
+	messageID      uuid.UUID
+	payloadType    uint32
+	payload        []byte
+}
+
+// marshal encodes the message as it is sent on the data channel
+func (m *agentMessage) marshal() []byte {
+	buf := make([]byte, agentPayloadOffset+len(m.payload))
+	binary.BigEndian.PutUint32(buf[0:], agentHeaderLength)
+	copy(buf[4:36], fmt.Sprintf("%-32s", m.messageType))
+	binary.BigEndian.PutUint32(buf[36:], 1) // Schema version
+	binary.BigEndian.PutUint64(buf[40:], uint64(time.Now().UnixMilli()))
+	binary.BigEndian.PutUint64(buf[48:], uint64(m.sequenceNumber))
+	binary.BigEndian.PutUint64(buf[56:], m.flags)
+	// Message IDs are sent with their least significant half first
+	copy(buf[64:72], m.messageID[8:])
+	copy(buf[72:80], m.messageID[:8])
+	digest := sha256.Sum256(m.payload)
+	copy(buf[80:112], digest[:])
+	binary.BigEndian.PutUint32(buf[112:], m.payloadType)
+	binary.BigEndian.PutUint32(buf[116:], uint32(len(m.payload)))
+	copy(buf[agentPayloadOffset:], m.payload)
+	return buf
+}
+
+// parseAgentMessage decodes a message received on the data channel
+func parseAgentMessage(data []byte) (*agentMessage, error) {
+	if len(data) < agentPayloadOffset {
+		return nil, fmt.Errorf("agent message too short: %d bytes", len(data))
+	}
+	headerLength := int(binary.BigEndian.Uint32(data[0:]))
+	if headerLength < agentHeaderLength || headerLength+4 > len(data) {
+		return nil, fmt.Errorf("invalid agent message header length: %d", headerLength)
+	}
+	payloadLength := int(binary.BigEndian.Uint32(data[headerLength:]))
+	start := headerLength + 4
+	if start+payloadLength > len(data) {
+		return nil, fmt.Errorf("agent message payload of %d bytes exceeds the message", payloadLength)
+	}
+
+	var messageID uuid.UUID
+	copy(messageID[8:], data[64:72])
+	copy(messageID[:8], data[72:80])
+
+	return &agentMessage{
+		messageType:    strings.TrimRight(string(data[4:36]), " \x00"),
+		sequenceNumber: int64(binary.BigEndian.Uint64(data[48:])),
+		flags:          binary.BigEndian.Uint64(data[56:]),
+		messageID:      messageID,
+		payloadType:    binary.BigEndian.Uint32(data[112:]),
+		payload:        data[start : start+payloadLength],
+	}, nil
+}
+
+// execSession is an interactive shell opened with ECS Exec. Reads return what the shell writes, in
+// order and once each, writes are typed into it.
+type execSession struct {
+	ws *websocket.Conn
+
+	writeMu  sync.Mutex // The data channel allows one writer at a time
+	inputSeq int64
+
+	output    *io.PipeReader
+	outputW   *io.PipeWriter
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// dialExecSession connects to the data channel of an ECS Exec session and opens it with the session's token
+func dialExecSession(ctx context.Context, session *types.Session) (*execSession, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, execDialTimeout)
+	defer cancel()
+
+	ws, _, err := websocket.DefaultDialer.DialContext(dialCtx, aws.ToString(session.StreamUrl), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to exec session: %w", err)
+	}
+
+	open, err := json.Marshal(map[string]string{
+		"MessageSchemaVersion": "1.0",
+		"RequestId":            uuid.NewString(),
+		"TokenValue":           aws.ToString(session.TokenValue),
+		"ClientId":             uuid.NewString(),
+		"ClientVersion":        execClientVersion,
+	})
+	if err != nil {
+		ws.Close()
+		return nil, err
+	}
+	if err := ws.WriteMessage(websocket.TextMessage, open); err != nil {
+		ws.Close()
+		return nil, fmt.Errorf("failed to open exec session: %w", err)
+	}
+
+	output, outputW := io.Pipe()
+	s := &execSession{
+		ws:      ws,
+		output:  output,
+		outputW: outputW,
+		done:    make(chan struct{}),
+	}
+	go s.readLoop()
+	go s.keepAlive()
+	return s, nil
+}
+
+// Read returns what the shell writes, io.EOF once it exits
+func (s *execSession) Read(p []byte) (int, error) {
+	return s.output.Read(p)
+}
+
+// Write types input into the shell
+func (s *execSession) Write(p []byte) (int, error) {
+	for sent := 0; sent < len(p); {
+		end := min(sent+execInputChunk, len(p))
+		if err := s.sendInput(payloadOutput, p[sent:end]); err != nil {
+			return sent, err
+		}
+		sent = end
+	}
+	return len(p), nil
+}
+
+// Resize sets the size of the shell's terminal
+func (s *execSession) Resize(cols, rows int) error {
+	size, err := json.Marshal(map[string]int{"cols": cols, "rows": rows})
+	if err != nil {
+		return err
+	}
+	return s.sendInput(payloadSize, size)
+}
+
+// Close ends the session, closing the data channel
+func (s *execSession) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.writeMu.Lock()
+		_ = s.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		s.writeMu.Unlock()
+		err = s.ws.Close()
+		s.output.Close()
+	})
+	return err
+}
+
+// sendInput sends the next input message of the session
+func (s *execSession) sendInput(payloadType uint32, payload []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	msg := &agentMessage{
+		messageType:    messageInputStream,
+		sequenceNumber: s.inputSeq,
+		messageID:      uuid.New(),
+		payloadType:    payloadType,
+		payload:        payload,
+	}
+	if err := s.ws.WriteMessage(websocket.BinaryMessage, msg.marshal()); err != nil {
+		return fmt.Errorf("failed to send to exec session: %w", err)
+	}
+	s.inputSeq++
+	return nil
+}
+
+// acknowledge tells the agent a message arrived, so it isn't sent again
+func (s *execSession) acknowledge(msg *agentMessage) error {
+	ack, err := json.Marshal(map[string]any{
+		"AcknowledgedMessageType":           msg.messageType,
+		"AcknowledgedMessageId":             msg.messageID.String(),
+		"AcknowledgedMessageSequenceNumber": msg.sequenceNumber,
+		"IsSequentialMessage":               true,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	reply := &agentMessage{
+		messageType: messageAcknowledge,
+		flags:       3,
+		messageID:   uuid.New(),
+		payload:     ack,
+	}
+	return s.ws.WriteMessage(websocket.BinaryMessage, reply.marshal())
+}
+
+// readLoop passes the shell's output on in sequence order until the data channel closes. The agent
+// resends messages it has no acknowledgement for, so duplicates are dropped and messages arriving ahead
+// of a missing one wait for it.
+func (s *execSession) readLoop() {
+	var expected int64
+	pending := make(map[int64]*agentMessage)
+
+	for {
+		_, data, err := s.ws.ReadMessage()
+		if err != nil {
+			select {
+			case <-s.done:
+				s.outputW.Close()
+			default:
+				s.outputW.CloseWithError(fmt.Errorf("exec session connection lost: %w", err))
+			}
+			return
+		}
+
+		msg, err := parseAgentMessage(data)
+		if err != nil {
+			slog.Warn("Dropping malformed exec session message", "error", err)
+			continue
+		}
+
+		switch msg.messageType {
+		case messageChannelClose:
+			s.outputW.Close()
+			return
+		case messageOutputStream:
+			if msg.sequenceNumber >= expected+maxOutOfOrder {
+				continue // Not acknowledged, so it is sent again once the missing messages arrived
+			}
+			if err := s.acknowledge(msg); err != nil {
+				s.outputW.CloseWithError(err)
+				return
+			}
+			if msg.sequenceNumber < expected {
+				continue
+			}
+			pending[msg.sequenceNumber] = msg
+			for next, ok := pending[expected]; ok; next, ok = pending[expected] {
+				delete(pending, expected)
+				expected++
+				if err := s.handleOutput(next); err != nil {
+					s.outputW.CloseWithError(err)
+					return
+				}
+			}
+		}
+	}
+}
+
+// handleOutput passes on what the shell wrote and answers the agent's handshake
+func (s *execSession) handleOutput(msg *agentMessage) error {
+	switch msg.payloadType {
+	case payloadOutput, payloadStdErr:
+		_, err := s.outputW.Write(msg.payload)
+		return err
+	case payloadHandshakeRequest:
+		return s.completeHandshake(msg.payload)
+	default:
+		return nil
+	}
+}
+
+// completeHandshake accepts the session type the agent asks for and declines anything else, such as
+// encrypting the session with KMS, which the plugin would handle
+func (s *execSession) completeHandshake(payload []byte) error {
+	var request struct {
+		RequestedClientActions []struct {
+			ActionType string
+		}
+	}
+	if err := json.Unmarshal(payload, &request); err != nil {
+		return fmt.Errorf("invalid exec session handshake: %w", err)
+	}
+
+	type processedAction struct {
+		ActionType   string
+		ActionStatus int
+		ActionResult any
+		Error        string
+	}
+	var processed []processedAction
+	for _, action := range request.RequestedClientActions {
+		if action.ActionType == "SessionType" {
+			processed = append(processed, processedAction{ActionType: action.ActionType, ActionStatus: 1})
+			continue
+		}
+		processed = append(processed, processedAction{
+			ActionType:   action.ActionType,
+			ActionStatus: 3, // Unsupported
+			Error:        fmt.Sprintf("%s is not supported", action.ActionType),
+		})
+	}
+
+	response, err := json.Marshal(map[string]any{
+		"ClientVersion":          execClientVersion,
+		"ProcessedClientActions": processed,
+		"Errors":                 []string{},
+	})
+	if err != nil {
+		return err
+	}
+	return s.sendInput(payloadHandshakeResponse, response)
+}
+
+// keepAlive pings the data channel so it isn't closed as idle while the user reads
+func (s *execSession) keepAlive() {
+	ticker := time.NewTicker(execPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.writeMu.Lock()
+			err := s.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second))
+			s.writeMu.Unlock()
+			if err != nil && !errors.Is(err, websocket.ErrCloseSent) {
+				slog.Warn("Failed to ping exec session", "error", err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package ecs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/infrastructure/cloudwatch"
+	"snapdeploy-core/internal/infrastructure/lambda"
+	"snapdeploy-core/internal/infrastructure/route53"
+)
+
+// deployLambda deploys a LAMBDA project: its image is published as a new version of the environment's function
+// and the function's live alias is moved to it, at once or, for CANARY projects, once a share of the requests
+// was served by the new version for the bake time. Requests reach the alias through an HTTP API on the
+// project's subdomain or through the function's URL. The Lambda Web Adapter in the image passes them to the
+// app's server.
+func (o *DeploymentOrchestrator) deployLambda(ctx context.Context, proj *project.Project, dep *deployment.Deployment, imageURI string, steps *stepTracker) error {
+	fail := func(step string, err error) error {
+		o.appendFailure(ctx, proj, dep, step, err)
+		dep.UpdateStatus(deployment.StatusFailed)
+		o.deploymentRepo.Save(ctx, dep)
+		return fmt.Errorf("%s: %w", strings.ToLower(step), err)
+	}
+
+	if err := dep.UpdateStatus(deployment.StatusDeploying); err != nil {
+		return fmt.Errorf("failed to update deployment status: %w", err)
+	}
+	if err := o.deploymentRepo.Save(ctx, dep); err != nil {
+		return fmt.Errorf("failed to save deployment: %w", err)
+	}
+
+	if o.functions == nil {
+		return fail("Lambda deployments unavailable", errors.New("Lambda is not configured on this platform"))
+	}
+
+	serviceName := environmentServiceName(proj.ID().String(), dep.Environment())
+	domain := proj.CustomDomain().ForEnvironment(dep.Environment())
+	fullDomain := fmt.Sprintf("%s.%s", domain.String(), o.baseDomain)
+
+	dep.AppendLog("🚀 Starting Lambda deployment...")
+	if !dep.Environment().IsProduction() {
+		dep.AppendLog(fmt.Sprintf("🌱 Environment: %s", dep.Environment()))
+	}
+	dep.AppendLog(fmt.Sprintf("λ Deploying function: %s", serviceName))
+	dep.AppendLog(fmt.Sprintf("🖼️  Image: %s", imageURI))
+	o.deploymentRepo.Save(ctx, dep)
+
+	runtime, err := o.prepareRuntime(ctx, proj, dep, serviceName, imageURI, steps)
+	if err != nil {
+		return err
+	}
+
+	// The adapter forwards requests to the port the app listens on once its health check passes
+	envVars := runtime.envVars
+	port := proj.Port()
+	if portStr, ok := envVars["PORT"]; ok {
+		if custom, err := parsePort(portStr); err == nil {
+			port = int(custom)
+		}
+	}
+	envVars["AWS_LWA_PORT"] = strconv.Itoa(port)
+	envVars["AWS_LWA_READINESS_CHECK_PATH"] = proj.HealthCheckPath()
+
+	memory := proj.Memory()
+	if memory > lambda.MaxMemory {
+		memory = lambda.MaxMemory
+		dep.AppendLog(fmt.Sprintf("⚠️  Warning: Lambda functions have at most %d MiB of memory, using that", lambda.MaxMemory))
+	}
+
+	req := lambda.FunctionRequest{
+		Name:      serviceName,
+		ImageURI:  imageURI,
+		ProjectID: proj.ID().String(),
+		MemoryMB:  int32(memory),
+		EnvVars:   envVars,
+	}
+	// Only functions that reach a database run in the VPC, which adds to their cold starts
+	if proj.RequireDB() || proj.UsesDatastore(project.DatastoreMySQL) {
+		req.SubnetIDs = o.subnetIDs
+		req.SecurityGroupID = o.securityGroupID
+	}
+
+	dep.AppendLog("📦 Publishing function version...")
+	o.deploymentRepo.Save(ctx, dep)
+
+	version, err := o.functions.PublishFunction(ctx, req)
+	if err != nil {
+		return fail("Failed to publish function", err)
+	}
+	dep.AppendLog(fmt.Sprintf("✅ Published version %s", version))
+	o.deploymentRepo.Save(ctx, dep)
+
+	// A canary is compared against the version being served, so the first deployment goes out without one
+	previous, err := o.functions.LiveVersion(ctx, serviceName)
+	if err != nil {
+		return fail("Failed to get the live version", err)
+	}
+	if proj.DeploymentStrategy() == project.StrategyCanary && previous != "" && previous != version {
+		if err := o.bakeFunctionCanary(ctx, proj, dep, serviceName, previous, version); err != nil {
+			return fail("Canary rolled back, requests stay on the previous version", err)
+		}
+	}
+
+	if err := o.functions.RouteAlias(ctx, serviceName, version, "", 0); err != nil {
+		return fail("Failed to route requests to the new version", err)
+	}
+	dep.AppendLog(fmt.Sprintf("✅ Version %s is live", version))
+	o.deploymentRepo.Save(ctx, dep)
+
+	if proj.LambdaEndpoint() == project.EndpointFunctionURL {
+		url, err := o.functions.EnsureFunctionURL(ctx, serviceName)
+		if err != nil {
+			return fail("Failed to configure the function URL", err)
+		}
+
+		// A function served on the project's subdomain before no longer is
+		if err := o.functions.RemoveAPI(ctx, serviceName); err != nil {
+			slog.WarnContext(ctx, "Failed to remove API of function", "project_id", proj.ID().String(), "error", err)
+		}
+		if err := o.route53Client.DeleteRecord(ctx, domain.String(), "A"); err != nil && !errors.Is(err, route53.ErrRecordNotFound) {
+			slog.WarnContext(ctx, "Failed to delete DNS record", "project_id", proj.ID().String(), "error", err)
+		}
+
+		dep.AppendLog(fmt.Sprintf("🌍 Your app is live at: %s", url))
+	} else {
+		dep.AppendLog("🔧 Configuring API Gateway...")
+		o.deploymentRepo.Save(ctx, dep)
+
+		api, err := o.functions.EnsureAPI(ctx, serviceName, fullDomain)
+		if err != nil {
+			return fail("Failed to configure API Gateway", err)
+		}
+		if err := o.functions.RemoveFunctionURL(ctx, serviceName); err != nil {
+			slog.WarnContext(ctx, "Failed to remove function URL", "project_id", proj.ID().String(), "error", err)
+		}
+		dep.AppendLog("✅ API Gateway configured")
+
+		dep.AppendLog(fmt.Sprintf("🌐 Configuring DNS for %s...", fullDomain))
+		o.deploymentRepo.Save(ctx, dep)
+
+		if err := o.route53Client.CreateOrUpdateRecord(ctx, route53.DNSRecordRequest{
+			Subdomain:    domain.String(),
+			Target:       api.DomainName,
+			Type:         "ALIAS",
+			HostedZoneID: api.HostedZoneID,
+		}); err != nil {
+			dep.AppendLog(fmt.Sprintf("⚠️  Warning: DNS configuration failed: %v", err))
+			// Don't fail deployment if DNS fails
+		} else {
+			dep.AppendLog("✅ DNS configured successfully")
+			dep.AppendLog(fmt.Sprintf("🌍 Your app is live at: https://%s", fullDomain))
+		}
+	}
+	o.deploymentRepo.Save(ctx, dep)
+
+	// A project that ran containers or was a static site before has them removed once the function serves it
+	if _, err := o.ecsClient.getService(ctx, serviceName); err == nil {
+		dep.AppendLog("♻️  Removing the existing service, the function serves the project now")
+		o.deploymentRepo.Save(ctx, dep)
+		if err := o.removeContainers(ctx, proj, dep.Environment(), serviceName); err != nil {
+			slog.WarnContext(ctx, "Failed to remove containers of function", "project_id", proj.ID().String(), "error", err)
+		}
+	} else if !isServiceNotFoundError(err) {
+		slog.WarnContext(ctx, "Failed to check service of function", "project_id", proj.ID().String(), "error", err)
+	}
+	o.retireStaticSite(ctx, proj, serviceName)
+
+	if err := dep.UpdateStatus(deployment.StatusDeployed); err != nil {
+		return fmt.Errorf("failed to update deployment status: %w", err)
+	}
+	if err := o.deploymentRepo.Save(ctx, dep); err != nil {
+		return fmt.Errorf("failed to save deployment: %w", err)
+	}
+
+	dep.AppendLog("🎉 Function deployed successfully!")
+	o.deploymentRepo.Save(ctx, dep)
+
+	slog.InfoContext(ctx, "Lambda deployment completed", "project_id", proj.ID().String())
+	return nil
+}
+
+// bakeFunctionCanary sends the project's share of requests to a new version of a function through its alias
+// and watches the version's errors and duration for the bake time. Requests go back to the previous version
+// if the canary fails.
+func (o *DeploymentOrchestrator) bakeFunctionCanary(ctx context.Context, proj *project.Project, dep *deployment.Deployment, name, previous, version string) error {
+	percent := proj.CanaryPercent()
+	bake := time.Duration(proj.CanaryBakeMinutes()) * time.Minute
+
+	if err := o.functions.RouteAlias(ctx, name, previous, version, float64(percent)/100); err != nil {
+		return err
+	}
+
+	dep.AppendLog(fmt.Sprintf("🔀 Sending %d%% of requests to version %s for %s", percent, version, bake))
+	o.deploymentRepo.Save(ctx, dep)
+
+	canaryTraffic := func(start, end time.Time) (*cloudwatch.Traffic, error) {
+		return o.metricsClient.GetFunctionTraffic(ctx, name, lambda.AliasName, version, start, end)
+	}
+	if err := waitUnlocked(ctx, func() error { return o.bakeCanary(ctx, dep, canaryTraffic, bake) }); err != nil {
+		if err := o.functions.RouteAlias(ctx, name, previous, "", 0); err != nil {
+			slog.ErrorContext(ctx, "Failed to route requests back to the previous version", "function", name, "error", err)
+		}
+		return err
+	}
+
+	dep.AppendLog("✅ Canary stayed healthy, promoting the new version")
+	o.deploymentRepo.Save(ctx, dep)
+	return nil
+}
+
+// retireFunction deletes the function and API of a project's environment that ran on Lambda before it ran
+// containers. Returns whether there was a function.
+func (o *DeploymentOrchestrator) retireFunction(ctx context.Context, proj *project.Project, serviceName string) bool {
+	if o.functions == nil {
+		return false
+	}
+	found, err := o.functions.DeleteFunction(ctx, serviceName)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to delete function of former Lambda project", "project_id", proj.ID().String(), "error", err)
+	}
+	return found
+}
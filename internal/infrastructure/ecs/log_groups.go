@@ -0,0 +1,98 @@
+package ecs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	logstypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// serviceLogGroup returns the CloudWatch log group the tasks of a service, or of a one-off task family, log to
+func serviceLogGroup(serviceName string) string {
+	return "/ecs/" + serviceName
+}
+
+// SetLogRetention sets how many days the logs of services are kept, 0 to keep them forever. It must be one of
+// the retention periods CloudWatch Logs accepts.
+func (c *ECSClient) SetLogRetention(days int32) {
+	c.logRetentionDays = days
+}
+
+// ensureLogGroup creates a log group tagged with its project if it doesn't exist, and sets its retention.
+// Existing groups get the retention too, so groups created before it was set stop keeping logs forever.
+func (c *ECSClient) ensureLogGroup(ctx context.Context, logGroupName, projectID string) error {
+	if _, ok := c.ensuredLogGroups.Load(logGroupName); ok {
+		return nil
+	}
+
+	_, err := c.logs.CreateLogGroup(ctx, &cloudwatchlogs.CreateLogGroupInput{
+		LogGroupName: aws.String(logGroupName),
+		Tags: map[string]string{
+			"snapdeploy:project-id": projectID,
+			"snapdeploy:managed-by": "snapdeploy-core",
+		},
+	})
+	var exists *logstypes.ResourceAlreadyExistsException
+	switch {
+	case errors.As(err, &exists):
+	case err != nil:
+		return fmt.Errorf("failed to create log group %s: %w", logGroupName, err)
+	default:
+		slog.InfoContext(ctx, "Created CloudWatch log group", "log_group", logGroupName)
+	}
+
+	if c.logRetentionDays > 0 {
+		_, err := c.logs.PutRetentionPolicy(ctx, &cloudwatchlogs.PutRetentionPolicyInput{
+			LogGroupName:    aws.String(logGroupName),
+			RetentionInDays: aws.Int32(c.logRetentionDays),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set retention of log group %s: %w", logGroupName, err)
+		}
+	}
+
+	c.ensuredLogGroups.Store(logGroupName, struct{}{})
+	return nil
+}
+
+// DeleteLogGroups deletes the log groups of a service and of everything named after it: its other services,
+// canary and one-off tasks
+func (c *ECSClient) DeleteLogGroups(ctx context.Context, serviceName string) error {
+	prefix := serviceLogGroup(serviceName)
+
+	var names []string
+	input := &cloudwatchlogs.DescribeLogGroupsInput{LogGroupNamePrefix: aws.String(prefix)}
+	for {
+		page, err := c.logs.DescribeLogGroups(ctx, input)
+		if err != nil {
+			return fmt.Errorf("failed to list log groups: %w", err)
+		}
+		for _, group := range page.LogGroups {
+			name := aws.ToString(group.LogGroupName)
+			// The prefix also matches services whose names merely start with this one's
+			if name == prefix || strings.HasPrefix(name, prefix+"-") {
+				names = append(names, name)
+			}
+		}
+		if aws.ToString(page.NextToken) == "" {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	for _, name := range names {
+		_, err := c.logs.DeleteLogGroup(ctx, &cloudwatchlogs.DeleteLogGroupInput{LogGroupName: aws.String(name)})
+		var notFound *logstypes.ResourceNotFoundException
+		if err != nil && !errors.As(err, &notFound) {
+			return fmt.Errorf("failed to delete log group %s: %w", name, err)
+		}
+		c.ensuredLogGroups.Delete(name)
+		slog.InfoContext(ctx, "Deleted CloudWatch log group", "log_group", name)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/infrastructure/quota"
+	"snapdeploy-core/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// stageRetryDelay is how long a stage that failed waits before its next attempt, times the attempts so far.
+// Tests shorten it.
+var stageRetryDelay = 5 * time.Second
+
+// stage is a state of a deployment's pipeline. A deployment goes through its stages in order and is saved
+// after each one; when a stage fails, the stages that completed undo what they did, latest first.
+type stage struct {
+	name string          // What the stage does, for traces and retry logs
+	step deployment.Step // Recorded pipeline step the stage is part of, empty for stages that aren't recorded
+
+	// failure is logged with the error when the stage fails, failing the deployment, and prefixes the error
+	// returned. Stages leaving it empty record their own failures.
+	failure string
+	// optional stages log a warning when they fail and the deployment goes on
+	optional bool
+	// attempts is how many times the stage is tried, more than once only for stages safe to repeat.
+	// Quota errors are never retried.
+	attempts int
+	// locked stages change the project's load balancer routing, DNS records or services, they run and are
+	// undone holding the project lock
+	locked bool
+
+	run        func(ctx context.Context) error
+	compensate func(ctx context.Context) // Undoes the stage when a later one fails, optional
+}
+
+// pipeline runs the stages of a deployment, recording the steps they are part of
+type pipeline struct {
+	o     *DeploymentOrchestrator
+	proj  *project.Project
+	dep   *deployment.Deployment
+	steps *stepTracker
+
+	completed []stage
+}
+
+// newPipeline creates the pipeline of a deployment
+func (o *DeploymentOrchestrator) newPipeline(proj *project.Project, dep *deployment.Deployment, steps *stepTracker) *pipeline {
+	return &pipeline{o: o, proj: proj, dep: dep, steps: steps}
+}
+
+// run goes through the stages in order. A recorded step starts with its first stage and finishes with its last.
+func (p *pipeline) run(ctx context.Context, stages []stage) error {
+	for i, s := range stages {
+		if s.step != "" {
+			if _, running := p.steps.running[s.step]; !running {
+				p.steps.start(ctx, s.step)
+			}
+		}
+
+		err := p.attempt(ctx, s)
+		if s.step != "" && (err != nil || !stepContinues(stages[i+1:], s.step)) {
+			p.steps.finish(ctx, s.step, err)
+		}
+
+		switch {
+		case err == nil:
+			p.completed = append(p.completed, s)
+		case s.optional:
+			p.dep.AppendLog(fmt.Sprintf("⚠️  Warning: %s: %v", s.failure, err))
+		default:
+			p.fail(ctx, s, err)
+			if s.failure != "" {
+				return fmt.Errorf("%s: %w", strings.ToLower(s.failure), err)
+			}
+			return err
+		}
+		p.o.deploymentRepo.Save(ctx, p.dep)
+	}
+	return nil
+}
+
+// attempt runs a stage, trying it again after a pause while it has attempts left
+func (p *pipeline) attempt(ctx context.Context, s stage) (err error) {
+	ctx, span := tracing.Start(ctx, "ecs.stage", attribute.String("stage", s.name))
+	defer func() { tracing.End(span, err) }()
+
+	attempts := max(s.attempts, 1)
+	for attempt := 1; ; attempt++ {
+		err = p.withLock(ctx, s, s.run)
+		if err == nil || attempt >= attempts || quota.Classify(err) != nil || ctx.Err() != nil {
+			return err
+		}
+
+		slog.WarnContext(ctx, "Deployment stage failed, retrying", "stage", s.name, "attempt", attempt, "error", err)
+		p.dep.AppendLog(fmt.Sprintf("🔁 %s failed, retrying (attempt %d of %d): %v", s.name, attempt+1, attempts, err))
+		p.o.deploymentRepo.Save(ctx, p.dep)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * stageRetryDelay):
+		}
+	}
+}
+
+// fail records a stage's failure on the deployment and undoes the stages that completed, latest first
+func (p *pipeline) fail(ctx context.Context, s stage, err error) {
+	if s.failure != "" {
+		p.o.appendFailure(ctx, p.proj, p.dep, s.failure, err)
+		p.dep.UpdateStatus(deployment.StatusFailed)
+	}
+
+	for i := len(p.completed) - 1; i >= 0; i-- {
+		if compensate := p.completed[i].compensate; compensate != nil {
+			err := p.withLock(ctx, p.completed[i], func(ctx context.Context) error {
+				compensate(ctx)
+				return nil
+			})
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to undo deployment stage", "stage", p.completed[i].name, "error", err)
+			}
+		}
+	}
+	p.completed = nil
+	p.o.deploymentRepo.Save(ctx, p.dep)
+}
+
+// withLock calls fn for a stage, holding the project lock if the stage is locked
+func (p *pipeline) withLock(ctx context.Context, s stage, fn func(ctx context.Context) error) error {
+	if !s.locked {
+		return fn(ctx)
+	}
+	ctx, unlock, err := p.o.lockProject(ctx, p.proj)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn(ctx)
+}
+
+// stepContinues reports whether any of the stages is part of a step
+func stepContinues(stages []stage, step deployment.Step) bool {
+	for _, s := range stages {
+		if s.step == step {
+			return true
+		}
+	}
+	return false
+}
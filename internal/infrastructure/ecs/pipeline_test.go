@@ -0,0 +1,266 @@
+package ecs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+
+	elbtypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+)
+
+// fakeDeployments saves deployments without storing them
+type fakeDeployments struct {
+	deployment.DeploymentRepository
+	saves int
+}
+
+func (r *fakeDeployments) Save(ctx context.Context, dep *deployment.Deployment) error {
+	r.saves++
+	return nil
+}
+
+// fakeSteps keeps the latest record of each step
+type fakeSteps struct {
+	deployment.StepRepository
+	records map[deployment.Step]deployment.StepRecord
+}
+
+func (r *fakeSteps) Save(ctx context.Context, record deployment.StepRecord) error {
+	r.records[record.Step] = record
+	return nil
+}
+
+// fakeLocker records when the project lock is taken and released
+type fakeLocker struct {
+	calls *[]string
+}
+
+func (l fakeLocker) LockProject(ctx context.Context, projectID string) (func(), error) {
+	*l.calls = append(*l.calls, "lock")
+	return func() { *l.calls = append(*l.calls, "unlock") }, nil
+}
+
+// pipelineRun runs stages in a pipeline of fakes, recording the stages run and compensated
+type pipelineRun struct {
+	p     *pipeline
+	steps *fakeSteps
+	calls []string
+}
+
+func newPipelineRun(t *testing.T) *pipelineRun {
+	t.Helper()
+	owner := user.NewUserID()
+	proj, err := project.NewProject(owner, "https://github.com/acme/app", "npm install", "", "npm start", "NODE", "", false, "")
+	if err != nil {
+		t.Fatalf("NewProject() error = %v", err)
+	}
+	dep, err := deployment.NewDeployment(proj.ID(), owner, "abc123def456", "main", project.EnvironmentProduction)
+	if err != nil {
+		t.Fatalf("NewDeployment() error = %v", err)
+	}
+
+	steps := &fakeSteps{records: map[deployment.Step]deployment.StepRecord{}}
+	o := &DeploymentOrchestrator{deploymentRepo: &fakeDeployments{}, steps: steps}
+	r := &pipelineRun{p: o.newPipeline(proj, dep, o.trackSteps(context.Background(), dep, "")), steps: steps}
+	o.locker = fakeLocker{calls: &r.calls}
+	return r
+}
+
+// stage returns a stage that fails with err, undone by a compensation that is recorded
+func (r *pipelineRun) stage(name string, err error) stage {
+	return stage{
+		name:    name,
+		failure: "Failed to " + name,
+		run: func(ctx context.Context) error {
+			r.calls = append(r.calls, name)
+			return err
+		},
+		compensate: func(ctx context.Context) {
+			r.calls = append(r.calls, "undo "+name)
+		},
+	}
+}
+
+func TestPipeline_CompensatesInReverseOrder(t *testing.T) {
+	errFailed := errors.New("failed")
+	tests := []struct {
+		name      string
+		failing   int // Index of the stage that fails, -1 for none
+		wantCalls []string
+	}{
+		{"none fails", -1, []string{"a", "b", "c"}},
+		{"first fails", 0, []string{"a"}},
+		{"second fails", 1, []string{"a", "b", "undo a"}},
+		{"last fails", 2, []string{"a", "b", "c", "undo b", "undo a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newPipelineRun(t)
+			var stages []stage
+			for i, name := range []string{"a", "b", "c"} {
+				var err error
+				if i == tt.failing {
+					err = errFailed
+				}
+				stages = append(stages, r.stage(name, err))
+			}
+
+			err := r.p.run(context.Background(), stages)
+			if tt.failing < 0 && err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if tt.failing >= 0 && !errors.Is(err, errFailed) {
+				t.Fatalf("run() error = %v, want the failure of the stage", err)
+			}
+			if !slices.Equal(r.calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", r.calls, tt.wantCalls)
+			}
+			if tt.failing >= 0 && !strings.Contains(r.p.dep.Logs().String(), "Failed to "+stages[tt.failing].name) {
+				t.Errorf("logs = %q, want the stage's failure", r.p.dep.Logs().String())
+			}
+		})
+	}
+}
+
+func TestPipeline_OptionalStagesDontFailTheRun(t *testing.T) {
+	r := newPipelineRun(t)
+	optional := r.stage("b", errors.New("no DNS"))
+	optional.optional = true
+
+	if err := r.p.run(context.Background(), []stage{r.stage("a", nil), optional, r.stage("c", nil)}); err != nil {
+		t.Fatalf("run() error = %v, want optional stages to be skipped", err)
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(r.calls, want) {
+		t.Errorf("calls = %v, want %v without compensations", r.calls, want)
+	}
+	if !strings.Contains(r.p.dep.Logs().String(), "Warning: Failed to b: no DNS") {
+		t.Errorf("logs = %q, want a warning for the optional stage", r.p.dep.Logs().String())
+	}
+}
+
+func TestPipeline_RecordsSteps(t *testing.T) {
+	tests := []struct {
+		name    string
+		failing bool
+		want    map[deployment.Step]deployment.StepStatus
+	}{
+		{"succeeds", false, map[deployment.Step]deployment.StepStatus{
+			deployment.StepALB: deployment.StepSucceeded,
+			deployment.StepECS: deployment.StepSucceeded,
+		}},
+		// The step of the failing stage fails, the step after it never starts
+		{"fails", true, map[deployment.Step]deployment.StepStatus{
+			deployment.StepALB: deployment.StepFailed,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newPipelineRun(t)
+			var err error
+			if tt.failing {
+				err = errors.New("no routing")
+			}
+			routing := r.stage("route", nil)
+			routing.step = deployment.StepALB
+			rule := r.stage("rule", err)
+			rule.step = deployment.StepALB
+			service := r.stage("deploy", nil)
+			service.step = deployment.StepECS
+
+			_ = r.p.run(context.Background(), []stage{routing, rule, service})
+
+			if len(r.steps.records) != len(tt.want) {
+				t.Errorf("recorded %d steps, want %d", len(r.steps.records), len(tt.want))
+			}
+			for step, want := range tt.want {
+				if got := r.steps.records[step].Status; got != want {
+					t.Errorf("step %s status = %s, want %s", step, got, want)
+				}
+			}
+			if len(r.p.steps.running) != 0 {
+				t.Errorf("%d steps still running after the run", len(r.p.steps.running))
+			}
+		})
+	}
+}
+
+func TestPipeline_Attempt(t *testing.T) {
+	delay := stageRetryDelay
+	stageRetryDelay = 0
+	t.Cleanup(func() { stageRetryDelay = delay })
+
+	tests := []struct {
+		name      string
+		attempts  int
+		errs      []error // Returned by the attempts in order, nil once they run out
+		wantRuns  int
+		wantError bool
+	}{
+		{"succeeds first time", 3, nil, 1, false},
+		{"succeeds on retry", 3, []error{errors.New("throttled")}, 2, false},
+		{"runs out of attempts", 3, []error{errors.New("a"), errors.New("b"), errors.New("c")}, 3, true},
+		{"runs once without attempts", 0, []error{errors.New("a")}, 1, true},
+		{"doesn't retry quota errors", 3, []error{&elbtypes.TooManyRulesException{}}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newPipelineRun(t)
+			runs := 0
+			s := stage{name: "route", attempts: tt.attempts, run: func(ctx context.Context) error {
+				runs++
+				if runs <= len(tt.errs) {
+					return tt.errs[runs-1]
+				}
+				return nil
+			}}
+
+			err := r.p.attempt(context.Background(), s)
+			if (err != nil) != tt.wantError {
+				t.Errorf("attempt() error = %v, want error %v", err, tt.wantError)
+			}
+			if runs != tt.wantRuns {
+				t.Errorf("stage ran %d times, want %d", runs, tt.wantRuns)
+			}
+		})
+	}
+}
+
+func TestPipeline_LockedStagesHoldTheProjectLock(t *testing.T) {
+	r := newPipelineRun(t)
+	routing := r.stage("route", nil)
+	routing.locked = true
+	service := r.stage("deploy", nil)
+	service.locked = true
+	service.run = func(ctx context.Context) error {
+		r.calls = append(r.calls, "deploy")
+		// Waiting for the service releases the lock and takes it again
+		return waitUnlocked(ctx, func() error {
+			r.calls = append(r.calls, "wait")
+			return nil
+		})
+	}
+
+	err := r.p.run(context.Background(), []stage{r.stage("prepare", nil), routing, service, r.stage("check", errors.New("unhealthy"))})
+	if err == nil {
+		t.Fatal("run() error = nil, want the failure of the last stage")
+	}
+
+	want := []string{
+		"prepare",
+		"lock", "route", "unlock",
+		"lock", "deploy", "unlock", "wait", "lock", "unlock",
+		"check",
+		"lock", "undo deploy", "unlock",
+		"lock", "undo route", "unlock",
+		"undo prepare",
+	}
+	if !slices.Equal(r.calls, want) {
+		t.Errorf("calls = %v, want %v", r.calls, want)
+	}
+}
@@ -0,0 +1,300 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/infrastructure/alb"
+	"snapdeploy-core/internal/infrastructure/cloudfront"
+	"snapdeploy-core/internal/infrastructure/database"
+)
+
+// PlanDeployment returns the changes deploying an image to a project's environment would make to its cloud
+// resources, reading their current state without changing anything. Returns deployment.ErrTargetUnavailable
+// when the deployment would fail because the platform isn't configured for the project.
+func (o *DeploymentOrchestrator) PlanDeployment(ctx context.Context, proj *project.Project, env project.Environment, imageURI string) ([]deployment.PlannedChange, error) {
+	serviceName := environmentServiceName(proj.ID().String(), env)
+	domain := proj.CustomDomain().ForEnvironment(env)
+	fullDomain := fmt.Sprintf("%s.%s", domain.String(), o.baseDomain)
+
+	if proj.Type() == project.TypeStatic {
+		return o.planStatic(proj, serviceName, fullDomain)
+	}
+
+	envVars, err := o.runtimeEnvVars(ctx, proj, env)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := o.planRuntime(ctx, proj, env, serviceName, imageURI, envVars)
+	if err != nil {
+		return nil, err
+	}
+
+	// The app listens on PORT when it is set, as it does once deployed
+	port := int32(proj.Port())
+	if custom, err := parsePort(envVars["PORT"]); err == nil {
+		port = custom
+	}
+
+	if proj.DeploymentTarget() == project.TargetLambda {
+		planned, err := o.planLambda(ctx, serviceName, fullDomain, imageURI, port)
+		return append(changes, planned...), err
+	}
+
+	planned, err := o.planServices(ctx, proj, serviceName, domain, imageURI, port)
+	return append(changes, planned...), err
+}
+
+// planStatic returns the changes publishing a static site would make
+func (o *DeploymentOrchestrator) planStatic(proj *project.Project, serviceName, fullDomain string) ([]deployment.PlannedChange, error) {
+	if o.cdn == nil {
+		return nil, fmt.Errorf("%w: CloudFront is not configured", deployment.ErrTargetUnavailable)
+	}
+	return []deployment.PlannedChange{
+		{
+			Action:   deployment.ChangeRun,
+			Resource: "ECS task",
+			Name:     serviceName,
+			Detail:   fmt.Sprintf("Uploads %s to s3://%s/%s", proj.OutputDirectory(), o.cdn.Bucket(), cloudfront.SitePrefix(serviceName)),
+		},
+		{
+			Action:   deployment.ChangeUpdate,
+			Resource: "CloudFront distribution",
+			Name:     serviceName,
+			Detail:   fmt.Sprintf("Serves %s, created if the site has none and invalidated", fullDomain),
+		},
+	}, nil
+}
+
+// planLambda returns the changes deploying a project to Lambda would make
+func (o *DeploymentOrchestrator) planLambda(ctx context.Context, serviceName, fullDomain, imageURI string, port int32) ([]deployment.PlannedChange, error) {
+	if o.functions == nil {
+		return nil, fmt.Errorf("%w: Lambda is not configured", deployment.ErrTargetUnavailable)
+	}
+
+	live, err := o.functions.LiveVersion(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	action, detail := deployment.ChangeCreate, fmt.Sprintf("Runs %s on port %d", imageURI, port)
+	if live != "" {
+		action, detail = deployment.ChangeUpdate, fmt.Sprintf("Publishes a version running %s on port %d, replacing version %s", imageURI, port, live)
+	}
+	return []deployment.PlannedChange{
+		{Action: action, Resource: "Lambda function", Name: serviceName, Detail: detail},
+		{Action: deployment.ChangeUpdate, Resource: "API Gateway API", Name: serviceName, Detail: "Serves " + fullDomain},
+	}, nil
+}
+
+// planRuntime returns the changes preparing what a project's processes run with would make: its database
+// and migrations
+func (o *DeploymentOrchestrator) planRuntime(ctx context.Context, proj *project.Project, env project.Environment, serviceName, imageURI string, envVars map[string]string) ([]deployment.PlannedChange, error) {
+	var changes []deployment.PlannedChange
+
+	// Environments with their own DATABASE_URL don't use the project's database
+	_, ownDatabase := envVars["DATABASE_URL"]
+	if proj.RequireDB() && !(ownDatabase && !env.IsProduction()) {
+		if o.dbManager == nil {
+			return nil, fmt.Errorf("%w: the project requires a database but databases aren't managed", deployment.ErrTargetUnavailable)
+		}
+		dbName := database.GetDatabaseName(proj.ID().String())
+		exists, err := o.dbManager.DatabaseExists(ctx, dbName)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			changes = append(changes, deployment.PlannedChange{Action: deployment.ChangeCreate, Resource: "Database", Name: dbName})
+		}
+	}
+
+	if (proj.RequireDB() || proj.UsesDatastore(project.DatastoreMySQL) || proj.HasVolume()) && !proj.MigrationCommand().IsEmpty() {
+		changes = append(changes, deployment.PlannedChange{
+			Action:   deployment.ChangeRun,
+			Resource: "ECS task",
+			Name:     serviceName + "-migration",
+			Detail:   fmt.Sprintf("Runs %q with %s", proj.MigrationCommand().String(), imageURI),
+		})
+	}
+
+	return changes, nil
+}
+
+// planServices returns the changes rolling out a project's ECS services, their routing and schedules would make
+func (o *DeploymentOrchestrator) planServices(ctx context.Context, proj *project.Project, serviceName string, domain project.CustomDomain, imageURI string, port int32) ([]deployment.PlannedChange, error) {
+	strategy := proj.DeploymentStrategy()
+	if strategy == project.StrategyBlueGreen && o.codeDeploy == nil {
+		return nil, fmt.Errorf("%w: blue/green deployments require CodeDeploy", deployment.ErrTargetUnavailable)
+	}
+	if proj.Type() == project.TypeCron && o.scheduler == nil {
+		return nil, fmt.Errorf("%w: scheduled tasks require EventBridge", deployment.ErrTargetUnavailable)
+	}
+
+	var changes []deployment.PlannedChange
+	taskDefinition := func(name string) {
+		changes = append(changes, deployment.PlannedChange{
+			Action:   deployment.ChangeCreate,
+			Resource: "Task definition revision",
+			Name:     name,
+			Detail:   fmt.Sprintf("Runs %s with %d CPU units and %d MiB of memory", imageURI, proj.CPU(), proj.Memory()),
+		})
+	}
+
+	// The project's other services go out first
+	for _, svc := range proj.Services() {
+		name := processServiceName(serviceName, svc)
+		taskDefinition(name)
+		if svc.Type() == project.TypeCron {
+			changes = append(changes, deployment.PlannedChange{Action: deployment.ChangeUpdate, Resource: "Schedule", Name: cronRuleName(name), Detail: "Runs on " + svc.Schedule().String()})
+			continue
+		}
+		svcPort := int32(0)
+		if svc.Type().ServesTraffic() {
+			svcPort = int32(svc.Port())
+		}
+		change, err := o.planService(ctx, name, project.StrategyRolling, svcPort)
+		if err != nil {
+			return nil, err
+		}
+		change.Detail = fmt.Sprintf("%d %s task(s)", svc.Replicas(), svc.Type())
+		changes = append(changes, change)
+	}
+
+	taskDefinition(serviceName)
+
+	if proj.Type() == project.TypeCron {
+		// A project that ran continuously before it became a cron job has a service to remove
+		if _, err := o.ecsClient.getService(ctx, serviceName); err == nil {
+			changes = append(changes, deployment.PlannedChange{Action: deployment.ChangeDelete, Resource: "ECS service", Name: serviceName, Detail: "Cron jobs only run on their schedule"})
+		} else if !isServiceNotFoundError(err) {
+			return nil, fmt.Errorf("failed to check service: %w", err)
+		}
+		return append(changes, deployment.PlannedChange{Action: deployment.ChangeUpdate, Resource: "Schedule", Name: cronRuleName(serviceName), Detail: "Runs on " + proj.Schedule().String()}), nil
+	}
+
+	servesTraffic := proj.Type().ServesTraffic()
+	if !servesTraffic {
+		port = 0
+	}
+	change, err := o.planService(ctx, serviceName, strategy, port)
+	if err != nil {
+		return nil, err
+	}
+	change.Detail = fmt.Sprintf("%s deployment of a %s service", strategy, proj.Type())
+	changes = append(changes, change)
+
+	if !servesTraffic {
+		if change.Action == deployment.ChangeReplace {
+			// The project served traffic before it became a worker
+			changes = append(changes, deployment.PlannedChange{Action: deployment.ChangeDelete, Resource: "Target group", Name: serviceName})
+		}
+		return changes, nil
+	}
+
+	routing, err := o.albClient.DescribeRouting(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	changes = append(changes, planRouting(routing, serviceName, port, proj.HealthCheckPath())...)
+	if strategy == project.StrategyBlueGreen {
+		changes = append(changes,
+			deployment.PlannedChange{Action: deployment.ChangeUpdate, Resource: "Target group", Name: alb.GreenTargetGroupName(serviceName), Detail: "Receives the new version before traffic shifts"},
+			deployment.PlannedChange{Action: deployment.ChangeUpdate, Resource: "CodeDeploy deployment group", Name: serviceName, Detail: "Shifts traffic to the new version"},
+		)
+	}
+	if strategy == project.StrategyCanary && change.Action == deployment.ChangeUpdate {
+		changes = append(changes, deployment.PlannedChange{
+			Action:   deployment.ChangeCreate,
+			Resource: "Target group",
+			Name:     alb.CanaryTargetGroupName(serviceName),
+			Detail:   fmt.Sprintf("Receives %d%% of traffic for %d minutes", proj.CanaryPercent(), proj.CanaryBakeMinutes()),
+		})
+	}
+
+	fullDomain := fmt.Sprintf("%s.%s", domain.String(), o.baseDomain)
+	exists, err := o.route53Client.RecordExists(ctx, domain.String())
+	if err != nil {
+		return nil, err
+	}
+	dnsAction := deployment.ChangeCreate
+	if exists {
+		dnsAction = deployment.ChangeUpdate
+	}
+	return append(changes, deployment.PlannedChange{Action: dnsAction, Resource: "DNS record", Name: fullDomain, Detail: "Alias of the load balancer"}), nil
+}
+
+// planService returns the change rolling out an ECS service with a strategy on a port would make
+func (o *DeploymentOrchestrator) planService(ctx context.Context, serviceName string, strategy project.DeploymentStrategy, port int32) (deployment.PlannedChange, error) {
+	change := deployment.PlannedChange{Resource: "ECS service", Name: serviceName}
+	service, err := o.ecsClient.getService(ctx, serviceName)
+	switch {
+	case err == nil && isIncompatible(service, strategy, port):
+		change.Action = deployment.ChangeReplace
+	case err == nil:
+		change.Action = deployment.ChangeUpdate
+	case isServiceNotFoundError(err):
+		change.Action = deployment.ChangeCreate
+	default:
+		return change, fmt.Errorf("failed to check service: %w", err)
+	}
+	return change, nil
+}
+
+// planRouting returns the changes routing a service's subdomain to its port would make to the load balancer
+func planRouting(routing *alb.Routing, serviceName string, port int32, healthCheckPath string) []deployment.PlannedChange {
+	detail := fmt.Sprintf("Port %d, health checked on %s", port, healthCheckPath)
+	targetGroup := deployment.PlannedChange{Resource: "Target group", Name: serviceName, Detail: detail}
+	switch {
+	case !routing.TargetGroupExists:
+		targetGroup.Action = deployment.ChangeCreate
+	case routing.Port != port:
+		targetGroup.Action = deployment.ChangeReplace
+		targetGroup.Detail = fmt.Sprintf("Port %d instead of %d, health checked on %s", port, routing.Port, healthCheckPath)
+	case routing.HealthCheckPath != healthCheckPath:
+		targetGroup.Action = deployment.ChangeUpdate
+		targetGroup.Detail = fmt.Sprintf("Health checked on %s instead of %s", healthCheckPath, routing.HealthCheckPath)
+	}
+
+	var changes []deployment.PlannedChange
+	if targetGroup.Action != "" {
+		changes = append(changes, targetGroup)
+	}
+	if !routing.RuleExists {
+		changes = append(changes, deployment.PlannedChange{Action: deployment.ChangeCreate, Resource: "Listener rule", Name: serviceName, Detail: "Forwards the subdomain to the target group"})
+	} else if targetGroup.Action == deployment.ChangeReplace {
+		changes = append(changes, deployment.PlannedChange{Action: deployment.ChangeUpdate, Resource: "Listener rule", Name: serviceName, Detail: "Forwards to the new target group"})
+	}
+	return changes
+}
+
+// runtimeEnvVars returns the environment variables a project's processes would run with in an environment,
+// before the platform provisions its datastores
+func (o *DeploymentOrchestrator) runtimeEnvVars(ctx context.Context, proj *project.Project, env project.Environment) (map[string]string, error) {
+	envVars := map[string]string{
+		"PROJECT_ID": proj.ID().String(),
+		"LANGUAGE":   proj.Language().String(),
+		"PORT":       strconv.Itoa(proj.Port()),
+	}
+	decrypter, ok := o.envVarRepo.(interface {
+		DecryptRuntime(ctx context.Context, projectID project.ProjectID, env project.Environment) (map[string]string, error)
+	})
+	if !ok {
+		return envVars, nil
+	}
+	userEnvVars, err := decrypter.DecryptRuntime(ctx, proj.ID(), env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load environment variables: %w", err)
+	}
+	for k, v := range userEnvVars {
+		envVars[k] = v
+	}
+	return envVars, nil
+}
+
+// DomainRecordExists checks if a DNS record exists for a subdomain of the platform's domain, whether a
+// project's deployment created it or it was added by hand
+func (o *DeploymentOrchestrator) DomainRecordExists(ctx context.Context, subdomain string) (bool, error) {
+	return o.route53Client.RecordExists(ctx, subdomain)
+}
@@ -0,0 +1,366 @@
+package ecs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/infrastructure/eventbridge"
+	"snapdeploy-core/internal/infrastructure/quota"
+	"snapdeploy-core/internal/infrastructure/route53"
+)
+
+// servicePrefix starts the names of the ECS services and schedules of a project's other services
+func servicePrefix(serviceName string) string {
+	return serviceName + "-"
+}
+
+// processServiceName returns the name of the ECS service running one of a project's other services
+func processServiceName(serviceName string, svc project.Service) string {
+	return servicePrefix(serviceName) + svc.Name()
+}
+
+// rolledOutService is an ECS service a deployment updated, and the task definition it ran before
+type rolledOutService struct {
+	name     string
+	previous string // Empty if the deployment created the service
+}
+
+// serviceRollout is what a deployment did to a project's other services, so it can be undone if
+// the deployment fails and completed once it succeeds
+type serviceRollout struct {
+	rolledOut  []rolledOutService
+	schedules  []eventbridge.ScheduleRequest // Put in place once the deployment succeeds
+	subdomains []string                      // Pointed at the load balancer once the deployment succeeds
+	keep       map[string]bool               // ECS services and schedule rules the project still defines
+}
+
+// rollOutServices deploys a project's other services from the main service's request, before the main
+// service goes out. Continuously running services are rolled out and must become stable, CRON services
+// have their task definitions registered. If one fails the others are reverted and the failure is
+// recorded on the deployment.
+func (o *DeploymentOrchestrator) rollOutServices(ctx context.Context, proj *project.Project, dep *deployment.Deployment, base DeploymentRequest) (*serviceRollout, error) {
+	rollout := &serviceRollout{keep: map[string]bool{}}
+	services := proj.Services()
+	if len(services) == 0 {
+		return rollout, nil
+	}
+
+	dep.AppendLog(fmt.Sprintf("🧱 Deploying %d more services...", len(services)))
+	o.deploymentRepo.Save(ctx, dep)
+
+	var clusterArn string
+	for _, svc := range services {
+		req := base
+		req.ServiceName = processServiceName(base.ServiceName, svc)
+		req.Command = strings.Fields(svc.Command().String())
+		req.DesiredCount = int32(svc.Replicas())
+		req.ContainerPort = 0
+		req.TargetGroupArn = ""
+		req.Strategy = project.StrategyRolling
+		if svc.Type().ServesTraffic() {
+			req.ContainerPort = int32(svc.Port())
+			req.EnvVars = maps.Clone(base.EnvVars)
+			req.EnvVars["PORT"] = strconv.Itoa(svc.Port())
+		}
+
+		var err error
+		if svc.Type() == project.TypeCron {
+			if clusterArn == "" && o.scheduler != nil {
+				clusterArn, err = o.ecsClient.ClusterARN(ctx)
+			}
+			if err == nil {
+				err = o.prepareScheduledService(ctx, proj, dep, svc, req, clusterArn, rollout)
+			}
+		} else {
+			err = o.rollOutService(ctx, proj, dep, svc, req, rollout)
+		}
+		if err != nil {
+			o.appendFailure(ctx, proj, dep, fmt.Sprintf("Service %s failed to deploy", svc.Name()), err)
+			o.revertServices(ctx, proj, dep, rollout)
+			dep.UpdateStatus(deployment.StatusFailed)
+			o.deploymentRepo.Save(ctx, dep)
+			return nil, fmt.Errorf("failed to deploy service %s: %w", svc.Name(), err)
+		}
+	}
+
+	return rollout, nil
+}
+
+// prepareScheduledService registers the task definition of a CRON service. Its schedule is put in
+// place once the rest of the deployment succeeds.
+func (o *DeploymentOrchestrator) prepareScheduledService(
+	ctx context.Context,
+	proj *project.Project,
+	dep *deployment.Deployment,
+	svc project.Service,
+	req DeploymentRequest,
+	clusterArn string,
+	rollout *serviceRollout,
+) error {
+	if o.scheduler == nil {
+		return errors.New("EventBridge is not configured on this platform")
+	}
+
+	taskDefArn, err := o.ecsClient.createTaskDefinition(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to register task definition: %w", err)
+	}
+
+	rule := cronRuleName(req.ServiceName)
+	rollout.keep[rule] = true
+	rollout.schedules = append(rollout.schedules, eventbridge.ScheduleRequest{
+		RuleName:          rule,
+		Schedule:          svc.Schedule().String(),
+		Description:       fmt.Sprintf("SnapDeploy cron job %s of project %s", svc.Name(), proj.ID().String()),
+		ClusterArn:        clusterArn,
+		TaskDefinitionArn: taskDefArn,
+		SubnetIDs:         req.SubnetIDs,
+		SecurityGroupID:   req.SecurityGroupID,
+		Tags:              map[string]string{deploymentIDTag: dep.ID().String()},
+	})
+
+	dep.AppendLog(fmt.Sprintf("⏰ %s: runs on %s once the deployment succeeds", svc.Name(), svc.Schedule()))
+	o.deploymentRepo.Save(ctx, dep)
+	return nil
+}
+
+// rollOutService rolls out a continuously running service with the rolling strategy and waits until
+// its new tasks are stable. WEB services are routed their own subdomain.
+func (o *DeploymentOrchestrator) rollOutService(
+	ctx context.Context,
+	proj *project.Project,
+	dep *deployment.Deployment,
+	svc project.Service,
+	req DeploymentRequest,
+	rollout *serviceRollout,
+) error {
+	rollout.keep[req.ServiceName] = true
+
+	// ECS can't add or remove the load balancer of a service, so one whose port changed is replaced
+	replaced, _, err := o.ecsClient.RemoveIncompatibleService(ctx, req.ServiceName, req.Strategy, req.ContainerPort)
+	if err != nil {
+		return fmt.Errorf("failed to replace service: %w", err)
+	}
+	if replaced {
+		dep.AppendLog(fmt.Sprintf("♻️  %s: removed the existing service, ECS can't move it to the new port", svc.Name()))
+	}
+
+	if svc.Type().ServesTraffic() {
+		subdomain := svc.Subdomain(proj.CustomDomain().ForEnvironment(dep.Environment()))
+		req.TargetGroupArn, err = o.albClient.CreateTargetGroupAndRule(ctx, req.ServiceName, subdomain, o.baseDomain, req.ContainerPort, project.DefaultHealthCheckPath)
+		if err != nil {
+			return fmt.Errorf("failed to create ALB routing: %w", err)
+		}
+		rollout.subdomains = append(rollout.subdomains, subdomain)
+	} else if replaced {
+		// The service served traffic before
+		o.removeServiceRouting(ctx, proj, dep.Environment(), req.ServiceName, svc.Name())
+	}
+
+	previous, err := o.ecsClient.CurrentTaskDefinition(ctx, req.ServiceName)
+	if err != nil && !isServiceNotFoundError(err) {
+		return fmt.Errorf("failed to check service: %w", err)
+	}
+
+	dep.AppendLog(fmt.Sprintf("📦 %s: rolling out %d task(s)...", svc.Name(), svc.Replicas()))
+	o.deploymentRepo.Save(ctx, dep)
+
+	result, err := o.ecsClient.DeployService(ctx, req)
+	if err != nil {
+		return err
+	}
+	rollout.rolledOut = append(rollout.rolledOut, rolledOutService{name: req.ServiceName, previous: previous})
+
+	waitErr := waitUnlocked(ctx, func() error {
+		return o.ecsClient.WaitForServiceStable(ctx, req.ServiceName, 5*time.Minute, o.rolloutReporter(ctx, dep))
+	})
+	if waitErr != nil && quota.Classify(waitErr) != nil {
+		return waitErr
+	}
+	if err := o.ecsClient.CheckRollout(ctx, req.ServiceName, result.TaskDefinitionArn); errors.Is(err, ErrDeploymentRolledBack) {
+		return err
+	}
+
+	if waitErr != nil {
+		dep.AppendLog(fmt.Sprintf("⚠️  Warning: %s may not be fully stable: %v", svc.Name(), waitErr))
+	} else {
+		dep.AppendLog(fmt.Sprintf("✅ %s is running and stable", svc.Name()))
+	}
+	o.deploymentRepo.Save(ctx, dep)
+	return nil
+}
+
+// revertServices puts the services a failed deployment rolled out back on the task definitions they
+// ran before, and deletes the ones it created
+func (o *DeploymentOrchestrator) revertServices(ctx context.Context, proj *project.Project, dep *deployment.Deployment, rollout *serviceRollout) {
+	for _, s := range rollout.rolledOut {
+		var err error
+		if s.previous == "" {
+			err = o.ecsClient.DeleteService(ctx, s.name)
+		} else {
+			err = o.ecsClient.RevertService(ctx, s.name, s.previous)
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to revert service", "project_id", proj.ID().String(), "service", s.name, "error", err)
+			dep.AppendLog(fmt.Sprintf("⚠️  Warning: Could not revert %s: %v", s.name, err))
+			continue
+		}
+		dep.AppendLog(fmt.Sprintf("↩️  Reverted %s to its previous version", s.name))
+	}
+	rollout.rolledOut = nil
+	o.deploymentRepo.Save(ctx, dep)
+}
+
+// finishServices completes or reverts what a deployment did to a project's other services, depending
+// on whether the deployment succeeded. Once the main service is live, failures are only logged.
+func (o *DeploymentOrchestrator) finishServices(ctx context.Context, proj *project.Project, dep *deployment.Deployment, serviceName string, rollout *serviceRollout, succeeded bool) {
+	if !succeeded {
+		if len(rollout.rolledOut) > 0 {
+			dep.AppendLog("↩️  Reverting the project's other services...")
+			o.revertServices(ctx, proj, dep, rollout)
+		}
+		return
+	}
+
+	for _, schedule := range rollout.schedules {
+		if err := o.scheduler.PutSchedule(ctx, schedule); err != nil {
+			dep.AppendLog(fmt.Sprintf("⚠️  Warning: Could not schedule %s: %v", schedule.RuleName, err))
+		}
+	}
+
+	for _, subdomain := range rollout.subdomains {
+		if err := o.route53Client.CreateOrUpdateRecord(ctx, route53.DNSRecordRequest{
+			Subdomain: subdomain,
+			Target:    o.albDNS,
+			Type:      "ALIAS",
+		}); err != nil {
+			dep.AppendLog(fmt.Sprintf("⚠️  Warning: DNS configuration of %s failed: %v", subdomain, err))
+		} else {
+			dep.AppendLog(fmt.Sprintf("🌍 Service live at: https://%s.%s", subdomain, o.baseDomain))
+		}
+	}
+
+	if err := o.removeServices(ctx, proj, dep.Environment(), serviceName, rollout.keep); err != nil {
+		dep.AppendLog(fmt.Sprintf("⚠️  Warning: Could not remove services the project no longer defines: %v", err))
+	}
+	o.deploymentRepo.Save(ctx, dep)
+}
+
+// removeServices deletes the ECS services, routing, DNS records and schedules of the other services of a
+// project's environment, except the ones named in keep
+func (o *DeploymentOrchestrator) removeServices(ctx context.Context, proj *project.Project, env project.Environment, serviceName string, keep map[string]bool) error {
+	prefix := servicePrefix(serviceName)
+
+	names, err := o.ecsClient.ListServiceNames(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if keep[name] || name == canaryServiceName(serviceName) {
+			continue
+		}
+		if err := o.ecsClient.DeleteService(ctx, name); err != nil {
+			return fmt.Errorf("failed to delete service %s: %w", name, err)
+		}
+		// The service may have been a WEB service
+		o.removeServiceRouting(ctx, proj, env, name, strings.TrimPrefix(name, prefix))
+		slog.InfoContext(ctx, "Removed service", "project_id", proj.ID().String(), "service", name)
+	}
+
+	if o.scheduler == nil {
+		return nil
+	}
+	rules, err := o.scheduler.ListScheduleNames(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if keep[rule] || rule == cronRuleName(serviceName) {
+			continue
+		}
+		if err := o.scheduler.DeleteSchedule(ctx, rule); err != nil {
+			return fmt.Errorf("failed to delete schedule %s: %w", rule, err)
+		}
+	}
+
+	return nil
+}
+
+// removeServiceRouting deletes the load balancer routing and DNS record of a service that no longer
+// receives traffic
+func (o *DeploymentOrchestrator) removeServiceRouting(ctx context.Context, proj *project.Project, env project.Environment, ecsName, name string) {
+	if err := o.albClient.DeleteTargetGroupAndRule(ctx, ecsName); err != nil {
+		slog.WarnContext(ctx, "Failed to delete ALB routing", "project_id", proj.ID().String(), "service", ecsName, "error", err)
+	}
+	subdomain := project.ServiceSubdomain(proj.CustomDomain().ForEnvironment(env), name)
+	if err := o.route53Client.DeleteRecord(ctx, subdomain, "A"); err != nil && !errors.Is(err, route53.ErrRecordNotFound) {
+		slog.WarnContext(ctx, "Failed to delete DNS record", "project_id", proj.ID().String(), "service", ecsName, "error", err)
+	}
+}
+
+// restartServices replaces the tasks of a project's continuously running other services
+func (o *DeploymentOrchestrator) restartServices(ctx context.Context, proj *project.Project, dep *deployment.Deployment, serviceName string) error {
+	for _, svc := range proj.Services() {
+		if svc.Type() == project.TypeCron {
+			continue
+		}
+
+		name := processServiceName(serviceName, svc)
+		dep.AppendLog(fmt.Sprintf("🔄 Restarting service: %s", name))
+		o.deploymentRepo.Save(ctx, dep)
+
+		if err := o.ecsClient.RestartService(ctx, name); err != nil {
+			return err
+		}
+		err := waitUnlocked(ctx, func() error {
+			return o.ecsClient.WaitForServiceStable(ctx, name, 5*time.Minute, o.rolloutReporter(ctx, dep))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stopServices scales a project's other services down to 0 tasks and deletes their schedules
+func (o *DeploymentOrchestrator) stopServices(ctx context.Context, serviceName string) error {
+	prefix := servicePrefix(serviceName)
+
+	names, err := o.ecsClient.ListServiceNames(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if name == canaryServiceName(serviceName) {
+			continue
+		}
+		if err := o.ecsClient.StopService(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	if o.scheduler == nil {
+		return nil
+	}
+	rules, err := o.scheduler.ListScheduleNames(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if rule == cronRuleName(serviceName) {
+			continue
+		}
+		if err := o.scheduler.DeleteSchedule(ctx, rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/shell"
+)
+
+// shellCommand is the shell opened in containers, the one every image has
+const shellCommand = "/bin/sh"
+
+// OpenShell opens an interactive shell with ECS Exec in the container of a running task of a project's
+// environment. Returns the shell with the ARN of the task it runs in.
+func (o *DeploymentOrchestrator) OpenShell(ctx context.Context, proj *project.Project, env project.Environment) (shell.Conn, string, error) {
+	serviceName := environmentServiceName(proj.ID().String(), env)
+
+	taskArn, err := o.ecsClient.RunningTask(ctx, serviceName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	session, err := o.ecsClient.ExecuteCommand(ctx, taskArn, serviceName, shellCommand)
+	if err != nil {
+		return nil, "", err
+	}
+
+	conn, err := dialExecSession(ctx, session)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open shell in task %s: %w", taskArn, err)
+	}
+	return conn, taskArn, nil
+}
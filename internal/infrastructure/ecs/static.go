@@ -0,0 +1,145 @@
+package ecs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/infrastructure/cloudfront"
+	"snapdeploy-core/internal/infrastructure/route53"
+)
+
+// deployStatic publishes a STATIC project: its image runs once to sync the built site to the environment's
+// prefix of the sites bucket, and the environment's CloudFront distribution serves it on its subdomain.
+// Nothing keeps running once the deployment is done.
+func (o *DeploymentOrchestrator) deployStatic(ctx context.Context, proj *project.Project, dep *deployment.Deployment, imageURI string) error {
+	fail := func(step string, err error) error {
+		o.appendFailure(ctx, proj, dep, step, err)
+		dep.UpdateStatus(deployment.StatusFailed)
+		o.deploymentRepo.Save(ctx, dep)
+		return fmt.Errorf("%s: %w", strings.ToLower(step), err)
+	}
+
+	if err := dep.UpdateStatus(deployment.StatusDeploying); err != nil {
+		return fmt.Errorf("failed to update deployment status: %w", err)
+	}
+	if err := o.deploymentRepo.Save(ctx, dep); err != nil {
+		return fmt.Errorf("failed to save deployment: %w", err)
+	}
+
+	if o.cdn == nil {
+		return fail("Static sites unavailable", errors.New("CloudFront is not configured on this platform"))
+	}
+
+	serviceName := environmentServiceName(proj.ID().String(), dep.Environment())
+	domain := proj.CustomDomain().ForEnvironment(dep.Environment())
+	prefix := cloudfront.SitePrefix(serviceName)
+
+	dep.AppendLog("🚀 Publishing static site...")
+	if !dep.Environment().IsProduction() {
+		dep.AppendLog(fmt.Sprintf("🌱 Environment: %s", dep.Environment()))
+	}
+	dep.AppendLog(fmt.Sprintf("📤 Uploading %s to s3://%s/%s...", proj.OutputDirectory(), o.cdn.Bucket(), prefix))
+	o.deploymentRepo.Save(ctx, dep)
+
+	// The upload runs with its own role, user deployments can't write to the bucket
+	taskDefArn, err := o.ecsClient.createTaskDefinition(ctx, DeploymentRequest{
+		ServiceName:  serviceName,
+		ImageURI:     imageURI,
+		ProjectID:    proj.ID().String(),
+		CustomDomain: domain.String(),
+		CPU:          "256",
+		Memory:       "512",
+		EnvVars: map[string]string{
+			"SITE_BUCKET": o.cdn.Bucket(),
+			"SITE_PREFIX": prefix,
+		},
+		TaskRoleArn: o.siteUploadRole,
+	})
+	if err != nil {
+		return fail("Failed to register upload task definition", err)
+	}
+
+	if err := o.taskRunner.RunTask(ctx, RunTaskRequest{
+		TaskDefinition: taskDefArn,
+		TaskName:       serviceName,
+		Purpose:        "SiteUpload",
+	}); err != nil {
+		return fail("Site upload failed", err)
+	}
+
+	dep.AppendLog("✅ Site uploaded")
+	dep.AppendLog("🔧 Configuring CloudFront distribution...")
+	o.deploymentRepo.Save(ctx, dep)
+
+	fullDomain := fmt.Sprintf("%s.%s", domain.String(), o.baseDomain)
+	site, err := o.cdn.EnsureDistribution(ctx, serviceName, fullDomain)
+	if err != nil {
+		return fail("Failed to configure CloudFront", err)
+	}
+
+	// Files of the previous deployment stay cached at the edge until they are invalidated
+	if err := o.cdn.Invalidate(ctx, site.DistributionID, dep.ID().String()); err != nil {
+		dep.AppendLog(fmt.Sprintf("⚠️  Warning: CloudFront cache invalidation failed, the previous version may be served for a while: %v", err))
+	} else {
+		dep.AppendLog("✅ CloudFront distribution ready, cache invalidated")
+	}
+	o.deploymentRepo.Save(ctx, dep)
+
+	dep.AppendLog(fmt.Sprintf("🌐 Configuring DNS for %s...", fullDomain))
+	o.deploymentRepo.Save(ctx, dep)
+
+	if err := o.route53Client.CreateOrUpdateRecord(ctx, route53.DNSRecordRequest{
+		Subdomain: domain.String(),
+		Target:    site.DomainName,
+		Type:      "ALIAS",
+	}); err != nil {
+		dep.AppendLog(fmt.Sprintf("⚠️  Warning: DNS configuration failed: %v", err))
+		// Don't fail deployment if DNS fails
+	} else {
+		dep.AppendLog("✅ DNS configured successfully")
+		dep.AppendLog(fmt.Sprintf("🌍 Your site is live at: https://%s", fullDomain))
+	}
+	o.deploymentRepo.Save(ctx, dep)
+
+	// A project that ran containers before it became a static site has them removed once the site is served
+	if _, err := o.ecsClient.getService(ctx, serviceName); err == nil {
+		dep.AppendLog("♻️  Removing the existing service, static sites are served without containers")
+		o.deploymentRepo.Save(ctx, dep)
+		if err := o.removeContainers(ctx, proj, dep.Environment(), serviceName); err != nil {
+			slog.WarnContext(ctx, "Failed to remove containers of static site", "project_id", proj.ID().String(), "error", err)
+		}
+	} else if !isServiceNotFoundError(err) {
+		slog.WarnContext(ctx, "Failed to check service of static site", "project_id", proj.ID().String(), "error", err)
+	}
+
+	if err := dep.UpdateStatus(deployment.StatusDeployed); err != nil {
+		return fmt.Errorf("failed to update deployment status: %w", err)
+	}
+	if err := o.deploymentRepo.Save(ctx, dep); err != nil {
+		return fmt.Errorf("failed to save deployment: %w", err)
+	}
+
+	dep.AppendLog("🎉 Static site deployed successfully! A new distribution can take a few minutes to reach every edge location.")
+	o.deploymentRepo.Save(ctx, dep)
+
+	slog.InfoContext(ctx, "Static site deployment completed", "project_id", proj.ID().String())
+	return nil
+}
+
+// retireStaticSite disables the distribution of a project's environment that was a static site before it
+// ran containers. Its files are kept until the project is deleted. Returns whether there was a distribution.
+func (o *DeploymentOrchestrator) retireStaticSite(ctx context.Context, proj *project.Project, serviceName string) bool {
+	if o.cdn == nil {
+		return false
+	}
+	found, err := o.cdn.DisableDistribution(ctx, serviceName)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to disable distribution of former static site", "project_id", proj.ID().String(), "error", err)
+	}
+	return found
+}
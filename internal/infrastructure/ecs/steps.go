@@ -0,0 +1,101 @@
+package ecs
+
+import (
+	"context"
+	"log/slog"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+)
+
+// SetStepRepository sets where the pipeline steps of deployments are recorded (optional). Without one,
+// retried deployments run every step again.
+func (o *DeploymentOrchestrator) SetStepRepository(steps deployment.StepRepository) {
+	o.steps = steps
+}
+
+// ResumeDeployment deploys the image a failed deployment already pushed again, picking up from a pipeline
+// step. Migrations that succeeded in the earlier attempt aren't run again when resuming after them; the other
+// steps only bring resources to the deployment's image and run again.
+func (o *DeploymentOrchestrator) ResumeDeployment(ctx context.Context, proj *project.Project, dep *deployment.Deployment, from deployment.Step) error {
+	return o.deploy(ctx, dep, proj, dep.ImageURI(), from)
+}
+
+// stepTracker records the pipeline steps of a deployment as the orchestrator goes through them
+type stepTracker struct {
+	repo    deployment.StepRepository
+	dep     *deployment.Deployment
+	from    deployment.Step                            // Step a retried deployment resumes from, empty for a first attempt
+	earlier map[deployment.Step]deployment.StepStatus  // Outcome of the steps of the earlier attempt
+	running map[deployment.Step]*deployment.StepRecord // Steps started and not finished yet
+}
+
+// trackSteps starts tracking the steps of a deployment, resumed from a step unless from is empty
+func (o *DeploymentOrchestrator) trackSteps(ctx context.Context, dep *deployment.Deployment, from deployment.Step) *stepTracker {
+	t := &stepTracker{
+		repo:    o.steps,
+		dep:     dep,
+		from:    from,
+		earlier: map[deployment.Step]deployment.StepStatus{},
+		running: map[deployment.Step]*deployment.StepRecord{},
+	}
+	if t.repo == nil || from == "" {
+		return t
+	}
+
+	records, err := t.repo.FindByDeploymentID(ctx, dep.ID())
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to load deployment steps, running every step again", "error", err)
+		return t
+	}
+	for _, record := range records {
+		t.earlier[record.Step] = record.Status
+	}
+	return t
+}
+
+// start records that a step is running
+func (t *stepTracker) start(ctx context.Context, step deployment.Step) {
+	record := deployment.StartStep(t.dep, step)
+	t.running[step] = &record
+	t.save(ctx, record)
+}
+
+// finish records the outcome of a running step, failed if err isn't nil
+func (t *stepTracker) finish(ctx context.Context, step deployment.Step, err error) {
+	record, ok := t.running[step]
+	if !ok {
+		return
+	}
+	delete(t.running, step)
+	record.Finish(err)
+	t.save(ctx, *record)
+}
+
+// close finishes the steps still running when the deployment ends, failed if err isn't nil
+func (t *stepTracker) close(ctx context.Context, err error) {
+	for _, step := range deployment.Steps {
+		t.finish(ctx, step, err)
+	}
+}
+
+// carryOver reports whether a resumed deployment keeps the outcome of a step from its earlier attempt,
+// because it comes before the step the deployment resumes from and completed then. The step is recorded
+// as skipped.
+func (t *stepTracker) carryOver(ctx context.Context, step deployment.Step) bool {
+	if t.from == "" || !step.Before(t.from) || !t.earlier[step].Done() {
+		return false
+	}
+	t.save(ctx, deployment.SkipStep(t.dep, step))
+	return true
+}
+
+// save records a step, deployments go ahead when it can't be recorded
+func (t *stepTracker) save(ctx context.Context, record deployment.StepRecord) {
+	if t.repo == nil {
+		return
+	}
+	if err := t.repo.Save(ctx, record); err != nil {
+		slog.WarnContext(ctx, "Failed to record deployment step", "step", record.Step.String(), "error", err)
+	}
+}
@@ -0,0 +1,82 @@
+package ecs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"snapdeploy-core/internal/domain/project"
+)
+
+// SuspendProject scales every service of a project down to 0 tasks and pauses its schedules while its owner's
+// payment is overdue. Static sites and Lambda functions run no service and keep being served.
+func (o *DeploymentOrchestrator) SuspendProject(ctx context.Context, proj *project.Project) error {
+	return o.scaleProject(ctx, proj, true)
+}
+
+// ResumeProject scales the services of a suspended project back to the tasks they are deployed with and
+// resumes its schedules
+func (o *DeploymentOrchestrator) ResumeProject(ctx context.Context, proj *project.Project) error {
+	return o.scaleProject(ctx, proj, false)
+}
+
+// scaleProject scales the services of every environment of a project to 0 tasks and pauses its schedules, or
+// undoes it. Services and schedules that were never deployed are skipped.
+func (o *DeploymentOrchestrator) scaleProject(ctx context.Context, proj *project.Project, suspend bool) error {
+	if proj.Type() == project.TypeStatic || proj.DeploymentTarget() == project.TargetLambda {
+		return nil
+	}
+
+	ctx, unlock, err := o.lockProject(ctx, proj)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	scale := func(name string, count int) error {
+		if suspend {
+			count = 0
+		}
+		found, err := o.ecsClient.ScaleService(ctx, name, int32(count))
+		if err != nil {
+			return fmt.Errorf("service %s: %w", name, err)
+		}
+		if found {
+			slog.InfoContext(ctx, "Scaled service", "project_id", proj.ID().String(), "service", name, "count", count)
+		}
+		return nil
+	}
+
+	schedule := func(serviceName string) error {
+		if o.scheduler == nil {
+			return nil
+		}
+		if err := o.scheduler.SetScheduleEnabled(ctx, cronRuleName(serviceName), !suspend); err != nil {
+			return fmt.Errorf("schedule of %s: %w", serviceName, err)
+		}
+		return nil
+	}
+
+	var errs []error
+	for _, env := range proj.Environments() {
+		serviceName := environmentServiceName(proj.ID().String(), env)
+
+		// Cron jobs have no main service, only a schedule
+		if proj.Type() == project.TypeCron {
+			errs = append(errs, schedule(serviceName))
+		} else {
+			errs = append(errs, scale(serviceName, 1))
+		}
+		for _, svc := range proj.Services() {
+			name := processServiceName(serviceName, svc)
+			if svc.Type() == project.TypeCron {
+				errs = append(errs, schedule(name))
+			} else {
+				errs = append(errs, scale(name, svc.Replicas()))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -2,27 +2,35 @@ package ecs
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
 	"time"
 
+	"snapdeploy-core/internal/infrastructure/quota"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	logstypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 )
 
-// TaskRunner handles running one-off ECS tasks (like migrations)
+// TaskRunner handles running one-off ECS tasks (like migrations and static site uploads)
 type TaskRunner struct {
 	client        *ecs.Client
+	logs          *cloudwatchlogs.Client
 	cluster       string
 	subnets       []string
 	securityGroup string
 }
 
 // NewTaskRunner creates a new task runner
-func NewTaskRunner(client *ecs.Client, cluster string, subnets []string, securityGroup string) *TaskRunner {
+func NewTaskRunner(client *ecs.Client, logs *cloudwatchlogs.Client, cluster string, subnets []string, securityGroup string) *TaskRunner {
 	return &TaskRunner{
 		client:        client,
+		logs:          logs,
 		cluster:       cluster,
 		subnets:       subnets,
 		securityGroup: securityGroup,
@@ -35,13 +43,40 @@ type RunTaskRequest struct {
 	Command        []string
 	EnvVars        map[string]string
 	TaskName       string
+	Purpose        string               // Tags the task, e.g. SiteUpload, defaults to Migration
+	OnLogLines     func(lines []string) // Receives the lines the task writes while it runs (optional)
+	Timeout        time.Duration        // How long the task may run before it is stopped, defaults to 5 minutes
+}
+
+// defaultTaskTimeout is how long one-off tasks may run unless their request says otherwise
+const defaultTaskTimeout = 5 * time.Minute
+
+// TaskFailedError is returned when a one-off task exits with a non-zero code
+type TaskFailedError struct {
+	ExitCode int32
+	Reason   string
+	Output   string // Line of the task's output explaining the failure, if it wrote one
+}
+
+func (e *TaskFailedError) Error() string {
+	message := fmt.Sprintf("task failed with exit code %d", e.ExitCode)
+	if e.Reason != "" {
+		message += ": " + e.Reason
+	}
+	if e.Output != "" {
+		message += ": " + e.Output
+	}
+	return message
 }
 
 // RunTask runs a one-off ECS task and waits for it to complete
 func (r *TaskRunner) RunTask(ctx context.Context, req RunTaskRequest) error {
-	log.Printf("[TaskRunner] Running one-off task: %s", req.TaskName)
-	log.Printf("[TaskRunner] Task definition: %s", req.TaskDefinition)
-	log.Printf("[TaskRunner] Command: %v", req.Command)
+	slog.InfoContext(ctx, "Running one-off task", "task", req.TaskName, "task_definition", req.TaskDefinition, "command", req.Command)
+
+	purpose := req.Purpose
+	if purpose == "" {
+		purpose = "Migration"
+	}
 
 	// Build environment variables
 	envVars := []types.KeyValuePair{}
@@ -76,7 +111,7 @@ func (r *TaskRunner) RunTask(ctx context.Context, req RunTaskRequest) error {
 		Tags: []types.Tag{
 			{
 				Key:   aws.String("Type"),
-				Value: aws.String("Migration"),
+				Value: aws.String(purpose),
 			},
 			{
 				Key:   aws.String("ManagedBy"),
@@ -91,22 +126,36 @@ func (r *TaskRunner) RunTask(ctx context.Context, req RunTaskRequest) error {
 	}
 
 	if len(result.Tasks) == 0 {
+		if len(result.Failures) > 0 {
+			reason := aws.ToString(result.Failures[0].Reason)
+			err := fmt.Errorf("no tasks were started: %s", reason)
+			if quotaErr := quota.FromMessage(reason, err); quotaErr != nil {
+				return quotaErr
+			}
+			return err
+		}
 		return fmt.Errorf("no tasks were started")
 	}
 
 	taskArn := *result.Tasks[0].TaskArn
-	log.Printf("[TaskRunner] Task started: %s", taskArn)
+	slog.InfoContext(ctx, "Task started", "task_arn", taskArn)
 
 	// Wait for task to complete
-	return r.waitForTaskCompletion(ctx, taskArn)
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = defaultTaskTimeout
+	}
+	tail := r.tailTaskLogs(req.TaskName, taskArn, req.OnLogLines)
+	return waitUnlocked(ctx, func() error { return r.waitForTaskCompletion(ctx, taskArn, timeout, tail) })
 }
 
-// waitForTaskCompletion waits for a task to complete and checks its exit code
-func (r *TaskRunner) waitForTaskCompletion(ctx context.Context, taskArn string) error {
-	log.Printf("[TaskRunner] Waiting for task completion...")
+// waitForTaskCompletion waits for a task to complete and checks its exit code, following its logs meanwhile.
+// Tasks that don't complete within the timeout are stopped.
+func (r *TaskRunner) waitForTaskCompletion(ctx context.Context, taskArn string, timeout time.Duration, tail *taskLogTail) error {
+	slog.DebugContext(ctx, "Waiting for task completion", "task_arn", taskArn)
 
-	// Poll task status
-	maxAttempts := 60 // 5 minutes (5 second intervals)
+	// Poll task status every 5 seconds
+	maxAttempts := int(timeout / (5 * time.Second))
 	for attempt := 0; attempt < maxAttempts; attempt++ {
 		// Describe the task
 		describeInput := &ecs.DescribeTasksInput{
@@ -126,22 +175,27 @@ func (r *TaskRunner) waitForTaskCompletion(ctx context.Context, taskArn string)
 		task := result.Tasks[0]
 		lastStatus := aws.ToString(task.LastStatus)
 
-		log.Printf("[TaskRunner] Task status: %s (attempt %d/%d)", lastStatus, attempt+1, maxAttempts)
+		slog.DebugContext(ctx, "Task status", "task_arn", taskArn, "status", lastStatus, "attempt", attempt+1, "max_attempts", maxAttempts)
+
+		tail.read(ctx)
 
 		// Check if task has stopped
 		if lastStatus == "STOPPED" {
+			// The last lines can reach CloudWatch after the task stops
+			tail.read(ctx)
+
 			// Check exit code
 			if len(task.Containers) > 0 {
 				container := task.Containers[0]
 				exitCode := aws.ToInt32(container.ExitCode)
 
 				if exitCode == 0 {
-					log.Printf("[TaskRunner] ✅ Task completed successfully")
+					slog.InfoContext(ctx, "Task completed", "task_arn", taskArn)
 					return nil
 				} else {
 					reason := aws.ToString(container.Reason)
-					log.Printf("[TaskRunner] ❌ Task failed with exit code %d: %s", exitCode, reason)
-					return fmt.Errorf("task failed with exit code %d: %s", exitCode, reason)
+					slog.WarnContext(ctx, "Task failed", "task_arn", taskArn, "exit_code", exitCode, "reason", reason)
+					return &TaskFailedError{ExitCode: exitCode, Reason: reason, Output: tail.failure()}
 				}
 			}
 
@@ -154,12 +208,99 @@ func (r *TaskRunner) waitForTaskCompletion(ctx context.Context, taskArn string)
 		time.Sleep(5 * time.Second)
 	}
 
-	return fmt.Errorf("task did not complete within timeout")
+	if err := r.StopTask(context.WithoutCancel(ctx), taskArn); err != nil {
+		slog.WarnContext(ctx, "Failed to stop timed out task", "task_arn", taskArn, "error", err)
+	}
+	return fmt.Errorf("task did not complete within %s", timeout)
+}
+
+// maxTaskTailLines bounds the last lines of a task's output kept to explain its failure
+const maxTaskTailLines = 50
+
+// taskLogTail follows the log stream of a one-off task, forwarding its new lines as they are written
+type taskLogTail struct {
+	logs    *cloudwatchlogs.Client
+	input   *cloudwatchlogs.GetLogEventsInput
+	forward func(lines []string)
+	recent  []string // Last lines read, to explain a failure
+}
+
+// tailTaskLogs starts following the logs of a task, which its container writes to the log group of the
+// task definition named after the task
+func (r *TaskRunner) tailTaskLogs(taskName, taskArn string, forward func(lines []string)) *taskLogTail {
+	taskID := taskArn[strings.LastIndex(taskArn, "/")+1:]
+	return &taskLogTail{
+		logs: r.logs,
+		input: &cloudwatchlogs.GetLogEventsInput{
+			LogGroupName:  aws.String(serviceLogGroup(taskName)),
+			LogStreamName: aws.String(fmt.Sprintf("ecs/%s/%s", taskName, taskID)),
+			StartFromHead: aws.Bool(true),
+		},
+		forward: forward,
+	}
+}
+
+// read forwards the lines written since the last read. Tasks aren't failed over their logs, so errors
+// reading them are only logged.
+func (t *taskLogTail) read(ctx context.Context) {
+	if t.logs == nil {
+		return
+	}
+
+	for {
+		result, err := t.logs.GetLogEvents(ctx, t.input)
+		if err != nil {
+			// The stream is only created once the container writes its first line
+			var notFound *logstypes.ResourceNotFoundException
+			if !errors.As(err, &notFound) {
+				slog.WarnContext(ctx, "Failed to read task logs", "log_stream", aws.ToString(t.input.LogStreamName), "error", err)
+			}
+			return
+		}
+
+		lines := make([]string, 0, len(result.Events))
+		for _, event := range result.Events {
+			lines = append(lines, strings.TrimRight(aws.ToString(event.Message), "\r\n"))
+		}
+		if len(lines) > 0 {
+			t.recent = append(t.recent, lines...)
+			if len(t.recent) > maxTaskTailLines {
+				t.recent = t.recent[len(t.recent)-maxTaskTailLines:]
+			}
+			if t.forward != nil {
+				t.forward(lines)
+			}
+		}
+
+		// The same token is returned once the end of the stream is reached
+		next := aws.ToString(result.NextForwardToken)
+		if next == "" || next == aws.ToString(t.input.NextToken) {
+			return
+		}
+		t.input.NextToken = result.NextForwardToken
+		if len(result.Events) == 0 {
+			return
+		}
+	}
+}
+
+// failure returns the line of the task's output most likely to explain its failure: the last one mentioning
+// an error, or else its last line
+func (t *taskLogTail) failure() string {
+	for i := len(t.recent) - 1; i >= 0; i-- {
+		if strings.Contains(strings.ToLower(t.recent[i]), "error") {
+			return strings.TrimSpace(t.recent[i])
+		}
+	}
+	if len(t.recent) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(t.recent[len(t.recent)-1])
 }
 
 // StopTask stops a running task
 func (r *TaskRunner) StopTask(ctx context.Context, taskArn string) error {
-	log.Printf("[TaskRunner] Stopping task: %s", taskArn)
+	slog.InfoContext(ctx, "Stopping task", "task_arn", taskArn)
 
 	input := &ecs.StopTaskInput{
 		Cluster: aws.String(r.cluster),
@@ -172,6 +313,6 @@ func (r *TaskRunner) StopTask(ctx context.Context, taskArn string) error {
 		return fmt.Errorf("failed to stop task: %w", err)
 	}
 
-	log.Printf("[TaskRunner] Task stopped successfully")
+	slog.InfoContext(ctx, "Task stopped", "task_arn", taskArn)
 	return nil
 }
@@ -0,0 +1,60 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+)
+
+// bytesPerGB converts volume sizes chosen in GB to the bytes EFS meters
+const bytesPerGB = 1 << 30
+
+// provisionVolume provisions the project's persistent volume and returns how it is mounted into the
+// service's container, or nil if the project has no volume
+func (o *DeploymentOrchestrator) provisionVolume(
+	ctx context.Context,
+	proj *project.Project,
+	dep *deployment.Deployment,
+) (*VolumeMount, error) {
+	if !proj.HasVolume() {
+		return nil, nil
+	}
+
+	if o.efsClient == nil {
+		dep.AppendLog("❌ Persistent volume required but EFS is not available on this platform")
+		return nil, fmt.Errorf("persistent volume required but EFS client not initialized")
+	}
+
+	dep.AppendLog(fmt.Sprintf("💾 Preparing persistent volume for %s...", proj.VolumeMountPath()))
+	o.deploymentRepo.Save(ctx, dep)
+
+	volume, err := o.efsClient.EnsureProjectVolume(ctx, proj.ID().String())
+	if err != nil {
+		dep.AppendLog(fmt.Sprintf("❌ Failed to provision persistent volume: %v", err))
+		return nil, fmt.Errorf("failed to provision persistent volume: %w", err)
+	}
+
+	if volume.Created {
+		dep.AppendLog(fmt.Sprintf("✅ Created persistent volume: %s", volume.FileSystemID))
+	} else {
+		dep.AppendLog(fmt.Sprintf("💾 Using existing persistent volume: %s", volume.FileSystemID))
+	}
+
+	// EFS grows with the files written to it, so the size is a budget that is reported rather than enforced
+	if limit := int64(proj.VolumeSizeGB()) * bytesPerGB; volume.SizeBytes > limit {
+		dep.AppendLog(fmt.Sprintf("⚠️  Warning: the volume holds %.1f GB, more than its %d GB size",
+			float64(volume.SizeBytes)/bytesPerGB, proj.VolumeSizeGB()))
+	}
+	if proj.DeploymentStrategy() != project.StrategyRecreate {
+		dep.AppendLog("ℹ️  Old and new tasks share the volume while they are replaced, use the RECREATE strategy for SQLite databases")
+	}
+	o.deploymentRepo.Save(ctx, dep)
+
+	return &VolumeMount{
+		FileSystemID:  volume.FileSystemID,
+		AccessPointID: volume.AccessPointID,
+		ContainerPath: proj.VolumeMountPath(),
+	}, nil
+}
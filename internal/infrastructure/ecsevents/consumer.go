@@ -0,0 +1,212 @@
+package ecsevents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"snapdeploy-core/internal/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const (
+	receiveWaitSeconds = 20              // Long polling, so an idle queue costs one request every 20 seconds
+	receiveRetryDelay  = 5 * time.Second // Wait after failing to receive before trying again
+	subscriberBuffer   = 16              // Events kept for a subscriber that hasn't read them yet
+	visibilityTimeout  = 30              // Seconds a received message is hidden from other consumers
+	maxReceiveMessages = 10              // The most SQS returns at once
+)
+
+// Consumer receives the ECS events EventBridge sends to an SQS queue and passes them to the subscribers of
+// their services. Events nobody here waits for are dropped, so with several instances sharing a queue an
+// instance may miss the events of its services; waiters check their services themselves now and then.
+type Consumer struct {
+	client      *sqs.Client
+	queueURL    string
+	clusterName string
+
+	mu          sync.Mutex
+	subscribers map[string]map[chan Event]struct{}
+}
+
+// NewConsumer creates a consumer of the queue, passing on the events of services in the cluster
+func NewConsumer(queueURL, clusterName string) (*Consumer, error) {
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	tracing.InstrumentAWS(&cfg)
+
+	return &Consumer{
+		client:      sqs.NewFromConfig(cfg),
+		queueURL:    queueURL,
+		clusterName: clusterName,
+		subscribers: make(map[string]map[chan Event]struct{}),
+	}, nil
+}
+
+// Subscribe returns a channel receiving the events of a service from now on, and a function ending the
+// subscription. Events arriving while the channel is full are dropped.
+func (c *Consumer) Subscribe(serviceName string) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	c.mu.Lock()
+	if c.subscribers[serviceName] == nil {
+		c.subscribers[serviceName] = make(map[chan Event]struct{})
+	}
+	c.subscribers[serviceName][ch] = struct{}{}
+	c.mu.Unlock()
+
+	return ch, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.subscribers[serviceName], ch)
+		if len(c.subscribers[serviceName]) == 0 {
+			delete(c.subscribers, serviceName)
+		}
+	}
+}
+
+// Run receives events until the context is cancelled
+func (c *Consumer) Run(ctx context.Context) {
+	slog.Info("ECS event consumer started", "queue_url", c.queueURL)
+	for {
+		if err := c.receive(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("Failed to receive ECS events", "queue_url", c.queueURL, "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(receiveRetryDelay):
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// receive passes on one batch of events and deletes their messages
+func (c *Consumer) receive(ctx context.Context) error {
+	result, err := c.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(c.queueURL),
+		MaxNumberOfMessages: maxReceiveMessages,
+		VisibilityTimeout:   visibilityTimeout,
+		WaitTimeSeconds:     receiveWaitSeconds,
+	})
+	if err != nil {
+		return err
+	}
+	if len(result.Messages) == 0 {
+		return nil
+	}
+
+	entries := make([]types.DeleteMessageBatchRequestEntry, 0, len(result.Messages))
+	for i, msg := range result.Messages {
+		// Messages that can't be parsed never will be, so they're deleted too
+		event, err := parseEvent([]byte(aws.ToString(msg.Body)))
+		if err != nil {
+			slog.Warn("Dropping malformed ECS event", "message_id", aws.ToString(msg.MessageId), "error", err)
+		} else if event != nil && (event.ClusterName == "" || event.ClusterName == c.clusterName) {
+			c.dispatch(*event)
+		}
+		entries = append(entries, types.DeleteMessageBatchRequestEntry{
+			Id:            aws.String(fmt.Sprintf("%d", i)),
+			ReceiptHandle: msg.ReceiptHandle,
+		})
+	}
+
+	deleted, err := c.client.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+		QueueUrl: aws.String(c.queueURL),
+		Entries:  entries,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete ECS event messages: %w", err)
+	}
+	for _, failed := range deleted.Failed {
+		slog.Warn("Failed to delete ECS event message", "code", aws.ToString(failed.Code), "error", aws.ToString(failed.Message))
+	}
+	return nil
+}
+
+// dispatch passes an event to the subscribers of its service without waiting for them
+func (c *Consumer) dispatch(event Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for ch := range c.subscribers[event.ServiceName] {
+		select {
+		case ch <- event:
+		default:
+			slog.Warn("Dropping ECS event, subscriber is behind", "service", event.ServiceName, "event", event.Name)
+		}
+	}
+}
+
+// serviceFromARN returns the cluster and name of a service from its ARN, which only names the cluster
+// in the long ARN format
+func serviceFromARN(arn string) (cluster, service string) {
+	_, resource, ok := strings.Cut(arn, ":service/")
+	if !ok {
+		return "", ""
+	}
+	if cluster, service, ok := strings.Cut(resource, "/"); ok {
+		return cluster, service
+	}
+	return "", resource
+}
+
+// parseEvent parses an EventBridge event of an ECS service, returning nil for other events
+func parseEvent(body []byte) (*Event, error) {
+	var envelope struct {
+		Source     string    `json:"source"`
+		DetailType string    `json:"detail-type"`
+		Time       time.Time `json:"time"`
+		Resources  []string  `json:"resources"`
+		Detail     struct {
+			EventType    string   `json:"eventType"`
+			EventName    string   `json:"eventName"`
+			DeploymentID string   `json:"deploymentId"`
+			Reason       string   `json:"reason"`
+			Reasons      []string `json:"reasons"`
+		} `json:"detail"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.Source != "aws.ecs" || (envelope.DetailType != detailDeploymentStateChange && envelope.DetailType != detailServiceAction) {
+		return nil, nil
+	}
+
+	for _, resource := range envelope.Resources {
+		cluster, service := serviceFromARN(resource)
+		if service == "" {
+			continue
+		}
+
+		reason := envelope.Detail.Reason
+		if reason == "" {
+			reason = strings.Join(envelope.Detail.Reasons, "; ")
+		}
+		return &Event{
+			ClusterName:  cluster,
+			ServiceName:  service,
+			Name:         envelope.Detail.EventName,
+			Type:         envelope.Detail.EventType,
+			DeploymentID: envelope.Detail.DeploymentID,
+			Reason:       reason,
+			Time:         envelope.Time,
+		}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,80 @@
+package ecsevents
+
+import (
+	"fmt"
+	"time"
+)
+
+// Detail types of the EventBridge events ECS sends about services
+const (
+	detailDeploymentStateChange = "ECS Deployment State Change"
+	detailServiceAction         = "ECS Service Action"
+)
+
+// Names of the service events that matter to rollouts
+const (
+	DeploymentInProgress = "SERVICE_DEPLOYMENT_IN_PROGRESS"
+	DeploymentCompleted  = "SERVICE_DEPLOYMENT_COMPLETED"
+	DeploymentFailed     = "SERVICE_DEPLOYMENT_FAILED"
+	SteadyState          = "SERVICE_STEADY_STATE"
+	TaskStartImpaired    = "SERVICE_TASK_START_IMPAIRED"
+	TaskPlacementFailure = "SERVICE_TASK_PLACEMENT_FAILURE"
+	TaskConfigFailure    = "SERVICE_TASK_CONFIGURATION_FAILURE"
+	TaskSetSteadyState   = "TASKSET_STEADY_STATE"
+)
+
+// Event is a change of an ECS service's deployment or an action ECS took on the service
+type Event struct {
+	ClusterName  string // Empty for services with short ARNs
+	ServiceName  string
+	Name         string // e.g. SERVICE_DEPLOYMENT_COMPLETED
+	Type         string // INFO, WARN or ERROR
+	DeploymentID string
+	Reason       string
+	Time         time.Time
+}
+
+// Settled reports whether the event may mean the service finished its rollout, one way or the other
+func (e Event) Settled() bool {
+	switch e.Name {
+	case DeploymentCompleted, DeploymentFailed, SteadyState, TaskSetSteadyState:
+		return true
+	}
+	return false
+}
+
+// Problem reports whether ECS is failing to start the service's tasks
+func (e Event) Problem() bool {
+	switch e.Name {
+	case TaskStartImpaired, TaskPlacementFailure, TaskConfigFailure:
+		return true
+	}
+	return e.Type == "ERROR"
+}
+
+// String describes the event for deployment logs
+func (e Event) String() string {
+	var what string
+	switch e.Name {
+	case DeploymentInProgress:
+		what = "ECS is replacing the service's tasks"
+	case DeploymentCompleted:
+		what = "ECS finished the deployment"
+	case DeploymentFailed:
+		what = "ECS deployment failed"
+	case SteadyState:
+		what = "Service reached a steady state"
+	case TaskStartImpaired:
+		what = "ECS is unable to start tasks"
+	case TaskPlacementFailure:
+		what = "ECS is unable to place tasks"
+	case TaskConfigFailure:
+		what = "ECS is unable to configure tasks"
+	default:
+		what = e.Name
+	}
+	if e.Reason != "" {
+		return fmt.Sprintf("%s: %s", what, e.Reason)
+	}
+	return what
+}
@@ -0,0 +1,343 @@
+package efs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"snapdeploy-core/internal/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/efs"
+	"github.com/aws/aws-sdk-go-v2/service/efs/types"
+)
+
+const (
+	// pollInterval is how often file systems and mount targets are checked while they change state
+	pollInterval = 5 * time.Second
+	// stateTimeout is how long a file system or its mount targets may take to become available or go away
+	stateTimeout = 5 * time.Minute
+	// rootDirectory is the directory of a file system that is mounted into containers
+	rootDirectory = "/data"
+)
+
+// EFSClient wraps AWS Elastic File System operations
+type EFSClient struct {
+	client          *efs.Client
+	subnetIDs       []string
+	securityGroupID string
+}
+
+// Volume is the persistent file system of a project
+type Volume struct {
+	FileSystemID  string
+	AccessPointID string
+	SizeBytes     int64 // Metered size, EFS grows as files are written so it is not bounded
+	Created       bool  // Set when the file system was created by this call
+}
+
+// NewEFSClient creates a new EFS client creating mount targets in the subnets, with the security group
+// which must accept NFS traffic from the service's tasks
+func NewEFSClient(subnetIDs []string, securityGroupID string) (*EFSClient, error) {
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	tracing.InstrumentAWS(&cfg)
+
+	if len(subnetIDs) == 0 {
+		return nil, fmt.Errorf("SUBNET_IDS is not set")
+	}
+	if securityGroupID == "" {
+		return nil, fmt.Errorf("EFS_SECURITY_GROUP_ID or SECURITY_GROUP_ID must be set")
+	}
+
+	return &EFSClient{
+		client:          efs.NewFromConfig(cfg),
+		subnetIDs:       subnetIDs,
+		securityGroupID: securityGroupID,
+	}, nil
+}
+
+// EnsureProjectVolume creates the project's file system if needed and makes sure tasks can mount it
+// from every subnet services run in. The file system is kept across deployments.
+func (c *EFSClient) EnsureProjectVolume(ctx context.Context, projectID string) (*Volume, error) {
+	fs, err := c.findFileSystem(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	volume := &Volume{}
+	if fs == nil {
+		output, err := c.client.CreateFileSystem(ctx, &efs.CreateFileSystemInput{
+			// The creation token makes retries return the same file system
+			CreationToken:   aws.String(creationToken(projectID)),
+			Encrypted:       aws.Bool(true),
+			PerformanceMode: types.PerformanceModeGeneralPurpose,
+			ThroughputMode:  types.ThroughputModeElastic,
+			Tags:            projectTags(projectID),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create file system: %w", err)
+		}
+		slog.InfoContext(ctx, "Created EFS file system", "project_id", projectID, "file_system_id", aws.ToString(output.FileSystemId))
+
+		volume.Created = true
+		fs = &types.FileSystemDescription{FileSystemId: output.FileSystemId, LifeCycleState: output.LifeCycleState}
+	}
+	volume.FileSystemID = aws.ToString(fs.FileSystemId)
+	if fs.SizeInBytes != nil {
+		volume.SizeBytes = fs.SizeInBytes.Value
+	}
+
+	if fs.LifeCycleState != types.LifeCycleStateAvailable {
+		if err := c.waitForFileSystem(ctx, projectID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.ensureMountTargets(ctx, volume.FileSystemID); err != nil {
+		return nil, err
+	}
+
+	accessPointID, err := c.ensureAccessPoint(ctx, projectID, volume.FileSystemID)
+	if err != nil {
+		return nil, err
+	}
+	volume.AccessPointID = accessPointID
+
+	return volume, nil
+}
+
+// DeleteProjectVolume deletes the project's file system and everything stored on it
+func (c *EFSClient) DeleteProjectVolume(ctx context.Context, projectID string) error {
+	fs, err := c.findFileSystem(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if fs == nil {
+		return nil
+	}
+	fileSystemID := aws.ToString(fs.FileSystemId)
+
+	accessPoints, err := c.client.DescribeAccessPoints(ctx, &efs.DescribeAccessPointsInput{
+		FileSystemId: aws.String(fileSystemID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list access points: %w", err)
+	}
+	for _, ap := range accessPoints.AccessPoints {
+		if _, err := c.client.DeleteAccessPoint(ctx, &efs.DeleteAccessPointInput{AccessPointId: ap.AccessPointId}); err != nil {
+			return fmt.Errorf("failed to delete access point: %w", err)
+		}
+	}
+
+	mountTargets, err := c.listMountTargets(ctx, fileSystemID)
+	if err != nil {
+		return err
+	}
+	for _, mt := range mountTargets {
+		if mt.LifeCycleState == types.LifeCycleStateDeleting {
+			continue
+		}
+		if _, err := c.client.DeleteMountTarget(ctx, &efs.DeleteMountTargetInput{MountTargetId: mt.MountTargetId}); err != nil {
+			return fmt.Errorf("failed to delete mount target: %w", err)
+		}
+	}
+
+	// A file system can only be deleted once all of its mount targets are gone
+	err = c.poll(ctx, func() (bool, error) {
+		mountTargets, err := c.listMountTargets(ctx, fileSystemID)
+		return len(mountTargets) == 0, err
+	})
+	if err != nil {
+		return fmt.Errorf("mount targets of file system %s were not deleted: %w", fileSystemID, err)
+	}
+
+	_, err = c.client.DeleteFileSystem(ctx, &efs.DeleteFileSystemInput{FileSystemId: aws.String(fileSystemID)})
+	if err != nil && !isFileSystemNotFound(err) {
+		return fmt.Errorf("failed to delete file system: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Deleted EFS file system", "project_id", projectID, "file_system_id", fileSystemID)
+	return nil
+}
+
+// findFileSystem returns the project's file system, or nil if it has none
+func (c *EFSClient) findFileSystem(ctx context.Context, projectID string) (*types.FileSystemDescription, error) {
+	output, err := c.client.DescribeFileSystems(ctx, &efs.DescribeFileSystemsInput{
+		CreationToken: aws.String(creationToken(projectID)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe file system: %w", err)
+	}
+
+	for _, fs := range output.FileSystems {
+		if fs.LifeCycleState != types.LifeCycleStateDeleted {
+			return &fs, nil
+		}
+	}
+	return nil, nil
+}
+
+// waitForFileSystem waits until the project's file system can have mount targets added
+func (c *EFSClient) waitForFileSystem(ctx context.Context, projectID string) error {
+	err := c.poll(ctx, func() (bool, error) {
+		fs, err := c.findFileSystem(ctx, projectID)
+		if err != nil {
+			return false, err
+		}
+		if fs == nil || fs.LifeCycleState == types.LifeCycleStateError {
+			return false, fmt.Errorf("file system failed to create")
+		}
+		return fs.LifeCycleState == types.LifeCycleStateAvailable, nil
+	})
+	if err != nil {
+		return fmt.Errorf("file system did not become available: %w", err)
+	}
+	return nil
+}
+
+// ensureMountTargets adds a mount target to every subnet that lacks one and waits until all are available
+func (c *EFSClient) ensureMountTargets(ctx context.Context, fileSystemID string) error {
+	existing, err := c.listMountTargets(ctx, fileSystemID)
+	if err != nil {
+		return err
+	}
+	covered := make(map[string]bool, len(existing))
+	for _, mt := range existing {
+		covered[aws.ToString(mt.SubnetId)] = true
+	}
+
+	for _, subnetID := range c.subnetIDs {
+		if covered[subnetID] {
+			continue
+		}
+		_, err := c.client.CreateMountTarget(ctx, &efs.CreateMountTargetInput{
+			FileSystemId:   aws.String(fileSystemID),
+			SubnetId:       aws.String(subnetID),
+			SecurityGroups: []string{c.securityGroupID},
+		})
+		// Only one mount target can exist per availability zone, which another subnet may already hold
+		if err != nil && !isMountTargetConflict(err) {
+			return fmt.Errorf("failed to create mount target in subnet %s: %w", subnetID, err)
+		}
+	}
+
+	err = c.poll(ctx, func() (bool, error) {
+		mountTargets, err := c.listMountTargets(ctx, fileSystemID)
+		if err != nil {
+			return false, err
+		}
+		for _, mt := range mountTargets {
+			if mt.LifeCycleState != types.LifeCycleStateAvailable {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("mount targets of file system %s did not become available: %w", fileSystemID, err)
+	}
+	return nil
+}
+
+// ensureAccessPoint returns the access point containers mount the file system through, creating it if needed.
+// It roots mounts in a directory any user can write to, so images that don't run as root can use the volume.
+func (c *EFSClient) ensureAccessPoint(ctx context.Context, projectID, fileSystemID string) (string, error) {
+	output, err := c.client.DescribeAccessPoints(ctx, &efs.DescribeAccessPointsInput{
+		FileSystemId: aws.String(fileSystemID),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list access points: %w", err)
+	}
+	for _, ap := range output.AccessPoints {
+		if ap.LifeCycleState != types.LifeCycleStateDeleting && ap.LifeCycleState != types.LifeCycleStateDeleted {
+			return aws.ToString(ap.AccessPointId), nil
+		}
+	}
+
+	created, err := c.client.CreateAccessPoint(ctx, &efs.CreateAccessPointInput{
+		ClientToken:  aws.String(creationToken(projectID)),
+		FileSystemId: aws.String(fileSystemID),
+		RootDirectory: &types.RootDirectory{
+			Path: aws.String(rootDirectory),
+			CreationInfo: &types.CreationInfo{
+				OwnerUid:    aws.Int64(0),
+				OwnerGid:    aws.Int64(0),
+				Permissions: aws.String("0777"),
+			},
+		},
+		Tags: projectTags(projectID),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create access point: %w", err)
+	}
+
+	return aws.ToString(created.AccessPointId), nil
+}
+
+// listMountTargets returns the mount targets of a file system
+func (c *EFSClient) listMountTargets(ctx context.Context, fileSystemID string) ([]types.MountTargetDescription, error) {
+	output, err := c.client.DescribeMountTargets(ctx, &efs.DescribeMountTargetsInput{
+		FileSystemId: aws.String(fileSystemID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mount targets: %w", err)
+	}
+	return output.MountTargets, nil
+}
+
+// poll calls done until it reports true, fails, or the state timeout runs out
+func (c *EFSClient) poll(ctx context.Context, done func() (bool, error)) error {
+	ctx, cancel := context.WithTimeout(ctx, stateTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		ok, err := done()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// creationToken returns the idempotency token a project's file system and access point are created with
+func creationToken(projectID string) string {
+	return "snapdeploy-" + projectID
+}
+
+// projectTags returns the tags of a project's EFS resources
+func projectTags(projectID string) []types.Tag {
+	return []types.Tag{
+		{Key: aws.String("Name"), Value: aws.String(creationToken(projectID))},
+		{Key: aws.String("snapdeploy:project-id"), Value: aws.String(projectID)},
+		{Key: aws.String("snapdeploy:managed-by"), Value: aws.String("snapdeploy-core")},
+	}
+}
+
+// isMountTargetConflict checks if the error indicates a mount target already exists in the subnet's availability zone
+func isMountTargetConflict(err error) bool {
+	var conflict *types.MountTargetConflict
+	return errors.As(err, &conflict)
+}
+
+// isFileSystemNotFound checks if the error indicates the file system doesn't exist
+func isFileSystemNotFound(err error) bool {
+	var notFound *types.FileSystemNotFound
+	return errors.As(err, &notFound)
+}
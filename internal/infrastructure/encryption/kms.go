@@ -0,0 +1,201 @@
+package encryption
+
+import (
+	"context"
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"snapdeploy-core/internal/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// KMSKeyID identifies the KMS key in ciphertexts
+const KMSKeyID = "kms"
+
+// kmsTimeout bounds each call to KMS
+const kmsTimeout = 10 * time.Second
+
+// kmsEncryptionContext is bound to the data keys, so they can't be decrypted for another purpose
+var kmsEncryptionContext = map[string]string{"snapdeploy": "environment-variables"}
+
+// kmsKey encrypts values with data keys of a KMS key. The server only holds data keys in memory, each for
+// the TTL: a data key encrypts new values until it expires, and data keys decrypted to read values are cached
+// as long. Ciphertexts carry the data key encrypted by KMS, then the value it encrypted, separated by a dot.
+type kmsKey struct {
+	client *kms.Client
+	keyID  string
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	current *dataKey
+	cache   map[string]*dataKey // Data keys by their encrypted blob, base64-encoded
+}
+
+// dataKey is a data key of the KMS key
+type dataKey struct {
+	aead    cipher.AEAD
+	blob    string
+	expires time.Time
+}
+
+// NewKMSEncryptionService creates an encryption service encrypting values with data keys of a KMS key, for
+// platforms that may not hold a static encryption key. Digests are keyed with the key digestKeyBlob holds,
+// base64-encoded ciphertext of 32 bytes KMS encrypted with the same key.
+func NewKMSEncryptionService(ctx context.Context, kmsKeyID, digestKeyBlob string, dataKeyTTL time.Duration) (*EncryptionService, error) {
+	k, err := newKMSKey(ctx, kmsKeyID, dataKeyTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(digestKeyBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode digest key: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, kmsTimeout)
+	defer cancel()
+	output, err := k.client.Decrypt(ctx, &kms.DecryptInput{
+		CiphertextBlob: blob,
+		KeyId:          aws.String(kmsKeyID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt digest key: %w", err)
+	}
+	if len(output.Plaintext) != 32 {
+		return nil, fmt.Errorf("digest key must be 32 bytes (got %d bytes)", len(output.Plaintext))
+	}
+
+	return &EncryptionService{
+		keys:        map[string]key{KMSKeyID: k},
+		activeKeyID: KMSKeyID,
+		digestKey:   deriveDigestKey(output.Plaintext),
+	}, nil
+}
+
+// AddKMSKey adds a KMS key values can be encrypted and decrypted with, with the ID "kms"
+func (s *EncryptionService) AddKMSKey(ctx context.Context, kmsKeyID string, dataKeyTTL time.Duration) error {
+	if _, exists := s.keys[KMSKeyID]; exists {
+		return fmt.Errorf("encryption key %q is already configured", KMSKeyID)
+	}
+
+	k, err := newKMSKey(ctx, kmsKeyID, dataKeyTTL)
+	if err != nil {
+		return err
+	}
+	s.keys[KMSKeyID] = k
+	return nil
+}
+
+// newKMSKey creates the key of a KMS key, caching its data keys for the TTL
+func newKMSKey(ctx context.Context, kmsKeyID string, dataKeyTTL time.Duration) (*kmsKey, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	tracing.InstrumentAWS(&cfg)
+
+	return &kmsKey{
+		client: kms.NewFromConfig(cfg),
+		keyID:  kmsKeyID,
+		ttl:    dataKeyTTL,
+		cache:  make(map[string]*dataKey),
+	}, nil
+}
+
+// seal encrypts plaintext with the current data key, generating one if it has expired
+func (k *kmsKey) seal(plaintext []byte) (string, error) {
+	k.mu.Lock()
+	current := k.current
+	k.mu.Unlock()
+
+	if current == nil || time.Now().After(current.expires) {
+		ctx, cancel := context.WithTimeout(context.Background(), kmsTimeout)
+		defer cancel()
+		output, err := k.client.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+			KeyId:             aws.String(k.keyID),
+			KeySpec:           types.DataKeySpecAes256,
+			EncryptionContext: kmsEncryptionContext,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to generate data key: %w", err)
+		}
+		if current, err = k.remember(output.CiphertextBlob, output.Plaintext); err != nil {
+			return "", err
+		}
+
+		k.mu.Lock()
+		k.current = current
+		k.mu.Unlock()
+	}
+
+	encoded, err := sealAEAD(current.aead, plaintext)
+	if err != nil {
+		return "", err
+	}
+	return current.blob + "." + encoded, nil
+}
+
+// open decrypts what seal returned, asking KMS for the data key unless it's cached
+func (k *kmsKey) open(encoded string) ([]byte, error) {
+	blob, ciphertext, ok := strings.Cut(encoded, ".")
+	if !ok {
+		return nil, fmt.Errorf("ciphertext has no data key")
+	}
+
+	k.mu.Lock()
+	dk := k.cache[blob]
+	k.mu.Unlock()
+
+	if dk == nil || time.Now().After(dk.expires) {
+		encrypted, err := base64.StdEncoding.DecodeString(blob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode data key: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), kmsTimeout)
+		defer cancel()
+		output, err := k.client.Decrypt(ctx, &kms.DecryptInput{
+			CiphertextBlob:    encrypted,
+			KeyId:             aws.String(k.keyID),
+			EncryptionContext: kmsEncryptionContext,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt data key: %w", err)
+		}
+		if dk, err = k.remember(encrypted, output.Plaintext); err != nil {
+			return nil, err
+		}
+	}
+
+	return openAEAD(dk.aead, ciphertext)
+}
+
+// remember caches a data key for the TTL, dropping those that expired
+func (k *kmsKey) remember(encrypted, plaintext []byte) (*dataKey, error) {
+	aead, err := newGCM(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+	dk := &dataKey{
+		aead:    aead,
+		blob:    base64.StdEncoding.EncodeToString(encrypted),
+		expires: time.Now().Add(k.ttl),
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for blob, cached := range k.cache {
+		if time.Now().After(cached.expires) {
+			delete(k.cache, blob)
+		}
+	}
+	k.cache[dk.blob] = dk
+	return dk, nil
+}
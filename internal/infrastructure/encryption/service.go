@@ -3,51 +3,110 @@ package encryption
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
-	"os"
+	"regexp"
+	"strings"
 )
 
-// EncryptionService handles encryption and decryption of sensitive data
+// DefaultKeyID identifies ENCRYPTION_KEY. Values encrypted with it carry no key ID, as they did before
+// keys had IDs, so servers predating key rotation can still read them.
+const DefaultKeyID = "default"
+
+// keyIDPattern is what key IDs may look like; they never contain the separator of a ciphertext
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// EncryptionService handles encryption and decryption of sensitive data. Ciphertexts start with the ID of
+// the key they were encrypted with, followed by a colon, so keys can be rotated: new values are encrypted
+// with the active key and the others are kept to decrypt what they encrypted until it has been re-encrypted.
 type EncryptionService struct {
+	keys        map[string]key
+	activeKeyID string
+	digestKey   []byte
+}
+
+// key encrypts and decrypts values, encoding their ciphertexts without the key's ID
+type key interface {
+	seal(plaintext []byte) (string, error)
+	open(encoded string) ([]byte, error)
+}
+
+// localKey is an AES-256-GCM key held by the server
+type localKey struct {
 	aead cipher.AEAD
 }
 
 // NewEncryptionService creates a new encryption service
-// Uses AES-256-GCM for encryption
-func NewEncryptionService() (*EncryptionService, error) {
-	// Get encryption key from environment
-	keyBase64 := os.Getenv("ENCRYPTION_KEY")
+// Uses AES-256-GCM for encryption, with the base64-encoded key as the active key
+func NewEncryptionService(keyBase64 string) (*EncryptionService, error) {
 	if keyBase64 == "" {
-		return nil, fmt.Errorf("ENCRYPTION_KEY environment variable is required (32-byte base64-encoded key)")
+		return nil, fmt.Errorf("ENCRYPTION_KEY is required (32-byte base64-encoded key)")
 	}
 
-	// Decode base64 key
-	key, err := base64.StdEncoding.DecodeString(keyBase64)
+	raw, aead, err := newAEAD(keyBase64)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode encryption key: %w", err)
+		return nil, err
 	}
 
-	// Key must be 32 bytes for AES-256
-	if len(key) != 32 {
-		return nil, fmt.Errorf("encryption key must be 32 bytes (got %d bytes)", len(key))
+	// Digests stay keyed with ENCRYPTION_KEY when another key is active, so recorded digests keep matching
+	return &EncryptionService{
+		keys:        map[string]key{DefaultKeyID: &localKey{aead: aead}},
+		activeKeyID: DefaultKeyID,
+		digestKey:   deriveDigestKey(raw),
+	}, nil
+}
+
+// AddKey adds a base64-encoded key values can be encrypted and decrypted with
+func (s *EncryptionService) AddKey(id, keyBase64 string) error {
+	if !ValidKeyID(id) {
+		return fmt.Errorf("invalid encryption key ID %q", id)
+	}
+	if _, exists := s.keys[id]; exists {
+		return fmt.Errorf("encryption key %q is already configured", id)
 	}
 
-	// Create AES cipher
-	block, err := aes.NewCipher(key)
+	_, aead, err := newAEAD(keyBase64)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create cipher: %w", err)
+		return fmt.Errorf("encryption key %q: %w", id, err)
 	}
+	s.keys[id] = &localKey{aead: aead}
+	return nil
+}
 
-	// Create GCM mode
-	aead, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create GCM: %w", err)
+// SetActiveKey sets the key new values are encrypted with
+func (s *EncryptionService) SetActiveKey(id string) error {
+	if _, ok := s.keys[id]; !ok {
+		return fmt.Errorf("encryption key %q is not configured", id)
 	}
+	s.activeKeyID = id
+	return nil
+}
 
-	return &EncryptionService{aead: aead}, nil
+// ActiveKeyID returns the ID of the key new values are encrypted with
+func (s *EncryptionService) ActiveKeyID() string {
+	return s.activeKeyID
+}
+
+// KeyID returns the ID of the key a ciphertext was encrypted with
+func (s *EncryptionService) KeyID(ciphertext string) string {
+	if id, _, ok := strings.Cut(ciphertext, ":"); ok {
+		return id
+	}
+	return DefaultKeyID
+}
+
+// Reencrypt decrypts a ciphertext and encrypts it again with the active key
+func (s *EncryptionService) Reencrypt(ciphertext string) (string, error) {
+	plaintext, err := s.Decrypt(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return s.Encrypt(plaintext)
 }
 
 // Encrypt encrypts plaintext and returns base64-encoded ciphertext
@@ -56,46 +115,144 @@ func (s *EncryptionService) Encrypt(plaintext string) (string, error) {
 		return "", nil
 	}
 
+	encoded, err := s.keys[s.activeKeyID].seal([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	// Return after the ID of the key
+	if s.activeKeyID == DefaultKeyID {
+		return encoded, nil
+	}
+	return s.activeKeyID + ":" + encoded, nil
+}
+
+// Decrypt decrypts base64-encoded ciphertext and returns plaintext
+func (s *EncryptionService) Decrypt(ciphertext string) (string, error) {
+	if ciphertext == "" {
+		return "", nil
+	}
+
+	keyID := s.KeyID(ciphertext)
+	k, ok := s.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("encryption key %q is not configured", keyID)
+	}
+	if _, encoded, ok := strings.Cut(ciphertext, ":"); ok {
+		ciphertext = encoded
+	}
+
+	plaintext, err := k.open(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Digest returns a keyed hex digest of plaintext. Equal values have equal digests, but unlike a plain hash
+// the digest of a short secret can't be brute-forced without the encryption key.
+func (s *EncryptionService) Digest(plaintext string) string {
+	mac := hmac.New(sha256.New, s.digestKey)
+	mac.Write([]byte(plaintext))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// seal encrypts plaintext and returns it base64-encoded after its nonce
+func (k *localKey) seal(plaintext []byte) (string, error) {
+	return sealAEAD(k.aead, plaintext)
+}
+
+// open decrypts what seal returned
+func (k *localKey) open(encoded string) ([]byte, error) {
+	return openAEAD(k.aead, encoded)
+}
+
+// sealAEAD encrypts plaintext with a random nonce and returns both base64-encoded
+func sealAEAD(aead cipher.AEAD, plaintext []byte) (string, error) {
 	// Generate random nonce
-	nonce := make([]byte, s.aead.NonceSize())
+	nonce := make([]byte, aead.NonceSize())
 	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return "", fmt.Errorf("failed to generate nonce: %w", err)
 	}
 
 	// Encrypt
-	ciphertext := s.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	ciphertext := aead.Seal(nonce, nonce, plaintext, nil)
 
 	// Return base64-encoded
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
-// Decrypt decrypts base64-encoded ciphertext and returns plaintext
-func (s *EncryptionService) Decrypt(ciphertext string) (string, error) {
-	if ciphertext == "" {
-		return "", nil
-	}
-
+// openAEAD decrypts what sealAEAD returned
+func openAEAD(aead cipher.AEAD, encoded string) ([]byte, error) {
 	// Decode base64
-	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	data, err := base64.StdEncoding.DecodeString(encoded)
 	if err != nil {
-		return "", fmt.Errorf("failed to decode ciphertext: %w", err)
+		return nil, fmt.Errorf("failed to decode ciphertext: %w", err)
 	}
 
 	// Extract nonce
-	nonceSize := s.aead.NonceSize()
+	nonceSize := aead.NonceSize()
 	if len(data) < nonceSize {
-		return "", fmt.Errorf("ciphertext too short")
+		return nil, fmt.Errorf("ciphertext too short")
 	}
 
 	nonce, ciphertextBytes := data[:nonceSize], data[nonceSize:]
 
 	// Decrypt
-	plaintext, err := s.aead.Open(nil, nonce, ciphertextBytes, nil)
+	plaintext, err := aead.Open(nil, nonce, ciphertextBytes, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to decrypt: %w", err)
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
 	}
+	return plaintext, nil
+}
 
-	return string(plaintext), nil
+// deriveDigestKey derives the key digests are keyed with from an encryption key, so the encryption key
+// itself is only ever used by AES
+func deriveDigestKey(key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("snapdeploy digest"))
+	return mac.Sum(nil)
+}
+
+// ValidKeyID reports whether an encryption key ID may be used
+func ValidKeyID(id string) bool {
+	return keyIDPattern.MatchString(id)
+}
+
+// newAEAD decodes a base64-encoded 32-byte key and creates its AES-256-GCM cipher
+func newAEAD(keyBase64 string) ([]byte, cipher.AEAD, error) {
+	// Decode base64 key
+	raw, err := base64.StdEncoding.DecodeString(keyBase64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode encryption key: %w", err)
+	}
+
+	aead, err := newGCM(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	return raw, aead, nil
+}
+
+// newGCM creates the AES-256-GCM cipher of a 32-byte key
+func newGCM(raw []byte) (cipher.AEAD, error) {
+	// Key must be 32 bytes for AES-256
+	if len(raw) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes (got %d bytes)", len(raw))
+	}
+
+	// Create AES cipher
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	// Create GCM mode
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return aead, nil
 }
 
 // GenerateKey generates a new 32-byte encryption key and returns it as base64
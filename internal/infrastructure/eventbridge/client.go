@@ -0,0 +1,181 @@
+package eventbridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"snapdeploy-core/internal/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+)
+
+// targetID identifies the ECS task target of a schedule's rule, which has no other targets
+const targetID = "task"
+
+// SchedulerClient wraps Amazon EventBridge operations used to run ECS tasks on a schedule
+type SchedulerClient struct {
+	client  *eventbridge.Client
+	roleArn string
+}
+
+// ScheduleRequest describes an ECS task to run on a schedule
+type ScheduleRequest struct {
+	RuleName          string
+	Schedule          string // EventBridge schedule expression, e.g. rate(1 hour)
+	Description       string
+	ClusterArn        string
+	TaskDefinitionArn string
+	SubnetIDs         []string
+	SecurityGroupID   string
+	Tags              map[string]string // Added to every task the schedule starts
+}
+
+// NewSchedulerClient creates a new EventBridge client starting tasks with the role, which must be allowed
+// to run them and pass their task and execution roles
+func NewSchedulerClient(roleArn string) (*SchedulerClient, error) {
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	tracing.InstrumentAWS(&cfg)
+
+	if roleArn == "" {
+		return nil, fmt.Errorf("SCHEDULED_TASK_ROLE_ARN is not set")
+	}
+
+	return &SchedulerClient{
+		client:  eventbridge.NewFromConfig(cfg),
+		roleArn: roleArn,
+	}, nil
+}
+
+// PutSchedule creates or updates a rule that runs a task on a schedule. Updating the rule of a
+// schedule replaces its task definition, so runs in progress finish on the previous version.
+func (c *SchedulerClient) PutSchedule(ctx context.Context, req ScheduleRequest) error {
+	_, err := c.client.PutRule(ctx, &eventbridge.PutRuleInput{
+		Name:               aws.String(req.RuleName),
+		Description:        aws.String(req.Description),
+		ScheduleExpression: aws.String(req.Schedule),
+		State:              types.RuleStateEnabled,
+		Tags: []types.Tag{
+			{Key: aws.String("ManagedBy"), Value: aws.String("SnapDeploy")},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put rule: %w", err)
+	}
+
+	tags := make([]types.Tag, 0, len(req.Tags))
+	for key, value := range req.Tags {
+		tags = append(tags, types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+
+	result, err := c.client.PutTargets(ctx, &eventbridge.PutTargetsInput{
+		Rule: aws.String(req.RuleName),
+		Targets: []types.Target{
+			{
+				Id:      aws.String(targetID),
+				Arn:     aws.String(req.ClusterArn),
+				RoleArn: aws.String(c.roleArn),
+				EcsParameters: &types.EcsParameters{
+					TaskDefinitionArn: aws.String(req.TaskDefinitionArn),
+					TaskCount:         aws.Int32(1),
+					LaunchType:        types.LaunchTypeFargate,
+					NetworkConfiguration: &types.NetworkConfiguration{
+						AwsvpcConfiguration: &types.AwsVpcConfiguration{
+							Subnets:        req.SubnetIDs,
+							SecurityGroups: []string{req.SecurityGroupID},
+							AssignPublicIp: types.AssignPublicIpEnabled,
+						},
+					},
+					Tags:                 tags,
+					EnableECSManagedTags: true,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put rule target: %w", err)
+	}
+	if result.FailedEntryCount > 0 && len(result.FailedEntries) > 0 {
+		failed := result.FailedEntries[0]
+		return fmt.Errorf("failed to put rule target: %s: %s", aws.ToString(failed.ErrorCode), aws.ToString(failed.ErrorMessage))
+	}
+
+	slog.InfoContext(ctx, "Scheduled task", "rule", req.RuleName, "schedule", req.Schedule, "task_definition", req.TaskDefinitionArn)
+	return nil
+}
+
+// DeleteSchedule deletes a schedule's rule so no more tasks are started. Tasks already running are left
+// to finish. Deleting a schedule that doesn't exist is not an error.
+func (c *SchedulerClient) DeleteSchedule(ctx context.Context, ruleName string) error {
+	_, err := c.client.RemoveTargets(ctx, &eventbridge.RemoveTargetsInput{
+		Rule:  aws.String(ruleName),
+		Ids:   []string{targetID},
+		Force: true,
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to remove rule target: %w", err)
+	}
+
+	if _, err := c.client.DeleteRule(ctx, &eventbridge.DeleteRuleInput{
+		Name:  aws.String(ruleName),
+		Force: true,
+	}); err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to delete rule: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Deleted schedule", "rule", ruleName)
+	return nil
+}
+
+// SetScheduleEnabled pauses or resumes a schedule's rule without changing the task it runs. Schedules that
+// don't exist are skipped.
+func (c *SchedulerClient) SetScheduleEnabled(ctx context.Context, ruleName string, enabled bool) error {
+	var err error
+	if enabled {
+		_, err = c.client.EnableRule(ctx, &eventbridge.EnableRuleInput{Name: aws.String(ruleName)})
+	} else {
+		_, err = c.client.DisableRule(ctx, &eventbridge.DisableRuleInput{Name: aws.String(ruleName)})
+	}
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to update rule state: %w", err)
+	}
+
+	return nil
+}
+
+// ListScheduleNames returns the names of the rules of schedules whose names start with a prefix
+func (c *SchedulerClient) ListScheduleNames(ctx context.Context, prefix string) ([]string, error) {
+	input := &eventbridge.ListRulesInput{NamePrefix: aws.String(prefix)}
+
+	var names []string
+	for {
+		result, err := c.client.ListRules(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list rules: %w", err)
+		}
+		for _, rule := range result.Rules {
+			names = append(names, aws.ToString(rule.Name))
+		}
+
+		if result.NextToken == nil {
+			return names, nil
+		}
+		input.NextToken = result.NextToken
+	}
+}
+
+// isNotFound checks if the error indicates the rule doesn't exist
+func isNotFound(err error) bool {
+	var notFound *types.ResourceNotFoundException
+	return errors.As(err, &notFound)
+}
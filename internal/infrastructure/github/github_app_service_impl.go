@@ -0,0 +1,85 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/github"
+)
+
+// GitHubAppServiceImpl implements the domain repo.GitHubAppService interface
+type GitHubAppServiceImpl struct {
+	app *github.App
+}
+
+// NewGitHubAppService creates a new GitHub App service implementation
+func NewGitHubAppService(app *github.App) repo.GitHubAppService {
+	return &GitHubAppServiceImpl{app: app}
+}
+
+// InstallURL returns the page where users install the app
+func (g *GitHubAppServiceImpl) InstallURL() string {
+	return g.app.InstallURL()
+}
+
+// GetInstallation fetches an installation from GitHub as a new, unclaimed Installation
+func (g *GitHubAppServiceImpl) GetInstallation(ctx context.Context, installationID int64) (*repo.Installation, error) {
+	installation, err := g.app.GetInstallation(ctx, installationID)
+	if err != nil {
+		if errors.Is(err, github.ErrNotFound) {
+			return nil, repo.ErrInstallationNotFound(installationID)
+		}
+		return nil, fmt.Errorf("failed to fetch installation from GitHub: %w", err)
+	}
+
+	domainInstallation, err := repo.NewInstallation(
+		installation.ID,
+		installation.Account.ID,
+		installation.Account.Login,
+		installation.Account.Type,
+		0, // The installer is only reported by the installation webhook
+	)
+	if err != nil {
+		return nil, err
+	}
+	if installation.SuspendedAt != nil {
+		domainInstallation.Suspend()
+	}
+
+	return domainInstallation, nil
+}
+
+// FetchInstallationRepositories fetches the repositories an installation has access to
+func (g *GitHubAppServiceImpl) FetchInstallationRepositories(ctx context.Context, installationID int64) ([]*repo.RemoteRepository, error) {
+	githubRepos, err := g.app.ListInstallationRepositories(ctx, installationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repositories from GitHub: %w", err)
+	}
+
+	return toDomainRepositories(githubRepos), nil
+}
+
+// FindRepositoryInstallation returns the ID of the installation with access to a repository, or 0 if there is none
+func (g *GitHubAppServiceImpl) FindRepositoryInstallation(ctx context.Context, owner, name string) (int64, error) {
+	installation, err := g.app.GetRepositoryInstallation(ctx, owner, name)
+	if err != nil {
+		if errors.Is(err, github.ErrNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to find repository installation: %w", err)
+	}
+
+	return installation.ID, nil
+}
+
+// CreateCloneToken creates a short-lived token that can only read the contents of one repository
+func (g *GitHubAppServiceImpl) CreateCloneToken(ctx context.Context, installationID int64, repositoryName string) (string, error) {
+	token, err := g.app.CreateInstallationToken(ctx, installationID, []string{repositoryName}, github.ClonePermissions)
+	if err != nil {
+		return "", err
+	}
+
+	return token.Token, nil
+}
@@ -0,0 +1,196 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/github"
+)
+
+// GitHubProviderImpl implements the domain repo.GitProvider interface for GitHub
+type GitHubProviderImpl struct {
+	client *github.Client
+}
+
+// NewGitHubProvider creates a new GitHub provider implementation
+func NewGitHubProvider(client *github.Client) repo.GitProvider {
+	return &GitHubProviderImpl{client: client}
+}
+
+// Provider returns repo.ProviderGitHub
+func (g *GitHubProviderImpl) Provider() repo.Provider {
+	return repo.ProviderGitHub
+}
+
+// FetchUserRepositories fetches all repositories for a user from GitHub
+func (g *GitHubProviderImpl) FetchUserRepositories(ctx context.Context, accessToken string) ([]*repo.RemoteRepository, error) {
+	list, err := g.client.ListUserRepositories(ctx, accessToken, time.Time{}, "")
+	if err != nil {
+		return nil, providerError(err, "failed to fetch repositories from GitHub")
+	}
+
+	return toDomainRepositories(list.Repositories), nil
+}
+
+// FetchUserRepositoriesSince fetches the repositories of a user updated on GitHub after since, or all of them if it is zero
+func (g *GitHubProviderImpl) FetchUserRepositoriesSince(ctx context.Context, accessToken string, since time.Time, etag string) (*repo.RepositoryChanges, error) {
+	list, err := g.client.ListUserRepositories(ctx, accessToken, since, etag)
+	if err != nil {
+		return nil, providerError(err, "failed to fetch repositories from GitHub")
+	}
+
+	return &repo.RepositoryChanges{
+		Repositories: toDomainRepositories(list.Repositories),
+		ETag:         list.ETag,
+		NotModified:  list.NotModified,
+	}, nil
+}
+
+// FetchBranches fetches the branch names of a GitHub repository
+func (g *GitHubProviderImpl) FetchBranches(ctx context.Context, accessToken, fullName string) ([]string, error) {
+	owner, name, err := github.ParseRepositoryFullName("https://github.com/" + fullName)
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := g.client.GetBranches(ctx, accessToken, owner, name)
+	if err != nil {
+		return nil, providerError(err, "failed to fetch branches from GitHub")
+	}
+
+	names := make([]string, len(branches))
+	for i, branch := range branches {
+		names[i] = branch.Name
+	}
+	return names, nil
+}
+
+// FetchCommits fetches the most recent commits on a branch of a GitHub repository
+func (g *GitHubProviderImpl) FetchCommits(ctx context.Context, accessToken, fullName, branch string, limit int) ([]*repo.Commit, error) {
+	owner, name, err := github.ParseRepositoryFullName("https://github.com/" + fullName)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := g.client.GetCommits(ctx, accessToken, owner, name, branch, limit)
+	if err != nil {
+		return nil, providerError(err, "failed to fetch commits from GitHub")
+	}
+
+	domainCommits := make([]*repo.Commit, len(commits))
+	for i, commit := range commits {
+		domainCommits[i] = &repo.Commit{
+			SHA:         commit.SHA,
+			Message:     commit.Commit.Message,
+			AuthorName:  commit.Commit.Author.Name,
+			CommittedAt: commit.Commit.Author.Date,
+			HTMLURL:     commit.HTMLURL,
+		}
+	}
+	return domainCommits, nil
+}
+
+// FetchFile fetches a file of a GitHub repository at a ref
+func (g *GitHubProviderImpl) FetchFile(ctx context.Context, accessToken, repositoryURL, ref, path string) ([]byte, error) {
+	owner, name, err := github.ParseRepositoryFullName(repositoryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := g.client.GetFileContent(ctx, accessToken, owner, name, ref, path)
+	if errors.Is(err, github.ErrNotFound) {
+		return nil, repo.ErrFileNotFound
+	}
+	if err != nil {
+		return nil, providerError(err, fmt.Sprintf("failed to fetch %s from GitHub", path))
+	}
+	return content, nil
+}
+
+// CompareCommits fetches the commits between two commits of a GitHub repository
+func (g *GitHubProviderImpl) CompareCommits(ctx context.Context, accessToken, repositoryURL, base, head string) (*repo.CommitComparison, error) {
+	owner, name, err := github.ParseRepositoryFullName(repositoryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison, err := g.client.CompareCommits(ctx, accessToken, owner, name, base, head)
+	if err != nil {
+		return nil, providerError(err, "failed to compare commits on GitHub")
+	}
+
+	// GitHub lists the commits oldest first
+	commits := make([]*repo.Commit, len(comparison.Commits))
+	for i, commit := range comparison.Commits {
+		commits[len(commits)-1-i] = &repo.Commit{
+			SHA:         commit.SHA,
+			Message:     commit.Commit.Message,
+			AuthorName:  commit.Commit.Author.Name,
+			CommittedAt: commit.Commit.Author.Date,
+			HTMLURL:     commit.HTMLURL,
+		}
+	}
+	return &repo.CommitComparison{
+		Commits:   commits,
+		Truncated: comparison.TotalCommits > len(commits),
+		HTMLURL:   comparison.HTMLURL,
+	}, nil
+}
+
+// CloneCredentials returns the credentials for cloning a repository with an OAuth, installation or personal access token
+func (g *GitHubProviderImpl) CloneCredentials(repositoryURL, accessToken string) (*repo.CloneCredentials, error) {
+	owner, name, err := github.ParseRepositoryFullName(repositoryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	cloneURL := &url.URL{
+		Scheme: "https",
+		Host:   "github.com",
+		Path:   fmt.Sprintf("/%s/%s.git", owner, name),
+	}
+	return &repo.CloneCredentials{
+		URL:      cloneURL.String(),
+		Username: "x-access-token",
+		Token:    accessToken,
+	}, nil
+}
+
+// providerError wraps an error of the GitHub client, reporting requests refused by the token's exhausted
+// rate limit as repo.ErrProviderRateLimited
+func providerError(err error, message string) error {
+	var apiErr *github.APIError
+	if errors.As(err, &apiErr) && apiErr.RateLimited() {
+		return repo.ErrProviderRateLimited(repo.ProviderGitHub, apiErr.RetryAt(time.Now()), err)
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// toDomainRepositories converts GitHub API repositories to domain remote repositories
+func toDomainRepositories(githubRepos []github.Repository) []*repo.RemoteRepository {
+	domainRepos := make([]*repo.RemoteRepository, len(githubRepos))
+	for i, ghRepo := range githubRepos {
+		domainRepos[i] = &repo.RemoteRepository{
+			ID:              strconv.FormatInt(ghRepo.ID, 10),
+			Name:            ghRepo.Name,
+			FullName:        ghRepo.FullName,
+			Description:     ghRepo.Description,
+			URL:             ghRepo.URL,
+			HTMLURL:         ghRepo.HTMLURL,
+			Private:         ghRepo.Private,
+			Fork:            ghRepo.Fork,
+			StargazersCount: ghRepo.StargazersCount,
+			WatchersCount:   ghRepo.WatchersCount,
+			ForksCount:      ghRepo.ForksCount,
+			DefaultBranch:   ghRepo.DefaultBranch,
+			Language:        ghRepo.Language,
+		}
+	}
+
+	return domainRepos
+}
@@ -0,0 +1,37 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"snapdeploy-core/internal/github"
+)
+
+// TemplateCopierImpl copies template repositories into users' GitHub accounts
+type TemplateCopierImpl struct {
+	client *github.Client
+}
+
+// NewTemplateCopier creates a new template copier using the GitHub client
+func NewTemplateCopier(client *github.Client) *TemplateCopierImpl {
+	return &TemplateCopierImpl{client: client}
+}
+
+// CopyTemplateRepository creates a repository named name in the account of the access token's user with the files
+// of the template repository, returning its URL. The template repository must be marked as a template on GitHub.
+func (t *TemplateCopierImpl) CopyTemplateRepository(ctx context.Context, accessToken, templateURL, name string, private bool) (string, error) {
+	owner, repoName, err := github.ParseRepositoryFullName(templateURL)
+	if err != nil {
+		return "", err
+	}
+
+	created, err := t.client.GenerateRepository(ctx, accessToken, owner, repoName, &github.GenerateRepositoryRequest{
+		Name:    name,
+		Private: private,
+	})
+	if err != nil {
+		return "", providerError(err, fmt.Sprintf("failed to copy template repository %s/%s", owner, repoName))
+	}
+
+	return created.HTMLURL, nil
+}
@@ -0,0 +1,156 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/gitlab"
+)
+
+// GitLabProviderImpl implements the domain repo.GitProvider interface for GitLab
+type GitLabProviderImpl struct {
+	client *gitlab.Client
+}
+
+// NewGitLabProvider creates a new GitLab provider implementation
+func NewGitLabProvider(client *gitlab.Client) repo.GitProvider {
+	return &GitLabProviderImpl{client: client}
+}
+
+// Provider returns repo.ProviderGitLab
+func (g *GitLabProviderImpl) Provider() repo.Provider {
+	return repo.ProviderGitLab
+}
+
+// FetchUserRepositories fetches the projects a user is a member of from GitLab
+func (g *GitLabProviderImpl) FetchUserRepositories(ctx context.Context, accessToken string) ([]*repo.RemoteRepository, error) {
+	projects, err := g.client.GetUserProjects(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repositories from GitLab: %w", err)
+	}
+
+	domainRepos := make([]*repo.RemoteRepository, len(projects))
+	for i, project := range projects {
+		domainRepos[i] = &repo.RemoteRepository{
+			ID:              strconv.FormatInt(project.ID, 10),
+			Name:            project.Name,
+			FullName:        project.PathWithNamespace,
+			Description:     project.Description,
+			URL:             project.WebURL,
+			HTMLURL:         project.WebURL,
+			Private:         project.IsPrivate(),
+			Fork:            project.IsFork(),
+			StargazersCount: project.StarCount,
+			ForksCount:      project.ForksCount,
+			DefaultBranch:   project.DefaultBranch,
+		}
+	}
+
+	return domainRepos, nil
+}
+
+// FetchBranches fetches the branch names of a GitLab project
+func (g *GitLabProviderImpl) FetchBranches(ctx context.Context, accessToken, fullName string) ([]string, error) {
+	branches, err := g.client.GetBranches(ctx, accessToken, fullName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch branches from GitLab: %w", err)
+	}
+
+	names := make([]string, len(branches))
+	for i, branch := range branches {
+		names[i] = branch.Name
+	}
+	return names, nil
+}
+
+// FetchCommits fetches the most recent commits on a branch of a GitLab project
+func (g *GitLabProviderImpl) FetchCommits(ctx context.Context, accessToken, fullName, branch string, limit int) ([]*repo.Commit, error) {
+	commits, err := g.client.GetCommits(ctx, accessToken, fullName, branch, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch commits from GitLab: %w", err)
+	}
+
+	domainCommits := make([]*repo.Commit, len(commits))
+	for i, commit := range commits {
+		domainCommits[i] = &repo.Commit{
+			SHA:         commit.ID,
+			Message:     commit.Message,
+			AuthorName:  commit.AuthorName,
+			CommittedAt: commit.CommittedDate,
+			HTMLURL:     commit.WebURL,
+		}
+	}
+	return domainCommits, nil
+}
+
+// FetchFile fetches a file of a GitLab project at a ref
+func (g *GitLabProviderImpl) FetchFile(ctx context.Context, accessToken, repositoryURL, ref, path string) ([]byte, error) {
+	projectPath, err := gitlab.ParseProjectPath(repositoryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := g.client.GetFileContent(ctx, accessToken, projectPath, ref, path)
+	if errors.Is(err, gitlab.ErrNotFound) {
+		return nil, repo.ErrFileNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s from GitLab: %w", path, err)
+	}
+	return content, nil
+}
+
+// CompareCommits fetches the commits between two commits of a GitLab project
+func (g *GitLabProviderImpl) CompareCommits(ctx context.Context, accessToken, repositoryURL, base, head string) (*repo.CommitComparison, error) {
+	projectPath, err := gitlab.ParseProjectPath(repositoryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison, err := g.client.CompareCommits(ctx, accessToken, projectPath, base, head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare commits on GitLab: %w", err)
+	}
+
+	commits := make([]*repo.Commit, len(comparison.Commits))
+	for i, commit := range comparison.Commits {
+		commits[i] = &repo.Commit{
+			SHA:         commit.ID,
+			Message:     commit.Message,
+			AuthorName:  commit.AuthorName,
+			CommittedAt: commit.CommittedDate,
+			HTMLURL:     commit.WebURL,
+		}
+	}
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].CommittedAt.After(commits[j].CommittedAt)
+	})
+	return &repo.CommitComparison{
+		Commits: commits,
+		HTMLURL: comparison.WebURL,
+	}, nil
+}
+
+// CloneCredentials returns the credentials for cloning a repository with an OAuth token
+func (g *GitLabProviderImpl) CloneCredentials(repositoryURL, accessToken string) (*repo.CloneCredentials, error) {
+	path, err := gitlab.ParseProjectPath(repositoryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	cloneURL := &url.URL{
+		Scheme: "https",
+		Host:   gitlab.Host,
+		Path:   "/" + path + ".git",
+	}
+	return &repo.CloneCredentials{
+		URL:      cloneURL.String(),
+		Username: "oauth2",
+		Token:    accessToken,
+	}, nil
+}
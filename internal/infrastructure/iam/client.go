@@ -0,0 +1,204 @@
+package iam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"snapdeploy-core/internal/infrastructure/awsretry"
+	"snapdeploy-core/internal/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+const (
+	// projectRolePath is the path the roles of projects are created under, so the platform's policies can
+	// allow passing them with a single resource pattern
+	projectRolePath = "/snapdeploy-projects/"
+	// validationTTL is how long a role found to exist is trusted to before it is checked again
+	validationTTL = 10 * time.Minute
+	// taskTrustPolicy lets ECS tasks assume a role
+	taskTrustPolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ecs-tasks.amazonaws.com"},"Action":"sts:AssumeRole"}]}`
+)
+
+// ErrRoleNotFound is returned when a role tasks are meant to run with doesn't exist
+var ErrRoleNotFound = errors.New("IAM role does not exist")
+
+// RoleClient wraps the AWS IAM operations on the roles tasks run with
+type RoleClient struct {
+	client         *iam.Client
+	policyARNs     []string // Attached to the roles of projects
+	boundaryARN    string   // Permissions boundary of the roles of projects, empty for none
+	validatedMu    sync.Mutex
+	validatedUntil map[string]time.Time
+}
+
+// NewRoleClient creates a new IAM client. The roles it creates for projects get the policies attached and,
+// if boundaryARN is set, the permissions boundary.
+func NewRoleClient(policyARNs []string, boundaryARN string) (*RoleClient, error) {
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	tracing.InstrumentAWS(&cfg)
+	awsretry.Configure(&cfg, "IAM")
+
+	return &RoleClient{
+		client:         iam.NewFromConfig(cfg),
+		policyARNs:     policyARNs,
+		boundaryARN:    boundaryARN,
+		validatedUntil: make(map[string]time.Time),
+	}, nil
+}
+
+// ValidateRole checks that a role exists, returning an error wrapping ErrRoleNotFound that names it if it
+// doesn't. Roles that can't be checked, such as when the platform may not read them, are assumed to exist.
+func (c *RoleClient) ValidateRole(ctx context.Context, roleArn string) error {
+	c.validatedMu.Lock()
+	until, ok := c.validatedUntil[roleArn]
+	c.validatedMu.Unlock()
+	if ok && time.Now().Before(until) {
+		return nil
+	}
+
+	_, err := c.client.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(roleName(roleArn))})
+	if isNoSuchEntity(err) {
+		return fmt.Errorf("%w: %s", ErrRoleNotFound, roleArn)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "Could not check IAM role", "role_arn", roleArn, "error", err)
+		return nil
+	}
+
+	c.validatedMu.Lock()
+	c.validatedUntil[roleArn] = time.Now().Add(validationTTL)
+	c.validatedMu.Unlock()
+	return nil
+}
+
+// EnsureProjectRole creates the role the project's tasks run with if needed, tagged with the project, and
+// attaches the configured policies to it. Returns the role's ARN.
+func (c *RoleClient) EnsureProjectRole(ctx context.Context, projectID string) (string, error) {
+	name := projectRoleName(projectID)
+
+	var role *types.Role
+	existing, err := c.client.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(name)})
+	switch {
+	case err == nil:
+		role = existing.Role
+	case isNoSuchEntity(err):
+		input := &iam.CreateRoleInput{
+			RoleName:                 aws.String(name),
+			Path:                     aws.String(projectRolePath),
+			AssumeRolePolicyDocument: aws.String(taskTrustPolicy),
+			Description:              aws.String(fmt.Sprintf("Tasks of SnapDeploy project %s", projectID)),
+			Tags:                     projectTags(projectID),
+		}
+		if c.boundaryARN != "" {
+			input.PermissionsBoundary = aws.String(c.boundaryARN)
+		}
+		created, err := c.client.CreateRole(ctx, input)
+		switch {
+		case isEntityAlreadyExists(err):
+			// Created by a concurrent deployment
+			existing, err := c.client.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(name)})
+			if err != nil {
+				return "", fmt.Errorf("failed to get role %s: %w", name, err)
+			}
+			role = existing.Role
+		case err != nil:
+			return "", fmt.Errorf("failed to create role %s: %w", name, err)
+		default:
+			role = created.Role
+			slog.InfoContext(ctx, "Created project task role", "project_id", projectID, "role", name)
+		}
+	default:
+		return "", fmt.Errorf("failed to get role %s: %w", name, err)
+	}
+
+	// Attaching is idempotent, so policies added to the configuration reach existing roles too
+	for _, policyARN := range c.policyARNs {
+		_, err := c.client.AttachRolePolicy(ctx, &iam.AttachRolePolicyInput{
+			RoleName:  aws.String(name),
+			PolicyArn: aws.String(policyARN),
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to attach policy %s to role %s: %w", policyARN, name, err)
+		}
+	}
+
+	return aws.ToString(role.Arn), nil
+}
+
+// DeleteProjectRole deletes the role of a project's tasks, if it has one
+func (c *RoleClient) DeleteProjectRole(ctx context.Context, projectID string) error {
+	name := projectRoleName(projectID)
+
+	// A role can only be deleted once no policy is attached to it
+	paginator := iam.NewListAttachedRolePoliciesPaginator(c.client, &iam.ListAttachedRolePoliciesInput{
+		RoleName: aws.String(name),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if isNoSuchEntity(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list policies of role %s: %w", name, err)
+		}
+		for _, policy := range page.AttachedPolicies {
+			_, err := c.client.DetachRolePolicy(ctx, &iam.DetachRolePolicyInput{
+				RoleName:  aws.String(name),
+				PolicyArn: policy.PolicyArn,
+			})
+			if err != nil && !isNoSuchEntity(err) {
+				return fmt.Errorf("failed to detach policy from role %s: %w", name, err)
+			}
+		}
+	}
+
+	_, err := c.client.DeleteRole(ctx, &iam.DeleteRoleInput{RoleName: aws.String(name)})
+	if err != nil && !isNoSuchEntity(err) {
+		return fmt.Errorf("failed to delete role %s: %w", name, err)
+	}
+
+	slog.InfoContext(ctx, "Deleted project task role", "project_id", projectID, "role", name)
+	return nil
+}
+
+// projectRoleName returns the name of the role a project's tasks run with
+func projectRoleName(projectID string) string {
+	return "snapdeploy-task-" + projectID
+}
+
+// roleName returns the name of a role from its ARN, whose resource is the role's path and name
+func roleName(roleArn string) string {
+	return roleArn[strings.LastIndex(roleArn, "/")+1:]
+}
+
+// projectTags returns the tags of a project's role
+func projectTags(projectID string) []types.Tag {
+	return []types.Tag{
+		{Key: aws.String("snapdeploy:project-id"), Value: aws.String(projectID)},
+		{Key: aws.String("snapdeploy:managed-by"), Value: aws.String("snapdeploy-core")},
+	}
+}
+
+// isNoSuchEntity checks if the error indicates the role doesn't exist
+func isNoSuchEntity(err error) bool {
+	var notFound *types.NoSuchEntityException
+	return errors.As(err, &notFound)
+}
+
+// isEntityAlreadyExists checks if the error indicates the role was already created
+func isEntityAlreadyExists(err error) bool {
+	var exists *types.EntityAlreadyExistsException
+	return errors.As(err, &exists)
+}
@@ -0,0 +1,495 @@
+package lambda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"snapdeploy-core/internal/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	apitypes "github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+const (
+	// AliasName is the alias requests are routed through. It points at the version being served and, while a
+	// canary bakes, sends a share of the requests to the new version.
+	AliasName = "live"
+
+	// MaxMemory is the most memory, in MiB, a function can have
+	MaxMemory = 10240
+
+	// functionTimeout is how long, in seconds, a request may run. API Gateway gives up after 30 seconds.
+	functionTimeout = 30
+
+	// functionUpdateTimeout is how long a function may take to apply a new image or configuration
+	functionUpdateTimeout = 5 * time.Minute
+
+	// functionTag names the function an API or domain name routes requests to, so they are found again
+	functionTag = "snapdeploy:function"
+)
+
+// LambdaClient wraps the Lambda and API Gateway operations used to run LAMBDA projects. Each environment of a
+// project has its own function, served through its live alias by an HTTP API on the project's subdomain or
+// by the function's URL.
+type LambdaClient struct {
+	client         *lambda.Client
+	apis           *apigatewayv2.Client
+	region         string
+	executionRole  string
+	certificateArn string
+}
+
+// NewLambdaClient creates a new Lambda client whose functions run with the execution role. certificateArn is
+// optional, API Gateway endpoints are unavailable without it.
+func NewLambdaClient(executionRole, certificateArn string) (*LambdaClient, error) {
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	tracing.InstrumentAWS(&cfg)
+
+	if executionRole == "" {
+		return nil, fmt.Errorf("LAMBDA_EXECUTION_ROLE_ARN must be set")
+	}
+
+	return &LambdaClient{
+		client:         lambda.NewFromConfig(cfg),
+		apis:           apigatewayv2.NewFromConfig(cfg),
+		region:         cfg.Region,
+		executionRole:  executionRole,
+		certificateArn: certificateArn,
+	}, nil
+}
+
+// FunctionRequest contains the information needed to publish a version of a function
+type FunctionRequest struct {
+	Name      string
+	ImageURI  string
+	ProjectID string
+	MemoryMB  int32
+	EnvVars   map[string]string
+
+	// SubnetIDs and SecurityGroupID attach the function to the VPC, which it needs to reach a database.
+	// Without them the function runs outside of it.
+	SubnetIDs       []string
+	SecurityGroupID string
+}
+
+// PublishFunction creates a function running an image, or updates the image and configuration of an
+// existing one, and publishes the result as a new version. Requests keep going to the version the alias
+// points at until it is routed to the new one.
+func (c *LambdaClient) PublishFunction(ctx context.Context, req FunctionRequest) (string, error) {
+	environment := &types.Environment{Variables: req.EnvVars}
+	vpc := &types.VpcConfig{SubnetIds: []string{}, SecurityGroupIds: []string{}}
+	if len(req.SubnetIDs) > 0 {
+		vpc = &types.VpcConfig{SubnetIds: req.SubnetIDs, SecurityGroupIds: []string{req.SecurityGroupID}}
+	}
+
+	_, err := c.client.GetFunction(ctx, &lambda.GetFunctionInput{FunctionName: aws.String(req.Name)})
+	switch {
+	case isNotFound(err):
+		if _, err := c.client.CreateFunction(ctx, &lambda.CreateFunctionInput{
+			FunctionName: aws.String(req.Name),
+			Role:         aws.String(c.executionRole),
+			PackageType:  types.PackageTypeImage,
+			Code:         &types.FunctionCode{ImageUri: aws.String(req.ImageURI)},
+			MemorySize:   aws.Int32(req.MemoryMB),
+			Timeout:      aws.Int32(functionTimeout),
+			Environment:  environment,
+			VpcConfig:    vpc,
+			Tags: map[string]string{
+				"ManagedBy": "SnapDeploy",
+				"ProjectID": req.ProjectID,
+			},
+		}); err != nil {
+			return "", fmt.Errorf("failed to create function: %w", err)
+		}
+		slog.InfoContext(ctx, "Created Lambda function", "function", req.Name)
+
+		waiter := lambda.NewFunctionActiveV2Waiter(c.client)
+		if err := waiter.Wait(ctx, &lambda.GetFunctionInput{FunctionName: aws.String(req.Name)}, functionUpdateTimeout); err != nil {
+			return "", fmt.Errorf("failed waiting for function to become active: %w", err)
+		}
+	case err != nil:
+		return "", fmt.Errorf("failed to get function: %w", err)
+	default:
+		// A function takes one update at a time, the image has to be in place before the configuration changes
+		if _, err := c.client.UpdateFunctionCode(ctx, &lambda.UpdateFunctionCodeInput{
+			FunctionName: aws.String(req.Name),
+			ImageUri:     aws.String(req.ImageURI),
+		}); err != nil {
+			return "", fmt.Errorf("failed to update function image: %w", err)
+		}
+		if err := c.waitUpdated(ctx, req.Name); err != nil {
+			return "", err
+		}
+
+		if _, err := c.client.UpdateFunctionConfiguration(ctx, &lambda.UpdateFunctionConfigurationInput{
+			FunctionName: aws.String(req.Name),
+			Role:         aws.String(c.executionRole),
+			MemorySize:   aws.Int32(req.MemoryMB),
+			Timeout:      aws.Int32(functionTimeout),
+			Environment:  environment,
+			VpcConfig:    vpc,
+		}); err != nil {
+			return "", fmt.Errorf("failed to update function configuration: %w", err)
+		}
+		if err := c.waitUpdated(ctx, req.Name); err != nil {
+			return "", err
+		}
+	}
+
+	// A function stopped by StopFunction takes requests again once it is deployed
+	if _, err := c.client.DeleteFunctionConcurrency(ctx, &lambda.DeleteFunctionConcurrencyInput{
+		FunctionName: aws.String(req.Name),
+	}); err != nil && !isNotFound(err) {
+		return "", fmt.Errorf("failed to resume function: %w", err)
+	}
+
+	result, err := c.client.PublishVersion(ctx, &lambda.PublishVersionInput{FunctionName: aws.String(req.Name)})
+	if err != nil {
+		return "", fmt.Errorf("failed to publish function version: %w", err)
+	}
+	return aws.ToString(result.Version), nil
+}
+
+// waitUpdated waits for the last update of a function to be applied
+func (c *LambdaClient) waitUpdated(ctx context.Context, name string) error {
+	waiter := lambda.NewFunctionUpdatedV2Waiter(c.client)
+	if err := waiter.Wait(ctx, &lambda.GetFunctionInput{FunctionName: aws.String(name)}, functionUpdateTimeout); err != nil {
+		return fmt.Errorf("failed waiting for function update: %w", err)
+	}
+	return nil
+}
+
+// LiveVersion returns the version the alias of a function serves, or "" if it has no alias yet
+func (c *LambdaClient) LiveVersion(ctx context.Context, name string) (string, error) {
+	result, err := c.client.GetAlias(ctx, &lambda.GetAliasInput{
+		FunctionName: aws.String(name),
+		Name:         aws.String(AliasName),
+	})
+	if isNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get function alias: %w", err)
+	}
+	return aws.ToString(result.FunctionVersion), nil
+}
+
+// RouteAlias points the alias of a function at a version, creating the alias if needed. With a canary
+// version, that version gets the given share of requests, between 0 and 1, and the other version the rest.
+func (c *LambdaClient) RouteAlias(ctx context.Context, name, version, canaryVersion string, canaryWeight float64) error {
+	// An empty routing config sends every request to the alias' version
+	routing := &types.AliasRoutingConfiguration{AdditionalVersionWeights: map[string]float64{}}
+	if canaryVersion != "" {
+		routing.AdditionalVersionWeights[canaryVersion] = canaryWeight
+	}
+
+	_, err := c.client.UpdateAlias(ctx, &lambda.UpdateAliasInput{
+		FunctionName:    aws.String(name),
+		Name:            aws.String(AliasName),
+		FunctionVersion: aws.String(version),
+		RoutingConfig:   routing,
+	})
+	if isNotFound(err) {
+		_, err = c.client.CreateAlias(ctx, &lambda.CreateAliasInput{
+			FunctionName:    aws.String(name),
+			Name:            aws.String(AliasName),
+			FunctionVersion: aws.String(version),
+			RoutingConfig:   routing,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to route function alias: %w", err)
+	}
+	return nil
+}
+
+// EnsureFunctionURL gives the alias of a function a public URL, and returns it
+func (c *LambdaClient) EnsureFunctionURL(ctx context.Context, name string) (string, error) {
+	existing, err := c.client.GetFunctionUrlConfig(ctx, &lambda.GetFunctionUrlConfigInput{
+		FunctionName: aws.String(name),
+		Qualifier:    aws.String(AliasName),
+	})
+	if err == nil {
+		return aws.ToString(existing.FunctionUrl), nil
+	}
+	if !isNotFound(err) {
+		return "", fmt.Errorf("failed to get function URL: %w", err)
+	}
+
+	result, err := c.client.CreateFunctionUrlConfig(ctx, &lambda.CreateFunctionUrlConfigInput{
+		FunctionName: aws.String(name),
+		Qualifier:    aws.String(AliasName),
+		AuthType:     types.FunctionUrlAuthTypeNone,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create function URL: %w", err)
+	}
+
+	// Anyone may call the URL, the app handles its own authentication
+	if err := c.allow(ctx, &lambda.AddPermissionInput{
+		FunctionName:        aws.String(name),
+		Qualifier:           aws.String(AliasName),
+		StatementId:         aws.String("function-url"),
+		Action:              aws.String("lambda:InvokeFunctionUrl"),
+		Principal:           aws.String("*"),
+		FunctionUrlAuthType: types.FunctionUrlAuthTypeNone,
+	}); err != nil {
+		return "", err
+	}
+
+	return aws.ToString(result.FunctionUrl), nil
+}
+
+// RemoveFunctionURL removes the URL of a function, if it has one
+func (c *LambdaClient) RemoveFunctionURL(ctx context.Context, name string) error {
+	_, err := c.client.DeleteFunctionUrlConfig(ctx, &lambda.DeleteFunctionUrlConfigInput{
+		FunctionName: aws.String(name),
+		Qualifier:    aws.String(AliasName),
+	})
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to delete function URL: %w", err)
+	}
+	return nil
+}
+
+// API is the HTTP API a function is served from on a domain
+type API struct {
+	ID           string
+	DomainName   string // e.g., d-abcdef1234.execute-api.us-east-1.amazonaws.com, the target of the DNS record
+	HostedZoneID string // Hosted zone of the domain name, for the DNS alias record
+}
+
+// EnsureAPI creates the HTTP API serving the alias of a function on a domain, or brings an existing one up to
+// date, e.g. after the project's domain changed. Domain names the function was served on before are removed.
+func (c *LambdaClient) EnsureAPI(ctx context.Context, name, domain string) (*API, error) {
+	if c.certificateArn == "" {
+		return nil, errors.New("API_GATEWAY_CERTIFICATE_ARN is not set, use the FUNCTION_URL endpoint instead")
+	}
+
+	function, err := c.client.GetFunction(ctx, &lambda.GetFunctionInput{FunctionName: aws.String(name)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get function: %w", err)
+	}
+	functionArn, err := arn.Parse(aws.ToString(function.Configuration.FunctionArn))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse function ARN: %w", err)
+	}
+
+	api, err := c.findAPI(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if api == nil {
+		// A quick create API routes every request to its target through an auto-deployed $default stage
+		result, err := c.apis.CreateApi(ctx, &apigatewayv2.CreateApiInput{
+			Name:         aws.String(name),
+			ProtocolType: apitypes.ProtocolTypeHttp,
+			Target:       aws.String(functionArn.String() + ":" + AliasName),
+			Tags:         map[string]string{functionTag: name, "ManagedBy": "SnapDeploy"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create API: %w", err)
+		}
+		api = &apitypes.Api{ApiId: result.ApiId, Name: result.Name}
+		slog.InfoContext(ctx, "Created HTTP API", "function", name, "api_id", aws.ToString(api.ApiId))
+	}
+
+	if err := c.allow(ctx, &lambda.AddPermissionInput{
+		FunctionName: aws.String(name),
+		Qualifier:    aws.String(AliasName),
+		StatementId:  aws.String("api-gateway"),
+		Action:       aws.String("lambda:InvokeFunction"),
+		Principal:    aws.String("apigateway.amazonaws.com"),
+		SourceArn: aws.String(fmt.Sprintf("arn:%s:execute-api:%s:%s:%s/*",
+			functionArn.Partition, c.region, functionArn.AccountID, aws.ToString(api.ApiId))),
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := c.removeDomainNames(ctx, name, domain); err != nil {
+		return nil, err
+	}
+
+	configuration, err := c.ensureDomainName(ctx, name, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings, err := c.apis.GetApiMappings(ctx, &apigatewayv2.GetApiMappingsInput{DomainName: aws.String(domain)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API mappings: %w", err)
+	}
+	if len(mappings.Items) == 0 {
+		if _, err := c.apis.CreateApiMapping(ctx, &apigatewayv2.CreateApiMappingInput{
+			DomainName: aws.String(domain),
+			ApiId:      api.ApiId,
+			Stage:      aws.String("$default"),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to map domain name to API: %w", err)
+		}
+	}
+
+	return &API{
+		ID:           aws.ToString(api.ApiId),
+		DomainName:   aws.ToString(configuration.ApiGatewayDomainName),
+		HostedZoneID: aws.ToString(configuration.HostedZoneId),
+	}, nil
+}
+
+// ensureDomainName creates the regional domain name a function is served on, unless it exists
+func (c *LambdaClient) ensureDomainName(ctx context.Context, name, domain string) (*apitypes.DomainNameConfiguration, error) {
+	existing, err := c.apis.GetDomainName(ctx, &apigatewayv2.GetDomainNameInput{DomainName: aws.String(domain)})
+	if err == nil && len(existing.DomainNameConfigurations) > 0 {
+		return &existing.DomainNameConfigurations[0], nil
+	}
+	if err != nil && !isNotFound(err) {
+		return nil, fmt.Errorf("failed to get domain name: %w", err)
+	}
+
+	result, err := c.apis.CreateDomainName(ctx, &apigatewayv2.CreateDomainNameInput{
+		DomainName: aws.String(domain),
+		DomainNameConfigurations: []apitypes.DomainNameConfiguration{
+			{
+				CertificateArn: aws.String(c.certificateArn),
+				EndpointType:   apitypes.EndpointTypeRegional,
+				SecurityPolicy: apitypes.SecurityPolicyTls12,
+			},
+		},
+		Tags: map[string]string{functionTag: name, "ManagedBy": "SnapDeploy"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create domain name: %w", err)
+	}
+	if len(result.DomainNameConfigurations) == 0 {
+		return nil, fmt.Errorf("domain name %s has no configuration", domain)
+	}
+	return &result.DomainNameConfigurations[0], nil
+}
+
+// RemoveAPI removes the HTTP API and domain names a function is served on, if it has any
+func (c *LambdaClient) RemoveAPI(ctx context.Context, name string) error {
+	if err := c.removeDomainNames(ctx, name, ""); err != nil {
+		return err
+	}
+
+	api, err := c.findAPI(ctx, name)
+	if err != nil || api == nil {
+		return err
+	}
+	if _, err := c.apis.DeleteApi(ctx, &apigatewayv2.DeleteApiInput{ApiId: api.ApiId}); err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to delete API: %w", err)
+	}
+	return nil
+}
+
+// removeDomainNames deletes the domain names a function is served on, except the one to keep
+func (c *LambdaClient) removeDomainNames(ctx context.Context, name, keep string) error {
+	var token *string
+	for {
+		page, err := c.apis.GetDomainNames(ctx, &apigatewayv2.GetDomainNamesInput{NextToken: token})
+		if err != nil {
+			return fmt.Errorf("failed to list domain names: %w", err)
+		}
+
+		for _, domainName := range page.Items {
+			domain := aws.ToString(domainName.DomainName)
+			if domainName.Tags[functionTag] != name || domain == keep {
+				continue
+			}
+			// Deleting a domain name deletes its API mappings
+			if _, err := c.apis.DeleteDomainName(ctx, &apigatewayv2.DeleteDomainNameInput{
+				DomainName: domainName.DomainName,
+			}); err != nil && !isNotFound(err) {
+				return fmt.Errorf("failed to delete domain name %s: %w", domain, err)
+			}
+			slog.InfoContext(ctx, "Deleted API domain name", "function", name, "domain", domain)
+		}
+
+		if page.NextToken == nil {
+			return nil
+		}
+		token = page.NextToken
+	}
+}
+
+// findAPI returns the HTTP API of a function, or nil if it has none
+func (c *LambdaClient) findAPI(ctx context.Context, name string) (*apitypes.Api, error) {
+	var token *string
+	for {
+		page, err := c.apis.GetApis(ctx, &apigatewayv2.GetApisInput{NextToken: token})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list APIs: %w", err)
+		}
+
+		for i := range page.Items {
+			if page.Items[i].Tags[functionTag] == name {
+				return &page.Items[i], nil
+			}
+		}
+
+		if page.NextToken == nil {
+			return nil, nil
+		}
+		token = page.NextToken
+	}
+}
+
+// StopFunction stops a function from taking requests by reserving it no concurrency. Publishing a new
+// version resumes it.
+func (c *LambdaClient) StopFunction(ctx context.Context, name string) error {
+	_, err := c.client.PutFunctionConcurrency(ctx, &lambda.PutFunctionConcurrencyInput{
+		FunctionName:                 aws.String(name),
+		ReservedConcurrentExecutions: aws.Int32(0),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to stop function: %w", err)
+	}
+	return nil
+}
+
+// DeleteFunction deletes a function along with the API and domain names it is served on. A function that
+// doesn't exist is not an error, so it is safe to call for projects that never ran on Lambda.
+// Returns whether there was a function.
+func (c *LambdaClient) DeleteFunction(ctx context.Context, name string) (bool, error) {
+	if err := c.RemoveAPI(ctx, name); err != nil {
+		return false, err
+	}
+
+	_, err := c.client.DeleteFunction(ctx, &lambda.DeleteFunctionInput{FunctionName: aws.String(name)})
+	if isNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to delete function: %w", err)
+	}
+	slog.InfoContext(ctx, "Deleted Lambda function", "function", name)
+	return true, nil
+}
+
+// allow adds a statement to the resource policy of a function, unless it already has it
+func (c *LambdaClient) allow(ctx context.Context, input *lambda.AddPermissionInput) error {
+	_, err := c.client.AddPermission(ctx, input)
+	var conflict *types.ResourceConflictException
+	if err != nil && !errors.As(err, &conflict) {
+		return fmt.Errorf("failed to grant %s to %s: %w", aws.ToString(input.Action), aws.ToString(input.Principal), err)
+	}
+	return nil
+}
+
+// isNotFound reports whether a Lambda or API Gateway call failed because the resource doesn't exist
+func isNotFound(err error) bool {
+	var functionNotFound *types.ResourceNotFoundException
+	var apiNotFound *apitypes.NotFoundException
+	return errors.As(err, &functionNotFound) || errors.As(err, &apiNotFound)
+}
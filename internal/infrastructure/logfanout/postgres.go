@@ -0,0 +1,142 @@
+package logfanout
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+
+	"snapdeploy-core/internal/database"
+)
+
+const (
+	// channel is the Postgres notification channel deployment log lines are published on
+	channel = "deployment_logs"
+	// maxPayloadBytes is the limit Postgres puts on notification payloads, less one
+	maxPayloadBytes = 7999
+	// queueSize bounds the log lines waiting to be published
+	queueSize = 4096
+	// maxReconnectDelay bounds the wait before listening again after the connection is lost
+	maxReconnectDelay = 30 * time.Second
+)
+
+// LocalDeliverer delivers log lines to the clients connected to this instance
+type LocalDeliverer interface {
+	DeliverLog(deploymentID string, logLine string)
+}
+
+// notification is the payload a log line is published as
+type notification struct {
+	DeploymentID string `json:"deployment_id"`
+	Line         string `json:"line"`
+}
+
+// PostgresFanOut shares deployment log lines between API instances with Postgres LISTEN/NOTIFY. Every line
+// published by any instance is delivered to the clients connected to each of them, so clients see a
+// deployment's logs whichever instance runs its build.
+type PostgresFanOut struct {
+	db    *database.DB
+	local LocalDeliverer
+	queue chan notification
+}
+
+// NewPostgresFanOut creates a fan-out delivering the lines of every instance to local
+func NewPostgresFanOut(db *database.DB, local LocalDeliverer) *PostgresFanOut {
+	return &PostgresFanOut{
+		db:    db,
+		local: local,
+		queue: make(chan notification, queueSize),
+	}
+}
+
+// PublishLog queues a log line to be published to every instance. It never waits: lines are dropped
+// when publishing falls too far behind, rather than holding up the build producing them.
+func (f *PostgresFanOut) PublishLog(deploymentID string, logLine string) {
+	select {
+	case f.queue <- notification{DeploymentID: deploymentID, Line: logLine}:
+	default:
+		slog.Warn("Log fan-out queue is full, dropping log line", "deployment_id", deploymentID)
+	}
+}
+
+// Run publishes queued lines and delivers the lines published by every instance until ctx is done
+func (f *PostgresFanOut) Run(ctx context.Context) {
+	go f.publish(ctx)
+	f.listen(ctx)
+}
+
+// publish sends queued lines in order. Lines that can't be published are still delivered to the clients of
+// this instance.
+func (f *PostgresFanOut) publish(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-f.queue:
+			payload, err := encode(n)
+			if err == nil {
+				err = f.db.Notify(ctx, channel, payload)
+			}
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Warn("Failed to publish log line, delivering it to local clients only", "deployment_id", n.DeploymentID, "error", err)
+				}
+				f.local.DeliverLog(n.DeploymentID, n.Line)
+			}
+		}
+	}
+}
+
+// listen delivers published lines to local clients, listening again with backoff whenever the connection
+// is lost. Lines published while it reconnects are missed, clients resuming their stream reload them.
+func (f *PostgresFanOut) listen(ctx context.Context) {
+	delay := time.Second
+	for {
+		started := time.Now()
+		err := f.db.Listen(ctx, channel, f.deliver)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > maxReconnectDelay {
+			delay = time.Second
+		}
+		slog.Error("Lost log fan-out connection, listening again", "retry_in", delay.String(), "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// deliver passes a published line to the clients of this instance
+func (f *PostgresFanOut) deliver(payload string) {
+	var n notification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		slog.Warn("Ignoring malformed log fan-out notification", "error", err)
+		return
+	}
+	f.local.DeliverLog(n.DeploymentID, n.Line)
+}
+
+// encode returns the payload a line is published as, shortening lines too long to be published
+func encode(n notification) (string, error) {
+	for {
+		payload, err := json.Marshal(n)
+		if err != nil || len(payload) <= maxPayloadBytes {
+			return string(payload), err
+		}
+		n.Line = truncate(n.Line, len(n.Line)/2)
+	}
+}
+
+// truncate shortens a line to at most size bytes without splitting a character, and marks it as shortened
+func truncate(line string, size int) string {
+	for size > 0 && !utf8.RuneStart(line[size]) {
+		size--
+	}
+	return line[:size] + "…"
+}
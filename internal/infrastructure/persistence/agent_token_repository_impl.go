@@ -0,0 +1,88 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/agent"
+	"snapdeploy-core/internal/domain/user"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// AgentTokenRepositoryImpl implements the domain agent.TokenRepository interface
+type AgentTokenRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewAgentTokenRepository creates a new agent token repository implementation
+func NewAgentTokenRepository(db *database.DB) agent.TokenRepository {
+	return &AgentTokenRepositoryImpl{db: db}
+}
+
+// Save persists a new token
+func (r *AgentTokenRepositoryImpl) Save(ctx context.Context, token *agent.Token) error {
+	err := r.db.Queries(ctx).CreateAgentToken(ctx, &database.CreateAgentTokenParams{
+		ID:        token.ID().UUID(),
+		UserID:    token.UserID().UUID(),
+		Name:      token.Name(),
+		TokenHash: token.TokenHash(),
+		CreatedAt: token.CreatedAt(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save agent token: %w", err)
+	}
+	return nil
+}
+
+// FindByHash retrieves the token with the given hash
+func (r *AgentTokenRepositoryImpl) FindByHash(ctx context.Context, tokenHash string) (*agent.Token, error) {
+	dbToken, err := r.db.Queries(ctx).GetAgentTokenByHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, agent.ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to find agent token: %w", err)
+	}
+	return r.toDomain(dbToken)
+}
+
+// FindByUserID retrieves a user's tokens, newest first
+func (r *AgentTokenRepositoryImpl) FindByUserID(ctx context.Context, userID user.UserID) ([]*agent.Token, error) {
+	dbTokens, err := r.db.Queries(ctx).ListAgentTokensByUserID(ctx, userID.UUID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent tokens: %w", err)
+	}
+
+	tokens := make([]*agent.Token, 0, len(dbTokens))
+	for _, dbToken := range dbTokens {
+		token, err := r.toDomain(dbToken)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// Delete revokes one of a user's tokens
+func (r *AgentTokenRepositoryImpl) Delete(ctx context.Context, id agent.TokenID, userID user.UserID) error {
+	deleted, err := r.db.Queries(ctx).DeleteAgentToken(ctx, &database.DeleteAgentTokenParams{
+		ID:     id.UUID(),
+		UserID: userID.UUID(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete agent token: %w", err)
+	}
+	if deleted == 0 {
+		return agent.ErrTokenNotFound
+	}
+	return nil
+}
+
+// toDomain converts a database agent token to a domain token
+func (r *AgentTokenRepositoryImpl) toDomain(dbToken *database.AgentToken) (*agent.Token, error) {
+	return agent.ReconstituteToken(dbToken.ID.String(), dbToken.UserID.String(), dbToken.Name, dbToken.TokenHash, dbToken.CreatedAt)
+}
@@ -0,0 +1,135 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/user"
+
+	"github.com/google/uuid"
+)
+
+// ApprovalRepositoryImpl implements the domain deployment.ApprovalRepository interface
+type ApprovalRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewApprovalRepository creates a new approval repository implementation
+func NewApprovalRepository(db *database.DB) deployment.ApprovalRepository {
+	return &ApprovalRepositoryImpl{db: db}
+}
+
+// Save records a decision on a deployment
+func (r *ApprovalRepositoryImpl) Save(ctx context.Context, approval deployment.Approval) error {
+	queries := r.db.Queries(ctx)
+
+	err := queries.CreateDeploymentApproval(ctx, &database.CreateDeploymentApprovalParams{
+		ID:           uuid.New(),
+		DeploymentID: approval.DeploymentID.UUID(),
+		ProjectID:    approval.ProjectID.UUID(),
+		UserID:       approval.UserID.UUID(),
+		Decision:     approval.Decision.String(),
+		Comment:      approval.Comment,
+		DecidedAt:    approval.DecidedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create deployment approval: %w", err)
+	}
+
+	return nil
+}
+
+// FindByDeploymentID retrieves the decisions on a deployment in chronological order
+func (r *ApprovalRepositoryImpl) FindByDeploymentID(ctx context.Context, deploymentID deployment.DeploymentID) ([]deployment.Approval, error) {
+	queries := r.db.Queries(ctx)
+
+	dbApprovals, err := queries.ListDeploymentApprovals(ctx, deploymentID.UUID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment approvals: %w", err)
+	}
+
+	approvals := make([]deployment.Approval, len(dbApprovals))
+	for i, dbApproval := range dbApprovals {
+		projectID, err := project.ParseProjectID(dbApproval.ProjectID.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid project ID: %w", err)
+		}
+		userID, err := user.ParseUserID(dbApproval.UserID.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID: %w", err)
+		}
+
+		approvals[i] = deployment.Approval{
+			DeploymentID: deploymentID,
+			ProjectID:    projectID,
+			UserID:       userID,
+			Decision:     deployment.ApprovalDecision(dbApproval.Decision),
+			Comment:      dbApproval.Comment,
+			DecidedAt:    dbApproval.DecidedAt,
+		}
+	}
+
+	return approvals, nil
+}
+
+// FindApprovers retrieves the users designated to decide on the deployments to a project's environment
+func (r *ApprovalRepositoryImpl) FindApprovers(ctx context.Context, projectID project.ProjectID, env project.Environment) ([]user.UserID, error) {
+	queries := r.db.Queries(ctx)
+
+	ids, err := queries.ListEnvironmentApprovers(ctx, &database.ListEnvironmentApproversParams{
+		ProjectID:   projectID.UUID(),
+		Environment: env.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environment approvers: %w", err)
+	}
+
+	approvers := make([]user.UserID, len(ids))
+	for i, id := range ids {
+		approvers[i], err = user.ParseUserID(id.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID: %w", err)
+		}
+	}
+
+	return approvers, nil
+}
+
+// SetApprovers replaces the approvers of a project's environment. Users that don't exist aren't added, so fewer
+// rows than approvers means one of them doesn't exist. Callers run it in a transaction to keep the previous
+// approvers then.
+func (r *ApprovalRepositoryImpl) SetApprovers(ctx context.Context, projectID project.ProjectID, env project.Environment, approvers []user.UserID) error {
+	queries := r.db.Queries(ctx)
+
+	err := queries.DeleteEnvironmentApprovers(ctx, &database.DeleteEnvironmentApproversParams{
+		ProjectID:   projectID.UUID(),
+		Environment: env.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete environment approvers: %w", err)
+	}
+	if len(approvers) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(approvers))
+	for i, approver := range approvers {
+		ids[i] = approver.UUID()
+	}
+	added, err := queries.CreateEnvironmentApprovers(ctx, &database.CreateEnvironmentApproversParams{
+		ProjectID:   projectID.UUID(),
+		Environment: env.String(),
+		UserIds:     ids,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create environment approvers: %w", err)
+	}
+	if added != int64(len(approvers)) {
+		return deployment.ErrInvalidApprovers
+	}
+
+	return nil
+}
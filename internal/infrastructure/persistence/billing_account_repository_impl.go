@@ -0,0 +1,170 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/billing"
+	"snapdeploy-core/internal/domain/usage"
+	"snapdeploy-core/internal/domain/user"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// BillingAccountRepositoryImpl implements the domain billing.AccountRepository interface
+type BillingAccountRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewBillingAccountRepository creates a new billing account repository implementation
+func NewBillingAccountRepository(db *database.DB) billing.AccountRepository {
+	return &BillingAccountRepositoryImpl{db: db}
+}
+
+// FindByUserID retrieves the billing account of a user
+func (r *BillingAccountRepositoryImpl) FindByUserID(ctx context.Context, userID user.UserID) (*billing.Account, error) {
+	queries := r.db.Queries(ctx)
+
+	dbAccount, err := queries.GetBillingAccountByUserID(ctx, userID.UUID())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, billing.ErrAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to get billing account: %w", err)
+	}
+
+	return r.toDomain(dbAccount)
+}
+
+// FindByCustomerID retrieves the billing account of a Stripe customer
+func (r *BillingAccountRepositoryImpl) FindByCustomerID(ctx context.Context, customerID string) (*billing.Account, error) {
+	queries := r.db.Queries(ctx)
+
+	dbAccount, err := queries.GetBillingAccountByCustomerID(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, billing.ErrAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to get billing account: %w", err)
+	}
+
+	return r.toDomain(dbAccount)
+}
+
+// List retrieves billing accounts with pagination, oldest first
+func (r *BillingAccountRepositoryImpl) List(ctx context.Context, limit, offset int32) ([]*billing.Account, error) {
+	queries := r.db.Queries(ctx)
+
+	dbAccounts, err := queries.ListBillingAccounts(ctx, &database.ListBillingAccountsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list billing accounts: %w", err)
+	}
+
+	accounts := make([]*billing.Account, 0, len(dbAccounts))
+	for _, dbAccount := range dbAccounts {
+		account, err := r.toDomain(dbAccount)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+// FindUnbilledUserIDs returns up to limit users without a billing account, oldest first
+func (r *BillingAccountRepositoryImpl) FindUnbilledUserIDs(ctx context.Context, limit int32) ([]user.UserID, error) {
+	queries := r.db.Queries(ctx)
+
+	ids, err := queries.ListUnbilledUserIDs(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users without a billing account: %w", err)
+	}
+
+	userIDs := make([]user.UserID, 0, len(ids))
+	for _, id := range ids {
+		userID, err := user.ParseUserID(id.String())
+		if err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, nil
+}
+
+// Save persists a billing account (create or update)
+func (r *BillingAccountRepositoryImpl) Save(ctx context.Context, account *billing.Account) error {
+	queries := r.db.Queries(ctx)
+
+	var suspendedAt sql.NullTime
+	if t := account.SuspendedAt(); t != nil {
+		suspendedAt = sql.NullTime{Time: *t, Valid: true}
+	}
+	buildMinutes, err := account.Meter(usage.MetricBuildMinutes)
+	if err != nil {
+		return err
+	}
+	vcpuHours, err := account.Meter(usage.MetricVCPUHours)
+	if err != nil {
+		return err
+	}
+
+	err = queries.UpsertBillingAccount(ctx, &database.UpsertBillingAccountParams{
+		UserID:                    account.UserID().UUID(),
+		StripeCustomerID:          account.CustomerID(),
+		StripeSubscriptionID:      sql.NullString{String: account.SubscriptionID(), Valid: account.SubscriptionID() != ""},
+		Plan:                      sql.NullString{String: account.Plan(), Valid: account.Plan() != ""},
+		Status:                    account.Status().String(),
+		SuspendedAt:               suspendedAt,
+		BuildMinutesReportedUntil: buildMinutes.ReportedUntil,
+		BuildMinutesCarry:         buildMinutes.Carry,
+		VcpuHoursReportedUntil:    vcpuHours.ReportedUntil,
+		VcpuHoursCarry:            vcpuHours.Carry,
+		CreatedAt:                 account.CreatedAt(),
+		UpdatedAt:                 account.UpdatedAt(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save billing account: %w", err)
+	}
+
+	return nil
+}
+
+// toDomain converts a database billing account to a domain billing account
+func (r *BillingAccountRepositoryImpl) toDomain(dbAccount *database.BillingAccount) (*billing.Account, error) {
+	userID, err := user.ParseUserID(dbAccount.UserID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := billing.NewStatus(dbAccount.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	var suspendedAt *time.Time
+	if dbAccount.SuspendedAt.Valid {
+		suspendedAt = &dbAccount.SuspendedAt.Time
+	}
+
+	return billing.ReconstructAccount(
+		userID,
+		dbAccount.StripeCustomerID,
+		dbAccount.StripeSubscriptionID.String,
+		dbAccount.Plan.String,
+		status,
+		suspendedAt,
+		billing.Meter{ReportedUntil: dbAccount.BuildMinutesReportedUntil, Carry: dbAccount.BuildMinutesCarry},
+		billing.Meter{ReportedUntil: dbAccount.VcpuHoursReportedUntil, Carry: dbAccount.VcpuHoursCarry},
+		dbAccount.CreatedAt,
+		dbAccount.UpdatedAt,
+	), nil
+}
@@ -0,0 +1,218 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/deployment"
+
+	"github.com/google/uuid"
+)
+
+// writtenIdleTimeout is how long a deployment that isn't saved is remembered before it's written through again
+const writtenIdleTimeout = 30 * time.Minute
+
+// BufferedDeploymentRepository writes the logs of a deployment in batches. Builders and orchestrators save
+// a deployment after each log line they append; a save that only appended lines is kept in memory and
+// written with the following ones once enough lines pile up or the flush interval passes. Saves changing
+// anything else write the deployment through at once, with the lines before them.
+type BufferedDeploymentRepository struct {
+	deployment.DeploymentRepository
+	db       *database.DB
+	maxLines int
+	interval time.Duration
+
+	mu      sync.Mutex
+	written map[uuid.UUID]*writtenDeployment
+	pending map[uuid.UUID]*pendingLogs
+}
+
+// writtenDeployment is what was last written of a deployment
+type writtenDeployment struct {
+	status      deployment.DeploymentStatus
+	imageURI    string
+	imageDigest string
+	logsLength  int
+	at          time.Time
+}
+
+// pendingLogs are the logs of a deployment waiting to be written
+type pendingLogs struct {
+	logs      string
+	updatedAt time.Time
+	lines     int
+	since     time.Time
+}
+
+// NewBufferedDeploymentRepository wraps a deployment repository so saves appending log lines are batched,
+// flushing after maxLines lines or once the interval has passed
+func NewBufferedDeploymentRepository(repo deployment.DeploymentRepository, db *database.DB, maxLines int, interval time.Duration) *BufferedDeploymentRepository {
+	return &BufferedDeploymentRepository{
+		DeploymentRepository: repo,
+		db:                   db,
+		maxLines:             maxLines,
+		interval:             interval,
+		written:              make(map[uuid.UUID]*writtenDeployment),
+		pending:              make(map[uuid.UUID]*pendingLogs),
+	}
+}
+
+// Save buffers a deployment whose logs are all that changed since it was last written, and writes
+// everything else through
+func (r *BufferedDeploymentRepository) Save(ctx context.Context, dep *deployment.Deployment) error {
+	id := dep.ID().UUID()
+	logs := dep.Logs().String()
+
+	r.mu.Lock()
+	if lines, ok := r.newLines(dep, logs); ok {
+		p := r.pending[id]
+		if p == nil {
+			p = &pendingLogs{since: time.Now()}
+			r.pending[id] = p
+		}
+		p.logs, p.updatedAt, p.lines = logs, dep.UpdatedAt(), lines
+		if p.lines < r.maxLines && time.Since(p.since) < r.interval {
+			r.mu.Unlock()
+			return nil
+		}
+	}
+	// Written through, the deployment's buffered lines go with it
+	delete(r.pending, id)
+	r.mu.Unlock()
+
+	if err := r.DeploymentRepository.Save(ctx, dep); err != nil {
+		r.forget(id)
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if dep.Status().IsTerminal() {
+		// Finished deployments are rarely saved again, and write through when they are
+		delete(r.written, id)
+		return nil
+	}
+	r.written[id] = &writtenDeployment{
+		status:      dep.Status(),
+		imageURI:    dep.ImageURI(),
+		imageDigest: dep.ImageDigest(),
+		logsLength:  len(logs),
+		at:          time.Now(),
+	}
+	return nil
+}
+
+// newLines returns how many lines were appended to a deployment's logs since it was last written, and
+// whether the lines are all that changed. Deployments with events to publish are written through so
+// their events are only published once saved.
+func (r *BufferedDeploymentRepository) newLines(dep *deployment.Deployment, logs string) (int, bool) {
+	written := r.written[dep.ID().UUID()]
+	if written == nil || dep.HasEvents() || len(logs) < written.logsLength ||
+		dep.Status() != written.status || dep.ImageURI() != written.imageURI || dep.ImageDigest() != written.imageDigest {
+		return 0, false
+	}
+	return strings.Count(logs[written.logsLength:], "\n"), true
+}
+
+// forget drops what was written of a deployment, so its next save is written through
+func (r *BufferedDeploymentRepository) forget(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.written, id)
+	delete(r.pending, id)
+}
+
+// FindByID writes a deployment's buffered logs before reading it
+func (r *BufferedDeploymentRepository) FindByID(ctx context.Context, id deployment.DeploymentID) (*deployment.Deployment, error) {
+	r.flushOne(ctx, id.UUID())
+	return r.DeploymentRepository.FindByID(ctx, id)
+}
+
+// FindByIDIncludingDeleted writes a deployment's buffered logs before reading it
+func (r *BufferedDeploymentRepository) FindByIDIncludingDeleted(ctx context.Context, id deployment.DeploymentID) (*deployment.Deployment, error) {
+	r.flushOne(ctx, id.UUID())
+	return r.DeploymentRepository.FindByIDIncludingDeleted(ctx, id)
+}
+
+// Run writes buffered logs every flush interval until the context is cancelled
+func (r *BufferedDeploymentRepository) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.flush(ctx, false)
+		}
+	}
+}
+
+// Flush writes all buffered logs, once builds and deploys have stopped when the server shuts down
+func (r *BufferedDeploymentRepository) Flush(ctx context.Context) {
+	r.flush(ctx, true)
+}
+
+// flush writes the logs buffered for longer than the flush interval, or all of them, and forgets the
+// deployments that haven't been saved for a while
+func (r *BufferedDeploymentRepository) flush(ctx context.Context, all bool) {
+	r.mu.Lock()
+	due := make(map[uuid.UUID]*pendingLogs)
+	for id, p := range r.pending {
+		if all || time.Since(p.since) >= r.interval {
+			due[id] = p
+			delete(r.pending, id)
+		}
+	}
+	for id, written := range r.written {
+		if _, buffered := r.pending[id]; !buffered && time.Since(written.at) > writtenIdleTimeout {
+			delete(r.written, id)
+		}
+	}
+	r.mu.Unlock()
+
+	for id, p := range due {
+		r.write(ctx, id, p)
+	}
+}
+
+// flushOne writes the logs buffered for a deployment, if any
+func (r *BufferedDeploymentRepository) flushOne(ctx context.Context, id uuid.UUID) {
+	r.mu.Lock()
+	p := r.pending[id]
+	delete(r.pending, id)
+	r.mu.Unlock()
+
+	if p != nil {
+		r.write(ctx, id, p)
+	}
+}
+
+// write saves a deployment's buffered logs. Logs older than what's stored are skipped, a save
+// writing the deployment through may have gone first.
+func (r *BufferedDeploymentRepository) write(ctx context.Context, id uuid.UUID, p *pendingLogs) {
+	_, err := r.db.Queries(ctx).UpdateDeploymentLogs(ctx, &database.UpdateDeploymentLogsParams{
+		ID:        id,
+		Logs:      sql.NullString{String: p.logs, Valid: true},
+		UpdatedAt: sql.NullTime{Time: p.updatedAt, Valid: true},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to write deployment logs", "deployment_id", id.String(), "error", err)
+		// The deployment's next save writes it through, logs included
+		r.forget(id)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if written := r.written[id]; written != nil && len(p.logs) > written.logsLength {
+		written.logsLength = len(p.logs)
+		written.at = time.Now()
+	}
+}
@@ -0,0 +1,131 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// BuildJobRepositoryImpl implements the domain deployment.BuildJobRepository interface
+type BuildJobRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewBuildJobRepository creates a new build job repository implementation
+func NewBuildJobRepository(db *database.DB) deployment.BuildJobRepository {
+	return &BuildJobRepositoryImpl{db: db}
+}
+
+// Enqueue persists a new build job
+func (r *BuildJobRepositoryImpl) Enqueue(ctx context.Context, job *deployment.BuildJob) error {
+	queries := r.db.Queries(ctx)
+
+	err := queries.CreateBuildJob(ctx, &database.CreateBuildJobParams{
+		DeploymentID:  job.DeploymentID().UUID(),
+		ProjectID:     job.ProjectID().UUID(),
+		Status:        job.Status().String(),
+		Attempts:      int32(job.Attempts()),
+		LastError:     job.LastError(),
+		CreatedAt:     job.CreatedAt(),
+		UpdatedAt:     job.UpdatedAt(),
+		CorrelationID: job.CorrelationID(),
+		TraceParent:   job.TraceParent(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create build job: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimNext claims the oldest claimable build job, skipping jobs locked by other workers
+func (r *BuildJobRepositoryImpl) ClaimNext(ctx context.Context, staleBefore time.Time, maxRunningPerUser int) (*deployment.BuildJob, error) {
+	queries := r.db.Queries(ctx)
+
+	dbJob, err := queries.ClaimBuildJob(ctx, &database.ClaimBuildJobParams{
+		StaleBefore:       sql.NullTime{Time: staleBefore, Valid: true},
+		MaxRunningPerUser: int64(maxRunningPerUser),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, deployment.ErrNoBuildJob
+		}
+		return nil, fmt.Errorf("failed to claim build job: %w", err)
+	}
+
+	return r.toDomain(dbJob)
+}
+
+// CountOpen counts the build jobs that are pending or being started
+func (r *BuildJobRepositoryImpl) CountOpen(ctx context.Context) (int64, error) {
+	queries := r.db.Queries(ctx)
+
+	count, err := queries.CountOpenBuildJobs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count open build jobs: %w", err)
+	}
+
+	return count, nil
+}
+
+// Save persists a build job's status
+func (r *BuildJobRepositoryImpl) Save(ctx context.Context, job *deployment.BuildJob) error {
+	queries := r.db.Queries(ctx)
+
+	err := queries.UpdateBuildJob(ctx, &database.UpdateBuildJobParams{
+		DeploymentID: job.DeploymentID().UUID(),
+		Status:       job.Status().String(),
+		LastError:    job.LastError(),
+		UpdatedAt:    job.UpdatedAt(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update build job: %w", err)
+	}
+
+	return nil
+}
+
+// Requeue queues the build job of an interrupted or retried deployment again
+func (r *BuildJobRepositoryImpl) Requeue(ctx context.Context, deploymentID deployment.DeploymentID) error {
+	queries := r.db.Queries(ctx)
+
+	if err := queries.RequeueBuildJob(ctx, deploymentID.UUID()); err != nil {
+		return fmt.Errorf("failed to requeue build job: %w", err)
+	}
+
+	return nil
+}
+
+// toDomain converts a database build job to a domain build job
+func (r *BuildJobRepositoryImpl) toDomain(dbJob *database.BuildJob) (*deployment.BuildJob, error) {
+	projectID, err := project.ParseProjectID(dbJob.ProjectID.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	var claimedAt *time.Time
+	if dbJob.ClaimedAt.Valid {
+		claimedAt = &dbJob.ClaimedAt.Time
+	}
+
+	return deployment.ReconstituteBuildJob(
+		dbJob.DeploymentID.String(),
+		projectID,
+		dbJob.Status,
+		int(dbJob.Attempts),
+		dbJob.LastError,
+		claimedAt,
+		dbJob.CorrelationID,
+		dbJob.TraceParent,
+		dbJob.CreatedAt,
+		dbJob.UpdatedAt,
+	)
+}
@@ -0,0 +1,127 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/command"
+	"snapdeploy-core/internal/domain/project"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CommandRunRepositoryImpl implements the domain command.RunRepository interface
+type CommandRunRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewCommandRunRepository creates a new command run repository implementation
+func NewCommandRunRepository(db *database.DB) command.RunRepository {
+	return &CommandRunRepositoryImpl{db: db}
+}
+
+// Save persists a run, replacing its recorded state and output
+func (r *CommandRunRepositoryImpl) Save(ctx context.Context, run *command.Run) error {
+	queries := r.db.Queries(ctx)
+
+	var exitCode sql.NullInt32
+	if code := run.ExitCode(); code != nil {
+		exitCode = sql.NullInt32{Int32: int32(*code), Valid: true}
+	}
+	var finishedAt sql.NullTime
+	if t := run.FinishedAt(); t != nil {
+		finishedAt = sql.NullTime{Time: *t, Valid: true}
+	}
+
+	err := queries.UpsertCommandRun(ctx, &database.UpsertCommandRunParams{
+		ID:           run.ID().UUID(),
+		ProjectID:    run.ProjectID().UUID(),
+		DeploymentID: run.DeploymentID().UUID(),
+		UserID:       run.UserID().UUID(),
+		Environment:  run.Environment().String(),
+		Command:      run.Command(),
+		Status:       run.Status().String(),
+		ExitCode:     exitCode,
+		Error:        run.ErrorMessage(),
+		Output:       strings.Join(run.Output(), "\n"),
+		StartedAt:    run.StartedAt(),
+		FinishedAt:   finishedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save command run: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a run by its ID
+func (r *CommandRunRepositoryImpl) FindByID(ctx context.Context, id command.RunID) (*command.Run, error) {
+	queries := r.db.Queries(ctx)
+
+	dbRun, err := queries.GetCommandRunByID(ctx, id.UUID())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, command.ErrRunNotFound
+		}
+		return nil, fmt.Errorf("failed to get command run: %w", err)
+	}
+
+	return r.toDomain(dbRun)
+}
+
+// FindByProjectID retrieves the most recent runs of a project, newest first
+func (r *CommandRunRepositoryImpl) FindByProjectID(ctx context.Context, projectID project.ProjectID, limit int) ([]*command.Run, error) {
+	queries := r.db.Queries(ctx)
+
+	dbRuns, err := queries.ListCommandRunsByProjectID(ctx, &database.ListCommandRunsByProjectIDParams{
+		ProjectID: projectID.UUID(),
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get command runs: %w", err)
+	}
+
+	runs := make([]*command.Run, len(dbRuns))
+	for i, dbRun := range dbRuns {
+		run, err := r.toDomain(dbRun)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert command run: %w", err)
+		}
+		runs[i] = run
+	}
+
+	return runs, nil
+}
+
+// toDomain converts database command run to domain command run
+func (r *CommandRunRepositoryImpl) toDomain(dbRun *database.CommandRun) (*command.Run, error) {
+	var exitCode *int
+	if dbRun.ExitCode.Valid {
+		code := int(dbRun.ExitCode.Int32)
+		exitCode = &code
+	}
+	var finishedAt *time.Time
+	if dbRun.FinishedAt.Valid {
+		finishedAt = &dbRun.FinishedAt.Time
+	}
+
+	return command.ReconstituteRun(
+		dbRun.ID.String(),
+		dbRun.ProjectID.String(),
+		dbRun.DeploymentID.String(),
+		dbRun.UserID.String(),
+		dbRun.Environment,
+		dbRun.Command,
+		dbRun.Status,
+		exitCode,
+		dbRun.Error,
+		dbRun.Output,
+		dbRun.StartedAt,
+		finishedAt,
+	)
+}
@@ -0,0 +1,135 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/cronrun"
+	"snapdeploy-core/internal/domain/deployment"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CronRunRepositoryImpl implements the domain cronrun.RunRepository interface
+type CronRunRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewCronRunRepository creates a new cron run repository implementation
+func NewCronRunRepository(db *database.DB) cronrun.RunRepository {
+	return &CronRunRepositoryImpl{db: db}
+}
+
+// Save persists a run, replacing the recorded state of its task
+func (r *CronRunRepositoryImpl) Save(ctx context.Context, run *cronrun.Run) error {
+	queries := r.db.Queries(ctx)
+
+	var exitCode sql.NullInt32
+	if code := run.ExitCode(); code != nil {
+		exitCode = sql.NullInt32{Int32: int32(*code), Valid: true}
+	}
+	var stoppedAt sql.NullTime
+	if t := run.StoppedAt(); t != nil {
+		stoppedAt = sql.NullTime{Time: *t, Valid: true}
+	}
+
+	_, err := queries.UpsertCronRun(ctx, &database.UpsertCronRunParams{
+		ID:           run.ID().UUID(),
+		ProjectID:    run.ProjectID().UUID(),
+		DeploymentID: run.DeploymentID().UUID(),
+		TaskArn:      run.TaskARN(),
+		Status:       run.Status().String(),
+		ExitCode:     exitCode,
+		Reason:       run.Reason(),
+		StartedAt:    run.StartedAt(),
+		StoppedAt:    stoppedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a run by its ID
+func (r *CronRunRepositoryImpl) FindByID(ctx context.Context, id cronrun.RunID) (*cronrun.Run, error) {
+	queries := r.db.Queries(ctx)
+
+	dbRun, err := queries.GetCronRunByID(ctx, id.UUID())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, cronrun.ErrRunNotFound
+		}
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+
+	return r.toDomain(dbRun)
+}
+
+// FindByTaskARN retrieves the run of an ECS task
+func (r *CronRunRepositoryImpl) FindByTaskARN(ctx context.Context, taskARN string) (*cronrun.Run, error) {
+	queries := r.db.Queries(ctx)
+
+	dbRun, err := queries.GetCronRunByTaskARN(ctx, taskARN)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, cronrun.ErrRunNotFound
+		}
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+
+	return r.toDomain(dbRun)
+}
+
+// FindByDeploymentID retrieves the most recent runs of a deployment, newest first
+func (r *CronRunRepositoryImpl) FindByDeploymentID(ctx context.Context, deploymentID deployment.DeploymentID, limit int) ([]*cronrun.Run, error) {
+	queries := r.db.Queries(ctx)
+
+	dbRuns, err := queries.ListCronRunsByDeploymentID(ctx, &database.ListCronRunsByDeploymentIDParams{
+		DeploymentID: deploymentID.UUID(),
+		Limit:        int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get runs: %w", err)
+	}
+
+	runs := make([]*cronrun.Run, len(dbRuns))
+	for i, dbRun := range dbRuns {
+		run, err := r.toDomain(dbRun)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert run: %w", err)
+		}
+		runs[i] = run
+	}
+
+	return runs, nil
+}
+
+// toDomain converts database run to domain run
+func (r *CronRunRepositoryImpl) toDomain(dbRun *database.CronRun) (*cronrun.Run, error) {
+	var exitCode *int
+	if dbRun.ExitCode.Valid {
+		code := int(dbRun.ExitCode.Int32)
+		exitCode = &code
+	}
+	var stoppedAt *time.Time
+	if dbRun.StoppedAt.Valid {
+		stoppedAt = &dbRun.StoppedAt.Time
+	}
+
+	return cronrun.ReconstituteRun(
+		dbRun.ID.String(),
+		dbRun.ProjectID.String(),
+		dbRun.DeploymentID.String(),
+		dbRun.TaskArn,
+		dbRun.Status,
+		exitCode,
+		dbRun.Reason,
+		dbRun.StartedAt,
+		stoppedAt,
+	)
+}
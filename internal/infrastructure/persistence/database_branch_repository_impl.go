@@ -0,0 +1,238 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/dbbranch"
+	"snapdeploy-core/internal/domain/project"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SnapshotRepositoryImpl implements the domain dbbranch.SnapshotRepository interface
+type SnapshotRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewSnapshotRepository creates a new snapshot repository implementation
+func NewSnapshotRepository(db *database.DB) dbbranch.SnapshotRepository {
+	return &SnapshotRepositoryImpl{db: db}
+}
+
+// Save persists a new snapshot
+func (r *SnapshotRepositoryImpl) Save(ctx context.Context, snapshot *dbbranch.Snapshot) error {
+	queries := r.db.Queries(ctx)
+
+	_, err := queries.CreateDatabaseSnapshot(ctx, &database.CreateDatabaseSnapshotParams{
+		ID:           snapshot.ID().UUID(),
+		ProjectID:    snapshot.ProjectID().UUID(),
+		DatabaseName: snapshot.DatabaseName(),
+		SizeBytes:    snapshot.SizeBytes(),
+		CreatedAt:    snapshot.CreatedAt(),
+		ExpiresAt:    snapshot.ExpiresAt(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a snapshot by its ID
+func (r *SnapshotRepositoryImpl) FindByID(ctx context.Context, id dbbranch.SnapshotID) (*dbbranch.Snapshot, error) {
+	queries := r.db.Queries(ctx)
+
+	dbSnapshot, err := queries.GetDatabaseSnapshotByID(ctx, id.UUID())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dbbranch.ErrSnapshotNotFound
+		}
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+
+	return r.toDomain(dbSnapshot)
+}
+
+// FindByProjectID retrieves the snapshots of a project, newest first
+func (r *SnapshotRepositoryImpl) FindByProjectID(ctx context.Context, projectID project.ProjectID) ([]*dbbranch.Snapshot, error) {
+	queries := r.db.Queries(ctx)
+
+	dbSnapshots, err := queries.ListDatabaseSnapshotsByProjectID(ctx, projectID.UUID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshots: %w", err)
+	}
+
+	return r.toDomainList(dbSnapshots)
+}
+
+// FindExpired retrieves snapshots that expired before the given time
+func (r *SnapshotRepositoryImpl) FindExpired(ctx context.Context, before time.Time) ([]*dbbranch.Snapshot, error) {
+	queries := r.db.Queries(ctx)
+
+	dbSnapshots, err := queries.ListExpiredDatabaseSnapshots(ctx, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired snapshots: %w", err)
+	}
+
+	return r.toDomainList(dbSnapshots)
+}
+
+// Delete removes a snapshot
+func (r *SnapshotRepositoryImpl) Delete(ctx context.Context, id dbbranch.SnapshotID) error {
+	queries := r.db.Queries(ctx)
+
+	if err := queries.DeleteDatabaseSnapshot(ctx, id.UUID()); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// toDomainList converts a list of database snapshots to domain snapshots
+func (r *SnapshotRepositoryImpl) toDomainList(dbSnapshots []*database.DatabaseSnapshot) ([]*dbbranch.Snapshot, error) {
+	snapshots := make([]*dbbranch.Snapshot, len(dbSnapshots))
+	for i, dbSnapshot := range dbSnapshots {
+		snapshot, err := r.toDomain(dbSnapshot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert snapshot: %w", err)
+		}
+		snapshots[i] = snapshot
+	}
+
+	return snapshots, nil
+}
+
+// toDomain converts database snapshot to domain snapshot
+func (r *SnapshotRepositoryImpl) toDomain(dbSnapshot *database.DatabaseSnapshot) (*dbbranch.Snapshot, error) {
+	return dbbranch.ReconstituteSnapshot(
+		dbSnapshot.ID.String(),
+		dbSnapshot.ProjectID.String(),
+		dbSnapshot.DatabaseName,
+		dbSnapshot.SizeBytes,
+		dbSnapshot.CreatedAt,
+		dbSnapshot.ExpiresAt,
+	)
+}
+
+// BranchRepositoryImpl implements the domain dbbranch.BranchRepository interface
+type BranchRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewBranchRepository creates a new branch repository implementation
+func NewBranchRepository(db *database.DB) dbbranch.BranchRepository {
+	return &BranchRepositoryImpl{db: db}
+}
+
+// Save persists a new branch
+func (r *BranchRepositoryImpl) Save(ctx context.Context, branch *dbbranch.Branch) error {
+	queries := r.db.Queries(ctx)
+
+	var snapshotID uuid.NullUUID
+	if id := branch.SnapshotID(); id != nil {
+		snapshotID = uuid.NullUUID{UUID: id.UUID(), Valid: true}
+	}
+
+	_, err := queries.CreateDatabaseBranch(ctx, &database.CreateDatabaseBranchParams{
+		ID:           branch.ID().UUID(),
+		ProjectID:    branch.ProjectID().UUID(),
+		SnapshotID:   snapshotID,
+		Name:         branch.Name().String(),
+		DatabaseName: branch.DatabaseName(),
+		CreatedAt:    branch.CreatedAt(),
+		ExpiresAt:    branch.ExpiresAt(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create branch: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves a branch by its ID
+func (r *BranchRepositoryImpl) FindByID(ctx context.Context, id dbbranch.BranchID) (*dbbranch.Branch, error) {
+	queries := r.db.Queries(ctx)
+
+	dbBranch, err := queries.GetDatabaseBranchByID(ctx, id.UUID())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, dbbranch.ErrBranchNotFound
+		}
+		return nil, fmt.Errorf("failed to get branch: %w", err)
+	}
+
+	return r.toDomain(dbBranch)
+}
+
+// FindByProjectID retrieves the branches of a project, newest first
+func (r *BranchRepositoryImpl) FindByProjectID(ctx context.Context, projectID project.ProjectID) ([]*dbbranch.Branch, error) {
+	queries := r.db.Queries(ctx)
+
+	dbBranches, err := queries.ListDatabaseBranchesByProjectID(ctx, projectID.UUID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branches: %w", err)
+	}
+
+	return r.toDomainList(dbBranches)
+}
+
+// FindExpired retrieves branches that expired before the given time
+func (r *BranchRepositoryImpl) FindExpired(ctx context.Context, before time.Time) ([]*dbbranch.Branch, error) {
+	queries := r.db.Queries(ctx)
+
+	dbBranches, err := queries.ListExpiredDatabaseBranches(ctx, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired branches: %w", err)
+	}
+
+	return r.toDomainList(dbBranches)
+}
+
+// Delete removes a branch
+func (r *BranchRepositoryImpl) Delete(ctx context.Context, id dbbranch.BranchID) error {
+	queries := r.db.Queries(ctx)
+
+	if err := queries.DeleteDatabaseBranch(ctx, id.UUID()); err != nil {
+		return fmt.Errorf("failed to delete branch: %w", err)
+	}
+
+	return nil
+}
+
+// toDomainList converts a list of database branches to domain branches
+func (r *BranchRepositoryImpl) toDomainList(dbBranches []*database.DatabaseBranch) ([]*dbbranch.Branch, error) {
+	branches := make([]*dbbranch.Branch, len(dbBranches))
+	for i, dbBranch := range dbBranches {
+		branch, err := r.toDomain(dbBranch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert branch: %w", err)
+		}
+		branches[i] = branch
+	}
+
+	return branches, nil
+}
+
+// toDomain converts database branch to domain branch
+func (r *BranchRepositoryImpl) toDomain(dbBranch *database.DatabaseBranch) (*dbbranch.Branch, error) {
+	var snapshotID *string
+	if dbBranch.SnapshotID.Valid {
+		id := dbBranch.SnapshotID.UUID.String()
+		snapshotID = &id
+	}
+
+	return dbbranch.ReconstituteBranch(
+		dbBranch.ID.String(),
+		dbBranch.ProjectID.String(),
+		snapshotID,
+		dbBranch.Name,
+		dbBranch.DatabaseName,
+		dbBranch.CreatedAt,
+		dbBranch.ExpiresAt,
+	)
+}
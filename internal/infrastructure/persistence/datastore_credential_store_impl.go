@@ -0,0 +1,91 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/infrastructure/encryption"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// DatastoreCredentialStoreImpl keeps the accounts of projects on shared datastore servers in Postgres, with
+// their passwords encrypted
+type DatastoreCredentialStoreImpl struct {
+	db                *database.DB
+	encryptionService *encryption.EncryptionService
+}
+
+// NewDatastoreCredentialStore creates a new datastore credential store
+func NewDatastoreCredentialStore(db *database.DB, encryptionService *encryption.EncryptionService) *DatastoreCredentialStoreImpl {
+	return &DatastoreCredentialStoreImpl{db: db, encryptionService: encryptionService}
+}
+
+// FindCredential returns a project's account on a datastore, an empty username if it has none
+func (s *DatastoreCredentialStoreImpl) FindCredential(ctx context.Context, projectID, datastore string) (string, string, error) {
+	id, err := uuid.Parse(projectID)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	credential, err := s.db.Queries(ctx).GetDatastoreCredential(ctx, &database.GetDatastoreCredentialParams{
+		ProjectID: id,
+		Datastore: datastore,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get datastore credential: %w", err)
+	}
+
+	password, err := s.encryptionService.Decrypt(credential.PasswordEncrypted)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decrypt datastore password: %w", err)
+	}
+	return credential.Username, password, nil
+}
+
+// CreateCredential saves a project's account on a datastore. It returns false if the project already has one.
+func (s *DatastoreCredentialStoreImpl) CreateCredential(ctx context.Context, projectID, datastore, username, password string) (bool, error) {
+	id, err := uuid.Parse(projectID)
+	if err != nil {
+		return false, fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	encrypted, err := s.encryptionService.Encrypt(password)
+	if err != nil {
+		return false, fmt.Errorf("failed to encrypt datastore password: %w", err)
+	}
+
+	created, err := s.db.Queries(ctx).CreateDatastoreCredential(ctx, &database.CreateDatastoreCredentialParams{
+		ProjectID:         id,
+		Datastore:         datastore,
+		Username:          username,
+		PasswordEncrypted: encrypted,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create datastore credential: %w", err)
+	}
+	return created == 1, nil
+}
+
+// DeleteCredential forgets a project's account on a datastore
+func (s *DatastoreCredentialStoreImpl) DeleteCredential(ctx context.Context, projectID, datastore string) error {
+	id, err := uuid.Parse(projectID)
+	if err != nil {
+		return fmt.Errorf("invalid project ID: %w", err)
+	}
+
+	err = s.db.Queries(ctx).DeleteDatastoreCredential(ctx, &database.DeleteDatastoreCredentialParams{
+		ProjectID: id,
+		Datastore: datastore,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete datastore credential: %w", err)
+	}
+	return nil
+}
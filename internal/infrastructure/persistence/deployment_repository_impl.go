@@ -3,12 +3,17 @@ package persistence
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"snapdeploy-core/internal/database"
 	"snapdeploy-core/internal/domain/deployment"
 	"snapdeploy-core/internal/domain/project"
 	"snapdeploy-core/internal/domain/user"
+
+	"github.com/jackc/pgx/v5"
 )
 
 // DeploymentRepositoryImpl implements the domain deployment.DeploymentRepository interface
@@ -23,117 +28,374 @@ func NewDeploymentRepository(db *database.DB) deployment.DeploymentRepository {
 
 // Save persists a deployment (create or update)
 func (r *DeploymentRepositoryImpl) Save(ctx context.Context, dep *deployment.Deployment) error {
-	queries := database.New(r.db.GetConnection())
-
-	// Check if deployment exists
-	_, err := queries.GetDeploymentByID(ctx, dep.ID().UUID())
-	if err != nil && err != sql.ErrNoRows {
-		return fmt.Errorf("failed to check if deployment exists: %w", err)
+	annotations := dep.Annotations()
+	labels, err := marshalLabels(annotations.Labels)
+	if err != nil {
+		return err
 	}
 
-	// If no error, deployment exists - update it
-	if err == nil {
-		// Update existing deployment
-		err := queries.UpdateDeployment(ctx, &database.UpdateDeploymentParams{
-			ID:        dep.ID().UUID(),
-			Status:    dep.Status().String(),
-			Logs:      sql.NullString{String: dep.Logs().String(), Valid: true},
-			UpdatedAt: sql.NullTime{Time: dep.UpdatedAt(), Valid: true},
-		})
+	return r.db.InTx(ctx, func(ctx context.Context) error {
+		queries := r.db.Queries(ctx)
+
+		// Check if deployment exists; a deleted deployment still finishing its build is updated too
+		exists, err := queries.ExistsDeploymentByID(ctx, dep.ID().UUID())
 		if err != nil {
-			return fmt.Errorf("failed to update deployment: %w", err)
+			return fmt.Errorf("failed to check if deployment exists: %w", err)
 		}
-	} else {
-		// Deployment doesn't exist (err == sql.ErrNoRows) - create it
-		_, err := queries.CreateDeployment(ctx, &database.CreateDeploymentParams{
-			ID:         dep.ID().UUID(),
-			ProjectID:  dep.ProjectID().UUID(),
-			UserID:     dep.UserID().UUID(),
-			CommitHash: dep.CommitHash().String(),
-			Branch:     dep.Branch().String(),
-			Status:     dep.Status().String(),
-			Logs:       sql.NullString{String: dep.Logs().String(), Valid: true},
-			CreatedAt:  sql.NullTime{Time: dep.CreatedAt(), Valid: true},
-			UpdatedAt:  sql.NullTime{Time: dep.UpdatedAt(), Valid: true},
-		})
-		if err != nil {
-			return fmt.Errorf("failed to create deployment: %w", err)
+
+		if exists {
+			// Update existing deployment
+			err := queries.UpdateDeployment(ctx, &database.UpdateDeploymentParams{
+				ID:          dep.ID().UUID(),
+				Status:      dep.Status().String(),
+				Logs:        sql.NullString{String: dep.Logs().String(), Valid: true},
+				UpdatedAt:   sql.NullTime{Time: dep.UpdatedAt(), Valid: true},
+				ImageUri:    dep.ImageURI(),
+				ImageDigest: dep.ImageDigest(),
+				Title:       annotations.Title,
+				Description: annotations.Description,
+				Labels:      labels,
+				Pinned:      dep.IsPinned(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update deployment: %w", err)
+			}
+		} else {
+			// Archived deployments are read only - don't recreate them in the active table
+			if _, err := queries.GetArchivedDeploymentByID(ctx, dep.ID().UUID()); err == nil {
+				return deployment.ErrDeploymentArchived
+			} else if !errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("failed to check if deployment is archived: %w", err)
+			}
+
+			// Deployment doesn't exist - create it
+			_, err := queries.CreateDeployment(ctx, &database.CreateDeploymentParams{
+				ID:         dep.ID().UUID(),
+				ProjectID:  dep.ProjectID().UUID(),
+				UserID:     dep.UserID().UUID(),
+				CommitHash: dep.CommitHash().String(),
+				Branch:     dep.Branch().String(),
+				Status:     dep.Status().String(),
+				Logs:       sql.NullString{String: dep.Logs().String(), Valid: true},
+				CreatedAt:  sql.NullTime{Time: dep.CreatedAt(), Valid: true},
+				UpdatedAt:  sql.NullTime{Time: dep.UpdatedAt(), Valid: true},
+				Type:       dep.Type().String(),
+				Environment: dep.Environment().String(),
+				ImageUri:    dep.ImageURI(),
+				ImageDigest: dep.ImageDigest(),
+				Title:       annotations.Title,
+				Description: annotations.Description,
+				Labels:      labels,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create deployment: %w", err)
+			}
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
-// FindByID retrieves a deployment by its ID
+// FindByID retrieves a deployment by its ID, falling back to the archive
 func (r *DeploymentRepositoryImpl) FindByID(ctx context.Context, id deployment.DeploymentID) (*deployment.Deployment, error) {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	dbDeployment, err := queries.GetDeploymentByID(ctx, id.UUID())
+	if err == nil {
+		return r.toDomain(dbDeployment)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get deployment: %w", err)
+	}
+
+	archived, err := queries.GetArchivedDeploymentByID(ctx, id.UUID())
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, deployment.ErrDeploymentNotFound
 		}
-		return nil, fmt.Errorf("failed to get deployment: %w", err)
+		return nil, fmt.Errorf("failed to get archived deployment: %w", err)
 	}
 
-	return r.toDomain(dbDeployment)
+	return r.toDomain((*database.Deployment)(archived))
 }
 
-// FindByProjectID retrieves all deployments for a project with pagination
-func (r *DeploymentRepositoryImpl) FindByProjectID(ctx context.Context, projectID project.ProjectID, limit, offset int32) ([]*deployment.Deployment, error) {
-	queries := database.New(r.db.GetConnection())
+// FindByIDIncludingDeleted retrieves a deployment by its ID, even if it was deleted, falling back to the archive
+func (r *DeploymentRepositoryImpl) FindByIDIncludingDeleted(ctx context.Context, id deployment.DeploymentID) (*deployment.Deployment, error) {
+	queries := r.db.Queries(ctx)
 
-	dbDeployments, err := queries.GetDeploymentsByProjectID(ctx, &database.GetDeploymentsByProjectIDParams{
-		ProjectID: projectID.UUID(),
-		Limit:     limit,
-		Offset:    offset,
-	})
+	dbDeployment, err := queries.GetDeploymentByIDIncludingDeleted(ctx, id.UUID())
 	if err != nil {
-		return nil, fmt.Errorf("failed to get deployments: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, deployment.ErrDeploymentNotFound
+		}
+		return nil, fmt.Errorf("failed to get deployment: %w", err)
 	}
 
-	deployments := make([]*deployment.Deployment, len(dbDeployments))
-	for i, dbDeployment := range dbDeployments {
-		domainDeployment, err := r.toDomain(dbDeployment)
+	return r.toDomain((*database.Deployment)(dbDeployment))
+}
+
+// FindByProjectID retrieves the deployments, including archived ones, for a project matching the filter with pagination
+func (r *DeploymentRepositoryImpl) FindByProjectID(ctx context.Context, projectID project.ProjectID, filter deployment.ListFilter, limit, offset int32) ([]*deployment.Deployment, error) {
+	queries := r.db.Queries(ctx)
+
+	status, branch, since, labels := listFilterParams(filter)
+	params := &database.GetDeploymentsByProjectIDParams{
+		ProjectID:  projectID.UUID(),
+		Status:     status,
+		Branch:     branch,
+		Since:      since,
+		Labels:      labels,
+		PageSize:   limit,
+		PageOffset: offset,
+	}
+
+	// Each order has a query of its own, so the database can walk the (created_at, id) list indexes either way
+	var dbDeployments []*database.Deployment
+	if filter.Ascending {
+		rows, err := queries.GetDeploymentsByProjectIDAscending(ctx, (*database.GetDeploymentsByProjectIDAscendingParams)(params))
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert deployment: %w", err)
+			return nil, fmt.Errorf("failed to get deployments: %w", err)
+		}
+		for _, row := range rows {
+			dbDeployments = append(dbDeployments, (*database.Deployment)(row))
+		}
+	} else {
+		rows, err := queries.GetDeploymentsByProjectID(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployments: %w", err)
+		}
+		for _, row := range rows {
+			dbDeployments = append(dbDeployments, (*database.Deployment)(row))
 		}
-		deployments[i] = domainDeployment
 	}
 
-	return deployments, nil
+	return r.toDomainList(dbDeployments)
 }
 
-// FindByUserID retrieves all deployments for a user with pagination
-func (r *DeploymentRepositoryImpl) FindByUserID(ctx context.Context, userID user.UserID, limit, offset int32) ([]*deployment.Deployment, error) {
-	queries := database.New(r.db.GetConnection())
+// FindByProjectIDIncludingDeleted retrieves the deployments, including archived and deleted ones, for a project matching the filter with pagination
+func (r *DeploymentRepositoryImpl) FindByProjectIDIncludingDeleted(ctx context.Context, projectID project.ProjectID, filter deployment.ListFilter, limit, offset int32) ([]*deployment.Deployment, error) {
+	queries := r.db.Queries(ctx)
+
+	status, branch, since, labels := listFilterParams(filter)
+	params := &database.GetDeploymentsByProjectIDIncludingDeletedParams{
+		ProjectID:  projectID.UUID(),
+		Status:     status,
+		Branch:     branch,
+		Since:      since,
+		Labels:      labels,
+		PageSize:   limit,
+		PageOffset: offset,
+	}
 
-	dbDeployments, err := queries.GetDeploymentsByUserID(ctx, &database.GetDeploymentsByUserIDParams{
-		UserID: userID.UUID(),
-		Limit:  limit,
-		Offset: offset,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get deployments: %w", err)
+	// Each order has a query of its own, so the database can walk the (created_at, id) list indexes either way
+	var dbDeployments []*database.Deployment
+	if filter.Ascending {
+		rows, err := queries.GetDeploymentsByProjectIDIncludingDeletedAscending(ctx, (*database.GetDeploymentsByProjectIDIncludingDeletedAscendingParams)(params))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployments: %w", err)
+		}
+		for _, row := range rows {
+			dbDeployments = append(dbDeployments, (*database.Deployment)(row))
+		}
+	} else {
+		rows, err := queries.GetDeploymentsByProjectIDIncludingDeleted(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployments: %w", err)
+		}
+		for _, row := range rows {
+			dbDeployments = append(dbDeployments, (*database.Deployment)(row))
+		}
 	}
 
-	deployments := make([]*deployment.Deployment, len(dbDeployments))
-	for i, dbDeployment := range dbDeployments {
-		domainDeployment, err := r.toDomain(dbDeployment)
+	return r.toDomainList(dbDeployments)
+}
+
+// FindByUserID retrieves the deployments, including archived ones, for a user matching the filter with pagination
+func (r *DeploymentRepositoryImpl) FindByUserID(ctx context.Context, userID user.UserID, filter deployment.ListFilter, limit, offset int32) ([]*deployment.Deployment, error) {
+	queries := r.db.Queries(ctx)
+
+	status, branch, since, labels := listFilterParams(filter)
+	params := &database.GetDeploymentsByUserIDParams{
+		UserID:     userID.UUID(),
+		Status:     status,
+		Branch:     branch,
+		Since:      since,
+		Labels:      labels,
+		PageSize:   limit,
+		PageOffset: offset,
+	}
+
+	// Each order has a query of its own, so the database can walk the (created_at, id) list indexes either way
+	var dbDeployments []*database.Deployment
+	if filter.Ascending {
+		rows, err := queries.GetDeploymentsByUserIDAscending(ctx, (*database.GetDeploymentsByUserIDAscendingParams)(params))
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert deployment: %w", err)
+			return nil, fmt.Errorf("failed to get deployments: %w", err)
+		}
+		for _, row := range rows {
+			dbDeployments = append(dbDeployments, (*database.Deployment)(row))
+		}
+	} else {
+		rows, err := queries.GetDeploymentsByUserID(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployments: %w", err)
+		}
+		for _, row := range rows {
+			dbDeployments = append(dbDeployments, (*database.Deployment)(row))
 		}
-		deployments[i] = domainDeployment
 	}
 
-	return deployments, nil
+	return r.toDomainList(dbDeployments)
+}
+
+// FindByProjectIDAfter retrieves up to limit deployments, including archived ones, for a project matching the filter that come after the cursor
+func (r *DeploymentRepositoryImpl) FindByProjectIDAfter(ctx context.Context, projectID project.ProjectID, filter deployment.ListFilter, after deployment.Cursor, limit int32) ([]*deployment.Deployment, error) {
+	queries := r.db.Queries(ctx)
+
+	status, branch, since, labels := listFilterParams(filter)
+	params := &database.GetDeploymentsByProjectIDAfterParams{
+		ProjectID:      projectID.UUID(),
+		Status:         status,
+		Branch:         branch,
+		Since:          since,
+		Labels:          labels,
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID.UUID(),
+		PageSize:       limit,
+	}
+
+	// Each order has a query of its own, so the database can walk the (created_at, id) list indexes either way
+	var dbDeployments []*database.Deployment
+	if filter.Ascending {
+		rows, err := queries.GetDeploymentsByProjectIDAfterAscending(ctx, (*database.GetDeploymentsByProjectIDAfterAscendingParams)(params))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployments: %w", err)
+		}
+		for _, row := range rows {
+			dbDeployments = append(dbDeployments, (*database.Deployment)(row))
+		}
+	} else {
+		rows, err := queries.GetDeploymentsByProjectIDAfter(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployments: %w", err)
+		}
+		for _, row := range rows {
+			dbDeployments = append(dbDeployments, (*database.Deployment)(row))
+		}
+	}
+
+	return r.toDomainList(dbDeployments)
 }
 
-// CountByProjectID counts total deployments for a project
-func (r *DeploymentRepositoryImpl) CountByProjectID(ctx context.Context, projectID project.ProjectID) (int64, error) {
-	queries := database.New(r.db.GetConnection())
+// FindByProjectIDIncludingDeletedAfter retrieves up to limit deployments, including archived and deleted ones, for a project matching the filter that come after the cursor
+func (r *DeploymentRepositoryImpl) FindByProjectIDIncludingDeletedAfter(ctx context.Context, projectID project.ProjectID, filter deployment.ListFilter, after deployment.Cursor, limit int32) ([]*deployment.Deployment, error) {
+	queries := r.db.Queries(ctx)
+
+	status, branch, since, labels := listFilterParams(filter)
+	params := &database.GetDeploymentsByProjectIDIncludingDeletedAfterParams{
+		ProjectID:      projectID.UUID(),
+		Status:         status,
+		Branch:         branch,
+		Since:          since,
+		Labels:          labels,
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID.UUID(),
+		PageSize:       limit,
+	}
 
-	count, err := queries.CountDeploymentsByProjectID(ctx, projectID.UUID())
+	// Each order has a query of its own, so the database can walk the (created_at, id) list indexes either way
+	var dbDeployments []*database.Deployment
+	if filter.Ascending {
+		rows, err := queries.GetDeploymentsByProjectIDIncludingDeletedAfterAscending(ctx, (*database.GetDeploymentsByProjectIDIncludingDeletedAfterAscendingParams)(params))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployments: %w", err)
+		}
+		for _, row := range rows {
+			dbDeployments = append(dbDeployments, (*database.Deployment)(row))
+		}
+	} else {
+		rows, err := queries.GetDeploymentsByProjectIDIncludingDeletedAfter(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployments: %w", err)
+		}
+		for _, row := range rows {
+			dbDeployments = append(dbDeployments, (*database.Deployment)(row))
+		}
+	}
+
+	return r.toDomainList(dbDeployments)
+}
+
+// FindByUserIDAfter retrieves up to limit deployments, including archived ones, for a user matching the filter that come after the cursor
+func (r *DeploymentRepositoryImpl) FindByUserIDAfter(ctx context.Context, userID user.UserID, filter deployment.ListFilter, after deployment.Cursor, limit int32) ([]*deployment.Deployment, error) {
+	queries := r.db.Queries(ctx)
+
+	status, branch, since, labels := listFilterParams(filter)
+	params := &database.GetDeploymentsByUserIDAfterParams{
+		UserID:         userID.UUID(),
+		Status:         status,
+		Branch:         branch,
+		Since:          since,
+		Labels:          labels,
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID.UUID(),
+		PageSize:       limit,
+	}
+
+	// Each order has a query of its own, so the database can walk the (created_at, id) list indexes either way
+	var dbDeployments []*database.Deployment
+	if filter.Ascending {
+		rows, err := queries.GetDeploymentsByUserIDAfterAscending(ctx, (*database.GetDeploymentsByUserIDAfterAscendingParams)(params))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployments: %w", err)
+		}
+		for _, row := range rows {
+			dbDeployments = append(dbDeployments, (*database.Deployment)(row))
+		}
+	} else {
+		rows, err := queries.GetDeploymentsByUserIDAfter(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployments: %w", err)
+		}
+		for _, row := range rows {
+			dbDeployments = append(dbDeployments, (*database.Deployment)(row))
+		}
+	}
+
+	return r.toDomainList(dbDeployments)
+}
+
+// CountByProjectID counts the deployments, including archived ones, for a project matching the filter
+func (r *DeploymentRepositoryImpl) CountByProjectID(ctx context.Context, projectID project.ProjectID, filter deployment.ListFilter) (int64, error) {
+	queries := r.db.Queries(ctx)
+
+	status, branch, since, labels := listFilterParams(filter)
+	count, err := queries.CountDeploymentsByProjectID(ctx, &database.CountDeploymentsByProjectIDParams{
+		ProjectID: projectID.UUID(),
+		Status:    status,
+		Branch:    branch,
+		Since:     since,
+		Labels:     labels,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count deployments: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountByProjectIDIncludingDeleted counts the deployments, including archived and deleted ones, for a project matching the filter
+func (r *DeploymentRepositoryImpl) CountByProjectIDIncludingDeleted(ctx context.Context, projectID project.ProjectID, filter deployment.ListFilter) (int64, error) {
+	queries := r.db.Queries(ctx)
+
+	status, branch, since, labels := listFilterParams(filter)
+	count, err := queries.CountDeploymentsByProjectIDIncludingDeleted(ctx, &database.CountDeploymentsByProjectIDIncludingDeletedParams{
+		ProjectID: projectID.UUID(),
+		Status:    status,
+		Branch:    branch,
+		Since:     since,
+		Labels:     labels,
+	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to count deployments: %w", err)
 	}
@@ -141,11 +403,18 @@ func (r *DeploymentRepositoryImpl) CountByProjectID(ctx context.Context, project
 	return count, nil
 }
 
-// CountByUserID counts total deployments for a user
-func (r *DeploymentRepositoryImpl) CountByUserID(ctx context.Context, userID user.UserID) (int64, error) {
-	queries := database.New(r.db.GetConnection())
+// CountByUserID counts the deployments, including archived ones, for a user matching the filter
+func (r *DeploymentRepositoryImpl) CountByUserID(ctx context.Context, userID user.UserID, filter deployment.ListFilter) (int64, error) {
+	queries := r.db.Queries(ctx)
 
-	count, err := queries.CountDeploymentsByUserID(ctx, userID.UUID())
+	status, branch, since, labels := listFilterParams(filter)
+	count, err := queries.CountDeploymentsByUserID(ctx, &database.CountDeploymentsByUserIDParams{
+		UserID: userID.UUID(),
+		Status: status,
+		Branch: branch,
+		Since:  since,
+		Labels:  labels,
+	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to count deployments: %w", err)
 	}
@@ -153,11 +422,50 @@ func (r *DeploymentRepositoryImpl) CountByUserID(ctx context.Context, userID use
 	return count, nil
 }
 
-// Delete removes a deployment
+// listFilterParams converts a list filter to the parameters of the list queries, NULL where it doesn't filter
+func listFilterParams(filter deployment.ListFilter) (sql.NullString, sql.NullString, sql.NullTime, []byte) {
+	status := sql.NullString{String: filter.Status.String(), Valid: filter.Status != ""}
+	branch := sql.NullString{String: filter.Branch, Valid: filter.Branch != ""}
+	since := sql.NullTime{Time: filter.Since, Valid: !filter.Since.IsZero()}
+	var labels []byte
+	if len(filter.Labels) > 0 {
+		labels, _ = json.Marshal(filter.Labels) // Maps of strings always encode
+	}
+	return status, branch, since, labels
+}
+
+// marshalLabels converts the labels of a deployment to the JSON object they are stored as
+func marshalLabels(labels map[string]string) ([]byte, error) {
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	data, err := json.Marshal(labels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode labels: %w", err)
+	}
+	return data, nil
+}
+
+// CountInProgressByUserID counts a user's deployments that are pending, building or deploying
+func (r *DeploymentRepositoryImpl) CountInProgressByUserID(ctx context.Context, userID user.UserID) (int64, error) {
+	queries := r.db.Queries(ctx)
+
+	count, err := queries.CountInProgressDeploymentsByUserID(ctx, userID.UUID())
+	if err != nil {
+		return 0, fmt.Errorf("failed to count in-progress deployments: %w", err)
+	}
+
+	return count, nil
+}
+
+// Delete soft deletes a deployment, whether active or archived
 func (r *DeploymentRepositoryImpl) Delete(ctx context.Context, id deployment.DeploymentID) error {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
-	err := queries.DeleteDeployment(ctx, id.UUID())
+	err := queries.SoftDeleteDeployment(ctx, &database.SoftDeleteDeploymentParams{
+		ID:        id.UUID(),
+		DeletedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete deployment: %w", err)
 	}
@@ -165,13 +473,28 @@ func (r *DeploymentRepositoryImpl) Delete(ctx context.Context, id deployment.Dep
 	return nil
 }
 
+// PurgeDeletedBefore permanently removes up to limit active or archived deployments deleted before the cutoff
+func (r *DeploymentRepositoryImpl) PurgeDeletedBefore(ctx context.Context, cutoff time.Time, limit int32) (int64, error) {
+	queries := r.db.Queries(ctx)
+
+	purged, err := queries.PurgeDeletedDeployments(ctx, &database.PurgeDeletedDeploymentsParams{
+		DeletedAt: sql.NullTime{Time: cutoff, Valid: true},
+		Limit:     limit,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted deployments: %w", err)
+	}
+
+	return purged, nil
+}
+
 // FindLatestByProjectID retrieves the most recent deployment for a project
 func (r *DeploymentRepositoryImpl) FindLatestByProjectID(ctx context.Context, projectID project.ProjectID) (*deployment.Deployment, error) {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	dbDeployment, err := queries.GetLatestDeploymentByProjectID(ctx, projectID.UUID())
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, deployment.ErrDeploymentNotFound
 		}
 		return nil, fmt.Errorf("failed to get latest deployment: %w", err)
@@ -180,7 +503,137 @@ func (r *DeploymentRepositoryImpl) FindLatestByProjectID(ctx context.Context, pr
 	return r.toDomain(dbDeployment)
 }
 
+// FindLatestInEnvironment retrieves the most recent deployment of a project's environment
+func (r *DeploymentRepositoryImpl) FindLatestInEnvironment(ctx context.Context, projectID project.ProjectID, env project.Environment) (*deployment.Deployment, error) {
+	queries := r.db.Queries(ctx)
+
+	dbDeployment, err := queries.GetLatestDeploymentInEnvironment(ctx, &database.GetLatestDeploymentInEnvironmentParams{
+		ProjectID:   projectID.UUID(),
+		Environment: env.String(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, deployment.ErrDeploymentNotFound
+		}
+		return nil, fmt.Errorf("failed to get latest deployment: %w", err)
+	}
+
+	return r.toDomain(dbDeployment)
+}
+
+// FindLatestDeployedInEnvironment retrieves the most recent successful deployment of a project's environment
+func (r *DeploymentRepositoryImpl) FindLatestDeployedInEnvironment(ctx context.Context, projectID project.ProjectID, env project.Environment) (*deployment.Deployment, error) {
+	queries := r.db.Queries(ctx)
+
+	dbDeployment, err := queries.GetLatestDeployedDeploymentInEnvironment(ctx, &database.GetLatestDeployedDeploymentInEnvironmentParams{
+		ProjectID:   projectID.UUID(),
+		Environment: env.String(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, deployment.ErrDeploymentNotFound
+		}
+		return nil, fmt.Errorf("failed to get latest deployed deployment: %w", err)
+	}
+
+	return r.toDomain(dbDeployment)
+}
+
+// FindLatestDeployed retrieves the most recent successful deployment of every project's environments
+func (r *DeploymentRepositoryImpl) FindLatestDeployed(ctx context.Context) ([]*deployment.Deployment, error) {
+	queries := r.db.Queries(ctx)
+
+	dbDeployments, err := queries.GetLatestDeployedDeployments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployed deployments: %w", err)
+	}
+
+	deployments := make([]*deployment.Deployment, len(dbDeployments))
+	for i, dbDeployment := range dbDeployments {
+		domainDeployment, err := r.toDomain(dbDeployment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert deployment: %w", err)
+		}
+		deployments[i] = domainDeployment
+	}
+
+	return deployments, nil
+}
+
+// FindPinnedByProjectID retrieves the pinned deployments of a project, newest first
+func (r *DeploymentRepositoryImpl) FindPinnedByProjectID(ctx context.Context, projectID project.ProjectID) ([]*deployment.Deployment, error) {
+	queries := r.db.Queries(ctx)
+
+	dbDeployments, err := queries.GetPinnedDeploymentsByProjectID(ctx, projectID.UUID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pinned deployments: %w", err)
+	}
+
+	deployments := make([]*deployment.Deployment, len(dbDeployments))
+	for i, dbDeployment := range dbDeployments {
+		domainDeployment, err := r.toDomain(dbDeployment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert deployment: %w", err)
+		}
+		deployments[i] = domainDeployment
+	}
+
+	return deployments, nil
+}
+
+// FindStuck retrieves up to limit in-progress or interrupted deployments last updated before the cutoff
+func (r *DeploymentRepositoryImpl) FindStuck(ctx context.Context, cutoff time.Time, limit int32) ([]*deployment.Deployment, error) {
+	queries := r.db.Queries(ctx)
+
+	dbDeployments, err := queries.GetStuckDeployments(ctx, &database.GetStuckDeploymentsParams{
+		UpdatedAt: sql.NullTime{Time: cutoff, Valid: true},
+		Limit:     limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stuck deployments: %w", err)
+	}
+
+	deployments := make([]*deployment.Deployment, len(dbDeployments))
+	for i, dbDeployment := range dbDeployments {
+		domainDeployment, err := r.toDomain(dbDeployment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert deployment: %w", err)
+		}
+		deployments[i] = domainDeployment
+	}
+
+	return deployments, nil
+}
+
+// ArchiveBefore moves up to limit finished deployments created before the cutoff to deployments_archive
+func (r *DeploymentRepositoryImpl) ArchiveBefore(ctx context.Context, cutoff time.Time, limit int32) (int64, error) {
+	queries := r.db.Queries(ctx)
+
+	archived, err := queries.ArchiveDeployments(ctx, &database.ArchiveDeploymentsParams{
+		CreatedAt: sql.NullTime{Time: cutoff, Valid: true},
+		Limit:     limit,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive deployments: %w", err)
+	}
+
+	return archived, nil
+}
+
 // toDomain converts database deployment to domain deployment
+// toDomainList converts database deployments to domain entities
+func (r *DeploymentRepositoryImpl) toDomainList(dbDeployments []*database.Deployment) ([]*deployment.Deployment, error) {
+	deployments := make([]*deployment.Deployment, len(dbDeployments))
+	for i, dbDeployment := range dbDeployments {
+		domainDeployment, err := r.toDomain(dbDeployment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert deployment: %w", err)
+		}
+		deployments[i] = domainDeployment
+	}
+	return deployments, nil
+}
+
 func (r *DeploymentRepositoryImpl) toDomain(dbDeployment *database.Deployment) (*deployment.Deployment, error) {
 	projectID, err := project.ParseProjectID(dbDeployment.ProjectID.String())
 	if err != nil {
@@ -198,16 +651,30 @@ func (r *DeploymentRepositoryImpl) toDomain(dbDeployment *database.Deployment) (
 		logs = dbDeployment.Logs.String
 	}
 
+	var labels map[string]string
+	if len(dbDeployment.Labels) > 0 {
+		if err := json.Unmarshal(dbDeployment.Labels, &labels); err != nil {
+			return nil, fmt.Errorf("failed to decode labels: %w", err)
+		}
+	}
+
 	return deployment.Reconstitute(
 		dbDeployment.ID.String(),
 		projectID,
 		userID,
 		dbDeployment.CommitHash,
 		dbDeployment.Branch,
+		dbDeployment.Type,
 		dbDeployment.Status,
 		logs,
 		createdAt,
 		updatedAt,
+		fromNullTime(dbDeployment.DeletedAt),
+		dbDeployment.Environment,
+		dbDeployment.ImageUri,
+		dbDeployment.ImageDigest,
+		deployment.Annotations{Title: dbDeployment.Title, Description: dbDeployment.Description, Labels: labels},
+		dbDeployment.Pinned,
 	)
 }
 
@@ -0,0 +1,96 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// EnvChangeRepositoryImpl implements the domain deployment.EnvChangeRepository interface
+type EnvChangeRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewEnvChangeRepository creates a new environment variable change repository implementation
+func NewEnvChangeRepository(db *database.DB) deployment.EnvChangeRepository {
+	return &EnvChangeRepositoryImpl{db: db}
+}
+
+// RecordChange records that an environment's variables changed, pending until a deployment picks it up
+func (r *EnvChangeRepositoryImpl) RecordChange(ctx context.Context, projectID project.ProjectID, env project.Environment, changedAt time.Time) error {
+	queries := r.db.Queries(ctx)
+
+	err := queries.UpsertEnvironmentVariableChange(ctx, &database.UpsertEnvironmentVariableChangeParams{
+		ProjectID:   projectID.UUID(),
+		Environment: env.String(),
+		ChangedAt:   changedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record environment variable change: %w", err)
+	}
+
+	return nil
+}
+
+// MarkApplied records that a deployment created at createdAt succeeded, picking up the environment's
+// pending change if it was made before then
+func (r *EnvChangeRepositoryImpl) MarkApplied(ctx context.Context, projectID project.ProjectID, env project.Environment, deploymentID deployment.DeploymentID, createdAt time.Time) (bool, error) {
+	queries := r.db.Queries(ctx)
+
+	applied, err := queries.ApplyEnvironmentVariableChange(ctx, &database.ApplyEnvironmentVariableChangeParams{
+		ProjectID:           projectID.UUID(),
+		Environment:         env.String(),
+		AppliedDeploymentID: uuid.NullUUID{UUID: deploymentID.UUID(), Valid: true},
+		AppliedAt:           sql.NullTime{Time: time.Now(), Valid: true},
+		ChangedAt:           createdAt,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to mark environment variable change applied: %w", err)
+	}
+
+	return applied > 0, nil
+}
+
+// Find retrieves the latest change to an environment's variables, or nil if none was recorded
+func (r *EnvChangeRepositoryImpl) Find(ctx context.Context, projectID project.ProjectID, env project.Environment) (*deployment.EnvChange, error) {
+	queries := r.db.Queries(ctx)
+
+	dbChange, err := queries.GetEnvironmentVariableChange(ctx, &database.GetEnvironmentVariableChangeParams{
+		ProjectID:   projectID.UUID(),
+		Environment: env.String(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get environment variable change: %w", err)
+	}
+
+	change := &deployment.EnvChange{
+		ProjectID:   projectID,
+		Environment: env,
+		ChangedAt:   dbChange.ChangedAt,
+	}
+	if dbChange.AppliedDeploymentID.Valid {
+		appliedBy, err := deployment.ParseDeploymentID(dbChange.AppliedDeploymentID.UUID.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid deployment ID: %w", err)
+		}
+		change.AppliedBy = &appliedBy
+	}
+	if dbChange.AppliedAt.Valid {
+		appliedAt := dbChange.AppliedAt.Time
+		change.AppliedAt = &appliedAt
+	}
+
+	return change, nil
+}
@@ -0,0 +1,93 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/infrastructure/encryption"
+
+	"github.com/google/uuid"
+)
+
+// EnvVarReencrypter re-encrypts the stored values of environment variables with the active encryption key
+type EnvVarReencrypter struct {
+	db                *database.DB
+	encryptionService *encryption.EncryptionService
+}
+
+// NewEnvVarReencrypter creates a new environment variable re-encrypter
+func NewEnvVarReencrypter(db *database.DB, encryptionService *encryption.EncryptionService) *EnvVarReencrypter {
+	return &EnvVarReencrypter{
+		db:                db,
+		encryptionService: encryptionService,
+	}
+}
+
+// ActiveKeyID returns the ID of the key values are re-encrypted with
+func (r *EnvVarReencrypter) ActiveKeyID() string {
+	return r.encryptionService.ActiveKeyID()
+}
+
+// CountByKey counts the stored values by the ID of the key they are encrypted with
+func (r *EnvVarReencrypter) CountByKey(ctx context.Context) (map[string]int64, error) {
+	rows, err := r.db.Queries(ctx).CountProjectEnvVarsByKeyID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count environment variables by key: %w", err)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.KeyID] = row.Count
+	}
+	return counts, nil
+}
+
+// ReencryptBatch re-encrypts up to limit values not encrypted with the active key, after the cursor a previous
+// batch returned, or from the start with an empty one. It returns the cursor of the next batch, empty once no
+// values are left. Values that can't be decrypted are skipped and counted as failed; values changed since
+// they were read already use the active key and are left alone.
+func (r *EnvVarReencrypter) ReencryptBatch(ctx context.Context, cursor string, limit int) (next string, reencrypted, failed int, err error) {
+	after := uuid.Nil
+	if cursor != "" {
+		if after, err = uuid.Parse(cursor); err != nil {
+			return "", 0, 0, fmt.Errorf("invalid cursor: %w", err)
+		}
+	}
+
+	queries := r.db.Queries(ctx)
+	rows, err := queries.ListProjectEnvVarsToReencrypt(ctx, &database.ListProjectEnvVarsToReencryptParams{
+		AfterID:   after,
+		KeyID:     r.encryptionService.ActiveKeyID(),
+		BatchSize: int32(limit),
+	})
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to list environment variables to re-encrypt: %w", err)
+	}
+
+	for _, row := range rows {
+		value, err := r.encryptionService.Reencrypt(row.Value)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to re-encrypt environment variable", "env_var_id", row.ID.String(),
+				"key_id", r.encryptionService.KeyID(row.Value), "error", err)
+			failed++
+			continue
+		}
+
+		updated, err := queries.ReencryptProjectEnvVar(ctx, &database.ReencryptProjectEnvVarParams{
+			Value:         value,
+			ID:            row.ID,
+			PreviousValue: row.Value,
+		})
+		if err != nil {
+			return "", reencrypted, failed, fmt.Errorf("failed to save re-encrypted environment variable: %w", err)
+		}
+		reencrypted += int(updated)
+	}
+
+	if len(rows) < limit {
+		return "", reencrypted, failed, nil
+	}
+	return rows[len(rows)-1].ID.String(), reencrypted, failed, nil
+}
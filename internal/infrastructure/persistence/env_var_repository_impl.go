@@ -2,12 +2,14 @@ package persistence
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
 
 	"snapdeploy-core/internal/database"
 	"snapdeploy-core/internal/domain/project"
 	"snapdeploy-core/internal/infrastructure/encryption"
+
+	"github.com/jackc/pgx/v5"
 )
 
 // EnvVarRepositoryImpl implements the project.EnvironmentVariableRepository interface
@@ -26,7 +28,7 @@ func NewEnvVarRepository(db *database.DB, encryptionService *encryption.Encrypti
 
 // Save persists an environment variable (create or update)
 func (r *EnvVarRepositoryImpl) Save(ctx context.Context, envVar *project.EnvironmentVariable) error {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	// Encrypt the value before storing
 	encryptedValue, err := r.encryptionService.Encrypt(envVar.Value().EncryptedValue())
@@ -36,16 +38,19 @@ func (r *EnvVarRepositoryImpl) Save(ctx context.Context, envVar *project.Environ
 
 	// Check if environment variable exists
 	_, err = queries.GetProjectEnvVar(ctx, &database.GetProjectEnvVarParams{
-		ProjectID: envVar.ProjectID().UUID(),
-		Key:       envVar.Key().String(),
+		ProjectID:   envVar.ProjectID().UUID(),
+		Key:         envVar.Key().String(),
+		Environment: envVar.Environment().String(),
 	})
 
-	if err == sql.ErrNoRows {
+	if errors.Is(err, pgx.ErrNoRows) {
 		// Create new
 		_, err = queries.CreateProjectEnvVar(ctx, &database.CreateProjectEnvVarParams{
-			ProjectID: envVar.ProjectID().UUID(),
-			Key:       envVar.Key().String(),
-			Value:     encryptedValue,
+			ProjectID:   envVar.ProjectID().UUID(),
+			Key:         envVar.Key().String(),
+			Value:       encryptedValue,
+			Scope:       envVar.Scope().String(),
+			Environment: envVar.Environment().String(),
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create environment variable: %w", err)
@@ -53,9 +58,11 @@ func (r *EnvVarRepositoryImpl) Save(ctx context.Context, envVar *project.Environ
 	} else if err == nil {
 		// Update existing
 		_, err = queries.UpdateProjectEnvVar(ctx, &database.UpdateProjectEnvVarParams{
-			ProjectID: envVar.ProjectID().UUID(),
-			Key:       envVar.Key().String(),
-			Value:     encryptedValue,
+			ProjectID:   envVar.ProjectID().UUID(),
+			Key:         envVar.Key().String(),
+			Value:       encryptedValue,
+			Scope:       envVar.Scope().String(),
+			Environment: envVar.Environment().String(),
 		})
 		if err != nil {
 			return fmt.Errorf("failed to update environment variable: %w", err)
@@ -67,11 +74,14 @@ func (r *EnvVarRepositoryImpl) Save(ctx context.Context, envVar *project.Environ
 	return nil
 }
 
-// FindByProjectID retrieves all environment variables for a project
-func (r *EnvVarRepositoryImpl) FindByProjectID(ctx context.Context, projectID project.ProjectID) ([]*project.EnvironmentVariable, error) {
-	queries := database.New(r.db.GetConnection())
+// FindByProjectID retrieves all environment variables of a project's environment
+func (r *EnvVarRepositoryImpl) FindByProjectID(ctx context.Context, projectID project.ProjectID, env project.Environment) ([]*project.EnvironmentVariable, error) {
+	queries := r.db.Queries(ctx)
 
-	dbEnvVars, err := queries.GetProjectEnvVars(ctx, projectID.UUID())
+	dbEnvVars, err := queries.GetProjectEnvVars(ctx, &database.GetProjectEnvVarsParams{
+		ProjectID:   projectID.UUID(),
+		Environment: env.String(),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get environment variables: %w", err)
 	}
@@ -88,16 +98,17 @@ func (r *EnvVarRepositoryImpl) FindByProjectID(ctx context.Context, projectID pr
 	return envVars, nil
 }
 
-// FindByKey retrieves a specific environment variable by project and key
-func (r *EnvVarRepositoryImpl) FindByKey(ctx context.Context, projectID project.ProjectID, key project.EnvVarKey) (*project.EnvironmentVariable, error) {
-	queries := database.New(r.db.GetConnection())
+// FindByKey retrieves a specific environment variable by project, environment and key
+func (r *EnvVarRepositoryImpl) FindByKey(ctx context.Context, projectID project.ProjectID, env project.Environment, key project.EnvVarKey) (*project.EnvironmentVariable, error) {
+	queries := r.db.Queries(ctx)
 
 	dbEnvVar, err := queries.GetProjectEnvVar(ctx, &database.GetProjectEnvVarParams{
-		ProjectID: projectID.UUID(),
-		Key:       key.String(),
+		ProjectID:   projectID.UUID(),
+		Key:         key.String(),
+		Environment: env.String(),
 	})
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, project.ErrEnvVarNotFound
 		}
 		return nil, fmt.Errorf("failed to get environment variable: %w", err)
@@ -107,12 +118,13 @@ func (r *EnvVarRepositoryImpl) FindByKey(ctx context.Context, projectID project.
 }
 
 // Delete removes an environment variable
-func (r *EnvVarRepositoryImpl) Delete(ctx context.Context, projectID project.ProjectID, key project.EnvVarKey) error {
-	queries := database.New(r.db.GetConnection())
+func (r *EnvVarRepositoryImpl) Delete(ctx context.Context, projectID project.ProjectID, env project.Environment, key project.EnvVarKey) error {
+	queries := r.db.Queries(ctx)
 
 	err := queries.DeleteProjectEnvVar(ctx, &database.DeleteProjectEnvVarParams{
-		ProjectID: projectID.UUID(),
-		Key:       key.String(),
+		ProjectID:   projectID.UUID(),
+		Key:         key.String(),
+		Environment: env.String(),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to delete environment variable: %w", err)
@@ -121,9 +133,24 @@ func (r *EnvVarRepositoryImpl) Delete(ctx context.Context, projectID project.Pro
 	return nil
 }
 
+// DeleteEnvironment removes all environment variables of a project's environment
+func (r *EnvVarRepositoryImpl) DeleteEnvironment(ctx context.Context, projectID project.ProjectID, env project.Environment) error {
+	queries := r.db.Queries(ctx)
+
+	err := queries.DeleteProjectEnvironmentEnvVars(ctx, &database.DeleteProjectEnvironmentEnvVarsParams{
+		ProjectID:   projectID.UUID(),
+		Environment: env.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete environment variables of %s: %w", env, err)
+	}
+
+	return nil
+}
+
 // DeleteAll removes all environment variables for a project
 func (r *EnvVarRepositoryImpl) DeleteAll(ctx context.Context, projectID project.ProjectID) error {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	err := queries.DeleteAllProjectEnvVars(ctx, projectID.UUID())
 	if err != nil {
@@ -133,11 +160,14 @@ func (r *EnvVarRepositoryImpl) DeleteAll(ctx context.Context, projectID project.
 	return nil
 }
 
-// Count returns the number of environment variables for a project
-func (r *EnvVarRepositoryImpl) Count(ctx context.Context, projectID project.ProjectID) (int64, error) {
-	queries := database.New(r.db.GetConnection())
+// Count returns the number of environment variables of a project's environment
+func (r *EnvVarRepositoryImpl) Count(ctx context.Context, projectID project.ProjectID, env project.Environment) (int64, error) {
+	queries := r.db.Queries(ctx)
 
-	count, err := queries.CountProjectEnvVars(ctx, projectID.UUID())
+	count, err := queries.CountProjectEnvVars(ctx, &database.CountProjectEnvVarsParams{
+		ProjectID:   projectID.UUID(),
+		Environment: env.String(),
+	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to count environment variables: %w", err)
 	}
@@ -152,6 +182,8 @@ func (r *EnvVarRepositoryImpl) toDomain(dbEnvVar *database.ProjectEnvironmentVar
 		projectID,
 		dbEnvVar.Key,
 		dbEnvVar.Value, // Still encrypted
+		dbEnvVar.Scope,
+		dbEnvVar.Environment,
 		dbEnvVar.CreatedAt,
 		dbEnvVar.UpdatedAt,
 	)
@@ -162,15 +194,59 @@ func (r *EnvVarRepositoryImpl) DecryptValue(ctx context.Context, envVar *project
 	return r.encryptionService.Decrypt(envVar.Value().EncryptedValue())
 }
 
-// DecryptAll decrypts all environment variables for a project (used for deployments)
-func (r *EnvVarRepositoryImpl) DecryptAll(ctx context.Context, projectID project.ProjectID) (map[string]string, error) {
-	envVars, err := r.FindByProjectID(ctx, projectID)
+// DecryptRuntime decrypts the environment variables passed to the containers of a project's environment
+// (used for deployments)
+func (r *EnvVarRepositoryImpl) DecryptRuntime(ctx context.Context, projectID project.ProjectID, env project.Environment) (map[string]string, error) {
+	return r.decryptScoped(ctx, projectID, env, project.EnvVarScope.AtRuntime)
+}
+
+// DecryptBuild decrypts the environment variables passed to the image build of a project's environment as build args
+func (r *EnvVarRepositoryImpl) DecryptBuild(ctx context.Context, projectID project.ProjectID, env project.Environment) (map[string]string, error) {
+	return r.decryptScoped(ctx, projectID, env, project.EnvVarScope.AtBuild)
+}
+
+// SecretValues returns the values of the environment variables of a project's environment, whatever their
+// scope (used to mask them in deployment logs)
+func (r *EnvVarRepositoryImpl) SecretValues(ctx context.Context, projectID project.ProjectID, env project.Environment) ([]string, error) {
+	values, err := r.decryptScoped(ctx, projectID, env, func(project.EnvVarScope) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make([]string, 0, len(values))
+	for _, value := range values {
+		secrets = append(secrets, value)
+	}
+	return secrets, nil
+}
+
+// DigestValues returns a keyed digest of the value of each environment variable of a project's environment by
+// name, whatever its scope (used to record what a deployment was built with without storing the values)
+func (r *EnvVarRepositoryImpl) DigestValues(ctx context.Context, projectID project.ProjectID, env project.Environment) (map[string]string, error) {
+	values, err := r.decryptScoped(ctx, projectID, env, func(project.EnvVarScope) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	digests := make(map[string]string, len(values))
+	for key, value := range values {
+		digests[key] = r.encryptionService.Digest(value)
+	}
+	return digests, nil
+}
+
+// decryptScoped decrypts the environment variables of a project's environment whose scope is accepted
+func (r *EnvVarRepositoryImpl) decryptScoped(ctx context.Context, projectID project.ProjectID, env project.Environment, accept func(project.EnvVarScope) bool) (map[string]string, error) {
+	envVars, err := r.FindByProjectID(ctx, projectID, env)
 	if err != nil {
 		return nil, err
 	}
 
 	result := make(map[string]string)
 	for _, envVar := range envVars {
+		if !accept(envVar.Scope()) {
+			continue
+		}
 		plaintext, err := r.encryptionService.Decrypt(envVar.Value().EncryptedValue())
 		if err != nil {
 			return nil, fmt.Errorf("failed to decrypt %s: %w", envVar.Key().String(), err)
@@ -180,4 +256,3 @@ func (r *EnvVarRepositoryImpl) DecryptAll(ctx context.Context, projectID project
 
 	return result, nil
 }
-
@@ -0,0 +1,43 @@
+package persistence
+
+import (
+	"context"
+	"log/slog"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/events"
+)
+
+// EventPublishingDeploymentRepository dispatches the domain events recorded by a deployment once it has been saved
+type EventPublishingDeploymentRepository struct {
+	deployment.DeploymentRepository
+	dispatcher *events.Dispatcher
+}
+
+// NewEventPublishingDeploymentRepository wraps a deployment repository so saves publish domain events
+func NewEventPublishingDeploymentRepository(repo deployment.DeploymentRepository, dispatcher *events.Dispatcher) deployment.DeploymentRepository {
+	return &EventPublishingDeploymentRepository{
+		DeploymentRepository: repo,
+		dispatcher:           dispatcher,
+	}
+}
+
+// Save persists a deployment, then publishes its events once the save is committed.
+// Events stay on the deployment if the save fails, so they are published by the next successful save.
+func (r *EventPublishingDeploymentRepository) Save(ctx context.Context, dep *deployment.Deployment) error {
+	if err := r.DeploymentRepository.Save(ctx, dep); err != nil {
+		return err
+	}
+
+	pending := dep.PullEvents()
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		for _, event := range pending {
+			if err := r.dispatcher.Dispatch(ctx, event); err != nil {
+				slog.ErrorContext(ctx, "Failed to publish event", "event_type", event.EventType(), "deployment_id", event.AggregateID(), "error", err)
+			}
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/monitor"
+	"snapdeploy-core/internal/domain/project"
+)
+
+// HealthCheckRepositoryImpl implements the domain monitor.CheckRepository interface
+type HealthCheckRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewHealthCheckRepository creates a new health check repository implementation
+func NewHealthCheckRepository(db *database.DB) monitor.CheckRepository {
+	return &HealthCheckRepositoryImpl{db: db}
+}
+
+// Save records a health check
+func (r *HealthCheckRepositoryImpl) Save(ctx context.Context, check monitor.Check) error {
+	queries := r.db.Queries(ctx)
+
+	err := queries.CreateHealthCheck(ctx, &database.CreateHealthCheckParams{
+		ProjectID:  check.ProjectID.UUID(),
+		Url:        check.URL,
+		Healthy:    check.Healthy,
+		StatusCode: int32(check.StatusCode),
+		LatencyMs:  int32(check.LatencyMS),
+		Error:      check.Error,
+		CheckedAt:  check.CheckedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save health check: %w", err)
+	}
+
+	return nil
+}
+
+// FindRecent retrieves the most recent health checks of a project, newest first
+func (r *HealthCheckRepositoryImpl) FindRecent(ctx context.Context, projectID project.ProjectID, limit int) ([]monitor.Check, error) {
+	queries := r.db.Queries(ctx)
+
+	dbChecks, err := queries.ListRecentHealthChecks(ctx, &database.ListRecentHealthChecksParams{
+		ProjectID: projectID.UUID(),
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get health checks: %w", err)
+	}
+
+	checks := make([]monitor.Check, len(dbChecks))
+	for i, dbCheck := range dbChecks {
+		checks[i] = monitor.Check{
+			ProjectID:  projectID,
+			URL:        dbCheck.Url,
+			Healthy:    dbCheck.Healthy,
+			StatusCode: int(dbCheck.StatusCode),
+			LatencyMS:  int(dbCheck.LatencyMs),
+			Error:      dbCheck.Error,
+			CheckedAt:  dbCheck.CheckedAt,
+		}
+	}
+
+	return checks, nil
+}
+
+// CountSince counts the health checks of a project made since the time, and those that succeeded
+func (r *HealthCheckRepositoryImpl) CountSince(ctx context.Context, projectID project.ProjectID, since time.Time) (int64, int64, error) {
+	queries := r.db.Queries(ctx)
+
+	counts, err := queries.CountHealthChecksSince(ctx, &database.CountHealthChecksSinceParams{
+		ProjectID: projectID.UUID(),
+		CheckedAt: since,
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count health checks: %w", err)
+	}
+
+	return counts.Succeeded, counts.Total, nil
+}
+
+// DeleteBefore removes the health checks of every project made before the cutoff
+func (r *HealthCheckRepositoryImpl) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	queries := r.db.Queries(ctx)
+
+	deleted, err := queries.DeleteHealthChecksBefore(ctx, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete health checks: %w", err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,115 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/idempotency"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// IdempotencyRepositoryImpl implements the domain idempotency.RecordRepository interface
+type IdempotencyRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewIdempotencyRepository creates a new idempotency record repository implementation
+func NewIdempotencyRepository(db *database.DB) idempotency.RecordRepository {
+	return &IdempotencyRepositoryImpl{db: db}
+}
+
+// Reserve persists a new in-progress record unless the owner already has a live one for the key
+func (r *IdempotencyRepositoryImpl) Reserve(ctx context.Context, record *idempotency.Record, staleBefore time.Time) (bool, error) {
+	queries := r.db.Queries(ctx)
+
+	reserved, err := queries.ReserveIdempotencyKey(ctx, &database.ReserveIdempotencyKeyParams{
+		OwnerID:        record.OwnerID(),
+		IdempotencyKey: record.Key().String(),
+		Route:          record.Route(),
+		RequestHash:    record.RequestHash(),
+		CreatedAt:      record.CreatedAt(),
+		ExpiresAt:      record.ExpiresAt(),
+		StaleBefore:    staleBefore,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	return reserved > 0, nil
+}
+
+// FindByKey retrieves the owner's record for a key
+func (r *IdempotencyRepositoryImpl) FindByKey(ctx context.Context, ownerID string, key idempotency.Key) (*idempotency.Record, error) {
+	queries := r.db.Queries(ctx)
+
+	dbRecord, err := queries.GetIdempotencyKey(ctx, &database.GetIdempotencyKeyParams{
+		OwnerID:        ownerID,
+		IdempotencyKey: key.String(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, idempotency.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return idempotency.Reconstitute(
+		dbRecord.OwnerID,
+		dbRecord.IdempotencyKey,
+		dbRecord.Route,
+		dbRecord.RequestHash,
+		int(dbRecord.StatusCode.Int32),
+		dbRecord.ResponseBody,
+		dbRecord.CreatedAt,
+		dbRecord.ExpiresAt,
+	)
+}
+
+// Save persists a record's response
+func (r *IdempotencyRepositoryImpl) Save(ctx context.Context, record *idempotency.Record) error {
+	queries := r.db.Queries(ctx)
+
+	err := queries.CompleteIdempotencyKey(ctx, &database.CompleteIdempotencyKeyParams{
+		OwnerID:        record.OwnerID(),
+		IdempotencyKey: record.Key().String(),
+		StatusCode:     sql.NullInt32{Int32: int32(record.StatusCode()), Valid: record.IsComplete()},
+		ResponseBody:   record.ResponseBody(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the owner's record for a key
+func (r *IdempotencyRepositoryImpl) Delete(ctx context.Context, ownerID string, key idempotency.Key) error {
+	queries := r.db.Queries(ctx)
+
+	err := queries.DeleteIdempotencyKey(ctx, &database.DeleteIdempotencyKeyParams{
+		OwnerID:        ownerID,
+		IdempotencyKey: key.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes records that expired at or before the given time
+func (r *IdempotencyRepositoryImpl) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	queries := r.db.Queries(ctx)
+
+	deleted, err := queries.DeleteExpiredIdempotencyKeys(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,158 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/incident"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// IncidentRepositoryImpl implements the domain incident.IncidentRepository interface
+type IncidentRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewIncidentRepository creates a new incident repository implementation
+func NewIncidentRepository(db *database.DB) incident.IncidentRepository {
+	return &IncidentRepositoryImpl{db: db}
+}
+
+// Save persists an incident (create or update)
+func (r *IncidentRepositoryImpl) Save(ctx context.Context, inc *incident.Incident) error {
+	queries := r.db.Queries(ctx)
+
+	// Check if incident exists
+	_, err := queries.GetSystemIncidentByID(ctx, inc.ID().UUID())
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to check if incident exists: %w", err)
+	}
+
+	// If no error, incident exists - update it
+	if err == nil {
+		err := queries.UpdateSystemIncident(ctx, &database.UpdateSystemIncidentParams{
+			ID:         inc.ID().UUID(),
+			Severity:   inc.Severity().String(),
+			Title:      inc.Title().String(),
+			Message:    inc.Message().String(),
+			EndsAt:     toNullTime(inc.EndsAt()),
+			ResolvedAt: toNullTime(inc.ResolvedAt()),
+			UpdatedAt:  inc.UpdatedAt(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update incident: %w", err)
+		}
+	} else {
+		// Incident doesn't exist (err == pgx.ErrNoRows) - create it
+		_, err := queries.CreateSystemIncident(ctx, &database.CreateSystemIncidentParams{
+			ID:         inc.ID().UUID(),
+			Kind:       inc.Kind().String(),
+			Severity:   inc.Severity().String(),
+			Title:      inc.Title().String(),
+			Message:    inc.Message().String(),
+			StartsAt:   inc.StartsAt(),
+			EndsAt:     toNullTime(inc.EndsAt()),
+			ResolvedAt: toNullTime(inc.ResolvedAt()),
+			CreatedBy:  inc.CreatedBy(),
+			CreatedAt:  inc.CreatedAt(),
+			UpdatedAt:  inc.UpdatedAt(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create incident: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// FindByID retrieves an incident by its ID
+func (r *IncidentRepositoryImpl) FindByID(ctx context.Context, id incident.IncidentID) (*incident.Incident, error) {
+	queries := r.db.Queries(ctx)
+
+	dbIncident, err := queries.GetSystemIncidentByID(ctx, id.UUID())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, incident.ErrIncidentNotFound
+		}
+		return nil, fmt.Errorf("failed to get incident: %w", err)
+	}
+
+	return r.toDomain(dbIncident)
+}
+
+// FindUnresolved retrieves all incidents that have not been resolved, including scheduled ones
+func (r *IncidentRepositoryImpl) FindUnresolved(ctx context.Context) ([]*incident.Incident, error) {
+	queries := r.db.Queries(ctx)
+
+	dbIncidents, err := queries.ListUnresolvedSystemIncidents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unresolved incidents: %w", err)
+	}
+
+	return r.toDomainList(dbIncidents)
+}
+
+// FindResolvedSince retrieves incidents resolved at or after the given time
+func (r *IncidentRepositoryImpl) FindResolvedSince(ctx context.Context, since time.Time) ([]*incident.Incident, error) {
+	queries := r.db.Queries(ctx)
+
+	dbIncidents, err := queries.ListSystemIncidentsResolvedSince(ctx, sql.NullTime{Time: since, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resolved incidents: %w", err)
+	}
+
+	return r.toDomainList(dbIncidents)
+}
+
+// toDomainList converts a list of database incidents to domain incidents
+func (r *IncidentRepositoryImpl) toDomainList(dbIncidents []*database.SystemIncident) ([]*incident.Incident, error) {
+	incidents := make([]*incident.Incident, len(dbIncidents))
+	for i, dbIncident := range dbIncidents {
+		domainIncident, err := r.toDomain(dbIncident)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert incident: %w", err)
+		}
+		incidents[i] = domainIncident
+	}
+
+	return incidents, nil
+}
+
+// toDomain converts database incident to domain incident
+func (r *IncidentRepositoryImpl) toDomain(dbIncident *database.SystemIncident) (*incident.Incident, error) {
+	return incident.Reconstitute(
+		dbIncident.ID.String(),
+		dbIncident.Kind,
+		dbIncident.Severity,
+		dbIncident.Title,
+		dbIncident.Message,
+		dbIncident.StartsAt,
+		fromNullTime(dbIncident.EndsAt),
+		fromNullTime(dbIncident.ResolvedAt),
+		dbIncident.CreatedBy,
+		dbIncident.CreatedAt,
+		dbIncident.UpdatedAt,
+	)
+}
+
+// toNullTime converts an optional time to a nullable database time
+func toNullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+// fromNullTime converts a nullable database time to an optional time
+func fromNullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	value := t.Time
+	return &value
+}
@@ -0,0 +1,122 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/domain/user"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// InstallationRepositoryImpl implements the domain repo.InstallationRepo interface
+type InstallationRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewInstallationRepository creates a new GitHub App installation repository implementation
+func NewInstallationRepository(db *database.DB) repo.InstallationRepo {
+	return &InstallationRepositoryImpl{db: db}
+}
+
+// Save persists an installation (create or update)
+func (r *InstallationRepositoryImpl) Save(ctx context.Context, installation *repo.Installation) error {
+	queries := r.db.Queries(ctx)
+
+	var userID uuid.NullUUID
+	if installation.UserID() != nil {
+		userID = uuid.NullUUID{UUID: installation.UserID().UUID(), Valid: true}
+	}
+
+	err := queries.UpsertGitHubInstallation(ctx, &database.UpsertGitHubInstallationParams{
+		ID:            installation.ID(),
+		UserID:        userID,
+		AccountID:     installation.AccountID(),
+		AccountLogin:  installation.AccountLogin(),
+		AccountType:   installation.AccountType(),
+		InstalledByID: installation.InstalledByID(),
+		Suspended:     installation.IsSuspended(),
+		CreatedAt:     installation.CreatedAt(),
+		UpdatedAt:     installation.UpdatedAt(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save GitHub installation: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves an installation by its GitHub installation ID
+func (r *InstallationRepositoryImpl) FindByID(ctx context.Context, id int64) (*repo.Installation, error) {
+	queries := r.db.Queries(ctx)
+
+	dbInstallation, err := queries.GetGitHubInstallation(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repo.ErrInstallationNotFound(id)
+		}
+		return nil, fmt.Errorf("failed to get GitHub installation: %w", err)
+	}
+
+	return r.toDomain(dbInstallation)
+}
+
+// FindByUserID retrieves the installations claimed by a user
+func (r *InstallationRepositoryImpl) FindByUserID(ctx context.Context, userID user.UserID) ([]*repo.Installation, error) {
+	queries := r.db.Queries(ctx)
+
+	dbInstallations, err := queries.ListGitHubInstallationsByUserID(ctx, uuid.NullUUID{UUID: userID.UUID(), Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitHub installations: %w", err)
+	}
+
+	installations := make([]*repo.Installation, len(dbInstallations))
+	for i, dbInstallation := range dbInstallations {
+		installation, err := r.toDomain(dbInstallation)
+		if err != nil {
+			return nil, err
+		}
+		installations[i] = installation
+	}
+
+	return installations, nil
+}
+
+// Delete removes an installation from persistence
+func (r *InstallationRepositoryImpl) Delete(ctx context.Context, id int64) error {
+	queries := r.db.Queries(ctx)
+
+	if err := queries.DeleteGitHubInstallation(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete GitHub installation: %w", err)
+	}
+
+	return nil
+}
+
+// toDomain converts a database installation to a domain installation
+func (r *InstallationRepositoryImpl) toDomain(dbInstallation *database.GithubInstallation) (*repo.Installation, error) {
+	var userID *user.UserID
+	if dbInstallation.UserID.Valid {
+		uid, err := user.ParseUserID(dbInstallation.UserID.UUID.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID: %w", err)
+		}
+		userID = &uid
+	}
+
+	return repo.ReconstituteInstallation(
+		dbInstallation.ID,
+		dbInstallation.AccountID,
+		dbInstallation.AccountLogin,
+		dbInstallation.AccountType,
+		dbInstallation.InstalledByID,
+		userID,
+		dbInstallation.Suspended,
+		dbInstallation.CreatedAt,
+		dbInstallation.UpdatedAt,
+	), nil
+}
@@ -0,0 +1,77 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"snapdeploy-core/internal/database"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ListenerPriorityStoreImpl keeps the priorities allocated to load balancer listener rules in Postgres, so every
+// server instance allocates from the same table
+type ListenerPriorityStoreImpl struct {
+	db *database.DB
+}
+
+// NewListenerPriorityStore creates a new listener priority store
+func NewListenerPriorityStore(db *database.DB) *ListenerPriorityStoreImpl {
+	return &ListenerPriorityStoreImpl{db: db}
+}
+
+// FindPriority returns the priority allocated to a service's rule on a listener, 0 if it has none
+func (s *ListenerPriorityStoreImpl) FindPriority(ctx context.Context, listenerArn, serviceName string) (int32, error) {
+	priority, err := s.db.Queries(ctx).GetListenerRulePriority(ctx, &database.GetListenerRulePriorityParams{
+		ListenerArn: listenerArn,
+		ServiceName: serviceName,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get listener rule priority: %w", err)
+	}
+	return priority, nil
+}
+
+// AllocatedPriorities returns the priorities allocated on a listener
+func (s *ListenerPriorityStoreImpl) AllocatedPriorities(ctx context.Context, listenerArn string) ([]int32, error) {
+	priorities, err := s.db.Queries(ctx).ListListenerRulePriorities(ctx, listenerArn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list listener rule priorities: %w", err)
+	}
+	return priorities, nil
+}
+
+// ReservePriority allocates a priority to a service's rule on a listener. When the service already has one,
+// reserved first by a concurrent deployment, that one is returned instead. It returns 0 if another service was
+// allocated the priority first.
+func (s *ListenerPriorityStoreImpl) ReservePriority(ctx context.Context, listenerArn string, priority int32, serviceName string) (int32, error) {
+	reserved, err := s.db.Queries(ctx).ReserveListenerRulePriority(ctx, &database.ReserveListenerRulePriorityParams{
+		ListenerArn: listenerArn,
+		Priority:    priority,
+		ServiceName: serviceName,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve listener rule priority: %w", err)
+	}
+	if reserved == 1 {
+		return priority, nil
+	}
+	// The insert conflicted on the priority or on the service's rule, which keeps its priority
+	return s.FindPriority(ctx, listenerArn, serviceName)
+}
+
+// ReleasePriority frees the priority allocated to a service's rule on a listener, for another rule to reuse
+func (s *ListenerPriorityStoreImpl) ReleasePriority(ctx context.Context, listenerArn, serviceName string) error {
+	err := s.db.Queries(ctx).ReleaseListenerRulePriority(ctx, &database.ReleaseListenerRulePriorityParams{
+		ListenerArn: listenerArn,
+		ServiceName: serviceName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release listener rule priority: %w", err)
+	}
+	return nil
+}
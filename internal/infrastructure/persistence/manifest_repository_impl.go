@@ -0,0 +1,93 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ManifestRepositoryImpl implements the domain deployment.ManifestRepository interface
+type ManifestRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewManifestRepository creates a new manifest repository implementation
+func NewManifestRepository(db *database.DB) deployment.ManifestRepository {
+	return &ManifestRepositoryImpl{db: db}
+}
+
+// Save records a deployment's manifest, replacing the one recorded by an earlier attempt of its build
+func (r *ManifestRepositoryImpl) Save(ctx context.Context, manifest *deployment.Manifest) error {
+	queries := r.db.Queries(ctx)
+
+	envVars, err := marshalStringMap(manifest.EnvVars)
+	if err != nil {
+		return fmt.Errorf("failed to encode environment variables: %w", err)
+	}
+	config, err := marshalStringMap(manifest.Config)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	err = queries.UpsertDeploymentManifest(ctx, &database.UpsertDeploymentManifestParams{
+		DeploymentID: manifest.DeploymentID.UUID(),
+		ProjectID:    manifest.ProjectID.UUID(),
+		ImageTag:     manifest.ImageTag,
+		EnvVars:      envVars,
+		Config:       config,
+		CreatedAt:    manifest.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save deployment manifest: %w", err)
+	}
+
+	return nil
+}
+
+// FindByDeploymentID retrieves a deployment's manifest
+func (r *ManifestRepositoryImpl) FindByDeploymentID(ctx context.Context, deploymentID deployment.DeploymentID) (*deployment.Manifest, error) {
+	queries := r.db.Queries(ctx)
+
+	dbManifest, err := queries.GetDeploymentManifest(ctx, deploymentID.UUID())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, deployment.ErrManifestNotFound
+		}
+		return nil, fmt.Errorf("failed to get deployment manifest: %w", err)
+	}
+
+	projectID, err := project.ParseProjectID(dbManifest.ProjectID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &deployment.Manifest{
+		DeploymentID: deploymentID,
+		ProjectID:    projectID,
+		ImageTag:     dbManifest.ImageTag,
+		CreatedAt:    dbManifest.CreatedAt,
+	}
+	if err := json.Unmarshal(dbManifest.EnvVars, &manifest.EnvVars); err != nil {
+		return nil, fmt.Errorf("failed to decode environment variables: %w", err)
+	}
+	if err := json.Unmarshal(dbManifest.Config, &manifest.Config); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// marshalStringMap converts a map to the JSON object it is stored as, an empty object for a nil map
+func marshalStringMap(values map[string]string) ([]byte, error) {
+	if values == nil {
+		values = map[string]string{}
+	}
+	return json.Marshal(values)
+}
@@ -0,0 +1,105 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/monitor"
+	"snapdeploy-core/internal/domain/project"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ProjectIncidentRepositoryImpl implements the domain monitor.IncidentRepository interface
+type ProjectIncidentRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewProjectIncidentRepository creates a new project incident repository implementation
+func NewProjectIncidentRepository(db *database.DB) monitor.IncidentRepository {
+	return &ProjectIncidentRepositoryImpl{db: db}
+}
+
+// Save persists an incident (create or update)
+func (r *ProjectIncidentRepositoryImpl) Save(ctx context.Context, incident *monitor.Incident) error {
+	queries := r.db.Queries(ctx)
+
+	var resolvedAt sql.NullTime
+	if t := incident.ResolvedAt(); t != nil {
+		resolvedAt = sql.NullTime{Time: *t, Valid: true}
+	}
+
+	_, err := queries.UpsertProjectIncident(ctx, &database.UpsertProjectIncidentParams{
+		ID:           incident.ID().UUID(),
+		ProjectID:    incident.ProjectID().UUID(),
+		Cause:        incident.Cause(),
+		FailedChecks: int32(incident.FailedChecks()),
+		StartedAt:    incident.StartedAt(),
+		ResolvedAt:   resolvedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save incident: %w", err)
+	}
+
+	return nil
+}
+
+// FindOpenByProjectID retrieves the open incident of a project
+func (r *ProjectIncidentRepositoryImpl) FindOpenByProjectID(ctx context.Context, projectID project.ProjectID) (*monitor.Incident, error) {
+	queries := r.db.Queries(ctx)
+
+	dbIncident, err := queries.GetOpenProjectIncident(ctx, projectID.UUID())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, monitor.ErrIncidentNotFound
+		}
+		return nil, fmt.Errorf("failed to get open incident: %w", err)
+	}
+
+	return r.toDomain(dbIncident)
+}
+
+// FindByProjectID retrieves the most recent incidents of a project, newest first
+func (r *ProjectIncidentRepositoryImpl) FindByProjectID(ctx context.Context, projectID project.ProjectID, limit int) ([]*monitor.Incident, error) {
+	queries := r.db.Queries(ctx)
+
+	dbIncidents, err := queries.ListProjectIncidents(ctx, &database.ListProjectIncidentsParams{
+		ProjectID: projectID.UUID(),
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incidents: %w", err)
+	}
+
+	incidents := make([]*monitor.Incident, len(dbIncidents))
+	for i, dbIncident := range dbIncidents {
+		incident, err := r.toDomain(dbIncident)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert incident: %w", err)
+		}
+		incidents[i] = incident
+	}
+
+	return incidents, nil
+}
+
+// toDomain converts database incident to domain incident
+func (r *ProjectIncidentRepositoryImpl) toDomain(dbIncident *database.ProjectIncident) (*monitor.Incident, error) {
+	var resolvedAt *time.Time
+	if dbIncident.ResolvedAt.Valid {
+		resolvedAt = &dbIncident.ResolvedAt.Time
+	}
+
+	return monitor.ReconstituteIncident(
+		dbIncident.ID.String(),
+		dbIncident.ProjectID.String(),
+		dbIncident.Cause,
+		int(dbIncident.FailedChecks),
+		dbIncident.StartedAt,
+		resolvedAt,
+	)
+}
@@ -0,0 +1,24 @@
+package persistence
+
+import (
+	"context"
+
+	"snapdeploy-core/internal/database"
+)
+
+// ProjectLockerImpl serializes changes to a project's cloud resources with Postgres advisory locks, so they
+// are never interleaved, whichever server instance makes them
+type ProjectLockerImpl struct {
+	db *database.DB
+}
+
+// NewProjectLocker creates a new project locker
+func NewProjectLocker(db *database.DB) *ProjectLockerImpl {
+	return &ProjectLockerImpl{db: db}
+}
+
+// LockProject waits until no other change to a project's cloud resources is in progress and takes the lock.
+// The caller must call unlock once done.
+func (l *ProjectLockerImpl) LockProject(ctx context.Context, projectID string) (unlock func(), err error) {
+	return l.db.AdvisoryLock(ctx, "project_infrastructure:"+projectID)
+}
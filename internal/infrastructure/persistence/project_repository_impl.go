@@ -3,13 +3,30 @@ package persistence
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"snapdeploy-core/internal/database"
 	"snapdeploy-core/internal/domain/project"
 	"snapdeploy-core/internal/domain/user"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// customDomainIndex is the unique index keeping two projects from having the same custom domain
+const customDomainIndex = "idx_projects_custom_domain"
+
+// isUniqueViolation checks if err is a Postgres unique violation of an index
+func isUniqueViolation(err error, index string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == index
+}
+
 // ProjectRepositoryImpl implements the domain project.ProjectRepository interface
 type ProjectRepositoryImpl struct {
 	db *database.DB
@@ -22,86 +39,189 @@ func NewProjectRepository(db *database.DB) project.ProjectRepository {
 
 // Save persists a project (create or update)
 func (r *ProjectRepositoryImpl) Save(ctx context.Context, proj *project.Project) error {
-	queries := database.New(r.db.GetConnection())
-
-	// Check if project exists
-	_, err := queries.GetProjectByID(ctx, proj.ID().UUID())
-	if err != nil && err != sql.ErrNoRows {
-		return fmt.Errorf("failed to check if project exists: %w", err)
+	services, err := marshalServices(proj.Services())
+	if err != nil {
+		return err
+	}
+	// Every project has a production environment, only the others are stored
+	environments := environmentNames(proj.Environments()[1:])
+	protectedEnvironments := environmentNames(proj.ProtectedEnvironments())
+	deployRules, err := marshalDeployRules(proj.DeployRules())
+	if err != nil {
+		return err
 	}
 
-	// If no error, project exists - update it
-	if err == nil {
-		// Update existing project
-		buildCmd := sql.NullString{
-			String: proj.BuildCommand().String(),
-			Valid:  !proj.BuildCommand().IsEmpty(),
-		}
-		migrationCmd := sql.NullString{
-			String: proj.MigrationCommand().String(),
-			Valid:  !proj.MigrationCommand().IsEmpty(),
-		}
-		_, err := queries.UpdateProject(ctx, &database.UpdateProjectParams{
-			ID:               proj.ID().UUID(),
-			RepositoryUrl:    proj.RepositoryURL().String(),
-			InstallCommand:   proj.InstallCommand().String(),
-			BuildCommand:     buildCmd,
-			RunCommand:       proj.RunCommand().String(),
-			Language:         proj.Language().String(),
-			CustomDomain:     proj.CustomDomain().String(),
-			RequireDb:        proj.RequireDB(),
-			MigrationCommand: migrationCmd,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to update project: %w", err)
-		}
-	} else {
-		// Project doesn't exist (err == sql.ErrNoRows) - create it
-		buildCmd := sql.NullString{
-			String: proj.BuildCommand().String(),
-			Valid:  !proj.BuildCommand().IsEmpty(),
-		}
-		migrationCmd := sql.NullString{
-			String: proj.MigrationCommand().String(),
-			Valid:  !proj.MigrationCommand().IsEmpty(),
+	return r.db.InTx(ctx, func(ctx context.Context) error {
+		queries := r.db.Queries(ctx)
+
+		// Check if project exists
+		_, err := queries.GetProjectByID(ctx, proj.ID().UUID())
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to check if project exists: %w", err)
 		}
-		_, err := queries.CreateProject(ctx, &database.CreateProjectParams{
-			UserID:           proj.UserID().UUID(),
-			RepositoryUrl:    proj.RepositoryURL().String(),
-			InstallCommand:   proj.InstallCommand().String(),
-			BuildCommand:     buildCmd,
-			RunCommand:       proj.RunCommand().String(),
-			Language:         proj.Language().String(),
-			CustomDomain:     proj.CustomDomain().String(),
-			RequireDb:        proj.RequireDB(),
-			MigrationCommand: migrationCmd,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to create project: %w", err)
+
+		// If no error, project exists - update it
+		if err == nil {
+			// Update existing project
+			buildCmd := sql.NullString{
+				String: proj.BuildCommand().String(),
+				Valid:  !proj.BuildCommand().IsEmpty(),
+			}
+			migrationCmd := sql.NullString{
+				String: proj.MigrationCommand().String(),
+				Valid:  !proj.MigrationCommand().IsEmpty(),
+			}
+			_, err := queries.UpdateProject(ctx, &database.UpdateProjectParams{
+				ID:               proj.ID().UUID(),
+				RepositoryUrl:    proj.RepositoryURL().String(),
+				InstallCommand:   proj.InstallCommand().String(),
+				BuildCommand:     buildCmd,
+				RunCommand:       proj.RunCommand().String(),
+				Language:         proj.Language().String(),
+				CustomDomain:     proj.CustomDomain().String(),
+				RequireDb:        proj.RequireDB(),
+				MigrationCommand: migrationCmd,
+				Status:           proj.Status().String(),
+				StatusMessage: sql.NullString{
+					String: proj.StatusMessage(),
+					Valid:  proj.StatusMessage() != "",
+				},
+				ImageRetention:        int32(proj.ImageRetention()),
+				DeploymentStrategy:    proj.DeploymentStrategy().String(),
+				CanaryPercent:         int32(proj.CanaryPercent()),
+				CanaryBakeMinutes:     int32(proj.CanaryBakeMinutes()),
+				Datastores:            datastoreNames(proj.Datastores()),
+				VolumeMountPath:       proj.VolumeMountPath(),
+				VolumeSizeGb:          int32(proj.VolumeSizeGB()),
+				ProjectType:           proj.Type().String(),
+				Schedule:              proj.Schedule().String(),
+				Services:              services,
+				Environments:          environments,
+				ProtectedEnvironments: protectedEnvironments,
+				ContainerPort:         int32(proj.Port()),
+				HealthCheckPath:       proj.HealthCheckPath(),
+				Cpu:                   int32(proj.CPU()),
+				Memory:                int32(proj.Memory()),
+				OutputDirectory:       proj.OutputDirectory(),
+				DeploymentTarget:      proj.DeploymentTarget().String(),
+				LambdaEndpoint:        proj.LambdaEndpoint().String(),
+				DeployRules:           deployRules,
+				PublicStatusPage:      proj.PublicStatusPage(),
+				BuildSize:             proj.BuildSize().String(),
+				BuildTimeoutMinutes:   int32(proj.BuildTimeoutMinutes()),
+				RedeployOnEnvChange:   proj.RedeployOnEnvChange(),
+			})
+			if isUniqueViolation(err, customDomainIndex) {
+				return project.ErrCustomDomainTaken
+			}
+			if err != nil {
+				return fmt.Errorf("failed to update project: %w", err)
+			}
+		} else {
+			// Project doesn't exist (err == pgx.ErrNoRows) - create it
+			buildCmd := sql.NullString{
+				String: proj.BuildCommand().String(),
+				Valid:  !proj.BuildCommand().IsEmpty(),
+			}
+			migrationCmd := sql.NullString{
+				String: proj.MigrationCommand().String(),
+				Valid:  !proj.MigrationCommand().IsEmpty(),
+			}
+			_, err := queries.CreateProject(ctx, &database.CreateProjectParams{
+				UserID:                proj.UserID().UUID(),
+				RepositoryUrl:         proj.RepositoryURL().String(),
+				InstallCommand:        proj.InstallCommand().String(),
+				BuildCommand:          buildCmd,
+				RunCommand:            proj.RunCommand().String(),
+				Language:              proj.Language().String(),
+				CustomDomain:          proj.CustomDomain().String(),
+				RequireDb:             proj.RequireDB(),
+				MigrationCommand:      migrationCmd,
+				ImageRetention:        int32(proj.ImageRetention()),
+				DeploymentStrategy:    proj.DeploymentStrategy().String(),
+				CanaryPercent:         int32(proj.CanaryPercent()),
+				CanaryBakeMinutes:     int32(proj.CanaryBakeMinutes()),
+				Datastores:            datastoreNames(proj.Datastores()),
+				VolumeMountPath:       proj.VolumeMountPath(),
+				VolumeSizeGb:          int32(proj.VolumeSizeGB()),
+				ProjectType:           proj.Type().String(),
+				Schedule:              proj.Schedule().String(),
+				Services:              services,
+				Environments:          environments,
+				ProtectedEnvironments: protectedEnvironments,
+				ContainerPort:         int32(proj.Port()),
+				HealthCheckPath:       proj.HealthCheckPath(),
+				Cpu:                   int32(proj.CPU()),
+				Memory:                int32(proj.Memory()),
+				OutputDirectory:       proj.OutputDirectory(),
+				DeploymentTarget:      proj.DeploymentTarget().String(),
+				LambdaEndpoint:        proj.LambdaEndpoint().String(),
+				DeployRules:           deployRules,
+				PublicStatusPage:      proj.PublicStatusPage(),
+				BuildSize:             proj.BuildSize().String(),
+				BuildTimeoutMinutes:   int32(proj.BuildTimeoutMinutes()),
+				RedeployOnEnvChange:   proj.RedeployOnEnvChange(),
+			})
+			if isUniqueViolation(err, customDomainIndex) {
+				return project.ErrCustomDomainTaken
+			}
+			if err != nil {
+				return fmt.Errorf("failed to create project: %w", err)
+			}
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
 // FindByID retrieves a project by its ID
 func (r *ProjectRepositoryImpl) FindByID(ctx context.Context, id project.ProjectID) (*project.Project, error) {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	dbProject, err := queries.GetProjectByID(ctx, id.UUID())
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, project.ErrProjectNotFound
 		}
 		return nil, fmt.Errorf("failed to get project: %w", err)
 	}
 
-	return r.toDomain(dbProject)
+	return r.load(ctx, queries, dbProject)
+}
+
+// ClaimStalledTeardown takes over the teardown of a project being deleted that made no progress since staleBefore
+func (r *ProjectRepositoryImpl) ClaimStalledTeardown(ctx context.Context, staleBefore time.Time) (*project.Project, error) {
+	queries := r.db.Queries(ctx)
+
+	dbProject, err := queries.ClaimStalledProjectTeardown(ctx, sql.NullTime{Time: staleBefore, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, project.ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to claim project teardown: %w", err)
+	}
+
+	return r.load(ctx, queries, dbProject)
+}
+
+// FindByIDIncludingDeleted retrieves a project by its ID, even if it was deleted
+func (r *ProjectRepositoryImpl) FindByIDIncludingDeleted(ctx context.Context, id project.ProjectID) (*project.Project, error) {
+	queries := r.db.Queries(ctx)
+
+	dbProject, err := queries.GetProjectByIDIncludingDeleted(ctx, id.UUID())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, project.ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return r.load(ctx, queries, dbProject)
 }
 
 // FindByUserID retrieves all projects for a user with pagination
 func (r *ProjectRepositoryImpl) FindByUserID(ctx context.Context, userID user.UserID, limit, offset int32) ([]*project.Project, error) {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	dbProjects, err := queries.GetProjectsByUserID(ctx, &database.GetProjectsByUserIDParams{
 		UserID: userID.UUID(),
@@ -112,39 +232,86 @@ func (r *ProjectRepositoryImpl) FindByUserID(ctx context.Context, userID user.Us
 		return nil, fmt.Errorf("failed to get projects: %w", err)
 	}
 
-	projects := make([]*project.Project, len(dbProjects))
-	for i, dbProject := range dbProjects {
-		domainProject, err := r.toDomain(dbProject)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert project: %w", err)
-		}
-		projects[i] = domainProject
+	return r.loadList(ctx, queries, dbProjects)
+}
+
+// FindByUserIDIncludingDeleted retrieves all projects for a user with pagination, deleted ones included
+func (r *ProjectRepositoryImpl) FindByUserIDIncludingDeleted(ctx context.Context, userID user.UserID, limit, offset int32) ([]*project.Project, error) {
+	queries := r.db.Queries(ctx)
+
+	dbProjects, err := queries.GetProjectsByUserIDIncludingDeleted(ctx, &database.GetProjectsByUserIDIncludingDeletedParams{
+		UserID: userID.UUID(),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
 	}
 
-	return projects, nil
+	return r.loadList(ctx, queries, dbProjects)
 }
 
-// FindByRepositoryURL retrieves a project by repository URL and user ID
+// FindAll retrieves the projects of every user with pagination, oldest first
+func (r *ProjectRepositoryImpl) FindAll(ctx context.Context, limit, offset int32) ([]*project.Project, error) {
+	queries := r.db.Queries(ctx)
+
+	dbProjects, err := queries.ListProjects(ctx, &database.ListProjectsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	return r.loadList(ctx, queries, dbProjects)
+}
+
+// FindWithCronJobs retrieves the projects of every user that run a cron job, as their main service or
+// another one, oldest first
+func (r *ProjectRepositoryImpl) FindWithCronJobs(ctx context.Context) ([]*project.Project, error) {
+	queries := r.db.Queries(ctx)
+
+	dbProjects, err := queries.ListProjectsWithCronJobs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	return r.loadList(ctx, queries, dbProjects)
+}
+
+// FindByRepositoryURL retrieves the oldest project of a user deploying a repository URL
 func (r *ProjectRepositoryImpl) FindByRepositoryURL(ctx context.Context, userID user.UserID, repoURL project.RepositoryURL) (*project.Project, error) {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	dbProject, err := queries.GetProjectByRepositoryURL(ctx, &database.GetProjectByRepositoryURLParams{
 		UserID:        userID.UUID(),
 		RepositoryUrl: repoURL.String(),
 	})
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, project.ErrProjectNotFound
 		}
 		return nil, fmt.Errorf("failed to get project by repository URL: %w", err)
 	}
 
-	return r.toDomain(dbProject)
+	return r.load(ctx, queries, dbProject)
+}
+
+// FindAllByRepositoryURL retrieves the projects of every user deployed from a repository
+func (r *ProjectRepositoryImpl) FindAllByRepositoryURL(ctx context.Context, repoURL project.RepositoryURL) ([]*project.Project, error) {
+	queries := r.db.Queries(ctx)
+
+	dbProjects, err := queries.ListProjectsByRepositoryURL(ctx, strings.TrimSuffix(repoURL.String(), ".git"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects by repository URL: %w", err)
+	}
+
+	return r.loadList(ctx, queries, dbProjects)
 }
 
 // CountByUserID counts total projects for a user
 func (r *ProjectRepositoryImpl) CountByUserID(ctx context.Context, userID user.UserID) (int64, error) {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	count, err := queries.CountProjectsByUserID(ctx, userID.UUID())
 	if err != nil {
@@ -154,11 +321,26 @@ func (r *ProjectRepositoryImpl) CountByUserID(ctx context.Context, userID user.U
 	return count, nil
 }
 
-// Delete removes a project
+// CountByUserIDIncludingDeleted counts total projects for a user, deleted ones included
+func (r *ProjectRepositoryImpl) CountByUserIDIncludingDeleted(ctx context.Context, userID user.UserID) (int64, error) {
+	queries := r.db.Queries(ctx)
+
+	count, err := queries.CountProjectsByUserIDIncludingDeleted(ctx, userID.UUID())
+	if err != nil {
+		return 0, fmt.Errorf("failed to count projects: %w", err)
+	}
+
+	return count, nil
+}
+
+// Delete soft deletes a project and its deployments
 func (r *ProjectRepositoryImpl) Delete(ctx context.Context, id project.ProjectID) error {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
-	err := queries.DeleteProject(ctx, id.UUID())
+	err := queries.SoftDeleteProject(ctx, &database.SoftDeleteProjectParams{
+		ID:        id.UUID(),
+		DeletedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete project: %w", err)
 	}
@@ -166,9 +348,24 @@ func (r *ProjectRepositoryImpl) Delete(ctx context.Context, id project.ProjectID
 	return nil
 }
 
+// PurgeDeletedBefore permanently removes up to limit projects deleted before the cutoff
+func (r *ProjectRepositoryImpl) PurgeDeletedBefore(ctx context.Context, cutoff time.Time, limit int32) (int64, error) {
+	queries := r.db.Queries(ctx)
+
+	purged, err := queries.PurgeDeletedProjects(ctx, &database.PurgeDeletedProjectsParams{
+		DeletedAt: sql.NullTime{Time: cutoff, Valid: true},
+		Limit:     limit,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted projects: %w", err)
+	}
+
+	return purged, nil
+}
+
 // ExistsByRepositoryURL checks if a project with the given repository URL exists for a user
 func (r *ProjectRepositoryImpl) ExistsByRepositoryURL(ctx context.Context, userID user.UserID, repoURL project.RepositoryURL) (bool, error) {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	exists, err := queries.ExistsProjectByRepositoryURL(ctx, &database.ExistsProjectByRepositoryURLParams{
 		UserID:        userID.UUID(),
@@ -181,6 +378,70 @@ func (r *ProjectRepositoryImpl) ExistsByRepositoryURL(ctx context.Context, userI
 	return exists, nil
 }
 
+// FindByCustomDomains retrieves the projects of every user, deleted ones excepted, whose custom domain is one
+// of the given domains
+func (r *ProjectRepositoryImpl) FindByCustomDomains(ctx context.Context, domains []string) ([]*project.Project, error) {
+	queries := r.db.Queries(ctx)
+
+	dbProjects, err := queries.ListProjectsByCustomDomains(ctx, domains)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects by custom domain: %w", err)
+	}
+
+	return r.loadList(ctx, queries, dbProjects)
+}
+
+// loadList converts a list of database projects to domain projects
+func (r *ProjectRepositoryImpl) loadList(ctx context.Context, queries *database.Queries, dbProjects []*database.Project) ([]*project.Project, error) {
+	projects := make([]*project.Project, len(dbProjects))
+	for i, dbProject := range dbProjects {
+		domainProject, err := r.load(ctx, queries, dbProject)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert project: %w", err)
+		}
+		projects[i] = domainProject
+	}
+
+	return projects, nil
+}
+
+// load converts a database project to a domain project, first storing a generated custom domain
+// for legacy projects created before custom domains existed. Deleted projects are left as they are.
+func (r *ProjectRepositoryImpl) load(ctx context.Context, queries *database.Queries, dbProject *database.Project) (*project.Project, error) {
+	if dbProject.CustomDomain == "" && !dbProject.DeletedAt.Valid {
+		backfilled, err := r.backfillCustomDomain(ctx, queries, dbProject.ID)
+		if err != nil {
+			return nil, err
+		}
+		dbProject = backfilled
+	}
+
+	return r.toDomain(dbProject)
+}
+
+// backfillCustomDomain stores a generated custom domain for a project without one.
+// A domain stored by a concurrent request is kept, so every reader sees the same domain.
+func (r *ProjectRepositoryImpl) backfillCustomDomain(ctx context.Context, queries *database.Queries, id uuid.UUID) (*database.Project, error) {
+	domain, err := project.NewCustomDomain("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate custom domain: %w", err)
+	}
+
+	dbProject, err := queries.BackfillProjectCustomDomain(ctx, &database.BackfillProjectCustomDomainParams{
+		ID:           id,
+		CustomDomain: domain.String(),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Backfilled by a concurrent request
+		dbProject, err = queries.GetProjectByID(ctx, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to backfill custom domain: %w", err)
+	}
+
+	return dbProject, nil
+}
+
 // toDomain converts database project to domain project
 func (r *ProjectRepositoryImpl) toDomain(dbProject *database.Project) (*project.Project, error) {
 	userID, err := user.ParseUserID(dbProject.UserID.String())
@@ -202,6 +463,21 @@ func (r *ProjectRepositoryImpl) toDomain(dbProject *database.Project) (*project.
 		migrationCommand = dbProject.MigrationCommand.String
 	}
 
+	// Handle nullable status_message
+	statusMessage := ""
+	if dbProject.StatusMessage.Valid {
+		statusMessage = dbProject.StatusMessage.String
+	}
+
+	services, err := unmarshalServices(dbProject.Services)
+	if err != nil {
+		return nil, err
+	}
+	deployRules, err := unmarshalDeployRules(dbProject.DeployRules)
+	if err != nil {
+		return nil, err
+	}
+
 	proj, err := project.Reconstitute(
 		dbProject.ID.String(),
 		userID,
@@ -213,41 +489,152 @@ func (r *ProjectRepositoryImpl) toDomain(dbProject *database.Project) (*project.
 		dbProject.CustomDomain,
 		dbProject.RequireDb,
 		migrationCommand,
+		dbProject.Status,
+		statusMessage,
+		int(dbProject.ImageRetention),
+		dbProject.DeploymentStrategy,
+		int(dbProject.CanaryPercent),
+		int(dbProject.CanaryBakeMinutes),
+		dbProject.Datastores,
+		dbProject.VolumeMountPath,
+		int(dbProject.VolumeSizeGb),
+		dbProject.ProjectType,
+		dbProject.Schedule,
+		services,
+		dbProject.Environments,
+		dbProject.ProtectedEnvironments,
+		int(dbProject.ContainerPort),
+		dbProject.HealthCheckPath,
+		int(dbProject.Cpu),
+		int(dbProject.Memory),
+		dbProject.OutputDirectory,
+		dbProject.DeploymentTarget,
+		dbProject.LambdaEndpoint,
+		deployRules,
+		dbProject.PublicStatusPage,
+		dbProject.BuildSize,
+		int(dbProject.BuildTimeoutMinutes),
+		dbProject.RedeployOnEnvChange,
 		createdAt,
 		updatedAt,
+		fromNullTime(dbProject.DeletedAt),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	// If custom domain was empty and got auto-generated, save it back to DB
-	// This handles legacy projects that were created before the custom_domain feature
-	if dbProject.CustomDomain == "" && !proj.CustomDomain().IsEmpty() {
-		// Update the database asynchronously to set the generated domain
-		go func() {
-			ctx := context.Background()
-			queries := database.New(r.db.GetConnection())
-			buildCmd := sql.NullString{
-				String: proj.BuildCommand().String(),
-				Valid:  !proj.BuildCommand().IsEmpty(),
-			}
-			migrationCmd := sql.NullString{
-				String: proj.MigrationCommand().String(),
-				Valid:  !proj.MigrationCommand().IsEmpty(),
-			}
-			queries.UpdateProject(ctx, &database.UpdateProjectParams{
-				ID:               proj.ID().UUID(),
-				RepositoryUrl:    proj.RepositoryURL().String(),
-				InstallCommand:   proj.InstallCommand().String(),
-				BuildCommand:     buildCmd,
-				RunCommand:       proj.RunCommand().String(),
-				Language:         proj.Language().String(),
-				CustomDomain:     proj.CustomDomain().String(),
-				RequireDb:        proj.RequireDB(),
-				MigrationCommand: migrationCmd,
-			})
-		}()
+	return proj, nil
+}
+
+// datastoreNames converts a project's datastores to the names they are stored as
+func datastoreNames(datastores []project.Datastore) []string {
+	names := make([]string, len(datastores))
+	for i, d := range datastores {
+		names[i] = d.String()
 	}
+	return names
+}
 
-	return proj, nil
+// environmentNames converts environments to the names they are stored as
+func environmentNames(environments []project.Environment) []string {
+	names := make([]string, len(environments))
+	for i, env := range environments {
+		names[i] = env.String()
+	}
+	return names
+}
+
+// storedService is how a project's service is stored in the services column
+type storedService struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Command  string `json:"command"`
+	Port     int    `json:"port,omitempty"`
+	Replicas int    `json:"replicas"`
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// marshalServices converts a project's services to the JSON array they are stored as
+func marshalServices(services []project.Service) ([]byte, error) {
+	stored := make([]storedService, len(services))
+	for i, s := range services {
+		stored[i] = storedService{
+			Name:     s.Name(),
+			Type:     s.Type().String(),
+			Command:  s.Command().String(),
+			Port:     s.Port(),
+			Replicas: s.Replicas(),
+			Schedule: s.Schedule().String(),
+		}
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode services: %w", err)
+	}
+	return data, nil
+}
+
+// unmarshalServices converts the stored JSON array of a project's services to domain services
+func unmarshalServices(data []byte) ([]project.Service, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var stored []storedService
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode services: %w", err)
+	}
+
+	services := make([]project.Service, len(stored))
+	for i, s := range stored {
+		svc, err := project.NewService(s.Name, s.Type, s.Command, s.Port, s.Replicas, s.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid service: %w", err)
+		}
+		services[i] = svc
+	}
+	return services, nil
+}
+
+// storedDeployRule is how a project's deploy rule is stored in the deploy_rules column
+type storedDeployRule struct {
+	Branch      string `json:"branch"`
+	Environment string `json:"environment"`
+}
+
+// marshalDeployRules converts a project's deploy rules to the JSON array they are stored as
+func marshalDeployRules(rules []project.DeployRule) ([]byte, error) {
+	stored := make([]storedDeployRule, len(rules))
+	for i, rule := range rules {
+		stored[i] = storedDeployRule{Branch: rule.Branch(), Environment: rule.Environment().String()}
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode deploy rules: %w", err)
+	}
+	return data, nil
+}
+
+// unmarshalDeployRules converts the stored JSON array of a project's deploy rules to domain deploy rules
+func unmarshalDeployRules(data []byte) ([]project.DeployRule, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var stored []storedDeployRule
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode deploy rules: %w", err)
+	}
+
+	rules := make([]project.DeployRule, len(stored))
+	for i, s := range stored {
+		rule, err := project.NewDeployRule(s.Branch, s.Environment)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deploy rule: %w", err)
+		}
+		rules[i] = rule
+	}
+	return rules, nil
 }
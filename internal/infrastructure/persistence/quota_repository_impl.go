@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/quota"
+	"snapdeploy-core/internal/domain/user"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// QuotaRepositoryImpl implements the domain quota.Repository interface
+type QuotaRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewQuotaRepository creates a new quota repository implementation
+func NewQuotaRepository(db *database.DB) quota.Repository {
+	return &QuotaRepositoryImpl{db: db}
+}
+
+// FindByUserID retrieves the quota of a user, or nil if they are on the default plan without overrides
+func (r *QuotaRepositoryImpl) FindByUserID(ctx context.Context, userID user.UserID) (*quota.Quota, error) {
+	queries := r.db.Queries(ctx)
+
+	dbQuota, err := queries.GetUserQuota(ctx, userID.UUID())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user quota: %w", err)
+	}
+
+	overrides := quota.Overrides{
+		Projects:             fromNullLimit(dbQuota.MaxProjects),
+		ConcurrentBuilds:     fromNullLimit(dbQuota.MaxConcurrentBuilds),
+		EnvVars:              fromNullLimit(dbQuota.MaxEnvVars),
+		BuildMinutesPerMonth: fromNullLimit(dbQuota.BuildMinutesPerMonth),
+	}
+	return quota.Reconstruct(userID, dbQuota.Plan.String, overrides, dbQuota.UpdatedAt), nil
+}
+
+// Save persists a user's quota (create or update)
+func (r *QuotaRepositoryImpl) Save(ctx context.Context, q *quota.Quota) error {
+	queries := r.db.Queries(ctx)
+
+	overrides := q.Overrides()
+	err := queries.UpsertUserQuota(ctx, &database.UpsertUserQuotaParams{
+		UserID:               q.UserID().UUID(),
+		Plan:                 sql.NullString{String: q.Plan(), Valid: q.Plan() != ""},
+		MaxProjects:          toNullLimit(overrides.Projects),
+		MaxConcurrentBuilds:  toNullLimit(overrides.ConcurrentBuilds),
+		MaxEnvVars:           toNullLimit(overrides.EnvVars),
+		BuildMinutesPerMonth: toNullLimit(overrides.BuildMinutesPerMonth),
+		UpdatedAt:            q.UpdatedAt(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save user quota: %w", err)
+	}
+
+	return nil
+}
+
+// fromNullLimit converts an overridden limit column, NULL when the plan's limit is kept
+func fromNullLimit(limit sql.NullInt32) *int {
+	if !limit.Valid {
+		return nil
+	}
+	value := int(limit.Int32)
+	return &value
+}
+
+// toNullLimit converts an overridden limit to its column
+func toNullLimit(limit *int) sql.NullInt32 {
+	if limit == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: int32(*limit), Valid: true}
+}
@@ -3,23 +3,26 @@ package persistence
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
 	"snapdeploy-core/internal/database"
 	"snapdeploy-core/internal/domain/repo"
 	"snapdeploy-core/internal/domain/user"
+
+	"github.com/jackc/pgx/v5"
 )
 
 // RepositoryRepoImpl implements the domain repo.RepositoryRepo interface
 type RepositoryRepoImpl struct {
-	queries *database.Queries
+	db *database.DB
 }
 
 // NewRepositoryRepository creates a new repository repository implementation
 func NewRepositoryRepository(db *database.DB) repo.RepositoryRepo {
 	return &RepositoryRepoImpl{
-		queries: database.New(db.GetConnection()),
+		db: db,
 	}
 }
 
@@ -45,9 +48,10 @@ func (r *RepositoryRepoImpl) Save(ctx context.Context, repository *repo.Reposito
 		branch = sql.NullString{String: *repository.DefaultBranch(), Valid: true}
 	}
 
-	_, err := r.queries.UpsertRepository(ctx, &database.UpsertRepositoryParams{
+	_, err := r.db.Queries(ctx).UpsertRepository(ctx, &database.UpsertRepositoryParams{
 		UserID:          repository.UserID().UUID(),
-		GithubID:        repository.GitHubID().Int64(),
+		Provider:        repository.Provider().String(),
+		ExternalID:      repository.ExternalID().String(),
 		Name:            repository.Name().String(),
 		FullName:        repository.FullName(),
 		Description:     desc,
@@ -70,14 +74,20 @@ func (r *RepositoryRepoImpl) Save(ctx context.Context, repository *repo.Reposito
 
 // FindByID retrieves a repository by its ID
 func (r *RepositoryRepoImpl) FindByID(ctx context.Context, id repo.RepositoryID) (*repo.Repository, error) {
-	// Note: We don't have a GetByID query, so this would need to be added to sqlc
-	// For now, we'll return an error
-	return nil, fmt.Errorf("FindByID not implemented - need to add to sqlc queries")
+	dbRepo, err := r.db.Queries(ctx).GetRepositoryByID(ctx, id.UUID())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repo.ErrRepositoryNotFound(id.String())
+		}
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	return r.toDomain(dbRepo)
 }
 
 // FindByUserID retrieves repositories for a specific user with pagination
 func (r *RepositoryRepoImpl) FindByUserID(ctx context.Context, userID user.UserID, limit, offset int32) ([]*repo.Repository, error) {
-	dbRepos, err := r.queries.GetRepositoriesByUserID(ctx, &database.GetRepositoriesByUserIDParams{
+	dbRepos, err := r.db.Queries(ctx).GetRepositoriesByUserID(ctx, &database.GetRepositoriesByUserIDParams{
 		UserID: userID.UUID(),
 		Limit:  limit,
 		Offset: offset,
@@ -105,7 +115,7 @@ func (r *RepositoryRepoImpl) SearchByUserID(ctx context.Context, userID user.Use
 		return r.FindByUserID(ctx, userID, limit, offset)
 	}
 
-	dbRepos, err := r.queries.SearchRepositoriesByUserID(ctx, &database.SearchRepositoriesByUserIDParams{
+	dbRepos, err := r.db.Queries(ctx).SearchRepositoriesByUserID(ctx, &database.SearchRepositoriesByUserIDParams{
 		UserID:  userID.UUID(),
 		Column2: sql.NullString{String: searchQuery, Valid: true},
 		Limit:   limit,
@@ -127,9 +137,63 @@ func (r *RepositoryRepoImpl) SearchByUserID(ctx context.Context, userID user.Use
 	return repositories, nil
 }
 
+// FindByUserIDAfter retrieves up to limit repositories for a user that come after the cursor, newest first
+func (r *RepositoryRepoImpl) FindByUserIDAfter(ctx context.Context, userID user.UserID, after repo.Cursor, limit int32) ([]*repo.Repository, error) {
+	dbRepos, err := r.db.Queries(ctx).GetRepositoriesByUserIDAfter(ctx, &database.GetRepositoriesByUserIDAfterParams{
+		UserID:         userID.UUID(),
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID.UUID(),
+		PageSize:       limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repositories: %w", err)
+	}
+
+	repositories := make([]*repo.Repository, len(dbRepos))
+	for i, dbRepo := range dbRepos {
+		domainRepo, err := r.toDomain(dbRepo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert repository: %w", err)
+		}
+		repositories[i] = domainRepo
+	}
+
+	return repositories, nil
+}
+
+// SearchByUserIDAfter searches up to limit repositories for a user that come after the cursor, newest first
+func (r *RepositoryRepoImpl) SearchByUserIDAfter(ctx context.Context, userID user.UserID, searchQuery string, after repo.Cursor, limit int32) ([]*repo.Repository, error) {
+	// If no search query, use regular find
+	if strings.TrimSpace(searchQuery) == "" {
+		return r.FindByUserIDAfter(ctx, userID, after, limit)
+	}
+
+	dbRepos, err := r.db.Queries(ctx).SearchRepositoriesByUserIDAfter(ctx, &database.SearchRepositoriesByUserIDAfterParams{
+		UserID:         userID.UUID(),
+		Search:         searchQuery,
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID.UUID(),
+		PageSize:       limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search repositories: %w", err)
+	}
+
+	repositories := make([]*repo.Repository, len(dbRepos))
+	for i, dbRepo := range dbRepos {
+		domainRepo, err := r.toDomain(dbRepo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert repository: %w", err)
+		}
+		repositories[i] = domainRepo
+	}
+
+	return repositories, nil
+}
+
 // CountByUserID returns the total number of repositories for a user
 func (r *RepositoryRepoImpl) CountByUserID(ctx context.Context, userID user.UserID) (int64, error) {
-	count, err := r.queries.CountRepositoriesByUserID(ctx, userID.UUID())
+	count, err := r.db.Queries(ctx).CountRepositoriesByUserID(ctx, userID.UUID())
 	if err != nil {
 		return 0, fmt.Errorf("failed to count repositories: %w", err)
 	}
@@ -144,7 +208,7 @@ func (r *RepositoryRepoImpl) CountSearchByUserID(ctx context.Context, userID use
 		return r.CountByUserID(ctx, userID)
 	}
 
-	count, err := r.queries.CountSearchRepositoriesByUserID(ctx, &database.CountSearchRepositoriesByUserIDParams{
+	count, err := r.db.Queries(ctx).CountSearchRepositoriesByUserID(ctx, &database.CountSearchRepositoriesByUserIDParams{
 		UserID:  userID.UUID(),
 		Column2: sql.NullString{String: searchQuery, Valid: true},
 	})
@@ -157,9 +221,9 @@ func (r *RepositoryRepoImpl) CountSearchByUserID(ctx context.Context, userID use
 
 // FindByURL retrieves a repository by its URL
 func (r *RepositoryRepoImpl) FindByURL(ctx context.Context, url repo.URL) (*repo.Repository, error) {
-	dbRepo, err := r.queries.GetRepositoryByURL(ctx, url.String())
+	dbRepo, err := r.db.Queries(ctx).GetRepositoryByURL(ctx, url.String())
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, repo.ErrRepositoryNotFound(url.String())
 		}
 		return nil, fmt.Errorf("failed to get repository: %w", err)
@@ -168,9 +232,121 @@ func (r *RepositoryRepoImpl) FindByURL(ctx context.Context, url repo.URL) (*repo
 	return r.toDomain(dbRepo)
 }
 
+// FindByURLs retrieves the repositories with any of the URLs, in no particular order
+func (r *RepositoryRepoImpl) FindByURLs(ctx context.Context, urls []repo.URL) ([]*repo.Repository, error) {
+	values := make([]string, len(urls))
+	for i, url := range urls {
+		values[i] = url.String()
+	}
+
+	dbRepos, err := r.db.Queries(ctx).GetRepositoriesByURLs(ctx, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repositories: %w", err)
+	}
+
+	repositories := make([]*repo.Repository, len(dbRepos))
+	for i, dbRepo := range dbRepos {
+		domainRepo, err := r.toDomain(dbRepo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert repository: %w", err)
+		}
+		repositories[i] = domainRepo
+	}
+
+	return repositories, nil
+}
+
+// SaveAll persists repositories (create or update via upsert), saveAllBatchSize at a time.
+// Batches are saved in one transaction, the caller's if it has one.
+func (r *RepositoryRepoImpl) SaveAll(ctx context.Context, repositories []*repo.Repository) error {
+	return r.db.InTx(ctx, func(ctx context.Context) error {
+		for start := 0; start < len(repositories); start += saveAllBatchSize {
+			batch := repositories[start:min(start+saveAllBatchSize, len(repositories))]
+			if err := r.db.Queries(ctx).UpsertRepositories(ctx, toUpsertRepositoriesParams(batch)); err != nil {
+				return fmt.Errorf("failed to upsert repositories: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// saveAllBatchSize is the number of repositories upserted by a query of SaveAll
+const saveAllBatchSize = 500
+
+// toUpsertRepositoriesParams converts repositories to the columns of a batch upsert.
+// Missing optional values are sent as empty strings, which the query stores as NULL.
+func toUpsertRepositoriesParams(repositories []*repo.Repository) *database.UpsertRepositoriesParams {
+	params := &database.UpsertRepositoriesParams{}
+	for _, repository := range repositories {
+		params.UserIds = append(params.UserIds, repository.UserID().UUID())
+		params.Providers = append(params.Providers, repository.Provider().String())
+		params.ExternalIds = append(params.ExternalIds, repository.ExternalID().String())
+		params.Names = append(params.Names, repository.Name().String())
+		params.FullNames = append(params.FullNames, repository.FullName())
+		params.Descriptions = append(params.Descriptions, stringValue(repository.Description()))
+		params.Urls = append(params.Urls, repository.URL().String())
+		params.HtmlUrls = append(params.HtmlUrls, stringValue(repository.HTMLURL()))
+		params.Privates = append(params.Privates, repository.IsPrivate())
+		params.Forks = append(params.Forks, repository.IsFork())
+		params.StargazersCounts = append(params.StargazersCounts, repository.StargazersCount())
+		params.WatchersCounts = append(params.WatchersCounts, repository.WatchersCount())
+		params.ForksCounts = append(params.ForksCounts, repository.ForksCount())
+		params.DefaultBranches = append(params.DefaultBranches, stringValue(repository.DefaultBranch()))
+		params.Languages = append(params.Languages, stringValue(repository.Language()))
+	}
+	return params
+}
+
+// stringValue returns the string s points to, or an empty string if it is nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// FindSyncState retrieves the state of the last sync of a user's repositories from a provider,
+// or nil if they never synced from it
+func (r *RepositoryRepoImpl) FindSyncState(ctx context.Context, userID user.UserID, provider repo.Provider) (*repo.SyncState, error) {
+	dbSync, err := r.db.Queries(ctx).GetRepositorySync(ctx, &database.GetRepositorySyncParams{
+		UserID:   userID.UUID(),
+		Provider: provider.String(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get repository sync state: %w", err)
+	}
+
+	return &repo.SyncState{
+		UserID:       userID,
+		Provider:     provider,
+		ETag:         dbSync.Etag,
+		SyncedAt:     dbSync.SyncedAt,
+		FullSyncedAt: dbSync.FullSyncedAt,
+	}, nil
+}
+
+// SaveSyncState persists the state of the last sync of a user's repositories from a provider
+func (r *RepositoryRepoImpl) SaveSyncState(ctx context.Context, state *repo.SyncState) error {
+	err := r.db.Queries(ctx).UpsertRepositorySync(ctx, &database.UpsertRepositorySyncParams{
+		UserID:       state.UserID.UUID(),
+		Provider:     state.Provider.String(),
+		Etag:         state.ETag,
+		SyncedAt:     state.SyncedAt,
+		FullSyncedAt: state.FullSyncedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save repository sync state: %w", err)
+	}
+
+	return nil
+}
+
 // Delete removes a repository from persistence
 func (r *RepositoryRepoImpl) Delete(ctx context.Context, id repo.RepositoryID) error {
-	err := r.queries.DeleteRepository(ctx, id.UUID())
+	err := r.db.Queries(ctx).DeleteRepository(ctx, id.UUID())
 	if err != nil {
 		return fmt.Errorf("failed to delete repository: %w", err)
 	}
@@ -208,7 +384,8 @@ func (r *RepositoryRepoImpl) toDomain(dbRepo *database.Repository) (*repo.Reposi
 	return repo.Reconstitute(
 		dbRepo.ID.String(),
 		userID,
-		dbRepo.GithubID,
+		dbRepo.Provider,
+		dbRepo.ExternalID,
 		dbRepo.Name,
 		dbRepo.FullName,
 		description,
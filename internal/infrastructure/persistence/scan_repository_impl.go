@@ -0,0 +1,140 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// vulnerabilityRecord is how a vulnerability is stored in deployment_scans.vulnerabilities
+type vulnerabilityRecord struct {
+	ID       string `json:"id"`
+	Severity string `json:"severity"`
+	Package  string `json:"package"`
+	Version  string `json:"version,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// ScanRepositoryImpl implements the domain deployment.ScanRepository interface
+type ScanRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewScanRepository creates a new scan repository implementation
+func NewScanRepository(db *database.DB) deployment.ScanRepository {
+	return &ScanRepositoryImpl{db: db}
+}
+
+// Save records the scan of a deployment's image, replacing the one recorded by an earlier attempt
+func (r *ScanRepositoryImpl) Save(ctx context.Context, report *deployment.ScanReport) error {
+	queries := r.db.Queries(ctx)
+
+	counts := make(map[string]int, len(report.SeverityCounts))
+	for severity, count := range report.SeverityCounts {
+		counts[severity.String()] = count
+	}
+	severityCounts, err := json.Marshal(counts)
+	if err != nil {
+		return fmt.Errorf("failed to encode severity counts: %w", err)
+	}
+
+	records := make([]vulnerabilityRecord, len(report.Vulnerabilities))
+	for i, v := range report.Vulnerabilities {
+		records[i] = vulnerabilityRecord{ID: v.ID, Severity: v.Severity.String(), Package: v.Package, Version: v.Version, URL: v.URL}
+	}
+	vulnerabilities, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("failed to encode vulnerabilities: %w", err)
+	}
+
+	params := &database.UpsertDeploymentScanParams{
+		DeploymentID:    report.DeploymentID.UUID(),
+		ProjectID:       report.ProjectID.UUID(),
+		ImageUri:        report.ImageURI,
+		Status:          report.Status.String(),
+		Error:           report.Error,
+		SeverityCounts:  severityCounts,
+		Vulnerabilities: vulnerabilities,
+		Blocked:         report.Blocked,
+		CreatedAt:       report.CreatedAt,
+	}
+	if report.SBOM != nil {
+		params.SbomFormat = report.SBOM.Format
+		params.SbomPackages = int32(report.SBOM.Packages)
+		params.Sbom = report.SBOM.Document
+	}
+
+	if err := queries.UpsertDeploymentScan(ctx, params); err != nil {
+		return fmt.Errorf("failed to save deployment scan: %w", err)
+	}
+
+	return nil
+}
+
+// FindByDeploymentID retrieves the scan of a deployment's image
+func (r *ScanRepositoryImpl) FindByDeploymentID(ctx context.Context, deploymentID deployment.DeploymentID) (*deployment.ScanReport, error) {
+	queries := r.db.Queries(ctx)
+
+	dbScan, err := queries.GetDeploymentScan(ctx, deploymentID.UUID())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, deployment.ErrScanNotFound
+		}
+		return nil, fmt.Errorf("failed to get deployment scan: %w", err)
+	}
+
+	projectID, err := project.ParseProjectID(dbScan.ProjectID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	report := &deployment.ScanReport{
+		DeploymentID:   deploymentID,
+		ProjectID:      projectID,
+		ImageURI:       dbScan.ImageUri,
+		Status:         deployment.ScanStatus(dbScan.Status),
+		Error:          dbScan.Error,
+		SeverityCounts: map[deployment.Severity]int{},
+		Blocked:        dbScan.Blocked,
+		CreatedAt:      dbScan.CreatedAt,
+	}
+
+	var counts map[string]int
+	if err := json.Unmarshal(dbScan.SeverityCounts, &counts); err != nil {
+		return nil, fmt.Errorf("failed to decode severity counts: %w", err)
+	}
+	for severity, count := range counts {
+		report.SeverityCounts[deployment.NewSeverity(severity)] += count
+	}
+
+	var records []vulnerabilityRecord
+	if err := json.Unmarshal(dbScan.Vulnerabilities, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode vulnerabilities: %w", err)
+	}
+	for _, record := range records {
+		report.Vulnerabilities = append(report.Vulnerabilities, deployment.Vulnerability{
+			ID:       record.ID,
+			Severity: deployment.NewSeverity(record.Severity),
+			Package:  record.Package,
+			Version:  record.Version,
+			URL:      record.URL,
+		})
+	}
+
+	if dbScan.Sbom != nil {
+		report.SBOM = &deployment.SBOM{
+			Format:   dbScan.SbomFormat,
+			Packages: int(dbScan.SbomPackages),
+			Document: dbScan.Sbom,
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,137 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/shell"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ShellSessionRepositoryImpl implements the domain shell.SessionRepository interface
+type ShellSessionRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewShellSessionRepository creates a new shell session repository implementation
+func NewShellSessionRepository(db *database.DB) shell.SessionRepository {
+	return &ShellSessionRepositoryImpl{db: db}
+}
+
+// Save persists a session, replacing its recorded state
+func (r *ShellSessionRepositoryImpl) Save(ctx context.Context, session *shell.Session) error {
+	queries := r.db.Queries(ctx)
+
+	err := queries.UpsertShellSession(ctx, &database.UpsertShellSessionParams{
+		ID:          session.ID().UUID(),
+		ProjectID:   session.ProjectID().UUID(),
+		UserID:      session.UserID().UUID(),
+		Environment: session.Environment().String(),
+		TokenHash:   session.TokenHash(),
+		Status:      session.Status().String(),
+		TaskArn:     session.TaskArn(),
+		EndReason:   session.EndReason().String(),
+		Error:       session.ErrorMessage(),
+		BytesIn:     session.BytesIn(),
+		BytesOut:    session.BytesOut(),
+		CreatedAt:   session.CreatedAt(),
+		StartedAt:   toNullTime(session.StartedAt()),
+		EndedAt:     toNullTime(session.EndedAt()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save shell session: %w", err)
+	}
+
+	return nil
+}
+
+// Claim records that a claimed session was connected to. Only one connection can move a session out of
+// PENDING, so a token can't be used twice even by connections reaching different instances.
+func (r *ShellSessionRepositoryImpl) Claim(ctx context.Context, session *shell.Session) error {
+	queries := r.db.Queries(ctx)
+
+	claimed, err := queries.ClaimShellSession(ctx, &database.ClaimShellSessionParams{
+		ID:        session.ID().UUID(),
+		StartedAt: toNullTime(session.StartedAt()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to claim shell session: %w", err)
+	}
+	if claimed == 0 {
+		return shell.ErrInvalidToken
+	}
+
+	return nil
+}
+
+// FindByID retrieves a session by its ID
+func (r *ShellSessionRepositoryImpl) FindByID(ctx context.Context, id shell.SessionID) (*shell.Session, error) {
+	queries := r.db.Queries(ctx)
+
+	dbSession, err := queries.GetShellSessionByID(ctx, id.UUID())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, shell.ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get shell session: %w", err)
+	}
+
+	return r.toDomain(dbSession)
+}
+
+// FindByProjectID retrieves the most recent sessions of a project, newest first
+func (r *ShellSessionRepositoryImpl) FindByProjectID(ctx context.Context, projectID project.ProjectID, limit int) ([]*shell.Session, error) {
+	queries := r.db.Queries(ctx)
+
+	dbSessions, err := queries.ListShellSessionsByProjectID(ctx, &database.ListShellSessionsByProjectIDParams{
+		ProjectID: projectID.UUID(),
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shell sessions: %w", err)
+	}
+
+	sessions := make([]*shell.Session, len(dbSessions))
+	for i, dbSession := range dbSessions {
+		session, err := r.toDomain(dbSession)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert shell session: %w", err)
+		}
+		sessions[i] = session
+	}
+
+	return sessions, nil
+}
+
+// toDomain converts database shell session to domain shell session
+func (r *ShellSessionRepositoryImpl) toDomain(dbSession *database.ShellSession) (*shell.Session, error) {
+	var startedAt, endedAt *time.Time
+	if dbSession.StartedAt.Valid {
+		startedAt = &dbSession.StartedAt.Time
+	}
+	if dbSession.EndedAt.Valid {
+		endedAt = &dbSession.EndedAt.Time
+	}
+
+	return shell.ReconstituteSession(
+		dbSession.ID.String(),
+		dbSession.ProjectID.String(),
+		dbSession.UserID.String(),
+		dbSession.Environment,
+		dbSession.TokenHash,
+		dbSession.Status,
+		dbSession.TaskArn,
+		dbSession.EndReason,
+		dbSession.Error,
+		dbSession.BytesIn,
+		dbSession.BytesOut,
+		dbSession.CreatedAt,
+		startedAt,
+		endedAt,
+	)
+}
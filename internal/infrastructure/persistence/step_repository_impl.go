@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+)
+
+// StepRepositoryImpl implements the domain deployment.StepRepository interface
+type StepRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewStepRepository creates a new step repository implementation
+func NewStepRepository(db *database.DB) deployment.StepRepository {
+	return &StepRepositoryImpl{db: db}
+}
+
+// Save records a deployment's step, replacing the record of an earlier attempt of the step
+func (r *StepRepositoryImpl) Save(ctx context.Context, record deployment.StepRecord) error {
+	queries := r.db.Queries(ctx)
+
+	params := &database.UpsertDeploymentStepParams{
+		DeploymentID: record.DeploymentID.UUID(),
+		ProjectID:    record.ProjectID.UUID(),
+		Step:         record.Step.String(),
+		Status:       record.Status.String(),
+		Error:        record.Error,
+		StartedAt:    record.StartedAt,
+	}
+	if record.FinishedAt != nil {
+		params.FinishedAt = sql.NullTime{Time: *record.FinishedAt, Valid: true}
+	}
+
+	if err := queries.UpsertDeploymentStep(ctx, params); err != nil {
+		return fmt.Errorf("failed to save deployment step: %w", err)
+	}
+
+	return nil
+}
+
+// FindByDeploymentID retrieves a deployment's step records in pipeline order
+func (r *StepRepositoryImpl) FindByDeploymentID(ctx context.Context, deploymentID deployment.DeploymentID) ([]deployment.StepRecord, error) {
+	queries := r.db.Queries(ctx)
+
+	dbSteps, err := queries.GetDeploymentSteps(ctx, deploymentID.UUID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment steps: %w", err)
+	}
+
+	records := make([]deployment.StepRecord, len(dbSteps))
+	for i, dbStep := range dbSteps {
+		projectID, err := project.ParseProjectID(dbStep.ProjectID.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid project ID: %w", err)
+		}
+
+		records[i] = deployment.StepRecord{
+			DeploymentID: deploymentID,
+			ProjectID:    projectID,
+			Step:         deployment.Step(dbStep.Step),
+			Status:       deployment.StepStatus(dbStep.Status),
+			Error:        dbStep.Error,
+			StartedAt:    dbStep.StartedAt,
+		}
+		if dbStep.FinishedAt.Valid {
+			finishedAt := dbStep.FinishedAt.Time
+			records[i].FinishedAt = &finishedAt
+		}
+	}
+
+	// Steps skipped by a retry are recorded after the steps they were carried over from ran
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Step.Before(records[j].Step)
+	})
+
+	return records, nil
+}
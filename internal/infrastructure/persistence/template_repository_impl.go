@@ -0,0 +1,99 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/template"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TemplateRepositoryImpl implements the domain template.TemplateRepository interface
+type TemplateRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewTemplateRepository creates a new template repository implementation
+func NewTemplateRepository(db *database.DB) template.TemplateRepository {
+	return &TemplateRepositoryImpl{db: db}
+}
+
+// FindAll retrieves every template in catalog order
+func (r *TemplateRepositoryImpl) FindAll(ctx context.Context) ([]*template.Template, error) {
+	queries := r.db.Queries(ctx)
+
+	dbTemplates, err := queries.ListProjectTemplates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	templates := make([]*template.Template, 0, len(dbTemplates))
+	for _, dbTemplate := range dbTemplates {
+		t, err := r.toDomain(dbTemplate)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, nil
+}
+
+// FindByID retrieves a template by its ID
+func (r *TemplateRepositoryImpl) FindByID(ctx context.Context, id string) (*template.Template, error) {
+	queries := r.db.Queries(ctx)
+
+	dbTemplate, err := queries.GetProjectTemplateByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, template.ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+
+	return r.toDomain(dbTemplate)
+}
+
+// storedVariable is how a template's environment variable is stored in the env_vars column
+type storedVariable struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Scope       string `json:"scope,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// toDomain converts a database template to a domain template
+func (r *TemplateRepositoryImpl) toDomain(dbTemplate *database.ProjectTemplate) (*template.Template, error) {
+	var stored []storedVariable
+	if len(dbTemplate.EnvVars) > 0 {
+		if err := json.Unmarshal(dbTemplate.EnvVars, &stored); err != nil {
+			return nil, fmt.Errorf("failed to decode environment variables of template %s: %w", dbTemplate.ID, err)
+		}
+	}
+
+	variables := make([]template.Variable, len(stored))
+	for i, v := range stored {
+		variables[i] = template.Variable(v)
+	}
+
+	return template.Reconstitute(
+		dbTemplate.ID,
+		dbTemplate.Name,
+		dbTemplate.Description,
+		dbTemplate.RepositoryUrl,
+		dbTemplate.Language,
+		dbTemplate.Type,
+		dbTemplate.InstallCommand,
+		dbTemplate.BuildCommand,
+		dbTemplate.RunCommand,
+		dbTemplate.RequireDb,
+		dbTemplate.MigrationCommand,
+		variables,
+		dbTemplate.CreatedAt,
+	), nil
+}
@@ -0,0 +1,64 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+
+	"github.com/google/uuid"
+)
+
+// TimelineRepositoryImpl implements the domain deployment.TimelineRepository interface
+type TimelineRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewTimelineRepository creates a new timeline repository implementation
+func NewTimelineRepository(db *database.DB) deployment.TimelineRepository {
+	return &TimelineRepositoryImpl{db: db}
+}
+
+// Append adds an entry to a deployment's timeline
+func (r *TimelineRepositoryImpl) Append(ctx context.Context, deploymentID deployment.DeploymentID, projectID project.ProjectID, entry deployment.TimelineEntry) error {
+	queries := r.db.Queries(ctx)
+
+	err := queries.CreateDeploymentEvent(ctx, &database.CreateDeploymentEventParams{
+		ID:           uuid.New(),
+		DeploymentID: deploymentID.UUID(),
+		ProjectID:    projectID.UUID(),
+		Source:       entry.Source.String(),
+		Type:         entry.Type,
+		Message:      entry.Message,
+		OccurredAt:   entry.OccurredAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create deployment event: %w", err)
+	}
+
+	return nil
+}
+
+// FindByDeploymentID retrieves a deployment's timeline in chronological order
+func (r *TimelineRepositoryImpl) FindByDeploymentID(ctx context.Context, deploymentID deployment.DeploymentID) ([]deployment.TimelineEntry, error) {
+	queries := r.db.Queries(ctx)
+
+	dbEvents, err := queries.ListDeploymentEvents(ctx, deploymentID.UUID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment events: %w", err)
+	}
+
+	entries := make([]deployment.TimelineEntry, len(dbEvents))
+	for i, dbEvent := range dbEvents {
+		entries[i] = deployment.TimelineEntry{
+			OccurredAt: dbEvent.OccurredAt,
+			Source:     deployment.TimelineSource(dbEvent.Source),
+			Type:       dbEvent.Type,
+			Message:    dbEvent.Message,
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,22 @@
+package persistence
+
+import (
+	"context"
+
+	"snapdeploy-core/internal/database"
+)
+
+// UnitOfWorkImpl runs use cases in a single database transaction shared by the repositories in this package
+type UnitOfWorkImpl struct {
+	db *database.DB
+}
+
+// NewUnitOfWork creates a new unit of work over the database
+func NewUnitOfWork(db *database.DB) *UnitOfWorkImpl {
+	return &UnitOfWorkImpl{db: db}
+}
+
+// Do runs fn in a transaction, committed if fn returns nil and rolled back otherwise
+func (u *UnitOfWorkImpl) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return u.db.InTx(ctx, fn)
+}
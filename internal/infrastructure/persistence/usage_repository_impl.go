@@ -0,0 +1,121 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/database"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/usage"
+	"snapdeploy-core/internal/domain/user"
+
+	"github.com/google/uuid"
+)
+
+// UsageRepositoryImpl implements the domain usage.RecordRepository interface
+type UsageRepositoryImpl struct {
+	db *database.DB
+}
+
+// NewUsageRepository creates a new usage repository implementation
+func NewUsageRepository(db *database.DB) usage.RecordRepository {
+	return &UsageRepositoryImpl{db: db}
+}
+
+// Save persists a usage record
+func (r *UsageRepositoryImpl) Save(ctx context.Context, record *usage.Record) error {
+	queries := r.db.Queries(ctx)
+
+	var deploymentID uuid.NullUUID
+	if record.DeploymentID() != nil {
+		deploymentID = uuid.NullUUID{UUID: record.DeploymentID().UUID(), Valid: true}
+	}
+
+	_, err := queries.CreateUsageRecord(ctx, &database.CreateUsageRecordParams{
+		ID:           record.ID().UUID(),
+		ProjectID:    record.ProjectID().UUID(),
+		UserID:       record.UserID().UUID(),
+		DeploymentID: deploymentID,
+		Metric:       record.Metric().String(),
+		Quantity:     record.Quantity(),
+		PeriodStart:  record.PeriodStart(),
+		PeriodEnd:    record.PeriodEnd(),
+		CreatedAt:    record.CreatedAt(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create usage record: %w", err)
+	}
+
+	return nil
+}
+
+// LastPeriodEnd returns the end of the last metered period for a project and metric, or nil if none
+func (r *UsageRepositoryImpl) LastPeriodEnd(ctx context.Context, projectID project.ProjectID, metric usage.Metric) (*time.Time, error) {
+	queries := r.db.Queries(ctx)
+
+	last, err := queries.GetLastUsagePeriodEnd(ctx, &database.GetLastUsagePeriodEndParams{
+		ProjectID: projectID.UUID(),
+		Metric:    metric.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last usage period: %w", err)
+	}
+
+	return fromNullTime(last), nil
+}
+
+// SumByProjectID sums usage for a project over periods ending in (from, to]
+func (r *UsageRepositoryImpl) SumByProjectID(ctx context.Context, projectID project.ProjectID, from, to time.Time) (usage.Totals, error) {
+	queries := r.db.Queries(ctx)
+
+	rows, err := queries.SumUsageByProjectID(ctx, &database.SumUsageByProjectIDParams{
+		ProjectID: projectID.UUID(),
+		FromTime:  from,
+		ToTime:    to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum project usage: %w", err)
+	}
+
+	totals := usage.Totals{}
+	for _, row := range rows {
+		metric, err := usage.NewMetric(row.Metric)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert usage: %w", err)
+		}
+		totals[metric] = row.Total
+	}
+
+	return totals, nil
+}
+
+// SumByUserID sums usage per project for a user over periods ending in (from, to]
+func (r *UsageRepositoryImpl) SumByUserID(ctx context.Context, userID user.UserID, from, to time.Time) (map[string]usage.Totals, error) {
+	queries := r.db.Queries(ctx)
+
+	rows, err := queries.SumUsageByUserID(ctx, &database.SumUsageByUserIDParams{
+		UserID:   userID.UUID(),
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum user usage: %w", err)
+	}
+
+	byProject := make(map[string]usage.Totals)
+	for _, row := range rows {
+		metric, err := usage.NewMetric(row.Metric)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert usage: %w", err)
+		}
+
+		projectID := row.ProjectID.String()
+		if byProject[projectID] == nil {
+			byProject[projectID] = usage.Totals{}
+		}
+		byProject[projectID][metric] = row.Total
+	}
+
+	return byProject, nil
+}
@@ -2,11 +2,13 @@ package persistence
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
 
 	"snapdeploy-core/internal/database"
 	"snapdeploy-core/internal/domain/user"
+
+	"github.com/jackc/pgx/v5"
 )
 
 // UserRepositoryImpl implements the domain user.Repository interface
@@ -21,11 +23,11 @@ func NewUserRepository(db *database.DB) user.Repository {
 
 // Save persists a user (create or update)
 func (r *UserRepositoryImpl) Save(ctx context.Context, usr *user.User) error {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	// Check if user exists
 	_, err := queries.GetUserByID(ctx, usr.ID().UUID())
-	if err != nil && err != sql.ErrNoRows {
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		return fmt.Errorf("failed to check if user exists: %w", err)
 	}
 
@@ -41,7 +43,7 @@ func (r *UserRepositoryImpl) Save(ctx context.Context, usr *user.User) error {
 			return fmt.Errorf("failed to update user: %w", err)
 		}
 	} else {
-		// User doesn't exist (err == sql.ErrNoRows) - create it
+		// User doesn't exist (err == pgx.ErrNoRows) - create it
 		_, err := queries.CreateUser(ctx, &database.CreateUserParams{
 			ID:          usr.ID().UUID(),
 			Email:       usr.Email().String(),
@@ -58,11 +60,11 @@ func (r *UserRepositoryImpl) Save(ctx context.Context, usr *user.User) error {
 
 // FindByID retrieves a user by their ID
 func (r *UserRepositoryImpl) FindByID(ctx context.Context, id user.UserID) (*user.User, error) {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	dbUser, err := queries.GetUserByID(ctx, id.UUID())
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, user.ErrUserNotFound(id.String())
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -73,11 +75,11 @@ func (r *UserRepositoryImpl) FindByID(ctx context.Context, id user.UserID) (*use
 
 // FindByEmail retrieves a user by their email
 func (r *UserRepositoryImpl) FindByEmail(ctx context.Context, email user.Email) (*user.User, error) {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	dbUser, err := queries.GetUserByEmail(ctx, email.String())
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, user.ErrUserNotFound(email.String())
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -88,11 +90,11 @@ func (r *UserRepositoryImpl) FindByEmail(ctx context.Context, email user.Email)
 
 // FindByClerkID retrieves a user by their Clerk user ID
 func (r *UserRepositoryImpl) FindByClerkID(ctx context.Context, clerkID user.ClerkUserID) (*user.User, error) {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	dbUser, err := queries.GetUserByClerkID(ctx, clerkID.String())
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, user.ErrUserNotFound(clerkID.String())
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -103,7 +105,7 @@ func (r *UserRepositoryImpl) FindByClerkID(ctx context.Context, clerkID user.Cle
 
 // Delete removes a user from persistence
 func (r *UserRepositoryImpl) Delete(ctx context.Context, id user.UserID) error {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	err := queries.DeleteUser(ctx, id.UUID())
 	if err != nil {
@@ -115,7 +117,7 @@ func (r *UserRepositoryImpl) Delete(ctx context.Context, id user.UserID) error {
 
 // List retrieves users with pagination
 func (r *UserRepositoryImpl) List(ctx context.Context, limit, offset int32) ([]*user.User, error) {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	dbUsers, err := queries.ListUsers(ctx, &database.ListUsersParams{
 		Limit:  limit,
@@ -139,7 +141,7 @@ func (r *UserRepositoryImpl) List(ctx context.Context, limit, offset int32) ([]*
 
 // Count returns the total number of users
 func (r *UserRepositoryImpl) Count(ctx context.Context) (int64, error) {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	count, err := queries.CountUsers(ctx)
 	if err != nil {
@@ -151,11 +153,11 @@ func (r *UserRepositoryImpl) Count(ctx context.Context) (int64, error) {
 
 // ExistsByEmail checks if a user with the given email exists
 func (r *UserRepositoryImpl) ExistsByEmail(ctx context.Context, email user.Email) (bool, error) {
-	queries := database.New(r.db.GetConnection())
+	queries := r.db.Queries(ctx)
 
 	_, err := queries.GetUserByEmail(ctx, email.String())
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return false, nil
 		}
 		return false, fmt.Errorf("failed to check user existence: %w", err)
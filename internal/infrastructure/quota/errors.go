@@ -0,0 +1,120 @@
+package quota
+
+import (
+	"errors"
+	"strings"
+
+	elbtypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+)
+
+// Limit identifies an AWS service quota that blocks deployments
+type Limit string
+
+const (
+	LimitTargetGroups       Limit = "TARGET_GROUPS"
+	LimitTargetGroupsPerALB Limit = "TARGET_GROUPS_PER_ALB"
+	LimitRulesPerListener   Limit = "RULES_PER_LISTENER"
+	LimitRulePriorities     Limit = "LISTENER_RULE_PRIORITIES"
+	LimitFargateVCPU        Limit = "FARGATE_VCPU"
+)
+
+// ErrRulePrioritiesExhausted is returned when every priority a listener rule can have is allocated. Listeners
+// cap priorities at 50,000 whatever their rules quota.
+var ErrRulePrioritiesExhausted = errors.New("no listener rule priorities are left")
+
+// fargateVCPULimitSignature appears in ECS messages when the Fargate vCPU quota is exhausted
+const fargateVCPULimitSignature = "limit on the number of vcpus"
+
+// Error is an AWS quota error translated into user-facing and operator-facing guidance
+type Error struct {
+	Limit Limit
+	// Message explains the failure to the project owner
+	Message string
+	// Remediation tells the project owner what they can do about it
+	Remediation string
+	// OperatorAction tells platform operators which quota to raise
+	OperatorAction string
+	Err            error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Classify returns the quota error behind err, or nil if err is not caused by a quota
+func Classify(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var existing *Error
+	if errors.As(err, &existing) {
+		return existing
+	}
+
+	var tooManyTargetGroups *elbtypes.TooManyTargetGroupsException
+	if errors.As(err, &tooManyTargetGroups) {
+		return newError(LimitTargetGroups, err)
+	}
+
+	var tooManyPerALB *elbtypes.TooManyUniqueTargetGroupsPerLoadBalancerException
+	if errors.As(err, &tooManyPerALB) {
+		return newError(LimitTargetGroupsPerALB, err)
+	}
+
+	var tooManyRules *elbtypes.TooManyRulesException
+	if errors.As(err, &tooManyRules) {
+		return newError(LimitRulesPerListener, err)
+	}
+
+	if errors.Is(err, ErrRulePrioritiesExhausted) {
+		return newError(LimitRulePriorities, err)
+	}
+
+	return FromMessage(err.Error(), err)
+}
+
+// FromMessage detects quota failures that AWS only reports as text,
+// such as ECS service events and RunTask failure reasons
+func FromMessage(message string, err error) *Error {
+	if strings.Contains(strings.ToLower(message), fargateVCPULimitSignature) {
+		if err == nil {
+			err = errors.New(message)
+		}
+		return newError(LimitFargateVCPU, err)
+	}
+	return nil
+}
+
+func newError(limit Limit, err error) *Error {
+	quotaErr := &Error{Limit: limit, Err: err}
+
+	switch limit {
+	case LimitTargetGroups:
+		quotaErr.Message = "The platform has reached the maximum number of load balancer target groups in this region, so your app could not be routed."
+		quotaErr.Remediation = "Our team has been alerted. Delete projects you no longer need or retry the deployment later."
+		quotaErr.OperatorAction = "Request an increase of the Elastic Load Balancing 'Target Groups per Region' quota in Service Quotas."
+	case LimitTargetGroupsPerALB:
+		quotaErr.Message = "The shared load balancer has reached the maximum number of target groups, so your app could not be routed."
+		quotaErr.Remediation = "Our team has been alerted. Delete projects you no longer need or retry the deployment later."
+		quotaErr.OperatorAction = "Request an increase of the Elastic Load Balancing 'Target Groups per Application Load Balancer' quota, or shard projects across additional load balancers."
+	case LimitRulesPerListener:
+		quotaErr.Message = "The shared load balancer has reached the maximum number of routing rules, so your domain could not be routed."
+		quotaErr.Remediation = "Our team has been alerted. Delete projects you no longer need or retry the deployment later."
+		quotaErr.OperatorAction = "Request an increase of the Elastic Load Balancing 'Rules per Application Load Balancer' quota, or shard projects across additional listeners."
+	case LimitRulePriorities:
+		quotaErr.Message = "The shared load balancer has run out of routing rule priorities, so your domain could not be routed."
+		quotaErr.Remediation = "Our team has been alerted. Delete projects you no longer need or retry the deployment later."
+		quotaErr.OperatorAction = "Delete listener rules SnapDeploy doesn't manage from the priorities it allocates (1000 and up), or shard projects across additional listeners."
+	case LimitFargateVCPU:
+		quotaErr.Message = "The platform has reached its Fargate vCPU capacity, so your app's container could not be started."
+		quotaErr.Remediation = "Our team has been alerted. Stop or delete projects you no longer need or retry the deployment later."
+		quotaErr.OperatorAction = "Request an increase of the Amazon ECS 'Fargate On-Demand vCPU resource count' quota in Service Quotas."
+	}
+
+	return quotaErr
+}
@@ -0,0 +1,290 @@
+// Package repoconfig reads snapdeploy.yaml, an optional file at the root of a repository that declares how
+// the project is built and run, so those settings are versioned with the code:
+//
+//	language: NODE
+//	install_command: npm ci
+//	build_command: npm run build
+//	run_command: npm start
+//	port: 3000
+//	health_check_path: /healthz
+//	resources:
+//	  cpu: 512
+//	  memory: 1024
+//	env:
+//	  - STRIPE_SECRET_KEY
+//
+// Every key is optional; the settings the file leaves out stay as configured on the project.
+package repoconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"snapdeploy-core/internal/domain/project"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the file, read from the root of the repository
+const FileName = "snapdeploy.yaml"
+
+// maxFileSize bounds the size of the file, which only ever holds a handful of settings
+const maxFileSize = 64 << 10
+
+// Config is the contents of a snapdeploy.yaml
+type Config struct {
+	Language        string    `yaml:"language"`
+	InstallCommand  string    `yaml:"install_command"`
+	BuildCommand    string    `yaml:"build_command"`
+	RunCommand      string    `yaml:"run_command"`
+	Port            int       `yaml:"port"`
+	HealthCheckPath string    `yaml:"health_check_path"`
+	Resources       Resources `yaml:"resources"`
+	Env             []string  `yaml:"env"` // Names of the environment variables the project needs to run
+
+	lines map[string]int // Line of each key, for error messages
+}
+
+// Resources are the CPU and memory of the project's tasks
+type Resources struct {
+	CPU    int `yaml:"cpu"`    // CPU units, 1024 being one vCPU
+	Memory int `yaml:"memory"` // MiB
+}
+
+// Error is a problem with a snapdeploy.yaml, pointing at the line it is on when known
+type Error struct {
+	Line    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s: line %d: %s", FileName, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", FileName, e.Message)
+}
+
+var (
+	unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (\S+) not found in type \S+$`)
+	wrongTypePattern    = regexp.MustCompile(`^line (\d+): cannot unmarshal !!(\w+)(?: ` + "`(.*)`" + `)? into (\S+)$`)
+	linePattern         = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+)
+
+// Parse decodes and validates a snapdeploy.yaml. The returned error is an *Error, or joins several of them.
+func Parse(data []byte) (*Config, error) {
+	if len(data) > maxFileSize {
+		return nil, &Error{Message: fmt.Sprintf("file is larger than %d KB", maxFileSize>>10)}
+	}
+
+	cfg := &Config{lines: make(map[string]int)}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil {
+		if errors.Is(err, io.EOF) {
+			return cfg, nil // An empty file changes nothing
+		}
+		return nil, decodeError(err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err == nil && len(doc.Content) > 0 {
+		recordLines(doc.Content[0], "", cfg.lines)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// decodeError rewrites the errors of the YAML decoder in terms of the file's keys
+func decodeError(err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return lineError(err.Error())
+	}
+
+	errs := make([]error, len(typeErr.Errors))
+	for i, message := range typeErr.Errors {
+		if m := unknownFieldPattern.FindStringSubmatch(message); m != nil {
+			errs[i] = lineError(fmt.Sprintf("line %s: unknown key %q", m[1], m[2]))
+			continue
+		}
+		if m := wrongTypePattern.FindStringSubmatch(message); m != nil {
+			got := m[2]
+			if m[3] != "" {
+				got = fmt.Sprintf("%q", m[3])
+			}
+			errs[i] = lineError(fmt.Sprintf("line %s: expected %s, got %s", m[1], describeType(m[4]), got))
+			continue
+		}
+		errs[i] = lineError(message)
+	}
+	return errors.Join(errs...)
+}
+
+// lineError turns a message of the YAML decoder into an *Error, taking out the line it starts with
+func lineError(message string) *Error {
+	m := linePattern.FindStringSubmatch(message)
+	if m == nil {
+		return &Error{Message: strings.TrimPrefix(message, "yaml: ")}
+	}
+	var line int
+	fmt.Sscanf(m[1], "%d", &line)
+	return &Error{Line: line, Message: m[2]}
+}
+
+// describeType names the Go type a value was decoded into the way the file's documentation does
+func describeType(goType string) string {
+	switch goType {
+	case "int":
+		return "a whole number"
+	case "string":
+		return "text"
+	case "[]string":
+		return "a list of names"
+	default:
+		return "a mapping of keys"
+	}
+}
+
+// recordLines records the line of every key of a mapping and its nested mappings, e.g. resources.cpu
+func recordLines(node *yaml.Node, prefix string, lines map[string]int) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := prefix + node.Content[i].Value
+		lines[key] = node.Content[i].Line
+		recordLines(node.Content[i+1], key+".", lines)
+	}
+}
+
+// errorAt returns an *Error on the line of a key
+func (c *Config) errorAt(key, format string, args ...interface{}) *Error {
+	return &Error{Line: c.lines[key], Message: fmt.Sprintf(format, args...)}
+}
+
+// validate checks the values the domain doesn't check when the file is applied to a project
+func (c *Config) validate() error {
+	var errs []error
+
+	if c.Language != "" {
+		lang, err := project.NewLanguage(c.Language)
+		if err != nil {
+			errs = append(errs, c.errorAt("language", "language must be one of NODE, NODE_TS, NEXTJS, GO or PYTHON, got %q", c.Language))
+		}
+		c.Language = lang.String()
+	}
+
+	commands := []struct {
+		key   string
+		value *string
+	}{
+		{"install_command", &c.InstallCommand},
+		{"build_command", &c.BuildCommand},
+		{"run_command", &c.RunCommand},
+	}
+	for _, cmd := range commands {
+		*cmd.value = strings.TrimSpace(*cmd.value)
+		if _, set := c.lines[cmd.key]; set && *cmd.value == "" {
+			errs = append(errs, c.errorAt(cmd.key, "%s can't be empty, leave the key out to keep the project's command", cmd.key))
+		}
+		if len(*cmd.value) > 500 {
+			errs = append(errs, c.errorAt(cmd.key, "%s is longer than 500 characters", cmd.key))
+		}
+	}
+
+	if c.Port < 0 || c.Port > 65535 {
+		errs = append(errs, c.errorAt("port", "port must be between 1 and 65535, got %d", c.Port))
+	}
+	if c.HealthCheckPath != "" && !strings.HasPrefix(c.HealthCheckPath, "/") {
+		errs = append(errs, c.errorAt("health_check_path", "health_check_path must start with /, got %q", c.HealthCheckPath))
+	}
+	if c.Resources.CPU < 0 {
+		errs = append(errs, c.errorAt("resources.cpu", "resources.cpu must be 256, 512, 1024, 2048 or 4096, got %d", c.Resources.CPU))
+	}
+	if c.Resources.Memory < 0 {
+		errs = append(errs, c.errorAt("resources.memory", "resources.memory must be a positive number of MiB, got %d", c.Resources.Memory))
+	}
+
+	seen := make(map[string]bool, len(c.Env))
+	for _, name := range c.Env {
+		if _, err := project.NewEnvVarKey(name); err != nil {
+			errs = append(errs, c.errorAt("env", "env: %q is not a valid environment variable name", name))
+			continue
+		}
+		if seen[name] {
+			errs = append(errs, c.errorAt("env", "env: %s is listed twice", name))
+		}
+		seen[name] = true
+	}
+
+	return errors.Join(errs...)
+}
+
+// Apply overrides the settings of a project with the ones the file sets, leaving the others as they are.
+// Returns whether any setting changed, so the project is only saved when needed.
+func (c *Config) Apply(proj *project.Project) (bool, error) {
+	changed := false
+
+	language := proj.Language().String()
+	installCommand := proj.InstallCommand().String()
+	buildCommand := proj.BuildCommand().String()
+	runCommand := proj.RunCommand().String()
+	override := func(setting *string, value string) {
+		if value != "" && value != *setting {
+			*setting = value
+			changed = true
+		}
+	}
+	override(&language, c.Language)
+	override(&installCommand, c.InstallCommand)
+	override(&buildCommand, c.BuildCommand)
+	override(&runCommand, c.RunCommand)
+
+	if changed {
+		err := proj.Update(proj.RepositoryURL().String(), installCommand, buildCommand, runCommand, language,
+			proj.CustomDomain().String(), proj.RequireDB(), proj.MigrationCommand().String())
+		if err != nil {
+			return false, &Error{Message: err.Error()}
+		}
+	}
+
+	port, healthCheckPath, cpu, memory := proj.Port(), proj.HealthCheckPath(), proj.CPU(), proj.Memory()
+	if c.Port != 0 {
+		port = c.Port
+	}
+	if c.HealthCheckPath != "" {
+		healthCheckPath = c.HealthCheckPath
+	}
+	if c.Resources.CPU != 0 && c.Resources.CPU != cpu {
+		// Without a memory size, the smallest the CPU size allows is used
+		cpu, memory = c.Resources.CPU, 0
+	}
+	if c.Resources.Memory != 0 {
+		memory = c.Resources.Memory
+	}
+
+	if port == proj.Port() && healthCheckPath == proj.HealthCheckPath() && cpu == proj.CPU() && memory == proj.Memory() {
+		return changed, nil
+	}
+	if err := proj.SetContainer(port, healthCheckPath, cpu, memory); err != nil {
+		switch {
+		case errors.Is(err, project.ErrInvalidHealthCheckPath):
+			return false, c.errorAt("health_check_path", "health_check_path must be at most 255 characters without spaces")
+		case errors.Is(err, project.ErrInvalidTaskSize):
+			return false, c.errorAt("resources", "resources: %d CPU units with %d MiB of memory is not a size Fargate runs. "+
+				"cpu 256 takes 512, 1024 or 2048 MiB; 512 takes 1024-4096; 1024 takes 2048-8192; 2048 takes 4096-16384; "+
+				"4096 takes 8192-30720, in steps of 1024", cpu, memory)
+		default:
+			return false, &Error{Message: err.Error()}
+		}
+	}
+	return true, nil
+}
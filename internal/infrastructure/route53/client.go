@@ -2,16 +2,25 @@ package route53
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
 	"strings"
 
+	"snapdeploy-core/internal/infrastructure/awsretry"
+	"snapdeploy-core/internal/tracing"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
 	"github.com/aws/aws-sdk-go-v2/service/route53/types"
 )
 
+// ErrRecordNotFound is returned when a DNS record to delete does not exist
+var ErrRecordNotFound = errors.New("record not found")
+
+// cloudFrontHostedZoneID is the hosted zone of every CloudFront distribution
+const cloudFrontHostedZoneID = "Z2FDTNDATAQYW2"
+
 // Route53Client wraps AWS Route53 operations
 type Route53Client struct {
 	client       *route53.Client
@@ -19,21 +28,20 @@ type Route53Client struct {
 	baseDomain   string
 }
 
-// NewRoute53Client creates a new Route53 client
-func NewRoute53Client() (*Route53Client, error) {
+// NewRoute53Client creates a new Route53 client managing the subdomains of the base domain in the hosted zone
+func NewRoute53Client(hostedZoneID, baseDomain string) (*Route53Client, error) {
 	cfg, err := config.LoadDefaultConfig(context.TODO())
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
+	tracing.InstrumentAWS(&cfg)
+	awsretry.Configure(&cfg, "Route 53")
 
-	hostedZoneID := os.Getenv("ROUTE53_HOSTED_ZONE_ID")
 	if hostedZoneID == "" {
-		return nil, fmt.Errorf("ROUTE53_HOSTED_ZONE_ID environment variable is not set")
+		return nil, fmt.Errorf("ROUTE53_HOSTED_ZONE_ID is not set")
 	}
-
-	baseDomain := os.Getenv("BASE_DOMAIN")
 	if baseDomain == "" {
-		baseDomain = "snapdeploy.app"
+		return nil, fmt.Errorf("BASE_DOMAIN is not set")
 	}
 
 	return &Route53Client{
@@ -46,8 +54,12 @@ func NewRoute53Client() (*Route53Client, error) {
 // DNSRecordRequest contains information for creating/updating DNS records
 type DNSRecordRequest struct {
 	Subdomain string // e.g., "my-app"
-	Target    string // ALB DNS name or IP address
+	Target    string // ALB or CloudFront DNS name, or IP address
 	Type      string // "A" or "CNAME"
+
+	// HostedZoneID makes the record an alias of a target in another AWS hosted zone, such as an API
+	// Gateway domain name. ALB and CloudFront targets don't need it.
+	HostedZoneID string
 }
 
 // CreateOrUpdateRecord creates or updates a DNS record for a subdomain
@@ -60,6 +72,11 @@ func (c *Route53Client) CreateOrUpdateRecord(ctx context.Context, req DNSRecordR
 	if strings.Contains(req.Target, ".elb.amazonaws.com") {
 		// ALB target - use ALIAS record
 		change = c.createAliasChange(fullDomain, req.Target)
+	} else if strings.HasSuffix(req.Target, ".cloudfront.net") {
+		// Static sites are served by their CloudFront distribution
+		change = c.createTargetAliasChange(fullDomain, req.Target, cloudFrontHostedZoneID)
+	} else if req.HostedZoneID != "" {
+		change = c.createTargetAliasChange(fullDomain, req.Target, req.HostedZoneID)
 	} else {
 		// Regular CNAME or A record
 		if req.Type == "A" {
@@ -107,6 +124,23 @@ func (c *Route53Client) createAliasChange(fullDomain, albDNS string) types.Chang
 	}
 }
 
+// createTargetAliasChange creates an ALIAS record change for a CloudFront distribution or API Gateway
+// domain name. Neither has target health to evaluate.
+func (c *Route53Client) createTargetAliasChange(fullDomain, targetDNS, hostedZoneID string) types.Change {
+	return types.Change{
+		Action: types.ChangeActionUpsert,
+		ResourceRecordSet: &types.ResourceRecordSet{
+			Name: aws.String(fullDomain),
+			Type: types.RRTypeA,
+			AliasTarget: &types.AliasTarget{
+				DNSName:              aws.String(targetDNS),
+				HostedZoneId:         aws.String(hostedZoneID),
+				EvaluateTargetHealth: false,
+			},
+		},
+	}
+}
+
 // createCNAMEChange creates a CNAME record change
 func (c *Route53Client) createCNAMEChange(fullDomain, target string) types.Change {
 	return types.Change{
@@ -155,11 +189,16 @@ func (c *Route53Client) DeleteRecord(ctx context.Context, subdomain, recordType
 	}
 
 	if len(listResult.ResourceRecordSets) == 0 {
-		return fmt.Errorf("record not found")
+		return ErrRecordNotFound
 	}
 
 	recordSet := &listResult.ResourceRecordSets[0]
 
+	// Listing starts at the requested name, so the first record may belong to another domain
+	if aws.ToString(recordSet.Name) != fullDomain+"." || string(recordSet.Type) != recordType {
+		return ErrRecordNotFound
+	}
+
 	// Delete the record
 	change := types.Change{
 		Action:            types.ChangeActionDelete,
@@ -251,4 +290,3 @@ func getALBHostedZoneID(region string) string {
 	// Default to us-east-1 if region not found
 	return "Z35SXDOTRQ7X7K"
 }
-
@@ -0,0 +1,82 @@
+package sbom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"snapdeploy-core/internal/domain/deployment"
+)
+
+// format is the SBOM format generated, CycloneDX as JSON
+const format = "cyclonedx-json"
+
+// RegistryCredentials returns the host of a registry and credentials to pull its images with
+type RegistryCredentials interface {
+	RegistryCredentials(ctx context.Context) (host, username, password string, err error)
+}
+
+// SyftGenerator generates the SBOMs of pushed images with the syft CLI, which reads them from the registry
+type SyftGenerator struct {
+	path        string
+	credentials RegistryCredentials
+}
+
+// NewSyftGenerator creates a generator running the syft binary at path, or found in PATH under that name
+func NewSyftGenerator(path string) (*SyftGenerator, error) {
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("syft not found: %w", err)
+	}
+	return &SyftGenerator{path: resolved}, nil
+}
+
+// SetRegistryCredentials sets where the credentials of a private registry are read from (optional).
+// Without them syft pulls with the Docker credentials of the host.
+func (g *SyftGenerator) SetRegistryCredentials(credentials RegistryCredentials) {
+	g.credentials = credentials
+}
+
+// GenerateSBOM lists the packages of an image pushed to the registry
+func (g *SyftGenerator) GenerateSBOM(ctx context.Context, imageURI string) (*deployment.SBOM, error) {
+	cmd := exec.CommandContext(ctx, g.path, "scan", "registry:"+imageURI, "--output", format, "--quiet")
+	cmd.Env = os.Environ()
+	if g.credentials != nil {
+		host, username, password, err := g.credentials.RegistryCredentials(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cmd.Env = append(cmd.Env,
+			"SYFT_REGISTRY_AUTH_AUTHORITY="+host,
+			"SYFT_REGISTRY_AUTH_USERNAME="+username,
+			"SYFT_REGISTRY_AUTH_PASSWORD="+password,
+		)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("syft failed: %s", message)
+		}
+		return nil, fmt.Errorf("syft failed: %w", err)
+	}
+
+	var document struct {
+		Components []json.RawMessage `json:"components"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &document); err != nil {
+		return nil, fmt.Errorf("failed to parse SBOM: %w", err)
+	}
+
+	return &deployment.SBOM{
+		Format:   format,
+		Packages: len(document.Components),
+		Document: stdout.Bytes(),
+	}, nil
+}
@@ -0,0 +1,59 @@
+package stripe
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"snapdeploy-core/internal/domain/billing"
+	"snapdeploy-core/internal/domain/usage"
+	"snapdeploy-core/internal/domain/user"
+	"snapdeploy-core/internal/stripe"
+)
+
+// BillingProviderImpl implements the domain billing.Provider interface
+type BillingProviderImpl struct {
+	client          *stripe.Client
+	meters          map[usage.Metric]string // Event name of the Stripe meter each metric is reported to
+	portalReturnURL string
+}
+
+// NewBillingProvider creates a new Stripe billing provider implementation
+func NewBillingProvider(client *stripe.Client, buildMinutesMeter, fargateHoursMeter, portalReturnURL string) billing.Provider {
+	return &BillingProviderImpl{
+		client: client,
+		meters: map[usage.Metric]string{
+			usage.MetricBuildMinutes: buildMinutesMeter,
+			usage.MetricVCPUHours:    fargateHoursMeter,
+		},
+		portalReturnURL: portalReturnURL,
+	}
+}
+
+// CreateCustomer creates a customer for a user, tagged with their ID. Creating one for the same user again
+// within a day returns the first.
+func (p *BillingProviderImpl) CreateCustomer(ctx context.Context, userID user.UserID, email, name string) (string, error) {
+	customer, err := p.client.CreateCustomer(ctx, email, name, map[string]string{"user_id": userID.String()}, "customer-"+userID.String())
+	if err != nil {
+		return "", err
+	}
+	return customer.ID, nil
+}
+
+// ReportUsage sends a meter event for a metric
+func (p *BillingProviderImpl) ReportUsage(ctx context.Context, customerID string, metric usage.Metric, quantity int64, identifier string, at time.Time) error {
+	eventName, ok := p.meters[metric]
+	if !ok {
+		return fmt.Errorf("no Stripe meter for metric %s", metric)
+	}
+	return p.client.CreateMeterEvent(ctx, eventName, customerID, quantity, identifier, at)
+}
+
+// CreatePortalSession creates a customer portal session returning to the configured URL
+func (p *BillingProviderImpl) CreatePortalSession(ctx context.Context, customerID string) (string, error) {
+	session, err := p.client.CreatePortalSession(ctx, customerID, p.portalReturnURL)
+	if err != nil {
+		return "", err
+	}
+	return session.URL, nil
+}
@@ -0,0 +1,38 @@
+package sts
+
+import (
+	"context"
+	"fmt"
+
+	"snapdeploy-core/internal/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// STSClient wraps the AWS STS operations used to check the server's own credentials
+type STSClient struct {
+	client *sts.Client
+}
+
+// NewSTSClient creates a new STS client
+func NewSTSClient() (*STSClient, error) {
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	tracing.InstrumentAWS(&cfg)
+
+	return &STSClient{
+		client: sts.NewFromConfig(cfg),
+	}, nil
+}
+
+// CheckCredentials returns an error unless AWS accepts the server's credentials. GetCallerIdentity needs no
+// permission, so it only fails when the credentials are missing, expired or revoked.
+func (c *STSClient) CheckCredentials(ctx context.Context) error {
+	if _, err := c.client.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{}); err != nil {
+		return fmt.Errorf("AWS rejected the credentials: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package uptime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// userAgent identifies health checks in the access logs of projects
+const userAgent = "SnapDeploy-Uptime/1.0"
+
+// HTTPProber checks the health endpoints of projects over HTTP, the way their visitors reach them
+type HTTPProber struct {
+	client *http.Client
+}
+
+// NewHTTPProber creates a new prober whose checks give up after the timeout
+func NewHTTPProber(timeout time.Duration) *HTTPProber {
+	return &HTTPProber{
+		client: &http.Client{
+			Timeout: timeout,
+			// A redirect is an answer, following it would check another endpoint
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Probe requests the URL and returns the HTTP status it answered with
+func (p *HTTPProber) Probe(ctx context.Context, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	_, _ = io.CopyN(io.Discard, resp.Body, 4096)
+
+	return resp.StatusCode, nil
+}
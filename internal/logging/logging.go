@@ -0,0 +1,127 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CorrelationIDKey is the attribute carrying the ID that ties together the log lines of one request or build
+const CorrelationIDKey = "correlation_id"
+
+type attrsKey struct{}
+
+// Setup installs a leveled logger as the default for slog and the standard log package.
+// Level is one of debug, info, warn or error; format is json or text.
+func Setup(level, format string) *slog.Logger {
+	logger := New(os.Stdout, level, format)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// New creates a logger that adds the attributes stored in a record's context to every line
+func New(w io.Writer, level, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: parseLevel(level)}
+
+	var handler slog.Handler
+	if strings.EqualFold(format, "text") {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+
+	return slog.New(&contextHandler{Handler: handler})
+}
+
+// With returns a context whose log lines carry the given key-value pairs in addition to those already stored
+func With(ctx context.Context, args ...any) context.Context {
+	record := slog.Record{}
+	record.Add(args...)
+
+	attrs := append([]slog.Attr(nil), attrsFrom(ctx)...)
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+
+	return context.WithValue(ctx, attrsKey{}, attrs)
+}
+
+// WithCorrelationID returns a context whose log lines carry the correlation ID.
+// An empty ID leaves the context unchanged.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return With(ctx, CorrelationIDKey, id)
+}
+
+// CorrelationID returns the correlation ID stored in a context, or "" if there is none
+func CorrelationID(ctx context.Context) string {
+	for _, attr := range attrsFrom(ctx) {
+		if attr.Key == CorrelationIDKey {
+			return attr.Value.String()
+		}
+	}
+	return ""
+}
+
+// Detach returns a background context carrying only the log attributes of ctx,
+// for work that outlives the request that started it
+func Detach(ctx context.Context) context.Context {
+	attrs := attrsFrom(ctx)
+	if len(attrs) == 0 {
+		return context.Background()
+	}
+	return context.WithValue(context.Background(), attrsKey{}, attrs)
+}
+
+func attrsFrom(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// contextHandler adds the attributes stored with With, and the ID of the current trace, to each record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	attrs := attrsFrom(ctx)
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		attrs = append(attrs[:len(attrs):len(attrs)], slog.String("trace_id", span.TraceID().String()))
+	}
+	if len(attrs) > 0 {
+		record = record.Clone()
+		record.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -5,12 +5,18 @@ import (
 	"crypto/rsa"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math/big"
+	"math/rand/v2"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"snapdeploy-core/internal/config"
+	"snapdeploy-core/internal/presentation/apierror"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -30,11 +36,30 @@ type JWKSet struct {
 	Keys []JWK `json:"keys"`
 }
 
+const (
+	// minKeyRefreshInterval bounds how often tokens with an unknown key ID trigger a JWKS fetch,
+	// so forged tokens can't be used to hammer Clerk
+	minKeyRefreshInterval = 30 * time.Second
+
+	// jwksFetchAttempts and jwksRetryDelay control the retries of a failed JWKS fetch;
+	// the delay doubles after each attempt and is jittered so instances don't retry in lockstep
+	jwksFetchAttempts = 3
+	jwksRetryDelay    = 500 * time.Millisecond
+)
+
 // AuthMiddleware handles JWT authentication using Clerk
 type AuthMiddleware struct {
 	jwksURL    string
 	issuer     string
+	httpClient *http.Client
+
+	mu         sync.RWMutex
 	publicKeys map[string]*rsa.PublicKey
+
+	refreshMu   sync.Mutex // serializes JWKS fetches
+	lastFetchAt time.Time  // guarded by refreshMu
+
+	streamTokenKey []byte // signs the tokens streams are opened with, see IssueStreamToken
 }
 
 // NewAuthMiddleware creates a new authentication middleware
@@ -42,38 +67,75 @@ func NewAuthMiddleware(cfg *config.Config) (*AuthMiddleware, error) {
 	am := &AuthMiddleware{
 		jwksURL:    cfg.Clerk.JWKSURL,
 		issuer:     cfg.Clerk.Issuer,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
 		publicKeys: make(map[string]*rsa.PublicKey),
+
+		streamTokenKey: deriveStreamTokenKey(cfg.Clerk.SecretKey),
 	}
 
 	// Load public keys from JWKS endpoint
-	if err := am.loadPublicKeys(); err != nil {
+	if err := am.ReloadKeys(context.Background()); err != nil {
 		return nil, fmt.Errorf("failed to load public keys: %w", err)
 	}
 
 	return am, nil
 }
 
-// RequireAuth is a Gin middleware that requires authentication
+// ReloadKeys fetches the signing keys from the JWKS endpoint and replaces the cached ones.
+// The cached keys are kept if the fetch fails.
+func (am *AuthMiddleware) ReloadKeys(ctx context.Context) error {
+	am.refreshMu.Lock()
+	defer am.refreshMu.Unlock()
+
+	return am.reloadKeysLocked(ctx)
+}
+
+// RunKeyRefresh reloads the signing keys on every interval until the context is cancelled,
+// so keys Clerk retires stop being accepted
+func (am *AuthMiddleware) RunKeyRefresh(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := am.ReloadKeys(ctx); err != nil {
+				slog.WarnContext(ctx, "Failed to refresh JWKS, keeping cached keys", "error", err)
+			}
+		}
+	}
+}
+
+// RequireAuth is a Gin middleware that requires authentication. Requests without an Authorization header can
+// be authenticated with a stream token issued for their path instead, see IssueStreamToken.
 func (am *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Extract token from Authorization header
 		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" && c.Request.Method == http.MethodGet && c.Query(streamTokenParam) != "" {
+			user, err := am.verifyStreamToken(c.Query(streamTokenParam), c.Request.URL.Path)
+			if err != nil {
+				apierror.Abort(c, fmt.Errorf("%w: %w", apierror.ErrInvalidStreamToken, err))
+				return
+			}
+			c.Set("user", user)
+			c.Next()
+			return
+		}
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": "Authorization header is required",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.ErrMissingAuthorization)
 			return
 		}
 
 		// Check if the header starts with "Bearer "
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": "Authorization header must start with 'Bearer '",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.ErrNotBearer)
 			return
 		}
 
@@ -83,12 +145,7 @@ func (am *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 		// Verify the token with Clerk
 		user, err := am.verifyToken(c.Request.Context(), token)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "unauthorized",
-				"message": "Invalid token",
-				"details": err.Error(),
-			})
-			c.Abort()
+			apierror.Abort(c, fmt.Errorf("%w: %w", apierror.ErrInvalidToken, err))
 			return
 		}
 
@@ -114,12 +171,7 @@ func (am *AuthMiddleware) verifyToken(ctx context.Context, token string) (*Clerk
 		}
 
 		// Get the public key for this key ID
-		publicKey, exists := am.publicKeys[kid]
-		if !exists {
-			return nil, fmt.Errorf("unknown key ID: %s", kid)
-		}
-
-		return publicKey, nil
+		return am.publicKey(ctx, kid)
 	})
 
 	if err != nil {
@@ -165,20 +217,88 @@ func (am *AuthMiddleware) verifyToken(ctx context.Context, token string) (*Clerk
 	return user, nil
 }
 
-// loadPublicKeys loads public keys from the JWKS endpoint
-func (am *AuthMiddleware) loadPublicKeys() error {
-	resp, err := http.Get(am.jwksURL)
+// publicKey returns the key for a key ID, reloading the keys once if it is unknown
+func (am *AuthMiddleware) publicKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	if key := am.cachedKey(kid); key != nil {
+		return key, nil
+	}
+
+	// Clerk may have rotated its signing key since the keys were loaded
+	am.refreshMu.Lock()
+	if time.Since(am.lastFetchAt) >= minKeyRefreshInterval {
+		if err := am.reloadKeysLocked(ctx); err != nil {
+			slog.WarnContext(ctx, "Failed to refresh JWKS for unknown key ID", "kid", kid, "error", err)
+		}
+	}
+	am.refreshMu.Unlock()
+
+	if key := am.cachedKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key ID: %s", kid)
+}
+
+func (am *AuthMiddleware) cachedKey(kid string) *rsa.PublicKey {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return am.publicKeys[kid]
+}
+
+// reloadKeysLocked fetches the keys, retrying with jittered backoff. The caller must hold refreshMu.
+func (am *AuthMiddleware) reloadKeysLocked(ctx context.Context) error {
+	am.lastFetchAt = time.Now()
+
+	var keys map[string]*rsa.PublicKey
+	var err error
+	delay := jwksRetryDelay
+	for attempt := 1; attempt <= jwksFetchAttempts; attempt++ {
+		if keys, err = am.fetchPublicKeys(ctx); err == nil {
+			break
+		}
+		if attempt == jwksFetchAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay/2 + rand.N(delay)):
+		}
+		delay *= 2
+	}
+
+	am.mu.Lock()
+	am.publicKeys = keys
+	am.mu.Unlock()
+
+	slog.InfoContext(ctx, "Loaded JWKS", "keys", len(keys))
+	return nil
+}
+
+// fetchPublicKeys loads public keys from the JWKS endpoint
+func (am *AuthMiddleware) fetchPublicKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, am.jwksURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+
+	resp, err := am.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to fetch JWKS: %w", err)
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
 	var jwks JWKSet
 	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
-		return fmt.Errorf("failed to decode JWKS: %w", err)
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
 	}
 
 	// Convert JWKs to RSA public keys
+	keys := make(map[string]*rsa.PublicKey)
 	for _, jwk := range jwks.Keys {
 		if jwk.Kty != "RSA" {
 			continue
@@ -201,10 +321,15 @@ func (am *AuthMiddleware) loadPublicKeys() error {
 			E: int(new(big.Int).SetBytes(eBytes).Int64()),
 		}
 
-		am.publicKeys[jwk.Kid] = publicKey
+		keys[jwk.Kid] = publicKey
 	}
 
-	return nil
+	// An empty set would lock everyone out; keep the cached keys instead
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS contains no usable RSA keys")
+	}
+
+	return keys, nil
 }
 
 // ClerkUser represents a user from Clerk
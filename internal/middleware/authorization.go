@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResourceAuthorizer decides whether a Clerk user may access a resource addressed by ID
+type ResourceAuthorizer interface {
+	CanAccessUser(ctx context.Context, clerkUserID, userID string) (bool, error)
+	CanAccessProject(ctx context.Context, clerkUserID, projectID string) (bool, error)
+	CanAccessDeployment(ctx context.Context, clerkUserID, deploymentID string) (bool, error)
+}
+
+// accessCheck is one of the ResourceAuthorizer methods
+type accessCheck func(ctx context.Context, clerkUserID, resourceID string) (bool, error)
+
+// RequireUserAccess only allows requests for the signed-in user's own /users/:id routes
+func RequireUserAccess(authorizer ResourceAuthorizer, operatorIDs []string) gin.HandlerFunc {
+	return requireAccess("user", authorizer.CanAccessUser, apierror.ErrUserForbidden, operatorIDs)
+}
+
+// RequireProjectAccess only allows requests for /projects/:id routes of projects the signed-in user owns
+func RequireProjectAccess(authorizer ResourceAuthorizer, operatorIDs []string) gin.HandlerFunc {
+	return requireAccess("project", authorizer.CanAccessProject, project.ErrUnauthorized, operatorIDs)
+}
+
+// RequireDeploymentAccess only allows requests for /deployments/:id routes of deployments the signed-in user owns
+func RequireDeploymentAccess(authorizer ResourceAuthorizer, operatorIDs []string) gin.HandlerFunc {
+	return requireAccess("deployment", authorizer.CanAccessDeployment, deployment.ErrUnauthorized, operatorIDs)
+}
+
+// requireAccess checks the :id path parameter against the signed-in user before the handler runs, responding
+// with forbidden to users who may not access it. Platform operators can access every resource. It must run
+// after RequireAuth.
+func requireAccess(resource string, canAccess accessCheck, forbidden error, operatorIDs []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := ContextUser(c)
+		if !ok {
+			return
+		}
+
+		if IsOperator(user, operatorIDs) {
+			c.Next()
+			return
+		}
+
+		allowed, err := canAccess(c.Request.Context(), user.ID, c.Param("id"))
+		if err != nil {
+			// Resources that don't exist are mapped to not_found, other failures are logged by Errors
+			apierror.Abort(c, fmt.Errorf("failed to check access to %s: %w", resource, err))
+			return
+		}
+
+		if !allowed {
+			slog.WarnContext(c.Request.Context(), "Access denied", "resource", resource, "resource_id", c.Param("id"), "clerk_user_id", user.ID)
+			apierror.Abort(c, forbidden)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeAuthorizer lets user_owner access every resource, failing checks with err when it is set
+type fakeAuthorizer struct {
+	err error
+}
+
+func (a *fakeAuthorizer) check(ctx context.Context, clerkUserID, resourceID string) (bool, error) {
+	return clerkUserID == "user_owner", a.err
+}
+
+func (a *fakeAuthorizer) CanAccessUser(ctx context.Context, clerkUserID, userID string) (bool, error) {
+	return a.check(ctx, clerkUserID, userID)
+}
+
+func (a *fakeAuthorizer) CanAccessProject(ctx context.Context, clerkUserID, projectID string) (bool, error) {
+	return a.check(ctx, clerkUserID, projectID)
+}
+
+func (a *fakeAuthorizer) CanAccessDeployment(ctx context.Context, clerkUserID, deploymentID string) (bool, error) {
+	return a.check(ctx, clerkUserID, deploymentID)
+}
+
+func TestRequireProjectAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       any
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"owner", &ClerkUser{ID: "user_owner"}, nil, http.StatusOK, ""},
+		{"operator", &ClerkUser{ID: "user_operator"}, nil, http.StatusOK, ""},
+		{"other user", &ClerkUser{ID: "user_other"}, nil, http.StatusForbidden, "forbidden"},
+		{"missing project", &ClerkUser{ID: "user_owner"}, project.ErrProjectNotFound, http.StatusNotFound, "not_found"},
+		{"failed check", &ClerkUser{ID: "user_owner"}, errors.New("connection refused"), http.StatusInternalServerError, "internal_error"},
+		{"no user", nil, nil, http.StatusUnauthorized, "unauthorized"},
+		{"wrong user type", "user_owner", nil, http.StatusInternalServerError, "internal_error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/projects/:id", func(c *gin.Context) {
+				if tt.user != nil {
+					c.Set("user", tt.user)
+				}
+			}, RequireProjectAccess(&fakeAuthorizer{err: tt.err}, []string{"user_operator"}), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/projects/123", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var body apierror.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %s isn't an error response: %v", w.Body.String(), err)
+			}
+			if body.Error != tt.wantCode {
+				t.Errorf("error = %q, want %q", body.Error, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimit is a Gin middleware that rejects request bodies larger than maxBytes with 413, so a single client
+// can't exhaust memory or fill the database with one request. Bodies announcing their size are rejected before
+// they are read, others once they are read past the limit. routes sets limits of their own, lower or higher, for
+// the routes with these full paths, such as /api/v1/deployments/:id/logs. A limit of 0 disables it.
+// Use it ahead of the middleware that read bodies, such as OpenAPIValidation and Idempotency.
+func BodyLimit(maxBytes int64, routes map[string]int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := maxBytes
+		if routeLimit, ok := routes[c.FullPath()]; ok {
+			limit = routeLimit
+		}
+		if limit <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			apierror.Abort(c, &http.MaxBytesError{Limit: limit})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// RequireJSON is a Gin middleware that rejects requests with a body that isn't declared as JSON with 415, rather
+// than decoding whatever was sent as JSON. Requests without a body, such as most DELETE requests, pass through.
+func RequireJSON() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasBody(c.Request) || isJSON(c.GetHeader("Content-Type")) {
+			c.Next()
+			return
+		}
+		apierror.Abort(c, apierror.New(http.StatusUnsupportedMediaType, "unsupported_media_type",
+			"Request body must be JSON, sent with Content-Type: application/json"))
+	}
+}
+
+// hasBody reports whether a request carries a body, either with its size or chunked
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
@@ -1,19 +1,29 @@
 package middleware
 
 import (
+	"net/http"
+	"time"
+
+	"snapdeploy-core/internal/config"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"time"
 )
 
-// CORS returns a CORS middleware
-func CORS() gin.HandlerFunc {
+// CORS returns a middleware that lets browsers call the API from the configured origins only.
+// Requests from other origins are rejected with 403.
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
 	return cors.New(cors.Config{
-		AllowOrigins:     []string{"*"}, // In production, specify actual origins
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+		AllowOrigins: cfg.AllowedOrigins,
+		AllowMethods: []string{
+			http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions,
+		},
+		AllowHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization", RequestIDHeader, IdempotencyKeyHeader},
+		ExposeHeaders: []string{
+			BannerHeader, BannerSeverityHeader, BannerIncidentHeader, RequestIDHeader, "Retry-After",
+			RateLimitLimitHeader, RateLimitRemainingHeader, RateLimitResetHeader, IdempotentReplayedHeader,
+		},
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           time.Duration(cfg.MaxAgeSeconds) * time.Second,
 	})
 }
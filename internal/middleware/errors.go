@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Errors is a Gin middleware for the errors handlers record with apierror.Abort or c.Error. Errors responded
+// with a 5xx are logged with their cause, which the response leaves out. An error recorded without a response
+// gets the error response for it, so no request ends with an empty 200.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		last := c.Errors.Last()
+		if last == nil {
+			return
+		}
+		apierror.Respond(c, last.Err)
+
+		if status, _ := apierror.From(last.Err); status >= http.StatusInternalServerError {
+			slog.ErrorContext(c.Request.Context(), "Request failed",
+				"method", c.Request.Method,
+				"path", c.FullPath(),
+				"status", status,
+				"error", last.Err,
+			)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/idempotency"
+	"snapdeploy-core/internal/logging"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Idempotency headers, following the IETF Idempotency-Key header field draft
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// IdempotencyStore remembers the responses to requests made with an Idempotency-Key
+type IdempotencyStore interface {
+	Begin(ctx context.Context, ownerID, key, route, requestHash string) (*dto.IdempotentResponse, error)
+	Complete(ctx context.Context, ownerID, key string, statusCode int, body []byte) error
+	Release(ctx context.Context, ownerID, key string) error
+}
+
+// Idempotency makes a route safe to retry: a request repeated with the same Idempotency-Key header
+// gets the original response instead of being handled again. Requests without the header are handled as usual.
+// It must run after RequireAuth, as keys are scoped per user.
+func Idempotency(store IdempotencyStore, route string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		userData, exists := c.Get("user")
+		if !exists {
+			c.Next()
+			return
+		}
+		user, ok := userData.(*ClerkUser)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			apierror.Abort(c, apierror.Invalid("Failed to read request body", err))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		replay, err := store.Begin(ctx, user.ID, key, route, requestHash(c.Request, body))
+		if err != nil {
+			abortIdempotency(c, route, err)
+			return
+		}
+		if replay != nil {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(replay.StatusCode, gin.MIMEJSON+"; charset=utf-8", replay.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// The client may have gone away, which is usually why it retries
+		ctx = logging.Detach(ctx)
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+			// Let a retry run the request again
+			if err := store.Release(ctx, user.ID, key); err != nil {
+				slog.ErrorContext(ctx, "Failed to release idempotency key", "route", route, "error", err)
+			}
+			return
+		}
+		if err := store.Complete(ctx, user.ID, key, status, recorder.body.Bytes()); err != nil {
+			slog.ErrorContext(ctx, "Failed to store idempotent response", "route", route, "error", err)
+		}
+	}
+}
+
+// abortIdempotency responds to a request whose key can't be used. Handling the request without its key could
+// repeat it, so the request fails when the key can't be checked too.
+func abortIdempotency(c *gin.Context, route string, err error) {
+	if errors.Is(err, idempotency.ErrRequestInProgress) {
+		c.Header("Retry-After", "1")
+	}
+	apierror.Abort(c, fmt.Errorf("failed to check idempotency key of %s: %w", route, err))
+}
+
+// requestHash identifies a request by its method, path, query and body, so a key can't be reused for another
+// request, such as a dry run's key for the deployment it planned
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body as it is written
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"strings"
+
+	"snapdeploy-core/internal/openapi"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Modes of OpenAPI request validation
+const (
+	OpenAPIValidationOff     = "off"     // Requests aren't checked
+	OpenAPIValidationReport  = "report"  // Requests that don't match the document are logged and handled anyway
+	OpenAPIValidationEnforce = "enforce" // Requests that don't match the document are rejected with 400
+)
+
+// maxRecordedResponse bounds the response bodies kept for validation, larger ones aren't checked
+const maxRecordedResponse = 1 << 20
+
+// OpenAPIValidation is a Gin middleware that checks the query and JSON body of requests against the operation
+// of the OpenAPI document for their route, so every malformed request gets the same kind of error before it reaches
+// a handler. basePath is the prefix of the routes that the document's paths leave out, such as /api/v1.
+// With validateResponses, JSON responses that don't match the document are logged, for catching a drifting document.
+// Routes the document doesn't describe, or that don't respond with JSON, are passed through.
+func OpenAPIValidation(doc *openapi.Document, basePath, mode string, validateResponses bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if mode == OpenAPIValidationOff && !validateResponses {
+			c.Next()
+			return
+		}
+
+		// Clients of images and event streams couldn't read a JSON error, their handlers respond in kind
+		op := doc.Operation(c.Request.Method, strings.TrimPrefix(c.FullPath(), basePath))
+		if op == nil || !op.ServesJSON() {
+			c.Next()
+			return
+		}
+
+		if mode != OpenAPIValidationOff {
+			errs, err := validateRequest(c, op)
+			if err != nil {
+				apierror.Abort(c, apierror.Invalid("Failed to read request body", err))
+				return
+			}
+			if len(errs) > 0 {
+				if mode == OpenAPIValidationEnforce {
+					apierror.Abort(c, apierror.Invalid("Request doesn't match the API schema", errors.New(validationDetails(errs))))
+					return
+				}
+				slog.WarnContext(c.Request.Context(), "Request doesn't match the API schema",
+					"method", op.Method, "path", op.Path, "errors", validationDetails(errs))
+			}
+		}
+
+		if !validateResponses {
+			c.Next()
+			return
+		}
+
+		recorder := &boundedRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if recorder.overflow || recorder.body.Len() == 0 || !isJSON(recorder.Header().Get("Content-Type")) {
+			return
+		}
+		if errs := op.ValidateResponse(recorder.Status(), recorder.body.Bytes()); len(errs) > 0 {
+			slog.WarnContext(c.Request.Context(), "Response doesn't match the API schema",
+				"method", op.Method, "path", op.Path, "status", recorder.Status(), "errors", validationDetails(errs))
+		}
+	}
+}
+
+// validateRequest checks the query and body of a request, leaving the body for the handler to read again
+func validateRequest(c *gin.Context, op *openapi.Operation) ([]openapi.ValidationError, error) {
+	errs := op.ValidateQuery(c.Request.URL.Query())
+
+	// Bodies of other media types, such as webhook forms, are left to their handlers
+	contentType := c.GetHeader("Content-Type")
+	if !op.HasBody() || c.Request.Body == nil || (contentType != "" && !isJSON(contentType)) {
+		return errs, nil
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	return append(errs, op.ValidateBody(body)...), nil
+}
+
+// validationDetails lists validation errors as the details of an error response
+func validationDetails(errs []openapi.ValidationError) string {
+	details := make([]string, len(errs))
+	for i, err := range errs {
+		details[i] = err.Error()
+	}
+	return strings.Join(details, "; ")
+}
+
+// isJSON reports whether a Content-Type is JSON, such as application/json or application/problem+json
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == gin.MIMEJSON || strings.HasSuffix(mediaType, "+json")
+}
+
+// boundedRecorder keeps a copy of the response body as it is written, up to maxRecordedResponse
+type boundedRecorder struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *boundedRecorder) Write(data []byte) (int, error) {
+	w.record(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *boundedRecorder) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *boundedRecorder) record(data []byte) {
+	if w.overflow {
+		return
+	}
+	if w.body.Len()+len(data) > maxRecordedResponse {
+		w.overflow = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(data)
+}
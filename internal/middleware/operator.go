@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireOperator is a Gin middleware that only allows platform operators
+// It must run after RequireAuth so the Clerk user is available in the context
+func RequireOperator(operatorIDs []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := ContextUser(c)
+		if !ok {
+			return
+		}
+
+		if !IsOperator(user, operatorIDs) {
+			apierror.Abort(c, apierror.ErrOperatorOnly)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ContextUser returns the Clerk user RequireAuth put in the context, or aborts the request if there is none
+func ContextUser(c *gin.Context) (*ClerkUser, bool) {
+	userData, exists := c.Get("user")
+	if !exists {
+		apierror.Abort(c, apierror.ErrNoUser)
+		return nil, false
+	}
+
+	user, ok := userData.(*ClerkUser)
+	if !ok {
+		apierror.Abort(c, apierror.ErrUserType)
+		return nil, false
+	}
+	return user, true
+}
+
+// IsOperator checks if the Clerk user is a platform operator
+func IsOperator(user *ClerkUser, operatorIDs []string) bool {
+	for _, id := range operatorIDs {
+		if user.ID == id {
+			return true
+		}
+	}
+	return false
+}
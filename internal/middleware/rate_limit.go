@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"snapdeploy-core/internal/presentation/apierror"
+	"snapdeploy-core/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Rate limit headers, following the IETF RateLimit header fields draft
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+)
+
+// RateLimit limits how often each user calls a route.
+// It must run after RequireAuth, which puts the user in the context.
+func RateLimit(store ratelimit.Store, route string, limit ratelimit.Limit) gin.HandlerFunc {
+	if !limit.Enabled() {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		user, ok := ContextUser(c)
+		if !ok {
+			return
+		}
+
+		result, err := store.Take(c.Request.Context(), route+":user:"+user.ID, limit)
+		if err != nil {
+			// An unavailable store shouldn't take the API down with it
+			slog.WarnContext(c.Request.Context(), "Rate limit check failed, allowing request", "route", route, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+		c.Header(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+		c.Header(RateLimitResetHeader, strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			apierror.Abort(c, apierror.RetryAfter(apierror.ErrRateLimited, max(1, ceilSeconds(result.RetryAfter))))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"snapdeploy-core/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeStore answers every request with the same result, recording the keys taken from
+type fakeStore struct {
+	result ratelimit.Result
+	keys   []string
+}
+
+func (s *fakeStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	s.keys = append(s.keys, key)
+	return s.result, nil
+}
+
+// serveRateLimited calls a route limited with store, as user when one is given
+func serveRateLimited(store ratelimit.Store, user *ClerkUser) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/deployments", func(c *gin.Context) {
+		if user != nil {
+			c.Set("user", user)
+		}
+	}, RateLimit(store, "create_deployment", ratelimit.Limit{PerMinute: 6, Burst: 3}), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/deployments", nil))
+	return w
+}
+
+func TestRateLimit_Headers(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      ratelimit.Result
+		wantStatus  int
+		wantHeaders map[string]string
+	}{
+		{
+			name:       "allowed",
+			result:     ratelimit.Result{Allowed: true, Limit: 3, Remaining: 2, Reset: 10 * time.Second},
+			wantStatus: http.StatusCreated,
+			wantHeaders: map[string]string{
+				RateLimitLimitHeader:     "3",
+				RateLimitRemainingHeader: "2",
+				RateLimitResetHeader:     "10",
+				"Retry-After":            "",
+			},
+		},
+		{
+			name:       "denied",
+			result:     ratelimit.Result{Limit: 3, Remaining: 0, Reset: 29500 * time.Millisecond, RetryAfter: 9500 * time.Millisecond},
+			wantStatus: http.StatusTooManyRequests,
+			wantHeaders: map[string]string{
+				RateLimitLimitHeader:     "3",
+				RateLimitRemainingHeader: "0",
+				RateLimitResetHeader:     "30",
+				"Retry-After":            "10",
+			},
+		},
+		{
+			// Clients shouldn't be told to retry straight away
+			name:        "denied until the next instant",
+			result:      ratelimit.Result{Limit: 3, RetryAfter: time.Nanosecond},
+			wantStatus:  http.StatusTooManyRequests,
+			wantHeaders: map[string]string{"Retry-After": "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{result: tt.result}
+			w := serveRateLimited(store, &ClerkUser{ID: "user_123"})
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			for header, want := range tt.wantHeaders {
+				if got := w.Header().Get(header); got != want {
+					t.Errorf("%s = %q, want %q", header, got, want)
+				}
+			}
+			if len(store.keys) != 1 || store.keys[0] != "create_deployment:user:user_123" {
+				t.Errorf("keys = %v, want the user's bucket of the route", store.keys)
+			}
+		})
+	}
+}
+
+func TestRateLimit_RequiresUser(t *testing.T) {
+	store := &fakeStore{result: ratelimit.Result{Allowed: true}}
+	w := serveRateLimited(store, nil)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d without a user", w.Code, http.StatusUnauthorized)
+	}
+	if len(store.keys) != 0 {
+		t.Errorf("keys = %v, want no token taken without a user", store.keys)
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"snapdeploy-core/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the correlation ID of a request, both inbound and on the response
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied IDs so they can't bloat log lines
+const maxRequestIDLength = 128
+
+// RequestID assigns every request a correlation ID, reusing the caller's X-Request-ID when present.
+// The ID is stored in the request context so log lines of the request and the work it starts carry it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = uuid.NewString()
+		}
+
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithCorrelationID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// RequestLogger logs each request once it has been handled
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		level := slog.LevelInfo
+		if c.Writer.Status() >= 500 {
+			level = slog.LevelError
+		}
+		slog.Log(c.Request.Context(), level, "request handled",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", c.Writer.Status(),
+			"duration_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+		)
+	}
+}
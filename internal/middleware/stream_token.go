@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// streamTokenParam is the query parameter stream tokens are sent in, as EventSource can't send headers
+	streamTokenParam = "stream_token"
+
+	// streamTokenTTL is how long a stream token can be used to open its stream
+	streamTokenTTL = 2 * time.Minute
+)
+
+// StreamTokenResponse is the response to a request for a stream token
+type StreamTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// IssueStreamToken is a Gin handler that issues a stream token to the signed-in user for the stream at the
+// request's path without its /token suffix, such as /api/v1/deployments/:id/logs/stream for
+// /api/v1/deployments/:id/logs/stream/token. Browsers open the stream with EventSource, which can't send the
+// Authorization header, with the token in the stream_token query parameter instead. A token only opens the
+// stream it was issued for, and only for a couple of minutes, so one leaked through a URL is of little use.
+// It must run after RequireAuth and the middleware authorizing access to the stream.
+func (am *AuthMiddleware) IssueStreamToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := ContextUser(c)
+		if !ok {
+			return
+		}
+
+		path := strings.TrimSuffix(c.Request.URL.Path, "/token")
+		expiresAt := time.Now().Add(streamTokenTTL)
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+			Subject:   user.ID,
+			Audience:  jwt.ClaimStrings{path},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		}).SignedString(am.streamTokenKey)
+		if err != nil {
+			apierror.Abort(c, fmt.Errorf("failed to issue stream token: %w", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, StreamTokenResponse{Token: token, ExpiresAt: expiresAt.UTC()})
+	}
+}
+
+// verifyStreamToken verifies a stream token issued for the stream at path and returns its user
+func (am *AuthMiddleware) verifyStreamToken(token, path string) (*ClerkUser, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		return am.streamTokenKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(path),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse stream token: %w", err)
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("stream token has no subject")
+	}
+	return &ClerkUser{ID: claims.Subject}, nil
+}
+
+// deriveStreamTokenKey derives the key stream tokens are signed with from the Clerk secret key, which every
+// instance shares, so a token issued by one instance opens streams served by any
+func deriveStreamTokenKey(secretKey string) []byte {
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	mac.Write([]byte("snapdeploy stream tokens"))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"context"
+
+	"snapdeploy-core/internal/application/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Response headers carrying the active system banner, exposed to browsers by CORS
+const (
+	BannerHeader         = "X-SnapDeploy-Banner"
+	BannerSeverityHeader = "X-SnapDeploy-Banner-Severity"
+	BannerIncidentHeader = "X-SnapDeploy-Banner-Incident"
+)
+
+// BannerProvider supplies the banner for the currently active incident
+type BannerProvider interface {
+	CurrentBanner(ctx context.Context) *dto.SystemBanner
+}
+
+// SystemBanner is a Gin middleware that attaches the active incident banner to every API response
+func SystemBanner(provider BannerProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if banner := provider.CurrentBanner(c.Request.Context()); banner != nil {
+			c.Header(BannerHeader, banner.Title+": "+banner.Message)
+			c.Header(BannerSeverityHeader, banner.Severity)
+			c.Header(BannerIncidentHeader, banner.IncidentID)
+		}
+		c.Next()
+	}
+}
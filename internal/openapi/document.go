@@ -0,0 +1,255 @@
+// Package openapi loads the OpenAPI document of the HTTP API and checks requests and responses against it
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a parsed OpenAPI 3.1 document
+type Document struct {
+	root       map[string]any
+	json       []byte
+	operations map[string]*Operation
+	schemas    *schemaSet
+}
+
+// Operation is an operation of the document: a method on a path
+type Operation struct {
+	Method       string
+	Path         string // Path template of the document, e.g. /projects/{id}
+	parameters   []parameter
+	body         any            // JSON schema of the request body, nil without a JSON body
+	bodyRequired bool           // Whether requests must have a body
+	responses    map[string]any // JSON schema of the response body by status code, 2XX range or default
+	otherMedia   bool           // Whether it succeeds with bodies of other media types only, such as SVG badges
+	schemas      *schemaSet
+}
+
+// parameter is a query parameter of an operation
+type parameter struct {
+	name     string
+	required bool
+	schema   any
+}
+
+// methods are the operations a path item may have
+var methods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+}
+
+// Load parses an OpenAPI 3.1 document in YAML or JSON
+func Load(data []byte) (*Document, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+	root, ok := normalize(raw).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("OpenAPI document must be an object")
+	}
+
+	version, _ := root["openapi"].(string)
+	if !strings.HasPrefix(version, "3.1.") {
+		return nil, fmt.Errorf("OpenAPI document must be version 3.1, got %q", version)
+	}
+
+	encoded, err := json.Marshal(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAPI document: %w", err)
+	}
+
+	doc := &Document{
+		root:       root,
+		json:       encoded,
+		operations: make(map[string]*Operation),
+		schemas:    newSchemaSet(root),
+	}
+
+	paths, _ := root["paths"].(map[string]any)
+	for path, item := range paths {
+		pathItem, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		shared := doc.parameters(pathItem["parameters"])
+		for _, method := range methods {
+			op, ok := pathItem[strings.ToLower(method)].(map[string]any)
+			if !ok {
+				continue
+			}
+			doc.operations[operationKey(method, path)] = doc.operation(method, path, op, shared)
+		}
+	}
+
+	// Every schema is compiled once up front, so a broken document fails to load instead of failing requests
+	if err := doc.schemas.check(); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// JSON returns the document encoded as JSON
+func (d *Document) JSON() []byte {
+	return d.json
+}
+
+// Operation finds the operation of a method on a path template, in the document's {name} or gin's :name form.
+// Path parameters are matched by position, whatever their names. Returns nil if the document doesn't have it.
+func (d *Document) Operation(method, path string) *Operation {
+	return d.operations[operationKey(method, path)]
+}
+
+// operation reads an operation of the document
+func (d *Document) operation(method, path string, op map[string]any, shared []parameter) *Operation {
+	operation := &Operation{
+		Method:     method,
+		Path:       path,
+		parameters: slices.Concat(shared, d.parameters(op["parameters"])),
+		responses:  make(map[string]any),
+		schemas:    d.schemas,
+	}
+
+	if body, ok := d.resolve(op["requestBody"]).(map[string]any); ok {
+		operation.bodyRequired, _ = body["required"].(bool)
+		operation.body = jsonSchema(body["content"])
+	}
+
+	responses, _ := op["responses"].(map[string]any)
+	successJSON, successOther := false, false
+	for status, value := range responses {
+		response, _ := d.resolve(value).(map[string]any)
+		schema := jsonSchema(response["content"])
+		if schema != nil {
+			operation.responses[status] = schema
+		}
+		if content, _ := response["content"].(map[string]any); strings.HasPrefix(status, "2") && len(content) > 0 {
+			successJSON = successJSON || schema != nil
+			successOther = successOther || schema == nil
+		}
+	}
+	operation.otherMedia = successOther && !successJSON
+
+	return operation
+}
+
+// parameters reads the query parameters of a path item or operation
+func (d *Document) parameters(value any) []parameter {
+	list, _ := value.([]any)
+	params := make([]parameter, 0, len(list))
+	for _, item := range list {
+		p, ok := d.resolve(item).(map[string]any)
+		if !ok || p["in"] != "query" {
+			continue
+		}
+		name, _ := p["name"].(string)
+		required, _ := p["required"].(bool)
+		params = append(params, parameter{name: name, required: required, schema: p["schema"]})
+	}
+	return params
+}
+
+// resolve follows the $ref of a parameter, request body or response to its definition
+func (d *Document) resolve(value any) any {
+	for range 10 {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return value
+		}
+		ref, ok := obj["$ref"].(string)
+		if !ok {
+			return obj
+		}
+		target, err := pointer(d.root, ref)
+		if err != nil {
+			return nil
+		}
+		value = target
+	}
+	return nil
+}
+
+// jsonSchema returns the schema of the JSON media type of a content map, nil if it has none
+func jsonSchema(content any) any {
+	media, _ := content.(map[string]any)
+	for contentType, value := range media {
+		if !isJSON(contentType) {
+			continue
+		}
+		if mediaType, ok := value.(map[string]any); ok {
+			return mediaType["schema"]
+		}
+	}
+	return nil
+}
+
+// isJSON reports whether a media type is JSON, such as application/json or application/problem+json
+func isJSON(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// operationKey identifies an operation by its method and path, path parameters replaced by {}
+func operationKey(method, path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") ||
+			(strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")) {
+			segments[i] = "{}"
+		}
+	}
+	return method + " " + strings.Join(segments, "/")
+}
+
+// pointer resolves a local JSON pointer reference such as #/components/schemas/Project
+func pointer(root map[string]any, ref string) (any, error) {
+	path, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil, fmt.Errorf("only local references are supported, got %q", ref)
+	}
+
+	var value any = root
+	for _, token := range strings.Split(path, "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("reference %q not found", ref)
+		}
+		if value, ok = obj[token]; !ok {
+			return nil, fmt.Errorf("reference %q not found", ref)
+		}
+	}
+	return value, nil
+}
+
+// normalize turns the maps YAML decodes with keys of other types, such as response codes, into string-keyed maps
+func normalize(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalize(item)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, item := range v {
+			m[fmt.Sprint(key)] = normalize(item)
+		}
+		return m
+	case []any:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
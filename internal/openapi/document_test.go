@@ -0,0 +1,128 @@
+package openapi_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"snapdeploy-core/api"
+	"snapdeploy-core/internal/openapi"
+)
+
+func loadDocument(t *testing.T) *openapi.Document {
+	t.Helper()
+	doc, err := openapi.Load(api.OpenAPI)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return doc
+}
+
+func TestLoad(t *testing.T) {
+	doc := loadDocument(t)
+
+	if !strings.Contains(string(doc.JSON()), `"openapi":"3.1.0"`) {
+		t.Errorf("JSON() doesn't carry the document version")
+	}
+	// Gin's :name parameters match the document's {name} ones, whatever their names
+	if op := doc.Operation("POST", "/projects/:id/duplicate"); op == nil || op.Path != "/projects/{id}/duplicate" {
+		t.Errorf("Operation(POST /projects/:id/duplicate) = %+v, want /projects/{id}/duplicate", op)
+	}
+	if op := doc.Operation("DELETE", "/templates"); op != nil {
+		t.Errorf("Operation(DELETE /templates) = %+v, want none", op)
+	}
+	if op := doc.Operation("GET", "/badges/projects/:id/status.svg"); op == nil || op.ServesJSON() {
+		t.Errorf("Operation(GET /badges/projects/:id/status.svg).ServesJSON() = true, want false for an SVG badge")
+	}
+}
+
+func TestLoadRejectsOtherVersions(t *testing.T) {
+	if _, err := openapi.Load([]byte("openapi: 3.0.3\npaths: {}\n")); err == nil {
+		t.Error("Load() of an OpenAPI 3.0 document succeeded, want an error")
+	}
+	if _, err := openapi.Load([]byte("openapi: 3.1.0\npaths: {}\ncomponents:\n  schemas:\n    A:\n      $ref: '#/components/schemas/B'\n")); err == nil {
+		t.Error("Load() of a document with a dangling reference succeeded, want an error")
+	}
+}
+
+func TestOperation_ValidateBody(t *testing.T) {
+	op := loadDocument(t).Operation("POST", "/users/:id/projects")
+
+	tests := []struct {
+		name      string
+		body      string
+		wantField string // Field of the first error, empty for a valid body
+	}{
+		{name: "valid", body: `{"repository_url": "https://github.com/a/b", "install_command": "npm ci", "run_command": "npm start", "language": "NODE", "custom_domain": ""}`},
+		{name: "null custom domain", body: `{"repository_url": "https://github.com/a/b", "install_command": "npm ci", "run_command": "npm start", "language": "NODE", "custom_domain": null}`},
+		{name: "missing field", body: `{"repository_url": "https://github.com/a/b", "install_command": "npm ci", "language": "NODE"}`, wantField: "body.run_command"},
+		{name: "unknown language", body: `{"repository_url": "https://github.com/a/b", "install_command": "npm ci", "run_command": "npm start", "language": "RUST"}`, wantField: "body.language"},
+		{name: "wrong type", body: `{"repository_url": "https://github.com/a/b", "install_command": "npm ci", "run_command": "npm start", "language": "NODE", "image_retention": "5"}`, wantField: "body.image_retention"},
+		{name: "fractional integer", body: `{"repository_url": "https://github.com/a/b", "install_command": "npm ci", "run_command": "npm start", "language": "NODE", "canary_percent": 2.5}`, wantField: "body.canary_percent"},
+		{name: "out of range", body: `{"repository_url": "https://github.com/a/b", "install_command": "npm ci", "run_command": "npm start", "language": "NODE", "build_timeout_minutes": 3}`, wantField: "body.build_timeout_minutes"},
+		{name: "invalid domain", body: `{"repository_url": "https://github.com/a/b", "install_command": "npm ci", "run_command": "npm start", "language": "NODE", "custom_domain": "My_App"}`, wantField: "body.custom_domain"},
+		{name: "not JSON", body: `{"repository_url":`, wantField: "body"},
+		{name: "missing body", body: ``, wantField: "body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := op.ValidateBody([]byte(tt.body))
+			if tt.wantField == "" {
+				if len(errs) != 0 {
+					t.Errorf("ValidateBody() = %v, want no errors", errs)
+				}
+				return
+			}
+			if len(errs) == 0 || errs[0].Field != tt.wantField {
+				t.Errorf("ValidateBody() = %v, want an error for %s", errs, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestOperation_ValidateQuery(t *testing.T) {
+	op := loadDocument(t).Operation("GET", "/projects/:id/deployments")
+
+	tests := []struct {
+		name      string
+		query     string
+		wantField string
+	}{
+		{name: "valid", query: "page=2&limit=50&status=DEPLOYED&label=env:prod&label=team:web"},
+		{name: "no parameters", query: ""},
+		{name: "not a number", query: "page=two", wantField: "query.page"},
+		{name: "over the maximum", query: "limit=500", wantField: "query.limit"},
+		{name: "unknown status", query: "status=DONE", wantField: "query.status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs := op.ValidateQuery(query)
+			if tt.wantField == "" {
+				if len(errs) != 0 {
+					t.Errorf("ValidateQuery() = %v, want no errors", errs)
+				}
+				return
+			}
+			if len(errs) == 0 || errs[0].Field != tt.wantField {
+				t.Errorf("ValidateQuery() = %v, want an error for %s", errs, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestOperation_ValidateResponse(t *testing.T) {
+	op := loadDocument(t).Operation("GET", "/templates/:template_id")
+
+	if errs := op.ValidateResponse(404, []byte(`{"error": "not_found", "message": "Template not found"}`)); len(errs) != 0 {
+		t.Errorf("ValidateResponse(404) = %v, want no errors", errs)
+	}
+	if errs := op.ValidateResponse(404, []byte(`{"error": "not_found", "details": 3}`)); len(errs) != 2 {
+		t.Errorf("ValidateResponse(404) = %v, want errors for the missing message and the details", errs)
+	}
+}
@@ -0,0 +1,327 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxDepth bounds how deeply schemas are followed, so a recursive schema can't loop forever
+const maxDepth = 64
+
+// ValidationError is a part of a request or response that doesn't match its schema
+type ValidationError struct {
+	Field   string // Where the value is, such as body.services[0].name or query.page
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// schemaSet validates values against the JSON schemas of a document, which they reference by local pointers
+type schemaSet struct {
+	root     map[string]any
+	patterns map[string]*regexp.Regexp // Compiled once by check, only read afterwards
+}
+
+func newSchemaSet(root map[string]any) *schemaSet {
+	return &schemaSet{root: root, patterns: make(map[string]*regexp.Regexp)}
+}
+
+// check makes sure every reference of the document resolves and every pattern compiles
+func (s *schemaSet) check() error {
+	return s.walk(s.root, "#")
+}
+
+func (s *schemaSet) walk(value any, location string) error {
+	switch v := value.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok {
+			if _, err := pointer(s.root, ref); err != nil {
+				return fmt.Errorf("%s: %w", location, err)
+			}
+		}
+		if pattern, ok := v["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("%s: invalid pattern %q: %w", location, pattern, err)
+			}
+			s.patterns[pattern] = re
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			// Examples are free-form, a key named pattern inside one isn't a schema keyword
+			if key == "example" || key == "examples" {
+				continue
+			}
+			if err := s.walk(v[key], location+"/"+key); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, item := range v {
+			if err := s.walk(item, location+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks a decoded JSON value, whose numbers are json.Number, against a schema
+func (s *schemaSet) validate(value, schema any, field string) []ValidationError {
+	var errs []ValidationError
+	s.validateAt(value, schema, field, 0, &errs)
+	return errs
+}
+
+func (s *schemaSet) validateAt(value, schema any, field string, depth int, errs *[]ValidationError) {
+	sch, ok := schema.(map[string]any)
+	if !ok || depth > maxDepth {
+		return
+	}
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if ref, ok := sch["$ref"].(string); ok {
+		if target, err := pointer(s.root, ref); err == nil {
+			s.validateAt(value, target, field, depth+1, errs)
+		}
+	}
+	if all, ok := sch["allOf"].([]any); ok {
+		for _, sub := range all {
+			s.validateAt(value, sub, field, depth+1, errs)
+		}
+	}
+	if anyOf, ok := sch["anyOf"].([]any); ok && s.matching(value, anyOf, field, depth) == 0 {
+		fail("must match at least one of the allowed schemas")
+	}
+	if oneOf, ok := sch["oneOf"].([]any); ok && s.matching(value, oneOf, field, depth) != 1 {
+		fail("must match exactly one of the allowed schemas")
+	}
+
+	if types := schemaTypes(sch["type"]); len(types) > 0 && !slices.ContainsFunc(types, func(t string) bool { return hasType(value, t) }) {
+		fail("must be %s", article(types))
+		return
+	}
+
+	if enum, ok := sch["enum"].([]any); ok && !slices.ContainsFunc(enum, func(allowed any) bool { return equal(value, allowed) }) {
+		values := make([]string, len(enum))
+		for i, allowed := range enum {
+			values[i] = fmt.Sprint(allowed)
+		}
+		fail("must be one of %s", strings.Join(values, ", "))
+	}
+	if constant, ok := sch["const"]; ok && !equal(value, constant) {
+		fail("must be %v", constant)
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if limit, ok := number(sch["minLength"]); ok && float64(length) < limit {
+			fail("must be at least %v characters long", limit)
+		}
+		if limit, ok := number(sch["maxLength"]); ok && float64(length) > limit {
+			fail("must be at most %v characters long", limit)
+		}
+		if pattern, ok := sch["pattern"].(string); ok {
+			if re := s.patterns[pattern]; re != nil && !re.MatchString(v) {
+				fail("must match the pattern %s", pattern)
+			}
+		}
+	case json.Number:
+		n, err := v.Float64()
+		if err != nil {
+			fail("must be a number")
+			return
+		}
+		if limit, ok := number(sch["minimum"]); ok && n < limit {
+			fail("must be at least %v", limit)
+		}
+		if limit, ok := number(sch["maximum"]); ok && n > limit {
+			fail("must be at most %v", limit)
+		}
+		if limit, ok := number(sch["exclusiveMinimum"]); ok && n <= limit {
+			fail("must be greater than %v", limit)
+		}
+		if limit, ok := number(sch["exclusiveMaximum"]); ok && n >= limit {
+			fail("must be less than %v", limit)
+		}
+	case []any:
+		if limit, ok := number(sch["minItems"]); ok && float64(len(v)) < limit {
+			fail("must have at least %v items", limit)
+		}
+		if limit, ok := number(sch["maxItems"]); ok && float64(len(v)) > limit {
+			fail("must have at most %v items", limit)
+		}
+		if items, ok := sch["items"]; ok {
+			for i, item := range v {
+				s.validateAt(item, items, fmt.Sprintf("%s[%d]", field, i), depth+1, errs)
+			}
+		}
+	case map[string]any:
+		s.validateObject(v, sch, field, depth, errs)
+	}
+}
+
+// validateObject checks the properties of an object
+func (s *schemaSet) validateObject(obj, sch map[string]any, field string, depth int, errs *[]ValidationError) {
+	if limit, ok := number(sch["minProperties"]); ok && float64(len(obj)) < limit {
+		*errs = append(*errs, ValidationError{Field: field, Message: fmt.Sprintf("must have at least %v properties", limit)})
+	}
+	if limit, ok := number(sch["maxProperties"]); ok && float64(len(obj)) > limit {
+		*errs = append(*errs, ValidationError{Field: field, Message: fmt.Sprintf("must have at most %v properties", limit)})
+	}
+
+	required, _ := sch["required"].([]any)
+	for _, name := range required {
+		key, _ := name.(string)
+		if _, ok := obj[key]; !ok {
+			*errs = append(*errs, ValidationError{Field: field + "." + key, Message: "is required"})
+		}
+	}
+
+	// Properties are checked in name order, so errors are listed the same way every time
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	properties, _ := sch["properties"].(map[string]any)
+	for _, key := range keys {
+		if property, ok := properties[key]; ok {
+			s.validateAt(obj[key], property, field+"."+key, depth+1, errs)
+			continue
+		}
+		switch additional := sch["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*errs = append(*errs, ValidationError{Field: field + "." + key, Message: "is not allowed"})
+			}
+		case map[string]any:
+			s.validateAt(obj[key], additional, field+"."+key, depth+1, errs)
+		}
+	}
+}
+
+// matching counts the schemas a value matches
+func (s *schemaSet) matching(value any, schemas []any, field string, depth int) int {
+	matched := 0
+	for _, sub := range schemas {
+		var errs []ValidationError
+		s.validateAt(value, sub, field, depth+1, &errs)
+		if len(errs) == 0 {
+			matched++
+		}
+	}
+	return matched
+}
+
+// schemaTypes returns the types a schema allows, from a single type or a list such as [string, "null"]
+func schemaTypes(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		types := make([]string, 0, len(v))
+		for _, t := range v {
+			if name, ok := t.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// hasType reports whether a decoded JSON value is of a JSON schema type
+func hasType(value any, schemaType string) bool {
+	switch schemaType {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		if _, err := n.Int64(); err == nil {
+			return true
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	}
+	return true
+}
+
+// article describes the types a value must have, e.g. "a string or null"
+func article(types []string) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		switch t {
+		case "null":
+			names[i] = "null"
+		case "array", "integer", "object":
+			names[i] = "an " + t
+		default:
+			names[i] = "a " + t
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
+// equal compares a decoded JSON value with a value of the document, numbers by value
+func equal(value, want any) bool {
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		w, isNumber := number(want)
+		return err == nil && isNumber && f == w
+	}
+	return value == want
+}
+
+// number converts a number of the document, decoded from YAML as an int or float64, to a float64
+func number(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
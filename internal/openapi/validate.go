@@ -0,0 +1,169 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ValidateQuery checks the query string of a request against the operation's query parameters.
+// Values are converted to the type of their schema first, since every query value is a string.
+func (o *Operation) ValidateQuery(query url.Values) []ValidationError {
+	var errs []ValidationError
+	for _, p := range o.parameters {
+		values, ok := query[p.name]
+		if !ok {
+			if p.required {
+				errs = append(errs, ValidationError{Field: "query." + p.name, Message: "is required"})
+			}
+			continue
+		}
+		value, ok := o.queryValue(values, p.schema)
+		if !ok {
+			errs = append(errs, ValidationError{Field: "query." + p.name, Message: "must be " + article(o.queryTypes(p.schema))})
+			continue
+		}
+		errs = append(errs, o.schemas.validate(value, p.schema, "query."+p.name)...)
+	}
+	return errs
+}
+
+// ValidateBody checks a request body against the operation's request body schema
+func (o *Operation) ValidateBody(body []byte) []ValidationError {
+	if len(bytes.TrimSpace(body)) == 0 {
+		if o.bodyRequired {
+			return []ValidationError{{Field: "body", Message: "is required"}}
+		}
+		return nil
+	}
+	if o.body == nil {
+		return nil
+	}
+	return o.validateJSON(body, o.body, "body")
+}
+
+// HasBody reports whether requests of the operation may have a JSON body
+func (o *Operation) HasBody() bool {
+	return o.body != nil || o.bodyRequired
+}
+
+// ServesJSON reports whether the operation responds with JSON, whose clients can read a JSON error.
+// Operations that only succeed with other media, such as images or event streams, don't.
+func (o *Operation) ServesJSON() bool {
+	return !o.otherMedia
+}
+
+// ValidateResponse checks a response body against the operation's schema for its status code,
+// falling back to the status code's range, e.g. 2XX, and then to the default response
+func (o *Operation) ValidateResponse(status int, body []byte) []ValidationError {
+	code := strconv.Itoa(status)
+	schema, ok := o.responses[code]
+	if !ok {
+		schema, ok = o.responses[code[:1]+"XX"]
+	}
+	if !ok {
+		schema, ok = o.responses["default"]
+	}
+	if !ok {
+		return nil
+	}
+	return o.validateJSON(body, schema, "response")
+}
+
+// validateJSON decodes a JSON document, keeping numbers exact, and validates it
+func (o *Operation) validateJSON(data []byte, schema any, field string) []ValidationError {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return []ValidationError{{Field: field, Message: "must be valid JSON: " + err.Error()}}
+	}
+	if err := decoder.Decode(new(any)); !errors.Is(err, io.EOF) {
+		return []ValidationError{{Field: field, Message: "must be a single JSON value"}}
+	}
+	return o.schemas.validate(value, schema, field)
+}
+
+// queryValue converts the values of a query parameter to the type of its schema
+func (o *Operation) queryValue(values []string, schema any) (any, bool) {
+	types := o.queryTypes(schema)
+	if slices.Contains(types, "array") {
+		items := o.items(schema)
+		list := make([]any, 0, len(values))
+		for _, v := range values {
+			for _, part := range strings.Split(v, ",") {
+				item, ok := o.queryValue([]string{part}, items)
+				if !ok {
+					return nil, false
+				}
+				list = append(list, item)
+			}
+		}
+		return list, true
+	}
+
+	value := ""
+	if len(values) > 0 {
+		value = values[0]
+	}
+	switch {
+	case slices.Contains(types, "integer"):
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, false
+		}
+		return json.Number(value), true
+	case slices.Contains(types, "number"):
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, false
+		}
+		return json.Number(value), true
+	case slices.Contains(types, "boolean"):
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, false
+		}
+		return b, true
+	}
+	return value, true
+}
+
+// queryTypes returns the types of a parameter schema, following its reference
+func (o *Operation) queryTypes(schema any) []string {
+	sch, _ := o.resolveSchema(schema).(map[string]any)
+	types := schemaTypes(sch["type"])
+	if len(types) == 0 {
+		return []string{"string"}
+	}
+	return types
+}
+
+// items returns the schema of the items of an array parameter
+func (o *Operation) items(schema any) any {
+	sch, _ := o.resolveSchema(schema).(map[string]any)
+	return sch["items"]
+}
+
+// resolveSchema follows the $ref of a schema to its definition
+func (o *Operation) resolveSchema(schema any) any {
+	for range maxDepth {
+		sch, ok := schema.(map[string]any)
+		if !ok {
+			return schema
+		}
+		ref, ok := sch["$ref"].(string)
+		if !ok {
+			return sch
+		}
+		target, err := pointer(o.schemas.root, ref)
+		if err != nil {
+			return nil
+		}
+		schema = target
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package agentrpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"snapdeploy-core/internal/domain/agent"
+	"snapdeploy-core/internal/infrastructure/builder"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AgentAuthenticator looks up the agent tokens users create for their self-hosted build agents
+type AgentAuthenticator interface {
+	AuthenticateAgent(ctx context.Context, secret string) (tokenID, userID string, err error)
+}
+
+// SetAgentAuthenticator sets the lookup of users' agent tokens. Without one, only agents presenting the
+// platform's AGENT_TOKEN are let in.
+func (s *Server) SetAgentAuthenticator(tokens AgentAuthenticator) {
+	s.tokens = tokens
+}
+
+// agentKey is the context key of the agent making a call
+type agentKey struct{}
+
+// callingAgent returns the agent making a call, as authenticated by the interceptors
+func callingAgent(ctx context.Context) builder.Agent {
+	caller, _ := ctx.Value(agentKey{}).(builder.Agent)
+	return caller
+}
+
+// authenticate checks the call carries the platform's token or a user's agent token as
+// "authorization: Bearer <token>" metadata, and returns the context of the agent presenting it
+func (s *Server) authenticate(ctx context.Context, platformToken string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+	}
+
+	presented, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid agent token")
+	}
+
+	if platformToken != "" && subtle.ConstantTimeCompare([]byte(presented), []byte(platformToken)) == 1 {
+		return context.WithValue(ctx, agentKey{}, builder.Agent{Credential: builder.PlatformCredential, Platform: true}), nil
+	}
+
+	if s.tokens == nil || !strings.HasPrefix(presented, agent.TokenPrefix) {
+		return nil, status.Error(codes.Unauthenticated, "invalid agent token")
+	}
+	tokenID, userID, err := s.tokens.AuthenticateAgent(ctx, presented)
+	if errors.Is(err, agent.ErrTokenNotFound) {
+		return nil, status.Error(codes.Unauthenticated, "invalid agent token")
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to authenticate build agent", "error", err)
+		return nil, status.Error(codes.Internal, "failed to check the agent token")
+	}
+	return context.WithValue(ctx, agentKey{}, builder.Agent{Credential: tokenID, UserID: userID}), nil
+}
+
+func (s *Server) unaryAuth(platformToken string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := s.authenticate(ctx, platformToken)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (s *Server) streamAuth(platformToken string) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := s.authenticate(stream.Context(), platformToken)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &agentStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// agentStream is a stream whose context carries the agent making the call
+type agentStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *agentStream) Context() context.Context {
+	return s.ctx
+}
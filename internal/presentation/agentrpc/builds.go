@@ -0,0 +1,120 @@
+package agentrpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"snapdeploy-core/internal/infrastructure/builder"
+	"snapdeploy-core/internal/presentation/agentrpc/agentpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ClaimWait is how long ClaimBuild waits for a build to be queued before answering with none
+const ClaimWait = 25 * time.Second
+
+// BuildDispatcher hands the builds queued for self-hosted agents out and takes back their outcome.
+// Agents are only handed the builds their credential may run, and only report on the builds they claimed.
+type BuildDispatcher interface {
+	Claim(ctx context.Context, agent builder.Agent) (*builder.AgentBuild, error)
+	Complete(ctx context.Context, agent builder.Agent, buildID string, succeeded bool, reason string) error
+	AuthorizeReport(agent builder.Agent, deploymentID string) error
+}
+
+// SetBuildDispatcher sets the dispatcher of builds to self-hosted agents, when BUILD_BACKEND=agent
+func (s *Server) SetBuildDispatcher(dispatcher BuildDispatcher) {
+	s.builds = dispatcher
+}
+
+// ClaimBuild hands the oldest queued build the calling agent may run to it, waiting up to ClaimWait for one
+func (s *Server) ClaimBuild(ctx context.Context, req *agentpb.ClaimBuildRequest) (*agentpb.ClaimBuildResponse, error) {
+	if s.builds == nil {
+		return nil, status.Error(codes.FailedPrecondition, "builds are not dispatched to agents, set BUILD_BACKEND=agent")
+	}
+	if req.GetAgentName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_name is required")
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, ClaimWait)
+	defer cancel()
+
+	caller := callingAgent(ctx)
+	caller.Name = req.GetAgentName()
+	build, err := s.builds.Claim(waitCtx, caller)
+	if errors.Is(err, builder.ErrNoQueuedBuild) {
+		return &agentpb.ClaimBuildResponse{}, nil
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to claim build", "agent", req.GetAgentName(), "error", err)
+		return nil, status.Error(codes.Internal, "failed to claim a build")
+	}
+
+	slog.InfoContext(ctx, "Build claimed by agent", "build_id", build.ID, "agent", req.GetAgentName())
+	return &agentpb.ClaimBuildResponse{Build: toBuild(build)}, nil
+}
+
+// CompleteBuild records the outcome of a build the calling agent ran
+func (s *Server) CompleteBuild(ctx context.Context, req *agentpb.CompleteBuildRequest) (*agentpb.CompleteBuildResponse, error) {
+	if s.builds == nil {
+		return nil, status.Error(codes.FailedPrecondition, "builds are not dispatched to agents, set BUILD_BACKEND=agent")
+	}
+	if req.GetBuildId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "build_id is required")
+	}
+
+	var succeeded bool
+	switch builder.BuildStatus(req.GetStatus()) {
+	case builder.BuildSucceeded:
+		succeeded = true
+	case builder.BuildFailed:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "status must be %s or %s, got %q", builder.BuildSucceeded, builder.BuildFailed, req.GetStatus())
+	}
+
+	if err := s.builds.Complete(ctx, callingAgent(ctx), req.GetBuildId(), succeeded, req.GetError()); err != nil {
+		return nil, buildStatus(ctx, err)
+	}
+	return &agentpb.CompleteBuildResponse{}, nil
+}
+
+// buildStatus maps a dispatcher error to the gRPC status agents receive
+func buildStatus(ctx context.Context, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, builder.ErrBuildNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, builder.ErrBuildClaimedByOtherAgent):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, builder.ErrBuildNotClaimed), errors.Is(err, builder.ErrBuildFinished):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		slog.ErrorContext(ctx, "Failed to check the build of an agent", "error", err)
+		return status.Error(codes.Internal, "failed to record the outcome")
+	}
+}
+
+func toBuild(build *builder.AgentBuild) *agentpb.Build {
+	req := build.Request
+	pb := &agentpb.Build{
+		BuildId:       build.ID,
+		DeploymentId:  req.Deployment.ID().String(),
+		RepositoryUrl: req.RepositoryURL,
+		Branch:        req.Branch,
+		CommitHash:    req.CommitHash,
+		ImageTag:      req.ImageTag,
+		Dockerfile:    req.Dockerfile,
+		BuildArgs:     req.BuildArgs,
+	}
+	if creds := build.CloneCredentials; creds != nil {
+		pb.CloneCredentials = &agentpb.CloneCredentials{
+			Url:      creds.URL,
+			Username: creds.Username,
+			Token:    creds.Token,
+		}
+	}
+	return pb
+}
@@ -0,0 +1,170 @@
+// Package agentrpc serves the gRPC API external build agents use to report the status and push the logs
+// of the deployments they build, in place of the per-line JSON endpoints. Self-hosted agents also claim
+// their builds through it when BUILD_BACKEND=agent.
+package agentrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/presentation/agentrpc/agentpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// MaxLinesPerBatch bounds the log lines of one PushLogs message, each batch being saved at once
+const MaxLinesPerBatch = 1000
+
+// DeploymentReporter records what build agents report about deployments
+type DeploymentReporter interface {
+	ReportAgentStatus(ctx context.Context, deploymentID, status, message string) (*dto.DeploymentResponse, error)
+	AppendAgentLogs(ctx context.Context, deploymentID string, lines []string) ([]string, error)
+}
+
+// LogBroadcaster relays log lines to the clients following a deployment's logs
+type LogBroadcaster interface {
+	BroadcastLog(deploymentID string, logLine string)
+}
+
+// Server implements the build agent services
+type Server struct {
+	agentpb.UnimplementedBuildStatusServiceServer
+	agentpb.UnimplementedLogIngestionServiceServer
+	agentpb.UnimplementedBuildJobServiceServer
+
+	deployments DeploymentReporter
+	broadcaster LogBroadcaster
+	builds      BuildDispatcher
+	tokens      AgentAuthenticator
+}
+
+// NewServer creates a new build agent server
+func NewServer(deployments DeploymentReporter, broadcaster LogBroadcaster) *Server {
+	return &Server{
+		deployments: deployments,
+		broadcaster: broadcaster,
+	}
+}
+
+// NewGRPCServer creates a gRPC server serving the build agent services to agents presenting the platform's
+// token, or an agent token of a user when the server has an AgentAuthenticator. An empty platform token lets
+// only users' agents in. Nil creds serve plaintext, for when TLS is terminated in front of the server.
+func NewGRPCServer(srv *Server, platformToken string, creds credentials.TransportCredentials) *grpc.Server {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(srv.unaryAuth(platformToken)),
+		grpc.ChainStreamInterceptor(srv.streamAuth(platformToken)),
+	}
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	server := grpc.NewServer(opts...)
+	agentpb.RegisterBuildStatusServiceServer(server, srv)
+	agentpb.RegisterLogIngestionServiceServer(server, srv)
+	agentpb.RegisterBuildJobServiceServer(server, srv)
+	return server
+}
+
+// ReportStatus moves a deployment to the status an agent reports
+func (s *Server) ReportStatus(ctx context.Context, req *agentpb.ReportStatusRequest) (*agentpb.ReportStatusResponse, error) {
+	if _, err := deployment.ParseDeploymentID(req.GetDeploymentId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.authorizeReport(ctx, req.GetDeploymentId()); err != nil {
+		return nil, err
+	}
+	if _, err := deployment.NewDeploymentStatus(req.GetStatus()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	dep, err := s.deployments.ReportAgentStatus(ctx, req.GetDeploymentId(), req.GetStatus(), req.GetMessage())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	if req.GetMessage() != "" {
+		// Status messages are short, so they are only masked by shape
+		var redactor deployment.LogRedactor
+		s.broadcaster.BroadcastLog(dep.ID, redactor.Redact(req.GetMessage()))
+	}
+
+	return &agentpb.ReportStatusResponse{
+		DeploymentId: dep.ID,
+		Status:       dep.Status,
+	}, nil
+}
+
+// PushLogs appends each batch of a stream to its deployment's logs as it arrives
+func (s *Server) PushLogs(stream agentpb.LogIngestionService_PushLogsServer) error {
+	ctx := stream.Context()
+
+	var accepted int64
+	authorized := make(map[string]bool) // Deployments the stream may push the logs of
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&agentpb.PushLogsResponse{LinesAccepted: accepted})
+		}
+		if err != nil {
+			return err
+		}
+
+		lines := req.GetLines()
+		if len(lines) == 0 {
+			continue
+		}
+		if len(lines) > MaxLinesPerBatch {
+			return status.Errorf(codes.InvalidArgument, "batches carry at most %d lines, got %d", MaxLinesPerBatch, len(lines))
+		}
+		if _, err := deployment.ParseDeploymentID(req.GetDeploymentId()); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if !authorized[req.GetDeploymentId()] {
+			if err := s.authorizeReport(ctx, req.GetDeploymentId()); err != nil {
+				return err
+			}
+			authorized[req.GetDeploymentId()] = true
+		}
+
+		recorded, err := s.deployments.AppendAgentLogs(ctx, req.GetDeploymentId(), lines)
+		if err != nil {
+			return toStatus(ctx, err)
+		}
+		for _, line := range recorded {
+			s.broadcaster.BroadcastLog(req.GetDeploymentId(), line)
+		}
+		accepted += int64(len(lines))
+	}
+}
+
+// authorizeReport checks the calling agent may report on a deployment: agents of users only on the builds they
+// claimed, platform agents on any deployment not claimed by another agent
+func (s *Server) authorizeReport(ctx context.Context, deploymentID string) error {
+	caller := callingAgent(ctx)
+	if s.builds == nil {
+		if !caller.Platform {
+			return status.Error(codes.PermissionDenied, "agent tokens can only report on the builds their agent claimed")
+		}
+		return nil
+	}
+	return buildStatus(ctx, s.builds.AuthorizeReport(caller, deploymentID))
+}
+
+// toStatus maps a service error to the gRPC status agents receive
+func toStatus(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, deployment.ErrDeploymentNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, deployment.ErrInvalidStatusTransition), errors.Is(err, deployment.ErrDeploymentArchived):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		slog.ErrorContext(ctx, "Build agent call failed", "error", err)
+		return status.Error(codes.Internal, "failed to record the report")
+	}
+}
@@ -0,0 +1,148 @@
+// Package apierror turns the errors of the application into the error responses of the API. Every error is
+// reported with a stable, machine-readable code and an HTTP status, mapped in one place, so clients can act on
+// the code rather than parse messages, and errors the API doesn't expect never leak their internals.
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Response is the body of every error response
+type Response struct {
+	Error   string `json:"error"`             // Stable code, such as not_found or quota_exceeded
+	Message string `json:"message"`           // Explanation for people, may change between releases
+	Details string `json:"details,omitempty"` // What exactly was wrong with the request, for some codes
+}
+
+// Error is an error the presentation layer reports itself, such as a request body that isn't valid JSON,
+// with the status, code and message it is responded with
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Err     error // Cause, shown as the details of invalid requests
+}
+
+// New returns an Error without a cause
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// Invalid returns an Error for a request the API can't handle as sent, with the cause as its details
+func Invalid(message string, err error) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: "invalid_request", Message: message, Err: err}
+}
+
+// Errors of the requests the middleware turns away before they reach a handler, mapped in codes.go with the
+// errors of the application
+var (
+	ErrMissingAuthorization = errors.New("authorization header is required")
+	ErrNotBearer            = errors.New("authorization header must start with 'Bearer '")
+	ErrInvalidToken         = errors.New("invalid token")
+	ErrInvalidStreamToken   = errors.New("invalid or expired stream token")
+	ErrNoUser               = errors.New("user not found in context")
+	ErrUserType             = errors.New("invalid user type in context")
+	ErrUserForbidden        = errors.New("access to another user denied")
+	ErrOperatorOnly         = errors.New("only platform operators can perform this action")
+	ErrRateLimited          = errors.New("too many requests")
+)
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// retryAfterError carries how long a client should wait before retrying a request that was turned away
+type retryAfterError struct {
+	error
+	seconds int
+}
+
+func (e *retryAfterError) Unwrap() error {
+	return e.error
+}
+
+// RetryAfter wraps err with the seconds sent as the Retry-After header if err is responded with 429
+func RetryAfter(err error, seconds int) error {
+	return &retryAfterError{error: err, seconds: seconds}
+}
+
+// Abort responds to a request with the error response for err and stops the handlers after the current one.
+// The error is also recorded on the context, for middleware.Errors to log.
+func Abort(c *gin.Context, err error) {
+	_ = c.Error(err)
+	write(c, err)
+	c.Abort()
+}
+
+// write writes the error response for err
+func write(c *gin.Context, err error) {
+	status, response := From(err)
+
+	var retry *retryAfterError
+	if status == http.StatusTooManyRequests && errors.As(err, &retry) {
+		c.Header("Retry-After", strconv.Itoa(retry.seconds))
+	}
+	c.JSON(status, response)
+}
+
+// Respond writes the error response for err unless a response was already written
+func Respond(c *gin.Context, err error) {
+	if c.Writer.Written() {
+		return
+	}
+	write(c, err)
+}
+
+// From returns the status and body of the error response for err. Errors that aren't mapped are internal:
+// they are 500 with a generic message, their own is left for the logs.
+func From(err error) (int, Response) {
+	// Bodies over the route's limit fail wherever they are read, so this is checked before how the read was reported
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge, Response{
+			Error:   "request_too_large",
+			Message: fmt.Sprintf("Request body must be at most %d bytes", tooLarge.Limit),
+		}
+	}
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		response := Response{Error: apiErr.Code, Message: apiErr.Message}
+		if apiErr.Err != nil && apiErr.Status < http.StatusInternalServerError {
+			response.Details = apiErr.Err.Error()
+		}
+		return apiErr.Status, response
+	}
+
+	if status, response, ok := lookup(err); ok {
+		return status, response
+	}
+
+	return http.StatusInternalServerError, Response{
+		Error:   "internal_error",
+		Message: "Internal server error",
+	}
+}
+
+// sentence capitalizes the first letter of an error message, to show it as a sentence
+func sentence(message string) string {
+	r, size := utf8.DecodeRuneInString(message)
+	if r == utf8.RuneError {
+		return message
+	}
+	return string(unicode.ToUpper(r)) + message[size:]
+}
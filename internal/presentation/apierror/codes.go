@@ -0,0 +1,210 @@
+package apierror
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/agent"
+	"snapdeploy-core/internal/domain/billing"
+	"snapdeploy-core/internal/domain/command"
+	"snapdeploy-core/internal/domain/cronrun"
+	"snapdeploy-core/internal/domain/dbbranch"
+	"snapdeploy-core/internal/domain/deployment"
+	"snapdeploy-core/internal/domain/idempotency"
+	"snapdeploy-core/internal/domain/incident"
+	"snapdeploy-core/internal/domain/job"
+	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/quota"
+	"snapdeploy-core/internal/domain/repo"
+	"snapdeploy-core/internal/domain/shell"
+	"snapdeploy-core/internal/domain/template"
+	"snapdeploy-core/internal/domain/user"
+	"snapdeploy-core/internal/domain/validation"
+)
+
+// mapping is the error response of an error
+type mapping struct {
+	status  int
+	code    string
+	message string
+	details bool // Whether what the error adds to its message, such as the invalid value, is sent as the details
+}
+
+// sentinels maps the sentinel errors of the application, matched with errors.Is in order
+var sentinels = []struct {
+	err error
+	mapping
+}{
+	// Resources that don't exist
+	{project.ErrProjectNotFound, mapping{http.StatusNotFound, "not_found", "Project not found", false}},
+	{project.ErrEnvironmentNotFound, mapping{http.StatusNotFound, "not_found", "Environment not found", false}},
+	{project.ErrEnvVarNotFound, mapping{http.StatusNotFound, "not_found", "Environment variable not found", false}},
+	{deployment.ErrDeploymentNotFound, mapping{http.StatusNotFound, "not_found", "Deployment not found", false}},
+	{deployment.ErrProjectNotFound, mapping{http.StatusNotFound, "not_found", "Project not found", false}},
+	{deployment.ErrScanNotFound, mapping{http.StatusNotFound, "not_found", "The deployment's image wasn't scanned", true}},
+	{template.ErrTemplateNotFound, mapping{http.StatusNotFound, "not_found", "Template not found", false}},
+	{job.ErrJobNotFound, mapping{http.StatusNotFound, "not_found", "Job not found", false}},
+	{incident.ErrIncidentNotFound, mapping{http.StatusNotFound, "not_found", "Incident not found", false}},
+	{command.ErrRunNotFound, mapping{http.StatusNotFound, "not_found", "Command run not found", false}},
+	{cronrun.ErrRunNotFound, mapping{http.StatusNotFound, "not_found", "Run not found", false}},
+	{dbbranch.ErrSnapshotNotFound, mapping{http.StatusNotFound, "not_found", "Snapshot not found", false}},
+	{dbbranch.ErrBranchNotFound, mapping{http.StatusNotFound, "not_found", "Branch not found", false}},
+	{shell.ErrSessionNotFound, mapping{http.StatusNotFound, "not_found", "Shell session not found", false}},
+	{agent.ErrTokenNotFound, mapping{http.StatusNotFound, "not_found", "Agent token not found", false}},
+	{service.ErrInvalidBadgeToken, mapping{http.StatusNotFound, "not_found", "Project not found", false}}, // Doesn't tell private projects apart
+
+	// Authentication
+	{ErrMissingAuthorization, mapping{http.StatusUnauthorized, "unauthorized", "Authorization header is required", false}},
+	{ErrNotBearer, mapping{http.StatusUnauthorized, "unauthorized", "Authorization header must start with 'Bearer '", false}},
+	{ErrInvalidToken, mapping{http.StatusUnauthorized, "unauthorized", "Invalid token", true}},
+	{ErrInvalidStreamToken, mapping{http.StatusUnauthorized, "unauthorized", "Invalid or expired stream token", false}},
+	{ErrNoUser, mapping{http.StatusUnauthorized, "unauthorized", "User not found in context", false}},
+	{ErrUserType, mapping{http.StatusInternalServerError, "internal_error", "Invalid user type in context", false}},
+
+	// Access
+	{ErrUserForbidden, mapping{http.StatusForbidden, "forbidden", "You don't have permission to access this user", false}},
+	{ErrOperatorOnly, mapping{http.StatusForbidden, "forbidden", "Only platform operators can perform this action", false}},
+	{project.ErrUnauthorized, mapping{http.StatusForbidden, "forbidden", "You don't have permission to access this project", false}},
+	{deployment.ErrUnauthorized, mapping{http.StatusForbidden, "forbidden", "You don't have permission to access this deployment", false}},
+	{deployment.ErrSelfApproval, mapping{http.StatusForbidden, "self_approval", "Deployments can't be approved by the user who started them", false}},
+	{deployment.ErrNotApprover, mapping{http.StatusForbidden, "not_approver", "Only the approvers of the deployment's environment and operators can decide on it", false}},
+	{shell.ErrInvalidToken, mapping{http.StatusUnauthorized, "invalid_token", "Shell session token is invalid or expired", false}},
+
+	// Requests the state of a resource doesn't allow
+	{project.ErrProjectDeleting, mapping{http.StatusConflict, "project_deleting", "Project is being deleted", false}},
+	{project.ErrProjectAlreadyExists, mapping{http.StatusConflict, "project_exists", "A project with this repository URL already exists", false}},
+	{project.ErrCustomDomainTaken, mapping{http.StatusConflict, "domain_taken", "The custom domain is already used by another project", true}},
+	{project.ErrDatabaseNotRequired, mapping{http.StatusConflict, "database_not_required", "Project does not require a database", false}},
+	{deployment.ErrDeploymentArchived, mapping{http.StatusConflict, "deployment_archived", "Archived deployments can't be modified", false}},
+	{deployment.ErrDeploymentPinned, mapping{http.StatusConflict, "deployment_pinned", "Pinned deployments can't be deleted. Unpin the deployment first.", false}},
+	{deployment.ErrDeploymentInProgress, mapping{http.StatusConflict, "deployment_in_progress", "Wait for the current deployment to finish and try again", false}},
+	{deployment.ErrNothingToRestart, mapping{http.StatusConflict, "nothing_to_restart", "Project has no successful deployment to restart", false}},
+	{deployment.ErrNothingToDeploy, mapping{http.StatusConflict, "nothing_to_deploy", "Project has no successful deployment to deploy", false}},
+	{deployment.ErrNotWaitingApproval, mapping{http.StatusConflict, "not_waiting_approval", "Deployment is not waiting for approval", true}},
+	{deployment.ErrNotRetryable, mapping{http.StatusConflict, "not_retryable", "Deployment cannot be retried from this step", true}},
+	{deployment.ErrTargetUnavailable, mapping{http.StatusConflict, "target_unavailable", "The deployment target is not available on this platform", true}},
+	{idempotency.ErrRequestInProgress, mapping{http.StatusConflict, "idempotency_key_in_progress", "A request with this idempotency key is still in progress", false}},
+	{idempotency.ErrKeyReused, mapping{http.StatusUnprocessableEntity, "idempotency_key_reused", "Idempotency key was already used for a different request", false}},
+	{incident.ErrIncidentResolved, mapping{http.StatusConflict, "incident_resolved", "Incident is already resolved", false}},
+	{dbbranch.ErrBranchAlreadyExists, mapping{http.StatusConflict, "branch_exists", "Project already has a branch with this name", false}},
+	{dbbranch.ErrSnapshotLimitReached, mapping{http.StatusConflict, "limit_reached", "Project has reached its snapshot limit", false}},
+	{dbbranch.ErrBranchLimitReached, mapping{http.StatusConflict, "limit_reached", "Project has reached its branch limit", false}},
+	{command.ErrUnsupportedProject, mapping{http.StatusConflict, "unsupported_project", "Commands can only run in projects deployed as ECS containers, not STATIC or LAMBDA projects", false}},
+	{shell.ErrUnsupportedProject, mapping{http.StatusConflict, "unsupported_project", "Shells can only be opened in projects running as ECS services, not STATIC, LAMBDA or CRON projects", false}},
+	{shell.ErrNoRunningTask, mapping{http.StatusConflict, "no_running_task", "The environment has no running task to open a shell in", false}},
+	{shell.ErrExecDisabled, mapping{http.StatusConflict, "exec_disabled", "Shells aren't enabled on the running task, redeploy the environment to enable them", false}},
+	{service.ErrNothingDeployed, mapping{http.StatusConflict, "nothing_deployed", "Deploy the environment before running commands in it", false}},
+	{service.ErrNothingToSnapshot, mapping{http.StatusConflict, "nothing_to_snapshot", "Deploy the project to create its database before taking a snapshot", false}},
+	{service.ErrRotationInProgress, mapping{http.StatusConflict, "rotation_in_progress", "Values are already being re-encrypted", false}},
+
+	// Invalid requests with a code of their own, the others are validation errors
+	{idempotency.ErrInvalidKey, mapping{http.StatusBadRequest, "invalid_idempotency_key", "Invalid idempotency key", true}},
+	{command.ErrInvalidCommand, mapping{http.StatusBadRequest, "invalid_command", "Command must be a single line of at most 1024 characters", false}},
+	{deployment.ErrInvalidStatusTransition, mapping{http.StatusBadRequest, "invalid_status_transition", "Invalid status transition", true}},
+	{deployment.ErrInvalidStep, mapping{http.StatusBadRequest, "invalid_step", "Unknown deployment step", true}},
+	{deployment.ErrInvalidAnnotations, mapping{http.StatusBadRequest, "invalid_annotations", "Invalid deployment annotations", true}},
+	{template.ErrMissingVariable, mapping{http.StatusBadRequest, "missing_env_var", "The template requires a value for an environment variable", true}},
+	{quota.ErrUnknownPlan, mapping{http.StatusBadRequest, "invalid_limits", "Invalid plan or limits", true}},
+	{quota.ErrInvalidLimit, mapping{http.StatusBadRequest, "invalid_limits", "Invalid plan or limits", true}},
+	{service.ErrInvalidCursor, mapping{http.StatusBadRequest, "invalid_request", "Invalid cursor", false}},
+	{service.ErrInvalidListFilter, mapping{http.StatusBadRequest, "invalid_request", "Invalid filter", true}},
+	{service.ErrInvalidComparison, mapping{http.StatusBadRequest, "invalid_request", "Invalid deployment ID", true}},
+	{service.ErrInvalidMetricsPeriod, mapping{http.StatusBadRequest, "invalid_request", "Invalid metrics period", true}},
+
+	// Limits of the platform and the user's plan
+	{ErrRateLimited, mapping{http.StatusTooManyRequests, "rate_limited", "Too many requests, please retry later", false}},
+	{deployment.ErrTooManyDeployments, mapping{http.StatusTooManyRequests, "too_many_deployments", "Too many deployments in progress. Wait for one to finish and try again.", false}},
+	{deployment.ErrBuildQueueFull, mapping{http.StatusTooManyRequests, "build_queue_full", "The build queue is full. Try again shortly.", false}},
+	{job.ErrQueueFull, mapping{http.StatusTooManyRequests, "too_many_jobs", "Too many operations in progress. Wait for one to finish and try again.", false}},
+	{billing.ErrAccountSuspended, mapping{http.StatusPaymentRequired, "payment_required", "A payment failed, deployments are suspended until it is made. Update your payment method in the billing portal.", false}},
+
+	// Features this server isn't configured for, or services it depends on
+	{billing.ErrBillingDisabled, mapping{http.StatusServiceUnavailable, "billing_unavailable", "Billing is not configured on this server", false}},
+	{service.ErrCommandsUnavailable, mapping{http.StatusServiceUnavailable, "commands_unavailable", "One-off commands are not available on this server", false}},
+	{service.ErrCronRunsUnavailable, mapping{http.StatusServiceUnavailable, "cron_runs_unavailable", "Cron job runs are not available on this server", false}},
+	{service.ErrDatabaseUnavailable, mapping{http.StatusServiceUnavailable, "database_unavailable", "Databases are not available on this server", false}},
+	{service.ErrGitHubAppUnavailable, mapping{http.StatusServiceUnavailable, "github_app_unavailable", "GitHub App is not configured on this server", false}},
+	{service.ErrMetricsUnavailable, mapping{http.StatusServiceUnavailable, "metrics_unavailable", "Metrics are not available on this server", false}},
+	{service.ErrShellsUnavailable, mapping{http.StatusServiceUnavailable, "shells_unavailable", "Interactive shells are not available on this server", false}},
+	{service.ErrRepositoryCopyUnavailable, mapping{http.StatusServiceUnavailable, "repository_copy_unavailable", "Template repositories can't be copied, create the project without a repository name", false}},
+	{service.ErrRepositoryCopyFailed, mapping{http.StatusBadGateway, "repository_copy_failed", "Failed to copy the template repository into your GitHub account", true}},
+}
+
+// domainCodes maps the codes of user.DomainError and repo.DomainError
+var domainCodes = map[string]mapping{
+	"USER_NOT_FOUND":             {http.StatusNotFound, "user_not_found", "User not found", false},
+	"USER_ALREADY_EXISTS":        {http.StatusConflict, "user_exists", "A user with this email already exists", false},
+	"INVALID_USER_DATA":          {http.StatusBadRequest, "invalid_request", "Invalid user data", true},
+	"REPOSITORY_NOT_FOUND":       {http.StatusNotFound, "not_found", "Repository not found", false},
+	"UNAUTHORIZED_ACCESS":        {http.StatusForbidden, "forbidden", "You don't have access to this repository", false},
+	"INVALID_REPOSITORY_DATA":    {http.StatusBadRequest, "invalid_request", "Invalid repository data", true},
+	"PROVIDER_NOT_SUPPORTED":     {http.StatusBadRequest, "invalid_request", "Git provider is not supported", true},
+	"PROVIDER_RATE_LIMITED":      {http.StatusTooManyRequests, "rate_limited", "Git provider rate limit exhausted", true},
+	"INSTALLATION_NOT_FOUND":     {http.StatusNotFound, "not_found", "GitHub App installation not found", false},
+	"INSTALLATION_NOT_CLAIMABLE": {http.StatusForbidden, "forbidden", "This installation was not made by your GitHub account", false},
+	"INSTALLATION_CLAIMED":       {http.StatusConflict, "installation_claimed", "This installation is linked to another SnapDeploy account", false},
+}
+
+// lookup returns the error response of an error of the application, if it is mapped
+func lookup(err error) (int, Response, bool) {
+	for _, s := range sentinels {
+		if errors.Is(err, s.err) {
+			return s.status, s.response(err, s.err.Error()), true
+		}
+	}
+
+	var exceeded *quota.ExceededError
+	if errors.As(err, &exceeded) {
+		status := http.StatusPaymentRequired
+		if exceeded.Temporary() {
+			status = http.StatusTooManyRequests
+		}
+		return status, Response{
+			Error:   "quota_exceeded",
+			Message: "Your plan's " + exceeded.Error(),
+			Details: exceeded.Resource.String(),
+		}, true
+	}
+
+	if m, message, ok := domainError(err); ok {
+		return m.status, m.response(err, message), true
+	}
+
+	var invalid *validation.Error
+	if errors.As(err, &invalid) {
+		return http.StatusBadRequest, Response{Error: "invalid_request", Message: sentence(invalid.Error())}, true
+	}
+
+	return 0, Response{}, false
+}
+
+// domainError returns the mapping and the message of a user.DomainError or repo.DomainError
+func domainError(err error) (mapping, string, bool) {
+	var userErr *user.DomainError
+	if errors.As(err, &userErr) {
+		m, ok := domainCodes[userErr.Code]
+		return m, userErr.Message, ok
+	}
+	var repoErr *repo.DomainError
+	if errors.As(err, &repoErr) {
+		m, ok := domainCodes[repoErr.Code]
+		return m, repoErr.Message, ok
+	}
+	return mapping{}, "", false
+}
+
+// response returns the error response of err. Its details, if the mapping has them, start at the message of the
+// error that was mapped, leaving out what the layers it went through prefixed it with.
+func (m mapping) response(err error, from string) Response {
+	response := Response{Error: m.code, Message: m.message}
+	if m.details {
+		details := err.Error()
+		if i := strings.Index(details, from); i >= 0 {
+			details = details[i:]
+		}
+		response.Details = details
+	}
+	return response
+}
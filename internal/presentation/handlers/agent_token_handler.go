@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/middleware"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AgentTokenHandler handles the HTTP requests managing the tokens of users' self-hosted build agents
+type AgentTokenHandler struct {
+	agentTokenService *service.AgentTokenService
+	userService       *service.UserService
+}
+
+// NewAgentTokenHandler creates a new agent token handler
+func NewAgentTokenHandler(agentTokenService *service.AgentTokenService, userService *service.UserService) *AgentTokenHandler {
+	return &AgentTokenHandler{
+		agentTokenService: agentTokenService,
+		userService:       userService,
+	}
+}
+
+// CreateAgentToken handles POST /agent-tokens
+func (h *AgentTokenHandler) CreateAgentToken(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateAgentTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, invalidBody(err))
+		return
+	}
+
+	response, err := h.agentTokenService.CreateToken(c.Request.Context(), userID, &req)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListAgentTokens handles GET /agent-tokens
+func (h *AgentTokenHandler) ListAgentTokens(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.agentTokenService.ListTokens(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeAgentToken handles DELETE /agent-tokens/:id
+func (h *AgentTokenHandler) RevokeAgentToken(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.agentTokenService.RevokeToken(c.Request.Context(), userID, c.Param("id")); err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// userID returns the ID of the authenticated user, aborting the request if there is none
+func (h *AgentTokenHandler) userID(c *gin.Context) (string, bool) {
+	clerkUserData, exists := c.Get("user")
+	if !exists {
+		apierror.Abort(c, errNoUser)
+		return "", false
+	}
+
+	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
+	if !ok {
+		apierror.Abort(c, errUserType)
+		return "", false
+	}
+
+	dbUser, err := h.userService.GetOrCreateUserByClerkID(c.Request.Context(), clerkUser.ID)
+	if err != nil {
+		apierror.Abort(c, err)
+		return "", false
+	}
+	return dbUser.ID, true
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBadgeBranchLength bounds the branch parameter of badges, git refuses longer ref names anyway
+const maxBadgeBranchLength = 255
+
+// badgeCacheControl lets browsers and image proxies such as GitHub's camo cache badges for a minute
+const badgeCacheControl = "public, max-age=60"
+
+// BadgeHandler handles HTTP requests for the deployment status badges of projects
+type BadgeHandler struct {
+	badgeService *service.BadgeService
+}
+
+// NewBadgeHandler creates a new badge handler
+func NewBadgeHandler(badgeService *service.BadgeService) *BadgeHandler {
+	return &BadgeHandler{
+		badgeService: badgeService,
+	}
+}
+
+// GetStatusBadge handles GET /badges/projects/:id/status.svg
+func (h *BadgeHandler) GetStatusBadge(c *gin.Context) {
+	branch := c.Query("branch")
+	if len(branch) > maxBadgeBranchLength {
+		renderBadge(c, http.StatusBadRequest, &dto.BadgeStatus{Label: "deploy", Message: "invalid branch", Color: "#9f9f9f"})
+		return
+	}
+
+	status, err := h.badgeService.GetBadgeStatus(c.Request.Context(), c.Param("id"), c.Query("token"), branch)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBadgeToken) {
+			renderBadge(c, http.StatusNotFound, &dto.BadgeStatus{Label: "deploy", Message: "not found", Color: "#9f9f9f"})
+			return
+		}
+		_ = c.Error(err) // Logged by middleware.Errors, the badge is an image either way
+		renderBadge(c, http.StatusInternalServerError, &dto.BadgeStatus{Label: "deploy", Message: "unavailable", Color: "#9f9f9f"})
+		return
+	}
+
+	renderBadge(c, http.StatusOK, status)
+}
+
+// GetProjectBadge handles GET /projects/:id/badge
+func (h *BadgeHandler) GetProjectBadge(c *gin.Context) {
+	response, err := h.badgeService.GetProjectBadge(c.Request.Context(), c.Param("id"), requestBaseURL(c))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// requestBaseURL returns the scheme and host the client reached the API at, behind the load balancer too
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// renderBadge responds with the SVG shield of a badge status. Badges are small enough to be rendered
+// on every request, their ETag spares clients downloading an unchanged one.
+func renderBadge(c *gin.Context, code int, status *dto.BadgeStatus) {
+	svg := badgeSVG(status)
+	sum := sha256.Sum256([]byte(svg))
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	c.Header("Cache-Control", badgeCacheControl)
+	c.Header("ETag", etag)
+	if code == http.StatusOK && etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(code, "image/svg+xml; charset=utf-8", []byte(svg))
+}
+
+// badgeSVG draws a badge in the flat shields.io style, the label on grey and the message on its color
+func badgeSVG(status *dto.BadgeStatus) string {
+	labelWidth := badgeTextWidth(status.Label)
+	messageWidth := badgeTextWidth(status.Message)
+	width := labelWidth + messageWidth
+	label := html.EscapeString(status.Label)
+	message := html.EscapeString(status.Message)
+	color := html.EscapeString(status.Color)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">`+
+		`<title>%[4]s: %[5]s</title>`+
+		`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`+
+		`<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>`+
+		`<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%[7]d" y="14">%[4]s</text>`+
+		`<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[5]s</text><text x="%[8]d" y="14">%[5]s</text>`+
+		`</g></svg>`,
+		width, labelWidth, messageWidth, label, message, color, labelWidth/2, labelWidth+messageWidth/2)
+}
+
+// badgeTextWidth approximates the width of a badge's text in Verdana 11px, with its padding
+func badgeTextWidth(text string) int {
+	return len([]rune(text))*7 + 10
+}
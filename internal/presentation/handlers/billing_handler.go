@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/billing"
+	"snapdeploy-core/internal/middleware"
+	"snapdeploy-core/internal/presentation/apierror"
+	"snapdeploy-core/internal/stripe"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BillingHandler handles the billing portal and Stripe webhooks
+type BillingHandler struct {
+	billingService *service.BillingService
+	userService    *service.UserService
+	webhookSecret  string
+}
+
+// NewBillingHandler creates a new billing handler
+func NewBillingHandler(billingService *service.BillingService, userService *service.UserService, webhookSecret string) *BillingHandler {
+	return &BillingHandler{
+		billingService: billingService,
+		userService:    userService,
+		webhookSecret:  webhookSecret,
+	}
+}
+
+// GetPortal handles GET /billing/portal
+func (h *BillingHandler) GetPortal(c *gin.Context) {
+	clerkUserData, exists := c.Get("user")
+	if !exists {
+		apierror.Abort(c, errNoUser)
+		return
+	}
+
+	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
+	if !ok {
+		apierror.Abort(c, errUserType)
+		return
+	}
+
+	dbUser, err := h.userService.GetOrCreateUserByClerkID(c.Request.Context(), clerkUser.ID)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	response, err := h.billingService.GetPortal(c.Request.Context(), dbUser.ID)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// HandleWebhook handles POST /billing/webhooks
+func (h *BillingHandler) HandleWebhook(c *gin.Context) {
+	if !h.billingService.Enabled() {
+		apierror.Abort(c, billing.ErrBillingDisabled)
+		return
+	}
+
+	payload, err := io.ReadAll(c.Request.Body) // Bounded by middleware.BodyLimit
+	if err != nil {
+		apierror.Abort(c, apierror.Invalid("Failed to read webhook payload", err))
+		return
+	}
+
+	if err := stripe.VerifyWebhookSignature(h.webhookSecret, payload, c.GetHeader("Stripe-Signature"), time.Now()); err != nil {
+		apierror.Abort(c, apierror.New(http.StatusUnauthorized, "unauthorized", "Invalid webhook signature"))
+		return
+	}
+
+	var event stripe.Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		apierror.Abort(c, apierror.Invalid("Invalid event", err))
+		return
+	}
+
+	// Failures are answered with 500 so Stripe retries the event
+	if err := h.billingService.HandleEvent(c.Request.Context(), &event); err != nil {
+		apierror.Abort(c, &apierror.Error{
+			Status:  http.StatusInternalServerError,
+			Code:    "webhook_failed",
+			Message: "Failed to handle event",
+			Err:     fmt.Errorf("stripe event %s of type %s: %w", event.ID, event.Type, err),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
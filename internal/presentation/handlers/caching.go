@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondCached responds with a list the client may have cached. Clients have to revalidate it on every
+// request, and get 304 Not Modified without a body when their If-None-Match still matches its ETag.
+func respondCached(c *gin.Context, etag string, response interface{}) {
+	c.Header("Cache-Control", "private, no-cache")
+	if etag == "" {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// etagMatches reports whether an If-None-Match header lists the ETag. If-None-Match uses the weak
+// comparison, W/ prefixes are ignored.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CommandHandler handles HTTP requests for the one-off commands run in projects' containers
+type CommandHandler struct {
+	commandService *service.CommandService
+	userService    *service.UserService
+}
+
+// NewCommandHandler creates a new command handler
+func NewCommandHandler(commandService *service.CommandService, userService *service.UserService) *CommandHandler {
+	return &CommandHandler{
+		commandService: commandService,
+		userService:    userService,
+	}
+}
+
+// RunCommand handles POST /projects/:id/exec
+func (h *CommandHandler) RunCommand(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService)
+	if !ok {
+		return
+	}
+
+	var req dto.RunCommandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, invalidBody(err))
+		return
+	}
+
+	response, err := h.commandService.RunCommand(c.Request.Context(), c.Param("id"), userID, &req)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
+
+// ListCommandRuns handles GET /projects/:id/exec
+func (h *CommandHandler) ListCommandRuns(c *gin.Context) {
+	response, err := h.commandService.ListCommandRuns(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCommandRun handles GET /projects/:id/exec/:run_id
+func (h *CommandHandler) GetCommandRun(c *gin.Context) {
+	response, err := h.commandService.GetCommandRun(c.Request.Context(), c.Param("id"), c.Param("run_id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// StreamCommandOutput handles GET /projects/:id/exec/:run_id/stream
+func (h *CommandHandler) StreamCommandOutput(c *gin.Context) {
+	projectID, runID := c.Param("id"), c.Param("run_id")
+	if _, err := h.commandService.GetCommandRun(c.Request.Context(), projectID, runID); err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	streamLogs(c, runID, func() []string {
+		run, err := h.commandService.GetCommandRun(c.Request.Context(), projectID, runID)
+		if err != nil {
+			return nil
+		}
+		return run.Output
+	})
+}
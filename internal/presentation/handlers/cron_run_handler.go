@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CronRunHandler handles HTTP requests for the runs of CRON projects' deployments
+type CronRunHandler struct {
+	cronRunService *service.CronRunService
+}
+
+// NewCronRunHandler creates a new cron run handler
+func NewCronRunHandler(cronRunService *service.CronRunService) *CronRunHandler {
+	return &CronRunHandler{
+		cronRunService: cronRunService,
+	}
+}
+
+// ListRuns handles GET /deployments/:id/runs
+func (h *CronRunHandler) ListRuns(c *gin.Context) {
+	response, err := h.cronRunService.ListRuns(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRunLogs handles GET /deployments/:id/runs/:run_id/logs
+func (h *CronRunHandler) GetRunLogs(c *gin.Context) {
+	response, err := h.cronRunService.GetRunLogs(c.Request.Context(), c.Param("id"), c.Param("run_id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
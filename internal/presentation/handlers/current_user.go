@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/middleware"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentUserID returns the internal ID of the signed-in user, creating the user on their first request.
+// It writes the error response and returns false if the user can't be resolved.
+func currentUserID(c *gin.Context, userService *service.UserService) (string, bool) {
+	clerkUser, ok := middleware.ContextUser(c)
+	if !ok {
+		return "", false
+	}
+
+	dbUser, err := userService.GetOrCreateUserByClerkID(c.Request.Context(), clerkUser.ID)
+	if err != nil {
+		apierror.Abort(c, err)
+		return "", false
+	}
+	return dbUser.ID, true
+}
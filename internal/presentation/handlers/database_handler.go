@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DatabaseHandler handles project database HTTP requests
+type DatabaseHandler struct {
+	databaseService *service.DatabaseService
+	branchService   *service.DatabaseBranchService
+}
+
+// NewDatabaseHandler creates a new database handler
+func NewDatabaseHandler(databaseService *service.DatabaseService, branchService *service.DatabaseBranchService) *DatabaseHandler {
+	return &DatabaseHandler{
+		databaseService: databaseService,
+		branchService:   branchService,
+	}
+}
+
+// GetProjectDatabase handles GET /projects/:id/database
+func (h *DatabaseHandler) GetProjectDatabase(c *gin.Context) {
+	response, err := h.databaseService.GetProjectDatabase(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetProjectDatabase handles POST /projects/:id/database/reset
+func (h *DatabaseHandler) ResetProjectDatabase(c *gin.Context) {
+	response, err := h.databaseService.ResetProjectDatabase(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateSnapshot handles POST /projects/:id/database/snapshots
+func (h *DatabaseHandler) CreateSnapshot(c *gin.Context) {
+	var req dto.CreateDatabaseSnapshotRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Abort(c, invalidBody(err))
+			return
+		}
+	}
+
+	response, err := h.branchService.CreateSnapshot(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListSnapshots handles GET /projects/:id/database/snapshots
+func (h *DatabaseHandler) ListSnapshots(c *gin.Context) {
+	response, err := h.branchService.ListSnapshots(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteSnapshot handles DELETE /projects/:id/database/snapshots/:snapshot_id
+func (h *DatabaseHandler) DeleteSnapshot(c *gin.Context) {
+	if err := h.branchService.DeleteSnapshot(c.Request.Context(), c.Param("id"), c.Param("snapshot_id")); err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateBranch handles POST /projects/:id/database/branches
+func (h *DatabaseHandler) CreateBranch(c *gin.Context) {
+	var req dto.CreateDatabaseBranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, invalidBody(err))
+		return
+	}
+
+	response, err := h.branchService.CreateBranch(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListBranches handles GET /projects/:id/database/branches
+func (h *DatabaseHandler) ListBranches(c *gin.Context) {
+	response, err := h.branchService.ListBranches(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteBranch handles DELETE /projects/:id/database/branches/:branch_id
+func (h *DatabaseHandler) DeleteBranch(c *gin.Context) {
+	if err := h.branchService.DeleteBranch(c.Request.Context(), c.Param("id"), c.Param("branch_id")); err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeploymentCompareHandler handles deployment comparison HTTP requests
+type DeploymentCompareHandler struct {
+	compareService *service.DeploymentCompareService
+}
+
+// NewDeploymentCompareHandler creates a new deployment compare handler
+func NewDeploymentCompareHandler(compareService *service.DeploymentCompareService) *DeploymentCompareHandler {
+	return &DeploymentCompareHandler{compareService: compareService}
+}
+
+// CompareDeployments handles GET /projects/:id/deployments/compare
+func (h *DeploymentCompareHandler) CompareDeployments(c *gin.Context) {
+	from, to := c.Query("from"), c.Query("to")
+	if from == "" || to == "" {
+		apierror.Abort(c, apierror.New(http.StatusBadRequest, "invalid_request", "Both from and to deployment IDs are required"))
+		return
+	}
+
+	response, err := h.compareService.CompareDeployments(c.Request.Context(), c.Param("id"), from, to)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
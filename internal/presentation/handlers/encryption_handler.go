@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EncryptionHandler handles encryption key rotation HTTP requests
+type EncryptionHandler struct {
+	keyRotationService *service.KeyRotationService
+}
+
+// NewEncryptionHandler creates a new encryption handler
+func NewEncryptionHandler(keyRotationService *service.KeyRotationService) *EncryptionHandler {
+	return &EncryptionHandler{
+		keyRotationService: keyRotationService,
+	}
+}
+
+// GetStatus handles GET /admin/encryption
+func (h *EncryptionHandler) GetStatus(c *gin.Context) {
+	response, err := h.keyRotationService.Status(c.Request.Context())
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Rotate handles POST /admin/encryption/rotate
+func (h *EncryptionHandler) Rotate(c *gin.Context) {
+	response, err := h.keyRotationService.Rotate(c.Request.Context())
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
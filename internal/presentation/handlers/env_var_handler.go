@@ -1,13 +1,12 @@
 package handlers
 
 import (
-	"errors"
 	"net/http"
 
 	"snapdeploy-core/internal/application/dto"
 	"snapdeploy-core/internal/application/service"
-	"snapdeploy-core/internal/domain/project"
 	"snapdeploy-core/internal/middleware"
+	"snapdeploy-core/internal/presentation/apierror"
 
 	"github.com/gin-gonic/gin"
 )
@@ -30,71 +29,32 @@ func NewEnvVarHandler(
 }
 
 // GetProjectEnvVars handles GET /projects/:id/env
-// @Summary Get project environment variables
-// @Description Returns all environment variables for a project (values are masked)
-// @Tags Environment Variables
-// @Security ClerkAuth
-// @Param id path string true "Project ID"
-// @Success 200 {object} dto.EnvVarListResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 403 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
-// @Router /projects/{id}/env [get]
 func (h *EnvVarHandler) GetProjectEnvVars(c *gin.Context) {
 	projectID := c.Param("id")
 
 	// Get authenticated user
 	clerkUserData, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "unauthorized",
-			Message: "User not found in context",
-		})
+		apierror.Abort(c, errNoUser)
 		return
 	}
 
 	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Invalid user type in context",
-		})
+		apierror.Abort(c, errUserType)
 		return
 	}
 
 	// Get internal user ID
 	dbUser, err := h.userService.GetOrCreateUserByClerkID(c.Request.Context(), clerkUser.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Failed to resolve user",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, err)
 		return
 	}
 
-	response, err := h.envVarService.GetProjectEnvVars(c.Request.Context(), projectID, dbUser.ID)
+	response, err := h.envVarService.GetProjectEnvVars(c.Request.Context(), projectID, dbUser.ID, c.Query("environment"))
 	if err != nil {
-		if errors.Is(err, project.ErrProjectNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{
-				Error:   "not_found",
-				Message: "Project not found",
-			})
-			return
-		}
-		if errors.Is(err, project.ErrUnauthorized) {
-			c.JSON(http.StatusForbidden, ErrorResponse{
-				Error:   "forbidden",
-				Message: "You don't have permission to access this project",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Failed to get environment variables",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, err)
 		return
 	}
 
@@ -102,83 +62,38 @@ func (h *EnvVarHandler) GetProjectEnvVars(c *gin.Context) {
 }
 
 // CreateOrUpdateEnvVar handles POST /projects/:id/env
-// @Summary Create or update an environment variable
-// @Description Creates or updates an environment variable for a project
-// @Tags Environment Variables
-// @Security ClerkAuth
-// @Param id path string true "Project ID"
-// @Param env_var body dto.CreateEnvVarRequest true "Environment variable data"
-// @Success 200 {object} dto.EnvVarResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 403 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
-// @Router /projects/{id}/env [post]
 func (h *EnvVarHandler) CreateOrUpdateEnvVar(c *gin.Context) {
 	projectID := c.Param("id")
 
 	// Get authenticated user
 	clerkUserData, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "unauthorized",
-			Message: "User not found in context",
-		})
+		apierror.Abort(c, errNoUser)
 		return
 	}
 
 	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Invalid user type in context",
-		})
+		apierror.Abort(c, errUserType)
 		return
 	}
 
 	// Get internal user ID
 	dbUser, err := h.userService.GetOrCreateUserByClerkID(c.Request.Context(), clerkUser.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Failed to resolve user",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, err)
 		return
 	}
 
 	var req dto.CreateEnvVarRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_request",
-			Message: "Invalid request body",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, invalidBody(err))
 		return
 	}
 
 	response, err := h.envVarService.CreateOrUpdateEnvVar(c.Request.Context(), projectID, dbUser.ID, &req)
 	if err != nil {
-		if errors.Is(err, project.ErrProjectNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{
-				Error:   "not_found",
-				Message: "Project not found",
-			})
-			return
-		}
-		if errors.Is(err, project.ErrUnauthorized) {
-			c.JSON(http.StatusForbidden, ErrorResponse{
-				Error:   "forbidden",
-				Message: "You don't have permission to modify this project",
-			})
-			return
-		}
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "creation_failed",
-			Message: "Failed to create/update environment variable",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, err)
 		return
 	}
 
@@ -186,18 +101,6 @@ func (h *EnvVarHandler) CreateOrUpdateEnvVar(c *gin.Context) {
 }
 
 // DeleteEnvVar handles DELETE /projects/:id/env/:key
-// @Summary Delete an environment variable
-// @Description Deletes an environment variable from a project
-// @Tags Environment Variables
-// @Security ClerkAuth
-// @Param id path string true "Project ID"
-// @Param key path string true "Environment variable key"
-// @Success 204
-// @Failure 401 {object} ErrorResponse
-// @Failure 403 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
-// @Router /projects/{id}/env/{key} [delete]
 func (h *EnvVarHandler) DeleteEnvVar(c *gin.Context) {
 	projectID := c.Param("id")
 	key := c.Param("key")
@@ -205,54 +108,26 @@ func (h *EnvVarHandler) DeleteEnvVar(c *gin.Context) {
 	// Get authenticated user
 	clerkUserData, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "unauthorized",
-			Message: "User not found in context",
-		})
+		apierror.Abort(c, errNoUser)
 		return
 	}
 
 	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Invalid user type in context",
-		})
+		apierror.Abort(c, errUserType)
 		return
 	}
 
 	// Get internal user ID
 	dbUser, err := h.userService.GetOrCreateUserByClerkID(c.Request.Context(), clerkUser.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Failed to resolve user",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, err)
 		return
 	}
 
-	err = h.envVarService.DeleteEnvVar(c.Request.Context(), projectID, dbUser.ID, key)
+	err = h.envVarService.DeleteEnvVar(c.Request.Context(), projectID, dbUser.ID, key, c.Query("environment"))
 	if err != nil {
-		if errors.Is(err, project.ErrProjectNotFound) || errors.Is(err, project.ErrEnvVarNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{
-				Error:   "not_found",
-				Message: "Environment variable not found",
-			})
-			return
-		}
-		if errors.Is(err, project.ErrUnauthorized) {
-			c.JSON(http.StatusForbidden, ErrorResponse{
-				Error:   "forbidden",
-				Message: "You don't have permission to modify this project",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "delete_failed",
-			Message: "Failed to delete environment variable",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, err)
 		return
 	}
 
@@ -1,8 +1,15 @@
 package handlers
 
-// ErrorResponse represents an error response
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
+import "snapdeploy-core/internal/presentation/apierror"
+
+// Errors of the user the authentication middleware puts in the context. Handlers respond to every other error
+// with apierror.Abort, which maps it to its status and code.
+var (
+	errNoUser   = apierror.ErrNoUser
+	errUserType = apierror.ErrUserType
+)
+
+// invalidBody returns the error of a request body that can't be bound
+func invalidBody(err error) error {
+	return apierror.Invalid("Invalid request body", err)
 }
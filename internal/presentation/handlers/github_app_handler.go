@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/github"
+	"snapdeploy-core/internal/middleware"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GitHubAppHandler handles GitHub App webhooks and installation HTTP requests
+type GitHubAppHandler struct {
+	installationService *service.GitHubInstallationService
+	pushDeployService   *service.PushDeployService
+	webhookSecret       string
+}
+
+// NewGitHubAppHandler creates a new GitHub App handler
+func NewGitHubAppHandler(installationService *service.GitHubInstallationService, webhookSecret string) *GitHubAppHandler {
+	return &GitHubAppHandler{
+		installationService: installationService,
+		webhookSecret:       webhookSecret,
+	}
+}
+
+// SetPushDeployService sets the service deploying pushes to branches matching project deploy rules (optional)
+func (h *GitHubAppHandler) SetPushDeployService(pushDeployService *service.PushDeployService) {
+	h.pushDeployService = pushDeployService
+}
+
+// HandleWebhook handles POST /github/webhooks
+func (h *GitHubAppHandler) HandleWebhook(c *gin.Context) {
+	if !h.installationService.Enabled() {
+		apierror.Abort(c, service.ErrGitHubAppUnavailable)
+		return
+	}
+
+	payload, err := io.ReadAll(c.Request.Body) // Bounded by middleware.BodyLimit
+	if err != nil {
+		apierror.Abort(c, apierror.Invalid("Failed to read webhook payload", err))
+		return
+	}
+
+	if err := github.VerifyWebhookSignature(h.webhookSecret, payload, c.GetHeader("X-Hub-Signature-256")); err != nil {
+		apierror.Abort(c, apierror.New(http.StatusUnauthorized, "unauthorized", "Invalid webhook signature"))
+		return
+	}
+
+	switch c.GetHeader("X-GitHub-Event") {
+	case github.EventInstallation:
+		var event github.InstallationEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			apierror.Abort(c, apierror.Invalid("Invalid installation event", err))
+			return
+		}
+
+		if err := h.installationService.HandleInstallationEvent(c.Request.Context(), &event); err != nil {
+			apierror.Abort(c, &apierror.Error{
+				Status:  http.StatusInternalServerError,
+				Code:    "webhook_failed",
+				Message: "Failed to handle installation event",
+				Err:     fmt.Errorf("GitHub installation event %s: %w", event.Action, err),
+			})
+			return
+		}
+
+	case github.EventPush:
+		if h.pushDeployService == nil {
+			break
+		}
+
+		var event github.PushEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			apierror.Abort(c, apierror.Invalid("Invalid push event", err))
+			return
+		}
+
+		if err := h.pushDeployService.HandlePushEvent(c.Request.Context(), &event); err != nil {
+			apierror.Abort(c, &apierror.Error{
+				Status:  http.StatusInternalServerError,
+				Code:    "webhook_failed",
+				Message: "Failed to handle push event",
+				Err:     fmt.Errorf("GitHub push event for %s %s: %w", event.Repository.FullName, event.Ref, err),
+			})
+			return
+		}
+	}
+
+	// Other events (including ping) are acknowledged and ignored
+	c.Status(http.StatusNoContent)
+}
+
+// GetUserInstallations handles GET /users/:id/github/installations
+func (h *GitHubAppHandler) GetUserInstallations(c *gin.Context) {
+	response, err := h.installationService.ListInstallations(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ClaimInstallation handles POST /users/:id/github/installations
+// Only installations made by the signed-in user's GitHub account can be claimed.
+func (h *GitHubAppHandler) ClaimInstallation(c *gin.Context) {
+	clerkUser, ok := middleware.ContextUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ClaimGitHubInstallationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, invalidBody(err))
+		return
+	}
+
+	response, err := h.installationService.ClaimInstallation(c.Request.Context(), c.Param("id"), clerkUser.ID, req.InstallationID)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
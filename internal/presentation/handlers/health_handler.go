@@ -1,36 +1,101 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// healthCheckTimeout bounds each dependency check, so a hanging dependency fails readiness instead of the probe
+const healthCheckTimeout = 5 * time.Second
+
+// HealthCheck checks a dependency the server needs to serve requests
+type HealthCheck struct {
+	Name  string                          // e.g. "postgres", the key of its result in the readiness response
+	Check func(ctx context.Context) error // Returns why the dependency is unavailable, nil if it is fine
+}
+
 // HealthHandler handles health check requests
-type HealthHandler struct{}
+type HealthHandler struct {
+	checks []HealthCheck
+}
 
-// NewHealthHandler creates a new health handler
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+// NewHealthHandler creates a new health handler checking the given dependencies for readiness
+func NewHealthHandler(checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{checks: checks}
 }
 
 // Health handles GET /health
-// @Summary Health check
-// @Description Returns the health status of the service
-// @Tags Health
-// @Accept json
-// @Produce json
-// @Success 200 {object} HealthResponse
-// @Router /health [get]
 func (h *HealthHandler) Health(c *gin.Context) {
+	h.Live(c)
+}
+
+// Live handles GET /health/live
+func (h *HealthHandler) Live(c *gin.Context) {
 	c.JSON(http.StatusOK, HealthResponse{
 		Status:  "healthy",
 		Message: "Service is running",
 	})
 }
 
+// Ready handles GET /health/ready
+func (h *HealthHandler) Ready(c *gin.Context) {
+	results := make(map[string]DependencyStatus, len(h.checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func(check HealthCheck) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+			defer cancel()
+
+			startedAt := time.Now()
+			err := check.Check(ctx)
+			result := DependencyStatus{Status: "ok", LatencyMs: time.Since(startedAt).Milliseconds()}
+			if err != nil {
+				result.Status = "unavailable"
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			results[check.Name] = result
+			mu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	response := ReadinessResponse{Status: "ready", Checks: results}
+	status := http.StatusOK
+	for _, result := range results {
+		if result.Error != "" {
+			response.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	c.JSON(status, response)
+}
+
 // HealthResponse represents the health check response
 type HealthResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 }
+
+// ReadinessResponse represents the readiness check response
+type ReadinessResponse struct {
+	Status string                      `json:"status"` // ready or unavailable
+	Checks map[string]DependencyStatus `json:"checks"` // Result of each dependency, by name
+}
+
+// DependencyStatus is the result of checking one dependency
+type DependencyStatus struct {
+	Status    string `json:"status"` // ok or unavailable
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
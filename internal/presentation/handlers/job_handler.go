@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/middleware"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobHandler handles background job HTTP requests
+type JobHandler struct {
+	jobService *service.JobService
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(jobService *service.JobService) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+	}
+}
+
+// GetJob handles GET /jobs/:id
+func (h *JobHandler) GetJob(c *gin.Context) {
+	jobID := c.Param("id")
+
+	// Get authenticated user from context
+	clerkUserData, exists := c.Get("user")
+	if !exists {
+		apierror.Abort(c, errNoUser)
+		return
+	}
+
+	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
+	if !ok {
+		apierror.Abort(c, errUserType)
+		return
+	}
+
+	response, err := h.jobService.GetJob(jobID, clerkUser.ID)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
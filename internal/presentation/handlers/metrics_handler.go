@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsHandler handles project runtime metrics HTTP requests
+type MetricsHandler struct {
+	metricsService *service.MetricsService
+}
+
+// NewMetricsHandler creates a new metrics handler
+func NewMetricsHandler(metricsService *service.MetricsService) *MetricsHandler {
+	return &MetricsHandler{
+		metricsService: metricsService,
+	}
+}
+
+// GetProjectMetrics handles GET /projects/:id/metrics
+func (h *MetricsHandler) GetProjectMetrics(c *gin.Context) {
+	response, err := h.metricsService.GetProjectMetrics(c.Request.Context(), c.Param("id"), c.Query("period"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
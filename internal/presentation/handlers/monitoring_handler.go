@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MonitoringHandler handles HTTP requests for the uptime monitoring of projects
+type MonitoringHandler struct {
+	monitoringService *service.MonitoringService
+}
+
+// NewMonitoringHandler creates a new monitoring handler
+func NewMonitoringHandler(monitoringService *service.MonitoringService) *MonitoringHandler {
+	return &MonitoringHandler{
+		monitoringService: monitoringService,
+	}
+}
+
+// ListIncidents handles GET /projects/:id/incidents
+func (h *MonitoringHandler) ListIncidents(c *gin.Context) {
+	response, err := h.monitoringService.ListIncidents(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"snapdeploy-core/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPIHandler serves the API's OpenAPI document
+type OpenAPIHandler struct {
+	doc *openapi.Document
+}
+
+// NewOpenAPIHandler creates a new handler serving the given document
+func NewOpenAPIHandler(doc *openapi.Document) *OpenAPIHandler {
+	return &OpenAPIHandler{doc: doc}
+}
+
+// Document handles GET /openapi.json
+func (h *OpenAPIHandler) Document(c *gin.Context) {
+	c.Data(http.StatusOK, gin.MIMEJSON+"; charset=utf-8", h.doc.JSON())
+}
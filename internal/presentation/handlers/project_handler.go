@@ -1,14 +1,15 @@
 package handlers
 
 import (
-	"errors"
+	"context"
 	"net/http"
 	"strconv"
 
 	"snapdeploy-core/internal/application/dto"
 	"snapdeploy-core/internal/application/service"
-	"snapdeploy-core/internal/domain/project"
+	"snapdeploy-core/internal/domain/job"
 	"snapdeploy-core/internal/middleware"
+	"snapdeploy-core/internal/presentation/apierror"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,132 +18,133 @@ import (
 type ProjectHandler struct {
 	projectService *service.ProjectService
 	userService    *service.UserService
+	jobService     *service.JobService
+	operatorIDs    []string
 }
 
 // NewProjectHandler creates a new project handler
-func NewProjectHandler(projectService *service.ProjectService, userService *service.UserService) *ProjectHandler {
+func NewProjectHandler(projectService *service.ProjectService, userService *service.UserService, jobService *service.JobService, operatorIDs []string) *ProjectHandler {
 	return &ProjectHandler{
 		projectService: projectService,
 		userService:    userService,
+		jobService:     jobService,
+		operatorIDs:    operatorIDs,
 	}
 }
 
 // CreateProject handles POST /users/:id/projects
-// @Summary Create a new project
-// @Description Creates a new project for a user
-// @Tags Projects
-// @Accept json
-// @Produce json
-// @Security ClerkAuth
-// @Param id path string true "User ID"
-// @Param project body dto.CreateProjectRequest true "Project data"
-// @Success 201 {object} dto.ProjectResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 409 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
-// @Router /users/{id}/projects [post]
 func (h *ProjectHandler) CreateProject(c *gin.Context) {
 	userID := c.Param("id")
 
 	// Get authenticated user from context
 	clerkUserData, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "unauthorized",
-			Message: "User not found in context",
-		})
+		apierror.Abort(c, errNoUser)
 		return
 	}
 
 	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Invalid user type in context",
-		})
+		apierror.Abort(c, errUserType)
 		return
 	}
 
 	// Get the internal user ID from Clerk ID
 	dbUser, err := h.userService.GetOrCreateUserByClerkID(c.Request.Context(), clerkUser.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Failed to resolve user",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, err)
 		return
 	}
 
 	// Verify user is creating project for themselves
 	if dbUser.ID != userID {
-		c.JSON(http.StatusForbidden, ErrorResponse{
-			Error:   "forbidden",
-			Message: "You can only create projects for yourself",
-		})
+		apierror.Abort(c, apierror.New(http.StatusForbidden, "forbidden", "You can only create projects for yourself"))
 		return
 	}
 
 	var req dto.CreateProjectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_request",
-			Message: "Invalid request body",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, invalidBody(err))
 		return
 	}
 
 	response, err := h.projectService.CreateProject(c.Request.Context(), userID, &req)
 	if err != nil {
-		if errors.Is(err, project.ErrProjectAlreadyExists) {
-			c.JSON(http.StatusConflict, ErrorResponse{
-				Error:   "project_exists",
-				Message: "A project with this repository URL already exists",
-			})
-			return
-		}
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "creation_failed",
-			Message: "Failed to create project",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, response)
 }
 
+// CheckDomain handles GET /domains/check
+func (h *ProjectHandler) CheckDomain(c *gin.Context) {
+	name := c.Query("name")
+	if name == "" {
+		apierror.Abort(c, apierror.New(http.StatusBadRequest, "invalid_request", "The name query parameter is required"))
+		return
+	}
+
+	response, err := h.projectService.CheckDomain(c.Request.Context(), name)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ValidateProject handles POST /projects/validate
+func (h *ProjectHandler) ValidateProject(c *gin.Context) {
+	clerkUserData, exists := c.Get("user")
+	if !exists {
+		apierror.Abort(c, errNoUser)
+		return
+	}
+
+	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
+	if !ok {
+		apierror.Abort(c, errUserType)
+		return
+	}
+
+	dbUser, err := h.userService.GetOrCreateUserByClerkID(c.Request.Context(), clerkUser.ID)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	var req dto.ValidateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, invalidBody(err))
+		return
+	}
+
+	// Validate in the background - reading the repository and resolving DNS can outlast a request
+	response, err := h.jobService.Submit(c.Request.Context(), clerkUser.ID, job.KindProjectValidation, func(ctx context.Context) (interface{}, error) {
+		return h.projectService.ValidateProject(ctx, dbUser.ID, &req)
+	})
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/jobs/"+response.ID)
+	c.JSON(http.StatusAccepted, response)
+}
+
 // GetProject handles GET /projects/:id
-// @Summary Get a project by ID
-// @Description Returns a single project by its ID
-// @Tags Projects
-// @Accept json
-// @Produce json
-// @Security ClerkAuth
-// @Param id path string true "Project ID"
-// @Success 200 {object} dto.ProjectResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Router /projects/{id} [get]
 func (h *ProjectHandler) GetProject(c *gin.Context) {
 	projectID := c.Param("id")
 
-	response, err := h.projectService.GetProjectByID(c.Request.Context(), projectID)
+	withDeleted, ok := includeDeleted(c, h.operatorIDs)
+	if !ok {
+		return
+	}
+
+	response, err := h.projectService.GetProjectByID(c.Request.Context(), projectID, withDeleted)
 	if err != nil {
-		if errors.Is(err, project.ErrProjectNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{
-				Error:   "not_found",
-				Message: "Project not found",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "fetch_failed",
-			Message: "Failed to fetch project",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, err)
 		return
 	}
 
@@ -150,22 +152,14 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 }
 
 // GetUserProjects handles GET /users/:id/projects
-// @Summary Get user projects
-// @Description Returns all projects for a user with pagination
-// @Tags Projects
-// @Accept json
-// @Produce json
-// @Security ClerkAuth
-// @Param id path string true "User ID"
-// @Param page query int false "Page number" default(1) minimum(1)
-// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
-// @Success 200 {object} dto.ProjectListResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
-// @Router /users/{id}/projects [get]
 func (h *ProjectHandler) GetUserProjects(c *gin.Context) {
 	userID := c.Param("id")
 
+	withDeleted, ok := includeDeleted(c, h.operatorIDs)
+	if !ok {
+		return
+	}
+
 	// Get pagination parameters
 	page := 1
 	limit := 20
@@ -187,98 +181,119 @@ func (h *ProjectHandler) GetUserProjects(c *gin.Context) {
 		userID,
 		int32(page),
 		int32(limit),
+		withDeleted,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "fetch_failed",
-			Message: "Failed to fetch projects",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	respondCached(c, response.ETag, response)
 }
 
 // UpdateProject handles PUT /projects/:id
-// @Summary Update a project
-// @Description Updates an existing project
-// @Tags Projects
-// @Accept json
-// @Produce json
-// @Security ClerkAuth
-// @Param id path string true "Project ID"
-// @Param project body dto.UpdateProjectRequest true "Project data"
-// @Success 200 {object} dto.ProjectResponse
-// @Failure 400 {object} ErrorResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 403 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Router /projects/{id} [put]
 func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 	projectID := c.Param("id")
 
 	// Get authenticated user from context
 	clerkUserData, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "unauthorized",
-			Message: "User not found in context",
-		})
+		apierror.Abort(c, errNoUser)
 		return
 	}
 
 	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Invalid user type in context",
-		})
+		apierror.Abort(c, errUserType)
 		return
 	}
 
 	// Get the internal user ID from Clerk ID
 	dbUser, err := h.userService.GetOrCreateUserByClerkID(c.Request.Context(), clerkUser.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Failed to resolve user",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, err)
 		return
 	}
 
 	var req dto.UpdateProjectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "invalid_request",
-			Message: "Invalid request body",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, invalidBody(err))
 		return
 	}
 
 	response, err := h.projectService.UpdateProject(c.Request.Context(), projectID, dbUser.ID, &req)
 	if err != nil {
-		if errors.Is(err, project.ErrProjectNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{
-				Error:   "not_found",
-				Message: "Project not found",
-			})
-			return
-		}
-		if errors.Is(err, project.ErrUnauthorized) {
-			c.JSON(http.StatusForbidden, ErrorResponse{
-				Error:   "forbidden",
-				Message: "You don't have permission to update this project",
-			})
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DuplicateProject handles POST /projects/:id/duplicate
+func (h *ProjectHandler) DuplicateProject(c *gin.Context) {
+	projectID := c.Param("id")
+
+	// Get authenticated user from context
+	clerkUserData, exists := c.Get("user")
+	if !exists {
+		apierror.Abort(c, errNoUser)
+		return
+	}
+
+	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
+	if !ok {
+		apierror.Abort(c, errUserType)
+		return
+	}
+
+	// Get the internal user ID from Clerk ID
+	dbUser, err := h.userService.GetOrCreateUserByClerkID(c.Request.Context(), clerkUser.ID)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	// Every field is optional, so is the body
+	var req dto.DuplicateProjectRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Abort(c, invalidBody(err))
 			return
 		}
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "update_failed",
-			Message: "Failed to update project",
-			Details: err.Error(),
-		})
+	}
+
+	response, err := h.projectService.DuplicateProject(c.Request.Context(), projectID, dbUser.ID, &req)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetDeployRules handles GET /projects/:id/deploy-rules
+func (h *ProjectHandler) GetDeployRules(c *gin.Context) {
+	response, err := h.projectService.GetDeployRules(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateDeployRules handles PUT /projects/:id/deploy-rules
+func (h *ProjectHandler) UpdateDeployRules(c *gin.Context) {
+	var req dto.UpdateDeployRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, invalidBody(err))
+		return
+	}
+
+	response, err := h.projectService.UpdateDeployRules(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		apierror.Abort(c, err)
 		return
 	}
 
@@ -286,74 +301,66 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 }
 
 // DeleteProject handles DELETE /projects/:id
-// @Summary Delete a project
-// @Description Deletes a project
-// @Tags Projects
-// @Accept json
-// @Produce json
-// @Security ClerkAuth
-// @Param id path string true "Project ID"
-// @Success 204
-// @Failure 401 {object} ErrorResponse
-// @Failure 403 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Router /projects/{id} [delete]
 func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 	projectID := c.Param("id")
 
 	// Get authenticated user from context
 	clerkUserData, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "unauthorized",
-			Message: "User not found in context",
-		})
+		apierror.Abort(c, errNoUser)
 		return
 	}
 
 	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Invalid user type in context",
-		})
+		apierror.Abort(c, errUserType)
 		return
 	}
 
 	// Get the internal user ID from Clerk ID
 	dbUser, err := h.userService.GetOrCreateUserByClerkID(c.Request.Context(), clerkUser.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Failed to resolve user",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, err)
 		return
 	}
 
-	err = h.projectService.DeleteProject(c.Request.Context(), projectID, dbUser.ID)
+	response, err := h.projectService.DeleteProject(c.Request.Context(), projectID, dbUser.ID)
 	if err != nil {
-		if errors.Is(err, project.ErrProjectNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{
-				Error:   "not_found",
-				Message: "Project not found",
-			})
-			return
-		}
-		if errors.Is(err, project.ErrUnauthorized) {
-			c.JSON(http.StatusForbidden, ErrorResponse{
-				Error:   "forbidden",
-				Message: "You don't have permission to delete this project",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "delete_failed",
-			Message: "Failed to delete project",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, err)
 		return
 	}
 
-	c.Status(http.StatusNoContent)
+	c.JSON(http.StatusAccepted, response)
+}
+
+// includeDeleted parses the include_deleted query parameter, which only platform operators may set.
+// It writes the error response and returns false if the parameter is invalid or not allowed.
+func includeDeleted(c *gin.Context, operatorIDs []string) (bool, bool) {
+	value := c.Query("include_deleted")
+	if value == "" {
+		return false, true
+	}
+
+	include, err := strconv.ParseBool(value)
+	if err != nil {
+		apierror.Abort(c, apierror.New(http.StatusBadRequest, "invalid_request", "include_deleted must be true or false"))
+		return false, false
+	}
+	if !include {
+		return false, true
+	}
+
+	clerkUserData, exists := c.Get("user")
+	if !exists {
+		apierror.Abort(c, errNoUser)
+		return false, false
+	}
+
+	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
+	if !ok || !middleware.IsOperator(clerkUser, operatorIDs) {
+		apierror.Abort(c, apierror.New(http.StatusForbidden, "forbidden", "Only platform operators can read deleted resources"))
+		return false, false
+	}
+
+	return true, true
 }
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuotaHandler handles plan limit HTTP requests
+type QuotaHandler struct {
+	quotaService *service.QuotaService
+}
+
+// NewQuotaHandler creates a new quota handler
+func NewQuotaHandler(quotaService *service.QuotaService) *QuotaHandler {
+	return &QuotaHandler{
+		quotaService: quotaService,
+	}
+}
+
+// GetUserLimits handles GET /users/:id/limits
+func (h *QuotaHandler) GetUserLimits(c *gin.Context) {
+	response, err := h.quotaService.GetUserLimits(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateUserLimits handles PUT /admin/users/:id/limits
+func (h *QuotaHandler) UpdateUserLimits(c *gin.Context) {
+	var req dto.UpdateUserLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, invalidBody(err))
+		return
+	}
+
+	response, err := h.quotaService.SetUserLimits(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
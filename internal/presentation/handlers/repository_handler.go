@@ -1,12 +1,19 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"snapdeploy-core/internal/application/dto"
 	"snapdeploy-core/internal/application/service"
 	"snapdeploy-core/internal/clerk"
+	"snapdeploy-core/internal/domain/job"
+	"snapdeploy-core/internal/domain/repo"
 	"snapdeploy-core/internal/middleware"
+	"snapdeploy-core/internal/presentation/apierror"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,92 +21,87 @@ import (
 // RepositoryHandler handles repository-related HTTP requests
 type RepositoryHandler struct {
 	repositoryService *service.RepositoryService
+	jobService        *service.JobService
+	userService       *service.UserService
 	clerkClient       *clerk.Client
 }
 
 // NewRepositoryHandler creates a new repository handler
-func NewRepositoryHandler(repositoryService *service.RepositoryService, clerkClient *clerk.Client) *RepositoryHandler {
+func NewRepositoryHandler(repositoryService *service.RepositoryService, jobService *service.JobService, userService *service.UserService, clerkClient *clerk.Client) *RepositoryHandler {
 	return &RepositoryHandler{
 		repositoryService: repositoryService,
+		jobService:        jobService,
+		userService:       userService,
 		clerkClient:       clerkClient,
 	}
 }
 
 // SyncRepositories handles POST /users/:id/repos/sync
-// @Summary Sync user repositories from GitHub
-// @Description Syncs the repositories for a user from GitHub
-// @Tags Repositories
-// @Accept json
-// @Produce json
-// @Security ClerkAuth
-// @Param id path string true "User ID"
-// @Success 200 {object} dto.RepositorySyncResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
-// @Router /users/{id}/repos/sync [post]
 func (h *RepositoryHandler) SyncRepositories(c *gin.Context) {
 	userID := c.Param("id")
 
 	// Get clerk user from context
 	clerkUserData, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "unauthorized",
-			Message: "User not found in context",
-		})
+		apierror.Abort(c, errNoUser)
 		return
 	}
 
 	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Invalid user type in context",
-		})
+		apierror.Abort(c, errUserType)
 		return
 	}
 
-	// Get GitHub access token from Clerk for this user
-	githubToken, err := h.clerkClient.GetGitHubAccessToken(c.Request.Context(), clerkUser.ID)
+	provider, err := repo.NewProvider(c.DefaultQuery("provider", "github"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "github_not_connected",
-			Message: "GitHub account not connected. Please connect your GitHub account in your user profile settings.",
-			Details: err.Error(),
+		apierror.Abort(c, &apierror.Error{
+			Status:  http.StatusBadRequest,
+			Code:    "invalid_provider",
+			Message: "Provider must be one of: github, gitlab, bitbucket",
+			Err:     err,
 		})
 		return
 	}
 
-	// Sync repositories using application service
-	response, err := h.repositoryService.SyncRepositoriesFromGitHub(c.Request.Context(), userID, githubToken)
+	var sync service.JobFunc
+	if provider == repo.ProviderGitHub {
+		// Users who installed the GitHub App sync through its installations instead of their own token
+		usesGitHubApp, err := h.repositoryService.UsesGitHubApp(c.Request.Context(), userID)
+		if err != nil {
+			apierror.Abort(c, err)
+			return
+		}
+		if usesGitHubApp {
+			sync = func(ctx context.Context) (interface{}, error) {
+				return h.repositoryService.SyncRepositoriesFromInstallations(ctx, userID)
+			}
+		}
+	}
+
+	if sync == nil {
+		// Get the provider's access token from Clerk for this user
+		accessToken, ok := h.getAccessToken(c, clerkUser.ID, provider)
+		if !ok {
+			return
+		}
+		sync = func(ctx context.Context) (interface{}, error) {
+			return h.repositoryService.SyncRepositoriesFromProvider(ctx, userID, provider.String(), accessToken)
+		}
+	}
+
+	// Sync in the background - large accounts take longer than proxies allow a request to run
+	response, err := h.jobService.Submit(c.Request.Context(), clerkUser.ID, job.KindRepositorySync, sync)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "sync_failed",
-			Message: "Failed to sync repositories from GitHub",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.Header("Location", "/api/v1/jobs/"+response.ID)
+	c.JSON(http.StatusAccepted, response)
 }
 
 // GetUserRepositories handles GET /users/:id/repos
-// @Summary Get user repositories with search
-// @Description Returns the repositories for a user with pagination and optional search
-// @Tags Repositories
-// @Accept json
-// @Produce json
-// @Security ClerkAuth
-// @Param id path string true "User ID"
-// @Param page query int false "Page number" default(1) minimum(1)
-// @Param limit query int false "Items per page" default(20) minimum(1) maximum(100)
-// @Param search query string false "Search query (searches name, full_name, description)"
-// @Success 200 {object} dto.RepositoryListResponse
-// @Failure 401 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Router /users/{id}/repos [get]
 func (h *RepositoryHandler) GetUserRepositories(c *gin.Context) {
 	userID := c.Param("id")
 
@@ -123,21 +125,119 @@ func (h *RepositoryHandler) GetUserRepositories(c *gin.Context) {
 	searchQuery := c.DefaultQuery("search", "")
 
 	// Fetch repositories using application service
-	response, err := h.repositoryService.GetRepositoriesByUserID(
-		c.Request.Context(),
-		userID,
-		searchQuery,
-		int32(page),
-		int32(limit),
-	)
+	var response *dto.RepositoryListResponse
+	var err error
+	if cursor := c.Query("cursor"); cursor != "" {
+		response, err = h.repositoryService.GetRepositoriesByUserIDAfter(c.Request.Context(), userID, searchQuery, cursor, int32(limit))
+	} else {
+		response, err = h.repositoryService.GetRepositoriesByUserID(
+			c.Request.Context(),
+			userID,
+			searchQuery,
+			int32(page),
+			int32(limit),
+		)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "fetch_failed",
-			Message: "Failed to fetch repositories",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, err)
+		return
+	}
+
+	respondCached(c, response.ETag, response)
+}
+
+// GetRepositoryBranches handles GET /repos/:id/branches
+func (h *RepositoryHandler) GetRepositoryBranches(c *gin.Context) {
+	userID, repositoryID, accessToken, ok := h.resolveRepositoryAccess(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.repositoryService.GetRepositoryBranches(c.Request.Context(), userID, repositoryID, accessToken)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRepositoryCommits handles GET /repos/:id/commits
+func (h *RepositoryHandler) GetRepositoryCommits(c *gin.Context) {
+	userID, repositoryID, accessToken, ok := h.resolveRepositoryAccess(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.repositoryService.GetRepositoryCommits(c.Request.Context(), userID, repositoryID, c.Query("branch"), accessToken)
+	if err != nil {
+		apierror.Abort(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// resolveRepositoryAccess returns the current user's ID, the repository in the path and the user's
+// access token for the repository's provider. Writes an error response and returns false on failure.
+func (h *RepositoryHandler) resolveRepositoryAccess(c *gin.Context) (string, string, string, bool) {
+	repositoryID := c.Param("id")
+
+	clerkUserData, exists := c.Get("user")
+	if !exists {
+		apierror.Abort(c, errNoUser)
+		return "", "", "", false
+	}
+
+	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
+	if !ok {
+		apierror.Abort(c, errUserType)
+		return "", "", "", false
+	}
+
+	dbUser, err := h.userService.GetOrCreateUserByClerkID(c.Request.Context(), clerkUser.ID)
+	if err != nil {
+		apierror.Abort(c, err)
+		return "", "", "", false
+	}
+
+	// The repository's provider decides which connected account is used
+	repository, err := h.repositoryService.GetRepository(c.Request.Context(), dbUser.ID, repositoryID)
+	if err != nil {
+		apierror.Abort(c, err)
+		return "", "", "", false
+	}
+
+	provider, err := repo.NewProvider(repository.Provider)
+	if err != nil {
+		apierror.Abort(c, err)
+		return "", "", "", false
+	}
+
+	accessToken, ok := h.getAccessToken(c, clerkUser.ID, provider)
+	if !ok {
+		return "", "", "", false
+	}
+
+	return dbUser.ID, repositoryID, accessToken, true
+}
+
+// getAccessToken fetches the user's OAuth token for a provider from Clerk.
+// Writes an error response and returns false if the user has not connected the provider.
+func (h *RepositoryHandler) getAccessToken(c *gin.Context, clerkUserID string, provider repo.Provider) (string, bool) {
+	accessToken, err := h.clerkClient.GetOAuthAccessToken(c.Request.Context(), clerkUserID, service.OAuthProviderName(provider))
+	if err != nil {
+		name := providerDisplayNames[provider]
+		apierror.Abort(c, apierror.New(http.StatusBadRequest, strings.ToLower(provider.String())+"_not_connected",
+			fmt.Sprintf("%s account not connected. Please connect your %s account in your user profile settings.", name, name)))
+		return "", false
+	}
+	return accessToken, true
+}
+
+// providerDisplayNames are the names of Git providers shown to users
+var providerDisplayNames = map[repo.Provider]string{
+	repo.ProviderGitHub:    "GitHub",
+	repo.ProviderGitLab:    "GitLab",
+	repo.ProviderBitbucket: "Bitbucket",
+}
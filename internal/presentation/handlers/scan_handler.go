@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScanHandler handles HTTP requests for the scans of deployment images
+type ScanHandler struct {
+	scanService *service.ImageScanService
+}
+
+// NewScanHandler creates a new scan handler
+func NewScanHandler(scanService *service.ImageScanService) *ScanHandler {
+	return &ScanHandler{
+		scanService: scanService,
+	}
+}
+
+// GetDeploymentScan handles GET /deployments/:id/scan
+func (h *ScanHandler) GetDeploymentScan(c *gin.Context) {
+	response, err := h.scanService.GetDeploymentScan(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetDeploymentSBOM handles GET /deployments/:id/scan/sbom
+func (h *ScanHandler) GetDeploymentSBOM(c *gin.Context) {
+	sbom, err := h.scanService.GetDeploymentSBOM(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.sbom.json"`, c.Param("id")))
+	c.Data(http.StatusOK, "application/json", sbom.Document)
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/shell"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// maxShellMessageBytes bounds the messages a terminal sends, a paste or a resize
+const maxShellMessageBytes = 64 * 1024
+
+// ShellHandler handles HTTP requests for interactive shells in projects' running containers
+type ShellHandler struct {
+	shellService *service.ShellService
+	userService  *service.UserService
+	upgrader     websocket.Upgrader
+}
+
+// NewShellHandler creates a new shell handler. Browsers may only connect terminals from the allowed origins.
+func NewShellHandler(shellService *service.ShellService, userService *service.UserService, allowedOrigins []string) *ShellHandler {
+	return &ShellHandler{
+		shellService: shellService,
+		userService:  userService,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  4096,
+			WriteBufferSize: 32 * 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				// Terminals outside a browser, such as the CLI, send no origin
+				origin := r.Header.Get("Origin")
+				return origin == "" || slices.Contains(allowedOrigins, origin)
+			},
+		},
+	}
+}
+
+// StartShell handles POST /projects/:id/shell
+func (h *ShellHandler) StartShell(c *gin.Context) {
+	userID, ok := currentUserID(c, h.userService)
+	if !ok {
+		return
+	}
+
+	var req dto.StartShellRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apierror.Abort(c, invalidBody(err))
+			return
+		}
+	}
+
+	response, err := h.shellService.StartSession(c.Request.Context(), c.Param("id"), userID, &req)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListShellSessions handles GET /projects/:id/shell
+func (h *ShellHandler) ListShellSessions(c *gin.Context) {
+	response, err := h.shellService.ListSessions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ConnectShell handles GET /shell/sessions/:id/connect
+func (h *ShellHandler) ConnectShell(c *gin.Context) {
+	var terminal *wsTerminal
+	session, err := h.shellService.AttachSession(c.Request.Context(), c.Param("id"), c.Query("token"), func() (service.Terminal, error) {
+		ws, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			return nil, err // The upgrader answered the request
+		}
+		// The server's timeouts are meant for requests, sessions are bounded by their own limits
+		_ = ws.NetConn().SetDeadline(time.Time{})
+		ws.SetReadLimit(maxShellMessageBytes)
+		terminal = &wsTerminal{ws: ws}
+		return terminal, nil
+	})
+
+	if terminal == nil {
+		if err != nil && !c.Writer.Written() {
+			apierror.Abort(c, err)
+		}
+		return
+	}
+	if err != nil {
+		terminal.close(websocket.CloseInternalServerErr, "Shell failed")
+		return
+	}
+	terminal.close(websocket.CloseNormalClosure, shellEndMessage(shell.EndReason(session.EndReason)))
+}
+
+// shellEndMessage tells the user why their session ended
+func shellEndMessage(reason shell.EndReason) string {
+	switch reason {
+	case shell.EndExited:
+		return "Shell exited"
+	case shell.EndIdleTimeout:
+		return "Session ended after being idle"
+	case shell.EndTimeLimit:
+		return "Session reached its time limit"
+	case shell.EndFailed:
+		return "Connection to the shell was lost"
+	default:
+		return "Session closed"
+	}
+}
+
+// wsTerminal is a terminal connected over a WebSocket
+type wsTerminal struct {
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+}
+
+// ReadInput returns what the user typed, sent as binary messages, or the terminal's new size, sent as a
+// resize text message. Other text messages are ignored.
+func (t *wsTerminal) ReadInput() ([]byte, int, int, error) {
+	for {
+		kind, data, err := t.ws.ReadMessage()
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if kind == websocket.BinaryMessage {
+			if len(data) == 0 {
+				continue
+			}
+			return data, 0, 0, nil
+		}
+
+		var control struct {
+			Type string `json:"type"`
+			Cols int    `json:"cols"`
+			Rows int    `json:"rows"`
+		}
+		if err := json.Unmarshal(data, &control); err != nil || control.Type != "resize" || control.Cols <= 0 || control.Rows <= 0 {
+			continue
+		}
+		return nil, control.Cols, control.Rows, nil
+	}
+}
+
+// Write sends what the shell wrote to the terminal
+func (t *wsTerminal) Write(p []byte) (int, error) {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if err := t.ws.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// close ends the WebSocket, telling the terminal why
+func (t *wsTerminal) close(code int, reason string) {
+	t.writeMu.Lock()
+	_ = t.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+	t.writeMu.Unlock()
+	t.ws.Close()
+}
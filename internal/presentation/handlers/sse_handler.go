@@ -3,6 +3,9 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -10,36 +13,191 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// SSEClient represents a connected SSE client
+const (
+	// sseClientBufferSize bounds the log lines queued for a client reading slower than they are broadcast
+	sseClientBufferSize = 256
+	// sseReplayBufferSize bounds the recent log lines kept per deployment for clients resuming a stream
+	sseReplayBufferSize = 500
+	// sseMaxReplayDeployments bounds the deployments whose recent log lines are kept, the least recently
+	// logging ones are evicted first
+	sseMaxReplayDeployments = 200
+)
+
+// SSEEvent is a log line broadcast for a deployment, numbered in the order it was broadcast
+type SSEEvent struct {
+	Seq  uint64
+	Line string
+}
+
+// SSEClient represents a connected SSE client. Broadcasts never wait for it: lines it hasn't read yet are
+// queued in a ring buffer, and once that is full the oldest are dropped and the client is marked as lagged.
 type SSEClient struct {
 	ID           string
 	DeploymentID string
-	Channel      chan string
 	Context      context.Context
+
+	mu      sync.Mutex
+	queue   []SSEEvent // Ring buffer of the lines not read yet
+	head    int
+	size    int
+	dropped int           // Lines dropped since the client last read
+	notify  chan struct{} // Signalled when lines are queued
+}
+
+// newSSEClient creates a client with an empty queue
+func newSSEClient(ctx context.Context, id, deploymentID string) *SSEClient {
+	return &SSEClient{
+		ID:           id,
+		DeploymentID: deploymentID,
+		Context:      ctx,
+		queue:        make([]SSEEvent, sseClientBufferSize),
+		notify:       make(chan struct{}, 1),
+	}
+}
+
+// push queues a line for the client, dropping the oldest queued line if the queue is full
+func (c *SSEClient) push(event SSEEvent) {
+	c.mu.Lock()
+	if c.size == len(c.queue) {
+		c.head = (c.head + 1) % len(c.queue)
+		c.size--
+		c.dropped++
+	}
+	c.queue[(c.head+c.size)%len(c.queue)] = event
+	c.size++
+	c.mu.Unlock()
+
+	select {
+	case c.notify <- struct{}{}:
+	default:
+		// Already signalled
+	}
+}
+
+// Notify returns a channel signalled when lines are queued for the client
+func (c *SSEClient) Notify() <-chan struct{} {
+	return c.notify
+}
+
+// Drain returns the queued lines and how many lines were dropped since the client last read
+func (c *SSEClient) Drain() ([]SSEEvent, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	events := make([]SSEEvent, c.size)
+	for i := range events {
+		events[i] = c.queue[(c.head+i)%len(c.queue)]
+	}
+	dropped := c.dropped
+	c.head, c.size, c.dropped = 0, 0, 0
+	return events, dropped
+}
+
+// replayBuffer keeps the recent log lines of a deployment so clients resuming a stream get the lines they
+// missed without reloading the whole log
+type replayBuffer struct {
+	events   []SSEEvent // Ring buffer of the most recent lines
+	head     int
+	size     int
+	lastSeq  uint64
+	lastUsed time.Time
+}
+
+// append numbers a line and keeps it, evicting the oldest line if the buffer is full
+func (b *replayBuffer) append(line string) SSEEvent {
+	b.lastSeq++
+	event := SSEEvent{Seq: b.lastSeq, Line: line}
+	if b.size == len(b.events) {
+		b.head = (b.head + 1) % len(b.events)
+		b.size--
+	}
+	b.events[(b.head+b.size)%len(b.events)] = event
+	b.size++
+	b.lastUsed = time.Now()
+	return event
+}
+
+// since returns the lines broadcast after seq, and false if some of them were already evicted or seq wasn't
+// broadcast yet
+func (b *replayBuffer) since(seq uint64) ([]SSEEvent, bool) {
+	if seq > b.lastSeq {
+		return nil, false
+	}
+	oldest := b.lastSeq - uint64(b.size) + 1
+	if seq+1 < oldest {
+		return nil, false
+	}
+
+	events := make([]SSEEvent, 0, b.lastSeq-seq)
+	for i := 0; i < b.size; i++ {
+		if event := b.events[(b.head+i)%len(b.events)]; event.Seq > seq {
+			events = append(events, event)
+		}
+	}
+	return events, true
+}
+
+// LogPublisher shares log lines with every API instance, which each deliver them to their own clients
+type LogPublisher interface {
+	PublishLog(deploymentID string, logLine string)
 }
 
 // SSEManager manages SSE connections
 type SSEManager struct {
-	clients map[string][]*SSEClient // deploymentID -> clients
-	mu      sync.RWMutex
+	clients   map[string][]*SSEClient  // deploymentID -> clients
+	replay    map[string]*replayBuffer // deploymentID -> recent log lines
+	epoch     string                   // Prefixes event IDs so IDs issued before a restart are never resumed from
+	delivered uint64                   // Lines delivered for every deployment, new replay buffers number on from it
+	publisher LogPublisher
+	mu        sync.Mutex
+
+	done     chan struct{} // Closed when the server shuts down
+	doneOnce sync.Once
 }
 
 // NewSSEManager creates a new SSE manager
 func NewSSEManager() *SSEManager {
 	return &SSEManager{
 		clients: make(map[string][]*SSEClient),
+		replay:  make(map[string]*replayBuffer),
+		epoch:   strconv.FormatInt(time.Now().UnixNano(), 36),
+		done:    make(chan struct{}),
 	}
 }
 
-// AddClient registers a new SSE client
-func (m *SSEManager) AddClient(deploymentID string, client *SSEClient) {
+// Subscribe registers a new SSE client for a deployment. If lastEventID is the ID of an event the client
+// already received, it also returns the lines broadcast since then and true; it returns false when those
+// lines are no longer kept and the client has to be sent the whole log.
+func (m *SSEManager) Subscribe(ctx context.Context, deploymentID, clientID, lastEventID string) (*SSEClient, []SSEEvent, bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if m.clients[deploymentID] == nil {
-		m.clients[deploymentID] = make([]*SSEClient, 0)
-	}
+	client := newSSEClient(ctx, clientID, deploymentID)
 	m.clients[deploymentID] = append(m.clients[deploymentID], client)
+
+	seq, ok := m.parseEventID(lastEventID)
+	if !ok {
+		return client, nil, false
+	}
+	buffer := m.replay[deploymentID]
+	if buffer == nil {
+		return client, nil, false
+	}
+	missed, ok := buffer.since(seq)
+	return client, missed, ok
+}
+
+// CanResume reports whether a client reconnecting with lastEventID can be sent only the lines it missed
+func (m *SSEManager) CanResume(deploymentID, lastEventID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seq, ok := m.parseEventID(lastEventID)
+	if !ok || m.replay[deploymentID] == nil {
+		return false
+	}
+	_, ok = m.replay[deploymentID].since(seq)
+	return ok
 }
 
 // RemoveClient removes an SSE client
@@ -50,7 +208,6 @@ func (m *SSEManager) RemoveClient(deploymentID string, clientID string) {
 	clients := m.clients[deploymentID]
 	for i, client := range clients {
 		if client.ID == clientID {
-			close(client.Channel)
 			m.clients[deploymentID] = append(clients[:i], clients[i+1:]...)
 			break
 		}
@@ -62,77 +219,162 @@ func (m *SSEManager) RemoveClient(deploymentID string, clientID string) {
 	}
 }
 
-// BroadcastLog sends a log line to all clients watching a deployment
+// SetPublisher shares broadcast log lines with the other API instances instead of only delivering them to the
+// clients of this one (optional). The publisher delivers lines back through DeliverLog.
+func (m *SSEManager) SetPublisher(publisher LogPublisher) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.publisher = publisher
+}
+
+// BroadcastLog sends a log line to all clients watching a deployment, on every instance when a publisher is set
 func (m *SSEManager) BroadcastLog(deploymentID string, logLine string) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	publisher := m.publisher
+	m.mu.Unlock()
 
-	clients := m.clients[deploymentID]
-	for _, client := range clients {
-		select {
-		case client.Channel <- logLine:
-			// Sent successfully
-		case <-time.After(1 * time.Second):
-			// Client is slow or disconnected, skip
+	if publisher != nil {
+		publisher.PublishLog(deploymentID, logLine)
+		return
+	}
+	m.DeliverLog(deploymentID, logLine)
+}
+
+// DeliverLog sends a log line to the clients of this instance watching a deployment and keeps it for clients
+// resuming later. It never waits for clients, slow ones drop lines instead.
+func (m *SSEManager) DeliverLog(deploymentID string, logLine string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buffer := m.replay[deploymentID]
+	if buffer == nil {
+		m.evictReplayBuffer()
+		// Numbering on from every line delivered, the IDs of an evicted buffer's lines never resume from its successor
+		buffer = &replayBuffer{events: make([]SSEEvent, sseReplayBufferSize), lastSeq: m.delivered}
+		m.replay[deploymentID] = buffer
+	}
+	m.delivered++
+	event := buffer.append(logLine)
+
+	for _, client := range m.clients[deploymentID] {
+		client.push(event)
+	}
+}
+
+// evictReplayBuffer drops the replay buffer of the least recently logging deployment once the limit is reached
+func (m *SSEManager) evictReplayBuffer() {
+	if len(m.replay) < sseMaxReplayDeployments {
+		return
+	}
+
+	var oldestID string
+	var oldest time.Time
+	for id, buffer := range m.replay {
+		if oldestID == "" || buffer.lastUsed.Before(oldest) {
+			oldestID, oldest = id, buffer.lastUsed
 		}
 	}
+	delete(m.replay, oldestID)
+}
+
+// EventID returns the SSE event ID of a broadcast line
+func (m *SSEManager) EventID(event SSEEvent) string {
+	return m.epoch + "-" + strconv.FormatUint(event.Seq, 10)
+}
+
+// parseEventID returns the sequence number of an event ID issued since the server started
+func (m *SSEManager) parseEventID(id string) (uint64, bool) {
+	epoch, seqStr, ok := strings.Cut(id, "-")
+	if !ok || epoch != m.epoch {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return seq, true
+}
+
+// Shutdown ends every stream with a shutdown event. Streams never go idle on their own, so the HTTP server
+// would otherwise wait for them until its shutdown times out.
+func (m *SSEManager) Shutdown() {
+	m.doneOnce.Do(func() {
+		close(m.done)
+	})
+}
+
+// Done returns a channel closed when the server shuts down
+func (m *SSEManager) Done() <-chan struct{} {
+	return m.done
 }
 
 // GetClientCount returns the number of clients watching a deployment
 func (m *SSEManager) GetClientCount(deploymentID string) int {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	return len(m.clients[deploymentID])
 }
 
+// writeSSEEvent writes an event with an ID, which browsers send back in Last-Event-ID when they reconnect
+func writeSSEEvent(w io.Writer, id, event, data string) {
+	fmt.Fprintf(w, "id: %s\nevent: %s\n", id, event)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	fmt.Fprint(w, "\n")
+}
+
 // StreamDeploymentLogs handles SSE streaming of deployment logs
-// @Summary Stream deployment logs
-// @Description Streams deployment logs in real-time using Server-Sent Events
-// @Tags Deployments
-// @Produce text/event-stream
-// @Param id path string true "Deployment ID"
-// @Param token query string false "Auth token (if not in header)"
-// @Success 200 {string} string "SSE stream"
-// @Router /deployments/{id}/logs/stream [get]
 func (h *DeploymentHandler) StreamDeploymentLogs(c *gin.Context) {
 	deploymentID := c.Param("id")
 
-	// DEBUG: Log SSE request
-	fmt.Printf("[SSE DEBUG] StreamDeploymentLogs called for deployment: %s\n", deploymentID)
-	fmt.Printf("[SSE DEBUG] Request path: %s\n", c.Request.URL.Path)
-	fmt.Printf("[SSE DEBUG] Request query: %s\n", c.Request.URL.RawQuery)
-	fmt.Printf("[SSE DEBUG] Authorization header: %s\n", c.GetHeader("Authorization"))
-	fmt.Printf("[SSE DEBUG] Token query param: %s\n", c.Query("token"))
+	slog.DebugContext(c.Request.Context(), "Log stream opened", "deployment_id", deploymentID)
+
+	streamLogs(c, deploymentID, func() []string {
+		// This ensures clients connecting mid-deployment see all logs
+		deployment, err := h.deploymentService.GetDeploymentByID(c.Request.Context(), deploymentID, false)
+		if err != nil || deployment.Logs == "" {
+			return nil
+		}
+		return strings.Split(deployment.Logs, "\n")
+	})
+}
 
+// streamLogs streams the lines broadcast for a stream ID to the client over SSE until it disconnects or
+// the server shuts down. Clients first get the lines logged before they connected, read with existing.
+func streamLogs(c *gin.Context, streamID string, existing func() []string) {
 	// Set SSE headers
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
 	c.Header("X-Accel-Buffering", "no") // Disable nginx buffering
-	c.Header("Access-Control-Allow-Origin", "*")
-	c.Header("Access-Control-Allow-Credentials", "true")
 
-	fmt.Printf("[SSE DEBUG] Headers set, creating SSE client\n")
+	// The log is loaded before the client is registered: lines broadcast while it is read would otherwise be
+	// queued for the client as well as read, and sent twice
+	lastEventID := c.GetHeader("Last-Event-ID")
+	var existingLines []string
+	loaded := !sseManager.CanResume(streamID, lastEventID)
+	if loaded {
+		existingLines = existing()
+	}
 
-	// Create client
 	clientID := fmt.Sprintf("client_%d", time.Now().UnixNano())
-	client := &SSEClient{
-		ID:           clientID,
-		DeploymentID: deploymentID,
-		Channel:      make(chan string, 100),
-		Context:      c.Request.Context(),
-	}
+	client, missed, resumed := sseManager.Subscribe(c.Request.Context(), streamID, clientID, lastEventID)
+	defer sseManager.RemoveClient(streamID, clientID)
 
-	// Register client
-	sseManager.AddClient(deploymentID, client)
-	defer sseManager.RemoveClient(deploymentID, clientID)
+	if !resumed && !loaded {
+		// The lines the client missed were evicted since, it gets the whole log
+		existingLines = existing()
+	}
 
-	// Send existing logs when client first connects
-	// This ensures clients connecting mid-deployment see all logs
-	deployment, err := h.deploymentService.GetDeploymentByID(c.Request.Context(), deploymentID)
-	if err == nil && deployment.Logs != "" {
-		// Send existing logs line by line
-		existingLines := strings.Split(deployment.Logs, "\n")
+	if resumed {
+		// Reconnecting clients only get the lines broadcast since the last one they received
+		for _, event := range missed {
+			writeSSEEvent(c.Writer, sseManager.EventID(event), "log", event.Line)
+		}
+		c.Writer.Flush()
+	} else if len(existingLines) > 0 {
+		// Send existing logs line by line when client first connects
 		for _, line := range existingLines {
 			if line != "" {
 				c.SSEvent("log", line)
@@ -150,9 +392,24 @@ func (h *DeploymentHandler) StreamDeploymentLogs(c *gin.Context) {
 		case <-c.Request.Context().Done():
 			// Client disconnected
 			return
-		case logLine := <-client.Channel:
-			// Send log line via SSE
-			c.SSEvent("log", logLine)
+		case <-sseManager.Done():
+			// Send the lines still queued, such as builds reporting their interruption, before ending the stream
+			events, _ := client.Drain()
+			for _, event := range events {
+				writeSSEEvent(c.Writer, sseManager.EventID(event), "log", event.Line)
+			}
+			c.SSEvent("shutdown", "server shutting down, reconnect to keep following the logs")
+			c.Writer.Flush()
+			return
+		case <-client.Notify():
+			events, dropped := client.Drain()
+			if dropped > 0 {
+				// The client fell behind and lost lines, it has to reload the log to see them
+				c.SSEvent("lagged", strconv.Itoa(dropped))
+			}
+			for _, event := range events {
+				writeSSEEvent(c.Writer, sseManager.EventID(event), "log", event.Line)
+			}
 			c.Writer.Flush()
 		case <-ticker.C:
 			// Send heartbeat to keep connection alive
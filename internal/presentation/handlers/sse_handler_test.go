@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSSEClient_DropsOldestLinesWhenFull(t *testing.T) {
+	client := newSSEClient(context.Background(), "client", "dep")
+	for seq := uint64(1); seq <= sseClientBufferSize+44; seq++ {
+		client.push(SSEEvent{Seq: seq, Line: fmt.Sprintf("line %d", seq)})
+	}
+
+	select {
+	case <-client.Notify():
+	default:
+		t.Fatal("Notify() wasn't signalled for queued lines")
+	}
+
+	events, dropped := client.Drain()
+	if dropped != 44 {
+		t.Errorf("Drain() dropped = %d, want 44 reported as lagged", dropped)
+	}
+	if len(events) != sseClientBufferSize {
+		t.Fatalf("Drain() returned %d lines, want %d", len(events), sseClientBufferSize)
+	}
+	for i, event := range events {
+		if want := uint64(45 + i); event.Seq != want {
+			t.Fatalf("Drain()[%d].Seq = %d, want the newest lines in order from %d", i, event.Seq, want)
+		}
+	}
+
+	// Draining empties the queue and resets the count of dropped lines
+	client.push(SSEEvent{Seq: 1000})
+	if events, dropped := client.Drain(); len(events) != 1 || dropped != 0 {
+		t.Errorf("Drain() = %d lines, %d dropped after draining, want 1 line and none dropped", len(events), dropped)
+	}
+}
+
+func TestReplayBuffer_Since(t *testing.T) {
+	buffer := &replayBuffer{events: make([]SSEEvent, 3)}
+	for _, line := range []string{"a", "b", "c", "d", "e"} {
+		buffer.append(line)
+	}
+
+	tests := []struct {
+		name   string
+		seq    uint64
+		want   []string
+		wantOK bool
+	}{
+		{"up to date", 5, []string{}, true},
+		{"missed the newest", 3, []string{"d", "e"}, true},
+		{"missed every kept line", 2, []string{"c", "d", "e"}, true},
+		{"missed evicted lines", 1, nil, false},
+		{"not broadcast yet", 6, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, ok := buffer.since(tt.seq)
+			if ok != tt.wantOK {
+				t.Fatalf("since(%d) ok = %v, want %v", tt.seq, ok, tt.wantOK)
+			}
+			if len(events) != len(tt.want) {
+				t.Fatalf("since(%d) = %v, want %v", tt.seq, events, tt.want)
+			}
+			for i, event := range events {
+				if event.Line != tt.want[i] {
+					t.Errorf("since(%d)[%d] = %q, want %q", tt.seq, i, event.Line, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSSEManager_ResumesFromLastEventID(t *testing.T) {
+	m := NewSSEManager()
+	m.DeliverLog("dep", "first")
+	client, _, _ := m.Subscribe(context.Background(), "dep", "a", "")
+	m.DeliverLog("dep", "second")
+	m.DeliverLog("dep", "third")
+
+	events, _ := client.Drain()
+	if len(events) != 2 {
+		t.Fatalf("client received %d lines, want 2", len(events))
+	}
+	lastEventID := m.EventID(events[0])
+
+	_, missed, resumed := m.Subscribe(context.Background(), "dep", "b", lastEventID)
+	if !resumed || len(missed) != 1 || missed[0].Line != "third" {
+		t.Errorf("Subscribe(%q) = %v, %v, want to resume with the third line", lastEventID, missed, resumed)
+	}
+	if !m.CanResume("dep", lastEventID) {
+		t.Errorf("CanResume(%q) = false, want true", lastEventID)
+	}
+
+	// IDs issued before a restart number other lines, the client gets the whole log
+	restarted := NewSSEManager()
+	restarted.epoch = m.epoch + "x"
+	restarted.DeliverLog("dep", "first")
+	restarted.DeliverLog("dep", "second")
+	if _, _, resumed := restarted.Subscribe(context.Background(), "dep", "c", lastEventID); resumed {
+		t.Errorf("Subscribe(%q) resumed from an ID of another epoch", lastEventID)
+	}
+	for _, id := range []string{"", "garbage", m.epoch + "-x"} {
+		if restarted.CanResume("dep", id) {
+			t.Errorf("CanResume(%q) = true, want false", id)
+		}
+	}
+}
+
+func TestSSEManager_EvictsLeastRecentlyLoggingDeployment(t *testing.T) {
+	m := NewSSEManager()
+	loggedAt := time.Now().Add(-time.Hour)
+	for i := 0; i < sseMaxReplayDeployments; i++ {
+		m.DeliverLog(fmt.Sprintf("dep-%d", i), "line")
+		// Clocks don't tell lines logged right after each other apart everywhere
+		m.replay[fmt.Sprintf("dep-%d", i)].lastUsed = loggedAt.Add(time.Duration(i) * time.Second)
+	}
+	client, _, _ := m.Subscribe(context.Background(), "dep-0", "a", "")
+	m.DeliverLog("dep-0", "again") // dep-1 is now the least recently logging
+	events, _ := client.Drain()
+	lastEventID := m.EventID(events[0])
+
+	m.DeliverLog("new", "line")
+	if len(m.replay) != sseMaxReplayDeployments {
+		t.Errorf("%d replay buffers kept, want at most %d", len(m.replay), sseMaxReplayDeployments)
+	}
+	if _, ok := m.replay["dep-1"]; ok {
+		t.Error("dep-1 was kept, want the least recently logging deployment evicted")
+	}
+	if _, ok := m.replay["dep-0"]; !ok {
+		t.Error("dep-0 was evicted, want recently logging deployments kept")
+	}
+
+	// Evicting dep-0 and logging for it again starts a new buffer, which the old IDs don't resume from
+	m.replay["dep-0"].lastUsed = loggedAt
+	for i := 0; i < sseMaxReplayDeployments; i++ {
+		m.DeliverLog(fmt.Sprintf("other-%d", i), "line")
+	}
+	m.DeliverLog("dep-0", "after eviction")
+	m.DeliverLog("dep-0", "more")
+	if m.CanResume("dep-0", lastEventID) {
+		t.Errorf("CanResume(%q) = true for the ID of an evicted buffer", lastEventID)
+	}
+}
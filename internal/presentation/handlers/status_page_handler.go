@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statusPageCacheControl lets browsers and CDNs cache status pages briefly, they are public and change slowly
+const statusPageCacheControl = "public, max-age=30"
+
+// StatusPageHandler handles HTTP requests for the public status pages of projects
+type StatusPageHandler struct {
+	statusPageService *service.StatusPageService
+}
+
+// NewStatusPageHandler creates a new status page handler
+func NewStatusPageHandler(statusPageService *service.StatusPageService) *StatusPageHandler {
+	return &StatusPageHandler{
+		statusPageService: statusPageService,
+	}
+}
+
+// GetStatusPage handles GET /public/projects/:slug/status
+func (h *StatusPageHandler) GetStatusPage(c *gin.Context) {
+	response, err := h.statusPageService.GetStatusPage(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", statusPageCacheControl)
+	c.JSON(http.StatusOK, response)
+}
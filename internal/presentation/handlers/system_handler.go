@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/middleware"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SystemHandler handles platform status and incident HTTP requests
+type SystemHandler struct {
+	systemStatusService *service.SystemStatusService
+}
+
+// NewSystemHandler creates a new system handler
+func NewSystemHandler(systemStatusService *service.SystemStatusService) *SystemHandler {
+	return &SystemHandler{
+		systemStatusService: systemStatusService,
+	}
+}
+
+// GetStatus handles GET /system/status
+func (h *SystemHandler) GetStatus(c *gin.Context) {
+	response, err := h.systemStatusService.GetStatus(c.Request.Context())
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateIncident handles POST /system/incidents
+func (h *SystemHandler) CreateIncident(c *gin.Context) {
+	clerkUserData, exists := c.Get("user")
+	if !exists {
+		apierror.Abort(c, errNoUser)
+		return
+	}
+
+	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
+	if !ok {
+		apierror.Abort(c, errUserType)
+		return
+	}
+
+	var req dto.CreateIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, invalidBody(err))
+		return
+	}
+
+	response, err := h.systemStatusService.CreateIncident(c.Request.Context(), clerkUser.ID, &req)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateIncident handles PUT /system/incidents/:id
+func (h *SystemHandler) UpdateIncident(c *gin.Context) {
+	var req dto.UpdateIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, invalidBody(err))
+		return
+	}
+
+	response, err := h.systemStatusService.UpdateIncident(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResolveIncident handles POST /system/incidents/:id/resolve
+func (h *SystemHandler) ResolveIncident(c *gin.Context) {
+	response, err := h.systemStatusService.ResolveIncident(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+
+	"snapdeploy-core/internal/application/dto"
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/clerk"
+	"snapdeploy-core/internal/middleware"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TemplateHandler handles the template catalog and creating projects from templates
+type TemplateHandler struct {
+	templateService *service.TemplateService
+	userService     *service.UserService
+	clerkClient     *clerk.Client
+}
+
+// NewTemplateHandler creates a new template handler
+func NewTemplateHandler(templateService *service.TemplateService, userService *service.UserService, clerkClient *clerk.Client) *TemplateHandler {
+	return &TemplateHandler{
+		templateService: templateService,
+		userService:     userService,
+		clerkClient:     clerkClient,
+	}
+}
+
+// ListTemplates handles GET /templates
+func (h *TemplateHandler) ListTemplates(c *gin.Context) {
+	response, err := h.templateService.ListTemplates(c.Request.Context())
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTemplate handles GET /templates/:template_id
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	response, err := h.templateService.GetTemplate(c.Request.Context(), c.Param("template_id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateProjectFromTemplate handles POST /projects/from-template/:template_id
+func (h *TemplateHandler) CreateProjectFromTemplate(c *gin.Context) {
+	// Get authenticated user from context
+	clerkUserData, exists := c.Get("user")
+	if !exists {
+		apierror.Abort(c, errNoUser)
+		return
+	}
+
+	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
+	if !ok {
+		apierror.Abort(c, errUserType)
+		return
+	}
+
+	// Get the internal user ID from Clerk ID
+	dbUser, err := h.userService.GetOrCreateUserByClerkID(c.Request.Context(), clerkUser.ID)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	var req dto.CreateProjectFromTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, invalidBody(err))
+		return
+	}
+
+	// Copying the template repository needs the user's GitHub account
+	var accessToken string
+	if req.RepositoryName != "" {
+		accessToken, err = h.clerkClient.GetOAuthAccessToken(c.Request.Context(), clerkUser.ID, clerk.ProviderGitHub)
+		if err != nil {
+			apierror.Abort(c, apierror.New(http.StatusBadRequest, "github_not_connected", "GitHub account not connected. Please connect your GitHub account in your user profile settings."))
+			return
+		}
+	}
+
+	response, err := h.templateService.CreateProjectFromTemplate(c.Request.Context(), dbUser.ID, c.Param("template_id"), accessToken, &req)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
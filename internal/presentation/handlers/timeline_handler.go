@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimelineHandler handles deployment timeline HTTP requests
+type TimelineHandler struct {
+	timelineService *service.TimelineService
+}
+
+// NewTimelineHandler creates a new timeline handler
+func NewTimelineHandler(timelineService *service.TimelineService) *TimelineHandler {
+	return &TimelineHandler{
+		timelineService: timelineService,
+	}
+}
+
+// GetDeploymentTimeline handles GET /deployments/:id/timeline
+func (h *TimelineHandler) GetDeploymentTimeline(c *gin.Context) {
+	response, err := h.timelineService.GetDeploymentTimeline(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/presentation/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageHandler handles usage reporting HTTP requests
+type UsageHandler struct {
+	usageService *service.UsageService
+}
+
+// NewUsageHandler creates a new usage handler
+func NewUsageHandler(usageService *service.UsageService) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+	}
+}
+
+// GetProjectUsage handles GET /projects/:id/usage
+func (h *UsageHandler) GetProjectUsage(c *gin.Context) {
+	from, to, err := parseUsagePeriod(c)
+	if err != nil {
+		apierror.Abort(c, apierror.Invalid("Invalid usage period", err))
+		return
+	}
+
+	response, err := h.usageService.GetProjectUsage(c.Request.Context(), c.Param("id"), from, to)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUserUsage handles GET /users/:id/usage
+func (h *UsageHandler) GetUserUsage(c *gin.Context) {
+	from, to, err := parseUsagePeriod(c)
+	if err != nil {
+		apierror.Abort(c, apierror.Invalid("Invalid usage period", err))
+		return
+	}
+
+	response, err := h.usageService.GetUserUsage(c.Request.Context(), c.Param("id"), from, to)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// parseUsagePeriod parses the from/to query parameters, defaulting to the current month
+func parseUsagePeriod(c *gin.Context) (time.Time, time.Time, error) {
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := now
+
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("from must be an RFC3339 timestamp: %w", err)
+		}
+		from = parsed
+	}
+
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("to must be an RFC3339 timestamp: %w", err)
+		}
+		to = parsed
+	}
+
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to must be after from")
+	}
+
+	return from, to, nil
+}
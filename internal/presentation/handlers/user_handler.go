@@ -1,66 +1,103 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 
 	"snapdeploy-core/internal/application/service"
+	"snapdeploy-core/internal/domain/job"
 	"snapdeploy-core/internal/middleware"
+	"snapdeploy-core/internal/presentation/apierror"
 
 	"github.com/gin-gonic/gin"
 )
 
 // UserHandler handles user-related HTTP requests
 type UserHandler struct {
-	userService *service.UserService
+	userService   *service.UserService
+	exportService *service.UserExportService
+	jobService    *service.JobService
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(userService *service.UserService) *UserHandler {
+func NewUserHandler(userService *service.UserService, exportService *service.UserExportService, jobService *service.JobService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:   userService,
+		exportService: exportService,
+		jobService:    jobService,
 	}
 }
 
 // GetCurrentUser handles GET /auth/me
-// @Summary Get current user information
-// @Description Returns information about the currently authenticated user
-// @Tags Authentication
-// @Accept json
-// @Produce json
-// @Security ClerkAuth
-// @Success 200 {object} dto.UserResponse
-// @Failure 401 {object} ErrorResponse
-// @Router /auth/me [get]
 func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 	// Get user from context (set by auth middleware)
 	clerkUser, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error:   "unauthorized",
-			Message: "User not found in context",
-		})
+		apierror.Abort(c, errNoUser)
 		return
 	}
 
 	user, ok := clerkUser.(*middleware.ClerkUser)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Invalid user type in context",
-		})
+		apierror.Abort(c, errUserType)
 		return
 	}
 
 	// Get or create user using application service
 	dbUser, err := h.userService.GetOrCreateUserByClerkID(c.Request.Context(), user.GetUserID())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "internal_error",
-			Message: "Failed to get user information",
-			Details: err.Error(),
-		})
+		apierror.Abort(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, dbUser)
 }
+
+// DeleteUser handles DELETE /users/:id
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	userID := c.Param("id")
+
+	clerkUserData, exists := c.Get("user")
+	if !exists {
+		apierror.Abort(c, errNoUser)
+		return
+	}
+
+	clerkUser, ok := clerkUserData.(*middleware.ClerkUser)
+	if !ok {
+		apierror.Abort(c, errUserType)
+		return
+	}
+
+	// Check the user exists before starting, so a typo isn't only reported by the job
+	if _, err := h.userService.GetUserByID(c.Request.Context(), userID); err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	// Tearing down the projects takes longer than proxies allow a request to run
+	response, err := h.jobService.Submit(c.Request.Context(), clerkUser.ID, job.KindUserDeletion, func(ctx context.Context) (interface{}, error) {
+		return nil, h.userService.DeleteUser(ctx, userID)
+	})
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/jobs/"+response.ID)
+	c.JSON(http.StatusAccepted, response)
+}
+
+// ExportUserData handles GET /users/:id/export
+func (h *UserHandler) ExportUserData(c *gin.Context) {
+	userID := c.Param("id")
+
+	response, err := h.exportService.ExportUserData(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="snapdeploy-export-`+response.User.ID+`.json"`)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: PerMinute tokens are added every minute, up to Burst.
+// Each request takes one token, so up to Burst requests can be made at once.
+type Limit struct {
+	PerMinute int
+	Burst     int
+}
+
+// Enabled reports whether requests are limited at all
+func (l Limit) Enabled() bool {
+	return l.PerMinute > 0
+}
+
+func (l Limit) burst() int {
+	if l.Burst <= 0 {
+		return l.PerMinute
+	}
+	return l.Burst
+}
+
+func (l Limit) perSecond() float64 {
+	return float64(l.PerMinute) / 60
+}
+
+// Result is the outcome of taking a token
+type Result struct {
+	Allowed    bool
+	Limit      int           // tokens in a full bucket
+	Remaining  int           // tokens left after this request
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next token, if the request was not allowed
+}
+
+// Store keeps the token buckets. A shared store, such as Redis, lets several instances enforce one limit.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// pruneInterval is how often buckets that have refilled are dropped from a MemoryStore
+const pruneInterval = time.Minute
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	limit     Limit
+}
+
+// MemoryStore keeps token buckets in memory, limiting requests to a single instance
+type MemoryStore struct {
+	mu       sync.Mutex
+	buckets  map[string]*bucket
+	prunedAt time.Time
+	now      func() time.Time // Tests replace it to control refills
+}
+
+// NewMemoryStore creates an in-memory token bucket store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Take takes a token from the key's bucket, if one is left
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	if !limit.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.pruneLocked(now)
+
+	burst := float64(limit.burst())
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, updatedAt: now}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+
+	// Refill for the time since the bucket was last used
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.updatedAt).Seconds()*limit.perSecond())
+	b.updatedAt = now
+
+	result := Result{Limit: limit.burst()}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / limit.perSecond())
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = secondsToDuration((burst - b.tokens) / limit.perSecond())
+
+	return result, nil
+}
+
+// pruneLocked drops buckets that have refilled, as they behave like new ones
+func (s *MemoryStore) pruneLocked(now time.Time) {
+	if now.Sub(s.prunedAt) < pruneInterval {
+		return
+	}
+	s.prunedAt = now
+
+	for key, b := range s.buckets {
+		refill := (float64(b.limit.burst()) - b.tokens) / b.limit.perSecond()
+		if now.Sub(b.updatedAt).Seconds() >= refill {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
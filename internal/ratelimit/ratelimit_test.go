@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock tests move by hand
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestStore() (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	return store, clock
+}
+
+func take(t *testing.T, store *MemoryStore, key string, limit Limit) Result {
+	t.Helper()
+	result, err := store.Take(context.Background(), key, limit)
+	if err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+	return result
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	store, clock := newTestStore()
+	limit := Limit{PerMinute: 60, Burst: 3} // A token a second
+
+	// A full bucket allows a burst, each request counting down the remaining tokens
+	for i, want := range []Result{
+		{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second},
+		{Allowed: true, Limit: 3, Remaining: 1, Reset: 2 * time.Second},
+		{Allowed: true, Limit: 3, Remaining: 0, Reset: 3 * time.Second},
+		{Allowed: false, Limit: 3, Remaining: 0, Reset: 3 * time.Second, RetryAfter: time.Second},
+	} {
+		if got := take(t, store, "user", limit); got != want {
+			t.Errorf("Take() #%d = %+v, want %+v", i+1, got, want)
+		}
+	}
+
+	// Half a token has been added, which isn't enough for a request
+	clock.Advance(500 * time.Millisecond)
+	want := Result{Allowed: false, Limit: 3, Remaining: 0, Reset: 2500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}
+	if got := take(t, store, "user", limit); got != want {
+		t.Errorf("Take() half a second later = %+v, want %+v", got, want)
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	want = Result{Allowed: true, Limit: 3, Remaining: 0, Reset: 3 * time.Second}
+	if got := take(t, store, "user", limit); got != want {
+		t.Errorf("Take() a second later = %+v, want %+v", got, want)
+	}
+
+	// Refills stop at the burst
+	clock.Advance(time.Hour)
+	want = Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second}
+	if got := take(t, store, "user", limit); got != want {
+		t.Errorf("Take() after an hour = %+v, want %+v", got, want)
+	}
+}
+
+func TestMemoryStore_TakeKeepsBucketsApart(t *testing.T) {
+	store, _ := newTestStore()
+	limit := Limit{PerMinute: 1, Burst: 1}
+
+	if got := take(t, store, "a", limit); !got.Allowed {
+		t.Fatalf("Take(a) = %+v, want allowed", got)
+	}
+	if got := take(t, store, "a", limit); got.Allowed {
+		t.Errorf("Take(a) again = %+v, want denied", got)
+	}
+	if got := take(t, store, "b", limit); !got.Allowed {
+		t.Errorf("Take(b) = %+v, want a bucket of its own", got)
+	}
+}
+
+func TestMemoryStore_TakeDefaultsBurstToPerMinute(t *testing.T) {
+	store, _ := newTestStore()
+	limit := Limit{PerMinute: 2}
+
+	for i := 0; i < 2; i++ {
+		if got := take(t, store, "user", limit); !got.Allowed || got.Limit != 2 {
+			t.Fatalf("Take() #%d = %+v, want allowed with a limit of 2", i+1, got)
+		}
+	}
+	if got := take(t, store, "user", limit); got.Allowed || got.RetryAfter != 30*time.Second {
+		t.Errorf("Take() #3 = %+v, want denied for 30s", got)
+	}
+}
+
+func TestMemoryStore_TakeDisabled(t *testing.T) {
+	store, _ := newTestStore()
+	for i := 0; i < 10; i++ {
+		if got := take(t, store, "user", Limit{}); !got.Allowed {
+			t.Fatalf("Take() #%d = %+v, want every request allowed without a limit", i+1, got)
+		}
+	}
+	if len(store.buckets) != 0 {
+		t.Errorf("%d buckets kept, want none without a limit", len(store.buckets))
+	}
+}
+
+func TestMemoryStore_PrunesRefilledBuckets(t *testing.T) {
+	store, clock := newTestStore()
+	limit := Limit{PerMinute: 60, Burst: 60}
+
+	take(t, store, "refilled", limit)
+	clock.Advance(pruneInterval - time.Second)
+	for i := 0; i < 30; i++ {
+		take(t, store, "busy", limit)
+	}
+
+	// At the next prune, "refilled" is full again and "busy" is still refilling
+	clock.Advance(time.Second)
+	take(t, store, "other", limit)
+	if _, ok := store.buckets["refilled"]; ok {
+		t.Error("refilled bucket was kept")
+	}
+	if _, ok := store.buckets["busy"]; !ok {
+		t.Error("refilling bucket was dropped")
+	}
+}
@@ -0,0 +1,153 @@
+package stripe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"snapdeploy-core/internal/config"
+)
+
+// Client is a Stripe API client for the few endpoints billing uses
+type Client struct {
+	apiURL     string
+	secretKey  string
+	httpClient *http.Client
+}
+
+// NewClient creates a new Stripe API client
+func NewClient(cfg *config.BillingConfig) *Client {
+	return &Client{
+		apiURL:    strings.TrimSuffix(cfg.StripeAPIURL, "/"),
+		secretKey: cfg.StripeSecretKey,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// APIError is an error response of the Stripe API
+type APIError struct {
+	StatusCode int
+	Type       string `json:"type"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("stripe API error: %d %s (%s) - %s", e.StatusCode, e.Type, e.Code, e.Message)
+	}
+	return fmt.Sprintf("stripe API error: %d %s - %s", e.StatusCode, e.Type, e.Message)
+}
+
+// Customer is a Stripe customer
+type Customer struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+}
+
+// PortalSession is a customer portal session, valid for a short while
+type PortalSession struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// CreateCustomer creates a customer. Retries with the same idempotency key return the customer created first.
+func (c *Client) CreateCustomer(ctx context.Context, email, name string, metadata map[string]string, idempotencyKey string) (*Customer, error) {
+	form := url.Values{}
+	form.Set("email", email)
+	if name != "" {
+		form.Set("name", name)
+	}
+	for key, value := range metadata {
+		form.Set("metadata["+key+"]", value)
+	}
+
+	var customer Customer
+	if err := c.post(ctx, "/v1/customers", form, idempotencyKey, &customer); err != nil {
+		return nil, fmt.Errorf("failed to create customer: %w", err)
+	}
+	return &customer, nil
+}
+
+// CreatePortalSession creates a session of the customer portal, which sends the customer to returnURL when
+// they leave it
+func (c *Client) CreatePortalSession(ctx context.Context, customerID, returnURL string) (*PortalSession, error) {
+	form := url.Values{}
+	form.Set("customer", customerID)
+	if returnURL != "" {
+		form.Set("return_url", returnURL)
+	}
+
+	var session PortalSession
+	if err := c.post(ctx, "/v1/billing_portal/sessions", form, "", &session); err != nil {
+		return nil, fmt.Errorf("failed to create portal session: %w", err)
+	}
+	return &session, nil
+}
+
+// CreateMeterEvent adds value to the meter listening for eventName of a customer. Stripe counts events with the
+// same identifier once.
+func (c *Client) CreateMeterEvent(ctx context.Context, eventName, customerID string, value int64, identifier string, at time.Time) error {
+	form := url.Values{}
+	form.Set("event_name", eventName)
+	form.Set("payload[stripe_customer_id]", customerID)
+	form.Set("payload[value]", strconv.FormatInt(value, 10))
+	form.Set("identifier", identifier)
+	form.Set("timestamp", strconv.FormatInt(at.Unix(), 10))
+
+	if err := c.post(ctx, "/v1/billing/meter_events", form, "", nil); err != nil {
+		return fmt.Errorf("failed to create meter event: %w", err)
+	}
+	return nil
+}
+
+// post sends a form-encoded request and decodes the response into out, if set
+func (c *Client) post(ctx context.Context, path string, form url.Values, idempotencyKey string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.secretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var errResp struct {
+			Error APIError `json:"error"`
+		}
+		if err := json.Unmarshal(body, &errResp); err != nil || errResp.Error.Message == "" {
+			return fmt.Errorf("stripe API error: %d - %s", resp.StatusCode, string(body))
+		}
+		errResp.Error.StatusCode = resp.StatusCode
+		return &errResp.Error
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
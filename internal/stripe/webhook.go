@@ -0,0 +1,119 @@
+package stripe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event types billing handles
+const (
+	EventSubscriptionCreated  = "customer.subscription.created"
+	EventSubscriptionUpdated  = "customer.subscription.updated"
+	EventSubscriptionDeleted  = "customer.subscription.deleted"
+	EventInvoicePaid          = "invoice.paid"
+	EventInvoicePaymentFailed = "invoice.payment_failed"
+)
+
+// signatureTolerance is how old a signed webhook may be, so captured requests can't be replayed later
+const signatureTolerance = 5 * time.Minute
+
+// ErrInvalidSignature is returned when a webhook payload was not signed with the webhook secret
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Event is a webhook event. Data.Object holds the object the event is about, such as a Subscription or Invoice.
+type Event struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// Subscription is a customer's subscription to one or more prices
+type Subscription struct {
+	ID       string `json:"id"`
+	Customer string `json:"customer"`
+	Status   string `json:"status"`
+	Items    struct {
+		Data []struct {
+			Price struct {
+				ID string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// PriceIDs returns the prices the subscription is to
+func (s *Subscription) PriceIDs() []string {
+	ids := make([]string, 0, len(s.Items.Data))
+	for _, item := range s.Items.Data {
+		ids = append(ids, item.Price.ID)
+	}
+	return ids
+}
+
+// Entitled reports whether the subscription grants its plan. Past due subscriptions keep it while Stripe
+// retries the payment; failed payments are handled by their invoice events.
+func (s *Subscription) Entitled() bool {
+	switch s.Status {
+	case "active", "trialing", "past_due":
+		return true
+	default:
+		return false
+	}
+}
+
+// Invoice is a bill sent to a customer
+type Invoice struct {
+	ID           string `json:"id"`
+	Customer     string `json:"customer"`
+	AttemptCount int    `json:"attempt_count"`
+}
+
+// VerifyWebhookSignature checks the Stripe-Signature header of a webhook against its payload
+func VerifyWebhookSignature(secret string, payload []byte, header string, now time.Time) error {
+	if secret == "" {
+		return ErrInvalidSignature
+	}
+
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			if signature, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expected) {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Add lifecycle status to projects so long-running operations (e.g. teardown) can report progress
+ALTER TABLE projects ADD COLUMN status VARCHAR(50) NOT NULL DEFAULT 'ACTIVE' CHECK (
+    status IN ('ACTIVE', 'DELETING', 'DELETE_FAILED')
+);
+ALTER TABLE projects ADD COLUMN status_message TEXT;
+
+CREATE INDEX idx_projects_status ON projects (status);
+
+COMMENT ON COLUMN projects.status IS 'Lifecycle status of the project (ACTIVE, DELETING, DELETE_FAILED)';
+COMMENT ON COLUMN projects.status_message IS 'Human readable progress or error message for the current status';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_projects_status;
+ALTER TABLE projects DROP COLUMN IF EXISTS status_message;
+ALTER TABLE projects DROP COLUMN IF EXISTS status;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- Create system_incidents table for operator-posted incidents and maintenance notices
+CREATE TABLE system_incidents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('INCIDENT', 'MAINTENANCE')),
+    severity VARCHAR(20) NOT NULL CHECK (severity IN ('INFO', 'WARNING', 'CRITICAL')),
+    title TEXT NOT NULL,
+    message TEXT NOT NULL,
+    starts_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ends_at TIMESTAMP,
+    resolved_at TIMESTAMP,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for the status page queries
+CREATE INDEX idx_system_incidents_resolved_at ON system_incidents(resolved_at);
+CREATE INDEX idx_system_incidents_starts_at ON system_incidents(starts_at);
+
+-- Add comments
+COMMENT ON TABLE system_incidents IS 'Platform incidents and maintenance notices shown on the status page';
+COMMENT ON COLUMN system_incidents.ends_at IS 'Scheduled end of a maintenance window (NULL if open-ended)';
+COMMENT ON COLUMN system_incidents.created_by IS 'Clerk user ID of the operator who posted the notice';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_system_incidents_starts_at;
+DROP INDEX IF EXISTS idx_system_incidents_resolved_at;
+DROP TABLE IF EXISTS system_incidents;
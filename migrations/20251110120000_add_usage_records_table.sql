@@ -0,0 +1,27 @@
+-- +goose Up
+-- Create usage_records table for metering compute and build usage per project
+CREATE TABLE usage_records (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    deployment_id UUID,
+    metric VARCHAR(50) NOT NULL CHECK (metric IN ('VCPU_HOURS', 'MEMORY_GB_HOURS', 'BUILD_MINUTES')),
+    quantity DOUBLE PRECISION NOT NULL CHECK (quantity >= 0),
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for usage summaries
+CREATE INDEX idx_usage_records_project_period ON usage_records(project_id, period_end);
+CREATE INDEX idx_usage_records_user_period ON usage_records(user_id, period_end);
+
+-- Add comments
+COMMENT ON TABLE usage_records IS 'Metered compute and build usage, kept after project deletion for billing';
+COMMENT ON COLUMN usage_records.project_id IS 'Project the usage belongs to (no foreign key so usage survives project deletion)';
+COMMENT ON COLUMN usage_records.quantity IS 'Amount of the metric consumed between period_start and period_end';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_usage_records_user_period;
+DROP INDEX IF EXISTS idx_usage_records_project_period;
+DROP TABLE IF EXISTS usage_records;
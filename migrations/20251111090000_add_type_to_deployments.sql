@@ -0,0 +1,10 @@
+-- +goose Up
+-- Distinguish full build deployments from restarts of the running image
+ALTER TABLE deployments ADD COLUMN type VARCHAR(50) NOT NULL DEFAULT 'BUILD' CHECK (
+    type IN ('BUILD', 'RESTART')
+);
+
+COMMENT ON COLUMN deployments.type IS 'Kind of deployment (BUILD, RESTART)';
+
+-- +goose Down
+ALTER TABLE deployments DROP COLUMN IF EXISTS type;
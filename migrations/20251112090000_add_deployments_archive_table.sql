@@ -0,0 +1,33 @@
+-- +goose Up
+-- Create deployments_archive table for finished deployments moved out of the active table.
+-- Columns match deployments so history queries can read both tables with UNION ALL.
+CREATE TABLE deployments_archive (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    commit_hash VARCHAR(40) NOT NULL,
+    branch VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    logs TEXT DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    type VARCHAR(50) NOT NULL DEFAULT 'BUILD'
+);
+
+-- Create indexes for deployment history
+CREATE INDEX idx_deployments_archive_project_created ON deployments_archive (project_id, created_at DESC);
+CREATE INDEX idx_deployments_archive_user_created ON deployments_archive (user_id, created_at DESC);
+
+-- Add comments
+COMMENT ON TABLE deployments_archive IS 'Finished deployments older than DEPLOYMENT_ARCHIVE_AFTER_MONTHS, read only by history queries';
+
+-- +goose Down
+-- Move archived deployments back so no history is lost
+INSERT INTO deployments (id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type)
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type
+FROM deployments_archive
+ON CONFLICT (id) DO NOTHING;
+
+DROP INDEX IF EXISTS idx_deployments_archive_user_created;
+DROP INDEX IF EXISTS idx_deployments_archive_project_created;
+DROP TABLE IF EXISTS deployments_archive;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Create deployment_events table for the platform timeline of each deployment
+CREATE TABLE deployment_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    deployment_id UUID NOT NULL,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    source VARCHAR(50) NOT NULL CHECK (source IN ('PLATFORM', 'MIGRATION')),
+    type VARCHAR(100) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create index for reading a deployment's timeline
+CREATE INDEX idx_deployment_events_deployment ON deployment_events(deployment_id, occurred_at);
+
+-- Add comments
+COMMENT ON TABLE deployment_events IS 'Platform timeline of deployments (phase changes and migration results)';
+COMMENT ON COLUMN deployment_events.deployment_id IS 'Deployment the event belongs to (no foreign key so events survive archiving)';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_deployment_events_deployment;
+DROP TABLE IF EXISTS deployment_events;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Create github_installations table for GitHub App installations on users' accounts
+CREATE TABLE github_installations (
+    id BIGINT PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    account_id BIGINT NOT NULL,
+    account_login VARCHAR(255) NOT NULL,
+    account_type VARCHAR(50) NOT NULL,
+    installed_by_id BIGINT NOT NULL DEFAULT 0,
+    suspended BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index for listing a user's installations
+CREATE INDEX idx_github_installations_user_id ON github_installations(user_id);
+
+-- Add comments
+COMMENT ON TABLE github_installations IS 'GitHub App installations used to sync and clone repositories without user tokens';
+COMMENT ON COLUMN github_installations.id IS 'GitHub installation ID';
+COMMENT ON COLUMN github_installations.user_id IS 'SnapDeploy user who claimed the installation, NULL until claimed';
+COMMENT ON COLUMN github_installations.installed_by_id IS 'GitHub user ID of who installed the app, 0 until the installation webhook arrives';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_github_installations_user_id;
+DROP TABLE IF EXISTS github_installations;
@@ -0,0 +1,34 @@
+-- +goose Up
+-- Repositories can come from GitLab and Bitbucket as well as GitHub. Bitbucket identifies
+-- repositories by UUID, so the numeric github_id becomes a textual external_id.
+ALTER TABLE repositories
+    ADD COLUMN provider VARCHAR(20) NOT NULL DEFAULT 'GITHUB'
+        CHECK (provider IN ('GITHUB', 'GITLAB', 'BITBUCKET')),
+    ADD COLUMN external_id VARCHAR(255);
+
+UPDATE repositories SET external_id = github_id::TEXT;
+
+ALTER TABLE repositories ALTER COLUMN external_id SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_repositories_github_id_user_id;
+ALTER TABLE repositories DROP COLUMN github_id;
+
+CREATE UNIQUE INDEX idx_repositories_provider_external_id_user_id ON repositories (provider, external_id, user_id);
+
+-- Add comments
+COMMENT ON COLUMN repositories.provider IS 'Git hosting service: GITHUB, GITLAB or BITBUCKET';
+COMMENT ON COLUMN repositories.external_id IS 'Repository ID at the provider';
+
+-- +goose Down
+-- Only GitHub repositories can be represented by the old schema
+DELETE FROM repositories WHERE provider <> 'GITHUB';
+
+ALTER TABLE repositories ADD COLUMN github_id BIGINT;
+UPDATE repositories SET github_id = external_id::BIGINT;
+ALTER TABLE repositories ALTER COLUMN github_id SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_repositories_provider_external_id_user_id;
+ALTER TABLE repositories DROP COLUMN external_id;
+ALTER TABLE repositories DROP COLUMN provider;
+
+CREATE UNIQUE INDEX idx_repositories_github_id_user_id ON repositories (github_id, user_id);
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Create build_jobs table, the outbox of builds waiting to be started by a build worker
+CREATE TABLE build_jobs (
+    deployment_id UUID PRIMARY KEY REFERENCES deployments(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'RUNNING', 'DONE', 'FAILED')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    claimed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create index for workers claiming the oldest open job
+CREATE INDEX idx_build_jobs_open ON build_jobs(created_at) WHERE status IN ('PENDING', 'RUNNING');
+
+-- Add comments
+COMMENT ON TABLE build_jobs IS 'Builds waiting to be started, claimed by build workers so a restart never loses a deployment';
+COMMENT ON COLUMN build_jobs.status IS 'Job status (PENDING, RUNNING, DONE, FAILED); DONE once the build has been started';
+COMMENT ON COLUMN build_jobs.attempts IS 'Number of times a worker has claimed the job';
+COMMENT ON COLUMN build_jobs.claimed_at IS 'When a worker last claimed the job; RUNNING jobs with an expired claim are claimed again';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_build_jobs_open;
+DROP TABLE IF EXISTS build_jobs;
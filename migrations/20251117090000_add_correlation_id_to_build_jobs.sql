@@ -0,0 +1,8 @@
+-- +goose Up
+-- Keep the correlation ID of the request that queued a build so its logs can be followed into the build worker
+ALTER TABLE build_jobs ADD COLUMN correlation_id VARCHAR(128) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN build_jobs.correlation_id IS 'Correlation ID of the request that queued the build';
+
+-- +goose Down
+ALTER TABLE build_jobs DROP COLUMN IF EXISTS correlation_id;
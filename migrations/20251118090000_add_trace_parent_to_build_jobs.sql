@@ -0,0 +1,8 @@
+-- +goose Up
+-- Keep the trace context of the request that queued a build so the build worker continues its trace
+ALTER TABLE build_jobs ADD COLUMN trace_parent VARCHAR(128) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN build_jobs.trace_parent IS 'W3C traceparent of the request that queued the build';
+
+-- +goose Down
+ALTER TABLE build_jobs DROP COLUMN IF EXISTS trace_parent;
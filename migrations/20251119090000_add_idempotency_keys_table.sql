@@ -0,0 +1,26 @@
+-- +goose Up
+-- Create idempotency_keys table, the responses replayed to clients retrying a request with the same Idempotency-Key
+CREATE TABLE idempotency_keys (
+    owner_id VARCHAR(255) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    route VARCHAR(100) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code INTEGER,
+    response_body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (owner_id, idempotency_key)
+);
+
+-- Create index for purging expired keys
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- Add comments
+COMMENT ON TABLE idempotency_keys IS 'Responses to requests made with an Idempotency-Key, replayed when the client retries';
+COMMENT ON COLUMN idempotency_keys.owner_id IS 'Clerk user ID of the caller; keys are scoped per user';
+COMMENT ON COLUMN idempotency_keys.request_hash IS 'SHA-256 of the request method, path and body; a retry must match it';
+COMMENT ON COLUMN idempotency_keys.status_code IS 'Response status, NULL while the original request is in flight';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_idempotency_keys_expires_at;
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,55 @@
+-- +goose Up
+-- Soft delete projects and deployments; the retention job purges them RETENTION_PURGE_AFTER_DAYS after deletion
+ALTER TABLE projects ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE deployments ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE deployments_archive ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Deleted projects no longer hold their repository URL or custom domain
+DROP INDEX IF EXISTS idx_projects_user_repository;
+CREATE UNIQUE INDEX idx_projects_user_repository ON projects (user_id, repository_url)
+WHERE
+    deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_projects_custom_domain;
+CREATE UNIQUE INDEX idx_projects_custom_domain ON projects (custom_domain)
+WHERE
+    custom_domain != ''
+    AND deleted_at IS NULL;
+
+-- Create indexes for the retention job
+CREATE INDEX idx_projects_deleted_at ON projects (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_deployments_deleted_at ON deployments (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_deployments_archive_deleted_at ON deployments_archive (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Add comments
+COMMENT ON COLUMN projects.deleted_at IS 'When the project was deleted, NULL while it exists; only operators can read deleted projects';
+COMMENT ON COLUMN deployments.deleted_at IS 'When the deployment was deleted, NULL while it exists; only operators can read deleted deployments';
+COMMENT ON COLUMN deployments_archive.deleted_at IS 'When the deployment was deleted, NULL while it exists';
+
+-- +goose Down
+-- Soft deleted rows are removed for good, as they would have been before
+DELETE FROM deployment_events
+WHERE deployment_id IN (
+    SELECT id FROM deployments WHERE deleted_at IS NOT NULL
+    UNION ALL
+    SELECT id FROM deployments_archive WHERE deleted_at IS NOT NULL
+);
+DELETE FROM deployments_archive WHERE deleted_at IS NOT NULL;
+DELETE FROM deployments WHERE deleted_at IS NOT NULL;
+DELETE FROM projects WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_deployments_archive_deleted_at;
+DROP INDEX IF EXISTS idx_deployments_deleted_at;
+DROP INDEX IF EXISTS idx_projects_deleted_at;
+
+DROP INDEX IF EXISTS idx_projects_custom_domain;
+CREATE UNIQUE INDEX idx_projects_custom_domain ON projects (custom_domain)
+WHERE
+    custom_domain != '';
+
+DROP INDEX IF EXISTS idx_projects_user_repository;
+CREATE UNIQUE INDEX idx_projects_user_repository ON projects (user_id, repository_url);
+
+ALTER TABLE deployments_archive DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE deployments DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE projects DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Let projects choose how many of their deployments keep their container images
+ALTER TABLE projects ADD COLUMN image_retention INTEGER NOT NULL DEFAULT 0;
+
+-- Add comments
+COMMENT ON COLUMN projects.image_retention IS 'Number of recent deployments whose images are kept by image cleanup, 0 for the platform default';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS image_retention;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Let projects choose how a new version replaces the running one
+ALTER TABLE projects ADD COLUMN deployment_strategy VARCHAR(20) NOT NULL DEFAULT 'ROLLING'
+    CHECK (deployment_strategy IN ('ROLLING', 'BLUE_GREEN', 'RECREATE'));
+
+-- Add comments
+COMMENT ON COLUMN projects.deployment_strategy IS 'How new versions replace running ones (ROLLING, BLUE_GREEN, RECREATE)';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS deployment_strategy;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Let projects deploy through a canary that receives a share of the traffic before it is promoted
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_deployment_strategy_check;
+ALTER TABLE projects ADD CONSTRAINT projects_deployment_strategy_check
+    CHECK (deployment_strategy IN ('ROLLING', 'BLUE_GREEN', 'RECREATE', 'CANARY'));
+
+ALTER TABLE projects ADD COLUMN canary_percent INTEGER NOT NULL DEFAULT 10;
+ALTER TABLE projects ADD COLUMN canary_bake_minutes INTEGER NOT NULL DEFAULT 10;
+
+-- Add comments
+COMMENT ON COLUMN projects.canary_percent IS 'Percentage of traffic routed to the canary of a CANARY deployment';
+COMMENT ON COLUMN projects.canary_bake_minutes IS 'Minutes the canary of a CANARY deployment is watched before it is promoted';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS canary_bake_minutes;
+ALTER TABLE projects DROP COLUMN IF EXISTS canary_percent;
+
+UPDATE projects SET deployment_strategy = 'ROLLING' WHERE deployment_strategy = 'CANARY';
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_deployment_strategy_check;
+ALTER TABLE projects ADD CONSTRAINT projects_deployment_strategy_check
+    CHECK (deployment_strategy IN ('ROLLING', 'BLUE_GREEN', 'RECREATE'));
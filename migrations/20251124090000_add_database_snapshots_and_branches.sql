@@ -0,0 +1,43 @@
+-- +goose Up
+-- Create database_snapshots table, frozen copies of project databases that branches are restored from
+CREATE TABLE database_snapshots (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    database_name VARCHAR(63) NOT NULL UNIQUE,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create database_branches table, ephemeral databases restored from snapshots for preview environments
+CREATE TABLE database_branches (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    snapshot_id UUID REFERENCES database_snapshots(id) ON DELETE SET NULL,
+    name VARCHAR(40) NOT NULL,
+    database_name VARCHAR(63) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE (project_id, name)
+);
+
+-- Create indexes for listing a project's copies and cleaning up expired ones
+CREATE INDEX idx_database_snapshots_project_id ON database_snapshots(project_id);
+CREATE INDEX idx_database_snapshots_expires_at ON database_snapshots(expires_at);
+CREATE INDEX idx_database_branches_project_id ON database_branches(project_id);
+CREATE INDEX idx_database_branches_expires_at ON database_branches(expires_at);
+
+-- Add comments
+COMMENT ON TABLE database_snapshots IS 'Frozen copies of project databases, dropped once they expire';
+COMMENT ON COLUMN database_snapshots.database_name IS 'Postgres database holding the copy, which refuses connections';
+COMMENT ON TABLE database_branches IS 'Databases restored from snapshots for preview environments, dropped once they expire';
+COMMENT ON COLUMN database_branches.snapshot_id IS 'Snapshot the branch was restored from, NULL once the snapshot is deleted';
+COMMENT ON COLUMN database_branches.name IS 'Name of the branch within its project, e.g. pr-42';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_database_branches_expires_at;
+DROP INDEX IF EXISTS idx_database_branches_project_id;
+DROP INDEX IF EXISTS idx_database_snapshots_expires_at;
+DROP INDEX IF EXISTS idx_database_snapshots_project_id;
+DROP TABLE IF EXISTS database_branches;
+DROP TABLE IF EXISTS database_snapshots;
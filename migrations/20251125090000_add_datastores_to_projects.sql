@@ -0,0 +1,10 @@
+-- +goose Up
+-- Let projects use Redis and MySQL besides their Postgres database
+ALTER TABLE projects ADD COLUMN datastores TEXT[] NOT NULL DEFAULT '{}'
+    CHECK (datastores <@ ARRAY['REDIS', 'MYSQL']::TEXT[]);
+
+-- Add comments
+COMMENT ON COLUMN projects.datastores IS 'Datastores provisioned besides the Postgres database (REDIS, MYSQL)';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS datastores;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Let projects mount a persistent volume into their containers
+ALTER TABLE projects ADD COLUMN volume_mount_path VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE projects ADD COLUMN volume_size_gb INTEGER NOT NULL DEFAULT 0
+    CHECK (volume_size_gb BETWEEN 0 AND 100);
+
+-- Add comments
+COMMENT ON COLUMN projects.volume_mount_path IS 'Where the persistent volume is mounted in the container, empty for none';
+COMMENT ON COLUMN projects.volume_size_gb IS 'Size in GB the persistent volume is expected to stay within';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS volume_size_gb;
+ALTER TABLE projects DROP COLUMN IF EXISTS volume_mount_path;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Let projects run background workers that receive no traffic
+ALTER TABLE projects ADD COLUMN project_type VARCHAR(20) NOT NULL DEFAULT 'WEB'
+    CHECK (project_type IN ('WEB', 'WORKER'));
+
+-- Add comments
+COMMENT ON COLUMN projects.project_type IS 'How the project runs (WEB serves traffic through the load balancer, WORKER runs in the background)';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS project_type;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- Let projects run one-off tasks on a schedule
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_project_type_check;
+ALTER TABLE projects ADD CONSTRAINT projects_project_type_check
+    CHECK (project_type IN ('WEB', 'WORKER', 'CRON'));
+ALTER TABLE projects ADD COLUMN schedule VARCHAR(256) NOT NULL DEFAULT '';
+
+-- Runs of CRON projects' scheduled tasks, recorded from ECS
+CREATE TABLE cron_runs (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    deployment_id UUID NOT NULL REFERENCES deployments(id) ON DELETE CASCADE,
+    task_arn VARCHAR(2048) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('RUNNING', 'SUCCEEDED', 'FAILED')),
+    exit_code INTEGER,
+    reason TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    stopped_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_cron_runs_deployment_id ON cron_runs(deployment_id, started_at DESC);
+
+-- Add comments
+COMMENT ON COLUMN projects.project_type IS 'How the project runs (WEB serves traffic through the load balancer, WORKER runs in the background, CRON runs on a schedule)';
+COMMENT ON COLUMN projects.schedule IS 'EventBridge schedule expression a CRON project runs on, empty for other types';
+COMMENT ON TABLE cron_runs IS 'Scheduled task runs of CRON projects';
+COMMENT ON COLUMN cron_runs.task_arn IS 'ARN of the ECS task the schedule started';
+COMMENT ON COLUMN cron_runs.exit_code IS 'Exit code of the container, NULL while running or if it never started';
+COMMENT ON COLUMN cron_runs.reason IS 'Why ECS stopped the task, if it failed';
+
+-- +goose Down
+DROP TABLE IF EXISTS cron_runs;
+ALTER TABLE projects DROP COLUMN IF EXISTS schedule;
+UPDATE projects SET project_type = 'WORKER' WHERE project_type = 'CRON';
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_project_type_check;
+ALTER TABLE projects ADD CONSTRAINT projects_project_type_check
+    CHECK (project_type IN ('WEB', 'WORKER'));
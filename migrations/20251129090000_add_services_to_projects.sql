@@ -0,0 +1,10 @@
+-- +goose Up
+-- Let projects run several services from the same repository, each deployed as its own ECS service
+ALTER TABLE projects ADD COLUMN services JSONB NOT NULL DEFAULT '[]'
+    CHECK (jsonb_typeof(services) = 'array');
+
+-- Add comments
+COMMENT ON COLUMN projects.services IS 'Processes run besides the main one, as a JSON array of {name, type, command, port, replicas, schedule}';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS services;
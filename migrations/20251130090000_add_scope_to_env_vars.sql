@@ -0,0 +1,10 @@
+-- +goose Up
+-- Let environment variables reach the image build, the running containers or both
+ALTER TABLE project_environment_variables ADD COLUMN scope VARCHAR(10) NOT NULL DEFAULT 'RUNTIME'
+    CHECK (scope IN ('BUILD', 'RUNTIME', 'BOTH'));
+
+-- Add comments
+COMMENT ON COLUMN project_environment_variables.scope IS 'Where the variable is passed (BUILD as a Docker build arg, RUNTIME to the containers, BOTH to either)';
+
+-- +goose Down
+ALTER TABLE project_environment_variables DROP COLUMN IF EXISTS scope;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- Let projects deploy to environments besides production, such as staging, each with its own
+-- environment variables, subdomain and ECS services
+ALTER TABLE projects ADD COLUMN environments TEXT[] NOT NULL DEFAULT '{}'
+    CHECK (NOT 'production' = ANY (environments));
+ALTER TABLE deployments ADD COLUMN environment VARCHAR(20) NOT NULL DEFAULT 'production';
+ALTER TABLE deployments_archive ADD COLUMN environment VARCHAR(20) NOT NULL DEFAULT 'production';
+ALTER TABLE project_environment_variables ADD COLUMN environment VARCHAR(20) NOT NULL DEFAULT 'production';
+
+-- Keys are unique within an environment instead of within the project
+ALTER TABLE project_environment_variables DROP CONSTRAINT IF EXISTS project_environment_variables_project_id_key_key;
+ALTER TABLE project_environment_variables ADD CONSTRAINT project_environment_variables_project_id_environment_key_key
+    UNIQUE (project_id, environment, key);
+
+-- Create indexes for finding the latest deployment of an environment
+CREATE INDEX idx_deployments_project_environment ON deployments (project_id, environment, created_at DESC);
+
+-- Add comments
+COMMENT ON COLUMN projects.environments IS 'Environments deployed to besides production, e.g. staging';
+COMMENT ON COLUMN deployments.environment IS 'Environment of the project the deployment runs in';
+COMMENT ON COLUMN deployments_archive.environment IS 'Environment of the project the deployment runs in';
+COMMENT ON COLUMN project_environment_variables.environment IS 'Environment of the project the variable is passed to';
+
+-- +goose Down
+-- Variables of other environments would clash with production's
+DELETE FROM project_environment_variables WHERE environment != 'production';
+
+DROP INDEX IF EXISTS idx_deployments_project_environment;
+
+ALTER TABLE project_environment_variables DROP CONSTRAINT IF EXISTS project_environment_variables_project_id_environment_key_key;
+ALTER TABLE project_environment_variables ADD CONSTRAINT project_environment_variables_project_id_key_key
+    UNIQUE (project_id, key);
+
+ALTER TABLE project_environment_variables DROP COLUMN IF EXISTS environment;
+ALTER TABLE deployments_archive DROP COLUMN IF EXISTS environment;
+ALTER TABLE deployments DROP COLUMN IF EXISTS environment;
+ALTER TABLE projects DROP COLUMN IF EXISTS environments;
@@ -0,0 +1,40 @@
+-- +goose Up
+-- Let deployments to protected environments wait for approval before they are built
+ALTER TABLE projects ADD COLUMN protected_environments TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE deployments DROP CONSTRAINT IF EXISTS deployments_status_check;
+ALTER TABLE deployments ADD CONSTRAINT deployments_status_check CHECK (
+    status IN ('PENDING', 'WAITING_APPROVAL', 'BUILDING', 'DEPLOYING', 'DEPLOYED', 'FAILED', 'ROLLED_BACK', 'REJECTED')
+);
+
+-- Create deployment_approvals table recording who approved or rejected each deployment
+CREATE TABLE deployment_approvals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    deployment_id UUID NOT NULL,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    decision VARCHAR(20) NOT NULL CHECK (decision IN ('APPROVED', 'REJECTED')),
+    comment TEXT NOT NULL DEFAULT '',
+    decided_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create index for reading the decisions on a deployment
+CREATE INDEX idx_deployment_approvals_deployment ON deployment_approvals(deployment_id, decided_at);
+
+-- Add comments
+COMMENT ON COLUMN projects.protected_environments IS 'Environments whose deployments wait for approval before they are built';
+COMMENT ON TABLE deployment_approvals IS 'Audit records of the approval or rejection of deployments to protected environments';
+COMMENT ON COLUMN deployment_approvals.deployment_id IS 'Deployment decided on (no foreign key so records survive archiving)';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_deployment_approvals_deployment;
+DROP TABLE IF EXISTS deployment_approvals;
+
+-- Deployments still waiting could no longer be stored
+UPDATE deployments SET status = 'FAILED' WHERE status IN ('WAITING_APPROVAL', 'REJECTED');
+ALTER TABLE deployments DROP CONSTRAINT IF EXISTS deployments_status_check;
+ALTER TABLE deployments ADD CONSTRAINT deployments_status_check CHECK (
+    status IN ('PENDING', 'BUILDING', 'DEPLOYING', 'DEPLOYED', 'FAILED', 'ROLLED_BACK')
+);
+
+ALTER TABLE projects DROP COLUMN IF EXISTS protected_environments;
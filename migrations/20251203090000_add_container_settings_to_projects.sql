@@ -0,0 +1,20 @@
+-- +goose Up
+-- Let projects choose the port their container listens on, the path the load balancer checks and the
+-- resources of their tasks, e.g. from the snapdeploy.yaml in their repository
+ALTER TABLE projects ADD COLUMN container_port INTEGER NOT NULL DEFAULT 8080
+    CHECK (container_port BETWEEN 1 AND 65535);
+ALTER TABLE projects ADD COLUMN health_check_path VARCHAR(255) NOT NULL DEFAULT '/';
+ALTER TABLE projects ADD COLUMN cpu INTEGER NOT NULL DEFAULT 256;
+ALTER TABLE projects ADD COLUMN memory INTEGER NOT NULL DEFAULT 512;
+
+-- Add comments
+COMMENT ON COLUMN projects.container_port IS 'Port the container listens on, unless a PORT environment variable overrides it';
+COMMENT ON COLUMN projects.health_check_path IS 'Path the load balancer requests to check the container is healthy';
+COMMENT ON COLUMN projects.cpu IS 'CPU units of the project''s tasks (1024 is one vCPU)';
+COMMENT ON COLUMN projects.memory IS 'Memory in MiB of the project''s tasks';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS memory;
+ALTER TABLE projects DROP COLUMN IF EXISTS cpu;
+ALTER TABLE projects DROP COLUMN IF EXISTS health_check_path;
+ALTER TABLE projects DROP COLUMN IF EXISTS container_port;
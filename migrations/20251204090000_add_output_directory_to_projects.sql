@@ -0,0 +1,10 @@
+-- +goose Up
+-- Let projects be static sites, built to files served from S3 through CloudFront
+ALTER TABLE projects ADD COLUMN output_directory VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Add comments
+COMMENT ON COLUMN projects.project_type IS 'How the project runs (WEB serves traffic through the load balancer, WORKER runs in the background, CRON runs on a schedule, STATIC is served from S3 through CloudFront)';
+COMMENT ON COLUMN projects.output_directory IS 'Directory a STATIC project''s build writes its files to, empty for other types';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS output_directory;
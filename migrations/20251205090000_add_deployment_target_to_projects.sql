@@ -0,0 +1,12 @@
+-- +goose Up
+-- Let projects run as Lambda functions instead of ECS services
+ALTER TABLE projects ADD COLUMN deployment_target VARCHAR(20) NOT NULL DEFAULT 'ECS';
+ALTER TABLE projects ADD COLUMN lambda_endpoint VARCHAR(20) NOT NULL DEFAULT '';
+
+-- Add comments
+COMMENT ON COLUMN projects.deployment_target IS 'Compute the project runs on (ECS services behind the load balancer, or a LAMBDA function)';
+COMMENT ON COLUMN projects.lambda_endpoint IS 'How requests reach a LAMBDA project (API_GATEWAY on its subdomain, or its FUNCTION_URL), empty for ECS projects';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS lambda_endpoint;
+ALTER TABLE projects DROP COLUMN IF EXISTS deployment_target;
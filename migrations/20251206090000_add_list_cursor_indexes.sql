@@ -0,0 +1,24 @@
+-- +goose Up
+-- Serve cursor pagination of deployments and repositories from the index, newest first with the ID breaking ties
+DROP INDEX IF EXISTS idx_deployments_project_created;
+CREATE INDEX idx_deployments_project_created ON deployments (project_id, created_at DESC, id DESC);
+CREATE INDEX idx_deployments_user_created ON deployments (user_id, created_at DESC, id DESC);
+
+DROP INDEX IF EXISTS idx_deployments_archive_project_created;
+DROP INDEX IF EXISTS idx_deployments_archive_user_created;
+CREATE INDEX idx_deployments_archive_project_created ON deployments_archive (project_id, created_at DESC, id DESC);
+CREATE INDEX idx_deployments_archive_user_created ON deployments_archive (user_id, created_at DESC, id DESC);
+
+CREATE INDEX idx_repositories_user_created ON repositories (user_id, created_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_repositories_user_created;
+
+DROP INDEX IF EXISTS idx_deployments_archive_user_created;
+DROP INDEX IF EXISTS idx_deployments_archive_project_created;
+CREATE INDEX idx_deployments_archive_project_created ON deployments_archive (project_id, created_at DESC);
+CREATE INDEX idx_deployments_archive_user_created ON deployments_archive (user_id, created_at DESC);
+
+DROP INDEX IF EXISTS idx_deployments_user_created;
+DROP INDEX IF EXISTS idx_deployments_project_created;
+CREATE INDEX idx_deployments_project_created ON deployments (project_id, created_at DESC);
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Create deployment_manifests table recording what each deployment was built from besides its commit
+CREATE TABLE deployment_manifests (
+    deployment_id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    image_tag VARCHAR(500) NOT NULL,
+    env_vars JSONB NOT NULL DEFAULT '{}' CHECK (jsonb_typeof(env_vars) = 'object'),
+    config JSONB NOT NULL DEFAULT '{}' CHECK (jsonb_typeof(config) = 'object'),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Add comments
+COMMENT ON TABLE deployment_manifests IS 'Image, environment variables and settings each deployment was built with, for comparing deployments';
+COMMENT ON COLUMN deployment_manifests.deployment_id IS 'Deployment the manifest belongs to (no foreign key so manifests survive archiving)';
+COMMENT ON COLUMN deployment_manifests.env_vars IS 'Keyed digest of each environment variable value by name, values themselves are never stored';
+COMMENT ON COLUMN deployment_manifests.config IS 'Project settings the deployment was built with, by name';
+
+-- +goose Down
+DROP TABLE IF EXISTS deployment_manifests;
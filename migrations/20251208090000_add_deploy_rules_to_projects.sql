@@ -0,0 +1,10 @@
+-- +goose Up
+-- Let projects choose which branches pushes deploy, and to which environment
+ALTER TABLE projects ADD COLUMN deploy_rules JSONB NOT NULL DEFAULT '[]'
+    CHECK (jsonb_typeof(deploy_rules) = 'array');
+
+-- Add comments
+COMMENT ON COLUMN projects.deploy_rules IS 'Branch rules pushes are deployed by, as a JSON array of {branch, environment} where the first rule matching the pushed branch wins';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS deploy_rules;
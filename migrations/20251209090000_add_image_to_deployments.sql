@@ -0,0 +1,18 @@
+-- +goose Up
+-- Record the exact image each deployment runs, so it can be traced to its artifact and rolled back by digest
+ALTER TABLE deployments ADD COLUMN image_uri TEXT NOT NULL DEFAULT '';
+ALTER TABLE deployments ADD COLUMN image_digest TEXT NOT NULL DEFAULT '';
+ALTER TABLE deployments_archive ADD COLUMN image_uri TEXT NOT NULL DEFAULT '';
+ALTER TABLE deployments_archive ADD COLUMN image_digest TEXT NOT NULL DEFAULT '';
+
+-- Add comments
+COMMENT ON COLUMN deployments.image_uri IS 'Image the deployment runs, empty until it starts running one';
+COMMENT ON COLUMN deployments.image_digest IS 'Digest of the image the deployment runs, empty if the registry did not report one';
+COMMENT ON COLUMN deployments_archive.image_uri IS 'Image the deployment runs, empty until it starts running one';
+COMMENT ON COLUMN deployments_archive.image_digest IS 'Digest of the image the deployment runs, empty if the registry did not report one';
+
+-- +goose Down
+ALTER TABLE deployments_archive DROP COLUMN IF EXISTS image_digest;
+ALTER TABLE deployments_archive DROP COLUMN IF EXISTS image_uri;
+ALTER TABLE deployments DROP COLUMN IF EXISTS image_digest;
+ALTER TABLE deployments DROP COLUMN IF EXISTS image_uri;
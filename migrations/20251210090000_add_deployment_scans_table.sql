@@ -0,0 +1,28 @@
+-- +goose Up
+-- Create deployment_scans table recording the vulnerabilities and SBOM of each deployment's image
+CREATE TABLE deployment_scans (
+    deployment_id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    image_uri TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('COMPLETED', 'FAILED')),
+    error TEXT NOT NULL DEFAULT '',
+    severity_counts JSONB NOT NULL DEFAULT '{}' CHECK (jsonb_typeof(severity_counts) = 'object'),
+    vulnerabilities JSONB NOT NULL DEFAULT '[]' CHECK (jsonb_typeof(vulnerabilities) = 'array'),
+    sbom_format VARCHAR(50) NOT NULL DEFAULT '',
+    sbom_packages INTEGER NOT NULL DEFAULT 0,
+    sbom JSONB,
+    blocked BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Add comments
+COMMENT ON TABLE deployment_scans IS 'Vulnerabilities and SBOM of the image of each deployment, found after its build and before it is deployed';
+COMMENT ON COLUMN deployment_scans.deployment_id IS 'Deployment whose image was scanned (no foreign key so scans survive archiving)';
+COMMENT ON COLUMN deployment_scans.status IS 'COMPLETED, or FAILED when the vulnerabilities could not be read and the deployment went ahead without them';
+COMMENT ON COLUMN deployment_scans.severity_counts IS 'Number of vulnerabilities found by severity, e.g. {"CRITICAL": 1, "HIGH": 4}';
+COMMENT ON COLUMN deployment_scans.vulnerabilities IS 'Most severe vulnerabilities found, with their package and version';
+COMMENT ON COLUMN deployment_scans.sbom IS 'SBOM document generated by syft, NULL if none could be generated';
+COMMENT ON COLUMN deployment_scans.blocked IS 'Whether the deployment was stopped for having too many critical vulnerabilities';
+
+-- +goose Down
+DROP TABLE IF EXISTS deployment_scans;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Let deployments whose build was cut off by a server shutdown wait for a build worker to start it over
+ALTER TABLE deployments DROP CONSTRAINT IF EXISTS deployments_status_check;
+ALTER TABLE deployments ADD CONSTRAINT deployments_status_check CHECK (
+    status IN ('PENDING', 'WAITING_APPROVAL', 'BUILDING', 'DEPLOYING', 'DEPLOYED', 'FAILED', 'ROLLED_BACK', 'REJECTED', 'INTERRUPTED')
+);
+
+-- +goose Down
+-- Interrupted deployments could no longer be stored
+UPDATE deployments SET status = 'FAILED' WHERE status = 'INTERRUPTED';
+ALTER TABLE deployments DROP CONSTRAINT IF EXISTS deployments_status_check;
+ALTER TABLE deployments ADD CONSTRAINT deployments_status_check CHECK (
+    status IN ('PENDING', 'WAITING_APPROVAL', 'BUILDING', 'DEPLOYING', 'DEPLOYED', 'FAILED', 'ROLLED_BACK', 'REJECTED')
+);
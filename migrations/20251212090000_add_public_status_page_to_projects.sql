@@ -0,0 +1,9 @@
+-- +goose Up
+-- Let projects opt in to a public status page, reachable without authentication
+ALTER TABLE projects ADD COLUMN public_status_page BOOLEAN NOT NULL DEFAULT false;
+
+-- Add comments
+COMMENT ON COLUMN projects.public_status_page IS 'Whether anyone may read the status page of the project, found by its custom domain';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS public_status_page;
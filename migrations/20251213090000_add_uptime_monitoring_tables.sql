@@ -0,0 +1,41 @@
+-- +goose Up
+-- Health checks of deployed projects, probed from the control plane
+CREATE TABLE health_checks (
+    id BIGSERIAL PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    healthy BOOLEAN NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    checked_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_health_checks_project_id ON health_checks(project_id, checked_at DESC);
+CREATE INDEX idx_health_checks_checked_at ON health_checks(checked_at);
+
+-- Periods a project's health checks kept failing
+CREATE TABLE project_incidents (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    cause TEXT NOT NULL DEFAULT '',
+    failed_checks INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    resolved_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_project_incidents_project_id ON project_incidents(project_id, started_at DESC);
+-- A project has at most one open incident, even with several server instances checking it
+CREATE UNIQUE INDEX idx_project_incidents_open ON project_incidents(project_id) WHERE resolved_at IS NULL;
+
+-- Add comments
+COMMENT ON TABLE health_checks IS 'Health checks of deployed projects, probed from the control plane';
+COMMENT ON COLUMN health_checks.status_code IS 'HTTP status the health endpoint answered with, 0 if it did not answer';
+COMMENT ON COLUMN health_checks.error IS 'Why the check failed, empty for healthy checks';
+COMMENT ON TABLE project_incidents IS 'Periods a project''s health checks kept failing';
+COMMENT ON COLUMN project_incidents.cause IS 'Why the last failed check of the incident failed';
+COMMENT ON COLUMN project_incidents.resolved_at IS 'When a check succeeded again, NULL while the incident is open';
+
+-- +goose Down
+DROP TABLE IF EXISTS project_incidents;
+DROP TABLE IF EXISTS health_checks;
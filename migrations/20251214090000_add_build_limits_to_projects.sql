@@ -0,0 +1,12 @@
+-- +goose Up
+-- Let projects choose the resources their builds get and how long a build may run
+ALTER TABLE projects ADD COLUMN build_size TEXT NOT NULL DEFAULT 'SMALL';
+ALTER TABLE projects ADD COLUMN build_timeout_minutes INTEGER NOT NULL DEFAULT 30;
+
+-- Add comments
+COMMENT ON COLUMN projects.build_size IS 'CPU and memory of the project''s builds (SMALL, MEDIUM or LARGE)';
+COMMENT ON COLUMN projects.build_timeout_minutes IS 'How long a build of the project may run before it is stopped and the deployment fails';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS build_timeout_minutes;
+ALTER TABLE projects DROP COLUMN IF EXISTS build_size;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Last sync of each user's repositories from each Git provider, so the next one fetches only what changed
+CREATE TABLE repository_syncs (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider TEXT NOT NULL,
+    etag TEXT NOT NULL DEFAULT '',
+    synced_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    full_synced_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, provider)
+);
+
+-- Add comments
+COMMENT ON TABLE repository_syncs IS 'Last sync of each user''s repositories from each Git provider, so the next one fetches only what changed';
+COMMENT ON COLUMN repository_syncs.etag IS 'Validator of the provider''s list of repositories at the last sync';
+COMMENT ON COLUMN repository_syncs.full_synced_at IS 'When the last sync fetching every repository started';
+
+-- +goose Down
+DROP TABLE IF EXISTS repository_syncs;
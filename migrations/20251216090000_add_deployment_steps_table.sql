@@ -0,0 +1,21 @@
+-- +goose Up
+-- Create deployment_steps table recording the outcome of each pipeline step of a deployment
+CREATE TABLE deployment_steps (
+    deployment_id UUID NOT NULL,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    step VARCHAR(20) NOT NULL CHECK (step IN ('clone', 'build', 'push', 'db', 'migrate', 'alb', 'ecs', 'dns')),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('RUNNING', 'SUCCEEDED', 'FAILED', 'SKIPPED')),
+    error TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (deployment_id, step)
+);
+
+-- Add comments
+COMMENT ON TABLE deployment_steps IS 'Outcome of each pipeline step of a deployment, used to retry failed deployments from the step that failed';
+COMMENT ON COLUMN deployment_steps.deployment_id IS 'Deployment the step belongs to (no foreign key so steps survive archiving)';
+COMMENT ON COLUMN deployment_steps.status IS 'RUNNING, SUCCEEDED, FAILED, or SKIPPED when a retried deployment carried the result of an earlier attempt over';
+COMMENT ON COLUMN deployment_steps.finished_at IS 'When the step finished, NULL while it is running';
+
+-- +goose Down
+DROP TABLE IF EXISTS deployment_steps;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- Create command_runs table recording the one-off commands run in projects' containers
+CREATE TABLE command_runs (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    deployment_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    environment VARCHAR(20) NOT NULL DEFAULT 'production',
+    command VARCHAR(1024) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('RUNNING', 'SUCCEEDED', 'FAILED')),
+    exit_code INTEGER,
+    error TEXT NOT NULL DEFAULT '',
+    output TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_command_runs_project_id ON command_runs(project_id, started_at DESC);
+
+-- Add comments
+COMMENT ON TABLE command_runs IS 'One-off commands run in the containers of projects, the audit trail of who ran what';
+COMMENT ON COLUMN command_runs.deployment_id IS 'Deployment whose image and environment variables the command ran with (no foreign key so runs survive archiving)';
+COMMENT ON COLUMN command_runs.user_id IS 'User who ran the command';
+COMMENT ON COLUMN command_runs.exit_code IS 'Exit code of the command, NULL while running or if it never started';
+COMMENT ON COLUMN command_runs.error IS 'Why the command failed';
+COMMENT ON COLUMN command_runs.output IS 'Last lines the command wrote, with secrets masked';
+
+-- +goose Down
+DROP TABLE IF EXISTS command_runs;
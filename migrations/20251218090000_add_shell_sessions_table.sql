@@ -0,0 +1,33 @@
+-- +goose Up
+-- Create shell_sessions table recording the interactive shells opened in projects' running tasks
+CREATE TABLE shell_sessions (
+    id UUID PRIMARY KEY,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    environment VARCHAR(20) NOT NULL DEFAULT 'production',
+    token_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('PENDING', 'ACTIVE', 'ENDED')),
+    task_arn TEXT NOT NULL DEFAULT '',
+    end_reason VARCHAR(20) NOT NULL DEFAULT '' CHECK (end_reason IN ('', 'CLOSED', 'EXITED', 'IDLE_TIMEOUT', 'TIME_LIMIT', 'FAILED')),
+    error TEXT NOT NULL DEFAULT '',
+    bytes_in BIGINT NOT NULL DEFAULT 0,
+    bytes_out BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    ended_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_shell_sessions_project_id ON shell_sessions(project_id, created_at DESC);
+
+-- Add comments
+COMMENT ON TABLE shell_sessions IS 'Interactive shells opened in the running tasks of projects, the audit trail of who opened them';
+COMMENT ON COLUMN shell_sessions.user_id IS 'User who opened the shell';
+COMMENT ON COLUMN shell_sessions.token_hash IS 'SHA-256 of the one-time token the user''s terminal connects with';
+COMMENT ON COLUMN shell_sessions.task_arn IS 'ECS task the shell ran in';
+COMMENT ON COLUMN shell_sessions.end_reason IS 'Why the session ended, empty until it has';
+COMMENT ON COLUMN shell_sessions.bytes_in IS 'Bytes typed into the shell';
+COMMENT ON COLUMN shell_sessions.bytes_out IS 'Bytes the shell wrote';
+COMMENT ON COLUMN shell_sessions.started_at IS 'When the user''s terminal connected, NULL if it never did';
+
+-- +goose Down
+DROP TABLE IF EXISTS shell_sessions;
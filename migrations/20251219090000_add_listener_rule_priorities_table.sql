@@ -0,0 +1,19 @@
+-- +goose Up
+-- Create listener_rule_priorities table allocating the priorities of the load balancer rules routing to services
+CREATE TABLE listener_rule_priorities (
+    listener_arn TEXT NOT NULL,
+    priority INTEGER NOT NULL CHECK (priority BETWEEN 1 AND 50000),
+    service_name VARCHAR(255) NOT NULL,
+    allocated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (listener_arn, priority),
+    UNIQUE (listener_arn, service_name)
+);
+
+-- Add comments
+COMMENT ON TABLE listener_rule_priorities IS 'Priorities allocated to the listener rules of services, so concurrent deployments never pick the same one and deleted rules free theirs';
+COMMENT ON COLUMN listener_rule_priorities.listener_arn IS 'Listener the rule is on';
+COMMENT ON COLUMN listener_rule_priorities.priority IS 'Priority of the rule, unique on its listener';
+COMMENT ON COLUMN listener_rule_priorities.service_name IS 'Service the rule routes to, with at most one rule per listener';
+
+-- +goose Down
+DROP TABLE IF EXISTS listener_rule_priorities;
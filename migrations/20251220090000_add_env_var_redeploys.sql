@@ -0,0 +1,37 @@
+-- +goose Up
+-- Let projects redeploy their last successful image when their environment variables change
+ALTER TABLE projects ADD COLUMN redeploy_on_env_change BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE deployments DROP CONSTRAINT IF EXISTS deployments_type_check;
+ALTER TABLE deployments ADD CONSTRAINT deployments_type_check CHECK (
+    type IN ('BUILD', 'RESTART', 'REDEPLOY')
+);
+
+-- Create environment_variable_changes table recording when an environment's variables last changed
+-- and the deployment that picked the change up
+CREATE TABLE environment_variable_changes (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    environment VARCHAR(20) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    applied_deployment_id UUID,
+    applied_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (project_id, environment)
+);
+
+-- Add comments
+COMMENT ON COLUMN projects.redeploy_on_env_change IS 'Whether changing an environment variable redeploys the last successful image of its environment';
+COMMENT ON TABLE environment_variable_changes IS 'Latest change to the environment variables of each project environment, pending until a deployment picks it up';
+COMMENT ON COLUMN environment_variable_changes.applied_deployment_id IS 'First deployment started after the change that succeeded, NULL while the change is not deployed (no foreign key so it survives archiving)';
+COMMENT ON COLUMN environment_variable_changes.applied_at IS 'When the deployment that picked the change up succeeded';
+
+-- +goose Down
+DROP TABLE IF EXISTS environment_variable_changes;
+
+-- Redeploys could no longer be stored
+UPDATE deployments SET type = 'RESTART' WHERE type = 'REDEPLOY';
+ALTER TABLE deployments DROP CONSTRAINT IF EXISTS deployments_type_check;
+ALTER TABLE deployments ADD CONSTRAINT deployments_type_check CHECK (
+    type IN ('BUILD', 'RESTART')
+);
+
+ALTER TABLE projects DROP COLUMN IF EXISTS redeploy_on_env_change;
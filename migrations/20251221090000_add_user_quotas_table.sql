@@ -0,0 +1,22 @@
+-- +goose Up
+-- Create user_quotas table holding the plan each user is on and the limits overridden for them
+CREATE TABLE user_quotas (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    plan VARCHAR(50),
+    max_projects INTEGER CHECK (max_projects >= 0),
+    max_concurrent_builds INTEGER CHECK (max_concurrent_builds >= 0),
+    max_env_vars INTEGER CHECK (max_env_vars >= 0),
+    build_minutes_per_month INTEGER CHECK (build_minutes_per_month >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Add comments
+COMMENT ON TABLE user_quotas IS 'Plan and limit overrides of users; users without a row are on the default plan';
+COMMENT ON COLUMN user_quotas.plan IS 'Name of a plan configured with QUOTA_PLANS, NULL for the default plan';
+COMMENT ON COLUMN user_quotas.max_projects IS 'Overrides the plan''s project limit, NULL keeps it and 0 is unlimited';
+COMMENT ON COLUMN user_quotas.max_concurrent_builds IS 'Overrides the plan''s limit of deployments in progress at once, NULL keeps it and 0 is unlimited';
+COMMENT ON COLUMN user_quotas.max_env_vars IS 'Overrides the plan''s limit of environment variables per project environment, NULL keeps it and 0 is unlimited';
+COMMENT ON COLUMN user_quotas.build_minutes_per_month IS 'Overrides the plan''s monthly build minutes, NULL keeps it and 0 is unlimited';
+
+-- +goose Down
+DROP TABLE IF EXISTS user_quotas;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- Create billing_accounts table linking users to their Stripe customers
+CREATE TABLE billing_accounts (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    stripe_customer_id VARCHAR(255) NOT NULL UNIQUE,
+    stripe_subscription_id VARCHAR(255),
+    plan VARCHAR(50),
+    status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'SUSPENDED')),
+    suspended_at TIMESTAMP WITH TIME ZONE,
+    build_minutes_reported_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    build_minutes_carry DOUBLE PRECISION NOT NULL DEFAULT 0,
+    vcpu_hours_reported_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    vcpu_hours_carry DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Add comments
+COMMENT ON TABLE billing_accounts IS 'Stripe customers of users, their subscription and how much of their usage was reported';
+COMMENT ON COLUMN billing_accounts.stripe_subscription_id IS 'Latest subscription of the customer, NULL before they subscribe';
+COMMENT ON COLUMN billing_accounts.plan IS 'Quota plan of the subscription''s price, NULL for the default plan';
+COMMENT ON COLUMN billing_accounts.status IS 'SUSPENDED while a payment failed: services are stopped and no builds start';
+COMMENT ON COLUMN billing_accounts.build_minutes_reported_until IS 'Build minutes of periods ending up to this time were reported to Stripe';
+COMMENT ON COLUMN billing_accounts.build_minutes_carry IS 'Fraction of a build minute not reported yet';
+COMMENT ON COLUMN billing_accounts.vcpu_hours_reported_until IS 'Fargate vCPU hours of periods ending up to this time were reported to Stripe';
+COMMENT ON COLUMN billing_accounts.vcpu_hours_carry IS 'Fraction of a Fargate vCPU hour not reported yet';
+
+-- +goose Down
+DROP TABLE IF EXISTS billing_accounts;
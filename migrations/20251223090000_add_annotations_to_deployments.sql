@@ -0,0 +1,32 @@
+-- +goose Up
+-- Let teams describe what a deployment ships, such as a release, beyond its commit
+ALTER TABLE deployments ADD COLUMN title TEXT NOT NULL DEFAULT '';
+ALTER TABLE deployments ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE deployments ADD COLUMN labels JSONB NOT NULL DEFAULT '{}'
+    CHECK (jsonb_typeof(labels) = 'object');
+ALTER TABLE deployments_archive ADD COLUMN title TEXT NOT NULL DEFAULT '';
+ALTER TABLE deployments_archive ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE deployments_archive ADD COLUMN labels JSONB NOT NULL DEFAULT '{}'
+    CHECK (jsonb_typeof(labels) = 'object');
+
+-- Deployments are listed by their labels
+CREATE INDEX idx_deployments_labels ON deployments USING GIN (labels jsonb_path_ops);
+CREATE INDEX idx_deployments_archive_labels ON deployments_archive USING GIN (labels jsonb_path_ops);
+
+-- Add comments
+COMMENT ON COLUMN deployments.title IS 'Short description of the deployment, such as the release it ships';
+COMMENT ON COLUMN deployments.description IS 'Release notes of the deployment';
+COMMENT ON COLUMN deployments.labels IS 'Arbitrary key/value labels deployments can be listed by';
+COMMENT ON COLUMN deployments_archive.title IS 'Short description of the deployment, such as the release it ships';
+COMMENT ON COLUMN deployments_archive.description IS 'Release notes of the deployment';
+COMMENT ON COLUMN deployments_archive.labels IS 'Arbitrary key/value labels deployments can be listed by';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_deployments_archive_labels;
+DROP INDEX IF EXISTS idx_deployments_labels;
+ALTER TABLE deployments_archive DROP COLUMN IF EXISTS labels;
+ALTER TABLE deployments_archive DROP COLUMN IF EXISTS description;
+ALTER TABLE deployments_archive DROP COLUMN IF EXISTS title;
+ALTER TABLE deployments DROP COLUMN IF EXISTS labels;
+ALTER TABLE deployments DROP COLUMN IF EXISTS description;
+ALTER TABLE deployments DROP COLUMN IF EXISTS title;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Pinned deployments, such as known-good rollback targets, can't be deleted and are never archived.
+-- Archived deployments are never pinned; the archive has the column so both tables keep the same columns.
+ALTER TABLE deployments ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE deployments_archive ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT false;
+
+-- Image cleanup keeps the images of each project's pinned deployments
+CREATE INDEX idx_deployments_pinned ON deployments(project_id) WHERE pinned AND deleted_at IS NULL;
+
+-- Add comments
+COMMENT ON COLUMN deployments.pinned IS 'Whether the deployment is kept: it cannot be deleted, archived or have its image cleaned up';
+COMMENT ON COLUMN deployments_archive.pinned IS 'Always false, pinned deployments are never archived';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_deployments_pinned;
+ALTER TABLE deployments_archive DROP COLUMN IF EXISTS pinned;
+ALTER TABLE deployments DROP COLUMN IF EXISTS pinned;
@@ -0,0 +1,65 @@
+-- +goose Up
+-- Create project_templates table holding the catalog of sample apps projects can be created from
+CREATE TABLE project_templates (
+    id VARCHAR(63) PRIMARY KEY CHECK (id ~ '^[a-z0-9][a-z0-9-]*$'),
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    repository_url TEXT NOT NULL,
+    language VARCHAR(20) NOT NULL,
+    type VARCHAR(20) NOT NULL DEFAULT 'WEB',
+    install_command TEXT NOT NULL,
+    build_command TEXT NOT NULL DEFAULT '',
+    run_command TEXT NOT NULL,
+    require_db BOOLEAN NOT NULL DEFAULT false,
+    migration_command TEXT NOT NULL DEFAULT '',
+    env_vars JSONB NOT NULL DEFAULT '[]' CHECK (jsonb_typeof(env_vars) = 'array'),
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Add comments
+COMMENT ON TABLE project_templates IS 'Curated sample apps users can create a project from';
+COMMENT ON COLUMN project_templates.id IS 'Slug identifying the template in the API, such as nextjs-starter';
+COMMENT ON COLUMN project_templates.repository_url IS 'Sample repository projects deploy, or copy when they are created with a repository of their own';
+COMMENT ON COLUMN project_templates.env_vars IS 'Environment variables pre-filled on created projects: key, value, description, scope and whether a value is required';
+COMMENT ON COLUMN project_templates.position IS 'Order of the template in the catalog, lowest first';
+
+-- Seed the catalog
+INSERT INTO project_templates (id, name, description, repository_url, language, install_command, build_command, run_command, require_db, migration_command, env_vars, position) VALUES
+(
+    'nextjs-starter',
+    'Next.js starter',
+    'A Next.js app with the App Router, TypeScript and Tailwind CSS, ready to deploy.',
+    'https://github.com/SnapDeploy/nextjs-starter',
+    'NEXTJS',
+    'npm ci',
+    'npm run build',
+    'npm start',
+    false,
+    '',
+    '[
+        {"key": "NEXT_PUBLIC_SITE_NAME", "value": "My Next.js app", "description": "Name shown in the page title and header", "scope": "BOTH"},
+        {"key": "NEXT_TELEMETRY_DISABLED", "value": "1", "description": "Turns off Next.js telemetry during builds", "scope": "BUILD"}
+    ]',
+    10
+),
+(
+    'go-api-starter',
+    'Go API starter',
+    'A JSON API in Go with a Postgres database, migrations and a health check.',
+    'https://github.com/SnapDeploy/go-api-starter',
+    'GO',
+    'go mod download',
+    'go build -o server ./cmd/server',
+    './server',
+    true,
+    './server migrate',
+    '[
+        {"key": "LOG_LEVEL", "value": "info", "description": "Lowest level logged: debug, info, warn or error"},
+        {"key": "API_KEY", "value": "", "description": "Key clients send in the X-API-Key header", "required": true}
+    ]',
+    20
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS project_templates;
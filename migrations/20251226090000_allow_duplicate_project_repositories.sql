@@ -0,0 +1,14 @@
+-- +goose Up
+-- Duplicated projects deploy the same repository as their source, so a user's projects no longer need distinct
+-- repository URLs; creating a project still refuses a repository another project of the user deploys
+DROP INDEX IF EXISTS idx_projects_user_repository;
+CREATE INDEX idx_projects_user_repository ON projects (user_id, repository_url)
+WHERE
+    deleted_at IS NULL;
+
+-- +goose Down
+-- Fails while a user has duplicated projects, which must be deleted first
+DROP INDEX IF EXISTS idx_projects_user_repository;
+CREATE UNIQUE INDEX idx_projects_user_repository ON projects (user_id, repository_url)
+WHERE
+    deleted_at IS NULL;
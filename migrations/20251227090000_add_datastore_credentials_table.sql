@@ -0,0 +1,19 @@
+-- +goose Up
+-- Create datastore_credentials table holding the accounts projects connect to shared datastore servers with
+CREATE TABLE datastore_credentials (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    datastore VARCHAR(20) NOT NULL,
+    username VARCHAR(32) NOT NULL,
+    password_encrypted TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, datastore)
+);
+
+-- Add comments
+COMMENT ON TABLE datastore_credentials IS 'Accounts of projects on shared datastore servers, each only granted access to its project''s database';
+COMMENT ON COLUMN datastore_credentials.datastore IS 'Datastore the account is on, such as MYSQL';
+COMMENT ON COLUMN datastore_credentials.username IS 'Name of the account on the datastore server';
+COMMENT ON COLUMN datastore_credentials.password_encrypted IS 'Password of the account, encrypted with the platform encryption key';
+
+-- +goose Down
+DROP TABLE IF EXISTS datastore_credentials;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Create agent_tokens table holding the credentials users' self-hosted build agents authenticate with
+CREATE TABLE agent_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_agent_tokens_user_id ON agent_tokens(user_id, created_at DESC);
+
+-- Add comments
+COMMENT ON TABLE agent_tokens IS 'Credentials of users'' self-hosted build agents, which only run the builds of their user''s projects';
+COMMENT ON COLUMN agent_tokens.user_id IS 'User whose builds agents presenting the token run';
+COMMENT ON COLUMN agent_tokens.token_hash IS 'SHA-256 of the token, which is only shown when it is created';
+
+-- +goose Down
+DROP TABLE IF EXISTS agent_tokens;
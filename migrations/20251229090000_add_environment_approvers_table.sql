@@ -0,0 +1,16 @@
+-- +goose Up
+-- Create environment_approvers table holding who may decide on deployments to a protected environment
+CREATE TABLE environment_approvers (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    environment VARCHAR(63) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, environment, user_id)
+);
+
+-- Add comments
+COMMENT ON TABLE environment_approvers IS 'Users designated to approve or reject deployments to a project''s protected environment';
+COMMENT ON COLUMN environment_approvers.user_id IS 'Approver, who may not approve the deployments they started';
+
+-- +goose Down
+DROP TABLE IF EXISTS environment_approvers;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Meter the bytes project services send alongside their compute
+ALTER TABLE usage_records DROP CONSTRAINT IF EXISTS usage_records_metric_check;
+ALTER TABLE usage_records ADD CONSTRAINT usage_records_metric_check
+    CHECK (metric IN ('VCPU_HOURS', 'MEMORY_GB_HOURS', 'BUILD_MINUTES', 'EGRESS_GB'));
+
+-- +goose Down
+DELETE FROM usage_records WHERE metric = 'EGRESS_GB';
+ALTER TABLE usage_records DROP CONSTRAINT IF EXISTS usage_records_metric_check;
+ALTER TABLE usage_records ADD CONSTRAINT usage_records_metric_check
+    CHECK (metric IN ('VCPU_HOURS', 'MEMORY_GB_HOURS', 'BUILD_MINUTES'));
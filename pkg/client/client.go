@@ -0,0 +1,268 @@
+// Package client is a typed Go client of the SnapDeploy API.
+//
+// Requests authenticate with an API token, take a context and are retried when the API is briefly
+// unavailable or rate limits them:
+//
+//	c := client.New(client.DefaultBaseURL, os.Getenv("SNAPDEPLOY_TOKEN"))
+//	dep, err := c.CreateDeployment(ctx, &client.CreateDeploymentRequest{
+//		ProjectID:  projectID,
+//		CommitHash: "abc123def456",
+//		Branch:     "main",
+//	})
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultBaseURL is the hosted SnapDeploy API
+const DefaultBaseURL = "https://core-dev.snap-deploy.com/api/v1"
+
+const (
+	// defaultMaxRetries is how often a failed request is retried unless WithMaxRetries says otherwise
+	defaultMaxRetries = 3
+	// retryDelay is the delay before the first retry; it doubles after each attempt and is jittered
+	retryDelay = 500 * time.Millisecond
+	// maxRetryDelay caps the delay between attempts, including delays the API asks for with Retry-After
+	maxRetryDelay = 30 * time.Second
+	// defaultTimeout bounds each attempt of a request, log streams excepted
+	defaultTimeout = 30 * time.Second
+)
+
+// APIError is an error response of the SnapDeploy API
+type APIError struct {
+	StatusCode int
+	Code       string        `json:"error"`   // Machine readable code, e.g. not_found
+	Message    string        `json:"message"` // Human readable message
+	Details    string        `json:"details,omitempty"`
+	RetryAfter time.Duration // How long the API asked to wait before retrying, 0 if it didn't
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s (HTTP %d)", e.Message, e.StatusCode)
+	if e.Details != "" {
+		msg += ": " + e.Details
+	}
+	return msg
+}
+
+// IsNotFound reports whether err is an API error for a resource that doesn't exist
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Client is a client of the SnapDeploy API. It is safe for concurrent use.
+type Client struct {
+	httpClient   *http.Client
+	streamClient *http.Client // httpClient without a timeout, as log streams stay open
+	baseURL      string
+	token        string
+	maxRetries   int
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client requests are sent with. Its timeout doesn't apply to log streams.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithMaxRetries sets how often a failed request is retried, 0 to never retry
+func WithMaxRetries(maxRetries int) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+	}
+}
+
+// New creates a client of the API at baseURL, e.g. DefaultBaseURL, authenticating with an API token
+func New(baseURL, token string, opts ...Option) *Client {
+	c := &Client{
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		maxRetries: defaultMaxRetries,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	streamClient := *c.httpClient
+	streamClient.Timeout = 0
+	c.streamClient = &streamClient
+	return c
+}
+
+// request describes an API call
+type request struct {
+	method string
+	path   string
+	body   any
+	// idempotent requests can be retried after any failure. Others are only retried when the API
+	// turned them away without handling them.
+	idempotent bool
+	// idempotencyKey is sent as the Idempotency-Key header so the API handles retries of the request once
+	idempotencyKey string
+}
+
+// get, post, put and del describe calls with the method's retry semantics
+func get(path string) *request {
+	return &request{method: http.MethodGet, path: path, idempotent: true}
+}
+
+func post(path string, body any) *request {
+	return &request{method: http.MethodPost, path: path, body: body}
+}
+
+func put(path string, body any) *request {
+	return &request{method: http.MethodPut, path: path, body: body, idempotent: true}
+}
+
+func del(path string) *request {
+	return &request{method: http.MethodDelete, path: path, idempotent: true}
+}
+
+// withIdempotencyKey makes a POST safe to retry on routes that honour the Idempotency-Key header
+func (r *request) withIdempotencyKey() *request {
+	r.idempotencyKey = uuid.NewString()
+	r.idempotent = true
+	return r
+}
+
+// do sends a request, retrying it when that is safe, and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, r *request, out any) error {
+	var payload []byte
+	if r.body != nil {
+		var err error
+		if payload, err = json.Marshal(r.body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	delay := retryDelay
+	for attempt := 0; ; attempt++ {
+		err := c.attempt(ctx, r, payload, out)
+		if err == nil || attempt >= c.maxRetries || !r.retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		wait := delay/2 + rand.N(delay/2+1)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			wait = apiErr.RetryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(min(wait, maxRetryDelay)):
+		}
+		delay *= 2
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, r *request, payload []byte, out any) error {
+	resp, err := c.send(ctx, c.httpClient, r.method, r.path, payload, r.idempotencyKey, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return decodeError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// send sends one authenticated request
+func (c *Client) send(ctx context.Context, httpClient *http.Client, method, path string, payload []byte, idempotencyKey, accept string) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", accept)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, &transportError{err: err}
+	}
+	return resp, nil
+}
+
+// retryable reports whether a failed attempt is worth repeating
+func (r *request) retryable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		// The request may or may not have reached the API
+		var tErr *transportError
+		return errors.As(err, &tErr) && r.idempotent
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests:
+		// Rate limited and saturated requests are turned away before they are handled
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return r.idempotent
+	default:
+		return false
+	}
+}
+
+// transportError is a request that failed before a response was received
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return fmt.Sprintf("request failed: %v", e.err)
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// decodeError reads an error response, falling back to the status when it isn't JSON
+func decodeError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err := json.Unmarshal(body, apiErr); err != nil || apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"net/url"
+)
+
+// CreateDeployment deploys a commit of a project. Retries of the request deploy it once.
+func (c *Client) CreateDeployment(ctx context.Context, req *CreateDeploymentRequest) (*Deployment, error) {
+	var dep Deployment
+	if err := c.do(ctx, post("/deployments", req).withIdempotencyKey(), &dep); err != nil {
+		return nil, err
+	}
+	return &dep, nil
+}
+
+// GetDeployment returns a deployment with its logs
+func (c *Client) GetDeployment(ctx context.Context, deploymentID string) (*Deployment, error) {
+	var dep Deployment
+	if err := c.do(ctx, get("/deployments/"+url.PathEscape(deploymentID)), &dep); err != nil {
+		return nil, err
+	}
+	return &dep, nil
+}
+
+// ListProjectDeployments returns a page of a project's deployments, newest first
+func (c *Client) ListProjectDeployments(ctx context.Context, projectID string, opts *ListOptions) (*DeploymentList, error) {
+	var deployments DeploymentList
+	if err := c.do(ctx, get("/projects/"+url.PathEscape(projectID)+"/deployments"+opts.query()), &deployments); err != nil {
+		return nil, err
+	}
+	return &deployments, nil
+}
+
+// LatestDeployment returns a project's most recent deployment
+func (c *Client) LatestDeployment(ctx context.Context, projectID string) (*Deployment, error) {
+	var dep Deployment
+	if err := c.do(ctx, get("/projects/"+url.PathEscape(projectID)+"/deployments/latest"), &dep); err != nil {
+		return nil, err
+	}
+	return &dep, nil
+}
+
+// DeleteDeployment deletes a deployment
+func (c *Client) DeleteDeployment(ctx context.Context, deploymentID string) error {
+	return c.do(ctx, del("/deployments/"+url.PathEscape(deploymentID)), nil)
+}
+
+// ApproveDeployment approves a deployment to a protected environment waiting for approval,
+// queueing its build. The comment is optional.
+func (c *Client) ApproveDeployment(ctx context.Context, deploymentID, comment string) (*Deployment, error) {
+	return c.decide(ctx, deploymentID, "approve", comment)
+}
+
+// RejectDeployment rejects a deployment to a protected environment waiting for approval.
+// The comment is optional.
+func (c *Client) RejectDeployment(ctx context.Context, deploymentID, comment string) (*Deployment, error) {
+	return c.decide(ctx, deploymentID, "reject", comment)
+}
+
+func (c *Client) decide(ctx context.Context, deploymentID, decision, comment string) (*Deployment, error) {
+	var dep Deployment
+	path := "/deployments/" + url.PathEscape(deploymentID) + "/" + decision
+	if err := c.do(ctx, post(path, &DeploymentDecisionRequest{Comment: comment}), &dep); err != nil {
+		return nil, err
+	}
+	return &dep, nil
+}
+
+// ListDeploymentApprovals returns who approved or rejected a deployment, oldest first
+func (c *Client) ListDeploymentApprovals(ctx context.Context, deploymentID string) (*DeploymentApprovalList, error) {
+	var approvals DeploymentApprovalList
+	if err := c.do(ctx, get("/deployments/"+url.PathEscape(deploymentID)+"/approvals"), &approvals); err != nil {
+		return nil, err
+	}
+	return &approvals, nil
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"net/url"
+)
+
+// ListEnvVars returns the environment variables of a project's environment with masked values.
+// An empty environment lists production's.
+func (c *Client) ListEnvVars(ctx context.Context, projectID, environment string) (*EnvVarList, error) {
+	var envVars EnvVarList
+	if err := c.do(ctx, get("/projects/"+url.PathEscape(projectID)+"/env"+environmentQuery(environment)), &envVars); err != nil {
+		return nil, err
+	}
+	return &envVars, nil
+}
+
+// SetEnvVar creates or updates an environment variable of a project. Changes apply on the next deployment.
+func (c *Client) SetEnvVar(ctx context.Context, projectID string, req *CreateEnvVarRequest) (*EnvVar, error) {
+	var envVar EnvVar
+	// Setting a variable twice leaves it as setting it once
+	r := post("/projects/"+url.PathEscape(projectID)+"/env", req)
+	r.idempotent = true
+	if err := c.do(ctx, r, &envVar); err != nil {
+		return nil, err
+	}
+	return &envVar, nil
+}
+
+// DeleteEnvVar deletes an environment variable of a project's environment.
+// An empty environment deletes production's.
+func (c *Client) DeleteEnvVar(ctx context.Context, projectID, key, environment string) error {
+	path := "/projects/" + url.PathEscape(projectID) + "/env/" + url.PathEscape(key) + environmentQuery(environment)
+	return c.do(ctx, del(path), nil)
+}
+
+func environmentQuery(environment string) string {
+	if environment == "" {
+		return ""
+	}
+	return "?environment=" + url.QueryEscape(environment)
+}
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// StreamLogs calls onLine with each log line of a deployment, starting with the lines logged so far,
+// until the context is cancelled or the API closes the stream. The stream doesn't end when the deployment
+// does, so poll GetDeployment to learn when to cancel it. Streams aren't retried, as reconnecting
+// replays every line.
+func (c *Client) StreamLogs(ctx context.Context, deploymentID string, onLine func(line string)) error {
+	resp, err := c.send(ctx, c.streamClient, http.MethodGet, "/deployments/"+url.PathEscape(deploymentID)+"/logs/stream", nil, "", "text/event-stream")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return decodeError(resp)
+	}
+
+	// Events are "event:<name>" and "data:<payload>" lines ended by a blank line; only log events carry lines
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var event string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			event = ""
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:") && event == "log":
+			onLine(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read log stream: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"net/url"
+)
+
+// CurrentUser returns the user the API token belongs to
+func (c *Client) CurrentUser(ctx context.Context) (*User, error) {
+	var user User
+	if err := c.do(ctx, get("/auth/me"), &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListProjects returns a page of a user's projects
+func (c *Client) ListProjects(ctx context.Context, userID string, opts *ListOptions) (*ProjectList, error) {
+	var projects ProjectList
+	if err := c.do(ctx, get("/users/"+url.PathEscape(userID)+"/projects"+opts.query()), &projects); err != nil {
+		return nil, err
+	}
+	return &projects, nil
+}
+
+// GetProject returns a project
+func (c *Client) GetProject(ctx context.Context, projectID string) (*Project, error) {
+	var proj Project
+	if err := c.do(ctx, get("/projects/"+url.PathEscape(projectID)), &proj); err != nil {
+		return nil, err
+	}
+	return &proj, nil
+}
+
+// CreateProject creates a project for a user. Retries of the request create it once.
+func (c *Client) CreateProject(ctx context.Context, userID string, req *CreateProjectRequest) (*Project, error) {
+	var proj Project
+	if err := c.do(ctx, post("/users/"+url.PathEscape(userID)+"/projects", req).withIdempotencyKey(), &proj); err != nil {
+		return nil, err
+	}
+	return &proj, nil
+}
+
+// UpdateProject replaces a project's settings
+func (c *Client) UpdateProject(ctx context.Context, projectID string, req *UpdateProjectRequest) (*Project, error) {
+	var proj Project
+	if err := c.do(ctx, put("/projects/"+url.PathEscape(projectID), req), &proj); err != nil {
+		return nil, err
+	}
+	return &proj, nil
+}
+
+// DeleteProject schedules the teardown of a project, returning it in the DELETING status
+func (c *Client) DeleteProject(ctx context.Context, projectID string) (*Project, error) {
+	var proj Project
+	if err := c.do(ctx, del("/projects/"+url.PathEscape(projectID)), &proj); err != nil {
+		return nil, err
+	}
+	return &proj, nil
+}
+
+// RestartProject redeploys the running image of a project's environment without rebuilding it.
+// An empty environment restarts production.
+func (c *Client) RestartProject(ctx context.Context, projectID, environment string) (*Deployment, error) {
+	path := "/projects/" + url.PathEscape(projectID) + "/restart"
+	if environment != "" {
+		path += "?environment=" + url.QueryEscape(environment)
+	}
+
+	var dep Deployment
+	if err := c.do(ctx, post(path, nil), &dep); err != nil {
+		return nil, err
+	}
+	return &dep, nil
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"snapdeploy-core/internal/application/dto"
+)
+
+// The API's request and response types, shared with the server so they can't drift apart
+
+type (
+	User       = dto.UserResponse
+	Pagination = dto.PaginationResponse
+
+	Project              = dto.ProjectResponse
+	ProjectList          = dto.ProjectListResponse
+	Service              = dto.ServiceResponse
+	Environment          = dto.EnvironmentResponse
+	CreateProjectRequest = dto.CreateProjectRequest
+	UpdateProjectRequest = dto.UpdateProjectRequest
+	ServiceRequest       = dto.ServiceRequest
+
+	Deployment                = dto.DeploymentResponse
+	DeploymentList            = dto.DeploymentListResponse
+	DeploymentApproval        = dto.DeploymentApprovalResponse
+	DeploymentApprovalList    = dto.DeploymentApprovalListResponse
+	CreateDeploymentRequest   = dto.CreateDeploymentRequest
+	DeploymentDecisionRequest = dto.DeploymentDecisionRequest
+
+	EnvVar              = dto.EnvVarResponse
+	EnvVarList          = dto.EnvVarListResponse
+	CreateEnvVarRequest = dto.CreateEnvVarRequest
+)
+
+// ListOptions selects a page of a list, the API's defaults apply to zero values
+type ListOptions struct {
+	Page   int    // 1-based
+	Limit  int    // At most 100
+	Cursor string // Pagination.NextCursor of the previous page, continues the list after it instead of selecting a page
+}
+
+func (o *ListOptions) query() string {
+	if o == nil {
+		return ""
+	}
+	var params []string
+	if o.Page > 0 {
+		params = append(params, fmt.Sprintf("page=%d", o.Page))
+	}
+	if o.Limit > 0 {
+		params = append(params, fmt.Sprintf("limit=%d", o.Limit))
+	}
+	if o.Cursor != "" {
+		params = append(params, "cursor="+url.QueryEscape(o.Cursor))
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return "?" + strings.Join(params, "&")
+}
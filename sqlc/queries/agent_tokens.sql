@@ -0,0 +1,23 @@
+-- name: CreateAgentToken :exec
+INSERT INTO agent_tokens (
+    id,
+    user_id,
+    name,
+    token_hash,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: GetAgentTokenByHash :one
+SELECT * FROM agent_tokens
+WHERE token_hash = $1;
+
+-- name: ListAgentTokensByUserID :many
+SELECT * FROM agent_tokens
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: DeleteAgentToken :execrows
+DELETE FROM agent_tokens
+WHERE id = $1 AND user_id = $2;
@@ -0,0 +1,48 @@
+-- name: GetBillingAccountByUserID :one
+SELECT * FROM billing_accounts
+WHERE user_id = $1;
+
+-- name: GetBillingAccountByCustomerID :one
+SELECT * FROM billing_accounts
+WHERE stripe_customer_id = $1;
+
+-- name: ListBillingAccounts :many
+SELECT * FROM billing_accounts
+ORDER BY created_at, user_id
+LIMIT $1 OFFSET $2;
+
+-- name: ListUnbilledUserIDs :many
+SELECT u.id FROM users u
+LEFT JOIN billing_accounts b ON b.user_id = u.id
+WHERE b.user_id IS NULL
+ORDER BY u.created_at
+LIMIT $1;
+
+-- name: UpsertBillingAccount :exec
+INSERT INTO billing_accounts (
+    user_id,
+    stripe_customer_id,
+    stripe_subscription_id,
+    plan,
+    status,
+    suspended_at,
+    build_minutes_reported_until,
+    build_minutes_carry,
+    vcpu_hours_reported_until,
+    vcpu_hours_carry,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    stripe_customer_id = EXCLUDED.stripe_customer_id,
+    stripe_subscription_id = EXCLUDED.stripe_subscription_id,
+    plan = EXCLUDED.plan,
+    status = EXCLUDED.status,
+    suspended_at = EXCLUDED.suspended_at,
+    build_minutes_reported_until = EXCLUDED.build_minutes_reported_until,
+    build_minutes_carry = EXCLUDED.build_minutes_carry,
+    vcpu_hours_reported_until = EXCLUDED.vcpu_hours_reported_until,
+    vcpu_hours_carry = EXCLUDED.vcpu_hours_carry,
+    updated_at = EXCLUDED.updated_at;
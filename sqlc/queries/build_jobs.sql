@@ -0,0 +1,46 @@
+-- name: CreateBuildJob :exec
+INSERT INTO build_jobs (
+    deployment_id,
+    project_id,
+    status,
+    attempts,
+    last_error,
+    created_at,
+    updated_at,
+    correlation_id,
+    trace_parent
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+);
+
+-- name: ClaimBuildJob :one
+UPDATE build_jobs
+SET status = 'RUNNING', attempts = attempts + 1, claimed_at = NOW(), updated_at = NOW()
+WHERE deployment_id = (
+    SELECT candidate.deployment_id FROM build_jobs candidate
+    JOIN deployments d ON d.id = candidate.deployment_id
+    WHERE (candidate.status = 'PENDING'
+           OR (candidate.status = 'RUNNING' AND candidate.claimed_at < sqlc.arg(stale_before)))
+      AND (
+          SELECT COUNT(*) FROM deployments running
+          WHERE running.user_id = d.user_id AND running.status IN ('BUILDING', 'DEPLOYING')
+      ) < sqlc.arg(max_running_per_user)::bigint
+    ORDER BY candidate.created_at
+    LIMIT 1
+    FOR UPDATE OF candidate SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CountOpenBuildJobs :one
+SELECT COUNT(*) FROM build_jobs
+WHERE status IN ('PENDING', 'RUNNING');
+
+-- name: UpdateBuildJob :exec
+UPDATE build_jobs
+SET status = $2, last_error = $3, updated_at = $4
+WHERE deployment_id = $1;
+
+-- name: RequeueBuildJob :exec
+UPDATE build_jobs
+SET status = 'PENDING', attempts = 0, last_error = '', claimed_at = NULL, updated_at = NOW()
+WHERE deployment_id = $1;
@@ -0,0 +1,33 @@
+-- name: UpsertCommandRun :exec
+INSERT INTO command_runs (
+    id,
+    project_id,
+    deployment_id,
+    user_id,
+    environment,
+    command,
+    status,
+    exit_code,
+    error,
+    output,
+    started_at,
+    finished_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+ON CONFLICT (id) DO UPDATE SET
+    status = EXCLUDED.status,
+    exit_code = EXCLUDED.exit_code,
+    error = EXCLUDED.error,
+    output = EXCLUDED.output,
+    finished_at = EXCLUDED.finished_at;
+
+-- name: GetCommandRunByID :one
+SELECT * FROM command_runs
+WHERE id = $1;
+
+-- name: ListCommandRunsByProjectID :many
+SELECT * FROM command_runs
+WHERE project_id = $1
+ORDER BY started_at DESC
+LIMIT $2;
@@ -0,0 +1,35 @@
+-- name: UpsertCronRun :one
+INSERT INTO cron_runs (
+    id,
+    project_id,
+    deployment_id,
+    task_arn,
+    status,
+    exit_code,
+    reason,
+    started_at,
+    stopped_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (task_arn) DO UPDATE SET
+    status = EXCLUDED.status,
+    exit_code = EXCLUDED.exit_code,
+    reason = EXCLUDED.reason,
+    started_at = EXCLUDED.started_at,
+    stopped_at = EXCLUDED.stopped_at
+RETURNING *;
+
+-- name: GetCronRunByID :one
+SELECT * FROM cron_runs
+WHERE id = $1;
+
+-- name: GetCronRunByTaskARN :one
+SELECT * FROM cron_runs
+WHERE task_arn = $1;
+
+-- name: ListCronRunsByDeploymentID :many
+SELECT * FROM cron_runs
+WHERE deployment_id = $1
+ORDER BY started_at DESC
+LIMIT $2;
@@ -0,0 +1,62 @@
+-- name: CreateDatabaseSnapshot :one
+INSERT INTO database_snapshots (
+    id,
+    project_id,
+    database_name,
+    size_bytes,
+    created_at,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetDatabaseSnapshotByID :one
+SELECT * FROM database_snapshots
+WHERE id = $1;
+
+-- name: ListDatabaseSnapshotsByProjectID :many
+SELECT * FROM database_snapshots
+WHERE project_id = $1
+ORDER BY created_at DESC;
+
+-- name: ListExpiredDatabaseSnapshots :many
+SELECT * FROM database_snapshots
+WHERE expires_at < $1
+ORDER BY expires_at ASC;
+
+-- name: DeleteDatabaseSnapshot :exec
+DELETE FROM database_snapshots
+WHERE id = $1;
+
+-- name: CreateDatabaseBranch :one
+INSERT INTO database_branches (
+    id,
+    project_id,
+    snapshot_id,
+    name,
+    database_name,
+    created_at,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetDatabaseBranchByID :one
+SELECT * FROM database_branches
+WHERE id = $1;
+
+-- name: ListDatabaseBranchesByProjectID :many
+SELECT * FROM database_branches
+WHERE project_id = $1
+ORDER BY created_at DESC;
+
+-- name: ListExpiredDatabaseBranches :many
+SELECT * FROM database_branches
+WHERE expires_at < $1
+ORDER BY expires_at ASC;
+
+-- name: DeleteDatabaseBranch :exec
+DELETE FROM database_branches
+WHERE id = $1;
@@ -0,0 +1,18 @@
+-- name: GetDatastoreCredential :one
+SELECT * FROM datastore_credentials
+WHERE project_id = $1 AND datastore = $2;
+
+-- name: CreateDatastoreCredential :execrows
+INSERT INTO datastore_credentials (
+    project_id,
+    datastore,
+    username,
+    password_encrypted
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (project_id, datastore) DO NOTHING;
+
+-- name: DeleteDatastoreCredential :exec
+DELETE FROM datastore_credentials
+WHERE project_id = $1 AND datastore = $2;
@@ -0,0 +1,17 @@
+-- name: CreateDeploymentApproval :exec
+INSERT INTO deployment_approvals (
+    id,
+    deployment_id,
+    project_id,
+    user_id,
+    decision,
+    comment,
+    decided_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+);
+
+-- name: ListDeploymentApprovals :many
+SELECT * FROM deployment_approvals
+WHERE deployment_id = $1
+ORDER BY decided_at;
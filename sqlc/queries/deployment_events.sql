@@ -0,0 +1,17 @@
+-- name: CreateDeploymentEvent :exec
+INSERT INTO deployment_events (
+    id,
+    deployment_id,
+    project_id,
+    source,
+    type,
+    message,
+    occurred_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+);
+
+-- name: ListDeploymentEvents :many
+SELECT * FROM deployment_events
+WHERE deployment_id = $1
+ORDER BY occurred_at;
@@ -0,0 +1,20 @@
+-- name: UpsertDeploymentManifest :exec
+INSERT INTO deployment_manifests (
+    deployment_id,
+    project_id,
+    image_tag,
+    env_vars,
+    config,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (deployment_id) DO UPDATE SET
+    image_tag = EXCLUDED.image_tag,
+    env_vars = EXCLUDED.env_vars,
+    config = EXCLUDED.config,
+    created_at = EXCLUDED.created_at;
+
+-- name: GetDeploymentManifest :one
+SELECT * FROM deployment_manifests
+WHERE deployment_id = $1;
@@ -0,0 +1,32 @@
+-- name: UpsertDeploymentScan :exec
+INSERT INTO deployment_scans (
+    deployment_id,
+    project_id,
+    image_uri,
+    status,
+    error,
+    severity_counts,
+    vulnerabilities,
+    sbom_format,
+    sbom_packages,
+    sbom,
+    blocked,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+ON CONFLICT (deployment_id) DO UPDATE SET
+    image_uri = EXCLUDED.image_uri,
+    status = EXCLUDED.status,
+    error = EXCLUDED.error,
+    severity_counts = EXCLUDED.severity_counts,
+    vulnerabilities = EXCLUDED.vulnerabilities,
+    sbom_format = EXCLUDED.sbom_format,
+    sbom_packages = EXCLUDED.sbom_packages,
+    sbom = EXCLUDED.sbom,
+    blocked = EXCLUDED.blocked,
+    created_at = EXCLUDED.created_at;
+
+-- name: GetDeploymentScan :one
+SELECT * FROM deployment_scans
+WHERE deployment_id = $1;
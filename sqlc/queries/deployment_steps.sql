@@ -0,0 +1,22 @@
+-- name: UpsertDeploymentStep :exec
+INSERT INTO deployment_steps (
+    deployment_id,
+    project_id,
+    step,
+    status,
+    error,
+    started_at,
+    finished_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (deployment_id, step) DO UPDATE SET
+    status = EXCLUDED.status,
+    error = EXCLUDED.error,
+    started_at = EXCLUDED.started_at,
+    finished_at = EXCLUDED.finished_at;
+
+-- name: GetDeploymentSteps :many
+SELECT * FROM deployment_steps
+WHERE deployment_id = $1
+ORDER BY started_at;
@@ -8,51 +8,423 @@ INSERT INTO deployments (
     status,
     logs,
     created_at,
-    updated_at
+    updated_at,
+    type,
+    environment,
+    image_uri,
+    image_digest,
+    title,
+    description,
+    labels
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
 )
 RETURNING *;
 
 -- name: GetDeploymentByID :one
 SELECT * FROM deployments
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetArchivedDeploymentByID :one
+SELECT * FROM deployments_archive
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetDeploymentByIDIncludingDeleted :one
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.id = $1
+UNION ALL
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.id = $1
+LIMIT 1;
+
+-- name: ExistsDeploymentByID :one
+SELECT EXISTS(
+    SELECT 1 FROM deployments
+    WHERE id = $1
+);
 
 -- name: GetDeploymentsByProjectID :many
-SELECT * FROM deployments
-WHERE project_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3;
+SELECT * FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.project_id = sqlc.arg(project_id) AND deployments.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamp IS NULL OR deployments.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments.labels @> sqlc.narg(labels))
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.project_id = sqlc.arg(project_id) AND deployments_archive.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments_archive.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments_archive.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamptz IS NULL OR deployments_archive.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments_archive.labels @> sqlc.narg(labels))
+) AS history
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: GetDeploymentsByProjectIDAscending :many
+SELECT * FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.project_id = sqlc.arg(project_id) AND deployments.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamp IS NULL OR deployments.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments.labels @> sqlc.narg(labels))
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.project_id = sqlc.arg(project_id) AND deployments_archive.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments_archive.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments_archive.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamptz IS NULL OR deployments_archive.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments_archive.labels @> sqlc.narg(labels))
+) AS history
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: GetDeploymentsByProjectIDIncludingDeleted :many
+SELECT * FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.project_id = sqlc.arg(project_id)
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamp IS NULL OR deployments.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments.labels @> sqlc.narg(labels))
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.project_id = sqlc.arg(project_id)
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments_archive.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments_archive.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamptz IS NULL OR deployments_archive.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments_archive.labels @> sqlc.narg(labels))
+) AS history
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: GetDeploymentsByProjectIDIncludingDeletedAscending :many
+SELECT * FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.project_id = sqlc.arg(project_id)
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamp IS NULL OR deployments.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments.labels @> sqlc.narg(labels))
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.project_id = sqlc.arg(project_id)
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments_archive.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments_archive.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamptz IS NULL OR deployments_archive.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments_archive.labels @> sqlc.narg(labels))
+) AS history
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
 
 -- name: GetDeploymentsByUserID :many
-SELECT * FROM deployments
-WHERE user_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3;
+SELECT * FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.user_id = sqlc.arg(user_id) AND deployments.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamp IS NULL OR deployments.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments.labels @> sqlc.narg(labels))
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.user_id = sqlc.arg(user_id) AND deployments_archive.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments_archive.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments_archive.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamptz IS NULL OR deployments_archive.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments_archive.labels @> sqlc.narg(labels))
+) AS history
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: GetDeploymentsByUserIDAscending :many
+SELECT * FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.user_id = sqlc.arg(user_id) AND deployments.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamp IS NULL OR deployments.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments.labels @> sqlc.narg(labels))
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.user_id = sqlc.arg(user_id) AND deployments_archive.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments_archive.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments_archive.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamptz IS NULL OR deployments_archive.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments_archive.labels @> sqlc.narg(labels))
+) AS history
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: GetDeploymentsByProjectIDAfter :many
+SELECT * FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.project_id = sqlc.arg(project_id) AND deployments.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamp IS NULL OR deployments.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments.labels @> sqlc.narg(labels))
+        AND (deployments.created_at, deployments.id) < (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::uuid)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.project_id = sqlc.arg(project_id) AND deployments_archive.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments_archive.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments_archive.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamptz IS NULL OR deployments_archive.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments_archive.labels @> sqlc.narg(labels))
+        AND (deployments_archive.created_at, deployments_archive.id) < (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::uuid)
+) AS history
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetDeploymentsByProjectIDAfterAscending :many
+SELECT * FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.project_id = sqlc.arg(project_id) AND deployments.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamp IS NULL OR deployments.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments.labels @> sqlc.narg(labels))
+        AND (deployments.created_at, deployments.id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::uuid)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.project_id = sqlc.arg(project_id) AND deployments_archive.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments_archive.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments_archive.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamptz IS NULL OR deployments_archive.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments_archive.labels @> sqlc.narg(labels))
+        AND (deployments_archive.created_at, deployments_archive.id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::uuid)
+) AS history
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetDeploymentsByProjectIDIncludingDeletedAfter :many
+SELECT * FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.project_id = sqlc.arg(project_id)
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamp IS NULL OR deployments.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments.labels @> sqlc.narg(labels))
+        AND (deployments.created_at, deployments.id) < (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::uuid)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.project_id = sqlc.arg(project_id)
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments_archive.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments_archive.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamptz IS NULL OR deployments_archive.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments_archive.labels @> sqlc.narg(labels))
+        AND (deployments_archive.created_at, deployments_archive.id) < (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::uuid)
+) AS history
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetDeploymentsByProjectIDIncludingDeletedAfterAscending :many
+SELECT * FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.project_id = sqlc.arg(project_id)
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamp IS NULL OR deployments.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments.labels @> sqlc.narg(labels))
+        AND (deployments.created_at, deployments.id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::uuid)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.project_id = sqlc.arg(project_id)
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments_archive.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments_archive.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamptz IS NULL OR deployments_archive.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments_archive.labels @> sqlc.narg(labels))
+        AND (deployments_archive.created_at, deployments_archive.id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::uuid)
+) AS history
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetDeploymentsByUserIDAfter :many
+SELECT * FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.user_id = sqlc.arg(user_id) AND deployments.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamp IS NULL OR deployments.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments.labels @> sqlc.narg(labels))
+        AND (deployments.created_at, deployments.id) < (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::uuid)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.user_id = sqlc.arg(user_id) AND deployments_archive.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments_archive.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments_archive.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamptz IS NULL OR deployments_archive.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments_archive.labels @> sqlc.narg(labels))
+        AND (deployments_archive.created_at, deployments_archive.id) < (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::uuid)
+) AS history
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetDeploymentsByUserIDAfterAscending :many
+SELECT * FROM (
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments WHERE deployments.user_id = sqlc.arg(user_id) AND deployments.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamp IS NULL OR deployments.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments.labels @> sqlc.narg(labels))
+        AND (deployments.created_at, deployments.id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::uuid)
+    UNION ALL
+    SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM deployments_archive WHERE deployments_archive.user_id = sqlc.arg(user_id) AND deployments_archive.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments_archive.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments_archive.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamptz IS NULL OR deployments_archive.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments_archive.labels @> sqlc.narg(labels))
+        AND (deployments_archive.created_at, deployments_archive.id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::uuid)
+) AS history
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(page_size);
 
 -- name: CountDeploymentsByProjectID :one
+SELECT (
+    (SELECT COUNT(*) FROM deployments WHERE deployments.project_id = sqlc.arg(project_id) AND deployments.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamp IS NULL OR deployments.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments.labels @> sqlc.narg(labels))) +
+    (SELECT COUNT(*) FROM deployments_archive WHERE deployments_archive.project_id = sqlc.arg(project_id) AND deployments_archive.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments_archive.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments_archive.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamptz IS NULL OR deployments_archive.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments_archive.labels @> sqlc.narg(labels)))
+)::bigint AS count;
+
+-- name: CountDeploymentsByProjectIDIncludingDeleted :one
+SELECT (
+    (SELECT COUNT(*) FROM deployments WHERE deployments.project_id = sqlc.arg(project_id)
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamp IS NULL OR deployments.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments.labels @> sqlc.narg(labels))) +
+    (SELECT COUNT(*) FROM deployments_archive WHERE deployments_archive.project_id = sqlc.arg(project_id)
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments_archive.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments_archive.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamptz IS NULL OR deployments_archive.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments_archive.labels @> sqlc.narg(labels)))
+)::bigint AS count;
+
+-- name: CountInProgressDeploymentsByUserID :one
 SELECT COUNT(*) FROM deployments
-WHERE project_id = $1;
+WHERE user_id = $1 AND status IN ('PENDING', 'BUILDING', 'DEPLOYING', 'INTERRUPTED') AND deleted_at IS NULL;
 
 -- name: CountDeploymentsByUserID :one
-SELECT COUNT(*) FROM deployments
-WHERE user_id = $1;
+SELECT (
+    (SELECT COUNT(*) FROM deployments WHERE deployments.user_id = sqlc.arg(user_id) AND deployments.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamp IS NULL OR deployments.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments.labels @> sqlc.narg(labels))) +
+    (SELECT COUNT(*) FROM deployments_archive WHERE deployments_archive.user_id = sqlc.arg(user_id) AND deployments_archive.deleted_at IS NULL
+        AND (sqlc.narg(status)::varchar IS NULL OR deployments_archive.status = sqlc.narg(status))
+        AND (sqlc.narg(branch)::varchar IS NULL OR deployments_archive.branch = sqlc.narg(branch))
+        AND (sqlc.narg(since)::timestamptz IS NULL OR deployments_archive.created_at >= sqlc.narg(since))
+        AND (sqlc.narg(labels)::jsonb IS NULL OR deployments_archive.labels @> sqlc.narg(labels)))
+)::bigint AS count;
 
 -- name: UpdateDeployment :exec
 UPDATE deployments
 SET
     status = $2,
     logs = $3,
-    updated_at = $4
+    updated_at = $4,
+    image_uri = $5,
+    image_digest = $6,
+    title = $7,
+    description = $8,
+    labels = $9,
+    pinned = $10
 WHERE id = $1;
 
--- name: DeleteDeployment :exec
-DELETE FROM deployments
-WHERE id = $1;
+-- name: UpdateDeploymentLogs :execrows
+UPDATE deployments
+SET
+    logs = $2,
+    updated_at = $3
+WHERE id = $1 AND (updated_at IS NULL OR updated_at < $3);
+
+-- name: SoftDeleteDeployment :exec
+WITH archived AS (
+    UPDATE deployments_archive SET deleted_at = $2
+    WHERE deployments_archive.id = $1 AND deployments_archive.deleted_at IS NULL
+), cancelled_build AS (
+    DELETE FROM build_jobs WHERE build_jobs.deployment_id = $1 AND build_jobs.status = 'PENDING'
+)
+UPDATE deployments
+SET deleted_at = $2
+WHERE deployments.id = $1 AND deployments.deleted_at IS NULL;
+
+-- name: PurgeDeletedDeployments :one
+WITH purged AS (
+    DELETE FROM deployments
+    WHERE deployments.id IN (
+        SELECT d.id FROM deployments d
+        WHERE d.deleted_at < $1
+        ORDER BY d.deleted_at
+        LIMIT $2
+    )
+    RETURNING deployments.id
+), purged_archive AS (
+    DELETE FROM deployments_archive
+    WHERE deployments_archive.id IN (
+        SELECT a.id FROM deployments_archive a
+        WHERE a.deleted_at < $1
+        ORDER BY a.deleted_at
+        LIMIT $2
+    )
+    RETURNING deployments_archive.id
+), timeline AS (
+    DELETE FROM deployment_events
+    WHERE deployment_events.deployment_id IN (SELECT id FROM purged UNION ALL SELECT id FROM purged_archive)
+), approvals AS (
+    DELETE FROM deployment_approvals
+    WHERE deployment_approvals.deployment_id IN (SELECT id FROM purged UNION ALL SELECT id FROM purged_archive)
+), manifests AS (
+    DELETE FROM deployment_manifests
+    WHERE deployment_manifests.deployment_id IN (SELECT id FROM purged UNION ALL SELECT id FROM purged_archive)
+), steps AS (
+    DELETE FROM deployment_steps
+    WHERE deployment_steps.deployment_id IN (SELECT id FROM purged UNION ALL SELECT id FROM purged_archive)
+)
+SELECT (
+    (SELECT COUNT(*) FROM purged) + (SELECT COUNT(*) FROM purged_archive)
+)::bigint AS count;
 
 -- name: GetLatestDeploymentByProjectID :one
 SELECT * FROM deployments
-WHERE project_id = $1
+WHERE project_id = $1 AND deleted_at IS NULL
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: GetLatestDeploymentInEnvironment :one
+SELECT * FROM deployments
+WHERE project_id = $1 AND environment = $2 AND deleted_at IS NULL
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: GetLatestDeployedDeployments :many
+SELECT DISTINCT ON (project_id, environment) * FROM deployments
+WHERE status = 'DEPLOYED' AND deleted_at IS NULL
+ORDER BY project_id, environment, created_at DESC;
+
+-- name: GetLatestDeployedDeploymentInEnvironment :one
+SELECT * FROM deployments
+WHERE project_id = $1 AND environment = $2 AND status = 'DEPLOYED' AND deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT 1;
 
+-- name: GetPinnedDeploymentsByProjectID :many
+SELECT * FROM deployments
+WHERE project_id = $1 AND pinned AND deleted_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: GetStuckDeployments :many
+SELECT * FROM deployments
+WHERE status IN ('PENDING', 'BUILDING', 'DEPLOYING', 'INTERRUPTED')
+  AND updated_at < $1
+  AND deleted_at IS NULL
+ORDER BY updated_at
+LIMIT $2;
+
+-- name: ArchiveDeployments :execrows
+WITH archived AS (
+    DELETE FROM deployments
+    WHERE deployments.id IN (
+        SELECT d.id FROM deployments d
+        WHERE d.created_at < $1
+          AND d.status IN ('DEPLOYED', 'FAILED', 'ROLLED_BACK', 'REJECTED')
+          AND d.deleted_at IS NULL
+          AND NOT d.pinned
+          AND d.id NOT IN (
+              SELECT DISTINCT ON (latest.project_id, latest.environment) latest.id FROM deployments latest
+              WHERE latest.status = 'DEPLOYED' AND latest.deleted_at IS NULL
+              ORDER BY latest.project_id, latest.environment, latest.created_at DESC
+          )
+        ORDER BY d.created_at
+        LIMIT $2
+    )
+    RETURNING id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned
+)
+INSERT INTO deployments_archive (id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned)
+SELECT id, project_id, user_id, commit_hash, branch, status, logs, created_at, updated_at, type, deleted_at, environment, image_uri, image_digest, title, description, labels, pinned FROM archived;
@@ -0,0 +1,13 @@
+-- name: ListEnvironmentApprovers :many
+SELECT user_id FROM environment_approvers
+WHERE project_id = $1 AND environment = $2
+ORDER BY created_at, user_id;
+
+-- name: DeleteEnvironmentApprovers :exec
+DELETE FROM environment_approvers
+WHERE project_id = $1 AND environment = $2;
+
+-- name: CreateEnvironmentApprovers :execrows
+INSERT INTO environment_approvers (project_id, environment, user_id)
+SELECT sqlc.arg(project_id), sqlc.arg(environment), id FROM users
+WHERE id = ANY(sqlc.arg(user_ids)::uuid[]);
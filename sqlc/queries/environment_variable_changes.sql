@@ -0,0 +1,26 @@
+-- name: UpsertEnvironmentVariableChange :exec
+INSERT INTO environment_variable_changes (
+    project_id,
+    environment,
+    changed_at
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (project_id, environment) DO UPDATE SET
+    changed_at = EXCLUDED.changed_at,
+    applied_deployment_id = NULL,
+    applied_at = NULL;
+
+-- name: ApplyEnvironmentVariableChange :execrows
+UPDATE environment_variable_changes
+SET
+    applied_deployment_id = $3,
+    applied_at = $4
+WHERE project_id = $1
+  AND environment = $2
+  AND applied_deployment_id IS NULL
+  AND changed_at <= $5;
+
+-- name: GetEnvironmentVariableChange :one
+SELECT * FROM environment_variable_changes
+WHERE project_id = $1 AND environment = $2;
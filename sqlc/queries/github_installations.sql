@@ -0,0 +1,34 @@
+-- name: GetGitHubInstallation :one
+SELECT * FROM github_installations
+WHERE id = $1;
+
+-- name: ListGitHubInstallationsByUserID :many
+SELECT * FROM github_installations
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: UpsertGitHubInstallation :exec
+INSERT INTO github_installations (
+    id,
+    user_id,
+    account_id,
+    account_login,
+    account_type,
+    installed_by_id,
+    suspended,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (id)
+DO UPDATE SET
+    user_id = EXCLUDED.user_id,
+    account_login = EXCLUDED.account_login,
+    installed_by_id = EXCLUDED.installed_by_id,
+    suspended = EXCLUDED.suspended,
+    updated_at = EXCLUDED.updated_at;
+
+-- name: DeleteGitHubInstallation :exec
+DELETE FROM github_installations
+WHERE id = $1;
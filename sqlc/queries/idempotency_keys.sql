@@ -0,0 +1,37 @@
+-- name: ReserveIdempotencyKey :execrows
+INSERT INTO idempotency_keys (
+    owner_id,
+    idempotency_key,
+    route,
+    request_hash,
+    created_at,
+    expires_at
+) VALUES (
+    sqlc.arg(owner_id), sqlc.arg(idempotency_key), sqlc.arg(route), sqlc.arg(request_hash), sqlc.arg(created_at), sqlc.arg(expires_at)
+)
+ON CONFLICT (owner_id, idempotency_key) DO UPDATE
+SET route = EXCLUDED.route,
+    request_hash = EXCLUDED.request_hash,
+    status_code = NULL,
+    response_body = NULL,
+    created_at = EXCLUDED.created_at,
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+   OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at < sqlc.arg(stale_before));
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE owner_id = $1 AND idempotency_key = $2;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status_code = $3, response_body = $4
+WHERE owner_id = $1 AND idempotency_key = $2;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE owner_id = $1 AND idempotency_key = $2;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= $1;
@@ -0,0 +1,22 @@
+-- name: GetListenerRulePriority :one
+SELECT priority FROM listener_rule_priorities
+WHERE listener_arn = $1 AND service_name = $2;
+
+-- name: ListListenerRulePriorities :many
+SELECT priority FROM listener_rule_priorities
+WHERE listener_arn = $1
+ORDER BY priority;
+
+-- name: ReleaseListenerRulePriority :exec
+DELETE FROM listener_rule_priorities
+WHERE listener_arn = $1 AND service_name = $2;
+
+-- name: ReserveListenerRulePriority :execrows
+INSERT INTO listener_rule_priorities (
+    listener_arn,
+    priority,
+    service_name
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT DO NOTHING;
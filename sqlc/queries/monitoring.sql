@@ -0,0 +1,56 @@
+-- name: CreateHealthCheck :exec
+INSERT INTO health_checks (
+    project_id,
+    url,
+    healthy,
+    status_code,
+    latency_ms,
+    error,
+    checked_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+);
+
+-- name: ListRecentHealthChecks :many
+SELECT * FROM health_checks
+WHERE project_id = $1
+ORDER BY checked_at DESC
+LIMIT $2;
+
+-- name: CountHealthChecksSince :one
+SELECT
+    COUNT(*) FILTER (WHERE healthy)::bigint AS succeeded,
+    COUNT(*)::bigint AS total
+FROM health_checks
+WHERE project_id = $1 AND checked_at >= $2;
+
+-- name: DeleteHealthChecksBefore :execrows
+DELETE FROM health_checks
+WHERE checked_at < $1;
+
+-- name: UpsertProjectIncident :one
+INSERT INTO project_incidents (
+    id,
+    project_id,
+    cause,
+    failed_checks,
+    started_at,
+    resolved_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (id) DO UPDATE SET
+    cause = EXCLUDED.cause,
+    failed_checks = EXCLUDED.failed_checks,
+    resolved_at = EXCLUDED.resolved_at
+RETURNING *;
+
+-- name: GetOpenProjectIncident :one
+SELECT * FROM project_incidents
+WHERE project_id = $1 AND resolved_at IS NULL;
+
+-- name: ListProjectIncidents :many
+SELECT * FROM project_incidents
+WHERE project_id = $1
+ORDER BY started_at DESC
+LIMIT $2;
@@ -2,32 +2,39 @@
 INSERT INTO project_environment_variables (
     project_id,
     key,
-    value
+    value,
+    scope,
+    environment
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5
 )
 RETURNING *;
 
 -- name: GetProjectEnvVars :many
 SELECT * FROM project_environment_variables
-WHERE project_id = $1
+WHERE project_id = $1 AND environment = $2
 ORDER BY key ASC;
 
 -- name: GetProjectEnvVar :one
 SELECT * FROM project_environment_variables
-WHERE project_id = $1 AND key = $2;
+WHERE project_id = $1 AND key = $2 AND environment = $3;
 
 -- name: UpdateProjectEnvVar :one
 UPDATE project_environment_variables
 SET 
     value = $3,
+    scope = $4,
     updated_at = CURRENT_TIMESTAMP
-WHERE project_id = $1 AND key = $2
+WHERE project_id = $1 AND key = $2 AND environment = $5
 RETURNING *;
 
 -- name: DeleteProjectEnvVar :exec
 DELETE FROM project_environment_variables
-WHERE project_id = $1 AND key = $2;
+WHERE project_id = $1 AND key = $2 AND environment = $3;
+
+-- name: DeleteProjectEnvironmentEnvVars :exec
+DELETE FROM project_environment_variables
+WHERE project_id = $1 AND environment = $2;
 
 -- name: DeleteAllProjectEnvVars :exec
 DELETE FROM project_environment_variables
@@ -35,5 +42,25 @@ WHERE project_id = $1;
 
 -- name: CountProjectEnvVars :one
 SELECT COUNT(*) FROM project_environment_variables
-WHERE project_id = $1;
+WHERE project_id = $1 AND environment = $2;
 
+-- name: CountProjectEnvVarsByKeyID :many
+SELECT (CASE WHEN strpos(value, ':') = 0 THEN 'default' ELSE split_part(value, ':', 1) END)::text AS key_id,
+    COUNT(*) AS count
+FROM project_environment_variables
+WHERE value <> ''
+GROUP BY 1
+ORDER BY 1;
+
+-- name: ListProjectEnvVarsToReencrypt :many
+SELECT id, value FROM project_environment_variables
+WHERE value <> ''
+  AND id > sqlc.arg(after_id)::uuid
+  AND (CASE WHEN strpos(value, ':') = 0 THEN 'default' ELSE split_part(value, ':', 1) END) <> sqlc.arg(key_id)::text
+ORDER BY id
+LIMIT sqlc.arg(batch_size);
+
+-- name: ReencryptProjectEnvVar :execrows
+UPDATE project_environment_variables
+SET value = sqlc.arg(value)
+WHERE id = sqlc.arg(id) AND value = sqlc.arg(previous_value);
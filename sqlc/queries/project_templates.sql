@@ -0,0 +1,7 @@
+-- name: ListProjectTemplates :many
+SELECT * FROM project_templates
+ORDER BY position, id;
+
+-- name: GetProjectTemplateByID :one
+SELECT * FROM project_templates
+WHERE id = $1;
@@ -1,19 +1,56 @@
 -- name: GetProjectByID :one
 SELECT * FROM projects
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetProjectByIDIncludingDeleted :one
+SELECT * FROM projects
 WHERE id = $1;
 
 -- name: GetProjectsByUserID :many
 SELECT * FROM projects
+WHERE user_id = $1 AND deleted_at IS NULL
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: GetProjectsByUserIDIncludingDeleted :many
+SELECT * FROM projects
 WHERE user_id = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3;
 
+-- name: ListProjects :many
+SELECT * FROM projects
+WHERE deleted_at IS NULL
+ORDER BY created_at, id
+LIMIT $1 OFFSET $2;
+
+-- name: ListProjectsByRepositoryURL :many
+SELECT * FROM projects
+WHERE repository_url IN (sqlc.arg(repository_url)::text, sqlc.arg(repository_url)::text || '.git') AND deleted_at IS NULL
+ORDER BY created_at, id;
+
+-- name: ListProjectsByCustomDomains :many
+SELECT * FROM projects
+WHERE custom_domain = ANY(sqlc.arg(custom_domains)::text[]) AND custom_domain != '' AND deleted_at IS NULL
+ORDER BY created_at, id;
+
+-- name: ListProjectsWithCronJobs :many
+SELECT * FROM projects
+WHERE (project_type = 'CRON' OR services @> '[{"type": "CRON"}]') AND deleted_at IS NULL
+ORDER BY created_at, id;
+
 -- name: GetProjectByRepositoryURL :one
 SELECT * FROM projects
-WHERE user_id = $1 AND repository_url = $2;
+WHERE user_id = $1 AND repository_url = $2 AND deleted_at IS NULL
+ORDER BY created_at, id
+LIMIT 1;
 
 -- name: CountProjectsByUserID :one
 SELECT COUNT(*) FROM projects
+WHERE user_id = $1 AND deleted_at IS NULL;
+
+-- name: CountProjectsByUserIDIncludingDeleted :one
+SELECT COUNT(*) FROM projects
 WHERE user_id = $1;
 
 -- name: CreateProject :one
@@ -26,9 +63,33 @@ INSERT INTO projects (
     language,
     custom_domain,
     require_db,
-    migration_command
+    migration_command,
+    image_retention,
+    deployment_strategy,
+    canary_percent,
+    canary_bake_minutes,
+    datastores,
+    volume_mount_path,
+    volume_size_gb,
+    project_type,
+    schedule,
+    services,
+    environments,
+    protected_environments,
+    container_port,
+    health_check_path,
+    cpu,
+    memory,
+    output_directory,
+    deployment_target,
+    lambda_endpoint,
+    deploy_rules,
+    public_status_page,
+    build_size,
+    build_timeout_minutes,
+    redeploy_on_env_change
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33
 )
 RETURNING *;
 
@@ -43,27 +104,89 @@ SET
     custom_domain = $7,
     require_db = $8,
     migration_command = $9,
+    status = $10,
+    status_message = $11,
+    image_retention = $12,
+    deployment_strategy = $13,
+    canary_percent = $14,
+    canary_bake_minutes = $15,
+    datastores = $16,
+    volume_mount_path = $17,
+    volume_size_gb = $18,
+    project_type = $19,
+    schedule = $20,
+    services = $21,
+    environments = $22,
+    protected_environments = $23,
+    container_port = $24,
+    health_check_path = $25,
+    cpu = $26,
+    memory = $27,
+    output_directory = $28,
+    deployment_target = $29,
+    lambda_endpoint = $30,
+    deploy_rules = $31,
+    public_status_page = $32,
+    build_size = $33,
+    build_timeout_minutes = $34,
+    redeploy_on_env_change = $35,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
 RETURNING *;
 
--- name: DeleteProject :exec
+-- name: BackfillProjectCustomDomain :one
+UPDATE projects
+SET custom_domain = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND custom_domain = ''
+RETURNING *;
+
+-- name: SoftDeleteProject :exec
+WITH deleted_deployments AS (
+    UPDATE deployments SET deleted_at = $2
+    WHERE deployments.project_id = $1 AND deployments.deleted_at IS NULL
+), deleted_archive AS (
+    UPDATE deployments_archive SET deleted_at = $2
+    WHERE deployments_archive.project_id = $1 AND deployments_archive.deleted_at IS NULL
+), cancelled_builds AS (
+    DELETE FROM build_jobs WHERE build_jobs.project_id = $1 AND build_jobs.status = 'PENDING'
+)
+UPDATE projects
+SET deleted_at = $2
+WHERE projects.id = $1 AND projects.deleted_at IS NULL;
+
+-- name: PurgeDeletedProjects :execrows
 DELETE FROM projects
-WHERE id = $1;
+WHERE projects.id IN (
+    SELECT p.id FROM projects p
+    WHERE p.deleted_at < $1
+    ORDER BY p.deleted_at
+    LIMIT $2
+);
 
 -- name: ExistsProjectByRepositoryURL :one
 SELECT EXISTS(
     SELECT 1 FROM projects
-    WHERE user_id = $1 AND repository_url = $2
+    WHERE user_id = $1 AND repository_url = $2 AND deleted_at IS NULL
 );
 
 -- name: ExistsProjectByCustomDomain :one
 SELECT EXISTS(
     SELECT 1 FROM projects
-    WHERE custom_domain = $1 AND custom_domain != ''
+    WHERE custom_domain = $1 AND custom_domain != '' AND deleted_at IS NULL
 );
 
 -- name: GetProjectByCustomDomain :one
 SELECT * FROM projects
-WHERE custom_domain = $1 AND custom_domain != '';
+WHERE custom_domain = $1 AND custom_domain != '' AND deleted_at IS NULL;
 
+-- name: ClaimStalledProjectTeardown :one
+UPDATE projects
+SET updated_at = CURRENT_TIMESTAMP
+WHERE id = (
+    SELECT candidate.id FROM projects candidate
+    WHERE candidate.status = 'DELETING' AND candidate.deleted_at IS NULL AND candidate.updated_at < $1
+    ORDER BY candidate.updated_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
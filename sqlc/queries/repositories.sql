@@ -1,7 +1,7 @@
 -- name: GetRepositoriesByUserID :many
 SELECT * FROM repositories
 WHERE user_id = $1
-ORDER BY created_at DESC
+ORDER BY created_at DESC, id DESC
 LIMIT $2 OFFSET $3;
 
 -- name: SearchRepositoriesByUserID :many
@@ -12,9 +12,28 @@ WHERE user_id = $1
     full_name LIKE '%' || $2 || '%' OR
     description LIKE '%' || $2 || '%'
   )
-ORDER BY created_at DESC
+ORDER BY created_at DESC, id DESC
 LIMIT $3 OFFSET $4;
 
+-- name: GetRepositoriesByUserIDAfter :many
+SELECT * FROM repositories
+WHERE user_id = sqlc.arg(user_id)
+  AND (created_at, id) < (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: SearchRepositoriesByUserIDAfter :many
+SELECT * FROM repositories
+WHERE user_id = sqlc.arg(user_id)
+  AND (
+    name LIKE '%' || sqlc.arg(search)::text || '%' OR
+    full_name LIKE '%' || sqlc.arg(search)::text || '%' OR
+    description LIKE '%' || sqlc.arg(search)::text || '%'
+  )
+  AND (created_at, id) < (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
 -- name: CountRepositoriesByUserID :one
 SELECT COUNT(*) FROM repositories
 WHERE user_id = $1;
@@ -28,14 +47,23 @@ WHERE user_id = $1
     description LIKE '%' || $2 || '%'
   );
 
+-- name: GetRepositoryByID :one
+SELECT * FROM repositories
+WHERE id = $1;
+
 -- name: GetRepositoryByURL :one
 SELECT * FROM repositories
 WHERE url = $1;
 
+-- name: GetRepositoriesByURLs :many
+SELECT * FROM repositories
+WHERE url = ANY(sqlc.arg(urls)::text[]);
+
 -- name: UpsertRepository :one
 INSERT INTO repositories (
     user_id,
-    github_id,
+    provider,
+    external_id,
     name,
     full_name,
     description,
@@ -49,7 +77,7 @@ INSERT INTO repositories (
     default_branch,
     language
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
 )
 ON CONFLICT (url) 
 DO UPDATE SET
@@ -67,6 +95,72 @@ DO UPDATE SET
     updated_at = CURRENT_TIMESTAMP
 RETURNING *;
 
+-- name: UpsertRepositories :exec
+INSERT INTO repositories (
+    user_id,
+    provider,
+    external_id,
+    name,
+    full_name,
+    description,
+    url,
+    html_url,
+    private,
+    fork,
+    stargazers_count,
+    watchers_count,
+    forks_count,
+    default_branch,
+    language
+)
+SELECT
+    r.user_id,
+    r.provider,
+    r.external_id,
+    r.name,
+    r.full_name,
+    NULLIF(r.description, ''),
+    r.url,
+    NULLIF(r.html_url, ''),
+    r.private,
+    r.fork,
+    r.stargazers_count,
+    r.watchers_count,
+    r.forks_count,
+    NULLIF(r.default_branch, ''),
+    NULLIF(r.language, '')
+FROM unnest(
+    sqlc.arg(user_ids)::uuid[],
+    sqlc.arg(providers)::text[],
+    sqlc.arg(external_ids)::text[],
+    sqlc.arg(names)::text[],
+    sqlc.arg(full_names)::text[],
+    sqlc.arg(descriptions)::text[],
+    sqlc.arg(urls)::text[],
+    sqlc.arg(html_urls)::text[],
+    sqlc.arg(privates)::boolean[],
+    sqlc.arg(forks)::boolean[],
+    sqlc.arg(stargazers_counts)::integer[],
+    sqlc.arg(watchers_counts)::integer[],
+    sqlc.arg(forks_counts)::integer[],
+    sqlc.arg(default_branches)::text[],
+    sqlc.arg(languages)::text[]
+) AS r(user_id, provider, external_id, name, full_name, description, url, html_url, private, fork, stargazers_count, watchers_count, forks_count, default_branch, language)
+ON CONFLICT (url)
+DO UPDATE SET
+    name = EXCLUDED.name,
+    full_name = EXCLUDED.full_name,
+    description = EXCLUDED.description,
+    html_url = EXCLUDED.html_url,
+    private = EXCLUDED.private,
+    fork = EXCLUDED.fork,
+    stargazers_count = EXCLUDED.stargazers_count,
+    watchers_count = EXCLUDED.watchers_count,
+    forks_count = EXCLUDED.forks_count,
+    default_branch = EXCLUDED.default_branch,
+    language = EXCLUDED.language,
+    updated_at = CURRENT_TIMESTAMP;
+
 -- name: DeleteRepository :exec
 DELETE FROM repositories
 WHERE id = $1;
@@ -0,0 +1,19 @@
+-- name: GetRepositorySync :one
+SELECT * FROM repository_syncs
+WHERE user_id = $1 AND provider = $2;
+
+-- name: UpsertRepositorySync :exec
+INSERT INTO repository_syncs (
+    user_id,
+    provider,
+    etag,
+    synced_at,
+    full_synced_at
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (user_id, provider)
+DO UPDATE SET
+    etag = EXCLUDED.etag,
+    synced_at = EXCLUDED.synced_at,
+    full_synced_at = EXCLUDED.full_synced_at;
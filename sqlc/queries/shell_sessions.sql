@@ -0,0 +1,43 @@
+-- name: UpsertShellSession :exec
+INSERT INTO shell_sessions (
+    id,
+    project_id,
+    user_id,
+    environment,
+    token_hash,
+    status,
+    task_arn,
+    end_reason,
+    error,
+    bytes_in,
+    bytes_out,
+    created_at,
+    started_at,
+    ended_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+)
+ON CONFLICT (id) DO UPDATE SET
+    status = EXCLUDED.status,
+    task_arn = EXCLUDED.task_arn,
+    end_reason = EXCLUDED.end_reason,
+    error = EXCLUDED.error,
+    bytes_in = EXCLUDED.bytes_in,
+    bytes_out = EXCLUDED.bytes_out,
+    started_at = EXCLUDED.started_at,
+    ended_at = EXCLUDED.ended_at;
+
+-- name: GetShellSessionByID :one
+SELECT * FROM shell_sessions
+WHERE id = $1;
+
+-- name: ClaimShellSession :execrows
+UPDATE shell_sessions
+SET status = 'ACTIVE', started_at = $2
+WHERE id = $1 AND status = 'PENDING';
+
+-- name: ListShellSessionsByProjectID :many
+SELECT * FROM shell_sessions
+WHERE project_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
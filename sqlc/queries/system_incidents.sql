@@ -0,0 +1,42 @@
+-- name: CreateSystemIncident :one
+INSERT INTO system_incidents (
+    id,
+    kind,
+    severity,
+    title,
+    message,
+    starts_at,
+    ends_at,
+    resolved_at,
+    created_by,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+)
+RETURNING *;
+
+-- name: GetSystemIncidentByID :one
+SELECT * FROM system_incidents
+WHERE id = $1;
+
+-- name: ListUnresolvedSystemIncidents :many
+SELECT * FROM system_incidents
+WHERE resolved_at IS NULL
+ORDER BY starts_at ASC;
+
+-- name: ListSystemIncidentsResolvedSince :many
+SELECT * FROM system_incidents
+WHERE resolved_at >= $1
+ORDER BY resolved_at DESC;
+
+-- name: UpdateSystemIncident :exec
+UPDATE system_incidents
+SET
+    severity = $2,
+    title = $3,
+    message = $4,
+    ends_at = $5,
+    resolved_at = $6,
+    updated_at = $7
+WHERE id = $1;
@@ -0,0 +1,34 @@
+-- name: CreateUsageRecord :one
+INSERT INTO usage_records (
+    id,
+    project_id,
+    user_id,
+    deployment_id,
+    metric,
+    quantity,
+    period_start,
+    period_end,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+RETURNING *;
+
+-- name: GetLastUsagePeriodEnd :one
+SELECT MAX(period_end)::timestamptz AS last_period_end FROM usage_records
+WHERE project_id = $1 AND metric = $2;
+
+-- name: SumUsageByProjectID :many
+SELECT metric, SUM(quantity)::double precision AS total FROM usage_records
+WHERE project_id = sqlc.arg(project_id)
+  AND period_end > sqlc.arg(from_time)
+  AND period_end <= sqlc.arg(to_time)
+GROUP BY metric;
+
+-- name: SumUsageByUserID :many
+SELECT project_id, metric, SUM(quantity)::double precision AS total FROM usage_records
+WHERE user_id = sqlc.arg(user_id)
+  AND period_end > sqlc.arg(from_time)
+  AND period_end <= sqlc.arg(to_time)
+GROUP BY project_id, metric
+ORDER BY project_id;
@@ -0,0 +1,23 @@
+-- name: GetUserQuota :one
+SELECT * FROM user_quotas
+WHERE user_id = $1;
+
+-- name: UpsertUserQuota :exec
+INSERT INTO user_quotas (
+    user_id,
+    plan,
+    max_projects,
+    max_concurrent_builds,
+    max_env_vars,
+    build_minutes_per_month,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    plan = EXCLUDED.plan,
+    max_projects = EXCLUDED.max_projects,
+    max_concurrent_builds = EXCLUDED.max_concurrent_builds,
+    max_env_vars = EXCLUDED.max_env_vars,
+    build_minutes_per_month = EXCLUDED.build_minutes_per_month,
+    updated_at = EXCLUDED.updated_at;